@@ -0,0 +1,113 @@
+// ABOUTME: pi-go auth subcommands: login, logout, and status for provider API keys
+// ABOUTME: Keys go to the OS keyring, falling back to ~/.pi-go/auth.json when there is none
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/config"
+)
+
+const authUsage = "usage: auth login <provider> | auth logout <provider> | auth status"
+
+// authProviders are always listed by auth status; others appear once a key
+// is stored for them.
+var authProviders = []string{"anthropic", "openai", "google"}
+
+// runAuth dispatches auth subcommands. login reads the key from stdin,
+// without echo when stdin is a terminal.
+func runAuth(args []string, stdin *os.File, w io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%s", authUsage)
+	}
+	auth, err := config.LoadAuth()
+	if err != nil {
+		return fmt.Errorf("loading auth: %w", err)
+	}
+	switch {
+	case args[0] == "login" && len(args) == 2:
+		key, err := readAPIKey(stdin, args[1])
+		if err != nil {
+			return err
+		}
+		return authLogin(auth, args[1], key, w)
+	case args[0] == "logout" && len(args) == 2:
+		removed, err := auth.RemoveKey(args[1])
+		if err != nil {
+			return err
+		}
+		if len(removed) == 0 {
+			fmt.Fprintf(w, "No stored %s key.\n", args[1])
+			return nil
+		}
+		fmt.Fprintf(w, "Removed the %s key from %s.\n", args[1], strings.Join(removed, " and "))
+		if source := auth.KeySource(args[1]); source != "" {
+			fmt.Fprintf(w, "Still set: %s.\n", source)
+		}
+		return nil
+	case args[0] == "status" && len(args) == 1:
+		providers := slices.Concat(authProviders, slices.Sorted(maps.Keys(auth.Keys)))
+		slices.Sort(providers)
+		for _, p := range slices.Compact(providers) {
+			source := auth.KeySource(p)
+			if source == "" {
+				source = "not set"
+			}
+			fmt.Fprintf(w, "%-12s %s\n", p, source)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%s", authUsage)
+	}
+}
+
+// authLogin stores key in the OS keyring, or in the auth file when the
+// keyring cannot be used.
+func authLogin(auth *config.AuthStore, provider, key string, w io.Writer) error {
+	err := auth.SaveToKeyring(provider, key)
+	if err == nil {
+		fmt.Fprintf(w, "Saved the %s key to the %s.\n", provider, config.KeyringName())
+		return nil
+	}
+	fmt.Fprintf(w, "warning: %v; using the auth file instead.\n", err)
+	auth.SetKey(provider, key)
+	if err := auth.Save(); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Saved the %s key to %s.\n", provider, config.AuthFile())
+	return nil
+}
+
+// readAPIKey prompts for provider's key on a terminal, or reads the first
+// line of piped input.
+func readAPIKey(stdin *os.File, provider string) (string, error) {
+	var key string
+	if fd := int(stdin.Fd()); term.IsTerminal(fd) {
+		fmt.Fprintf(os.Stderr, "%s API key: ", provider)
+		b, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("reading key: %w", err)
+		}
+		key = string(b)
+	} else {
+		line, err := bufio.NewReader(stdin).ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", fmt.Errorf("reading key: %w", err)
+		}
+		key = line
+	}
+	if key = strings.TrimSpace(key); key == "" {
+		return "", fmt.Errorf("no key given")
+	}
+	return key, nil
+}
@@ -0,0 +1,33 @@
+// ABOUTME: pi-go run subcommand: unattended runs of built-in batch templates such as repo-health
+// ABOUTME: Renders the template prompt for print mode and lists templates when none is given
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/batch"
+)
+
+// batchPrompt renders the --template prompt for a run in cwd.
+func batchPrompt(args cliArgs, cwd string) (string, error) {
+	vars, err := batch.ParseVars(args.vars)
+	if err != nil {
+		return "", err
+	}
+	return batch.Render(args.template, cwd, time.Now(), vars)
+}
+
+// printBatchTemplates lists the built-in templates with their variables.
+func printBatchTemplates(w io.Writer) {
+	fmt.Fprintln(w, "usage: pi-go run --template <name> [--var KEY=value ...] [flags]")
+	fmt.Fprintln(w, "\nTemplates:")
+	for _, t := range batch.Templates() {
+		fmt.Fprintf(w, "  %-14s %s\n", t.Name, t.Description)
+		for _, k := range t.VarNames() {
+			fmt.Fprintf(w, "  %-14s   --var %s=%s\n", "", k, t.Defaults[k])
+		}
+	}
+}
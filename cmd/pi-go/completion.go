@@ -0,0 +1,332 @@
+// ABOUTME: pi-go completion: bash, zsh, and fish scripts that ask the binary for candidates
+// ABOUTME: The hidden __complete subcommand completes subcommands, flags, models, and session IDs
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/batch"
+	"github.com/mauromedda/pi-coding-agent-go/internal/config"
+	"github.com/mauromedda/pi-coding-agent-go/internal/session"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+const completionUsage = "usage: completion <bash|zsh|fish>"
+
+// filesDirective asks the shell script to complete file names instead.
+const filesDirective = ":files"
+
+// candidate is one completion, with an optional description for zsh and fish.
+type candidate struct {
+	value, desc string
+}
+
+// subcommands are the commands main dispatches before flag parsing.
+var subcommands = []candidate{
+	{"auth", "Manage provider API keys"},
+	{"completion", "Print a shell completion script"},
+	{"doctor", "Check the environment and configuration"},
+	{"eval", "Score prompt versions and models on task fixtures"},
+	{"export", "Export a session as Markdown or HTML"},
+	{"install", "Install a package"},
+	{"list", "List installed packages"},
+	{"package", "Create or publish a package"},
+	{"remove", "Remove a package"},
+	{"run", "Run a batch template unattended"},
+	{"serve", "Run the agent behind a REST/SSE API"},
+	{"sessions", "List, import, or search sessions"},
+	{"setup", "Choose a provider, key, model, theme, and permissions"},
+	{"share", "Open a shared session"},
+	{"update", "Update installed packages"},
+}
+
+// evalFlags are the flags of the eval subcommand.
+var evalFlags = []candidate{
+	{"--model", "Comma-separated models to compare"},
+	{"--prompt-version", "Comma-separated prompt versions to compare"},
+	{"--bin", "pi-go binary to evaluate"},
+	{"--json", "Write the report as JSON"},
+	{"-o", "Write the report to a file"},
+	{"--keep", "Keep each run's working directory"},
+	{"--strict", "Exit non-zero when any check fails"},
+}
+
+// flagValues lists the fixed values of enumerated flags.
+var flagValues = map[string][]string{
+	"permission-mode": {"default", "acceptEdits", "plan", "dontAsk", "bypassPermissions"},
+	"output-format":   {"text", "json", "stream-json"},
+	"input-format":    {"stream-json"},
+	"style":           {"concise", "verbose", "formal", "casual"},
+}
+
+// runCompletion prints the completion script for a shell.
+func runCompletion(args []string, w io.Writer) error {
+	if len(args) != 1 {
+		return fmt.Errorf("%s", completionUsage)
+	}
+	script, ok := completionScripts[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown shell %q: expected bash, zsh, or fish", args[0])
+	}
+	_, err := io.WriteString(w, script)
+	return err
+}
+
+// runComplete prints the candidates for the last of words, the command line
+// after "pi-go", one per line as "value" or "value<TAB>description".
+func runComplete(words []string, w io.Writer) error {
+	for _, c := range completeWords(words) {
+		if c.desc == "" {
+			fmt.Fprintln(w, c.value)
+		} else {
+			fmt.Fprintf(w, "%s\t%s\n", c.value, c.desc)
+		}
+	}
+	return nil
+}
+
+// completeWords completes the last word given the ones before it.
+func completeWords(words []string) []candidate {
+	// bash splits "--model=x" into "--model", "=", "x".
+	words = slices.DeleteFunc(slices.Clone(words), func(w string) bool { return w == "=" })
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	cur, prev := words[len(words)-1], words[:len(words)-1]
+
+	sub := ""
+	if len(prev) > 0 && !strings.HasPrefix(prev[0], "-") {
+		sub, prev = prev[0], prev[1:]
+	}
+
+	// --flag=value in a single word.
+	if name, value, ok := strings.Cut(cur, "="); ok && strings.HasPrefix(name, "-") {
+		return withPrefix(name+"=", flagValueCandidates(strings.TrimLeft(name, "-"), value))
+	}
+	if len(prev) > 0 && takesValue(sub, prev[len(prev)-1]) {
+		return flagValueCandidates(strings.TrimLeft(prev[len(prev)-1], "-"), cur)
+	}
+	if strings.HasPrefix(cur, "-") {
+		return filterPrefix(flagCandidates(sub), cur)
+	}
+	if sub == "" {
+		if len(prev) == 0 {
+			return filterPrefix(subcommands, cur)
+		}
+		return nil
+	}
+	var positional []string
+	for _, w := range prev {
+		if !strings.HasPrefix(w, "-") {
+			positional = append(positional, w)
+		}
+	}
+	return filterPrefix(argCandidates(sub, positional), cur)
+}
+
+// argCandidates returns the candidates for a subcommand's next positional
+// argument.
+func argCandidates(sub string, positional []string) []candidate {
+	words := func(values ...string) []candidate {
+		cs := make([]candidate, len(values))
+		for i, v := range values {
+			cs[i] = candidate{value: v}
+		}
+		return cs
+	}
+	switch {
+	case sub == "auth" && len(positional) == 0:
+		return words("login", "logout", "status")
+	case sub == "auth" && len(positional) == 1 && positional[0] != "status":
+		return words(authProviders...)
+	case sub == "completion" && len(positional) == 0:
+		return words("bash", "zsh", "fish")
+	case sub == "doctor" && len(positional) == 0:
+		return words("terminal", "network")
+	case sub == "package" && len(positional) == 0:
+		return words("init", "publish")
+	case sub == "export" && len(positional) == 0:
+		return sessionCandidates()
+	case sub == "eval" && len(positional) == 0:
+		return []candidate{{value: filesDirective}}
+	case sub == "sessions" && len(positional) == 0:
+		return words("list", "import", "search")
+	case sub == "share" && len(positional) == 0:
+		return words("open")
+	}
+	return nil
+}
+
+// flagCandidates lists the flags a subcommand accepts: the main flags for
+// the agent itself and for serve and run.
+func flagCandidates(sub string) []candidate {
+	switch sub {
+	case "", "serve", "run":
+	case "export":
+		return []candidate{{"--format", "Output format: md or html"}, {"-o", "Output file"}}
+	case "eval":
+		return evalFlags
+	default:
+		return nil
+	}
+	var cs []candidate
+	mainFlags().VisitAll(func(f *flag.Flag) {
+		cs = append(cs, candidate{"--" + f.Name, f.Usage})
+	})
+	return cs
+}
+
+// takesValue reports whether word is a flag whose value is the next word.
+func takesValue(sub, word string) bool {
+	if !strings.HasPrefix(word, "-") || strings.Contains(word, "=") {
+		return false
+	}
+	name := strings.TrimLeft(word, "-")
+	if sub == "export" {
+		return name == "format" || name == "o"
+	}
+	if sub == "eval" {
+		return name == "model" || name == "prompt-version" || name == "bin" || name == "o"
+	}
+	if sub != "" && sub != "serve" && sub != "run" {
+		return false
+	}
+	f := mainFlags().Lookup(name)
+	if f == nil {
+		return false
+	}
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return !ok || !b.IsBoolFlag()
+}
+
+// flagValueCandidates completes the value of the named flag.
+func flagValueCandidates(name, cur string) []candidate {
+	var cs []candidate
+	switch name {
+	case "model":
+		for _, m := range ai.BuiltinModels() {
+			cs = append(cs, candidate{m.ID, m.Name})
+		}
+	case "template":
+		for _, t := range batch.Templates() {
+			cs = append(cs, candidate{t.Name, t.Description})
+		}
+	case "format":
+		cs = []candidate{{value: "md"}, {value: "html"}}
+	case "json-schema", "o", "bin", "record", "replay", "log-file":
+		return []candidate{{value: filesDirective}}
+	default:
+		for _, v := range flagValues[name] {
+			cs = append(cs, candidate{value: v})
+		}
+	}
+	return filterPrefix(cs, cur)
+}
+
+// sessionCandidates lists stored sessions, newest first, described by
+// start time and working directory.
+func sessionCandidates() []candidate {
+	sessions, err := session.ListSessionsInDir(config.SessionsDir())
+	if err != nil {
+		return nil
+	}
+	slices.SortFunc(sessions, func(a, b session.SessionStartData) int { return b.StartedAt.Compare(a.StartedAt) })
+	cs := make([]candidate, 0, len(sessions))
+	for _, s := range sessions {
+		cs = append(cs, candidate{s.ID, s.StartedAt.Local().Format("2006-01-02 15:04") + " " + s.CWD})
+	}
+	return cs
+}
+
+// mainFlags returns the main command's flags, unparsed.
+func mainFlags() *flag.FlagSet {
+	fs := flag.NewFlagSet("pi-go", flag.ContinueOnError)
+	defineFlags(fs, &cliArgs{})
+	return fs
+}
+
+// filterPrefix keeps the candidates starting with prefix.
+func filterPrefix(cs []candidate, prefix string) []candidate {
+	return slices.DeleteFunc(slices.Clone(cs), func(c candidate) bool {
+		return c.value != filesDirective && !strings.HasPrefix(c.value, prefix)
+	})
+}
+
+// withPrefix prepends prefix to every candidate value.
+func withPrefix(prefix string, cs []candidate) []candidate {
+	for i := range cs {
+		if cs[i].value != filesDirective {
+			cs[i].value = prefix + cs[i].value
+		}
+	}
+	return cs
+}
+
+// completionScripts hold the per-shell glue; all logic lives in __complete.
+var completionScripts = map[string]string{
+	"bash": `# bash completion for pi-go
+# Load with: source <(pi-go completion bash)
+
+_pi_go() {
+    local cur=${COMP_WORDS[COMP_CWORD]}
+    local IFS=$'\n'
+    local -a out
+    out=($(pi-go __complete "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null))
+    if [[ ${out[0]} == ":files" ]]; then
+        COMPREPLY=($(compgen -f -- "$cur"))
+        return
+    fi
+    COMPREPLY=("${out[@]%%$'\t'*}")
+}
+
+complete -F _pi_go pi-go
+`,
+	"zsh": `#compdef pi-go
+# zsh completion for pi-go
+# Load with: source <(pi-go completion zsh), or save as _pi-go in $fpath
+
+_pi_go() {
+  local -a lines specs
+  local line word desc
+  lines=("${(@f)$(pi-go __complete "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+  if [[ ${lines[1]} == ":files" ]]; then
+    _files
+    return
+  fi
+  for line in "${lines[@]}"; do
+    [[ -z $line ]] && continue
+    word=${line%%$'\t'*}
+    desc=${line#*$'\t'}
+    [[ $desc == "$line" ]] && desc=
+    specs+=("${word//:/\\:}${desc:+:$desc}")
+  done
+  (( ${#specs} )) && _describe -t values pi-go specs
+}
+
+if [[ $funcstack[1] == _pi_go ]]; then
+  _pi_go "$@"
+else
+  compdef _pi_go pi-go
+fi
+`,
+	"fish": `# fish completion for pi-go
+# Load with: pi-go completion fish | source
+
+function __pi_go_complete
+    set -l args (commandline -opc)[2..-1] (commandline -ct)
+    set -l out (pi-go __complete $args 2>/dev/null)
+    if test "$out[1]" = ":files"
+        __fish_complete_path (commandline -ct)
+        return
+    end
+    printf '%s\n' $out
+end
+
+complete -c pi-go -f -a '(__pi_go_complete)'
+`,
+}
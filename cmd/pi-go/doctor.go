@@ -0,0 +1,397 @@
+// ABOUTME: pi-go doctor: checks config files, auth keys, provider reachability, git, terminal, and MCP servers
+// ABOUTME: Each failed or doubtful check prints a fix; "doctor terminal" and "doctor network" run one section
+
+package main
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/config"
+	"github.com/mauromedda/pi-coding-agent-go/internal/mcp"
+	"github.com/mauromedda/pi-coding-agent-go/internal/plugin"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/termcap"
+)
+
+// doctorTimeout bounds each network probe and MCP server handshake.
+const doctorTimeout = 10 * time.Second
+
+// runDoctor runs every check, or only the named section. It fails when any
+// check fails; warnings alone do not.
+func runDoctor(args []string, w io.Writer) error {
+	if len(args) > 1 {
+		return fmt.Errorf("usage: doctor [terminal|network]")
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("getting working directory: %w", err)
+	}
+	r := &doctorReport{w: w}
+	if len(args) == 1 {
+		switch args[0] {
+		case "terminal":
+			printTermcapMatrix(w, termcap.Detect(), "")
+			return nil
+		case "network":
+			cfg, err := config.LoadAll(cwd, nil)
+			if err != nil {
+				return fmt.Errorf("loading config: %w", err)
+			}
+			checkNetwork(r, cfg, nil)
+			return r.result()
+		default:
+			return fmt.Errorf("unknown subcommand %q: expected terminal or network", args[0])
+		}
+	}
+
+	cfg := checkConfig(r, cwd)
+	needed := checkAuth(r, cfg)
+	checkNetwork(r, cfg, needed)
+	checkGit(r, cwd)
+	r.section("Terminal")
+	printTermcapMatrix(w, termcap.Detect(), "  ")
+	checkMCP(r, cwd)
+	checkPlugins(r, cfg, cwd)
+	return r.result()
+}
+
+// doctorReport prints check results and counts failures.
+type doctorReport struct {
+	w       io.Writer
+	failed  int
+	started bool
+}
+
+func (r *doctorReport) section(title string) {
+	if r.started {
+		fmt.Fprintln(r.w)
+	}
+	r.started = true
+	fmt.Fprintln(r.w, title)
+}
+
+func (r *doctorReport) ok(format string, args ...any) {
+	fmt.Fprintf(r.w, "  ok    %s\n", fmt.Sprintf(format, args...))
+}
+
+// info prints a neutral line, e.g. a provider without a key.
+func (r *doctorReport) info(format string, args ...any) {
+	fmt.Fprintf(r.w, "  -     %s\n", fmt.Sprintf(format, args...))
+}
+
+func (r *doctorReport) warn(fix, format string, args ...any) {
+	r.print("warn", fix, format, args...)
+}
+
+func (r *doctorReport) fail(fix, format string, args ...any) {
+	r.failed++
+	r.print("FAIL", fix, format, args...)
+}
+
+func (r *doctorReport) print(label, fix, format string, args ...any) {
+	fmt.Fprintf(r.w, "  %-4s  %s\n", label, fmt.Sprintf(format, args...))
+	if fix != "" {
+		fmt.Fprintf(r.w, "        fix: %s\n", fix)
+	}
+}
+
+func (r *doctorReport) result() error {
+	if r.failed > 0 {
+		return fmt.Errorf("%d check(s) failed", r.failed)
+	}
+	return nil
+}
+
+// checkConfig parses every settings file on its own, since the loader skips
+// one that does not parse, then loads the merged settings. It returns the
+// defaults when they do not load, so later checks still run.
+func checkConfig(r *doctorReport, cwd string) *config.Settings {
+	r.section("Config")
+	found := false
+	for _, path := range config.SettingsFiles(cwd) {
+		err := config.CheckSettingsFile(path)
+		switch {
+		case os.IsNotExist(err):
+			continue
+		case err != nil:
+			r.fail("correct the JSON; pi-go ignores this file until it parses", "%v", err)
+		default:
+			r.ok("%s", path)
+		}
+		found = true
+	}
+	if !found {
+		r.info("no settings files; using the defaults")
+	}
+	cfg, err := config.LoadAll(cwd, nil)
+	if err != nil {
+		r.fail("pi-go refuses to start until this is fixed", "loading settings: %v", err)
+		return &config.Settings{}
+	}
+	return cfg
+}
+
+// checkAuth lists where each provider's key comes from. Only a missing key
+// for the configured model's provider fails. It reports which providers are
+// in use: those with a key and the model's.
+func checkAuth(r *doctorReport, cfg *config.Settings) func(ai.Api) bool {
+	r.section("Auth")
+	var model *ai.Model
+	if m, err := config.ResolveModel(cfg.Model); err == nil {
+		model = m
+	}
+	inUse := func(api ai.Api) bool { return model != nil && api == model.Api }
+
+	auth, err := config.LoadAuth()
+	if err != nil {
+		r.fail("correct or remove "+config.AuthFile(), "loading auth: %v", err)
+		return inUse
+	}
+	config.MergePiAuth(auth, config.PiAgentDir())
+
+	withKey := map[ai.Api]bool{}
+	for _, p := range authProviders {
+		source := auth.KeySource(p)
+		switch {
+		case source != "":
+			withKey[ai.Api(p)] = true
+			r.ok("%-9s key from %s", p, source)
+		case model != nil && string(model.Api) == p && cfg.BaseURL == "":
+			r.fail(fmt.Sprintf("run `pi-go auth login %s` or set %s_API_KEY", p, strings.ToUpper(p)),
+				"%-9s no key, but the model %s needs one", p, model.ID)
+		default:
+			r.info("%-9s no key", p)
+		}
+	}
+	return func(api ai.Api) bool { return withKey[api] || inUse(api) }
+}
+
+// endpoint is a provider base URL probed by checkNetwork.
+type endpoint struct {
+	api ai.Api
+	url string
+}
+
+// checkNetwork prints the proxy and CA settings in effect and sends a
+// request to every provider endpoint through the same transport the
+// provider would use. Any HTTP response counts as reachable. An unreachable
+// endpoint fails when inUse reports its provider, or inUse is nil, and
+// only warns otherwise.
+func checkNetwork(r *doctorReport, cfg *config.Settings, inUse func(ai.Api) bool) {
+	r.section("Network")
+	n := cfg.Network
+	switch {
+	case n != nil && n.Proxy != "":
+		r.info("proxy %s (network.proxy)", n.RedactedProxy())
+	case os.Getenv("HTTPS_PROXY") != "" || os.Getenv("https_proxy") != "":
+		r.info("proxy from HTTPS_PROXY")
+	case os.Getenv("HTTP_PROXY") != "" || os.Getenv("http_proxy") != "":
+		r.info("proxy from HTTP_PROXY")
+	default:
+		r.info("no proxy")
+	}
+	if n != nil && n.CACert != "" {
+		r.info("trusting the system roots and %s", n.CACert)
+	}
+	if n != nil && n.InsecureSkipVerify {
+		r.warn("remove network.insecureSkipVerify once the CA bundle works", "TLS certificates are not verified")
+	}
+
+	for _, e := range networkEndpoints(cfg) {
+		opts := n.HTTPOptionsFor(string(e.api), e.url)
+		opts.RequestTimeout = doctorTimeout
+		start := time.Now()
+		resp, err := opts.NewClient().Get(e.url)
+		if err != nil {
+			report := r.fail
+			if inUse != nil && !inUse(e.api) && e.url != cfg.BaseURL {
+				report = r.warn
+			}
+			report(networkHint(err), "%-9s %s: %v", e.api, e.url, err)
+			continue
+		}
+		resp.Body.Close()
+		r.ok("%-9s %s (HTTP %d, %s)", e.api, e.url, resp.StatusCode, time.Since(start).Round(time.Millisecond))
+	}
+}
+
+// networkEndpoints lists the hosted providers' endpoints plus every base URL
+// the settings configure, without duplicates.
+func networkEndpoints(cfg *config.Settings) []endpoint {
+	endpoints := []endpoint{
+		{ai.ApiAnthropic, "https://api.anthropic.com"},
+		{ai.ApiOpenAI, "https://api.openai.com"},
+		{ai.ApiGoogle, "https://generativelanguage.googleapis.com"},
+	}
+	add := func(modelID, baseURL string) {
+		if baseURL == "" {
+			return
+		}
+		api := ai.ApiOpenAI
+		if m, err := config.ResolveModel(modelID); err == nil {
+			api = m.Api
+		}
+		e := endpoint{api, baseURL}
+		if !slices.Contains(endpoints, e) {
+			endpoints = append(endpoints, e)
+		}
+	}
+	add(cfg.Model, cfg.BaseURL)
+	for _, id := range slices.Sorted(maps.Keys(cfg.ModelOverrides)) {
+		add(id, cfg.ModelOverrides[id].BaseURL)
+	}
+	return endpoints
+}
+
+// networkHint suggests the setting that usually fixes a failed probe.
+func networkHint(err error) string {
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostname x509.HostnameError
+	switch {
+	case errors.As(err, &unknownAuthority):
+		return "the certificate is signed by an unknown CA; set network.caCert to your CA bundle"
+	case errors.As(err, &hostname):
+		return "the certificate does not match the host; check network.proxy and the base URL"
+	case errors.Is(err, http.ErrSchemeMismatch):
+		return "the endpoint speaks plain HTTP; check the base URL scheme"
+	case errors.Is(err, context.DeadlineExceeded) || os.IsTimeout(err):
+		return "no answer in time; check the network, network.proxy, or HTTPS_PROXY"
+	default:
+		return ""
+	}
+}
+
+// checkGit reports the git version and whether cwd is inside a repository.
+func checkGit(r *doctorReport, cwd string) {
+	r.section("Git")
+	if _, err := exec.LookPath("git"); err != nil {
+		r.fail("install git and make sure it is on PATH", "git not found")
+		return
+	}
+	out, err := exec.Command("git", "--version").Output()
+	if err != nil {
+		r.fail("reinstall git", "git --version: %v", err)
+		return
+	}
+	r.ok("%s", strings.TrimSpace(string(out)))
+	top, err := exec.Command("git", "-C", cwd, "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		r.warn("run `git init` to enable checkpoints and session worktrees", "%s is not in a git repository", cwd)
+		return
+	}
+	r.ok("repository %s", strings.TrimSpace(string(top)))
+}
+
+// checkMCP connects to every configured MCP server and lists its tools.
+func checkMCP(r *doctorReport, cwd string) {
+	r.section("MCP servers")
+	home, _ := os.UserHomeDir()
+	servers := mcp.LoadConfig(cwd, home)
+	if len(servers) == 0 {
+		r.info("no MCP servers configured")
+		return
+	}
+	for _, name := range slices.Sorted(maps.Keys(servers)) {
+		sc := servers[name]
+		start := time.Now()
+		tools, err := probeMCPServer(sc)
+		if err != nil {
+			fix := fmt.Sprintf("check that `%s` runs on its own", strings.Join(append([]string{sc.Command}, sc.Args...), " "))
+			if sc.Type == "http" {
+				fix = "check the url and that the server is running"
+			}
+			r.fail(fix, "%s: %v", name, err)
+			continue
+		}
+		r.ok("%s: %d tools (%s)", name, tools, time.Since(start).Round(time.Millisecond))
+	}
+}
+
+// checkPlugins starts every discovered and declared plugin and lists what
+// it registers.
+func checkPlugins(r *doctorReport, cfg *config.Settings, cwd string) {
+	r.section("Plugins")
+	var declared map[string]config.PluginDef
+	if cfg != nil {
+		declared = cfg.Plugins
+	}
+	defs, errs := plugin.Discover(config.PluginsDirs(), declared)
+	for _, err := range errs {
+		r.fail("fix the plugin.json or the plugins entry in settings", "%v", err)
+	}
+	if len(defs) == 0 && len(errs) == 0 {
+		r.info("no plugins installed in %s", strings.Join(config.PluginsDirs(), " or "))
+		return
+	}
+	for _, name := range slices.Sorted(maps.Keys(defs)) {
+		def := defs[name]
+		start := time.Now()
+		p, err := plugin.Start(context.Background(), def, cwd)
+		if err != nil {
+			r.fail(fmt.Sprintf("check that `%s` runs on its own and answers initialize", strings.Join(append([]string{def.Command}, def.Args...), " ")), "%v", err)
+			continue
+		}
+		elapsed := time.Since(start).Round(time.Millisecond)
+		_ = p.Close()
+		r.ok("%s: %d tools, %d commands, %d events (%s)", name, len(p.Caps.Tools), len(p.Caps.Commands), len(p.Caps.Events), elapsed)
+	}
+}
+
+// probeMCPServer performs the initialize handshake and returns the number
+// of tools the server offers.
+func probeMCPServer(sc mcp.ServerConfig) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), doctorTimeout)
+	defer cancel()
+
+	var transport mcp.Transport
+	switch sc.Type {
+	case "http":
+		if sc.URL == "" {
+			return 0, errors.New("http server without a url")
+		}
+		transport = mcp.NewHTTPTransport(sc.URL, "")
+	default:
+		env := os.Environ()
+		for k, v := range sc.Env {
+			env = append(env, k+"="+v)
+		}
+		t, err := mcp.NewStdioTransport(ctx, sc.Command, sc.Args, env)
+		if err != nil {
+			return 0, err
+		}
+		transport = t
+	}
+	client := mcp.NewClient(transport)
+	defer client.Close()
+	if err := client.Connect(ctx); err != nil {
+		return 0, err
+	}
+	tools, err := client.ListTools(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return len(tools), nil
+}
+
+func printTermcapMatrix(w io.Writer, caps termcap.Capabilities, indent string) {
+	rows := caps.Matrix()
+	labelWidth := 0
+	for _, r := range rows {
+		labelWidth = max(labelWidth, len(r[0]))
+	}
+	for _, r := range rows {
+		fmt.Fprintf(w, "%s%-*s  %s\n", indent, labelWidth, r[0], r[1])
+	}
+	fmt.Fprintf(w, "%s%-*s  %s\n", indent, labelWidth, "hyperlink", caps.Hyperlink("https://github.com/mauromedda/pi-coding-agent-go", "pi-go"))
+}
@@ -0,0 +1,99 @@
+// ABOUTME: pi-go eval subcommand: runs task fixtures against models and prompt versions in print mode
+// ABOUTME: Scores tool calls and output assertions and prints a comparison report (text or --json)
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/eval"
+)
+
+const evalUsage = "usage: eval <fixtures-dir> [--model a,b] [--prompt-version v1,v2] [--bin path] [--json] [-o path] [--keep] [--strict]"
+
+// runEval runs every fixture under the fixtures directory once per model
+// and prompt version, then writes the report to stdout or -o. Progress
+// goes to stderr.
+func runEval(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("eval", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	models := fs.String("model", "", "Comma-separated models to compare (default: the configured model)")
+	versions := fs.String("prompt-version", "", "Comma-separated prompt versions to compare (default: the built-in prompt)")
+	bin := fs.String("bin", "", "pi-go binary to evaluate (default: this one)")
+	asJSON := fs.Bool("json", false, "Write the report as JSON")
+	outPath := fs.String("o", "", "Write the report to a file")
+	keep := fs.Bool("keep", false, "Keep each run's working directory")
+	strict := fs.Bool("strict", false, "Exit non-zero when any check fails")
+
+	// Accept the fixtures directory before or after the flags.
+	var dir string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		dir, args = args[0], args[1:]
+	}
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("eval: %w", err)
+	}
+	if dir == "" && fs.NArg() > 0 {
+		dir = fs.Arg(0)
+	}
+	if dir == "" {
+		return fmt.Errorf("%s", evalUsage)
+	}
+
+	tasks, err := eval.LoadTasks(dir)
+	if err != nil {
+		return err
+	}
+	if *bin == "" {
+		if *bin, err = os.Executable(); err != nil {
+			return fmt.Errorf("locating pi-go binary: %w", err)
+		}
+	}
+	variants := eval.Variants(splitList(*models), splitList(*versions))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	rep, err := eval.Run(ctx, tasks, variants, eval.ExecRunner(*bin), eval.Options{Progress: stderr, KeepDirs: *keep})
+	if err != nil {
+		return fmt.Errorf("eval: %w", err)
+	}
+
+	w := stdout
+	if *outPath != "" {
+		f, err := os.Create(*outPath)
+		if err != nil {
+			return fmt.Errorf("create file: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+	if *asJSON {
+		err = rep.WriteJSON(w)
+	} else {
+		err = rep.WriteText(w)
+	}
+	if err != nil {
+		return fmt.Errorf("writing report: %w", err)
+	}
+	if *strict && rep.Failed() {
+		return fmt.Errorf("eval: some checks failed")
+	}
+	return nil
+}
+
+// splitList splits a comma-separated flag value, dropping empty items.
+func splitList(s string) []string {
+	var out []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
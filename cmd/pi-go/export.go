@@ -0,0 +1,95 @@
+// ABOUTME: pi-go export subcommand: renders a saved session as Markdown or standalone HTML
+// ABOUTME: Writes to -o (format from its extension) or stdout; --format overrides the choice
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/config"
+	"github.com/mauromedda/pi-coding-agent-go/internal/export"
+	"github.com/mauromedda/pi-coding-agent-go/internal/session"
+)
+
+const exportUsage = "usage: export <session-id> [--format md|html] [-o path] [--no-redact]"
+
+// runExport renders the session sessionID from sessionsDir. Without -o the
+// transcript goes to stdout.
+func runExport(args []string, sessionsDir string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	formatFlag := fs.String("format", "", "Output format: md or html (default: from -o, else md)")
+	outPath := fs.String("o", "", "Output file (default: stdout)")
+	noRedact := fs.Bool("no-redact", false, "Leave secrets in the export (redaction settings)")
+
+	// Accept the session ID before or after the flags.
+	var id string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		id, args = args[0], args[1:]
+	}
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+	if id == "" && fs.NArg() > 0 {
+		id = fs.Arg(0)
+	}
+	if id == "" {
+		return fmt.Errorf("%s", exportUsage)
+	}
+
+	format := export.FormatForPath(*outPath)
+	if *formatFlag != "" {
+		f, err := export.ParseFormat(*formatFlag)
+		if err != nil {
+			return err
+		}
+		format = f
+	}
+
+	records, err := session.ReadRecordsInDir(sessionsDir, id)
+	if err != nil {
+		return err
+	}
+	tr, err := export.SessionTranscript(records)
+	if err != nil {
+		return fmt.Errorf("reading session %s: %w", id, err)
+	}
+	if !*noRedact {
+		// Sessions recorded with redaction off, or before a pattern was
+		// added, are redacted now.
+		cwd, _ := os.Getwd()
+		cfg, err := config.LoadAll(cwd, nil)
+		if err != nil {
+			return fmt.Errorf("loading settings: %w", err)
+		}
+		redactor, err := newRedactor(cfg.Redaction, false)
+		if err != nil {
+			return err
+		}
+		if redactor != nil {
+			tr.Messages = redactor.Messages(tr.Messages)
+		}
+	}
+
+	w := stdout
+	if *outPath != "" {
+		f, err := os.Create(*outPath)
+		if err != nil {
+			return fmt.Errorf("create file: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := tr.Write(w, format, export.Options{ExportedAt: time.Now()}); err != nil {
+		return fmt.Errorf("exporting session %s: %w", id, err)
+	}
+	if *outPath != "" {
+		fmt.Fprintf(stdout, "exported session %s to %s\n", id, *outPath)
+	}
+	return nil
+}
@@ -3,7 +3,12 @@
 
 package main
 
-import "flag"
+import (
+	"flag"
+	"strings"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/mode/serve"
+)
 
 type cliArgs struct {
 	yolo             bool
@@ -24,43 +29,95 @@ type cliArgs struct {
 	permissionMode   string // --permission-mode
 	allowedTools     string // --allowedTools (comma-separated)
 	disallowedTools  string // --disallowedTools (comma-separated)
+	permPromptTool   string // --permission-prompt-tool command or mcp__ tool deciding approvals in print mode
 	lean             bool   // --lean minimal system prompt
 	dangerouslySkip  bool   // --dangerously-skip-permissions
 	verbose          bool   // -v / --verbose debug output
+	logFile          string // --log-file structured log destination instead of the per-session file
+	dumpLLM          bool   // --dump-llm write each provider request and response beside the log
+	noRedact         bool   // --no-redact leave secrets in tool results and exports for this run
 	noWorktree       bool   // --no-worktree disable session worktree
+	container        bool   // --container run bash in a Docker or Podman container (container settings)
+	devcontainer     bool   // --devcontainer run bash in the project's devcontainer
+	serve            bool   // set by the "serve" subcommand
+	listen           string // --listen address for serve mode
+	serveToken       string // --serve-token bearer token for serve mode
+	ideLink          bool   // --ide listen for live editor context from an IDE extension
+	offline          bool   // --offline local models only; network features fail fast
+	ascii            bool   // --ascii draw the TUI with ASCII characters only
+	record           string // --record cassette file receiving every provider response
+	replay           string // --replay cassette file answering provider calls
+	replaySpeed      float64 // --replay-speed timing scale for --replay; 0 = no delays
+
+	// run subcommand: unattended batch template runs
+	run      bool       // set by the "run" subcommand
+	template string     // --template batch template name
+	vars     stringList // --var KEY=value template variables
 }
 
 func parseFlags() cliArgs {
 	var args cliArgs
-
-	flag.BoolVar(&args.yolo, "yolo", false, "Skip all permission prompts")
-	flag.StringVar(&args.model, "model", "", "Model to use (e.g., claude-sonnet-4-20250514)")
-	flag.BoolVar(&args.plan, "plan", false, "Start in plan mode")
-	flag.BoolVar(&args.print, "print", false, "Non-interactive print mode")
-	flag.BoolVar(&args.thinking, "thinking", false, "Enable thinking/reasoning")
-	flag.BoolVar(&args.version, "version", false, "Show version and exit")
-	flag.BoolVar(&args.update, "update", false, "Self-update to latest version")
-	flag.StringVar(&args.baseURL, "base-url", "", "Custom API base URL")
-	flag.IntVar(&args.maxTurns, "max-turns", 0, "Maximum agent turns (0 = unlimited)")
-	flag.Float64Var(&args.maxBudget, "max-budget-usd", 0.0, "Maximum budget in USD (0 = unlimited)")
-	flag.StringVar(&args.outputFormat, "output-format", "text", "Output format: text, json, stream-json")
-	flag.StringVar(&args.inputFormat, "input-format", "", "Input format: empty = plain text, stream-json = JSONL from stdin")
-	flag.StringVar(&args.jsonSchema, "json-schema", "", "Path to JSON schema file for output validation")
-	flag.StringVar(&args.style, "style", "", "Output style: concise, verbose, formal, casual")
-	flag.StringVar(&args.prompt, "p", "", "Non-interactive mode: run prompt and exit")
-	flag.StringVar(&args.permissionMode, "permission-mode", "", "Permission mode: default, acceptEdits, plan, dontAsk, bypassPermissions")
-	flag.StringVar(&args.allowedTools, "allowedTools", "", "Comma-separated list of allowed tools")
-	flag.StringVar(&args.disallowedTools, "disallowedTools", "", "Comma-separated list of disallowed tools")
-	flag.BoolVar(&args.lean, "lean", false, "Use minimal system prompt (no memory, personality, context)")
-	flag.BoolVar(&args.dangerouslySkip, "dangerously-skip-permissions", false, "Skip all permission checks (alias for bypassPermissions)")
-	flag.BoolVar(&args.verbose, "v", false, "Enable verbose debug output")
-	flag.BoolVar(&args.verbose, "verbose", false, "Enable verbose debug output")
-	flag.BoolVar(&args.noWorktree, "no-worktree", false, "Disable session worktree isolation")
-
+	defineFlags(flag.CommandLine, &args)
 	flag.Parse()
 	return args
 }
 
+// defineFlags registers the command-line flags on fs, bound to a. Shell
+// completion reads the same definitions.
+func defineFlags(fs *flag.FlagSet, a *cliArgs) {
+	fs.BoolVar(&a.yolo, "yolo", false, "Skip all permission prompts")
+	fs.StringVar(&a.model, "model", "", "Model to use (e.g., claude-sonnet-4-20250514)")
+	fs.BoolVar(&a.plan, "plan", false, "Start in plan mode")
+	fs.BoolVar(&a.print, "print", false, "Non-interactive print mode")
+	fs.BoolVar(&a.thinking, "thinking", false, "Enable thinking/reasoning")
+	fs.BoolVar(&a.version, "version", false, "Show version and exit")
+	fs.BoolVar(&a.update, "update", false, "Self-update to latest version")
+	fs.StringVar(&a.baseURL, "base-url", "", "Custom API base URL")
+	fs.IntVar(&a.maxTurns, "max-turns", 0, "Maximum agent turns (0 = unlimited)")
+	fs.Float64Var(&a.maxBudget, "max-budget-usd", 0.0, "Maximum budget in USD (0 = unlimited)")
+	fs.StringVar(&a.outputFormat, "output-format", "text", "Output format: text, json, stream-json")
+	fs.StringVar(&a.inputFormat, "input-format", "", "Input format: empty = plain text, stream-json = JSONL from stdin")
+	fs.StringVar(&a.jsonSchema, "json-schema", "", "Path to JSON schema file for output validation")
+	fs.StringVar(&a.style, "style", "", "Output style: concise, verbose, formal, casual")
+	fs.StringVar(&a.prompt, "p", "", "Non-interactive mode: run prompt and exit")
+	fs.StringVar(&a.permissionMode, "permission-mode", "", "Permission mode: default, acceptEdits, plan, dontAsk, bypassPermissions")
+	fs.StringVar(&a.allowedTools, "allowedTools", "", "Comma-separated list of allowed tools")
+	fs.StringVar(&a.disallowedTools, "disallowedTools", "", "Comma-separated list of disallowed tools")
+	fs.StringVar(&a.permPromptTool, "permission-prompt-tool", "", "Print mode: command or mcp__ tool that answers permission requests (JSON in, allow/deny out)")
+	fs.BoolVar(&a.lean, "lean", false, "Use minimal system prompt (no memory, personality, context)")
+	fs.BoolVar(&a.dangerouslySkip, "dangerously-skip-permissions", false, "Skip all permission checks (alias for bypassPermissions)")
+	fs.BoolVar(&a.verbose, "v", false, "Enable verbose debug output")
+	fs.BoolVar(&a.verbose, "verbose", false, "Enable verbose debug output")
+	fs.StringVar(&a.logFile, "log-file", "", "Write structured JSON logs to this file instead of ~/.pi-go/logs/<session>.log")
+	fs.BoolVar(&a.dumpLLM, "dump-llm", false, "Write each provider HTTP request and response, credentials redacted, beside the session log")
+	fs.BoolVar(&a.noRedact, "no-redact", false, "Leave secrets in tool results and exports for this run (redaction settings)")
+	fs.BoolVar(&a.noWorktree, "no-worktree", false, "Disable session worktree isolation")
+	fs.BoolVar(&a.container, "container", false, "Run bash commands in a Docker or Podman container with the project mounted; file changes stay inside it")
+	fs.BoolVar(&a.devcontainer, "devcontainer", false, "Run bash commands in the project's devcontainer (.devcontainer/devcontainer.json)")
+	fs.BoolVar(&a.ideLink, "ide", false, "Accept active file/selection from an IDE extension (auto in VS Code)")
+	fs.BoolVar(&a.offline, "offline", false, "Offline mode: local model servers only; disable web tools, sharing, and self-update")
+	fs.BoolVar(&a.ascii, "ascii", false, "Draw the TUI with ASCII characters only (no box drawing or symbols)")
+	fs.StringVar(&a.record, "record", "", "Record every provider response to a cassette file")
+	fs.StringVar(&a.replay, "replay", "", "Answer provider calls from a cassette file instead of the API")
+	fs.Float64Var(&a.replaySpeed, "replay-speed", 1, "Replay timing scale: 1 = recorded pace, 0 = no delays")
+	fs.StringVar(&a.listen, "listen", serve.DefaultAddr, "Listen address for serve mode")
+	fs.StringVar(&a.serveToken, "serve-token", "", "Bearer token required by serve mode (default $PI_SERVE_TOKEN)")
+	fs.StringVar(&a.template, "template", "", "Batch template for the run subcommand (e.g., repo-health)")
+	fs.Var(&a.vars, "var", "Template variable KEY=value for the run subcommand (repeatable)")
+}
+
+// stringList is a repeatable string flag.
+type stringList []string
+
+// String implements flag.Value.
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+// Set implements flag.Value, appending each occurrence.
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
 // remaining returns the non-flag command-line arguments.
 func (a cliArgs) remaining() []string {
 	return flag.Args()
@@ -6,9 +6,16 @@ package main
 import (
 	"context"
 	"fmt"
+	"maps"
+	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
 
 	// termfix must be imported before any package that imports bubbletea.
 	// It sets lipgloss.SetHasDarkBackground(true) in its init(), preventing
@@ -16,26 +23,42 @@ import (
 	// async responses leak garbage into the editor.
 	_ "github.com/mauromedda/pi-coding-agent-go/internal/termfix"
 
+	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
+	"github.com/mauromedda/pi-coding-agent-go/internal/audit"
+	"github.com/mauromedda/pi-coding-agent-go/internal/commands"
 	"github.com/mauromedda/pi-coding-agent-go/internal/config"
+	"github.com/mauromedda/pi-coding-agent-go/internal/container"
+	"github.com/mauromedda/pi-coding-agent-go/internal/export"
+	"github.com/mauromedda/pi-coding-agent-go/internal/fetchcache"
 	"github.com/mauromedda/pi-coding-agent-go/internal/git"
+	"github.com/mauromedda/pi-coding-agent-go/internal/hooks"
+	"github.com/mauromedda/pi-coding-agent-go/internal/ide"
 	"github.com/mauromedda/pi-coding-agent-go/internal/intent"
 	pilog "github.com/mauromedda/pi-coding-agent-go/internal/log"
 	"github.com/mauromedda/pi-coding-agent-go/internal/memory"
 	"github.com/mauromedda/pi-coding-agent-go/internal/mode/interactive/btea"
 	"github.com/mauromedda/pi-coding-agent-go/internal/mode/print"
+	"github.com/mauromedda/pi-coding-agent-go/internal/mode/serve"
+	"github.com/mauromedda/pi-coding-agent-go/internal/offline"
 	"github.com/mauromedda/pi-coding-agent-go/internal/permission"
 	"github.com/mauromedda/pi-coding-agent-go/internal/personality"
 	"github.com/mauromedda/pi-coding-agent-go/internal/personality/checks"
 	"github.com/mauromedda/pi-coding-agent-go/internal/pkgmanager"
+	"github.com/mauromedda/pi-coding-agent-go/internal/plugin"
 	"github.com/mauromedda/pi-coding-agent-go/internal/prompt"
+	"github.com/mauromedda/pi-coding-agent-go/internal/reminder"
+	"github.com/mauromedda/pi-coding-agent-go/internal/session"
+	"github.com/mauromedda/pi-coding-agent-go/internal/shell"
 	"github.com/mauromedda/pi-coding-agent-go/internal/statusline"
 	"github.com/mauromedda/pi-coding-agent-go/internal/telemetry"
 	"github.com/mauromedda/pi-coding-agent-go/internal/tools"
 	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
 	"github.com/mauromedda/pi-coding-agent-go/pkg/ai/provider/anthropic"
 	"github.com/mauromedda/pi-coding-agent-go/pkg/ai/provider/google"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai/provider/mock"
 	"github.com/mauromedda/pi-coding-agent-go/pkg/ai/provider/openai"
 	"github.com/mauromedda/pi-coding-agent-go/pkg/ai/provider/vertex"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/termcap"
 	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/theme"
 )
 
@@ -46,7 +69,8 @@ var (
 )
 
 func main() {
-	// Intercept package subcommands before flag parsing.
+	// Intercept package and session subcommands before flag parsing.
+	serveMode, runMode := false, false
 	if len(os.Args) > 1 {
 		switch os.Args[1] {
 		case "install", "remove", "update", "list":
@@ -56,10 +80,88 @@ func main() {
 				os.Exit(1)
 			}
 			os.Exit(0)
+		case "package":
+			if err := pkgmanager.RunPackageCLI(os.Args[2:], os.Stdout); err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(1)
+			}
+			os.Exit(0)
+		case "serve":
+			// Strip the subcommand so the remaining flags parse normally.
+			serveMode = true
+			os.Args = append(os.Args[:1:1], os.Args[2:]...)
+		case "run":
+			runMode = true
+			os.Args = append(os.Args[:1:1], os.Args[2:]...)
+		case "sessions":
+			if err := session.RunCLI(os.Args[2:], config.SessionsDir()); err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(1)
+			}
+			os.Exit(0)
+		case "export":
+			if err := runExport(os.Args[2:], config.SessionsDir(), os.Stdout); err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(1)
+			}
+			os.Exit(0)
+		case "share":
+			if err := runShare(os.Args[2:], os.Stdout); err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(1)
+			}
+			os.Exit(0)
+		case "eval":
+			if err := runEval(os.Args[2:], os.Stdout, os.Stderr); err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(1)
+			}
+			os.Exit(0)
+		case "doctor":
+			if err := runDoctor(os.Args[2:], os.Stdout); err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(1)
+			}
+			os.Exit(0)
+		case "auth":
+			if err := runAuth(os.Args[2:], os.Stdin, os.Stdout); err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(1)
+			}
+			os.Exit(0)
+		case "setup":
+			var network *config.NetworkSettings
+			if cwd, err := os.Getwd(); err == nil {
+				if cfg, err := config.LoadAll(cwd, nil); err == nil {
+					network = cfg.Network
+				}
+			}
+			if err := runSetup(os.Stdin, os.Stdout, network); err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(1)
+			}
+			os.Exit(0)
+		case "completion":
+			if err := runCompletion(os.Args[2:], os.Stdout); err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(1)
+			}
+			os.Exit(0)
+		case "__complete":
+			// Called by the completion scripts; errors would only garble the shell.
+			_ = runComplete(os.Args[2:], os.Stdout)
+			os.Exit(0)
 		}
 	}
 
 	args := parseFlags()
+	args.serve = serveMode
+	args.run = runMode
+
+	if args.run && args.template == "" {
+		printBatchTemplates(os.Stdout)
+		os.Exit(0)
+	}
 
 	if args.version {
 		fmt.Printf("pi-go %s (%s) built %s\n", version, commit, date)
@@ -67,6 +169,10 @@ func main() {
 	}
 
 	if args.update {
+		if args.offline {
+			fmt.Fprintf(os.Stderr, "update failed: %v\n", offline.Unavailable("self-update", "rerun --update without --offline"))
+			os.Exit(1)
+		}
 		if err := runSelfUpdate(version); err != nil {
 			fmt.Fprintf(os.Stderr, "update failed: %v\n", err)
 			os.Exit(1)
@@ -76,7 +182,9 @@ func main() {
 
 	if err := run(args); err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		os.Exit(1)
+		// Print mode reports its outcome through a documented exit-code
+		// contract; every other failure maps to 1.
+		os.Exit(print.ExitCode(err))
 	}
 }
 
@@ -86,6 +194,16 @@ func run(args cliArgs) error {
 		pilog.SetLevel(pilog.LevelDebug)
 	}
 
+	// Structured logs: --log-file opens now; otherwise an interactive
+	// session opens ~/.pi-go/logs/<session>.log once its ID is known.
+	if args.logFile != "" {
+		closeLog, err := pilog.OpenFile(args.logFile)
+		if err != nil {
+			return fmt.Errorf("opening log file: %w", err)
+		}
+		defer closeLog()
+	}
+
 	cwd, err := os.Getwd()
 	if err != nil {
 		return fmt.Errorf("getting working directory: %w", err)
@@ -107,10 +225,34 @@ func run(args cliArgs) error {
 	if err != nil {
 		return fmt.Errorf("loading config: %w", err)
 	}
+	if legacy := config.DeprecatedManagedSettingsFile(); legacy != "" {
+		fmt.Fprintf(os.Stderr, "warning: managed settings in %s are deprecated; move them to %s\n", legacy, config.ManagedSettingsFile())
+	}
+
+	// First run on this machine: set up a provider rather than fail to find one.
+	if needsFirstRunSetup(args, cfg, auth) {
+		if err := runSetup(os.Stdin, os.Stdout, cfg.Network); err != nil {
+			return err
+		}
+		if auth, err = config.LoadAuth(); err != nil {
+			return fmt.Errorf("loading auth: %w", err)
+		}
+		config.MergePiAuth(auth, config.PiAgentDir())
+		if cfg, err = config.LoadAll(cwd, buildCLIOverrides(args)); err != nil {
+			return fmt.Errorf("loading config: %w", err)
+		}
+	}
+
+	// Clients without their own transport (web tools, MCP over HTTP, sharing)
+	// use the default one; route it through the configured proxy and CA bundle.
+	http.DefaultTransport = cfg.Network.ClientOptions().NewTransport()
+
+	// IDE extensions identify us by the directory the user opened, not the worktree.
+	workspace := cwd
 
 	// Set up session worktree if enabled (before theme/tools so cwd is correct).
 	var sessionWT *git.SessionWorktree
-	if cfg.Worktree.IsEnabled() && args.prompt == "" && !args.print {
+	if cfg.Worktree.IsEnabled() && args.prompt == "" && !args.print && !args.serve {
 		sw, err := git.SetupSessionWorktree(cwd)
 		if err != nil {
 			pilog.Debug("worktree: %v", err)
@@ -125,7 +267,7 @@ func run(args cliArgs) error {
 	}
 
 	// Resolve and activate theme from config
-	resolveTheme(cfg, cwd)
+	resolveTheme(cfg, cwd, args.prompt == "" && !args.print && !args.serve)
 
 	model, err := resolveModel(args, cfg)
 	if err != nil {
@@ -134,12 +276,35 @@ func run(args cliArgs) error {
 
 	baseURL := resolveBaseURL(args, cfg)
 
+	// Offline mode: refuse hosted endpoints up front rather than timing out mid-turn.
+	if cfg.Offline && model.Api != ai.ApiMock {
+		if err := offline.CheckModelURL(model.ID, baseURL); err != nil {
+			if args.print || args.prompt != "" {
+				return &print.ExitError{Code: print.ExitProviderError, Err: err}
+			}
+			return err
+		}
+	}
+
 	// W4: Register providers with auth keys
-	registerProvidersWithAuth(auth, baseURL)
+	keyPools := registerProvidersWithAuth(auth, cfg.Network, cfg.KeyPools, newWireDump(args))
+	registerMockProvider(cfg.Mock)
+
+	// Cassettes: --record tees every provider response to a file, --replay
+	// answers every call from one without contacting the API.
+	flushCassette, err := setupCassette(args)
+	if err != nil {
+		return err
+	}
+	defer flushCassette()
 
 	provider := ai.GetProvider(model.Api, baseURL)
 	if provider == nil {
-		return fmt.Errorf("no provider registered for API %q", model.Api)
+		err := fmt.Errorf("no provider registered for API %q", model.Api)
+		if args.print || args.prompt != "" {
+			return &print.ExitError{Code: print.ExitProviderError, Err: err}
+		}
+		return err
 	}
 
 	pathSandbox, err := permission.NewSandbox([]string{cwd})
@@ -147,23 +312,162 @@ func run(args cliArgs) error {
 		return fmt.Errorf("creating path sandbox: %w", err)
 	}
 
+	// The bash tool, ! commands, hooks, and the status line share one shell.
+	if err := shell.Configure(cfg.Terminal.EffectiveShell()); err != nil {
+		return fmt.Errorf("terminal settings: %w", err)
+	}
+
+	// File scans, including @-mention completion, prune the same directories.
+	// find answers from the project index, which is built on first use.
+	tools.SetExcludedDirs(cfg.ExcludeDirs)
+	// Protected files stay untouched in every permission mode.
+	tools.SetNeverModify(cfg.Safety.EffectiveNeverModify())
+	if cfg.Safety.DeniesSecretReads() {
+		tools.SetSecretFiles(cfg.Safety.EffectiveSecretFiles())
+	}
+	// Container mode: bash runs in a container and file changes stay in its
+	// mounts. Started before the registry so the bash tool describes it.
+	if cfg.Container.IsEnabled() {
+		c, err := startContainer(cfg.Container, cwd, sessionWT)
+		if err != nil {
+			return fmt.Errorf("container mode: %w", err)
+		}
+		defer c.Stop()
+		tools.SetContainer(c)
+		fmt.Fprintf(os.Stderr, "Running bash in %s\n", c)
+	}
+	tools.ProjectIndex(cwd)
+
 	// W1/W3: Registry with sandbox registers all builtins including web tools
 	toolRegistry := tools.NewRegistryWithSandbox(pathSandbox)
+	toolRegistry.SetLimits(func(name string) tools.Limits {
+		timeout, maxBytes := cfg.ToolLimits.For(name)
+		return tools.Limits{Timeout: timeout, MaxOutputBytes: maxBytes}
+	})
+	if args.verbose {
+		toolRegistry.Use(tools.LogCalls)
+	}
+	var auditLog *audit.Logger
+	if cfg.Audit.IsEnabled() {
+		if auditLog, err = audit.Open(cfg.Audit.EffectivePath(), cfg.Audit.EffectiveSyslog()); err != nil {
+			return fmt.Errorf("audit: %w", err)
+		}
+		defer auditLog.Close()
+		toolRegistry.Use(tools.AuditCalls(auditLog))
+	}
+	redactor, err := newRedactor(cfg.Redaction, args.noRedact)
+	if err != nil {
+		return err
+	}
+	if redactor != nil {
+		toolRegistry.Use(tools.RedactResults(redactor.Redact))
+	}
+	if cfg.Offline {
+		toolRegistry.DisableNetworkTools()
+	}
+	if names := cfg.Edit.EffectiveStrategies(); len(names) > 0 {
+		strategies, err := tools.ParseMatchStrategies(names)
+		if err != nil {
+			return fmt.Errorf("edit settings: %w", err)
+		}
+		toolRegistry.SetEditStrategies(strategies)
+	}
+
+	// Checkpoints: file-writing tools snapshot their target first so /undo
+	// can restore what a turn changed. Only interactive turns record them,
+	// each pane into its own checkpoints; these are the first pane's.
+	checkpoints := ide.NewTurnCheckpoints()
+	toolRegistry.EnableCheckpoints()
+
+	// Fetch cache: webfetch results by URL; docs pages persist across sessions.
+	fetchCache := buildFetchCache(cfg.FetchCache)
+	tools.SetFetchCache(fetchCache)
+
+	// Skills: SKILL.md bundles are exposed via the skill tool; always-on,
+	// glob-matched, and prompt-triggered ones are preloaded into the system prompt.
+	var skills, preloadedSkills []prompt.Skill
+	if !args.lean {
+		skills, _ = prompt.LoadSkills(cwd)
+		if len(skills) > 0 {
+			toolRegistry.Register(tools.NewSkillTool(skills))
+			initialPrompt := args.prompt + " " + strings.Join(args.remaining(), " ")
+			preloadedSkills = prompt.SelectSkills(skills, initialPrompt, skillScanFiles(skills, cwd))
+		}
+	}
+
+	// Plugins: subprocesses adding tools, slash commands, and event hooks.
+	// Started before pipelines and sub-agents so their steps and tool sets
+	// include plugin tools and pass through plugin hooks.
+	var pluginCommands []*commands.Command
+	if !args.lean {
+		var pluginHost *plugin.Host
+		pluginHost, pluginCommands = startPlugins(cfg, cwd, toolRegistry)
+		if pluginHost != nil {
+			pluginHost.Emit(hooks.SessionStart)
+			defer func() {
+				pluginHost.Emit(hooks.SessionEnd)
+				pluginHost.Close()
+			}()
+		}
+	}
+
+	// Pipelines: composite tools from settings. Registered before
+	// --disallowedTools so they can be disallowed too; a step whose tool was
+	// removed fails when it runs.
+	pipelineTools, err := buildPipelineTools(cfg.Pipelines, toolRegistry.Get)
+	if err != nil {
+		return fmt.Errorf("pipelines: %w", err)
+	}
+	for _, t := range pipelineTools {
+		toolRegistry.Register(t)
+	}
 
 	// Apply --disallowedTools: remove tools before creating checker
+	taskDisallowed, fanOutDisallowed := false, false
 	if args.disallowedTools != "" {
 		for spec := range strings.SplitSeq(args.disallowedTools, ",") {
 			spec = strings.TrimSpace(spec)
 			if spec != "" {
 				toolRegistry.Remove(spec)
+				taskDisallowed = taskDisallowed || spec == "task"
+				fanOutDisallowed = fanOutDisallowed || spec == "minion_fanout"
 			}
 		}
 	}
 
+	// Sub-agents: the task tool delegates to named definitions (.pi/agents/*.md
+	// and friends). They get the remaining tools minus task itself, so
+	// delegation cannot recurse.
+	var agentDefs []agent.Definition
+	if !args.lean && !taskDisallowed {
+		defs, _ := agent.LoadDefinitions(cwd, home)
+		for _, name := range slices.Sorted(maps.Keys(defs)) {
+			agentDefs = append(agentDefs, defs[name])
+		}
+		toolRegistry.Register(tools.NewTaskTool(agent.SpawnDeps{
+			Provider:     provider,
+			Model:        model,
+			AllTools:     toolRegistry.All(),
+			ResolveModel: subagentModelResolver(model, provider, baseURL, cfg.Offline),
+		}, defs))
+	}
+
+	// Minion: a cheaper model for simple turns; in plural mode the main model
+	// can also fan per-file edits out to parallel minions.
+	minion := buildMinion(cfg.Minion, model, provider, baseURL, cfg.Offline)
+	if minion != nil && !fanOutDisallowed {
+		toolRegistry.Register(tools.NewMinionFanOutTool(minion, toolRegistry.All()))
+	}
+
 	// W7: Create checker from settings with glob rules using effective permissions
 	permMode := resolvePermissionMode(args, cfg)
 	allow, deny, ask := cfg.EffectivePermissions()
 	checker := permission.NewCheckerFromSettings(permMode, nil, allow, deny, ask)
+	if auditLog != nil {
+		checker.SetDecisionFn(func(d permission.Decision) {
+			auditLog.Permission(d.Tool, d.Args, d.Allowed, string(d.Source), d.Reason)
+		})
+	}
 
 	// Apply --allowedTools: add as glob allow rules
 	if args.allowedTools != "" {
@@ -183,10 +487,11 @@ func run(args cliArgs) error {
 
 	var memSection string
 	var personalityPrompt string
+	var personalityEngine *personality.Engine
 
 	if !args.lean {
 		// W2: Load memory hierarchy and format for system prompt
-		memEntries, _ := memory.Load(cwd, home)
+		memEntries, _ := memory.Load(cwd, home, tools.IsSecretFile)
 		memSection = memory.FormatForPrompt(memEntries, nil)
 
 		// Initialize telemetry tracker
@@ -202,10 +507,24 @@ func run(args cliArgs) error {
 		}
 		_ = tracker // Will be wired into agent loop in a future phase
 
-		// Initialize personality engine
-		if cfg.Personality != nil {
-			engine, err := personality.NewEngine("")
-			if err == nil {
+		// Initialize personality engine; always present for output styles,
+		// profile/check composition only when configured.
+		if engine, err := personality.NewEngine(""); err == nil {
+			personalityEngine = engine
+			for _, dir := range config.OutputStylesDirs(cwd) {
+				styles, err := personality.LoadOutputStyles(dir)
+				if err != nil {
+					pilog.Debug("output styles: %v", err)
+					continue
+				}
+				engine.AddOutputStyles(styles)
+			}
+			if cfg.OutputStyle != "" {
+				if err := engine.SetOutputStyle(cfg.OutputStyle); err != nil {
+					pilog.Debug("output style: %v; using default", err)
+				}
+			}
+			if cfg.Personality != nil {
 				if err := engine.SetProfile(cfg.Personality.EffectiveProfile()); err != nil {
 					pilog.Debug("personality: profile %q not found, using base", cfg.Personality.EffectiveProfile())
 				}
@@ -237,10 +556,74 @@ func run(args cliArgs) error {
 		sysOpts.ContextFiles = prompt.LoadContextFiles(cwd)
 		sysOpts.Style = args.style
 		sysOpts.PersonalityPrompt = personalityPrompt
+		if personalityEngine != nil {
+			sysOpts.OutputStylePrompt = personalityEngine.ComposeOutputStyle()
+		}
 		sysOpts.PromptVersion = promptVersion(cfg)
+		sysOpts.Adapter = prompt.ResolveAdapter(cfg.Prompts, model)
+		sysOpts.Model = model
+		sysOpts.Skills = prompt.SkillRefs(preloadedSkills)
 	}
 	systemPrompt := prompt.BuildSystem(sysOpts)
 
+	// --permission-prompt-tool: unattended runs ask an external policy
+	// instead of failing on the first tool call that needs approval.
+	if args.permPromptTool != "" {
+		if !args.print && args.prompt == "" && !args.run {
+			return fmt.Errorf("--permission-prompt-tool applies to print mode (--print, -p, run)")
+		}
+		ask, err := permission.NewPromptTool(args.permPromptTool, toolRegistry.Get)
+		if err != nil {
+			return fmt.Errorf("--permission-prompt-tool: %w", err)
+		}
+		checker.SetAskFn(ask)
+	}
+
+	// Headless HTTP server: drives the same agent core over REST/SSE.
+	if args.serve {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		token := args.serveToken
+		if token == "" {
+			token = os.Getenv("PI_SERVE_TOKEN")
+		}
+		if token == "" {
+			if token, err = serve.GenerateToken(); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "pi-go serve: no --serve-token or PI_SERVE_TOKEN given; clients must send\n  Authorization: Bearer %s\n", token)
+		}
+		return serve.Run(ctx, serve.Config{
+			Addr:  args.listen,
+			Token: token,
+		}, serve.Deps{
+			Provider:     provider,
+			Model:        model,
+			Tools:        toolRegistry.All(),
+			Checker:      checker,
+			SystemPrompt: systemPrompt,
+		})
+	}
+
+	// run --template: unattended batch run of a built-in prompt template
+	if args.run {
+		promptText, err := batchPrompt(args, cwd)
+		if err != nil {
+			return err
+		}
+		return print.RunWithConfig(context.Background(), print.Config{
+			OutputFormat: args.outputFormat,
+			MaxTurns:     args.maxTurns,
+			MaxBudgetUSD: args.maxBudget,
+			SystemPrompt: systemPrompt,
+		}, print.Deps{
+			Provider:  provider,
+			Model:     model,
+			Tools:     toolRegistry.All(),
+			PermCheck: checker.Check,
+		}, promptText)
+	}
+
 	// -p "prompt" shorthand: non-interactive mode with inline prompt
 	if args.prompt != "" {
 		return print.RunWithConfig(context.Background(), print.Config{
@@ -251,9 +634,10 @@ func run(args cliArgs) error {
 			InputFormat:  args.inputFormat,
 			JSONSchema:   args.jsonSchema,
 		}, print.Deps{
-			Provider: provider,
-			Model:    model,
-			Tools:    toolRegistry.All(),
+			Provider:  provider,
+			Model:     model,
+			Tools:     toolRegistry.All(),
+			PermCheck: checker.Check,
 		}, args.prompt)
 	}
 
@@ -273,31 +657,161 @@ func run(args cliArgs) error {
 			InputFormat:  args.inputFormat,
 			JSONSchema:   args.jsonSchema,
 		}, print.Deps{
-			Provider: provider,
-			Model:    model,
-			Tools:    toolRegistry.All(),
+			Provider:  provider,
+			Model:     model,
+			Tools:     toolRegistry.All(),
+			PermCheck: checker.Check,
 		}, promptText)
 	}
 
 	// Build optional status line engine from config
 	var statusEngine *statusline.Engine
 	if cfg.StatusLine != nil && cfg.StatusLine.Command != "" {
-		statusEngine = statusline.New(cfg.StatusLine.Command, cfg.StatusLine.Padding)
+		statusEngine = statusline.New(cfg.StatusLine.Command, cfg.StatusLine.Padding).
+			WithRefresh(time.Duration(cfg.StatusLine.RefreshInterval) * time.Second)
+	}
+
+	// Live IDE link: explicit --ide, or automatic inside VS Code's terminal
+	var ideLink *ide.Link
+	if args.ideLink || ide.Detect() == ide.IDEVSCode {
+		l, err := ide.ListenLink(config.IDEDir(), workspace)
+		if err != nil {
+			pilog.Debug("ide link: %v", err)
+		} else {
+			ideLink = l
+			defer l.Close()
+		}
+	}
+
+	// /output-style switches the engine, saves the choice for this project,
+	// and rebuilds the system prompt with the new style section.
+	var onOutputStyle func(string) (string, error)
+	if personalityEngine != nil {
+		onOutputStyle = func(name string) (string, error) {
+			if err := config.SaveLocalSetting(workspace, "outputStyle", name); err != nil {
+				return "", fmt.Errorf("saving output style: %w", err)
+			}
+			opts := sysOpts
+			opts.OutputStylePrompt = personalityEngine.ComposeOutputStyle()
+			return prompt.BuildSystem(opts), nil
+		}
+	}
+
+	// /memory and the '#' shortcut edit memory files, then reload them into
+	// the system prompt so the next turn sees the change.
+	var memoryAccess *btea.MemoryAccess
+	if !args.lean {
+		memoryAccess = &btea.MemoryAccess{
+			ProjectDir: cwd,
+			HomeDir:    home,
+			Reload: func() (string, string, error) {
+				entries, err := memory.Load(cwd, home, tools.IsSecretFile)
+				if err != nil {
+					return "", "", fmt.Errorf("loading memory: %w", err)
+				}
+				sysOpts.MemorySection = memory.FormatForPrompt(entries, nil)
+				opts := sysOpts
+				if personalityEngine != nil {
+					opts.OutputStylePrompt = personalityEngine.ComposeOutputStyle()
+				}
+				return prompt.BuildSystem(opts), sysOpts.MemorySection, nil
+			},
+		}
+	}
+
+	// /reload and edits to the settings files re-read every level, with the
+	// same command-line overrides on top.
+	reloadSettings := func() (*config.Settings, error) {
+		return config.LoadAll(workspace, buildCLIOverrides(args))
+	}
+
+	// /permissions <level> and first-run onboarding write a starter block to
+	// project settings, then reload so user-level rules still apply. A block
+	// the managed settings lock is refused before it is written, since the
+	// reload, and every later start, would fail on it.
+	applyAutonomy := func(level string) (*config.PermissionsConfig, error) {
+		p, err := config.StarterPermissions(level)
+		if err != nil {
+			return nil, err
+		}
+		if err := config.CheckManagedLocks(config.ProjectSettingsFile(workspace), &config.Settings{Permissions: p}); err != nil {
+			return nil, err
+		}
+		if err := config.SaveProjectSetting(workspace, "permissions", p); err != nil {
+			return nil, fmt.Errorf("saving permissions: %w", err)
+		}
+		fresh, err := reloadSettings()
+		if err != nil {
+			return nil, fmt.Errorf("reloading settings: %w", err)
+		}
+		checker.SetGlobRules(fresh.EffectivePermissions())
+		if mode, err := permission.ParseMode(fresh.EffectiveDefaultMode()); err == nil {
+			checker.SetMode(mode)
+		}
+		return fresh.Permissions, nil
+	}
+	onboardPermissions := !permissionModeFromFlags(args) && !cfg.Yolo && config.NeedsPermissionOnboarding(workspace)
+	if _, err := git.RepoRoot(workspace); err != nil {
+		onboardPermissions = false
 	}
 
 	// Interactive mode (default)
-	return runInteractive(model, checker, provider, toolRegistry, systemPrompt, statusEngine, cfg.AutoCompactThreshold, sessionWT)
+	return runInteractive(btea.AppDeps{
+		Provider:             provider,
+		Model:                model,
+		Tools:                toolRegistry.All(),
+		Checker:              checker,
+		SystemPrompt:         systemPrompt,
+		MemoryPrompt:         memSection,
+		StatusEngine:         statusEngine,
+		WorktreeSession:      sessionWT,
+		IDELink:              ideLink,
+		Redactor:             redactor,
+		Skills:               prompt.NewSkillActivator(skills, preloadedSkills),
+		Agents:               agentDefs,
+		Personality:          personalityEngine,
+		OnOutputStyleChange:  onOutputStyle,
+		Minion:               minion,
+		FetchCache:           fetchCache,
+		ReloadSettings:       reloadSettings,
+		SettingsFiles:        config.SettingsFiles(workspace),
+		ApplyAutonomy:        applyAutonomy,
+		PermissionOnboarding: onboardPermissions,
+		Memory:               memoryAccess,
+		KeyPools:             keyPools,
+		Checkpoints:          checkpoints,
+		PluginCommands:       pluginCommands,
+	}, cfg, auditLog)
 }
 
-// registerProvidersWithAuth registers providers with auth keys from the store.
-func registerProvidersWithAuth(auth *config.AuthStore, _ string) {
-	if key := auth.GetKey("anthropic"); key != "" {
+// registerProvidersWithAuth registers providers with auth keys from the store
+// and HTTP transport options resolved per provider and base URL. A provider
+// with a key pool rotates between the pooled keys instead; the pools are
+// returned for usage reporting.
+func registerProvidersWithAuth(auth *config.AuthStore, network *config.NetworkSettings, pools map[string][]config.PooledKey, dump *ai.WireDump) []*ai.KeyPool {
+	var registered []*ai.KeyPool
+	httpOptions := func(api ai.Api, baseURL string) ai.HTTPOptions {
+		opts := network.HTTPOptionsFor(string(api), baseURL)
+		opts.Dump = dump
+		return opts
+	}
+
+	newAnthropic := func(key, baseURL string) ai.ApiProvider {
+		return anthropic.NewWithOptions(key, baseURL, httpOptions(ai.ApiAnthropic, baseURL))
+	}
+	if pool := buildKeyPool(auth, pools["anthropic"], ai.ApiAnthropic, newAnthropic); pool != nil {
+		ai.RegisterProvider(ai.ApiAnthropic, pool.Provider)
+		registered = append(registered, pool)
+	} else if key := auth.GetKey("anthropic"); key != "" {
 		ai.RegisterProvider(ai.ApiAnthropic, func(baseURL string) ai.ApiProvider {
-			return anthropic.New(key, baseURL)
+			return newAnthropic(key, baseURL)
 		})
 	}
 
 	// OpenAI-compatible: also check vllm and ollama keys
+	newOpenAI := func(key, baseURL string) ai.ApiProvider {
+		return openai.NewWithOptions(key, baseURL, httpOptions(ai.ApiOpenAI, baseURL))
+	}
 	openaiKey := auth.GetKey("openai")
 	if openaiKey == "" {
 		openaiKey = auth.GetKey("vllm")
@@ -305,22 +819,143 @@ func registerProvidersWithAuth(auth *config.AuthStore, _ string) {
 	if openaiKey == "" {
 		openaiKey = auth.GetKey("ollama")
 	}
-	if openaiKey != "" {
+	if pool := buildKeyPool(auth, pools["openai"], ai.ApiOpenAI, newOpenAI); pool != nil {
+		ai.RegisterProvider(ai.ApiOpenAI, pool.Provider)
+		registered = append(registered, pool)
+	} else if openaiKey != "" {
 		ai.RegisterProvider(ai.ApiOpenAI, func(baseURL string) ai.ApiProvider {
-			return openai.New(openaiKey, baseURL)
+			return newOpenAI(openaiKey, baseURL)
 		})
 	}
 
-	if key := auth.GetKey("google"); key != "" {
+	newGoogle := func(key, baseURL string) ai.ApiProvider {
+		return google.NewWithOptions(key, baseURL, httpOptions(ai.ApiGoogle, baseURL))
+	}
+	if pool := buildKeyPool(auth, pools["google"], ai.ApiGoogle, newGoogle); pool != nil {
+		ai.RegisterProvider(ai.ApiGoogle, pool.Provider)
+		registered = append(registered, pool)
+	} else if key := auth.GetKey("google"); key != "" {
 		ai.RegisterProvider(ai.ApiGoogle, func(baseURL string) ai.ApiProvider {
-			return google.New(key, baseURL)
+			return newGoogle(key, baseURL)
 		})
 	}
 
 	// Vertex uses env-based auth (VERTEX_PROJECT_ID, VERTEX_API_KEY); always register.
 	ai.RegisterProvider(ai.ApiVertex, func(baseURL string) ai.ApiProvider {
-		return vertex.New("", "", baseURL)
+		return vertex.NewWithOptions("", "", baseURL, httpOptions(ai.ApiVertex, baseURL))
 	})
+	return registered
+}
+
+// newRedactor returns the redactor for tool results and exports, or nil
+// when redaction is off in settings or for this run.
+func newRedactor(rs *config.RedactionSettings, off bool) (*export.Redactor, error) {
+	if off || !rs.IsEnabled() {
+		return nil, nil
+	}
+	r, err := export.NewRedactor(rs.EffectivePatterns())
+	if err != nil {
+		return nil, fmt.Errorf("redaction settings: %w", err)
+	}
+	return r, nil
+}
+
+// newWireDump returns the --dump-llm dumper, or nil. Dumps go beside the
+// session log, in <log>.llm/; without a log, in a timestamped directory
+// under the logs directory.
+func newWireDump(args cliArgs) *ai.WireDump {
+	if !args.dumpLLM {
+		return nil
+	}
+	fallback := filepath.Join(config.LogsDir(), time.Now().Format("20060102-150405")+".llm")
+	return &ai.WireDump{
+		Dir: func() string {
+			if p := pilog.Path(); p != "" {
+				return strings.TrimSuffix(p, filepath.Ext(p)) + ".llm"
+			}
+			return fallback
+		},
+		Redact: export.RedactSecrets,
+	}
+}
+
+// registerMockProvider registers the mock provider, which needs no key, so
+// the TUI can be developed with --model mock. A script that fails to load
+// leaves it echoing.
+func registerMockProvider(ms *config.MockSettings) {
+	var opts mock.Options
+	if ms != nil {
+		opts = mock.Options{
+			Latency:      time.Duration(ms.LatencyMs) * time.Millisecond,
+			ChunkDelay:   time.Duration(ms.ChunkDelayMs) * time.Millisecond,
+			InputTokens:  ms.InputTokens,
+			OutputTokens: ms.OutputTokens,
+		}
+		if ms.Script != "" {
+			script, err := mock.LoadScript(ms.Script)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+			}
+			opts.Script = script
+		}
+	}
+	ai.RegisterProvider(ai.ApiMock, func(string) ai.ApiProvider { return mock.New(opts) })
+}
+
+// setupCassette wraps every registered provider for --record, or replaces
+// them all for --replay.
+// The returned function writes what was recorded.
+func setupCassette(args cliArgs) (func(), error) {
+	switch {
+	case args.record != "" && args.replay != "":
+		return nil, fmt.Errorf("--record and --replay are mutually exclusive")
+	case args.record != "":
+		rec := ai.NewRecorder(args.record)
+		ai.WrapProviders(rec.Wrap)
+		return func() {
+			if err := rec.Flush(); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+			}
+		}, nil
+	case args.replay != "":
+		c, err := ai.LoadCassette(args.replay)
+		if err != nil {
+			return nil, err
+		}
+		// Every API answers from the cassette, so a replay needs no keys.
+		rep := ai.NewReplayer(c, ai.ReplayOptions{Speed: args.replaySpeed})
+		for _, api := range []ai.Api{ai.ApiAnthropic, ai.ApiOpenAI, ai.ApiGoogle, ai.ApiVertex, ai.ApiMock} {
+			ai.RegisterProvider(api, func(string) ai.ApiProvider { return rep.Provider(api) })
+		}
+		return func() {
+			if n := rep.Remaining(); n > 0 {
+				pilog.Debug("cassette: %d recorded calls not replayed", n)
+			}
+		}, nil
+	}
+	return func() {}, nil
+}
+
+// buildKeyPool resolves the configured keys of one provider's pool. It
+// returns nil when no key resolves; unresolved keys are skipped.
+func buildKeyPool(auth *config.AuthStore, keys []config.PooledKey, api ai.Api, newProvider func(key, baseURL string) ai.ApiProvider) *ai.KeyPool {
+	var resolved []ai.PoolKey
+	for i, k := range keys {
+		key := auth.ResolveKey(k.Key)
+		if key == "" {
+			pilog.Debug("key pool %s: key %d (%s) did not resolve; skipping", api, i+1, k.Name)
+			continue
+		}
+		name := k.Name
+		if name == "" {
+			name = fmt.Sprintf("key-%d", i+1)
+		}
+		resolved = append(resolved, ai.PoolKey{Name: name, Key: key, MaxTokens: k.MaxTokens})
+	}
+	if len(resolved) == 0 {
+		return nil
+	}
+	return ai.NewKeyPool(api, resolved, newProvider)
 }
 
 // buildCLIOverrides maps CLI flags to a Settings struct for LoadAll.
@@ -338,20 +973,75 @@ func buildCLIOverrides(args cliArgs) *config.Settings {
 	if args.yolo {
 		s.Yolo = true
 	}
+	if args.offline {
+		s.Offline = true
+	}
+	if args.ascii {
+		s.ASCII = true
+	}
 	if args.noWorktree {
 		f := false
 		s.Worktree = &config.WorktreeSettings{Enabled: &f}
 	}
+	if args.container || args.devcontainer {
+		t := true
+		s.Container = &config.ContainerSettings{Enabled: &t}
+		if args.devcontainer {
+			s.Container.Devcontainer = &t
+		}
+	}
 	return s
 }
 
+// startContainer starts the container-mode container for the project at
+// root, or its devcontainer when the settings say so. A session worktree's
+// commits land in the main repository's .git, so that is mounted too.
+func startContainer(s *config.ContainerSettings, root string, sessionWT *git.SessionWorktree) (*container.Container, error) {
+	cfg := container.Config{
+		Runtime:   s.Runtime,
+		Image:     s.Image,
+		Network:   s.Network,
+		CPUs:      s.CPUs,
+		Memory:    s.Memory,
+		PidsLimit: s.PidsLimit,
+		Mounts:    s.Mounts,
+		Shell:     s.Shell,
+	}
+	if sessionWT != nil {
+		cfg.Mounts = append(slices.Clone(cfg.Mounts), filepath.Join(sessionWT.RepoRoot, ".git"))
+	}
+	// Pulling or building an image can take a while.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Minute)
+	defer cancel()
+	if s.UsesDevcontainer() {
+		path := container.FindDevcontainer(root)
+		if path == "" {
+			return nil, fmt.Errorf("no .devcontainer/devcontainer.json or .devcontainer.json in %s", root)
+		}
+		dc, err := container.LoadDevcontainer(path)
+		if err != nil {
+			return nil, err
+		}
+		return container.StartDevcontainer(ctx, cfg, dc, root)
+	}
+	return container.Start(ctx, cfg, root)
+}
+
 // resolveModel determines the model from CLI flag, config, or default.
 func resolveModel(args cliArgs, cfg *config.Settings) (*ai.Model, error) {
 	modelID := args.model
 	if modelID == "" {
 		modelID = cfg.Model
 	}
-	return config.ResolveModel(modelID)
+	m, err := config.ResolveModel(modelID)
+	if err != nil {
+		return nil, err
+	}
+	// Built-in definitions are shared; overrides apply to a copy.
+	model := *m
+	model.CustomHeaders = maps.Clone(m.CustomHeaders)
+	config.ApplyModelOverrides(&model, cfg)
+	return &model, nil
 }
 
 // resolveBaseURL picks the API base URL from CLI flag or config.
@@ -362,6 +1052,12 @@ func resolveBaseURL(args cliArgs, cfg *config.Settings) string {
 	return cfg.BaseURL
 }
 
+// permissionModeFromFlags reports whether the command line already chose a
+// permission mode or rules, in which case first-run onboarding is skipped.
+func permissionModeFromFlags(args cliArgs) bool {
+	return args.dangerouslySkip || args.permissionMode != "" || args.yolo || args.plan || args.allowedTools != ""
+}
+
 // resolvePermissionMode maps CLI flags and config to a permission.Mode.
 // Priority: --dangerously-skip-permissions > --permission-mode > --yolo/--plan > config > normal.
 func resolvePermissionMode(args cliArgs, cfg *config.Settings) permission.Mode {
@@ -387,22 +1083,245 @@ func resolvePermissionMode(args cliArgs, cfg *config.Settings) permission.Mode {
 	return permission.ModeNormal
 }
 
-// runInteractive starts the Bubble Tea interactive TUI.
-func runInteractive(model *ai.Model, checker *permission.Checker, provider ai.ApiProvider, toolReg *tools.Registry, systemPrompt string, statusEngine *statusline.Engine, autoCompactThreshold int, sessionWT *git.SessionWorktree) error {
-	return btea.Run(btea.AppDeps{
-		Provider:             provider,
-		Model:                model,
-		Tools:                toolReg.All(),
-		Checker:              checker,
-		SystemPrompt:         systemPrompt,
-		Version:              version,
-		StatusEngine:         statusEngine,
-		AutoCompactThreshold: autoCompactThreshold,
-		PermissionMode:       checker.Mode(),
-		WorktreeSession:      sessionWT,
+// runInteractive starts the Bubble Tea interactive TUI. deps carries what
+// main built; runInteractive adds the settings-derived fields from cfg and
+// the session, devcontainer, and reminders that belong to this run.
+func runInteractive(deps btea.AppDeps, cfg *config.Settings, auditLog *audit.Logger) error {
+	model, provider, sessionWT := deps.Model, deps.Provider, deps.WorktreeSession
+	keys, err := btea.ParseKeyMap(cfg.Keybindings)
+	if err != nil {
+		return fmt.Errorf("keybindings: %w", err)
+	}
+	reminders := reminder.New()
+	reminders.TrackFiles()
+	var budgetUSD float64
+	if cfg.Telemetry != nil {
+		budgetUSD = cfg.Telemetry.BudgetUSD
+	}
+
+	// The conversation is saved so /fork and /tree can branch it. A turn a
+	// crashed run left behind in this directory is offered for recovery
+	// before the new session's journal exists.
+	cwd, _ := os.Getwd()
+	interrupted, _ := session.FindInterrupted(config.SessionsDir(), cwd)
+	sess, err := session.Start(model, provider, cwd)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: session will not be saved: %v\n", err)
+		sess, interrupted = nil, nil
+	} else if sess.Journal, err = session.OpenJournal(config.SessionsDir(), sess.ID); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: turns will not be journaled for crash recovery: %v\n", err)
+	}
+	if sess != nil && pilog.Path() == "" {
+		pilog.PruneDir(config.LogsDir(), pilog.DefaultMaxAge)
+		closeLog, err := pilog.OpenFile(filepath.Join(config.LogsDir(), sess.ID+".log"))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: logs will not be saved: %v\n", err)
+		} else {
+			defer closeLog()
+		}
+	}
+	// A project with a devcontainer is offered it unless settings decide.
+	var devcontainer string
+	var startDevcontainer func() (string, error)
+	var devcontainerStarted atomic.Pointer[container.Container]
+	if cfg.Container.OffersDevcontainer() {
+		if path := container.FindDevcontainer(cwd); path != "" {
+			if dc, err := container.LoadDevcontainer(path); err != nil {
+				pilog.Debug("devcontainer: %v", err)
+			} else {
+				devcontainer = dc.Describe()
+				startDevcontainer = func() (string, error) {
+					use, t := config.ContainerSettings{}, true
+					if cfg.Container != nil {
+						use = *cfg.Container
+					}
+					use.Devcontainer = &t
+					c, err := startContainer(&use, cwd, sessionWT)
+					if err != nil {
+						return "", err
+					}
+					devcontainerStarted.Store(c)
+					tools.SetContainer(c)
+					return c.String(), nil
+				}
+				defer func() {
+					if c := devcontainerStarted.Load(); c != nil {
+						c.Stop()
+					}
+				}()
+			}
+		}
+	}
+	if sess != nil {
+		auditLog.SetSession(sess.ID)
+		pilog.With("session", sess.ID).Debug("session start: model=%s cwd=%s version=%s", model.ID, cwd, version)
+	}
+
+	deps.Version = version
+	deps.AutoCompactThreshold = cfg.AutoCompactThreshold
+	deps.PermissionMode = deps.Checker.Mode()
+	deps.Session = sess
+	deps.Display = cfg.Display
+	deps.ASCII = cfg.ASCII
+	deps.Terminal = cfg.Terminal
+	deps.Keys = keys
+	deps.Suspend = cfg.Suspend
+	deps.Notify = cfg.Notify
+	deps.ContextEviction = cfg.ContextEviction
+	deps.Prewarm = cfg.Prewarm
+	deps.ThinkingRetention = cfg.ThinkingRetention
+	deps.ThemeDirs = config.ThemesDirs(cwd)
+	deps.OnThemeChange = saveTheme
+	deps.ThinkingLevels = cfg.ThinkingLevels
+	deps.OnThinkingChange = saveThinkingLevel
+	deps.Offline = cfg.Offline
+	deps.Hooks = cfg.Hooks
+	deps.Settings = cfg
+	deps.Permissions = cfg.Permissions
+	deps.Reminders = reminders
+	deps.BudgetUSD = budgetUSD
+	deps.BudgetWarnPct = cfg.Telemetry.EffectiveWarnAtPct()
+	deps.Share = cfg.Share
+	deps.Interrupted = interrupted
+	deps.Devcontainer = devcontainer
+	deps.StartDevcontainer = startDevcontainer
+	err = btea.Run(deps)
+	if sess != nil {
+		if err != nil {
+			// Keep the journal: the TUI died and its turn may be recoverable.
+			_ = sess.Writer.Close()
+		} else {
+			_ = sess.Close()
+		}
+	}
+	return err
+}
+
+// buildFetchCache creates the webfetch cache, or returns nil when disabled.
+func buildFetchCache(fs *config.FetchCacheSettings) *fetchcache.Store {
+	if !fs.IsEnabled() {
+		return nil
+	}
+	var domains []string
+	if fs != nil {
+		domains = fs.DocsDomains
+	}
+	return fetchcache.New(fetchcache.Options{
+		Dir:         config.FetchCacheDir(),
+		TTL:         fs.EffectiveTTL(),
+		DocsTTL:     fs.EffectiveDocsTTL(),
+		DocsDomains: domains,
 	})
 }
 
+// buildPipelineTools creates the composite tools declared in settings.
+func buildPipelineTools(defs map[string]config.PipelineDef, lookup func(string) *agent.AgentTool) ([]*agent.AgentTool, error) {
+	names := slices.Sorted(maps.Keys(defs))
+	out := make([]*agent.AgentTool, 0, len(defs))
+	for _, name := range names {
+		def := defs[name]
+		p := tools.Pipeline{Name: name, Description: def.Description}
+		for _, pname := range slices.Sorted(maps.Keys(def.Parameters)) {
+			pd := def.Parameters[pname]
+			p.Params = append(p.Params, tools.PipelineParam{
+				Name: pname, Type: pd.Type, Description: pd.Description, Required: pd.Required,
+			})
+		}
+		for _, sd := range def.Steps {
+			p.Steps = append(p.Steps, tools.PipelineStep{
+				Name: sd.Name, Tool: sd.Tool, Args: sd.Args,
+				ForEach: sd.ForEach, Match: sd.Match, ContinueOnError: sd.ContinueOnError,
+			})
+		}
+		t, err := tools.NewPipelineTool(p, names, lookup)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+// buildMinion resolves the configured minion model. It returns nil when no
+// minion is configured, it cannot be resolved, or it is the main model.
+func buildMinion(ms *config.MinionSettings, main *ai.Model, mainProvider ai.ApiProvider, baseURL string, offlineMode bool) *agent.Minion {
+	if ms == nil || ms.Model == "" {
+		return nil
+	}
+	m, p, err := subagentModelResolver(main, mainProvider, baseURL, offlineMode)(ms.Model)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: minion disabled: %v\n", err)
+		return nil
+	}
+	if m.ID == main.ID {
+		return nil
+	}
+	mode, err := agent.ParseMinionMode(ms.EffectiveMode())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: minion: %v; using auto\n", err)
+		mode = agent.MinionAuto
+	}
+	minion := agent.NewMinion(p, m, mode)
+	minion.MaxInputTokens = ms.MaxInputTokens
+	minion.Parallel = ms.Parallel
+	return minion
+}
+
+// subagentModelResolver resolves an agent definition's model field. Tiers
+// (fast/powerful/default) fall back to the parent model when their mapped
+// model or its provider is unavailable (or remote while offline); explicit
+// model IDs must resolve.
+func subagentModelResolver(parent *ai.Model, parentProvider ai.ApiProvider, baseURL string, offlineMode bool) func(string) (*ai.Model, ai.ApiProvider, error) {
+	return func(name string) (*ai.Model, ai.ApiProvider, error) {
+		if name == "default" {
+			return parent, parentProvider, nil
+		}
+		m, err := config.ResolveModel(agent.ResolveAgentModel(name))
+		if err != nil {
+			if agent.IsModelTier(name) {
+				return parent, parentProvider, nil
+			}
+			return nil, nil, err
+		}
+		if m.ID == parent.ID {
+			return parent, parentProvider, nil
+		}
+		url := ""
+		if m.Api == parent.Api {
+			url = baseURL
+		}
+		if offlineMode {
+			if err := offline.CheckModelURL(m.ID, url); err != nil {
+				if agent.IsModelTier(name) {
+					return parent, parentProvider, nil
+				}
+				return nil, nil, err
+			}
+		}
+		p := ai.GetProvider(m.Api, url)
+		if p == nil {
+			if agent.IsModelTier(name) {
+				return parent, parentProvider, nil
+			}
+			return nil, nil, fmt.Errorf("no provider registered for API %q", m.Api)
+		}
+		return m, p, nil
+	}
+}
+
+// skillScanLimit caps the project files inspected for skill glob triggers.
+const skillScanLimit = 5000
+
+// skillScanFiles lists project files for glob matching, only when some skill declares globs.
+func skillScanFiles(skills []prompt.Skill, cwd string) []string {
+	for _, s := range skills {
+		if len(s.Globs) > 0 {
+			return prompt.ScanProjectFiles(cwd, skillScanLimit)
+		}
+	}
+	return nil
+}
+
 // promptVersion returns the active prompt version from config, or empty for hardcoded fallback.
 func promptVersion(cfg *config.Settings) string {
 	if cfg.Prompts != nil && cfg.Prompts.ActiveVersion != "" {
@@ -411,31 +1330,54 @@ func promptVersion(cfg *config.Settings) string {
 	return ""
 }
 
-// resolveTheme loads the theme from config. It checks:
-// 1. Built-in theme names (default, dark, light, monochrome)
-// 2. JSON file in theme directories
-// Falls back to "default" if not set or not found.
-func resolveTheme(cfg *config.Settings, cwd string) {
+// backgroundQueryTimeout bounds the wait for the terminal's OSC 11 reply.
+const backgroundQueryTimeout = 200 * time.Millisecond
+
+// resolveTheme loads the theme from config: a built-in name (default, dark,
+// light, monochrome) or a JSON file in the theme directories. "auto" picks
+// dark or light from the terminal background, which is only queried for the
+// interactive TUI, before it starts reading input. Falls back to "default"
+// if not set or not found.
+func resolveTheme(cfg *config.Settings, cwd string, interactive bool) {
 	name := cfg.Theme
+	if name == "auto" {
+		name = ""
+		if interactive {
+			if dark, ok := termcap.QueryBackground(backgroundQueryTimeout); ok {
+				name = "light"
+				if dark {
+					name = "dark"
+				}
+			}
+		}
+	}
 	if name == "" {
 		return // already initialized to default
 	}
 
-	// Try built-in first
-	if th := theme.Builtin(name); th != nil {
-		theme.Set(th)
+	th, err := theme.Resolve(name, config.ThemesDirs(cwd))
+	if err != nil {
+		// Unknown theme; keep default
+		fmt.Fprintf(os.Stderr, "warning: unknown theme %q, using default\n", name)
 		return
 	}
+	theme.Set(th)
+}
 
-	// Try loading from theme directories
-	for _, dir := range config.ThemesDirs(cwd) {
-		path := filepath.Join(dir, name+".json")
-		if th, err := theme.LoadFile(path); err == nil {
-			theme.Set(th)
-			return
-		}
+// saveThinkingLevel records the thinking level picked with alt+t for a model
+// in the user settings.
+func saveThinkingLevel(model, level string) error {
+	if err := config.SaveUserSettingEntry("thinkingLevels", model, level); err != nil {
+		return fmt.Errorf("saving thinking level: %w", err)
 	}
+	return nil
+}
 
-	// Unknown theme; keep default
-	fmt.Fprintf(os.Stderr, "warning: unknown theme %q, using default\n", name)
+// saveTheme records the theme picked with /theme in the user settings, so it
+// applies to every project.
+func saveTheme(name string) error {
+	if err := config.SaveUserSetting("theme", name); err != nil {
+		return fmt.Errorf("saving theme: %w", err)
+	}
+	return nil
 }
@@ -0,0 +1,67 @@
+// ABOUTME: Starts plugins and wires their tools, slash commands, and tool events into the session
+// ABOUTME: Problems with one plugin are warned about and leave the others running
+
+package main
+
+import (
+	"context"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/commands"
+	"github.com/mauromedda/pi-coding-agent-go/internal/config"
+	pilog "github.com/mauromedda/pi-coding-agent-go/internal/log"
+	"github.com/mauromedda/pi-coding-agent-go/internal/plugin"
+	"github.com/mauromedda/pi-coding-agent-go/internal/tools"
+)
+
+// startPlugins starts the discovered and declared plugins, registers their
+// tools, and routes every registered tool through their PreToolUse and
+// PostToolUse hooks. It returns nil when no plugin runs; the caller closes
+// the host.
+func startPlugins(cfg *config.Settings, cwd string, reg *tools.Registry) (*plugin.Host, []*commands.Command) {
+	defs, errs := plugin.Discover(config.PluginsDirs(), cfg.Plugins)
+	for _, err := range errs {
+		pilog.Warn("%v", err)
+	}
+	if len(defs) == 0 {
+		return nil, nil
+	}
+	host, errs := plugin.StartAll(context.Background(), defs, cwd)
+	for _, err := range errs {
+		pilog.Warn("%v", err)
+	}
+	if len(host.Plugins()) == 0 {
+		return nil, nil
+	}
+
+	pluginTools, errs := host.Tools(func(name string) bool { return reg.Get(name) != nil })
+	for _, err := range errs {
+		pilog.Warn("%v", err)
+	}
+	for _, t := range pluginTools {
+		reg.Register(t)
+	}
+	if mw := host.Middleware(); mw != nil {
+		reg.Use(mw)
+	}
+
+	// A throwaway registry holds the built-ins, so collisions with them
+	// and between plugins are reported here rather than inside the TUI.
+	builtins := commands.NewRegistry()
+	var cmds []*commands.Command
+	for _, pc := range host.Commands() {
+		cmd := &commands.Command{
+			Name:        pc.Name,
+			Category:    "Plugins",
+			Description: pc.Description,
+			Execute: func(_ *commands.CommandContext, args string) (string, error) {
+				return pc.Run(args)
+			},
+		}
+		if err := builtins.Register(cmd); err != nil {
+			pilog.Warn("plugin %s: %v", pc.Plugin, err)
+			continue
+		}
+		cmds = append(cmds, cmd)
+	}
+	return host, cmds
+}
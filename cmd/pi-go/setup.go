@@ -0,0 +1,217 @@
+// ABOUTME: First-run setup wizard: provider, API key (checked before saving), default model, theme, and permissions
+// ABOUTME: Runs on a terminal when no settings or keys exist, and on demand as pi-go setup
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/config"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/theme"
+)
+
+// setupKeyTimeout bounds the request checking a pasted key.
+const setupKeyTimeout = 15 * time.Second
+
+// autonomyChoices describes the permission levels setup offers.
+var autonomyChoices = map[string]string{
+	config.AutonomyCautious:   "ask before edits and commands",
+	config.AutonomyBalanced:   "apply edits, ask for risky commands",
+	config.AutonomyAutonomous: "run freely, ask before push and rm -rf",
+}
+
+// needsFirstRunSetup reports whether an interactive session on a terminal
+// is starting on a machine with no settings or keys. The mock model and
+// custom endpoints need no key, so they skip setup.
+func needsFirstRunSetup(args cliArgs, cfg *config.Settings, auth *config.AuthStore) bool {
+	if args.prompt != "" || args.print || args.serve || args.run || !term.IsTerminal(int(os.Stdin.Fd())) {
+		return false
+	}
+	if args.model == "mock" || cfg.Model == "mock" || args.baseURL != "" || cfg.BaseURL != "" {
+		return false
+	}
+	return config.NeedsSetup(auth)
+}
+
+// runSetup asks for a provider, its API key, a default model, a theme, and
+// a permission default, then saves the key like pi-go auth login and the
+// rest to ~/.pi-go/settings.json. Prompts go to w; answers come from stdin,
+// and the key is read without echo on a terminal.
+func runSetup(stdin *os.File, w io.Writer, network *config.NetworkSettings) error {
+	auth, err := config.LoadAuth()
+	if err != nil {
+		return fmt.Errorf("loading auth: %w", err)
+	}
+	r := bufio.NewReader(stdin)
+	fmt.Fprintf(w, "Setting up pi-go. Press Enter to accept the [default]; Ctrl+C quits without saving.\n")
+
+	var s config.Setup
+	i, err := choose(r, w, "Provider", config.SetupProviders, 0)
+	if err != nil {
+		return err
+	}
+	s.Provider = config.SetupProviders[i]
+
+	key, err := setupKey(r, stdin, w, network, s.Provider)
+	if err != nil {
+		return err
+	}
+
+	models := config.SetupModels(s.Provider)
+	labels := make([]string, len(models))
+	for i, m := range models {
+		labels[i] = fmt.Sprintf("%s (%s)", m.Name, m.ID)
+	}
+	if i, err = choose(r, w, "Default model", labels, 0); err != nil {
+		return err
+	}
+	s.Model = models[i].ID
+
+	themes := append([]string{"auto"}, theme.BuiltinNames()...)
+	if i, err = choose(r, w, "Theme (auto follows the terminal background)", themes, 0); err != nil {
+		return err
+	}
+	s.Theme = themes[i]
+
+	levels := make([]string, len(config.AutonomyLevels))
+	for i, level := range config.AutonomyLevels {
+		levels[i] = level + ": " + autonomyChoices[level]
+	}
+	if i, err = choose(r, w, "How autonomous should the agent be by default?", levels, 0); err != nil {
+		return err
+	}
+	s.Autonomy = config.AutonomyLevels[i]
+
+	fmt.Fprintln(w)
+	if err := authLogin(auth, s.Provider, key, w); err != nil {
+		return err
+	}
+	if err := s.Save(); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Saved the model, theme, and permissions to %s.\n", config.UserSettingsFile())
+	fmt.Fprintf(w, "Rerun pi-go setup, or use /model, /theme, and /permissions, to change them.\n\n")
+	return nil
+}
+
+// setupKey reads provider's key and checks it, asking again while the
+// provider rejects it. A key that cannot be checked, say offline or behind
+// a proxy not yet configured, is kept if the user says so.
+func setupKey(r *bufio.Reader, stdin *os.File, w io.Writer, network *config.NetworkSettings, provider string) (string, error) {
+	for {
+		key, err := readSetupKey(r, stdin, w, provider)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(w, "Checking the key... ")
+		opts := network.HTTPOptionsFor(provider, "")
+		opts.RequestTimeout = setupKeyTimeout
+		ctx, cancel := context.WithTimeout(context.Background(), setupKeyTimeout)
+		err = config.VerifyAPIKey(ctx, opts.NewClient(), provider, "", key)
+		cancel()
+		switch {
+		case err == nil:
+			fmt.Fprintln(w, "ok.")
+			return key, nil
+		case errors.Is(err, config.ErrKeyRejected):
+			fmt.Fprintf(w, "%v. Paste it again.\n", err)
+		default:
+			fmt.Fprintf(w, "could not check it: %v\n", err)
+			keep, err := confirm(r, w, "Keep the key anyway?", true)
+			if err != nil || keep {
+				return key, err
+			}
+		}
+	}
+}
+
+// readSetupKey reads a key without echo on a terminal, or as the next line
+// of r otherwise.
+func readSetupKey(r *bufio.Reader, stdin *os.File, w io.Writer, provider string) (string, error) {
+	fmt.Fprintf(w, "\n%s API key: ", provider)
+	var key string
+	if fd := int(stdin.Fd()); term.IsTerminal(fd) {
+		b, err := term.ReadPassword(fd)
+		fmt.Fprintln(w)
+		if err != nil {
+			return "", fmt.Errorf("reading key: %w", err)
+		}
+		key = string(b)
+	} else {
+		line, err := readLine(r)
+		if err != nil {
+			return "", err
+		}
+		key = line
+	}
+	if key = strings.TrimSpace(key); key == "" {
+		return "", fmt.Errorf("no key given")
+	}
+	return key, nil
+}
+
+// choose lists options under title and returns the index picked by number,
+// or def on an empty answer. It asks again after an invalid answer.
+func choose(r *bufio.Reader, w io.Writer, title string, options []string, def int) (int, error) {
+	fmt.Fprintf(w, "\n%s\n", title)
+	for i, opt := range options {
+		fmt.Fprintf(w, "  %d) %s\n", i+1, opt)
+	}
+	for {
+		fmt.Fprintf(w, "Choose [%d]: ", def+1)
+		line, err := readLine(r)
+		if err != nil {
+			return 0, err
+		}
+		if line == "" {
+			return def, nil
+		}
+		if n, err := strconv.Atoi(line); err == nil && n >= 1 && n <= len(options) {
+			return n - 1, nil
+		}
+		fmt.Fprintf(w, "Enter a number from 1 to %d.\n", len(options))
+	}
+}
+
+// confirm asks a yes/no question; an empty answer is def.
+func confirm(r *bufio.Reader, w io.Writer, question string, def bool) (bool, error) {
+	hint := "[y/N]"
+	if def {
+		hint = "[Y/n]"
+	}
+	fmt.Fprintf(w, "%s %s ", question, hint)
+	line, err := readLine(r)
+	if err != nil {
+		return false, err
+	}
+	switch strings.ToLower(line) {
+	case "":
+		return def, nil
+	case "y", "yes":
+		return true, nil
+	}
+	return false, nil
+}
+
+// readLine returns the next trimmed line of r. Input ending before setup
+// finishes is an error, so nothing half-answered is saved.
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err == io.EOF && line == "" {
+		return "", errors.New("setup cancelled: input ended")
+	}
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("reading answer: %w", err)
+	}
+	return strings.TrimSpace(line), nil
+}
@@ -0,0 +1,26 @@
+// ABOUTME: pi-go share open subcommand: downloads a /share link and prints its Markdown
+// ABOUTME: Encrypted shares are decrypted with the key from the link fragment
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/export"
+)
+
+const shareUsage = "usage: share open <link>"
+
+// runShare handles "share open <link>", writing the transcript to stdout.
+func runShare(args []string, stdout io.Writer) error {
+	if len(args) != 2 || args[0] != "open" {
+		return fmt.Errorf("%s", shareUsage)
+	}
+	md, err := export.OpenShare(args[1])
+	if err != nil {
+		return fmt.Errorf("opening share: %w", err)
+	}
+	_, err = io.WriteString(stdout, md)
+	return err
+}
@@ -13,6 +13,7 @@ import (
 
 	pilog "github.com/mauromedda/pi-coding-agent-go/internal/log"
 	"github.com/mauromedda/pi-coding-agent-go/internal/perf"
+	"github.com/mauromedda/pi-coding-agent-go/internal/reminder"
 	"github.com/mauromedda/pi-coding-agent-go/internal/session"
 	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
 	"golang.org/x/sync/errgroup"
@@ -36,6 +37,9 @@ type Agent struct {
 	tools     map[string]*AgentTool
 	permCheck PermCheckFunc
 	adaptive  *AdaptiveConfig
+	minion    *Minion
+	reminders *reminder.Injector
+	escalated bool         // minion escalated to the main model during the current turn
 	state     atomic.Int32 // stores AgentState
 	events    chan AgentEvent
 	steerCh   chan ai.Message
@@ -68,6 +72,18 @@ func (a *Agent) SetAdaptive(cfg *AdaptiveConfig) {
 	a.adaptive = cfg
 }
 
+// SetMinion enables minion routing: eligible LLM calls go to the minion's
+// model, and every routing decision is emitted as EventModelRoute.
+func (a *Agent) SetMinion(m *Minion) {
+	a.minion = m
+}
+
+// SetReminders injects the injector's system reminders before each LLM
+// call and reports finished tool calls to it.
+func (a *Agent) SetReminders(in *reminder.Injector) {
+	a.reminders = in
+}
+
 // Prompt starts the agent loop in a goroutine and returns an event channel.
 // The channel is closed when the loop terminates (end-turn, error, or cancel).
 func (a *Agent) Prompt(ctx context.Context, llmCtx *ai.Context, opts *ai.StreamOptions) <-chan AgentEvent {
@@ -116,6 +132,8 @@ func (a *Agent) loop(ctx context.Context, llmCtx *ai.Context, opts *ai.StreamOpt
 	// so they are delivered even after context cancellation.
 	pilog.Debug("agent: loop start model=%s tools=%d", a.model.Name, len(a.tools))
 	a.emitFinal(AgentEvent{Type: EventAgentStart})
+	a.escalated = false
+	malformed := 0 // replies in a row whose every tool call failed to parse
 
 	for {
 		if err := ctx.Err(); err != nil {
@@ -124,9 +142,13 @@ func (a *Agent) loop(ctx context.Context, llmCtx *ai.Context, opts *ai.StreamOpt
 		}
 
 		a.drainSteeringMessages(llmCtx)
+		a.injectReminders(llmCtx)
 		a.applyAdaptive(ctx, llmCtx, opts)
 
-		msg, err := a.streamResponse(ctx, llmCtx, opts)
+		// One request ID per LLM call tags its log records and those of
+		// the tool calls it makes.
+		callCtx := pilog.WithRequestID(ctx, pilog.NewRequestID())
+		msg, err := a.streamResponse(callCtx, llmCtx, opts)
 		if err != nil {
 			a.emitFinal(AgentEvent{Type: EventError, Error: fmt.Errorf("streaming response: %w", err)})
 			break
@@ -138,6 +160,15 @@ func (a *Agent) loop(ctx context.Context, llmCtx *ai.Context, opts *ai.StreamOpt
 		if len(toolCalls) == 0 && len(parseErrResults) == 0 {
 			break
 		}
+		if len(toolCalls) == 0 {
+			malformed++
+		} else {
+			malformed = 0
+		}
+		if malformed > maxMalformedRounds {
+			a.emitFinal(AgentEvent{Type: EventError, Error: fmt.Errorf("the model sent only malformed tool calls %d times in a row", malformed)})
+			break
+		}
 
 		var results []toolExecResult
 
@@ -145,7 +176,7 @@ func (a *Agent) loop(ctx context.Context, llmCtx *ai.Context, opts *ai.StreamOpt
 		results = append(results, parseErrResults...)
 
 		if len(toolCalls) > 0 {
-			execResults, err := a.executeTools(ctx, toolCalls)
+			execResults, err := a.executeTools(callCtx, toolCalls)
 			if err != nil {
 				a.emitFinal(AgentEvent{Type: EventError, Error: fmt.Errorf("executing tools: %w", err)})
 				break
@@ -171,6 +202,16 @@ func (a *Agent) drainSteeringMessages(llmCtx *ai.Context) {
 	}
 }
 
+// injectReminders appends pending system reminders to the context.
+func (a *Agent) injectReminders(llmCtx *ai.Context) {
+	if a.reminders == nil {
+		return
+	}
+	if msg, ok := a.reminders.Collect(); ok {
+		llmCtx.Messages = append(llmCtx.Messages, msg)
+	}
+}
+
 // applyAdaptive runs pre-flight decisions: compaction and adaptive MaxOutputTokens.
 func (a *Agent) applyAdaptive(ctx context.Context, llmCtx *ai.Context, opts *ai.StreamOptions) {
 	if a.adaptive == nil {
@@ -183,16 +224,7 @@ func (a *Agent) applyAdaptive(ctx context.Context, llmCtx *ai.Context, opts *ai.
 
 	// Pre-flight compaction
 	if params.CompactBeforeCall && a.adaptive.Summarizer != nil {
-		if session.ShouldCompact(llmCtx.Messages, contextWindow, a.adaptive.Compaction) {
-			pilog.Debug("agent: pre-flight compaction triggered (input=%d, window=%d)", inputTokens, contextWindow)
-			result, err := session.CompactWithLLM(ctx, llmCtx.Messages, a.adaptive.Compaction, a.adaptive.Summarizer)
-			if err != nil {
-				pilog.Debug("agent: pre-flight compaction failed: %v", err)
-			} else {
-				llmCtx.Messages = result.Messages
-				pilog.Debug("agent: compacted %d→%d tokens", result.TokensBefore, session.EstimateMessagesTokens(result.Messages))
-			}
-		}
+		a.compactBeforeCall(ctx, llmCtx, inputTokens, contextWindow)
 	}
 
 	// Adaptive parameters
@@ -205,28 +237,97 @@ func (a *Agent) applyAdaptive(ctx context.Context, llmCtx *ai.Context, opts *ai.
 	}
 }
 
+// compactBeforeCall compacts the context when it exceeds the window. System
+// reminders are dropped first; when that is enough, nothing is summarized.
+func (a *Agent) compactBeforeCall(ctx context.Context, llmCtx *ai.Context, inputTokens, contextWindow int) {
+	cfg := a.adaptive.Compaction
+	if !session.ShouldCompact(llmCtx.Messages, contextWindow, cfg) {
+		return
+	}
+	if msgs, n := session.DropEphemeral(llmCtx.Messages); n > 0 && !session.ShouldCompact(msgs, contextWindow, cfg) {
+		pilog.Debug("agent: dropped %d system reminders instead of compacting", n)
+		llmCtx.Messages = msgs
+		return
+	}
+	pilog.Debug("agent: pre-flight compaction triggered (input=%d, window=%d)", inputTokens, contextWindow)
+	result, err := session.CompactWithLLM(ctx, llmCtx.Messages, cfg, a.adaptive.Summarizer)
+	if err != nil {
+		pilog.Debug("agent: pre-flight compaction failed: %v", err)
+		return
+	}
+	llmCtx.Messages = result.Messages
+	pilog.Debug("agent: compacted %d→%d tokens", result.TokensBefore, session.EstimateMessagesTokens(result.Messages))
+}
+
 // streamResponse streams a single LLM response, emitting text/thinking events.
+// With a minion configured, the call is routed first and escalates to the main
+// model when the minion fails before producing any output.
 func (a *Agent) streamResponse(ctx context.Context, llmCtx *ai.Context, opts *ai.StreamOptions) (*ai.AssistantMessage, error) {
-	pilog.Debug("agent: streaming model=%s messages=%d", a.model.Name, len(llmCtx.Messages))
-	stream := a.provider.Stream(ctx, a.model, llmCtx, opts)
+	if a.minion != nil {
+		useMinion, reason := a.minion.route(llmCtx.Messages, a.escalated)
+		if useMinion {
+			a.emit(ctx, AgentEvent{Type: EventModelRoute, Route: &ModelRoute{Model: a.minion.Model.Name, Minion: true, Reason: reason}})
+			msg, streamed, err := a.streamFrom(ctx, a.minion.Provider, a.minion.Model, llmCtx, opts, true)
+			if err == nil || streamed || ctx.Err() != nil {
+				return msg, err
+			}
+			pilog.Debug("agent: minion failed, escalating: %v", err)
+			reason = "minion failed: " + err.Error()
+		}
+		if a.minion.Mode() != MinionOff {
+			a.escalated = true
+		}
+		a.emit(ctx, AgentEvent{Type: EventModelRoute, Route: &ModelRoute{Model: a.model.Name, Reason: reason}})
+	}
+	msg, _, err := a.streamFrom(ctx, a.provider, a.model, llmCtx, opts, false)
+	return msg, err
+}
 
+// streamFrom streams one response from provider/model. streamed reports
+// whether any content reached the consumer. In quiet mode provider errors
+// are returned instead of emitted, so the caller can retry elsewhere.
+func (a *Agent) streamFrom(ctx context.Context, provider ai.ApiProvider, model *ai.Model, llmCtx *ai.Context, opts *ai.StreamOptions, quiet bool) (*ai.AssistantMessage, bool, error) {
+	logger := pilog.FromContext(ctx).With("model", model.ID)
+	logger.Debug("agent: streaming model=%s messages=%d", model.Name, len(llmCtx.Messages))
+	start := time.Now()
+	stream := provider.Stream(ctx, model, forModel(model, llmCtx), opts)
+
+	streamed := false
+	var streamErr error
 	for evt := range stream.Events() {
 		if ctx.Err() != nil {
-			return nil, fmt.Errorf("context cancelled during stream: %w", ctx.Err())
+			return nil, streamed, fmt.Errorf("context cancelled during stream: %w", ctx.Err())
+		}
+		if quiet && evt.Type == ai.EventError {
+			streamErr = evt.Error
+			continue
+		}
+		if evt.Type == ai.EventContentDelta || evt.Type == ai.EventThinkingDelta {
+			streamed = true
 		}
 		a.forwardStreamEvent(ctx, evt)
 	}
 
 	result := stream.Result()
 	if result == nil {
-		return nil, fmt.Errorf("stream completed without result")
+		if streamErr != nil {
+			logger.Debug("agent: llm call failed after %s: %v", time.Since(start).Round(time.Millisecond), streamErr)
+			return nil, streamed, streamErr
+		}
+		logger.Debug("agent: llm call ended without result after %s", time.Since(start).Round(time.Millisecond))
+		return nil, streamed, fmt.Errorf("stream completed without result")
+	}
+	logger.Debug("agent: llm call done in %s: stop=%s in=%d out=%d", time.Since(start).Round(time.Millisecond),
+		result.StopReason, result.Usage.InputTokens, result.Usage.OutputTokens)
+	if model.TextToolCalls {
+		result = textToolCalls(result)
 	}
 
 	// Emit token usage stats
 	usage := result.Usage
 	a.emit(ctx, AgentEvent{Type: EventUsageUpdate, Usage: &usage})
 
-	return result, nil
+	return result, streamed, nil
 }
 
 // forwardStreamEvent translates an ai.StreamEvent into an AgentEvent.
@@ -274,6 +375,11 @@ func extractToolCalls(msg *ai.AssistantMessage) ([]toolCall, []toolExecResult) {
 		if c.Type != ai.ContentToolUse {
 			continue
 		}
+		if c.Name == "" {
+			// A textual tool block that did not parse; Text says why.
+			errResults = append(errResults, toolExecResult{ID: c.ID, Result: ToolResult{Content: c.Text, IsError: true}})
+			continue
+		}
 
 		args, err := ParseToolArgs(c.Input)
 		if err != nil {
@@ -370,9 +476,12 @@ func (a *Agent) executeSingleTool(ctx context.Context, tc toolCall) (toolExecRes
 		}, nil
 	}
 
+	logger := pilog.FromContext(ctx).With("tool", tc.Name, "tool_id", tc.ID)
+
 	// Permission check before execution
 	if a.permCheck != nil {
 		if err := a.permCheck(tc.Name, tc.Args); err != nil {
+			logger.Debug("agent: tool %s denied: %v", tc.Name, err)
 			result := ToolResult{Content: err.Error(), IsError: true}
 			a.emit(ctx, AgentEvent{
 				Type: EventToolEnd, ToolID: tc.ID, ToolName: tc.Name, ToolResult: &result,
@@ -381,16 +490,19 @@ func (a *Agent) executeSingleTool(ctx context.Context, tc toolCall) (toolExecRes
 		}
 	}
 
-	pilog.Debug("agent: tool %s (id=%s)", tc.Name, tc.ID)
+	logger.Debug("agent: tool %s (id=%s)", tc.Name, tc.ID)
 	a.emit(ctx, AgentEvent{
 		Type: EventToolStart, ToolID: tc.ID, ToolName: tc.Name, ToolArgs: tc.Args,
 	})
 
 	start := time.Now()
 	onUpdate := func(u ToolUpdate) {
-		a.emit(ctx, AgentEvent{Type: EventToolUpdate, ToolID: tc.ID, ToolName: tc.Name, Text: u.Output})
+		a.emit(ctx, AgentEvent{Type: EventToolUpdate, ToolID: tc.ID, ToolName: tc.Name, Text: u.Output, ToolStep: u.Step})
 	}
 
+	if a.permCheck != nil {
+		ctx = WithPermCheck(ctx, a.permCheck)
+	}
 	result, err := tool.Execute(ctx, tc.ID, tc.Args, onUpdate)
 	result.Duration = time.Since(start)
 
@@ -398,6 +510,10 @@ func (a *Agent) executeSingleTool(ctx context.Context, tc toolCall) (toolExecRes
 		result.Content = err.Error()
 		result.IsError = true
 	}
+	logger.Debug("agent: tool %s done in %s: error=%t bytes=%d", tc.Name, result.Duration.Round(time.Millisecond), result.IsError, len(result.Content))
+	if a.reminders != nil && !result.IsError {
+		a.reminders.ToolDone(tc.Name, tc.Args)
+	}
 
 	a.emit(ctx, AgentEvent{
 		Type: EventToolEnd, ToolID: tc.ID, ToolName: tc.Name, ToolResult: &result,
@@ -8,12 +8,17 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/mauromedda/pi-coding-agent-go/internal/perf"
+	"github.com/mauromedda/pi-coding-agent-go/internal/reminder"
 	"github.com/mauromedda/pi-coding-agent-go/internal/types"
 	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
 )
@@ -765,3 +770,66 @@ func TestAgent_AbortCancelsExecution(t *testing.T) {
 		t.Error("expected an error event after abort")
 	}
 }
+
+// contextCapturingProvider records the messages sent on each call.
+type contextCapturingProvider struct {
+	mockProvider
+	mu    sync.Mutex
+	calls [][]ai.Message
+}
+
+func (c *contextCapturingProvider) Stream(ctx context.Context, model *ai.Model, aiCtx *ai.Context, opts *ai.StreamOptions) *ai.EventStream {
+	c.mu.Lock()
+	c.calls = append(c.calls, slices.Clone(aiCtx.Messages))
+	c.mu.Unlock()
+	return c.mockProvider.Stream(ctx, model, aiCtx, opts)
+}
+
+func TestAgent_InjectsReminders(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("v1"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	provider := &contextCapturingProvider{mockProvider: mockProvider{responses: []*ai.AssistantMessage{
+		{
+			Content:    []ai.Content{{Type: ai.ContentToolUse, ID: "t1", Name: "read", Input: json.RawMessage(`{"path":"` + path + `"}`)}},
+			StopReason: ai.StopToolUse,
+		},
+		{Content: []ai.Content{{Type: ai.ContentText, Text: "done"}}, StopReason: ai.StopEndTurn},
+	}}}
+	readTool := &AgentTool{
+		Name:     "read",
+		ReadOnly: true,
+		Execute: func(context.Context, string, map[string]any, func(ToolUpdate)) (ToolResult, error) {
+			return ToolResult{Content: "v1"}, nil
+		},
+	}
+
+	in := reminder.New(reminder.PlanMode(func() bool { return true }))
+	files := in.TrackFiles()
+	ag := New(provider, newTestModel(), []*AgentTool{readTool})
+	ag.SetReminders(in)
+	collectEvents(ag.Prompt(context.Background(), newTestContext(), &ai.StreamOptions{}))
+
+	if len(provider.calls) != 2 {
+		t.Fatalf("calls = %d; want 2", len(provider.calls))
+	}
+	first := provider.calls[0]
+	if last := first[len(first)-1]; !last.Ephemeral || !strings.Contains(last.Content[0].Text, "Plan mode") {
+		t.Errorf("first call should end with the plan mode reminder, got %+v", last)
+	}
+	if second := provider.calls[1]; second[len(second)-1].Ephemeral {
+		t.Error("an unchanged reminder must not be sent again")
+	}
+
+	// The read was recorded, so an outside change is now detected.
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if changed := files.Changed(); len(changed) != 1 || changed[0] != path {
+		t.Errorf("Changed() = %v; want the file the agent read", changed)
+	}
+}
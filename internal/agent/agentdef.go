@@ -1,11 +1,13 @@
 // ABOUTME: Agent definition registry with builtins and custom agent loading
-// ABOUTME: Loads from .pi-go/agents/, ~/.pi-go/agents/, .claude/agents/ directories
+// ABOUTME: Loads from .pi-go/agents/, .pi/agents/, ~/.pi-go/agents/, .claude/agents/ directories
 
 package agent
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -20,6 +22,90 @@ type Definition struct {
 	DisallowedTools []string
 	AllowedTools    []string
 	MaxTurns        int
+
+	// Source is the file the definition was loaded from ("" for builtins).
+	Source string
+	// Problems lists issues found while parsing the definition file.
+	Problems []string
+}
+
+// ToolAllowlist returns the union of tools and allowed-tools (nil = inherit all).
+func (d Definition) ToolAllowlist() []string {
+	if len(d.AllowedTools) == 0 {
+		return d.Tools
+	}
+	out := slices.Clone(d.Tools)
+	for _, t := range d.AllowedTools {
+		if !slices.Contains(out, t) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// Validate reports problems with the definition: parse issues, unknown tool
+// names, an unresolvable model, and allowlists that leave no usable tool.
+// resolveModel may be nil to skip model checks.
+func (d Definition) Validate(toolNames []string, resolveModel func(string) error) []string {
+	problems := slices.Clone(d.Problems)
+	if d.Description == "" {
+		problems = append(problems, "missing description (the main agent uses it to decide when to delegate)")
+	}
+	if d.MaxTurns < 0 {
+		problems = append(problems, fmt.Sprintf("max-turns must be positive, got %d", d.MaxTurns))
+	}
+
+	known := make(map[string]bool, len(toolNames))
+	for _, n := range toolNames {
+		known[n] = true
+	}
+	for _, list := range []struct {
+		key   string
+		names []string
+	}{
+		{"tools", d.Tools},
+		{"allowed-tools", d.AllowedTools},
+		{"disallowed-tools", d.DisallowedTools},
+	} {
+		for _, n := range list.names {
+			if !known[n] {
+				problems = append(problems, fmt.Sprintf("%s: unknown tool %q", list.key, n))
+			}
+		}
+	}
+	if allow := d.ToolAllowlist(); len(allow) > 0 {
+		usable := false
+		for _, n := range allow {
+			if known[n] && !slices.Contains(d.DisallowedTools, n) {
+				usable = true
+				break
+			}
+		}
+		if !usable {
+			problems = append(problems, "tool allowlist leaves no usable tools")
+		}
+	}
+
+	if resolveModel != nil && d.Model != "" && d.Model != ModelInherit && !IsModelTier(d.Model) {
+		if err := resolveModel(d.Model); err != nil {
+			problems = append(problems, fmt.Sprintf("model %q: %v", d.Model, err))
+		}
+	}
+	return problems
+}
+
+// ModelInherit makes a sub-agent run on the parent's model.
+const ModelInherit = "inherit"
+
+// IsModelTier reports whether name is a shorthand tier (fast, default,
+// powerful) rather than a concrete model ID. Tiers fall back to the parent
+// model when the mapped model is unavailable.
+func IsModelTier(name string) bool {
+	switch name {
+	case "fast", "default", "powerful":
+		return true
+	}
+	return false
 }
 
 // ResolveAgentModel maps shorthand names to full model IDs.
@@ -80,6 +166,7 @@ func LoadDefinitions(projectDir, homeDir string) (map[string]Definition, error)
 		filepath.Join(homeDir, ".pi-go", "agents"),
 		filepath.Join(homeDir, ".claude", "agents"),
 		filepath.Join(projectDir, ".pi-go", "agents"),
+		filepath.Join(projectDir, ".pi", "agents"),
 		filepath.Join(projectDir, ".claude", "agents"),
 	}
 
@@ -93,12 +180,14 @@ func LoadDefinitions(projectDir, homeDir string) (map[string]Definition, error)
 				continue
 			}
 
-			data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+			path := filepath.Join(dir, entry.Name())
+			data, err := os.ReadFile(path)
 			if err != nil {
 				continue
 			}
 
 			def := parseAgentFile(string(data), entry.Name())
+			def.Source = path
 			if def.Name != "" {
 				defs[def.Name] = def
 			}
@@ -115,14 +204,17 @@ func parseAgentFile(content, filename string) Definition {
 	// Default name from filename
 	def.Name = strings.TrimSuffix(filename, filepath.Ext(filename))
 
+	content = strings.ReplaceAll(content, "\r\n", "\n")
 	if !strings.HasPrefix(content, "---\n") {
 		def.SystemPrompt = content
+		def.Problems = append(def.Problems, "no frontmatter: name, description, model, and tools use defaults")
 		return def
 	}
 
 	endIdx := strings.Index(content[4:], "\n---")
 	if endIdx < 0 {
 		def.SystemPrompt = content
+		def.Problems = append(def.Problems, "unterminated frontmatter: missing closing ---")
 		return def
 	}
 
@@ -137,6 +229,7 @@ func parseAgentFile(content, filename string) Definition {
 
 		key, value, ok := strings.Cut(line, ":")
 		if !ok {
+			def.Problems = append(def.Problems, fmt.Sprintf("malformed frontmatter line %q", line))
 			continue
 		}
 		key = strings.TrimSpace(key)
@@ -152,6 +245,8 @@ func parseAgentFile(content, filename string) Definition {
 		case "max-turns":
 			if n, err := strconv.Atoi(value); err == nil {
 				def.MaxTurns = n
+			} else {
+				def.Problems = append(def.Problems, fmt.Sprintf("max-turns: %q is not a number", value))
 			}
 		case "tools":
 			def.Tools = splitTrimCSV(value)
@@ -159,14 +254,23 @@ func parseAgentFile(content, filename string) Definition {
 			def.DisallowedTools = splitTrimCSV(value)
 		case "allowed-tools":
 			def.AllowedTools = splitTrimCSV(value)
+		case "color":
+			// Claude Code UI hint; accepted for compatibility.
+		default:
+			def.Problems = append(def.Problems, fmt.Sprintf("unknown frontmatter key %q", key))
 		}
 	}
 
+	if def.SystemPrompt == "" {
+		def.Problems = append(def.Problems, "empty system prompt body")
+	}
 	return def
 }
 
 // splitTrimCSV splits a comma-separated string and trims whitespace.
+// A YAML flow list ("[read, grep]") is accepted as well.
 func splitTrimCSV(s string) []string {
+	s = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(s), "["), "]")
 	parts := strings.Split(s, ",")
 	var result []string
 	for _, p := range parts {
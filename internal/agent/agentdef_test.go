@@ -4,6 +4,10 @@
 package agent
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -152,3 +156,113 @@ You deploy applications safely.
 		t.Errorf("SystemPrompt = %q; want %q", def.SystemPrompt, "You deploy applications safely.")
 	}
 }
+
+func TestParseDefinition_ReportsProblems(t *testing.T) {
+	t.Parallel()
+
+	content := "---\nname: broken\nmax-turns: lots\nflavor: spicy\n---\n"
+	def := parseAgentFile(content, "broken.md")
+
+	want := []string{"max-turns", "unknown frontmatter key \"flavor\"", "empty system prompt"}
+	for _, w := range want {
+		found := false
+		for _, p := range def.Problems {
+			if strings.Contains(p, w) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Problems %v missing %q", def.Problems, w)
+		}
+	}
+}
+
+func TestParseDefinition_FlowListTools(t *testing.T) {
+	t.Parallel()
+
+	def := parseAgentFile("---\ndescription: d\ntools: [read, grep]\n---\nPrompt.", "a.md")
+	if len(def.Tools) != 2 || def.Tools[0] != "read" || def.Tools[1] != "grep" {
+		t.Errorf("Tools = %v; want [read grep]", def.Tools)
+	}
+	if len(def.Problems) != 0 {
+		t.Errorf("unexpected problems: %v", def.Problems)
+	}
+}
+
+func TestDefinition_ToolAllowlist(t *testing.T) {
+	t.Parallel()
+
+	d := Definition{Tools: []string{"read", "grep"}, AllowedTools: []string{"grep", "edit"}}
+	got := d.ToolAllowlist()
+	if strings.Join(got, ",") != "read,grep,edit" {
+		t.Errorf("ToolAllowlist() = %v", got)
+	}
+	if (Definition{}).ToolAllowlist() != nil {
+		t.Error("empty definition should inherit all tools (nil)")
+	}
+}
+
+func TestDefinition_Validate(t *testing.T) {
+	t.Parallel()
+
+	tools := []string{"read", "grep", "bash"}
+	resolve := func(name string) error {
+		if name == "gpt-4o" {
+			return nil
+		}
+		return fmt.Errorf("unknown model %q", name)
+	}
+
+	ok := Definition{Name: "r", Description: "Reviews", Model: "gpt-4o", Tools: []string{"read"}}
+	if p := ok.Validate(tools, resolve); len(p) != 0 {
+		t.Errorf("valid definition reported %v", p)
+	}
+
+	tier := Definition{Name: "t", Description: "Tier", Model: "fast"}
+	if p := tier.Validate(tools, resolve); len(p) != 0 {
+		t.Errorf("tier model should not be resolved: %v", p)
+	}
+
+	bad := Definition{
+		Name:            "b",
+		Model:           "mystery",
+		Tools:           []string{"read", "telepathy"},
+		DisallowedTools: []string{"read"},
+	}
+	problems := strings.Join(bad.Validate(tools, resolve), "\n")
+	for _, w := range []string{"missing description", `unknown tool "telepathy"`, "no usable tools", `model "mystery"`} {
+		if !strings.Contains(problems, w) {
+			t.Errorf("problems missing %q:\n%s", w, problems)
+		}
+	}
+}
+
+func TestLoadDefinitions_PiAgentsDir(t *testing.T) {
+	project := t.TempDir()
+	home := t.TempDir()
+
+	dir := filepath.Join(project, ".pi", "agents")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "doc-writer.md")
+	content := "---\ndescription: Writes docs\nmodel: inherit\ntools: read, write\n---\nYou write documentation."
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	defs, err := LoadDefinitions(project, home)
+	if err != nil {
+		t.Fatalf("LoadDefinitions: %v", err)
+	}
+	def, ok := defs["doc-writer"]
+	if !ok {
+		t.Fatal("expected doc-writer from .pi/agents")
+	}
+	if def.Source != path {
+		t.Errorf("Source = %q; want %q", def.Source, path)
+	}
+	if defs["explore"].Source != "" {
+		t.Error("builtin definitions should have empty Source")
+	}
+}
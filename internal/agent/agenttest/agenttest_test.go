@@ -0,0 +1,133 @@
+// ABOUTME: Tests for the agenttest harness driving the real agent loop
+// ABOUTME: Covers tool round-trips, permission denials, provider failures, updates, and request capture
+
+package agenttest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+func TestHarness_ToolRoundTrip(t *testing.T) {
+	t.Parallel()
+	read := NewReadOnlyTool("read", Returns("package main"))
+	provider := NewProvider(
+		Reply{Text: "Reading.", ToolCalls: []ToolCall{{Name: "read", Args: map[string]any{"path": "main.go"}}}, Usage: ai.Usage{InputTokens: 10, OutputTokens: 3}},
+		Reply{Text: "It is a main package.", Usage: ai.Usage{InputTokens: 20, OutputTokens: 5}},
+	)
+	h := New(provider, nil, read)
+
+	run := h.Run(t, "what is main.go?")
+	run.AssertNoErrors(t)
+	run.AssertText(t, "Reading.It is a main package.")
+	run.AssertToolCalls(t, "read")
+	run.AssertResult(t, "read", "package main", false)
+	run.AssertEventOrder(t, agent.EventAgentStart, agent.EventToolStart, agent.EventToolEnd, agent.EventAgentEnd)
+
+	if calls := read.Calls(); len(calls) != 1 || calls[0]["path"] != "main.go" {
+		t.Errorf("read calls = %v", calls)
+	}
+	if u := run.Usage(); u.InputTokens != 30 || u.OutputTokens != 8 {
+		t.Errorf("usage = %+v; want 30 in, 8 out", u)
+	}
+
+	reqs := provider.Requests()
+	if len(reqs) != 2 {
+		t.Fatalf("requests = %d; want 2", len(reqs))
+	}
+	last := reqs[1].Messages[len(reqs[1].Messages)-1]
+	if last.Content[0].Type != ai.ContentToolResult || last.Content[0].ResultText != "package main" {
+		t.Errorf("second request ends with %+v; want the tool result", last)
+	}
+	if provider.Remaining() != 0 {
+		t.Errorf("Remaining = %d; want 0", provider.Remaining())
+	}
+}
+
+func TestHarness_PermissionDenied(t *testing.T) {
+	t.Parallel()
+	write := NewTool("write")
+	deny := func(tool string, _ map[string]any) error {
+		if tool == "write" {
+			return errors.New("write denied by policy")
+		}
+		return nil
+	}
+	h := New(NewProvider(Call("write", map[string]any{"path": "x"}), Text("ok")), deny, write)
+
+	run := h.Run(t, "write x")
+	run.AssertNoErrors(t)
+	run.AssertToolCalls(t)
+	run.AssertResult(t, "write", "denied by policy", true)
+	if write.CallCount() != 0 {
+		t.Errorf("denied tool ran %d time(s)", write.CallCount())
+	}
+}
+
+func TestHarness_ToolErrorsAndUpdates(t *testing.T) {
+	t.Parallel()
+	bash := NewTool("bash",
+		Result{Updates: []string{"line 1", "line 2"}, Content: "exit 0"},
+		Result{Err: errors.New("exit 1")},
+	)
+	h := New(NewProvider(Call("bash", nil), Call("bash", nil), Text("done")), nil, bash)
+
+	run := h.Run(t, "build")
+	run.AssertToolCalls(t, "bash", "bash")
+	run.AssertResult(t, "bash", "exit 1", true)
+	if updates := run.Of(agent.EventToolUpdate); len(updates) != 2 || updates[1].Text != "line 2" {
+		t.Errorf("updates = %v", updates)
+	}
+}
+
+func TestHarness_ProviderFailure(t *testing.T) {
+	t.Parallel()
+	h := New(NewProvider(Fail(errors.New("overloaded"))), nil)
+
+	run := h.Run(t, "hi")
+	errs := run.Errors()
+	if len(errs) == 0 || !strings.Contains(errs[0].Error(), "overloaded") {
+		t.Errorf("errors = %v; want the provider failure", errs)
+	}
+	run.AssertEventOrder(t, agent.EventAgentStart, agent.EventError, agent.EventAgentEnd)
+}
+
+func TestHarness_UnscriptedCallFails(t *testing.T) {
+	t.Parallel()
+	h := New(NewProvider(Call("read", nil)), nil, NewReadOnlyTool("read"))
+
+	run := h.Run(t, "hi")
+	if len(run.Errors()) == 0 {
+		t.Error("a call past the script should fail the loop")
+	}
+	if !strings.Contains(run.String(), "tool_start(read)") {
+		t.Errorf("String() = %s; want the tool start listed", run)
+	}
+}
+
+func TestHarness_ConcurrentReadOnly(t *testing.T) {
+	t.Parallel()
+	release := make(chan struct{})
+	slow := NewReadOnlyTool("slow", Result{Wait: release, Content: "slow done"})
+	fast := NewReadOnlyTool("fast", Returns("fast done"))
+	// fast closes release, so the loop only finishes when both run together.
+	fastTool := fast.AgentTool()
+	exec := fastTool.Execute
+	fastTool.Execute = func(ctx context.Context, id string, params map[string]any, onUpdate func(agent.ToolUpdate)) (agent.ToolResult, error) {
+		defer close(release)
+		return exec(ctx, id, params, onUpdate)
+	}
+	provider := NewProvider(Calls(ToolCall{Name: "slow"}, ToolCall{Name: "fast"}), Text("both"))
+	h := &Harness{Provider: provider, Model: Model()}
+	h.Agent = agent.New(provider, h.Model, []*agent.AgentTool{slow.AgentTool(), fastTool})
+
+	run := h.Run(t, "go")
+	run.AssertNoErrors(t)
+	run.AssertResult(t, "slow", "slow done", false)
+	run.AssertText(t, "both")
+}
@@ -0,0 +1,134 @@
+// ABOUTME: Scripted fake provider for in-process agent loop tests: one canned reply per LLM call
+// ABOUTME: Records every request so tests can assert what the loop sent (tool results, reminders, compaction)
+
+package agenttest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+// Reply is the canned answer to one LLM call.
+type Reply struct {
+	Thinking  string
+	Text      string
+	ToolCalls []ToolCall
+	Usage     ai.Usage
+	// Err fails the call with an error event and no result.
+	Err error
+}
+
+// ToolCall is a tool call a Reply makes.
+type ToolCall struct {
+	Name string
+	Args map[string]any
+}
+
+// Text returns a reply that ends the turn with s.
+func Text(s string) Reply { return Reply{Text: s} }
+
+// Call returns a reply that calls one tool with args.
+func Call(name string, args map[string]any) Reply {
+	return Reply{ToolCalls: []ToolCall{{Name: name, Args: args}}}
+}
+
+// Calls returns a reply that calls several tools at once.
+func Calls(calls ...ToolCall) Reply { return Reply{ToolCalls: calls} }
+
+// Fail returns a reply that fails the call with err.
+func Fail(err error) Reply { return Reply{Err: err} }
+
+// Provider is an ai.ApiProvider that answers the Nth call with the Nth
+// reply. Calls past the last reply fail.
+type Provider struct {
+	mu       sync.Mutex
+	replies  []Reply
+	requests []ai.Context
+}
+
+// NewProvider creates a provider scripted with replies.
+func NewProvider(replies ...Reply) *Provider {
+	return &Provider{replies: replies}
+}
+
+// Api returns ai.ApiAnthropic, so model-specific paths behave as for Claude.
+func (p *Provider) Api() ai.Api { return ai.ApiAnthropic }
+
+// Stream answers the call with the next reply.
+func (p *Provider) Stream(_ context.Context, _ *ai.Model, llmCtx *ai.Context, _ *ai.StreamOptions) *ai.EventStream {
+	stream := ai.NewEventStream(16)
+
+	p.mu.Lock()
+	idx := len(p.requests)
+	var snapshot ai.Context
+	if llmCtx != nil {
+		snapshot = *llmCtx
+		snapshot.Messages = append([]ai.Message(nil), llmCtx.Messages...)
+	}
+	p.requests = append(p.requests, snapshot)
+	p.mu.Unlock()
+
+	go func() {
+		if idx >= len(p.replies) {
+			stream.FinishWithError(fmt.Errorf("agenttest: no reply scripted for call %d", idx+1))
+			return
+		}
+		r := p.replies[idx]
+		if r.Err != nil {
+			stream.FinishWithError(r.Err)
+			return
+		}
+
+		msg := &ai.AssistantMessage{StopReason: ai.StopEndTurn, Usage: r.Usage}
+		if r.Thinking != "" {
+			stream.Send(ai.StreamEvent{Type: ai.EventThinkingDelta, Text: r.Thinking})
+			msg.Content = append(msg.Content, ai.Content{Type: ai.ContentThinking, Thinking: r.Thinking})
+		}
+		if r.Text != "" {
+			stream.Send(ai.StreamEvent{Type: ai.EventContentDelta, Text: r.Text})
+			msg.Content = append(msg.Content, ai.Content{Type: ai.ContentText, Text: r.Text})
+		}
+		for i, tc := range r.ToolCalls {
+			input, err := json.Marshal(tc.Args)
+			if err != nil {
+				stream.FinishWithError(fmt.Errorf("agenttest: tool call %s: %w", tc.Name, err))
+				return
+			}
+			if tc.Args == nil {
+				input = []byte("{}")
+			}
+			id := fmt.Sprintf("call_%d_%d", idx+1, i+1)
+			stream.Send(ai.StreamEvent{Type: ai.EventToolUseStart, ToolID: id, ToolName: tc.Name})
+			msg.Content = append(msg.Content, ai.Content{Type: ai.ContentToolUse, ID: id, Name: tc.Name, Input: input})
+			msg.StopReason = ai.StopToolUse
+		}
+		stream.Finish(msg)
+	}()
+
+	return stream
+}
+
+// Requests returns a copy of the context of every call so far, in order.
+func (p *Provider) Requests() []ai.Context {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]ai.Context(nil), p.requests...)
+}
+
+// CallCount returns the number of calls so far.
+func (p *Provider) CallCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.requests)
+}
+
+// Remaining returns how many scripted replies were not used.
+func (p *Provider) Remaining() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return max(0, len(p.replies)-len(p.requests))
+}
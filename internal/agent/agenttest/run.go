@@ -0,0 +1,244 @@
+// ABOUTME: Harness running the real agent loop in-process against a scripted provider and fake tools
+// ABOUTME: A Run collects every emitted event and offers queries and testing.TB assertions over them
+
+package agenttest
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+// Timeout bounds one Run; a loop still running then fails the test.
+const Timeout = 10 * time.Second
+
+// Model returns the model harnesses use: tool-capable, without images or
+// thinking.
+func Model() *ai.Model {
+	return &ai.Model{ID: "agenttest-model", Name: "agenttest", Api: ai.ApiAnthropic, MaxTokens: 200000, MaxOutputTokens: 8192, SupportsTools: true}
+}
+
+// Harness is an agent wired to a scripted provider and fake tools. Agent
+// is exposed so tests can configure it further (adaptive compaction,
+// reminders, a minion) before running it.
+type Harness struct {
+	Provider *Provider
+	Agent    *agent.Agent
+	Model    *ai.Model
+}
+
+// New creates a harness around provider and tools. permCheck is nilable.
+func New(provider *Provider, permCheck agent.PermCheckFunc, tools ...*Tool) *Harness {
+	model := Model()
+	defs := make([]*agent.AgentTool, len(tools))
+	for i, t := range tools {
+		defs[i] = t.AgentTool()
+	}
+	return &Harness{
+		Provider: provider,
+		Agent:    agent.NewWithPermissions(provider, model, defs, permCheck),
+		Model:    model,
+	}
+}
+
+// Run prompts the agent with a user message and waits for the loop to end.
+func (h *Harness) Run(t testing.TB, prompt string) *Run {
+	t.Helper()
+	llmCtx := &ai.Context{Messages: []ai.Message{ai.NewTextMessage(ai.RoleUser, prompt)}}
+	return h.RunContext(t, context.Background(), llmCtx)
+}
+
+// RunContext runs the agent on llmCtx until the loop ends, failing t when
+// it outlasts Timeout. The agent appends to llmCtx as it goes.
+func (h *Harness) RunContext(t testing.TB, ctx context.Context, llmCtx *ai.Context) *Run {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+
+	run := &Run{Context: llmCtx}
+	events := h.Agent.Prompt(ctx, llmCtx, &ai.StreamOptions{})
+	for {
+		select {
+		case evt, ok := <-events:
+			if !ok {
+				return run
+			}
+			run.Events = append(run.Events, evt)
+		case <-ctx.Done():
+			h.Agent.Abort()
+			t.Fatalf("agenttest: agent loop still running after %s; events so far: %s", Timeout, run)
+			return run
+		}
+	}
+}
+
+// Run is the outcome of one agent loop.
+type Run struct {
+	// Events are every event the loop emitted, in order.
+	Events []agent.AgentEvent
+	// Context is the conversation after the loop, including the assistant
+	// messages and tool results it appended.
+	Context *ai.Context
+}
+
+// Of returns the events of the given types, in order.
+func (r *Run) Of(types ...agent.AgentEventType) []agent.AgentEvent {
+	var out []agent.AgentEvent
+	for _, e := range r.Events {
+		if slices.Contains(types, e.Type) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// Text returns the streamed assistant text.
+func (r *Run) Text() string {
+	var b strings.Builder
+	for _, e := range r.Of(agent.EventAssistantText) {
+		b.WriteString(e.Text)
+	}
+	return b.String()
+}
+
+// ToolCalls returns the names of the tools that started, in order. Calls
+// denied by the permission check end without starting.
+func (r *Run) ToolCalls() []string {
+	var names []string
+	for _, e := range r.Of(agent.EventToolStart) {
+		names = append(names, e.ToolName)
+	}
+	return names
+}
+
+// Results returns the results of every finished call to the named tool,
+// denied calls included.
+func (r *Run) Results(tool string) []*agent.ToolResult {
+	var out []*agent.ToolResult
+	for _, e := range r.Of(agent.EventToolEnd) {
+		if e.ToolName == tool {
+			out = append(out, e.ToolResult)
+		}
+	}
+	return out
+}
+
+// Errors returns the errors the loop emitted.
+func (r *Run) Errors() []error {
+	var out []error
+	for _, e := range r.Of(agent.EventError) {
+		out = append(out, e.Error)
+	}
+	return out
+}
+
+// Usage returns the token usage summed over every LLM call.
+func (r *Run) Usage() ai.Usage {
+	var u ai.Usage
+	for _, e := range r.Of(agent.EventUsageUpdate) {
+		u.InputTokens += e.Usage.InputTokens
+		u.OutputTokens += e.Usage.OutputTokens
+		u.CacheRead += e.Usage.CacheRead
+		u.CacheCreate += e.Usage.CacheCreate
+	}
+	return u
+}
+
+// AssertNoErrors fails t when the loop emitted an error.
+func (r *Run) AssertNoErrors(t testing.TB) {
+	t.Helper()
+	if errs := r.Errors(); len(errs) > 0 {
+		t.Errorf("agent emitted %d error(s); first: %v", len(errs), errs[0])
+	}
+}
+
+// AssertText fails t unless the streamed text equals want.
+func (r *Run) AssertText(t testing.TB, want string) {
+	t.Helper()
+	if got := r.Text(); got != want {
+		t.Errorf("assistant text = %q; want %q", got, want)
+	}
+}
+
+// AssertToolCalls fails t unless exactly the named tools started, in order.
+func (r *Run) AssertToolCalls(t testing.TB, names ...string) {
+	t.Helper()
+	if got := r.ToolCalls(); !slices.Equal(got, names) {
+		t.Errorf("tool calls = %v; want %v", got, names)
+	}
+}
+
+// AssertResult fails t unless the last result of the named tool contains
+// substr and has the given error flag.
+func (r *Run) AssertResult(t testing.TB, tool, substr string, isError bool) {
+	t.Helper()
+	results := r.Results(tool)
+	if len(results) == 0 {
+		t.Errorf("no result for tool %s", tool)
+		return
+	}
+	res := results[len(results)-1]
+	if !strings.Contains(res.Content, substr) || res.IsError != isError {
+		t.Errorf("%s result = %q (error %v); want it to contain %q (error %v)", tool, res.Content, res.IsError, substr, isError)
+	}
+}
+
+// AssertEventOrder fails t unless the events of the given types occur in
+// that order. Other events may come in between.
+func (r *Run) AssertEventOrder(t testing.TB, types ...agent.AgentEventType) {
+	t.Helper()
+	i := 0
+	for _, e := range r.Events {
+		if i < len(types) && e.Type == types[i] {
+			i++
+		}
+	}
+	if i < len(types) {
+		t.Errorf("event %s not found in order; events: %s", eventName(types[i]), r)
+	}
+}
+
+// String lists the event types, for failure messages.
+func (r *Run) String() string {
+	names := make([]string, len(r.Events))
+	for i, e := range r.Events {
+		names[i] = eventName(e.Type)
+		if e.ToolName != "" {
+			names[i] += "(" + e.ToolName + ")"
+		}
+	}
+	return "[" + strings.Join(names, " ") + "]"
+}
+
+// eventName names an event type.
+func eventName(t agent.AgentEventType) string {
+	switch t {
+	case agent.EventAgentStart:
+		return "start"
+	case agent.EventAgentEnd:
+		return "end"
+	case agent.EventAssistantText:
+		return "text"
+	case agent.EventAssistantThinking:
+		return "thinking"
+	case agent.EventToolStart:
+		return "tool_start"
+	case agent.EventToolUpdate:
+		return "tool_update"
+	case agent.EventToolEnd:
+		return "tool_end"
+	case agent.EventUsageUpdate:
+		return "usage"
+	case agent.EventModelRoute:
+		return "route"
+	case agent.EventError:
+		return "error"
+	}
+	return fmt.Sprintf("event(%d)", int(t))
+}
@@ -0,0 +1,105 @@
+// ABOUTME: Scripted fake tools: each call returns the next canned result and is recorded with its arguments
+// ABOUTME: Results can fail, stream updates, or block until released to test ordering and cancellation
+
+package agenttest
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
+)
+
+// Result is the canned outcome of one tool call.
+type Result struct {
+	Content string
+	IsError bool
+	// Err is returned from Execute; the agent reports it as an error result.
+	Err error
+	// Updates are sent through onUpdate before the result.
+	Updates []string
+	// Wait, when set, blocks the call until it is closed or the call's
+	// context ends.
+	Wait <-chan struct{}
+}
+
+// Returns is a successful result with content.
+func Returns(content string) Result { return Result{Content: content} }
+
+// Errors is an error result with message.
+func Errors(message string) Result { return Result{Content: message, IsError: true} }
+
+// Tool is a fake tool. The Nth call returns the Nth result; once they run
+// out, the last result repeats. Without results a call returns "ok".
+type Tool struct {
+	Name     string
+	ReadOnly bool
+
+	mu      sync.Mutex
+	results []Result
+	calls   []map[string]any
+}
+
+// NewTool creates a tool scripted with results.
+func NewTool(name string, results ...Result) *Tool {
+	return &Tool{Name: name, results: results}
+}
+
+// NewReadOnlyTool creates a read-only tool, which the agent runs
+// concurrently with other read-only calls.
+func NewReadOnlyTool(name string, results ...Result) *Tool {
+	return &Tool{Name: name, ReadOnly: true, results: results}
+}
+
+// AgentTool returns the tool definition to hand to an agent.
+func (t *Tool) AgentTool() *agent.AgentTool {
+	return &agent.AgentTool{
+		Name:        t.Name,
+		Label:       t.Name,
+		Description: "agenttest fake tool " + t.Name,
+		Parameters:  []byte(`{"type":"object"}`),
+		ReadOnly:    t.ReadOnly,
+		Execute:     t.execute,
+	}
+}
+
+func (t *Tool) execute(ctx context.Context, _ string, params map[string]any, onUpdate func(agent.ToolUpdate)) (agent.ToolResult, error) {
+	t.mu.Lock()
+	n := len(t.calls)
+	t.calls = append(t.calls, params)
+	r := Result{Content: "ok"}
+	if len(t.results) > 0 {
+		r = t.results[min(n, len(t.results)-1)]
+	}
+	t.mu.Unlock()
+
+	for _, u := range r.Updates {
+		onUpdate(agent.ToolUpdate{Output: u})
+	}
+	if r.Wait != nil {
+		select {
+		case <-r.Wait:
+		case <-ctx.Done():
+			return agent.ToolResult{}, errors.Join(errors.New("agenttest: tool cancelled"), ctx.Err())
+		}
+	}
+	if r.Err != nil {
+		return agent.ToolResult{}, r.Err
+	}
+	return agent.ToolResult{Content: r.Content, IsError: r.IsError}, nil
+}
+
+// Calls returns the arguments of every call so far, in order.
+func (t *Tool) Calls() []map[string]any {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]map[string]any(nil), t.calls...)
+}
+
+// CallCount returns the number of calls so far.
+func (t *Tool) CallCount() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.calls)
+}
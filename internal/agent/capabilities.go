@@ -0,0 +1,184 @@
+// ABOUTME: Capability gating: shapes each LLM call to what the model declares it can handle
+// ABOUTME: Prompts tools for text-calling models, drops them for tool-less ones, trims schemas, and replaces images
+
+package agent
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+// coreTools are kept first when a model takes fewer tools than are
+// registered: with them the agent can still read, change, and search code.
+var coreTools = []string{"read", "edit", "write", "bash", "grep", "find", "ls"}
+
+// schemaKeys are the JSON Schema keywords a simplified schema keeps.
+var schemaKeys = []string{"type", "properties", "required", "items", "enum", "description"}
+
+// forModel returns llmCtx as model can take it. A model calling tools in
+// text gets them described in the system prompt and its history in the
+// same form; otherwise a model without tool support gets no tools. One
+// with MaxToolSchemas gets that many, core tools first, with simplified
+// schemas; one without image support gets a note in place of each image.
+// llmCtx itself is returned, untouched, when nothing needs to change, and
+// is never modified.
+func forModel(model *ai.Model, llmCtx *ai.Context) *ai.Context {
+	adapted := *llmCtx
+	changed := false
+	switch {
+	case model.TextToolCalls:
+		tools := llmCtx.Tools
+		if model.MaxToolSchemas > 0 {
+			tools = limitTools(tools, model.MaxToolSchemas)
+		}
+		if len(tools) > 0 {
+			adapted.System = strings.TrimSpace(llmCtx.System + "\n\n" + textToolPrompt(tools))
+		}
+		adapted.Tools = nil
+		adapted.Messages = textToolMessages(llmCtx.Messages)
+		changed = true
+	case !model.SupportsTools && len(llmCtx.Tools) > 0:
+		adapted.Tools = nil
+		changed = true
+	case model.SupportsTools && model.MaxToolSchemas > 0 && len(llmCtx.Tools) > 0:
+		adapted.Tools = limitTools(llmCtx.Tools, model.MaxToolSchemas)
+		changed = true
+	}
+	if !model.SupportsImages {
+		if msgs, ok := withoutImages(adapted.Messages, model.Name); ok {
+			adapted.Messages = msgs
+			changed = true
+		}
+	}
+	if !changed {
+		return llmCtx
+	}
+	return &adapted
+}
+
+// limitTools returns at most n of tools, core tools first and the rest in
+// their given order, each with a simplified schema.
+func limitTools(tools []ai.Tool, n int) []ai.Tool {
+	ordered := make([]ai.Tool, 0, len(tools))
+	for _, name := range coreTools {
+		if i := slices.IndexFunc(tools, func(t ai.Tool) bool { return t.Name == name }); i >= 0 {
+			ordered = append(ordered, tools[i])
+		}
+	}
+	for _, t := range tools {
+		if !slices.Contains(coreTools, t.Name) {
+			ordered = append(ordered, t)
+		}
+	}
+	ordered = ordered[:min(n, len(ordered))]
+	for i := range ordered {
+		ordered[i].Description = firstSentence(ordered[i].Description)
+		ordered[i].Parameters = simplifySchema(ordered[i].Parameters)
+	}
+	return ordered
+}
+
+// simplifySchema keeps the keywords a small model needs to fill in a call,
+// the types, properties, required list, item types, and enums, with
+// descriptions cut to their first sentence. A union takes its first
+// alternative. A schema that does not parse is returned as is.
+func simplifySchema(raw json.RawMessage) json.RawMessage {
+	var schema map[string]any
+	if len(raw) == 0 || json.Unmarshal(raw, &schema) != nil {
+		return raw
+	}
+	out, err := json.Marshal(simplifyNode(schema))
+	if err != nil {
+		return raw
+	}
+	return out
+}
+
+func simplifyNode(node map[string]any) map[string]any {
+	if _, typed := node["type"]; !typed {
+		for _, union := range []string{"anyOf", "oneOf"} {
+			if alts, ok := node[union].([]any); ok && len(alts) > 0 {
+				if first, ok := alts[0].(map[string]any); ok {
+					return simplifyNode(first)
+				}
+			}
+		}
+	}
+	out := make(map[string]any, len(schemaKeys))
+	for _, key := range schemaKeys {
+		v, ok := node[key]
+		if !ok {
+			continue
+		}
+		switch key {
+		case "properties":
+			props, _ := v.(map[string]any)
+			simple := make(map[string]any, len(props))
+			for name, p := range props {
+				if pm, ok := p.(map[string]any); ok {
+					simple[name] = simplifyNode(pm)
+				}
+			}
+			v = simple
+		case "items":
+			if im, ok := v.(map[string]any); ok {
+				v = simplifyNode(im)
+			}
+		case "description":
+			s, _ := v.(string)
+			v = firstSentence(s)
+		}
+		out[key] = v
+	}
+	return out
+}
+
+// firstSentence returns s up to the end of its first sentence or line.
+func firstSentence(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	if i := strings.Index(s, ". "); i >= 0 {
+		s = s[:i+1]
+	}
+	return strings.TrimSpace(s)
+}
+
+// withoutImages returns a copy of msgs with each image block, and each
+// image attached to a tool result, replaced by a note; false when msgs has
+// no images.
+func withoutImages(msgs []ai.Message, modelName string) ([]ai.Message, bool) {
+	hasImage := func(m ai.Message) bool {
+		return slices.ContainsFunc(m.Content, func(c ai.Content) bool {
+			return c.Type == ai.ContentImage || len(c.Images) > 0
+		})
+	}
+	if !slices.ContainsFunc(msgs, hasImage) {
+		return nil, false
+	}
+	note := "[image omitted: " + modelName + " does not accept images]"
+	out := make([]ai.Message, len(msgs))
+	for i, m := range msgs {
+		out[i] = m
+		if !hasImage(m) {
+			continue
+		}
+		content := make([]ai.Content, 0, len(m.Content))
+		for _, c := range m.Content {
+			switch {
+			case c.Type == ai.ContentImage:
+				c = ai.Content{Type: ai.ContentText, Text: note}
+			case len(c.Images) > 0:
+				c.Images = nil
+				c.ResultText = strings.TrimSpace(c.ResultText + "\n" + note)
+			}
+			content = append(content, c)
+		}
+		out[i].Content = content
+	}
+	return out, true
+}
@@ -0,0 +1,120 @@
+// ABOUTME: Tests for capability gating: dropped tools, trimmed and simplified schemas, and replaced images
+// ABOUTME: Checks that the caller's context is never modified
+
+package agent
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+func testTools(names ...string) []ai.Tool {
+	tools := make([]ai.Tool, len(names))
+	for i, name := range names {
+		tools[i] = ai.Tool{
+			Name:        name,
+			Description: "Does " + name + ". Long details follow here.",
+			Parameters: json.RawMessage(`{"type":"object","$schema":"http://json-schema.org/draft-07/schema#",
+				"properties":{"path":{"type":"string","description":"The file. Absolute or relative.","default":"."},
+				"mode":{"anyOf":[{"type":"string","enum":["a","b"]},{"type":"null"}]}},
+				"required":["path"],"additionalProperties":false}`),
+		}
+	}
+	return tools
+}
+
+func TestForModel_FullModelUnchanged(t *testing.T) {
+	t.Parallel()
+	model := &ai.Model{SupportsTools: true, SupportsImages: true}
+	llmCtx := &ai.Context{Tools: testTools("read", "bash")}
+	if got := forModel(model, llmCtx); got != llmCtx {
+		t.Error("a model without limits should get the context as is")
+	}
+}
+
+func TestForModel_NoTools(t *testing.T) {
+	t.Parallel()
+	model := &ai.Model{SupportsImages: true}
+	llmCtx := &ai.Context{Tools: testTools("read")}
+	got := forModel(model, llmCtx)
+	if len(got.Tools) != 0 {
+		t.Errorf("Tools = %v; want none", got.Tools)
+	}
+	if len(llmCtx.Tools) != 1 {
+		t.Error("the caller's tools were modified")
+	}
+}
+
+func TestForModel_MaxToolSchemas(t *testing.T) {
+	t.Parallel()
+	model := &ai.Model{SupportsTools: true, SupportsImages: true, MaxToolSchemas: 3}
+	llmCtx := &ai.Context{Tools: testTools("mcp_search", "webfetch", "bash", "read", "edit")}
+	got := forModel(model, llmCtx)
+
+	var names []string
+	for _, tool := range got.Tools {
+		names = append(names, tool.Name)
+	}
+	if strings.Join(names, ",") != "read,edit,bash" {
+		t.Errorf("tools = %v; want the core tools first", names)
+	}
+	if d := got.Tools[0].Description; d != "Does read." {
+		t.Errorf("Description = %q; want the first sentence", d)
+	}
+
+	var schema map[string]any
+	if err := json.Unmarshal(got.Tools[0].Parameters, &schema); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"$schema", "additionalProperties"} {
+		if _, ok := schema[key]; ok {
+			t.Errorf("simplified schema keeps %s", key)
+		}
+	}
+	props := schema["properties"].(map[string]any)
+	path := props["path"].(map[string]any)
+	if _, ok := path["default"]; ok || path["description"] != "The file." {
+		t.Errorf("path = %v; want type and first sentence only", path)
+	}
+	if mode := props["mode"].(map[string]any); mode["type"] != "string" || mode["enum"] == nil {
+		t.Errorf("mode = %v; want the first anyOf alternative", mode)
+	}
+
+	if llmCtx.Tools[0].Name != "mcp_search" || !strings.Contains(string(llmCtx.Tools[3].Parameters), "$schema") {
+		t.Error("the caller's tools were modified")
+	}
+}
+
+func TestForModel_NoImages(t *testing.T) {
+	t.Parallel()
+	model := &ai.Model{Name: "tiny", SupportsTools: true}
+	llmCtx := &ai.Context{Messages: []ai.Message{
+		ai.NewTextMessage(ai.RoleUser, "plain"),
+		{Role: ai.RoleUser, Content: []ai.Content{
+			{Type: ai.ContentText, Text: "what is this?"},
+			{Type: ai.ContentImage, MediaType: "image/png", Data: "aGk="},
+		}},
+		{Role: ai.RoleUser, Content: []ai.Content{
+			{Type: ai.ContentToolResult, ID: "t1", ResultText: "screenshot", Images: []ai.ImageContent{{MediaType: "image/png", Data: "aGk="}}},
+		}},
+	}}
+	got := forModel(model, llmCtx)
+
+	if c := got.Messages[1].Content[1]; c.Type != ai.ContentText || !strings.Contains(c.Text, "tiny does not accept images") {
+		t.Errorf("image block = %+v; want a note", c)
+	}
+	if c := got.Messages[2].Content[0]; len(c.Images) != 0 || !strings.HasPrefix(c.ResultText, "screenshot\n[image omitted") {
+		t.Errorf("tool result = %+v; want the images replaced by a note", c)
+	}
+	if llmCtx.Messages[1].Content[1].Type != ai.ContentImage || len(llmCtx.Messages[2].Content[0].Images) != 1 {
+		t.Error("the caller's messages were modified")
+	}
+
+	plain := &ai.Context{Messages: llmCtx.Messages[:1]}
+	if forModel(model, plain) != plain {
+		t.Error("messages without images need no copy")
+	}
+}
@@ -0,0 +1,167 @@
+// ABOUTME: Plural minion fan-out: one instruction mapped over many files by parallel minion sub-agents
+// ABOUTME: Reports progress to a hook for the background view; the main model reduces the per-file results
+
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// defaultFanOutParallel bounds concurrent minion sub-tasks when Parallel is unset.
+const defaultFanOutParallel = 4
+
+// fanOutMaxTurns caps each per-file sub-agent; one file rarely needs more.
+const fanOutMaxTurns = 8
+
+// fanOutTools are the only tools a per-file minion may use.
+var fanOutTools = []string{"read", "edit", "write", "grep", "find", "ls"}
+
+var fanOutCounter atomic.Int64
+
+// ErrNotPlural is returned by FanOut when the minion is not in plural mode.
+var ErrNotPlural = errors.New("minion is not in plural mode")
+
+// FanOutProgress is a snapshot of a running fan-out, sent after each sub-task.
+type FanOutProgress struct {
+	ID          string
+	Instruction string
+	Total       int
+	Done        int // finished sub-tasks, including failed ones
+	Failed      int
+	File        string // file whose sub-task just finished; empty on start
+	Finished    bool
+}
+
+// FanOutResult is the outcome of one per-file sub-task.
+type FanOutResult struct {
+	File string
+	Text string // the minion's summary of its change
+	Err  error
+}
+
+// SetFanOutHook installs fn to receive fan-out progress. Safe to call while
+// fan-outs run; nil removes the hook.
+func (m *Minion) SetFanOutHook(fn func(FanOutProgress)) {
+	if fn == nil {
+		m.fanOut.Store(nil)
+		return
+	}
+	m.fanOut.Store(&fn)
+}
+
+func (m *Minion) reportFanOut(p FanOutProgress) {
+	if fn := m.fanOut.Load(); fn != nil {
+		(*fn)(p)
+	}
+}
+
+// FanOut applies instruction to each file with its own minion sub-agent,
+// running up to Parallel at once. Results keep the order of files. Each
+// sub-agent sees only its file and the file tools from tools, and inherits
+// the permission checker carried by ctx.
+func (m *Minion) FanOut(ctx context.Context, instruction string, files []string, tools []*AgentTool, onProgress func(FanOutProgress)) ([]FanOutResult, error) {
+	if m.Mode() != MinionPlural {
+		return nil, ErrNotPlural
+	}
+	parallel := m.Parallel
+	if parallel <= 0 {
+		parallel = defaultFanOutParallel
+	}
+
+	progress := FanOutProgress{ID: fmt.Sprintf("fan-%d", fanOutCounter.Add(1)), Instruction: instruction, Total: len(files)}
+	// Reports are serialized so hooks see Done increase monotonically.
+	var mu sync.Mutex
+	report := func(update func(*FanOutProgress)) {
+		mu.Lock()
+		defer mu.Unlock()
+		update(&progress)
+		m.reportFanOut(progress)
+		if onProgress != nil {
+			onProgress(progress)
+		}
+	}
+	report(func(*FanOutProgress) {})
+
+	deps := SpawnDeps{Provider: m.Provider, Model: m.Model, AllTools: tools}
+	results := make([]FanOutResult, len(files))
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i, file := range files {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				results[i] = FanOutResult{File: file, Err: ctx.Err()}
+				report(func(p *FanOutProgress) { p.Done++; p.Failed++; p.File = file })
+				return
+			}
+			results[i] = m.runFanOutTask(ctx, instruction, file, deps)
+			report(func(p *FanOutProgress) {
+				p.Done++
+				if results[i].Err != nil {
+					p.Failed++
+				}
+				p.File = file
+			})
+		}()
+	}
+	wg.Wait()
+
+	report(func(p *FanOutProgress) { p.Finished = true; p.File = "" })
+	return results, nil
+}
+
+// runFanOutTask runs one foreground minion sub-agent scoped to file.
+func (m *Minion) runFanOutTask(ctx context.Context, instruction, file string, deps SpawnDeps) FanOutResult {
+	cfg := SubAgentConfig{
+		Name: "minion",
+		SystemPrompt: fmt.Sprintf("You are a minion working on exactly one file: %s. "+
+			"Read it, apply the instruction to that file only, and never modify other files. "+
+			"Finish with one short paragraph describing what you changed, or why you changed nothing.", file),
+		Tools:    fanOutTools,
+		MaxTurns: fanOutMaxTurns,
+	}
+	handle, err := Spawn(ctx, cfg, fmt.Sprintf("File: %s\nInstruction: %s", file, instruction), deps)
+	if err != nil {
+		return FanOutResult{File: file, Err: err}
+	}
+	<-handle.Done
+	res := handle.Result()
+	if res == nil {
+		return FanOutResult{File: file, Err: errors.New("minion returned no result")}
+	}
+	return FanOutResult{File: file, Text: strings.TrimSpace(res.Text), Err: res.Error}
+}
+
+// FormatFanOutResults renders per-file results for the main model to review.
+func FormatFanOutResults(results []FanOutResult) string {
+	var b strings.Builder
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+		}
+	}
+	fmt.Fprintf(&b, "Minions processed %d files (%d failed). Review each change before answering: "+
+		"read or diff the files, fix mistakes, and redo failed files yourself.\n", len(results), failed)
+	for _, r := range results {
+		status := "ok"
+		if r.Err != nil {
+			status = "failed: " + r.Err.Error()
+		}
+		fmt.Fprintf(&b, "\n## %s (%s)\n", r.File, status)
+		if r.Text != "" {
+			b.WriteString(r.Text)
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,89 @@
+// ABOUTME: Tests for plural minion fan-out: mode gating, result order, progress, and failures
+// ABOUTME: Uses the shared mock provider; each per-file sub-agent consumes one response
+
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+func TestMinionFanOut_RequiresPlural(t *testing.T) {
+	t.Parallel()
+	m := NewMinion(&mockProvider{}, newMinionModel(), MinionAuto)
+	if _, err := m.FanOut(context.Background(), "add docs", []string{"a.go"}, nil, nil); !errors.Is(err, ErrNotPlural) {
+		t.Fatalf("err = %v, want ErrNotPlural", err)
+	}
+}
+
+func TestMinionFanOut_MapsFilesAndReportsProgress(t *testing.T) {
+	t.Parallel()
+	mini := &mockProvider{responses: []*ai.AssistantMessage{endTurn("done"), endTurn("done"), endTurn("done")}}
+	m := NewMinion(mini, newMinionModel(), MinionPlural)
+	m.Parallel = 2
+
+	var mu sync.Mutex
+	var hooked []FanOutProgress
+	m.SetFanOutHook(func(p FanOutProgress) {
+		mu.Lock()
+		hooked = append(hooked, p)
+		mu.Unlock()
+	})
+	var direct int
+	files := []string{"a.go", "b.go", "c.go"}
+	results, err := m.FanOut(context.Background(), "add docs", files, nil, func(FanOutProgress) { direct++ })
+	if err != nil {
+		t.Fatalf("FanOut() error = %v", err)
+	}
+
+	for i, r := range results {
+		if r.File != files[i] || r.Err != nil || r.Text != "done" {
+			t.Errorf("results[%d] = %+v", i, r)
+		}
+	}
+	// start + one per file + finish
+	if len(hooked) != 5 || direct != 5 {
+		t.Fatalf("progress reports: hook %d, callback %d; want 5", len(hooked), direct)
+	}
+	last := hooked[len(hooked)-1]
+	if !last.Finished || last.Done != 3 || last.Failed != 0 || last.Total != 3 {
+		t.Errorf("final progress = %+v", last)
+	}
+	if mini.callCount.Load() != 3 {
+		t.Errorf("minion called %d times, want 3", mini.callCount.Load())
+	}
+}
+
+func TestMinionFanOut_FailedFilesAreReported(t *testing.T) {
+	t.Parallel()
+	mini := &mockProvider{responses: []*ai.AssistantMessage{endTurn("done")}}
+	m := NewMinion(mini, newMinionModel(), MinionPlural)
+	m.Parallel = 1
+
+	results, err := m.FanOut(context.Background(), "add docs", []string{"a.go", "b.go"}, nil, nil)
+	if err != nil {
+		t.Fatalf("FanOut() error = %v", err)
+	}
+	// Sub-tasks start in any order; exactly one runs out of mock responses.
+	if (results[0].Err == nil) == (results[1].Err == nil) {
+		t.Fatalf("results = %+v, want exactly one failed file", results)
+	}
+}
+
+func TestFormatFanOutResults(t *testing.T) {
+	t.Parallel()
+	out := FormatFanOutResults([]FanOutResult{
+		{File: "a.go", Text: "Added docs."},
+		{File: "b.go", Err: errors.New("boom")},
+	})
+	for _, want := range []string{"2 files (1 failed)", "## a.go (ok)\nAdded docs.", "## b.go (failed: boom)", "Review each change"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("FormatFanOutResults missing %q:\n%s", want, out)
+		}
+	}
+}
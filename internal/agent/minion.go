@@ -0,0 +1,132 @@
+// ABOUTME: Minion transform: routes eligible LLM calls to a cheaper model, escalating to the main one
+// ABOUTME: Mode (off/auto/force/plural) can change mid-session; each routing decision is reported as an event
+
+package agent
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/session"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+// MinionMode controls how LLM calls are routed between the minion and the main model.
+type MinionMode int32
+
+const (
+	MinionOff    MinionMode = iota // every call goes to the main model
+	MinionAuto                     // minion serves calls until a turn needs escalation
+	MinionForce                    // minion serves every call; escalates only if it fails
+	MinionPlural                   // like auto, plus per-file fan-out to parallel minions
+)
+
+// defaultMinionMaxInputTokens is the context size above which auto mode escalates.
+const defaultMinionMaxInputTokens = 16000
+
+// String returns the mode name.
+func (m MinionMode) String() string {
+	switch m {
+	case MinionAuto:
+		return "auto"
+	case MinionForce:
+		return "force"
+	case MinionPlural:
+		return "plural"
+	default:
+		return "off"
+	}
+}
+
+// ParseMinionMode parses "off", "auto", "force", or "plural" (case-insensitive).
+func ParseMinionMode(s string) (MinionMode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "off":
+		return MinionOff, nil
+	case "auto", "on":
+		return MinionAuto, nil
+	case "force":
+		return MinionForce, nil
+	case "plural":
+		return MinionPlural, nil
+	}
+	return MinionOff, fmt.Errorf("unknown minion mode %q (want off, auto, force, or plural)", s)
+}
+
+// ModelRoute records which model serves an LLM call and why.
+type ModelRoute struct {
+	Model  string // display name of the serving model
+	Minion bool   // true when the minion serves the call
+	Reason string // e.g. "auto", "forced", "context 20K > 16K", "minion failed: ..."
+}
+
+// Minion is a cheaper model that handles simple calls on behalf of the main model.
+// It is safe to change the mode while an agent loop is running.
+type Minion struct {
+	Provider       ai.ApiProvider
+	Model          *ai.Model
+	MaxInputTokens int // auto mode escalates above this estimate; 0 uses the default
+	Parallel       int // concurrent fan-out sub-tasks in plural mode; 0 uses the default
+
+	mode   atomic.Int32
+	fanOut atomic.Pointer[func(FanOutProgress)]
+}
+
+// NewMinion creates a minion in the given mode.
+func NewMinion(provider ai.ApiProvider, model *ai.Model, mode MinionMode) *Minion {
+	m := &Minion{Provider: provider, Model: model}
+	m.SetMode(mode)
+	return m
+}
+
+// Mode returns the current routing mode; a nil minion is always off.
+func (m *Minion) Mode() MinionMode {
+	if m == nil {
+		return MinionOff
+	}
+	return MinionMode(m.mode.Load())
+}
+
+// SetMode changes the routing mode; it takes effect on the next LLM call.
+func (m *Minion) SetMode(mode MinionMode) {
+	m.mode.Store(int32(mode))
+}
+
+// route decides whether the minion should serve a call with the given context.
+// escalated is true once an earlier call in the same turn went to the main model.
+func (m *Minion) route(msgs []ai.Message, escalated bool) (bool, string) {
+	switch m.Mode() {
+	case MinionOff:
+		return false, "minion off"
+	case MinionForce:
+		return true, "forced"
+	}
+	if escalated {
+		return false, "escalated earlier this turn"
+	}
+	limit := m.MaxInputTokens
+	if limit <= 0 {
+		limit = defaultMinionMaxInputTokens
+	}
+	if tokens := session.EstimateMessagesTokens(msgs); tokens > limit {
+		return false, fmt.Sprintf("context %d tokens > %d", tokens, limit)
+	}
+	if lastToolResultFailed(msgs) {
+		return false, "tool error"
+	}
+	return true, "auto"
+}
+
+// lastToolResultFailed reports whether the most recent message carries a failed tool result.
+func lastToolResultFailed(msgs []ai.Message) bool {
+	if len(msgs) == 0 {
+		return false
+	}
+	for _, c := range msgs[len(msgs)-1].Content {
+		if c.Type == ai.ContentToolResult && c.IsError {
+			return true
+		}
+	}
+	return false
+}
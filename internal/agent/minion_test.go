@@ -0,0 +1,138 @@
+// ABOUTME: Tests for the minion transform: mode parsing, routing rules, and escalation events
+// ABOUTME: Uses mock providers to verify which model serves each LLM call
+
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+func newMinionModel() *ai.Model {
+	return &ai.Model{ID: "mini-model", Name: "Mini", Api: ai.ApiAnthropic, SupportsTools: true}
+}
+
+func endTurn(text string) *ai.AssistantMessage {
+	return &ai.AssistantMessage{Content: []ai.Content{{Type: ai.ContentText, Text: text}}, StopReason: ai.StopEndTurn}
+}
+
+func routeEvents(events []AgentEvent) []ModelRoute {
+	var out []ModelRoute
+	for _, e := range events {
+		if e.Type == EventModelRoute && e.Route != nil {
+			out = append(out, *e.Route)
+		}
+	}
+	return out
+}
+
+func TestParseMinionMode(t *testing.T) {
+	t.Parallel()
+	for in, want := range map[string]MinionMode{"off": MinionOff, "AUTO": MinionAuto, "on": MinionAuto, " force ": MinionForce, "Plural": MinionPlural} {
+		got, err := ParseMinionMode(in)
+		if err != nil || got != want {
+			t.Errorf("ParseMinionMode(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := ParseMinionMode("sometimes"); err == nil {
+		t.Error("expected error for unknown mode")
+	}
+}
+
+func TestMinionRoute_Rules(t *testing.T) {
+	t.Parallel()
+	m := NewMinion(nil, newMinionModel(), MinionAuto)
+	m.MaxInputTokens = 50
+
+	small := []ai.Message{ai.NewTextMessage(ai.RoleUser, "hi")}
+	if ok, reason := m.route(small, false); !ok || reason != "auto" {
+		t.Errorf("small context: route = %v %q, want minion/auto", ok, reason)
+	}
+	if ok, _ := m.route(small, true); ok {
+		t.Error("auto should stay on the main model after escalating in the same turn")
+	}
+
+	big := []ai.Message{ai.NewTextMessage(ai.RoleUser, strings.Repeat("x", 1000))}
+	if ok, reason := m.route(big, false); ok || !strings.HasPrefix(reason, "context") {
+		t.Errorf("big context: route = %v %q, want main/context", ok, reason)
+	}
+
+	failed := []ai.Message{{Role: ai.RoleUser, Content: []ai.Content{{Type: ai.ContentToolResult, ID: "t1", IsError: true}}}}
+	if ok, reason := m.route(failed, false); ok || reason != "tool error" {
+		t.Errorf("tool error: route = %v %q, want main/tool error", ok, reason)
+	}
+
+	m.SetMode(MinionForce)
+	if ok, _ := m.route(big, true); !ok {
+		t.Error("force mode should route every call to the minion")
+	}
+	m.SetMode(MinionOff)
+	if ok, _ := m.route(small, false); ok {
+		t.Error("off mode should never route to the minion")
+	}
+}
+
+func TestAgent_MinionServesTurn(t *testing.T) {
+	t.Parallel()
+	main := &mockProvider{responses: []*ai.AssistantMessage{endTurn("from main")}}
+	mini := &mockProvider{responses: []*ai.AssistantMessage{endTurn("from minion")}}
+
+	ag := New(main, newTestModel(), nil)
+	ag.SetMinion(NewMinion(mini, newMinionModel(), MinionAuto))
+	events := collectEvents(ag.Prompt(context.Background(), newTestContext(), &ai.StreamOptions{}))
+
+	routes := routeEvents(events)
+	if len(routes) != 1 || !routes[0].Minion || routes[0].Model != "Mini" {
+		t.Fatalf("routes = %+v, want one minion route", routes)
+	}
+	if main.callCount.Load() != 0 {
+		t.Errorf("main model called %d times, want 0", main.callCount.Load())
+	}
+}
+
+func TestAgent_MinionFailureEscalates(t *testing.T) {
+	t.Parallel()
+	main := &mockProvider{responses: []*ai.AssistantMessage{endTurn("from main")}}
+	mini := &mockProvider{} // no responses: every call fails
+
+	ag := New(main, newTestModel(), nil)
+	ag.SetMinion(NewMinion(mini, newMinionModel(), MinionForce))
+	events := collectEvents(ag.Prompt(context.Background(), newTestContext(), &ai.StreamOptions{}))
+
+	for _, e := range events {
+		if e.Type == EventError {
+			t.Errorf("minion failure should not surface as an error: %v", e.Error)
+		}
+	}
+	routes := routeEvents(events)
+	if len(routes) != 2 || !routes[0].Minion || routes[1].Minion {
+		t.Fatalf("routes = %+v, want minion then main", routes)
+	}
+	if !strings.HasPrefix(routes[1].Reason, "minion failed") {
+		t.Errorf("escalation reason = %q", routes[1].Reason)
+	}
+	if main.callCount.Load() != 1 {
+		t.Errorf("main model called %d times, want 1", main.callCount.Load())
+	}
+}
+
+func TestAgent_MinionOffReportsMain(t *testing.T) {
+	t.Parallel()
+	main := &mockProvider{responses: []*ai.AssistantMessage{endTurn("from main")}}
+	mini := &mockProvider{responses: []*ai.AssistantMessage{endTurn("from minion")}}
+
+	ag := New(main, newTestModel(), nil)
+	ag.SetMinion(NewMinion(mini, newMinionModel(), MinionOff))
+	events := collectEvents(ag.Prompt(context.Background(), newTestContext(), &ai.StreamOptions{}))
+
+	routes := routeEvents(events)
+	if len(routes) != 1 || routes[0].Minion || routes[0].Reason != "minion off" {
+		t.Fatalf("routes = %+v, want one main route with reason 'minion off'", routes)
+	}
+	if mini.callCount.Load() != 0 {
+		t.Errorf("minion called %d times, want 0", mini.callCount.Load())
+	}
+}
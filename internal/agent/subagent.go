@@ -48,12 +48,39 @@ type SpawnDeps struct {
 	Provider ai.ApiProvider
 	Model    *ai.Model
 	AllTools []*AgentTool
+
+	// ResolveModel maps a definition's model field to a model and provider.
+	// Nil means sub-agents always run on the parent model.
+	ResolveModel func(name string) (*ai.Model, ai.ApiProvider, error)
+}
+
+type permCheckKey struct{}
+
+// WithPermCheck returns a context carrying the calling agent's permission
+// checker, so sub-agents spawned from a tool are held to the same rules.
+func WithPermCheck(ctx context.Context, fn PermCheckFunc) context.Context {
+	return context.WithValue(ctx, permCheckKey{}, fn)
+}
+
+// PermCheckFrom returns the permission checker stored by WithPermCheck, or nil.
+func PermCheckFrom(ctx context.Context) PermCheckFunc {
+	fn, _ := ctx.Value(permCheckKey{}).(PermCheckFunc)
+	return fn
 }
 
 // Spawn creates and runs a sub-agent with isolated context.
 func Spawn(ctx context.Context, cfg SubAgentConfig, prompt string, deps SpawnDeps) (*SubAgentHandle, error) {
 	tools := filterTools(deps.AllTools, cfg.Tools, cfg.DisallowedTools)
 
+	model, provider := deps.Model, deps.Provider
+	if cfg.Model != "" && cfg.Model != ModelInherit && deps.ResolveModel != nil {
+		m, p, err := deps.ResolveModel(cfg.Model)
+		if err != nil {
+			return nil, fmt.Errorf("resolving model %q: %w", cfg.Model, err)
+		}
+		model, provider = m, p
+	}
+
 	system := cfg.SystemPrompt
 	if system == "" {
 		system = fmt.Sprintf("You are %s. %s", cfg.Name, cfg.Description)
@@ -71,7 +98,7 @@ func Spawn(ctx context.Context, cfg SubAgentConfig, prompt string, deps SpawnDep
 		MaxTokens: 4096,
 	}
 
-	ag := New(deps.Provider, deps.Model, tools)
+	ag := NewWithPermissions(provider, model, tools, PermCheckFrom(ctx))
 
 	done := make(chan struct{})
 	handle := &SubAgentHandle{
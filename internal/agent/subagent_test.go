@@ -4,9 +4,15 @@
 package agent
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 	"testing"
+
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
 )
 
 func TestSubAgentConfig_Defaults(t *testing.T) {
@@ -219,3 +225,122 @@ func TestParseAgentFrontmatter(t *testing.T) {
 		t.Errorf("unexpected body: %q", def.SystemPrompt)
 	}
 }
+
+func TestSpawn_InheritsPermCheckFromContext(t *testing.T) {
+	t.Parallel()
+
+	provider := &mockProvider{
+		responses: []*ai.AssistantMessage{
+			{
+				Content:    []ai.Content{{Type: ai.ContentToolUse, ID: "t1", Name: "bash", Input: json.RawMessage(`{"command":"rm -rf /"}`)}},
+				StopReason: ai.StopToolUse,
+			},
+			{
+				Content:    []ai.Content{{Type: ai.ContentText, Text: "blocked"}},
+				StopReason: ai.StopEndTurn,
+			},
+		},
+	}
+	var executed atomic.Bool
+	bash := &AgentTool{
+		Name: "bash",
+		Execute: func(context.Context, string, map[string]any, func(ToolUpdate)) (ToolResult, error) {
+			executed.Store(true)
+			return ToolResult{Content: "ran"}, nil
+		},
+	}
+	var checked atomic.Bool
+	ctx := WithPermCheck(context.Background(), func(tool string, _ map[string]any) error {
+		checked.Store(true)
+		return fmt.Errorf("tool %q denied", tool)
+	})
+
+	handle, err := Spawn(ctx, SubAgentConfig{Name: "worker", MaxTurns: 3}, "clean up", SpawnDeps{
+		Provider: provider,
+		Model:    newTestModel(),
+		AllTools: []*AgentTool{bash},
+	})
+	if err != nil {
+		t.Fatalf("Spawn: %v", err)
+	}
+	<-handle.Done
+	if !checked.Load() {
+		t.Error("parent permission check was not applied to the sub-agent")
+	}
+	if executed.Load() {
+		t.Error("denied tool executed in sub-agent")
+	}
+}
+
+func TestSpawn_ResolvesDefinitionModel(t *testing.T) {
+	t.Parallel()
+
+	parent := &mockProvider{}
+	child := &mockProvider{
+		responses: []*ai.AssistantMessage{
+			{Content: []ai.Content{{Type: ai.ContentText, Text: "from child model"}}, StopReason: ai.StopEndTurn},
+		},
+	}
+	var asked string
+	deps := SpawnDeps{
+		Provider: parent,
+		Model:    newTestModel(),
+		ResolveModel: func(name string) (*ai.Model, ai.ApiProvider, error) {
+			asked = name
+			return &ai.Model{ID: "child", Name: "Child", Api: ai.ApiAnthropic}, child, nil
+		},
+	}
+
+	handle, err := Spawn(context.Background(), SubAgentConfig{Name: "w", Model: "powerful"}, "go", deps)
+	if err != nil {
+		t.Fatalf("Spawn: %v", err)
+	}
+	<-handle.Done
+	if asked != "powerful" {
+		t.Errorf("ResolveModel asked %q; want powerful", asked)
+	}
+	if got := handle.Result().Text; got != "from child model" {
+		t.Errorf("result = %q; want child provider output", got)
+	}
+	if parent.callCount.Load() != 0 {
+		t.Error("parent provider should not be used when the model resolves")
+	}
+}
+
+func TestSpawn_InheritModelSkipsResolver(t *testing.T) {
+	t.Parallel()
+
+	provider := &mockProvider{
+		responses: []*ai.AssistantMessage{
+			{Content: []ai.Content{{Type: ai.ContentText, Text: "ok"}}, StopReason: ai.StopEndTurn},
+		},
+	}
+	deps := SpawnDeps{
+		Provider: provider,
+		Model:    newTestModel(),
+		ResolveModel: func(string) (*ai.Model, ai.ApiProvider, error) {
+			t.Error("resolver should not be called for inherit")
+			return nil, nil, nil
+		},
+	}
+	handle, err := Spawn(context.Background(), SubAgentConfig{Name: "w", Model: ModelInherit}, "go", deps)
+	if err != nil {
+		t.Fatalf("Spawn: %v", err)
+	}
+	<-handle.Done
+}
+
+func TestSpawn_UnresolvableModelFails(t *testing.T) {
+	t.Parallel()
+
+	deps := SpawnDeps{
+		Provider: &mockProvider{},
+		Model:    newTestModel(),
+		ResolveModel: func(name string) (*ai.Model, ai.ApiProvider, error) {
+			return nil, nil, fmt.Errorf("unknown model %q", name)
+		},
+	}
+	if _, err := Spawn(context.Background(), SubAgentConfig{Name: "w", Model: "nope"}, "go", deps); err == nil {
+		t.Error("expected error for unresolvable model")
+	}
+}
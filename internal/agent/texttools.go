@@ -0,0 +1,253 @@
+// ABOUTME: Textual (ReAct-style) tool calling for models without native function calling
+// ABOUTME: Describes tools in the system prompt and parses fenced tool blocks out of the reply text
+
+package agent
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+// maxMalformedRounds is how many replies in a row may consist only of tool
+// calls that cannot be parsed before the loop gives up.
+const maxMalformedRounds = 3
+
+// toolFence matches a fenced block tagged tool or tool_call. The closing
+// fence is optional: a model may stop right after the call.
+var toolFence = regexp.MustCompile("(?ms)^[ \t]*```[ \t]*(?i:tool|tool_call)[ \t]*\n(.*?)(?:^[ \t]*```[ \t]*$|\\z)")
+
+// textToolPrompt returns the system prompt section describing tools for a
+// model that calls them in text.
+func textToolPrompt(tools []ai.Tool) string {
+	var b strings.Builder
+	b.WriteString(`# Tools
+
+You can call the tools below. To call one, write a fenced block tagged tool holding a JSON object with the tool's name and arguments, then stop and wait:
+
+` + "```tool" + `
+{"name": "read", "arguments": {"path": "main.go"}}
+` + "```" + `
+
+Write one call per block; several blocks run in order. Do not write the result yourself: it comes back in the next message. When you need no tool, answer normally without a tool block.
+
+`)
+	for _, t := range tools {
+		fmt.Fprintf(&b, "## %s\n%s\nArguments: %s\n\n", t.Name, strings.TrimSpace(t.Description), compactJSON(t.Parameters))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// textToolMessages rewrites tool calls and results in msgs as the text a
+// textual model writes and reads, so the history matches the protocol and
+// carries no blocks the provider would expect tool schemas for.
+func textToolMessages(msgs []ai.Message) []ai.Message {
+	names := make(map[string]string)
+	out := make([]ai.Message, len(msgs))
+	for i, m := range msgs {
+		out[i] = m
+		var content []ai.Content
+		changed := false
+		for _, c := range m.Content {
+			switch c.Type {
+			case ai.ContentToolUse:
+				changed = true
+				if c.Name == "" {
+					continue // malformed: the block is still in the reply text
+				}
+				names[c.ID] = c.Name
+				call, _ := json.Marshal(map[string]any{"name": c.Name, "arguments": json.RawMessage(orEmpty(c.Input))})
+				content = append(content, ai.Content{Type: ai.ContentText, Text: "```tool\n" + string(call) + "\n```"})
+			case ai.ContentToolResult:
+				changed = true
+				label := "Result"
+				if c.IsError {
+					label = "Error"
+				}
+				if name := names[c.ID]; name != "" {
+					label += " of " + name
+				}
+				content = append(content, ai.Content{Type: ai.ContentText, Text: label + ":\n" + c.ResultText})
+			default:
+				content = append(content, c)
+			}
+		}
+		if changed {
+			out[i].Content = content
+		}
+	}
+	return out
+}
+
+// textToolCalls turns the tool blocks in msg's text into tool-use content.
+// Parsed blocks leave the text; a block that does not parse stays in it and
+// becomes a nameless call whose Text says what is wrong, which the loop
+// returns to the model as an error. Text after the last call is dropped:
+// it can only be a guess at the result.
+func textToolCalls(msg *ai.AssistantMessage) *ai.AssistantMessage {
+	var content, calls []ai.Content
+	n := 0
+	for _, c := range msg.Content {
+		if c.Type != ai.ContentText {
+			content = append(content, c)
+			continue
+		}
+		blocks := toolFence.FindAllStringSubmatchIndex(c.Text, -1)
+		if len(blocks) == 0 {
+			content = append(content, c)
+			continue
+		}
+		var kept strings.Builder
+		prev := 0
+		for _, blk := range blocks {
+			kept.WriteString(c.Text[prev:blk[0]])
+			prev = blk[1]
+			parsed, err := parseTextToolCall(c.Text[blk[2]:blk[3]])
+			if err != nil {
+				kept.WriteString(c.Text[blk[0]:blk[1]])
+				n++
+				calls = append(calls, ai.Content{
+					Type: ai.ContentToolUse,
+					ID:   fmt.Sprintf("text_call_%d", n),
+					Text: fmt.Sprintf(`malformed tool block: %v. Write a fenced tool block holding {"name": "<tool>", "arguments": {...}} as valid JSON.`, err),
+				})
+				continue
+			}
+			for _, call := range parsed {
+				n++
+				call.ID = fmt.Sprintf("text_call_%d", n)
+				calls = append(calls, call)
+			}
+		}
+		if text := strings.TrimSpace(kept.String()); text != "" {
+			content = append(content, ai.Content{Type: ai.ContentText, Text: text})
+		}
+	}
+	if len(calls) == 0 {
+		return msg
+	}
+	out := *msg
+	out.Content = append(content, calls...)
+	out.StopReason = ai.StopToolUse
+	return &out
+}
+
+// parseTextToolCall parses the body of one tool block: an object, or an
+// array of objects, naming the tool under name, tool, or function and its
+// arguments under arguments, args, input, or parameters. Arguments may be
+// an object or a JSON string holding one. Trailing commas are tolerated.
+func parseTextToolCall(body string) ([]ai.Content, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, errors.New("the block is empty")
+	}
+	var raw any
+	if err := json.Unmarshal([]byte(body), &raw); err != nil {
+		if err2 := json.Unmarshal(dropTrailingCommas([]byte(body)), &raw); err2 != nil {
+			return nil, fmt.Errorf("invalid JSON: %v", err)
+		}
+	}
+	objs, ok := raw.([]any)
+	if !ok {
+		objs = []any{raw}
+	}
+	calls := make([]ai.Content, 0, len(objs))
+	for _, o := range objs {
+		obj, ok := o.(map[string]any)
+		if !ok {
+			return nil, errors.New("want a JSON object")
+		}
+		name := firstString(obj, "name", "tool", "function")
+		if name == "" {
+			return nil, errors.New(`the object has no "name"`)
+		}
+		var args any = map[string]any{}
+		for _, key := range []string{"arguments", "args", "input", "parameters"} {
+			if v, ok := obj[key]; ok && v != nil {
+				args = v
+				break
+			}
+		}
+		if s, ok := args.(string); ok {
+			var parsed map[string]any
+			if err := json.Unmarshal([]byte(s), &parsed); err != nil {
+				return nil, fmt.Errorf("arguments of %s are not a JSON object", name)
+			}
+			args = parsed
+		}
+		if _, ok := args.(map[string]any); !ok {
+			return nil, fmt.Errorf("arguments of %s are not a JSON object", name)
+		}
+		input, err := json.Marshal(args)
+		if err != nil {
+			return nil, err
+		}
+		calls = append(calls, ai.Content{Type: ai.ContentToolUse, Name: name, Input: input})
+	}
+	return calls, nil
+}
+
+// firstString returns the first of keys in obj holding a non-empty string.
+func firstString(obj map[string]any, keys ...string) string {
+	for _, key := range keys {
+		if s, ok := obj[key].(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// dropTrailingCommas removes commas directly before a closing bracket,
+// outside strings.
+func dropTrailingCommas(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	inString := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case inString:
+			if c == '\\' && i+1 < len(data) {
+				out = append(out, c, data[i+1])
+				i++
+				continue
+			}
+			if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == '}' || c == ']':
+			k := len(out) - 1
+			for k >= 0 && strings.ContainsRune(" \t\r\n", rune(out[k])) {
+				k--
+			}
+			if k >= 0 && out[k] == ',' {
+				out = append(out[:k], out[k+1:]...)
+			}
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// compactJSON returns raw without insignificant whitespace, or "{}" when
+// it is empty or invalid.
+func compactJSON(raw json.RawMessage) string {
+	var buf bytes.Buffer
+	if len(raw) == 0 || json.Compact(&buf, raw) != nil {
+		return "{}"
+	}
+	return buf.String()
+}
+
+func orEmpty(raw json.RawMessage) json.RawMessage {
+	if len(raw) == 0 || !json.Valid(raw) {
+		return json.RawMessage(`{}`)
+	}
+	return raw
+}
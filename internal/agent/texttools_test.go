@@ -0,0 +1,208 @@
+// ABOUTME: Tests for textual tool calling: the prompt section, block parsing, history rewriting, and the loop
+// ABOUTME: A recording provider checks what a text-calling model is sent on each turn
+
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+func TestParseTextToolCall(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name, body, want string
+	}{
+		{"canonical", `{"name": "read", "arguments": {"path": "a.go"}}`, `read{"path":"a.go"}`},
+		{"aliases", `{"tool": "ls", "args": {"path": "."}}`, `ls{"path":"."}`},
+		{"string arguments", `{"name": "bash", "arguments": "{\"command\": \"go test\"}"}`, `bash{"command":"go test"}`},
+		{"no arguments", `{"name": "ls"}`, `ls{}`},
+		{"trailing commas", "{\"name\": \"grep\", \"arguments\": {\"pattern\": \"a,}\",},}", `grep{"pattern":"a,}"}`},
+		{"array", `[{"name": "read", "input": {"path": "a"}}, {"function": "read", "parameters": {"path": "b"}}]`, `read{"path":"a"}read{"path":"b"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			calls, err := parseTextToolCall(tt.body)
+			if err != nil {
+				t.Fatalf("parseTextToolCall() error = %v", err)
+			}
+			var got strings.Builder
+			for _, c := range calls {
+				got.WriteString(c.Name + string(c.Input))
+			}
+			if got.String() != tt.want {
+				t.Errorf("got %s, want %s", got.String(), tt.want)
+			}
+		})
+	}
+
+	for _, body := range []string{``, `{"name": "read", "arguments": {"path": }`, `{"arguments": {}}`, `"read"`, `{"name": "read", "arguments": [1]}`} {
+		if _, err := parseTextToolCall(body); err == nil {
+			t.Errorf("parseTextToolCall(%q): expected error", body)
+		}
+	}
+}
+
+func TestTextToolCalls(t *testing.T) {
+	t.Parallel()
+	msg := &ai.AssistantMessage{Content: []ai.Content{{Type: ai.ContentText, Text: "Let me look.\n" +
+		"```tool\n{\"name\": \"read\", \"arguments\": {\"path\": \"a.go\"}}\n```\n" +
+		"```tool\n{\"name\": \"read\", oops}\n```\n" +
+		"Result: package a"}}, StopReason: ai.StopEndTurn}
+
+	got := textToolCalls(msg)
+	if got.StopReason != ai.StopToolUse || len(got.Content) != 3 {
+		t.Fatalf("content = %+v", got.Content)
+	}
+	text := got.Content[0].Text
+	if !strings.HasPrefix(text, "Let me look.") || strings.Contains(text, `"path"`) || !strings.Contains(text, "oops") {
+		t.Errorf("text = %q; want the parsed block removed and the malformed one kept", text)
+	}
+	if strings.Contains(text, "Result: package a") {
+		t.Error("text after the last block is a guessed result and should be dropped")
+	}
+	if c := got.Content[1]; c.Name != "read" || c.ID == "" || string(c.Input) != `{"path":"a.go"}` {
+		t.Errorf("call = %+v", c)
+	}
+	if c := got.Content[2]; c.Name != "" || !strings.Contains(c.Text, "malformed tool block") {
+		t.Errorf("malformed call = %+v", c)
+	}
+
+	plain := &ai.AssistantMessage{Content: []ai.Content{{Type: ai.ContentText, Text: "```go\nfmt.Println()\n```"}}}
+	if textToolCalls(plain) != plain {
+		t.Error("a reply without tool blocks should be returned as is")
+	}
+
+	unclosed := &ai.AssistantMessage{Content: []ai.Content{{Type: ai.ContentText, Text: "```tool\n{\"name\": \"ls\"}"}}}
+	if got := textToolCalls(unclosed); len(got.Content) != 1 || got.Content[0].Name != "ls" {
+		t.Errorf("unclosed block: content = %+v", got.Content)
+	}
+}
+
+func TestForModel_TextToolCalls(t *testing.T) {
+	t.Parallel()
+	model := &ai.Model{TextToolCalls: true, SupportsImages: true}
+	llmCtx := &ai.Context{
+		System: "Be helpful.",
+		Tools:  testTools("read"),
+		Messages: []ai.Message{
+			ai.NewTextMessage(ai.RoleUser, "show a.go"),
+			{Role: ai.RoleAssistant, Content: []ai.Content{{Type: ai.ContentToolUse, ID: "c1", Name: "read", Input: json.RawMessage(`{"path":"a.go"}`)}}},
+			{Role: ai.RoleUser, Content: []ai.Content{{Type: ai.ContentToolResult, ID: "c1", ResultText: "package a"}}},
+		},
+	}
+	got := forModel(model, llmCtx)
+
+	if len(got.Tools) != 0 {
+		t.Error("a text-calling model should get no tool schemas")
+	}
+	if !strings.HasPrefix(got.System, "Be helpful.\n\n# Tools") || !strings.Contains(got.System, "## read\nDoes read.") {
+		t.Errorf("System = %q", got.System)
+	}
+	if c := got.Messages[1].Content[0]; c.Type != ai.ContentText || c.Text != "```tool\n{\"arguments\":{\"path\":\"a.go\"},\"name\":\"read\"}\n```" {
+		t.Errorf("tool call in history = %+v", c)
+	}
+	if c := got.Messages[2].Content[0]; c.Type != ai.ContentText || c.Text != "Result of read:\npackage a" {
+		t.Errorf("tool result in history = %+v", c)
+	}
+	if llmCtx.Messages[1].Content[0].Type != ai.ContentToolUse || llmCtx.System != "Be helpful." {
+		t.Error("the caller's context was modified")
+	}
+}
+
+// recordingProvider replays responses and records the context of each call.
+type recordingProvider struct {
+	mu        sync.Mutex
+	responses []string
+	seen      []*ai.Context
+}
+
+func (p *recordingProvider) Api() ai.Api { return ai.ApiOpenAI }
+
+func (p *recordingProvider) Stream(_ context.Context, _ *ai.Model, llmCtx *ai.Context, _ *ai.StreamOptions) *ai.EventStream {
+	p.mu.Lock()
+	idx := len(p.seen)
+	p.seen = append(p.seen, llmCtx)
+	p.mu.Unlock()
+	stream := ai.NewEventStream(4)
+	go func() {
+		text := "done"
+		if idx < len(p.responses) {
+			text = p.responses[idx]
+		}
+		stream.Send(ai.StreamEvent{Type: ai.EventContentDelta, Text: text})
+		stream.Finish(&ai.AssistantMessage{Content: []ai.Content{{Type: ai.ContentText, Text: text}}, StopReason: ai.StopEndTurn})
+	}()
+	return stream
+}
+
+func TestAgent_TextToolCalls(t *testing.T) {
+	t.Parallel()
+	provider := &recordingProvider{responses: []string{
+		"```tool\n{\"name\": \"read\", \"arguments\": {\"path\": \"a.go\"}}\n```",
+		"It declares package a.",
+	}}
+	var gotPath any
+	readTool := &AgentTool{
+		Name: "read", Description: "Read a file.", ReadOnly: true,
+		Parameters: json.RawMessage(`{"type":"object","properties":{"path":{"type":"string"}}}`),
+		Execute: func(_ context.Context, _ string, params map[string]any, _ func(ToolUpdate)) (ToolResult, error) {
+			gotPath = params["path"]
+			return ToolResult{Content: "package a"}, nil
+		},
+	}
+	model := &ai.Model{ID: "local", Name: "local", TextToolCalls: true}
+	llmCtx := newTestContext()
+	llmCtx.Tools = aiTools(map[string]*AgentTool{"read": readTool})
+
+	ag := New(provider, model, []*AgentTool{readTool})
+	for evt := range ag.Prompt(context.Background(), llmCtx, &ai.StreamOptions{}) {
+		if evt.Type == EventError {
+			t.Fatalf("error event: %v", evt.Error)
+		}
+	}
+
+	if gotPath != "a.go" {
+		t.Errorf("read ran with path %v; want a.go", gotPath)
+	}
+	if len(provider.seen) != 2 {
+		t.Fatalf("%d calls; want 2", len(provider.seen))
+	}
+	second := provider.seen[1]
+	last := second.Messages[len(second.Messages)-1]
+	if len(second.Tools) != 0 || last.Content[0].Text != "Result of read:\npackage a" {
+		t.Errorf("second call: tools=%d last message=%+v", len(second.Tools), last)
+	}
+}
+
+func TestAgent_TextToolCallsGiveUpOnMalformed(t *testing.T) {
+	t.Parallel()
+	bad := "```tool\n{\"name\": \"read\", \"arguments\": {\"path\": }\n```"
+	provider := &recordingProvider{responses: []string{bad, bad, bad, bad, bad, bad}}
+	model := &ai.Model{ID: "local", Name: "local", TextToolCalls: true}
+
+	ag := New(provider, model, nil)
+	var lastErr error
+	for evt := range ag.Prompt(context.Background(), newTestContext(), &ai.StreamOptions{}) {
+		if evt.Type == EventError {
+			lastErr = evt.Error
+		}
+	}
+	if lastErr == nil || !strings.Contains(lastErr.Error(), "malformed") {
+		t.Errorf("error = %v; want the loop to give up on malformed calls", lastErr)
+	}
+	if n := len(provider.seen); n != maxMalformedRounds+1 {
+		t.Errorf("%d calls; want %d", n, maxMalformedRounds+1)
+	}
+	// Each retry shows the model what was wrong.
+	retry := provider.seen[1].Messages
+	if !strings.Contains(retry[len(retry)-1].Content[0].Text, "malformed tool block") {
+		t.Errorf("retry message = %+v", retry[len(retry)-1])
+	}
+}
@@ -12,6 +12,7 @@ import (
 type AgentTool = types.AgentTool
 type ToolResult = types.ToolResult
 type ToolUpdate = types.ToolUpdate
+type ToolStep = types.ToolStep
 
 // AgentEventType identifies the kind of agent event emitted during execution.
 type AgentEventType int
@@ -25,6 +26,7 @@ const (
 	EventToolUpdate                             // Incremental tool output
 	EventToolEnd                                // Tool execution completed
 	EventUsageUpdate                            // Token usage stats from LLM response
+	EventModelRoute                             // Which model serves the next LLM call (minion routing)
 	EventError                                  // Non-recoverable error
 )
 
@@ -36,7 +38,9 @@ type AgentEvent struct {
 	ToolName   string
 	ToolArgs   map[string]any
 	ToolResult *ToolResult
+	ToolStep   *ToolStep // composite tool step, on EventToolUpdate
 	Usage      *ai.Usage
+	Route      *ModelRoute
 	Error      error
 }
 
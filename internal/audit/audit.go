@@ -0,0 +1,164 @@
+// ABOUTME: Append-only audit log: one JSON line per tool call and permission decision
+// ABOUTME: Arguments are recorded as a hash, never in the clear; entries can also go to syslog
+
+package audit
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Event kinds.
+const (
+	EventPermission = "permission"
+	EventTool       = "tool"
+)
+
+// Entry is one line of the audit log.
+type Entry struct {
+	Time     time.Time `json:"ts"`
+	Session  string    `json:"session"`
+	Event    string    `json:"event"` // EventPermission or EventTool
+	Tool     string    `json:"tool"`
+	CallID   string    `json:"callId,omitempty"`
+	ArgsHash string    `json:"argsHash"` // "sha256:" and the hex digest of the arguments as JSON
+
+	// Permission decisions.
+	Decision string `json:"decision,omitempty"` // "allow" or "deny"
+	Source   string `json:"source,omitempty"`   // "rule", "mode", or "user"
+	Reason   string `json:"reason,omitempty"`   // why a call was denied
+
+	// Tool calls.
+	Status     string `json:"status,omitempty"` // "ok", "error", or "failed" when the call itself failed
+	DurationMS int64  `json:"durationMs,omitempty"`
+}
+
+// Logger appends entries to a file opened in append-only mode, and to
+// syslog when configured. A nil Logger records nothing. It is safe for
+// concurrent use.
+type Logger struct {
+	mu      sync.Mutex
+	f       *os.File
+	syslog  io.WriteCloser
+	session string
+	now     func() time.Time
+}
+
+// Open opens, creating if needed, the audit log at path. dest forwards each
+// entry to syslog as well: "local" for the local daemon, or
+// "udp://host:port" or "tcp://host:port" for a remote one; "" for none.
+// Until SetSession names the session, entries carry a random run ID.
+func Open(path, dest string) (*Logger, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("creating audit log directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("opening audit log: %w", err)
+	}
+	l := &Logger{f: f, session: runID(), now: time.Now}
+	if dest != "" {
+		w, err := dialSyslog(dest)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("audit syslog %s: %w", dest, err)
+		}
+		l.syslog = w
+	}
+	return l, nil
+}
+
+// SetSession names the session later entries belong to.
+func (l *Logger) SetSession(id string) {
+	if l == nil || id == "" {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.session = id
+}
+
+// Permission records a permission decision for a call of tool with args.
+func (l *Logger) Permission(tool string, args map[string]any, allowed bool, source, reason string) {
+	decision := "deny"
+	if allowed {
+		decision = "allow"
+	}
+	l.write(Entry{Event: EventPermission, Tool: tool, ArgsHash: HashArgs(args), Decision: decision, Source: source, Reason: reason})
+}
+
+// Tool records a finished tool call. status is "ok", "error" for a call
+// that returned an error result, or "failed" when the tool could not run.
+func (l *Logger) Tool(id, tool string, args map[string]any, status string, took time.Duration) {
+	l.write(Entry{Event: EventTool, Tool: tool, CallID: id, ArgsHash: HashArgs(args), Status: status, DurationMS: took.Milliseconds()})
+}
+
+// write stamps e and appends it. A failed write is reported on stderr: an
+// audit trail must not fail the call it records, nor go quietly missing.
+func (l *Logger) write(e Entry) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e.Time = l.now().UTC()
+	e.Session = l.session
+	line, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+	if _, err := l.f.Write(line); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: audit log: %v\n", err)
+	}
+	if l.syslog != nil {
+		if _, err := l.syslog.Write(line[:len(line)-1]); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: audit syslog: %v\n", err)
+		}
+	}
+}
+
+// Close flushes and closes the log.
+func (l *Logger) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.syslog != nil {
+		l.syslog.Close()
+	}
+	if err := l.f.Sync(); err != nil {
+		l.f.Close()
+		return err
+	}
+	return l.f.Close()
+}
+
+// HashArgs returns "sha256:" and the hex SHA-256 of args as JSON. Map keys
+// marshal sorted, so equal arguments hash alike.
+func HashArgs(args map[string]any) string {
+	data, err := json.Marshal(args)
+	if err != nil {
+		data = []byte(fmt.Sprint(args))
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// runID returns an ID for entries written before a session is known.
+func runID() string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "run"
+	}
+	return "run-" + hex.EncodeToString(b)
+}
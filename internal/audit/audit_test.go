@@ -0,0 +1,124 @@
+// ABOUTME: Tests for the audit log: JSONL entries, argument hashing, append-only reopening, and sessions
+// ABOUTME: Uses t.TempDir; syslog forwarding is only checked for bad destinations
+
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readEntries(t *testing.T, path string) []Entry {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("bad line %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func TestLogger_WritesEntries(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "logs", "audit.jsonl")
+
+	l, err := Open(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.now = func() time.Time { return time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC) }
+	args := map[string]any{"command": "cat secrets.txt"}
+	l.Permission("bash", args, true, "user", "")
+	l.SetSession("sess-1")
+	l.Tool("call-1", "bash", args, "error", 1500*time.Millisecond)
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := readEntries(t, path)
+	if len(entries) != 2 {
+		t.Fatalf("entries = %+v; want 2", entries)
+	}
+	p, c := entries[0], entries[1]
+	if p.Event != EventPermission || p.Decision != "allow" || p.Source != "user" || !strings.HasPrefix(p.Session, "run-") {
+		t.Errorf("permission entry = %+v", p)
+	}
+	if c.Event != EventTool || c.Session != "sess-1" || c.CallID != "call-1" || c.Status != "error" || c.DurationMS != 1500 {
+		t.Errorf("tool entry = %+v", c)
+	}
+	if p.ArgsHash != c.ArgsHash || !strings.HasPrefix(p.ArgsHash, "sha256:") {
+		t.Errorf("hashes %q and %q should match", p.ArgsHash, c.ArgsHash)
+	}
+	if !c.Time.Equal(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("Time = %v", c.Time)
+	}
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "secrets.txt") {
+		t.Error("arguments should be hashed, not logged")
+	}
+	if info, err := os.Stat(path); err == nil && runtime.GOOS != "windows" && info.Mode().Perm()&0o077 != 0 {
+		t.Errorf("audit log mode = %v; want owner-only", info.Mode().Perm())
+	}
+}
+
+func TestLogger_Appends(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	for range 2 {
+		l, err := Open(path, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		l.Tool("id", "read", nil, "ok", 0)
+		l.Close()
+	}
+	if entries := readEntries(t, path); len(entries) != 2 {
+		t.Errorf("entries = %d; want both runs kept", len(entries))
+	}
+}
+
+func TestHashArgs_Stable(t *testing.T) {
+	t.Parallel()
+	a := HashArgs(map[string]any{"path": "a.go", "limit": 10})
+	b := HashArgs(map[string]any{"limit": 10, "path": "a.go"})
+	if a != b {
+		t.Errorf("equal arguments hash differently: %s vs %s", a, b)
+	}
+	if a == HashArgs(map[string]any{"path": "b.go", "limit": 10}) {
+		t.Error("different arguments should hash differently")
+	}
+}
+
+func TestLogger_Nil(t *testing.T) {
+	t.Parallel()
+	var l *Logger
+	l.SetSession("s")
+	l.Permission("bash", nil, false, "mode", "plan mode")
+	l.Tool("id", "bash", nil, "ok", 0)
+	if err := l.Close(); err != nil {
+		t.Errorf("Close on nil = %v", err)
+	}
+}
+
+func TestOpen_BadSyslog(t *testing.T) {
+	t.Parallel()
+	if _, err := Open(filepath.Join(t.TempDir(), "audit.jsonl"), "carrier-pigeon"); err == nil {
+		t.Error("an unknown syslog destination should fail")
+	}
+}
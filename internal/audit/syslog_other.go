@@ -0,0 +1,16 @@
+// ABOUTME: Syslog forwarding stub for platforms without log/syslog, such as Windows
+// ABOUTME: The JSONL audit file works everywhere; only forwarding is unavailable
+
+//go:build !unix
+
+package audit
+
+import (
+	"errors"
+	"io"
+)
+
+// dialSyslog reports that syslog is unavailable on this platform.
+func dialSyslog(string) (io.WriteCloser, error) {
+	return nil, errors.New("syslog is not supported on this platform")
+}
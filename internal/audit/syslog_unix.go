@@ -0,0 +1,26 @@
+// ABOUTME: Unix syslog forwarding for the audit log, local or over UDP/TCP
+// ABOUTME: Entries go out at LOG_AUTHPRIV|LOG_INFO tagged pi-go
+
+//go:build unix
+
+package audit
+
+import (
+	"fmt"
+	"io"
+	"log/syslog"
+	"strings"
+)
+
+// dialSyslog connects to the syslog destination dest.
+func dialSyslog(dest string) (io.WriteCloser, error) {
+	const priority = syslog.LOG_AUTHPRIV | syslog.LOG_INFO
+	if dest == "local" {
+		return syslog.New(priority, "pi-go")
+	}
+	network, addr, ok := strings.Cut(dest, "://")
+	if !ok || (network != "udp" && network != "tcp") || addr == "" {
+		return nil, fmt.Errorf("want local, udp://host:port, or tcp://host:port")
+	}
+	return syslog.Dial(network, addr, priority, "pi-go")
+}
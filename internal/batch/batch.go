@@ -0,0 +1,109 @@
+// ABOUTME: Built-in batch templates for unattended runs: `pi-go run --template <name>`
+// ABOUTME: Templates are embedded prompts rendered with the working directory, date, and variables
+
+package batch
+
+import (
+	"embed"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+)
+
+//go:embed templates/*.md
+var templatesFS embed.FS
+
+// Template is a built-in batch prompt.
+type Template struct {
+	Name        string
+	Description string
+	// Defaults are the template variables a caller may override with Vars.
+	Defaults map[string]string
+}
+
+// builtins lists the shipped templates; each has templates/<name>.md.
+var builtins = map[string]Template{
+	"repo-health": {
+		Name:        "repo-health",
+		Description: "Markdown report of failing tests, outdated dependencies, TODO/FIXME aging, and large files",
+		Defaults: map[string]string{
+			"TODO_LIMIT":    "10",
+			"TODO_AGE_DAYS": "180",
+			"LARGE_FILE_KB": "1024",
+		},
+	},
+}
+
+// VarNames returns the template's variable names, sorted.
+func (t Template) VarNames() []string {
+	return slices.Sorted(maps.Keys(t.Defaults))
+}
+
+// Templates returns the built-in templates sorted by name.
+func Templates() []Template {
+	out := make([]Template, 0, len(builtins))
+	for _, name := range slices.Sorted(maps.Keys(builtins)) {
+		out = append(out, builtins[name])
+	}
+	return out
+}
+
+// Lookup returns the built-in template called name.
+func Lookup(name string) (Template, bool) {
+	t, ok := builtins[name]
+	return t, ok
+}
+
+// Render returns the prompt of template name for a run in cwd. vars
+// override the template defaults; unknown variables are an error so a
+// typo in a scheduled job fails loudly instead of being ignored.
+func Render(name, cwd string, now time.Time, vars map[string]string) (string, error) {
+	t, ok := builtins[name]
+	if !ok {
+		return "", fmt.Errorf("unknown template %q (available: %s)", name, strings.Join(names(), ", "))
+	}
+	data := maps.Clone(t.Defaults)
+	for k, v := range vars {
+		if _, ok := data[k]; !ok {
+			return "", fmt.Errorf("template %s has no variable %q", name, k)
+		}
+		data[k] = v
+	}
+	data["CWD"] = cwd
+	data["DATE"] = now.Format("2006-01-02")
+
+	raw, err := templatesFS.ReadFile("templates/" + name + ".md")
+	if err != nil {
+		return "", fmt.Errorf("reading template %s: %w", name, err)
+	}
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(string(raw))
+	if err != nil {
+		return "", fmt.Errorf("parsing template %s: %w", name, err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("rendering template %s: %w", name, err)
+	}
+	return b.String(), nil
+}
+
+// ParseVars parses "KEY=value" pairs as given to --var.
+func ParseVars(pairs []string) (map[string]string, error) {
+	vars := make(map[string]string, len(pairs))
+	for _, p := range pairs {
+		k, v, ok := strings.Cut(p, "=")
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid variable %q: want KEY=value", p)
+		}
+		vars[k] = v
+	}
+	return vars, nil
+}
+
+// names returns the built-in template names, sorted.
+func names() []string {
+	return slices.Sorted(maps.Keys(builtins))
+}
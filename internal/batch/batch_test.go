@@ -0,0 +1,62 @@
+// ABOUTME: Tests for built-in batch templates: lookup, rendering, variable overrides, and --var parsing
+// ABOUTME: Checks the repo-health prompt covers every check and fails loudly on unknown names
+
+package batch
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRender_RepoHealth(t *testing.T) {
+	now := time.Date(2026, 3, 14, 2, 0, 0, 0, time.UTC)
+	got, err := Render("repo-health", "/src/app", now, map[string]string{"LARGE_FILE_KB": "512"})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	for _, want := range []string{
+		"Repository: /src/app", "Date: 2026-03-14",
+		"## Failing tests", "## Outdated dependencies", "## TODO/FIXME aging", "## Large files",
+		"larger than 512 KB", "older than 180 days",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("prompt missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "{{") {
+		t.Errorf("unrendered placeholder in prompt:\n%s", got)
+	}
+}
+
+func TestRender_Errors(t *testing.T) {
+	if _, err := Render("nightly", "/src", time.Now(), nil); err == nil || !strings.Contains(err.Error(), "repo-health") {
+		t.Errorf("unknown template error = %v; want the available names", err)
+	}
+	if _, err := Render("repo-health", "/src", time.Now(), map[string]string{"LARGE_FILE_MB": "1"}); err == nil {
+		t.Error("unknown variable should be an error")
+	}
+}
+
+func TestTemplates_HaveFiles(t *testing.T) {
+	for _, tmpl := range Templates() {
+		if _, err := Render(tmpl.Name, "/src", time.Now(), nil); err != nil {
+			t.Errorf("template %s: %v", tmpl.Name, err)
+		}
+		if _, ok := Lookup(tmpl.Name); !ok || tmpl.Description == "" {
+			t.Errorf("template %s should be listed with a description", tmpl.Name)
+		}
+	}
+}
+
+func TestParseVars(t *testing.T) {
+	vars, err := ParseVars([]string{"TODO_LIMIT=5", "NOTE=a=b"})
+	if err != nil || vars["TODO_LIMIT"] != "5" || vars["NOTE"] != "a=b" {
+		t.Errorf("ParseVars = %v, %v", vars, err)
+	}
+	for _, bad := range []string{"TODO_LIMIT", "=5"} {
+		if _, err := ParseVars([]string{bad}); err == nil {
+			t.Errorf("ParseVars(%q) should fail", bad)
+		}
+	}
+}
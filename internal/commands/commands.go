@@ -64,17 +64,19 @@ type CommandContext struct {
 	ReloadFn           func() (string, error)
 
 	// Phase 1 integration callbacks
-	SessionTreeFn       func() string // /tree: show interactive session tree
-	HookManagerFn       func() string // /hooks: show hook manager
-	PermissionManagerFn func() string // /permissions: show permission manager
-	ScopedModelsFn      func() string // /scoped-models: show model config
-	KeybindingsFn       func() string // /hotkeys: show keybindings
-	ListSessionsFn      func() string // /resume with no args: list sessions
+	SessionTreeFn       func() string                    // /tree: show interactive session tree
+	HookManagerFn       func() string                    // /hooks: show hook manager
+	PermissionManagerFn func(arg string) (string, error) // /permissions [level]: show rules or apply an autonomy preset
+	ScopedModelsFn      func() string                    // /scoped-models: show model config
+	KeybindingsFn       func() string                    // /hotkeys: show keybindings
+	ListSessionsFn      func() string                    // /resume with no args: list sessions
+	AgentsFn            func() string                    // /agents: list and validate agent definitions
 
 	// Session management callbacks
 	CopyLastMessageFn func() (string, error) // /copy: copy last assistant message to clipboard
 	NewSessionFn      func()                 // /new: start new session
-	ForkSessionFn     func() (string, error) // /fork: fork current session
+	ForkSessionFn     func() (string, error) // /fork all: fork the whole current session
+	ForkPickerFn      func()                 // /fork: pick a past message to fork from
 
 	// Phase 4 integration callbacks
 	GetSettings  func() string       // /settings: show current settings
@@ -84,6 +86,65 @@ type CommandContext struct {
 	// Phase 5 callbacks
 	DiffFn   func() (string, error)    // /diff: show git diff
 	RevertFn func(steps int) (string, error) // /revert: revert file operations
+
+	UndoTurnFn func() (string, error) // /undo: drop the last turn and restore the files it changed
+
+	// Background tasks. Nilable; the commands return "not available" when nil.
+	TasksFn  func(arg string) (string, error)  // /tasks [name <task> <name>]: list or name background tasks
+	DetachFn func(name string) (string, error) // /detach [name]: move the running turn to the background
+
+	// Approved plan execution. Nilable; /plan with an argument returns "not available" when nil.
+	PlanFn func(action string) (string, error) // /plan pause|resume|stop: control the running plan
+
+	// Project bootstrap. Nilable; /init returns "not available" when nil.
+	InitFn func(focus string) (string, error) // /init [focus]: analyze the project and propose PI.md
+
+	// Split panes. Nilable; /split returns "not available" when nil.
+	SplitFn func(prompt string) (string, error) // /split [prompt]: open a pane with its own agent
+
+	// Theme callback: "" opens the picker; a name applies and saves that theme. Nilable.
+	ThemeFn func(name string) (string, error)
+
+	// Output style callbacks
+	ListOutputStylesFn func() string           // /output-style: list styles, marking the active one
+	SetOutputStyleFn   func(name string) error // /output-style <name>: switch and persist per project
+
+	// Minion callback: "" shows status; otherwise off, auto, force, or toggle. Returns the new status.
+	MinionFn func(arg string) (string, error)
+
+	// Cache callback: "" or "stats" shows fetch cache stats; "clear" empties it.
+	CacheFn func(arg string) (string, error)
+
+	// Lets read and grep open a secret file for the rest of the session. Nilable.
+	AllowReadFn func(path string) (string, error)
+
+	// Per-key consumption of API key pools, appended to /cost. Nilable.
+	KeyUsageFn func() string
+
+	// Tool results shrunk by context eviction this session, shown by /context.
+	EvictedResults int
+	EvictedBytes   int
+
+	// Read tool calls this session and those answered with the unchanged stub, shown by /context.
+	ReadCacheReads int
+	ReadCacheHits  int
+	ReadCacheSaved int // bytes of file content the stubs stood in for
+
+	// Pinned messages that compaction keeps verbatim, shown by /context.
+	PinnedMessages int
+	PinnedTokens   int
+
+	// Opens the /context breakdown overlay; nil prints the plain summary.
+	ContextViewFn func()
+
+	// Pin callback: "" opens the message picker, "list" lists pins, N toggles message N.
+	PinFn func(arg string) (string, error)
+
+	// Opens the memory file picker for editing in $EDITOR; nil lists MemoryEntries.
+	MemoryEditFn func()
+
+	// Opens the /debug overlay tailing the session log; nil when logs only go to stderr.
+	DebugLogFn func()
 }
 
 // Registry holds all registered slash commands.
@@ -109,6 +170,21 @@ func (r *Registry) registerAliases() {
 	}
 }
 
+// Register adds a command, such as one served by a plugin. It refuses
+// names and aliases already taken, so built-ins cannot be shadowed.
+func (r *Registry) Register(cmd *Command) error {
+	for _, name := range append([]string{cmd.Name}, cmd.Aliases...) {
+		if _, ok := r.commands[name]; ok {
+			return fmt.Errorf("command /%s already exists", name)
+		}
+	}
+	r.commands[cmd.Name] = cmd
+	for _, alias := range cmd.Aliases {
+		r.commands[alias] = cmd
+	}
+	return nil
+}
+
 // Get returns a command by name.
 // The second return value indicates whether the name was found.
 func (r *Registry) Get(name string) (*Command, bool) {
@@ -242,7 +318,7 @@ func (r *Registry) registerCoreCommands() {
 			Execute: func(_ *CommandContext, _ string) (string, error) {
 				// Group commands by category
 				categories := map[string][]*Command{}
-				categoryOrder := []string{"Session", "Mode", "Config", "Info"}
+				categoryOrder := []string{"Session", "Mode", "Config", "Info", "Plugins"}
 				for _, cmd := range r.List() {
 					cat := cmd.Category
 					if cat == "" {
@@ -280,6 +356,21 @@ func (r *Registry) registerCoreCommands() {
 				return fmt.Sprintf("Model set to: %s", args), nil
 			},
 		},
+		{
+			Name:        "minion",
+			Category:    "Mode",
+			Description: "Show or change minion routing (off, auto, force, plural, toggle)",
+			Execute: func(ctx *CommandContext, args string) (string, error) {
+				if ctx.MinionFn == nil {
+					return "Minion not available (set minion.model in settings).", nil
+				}
+				out, err := ctx.MinionFn(args)
+				if err != nil {
+					return "", fmt.Errorf("minion: %w", err)
+				}
+				return out, nil
+			},
+		},
 		{
 			Name:        "status",
 			Aliases:     []string{"s"},
@@ -295,16 +386,23 @@ func (r *Registry) registerCoreCommands() {
 		{
 			Name:        "init",
 			Category:    "Info",
-			Description: "Initialize project configuration",
-			Execute: func(_ *CommandContext, _ string) (string, error) {
-				return "Project initialized.", nil
+			Description: "Analyze the project and propose a PI.md memory file, shown as a diff to approve",
+			Execute: func(ctx *CommandContext, args string) (string, error) {
+				if ctx.InitFn == nil {
+					return "Project initialization not available.", nil
+				}
+				return ctx.InitFn(strings.TrimSpace(args))
 			},
 		},
 		{
 			Name:        "memory",
 			Category:    "Info",
-			Description: "Show memory entries",
-			Execute: func(ctx *CommandContext, _ string) (string, error) {
+			Description: "Edit a memory file in $EDITOR (list: show loaded memory files)",
+			Execute: func(ctx *CommandContext, args string) (string, error) {
+				if ctx.MemoryEditFn != nil && strings.TrimSpace(args) != "list" {
+					ctx.MemoryEditFn()
+					return "", nil
+				}
 				if len(ctx.MemoryEntries) == 0 {
 					return "No memory entries.", nil
 				}
@@ -316,14 +414,32 @@ func (r *Registry) registerCoreCommands() {
 				return b.String(), nil
 			},
 		},
+		{
+			Name:        "debug",
+			Category:    "Info",
+			Description: "Tail this session's structured log in an overlay",
+			Execute: func(ctx *CommandContext, _ string) (string, error) {
+				if ctx.DebugLogFn == nil {
+					return "Debug log not available.", nil
+				}
+				ctx.DebugLogFn()
+				return "", nil
+			},
+		},
 		{
 			Name:        "context",
 			Category:    "Info",
-			Description: "Show current context info",
+			Description: "Show context window usage by category, pinned messages, evicted tool output, and read cache hits",
 			Execute: func(ctx *CommandContext, _ string) (string, error) {
+				if ctx.ContextViewFn != nil {
+					ctx.ContextViewFn()
+					return "", nil
+				}
 				return fmt.Sprintf(
-					"CWD:   %s\nModel: %s\nMessages: %d",
-					ctx.CWD, ctx.Model, ctx.Messages,
+					"CWD:   %s\nModel: %s\nMessages: %d\nPinned: %d messages (~%d tokens)\nEvicted: %d tool results (%.1f KB)\nRead cache: %d of %d reads unchanged (%.1f KB saved)",
+					ctx.CWD, ctx.Model, ctx.Messages, ctx.PinnedMessages, ctx.PinnedTokens,
+					ctx.EvictedResults, float64(ctx.EvictedBytes)/1024,
+					ctx.ReadCacheHits, ctx.ReadCacheReads, float64(ctx.ReadCacheSaved)/1024,
 				), nil
 			},
 		},
@@ -332,10 +448,16 @@ func (r *Registry) registerCoreCommands() {
 			Category:    "Info",
 			Description: "Show session cost breakdown",
 			Execute: func(ctx *CommandContext, _ string) (string, error) {
-				return fmt.Sprintf(
+				out := fmt.Sprintf(
 					"Session cost: $%.4f\nTotal tokens: %d",
 					ctx.TotalCost, ctx.TotalTokens,
-				), nil
+				)
+				if ctx.KeyUsageFn != nil {
+					if keys := ctx.KeyUsageFn(); keys != "" {
+						out += "\n\n" + keys
+					}
+				}
+				return out, nil
 			},
 		},
 		{
@@ -350,11 +472,52 @@ func (r *Registry) registerCoreCommands() {
 				return "Goodbye.", nil
 			},
 		},
+		{
+			Name:        "output-style",
+			Category:    "Mode",
+			Description: "Show or change the response output style",
+			Execute: func(ctx *CommandContext, args string) (string, error) {
+				if args == "" {
+					if ctx.ListOutputStylesFn == nil {
+						return "Output styles not available.", nil
+					}
+					return ctx.ListOutputStylesFn(), nil
+				}
+				if ctx.SetOutputStyleFn == nil {
+					return "Output styles not available.", nil
+				}
+				if err := ctx.SetOutputStyleFn(args); err != nil {
+					return "", fmt.Errorf("set output style: %w", err)
+				}
+				return fmt.Sprintf("Output style set to: %s (saved for this project)", args), nil
+			},
+		},
+		{
+			Name:        "theme",
+			Category:    "Mode",
+			Description: "Pick a color theme with live preview, or switch to one: /theme [name]",
+			Execute: func(ctx *CommandContext, args string) (string, error) {
+				if ctx.ThemeFn == nil {
+					return "Themes not available.", nil
+				}
+				return ctx.ThemeFn(strings.TrimSpace(args))
+			},
+		},
 		{
 			Name:        "plan",
 			Category:    "Mode",
-			Description: "Toggle plan mode",
-			Execute: func(ctx *CommandContext, _ string) (string, error) {
+			Description: "Toggle plan mode, or pause, resume, or stop a running plan: /plan [pause|resume|stop]",
+			Execute: func(ctx *CommandContext, args string) (string, error) {
+				if args = strings.TrimSpace(args); args != "" {
+					if ctx.PlanFn == nil {
+						return "Plan execution not available.", nil
+					}
+					out, err := ctx.PlanFn(args)
+					if err != nil {
+						return "", fmt.Errorf("plan: %w", err)
+					}
+					return out, nil
+				}
 				if ctx.ToggleMode == nil || ctx.GetMode == nil {
 					return "Plan mode not available.", nil
 				}
@@ -408,6 +571,21 @@ func (r *Registry) registerCoreCommands() {
 				return ctx.SandboxStatus(), nil
 			},
 		},
+		{
+			Name:        "allow-read",
+			Category:    "Config",
+			Description: "Allow reading a secret file (such as .env) for this session",
+			Execute: func(ctx *CommandContext, args string) (string, error) {
+				if ctx.AllowReadFn == nil {
+					return "Secret file reads are not restricted.", nil
+				}
+				path := strings.TrimSpace(args)
+				if path == "" {
+					return "", fmt.Errorf("usage: /allow-read <path>")
+				}
+				return ctx.AllowReadFn(path)
+			},
+		},
 		{
 			Name:        "vim",
 			Category:    "Mode",
@@ -444,6 +622,36 @@ func (r *Registry) registerCoreCommands() {
 				return b.String(), nil
 			},
 		},
+		{
+			Name:        "cache",
+			Category:    "Config",
+			Description: "Show web fetch and MCP resource cache stats; /cache clear empties it",
+			Execute: func(ctx *CommandContext, args string) (string, error) {
+				if ctx.CacheFn == nil {
+					return "Fetch cache not available.", nil
+				}
+				out, err := ctx.CacheFn(strings.TrimSpace(args))
+				if err != nil {
+					return "", fmt.Errorf("cache: %w", err)
+				}
+				return out, nil
+			},
+		},
+		{
+			Name:        "pin",
+			Category:    "Session",
+			Description: "Pin messages so compaction keeps them verbatim; /pin list, /pin <n> toggles",
+			Execute: func(ctx *CommandContext, args string) (string, error) {
+				if ctx.PinFn == nil {
+					return "Pinning not available.", nil
+				}
+				out, err := ctx.PinFn(strings.TrimSpace(args))
+				if err != nil {
+					return "", fmt.Errorf("pin: %w", err)
+				}
+				return out, nil
+			},
+		},
 		{
 			Name:        "export",
 			Category:    "Session",
@@ -518,10 +726,10 @@ func (r *Registry) registerCoreCommands() {
 		{
 			Name:        "permissions",
 			Category:    "Config",
-			Description: "Show and manage permission rules",
-			Execute: func(ctx *CommandContext, _ string) (string, error) {
+			Description: "Show permission rules; /permissions cautious|balanced|autonomous rewrites them",
+			Execute: func(ctx *CommandContext, args string) (string, error) {
 				if ctx.PermissionManagerFn != nil {
-					return ctx.PermissionManagerFn(), nil
+					return ctx.PermissionManagerFn(strings.TrimSpace(args))
 				}
 				return "Permission manager not available.", nil
 			},
@@ -537,6 +745,17 @@ func (r *Registry) registerCoreCommands() {
 				return defaultHotkeysTable(), nil
 			},
 		},
+		{
+			Name:        "agents",
+			Category:    "Config",
+			Description: "List sub-agent definitions and validate them",
+			Execute: func(ctx *CommandContext, _ string) (string, error) {
+				if ctx.AgentsFn == nil {
+					return "Agents not available.", nil
+				}
+				return ctx.AgentsFn(), nil
+			},
+		},
 		{
 			Name:        "changelog",
 			Category:    "Info",
@@ -559,7 +778,7 @@ func (r *Registry) registerCoreCommands() {
 		{
 			Name:        "share",
 			Category:    "Session",
-			Description: "Share current session",
+			Description: "Share current session (encrypted upload, confirmed first)",
 			Execute: func(ctx *CommandContext, _ string) (string, error) {
 				if ctx.ShareFn != nil {
 					return ctx.ShareFn(), nil
@@ -593,8 +812,16 @@ func (r *Registry) registerCoreCommands() {
 		{
 			Name:        "fork",
 			Category:    "Session",
-			Description: "Fork current session",
-			Execute: func(ctx *CommandContext, _ string) (string, error) {
+			Description: "Fork the session from a past message (/fork all: copy everything)",
+			Execute: func(ctx *CommandContext, args string) (string, error) {
+				args = strings.TrimSpace(args)
+				if args == "" && ctx.ForkPickerFn != nil {
+					ctx.ForkPickerFn()
+					return "", nil
+				}
+				if args != "" && args != "all" {
+					return "Usage: /fork [all]", nil
+				}
 				if ctx.ForkSessionFn == nil {
 					return "Fork not available.", nil
 				}
@@ -653,12 +880,49 @@ func (r *Registry) registerCoreCommands() {
 			Name:        "undo",
 			Aliases:     []string{"u"},
 			Category:    "Session",
-			Description: "Undo last file operation (alias for /revert 1)",
+			Description: "Undo the last turn: drop it from the conversation and restore the files it changed",
 			Execute: func(ctx *CommandContext, _ string) (string, error) {
-				if ctx.RevertFn == nil {
+				if ctx.UndoTurnFn == nil {
 					return "Undo not available.", nil
 				}
-				return ctx.RevertFn(1)
+				return ctx.UndoTurnFn()
+			},
+		},
+		{
+			Name:        "tasks",
+			Category:    "Session",
+			Description: "List background tasks, like Ctrl+B; /tasks name <task> <name> names one",
+			Execute: func(ctx *CommandContext, args string) (string, error) {
+				if ctx.TasksFn == nil {
+					return "Background tasks not available.", nil
+				}
+				out, err := ctx.TasksFn(strings.TrimSpace(args))
+				if err != nil {
+					return "", fmt.Errorf("tasks: %w", err)
+				}
+				return out, nil
+			},
+		},
+		{
+			Name:        "detach",
+			Category:    "Session",
+			Description: "Move the running turn to the background, optionally named: /detach [name]",
+			Execute: func(ctx *CommandContext, args string) (string, error) {
+				if ctx.DetachFn == nil {
+					return "Background tasks not available.", nil
+				}
+				return ctx.DetachFn(strings.TrimSpace(args))
+			},
+		},
+		{
+			Name:        "split",
+			Category:    "Session",
+			Description: "Open a pane beside this one with its own agent, optionally running a prompt: /split [prompt]",
+			Execute: func(ctx *CommandContext, args string) (string, error) {
+				if ctx.SplitFn == nil {
+					return "Split panes not available.", nil
+				}
+				return ctx.SplitFn(strings.TrimSpace(args))
 			},
 		},
 	}
@@ -107,10 +107,10 @@ func TestRegistry_AllCommandsRegistered(t *testing.T) {
 	reg := NewRegistry()
 
 	expected := []string{
-		"changelog", "clear", "compact", "config", "context", "copy", "cost",
-		"diff", "exit", "export", "fork", "help", "hooks", "hotkeys", "init", "mcp", "memory",
-		"model", "new", "permissions", "plan", "quit", "reload", "rename", "resume", "revert",
-		"sandbox", "scoped-models", "settings", "share", "status", "tree", "undo", "vim",
+		"agents", "allow-read", "cache", "changelog", "clear", "compact", "config", "context", "copy", "cost",
+		"debug", "detach", "diff", "exit", "export", "fork", "help", "hooks", "hotkeys", "init", "mcp", "memory",
+		"minion", "model", "new", "output-style", "permissions", "pin", "plan", "quit", "reload", "rename", "resume", "revert",
+		"sandbox", "scoped-models", "settings", "share", "split", "status", "tasks", "theme", "tree", "undo", "vim",
 	}
 	for _, name := range expected {
 		cmd, ok := reg.Get(name)
@@ -144,6 +144,45 @@ func TestRegistry_AllCommandsRegistered(t *testing.T) {
 	}
 }
 
+func TestRegistry_Register(t *testing.T) {
+	t.Parallel()
+
+	reg := NewRegistry()
+	lint := &Command{
+		Name:        "lint",
+		Aliases:     []string{"l"},
+		Category:    "Plugins",
+		Description: "Run the linter",
+		Execute: func(_ *CommandContext, args string) (string, error) {
+			return "linted " + args, nil
+		},
+	}
+	if err := reg.Register(lint); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	ctx, _ := testContext()
+	if out, err := reg.Dispatch(ctx, "/l ./..."); err != nil || out != "linted ./..." {
+		t.Errorf("Dispatch(/l) = %q, %v", out, err)
+	}
+	help, _ := reg.Dispatch(ctx, "/help")
+	if !strings.Contains(help, "## Plugins\n  /lint — Run the linter") {
+		t.Errorf("/help should list plugin commands:\n%s", help)
+	}
+
+	for _, c := range []*Command{
+		{Name: "clear", Description: "shadow"},
+		{Name: "lint2", Aliases: []string{"q"}, Description: "alias of /quit"},
+		{Name: "lint", Description: "again"},
+	} {
+		if err := reg.Register(c); err == nil {
+			t.Errorf("Register(%q, %v) should fail: the name is taken", c.Name, c.Aliases)
+		}
+	}
+	if cmd, _ := reg.Get("clear"); cmd.Description == "shadow" {
+		t.Error("a built-in was shadowed")
+	}
+}
+
 func TestDispatch_Clear(t *testing.T) {
 	t.Parallel()
 
@@ -337,8 +376,20 @@ func TestDispatch_Init(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if !strings.Contains(strings.ToLower(result), "initialized") {
-		t.Errorf("expected result to contain 'initialized', got %q", result)
+	if !strings.Contains(result, "not available") {
+		t.Errorf("expected 'not available' without InitFn, got %q", result)
+	}
+
+	var focus string
+	ctx.InitFn = func(f string) (string, error) {
+		focus = f
+		return "", nil
+	}
+	if _, err := reg.Dispatch(ctx, "/init  mention the release process "); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if focus != "mention the release process" {
+		t.Errorf("InitFn got %q; want the trimmed focus", focus)
 	}
 }
 
@@ -375,17 +426,56 @@ func TestDispatch_Memory_Empty(t *testing.T) {
 	}
 }
 
+func TestDispatch_Memory_OpensEditor(t *testing.T) {
+	t.Parallel()
+
+	reg := NewRegistry()
+	ctx, _ := testContext()
+	opened := 0
+	ctx.MemoryEditFn = func() { opened++ }
+
+	result, err := reg.Dispatch(ctx, "/memory")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opened != 1 || result != "" {
+		t.Errorf("opened = %d, result = %q; want the picker and no text", opened, result)
+	}
+
+	result, _ = reg.Dispatch(ctx, "/memory list")
+	if opened != 1 || !strings.Contains(result, "prefer table-driven tests") {
+		t.Errorf("/memory list should print entries, got %q", result)
+	}
+}
+
+func TestDispatch_ContextOverlay(t *testing.T) {
+	t.Parallel()
+
+	reg := NewRegistry()
+	ctx, _ := testContext()
+	opened := false
+	ctx.ContextViewFn = func() { opened = true }
+
+	result, err := reg.Dispatch(ctx, "/context")
+	if err != nil || result != "" || !opened {
+		t.Errorf("result = %q, err = %v, opened = %v; want the overlay instead of text", result, err, opened)
+	}
+}
+
 func TestDispatch_Context(t *testing.T) {
 	t.Parallel()
 
 	reg := NewRegistry()
 	ctx, _ := testContext()
+	ctx.EvictedResults, ctx.EvictedBytes = 3, 6144
+	ctx.PinnedMessages, ctx.PinnedTokens = 2, 150
+	ctx.ReadCacheReads, ctx.ReadCacheHits, ctx.ReadCacheSaved = 8, 3, 2048
 
 	result, err := reg.Dispatch(ctx, "/context")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	for _, want := range []string{"/tmp/project", "claude-sonnet"} {
+	for _, want := range []string{"/tmp/project", "claude-sonnet", "Pinned: 2 messages (~150 tokens)", "Evicted: 3 tool results (6.0 KB)", "Read cache: 3 of 8 reads unchanged (2.0 KB saved)"} {
 		if !strings.Contains(result, want) {
 			t.Errorf("expected context output to contain %q, got:\n%s", want, result)
 		}
@@ -410,6 +500,22 @@ func TestDispatch_Cost(t *testing.T) {
 	}
 }
 
+func TestDispatch_CostWithKeyUsage(t *testing.T) {
+	t.Parallel()
+
+	reg := NewRegistry()
+	ctx, _ := testContext()
+	ctx.KeyUsageFn = func() string { return "API keys (anthropic):\n  team-a ..." }
+
+	result, err := reg.Dispatch(ctx, "/cost")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(result, "Total tokens: 4567\n\nAPI keys (anthropic):") {
+		t.Errorf("expected key usage after the totals, got:\n%s", result)
+	}
+}
+
 func TestDispatch_Plan(t *testing.T) {
 	t.Parallel()
 
@@ -445,6 +551,53 @@ func TestDispatch_Plan_NilCallback(t *testing.T) {
 	}
 }
 
+func TestDispatch_PlanAction(t *testing.T) {
+	t.Parallel()
+
+	reg := NewRegistry()
+	ctx, cb := testContext()
+	result, err := reg.Dispatch(ctx, "/plan pause")
+	if err != nil || !strings.Contains(result, "not available") || cb.toggleModeCalled {
+		t.Fatalf("/plan pause without a handler = %q, %v", result, err)
+	}
+
+	var got string
+	ctx.PlanFn = func(action string) (string, error) {
+		got = action
+		return "ok", nil
+	}
+	if _, err := reg.Dispatch(ctx, "/plan  resume "); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "resume" || cb.toggleModeCalled {
+		t.Errorf("action = %q, toggled %v; want resume passed on without toggling the mode", got, cb.toggleModeCalled)
+	}
+}
+
+func TestDispatch_Theme(t *testing.T) {
+	t.Parallel()
+
+	reg := NewRegistry()
+	ctx, _ := testContext()
+	if result, err := reg.Dispatch(ctx, "/theme"); err != nil || !strings.Contains(result, "not available") {
+		t.Fatalf("/theme without a handler = %q, %v", result, err)
+	}
+
+	var got []string
+	ctx.ThemeFn = func(name string) (string, error) {
+		got = append(got, name)
+		return "", nil
+	}
+	for _, line := range []string{"/theme", "/theme  light "} {
+		if _, err := reg.Dispatch(ctx, line); err != nil {
+			t.Fatalf("%s: unexpected error: %v", line, err)
+		}
+	}
+	if len(got) != 2 || got[0] != "" || got[1] != "light" {
+		t.Errorf("ThemeFn args = %q; want \"\" then \"light\"", got)
+	}
+}
+
 func TestDispatch_Rename(t *testing.T) {
 	t.Parallel()
 
@@ -792,6 +945,27 @@ func TestDispatch_Permissions_NilCallback(t *testing.T) {
 	}
 }
 
+func TestDispatch_Permissions_PassesLevel(t *testing.T) {
+	t.Parallel()
+
+	reg := NewRegistry()
+	ctx, _ := testContext()
+
+	var gotArg string
+	ctx.PermissionManagerFn = func(arg string) (string, error) {
+		gotArg = arg
+		return "applied", nil
+	}
+
+	result, err := reg.Dispatch(ctx, "/permissions  balanced ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotArg != "balanced" || result != "applied" {
+		t.Errorf("arg = %q, result = %q", gotArg, result)
+	}
+}
+
 func TestDispatch_Hotkeys_Default(t *testing.T) {
 	t.Parallel()
 
@@ -1200,6 +1374,34 @@ func TestDispatch_Fork_Error(t *testing.T) {
 	}
 }
 
+func TestDispatch_Fork_OpensPicker(t *testing.T) {
+	t.Parallel()
+
+	reg := NewRegistry()
+	ctx, cb := testContext()
+	picked := false
+	ctx.ForkPickerFn = func() { picked = true }
+
+	if _, err := reg.Dispatch(ctx, "/fork"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !picked || cb.forkSessionCalled {
+		t.Errorf("/fork should open the picker; picked=%v forked=%v", picked, cb.forkSessionCalled)
+	}
+
+	result, err := reg.Dispatch(ctx, "/fork all")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cb.forkSessionCalled || !strings.Contains(result, "fork-id-123") {
+		t.Errorf("/fork all should fork the whole session, got %q", result)
+	}
+
+	if result, _ := reg.Dispatch(ctx, "/fork 3"); !strings.Contains(result, "Usage") {
+		t.Errorf("expected usage for unknown argument, got %q", result)
+	}
+}
+
 func TestDispatch_Quit(t *testing.T) {
 	t.Parallel()
 
@@ -1321,9 +1523,11 @@ func TestDispatch_Undo(t *testing.T) {
 
 	reg := NewRegistry()
 	ctx, _ := testContext()
-	var gotSteps int
-	ctx.RevertFn = func(steps int) (string, error) {
-		gotSteps = steps
+	ctx.RevertFn = func(int) (string, error) {
+		t.Error("/undo should undo the turn, not revert file operations")
+		return "", nil
+	}
+	ctx.UndoTurnFn = func() (string, error) {
 		return "Undone.", nil
 	}
 
@@ -1331,12 +1535,14 @@ func TestDispatch_Undo(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if gotSteps != 1 {
-		t.Errorf("expected steps=1 for undo, got %d", gotSteps)
-	}
 	if !strings.Contains(result, "Undone") {
 		t.Errorf("expected undo result, got %q", result)
 	}
+
+	ctx.UndoTurnFn = nil
+	if result, _ := reg.Dispatch(ctx, "/undo"); !strings.Contains(result, "not available") {
+		t.Errorf("expected 'not available', got %q", result)
+	}
 }
 
 func TestDispatch_Revert_NilCallback(t *testing.T) {
@@ -1381,7 +1587,7 @@ func TestAlias_UndoAlias(t *testing.T) {
 	reg := NewRegistry()
 	ctx, _ := testContext()
 	called := false
-	ctx.RevertFn = func(_ int) (string, error) {
+	ctx.UndoTurnFn = func() (string, error) {
 		called = true
 		return "ok", nil
 	}
@@ -1391,7 +1597,7 @@ func TestAlias_UndoAlias(t *testing.T) {
 		t.Fatalf("unexpected error via alias /u: %v", err)
 	}
 	if !called {
-		t.Error("RevertFn not called via alias /u")
+		t.Error("UndoTurnFn not called via alias /u")
 	}
 }
 
@@ -1550,3 +1756,240 @@ func TestIsCommand(t *testing.T) {
 		})
 	}
 }
+
+func TestDispatch_OutputStyle_List(t *testing.T) {
+	t.Parallel()
+
+	reg := NewRegistry()
+	ctx, _ := testContext()
+	ctx.ListOutputStylesFn = func() string { return "* default\n  teaching" }
+
+	result, err := reg.Dispatch(ctx, "/output-style")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(result, "teaching") {
+		t.Errorf("expected style list, got %q", result)
+	}
+}
+
+func TestDispatch_OutputStyle_Set(t *testing.T) {
+	t.Parallel()
+
+	reg := NewRegistry()
+	ctx, _ := testContext()
+	var got string
+	ctx.SetOutputStyleFn = func(name string) error {
+		got = name
+		return nil
+	}
+
+	result, err := reg.Dispatch(ctx, "/output-style teaching")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "teaching" {
+		t.Errorf("SetOutputStyleFn got %q; want teaching", got)
+	}
+	if !strings.Contains(result, "teaching") {
+		t.Errorf("expected confirmation, got %q", result)
+	}
+}
+
+func TestDispatch_OutputStyle_SetError(t *testing.T) {
+	t.Parallel()
+
+	reg := NewRegistry()
+	ctx, _ := testContext()
+	ctx.SetOutputStyleFn = func(name string) error {
+		return fmt.Errorf("unknown output style %q", name)
+	}
+
+	if _, err := reg.Dispatch(ctx, "/output-style bogus"); err == nil {
+		t.Error("expected error for unknown style")
+	}
+}
+
+func TestDispatch_OutputStyle_NilCallback(t *testing.T) {
+	t.Parallel()
+
+	reg := NewRegistry()
+	ctx, _ := testContext()
+
+	result, err := reg.Dispatch(ctx, "/output-style")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(strings.ToLower(result), "not available") {
+		t.Errorf("expected 'not available', got %q", result)
+	}
+}
+
+func TestDispatch_Agents(t *testing.T) {
+	t.Parallel()
+
+	reg := NewRegistry()
+	ctx, _ := testContext()
+	ctx.AgentsFn = func() string { return "explore  ok" }
+
+	result, err := reg.Dispatch(ctx, "/agents")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != "explore  ok" {
+		t.Errorf("unexpected result %q", result)
+	}
+
+	ctx.AgentsFn = nil
+	result, _ = reg.Dispatch(ctx, "/agents")
+	if !strings.Contains(strings.ToLower(result), "not available") {
+		t.Errorf("expected 'not available', got %q", result)
+	}
+}
+
+func TestMinionCommand(t *testing.T) {
+	t.Parallel()
+	reg := NewRegistry()
+	ctx := &CommandContext{}
+
+	var gotArg string
+	ctx.MinionFn = func(arg string) (string, error) {
+		gotArg = arg
+		if arg == "bogus" {
+			return "", fmt.Errorf("unknown minion mode")
+		}
+		return "Minion mode: force", nil
+	}
+
+	result, err := reg.Dispatch(ctx, "/minion force")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotArg != "force" || result != "Minion mode: force" {
+		t.Errorf("arg = %q, result = %q", gotArg, result)
+	}
+
+	if _, err := reg.Dispatch(ctx, "/minion bogus"); err == nil {
+		t.Error("expected error for an unknown mode")
+	}
+
+	ctx.MinionFn = nil
+	result, _ = reg.Dispatch(ctx, "/minion")
+	if !strings.Contains(strings.ToLower(result), "not available") {
+		t.Errorf("expected 'not available', got %q", result)
+	}
+}
+
+func TestCacheCommand(t *testing.T) {
+	t.Parallel()
+	reg := NewRegistry()
+	ctx := &CommandContext{}
+
+	var gotArg string
+	ctx.CacheFn = func(arg string) (string, error) {
+		gotArg = arg
+		if arg == "bogus" {
+			return "", fmt.Errorf("unknown subcommand")
+		}
+		return "Fetch cache cleared.", nil
+	}
+
+	result, err := reg.Dispatch(ctx, "/cache clear")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotArg != "clear" || result != "Fetch cache cleared." {
+		t.Errorf("arg = %q, result = %q", gotArg, result)
+	}
+
+	if _, err := reg.Dispatch(ctx, "/cache bogus"); err == nil {
+		t.Error("expected error for an unknown subcommand")
+	}
+
+	ctx.CacheFn = nil
+	result, _ = reg.Dispatch(ctx, "/cache")
+	if !strings.Contains(strings.ToLower(result), "not available") {
+		t.Errorf("expected 'not available', got %q", result)
+	}
+}
+
+func TestPinCommand(t *testing.T) {
+	t.Parallel()
+	reg := NewRegistry()
+	ctx := &CommandContext{}
+
+	var gotArg string
+	ctx.PinFn = func(arg string) (string, error) {
+		gotArg = arg
+		if arg == "99" {
+			return "", fmt.Errorf("no message 99")
+		}
+		return "Pinned message 2.", nil
+	}
+
+	result, err := reg.Dispatch(ctx, "/pin 2 ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotArg != "2" || result != "Pinned message 2." {
+		t.Errorf("arg = %q, result = %q", gotArg, result)
+	}
+
+	if _, err := reg.Dispatch(ctx, "/pin 99"); err == nil {
+		t.Error("expected error for a missing message")
+	}
+
+	ctx.PinFn = nil
+	result, _ = reg.Dispatch(ctx, "/pin")
+	if !strings.Contains(strings.ToLower(result), "not available") {
+		t.Errorf("expected 'not available', got %q", result)
+	}
+}
+
+func TestDispatch_TasksAndDetach(t *testing.T) {
+	t.Parallel()
+
+	reg := NewRegistry()
+	ctx, _ := testContext()
+	for _, input := range []string{"/tasks", "/detach"} {
+		result, err := reg.Dispatch(ctx, input)
+		if err != nil || !strings.Contains(result, "not available") {
+			t.Errorf("%s without a handler = %q, %v", input, result, err)
+		}
+	}
+
+	var gotTasks, gotDetach string
+	ctx.TasksFn = func(arg string) (string, error) {
+		gotTasks = arg
+		return "", nil
+	}
+	ctx.DetachFn = func(name string) (string, error) {
+		gotDetach = name
+		return "", nil
+	}
+	if _, err := reg.Dispatch(ctx, "/tasks name bg-1  tests "); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := reg.Dispatch(ctx, "/detach nightly build"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotTasks != "name bg-1  tests" || gotDetach != "nightly build" {
+		t.Errorf("tasks arg = %q, detach name = %q", gotTasks, gotDetach)
+	}
+}
+
+func TestDebugCommand(t *testing.T) {
+	t.Parallel()
+	reg := NewRegistry()
+
+	result, err := reg.Dispatch(&CommandContext{}, "/debug")
+	if err != nil || !strings.Contains(result, "not available") {
+		t.Errorf("without a log file: %q, %v", result, err)
+	}
+
+	opened := false
+	result, err = reg.Dispatch(&CommandContext{DebugLogFn: func() { opened = true }}, "/debug")
+	if err != nil || result != "" || !opened {
+		t.Errorf("with a log file: %q, %v, opened %v", result, err, opened)
+	}
+}
@@ -1,10 +1,11 @@
-// ABOUTME: Auth credential storage with file locking via flock
-// ABOUTME: Reads/writes ~/.pi-go/auth.json with 0600 permissions
+// ABOUTME: Auth credential storage: the OS keyring first, then ~/.pi-go/auth.json (0600)
+// ABOUTME: Resolves keys by priority: --api-key, keyring, auth file (literal or !command), environment
 
 package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -21,11 +22,14 @@ type AuthStore struct {
 	runtimeKey string            // CLI --api-key override; not persisted
 	cmdCache   map[string]string // per-process cache for !command resolutions
 	cmdGroup   singleflight.Group // deduplicates concurrent resolveCommandKey calls
+
+	keyring     keyring           // consulted before Keys; nil = auth file only
+	keyringKeys map[string]string // per-process cache of keyring lookups; "" = none
 }
 
 // LoadAuth reads the auth file, or returns an empty store if it doesn't exist.
 func LoadAuth() (*AuthStore, error) {
-	store := &AuthStore{Keys: make(map[string]string)}
+	store := &AuthStore{Keys: make(map[string]string), keyring: osKeyring}
 	data, err := os.ReadFile(AuthFile())
 	if os.IsNotExist(err) {
 		return store, nil
@@ -74,32 +78,50 @@ func (a *AuthStore) SetRuntimeKey(key string) {
 }
 
 // GetKey returns the API key for a provider using the priority chain:
-// runtimeKey > stored key (with !command resolution) > env var > empty.
+// runtimeKey > OS keyring > stored key (with !command resolution) > env var > empty.
 func (a *AuthStore) GetKey(provider string) string {
+	key, _ := a.lookup(provider)
+	return key
+}
+
+// KeySource describes where GetKey finds provider's key, e.g. "macOS
+// Keychain" or "ANTHROPIC_API_KEY environment variable"; "" when it has none.
+func (a *AuthStore) KeySource(provider string) string {
+	_, source := a.lookup(provider)
+	return source
+}
+
+// lookup implements GetKey and KeySource.
+func (a *AuthStore) lookup(provider string) (key, source string) {
 	a.mu.Lock()
 	runtime := a.runtimeKey
-	key := a.Keys[provider]
+	stored := a.Keys[provider]
 	a.mu.Unlock()
 
 	// Priority 1: CLI runtime override
 	if runtime != "" {
-		return runtime
+		return runtime, "--api-key"
 	}
 
-	// Priority 2: Stored key (may be a !command)
-	if key != "" {
-		if strings.HasPrefix(key, "!") {
-			resolved, err := a.resolveCommandKey(key[1:])
+	// Priority 2: OS keyring (pi-go auth login)
+	if key := a.keyringKey(provider); key != "" {
+		return key, a.keyring.name()
+	}
+
+	// Priority 3: Stored key (may be a !command)
+	if stored != "" {
+		if strings.HasPrefix(stored, "!") {
+			resolved, err := a.resolveCommandKey(stored[1:])
 			if err == nil && resolved != "" {
-				return resolved
+				return resolved, "command in " + AuthFile()
 			}
 			// Fall through to env vars on command error
 		} else {
-			return key
+			return stored, AuthFile()
 		}
 	}
 
-	// Priority 3: Environment variables
+	// Priority 4: Environment variables
 	upper := strings.ToUpper(provider)
 	envVars := []string{
 		"PI_API_KEY_" + upper,
@@ -107,10 +129,82 @@ func (a *AuthStore) GetKey(provider string) string {
 	}
 	for _, env := range envVars {
 		if v := os.Getenv(env); v != "" {
-			return v
+			return v, env + " environment variable"
+		}
+	}
+	return "", ""
+}
+
+// keyringKey returns provider's key from the OS keyring, or "" when it has
+// none or cannot be read. Lookups are cached for the process.
+func (a *AuthStore) keyringKey(provider string) string {
+	if a.keyring == nil {
+		return ""
+	}
+	a.mu.Lock()
+	key, ok := a.keyringKeys[provider]
+	a.mu.Unlock()
+	if ok {
+		return key
+	}
+	key, _ = a.keyring.get(KeyringService, provider)
+	a.mu.Lock()
+	if a.keyringKeys == nil {
+		a.keyringKeys = make(map[string]string)
+	}
+	a.keyringKeys[provider] = key
+	a.mu.Unlock()
+	return key
+}
+
+// SaveToKeyring stores provider's key in the OS keyring and removes any
+// copy from the auth file. It fails, leaving the auth file alone, when the
+// keyring is unavailable.
+func (a *AuthStore) SaveToKeyring(provider, key string) error {
+	if a.keyring == nil {
+		return ErrKeyringUnavailable
+	}
+	if err := a.keyring.set(KeyringService, provider, key); err != nil {
+		return fmt.Errorf("saving to the %s: %w", a.keyring.name(), err)
+	}
+	a.mu.Lock()
+	if a.keyringKeys == nil {
+		a.keyringKeys = make(map[string]string)
+	}
+	a.keyringKeys[provider] = key
+	_, inFile := a.Keys[provider]
+	delete(a.Keys, provider)
+	a.mu.Unlock()
+	if inFile {
+		return a.Save()
+	}
+	return nil
+}
+
+// RemoveKey deletes provider's key from the OS keyring and the auth file,
+// and returns where it was removed from.
+func (a *AuthStore) RemoveKey(provider string) ([]string, error) {
+	var removed []string
+	if a.keyring != nil {
+		switch err := a.keyring.delete(KeyringService, provider); {
+		case err == nil:
+			removed = append(removed, a.keyring.name())
+		case !errors.Is(err, ErrNotInKeyring) && !errors.Is(err, ErrKeyringUnavailable):
+			return nil, fmt.Errorf("removing from the %s: %w", a.keyring.name(), err)
 		}
 	}
-	return ""
+	a.mu.Lock()
+	delete(a.keyringKeys, provider)
+	_, inFile := a.Keys[provider]
+	delete(a.Keys, provider)
+	a.mu.Unlock()
+	if inFile {
+		if err := a.Save(); err != nil {
+			return removed, err
+		}
+		removed = append(removed, AuthFile())
+	}
+	return removed, nil
 }
 
 // resolveCommandKey executes a shell command and returns its trimmed output.
@@ -167,3 +261,21 @@ func (a *AuthStore) SetKey(provider, key string) {
 	a.Keys[provider] = key
 	a.mu.Unlock()
 }
+
+// ResolveKey resolves a key reference as used in key pools: "!command"
+// runs the command, "$NAME" reads an environment variable, anything else
+// is the key itself. It returns "" when the reference cannot be resolved.
+func (a *AuthStore) ResolveKey(ref string) string {
+	switch {
+	case strings.HasPrefix(ref, "!"):
+		key, err := a.resolveCommandKey(ref[1:])
+		if err != nil {
+			return ""
+		}
+		return key
+	case strings.HasPrefix(ref, "$"):
+		return os.Getenv(ref[1:])
+	default:
+		return ref
+	}
+}
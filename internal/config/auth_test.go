@@ -165,3 +165,20 @@ func TestAuthStore_GetKey_CommandError(t *testing.T) {
 		t.Errorf("GetKey after command error = %q; want %q", got, "fallback")
 	}
 }
+
+func TestAuthStore_ResolveKey(t *testing.T) {
+	t.Setenv("PI_TEST_POOL_KEY", "env-key")
+	store := &AuthStore{Keys: map[string]string{}}
+
+	for ref, want := range map[string]string{
+		"literal-key":       "literal-key",
+		"$PI_TEST_POOL_KEY": "env-key",
+		"$PI_TEST_UNSET":    "",
+		"!echo cmd-key":     "cmd-key",
+		"!exit 1":           "",
+	} {
+		if got := store.ResolveKey(ref); got != want {
+			t.Errorf("ResolveKey(%q) = %q; want %q", ref, got, want)
+		}
+	}
+}
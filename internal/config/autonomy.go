@@ -0,0 +1,125 @@
+// ABOUTME: Autonomy presets for first-run permission onboarding: cautious, balanced, autonomous
+// ABOUTME: Maps one answer to a starter permissions block and explains the resulting rules in plain words
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Autonomy levels offered by the onboarding question
+// "How autonomous should the agent be here?".
+const (
+	AutonomyCautious   = "cautious"
+	AutonomyBalanced   = "balanced"
+	AutonomyAutonomous = "autonomous"
+)
+
+// AutonomyLevels lists the levels in the order they are offered.
+var AutonomyLevels = []string{AutonomyCautious, AutonomyBalanced, AutonomyAutonomous}
+
+// readOnlyRules are safe everywhere: they cannot change the project.
+var readOnlyRules = []string{"read", "grep", "find", "ls"}
+
+// StarterPermissions returns the permissions block for an autonomy level.
+func StarterPermissions(level string) (*PermissionsConfig, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case AutonomyCautious:
+		return &PermissionsConfig{
+			DefaultMode: "default",
+			Allow:       slices.Clone(readOnlyRules),
+			Ask:         []string{"edit", "write", "bash", "webfetch"},
+			Deny:        []string{"Bash(rm -rf *)", "Bash(git push *)", "Bash(sudo *)"},
+		}, nil
+	case AutonomyBalanced:
+		return &PermissionsConfig{
+			DefaultMode: "acceptEdits",
+			Allow:       slices.Concat(readOnlyRules, []string{"Bash(git status*)", "Bash(git diff*)", "Bash(git log*)"}),
+			Ask:         []string{"Bash(git push *)", "Bash(rm *)", "webfetch"},
+			Deny:        []string{"Bash(sudo *)"},
+		}, nil
+	case AutonomyAutonomous:
+		return &PermissionsConfig{
+			DefaultMode: "bypassPermissions",
+			Ask:         []string{"Bash(git push *)", "Bash(rm -rf *)"},
+			Deny:        []string{"Bash(sudo *)"},
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown autonomy level %q (want %s)", level, strings.Join(AutonomyLevels, ", "))
+}
+
+// modeDescriptions explains what each defaultMode does for unmatched tools.
+var modeDescriptions = map[string]string{
+	"default":           "other tools that change files or run commands ask first",
+	"acceptEdits":       "file edits are applied without asking; other commands ask first",
+	"plan":              "only read-only tools run until you leave plan mode",
+	"dontAsk":           "other tools that change files or run commands are refused",
+	"bypassPermissions": "everything else runs without asking",
+}
+
+// ExplainPermissions describes a permissions block in plain words, one rule
+// group per line. Deny beats ask, and ask beats allow.
+func ExplainPermissions(p *PermissionsConfig) string {
+	if p == nil {
+		return "No permission rules configured; every change asks first."
+	}
+	var b strings.Builder
+	if p.DefaultMode != "" {
+		desc := modeDescriptions[p.DefaultMode]
+		if desc == "" {
+			desc = "unknown mode"
+		}
+		fmt.Fprintf(&b, "Mode %s: %s.\n", p.DefaultMode, desc)
+	}
+	writeRuleGroup(&b, "Always allowed", p.Allow)
+	writeRuleGroup(&b, "Always asks", p.Ask)
+	writeRuleGroup(&b, "Never allowed", p.Deny)
+	b.WriteString("Deny rules win over ask rules, which win over allow rules.")
+	return b.String()
+}
+
+func writeRuleGroup(b *strings.Builder, label string, rules []string) {
+	if len(rules) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "%s: %s\n", label, strings.Join(rules, ", "))
+}
+
+// NeedsPermissionOnboarding reports whether the project at projectRoot has no
+// permission rules in either its settings.json or settings.local.json, the
+// user settings chose no default for every project either, and the managed
+// settings neither set permissions nor lock them.
+func NeedsPermissionOnboarding(projectRoot string) bool {
+	return needsPermissionOnboarding(managedSettingsPath(), UserSettingsFile(), ProjectSettingsFile(projectRoot), LocalSettingsFile(projectRoot))
+}
+
+func needsPermissionOnboarding(managedPath string, files ...string) bool {
+	if managed, err := loadFile(managedPath); err == nil && managed.Safety != nil {
+		for _, key := range managed.Safety.LockedKeys {
+			if key == "permissions" || strings.HasPrefix(key, "permissions.") {
+				return false
+			}
+		}
+	}
+	for _, path := range append([]string{managedPath}, files...) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var fields map[string]json.RawMessage
+		if json.Unmarshal(data, &fields) != nil {
+			// Unparseable settings are the user's to fix; don't pile on.
+			return false
+		}
+		for _, key := range []string{"permissions", "allow", "deny", "ask"} {
+			if _, ok := fields[key]; ok {
+				return false
+			}
+		}
+	}
+	return true
+}
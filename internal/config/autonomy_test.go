@@ -0,0 +1,164 @@
+// ABOUTME: Tests for autonomy presets, their plain-words explanation, and onboarding detection
+// ABOUTME: Round-trips a saved starter block through SaveProjectSetting and LoadAllWithHome
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStarterPermissions_Levels(t *testing.T) {
+	t.Parallel()
+	wantMode := map[string]string{
+		AutonomyCautious:   "default",
+		AutonomyBalanced:   "acceptEdits",
+		AutonomyAutonomous: "bypassPermissions",
+	}
+	for _, level := range AutonomyLevels {
+		p, err := StarterPermissions(level)
+		if err != nil {
+			t.Fatalf("StarterPermissions(%q) error = %v", level, err)
+		}
+		if p.DefaultMode != wantMode[level] {
+			t.Errorf("%s: DefaultMode = %q, want %q", level, p.DefaultMode, wantMode[level])
+		}
+		if len(p.Deny) == 0 {
+			t.Errorf("%s: every level should deny something", level)
+		}
+	}
+	if _, err := StarterPermissions("reckless"); err == nil {
+		t.Error("expected error for unknown level")
+	}
+}
+
+func TestStarterPermissions_DoesNotShareSlices(t *testing.T) {
+	t.Parallel()
+	a, _ := StarterPermissions(AutonomyCautious)
+	a.Allow[0] = "mutated"
+	b, _ := StarterPermissions(AutonomyCautious)
+	if b.Allow[0] == "mutated" {
+		t.Error("presets must return fresh slices")
+	}
+}
+
+func TestExplainPermissions(t *testing.T) {
+	t.Parallel()
+	p, _ := StarterPermissions(AutonomyBalanced)
+	got := ExplainPermissions(p)
+	for _, want := range []string{"Mode acceptEdits", "Always allowed: read", "Always asks: Bash(git push *)", "Never allowed: Bash(sudo *)"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("explanation missing %q:\n%s", want, got)
+		}
+	}
+	if !strings.Contains(ExplainPermissions(nil), "No permission rules") {
+		t.Errorf("nil explanation = %q", ExplainPermissions(nil))
+	}
+}
+
+func TestNeedsPermissionOnboarding(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	if !NeedsPermissionOnboarding(root) {
+		t.Fatal("fresh project should need onboarding")
+	}
+
+	if err := SaveLocalSetting(root, "outputStyle", "terse"); err != nil {
+		t.Fatal(err)
+	}
+	if !NeedsPermissionOnboarding(root) {
+		t.Error("unrelated local keys should not count as permissions")
+	}
+
+	if err := SaveLocalSetting(root, "allow", []string{"read"}); err != nil {
+		t.Fatal(err)
+	}
+	if NeedsPermissionOnboarding(root) {
+		t.Error("top-level allow rules count as configured permissions")
+	}
+}
+
+func TestNeedsPermissionOnboarding_Managed(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	managed := filepath.Join(dir, "managed-settings.json")
+	project := filepath.Join(dir, "settings.json")
+
+	if !needsPermissionOnboarding(managed, project) {
+		t.Fatal("no settings anywhere should need onboarding")
+	}
+	writeJSON(t, managed, `{"model":"org-model"}`)
+	if !needsPermissionOnboarding(managed, project) {
+		t.Error("managed settings without permissions should not skip onboarding")
+	}
+	writeJSON(t, managed, `{"permissions":{"defaultMode":"default"}}`)
+	if needsPermissionOnboarding(managed, project) {
+		t.Error("managed permissions count as configured")
+	}
+	writeJSON(t, managed, `{"safety":{"lockedKeys":["permissions.allow"]}}`)
+	if needsPermissionOnboarding(managed, project) {
+		t.Error("locked permissions should skip onboarding")
+	}
+}
+
+func TestCheckManagedLocks(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	managed := filepath.Join(dir, "managed-settings.json")
+	p, _ := StarterPermissions(AutonomyBalanced)
+	block := &Settings{Permissions: p}
+
+	if err := checkManagedLocks(managed, "settings.json", block); err != nil {
+		t.Errorf("no managed settings: %v", err)
+	}
+	writeJSON(t, managed, `{"safety":{"lockedKeys":["model"]}}`)
+	if err := checkManagedLocks(managed, "settings.json", block); err != nil {
+		t.Errorf("unrelated lock: %v", err)
+	}
+	writeJSON(t, managed, `{"permissions":{"defaultMode":"default"},"safety":{"lockedKeys":["permissions.defaultMode"]}}`)
+	err := checkManagedLocks(managed, "settings.json", block)
+	if err == nil || !strings.Contains(err.Error(), "permissions.defaultMode") {
+		t.Errorf("locked defaultMode: err = %v", err)
+	}
+	cautious, _ := StarterPermissions(AutonomyCautious)
+	if err := checkManagedLocks(managed, "settings.json", &Settings{Permissions: cautious}); err != nil {
+		t.Errorf("a block matching the locked value should pass: %v", err)
+	}
+}
+
+func TestSaveProjectSetting_StarterBlockRoundTrip(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	path := ProjectSettingsFile(root)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`{"model":"opus"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	p, _ := StarterPermissions(AutonomyCautious)
+	if err := SaveProjectSetting(root, "permissions", p); err != nil {
+		t.Fatalf("SaveProjectSetting() error = %v", err)
+	}
+	if NeedsPermissionOnboarding(root) {
+		t.Error("saved block should end onboarding")
+	}
+
+	s, err := LoadAllWithHome(root, t.TempDir(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Model != "opus" {
+		t.Errorf("Model = %q; unrelated key lost", s.Model)
+	}
+	if s.EffectiveDefaultMode() != "default" {
+		t.Errorf("EffectiveDefaultMode() = %q", s.EffectiveDefaultMode())
+	}
+	_, deny, _ := s.EffectivePermissions()
+	if len(deny) != len(p.Deny) {
+		t.Errorf("deny = %v, want %v", deny, p.Deny)
+	}
+}
@@ -4,11 +4,14 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"maps"
 	"os"
-	"path/filepath"
+	"slices"
+	"time"
 )
 
 // Settings holds the merged configuration.
@@ -19,6 +22,8 @@ type Settings struct {
 	MaxTokens   int               `json:"max_tokens,omitempty"`
 	Yolo        bool              `json:"yolo,omitempty"`
 	Thinking    bool              `json:"thinking,omitempty"`
+	Offline     bool              `json:"offline,omitempty"` // local models only; network features fail fast
+	ASCII       bool              `json:"ascii,omitempty"`   // draw the TUI with ASCII characters only
 	Env         map[string]string `json:"env,omitempty"`
 
 	// Permission rules (top-level, for backward compat)
@@ -42,19 +47,33 @@ type Settings struct {
 	// Compaction controls auto-compaction behavior
 	Compaction *CompactionSettings `json:"compaction,omitempty"`
 
+	// ContextEviction shrinks old tool results before compaction is needed
+	ContextEviction *ContextEvictionSettings `json:"contextEviction,omitempty"`
+
 	// Auto-compact threshold (percentage 1-100; 0 means use default 80%)
 	// Deprecated: use Compaction.Enabled instead
 	AutoCompactThreshold int `json:"autoCompactThreshold,omitempty"`
 
-	// Theme name or path to a custom JSON theme file
+	// Theme name: a built-in, a JSON file in a themes directory, or "auto"
+	// to pick light or dark from the terminal background
 	Theme string `json:"theme,omitempty"`
 
+	// ThinkingLevels maps a model ID to the thinking level last chosen for it
+	// (off, minimal, low, medium, high, xhigh); alt+t saves it here
+	ThinkingLevels map[string]string `json:"thinkingLevels,omitempty"`
+
+	// OutputStyle selects the response formatting persona (e.g. "explanatory", "teaching")
+	OutputStyle string `json:"outputStyle,omitempty"`
+
 	// ModelOverrides allows per-model customization of BaseURL, headers, etc.
 	ModelOverrides map[string]ModelOverride `json:"modelOverrides,omitempty"`
 
 	// Retry controls retry behavior for API calls
 	Retry *RetrySettings `json:"retry,omitempty"`
 
+	// Network configures the proxy, CA bundle, and provider HTTP timeouts, keepalive, and HTTP/2
+	Network *NetworkSettings `json:"network,omitempty"`
+
 	// Terminal controls terminal rendering behavior
 	Terminal *TerminalSettings `json:"terminal,omitempty"`
 
@@ -75,6 +94,73 @@ type Settings struct {
 
 	// Worktree configures default worktree isolation per session
 	Worktree *WorktreeSettings `json:"worktree,omitempty"`
+
+	// Display configures how timestamps are rendered in listings and exports
+	Display *DisplaySettings `json:"display,omitempty"`
+
+	// Suspend controls Ctrl+Z / SIGTSTP behavior in the interactive TUI
+	Suspend *SuspendSettings `json:"suspend,omitempty"`
+
+	// Notify controls how a finished background task is announced
+	Notify *NotifySettings `json:"notify,omitempty"`
+
+	// Minion routes simple turns to a cheaper model, escalating to the main one
+	Minion *MinionSettings `json:"minion,omitempty"`
+
+	// FetchCache caches web fetches and MCP resource reads
+	FetchCache *FetchCacheSettings `json:"fetchCache,omitempty"`
+
+	// Prewarm prepares the provider for the next turn while the user types
+	Prewarm *PrewarmSettings `json:"prewarm,omitempty"`
+
+	// Suggestions offers a predicted next prompt as ghost text after each turn
+	Suggestions *SuggestionsSettings `json:"suggestions,omitempty"`
+
+	// ThinkingRetention controls how thinking content is kept in sessions and exports
+	ThinkingRetention *ThinkingRetentionSettings `json:"thinkingRetention,omitempty"`
+
+	// Edit configures how the edit tool locates old_string
+	Edit *EditSettings `json:"edit,omitempty"`
+
+	// ToolLimits bounds how long a tool call may run and how much of its
+	// output the model sees, globally and per tool
+	ToolLimits *ToolLimitsSettings `json:"toolLimits,omitempty"`
+
+	// Redaction scrubs secrets from tool results before the model, the
+	// session file, and exports see them
+	Redaction *RedactionSettings `json:"redaction,omitempty"`
+
+	// Audit appends every tool call and permission decision to a JSONL
+	// file, optionally forwarded to syslog
+	Audit *AuditSettings `json:"audit,omitempty"`
+
+	// Container runs the bash tool in a Docker or Podman container with the
+	// project bind-mounted; file changes are confined to its mounts
+	Container *ContainerSettings `json:"container,omitempty"`
+
+	// Share configures /share uploads
+	Share *ShareSettings `json:"share,omitempty"`
+
+	// Mock configures the built-in mock provider (model "mock")
+	Mock *MockSettings `json:"mock,omitempty"`
+
+	// KeyPools lists several API keys per provider; calls rotate between them
+	KeyPools map[string][]PooledKey `json:"keyPools,omitempty"`
+
+	// Pipelines defines composite tools that chain existing tools
+	Pipelines map[string]PipelineDef `json:"pipelines,omitempty"`
+
+	// Plugins declares subprocess plugins by name; an entry overrides the
+	// plugin of the same name discovered in a plugins directory
+	Plugins map[string]PluginDef `json:"plugins,omitempty"`
+
+	// ExcludeDirs adds directory names or globs that file scans (find, grep,
+	// @-mentions) prune to the defaults; "!name" scans a default one again
+	ExcludeDirs []string `json:"excludeDirs,omitempty"`
+
+	// Keybindings rebinds interactive-mode actions to key chords, e.g.
+	// {"background": ["ctrl+x"]}; unlisted actions keep their defaults
+	Keybindings map[string][]string `json:"keybindings,omitempty"`
 }
 
 // ModelOverride allows per-model customization.
@@ -83,6 +169,18 @@ type ModelOverride struct {
 	CustomHeaders    map[string]string `json:"customHeaders,omitempty"`
 	MaxOutputTokens  int               `json:"maxOutputTokens,omitempty"`
 	ContextWindow    int               `json:"contextWindow,omitempty"`
+
+	// Capabilities, for models that handle less than the defaults assume;
+	// unset keeps the built-in or default value.
+	SupportsTools             *bool `json:"supportsTools,omitempty"`             // false: send no tool schemas
+	SupportsParallelToolCalls *bool `json:"supportsParallelToolCalls,omitempty"` // false: ask for one tool call per turn
+	SupportsImages            *bool `json:"supportsImages,omitempty"`            // false: replace images with a note
+	MaxToolSchemas            int   `json:"maxToolSchemas,omitempty"`            // send at most this many tools, simplified; -1 = all
+
+	// ToolCalling is "native" (function calling), "text" (tools described
+	// in the prompt, called in fenced tool blocks), or "none". Unset, a
+	// model with supportsTools false calls tools in text.
+	ToolCalling string `json:"toolCalling,omitempty"`
 }
 
 // RetrySettings controls retry behavior for API calls.
@@ -118,8 +216,41 @@ func (r *RetrySettings) EffectiveMaxDelay() int {
 
 // TerminalSettings controls terminal rendering.
 type TerminalSettings struct {
-	LineWidth int  `json:"lineWidth,omitempty"` // max line width; 0 = auto-detect
-	Pager     bool `json:"pager,omitempty"`     // enable pager for long output
+	LineWidth       int  `json:"lineWidth,omitempty"`       // max line width; 0 = auto-detect
+	Pager           bool `json:"pager,omitempty"`           // enable pager for long output
+	ToolOutputLines int  `json:"toolOutputLines,omitempty"` // tool output lines shown before folding; 0 = 10, negative = none
+
+	// Shell runs the bash tool, ! commands, hooks, and the status line
+	// command; nil detects bash (PowerShell or cmd on Windows without it).
+	Shell *ShellSettings `json:"shell,omitempty"`
+}
+
+// ShellSettings picks the shell commands run in.
+type ShellSettings struct {
+	Path string   `json:"path,omitempty"` // executable, or a name looked up on PATH (e.g. "zsh", "pwsh")
+	Args []string `json:"args,omitempty"` // arguments before the command; nil = the shell's defaults, e.g. ["-f", "-c"] for zsh
+}
+
+// EffectiveShell returns the configured shell path and arguments; an
+// empty path means detect.
+func (s *TerminalSettings) EffectiveShell() (path string, args []string) {
+	if s == nil || s.Shell == nil {
+		return "", nil
+	}
+	return s.Shell.Path, s.Shell.Args
+}
+
+// EffectiveToolOutputLines returns how many lines of a tool result are
+// shown before the rest is folded: the default (10) when unset, and 0 when
+// negative, which folds every result.
+func (s *TerminalSettings) EffectiveToolOutputLines() int {
+	switch {
+	case s == nil || s.ToolOutputLines == 0:
+		return 10
+	case s.ToolOutputLines < 0:
+		return 0
+	}
+	return s.ToolOutputLines
 }
 
 // IntentSettings configures automatic intent classification.
@@ -158,6 +289,27 @@ type PromptsSettings struct {
 	ActiveVersion         string `json:"activeVersion,omitempty"`         // e.g., "v1.0.0"
 	OverridesDir          string `json:"overridesDir,omitempty"`          // path to overrides directory
 	MaxSystemPromptTokens int    `json:"maxSystemPromptTokens,omitempty"` // budget; default 4096
+
+	// ModelFamily selects the prompt adapter: "auto" (default) detects it from
+	// the model, a family name forces one, and "none" disables adapters.
+	ModelFamily string `json:"modelFamily,omitempty"`
+	// Adapters overrides the tone/format sections per family ("claude", "gpt", "gemini", "small-local").
+	Adapters map[string]PromptAdapterSettings `json:"adapters,omitempty"`
+}
+
+// PromptAdapterSettings overrides a model family's prompt adapter. Empty
+// fields keep the built-in section.
+type PromptAdapterSettings struct {
+	Tone   string `json:"tone,omitempty"`
+	Format string `json:"format,omitempty"`
+}
+
+// EffectiveModelFamily returns the configured model family or "auto".
+func (s *PromptsSettings) EffectiveModelFamily() string {
+	if s == nil || s.ModelFamily == "" {
+		return "auto"
+	}
+	return s.ModelFamily
 }
 
 // EffectiveMaxSystemPromptTokens returns the budget or default (4096).
@@ -221,8 +373,42 @@ func (s *TelemetrySettings) EffectiveWarnAtPct() int {
 
 // SafetySettings configures safety guardrails.
 type SafetySettings struct {
-	NeverModify []string `json:"neverModify,omitempty"` // glob patterns for files that must never be modified
-	LockedKeys  []string `json:"lockedKeys,omitempty"`  // config keys that cannot be overridden at lower levels
+	NeverModify     []string `json:"neverModify,omitempty"`     // glob patterns for files that must never be modified
+	LockedKeys      []string `json:"lockedKeys,omitempty"`      // config keys that cannot be overridden at lower levels
+	DenySecretReads *bool    `json:"denySecretReads,omitempty"` // refuse reads of secret files; nil means off
+	SecretFiles     []string `json:"secretFiles,omitempty"`     // gitignore-style patterns added to DefaultSecretFiles
+}
+
+// DefaultSecretFiles are the gitignore-style patterns of files holding
+// credentials. Later patterns win, so "!" re-includes templates.
+var DefaultSecretFiles = []string{
+	".env", ".env.*", "!.env.example", "!.env.sample", "!.env.template",
+	"*.pem", "*.key", "*.p12", "*.pfx",
+	"id_rsa", "id_dsa", "id_ecdsa", "id_ed25519",
+	".netrc", ".pgpass",
+}
+
+// DeniesSecretReads reports whether reads of secret files are refused.
+func (s *SafetySettings) DeniesSecretReads() bool {
+	return s != nil && s.DenySecretReads != nil && *s.DenySecretReads
+}
+
+// EffectiveSecretFiles returns DefaultSecretFiles followed by the
+// configured patterns, so a configured "!pattern" can re-include a default.
+func (s *SafetySettings) EffectiveSecretFiles() []string {
+	patterns := slices.Clone(DefaultSecretFiles)
+	if s != nil {
+		patterns = append(patterns, s.SecretFiles...)
+	}
+	return patterns
+}
+
+// EffectiveNeverModify returns the protected file globs, or nil.
+func (s *SafetySettings) EffectiveNeverModify() []string {
+	if s == nil {
+		return nil
+	}
+	return s.NeverModify
 }
 
 // WorktreeSettings configures default worktree isolation per session.
@@ -239,6 +425,176 @@ func (w *WorktreeSettings) IsEnabled() bool {
 	return *w.Enabled
 }
 
+// DisplaySettings controls how stored UTC timestamps are presented to the user.
+type DisplaySettings struct {
+	TimeFormat string `json:"timeFormat,omitempty"` // Go time layout; default "2006-01-02 15:04 MST"
+	TimeZone   string `json:"timeZone,omitempty"`   // IANA zone name; default local zone
+}
+
+// EffectiveTimeFormat returns TimeFormat or the default layout.
+func (d *DisplaySettings) EffectiveTimeFormat() string {
+	if d == nil || d.TimeFormat == "" {
+		return "2006-01-02 15:04 MST"
+	}
+	return d.TimeFormat
+}
+
+// EffectiveLocation resolves TimeZone to a location, falling back to
+// time.Local when unset or unknown.
+func (d *DisplaySettings) EffectiveLocation() *time.Location {
+	if d == nil || d.TimeZone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(d.TimeZone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// SuspendSettings controls what happens to a running agent turn on Ctrl+Z.
+type SuspendSettings struct {
+	PauseTurns *bool `json:"pauseTurns,omitempty"` // nil = false: tool commands keep running
+}
+
+// ShouldPauseTurns reports whether suspending should also stop running tool
+// processes. Defaults to false so long commands finish while suspended.
+func (s *SuspendSettings) ShouldPauseTurns() bool {
+	if s == nil || s.PauseTurns == nil {
+		return false
+	}
+	return *s.PauseTurns
+}
+
+// NotifySettings controls the notification sent when a background task
+// finishes.
+type NotifySettings struct {
+	Method  string `json:"method,omitempty"`  // "osc777" (default), "bell", "command", or "off"
+	Command string `json:"command,omitempty"` // shell command run by the "command" method
+}
+
+// EffectiveMethod returns the notification method, defaulting to "osc777".
+// "command" without a command falls back to "bell".
+func (n *NotifySettings) EffectiveMethod() string {
+	if n == nil || n.Method == "" {
+		return "osc777"
+	}
+	if n.Method == "command" && n.Command == "" {
+		return "bell"
+	}
+	return n.Method
+}
+
+// MinionSettings configures the minion transform: a cheaper model that serves
+// simple turns while the main model handles escalations.
+type MinionSettings struct {
+	Model          string `json:"model,omitempty"`          // model ID or alias; empty disables the minion
+	Mode           string `json:"mode,omitempty"`           // "off", "auto", "force", or "plural"; default "auto"
+	MaxInputTokens int    `json:"maxInputTokens,omitempty"` // auto escalates above this estimate; default 16000
+	Parallel       int    `json:"parallel,omitempty"`       // concurrent per-file minions in plural mode; default 4
+}
+
+// EffectiveMode returns Mode or the default ("auto").
+func (m *MinionSettings) EffectiveMode() string {
+	if m == nil || m.Mode == "" {
+		return "auto"
+	}
+	return m.Mode
+}
+
+// FetchCacheSettings configures the cache for web fetches and MCP resources.
+// Documentation pages persist across sessions; everything else lasts one session.
+type FetchCacheSettings struct {
+	Enabled     *bool    `json:"enabled,omitempty"`     // nil = true
+	TTL         int      `json:"ttl,omitempty"`         // seconds a page stays fresh; default 900
+	DocsTTL     int      `json:"docsTTL,omitempty"`     // seconds a docs page stays fresh; default 86400
+	DocsDomains []string `json:"docsDomains,omitempty"` // extra hosts treated as documentation
+}
+
+// IsEnabled returns whether fetch caching is on (default true).
+func (f *FetchCacheSettings) IsEnabled() bool {
+	if f == nil || f.Enabled == nil {
+		return true
+	}
+	return *f.Enabled
+}
+
+// EffectiveTTL returns TTL as a duration, or the 15-minute default.
+func (f *FetchCacheSettings) EffectiveTTL() time.Duration {
+	if f == nil || f.TTL <= 0 {
+		return 15 * time.Minute
+	}
+	return time.Duration(f.TTL) * time.Second
+}
+
+// EffectiveDocsTTL returns DocsTTL as a duration, or the 24-hour default.
+func (f *FetchCacheSettings) EffectiveDocsTTL() time.Duration {
+	if f == nil || f.DocsTTL <= 0 {
+		return 24 * time.Hour
+	}
+	return time.Duration(f.DocsTTL) * time.Second
+}
+
+// Prewarm modes.
+const (
+	PrewarmOff        = "off"
+	PrewarmConnection = "connection" // open a pooled connection; free
+	PrewarmPrefix     = "prefix"     // also cache the prompt prefix; billed as a one-token call
+)
+
+// PrewarmSettings configures speculative preparation for the next turn,
+// started when the user begins typing while the agent is idle.
+type PrewarmSettings struct {
+	Mode string `json:"mode,omitempty"` // "off", "connection", or "prefix"; default "off"
+}
+
+// EffectiveMode returns Mode or the default ("off").
+func (p *PrewarmSettings) EffectiveMode() string {
+	if p == nil || p.Mode == "" {
+		return PrewarmOff
+	}
+	return p.Mode
+}
+
+// SuggestionsSettings configures next-prompt suggestions: after a turn the
+// minion model, or the main one without a minion, predicts a follow-up that
+// Tab accepts.
+type SuggestionsSettings struct {
+	Enabled *bool `json:"enabled,omitempty"` // nil = false
+}
+
+// IsEnabled reports whether suggestions are requested (default false).
+func (s *SuggestionsSettings) IsEnabled() bool {
+	return s != nil && s.Enabled != nil && *s.Enabled
+}
+
+// Thinking retention modes.
+const (
+	ThinkingFull       = "full"       // keep thinking verbatim
+	ThinkingSummarized = "summarized" // keep a short extract and the omitted length
+	ThinkingStripped   = "stripped"   // drop thinking entirely
+)
+
+// ThinkingRetentionSettings controls whether thinking/reasoning content is
+// written to session files and exports. The live conversation is unaffected.
+type ThinkingRetentionSettings struct {
+	Mode string `json:"mode,omitempty"` // "full", "summarized", or "stripped"; default "summarized"
+}
+
+// EffectiveMode returns Mode, or "summarized" when unset or unrecognized so
+// a typo never widens what is stored.
+func (t *ThinkingRetentionSettings) EffectiveMode() string {
+	if t == nil {
+		return ThinkingSummarized
+	}
+	switch t.Mode {
+	case ThinkingFull, ThinkingStripped:
+		return t.Mode
+	default:
+		return ThinkingSummarized
+	}
+}
+
 // PermissionsConfig holds nested permission settings (Claude Code format).
 type PermissionsConfig struct {
 	Allow       []string `json:"allow,omitempty"`
@@ -252,6 +608,10 @@ type StatusLineConfig struct {
 	Type    string `json:"type,omitempty"`    // "command" or empty for built-in
 	Command string `json:"command,omitempty"` // Shell command for external status line
 	Padding int    `json:"padding,omitempty"` // Padding characters
+
+	// RefreshInterval re-runs Command every this many seconds while idle;
+	// 0 runs it only at startup and after each turn or ! command.
+	RefreshInterval int `json:"refreshInterval,omitempty"`
 }
 
 // HookDef describes a lifecycle hook.
@@ -292,6 +652,300 @@ func (c *CompactionSettings) EffectiveKeepRecentTokens() int {
 	return c.KeepRecentTokens
 }
 
+// EditSettings configures the edit tool's match policy.
+type EditSettings struct {
+	// Strategies are tried in order until one finds old_string: "exact",
+	// "whitespace", "anchor". Empty uses exact then whitespace.
+	Strategies []string `json:"strategies,omitempty"`
+}
+
+// EffectiveStrategies returns the configured strategies, or nil for the
+// tool default.
+func (e *EditSettings) EffectiveStrategies() []string {
+	if e == nil {
+		return nil
+	}
+	return e.Strategies
+}
+
+// Tool limit defaults.
+const (
+	DefaultToolTimeout        = 10 * time.Minute
+	DefaultToolMaxOutputBytes = 100 * 1024
+)
+
+// ToolLimit bounds one tool's calls. Zero fields fall back to the global
+// limit; negative ones lift it.
+type ToolLimit struct {
+	Timeout        int `json:"timeout,omitempty"`        // seconds a call may run
+	MaxOutputBytes int `json:"maxOutputBytes,omitempty"` // bytes of a result the model sees
+}
+
+// ToolLimitsSettings bounds tool calls: Timeout and MaxOutputBytes apply
+// to every tool, and Tools overrides them by tool name.
+type ToolLimitsSettings struct {
+	Timeout        int                  `json:"timeout,omitempty"`        // seconds; default 600, negative = none
+	MaxOutputBytes int                  `json:"maxOutputBytes,omitempty"` // default 102400, negative = none
+	Tools          map[string]ToolLimit `json:"tools,omitempty"`          // per-tool overrides
+}
+
+// For returns the timeout and output cap for tool; zero means unlimited.
+func (t *ToolLimitsSettings) For(tool string) (time.Duration, int) {
+	timeout, maxBytes := DefaultToolTimeout, DefaultToolMaxOutputBytes
+	if t == nil {
+		return timeout, maxBytes
+	}
+	secs, n := t.Timeout, t.MaxOutputBytes
+	if o, ok := t.Tools[tool]; ok {
+		if o.Timeout != 0 {
+			secs = o.Timeout
+		}
+		if o.MaxOutputBytes != 0 {
+			n = o.MaxOutputBytes
+		}
+	}
+	switch {
+	case secs < 0:
+		timeout = 0
+	case secs > 0:
+		timeout = time.Duration(secs) * time.Second
+	}
+	switch {
+	case n < 0:
+		maxBytes = 0
+	case n > 0:
+		maxBytes = n
+	}
+	return timeout, maxBytes
+}
+
+// RedactionSettings configures the secret redaction of tool results and
+// exports.
+type RedactionSettings struct {
+	Enabled  *bool    `json:"enabled,omitempty"`  // nil = true
+	Patterns []string `json:"patterns,omitempty"` // regular expressions added to the built-in ones
+}
+
+// IsEnabled reports whether secrets are redacted (default true).
+func (r *RedactionSettings) IsEnabled() bool {
+	return r == nil || r.Enabled == nil || *r.Enabled
+}
+
+// EffectivePatterns returns the user patterns, or nil.
+func (r *RedactionSettings) EffectivePatterns() []string {
+	if r == nil {
+		return nil
+	}
+	return r.Patterns
+}
+
+// AuditSettings configures the append-only audit log of tool calls and
+// permission decisions.
+type AuditSettings struct {
+	Enabled *bool  `json:"enabled,omitempty"` // nil = false
+	Path    string `json:"path,omitempty"`    // JSONL file; default ~/.pi-go/audit.jsonl
+	Syslog  string `json:"syslog,omitempty"`  // "local", "udp://host:port", or "tcp://host:port"; "" = off
+}
+
+// IsEnabled reports whether the audit log is written (default false).
+func (a *AuditSettings) IsEnabled() bool {
+	return a != nil && a.Enabled != nil && *a.Enabled
+}
+
+// EffectivePath returns the audit log file, ~/.pi-go/audit.jsonl unless set.
+func (a *AuditSettings) EffectivePath() string {
+	if a == nil || a.Path == "" {
+		return AuditFile()
+	}
+	return a.Path
+}
+
+// EffectiveSyslog returns the syslog destination, or "" for none.
+func (a *AuditSettings) EffectiveSyslog() string {
+	if a == nil {
+		return ""
+	}
+	return a.Syslog
+}
+
+// ContainerSettings configures container mode, which runs the bash tool's
+// commands in a Docker or Podman container with the project bind-mounted at
+// its own path.
+type ContainerSettings struct {
+	Enabled   *bool    `json:"enabled,omitempty"`   // nil = false; --container turns it on
+	Runtime   string   `json:"runtime,omitempty"`   // "docker" or "podman"; "" = whichever is installed
+	Image     string   `json:"image,omitempty"`     // default debian:stable-slim
+	Network   string   `json:"network,omitempty"`   // "none", "bridge", "host", or a named network; default none
+	CPUs      string   `json:"cpus,omitempty"`      // e.g. "2"; "" = unlimited
+	Memory    string   `json:"memory,omitempty"`    // e.g. "4g"; "" = unlimited
+	PidsLimit int      `json:"pidsLimit,omitempty"` // 0 = unlimited
+	Mounts    []string `json:"mounts,omitempty"`    // extra bind mounts, "host[:container][:ro]"
+	Shell     string   `json:"shell,omitempty"`     // shell inside the container; default /bin/sh
+
+	// Devcontainer uses the project's devcontainer.json for the container:
+	// nil = offer it at startup, true = always, false = never
+	Devcontainer *bool `json:"devcontainer,omitempty"`
+}
+
+// IsEnabled reports whether container mode is on (default false). Using
+// the devcontainer turns it on.
+func (c *ContainerSettings) IsEnabled() bool {
+	return c != nil && ((c.Enabled != nil && *c.Enabled) || c.UsesDevcontainer())
+}
+
+// UsesDevcontainer reports whether the container is the project's
+// devcontainer, when it has one (default false).
+func (c *ContainerSettings) UsesDevcontainer() bool {
+	return c != nil && c.Devcontainer != nil && *c.Devcontainer
+}
+
+// OffersDevcontainer reports whether a project's devcontainer is offered
+// at startup: container mode is off and the user has not decided.
+func (c *ContainerSettings) OffersDevcontainer() bool {
+	return c == nil || (c.Devcontainer == nil && !c.IsEnabled())
+}
+
+// PooledKey is one API key of a provider's key pool.
+type PooledKey struct {
+	Name      string `json:"name,omitempty"`      // label in /cost; defaults to key-N
+	Key       string `json:"key"`                 // literal, "!command", or "$ENV_VAR"
+	MaxTokens int    `json:"maxTokens,omitempty"` // input plus output tokens per session; 0 = uncapped
+}
+
+// MockSettings configures the mock provider used for local development.
+type MockSettings struct {
+	Script       string `json:"script,omitempty"`       // YAML/JSON file of scripted replies and tool calls
+	LatencyMs    int    `json:"latencyMs,omitempty"`    // delay before the first event
+	ChunkDelayMs int    `json:"chunkDelayMs,omitempty"` // delay between streamed words
+	InputTokens  int    `json:"inputTokens,omitempty"`  // reported input tokens; 0 = estimate
+	OutputTokens int    `json:"outputTokens,omitempty"` // reported output tokens; 0 = estimate
+}
+
+// Share backends.
+const (
+	ShareBackendGist  = "gist"  // GitHub gist via GITHUB_TOKEN
+	ShareBackendPaste = "paste" // POST to PasteURL; the response body is the link
+)
+
+// ShareSettings configures /share. The transcript is encrypted before it
+// leaves the machine unless Encrypt is false; the key travels only in the
+// link fragment.
+type ShareSettings struct {
+	Backend       string `json:"backend,omitempty"`       // "gist" (default) or "paste"
+	PasteURL      string `json:"pasteUrl,omitempty"`      // endpoint for the paste backend
+	Encrypt       *bool  `json:"encrypt,omitempty"`       // nil = true
+	RedactPaths   *bool  `json:"redactPaths,omitempty"`   // nil = true: home and project paths
+	RedactSecrets *bool  `json:"redactSecrets,omitempty"` // nil = true: API keys, tokens, private keys
+}
+
+// EffectiveBackend returns the backend, or "gist".
+func (s *ShareSettings) EffectiveBackend() string {
+	if s == nil || s.Backend == "" {
+		return ShareBackendGist
+	}
+	return s.Backend
+}
+
+// EffectivePasteURL returns the paste endpoint, or "" when unset.
+func (s *ShareSettings) EffectivePasteURL() string {
+	if s == nil {
+		return ""
+	}
+	return s.PasteURL
+}
+
+// EncryptEnabled reports whether uploads are encrypted (default true).
+func (s *ShareSettings) EncryptEnabled() bool {
+	return s == nil || s.Encrypt == nil || *s.Encrypt
+}
+
+// RedactPathsEnabled reports whether file paths are redacted (default true).
+func (s *ShareSettings) RedactPathsEnabled() bool {
+	return s == nil || s.RedactPaths == nil || *s.RedactPaths
+}
+
+// RedactSecretsEnabled reports whether secrets are redacted (default true).
+func (s *ShareSettings) RedactSecretsEnabled() bool {
+	return s == nil || s.RedactSecrets == nil || *s.RedactSecrets
+}
+
+// PipelineDef declares a composite tool: the model sees one tool, and each
+// call runs Steps in order. String arguments are Go templates over
+// .params, .steps (outputs by step name), .prev, and .item.
+type PipelineDef struct {
+	Description string                      `json:"description"`
+	Parameters  map[string]PipelineParamDef `json:"parameters,omitempty"`
+	Steps       []PipelineStepDef           `json:"steps"`
+}
+
+// PluginDef declares a plugin: a subprocess speaking the plugin JSON
+// protocol on stdin/stdout to add tools, slash commands, and event hooks.
+type PluginDef struct {
+	Command  string            `json:"command,omitempty"`
+	Args     []string          `json:"args,omitempty"`
+	Env      map[string]string `json:"env,omitempty"`
+	Disabled bool              `json:"disabled,omitempty"` // turns off a discovered plugin
+}
+
+// PipelineParamDef is one parameter of a composite tool.
+type PipelineParamDef struct {
+	Type        string `json:"type,omitempty"` // JSON schema type; default "string"
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// PipelineStepDef runs one tool. With ForEach, the step runs once per
+// distinct non-empty line of the rendered template, exposed as .item.
+type PipelineStepDef struct {
+	Name            string         `json:"name,omitempty"` // key in .steps; defaults to the tool name
+	Tool            string         `json:"tool"`
+	Args            map[string]any `json:"args,omitempty"`
+	ForEach         string         `json:"forEach,omitempty"`
+	Match           string         `json:"match,omitempty"`           // regexp selecting ForEach lines; its first group is the item
+	ContinueOnError bool           `json:"continueOnError,omitempty"` // keep going after this step fails
+}
+
+// ContextEvictionSettings caps the size of tool results once they are a few
+// user turns old. File reads are replaced by a stub naming the path.
+type ContextEvictionSettings struct {
+	Enabled        *bool    `json:"enabled,omitempty"`        // nil = true (default on)
+	AfterTurns     int      `json:"afterTurns,omitempty"`     // user turns before a result is eligible (default 3)
+	MaxResultBytes int      `json:"maxResultBytes,omitempty"` // larger eligible results are shrunk (default 4096)
+	PinnedTools    []string `json:"pinnedTools,omitempty"`    // tools whose results are never shrunk (default ["skill"])
+}
+
+// IsEnabled returns whether eviction is enabled (default true).
+func (c *ContextEvictionSettings) IsEnabled() bool {
+	if c == nil || c.Enabled == nil {
+		return true
+	}
+	return *c.Enabled
+}
+
+// EffectiveAfterTurns returns AfterTurns or the default (3).
+func (c *ContextEvictionSettings) EffectiveAfterTurns() int {
+	if c == nil || c.AfterTurns == 0 {
+		return 3
+	}
+	return c.AfterTurns
+}
+
+// EffectiveMaxResultBytes returns MaxResultBytes or the default (4096).
+func (c *ContextEvictionSettings) EffectiveMaxResultBytes() int {
+	if c == nil || c.MaxResultBytes == 0 {
+		return 4096
+	}
+	return c.MaxResultBytes
+}
+
+// EffectivePinnedTools returns PinnedTools or the default (["skill"]).
+func (c *ContextEvictionSettings) EffectivePinnedTools() []string {
+	if c == nil || len(c.PinnedTools) == 0 {
+		return []string{"skill"}
+	}
+	return c.PinnedTools
+}
+
 // SandboxSettings configures the OS sandbox.
 type SandboxSettings struct {
 	ExcludedCommands []string `json:"excludedCommands,omitempty"`
@@ -324,11 +978,39 @@ func loadFile(path string) (*Settings, error) {
 	}
 	var s Settings
 	if err := json.Unmarshal(data, &s); err != nil {
-		return nil, fmt.Errorf("parsing %s: %w", path, err)
+		return nil, fmt.Errorf("parsing %s: %w", path, jsonPosition(data, err))
 	}
 	return &s, nil
 }
 
+// CheckSettingsFile reports whether the settings file at path parses.
+// LoadAll skips a file that does not, so pi-go doctor uses this to name the
+// line at fault. A missing file yields an os.IsNotExist error.
+func CheckSettingsFile(path string) error {
+	_, err := loadFile(path)
+	return err
+}
+
+// jsonPosition prefixes a JSON syntax or type error with the line and
+// column of the offending byte in data.
+func jsonPosition(data []byte, err error) error {
+	var offset int64
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+	default:
+		return err
+	}
+	before := data[:min(int(offset), len(data))]
+	line := bytes.Count(before, []byte("\n")) + 1
+	col := len(before) - bytes.LastIndexByte(before, '\n') - 1
+	return fmt.Errorf("line %d, column %d: %w", line, col, err)
+}
+
 // SettingsLevel represents the precedence level of a settings source.
 type SettingsLevel int
 
@@ -346,58 +1028,61 @@ const (
 // Level  1: .pi-go/ project settings
 // Level  2: .pi-go/settings.local.json (gitignored)
 // Level  3: CLI overrides
-// Level  4: Managed settings (/etc/pi-go/ or ~/Library/Application Support/)
+// Level  4: Managed settings (/etc/pi-go/ or /Library/Application Support/pi-go/)
+// It fails when a lower level sets a key the managed settings lock.
 func LoadAllWithHome(projectRoot, homeDir string, cliOverrides *Settings) (*Settings, error) {
+	return loadLevels(projectRoot, homeDir, managedSettingsPath(), cliOverrides)
+}
+
+// loadLevels implements LoadAllWithHome with an explicit managed settings path.
+func loadLevels(projectRoot, homeDir, managedPath string, cliOverrides *Settings) (*Settings, error) {
 	result := &Settings{}
+	var lower []settingsSource // every level below managed, for locked key checks
 
 	// Level -1: ~/.pi/agent/ compat (lowest priority, base layer)
 	if piDir := PiAgentDirFrom(homeDir); piDir != "" {
 		if piSettings, _, err := LoadPiCompat(piDir); err == nil {
 			result = merge(result, piSettings)
+			lower = append(lower, settingsSource{name: piDir, raw: rawSettings(piSettings)})
 		}
 	}
 
 	// Level 0: User settings (old config.json + new settings.json)
-	sources := []string{
-		filepath.Join(homeDir, ".pi-go", "config.json"),
-		filepath.Join(homeDir, ".pi-go", "settings.json"),
-	}
-	for _, path := range sources {
-		if s, err := loadFile(path); err == nil {
-			result = merge(result, s)
-		}
-	}
-
 	// Level 1: Project settings
-	projectSources := []string{
-		filepath.Join(projectRoot, ".pi-go", "config.json"),
-		filepath.Join(projectRoot, ".pi-go", "settings.json"),
-	}
-	for _, path := range projectSources {
+	// Level 2: Local settings (gitignored)
+	for _, path := range levelFiles(projectRoot, homeDir) {
 		if s, err := loadFile(path); err == nil {
 			result = merge(result, s)
+			lower = append(lower, settingsSource{name: path, raw: rawSettings(s)})
 		}
 	}
 
-	// Level 2: Local settings (gitignored)
-	localPath := filepath.Join(projectRoot, ".pi-go", "settings.local.json")
-	if s, err := loadFile(localPath); err == nil {
-		result = merge(result, s)
-	}
-
 	// Level 3: CLI overrides
 	if cliOverrides != nil {
 		result = merge(result, cliOverrides)
+		lower = append(lower, settingsSource{name: "command-line flags", raw: rawSettings(cliOverrides)})
 	}
 
 	// Level 4: Managed settings (enterprise/system)
-	managedPath := ManagedSettingsFile()
-	if s, err := loadFile(managedPath); err == nil {
-		result = merge(result, s)
+	managed, err := loadFile(managedPath)
+	switch {
+	case err == nil:
+		if err := checkLockedKeys(settingsSource{name: managedPath, raw: rawSettings(managed)}, managed.Safety, lower); err != nil {
+			return nil, err
+		}
+		result = merge(result, managed)
+	case !os.IsNotExist(err):
+		return nil, fmt.Errorf("loading managed settings: %w", err)
 	}
 
-	// Expand ${VAR} patterns in string fields
+	// Expand ${VAR} patterns in string fields, then read secret references
 	ResolveEnvVars(result)
+	if err := ResolveSecrets(result); err != nil {
+		return nil, err
+	}
+	if err := result.Network.LoadCACert(); err != nil {
+		return nil, err
+	}
 
 	return result, nil
 }
@@ -462,9 +1147,21 @@ func merge(global, project *Settings) *Settings {
 	if project.Thinking {
 		result.Thinking = true
 	}
+	if project.Offline {
+		result.Offline = true
+	}
+	if project.ASCII {
+		result.ASCII = true
+	}
 	if project.DefaultMode != "" {
 		result.DefaultMode = project.DefaultMode
 	}
+	if project.OutputStyle != "" {
+		result.OutputStyle = project.OutputStyle
+	}
+	if project.Theme != "" {
+		result.Theme = project.Theme
+	}
 
 	// Merge env maps
 	if len(project.Env) > 0 {
@@ -538,6 +1235,25 @@ func merge(global, project *Settings) *Settings {
 		}
 	}
 
+	// ContextEviction: merge if present
+	if project.ContextEviction != nil {
+		if result.ContextEviction == nil {
+			result.ContextEviction = &ContextEvictionSettings{}
+		}
+		if project.ContextEviction.Enabled != nil {
+			result.ContextEviction.Enabled = project.ContextEviction.Enabled
+		}
+		if project.ContextEviction.AfterTurns != 0 {
+			result.ContextEviction.AfterTurns = project.ContextEviction.AfterTurns
+		}
+		if project.ContextEviction.MaxResultBytes != 0 {
+			result.ContextEviction.MaxResultBytes = project.ContextEviction.MaxResultBytes
+		}
+		if len(project.ContextEviction.PinnedTools) > 0 {
+			result.ContextEviction.PinnedTools = project.ContextEviction.PinnedTools
+		}
+	}
+
 	// Sandbox: override if present
 	if len(project.Sandbox.ExcludedCommands) > 0 {
 		result.Sandbox.ExcludedCommands = project.Sandbox.ExcludedCommands
@@ -570,6 +1286,31 @@ func merge(global, project *Settings) *Settings {
 		}
 	}
 
+	// Network: merge field-by-field; overrides merge per key
+	if project.Network != nil {
+		if result.Network == nil {
+			result.Network = &NetworkSettings{}
+		}
+		result.Network.HTTPSettings = result.Network.HTTPSettings.overlay(project.Network.HTTPSettings)
+		if len(project.Network.Overrides) > 0 {
+			if result.Network.Overrides == nil {
+				result.Network.Overrides = make(map[string]HTTPSettings)
+			}
+			for key, o := range project.Network.Overrides {
+				result.Network.Overrides[key] = result.Network.Overrides[key].overlay(o)
+			}
+		}
+		if project.Network.Proxy != "" {
+			result.Network.Proxy = project.Network.Proxy
+		}
+		if project.Network.CACert != "" {
+			result.Network.CACert = project.Network.CACert
+		}
+		if project.Network.InsecureSkipVerify {
+			result.Network.InsecureSkipVerify = true
+		}
+	}
+
 	// Terminal: override if present
 	if project.Terminal != nil {
 		result.Terminal = project.Terminal
@@ -605,6 +1346,15 @@ func merge(global, project *Settings) *Settings {
 		if project.Prompts.MaxSystemPromptTokens != 0 {
 			result.Prompts.MaxSystemPromptTokens = project.Prompts.MaxSystemPromptTokens
 		}
+		if project.Prompts.ModelFamily != "" {
+			result.Prompts.ModelFamily = project.Prompts.ModelFamily
+		}
+		if len(project.Prompts.Adapters) > 0 {
+			if result.Prompts.Adapters == nil {
+				result.Prompts.Adapters = make(map[string]PromptAdapterSettings)
+			}
+			maps.Copy(result.Prompts.Adapters, project.Prompts.Adapters)
+		}
 	}
 
 	// Personality: merge if present
@@ -650,6 +1400,12 @@ func merge(global, project *Settings) *Settings {
 		if len(project.Safety.LockedKeys) > 0 {
 			result.Safety.LockedKeys = dedupStrings(result.Safety.LockedKeys, project.Safety.LockedKeys)
 		}
+		if project.Safety.DenySecretReads != nil {
+			result.Safety.DenySecretReads = project.Safety.DenySecretReads
+		}
+		if len(project.Safety.SecretFiles) > 0 {
+			result.Safety.SecretFiles = dedupStrings(result.Safety.SecretFiles, project.Safety.SecretFiles)
+		}
 	}
 
 	// Worktree: merge if present
@@ -662,6 +1418,277 @@ func merge(global, project *Settings) *Settings {
 		}
 	}
 
+	// Display: merge if present
+	if project.Display != nil {
+		if result.Display == nil {
+			result.Display = &DisplaySettings{}
+		}
+		if project.Display.TimeFormat != "" {
+			result.Display.TimeFormat = project.Display.TimeFormat
+		}
+		if project.Display.TimeZone != "" {
+			result.Display.TimeZone = project.Display.TimeZone
+		}
+	}
+
+	// Suspend: merge if present
+	if project.Suspend != nil {
+		if result.Suspend == nil {
+			result.Suspend = &SuspendSettings{}
+		}
+		if project.Suspend.PauseTurns != nil {
+			result.Suspend.PauseTurns = project.Suspend.PauseTurns
+		}
+	}
+
+	// Notify: field-level override
+	if project.Notify != nil {
+		if result.Notify == nil {
+			result.Notify = &NotifySettings{}
+		}
+		if project.Notify.Method != "" {
+			result.Notify.Method = project.Notify.Method
+		}
+		if project.Notify.Command != "" {
+			result.Notify.Command = project.Notify.Command
+		}
+	}
+
+	// Minion: merge if present
+	if project.Minion != nil {
+		if result.Minion == nil {
+			result.Minion = &MinionSettings{}
+		}
+		if project.Minion.Model != "" {
+			result.Minion.Model = project.Minion.Model
+		}
+		if project.Minion.Mode != "" {
+			result.Minion.Mode = project.Minion.Mode
+		}
+		if project.Minion.MaxInputTokens != 0 {
+			result.Minion.MaxInputTokens = project.Minion.MaxInputTokens
+		}
+		if project.Minion.Parallel != 0 {
+			result.Minion.Parallel = project.Minion.Parallel
+		}
+	}
+
+	// FetchCache: merge if present
+	if project.FetchCache != nil {
+		if result.FetchCache == nil {
+			result.FetchCache = &FetchCacheSettings{}
+		}
+		if project.FetchCache.Enabled != nil {
+			result.FetchCache.Enabled = project.FetchCache.Enabled
+		}
+		if project.FetchCache.TTL != 0 {
+			result.FetchCache.TTL = project.FetchCache.TTL
+		}
+		if project.FetchCache.DocsTTL != 0 {
+			result.FetchCache.DocsTTL = project.FetchCache.DocsTTL
+		}
+		result.FetchCache.DocsDomains = dedupStrings(result.FetchCache.DocsDomains, project.FetchCache.DocsDomains)
+	}
+
+	// Prewarm: override if present
+	if project.Prewarm != nil && project.Prewarm.Mode != "" {
+		result.Prewarm = &PrewarmSettings{Mode: project.Prewarm.Mode}
+	}
+
+	// Suggestions: override if present
+	if project.Suggestions != nil && project.Suggestions.Enabled != nil {
+		result.Suggestions = &SuggestionsSettings{Enabled: project.Suggestions.Enabled}
+	}
+
+	// ThinkingRetention: override if present
+	if project.ThinkingRetention != nil && project.ThinkingRetention.Mode != "" {
+		result.ThinkingRetention = &ThinkingRetentionSettings{Mode: project.ThinkingRetention.Mode}
+	}
+
+	// Edit: a project policy replaces the global order as a whole
+	if project.Edit != nil && len(project.Edit.Strategies) > 0 {
+		result.Edit = &EditSettings{Strategies: slices.Clone(project.Edit.Strategies)}
+	}
+
+	// ToolLimits: merge scalars; per-tool overrides replace by tool name
+	if project.ToolLimits != nil {
+		if result.ToolLimits == nil {
+			result.ToolLimits = &ToolLimitsSettings{}
+		}
+		if project.ToolLimits.Timeout != 0 {
+			result.ToolLimits.Timeout = project.ToolLimits.Timeout
+		}
+		if project.ToolLimits.MaxOutputBytes != 0 {
+			result.ToolLimits.MaxOutputBytes = project.ToolLimits.MaxOutputBytes
+		}
+		if len(project.ToolLimits.Tools) > 0 {
+			tools := make(map[string]ToolLimit, len(result.ToolLimits.Tools)+len(project.ToolLimits.Tools))
+			maps.Copy(tools, result.ToolLimits.Tools)
+			maps.Copy(tools, project.ToolLimits.Tools)
+			result.ToolLimits.Tools = tools
+		}
+	}
+
+	// Redaction: override the switch; patterns accumulate
+	if project.Redaction != nil {
+		if result.Redaction == nil {
+			result.Redaction = &RedactionSettings{}
+		}
+		if project.Redaction.Enabled != nil {
+			result.Redaction.Enabled = project.Redaction.Enabled
+		}
+		result.Redaction.Patterns = dedupStrings(result.Redaction.Patterns, project.Redaction.Patterns)
+	}
+
+	// Audit: field-level override
+	if project.Audit != nil {
+		if result.Audit == nil {
+			result.Audit = &AuditSettings{}
+		}
+		if project.Audit.Enabled != nil {
+			result.Audit.Enabled = project.Audit.Enabled
+		}
+		if project.Audit.Path != "" {
+			result.Audit.Path = project.Audit.Path
+		}
+		if project.Audit.Syslog != "" {
+			result.Audit.Syslog = project.Audit.Syslog
+		}
+	}
+
+	// Container: field-level override; mounts accumulate
+	if project.Container != nil {
+		if result.Container == nil {
+			result.Container = &ContainerSettings{}
+		}
+		c, p := result.Container, project.Container
+		if p.Enabled != nil {
+			c.Enabled = p.Enabled
+		}
+		if p.Runtime != "" {
+			c.Runtime = p.Runtime
+		}
+		if p.Image != "" {
+			c.Image = p.Image
+		}
+		if p.Network != "" {
+			c.Network = p.Network
+		}
+		if p.CPUs != "" {
+			c.CPUs = p.CPUs
+		}
+		if p.Memory != "" {
+			c.Memory = p.Memory
+		}
+		if p.PidsLimit != 0 {
+			c.PidsLimit = p.PidsLimit
+		}
+		c.Mounts = dedupStrings(c.Mounts, p.Mounts)
+		if p.Shell != "" {
+			c.Shell = p.Shell
+		}
+		if p.Devcontainer != nil {
+			c.Devcontainer = p.Devcontainer
+		}
+	}
+
+	// KeyPools: merge by provider; a project pool replaces the user pool
+	if len(project.KeyPools) > 0 {
+		if result.KeyPools == nil {
+			result.KeyPools = make(map[string][]PooledKey)
+		}
+		maps.Copy(result.KeyPools, project.KeyPools)
+	}
+
+	// Share: field-level override
+	if project.Share != nil {
+		if result.Share == nil {
+			result.Share = &ShareSettings{}
+		}
+		if project.Share.Backend != "" {
+			result.Share.Backend = project.Share.Backend
+		}
+		if project.Share.PasteURL != "" {
+			result.Share.PasteURL = project.Share.PasteURL
+		}
+		if project.Share.Encrypt != nil {
+			result.Share.Encrypt = project.Share.Encrypt
+		}
+		if project.Share.RedactPaths != nil {
+			result.Share.RedactPaths = project.Share.RedactPaths
+		}
+		if project.Share.RedactSecrets != nil {
+			result.Share.RedactSecrets = project.Share.RedactSecrets
+		}
+	}
+
+	// Mock: field-level override
+	if project.Mock != nil {
+		if result.Mock == nil {
+			result.Mock = &MockSettings{}
+		}
+		if project.Mock.Script != "" {
+			result.Mock.Script = project.Mock.Script
+		}
+		if project.Mock.LatencyMs != 0 {
+			result.Mock.LatencyMs = project.Mock.LatencyMs
+		}
+		if project.Mock.ChunkDelayMs != 0 {
+			result.Mock.ChunkDelayMs = project.Mock.ChunkDelayMs
+		}
+		if project.Mock.InputTokens != 0 {
+			result.Mock.InputTokens = project.Mock.InputTokens
+		}
+		if project.Mock.OutputTokens != 0 {
+			result.Mock.OutputTokens = project.Mock.OutputTokens
+		}
+	}
+
+	// Pipelines: merge by name; a project pipeline replaces the user one
+	if len(project.Pipelines) > 0 {
+		pipelines := maps.Clone(result.Pipelines)
+		if pipelines == nil {
+			pipelines = make(map[string]PipelineDef)
+		}
+		maps.Copy(pipelines, project.Pipelines)
+		result.Pipelines = pipelines
+	}
+
+	// Plugins: merge by name; a project plugin replaces the user one
+	if len(project.Plugins) > 0 {
+		plugins := maps.Clone(result.Plugins)
+		if plugins == nil {
+			plugins = make(map[string]PluginDef)
+		}
+		maps.Copy(plugins, project.Plugins)
+		result.Plugins = plugins
+	}
+
+	// Excluded directories: union with dedup
+	if len(project.ExcludeDirs) > 0 {
+		result.ExcludeDirs = dedupStrings(result.ExcludeDirs, project.ExcludeDirs)
+	}
+
+	// Keybindings: merge by action; a project binding replaces the user one
+	if len(project.Keybindings) > 0 {
+		keys := maps.Clone(result.Keybindings)
+		if keys == nil {
+			keys = make(map[string][]string)
+		}
+		maps.Copy(keys, project.Keybindings)
+		result.Keybindings = keys
+	}
+
+	// ThinkingLevels: merge by model; a project level replaces the user one
+	if len(project.ThinkingLevels) > 0 {
+		levels := maps.Clone(result.ThinkingLevels)
+		if levels == nil {
+			levels = make(map[string]string)
+		}
+		maps.Copy(levels, project.ThinkingLevels)
+		result.ThinkingLevels = levels
+	}
+
 	return &result
 }
 
@@ -4,9 +4,12 @@
 package config
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
+	"time"
 )
 
 func TestMerge(t *testing.T) {
@@ -250,6 +253,36 @@ func TestMerge_DefaultMode(t *testing.T) {
 	}
 }
 
+func TestMerge_ThemeAndASCII(t *testing.T) {
+	t.Parallel()
+
+	global := &Settings{Theme: "dark", ASCII: true}
+	project := &Settings{Theme: "light"}
+
+	result := merge(global, project)
+	if result.Theme != "light" {
+		t.Errorf("Theme = %q, want %q", result.Theme, "light")
+	}
+	if !result.ASCII {
+		t.Error("ASCII should survive a project that does not set it")
+	}
+}
+
+func TestMerge_Mock(t *testing.T) {
+	t.Parallel()
+
+	global := &Settings{Mock: &MockSettings{Script: "global.yaml", LatencyMs: 200}}
+	project := &Settings{Mock: &MockSettings{Script: "project.yaml", OutputTokens: 50}}
+
+	result := merge(global, project)
+	if result.Mock.Script != "project.yaml" {
+		t.Errorf("Mock.Script = %q, want %q", result.Mock.Script, "project.yaml")
+	}
+	if result.Mock.LatencyMs != 200 || result.Mock.OutputTokens != 50 {
+		t.Errorf("Mock = %+v, want latency from global and tokens from project", result.Mock)
+	}
+}
+
 func TestMerge_StatusLine(t *testing.T) {
 	t.Parallel()
 
@@ -577,6 +610,42 @@ func TestMerge_Terminal(t *testing.T) {
 	}
 }
 
+func TestTerminalSettings_EffectiveToolOutputLines(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		s    *TerminalSettings
+		want int
+	}{
+		{nil, 10},
+		{&TerminalSettings{}, 10},
+		{&TerminalSettings{ToolOutputLines: 40}, 40},
+		{&TerminalSettings{ToolOutputLines: -1}, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.s.EffectiveToolOutputLines(); got != tt.want {
+			t.Errorf("EffectiveToolOutputLines(%+v) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestTerminalSettings_EffectiveShell(t *testing.T) {
+	t.Parallel()
+
+	if path, args := (*TerminalSettings)(nil).EffectiveShell(); path != "" || args != nil {
+		t.Errorf("nil settings = %q %q; want detection", path, args)
+	}
+
+	var s Settings
+	if err := json.Unmarshal([]byte(`{"terminal": {"shell": {"path": "zsh", "args": ["-l", "-c"]}}}`), &s); err != nil {
+		t.Fatal(err)
+	}
+	path, args := s.Terminal.EffectiveShell()
+	if path != "zsh" || !slices.Equal(args, []string{"-l", "-c"}) {
+		t.Errorf("EffectiveShell = %q %q; want zsh [-l -c]", path, args)
+	}
+}
+
 func TestMerge_ModelOverrides(t *testing.T) {
 	t.Parallel()
 
@@ -821,6 +890,32 @@ func TestMerge_PromptsSettings(t *testing.T) {
 	}
 }
 
+func TestMerge_PromptAdapters(t *testing.T) {
+	t.Parallel()
+
+	global := &Settings{
+		Prompts: &PromptsSettings{
+			ModelFamily: "gpt",
+			Adapters:    map[string]PromptAdapterSettings{"gpt": {Tone: "global"}, "claude": {Format: "md"}},
+		},
+	}
+	project := &Settings{
+		Prompts: &PromptsSettings{Adapters: map[string]PromptAdapterSettings{"gpt": {Tone: "project"}}},
+	}
+
+	result := merge(global, project)
+
+	if got := result.Prompts.EffectiveModelFamily(); got != "gpt" {
+		t.Errorf("EffectiveModelFamily = %q, want %q (from global)", got, "gpt")
+	}
+	if result.Prompts.Adapters["gpt"].Tone != "project" || result.Prompts.Adapters["claude"].Format != "md" {
+		t.Errorf("Adapters = %+v; want project gpt override and global claude", result.Prompts.Adapters)
+	}
+	if got := (*PromptsSettings)(nil).EffectiveModelFamily(); got != "auto" {
+		t.Errorf("nil EffectiveModelFamily = %q, want auto", got)
+	}
+}
+
 func TestMerge_PersonalitySettings(t *testing.T) {
 	t.Parallel()
 
@@ -915,6 +1010,28 @@ func TestMerge_SafetySettings(t *testing.T) {
 	}
 }
 
+func TestSafetySettings_SecretFiles(t *testing.T) {
+	t.Parallel()
+
+	var unset *SafetySettings
+	if unset.DeniesSecretReads() {
+		t.Error("secret reads should be allowed by default")
+	}
+
+	on := true
+	global := &Settings{Safety: &SafetySettings{DenySecretReads: &on}}
+	project := &Settings{Safety: &SafetySettings{SecretFiles: []string{"!.env.local", "secrets/"}}}
+	result := merge(global, project)
+
+	if !result.Safety.DeniesSecretReads() {
+		t.Error("DenySecretReads should survive a merge that leaves it unset")
+	}
+	got := result.Safety.EffectiveSecretFiles()
+	if len(got) != len(DefaultSecretFiles)+2 || got[0] != DefaultSecretFiles[0] || got[len(got)-2] != "!.env.local" {
+		t.Errorf("EffectiveSecretFiles = %v; want the defaults followed by the configured patterns", got)
+	}
+}
+
 func TestLoadFile_NewSettingsFields(t *testing.T) {
 	t.Parallel()
 
@@ -1082,3 +1199,480 @@ func TestMerge_Worktree_NilProject(t *testing.T) {
 func boolPtr(b bool) *bool {
 	return &b
 }
+
+func TestDisplaySettings_Defaults(t *testing.T) {
+	t.Parallel()
+	var d *DisplaySettings
+	if got := d.EffectiveTimeFormat(); got != "2006-01-02 15:04 MST" {
+		t.Errorf("EffectiveTimeFormat() = %q, want default layout", got)
+	}
+	if got := d.EffectiveLocation(); got != time.Local {
+		t.Errorf("EffectiveLocation() = %v, want time.Local", got)
+	}
+}
+
+func TestDisplaySettings_TimeZone(t *testing.T) {
+	t.Parallel()
+	d := &DisplaySettings{TimeZone: "UTC"}
+	if got := d.EffectiveLocation(); got.String() != "UTC" {
+		t.Errorf("EffectiveLocation() = %v, want UTC", got)
+	}
+	bad := &DisplaySettings{TimeZone: "Not/AZone"}
+	if got := bad.EffectiveLocation(); got != time.Local {
+		t.Errorf("unknown zone should fall back to time.Local, got %v", got)
+	}
+}
+
+func TestMerge_Display(t *testing.T) {
+	t.Parallel()
+
+	global := &Settings{Display: &DisplaySettings{TimeFormat: "15:04", TimeZone: "UTC"}}
+	project := &Settings{Display: &DisplaySettings{TimeZone: "Europe/Rome"}}
+
+	result := merge(global, project)
+	if result.Display == nil {
+		t.Fatal("Display should not be nil after merge")
+	}
+	if result.Display.TimeFormat != "15:04" {
+		t.Errorf("TimeFormat = %q, want global value kept", result.Display.TimeFormat)
+	}
+	if result.Display.TimeZone != "Europe/Rome" {
+		t.Errorf("TimeZone = %q, want project override", result.Display.TimeZone)
+	}
+}
+
+func TestSuspendSettings_ShouldPauseTurns(t *testing.T) {
+	t.Parallel()
+	var nilSettings *SuspendSettings
+	if nilSettings.ShouldPauseTurns() {
+		t.Error("nil SuspendSettings should default to not pausing")
+	}
+	if !(&SuspendSettings{PauseTurns: boolPtr(true)}).ShouldPauseTurns() {
+		t.Error("PauseTurns=true should pause")
+	}
+}
+
+func TestMerge_Suspend(t *testing.T) {
+	t.Parallel()
+
+	global := &Settings{Suspend: &SuspendSettings{PauseTurns: boolPtr(true)}}
+	project := &Settings{Suspend: &SuspendSettings{PauseTurns: boolPtr(false)}}
+
+	result := merge(global, project)
+	if result.Suspend.ShouldPauseTurns() {
+		t.Error("project should override global: want not pausing")
+	}
+}
+
+func TestNotifySettings_EffectiveMethod(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		settings *NotifySettings
+		want     string
+	}{
+		{nil, "osc777"},
+		{&NotifySettings{Method: "off"}, "off"},
+		{&NotifySettings{Method: "command", Command: "notify-send done"}, "command"},
+		{&NotifySettings{Method: "command"}, "bell"},
+	}
+	for _, tt := range tests {
+		if got := tt.settings.EffectiveMethod(); got != tt.want {
+			t.Errorf("EffectiveMethod(%+v) = %q, want %q", tt.settings, got, tt.want)
+		}
+	}
+}
+
+func TestMerge_Notify(t *testing.T) {
+	t.Parallel()
+
+	global := &Settings{Notify: &NotifySettings{Method: "command", Command: "say done"}}
+	project := &Settings{Notify: &NotifySettings{Method: "bell"}}
+
+	result := merge(global, project)
+	if result.Notify.Method != "bell" || result.Notify.Command != "say done" {
+		t.Errorf("Notify = %+v, want project method and global command", result.Notify)
+	}
+}
+
+func TestMinionSettings_EffectiveMode(t *testing.T) {
+	t.Parallel()
+	var nilSettings *MinionSettings
+	if got := nilSettings.EffectiveMode(); got != "auto" {
+		t.Errorf("nil MinionSettings mode = %q, want auto", got)
+	}
+	if got := (&MinionSettings{Mode: "force"}).EffectiveMode(); got != "force" {
+		t.Errorf("EffectiveMode() = %q, want force", got)
+	}
+}
+
+func TestMerge_Minion(t *testing.T) {
+	t.Parallel()
+
+	global := &Settings{Minion: &MinionSettings{Model: "haiku", MaxInputTokens: 8000}}
+	project := &Settings{Minion: &MinionSettings{Mode: "plural", Parallel: 8}}
+
+	result := merge(global, project)
+	if result.Minion.Model != "haiku" || result.Minion.MaxInputTokens != 8000 {
+		t.Errorf("Minion = %+v, want global model and limit kept", result.Minion)
+	}
+	if result.Minion.Mode != "plural" || result.Minion.Parallel != 8 {
+		t.Errorf("Minion = %+v, want project mode and parallelism", result.Minion)
+	}
+}
+
+func TestMerge_FetchCache(t *testing.T) {
+	t.Parallel()
+
+	off := false
+	global := &Settings{FetchCache: &FetchCacheSettings{TTL: 60, DocsDomains: []string{"wiki.corp"}}}
+	project := &Settings{FetchCache: &FetchCacheSettings{Enabled: &off, DocsDomains: []string{"api.corp"}}}
+
+	result := merge(global, project)
+	if result.FetchCache.IsEnabled() {
+		t.Error("project should disable the fetch cache")
+	}
+	if result.FetchCache.EffectiveTTL() != time.Minute {
+		t.Errorf("EffectiveTTL() = %v, want global 1m kept", result.FetchCache.EffectiveTTL())
+	}
+	if len(result.FetchCache.DocsDomains) != 2 {
+		t.Errorf("DocsDomains = %v, want union", result.FetchCache.DocsDomains)
+	}
+}
+
+func TestMerge_ToolLimits(t *testing.T) {
+	t.Parallel()
+
+	global := &Settings{ToolLimits: &ToolLimitsSettings{
+		Timeout: 300,
+		Tools:   map[string]ToolLimit{"bash": {Timeout: 900}, "grep": {MaxOutputBytes: 4096}},
+	}}
+	project := &Settings{ToolLimits: &ToolLimitsSettings{
+		MaxOutputBytes: 50000,
+		Tools:          map[string]ToolLimit{"bash": {MaxOutputBytes: -1}},
+	}}
+
+	result := merge(global, project)
+	if timeout, maxBytes := result.ToolLimits.For("read"); timeout != 5*time.Minute || maxBytes != 50000 {
+		t.Errorf("For(read) = %v, %d; want 5m0s, 50000", timeout, maxBytes)
+	}
+	if timeout, maxBytes := result.ToolLimits.For("bash"); timeout != 5*time.Minute || maxBytes != 0 {
+		t.Errorf("For(bash) = %v, %d; want the project override to replace the global one", timeout, maxBytes)
+	}
+	if _, maxBytes := result.ToolLimits.For("grep"); maxBytes != 4096 {
+		t.Errorf("For(grep) maxBytes = %d; want 4096", maxBytes)
+	}
+}
+
+func TestToolLimitsSettings_Defaults(t *testing.T) {
+	t.Parallel()
+
+	var l *ToolLimitsSettings
+	if timeout, maxBytes := l.For("bash"); timeout != DefaultToolTimeout || maxBytes != DefaultToolMaxOutputBytes {
+		t.Errorf("defaults = %v, %d", timeout, maxBytes)
+	}
+	l = &ToolLimitsSettings{Timeout: -1, Tools: map[string]ToolLimit{"bash": {Timeout: 30}}}
+	if timeout, _ := l.For("read"); timeout != 0 {
+		t.Errorf("negative timeout should lift the limit, got %v", timeout)
+	}
+	if timeout, _ := l.For("bash"); timeout != 30*time.Second {
+		t.Errorf("For(bash) timeout = %v; want 30s", timeout)
+	}
+}
+
+func TestMerge_Redaction(t *testing.T) {
+	t.Parallel()
+
+	off := false
+	global := &Settings{Redaction: &RedactionSettings{Patterns: []string{`corp_\w+`}}}
+	project := &Settings{Redaction: &RedactionSettings{Enabled: &off, Patterns: []string{`acme-\d+`}}}
+
+	result := merge(global, project)
+	if result.Redaction.IsEnabled() {
+		t.Error("project should disable redaction")
+	}
+	if got := result.Redaction.EffectivePatterns(); len(got) != 2 {
+		t.Errorf("Patterns = %v, want both levels", got)
+	}
+	if !(*RedactionSettings)(nil).IsEnabled() {
+		t.Error("redaction should default on")
+	}
+}
+
+func TestMerge_Audit(t *testing.T) {
+	t.Parallel()
+
+	on := true
+	global := &Settings{Audit: &AuditSettings{Enabled: &on, Syslog: "local"}}
+	project := &Settings{Audit: &AuditSettings{Path: "/var/log/pi-go/audit.jsonl"}}
+
+	result := merge(global, project)
+	if !result.Audit.IsEnabled() || result.Audit.EffectiveSyslog() != "local" {
+		t.Errorf("Audit = %+v; want the user's switch and syslog kept", result.Audit)
+	}
+	if got := result.Audit.EffectivePath(); got != "/var/log/pi-go/audit.jsonl" {
+		t.Errorf("EffectivePath = %q; want the project path", got)
+	}
+	var unset *AuditSettings
+	if unset.IsEnabled() || unset.EffectivePath() != AuditFile() {
+		t.Error("the audit log should default off, at ~/.pi-go/audit.jsonl")
+	}
+}
+
+func TestMerge_Container(t *testing.T) {
+	t.Parallel()
+
+	on := true
+	global := &Settings{Container: &ContainerSettings{Image: "golang:1.24", Memory: "4g", Mounts: []string{"~/.cache/go-build"}}}
+	project := &Settings{Container: &ContainerSettings{Enabled: &on, Network: "bridge", Mounts: []string{"../shared:ro", "~/.cache/go-build"}}}
+
+	result := merge(global, project)
+	c := result.Container
+	if !c.IsEnabled() || c.Image != "golang:1.24" || c.Memory != "4g" || c.Network != "bridge" {
+		t.Errorf("Container = %+v; want the user's image and memory with the project's switch and network", c)
+	}
+	if want := []string{"~/.cache/go-build", "../shared:ro"}; !slices.Equal(c.Mounts, want) {
+		t.Errorf("Mounts = %v; want %v", c.Mounts, want)
+	}
+	var unset *ContainerSettings
+	if unset.IsEnabled() || !unset.OffersDevcontainer() {
+		t.Error("container mode should default off, with a devcontainer offered")
+	}
+
+	off := false
+	result = merge(&Settings{Container: &ContainerSettings{Devcontainer: &on}}, &Settings{Container: &ContainerSettings{Devcontainer: &off}})
+	if result.Container.UsesDevcontainer() || result.Container.IsEnabled() || result.Container.OffersDevcontainer() {
+		t.Errorf("Container = %+v; want the project's refusal of the devcontainer to win", result.Container)
+	}
+	if c := (&ContainerSettings{Devcontainer: &on}); !c.IsEnabled() {
+		t.Error("using the devcontainer should turn container mode on")
+	}
+}
+
+func TestFetchCacheSettings_Defaults(t *testing.T) {
+	t.Parallel()
+
+	var f *FetchCacheSettings
+	if !f.IsEnabled() {
+		t.Error("nil settings should enable the cache")
+	}
+	if f.EffectiveTTL() != 15*time.Minute || f.EffectiveDocsTTL() != 24*time.Hour {
+		t.Errorf("defaults = %v / %v", f.EffectiveTTL(), f.EffectiveDocsTTL())
+	}
+}
+
+func TestMerge_ContextEviction(t *testing.T) {
+	t.Parallel()
+
+	global := &Settings{ContextEviction: &ContextEvictionSettings{AfterTurns: 5, PinnedTools: []string{"skill", "task"}}}
+	project := &Settings{ContextEviction: &ContextEvictionSettings{MaxResultBytes: 1024}}
+
+	result := merge(global, project)
+	if result.ContextEviction.EffectiveAfterTurns() != 5 || result.ContextEviction.EffectiveMaxResultBytes() != 1024 {
+		t.Errorf("ContextEviction = %+v, want global turns and project size", result.ContextEviction)
+	}
+	if len(result.ContextEviction.EffectivePinnedTools()) != 2 {
+		t.Errorf("PinnedTools = %v, want global list kept", result.ContextEviction.PinnedTools)
+	}
+
+	var nilSettings *ContextEvictionSettings
+	if !nilSettings.IsEnabled() || nilSettings.EffectiveAfterTurns() != 3 || nilSettings.EffectivePinnedTools()[0] != "skill" {
+		t.Error("nil settings should use the defaults")
+	}
+}
+
+func TestMerge_Prewarm(t *testing.T) {
+	t.Parallel()
+
+	global := &Settings{Prewarm: &PrewarmSettings{Mode: PrewarmPrefix}}
+	if got := merge(global, &Settings{}).Prewarm.EffectiveMode(); got != PrewarmPrefix {
+		t.Errorf("mode = %q, want global prefix kept", got)
+	}
+	project := &Settings{Prewarm: &PrewarmSettings{Mode: PrewarmConnection}}
+	if got := merge(global, project).Prewarm.EffectiveMode(); got != PrewarmConnection {
+		t.Errorf("mode = %q, want project override", got)
+	}
+	if got := merge(&Settings{}, &Settings{}).Prewarm.EffectiveMode(); got != PrewarmOff {
+		t.Errorf("default mode = %q, want off", got)
+	}
+}
+
+func TestMerge_Suggestions(t *testing.T) {
+	t.Parallel()
+
+	on, off := true, false
+	global := &Settings{Suggestions: &SuggestionsSettings{Enabled: &on}}
+	if !merge(global, &Settings{}).Suggestions.IsEnabled() {
+		t.Error("global suggestions should be kept")
+	}
+	if merge(global, &Settings{Suggestions: &SuggestionsSettings{Enabled: &off}}).Suggestions.IsEnabled() {
+		t.Error("a project should be able to turn suggestions off")
+	}
+	if merge(&Settings{}, &Settings{}).Suggestions.IsEnabled() {
+		t.Error("suggestions should be off by default")
+	}
+}
+
+func TestMerge_ThinkingRetention(t *testing.T) {
+	t.Parallel()
+
+	global := &Settings{ThinkingRetention: &ThinkingRetentionSettings{Mode: ThinkingFull}}
+	if got := merge(global, &Settings{}).ThinkingRetention.EffectiveMode(); got != ThinkingFull {
+		t.Errorf("mode = %q, want global full kept", got)
+	}
+	project := &Settings{ThinkingRetention: &ThinkingRetentionSettings{Mode: ThinkingStripped}}
+	if got := merge(global, project).ThinkingRetention.EffectiveMode(); got != ThinkingStripped {
+		t.Errorf("mode = %q, want project override", got)
+	}
+	if got := merge(&Settings{}, &Settings{}).ThinkingRetention.EffectiveMode(); got != ThinkingSummarized {
+		t.Errorf("default mode = %q, want summarized", got)
+	}
+	typo := &ThinkingRetentionSettings{Mode: "ful"}
+	if got := typo.EffectiveMode(); got != ThinkingSummarized {
+		t.Errorf("unknown mode = %q, want summarized", got)
+	}
+}
+
+func TestMerge_EditStrategies(t *testing.T) {
+	t.Parallel()
+
+	global := &Settings{Edit: &EditSettings{Strategies: []string{"exact", "whitespace", "anchor"}}}
+	if got := merge(global, &Settings{}).Edit.EffectiveStrategies(); len(got) != 3 {
+		t.Errorf("strategies = %v, want global kept", got)
+	}
+	project := &Settings{Edit: &EditSettings{Strategies: []string{"exact"}}}
+	if got := merge(global, project).Edit.EffectiveStrategies(); len(got) != 1 || got[0] != "exact" {
+		t.Errorf("strategies = %v, want project order replacing global", got)
+	}
+	if got := merge(&Settings{}, &Settings{}).Edit.EffectiveStrategies(); got != nil {
+		t.Errorf("default strategies = %v, want nil for the tool default", got)
+	}
+}
+
+func TestMerge_ShareSettings(t *testing.T) {
+	t.Parallel()
+
+	var nilShare *ShareSettings
+	if nilShare.EffectiveBackend() != ShareBackendGist || !nilShare.EncryptEnabled() ||
+		!nilShare.RedactPathsEnabled() || !nilShare.RedactSecretsEnabled() {
+		t.Error("defaults: want gist, encrypted, with paths and secrets redacted")
+	}
+
+	off := false
+	global := &Settings{Share: &ShareSettings{Backend: ShareBackendPaste, PasteURL: "https://paste.example/"}}
+	project := &Settings{Share: &ShareSettings{RedactPaths: &off}}
+	got := merge(global, project).Share
+	if got.EffectiveBackend() != ShareBackendPaste || got.EffectivePasteURL() != "https://paste.example/" {
+		t.Errorf("share = %+v, want the global backend kept", got)
+	}
+	if got.RedactPathsEnabled() || !got.RedactSecretsEnabled() || !got.EncryptEnabled() {
+		t.Errorf("share = %+v, want only path redaction turned off", got)
+	}
+}
+
+func TestMerge_KeyPools(t *testing.T) {
+	t.Parallel()
+
+	global := &Settings{KeyPools: map[string][]PooledKey{
+		"anthropic": {{Name: "a", Key: "$A"}},
+		"openai":    {{Key: "o1"}, {Key: "o2"}},
+	}}
+	project := &Settings{KeyPools: map[string][]PooledKey{
+		"anthropic": {{Name: "team", Key: "$TEAM", MaxTokens: 1000}},
+	}}
+	got := merge(global, project).KeyPools
+	if len(got["anthropic"]) != 1 || got["anthropic"][0].Name != "team" {
+		t.Errorf("anthropic pool = %+v, want the project pool", got["anthropic"])
+	}
+	if len(got["openai"]) != 2 {
+		t.Errorf("openai pool = %+v, want the global pool kept", got["openai"])
+	}
+}
+
+func TestMerge_Pipelines(t *testing.T) {
+	t.Parallel()
+
+	global := &Settings{Pipelines: map[string]PipelineDef{
+		"lint_fix": {Steps: []PipelineStepDef{{Tool: "bash"}}},
+		"check":    {Steps: []PipelineStepDef{{Tool: "grep"}}},
+	}}
+	project := &Settings{Pipelines: map[string]PipelineDef{
+		"lint_fix": {Steps: []PipelineStepDef{{Tool: "bash"}, {Tool: "read", ForEach: "{{.prev}}"}}},
+	}}
+	got := merge(global, project).Pipelines
+	if len(got["lint_fix"].Steps) != 2 {
+		t.Errorf("lint_fix = %+v, want the project pipeline", got["lint_fix"])
+	}
+	if _, ok := got["check"]; !ok {
+		t.Error("global pipeline dropped")
+	}
+	if len(global.Pipelines["lint_fix"].Steps) != 1 {
+		t.Error("merge mutated the global settings")
+	}
+}
+
+func TestMerge_ExcludeDirs(t *testing.T) {
+	t.Parallel()
+
+	global := &Settings{ExcludeDirs: []string{"target", ".gradle"}}
+	project := &Settings{ExcludeDirs: []string{"!vendor", "target"}}
+	got := merge(global, project).ExcludeDirs
+	if want := []string{"target", ".gradle", "!vendor"}; !slices.Equal(got, want) {
+		t.Errorf("ExcludeDirs = %v; want %v", got, want)
+	}
+}
+
+func TestMerge_Plugins(t *testing.T) {
+	t.Parallel()
+
+	global := &Settings{Plugins: map[string]PluginDef{
+		"lint":   {Command: "lint-plugin"},
+		"search": {Command: "search-plugin"},
+	}}
+	project := &Settings{Plugins: map[string]PluginDef{
+		"lint": {Disabled: true},
+	}}
+	got := merge(global, project).Plugins
+	if !got["lint"].Disabled {
+		t.Errorf("lint = %+v, want the project entry", got["lint"])
+	}
+	if got["search"].Command != "search-plugin" {
+		t.Error("global plugin dropped")
+	}
+	if global.Plugins["lint"].Disabled {
+		t.Error("merge mutated the global settings")
+	}
+}
+
+func TestMerge_Keybindings(t *testing.T) {
+	t.Parallel()
+
+	global := &Settings{Keybindings: map[string][]string{
+		"background": {"ctrl+x"},
+		"submit":     {"enter"},
+	}}
+	project := &Settings{Keybindings: map[string][]string{"background": {"alt+b"}}}
+	got := merge(global, project).Keybindings
+	if !slices.Equal(got["background"], []string{"alt+b"}) {
+		t.Errorf("background = %v, want the project binding", got["background"])
+	}
+	if !slices.Equal(got["submit"], []string{"enter"}) {
+		t.Errorf("submit = %v, want the global binding kept", got["submit"])
+	}
+	if !slices.Equal(global.Keybindings["background"], []string{"ctrl+x"}) {
+		t.Error("merge mutated the global settings")
+	}
+}
+
+func TestMerge_ThinkingLevels(t *testing.T) {
+	t.Parallel()
+
+	global := &Settings{ThinkingLevels: map[string]string{"a": "low", "b": "high"}}
+	project := &Settings{ThinkingLevels: map[string]string{"a": "xhigh"}}
+	got := merge(global, project).ThinkingLevels
+	if got["a"] != "xhigh" || got["b"] != "high" {
+		t.Errorf("ThinkingLevels = %v, want a=xhigh b=high", got)
+	}
+	if global.ThinkingLevels["a"] != "low" {
+		t.Error("merge mutated the global settings")
+	}
+}
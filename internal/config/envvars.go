@@ -1,45 +1,104 @@
 // ABOUTME: Environment variable expansion in config string fields
-// ABOUTME: Replaces ${VAR} patterns with os.Getenv values; unset vars become empty
+// ABOUTME: Replaces ${VAR} and ${VAR:-default} in every string setting; unset vars without a default become empty
 
 package config
 
 import (
+	"fmt"
 	"os"
+	"reflect"
 	"regexp"
+	"strings"
 )
 
-var envVarPattern = regexp.MustCompile(`\$\{(\w+)\}`)
+var envVarPattern = regexp.MustCompile(`\$\{(\w+)(?::-([^}]*))?\}`)
 
-// ResolveEnvVars expands ${VAR} patterns in string fields of Settings.
+// ResolveEnvVars expands ${VAR} patterns in every string field of Settings,
+// including map values and list entries, so committed settings can leave
+// machine-specific values to the environment.
 func ResolveEnvVars(s *Settings) {
-	s.Model = expandEnv(s.Model)
-	s.BaseURL = expandEnv(s.BaseURL)
-	s.DefaultMode = expandEnv(s.DefaultMode)
+	_ = walkStrings(reflect.ValueOf(s), "", func(_, v string) (string, error) {
+		return expandEnv(v), nil
+	})
+}
 
-	if s.StatusLine != nil {
-		s.StatusLine.Command = expandEnv(s.StatusLine.Command)
+// expandEnv replaces ${VAR} with os.Getenv(VAR). Unset or empty vars become
+// the default given as ${VAR:-default}, or "".
+func expandEnv(s string) string {
+	if s == "" {
+		return s
 	}
+	return envVarPattern.ReplaceAllStringFunc(s, func(match string) string {
+		m := envVarPattern.FindStringSubmatch(match)
+		if v := os.Getenv(m[1]); v != "" {
+			return v
+		}
+		return m[2]
+	})
+}
 
-	for k, v := range s.Env {
-		s.Env[k] = expandEnv(v)
+// walkStrings calls fn with the dotted JSON path and value of every string
+// reachable from v through structs, pointers, slices, and maps, and stores
+// what fn returns. Untyped values (any) are left alone.
+func walkStrings(v reflect.Value, path string, fn func(path, s string) (string, error)) error {
+	switch v.Kind() {
+	case reflect.String:
+		if !v.CanSet() {
+			return nil
+		}
+		out, err := fn(path, v.String())
+		if err != nil {
+			return err
+		}
+		v.SetString(out)
+	case reflect.Pointer:
+		if !v.IsNil() {
+			return walkStrings(v.Elem(), path, fn)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			if err := walkStrings(v.Field(i), joinPath(path, jsonName(f)), fn); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			if err := walkStrings(v.Index(i), fmt.Sprintf("%s[%d]", path, i), fn); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		// Map values are not addressable: walk a copy and store it back.
+		for _, k := range v.MapKeys() {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(k))
+			if err := walkStrings(elem, joinPath(path, fmt.Sprint(k.Interface())), fn); err != nil {
+				return err
+			}
+			v.SetMapIndex(k, elem)
+		}
 	}
+	return nil
+}
 
-	for event, defs := range s.Hooks {
-		for i := range defs {
-			defs[i].Command = expandEnv(defs[i].Command)
-			defs[i].Matcher = expandEnv(defs[i].Matcher)
-		}
-		s.Hooks[event] = defs
+// jsonName returns the key a struct field has in settings.json.
+func jsonName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return f.Name
 	}
+	return name
 }
 
-// expandEnv replaces ${VAR} with os.Getenv(VAR). Unset vars become "".
-func expandEnv(s string) string {
-	if s == "" {
-		return s
+// joinPath appends key to a dotted settings path.
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
 	}
-	return envVarPattern.ReplaceAllStringFunc(s, func(match string) string {
-		varName := envVarPattern.FindStringSubmatch(match)[1]
-		return os.Getenv(varName)
-	})
+	return path + "." + key
 }
@@ -5,6 +5,8 @@ package config
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 )
 
@@ -37,9 +39,18 @@ func Explain(s *Settings) string {
 	if s.Thinking {
 		b.WriteString("  Thinking:    true\n")
 	}
+	if s.Offline {
+		b.WriteString("  Offline:     true\n")
+	}
 	if s.Theme != "" {
 		fmt.Fprintf(&b, "  Theme:       %s\n", s.Theme)
 	}
+	if s.OutputStyle != "" {
+		fmt.Fprintf(&b, "  OutputStyle: %s\n", s.OutputStyle)
+	}
+	for _, model := range slices.Sorted(maps.Keys(s.ThinkingLevels)) {
+		fmt.Fprintf(&b, "  Thinking:    %s = %s\n", model, s.ThinkingLevels[model])
+	}
 	b.WriteString("\n")
 
 	// Permissions
@@ -83,6 +94,19 @@ func Explain(s *Settings) string {
 		if s.Prompts.MaxSystemPromptTokens != 0 {
 			fmt.Fprintf(&b, "  MaxSystemPromptTokens: %d\n", s.Prompts.MaxSystemPromptTokens)
 		}
+		if s.Prompts.ModelFamily != "" {
+			fmt.Fprintf(&b, "  ModelFamily:           %s\n", s.Prompts.ModelFamily)
+		}
+		for _, family := range slices.Sorted(maps.Keys(s.Prompts.Adapters)) {
+			a := s.Prompts.Adapters[family]
+			fmt.Fprintf(&b, "  [adapter %s]\n", family)
+			if a.Tone != "" {
+				fmt.Fprintf(&b, "    Tone:   %s\n", a.Tone)
+			}
+			if a.Format != "" {
+				fmt.Fprintf(&b, "    Format: %s\n", a.Format)
+			}
+		}
 	}
 	b.WriteString("\n")
 
@@ -124,6 +148,9 @@ func Explain(s *Settings) string {
 		if len(s.Safety.LockedKeys) > 0 {
 			fmt.Fprintf(&b, "  LockedKeys:  %s\n", strings.Join(s.Safety.LockedKeys, ", "))
 		}
+		if s.Safety.DeniesSecretReads() {
+			fmt.Fprintf(&b, "  SecretFiles: %s (reads denied)\n", strings.Join(s.Safety.EffectiveSecretFiles(), ", "))
+		}
 	}
 	b.WriteString("\n")
 
@@ -140,6 +167,16 @@ func Explain(s *Settings) string {
 	}
 	b.WriteString("\n")
 
+	// Context eviction
+	b.WriteString("=== Context Eviction ===\n")
+	if s.ContextEviction != nil {
+		fmt.Fprintf(&b, "  Enabled:        %v\n", s.ContextEviction.IsEnabled())
+		fmt.Fprintf(&b, "  AfterTurns:     %d\n", s.ContextEviction.EffectiveAfterTurns())
+		fmt.Fprintf(&b, "  MaxResultBytes: %d\n", s.ContextEviction.EffectiveMaxResultBytes())
+		fmt.Fprintf(&b, "  PinnedTools:    %s\n", strings.Join(s.ContextEviction.EffectivePinnedTools(), ", "))
+	}
+	b.WriteString("\n")
+
 	// Retry
 	b.WriteString("=== Retry ===\n")
 	if s.Retry != nil {
@@ -155,6 +192,189 @@ func Explain(s *Settings) string {
 	}
 	b.WriteString("\n")
 
+	// Network
+	b.WriteString("=== Network ===\n")
+	if s.Network != nil {
+		if s.Network.Proxy != "" {
+			fmt.Fprintf(&b, "  Proxy: %s\n", s.Network.RedactedProxy())
+		}
+		if s.Network.CACert != "" {
+			fmt.Fprintf(&b, "  CACert: %s\n", s.Network.CACert)
+		}
+		if s.Network.InsecureSkipVerify {
+			b.WriteString("  InsecureSkipVerify: true (TLS certificates are not verified)\n")
+		}
+		writeHTTPSettings(&b, "  ", s.Network.HTTPSettings)
+		keys := slices.Sorted(maps.Keys(s.Network.Overrides))
+		for _, key := range keys {
+			fmt.Fprintf(&b, "  [%s]\n", key)
+			writeHTTPSettings(&b, "    ", s.Network.Overrides[key])
+		}
+	}
+	b.WriteString("\n")
+
+	// Minion
+	b.WriteString("=== Minion ===\n")
+	if s.Minion != nil && s.Minion.Model != "" {
+		fmt.Fprintf(&b, "  Model: %s\n", s.Minion.Model)
+		fmt.Fprintf(&b, "  Mode:  %s\n", s.Minion.EffectiveMode())
+		if s.Minion.MaxInputTokens != 0 {
+			fmt.Fprintf(&b, "  MaxInputTokens: %d\n", s.Minion.MaxInputTokens)
+		}
+		if s.Minion.Parallel != 0 {
+			fmt.Fprintf(&b, "  Parallel: %d\n", s.Minion.Parallel)
+		}
+	}
+	b.WriteString("\n")
+
+	// Prewarm
+	b.WriteString("=== Prewarm ===\n")
+	fmt.Fprintf(&b, "  Mode: %s\n", s.Prewarm.EffectiveMode())
+	b.WriteString("\n")
+
+	// Suggestions
+	b.WriteString("=== Suggestions ===\n")
+	fmt.Fprintf(&b, "  Enabled: %v\n", s.Suggestions.IsEnabled())
+	b.WriteString("\n")
+
+	// Thinking retention
+	b.WriteString("=== Thinking Retention ===\n")
+	fmt.Fprintf(&b, "  Mode: %s\n", s.ThinkingRetention.EffectiveMode())
+	b.WriteString("\n")
+
+	// Edit
+	b.WriteString("=== Edit ===\n")
+	if strategies := s.Edit.EffectiveStrategies(); len(strategies) > 0 {
+		fmt.Fprintf(&b, "  Strategies: %s\n", strings.Join(strategies, ", "))
+	} else {
+		b.WriteString("  Strategies: exact, whitespace (default)\n")
+	}
+	b.WriteString("\n")
+
+	b.WriteString("=== Tool Limits ===\n")
+	timeout, maxBytes := s.ToolLimits.For("")
+	fmt.Fprintf(&b, "  Timeout:        %s\n", limitString(timeout.String(), timeout == 0))
+	fmt.Fprintf(&b, "  MaxOutputBytes: %s\n", limitString(fmt.Sprint(maxBytes), maxBytes == 0))
+	if s.ToolLimits != nil {
+		for _, name := range slices.Sorted(maps.Keys(s.ToolLimits.Tools)) {
+			timeout, maxBytes := s.ToolLimits.For(name)
+			fmt.Fprintf(&b, "  %s: timeout %s, maxOutputBytes %s\n", name,
+				limitString(timeout.String(), timeout == 0), limitString(fmt.Sprint(maxBytes), maxBytes == 0))
+		}
+	}
+	b.WriteString("\n")
+
+	b.WriteString("=== Redaction ===\n")
+	fmt.Fprintf(&b, "  Enabled: %v\n", s.Redaction.IsEnabled())
+	if patterns := s.Redaction.EffectivePatterns(); len(patterns) > 0 {
+		fmt.Fprintf(&b, "  Patterns: %s\n", strings.Join(patterns, ", "))
+	}
+	b.WriteString("\n")
+
+	b.WriteString("=== Audit ===\n")
+	fmt.Fprintf(&b, "  Enabled: %v\n", s.Audit.IsEnabled())
+	if s.Audit.IsEnabled() {
+		fmt.Fprintf(&b, "  Path: %s\n", s.Audit.EffectivePath())
+		if dest := s.Audit.EffectiveSyslog(); dest != "" {
+			fmt.Fprintf(&b, "  Syslog: %s\n", dest)
+		}
+	}
+	b.WriteString("\n")
+
+	b.WriteString("=== Container ===\n")
+	fmt.Fprintf(&b, "  Enabled: %v\n", s.Container.IsEnabled())
+	if c := s.Container; c.IsEnabled() {
+		for _, kv := range [][2]string{{"Runtime", c.Runtime}, {"Image", c.Image}, {"Network", c.Network}, {"CPUs", c.CPUs}, {"Memory", c.Memory}, {"Shell", c.Shell}} {
+			if kv[1] != "" {
+				fmt.Fprintf(&b, "  %s: %s\n", kv[0], kv[1])
+			}
+		}
+		if c.PidsLimit > 0 {
+			fmt.Fprintf(&b, "  PidsLimit: %d\n", c.PidsLimit)
+		}
+		if len(c.Mounts) > 0 {
+			fmt.Fprintf(&b, "  Mounts: %s\n", strings.Join(c.Mounts, ", "))
+		}
+		fmt.Fprintf(&b, "  Devcontainer: %v\n", c.UsesDevcontainer())
+	}
+	b.WriteString("\n")
+
+	b.WriteString("=== Share ===\n")
+	fmt.Fprintf(&b, "  Backend: %s\n", s.Share.EffectiveBackend())
+	if u := s.Share.EffectivePasteURL(); u != "" {
+		fmt.Fprintf(&b, "  PasteURL: %s\n", u)
+	}
+	fmt.Fprintf(&b, "  Encrypt: %v\n", s.Share.EncryptEnabled())
+	fmt.Fprintf(&b, "  RedactPaths: %v\n", s.Share.RedactPathsEnabled())
+	fmt.Fprintf(&b, "  RedactSecrets: %v\n", s.Share.RedactSecretsEnabled())
+	b.WriteString("\n")
+
+	if len(s.KeyPools) > 0 {
+		b.WriteString("=== Key Pools ===\n")
+		for _, provider := range slices.Sorted(maps.Keys(s.KeyPools)) {
+			var keys []string
+			for i, k := range s.KeyPools[provider] {
+				label := k.Name
+				if label == "" {
+					label = fmt.Sprintf("key-%d", i+1)
+				}
+				if k.MaxTokens > 0 {
+					label += fmt.Sprintf(" (max %d tokens)", k.MaxTokens)
+				}
+				keys = append(keys, label)
+			}
+			fmt.Fprintf(&b, "  %s: %s\n", provider, strings.Join(keys, ", "))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(s.Pipelines) > 0 {
+		b.WriteString("=== Pipelines ===\n")
+		for _, name := range slices.Sorted(maps.Keys(s.Pipelines)) {
+			var steps []string
+			for _, st := range s.Pipelines[name].Steps {
+				step := st.Tool
+				if st.ForEach != "" {
+					step += " (for each)"
+				}
+				steps = append(steps, step)
+			}
+			fmt.Fprintf(&b, "  %s: %s\n", name, strings.Join(steps, " → "))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(s.ExcludeDirs) > 0 {
+		b.WriteString("=== Excluded Directories ===\n")
+		fmt.Fprintf(&b, "  %s (added to the defaults)\n", strings.Join(s.ExcludeDirs, ", "))
+		b.WriteString("\n")
+	}
+
+	if len(s.Plugins) > 0 {
+		b.WriteString("=== Plugins ===\n")
+		for _, name := range slices.Sorted(maps.Keys(s.Plugins)) {
+			p := s.Plugins[name]
+			if p.Disabled {
+				fmt.Fprintf(&b, "  %s: disabled\n", name)
+				continue
+			}
+			fmt.Fprintf(&b, "  %s: %s\n", name, strings.Join(append([]string{p.Command}, p.Args...), " "))
+		}
+		b.WriteString("\n")
+	}
+
+	// Fetch cache
+	b.WriteString("=== Fetch Cache ===\n")
+	fmt.Fprintf(&b, "  Enabled: %v\n", s.FetchCache.IsEnabled())
+	if s.FetchCache.IsEnabled() {
+		fmt.Fprintf(&b, "  TTL:     %s\n", s.FetchCache.EffectiveTTL())
+		fmt.Fprintf(&b, "  DocsTTL: %s\n", s.FetchCache.EffectiveDocsTTL())
+		if s.FetchCache != nil && len(s.FetchCache.DocsDomains) > 0 {
+			fmt.Fprintf(&b, "  DocsDomains: %s\n", strings.Join(s.FetchCache.DocsDomains, ", "))
+		}
+	}
+	b.WriteString("\n")
+
 	// Terminal
 	b.WriteString("=== Terminal ===\n")
 	if s.Terminal != nil {
@@ -164,6 +384,12 @@ func Explain(s *Settings) string {
 		if s.Terminal.Pager {
 			b.WriteString("  Pager:     true\n")
 		}
+		if s.Terminal.ToolOutputLines != 0 {
+			fmt.Fprintf(&b, "  ToolOutputLines: %d\n", s.Terminal.ToolOutputLines)
+		}
+		if path, args := s.Terminal.EffectiveShell(); path != "" {
+			fmt.Fprintf(&b, "  Shell:     %s\n", strings.TrimSpace(path+" "+strings.Join(args, " ")))
+		}
 	}
 	b.WriteString("\n")
 
@@ -179,3 +405,36 @@ func Explain(s *Settings) string {
 
 	return b.String()
 }
+
+// writeHTTPSettings prints the non-zero transport fields of h.
+func writeHTTPSettings(b *strings.Builder, indent string, h HTTPSettings) {
+	for _, f := range []struct {
+		name string
+		ms   int
+	}{
+		{"ConnectTimeout", h.ConnectTimeout},
+		{"ResponseHeaderTimeout", h.ResponseHeaderTimeout},
+		{"IdleReadTimeout", h.IdleReadTimeout},
+		{"RequestTimeout", h.RequestTimeout},
+		{"KeepAlive", h.KeepAlive},
+		{"HTTP2PingInterval", h.HTTP2PingInterval},
+	} {
+		switch {
+		case f.ms < 0:
+			fmt.Fprintf(b, "%s%s: off\n", indent, f.name)
+		case f.ms > 0:
+			fmt.Fprintf(b, "%s%s: %dms\n", indent, f.name, f.ms)
+		}
+	}
+	if h.HTTP2 != nil {
+		fmt.Fprintf(b, "%sHTTP2: %v\n", indent, *h.HTTP2)
+	}
+}
+
+// limitString renders a tool limit, or "none" when it is lifted.
+func limitString(s string, none bool) string {
+	if none {
+		return "none"
+	}
+	return s
+}
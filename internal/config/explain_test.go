@@ -134,3 +134,52 @@ func TestExplain_NilSettings(t *testing.T) {
 		t.Error("Explain(nil) should return non-empty string")
 	}
 }
+
+func TestExplain_Pipelines(t *testing.T) {
+	t.Parallel()
+
+	s := &Settings{Pipelines: map[string]PipelineDef{
+		"lint_fix": {Steps: []PipelineStepDef{{Tool: "bash"}, {Tool: "read", ForEach: "{{.prev}}"}}},
+	}}
+	result := Explain(s)
+	if !strings.Contains(result, "lint_fix: bash → read (for each)") {
+		t.Errorf("missing pipeline line:\n%s", result)
+	}
+}
+
+func TestExplain_Plugins(t *testing.T) {
+	t.Parallel()
+
+	s := &Settings{Plugins: map[string]PluginDef{
+		"lint":  {Command: "lint-plugin", Args: []string{"--serve"}},
+		"noisy": {Disabled: true},
+	}}
+	result := Explain(s)
+	if !strings.Contains(result, "lint: lint-plugin --serve") || !strings.Contains(result, "noisy: disabled") {
+		t.Errorf("missing plugin lines:\n%s", result)
+	}
+}
+
+func TestExplain_ExcludeDirs(t *testing.T) {
+	t.Parallel()
+
+	result := Explain(&Settings{ExcludeDirs: []string{".gradle", "!vendor"}})
+	if !strings.Contains(result, "=== Excluded Directories ===\n  .gradle, !vendor (added to the defaults)") {
+		t.Errorf("missing excluded directories:\n%s", result)
+	}
+}
+
+func TestExplain_KeyPoolsHideKeys(t *testing.T) {
+	t.Parallel()
+
+	s := &Settings{KeyPools: map[string][]PooledKey{
+		"anthropic": {{Name: "team-a", Key: "sk-secret", MaxTokens: 5000}, {Key: "$OTHER"}},
+	}}
+	result := Explain(s)
+	if !strings.Contains(result, "anthropic: team-a (max 5000 tokens), key-2") {
+		t.Errorf("missing key pool line:\n%s", result)
+	}
+	if strings.Contains(result, "sk-secret") || strings.Contains(result, "$OTHER") {
+		t.Error("Explain must not print pooled keys")
+	}
+}
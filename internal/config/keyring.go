@@ -0,0 +1,37 @@
+// ABOUTME: OS keyring access for API keys and keychain:// secrets
+// ABOUTME: macOS Keychain, Linux Secret Service, or Windows Credential Manager, per platform file
+
+package config
+
+import (
+	"errors"
+)
+
+// KeyringService is the service API keys are stored under; the account is
+// the provider name.
+const KeyringService = "pi-go"
+
+var (
+	// ErrNotInKeyring is returned when the keyring has no such entry.
+	ErrNotInKeyring = errors.New("not found in the keyring")
+	// ErrKeyringUnavailable is returned when this system has no usable
+	// keyring, e.g. secret-tool is missing or no Secret Service runs.
+	ErrKeyringUnavailable = errors.New("no OS keyring available")
+)
+
+// keyring is an OS credential store holding one secret per service and
+// account.
+type keyring interface {
+	name() string
+	get(service, account string) (string, error)
+	set(service, account, secret string) error
+	delete(service, account string) error
+}
+
+// osKeyring is this platform's keyring. Tests replace it.
+var osKeyring keyring = platformKeyring{}
+
+// KeyringName names the OS keyring for messages, e.g. "macOS Keychain".
+func KeyringName() string {
+	return osKeyring.name()
+}
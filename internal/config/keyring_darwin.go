@@ -0,0 +1,64 @@
+// ABOUTME: macOS keyring: generic passwords in the login Keychain via the security tool
+// ABOUTME: Secrets are written through security -i as hex, so they never appear in argv
+
+//go:build darwin
+
+package config
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// platformKeyring stores secrets in the macOS Keychain.
+type platformKeyring struct{}
+
+func (platformKeyring) name() string { return "macOS Keychain" }
+
+func (platformKeyring) get(service, account string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("security", "find-generic-password", "-s", service, "-a", account, "-w")
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if strings.Contains(stderr.String(), "could not be found") {
+			return "", ErrNotInKeyring
+		}
+		return "", fmt.Errorf("security find-generic-password: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimRight(string(out), "\n"), nil
+}
+
+func (platformKeyring) set(service, account, secret string) error {
+	// -U updates an existing entry; -X takes the password as hex.
+	script := fmt.Sprintf("add-generic-password -U -s %s -a %s -X %s\n", quoteSecurityArg(service), quoteSecurityArg(account), hex.EncodeToString([]byte(secret)))
+	var stderr bytes.Buffer
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(script)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("security add-generic-password: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+func (platformKeyring) delete(service, account string) error {
+	var stderr bytes.Buffer
+	cmd := exec.Command("security", "delete-generic-password", "-s", service, "-a", account)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if strings.Contains(stderr.String(), "could not be found") {
+			return ErrNotInKeyring
+		}
+		return fmt.Errorf("security delete-generic-password: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// quoteSecurityArg quotes s for a security -i command line.
+func quoteSecurityArg(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
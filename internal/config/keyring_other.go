@@ -0,0 +1,60 @@
+// ABOUTME: Linux and BSD keyring: the freedesktop Secret Service (GNOME Keyring, KWallet) via secret-tool
+// ABOUTME: Secrets are written on stdin; a missing secret-tool makes the keyring unavailable
+
+//go:build !darwin && !windows
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// platformKeyring stores secrets in the Secret Service.
+type platformKeyring struct{}
+
+func (platformKeyring) name() string { return "Secret Service" }
+
+// secretTool runs secret-tool with stdin and returns its stdout.
+func secretTool(stdin string, args ...string) (string, error) {
+	if _, err := exec.LookPath("secret-tool"); err != nil {
+		return "", fmt.Errorf("%w: secret-tool not found (install libsecret-tools)", ErrKeyringUnavailable)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("secret-tool", args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			// lookup and clear exit 1 silently when nothing matches.
+			return "", ErrNotInKeyring
+		}
+		return "", fmt.Errorf("%w: secret-tool %s: %s", ErrKeyringUnavailable, args[0], msg)
+	}
+	return stdout.String(), nil
+}
+
+func (platformKeyring) get(service, account string) (string, error) {
+	out, err := secretTool("", "lookup", "service", service, "account", account)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(out, "\n"), nil
+}
+
+func (platformKeyring) set(service, account, secret string) error {
+	_, err := secretTool(secret, "store", "--label", service+": "+account, "service", service, "account", account)
+	return err
+}
+
+func (k platformKeyring) delete(service, account string) error {
+	// clear succeeds when nothing matches; look first to report that.
+	if _, err := k.get(service, account); err != nil {
+		return err
+	}
+	_, err := secretTool("", "clear", "service", service, "account", account)
+	return err
+}
@@ -0,0 +1,146 @@
+// ABOUTME: In-memory keyring for tests, and tests for AuthStore's keyring backend
+// ABOUTME: Covers lookup priority, login migration out of the auth file, logout, and the unavailable fallback
+
+package config
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// memKeyring is a keyring held in memory, keyed "<service>/<account>".
+type memKeyring struct {
+	secrets     map[string]string
+	unavailable bool
+	gets        int
+}
+
+func (k *memKeyring) name() string { return "test keyring" }
+
+func (k *memKeyring) get(service, account string) (string, error) {
+	k.gets++
+	if k.unavailable {
+		return "", ErrKeyringUnavailable
+	}
+	secret, ok := k.secrets[service+"/"+account]
+	if !ok {
+		return "", ErrNotInKeyring
+	}
+	return secret, nil
+}
+
+func (k *memKeyring) set(service, account, secret string) error {
+	if k.unavailable {
+		return ErrKeyringUnavailable
+	}
+	k.secrets[service+"/"+account] = secret
+	return nil
+}
+
+func (k *memKeyring) delete(service, account string) error {
+	if k.unavailable {
+		return ErrKeyringUnavailable
+	}
+	if _, ok := k.secrets[service+"/"+account]; !ok {
+		return ErrNotInKeyring
+	}
+	delete(k.secrets, service+"/"+account)
+	return nil
+}
+
+// useMemKeyring makes an empty memKeyring the OS keyring for the test.
+func useMemKeyring(t *testing.T) *memKeyring {
+	t.Helper()
+	kr := &memKeyring{secrets: map[string]string{}}
+	orig := osKeyring
+	osKeyring = kr
+	t.Cleanup(func() { osKeyring = orig })
+	return kr
+}
+
+// authWithKeyring returns a store backed by kr whose auth file lives in a
+// temporary home, with no keys in the environment.
+func authWithKeyring(t *testing.T, kr *memKeyring, keys map[string]string) *AuthStore {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	for _, env := range []string{"ANTHROPIC_API_KEY", "PI_API_KEY_ANTHROPIC", "OPENAI_API_KEY", "PI_API_KEY_OPENAI"} {
+		t.Setenv(env, "")
+	}
+	return &AuthStore{Keys: keys, keyring: kr}
+}
+
+func TestAuthStore_KeyringBeatsFile(t *testing.T) {
+	kr := &memKeyring{secrets: map[string]string{"pi-go/anthropic": "sk-keyring"}}
+	store := authWithKeyring(t, kr, map[string]string{"anthropic": "sk-file", "openai": "sk-openai"})
+	t.Setenv("ANTHROPIC_API_KEY", "sk-env")
+
+	if got := store.GetKey("anthropic"); got != "sk-keyring" {
+		t.Errorf("GetKey(anthropic) = %q; want the keyring key", got)
+	}
+	if got := store.KeySource("anthropic"); got != "test keyring" {
+		t.Errorf("KeySource(anthropic) = %q", got)
+	}
+	if got := store.GetKey("openai"); got != "sk-openai" {
+		t.Errorf("GetKey(openai) = %q; want the auth file key as fallback", got)
+	}
+	store.GetKey("anthropic")
+	store.GetKey("openai")
+	if kr.gets != 2 {
+		t.Errorf("keyring lookups = %d; want one per provider", kr.gets)
+	}
+}
+
+func TestAuthStore_SaveToKeyringMovesKey(t *testing.T) {
+	kr := &memKeyring{secrets: map[string]string{}}
+	store := authWithKeyring(t, kr, map[string]string{"anthropic": "sk-old", "openai": "sk-openai"})
+
+	if err := store.SaveToKeyring("anthropic", "sk-new"); err != nil {
+		t.Fatalf("SaveToKeyring: %v", err)
+	}
+	if kr.secrets["pi-go/anthropic"] != "sk-new" || store.GetKey("anthropic") != "sk-new" {
+		t.Errorf("the key should be in the keyring and used; keyring %v", kr.secrets)
+	}
+	saved, err := LoadAuth()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := saved.Keys["anthropic"]; ok || saved.Keys["openai"] != "sk-openai" {
+		t.Errorf("auth file keys = %v; want only the plaintext anthropic key removed", saved.Keys)
+	}
+}
+
+func TestAuthStore_RemoveKey(t *testing.T) {
+	kr := &memKeyring{secrets: map[string]string{"pi-go/anthropic": "sk-keyring"}}
+	store := authWithKeyring(t, kr, map[string]string{"anthropic": "sk-file"})
+	store.GetKey("anthropic") // cache the keyring lookup
+
+	removed, err := store.RemoveKey("anthropic")
+	if err != nil {
+		t.Fatalf("RemoveKey: %v", err)
+	}
+	if want := []string{"test keyring", filepath.Join(GlobalDir(), "auth.json")}; !slices.Equal(removed, want) {
+		t.Errorf("removed from %v; want %v", removed, want)
+	}
+	if got := store.GetKey("anthropic"); got != "" {
+		t.Errorf("GetKey after logout = %q; want none", got)
+	}
+	if removed, err := store.RemoveKey("anthropic"); err != nil || len(removed) != 0 {
+		t.Errorf("second RemoveKey = %v, %v; want nothing removed", removed, err)
+	}
+}
+
+func TestAuthStore_KeyringUnavailable(t *testing.T) {
+	kr := &memKeyring{secrets: map[string]string{}, unavailable: true}
+	store := authWithKeyring(t, kr, map[string]string{"anthropic": "sk-file"})
+
+	if got := store.GetKey("anthropic"); got != "sk-file" {
+		t.Errorf("GetKey = %q; an unavailable keyring should fall back to the auth file", got)
+	}
+	if err := store.SaveToKeyring("anthropic", "sk-new"); err == nil {
+		t.Error("SaveToKeyring should fail without a keyring")
+	}
+	if store.Keys["anthropic"] != "sk-file" {
+		t.Error("a failed SaveToKeyring should leave the auth file key")
+	}
+}
@@ -0,0 +1,100 @@
+// ABOUTME: Persists individual keys into the user or project settings.json, or the gitignored settings.local.json
+// ABOUTME: Preserves unrelated keys so user edits to the file survive runtime updates
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// SaveLocalSetting sets a single top-level key in .pi-go/settings.local.json,
+// creating the file if needed. A nil value removes the key.
+func SaveLocalSetting(projectRoot, key string, value any) error {
+	return saveSettingKey(LocalSettingsFile(projectRoot), key, value)
+}
+
+// SaveProjectSetting sets a single top-level key in the shared
+// .pi-go/settings.json, creating the file if needed. A nil value removes the key.
+func SaveProjectSetting(projectRoot, key string, value any) error {
+	return saveSettingKey(ProjectSettingsFile(projectRoot), key, value)
+}
+
+// SaveUserSetting sets a single top-level key in the user's
+// ~/.pi-go/settings.json, creating the file if needed. A nil value removes the key.
+func SaveUserSetting(key string, value any) error {
+	return saveSettingKey(UserSettingsFile(), key, value)
+}
+
+// SaveUserSettingEntry sets one entry of an object-valued top-level key in
+// the user's ~/.pi-go/settings.json, keeping its other entries. A nil value
+// removes the entry.
+func SaveUserSettingEntry(key, name string, value any) error {
+	path := UserSettingsFile()
+	entries := make(map[string]json.RawMessage)
+	if data, err := os.ReadFile(path); err == nil {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return fmt.Errorf("parsing %s: %w", path, err)
+		}
+		if raw, ok := fields[key]; ok {
+			if err := json.Unmarshal(raw, &entries); err != nil {
+				return fmt.Errorf("parsing %s in %s: %w", key, path, err)
+			}
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	if value == nil {
+		delete(entries, name)
+	} else {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("encoding %s.%s: %w", key, name, err)
+		}
+		entries[name] = raw
+	}
+	if len(entries) == 0 {
+		return saveSettingKey(path, key, nil)
+	}
+	return saveSettingKey(path, key, entries)
+}
+
+// saveSettingKey rewrites one top-level key of the JSON settings file at path.
+func saveSettingKey(path, key string, value any) error {
+	fields := make(map[string]json.RawMessage)
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return fmt.Errorf("parsing %s: %w", path, err)
+		}
+	case !os.IsNotExist(err):
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	if value == nil {
+		delete(fields, key)
+	} else {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("encoding %s: %w", key, err)
+		}
+		fields[key] = raw
+	}
+
+	out, err := json.MarshalIndent(fields, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding %s: %w", path, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, append(out, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+// ABOUTME: Tests for SaveLocalSetting and SaveUserSetting persisting keys into settings files
+// ABOUTME: Verifies creation, key preservation, removal, and round-trip through LoadAllWithHome
+
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveLocalSetting_CreatesAndPreserves(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	path := LocalSettingsFile(root)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`{"model":"opus","yolo":true}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SaveLocalSetting(root, "outputStyle", "teaching"); err != nil {
+		t.Fatalf("SaveLocalSetting() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["outputStyle"] != "teaching" || got["model"] != "opus" || got["yolo"] != true {
+		t.Errorf("settings.local.json = %v", got)
+	}
+
+	s, err := LoadAllWithHome(root, t.TempDir(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.OutputStyle != "teaching" {
+		t.Errorf("OutputStyle = %q; want teaching", s.OutputStyle)
+	}
+}
+
+func TestSaveLocalSetting_NilRemovesKey(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	if err := SaveLocalSetting(root, "outputStyle", "terse"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveLocalSetting(root, "outputStyle", nil); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(LocalSettingsFile(root))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := got["outputStyle"]; ok {
+		t.Errorf("outputStyle still present: %v", got)
+	}
+}
+
+func TestSaveLocalSetting_InvalidExistingFile(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	path := LocalSettingsFile(root)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`{not json`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveLocalSetting(root, "outputStyle", "terse"); err == nil {
+		t.Error("expected error for malformed settings.local.json")
+	}
+}
+
+func TestSaveUserSetting(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := SaveUserSetting("theme", "light"); err != nil {
+		t.Fatalf("SaveUserSetting() error = %v", err)
+	}
+	s, err := LoadAllWithHome(t.TempDir(), home, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Theme != "light" {
+		t.Errorf("Theme = %q; want light", s.Theme)
+	}
+}
+
+func TestSaveUserSettingEntry(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := SaveUserSetting("theme", "light"); err != nil {
+		t.Fatal(err)
+	}
+	for _, kv := range [][2]string{{"m1", "high"}, {"m2", "low"}, {"m1", "minimal"}} {
+		if err := SaveUserSettingEntry("thinkingLevels", kv[0], kv[1]); err != nil {
+			t.Fatalf("SaveUserSettingEntry(%s) error = %v", kv[0], err)
+		}
+	}
+	s, err := LoadAllWithHome(t.TempDir(), home, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Theme != "light" {
+		t.Errorf("Theme = %q; want the other key kept", s.Theme)
+	}
+	if s.ThinkingLevels["m1"] != "minimal" || s.ThinkingLevels["m2"] != "low" {
+		t.Errorf("ThinkingLevels = %v; want m1=minimal m2=low", s.ThinkingLevels)
+	}
+
+	if err := SaveUserSettingEntry("thinkingLevels", "m2", nil); err != nil {
+		t.Fatal(err)
+	}
+	s, err = LoadAllWithHome(t.TempDir(), home, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.ThinkingLevels["m2"]; ok {
+		t.Errorf("ThinkingLevels = %v; want m2 removed", s.ThinkingLevels)
+	}
+}
@@ -0,0 +1,95 @@
+// ABOUTME: Locked keys from the org-managed settings file: lower levels may not set them
+// ABOUTME: Keys are dotted JSON paths such as "model" or "permissions.defaultMode"
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// settingsSource is one loaded settings level, kept as generic JSON so
+// locked keys can be looked up by path.
+type settingsSource struct {
+	name string // file path, or a description such as "command-line flags"
+	raw  map[string]any
+}
+
+// rawSettings returns the keys s sets as generic JSON. Empty objects are
+// dropped, so a section with no values set does not count as set.
+func rawSettings(s *Settings) map[string]any {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil
+	}
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil
+	}
+	pruneEmpty(raw)
+	return raw
+}
+
+// pruneEmpty removes empty objects from m, innermost first.
+func pruneEmpty(m map[string]any) {
+	for k, v := range m {
+		if sub, ok := v.(map[string]any); ok {
+			pruneEmpty(sub)
+			if len(sub) == 0 {
+				delete(m, k)
+			}
+		}
+	}
+}
+
+// lookupKey returns the value at a dotted path and whether it is set.
+func lookupKey(raw map[string]any, key string) (any, bool) {
+	var v any = raw
+	for part := range strings.SplitSeq(key, ".") {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if v, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// checkLockedKeys fails when a lower level sets a key that safety locks
+// to a different value than the managed settings give it. Setting it to
+// the managed value is allowed.
+func checkLockedKeys(managed settingsSource, safety *SafetySettings, lower []settingsSource) error {
+	if safety == nil {
+		return nil
+	}
+	for _, key := range safety.LockedKeys {
+		want, wantSet := lookupKey(managed.raw, key)
+		for _, src := range lower {
+			got, ok := lookupKey(src.raw, key)
+			if !ok || (wantSet && reflect.DeepEqual(got, want)) {
+				continue
+			}
+			return fmt.Errorf("%s sets %q, which is locked by the managed settings in %s; remove it from %s", src.name, key, managed.name, src.name)
+		}
+	}
+	return nil
+}
+
+// CheckManagedLocks returns the error LoadAll would report if s were saved
+// to the settings file name, so a write can be refused before it happens.
+// It is nil when no managed settings file exists.
+func CheckManagedLocks(name string, s *Settings) error {
+	return checkManagedLocks(managedSettingsPath(), name, s)
+}
+
+func checkManagedLocks(managedPath, name string, s *Settings) error {
+	managed, err := loadFile(managedPath)
+	if err != nil {
+		return nil
+	}
+	return checkLockedKeys(settingsSource{name: managedPath, raw: rawSettings(managed)}, managed.Safety, []settingsSource{{name: name, raw: rawSettings(s)}})
+}
@@ -11,6 +11,10 @@ import (
 	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
 )
 
+// LocalMaxToolSchemas is how many tools an ollama: or vllm: model gets per
+// call unless modelOverrides says otherwise.
+const LocalMaxToolSchemas = 10
+
 // ApplyModelOverrides applies per-model and global settings overrides to a model.
 func ApplyModelOverrides(m *ai.Model, settings *Settings) {
 	if settings == nil {
@@ -45,6 +49,27 @@ func ApplyModelOverrides(m *ai.Model, settings *Settings) {
 		}
 		maps.Copy(m.CustomHeaders, override.CustomHeaders)
 	}
+	if override.SupportsTools != nil {
+		m.SupportsTools = *override.SupportsTools
+		m.TextToolCalls = !m.SupportsTools
+	}
+	if override.SupportsParallelToolCalls != nil {
+		m.SupportsParallelToolCalls = *override.SupportsParallelToolCalls
+	}
+	if override.SupportsImages != nil {
+		m.SupportsImages = *override.SupportsImages
+	}
+	if override.MaxToolSchemas != 0 {
+		m.MaxToolSchemas = max(override.MaxToolSchemas, 0) // negative: no limit
+	}
+	switch override.ToolCalling {
+	case "native":
+		m.SupportsTools, m.TextToolCalls = true, false
+	case "text":
+		m.TextToolCalls = true
+	case "none":
+		m.SupportsTools, m.TextToolCalls = false, false
+	}
 }
 
 // ResolveModel finds a model by ID or alias.
@@ -78,19 +103,29 @@ func customModel(provider, modelID string) (*ai.Model, error) {
 		api = ai.ApiGoogle
 	case "vertex":
 		api = ai.ApiVertex
+	case "mock":
+		api = ai.ApiMock
 	case "ollama", "vllm":
 		api = ai.ApiOpenAI // Ollama and vLLM use OpenAI-compatible API
 	default:
 		return nil, fmt.Errorf("unknown provider %q", provider)
 	}
 
-	return &ai.Model{
-		ID:              modelID,
-		Name:            modelID,
-		Api:             api,
-		MaxTokens:       128000,
-		MaxOutputTokens: 16384,
-		SupportsImages:  true,
-		SupportsTools:   true,
-	}, nil
+	m := &ai.Model{
+		ID:                        modelID,
+		Name:                      modelID,
+		Api:                       api,
+		MaxTokens:                 128000,
+		MaxOutputTokens:           16384,
+		SupportsImages:            true,
+		SupportsTools:             true,
+		SupportsParallelToolCalls: true,
+	}
+	// Models served locally are mostly small: they handle one call at a
+	// time over a short tool list better. modelOverrides lifts the limits.
+	if p := strings.ToLower(provider); p == "ollama" || p == "vllm" {
+		m.SupportsParallelToolCalls = false
+		m.MaxToolSchemas = LocalMaxToolSchemas
+	}
+	return m, nil
 }
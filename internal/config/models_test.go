@@ -138,6 +138,60 @@ func TestApplyModelOverrides_PerModelOverridesGlobalBaseURL(t *testing.T) {
 	}
 }
 
+func TestApplyModelOverrides_Capabilities(t *testing.T) {
+	t.Parallel()
+
+	m, err := ResolveModel("ollama:qwen2.5-coder:7b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.SupportsParallelToolCalls || m.MaxToolSchemas != LocalMaxToolSchemas {
+		t.Fatalf("local model: parallel=%v maxToolSchemas=%d; want false, %d", m.SupportsParallelToolCalls, m.MaxToolSchemas, LocalMaxToolSchemas)
+	}
+
+	no := false
+	ApplyModelOverrides(m, &Settings{ModelOverrides: map[string]ModelOverride{
+		"qwen2.5-coder:7b": {SupportsTools: &no, SupportsImages: &no, MaxToolSchemas: -1},
+	}})
+	if m.SupportsTools || m.SupportsImages {
+		t.Errorf("SupportsTools, SupportsImages = %v, %v; want false", m.SupportsTools, m.SupportsImages)
+	}
+	if m.MaxToolSchemas != 0 {
+		t.Errorf("MaxToolSchemas = %d; -1 should lift the limit", m.MaxToolSchemas)
+	}
+	if hosted, _ := ResolveModel("openai:gpt-4.1"); !hosted.SupportsParallelToolCalls || hosted.MaxToolSchemas != 0 {
+		t.Errorf("hosted custom model should keep the defaults: %+v", hosted)
+	}
+}
+
+func TestApplyModelOverrides_ToolCalling(t *testing.T) {
+	t.Parallel()
+
+	no := false
+	tests := []struct {
+		name      string
+		override  ModelOverride
+		tools     bool
+		textCalls bool
+	}{
+		{"unset keeps native", ModelOverride{}, true, false},
+		{"supportsTools false falls back to text", ModelOverride{SupportsTools: &no}, false, true},
+		{"text", ModelOverride{ToolCalling: "text"}, true, true},
+		{"none", ModelOverride{SupportsTools: &no, ToolCalling: "none"}, false, false},
+		{"native", ModelOverride{SupportsTools: &no, ToolCalling: "native"}, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			m := &ai.Model{ID: "local", SupportsTools: true}
+			ApplyModelOverrides(m, &Settings{ModelOverrides: map[string]ModelOverride{"local": tt.override}})
+			if m.SupportsTools != tt.tools || m.TextToolCalls != tt.textCalls {
+				t.Errorf("SupportsTools, TextToolCalls = %v, %v; want %v, %v", m.SupportsTools, m.TextToolCalls, tt.tools, tt.textCalls)
+			}
+		})
+	}
+}
+
 func TestApplyModelOverrides_NilSettings(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,183 @@
+// ABOUTME: Network settings: proxy, CA bundle, and transport timeouts/keepalive/HTTP/2 per provider or base URL
+// ABOUTME: Resolves layered defaults -> provider -> base URL prefix into ai.HTTPOptions
+
+package config
+
+import (
+	"crypto/x509"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+// NetworkSettings configures provider HTTP transports. The embedded fields
+// apply to every provider; Overrides entries keyed by provider API
+// ("anthropic", "openai", "google", "vertex") or by base URL prefix
+// ("http://localhost:11434") refine them, base URL matches winning.
+// Proxy, CACert, and InsecureSkipVerify apply to every HTTP client: the
+// providers, the web tools, and MCP servers over HTTP.
+type NetworkSettings struct {
+	HTTPSettings
+	Overrides map[string]HTTPSettings `json:"overrides,omitempty"`
+
+	Proxy              string `json:"proxy,omitempty"`              // proxy URL; default HTTPS_PROXY/HTTP_PROXY
+	CACert             string `json:"caCert,omitempty"`             // PEM bundle trusted on top of the system roots
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"` // skip TLS verification; debugging only
+
+	rootCAs *x509.CertPool // system roots plus CACert, set by LoadCACert
+}
+
+// HTTPSettings holds transport limits in milliseconds. Zero keeps the
+// default; -1 disables the limit.
+type HTTPSettings struct {
+	ConnectTimeout        int   `json:"connectTimeout,omitempty"`        // default 30000
+	ResponseHeaderTimeout int   `json:"responseHeaderTimeout,omitempty"` // default 30000; raise for slow local backends
+	IdleReadTimeout       int   `json:"idleReadTimeout,omitempty"`       // max stream silence; default off
+	RequestTimeout        int   `json:"requestTimeout,omitempty"`        // whole request; default 300000
+	KeepAlive             int   `json:"keepAlive,omitempty"`             // TCP keepalive; default 30000
+	HTTP2                 *bool `json:"http2,omitempty"`                 // default true
+	HTTP2PingInterval     int   `json:"http2PingInterval,omitempty"`     // PING silent connections; default off
+}
+
+// overlay returns h with every non-zero field of o applied on top.
+func (h HTTPSettings) overlay(o HTTPSettings) HTTPSettings {
+	if o.ConnectTimeout != 0 {
+		h.ConnectTimeout = o.ConnectTimeout
+	}
+	if o.ResponseHeaderTimeout != 0 {
+		h.ResponseHeaderTimeout = o.ResponseHeaderTimeout
+	}
+	if o.IdleReadTimeout != 0 {
+		h.IdleReadTimeout = o.IdleReadTimeout
+	}
+	if o.RequestTimeout != 0 {
+		h.RequestTimeout = o.RequestTimeout
+	}
+	if o.KeepAlive != 0 {
+		h.KeepAlive = o.KeepAlive
+	}
+	if o.HTTP2 != nil {
+		h.HTTP2 = o.HTTP2
+	}
+	if o.HTTP2PingInterval != 0 {
+		h.HTTP2PingInterval = o.HTTP2PingInterval
+	}
+	return h
+}
+
+// Options converts the settings to ai.HTTPOptions.
+func (h HTTPSettings) Options() ai.HTTPOptions {
+	return ai.HTTPOptions{
+		ConnectTimeout:        msDuration(h.ConnectTimeout),
+		ResponseHeaderTimeout: msDuration(h.ResponseHeaderTimeout),
+		IdleReadTimeout:       msDuration(h.IdleReadTimeout),
+		RequestTimeout:        msDuration(h.RequestTimeout),
+		KeepAlive:             msDuration(h.KeepAlive),
+		DisableHTTP2:          h.HTTP2 != nil && !*h.HTTP2,
+		HTTP2PingInterval:     msDuration(h.HTTP2PingInterval),
+	}
+}
+
+// msDuration keeps negative values negative so "disabled" survives conversion.
+func msDuration(ms int) time.Duration {
+	if ms < 0 {
+		return -1
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// LoadCACert reads the CACert bundle into the pool HTTPOptionsFor hands to
+// transports. The certificates are added to the system roots, so public
+// endpoints keep working. A nil receiver or an empty CACert does nothing.
+func (n *NetworkSettings) LoadCACert() error {
+	if n == nil {
+		return nil
+	}
+	if n.CACert == "" {
+		n.rootCAs = nil
+		return nil
+	}
+	path := n.CACert
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("network.caCert: %w", err)
+		}
+		path = filepath.Join(home, rest)
+	}
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("network.caCert: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return fmt.Errorf("network.caCert: no PEM certificates in %s", path)
+	}
+	n.rootCAs = pool
+	return nil
+}
+
+// RedactedProxy returns the proxy URL with any password masked.
+func (n *NetworkSettings) RedactedProxy() string {
+	if n == nil || n.Proxy == "" {
+		return ""
+	}
+	u, err := url.Parse(n.Proxy)
+	if err != nil {
+		return n.Proxy
+	}
+	return u.Redacted()
+}
+
+// ClientOptions returns only the proxy and TLS options, for HTTP clients
+// other than the providers. Their response header timeout stays off, as
+// with the standard library's default transport.
+func (n *NetworkSettings) ClientOptions() ai.HTTPOptions {
+	opts := ai.HTTPOptions{ResponseHeaderTimeout: -1}
+	if n != nil {
+		opts.Proxy = n.Proxy
+		opts.RootCAs = n.rootCAs
+		opts.InsecureSkipVerify = n.InsecureSkipVerify
+	}
+	return opts
+}
+
+// HTTPOptionsFor resolves the transport options for a provider API and base
+// URL: global settings, then the API override, then every matching base URL
+// prefix override from least to most specific. A nil receiver yields the defaults.
+func (n *NetworkSettings) HTTPOptionsFor(api, baseURL string) ai.HTTPOptions {
+	if n == nil {
+		return ai.HTTPOptions{}
+	}
+	h := n.HTTPSettings
+	if o, ok := n.Overrides[api]; ok {
+		h = h.overlay(o)
+	}
+	if baseURL != "" {
+		var prefixes []string
+		for key := range n.Overrides {
+			if strings.Contains(key, "://") && strings.HasPrefix(baseURL, strings.TrimRight(key, "/")) {
+				prefixes = append(prefixes, key)
+			}
+		}
+		// Shorter prefixes first so more specific ones win.
+		slices.SortFunc(prefixes, func(a, b string) int { return len(a) - len(b) })
+		for _, key := range prefixes {
+			h = h.overlay(n.Overrides[key])
+		}
+	}
+	opts := h.Options()
+	opts.Proxy = n.Proxy
+	opts.RootCAs = n.rootCAs
+	opts.InsecureSkipVerify = n.InsecureSkipVerify
+	return opts
+}
@@ -33,6 +33,26 @@ func SessionsDir() string {
 	return filepath.Join(GlobalDir(), "sessions")
 }
 
+// LogsDir returns where per-session structured logs are written.
+func LogsDir() string {
+	return filepath.Join(GlobalDir(), "logs")
+}
+
+// AuditFile returns the default audit log of tool calls and permission decisions.
+func AuditFile() string {
+	return filepath.Join(GlobalDir(), "audit.jsonl")
+}
+
+// IDEDir returns the directory holding live IDE link sockets and lock files.
+func IDEDir() string {
+	return filepath.Join(GlobalDir(), "ide")
+}
+
+// FetchCacheDir returns where cached documentation pages persist across sessions.
+func FetchCacheDir() string {
+	return filepath.Join(GlobalDir(), "cache", "fetch")
+}
+
 // AuthFile returns the path to the auth credentials file.
 func AuthFile() string {
 	return filepath.Join(GlobalDir(), "auth.json")
@@ -49,11 +69,12 @@ func ProjectConfigFile(projectRoot string) string {
 }
 
 // SkillsDirs returns the skill directories in resolution order
-// (project-local first, then global, then Claude Code compat).
+// (project-local first, then pi compat .pi/skills, global, and Claude Code compat).
 func SkillsDirs(projectRoot string) []string {
 	home, _ := os.UserHomeDir()
 	dirs := []string{
 		filepath.Join(ProjectDir(projectRoot), "skills"),
+		filepath.Join(projectRoot, ".pi", "skills"),
 		filepath.Join(GlobalDir(), "skills"),
 		filepath.Join(home, ".claude", "skills"),
 	}
@@ -89,19 +110,64 @@ func LocalSettingsFile(projectRoot string) string {
 	return filepath.Join(ProjectDir(projectRoot), "settings.local.json")
 }
 
-// ManagedSettingsFile returns the platform-dependent managed settings path.
+// ManagedSettingsFile returns the platform-dependent path of the
+// org-managed settings file, whose values and locked keys user and
+// project settings cannot override.
 func ManagedSettingsFile() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return "/Library/Application Support/pi-go/managed-settings.json"
+	case "windows":
+		if dir := os.Getenv("ProgramData"); dir != "" {
+			return filepath.Join(dir, "pi-go", "managed-settings.json")
+		}
+		return `C:\ProgramData\pi-go\managed-settings.json`
+	default:
+		return "/etc/pi-go/managed-settings.json"
+	}
+}
+
+// legacyManagedSettingsFile returns where the managed settings file lived
+// before it was renamed to ManagedSettingsFile, or "" where there was none.
+func legacyManagedSettingsFile() string {
 	switch runtime.GOOS {
 	case "linux":
 		return "/etc/pi-go/settings.json"
-	default:
-		// macOS / fallback
-		home, _ := os.UserHomeDir()
-		if home != "" {
+	case "darwin":
+		if home, _ := os.UserHomeDir(); home != "" {
 			return filepath.Join(home, "Library", "Application Support", "pi-go", "settings.json")
 		}
-		return filepath.Join("/etc", "pi-go", "settings.json")
 	}
+	return ""
+}
+
+// DeprecatedManagedSettingsFile returns the legacy managed settings path
+// when it is still read because ManagedSettingsFile does not exist, or "".
+func DeprecatedManagedSettingsFile() string {
+	return legacyManagedFallback(ManagedSettingsFile(), legacyManagedSettingsFile())
+}
+
+// legacyManagedFallback returns legacy when it exists and path does not.
+func legacyManagedFallback(path, legacy string) string {
+	if legacy == "" {
+		return ""
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		return ""
+	}
+	if _, err := os.Stat(legacy); err != nil {
+		return ""
+	}
+	return legacy
+}
+
+// managedSettingsPath returns the managed settings file LoadAll reads:
+// ManagedSettingsFile, or the legacy file while only that one exists.
+func managedSettingsPath() string {
+	if legacy := DeprecatedManagedSettingsFile(); legacy != "" {
+		return legacy
+	}
+	return ManagedSettingsFile()
 }
 
 // RulesDirs returns the rules directories for a project.
@@ -119,6 +185,7 @@ func AgentsDirs(projectRoot string) []string {
 	home, _ := os.UserHomeDir()
 	dirs := []string{
 		filepath.Join(ProjectDir(projectRoot), "agents"),
+		filepath.Join(projectRoot, ".pi", "agents"),
 		filepath.Join(home, ".pi-go", "agents"),
 		filepath.Join(home, ".claude", "agents"),
 		filepath.Join(projectRoot, ".claude", "agents"),
@@ -162,11 +229,30 @@ func ExtensionsDirs(projectRoot string) []string {
 	return dirs
 }
 
+// OutputStylesDirs returns the output style directories in load order;
+// later directories override earlier ones (global first, then project).
+func OutputStylesDirs(projectRoot string) []string {
+	return []string{
+		filepath.Join(GlobalDir(), "output-styles"),
+		filepath.Join(ProjectDir(projectRoot), "output-styles"),
+	}
+}
+
 // PackagesDir returns the global packages directory (~/.pi-go/packages/).
 func PackagesDir() string {
 	return filepath.Join(GlobalDir(), "packages")
 }
 
+// PluginsDirs returns the directories searched for plugins, each holding
+// one subdirectory per plugin: ~/.pi/plugins, then ~/.pi-go/plugins.
+func PluginsDirs() []string {
+	dirs := []string{filepath.Join(GlobalDir(), "plugins")}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append([]string{filepath.Join(home, ".pi", "plugins")}, dirs...)
+	}
+	return dirs
+}
+
 // PackagesDirLocal returns the project-local packages directory.
 func PackagesDirLocal(projectRoot string) string {
 	return filepath.Join(ProjectDir(projectRoot), "packages")
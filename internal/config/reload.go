@@ -0,0 +1,50 @@
+// ABOUTME: Settings reload support: the files a running session watches and the keys that changed
+// ABOUTME: ChangedKeys compares two merged settings by top-level JSON key
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+)
+
+// levelFiles returns the user, project, and local settings files in load
+// order, lowest priority first.
+func levelFiles(projectRoot, homeDir string) []string {
+	return []string{
+		filepath.Join(homeDir, ".pi-go", "config.json"),
+		filepath.Join(homeDir, ".pi-go", "settings.json"),
+		filepath.Join(projectRoot, ".pi-go", "config.json"),
+		filepath.Join(projectRoot, ".pi-go", "settings.json"),
+		filepath.Join(projectRoot, ".pi-go", "settings.local.json"),
+	}
+}
+
+// SettingsFiles returns every settings file LoadAll reads for projectRoot,
+// managed settings last. Files that do not exist yet are included, so a
+// watcher notices when one is created.
+func SettingsFiles(projectRoot string) []string {
+	home, _ := os.UserHomeDir()
+	return append(levelFiles(projectRoot, home), managedSettingsPath())
+}
+
+// ChangedKeys returns the top-level keys, by JSON name, whose values differ
+// between old and fresh, sorted.
+func ChangedKeys(old, fresh *Settings) []string {
+	a, b := rawSettings(old), rawSettings(fresh)
+	var changed []string
+	for k, v := range a {
+		if !reflect.DeepEqual(v, b[k]) {
+			changed = append(changed, k)
+		}
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			changed = append(changed, k)
+		}
+	}
+	slices.Sort(changed)
+	return changed
+}
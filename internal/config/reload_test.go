@@ -0,0 +1,55 @@
+// ABOUTME: Tests for settings reload support: watched files and changed-key detection
+// ABOUTME: ChangedKeys must ignore unchanged and empty sections
+
+package config
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestSettingsFiles(t *testing.T) {
+	t.Parallel()
+
+	project := t.TempDir()
+	files := SettingsFiles(project)
+	for _, want := range []string{
+		filepath.Join(project, ".pi-go", "settings.json"),
+		filepath.Join(project, ".pi-go", "settings.local.json"),
+	} {
+		if !slices.Contains(files, want) {
+			t.Errorf("SettingsFiles missing %s: %v", want, files)
+		}
+	}
+	if last := files[len(files)-1]; last != ManagedSettingsFile() {
+		t.Errorf("managed settings should be last; got %s", last)
+	}
+}
+
+func TestChangedKeys(t *testing.T) {
+	t.Parallel()
+
+	old := &Settings{
+		Theme:      "dark",
+		Model:      "claude-sonnet",
+		StatusLine: &StatusLineConfig{Command: "status.sh"},
+	}
+	fresh := &Settings{
+		Theme:       "light",
+		Model:       "claude-sonnet",
+		StatusLine:  &StatusLineConfig{Command: "status.sh"},
+		Permissions: &PermissionsConfig{Allow: []string{"Bash(go test*)"}},
+		Compaction:  &CompactionSettings{},
+	}
+	got := ChangedKeys(old, fresh)
+	if want := []string{"permissions", "theme"}; !slices.Equal(got, want) {
+		t.Errorf("ChangedKeys = %v; want %v", got, want)
+	}
+	if got := ChangedKeys(fresh, old); !slices.Equal(got, []string{"permissions", "theme"}) {
+		t.Errorf("removed keys should count as changed; got %v", got)
+	}
+	if got := ChangedKeys(old, old); len(got) != 0 {
+		t.Errorf("identical settings changed %v", got)
+	}
+}
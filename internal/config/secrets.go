@@ -0,0 +1,103 @@
+// ABOUTME: Secret references in settings: values such as "op://vault/item/field" or "keychain://service/account"
+// ABOUTME: Resolved at load time through the 1Password CLI or the OS keyring, once per process
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// secretTimeout bounds one secret lookup; a manager waiting on an unlock
+// prompt fails the load instead of hanging it.
+const secretTimeout = 30 * time.Second
+
+var (
+	secretMu    sync.Mutex
+	secretCache = map[string]string{} // reference -> secret, for reloads
+)
+
+// runSecretCommand runs a secret manager's CLI and returns its stdout.
+// Tests replace it.
+var runSecretCommand = func(name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), secretTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, name, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s: %w: %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// ResolveSecrets replaces every settings value that is a secret reference
+// with the secret it names. A reference that cannot be read fails the load,
+// naming the settings key that holds it.
+func ResolveSecrets(s *Settings) error {
+	return walkStrings(reflect.ValueOf(s), "", func(path, v string) (string, error) {
+		if !isSecretRef(v) {
+			return v, nil
+		}
+		secret, err := resolveSecret(v)
+		if err != nil {
+			return "", fmt.Errorf("settings %s: %w", path, err)
+		}
+		return secret, nil
+	})
+}
+
+// isSecretRef reports whether v is a secret reference rather than a value.
+func isSecretRef(v string) bool {
+	return strings.HasPrefix(v, "op://") || strings.HasPrefix(v, "keychain://")
+}
+
+// resolveSecret reads ref, caching the secret for the life of the process.
+func resolveSecret(ref string) (string, error) {
+	secretMu.Lock()
+	defer secretMu.Unlock()
+	if secret, ok := secretCache[ref]; ok {
+		return secret, nil
+	}
+	secret, err := readSecret(ref)
+	if err != nil {
+		return "", err
+	}
+	if secret == "" {
+		return "", fmt.Errorf("reading %s: the secret is empty", ref)
+	}
+	secretCache[ref] = secret
+	return secret, nil
+}
+
+// readSecret reads ref from the 1Password CLI or the OS keyring.
+func readSecret(ref string) (string, error) {
+	if strings.HasPrefix(ref, "keychain://") {
+		return keychainSecret(ref)
+	}
+	out, err := runSecretCommand("op", "read", "--no-newline", ref)
+	if err != nil {
+		return "", fmt.Errorf("reading %s: %w", ref, err)
+	}
+	return strings.TrimRight(out, "\r\n"), nil
+}
+
+// keychainSecret reads a keychain://<service>/<account> reference from the
+// OS keyring.
+func keychainSecret(ref string) (string, error) {
+	service, account, ok := strings.Cut(strings.TrimPrefix(ref, "keychain://"), "/")
+	if !ok || service == "" || account == "" {
+		return "", fmt.Errorf("secret reference %q: want keychain://<service>/<account>", ref)
+	}
+	secret, err := osKeyring.get(service, account)
+	if err != nil {
+		return "", fmt.Errorf("reading %s from the %s: %w", ref, osKeyring.name(), err)
+	}
+	return secret, nil
+}
@@ -0,0 +1,131 @@
+// ABOUTME: Tests for secret references in settings: resolution, caching, key paths in errors
+// ABOUTME: The secret manager CLI is stubbed; tests that replace it do not run in parallel
+
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// stubSecrets answers secret lookups from secrets, keyed by the last
+// argument, and records the commands run.
+func stubSecrets(t *testing.T, secrets map[string]string) *[]string {
+	t.Helper()
+	var calls []string
+	orig := runSecretCommand
+	runSecretCommand = func(name string, args ...string) (string, error) {
+		calls = append(calls, name+" "+strings.Join(args, " "))
+		if secret, ok := secrets[args[len(args)-1]]; ok {
+			return secret + "\n", nil
+		}
+		return "", errors.New("item not found")
+	}
+	t.Cleanup(func() {
+		runSecretCommand = orig
+		secretMu.Lock()
+		clear(secretCache)
+		secretMu.Unlock()
+	})
+	return &calls
+}
+
+func TestResolveSecrets(t *testing.T) {
+	calls := stubSecrets(t, map[string]string{"op://dev/anthropic/key": "sk-ant-123"})
+	s := &Settings{
+		KeyPools: map[string][]PooledKey{"anthropic": {{Name: "team", Key: "op://dev/anthropic/key"}}},
+		ModelOverrides: map[string]ModelOverride{
+			"local": {CustomHeaders: map[string]string{"Authorization": "op://dev/anthropic/key"}},
+		},
+		Model: "claude-sonnet",
+	}
+	if err := ResolveSecrets(s); err != nil {
+		t.Fatalf("ResolveSecrets: %v", err)
+	}
+	if got := s.KeyPools["anthropic"][0].Key; got != "sk-ant-123" {
+		t.Errorf("pooled key = %q; want the secret without its newline", got)
+	}
+	if got := s.ModelOverrides["local"].CustomHeaders["Authorization"]; got != "sk-ant-123" {
+		t.Errorf("header = %q; want the secret", got)
+	}
+	if s.Model != "claude-sonnet" {
+		t.Errorf("plain values should be left alone; model = %q", s.Model)
+	}
+	if len(*calls) != 1 || (*calls)[0] != "op read --no-newline op://dev/anthropic/key" {
+		t.Errorf("commands = %v; want one op read, the second use cached", *calls)
+	}
+}
+
+func TestResolveSecrets_ErrorNamesKey(t *testing.T) {
+	stubSecrets(t, nil)
+	s := &Settings{Network: &NetworkSettings{}, Env: map[string]string{"GITHUB_TOKEN": "op://dev/github/token"}}
+	err := ResolveSecrets(s)
+	if err == nil || !strings.Contains(err.Error(), "settings env.GITHUB_TOKEN: reading op://dev/github/token: item not found") {
+		t.Errorf("error = %v; want the key, the reference, and the cause", err)
+	}
+}
+
+func TestResolveSecrets_Keychain(t *testing.T) {
+	stubSecrets(t, nil)
+	kr := useMemKeyring(t)
+	kr.secrets["corp-proxy/ci"] = "hunter2"
+
+	s := &Settings{Env: map[string]string{"PROXY_PASSWORD": "keychain://corp-proxy/ci"}}
+	if err := ResolveSecrets(s); err != nil {
+		t.Fatalf("ResolveSecrets: %v", err)
+	}
+	if s.Env["PROXY_PASSWORD"] != "hunter2" {
+		t.Errorf("env = %q; want the keyring secret", s.Env["PROXY_PASSWORD"])
+	}
+
+	for ref, want := range map[string]string{
+		"keychain://corp-proxy":       "want keychain://<service>/<account>",
+		"keychain://corp-proxy/other": "reading keychain://corp-proxy/other from the test keyring: not found",
+	} {
+		s := &Settings{BaseURL: ref}
+		if err := ResolveSecrets(s); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ResolveSecrets(%s) error = %v; want it to contain %q", ref, err, want)
+		}
+	}
+}
+
+func TestLoadAll_ExpandsReferences(t *testing.T) {
+	stubSecrets(t, map[string]string{"op://dev/proxy/url": "https://proxy.internal"})
+	t.Setenv("PI_TEST_EXCLUDED", "docker")
+
+	home, project := t.TempDir(), t.TempDir()
+	if err := os.MkdirAll(filepath.Join(project, ".pi-go"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeJSON(t, filepath.Join(project, ".pi-go", "settings.json"), `{
+		"base_url": "op://dev/proxy/url",
+		"theme": "${PI_TEST_THEME:-dark}",
+		"sandbox": {"excludedCommands": ["${PI_TEST_EXCLUDED}"]}
+	}`)
+	s, err := LoadAllWithHome(project, home, nil)
+	if err != nil {
+		t.Fatalf("LoadAll: %v", err)
+	}
+	if s.BaseURL != "https://proxy.internal" || s.Theme != "dark" {
+		t.Errorf("base_url = %q, theme = %q; want the secret and the default", s.BaseURL, s.Theme)
+	}
+	if !slices.Equal(s.Sandbox.ExcludedCommands, []string{"docker"}) {
+		t.Errorf("excluded commands = %v; want the expanded variable", s.Sandbox.ExcludedCommands)
+	}
+
+	writeJSON(t, filepath.Join(project, ".pi-go", "settings.json"), `{"base_url": "op://dev/missing"}`)
+	if _, err := LoadAllWithHome(project, home, nil); err == nil || !strings.Contains(err.Error(), "base_url") {
+		t.Errorf("an unreadable secret should fail the load naming the key; got %v", err)
+	}
+}
+
+func TestExpandEnv_Default(t *testing.T) {
+	t.Setenv("PI_TEST_SET", "value")
+	if got := expandEnv("${PI_TEST_SET:-fallback}/${PI_TEST_UNSET_98765:-fallback}"); got != "value/fallback" {
+		t.Errorf("expandEnv = %q; want value/fallback", got)
+	}
+}
@@ -197,16 +197,116 @@ func TestManagedSettingsFile(t *testing.T) {
 
 	switch runtime.GOOS {
 	case "darwin":
-		if !strings.Contains(path, "Library/Application Support") {
-			t.Errorf("on darwin expected Library/Application Support in path, got %q", path)
+		if path != "/Library/Application Support/pi-go/managed-settings.json" {
+			t.Errorf("on darwin expected the system Library path, got %q", path)
 		}
 	case "linux":
-		if path != "/etc/pi-go/settings.json" {
-			t.Errorf("on linux expected /etc/pi-go/settings.json, got %q", path)
+		if path != "/etc/pi-go/managed-settings.json" {
+			t.Errorf("on linux expected /etc/pi-go/managed-settings.json, got %q", path)
 		}
 	}
 }
 
+func TestLegacyManagedFallback(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "managed-settings.json")
+	legacy := filepath.Join(dir, "settings.json")
+
+	if got := legacyManagedFallback(path, legacy); got != "" {
+		t.Errorf("neither file exists: got %q; want \"\"", got)
+	}
+	writeJSON(t, legacy, `{"model":"org-model"}`)
+	if got := legacyManagedFallback(path, legacy); got != legacy {
+		t.Errorf("only the legacy file exists: got %q; want %q", got, legacy)
+	}
+	writeJSON(t, path, `{"model":"org-model"}`)
+	if got := legacyManagedFallback(path, legacy); got != "" {
+		t.Errorf("both files exist: got %q; want the new file to win", got)
+	}
+	if got := legacyManagedFallback(path, ""); got != "" {
+		t.Errorf("no legacy path: got %q", got)
+	}
+}
+
+func TestLoadAll_ManagedOverridesEverything(t *testing.T) {
+	t.Parallel()
+
+	project, home := t.TempDir(), t.TempDir()
+	mkDir(t, filepath.Join(project, ".pi-go"))
+	writeJSON(t, filepath.Join(project, ".pi-go", "settings.json"), `{"model":"project-model","temperature":0.2}`)
+	managed := filepath.Join(t.TempDir(), "managed-settings.json")
+	writeJSON(t, managed, `{"model":"org-model"}`)
+
+	s, err := loadLevels(project, home, managed, &Settings{Model: "cli-model"})
+	if err != nil {
+		t.Fatalf("loadLevels: %v", err)
+	}
+	if s.Model != "org-model" || s.Temperature != 0.2 {
+		t.Errorf("model = %q, temperature = %v; want the managed model and the project temperature", s.Model, s.Temperature)
+	}
+}
+
+func TestLoadAll_LockedKeys(t *testing.T) {
+	t.Parallel()
+
+	managedJSON := `{
+		"permissions": {"defaultMode": "default"},
+		"safety": {"lockedKeys": ["permissions.defaultMode", "base_url", "sandbox"]}
+	}`
+	tests := []struct {
+		name    string
+		project string
+		cli     *Settings
+		wantErr string
+	}{
+		{"unrelated keys are fine", `{"model":"m","permissions":{"allow":["read"]}}`, nil, ""},
+		{"same value as managed is fine", `{"permissions":{"defaultMode":"default"}}`, nil, ""},
+		{"overriding a locked value", `{"permissions":{"defaultMode":"bypassPermissions"}}`, nil, `sets "permissions.defaultMode"`},
+		{"setting a locked key managed leaves unset", `{"base_url":"https://proxy.example"}`, nil, `sets "base_url"`},
+		{"locking a whole section", `{"sandbox":{"allowedDomains":["x.dev"]}}`, nil, `sets "sandbox"`},
+		{"command-line flags", `{}`, &Settings{BaseURL: "https://other"}, `command-line flags sets "base_url"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			project, home := t.TempDir(), t.TempDir()
+			mkDir(t, filepath.Join(project, ".pi-go"))
+			writeJSON(t, filepath.Join(project, ".pi-go", "settings.json"), tt.project)
+			managed := filepath.Join(t.TempDir(), "managed-settings.json")
+			writeJSON(t, managed, managedJSON)
+
+			s, err := loadLevels(project, home, managed, tt.cli)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("loadLevels: %v", err)
+				}
+				if s.EffectiveDefaultMode() != "default" {
+					t.Errorf("default mode = %q; want the managed value", s.EffectiveDefaultMode())
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) || !strings.Contains(err.Error(), managed) {
+				t.Errorf("loadLevels error = %v; want it to contain %q and name the managed file", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadAll_UnreadableManagedSettings(t *testing.T) {
+	t.Parallel()
+
+	managed := filepath.Join(t.TempDir(), "managed-settings.json")
+	writeJSON(t, managed, `{"model":`)
+	if _, err := loadLevels(t.TempDir(), t.TempDir(), managed, nil); err == nil {
+		t.Error("a broken managed settings file must stop startup, not be skipped")
+	}
+	if _, err := loadLevels(t.TempDir(), t.TempDir(), filepath.Join(t.TempDir(), "absent.json"), nil); err != nil {
+		t.Errorf("a missing managed settings file is fine: %v", err)
+	}
+}
+
 func TestHookDef(t *testing.T) {
 	t.Parallel()
 
@@ -298,6 +398,34 @@ func TestLoadAll_UserOverridesPiCompat(t *testing.T) {
 	}
 }
 
+func TestCheckSettingsFile_ReportsLine(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	for _, tc := range []struct {
+		name, content, want string
+	}{
+		{"syntax", "{\n  \"model\": \"opus\",\n  \"theme\": \"dark\",,\n}", "line 3, column 19"},
+		{"type", "{\n  \"model\": \"opus\",\n  \"max_tokens\": \"many\"\n}", "line 3"},
+	} {
+		path := filepath.Join(dir, tc.name+".json")
+		writeJSON(t, path, tc.content)
+		err := CheckSettingsFile(path)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: CheckSettingsFile = %v; want the position %q", tc.name, err, tc.want)
+		}
+	}
+
+	path := filepath.Join(dir, "ok.json")
+	writeJSON(t, path, `{"model": "opus"}`)
+	if err := CheckSettingsFile(path); err != nil {
+		t.Errorf("valid file: %v", err)
+	}
+	if err := CheckSettingsFile(filepath.Join(dir, "missing.json")); !os.IsNotExist(err) {
+		t.Errorf("missing file: %v; want a not-exist error", err)
+	}
+}
+
 // Helpers
 
 func mkDir(t *testing.T, path string) {
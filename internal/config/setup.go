@@ -0,0 +1,148 @@
+// ABOUTME: First-run setup: detects a machine with no settings or keys, checks a pasted API key, and saves the choices
+// ABOUTME: The terminal wizard in cmd/pi-go asks the questions; this file holds the parts that touch files and the network
+
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+// SetupProviders are the hosted providers first-run setup offers, in order.
+var SetupProviders = []string{"anthropic", "openai", "google"}
+
+// setupDefaultModels is the model setup preselects for each provider.
+var setupDefaultModels = map[string]string{
+	"anthropic": ai.ModelClaude4Sonnet.ID,
+	"openai":    ai.ModelGPT4o.ID,
+	"google":    ai.ModelGemini25Pro.ID,
+}
+
+// ErrKeyRejected is returned by VerifyAPIKey when the provider refuses the key.
+var ErrKeyRejected = errors.New("the provider rejected the key")
+
+// Setup holds the answers of first-run setup.
+type Setup struct {
+	Provider string // one of SetupProviders
+	Model    string
+	Theme    string // "auto" or a theme name
+	Autonomy string // one of AutonomyLevels
+}
+
+// NeedsSetup reports whether this machine has neither a user settings file
+// nor a key for any provider, so a session could not reach a model.
+func NeedsSetup(auth *AuthStore) bool {
+	if _, err := os.Stat(UserSettingsFile()); !os.IsNotExist(err) {
+		return false
+	}
+	auth.mu.Lock()
+	stored := len(auth.Keys)
+	auth.mu.Unlock()
+	if stored > 0 {
+		return false
+	}
+	for _, p := range SetupProviders {
+		if auth.KeySource(p) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// SetupModels returns the built-in models of provider with the one setup
+// preselects first.
+func SetupModels(provider string) []ai.Model {
+	var models []ai.Model
+	for _, m := range ai.BuiltinModels() {
+		if string(m.Api) != provider {
+			continue
+		}
+		if m.ID == setupDefaultModels[provider] {
+			models = append([]ai.Model{m}, models...)
+		} else {
+			models = append(models, m)
+		}
+	}
+	return models
+}
+
+// Save writes the model, theme, and starter permissions of s to the user's
+// ~/.pi-go/settings.json, keeping any keys already there. The permissions
+// apply to every project without rules of its own.
+func (s Setup) Save() error {
+	perms, err := StarterPermissions(s.Autonomy)
+	if err != nil {
+		return err
+	}
+	for _, kv := range []struct {
+		key   string
+		value any
+	}{
+		{"model", s.Model},
+		{"theme", s.Theme},
+		{"permissions", perms},
+	} {
+		if err := SaveUserSetting(kv.key, kv.value); err != nil {
+			return fmt.Errorf("saving %s: %w", kv.key, err)
+		}
+	}
+	return nil
+}
+
+// VerifyAPIKey checks key by listing provider's models, which costs no
+// tokens. baseURL overrides the hosted endpoint when set. A refused key
+// returns an error wrapping ErrKeyRejected; any other failure means the
+// key could not be checked.
+func VerifyAPIKey(ctx context.Context, client *http.Client, provider, baseURL, key string) error {
+	var endpoint string
+	header := http.Header{}
+	switch provider {
+	case "anthropic":
+		endpoint = "https://api.anthropic.com/v1/models"
+		header.Set("x-api-key", key)
+		header.Set("anthropic-version", "2023-06-01")
+	case "openai":
+		endpoint = "https://api.openai.com/v1/models"
+		header.Set("Authorization", "Bearer "+key)
+	case "google":
+		endpoint = "https://generativelanguage.googleapis.com/v1beta/models"
+		header.Set("x-goog-api-key", key)
+	default:
+		return fmt.Errorf("unknown provider %q (want %s)", provider, strings.Join(SetupProviders, ", "))
+	}
+	if baseURL != "" {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return err
+		}
+		endpoint = strings.TrimSuffix(baseURL, "/") + u.Path
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header = header
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("%w (HTTP %d)", ErrKeyRejected, resp.StatusCode)
+	case resp.StatusCode == http.StatusBadRequest && provider == "google":
+		// Google answers a malformed key with 400 API_KEY_INVALID.
+		return fmt.Errorf("%w (HTTP %d)", ErrKeyRejected, resp.StatusCode)
+	case resp.StatusCode >= 300:
+		return fmt.Errorf("checking the key: HTTP %d from %s", resp.StatusCode, endpoint)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+// ABOUTME: Tests for first-run setup: detecting a bare machine, ordering models, saving answers, checking keys
+// ABOUTME: Keys are checked against an httptest server standing in for each provider's models endpoint
+
+package config
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+func TestNeedsSetup(t *testing.T) {
+	kr := &memKeyring{secrets: map[string]string{}}
+	store := authWithKeyring(t, kr, map[string]string{})
+	for _, env := range []string{"GOOGLE_API_KEY", "PI_API_KEY_GOOGLE"} {
+		t.Setenv(env, "")
+	}
+	if !NeedsSetup(store) {
+		t.Fatal("a machine without settings or keys needs setup")
+	}
+
+	t.Setenv("OPENAI_API_KEY", "sk-env")
+	if NeedsSetup(store) {
+		t.Error("a key in the environment is enough")
+	}
+	t.Setenv("OPENAI_API_KEY", "")
+
+	store.SetKey("vllm", "token")
+	if NeedsSetup(store) {
+		t.Error("a stored key for any provider is enough")
+	}
+	store.Keys = map[string]string{}
+
+	if err := SaveUserSetting("model", "mock"); err != nil {
+		t.Fatal(err)
+	}
+	if NeedsSetup(store) {
+		t.Error("an existing user settings file means the machine is configured")
+	}
+}
+
+func TestSetupModels_DefaultFirst(t *testing.T) {
+	t.Parallel()
+	models := SetupModels("anthropic")
+	if len(models) < 2 || models[0].ID != ai.ModelClaude4Sonnet.ID {
+		t.Fatalf("SetupModels(anthropic) = %v; want %s first", models, ai.ModelClaude4Sonnet.ID)
+	}
+	for _, m := range models {
+		if m.Api != ai.ApiAnthropic {
+			t.Errorf("SetupModels(anthropic) includes %s (%s)", m.ID, m.Api)
+		}
+	}
+	if got := SetupModels("mock"); len(got) != 1 {
+		t.Errorf("SetupModels(mock) = %v", got)
+	}
+}
+
+func TestSetup_Save(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := SaveUserSetting("outputStyle", "terse"); err != nil {
+		t.Fatal(err)
+	}
+	s := Setup{Provider: "openai", Model: "gpt-4o", Theme: "dark", Autonomy: AutonomyBalanced}
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	got, err := LoadAllWithHome(t.TempDir(), home, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Model != "gpt-4o" || got.Theme != "dark" || got.OutputStyle != "terse" {
+		t.Errorf("Model, Theme, OutputStyle = %q, %q, %q", got.Model, got.Theme, got.OutputStyle)
+	}
+	if got.EffectiveDefaultMode() != "acceptEdits" {
+		t.Errorf("EffectiveDefaultMode() = %q; want acceptEdits", got.EffectiveDefaultMode())
+	}
+	if NeedsPermissionOnboarding(t.TempDir()) {
+		t.Error("a user-level permissions block should skip project onboarding")
+	}
+
+	if err := (Setup{Autonomy: "reckless"}).Save(); err == nil {
+		t.Error("expected error for unknown autonomy level")
+	}
+}
+
+func TestVerifyAPIKey(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		provider string
+		path     string
+		header   string
+		prefix   string
+	}{
+		{"anthropic", "/v1/models", "x-api-key", ""},
+		{"openai", "/v1/models", "Authorization", "Bearer "},
+		{"google", "/v1beta/models", "x-goog-api-key", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			t.Parallel()
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != tt.path {
+					http.NotFound(w, r)
+					return
+				}
+				if r.Header.Get(tt.header) != tt.prefix+"good" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				w.Write([]byte(`{"data":[]}`))
+			}))
+			defer srv.Close()
+
+			ctx := context.Background()
+			if err := VerifyAPIKey(ctx, srv.Client(), tt.provider, srv.URL, "good"); err != nil {
+				t.Errorf("good key: %v", err)
+			}
+			err := VerifyAPIKey(ctx, srv.Client(), tt.provider, srv.URL+"/", "bad")
+			if !errors.Is(err, ErrKeyRejected) {
+				t.Errorf("bad key: err = %v; want ErrKeyRejected", err)
+			}
+		})
+	}
+}
+
+func TestVerifyAPIKey_Unreachable(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	err := VerifyAPIKey(context.Background(), srv.Client(), "openai", srv.URL, "sk")
+	if err == nil || errors.Is(err, ErrKeyRejected) {
+		t.Errorf("err = %v; want a check failure, not a rejection", err)
+	}
+	if err := VerifyAPIKey(context.Background(), srv.Client(), "acme", "", "sk"); err == nil {
+		t.Error("expected error for unknown provider")
+	}
+}
@@ -0,0 +1,318 @@
+// ABOUTME: Container execution mode: one Docker or Podman container per session runs the bash tool's commands
+// ABOUTME: The project is bind-mounted at its own path, so paths match inside and out; network and resources are capped
+
+package container
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Defaults for unset Config fields.
+const (
+	DefaultImage   = "debian:stable-slim"
+	DefaultNetwork = "none"
+	DefaultShell   = "/bin/sh"
+)
+
+// Config describes the container.
+type Config struct {
+	Runtime   string   // "docker" or "podman"; "" uses whichever is installed, docker first
+	Image     string   // "" = DefaultImage
+	Network   string   // "none", "bridge", "host", or a named network; "" = DefaultNetwork
+	CPUs      string   // --cpus, e.g. "2"; "" = unlimited
+	Memory    string   // --memory, e.g. "4g"; "" = unlimited
+	PidsLimit int      // --pids-limit; 0 = unlimited
+	Mounts    []string // extra bind mounts, "host[:container][:ro]"; the container path defaults to the host path
+	Shell     string   // shell commands run in; "" = DefaultShell
+	Workdir   string   // where the project is mounted; "" = its own path
+	User      string   // user commands run as; "" = the current user on Unix, the image's user elsewhere
+	Env       []string // "NAME=value" pairs set in the container
+}
+
+// Mount is one bind mount of a host directory.
+type Mount struct {
+	Host      string
+	Container string
+	ReadOnly  bool
+}
+
+// Container is a running container commands execute in.
+type Container struct {
+	runtime string // resolved runtime executable
+	name    string
+	image   string
+	network string
+	shell   string
+	user    string // user exec runs commands as; "" = the container's
+	root    string
+	mounts  []Mount
+	keep    bool         // Stop leaves the container running: pi-go did not create it
+	seq     atomic.Int64 // numbers commands for their pid files
+}
+
+// ParseMount parses "host[:container][:ro]". The host path may start with
+// ~ and use environment variables; it is made absolute.
+func ParseMount(spec string) (Mount, error) {
+	parts := strings.Split(spec, ":")
+	// A Windows drive letter, as in C:\src, is part of the host path.
+	if runtime.GOOS == "windows" && len(parts) > 1 && len(parts[0]) == 1 {
+		parts = append([]string{parts[0] + ":" + parts[1]}, parts[2:]...)
+	}
+	var m Mount
+	if n := len(parts); n > 1 && (parts[n-1] == "ro" || parts[n-1] == "rw") {
+		m.ReadOnly = parts[n-1] == "ro"
+		parts = parts[:n-1]
+	}
+	if len(parts) == 0 || parts[0] == "" || len(parts) > 2 {
+		return Mount{}, fmt.Errorf("mount %q: want host[:container][:ro]", spec)
+	}
+	host := os.ExpandEnv(parts[0])
+	if rest, ok := strings.CutPrefix(host, "~"); ok && (rest == "" || rest[0] == '/' || rest[0] == filepath.Separator) {
+		if home, err := os.UserHomeDir(); err == nil {
+			host = home + rest
+		}
+	}
+	host, err := filepath.Abs(host)
+	if err != nil {
+		return Mount{}, fmt.Errorf("mount %q: %w", spec, err)
+	}
+	m.Host, m.Container = host, containerDefault(host)
+	if len(parts) == 2 && parts[1] != "" {
+		m.Container = parts[1]
+	}
+	return m, nil
+}
+
+// Start starts a container for the project at root, mounted read-write at
+// the same path, or at cfg.Workdir, and used as the working directory.
+// Unless cfg.User says otherwise it runs as the current user on Unix, so
+// files it creates are the user's.
+func Start(ctx context.Context, cfg Config, root string) (*Container, error) {
+	rt, err := findRuntime(cfg.Runtime)
+	if err != nil {
+		return nil, err
+	}
+	root, err = filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	mounts := []Mount{{Host: root, Container: containerDefault(root)}}
+	if cfg.Workdir != "" {
+		mounts[0].Container = cfg.Workdir
+	}
+	for _, spec := range cfg.Mounts {
+		m, err := ParseMount(spec)
+		if err != nil {
+			return nil, err
+		}
+		mounts = append(mounts, m)
+	}
+	c := &Container{runtime: rt, name: "pi-go-" + randomSuffix(), image: cfg.Image, network: cfg.Network, shell: cfg.Shell, root: root, mounts: mounts}
+	if c.image == "" {
+		c.image = DefaultImage
+	}
+	if c.network == "" {
+		c.network = DefaultNetwork
+	}
+	if c.shell == "" {
+		c.shell = DefaultShell
+	}
+
+	out, err := exec.CommandContext(ctx, rt, c.runArgs(cfg)...).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("starting %s container from %s: %w: %s", c.Runtime(), c.image, err, bytes.TrimSpace(out))
+	}
+	return c, nil
+}
+
+// runArgs returns the arguments of the run command starting c: detached,
+// removed when stopped, and kept alive by sleep until then.
+func (c *Container) runArgs(cfg Config) []string {
+	args := []string{"run", "--detach", "--rm", "--init", "--name", c.name,
+		"--network", c.network, "--workdir", c.containerPath(c.root)}
+	if cfg.User != "" {
+		args = append(args, "--user", cfg.User)
+	} else if uid, gid := os.Getuid(), os.Getgid(); uid >= 0 {
+		args = append(args, "--user", fmt.Sprintf("%d:%d", uid, gid))
+	}
+	for _, kv := range cfg.Env {
+		args = append(args, "--env", kv)
+	}
+	for _, m := range c.mounts {
+		spec := m.Host + ":" + m.Container
+		if m.ReadOnly {
+			spec += ":ro"
+		}
+		args = append(args, "--volume", spec)
+	}
+	if cfg.CPUs != "" {
+		args = append(args, "--cpus", cfg.CPUs)
+	}
+	if cfg.Memory != "" {
+		args = append(args, "--memory", cfg.Memory)
+	}
+	if cfg.PidsLimit > 0 {
+		args = append(args, "--pids-limit", strconv.Itoa(cfg.PidsLimit))
+	}
+	return append(args, c.image, "sleep", "infinity")
+}
+
+// Command returns a command running command in the container's shell in
+// dir (the project root when empty) with env, "NAME=value" pairs, set.
+// Only env reaches the command: the host environment stays outside.
+// Cancelling ctx kills the command inside the container, not just the
+// client running it.
+func (c *Container) Command(ctx context.Context, command, dir string, env []string) *exec.Cmd {
+	if dir == "" {
+		dir = c.root
+	}
+	pidFile := fmt.Sprintf("/tmp/pi-go-%s-%d.pid", c.name, c.seq.Add(1))
+	args := []string{"exec", "--interactive", "--workdir", c.containerPath(dir)}
+	if c.user != "" {
+		args = append(args, "--user", c.user)
+	}
+	for _, kv := range env {
+		args = append(args, "--env", kv)
+	}
+	// The wrapper records the shell's pid so a cancel can kill it.
+	wrapper := `echo $$ > "$0"; exec ` + c.shell + ` -c "$1"`
+	args = append(args, c.name, c.shell, "-c", wrapper, pidFile, command)
+
+	cmd := exec.CommandContext(ctx, c.runtime, args...)
+	cmd.Cancel = func() error {
+		c.kill(pidFile)
+		return cmd.Process.Kill()
+	}
+	cmd.WaitDelay = 2 * time.Second
+	return cmd
+}
+
+// kill ends the process whose pid is in pidFile, and its process group
+// when it leads one.
+func (c *Container) kill(pidFile string) {
+	script := `p=$(cat "$0" 2>/dev/null) && { kill -KILL -- "-$p" 2>/dev/null || kill -KILL "$p"; }; rm -f "$0"`
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_ = exec.CommandContext(ctx, c.runtime, "exec", c.name, c.shell, "-c", script, pidFile).Run()
+}
+
+// Stop removes the container, unless it was attached to rather than
+// started.
+func (c *Container) Stop() error {
+	if c.keep {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if out, err := exec.CommandContext(ctx, c.runtime, "rm", "--force", c.name).CombinedOutput(); err != nil {
+		return fmt.Errorf("removing container %s: %w: %s", c.name, err, bytes.TrimSpace(out))
+	}
+	return nil
+}
+
+// Name returns the container's name.
+func (c *Container) Name() string { return c.name }
+
+// Runtime returns the runtime's name, "docker" or "podman".
+func (c *Container) Runtime() string { return strings.TrimSuffix(filepath.Base(c.runtime), ".exe") }
+
+// Shell returns the shell commands run in.
+func (c *Container) Shell() string { return c.shell }
+
+// String describes the container, as in
+// "docker container pi-go-1a2b3c4d (debian:stable-slim, network none)".
+func (c *Container) String() string {
+	name := c.name
+	if c.keep && len(name) > 12 {
+		name = name[:12] // a container ID
+	}
+	if c.network == "" {
+		return fmt.Sprintf("%s container %s (%s)", c.Runtime(), name, c.image)
+	}
+	return fmt.Sprintf("%s container %s (%s, network %s)", c.Runtime(), name, c.image, c.network)
+}
+
+// Writable reports whether the host path p lies in a read-write mount, so
+// a change to it is a change the container sees.
+func (c *Container) Writable(p string) bool {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return false
+	}
+	for _, m := range c.mounts {
+		if !m.ReadOnly && within(abs, m.Host) {
+			return true
+		}
+	}
+	return false
+}
+
+// containerPath maps a host path to its path in the container. A path
+// outside every mount is passed through, and the runtime reports it.
+func (c *Container) containerPath(p string) string {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return filepath.ToSlash(p)
+	}
+	for _, m := range c.mounts {
+		if within(abs, m.Host) {
+			rel, _ := filepath.Rel(m.Host, abs)
+			return strings.TrimSuffix(m.Container+"/"+filepath.ToSlash(rel), "/.")
+		}
+	}
+	return filepath.ToSlash(abs)
+}
+
+// containerDefault returns where a host directory is mounted unless the
+// mount says otherwise: the same path, or /c/src for C:\src on Windows.
+func containerDefault(host string) string {
+	if vol := filepath.VolumeName(host); len(vol) == 2 && vol[1] == ':' {
+		return "/" + strings.ToLower(vol[:1]) + filepath.ToSlash(host[2:])
+	}
+	return filepath.ToSlash(host)
+}
+
+// within reports whether p is dir or below it.
+func within(p, dir string) bool {
+	rel, err := filepath.Rel(dir, p)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// findRuntime resolves name, or finds docker or else podman on PATH.
+func findRuntime(name string) (string, error) {
+	if name != "" {
+		path, err := exec.LookPath(name)
+		if err != nil {
+			return "", fmt.Errorf("container runtime %q: %w", name, err)
+		}
+		return path, nil
+	}
+	for _, candidate := range []string{"docker", "podman"} {
+		if path, err := exec.LookPath(candidate); err == nil {
+			return path, nil
+		}
+	}
+	return "", errors.New("container mode needs docker or podman on PATH")
+}
+
+// randomSuffix returns a short random hex string for container names.
+func randomSuffix() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b)
+}
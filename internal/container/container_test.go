@@ -0,0 +1,151 @@
+// ABOUTME: Tests for container mode: mount parsing, path mapping, and the runtime commands built
+// ABOUTME: A fake runtime script logs its arguments, so no Docker or Podman is needed
+
+package container
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// fakeRuntime writes an executable that appends its arguments, one call
+// per line, to the returned log file and succeeds.
+func fakeRuntime(t *testing.T) (bin, log string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake runtime is a shell script")
+	}
+	dir := t.TempDir()
+	bin, log = filepath.Join(dir, "docker"), filepath.Join(dir, "calls.log")
+	script := "#!/bin/sh\necho \"$*\" >> " + log + "\n"
+	if err := os.WriteFile(bin, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return bin, log
+}
+
+func TestParseMount(t *testing.T) {
+	t.Parallel()
+
+	wd, _ := os.Getwd()
+	for spec, want := range map[string]Mount{
+		"/data":              {Host: "/data", Container: "/data"},
+		"/data:/mnt/data":    {Host: "/data", Container: "/mnt/data"},
+		"/data:ro":           {Host: "/data", Container: "/data", ReadOnly: true},
+		"/data:/mnt/data:ro": {Host: "/data", Container: "/mnt/data", ReadOnly: true},
+		"shared:/shared:rw":  {Host: filepath.Join(wd, "shared"), Container: "/shared"},
+	} {
+		if runtime.GOOS == "windows" && strings.HasPrefix(spec, "/") {
+			continue
+		}
+		got, err := ParseMount(spec)
+		if err != nil || got != want {
+			t.Errorf("ParseMount(%q) = %+v, %v; want %+v", spec, got, err, want)
+		}
+	}
+	home, _ := os.UserHomeDir()
+	if got, err := ParseMount("~/.cache:/cache"); err != nil || got.Host != filepath.Join(home, ".cache") {
+		t.Errorf("ParseMount(~/.cache) = %+v, %v; want the home directory expanded", got, err)
+	}
+	for _, bad := range []string{"", ":ro", "/a:/b:/c"} {
+		if _, err := ParseMount(bad); err == nil {
+			t.Errorf("ParseMount(%q) succeeded; want an error", bad)
+		}
+	}
+}
+
+func TestStart_RunArgs(t *testing.T) {
+	t.Parallel()
+
+	bin, log := fakeRuntime(t)
+	root := t.TempDir()
+	c, err := Start(context.Background(), Config{
+		Runtime: bin, CPUs: "2", Memory: "4g", PidsLimit: 256, Mounts: []string{"/opt/cache:ro"},
+	}, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls, _ := os.ReadFile(log)
+	got := strings.TrimSpace(string(calls))
+	for _, want := range []string{
+		"run --detach --rm --init --name " + c.Name(),
+		"--network none --workdir " + root,
+		"--volume " + root + ":" + root + " --volume /opt/cache:/opt/cache:ro",
+		"--cpus 2 --memory 4g --pids-limit 256 " + DefaultImage + " sleep infinity",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("run command %q lacks %q", got, want)
+		}
+	}
+	if want := "docker container " + c.Name() + " (" + DefaultImage + ", network none)"; c.String() != want {
+		t.Errorf("String() = %q; want %q", c.String(), want)
+	}
+}
+
+func TestCommand_ExecArgs(t *testing.T) {
+	t.Parallel()
+
+	bin, _ := fakeRuntime(t)
+	root := t.TempDir()
+	c, err := Start(context.Background(), Config{Runtime: bin, Shell: "/bin/bash"}, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd := c.Command(context.Background(), "make test", filepath.Join(root, "sub"), []string{"CI=1"})
+	args := cmd.Args[1:]
+	want := []string{"exec", "--interactive", "--workdir", root + "/sub", "--env", "CI=1", c.Name(), "/bin/bash", "-c"}
+	if len(args) < len(want) || !slices.Equal(args[:len(want)], want) {
+		t.Errorf("exec args = %q; want prefix %q", args, want)
+	}
+	if last := args[len(args)-1]; last != "make test" {
+		t.Errorf("command = %q; want it passed as the last argument", last)
+	}
+	if slices.ContainsFunc(cmd.Env, func(kv string) bool { return strings.HasPrefix(kv, "CI=") }) {
+		t.Error("the command's environment should go to the container, not the runtime client")
+	}
+}
+
+func TestWritable(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("host paths in this test are Unix paths")
+	}
+	c := &Container{root: "/work/project", mounts: []Mount{
+		{Host: "/work/project", Container: "/work/project"},
+		{Host: "/opt/cache", Container: "/cache", ReadOnly: true},
+	}}
+	for p, want := range map[string]bool{
+		"/work/project":          true,
+		"/work/project/src/a.go": true,
+		"/work/project-other/a":  false,
+		"/opt/cache/x":           false,
+		"/etc/passwd":            false,
+	} {
+		if got := c.Writable(p); got != want {
+			t.Errorf("Writable(%q) = %v; want %v", p, got, want)
+		}
+	}
+	if got := c.containerPath("/opt/cache/x/y"); got != "/cache/x/y" {
+		t.Errorf("containerPath = %q; want /cache/x/y", got)
+	}
+	if got := c.containerPath("/work/project"); got != "/work/project" {
+		t.Errorf("containerPath(root) = %q; want /work/project", got)
+	}
+}
+
+func TestContainerDefault_WindowsDrive(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS != "windows" {
+		t.Skip("drive letters are Windows-only")
+	}
+	if got := containerDefault(`C:\src\app`); got != "/c/src/app" {
+		t.Errorf("containerDefault = %q; want /c/src/app", got)
+	}
+}
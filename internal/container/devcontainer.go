@@ -0,0 +1,305 @@
+// ABOUTME: Devcontainer support: finds a project's devcontainer.json and starts the container it describes
+// ABOUTME: Uses the devcontainer CLI when installed; otherwise builds or pulls the image and runs it directly
+
+package container
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Devcontainer is the part of a devcontainer.json pi-go understands.
+type Devcontainer struct {
+	Name  string `json:"name"`
+	Image string `json:"image"`
+	Build struct {
+		Dockerfile string            `json:"dockerfile"`
+		Context    string            `json:"context"`
+		Args       map[string]string `json:"args"`
+		Target     string            `json:"target"`
+	} `json:"build"`
+	DockerFile        string            `json:"dockerFile"` // legacy spelling of build.dockerfile
+	DockerComposeFile json.RawMessage   `json:"dockerComposeFile"`
+	WorkspaceFolder   string            `json:"workspaceFolder"`
+	RemoteUser        string            `json:"remoteUser"`
+	ContainerUser     string            `json:"containerUser"`
+	ContainerEnv      map[string]string `json:"containerEnv"`
+
+	path string // the devcontainer.json file
+}
+
+// FindDevcontainer returns the devcontainer.json of the project at root, or
+// "" when it has none.
+func FindDevcontainer(root string) string {
+	for _, name := range []string{filepath.Join(".devcontainer", "devcontainer.json"), ".devcontainer.json"} {
+		p := filepath.Join(root, name)
+		if fi, err := os.Stat(p); err == nil && !fi.IsDir() {
+			return p
+		}
+	}
+	return ""
+}
+
+// LoadDevcontainer parses the devcontainer.json at path, which may contain
+// comments and trailing commas.
+func LoadDevcontainer(path string) (*Devcontainer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var dc Devcontainer
+	if err := json.Unmarshal(stripJSONC(data), &dc); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	dc.path = path
+	return &dc, nil
+}
+
+// Describe names the devcontainer for prompts: its name, else its image
+// or Dockerfile.
+func (dc *Devcontainer) Describe() string {
+	switch {
+	case dc.Name != "":
+		return dc.Name
+	case dc.Image != "":
+		return dc.Image
+	}
+	return filepath.Base(dc.dockerfile())
+}
+
+// StartDevcontainer starts the devcontainer of the project at root. With
+// the devcontainer CLI installed it runs "devcontainer up", which applies
+// features and lifecycle commands and reuses a running container, and
+// attaches to the result. Without it, an image or Dockerfile definition is
+// built and started like Start would; Compose definitions need the CLI.
+// cfg supplies the runtime, network, limits, extra mounts, and shell.
+func StartDevcontainer(ctx context.Context, cfg Config, dc *Devcontainer, root string) (*Container, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	if cli, err := exec.LookPath("devcontainer"); err == nil {
+		return devcontainerUp(ctx, cli, cfg, dc, root)
+	}
+	if len(dc.DockerComposeFile) > 0 {
+		return nil, errors.New("Docker Compose devcontainers need the devcontainer CLI (npm install -g @devcontainers/cli)")
+	}
+
+	rt, err := findRuntime(cfg.Runtime)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Runtime = rt
+	cfg.Image = dc.Image
+	if dc.Image == "" {
+		if cfg.Image, err = dc.build(ctx, rt); err != nil {
+			return nil, err
+		}
+	}
+	cfg.Workdir = dc.workspaceFolder(root)
+	if cfg.User = dc.RemoteUser; cfg.User == "" {
+		cfg.User = dc.ContainerUser
+	}
+	names := make([]string, 0, len(dc.ContainerEnv))
+	for name := range dc.ContainerEnv {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		cfg.Env = append(cfg.Env, name+"="+dc.expand(dc.ContainerEnv[name], root))
+	}
+	return Start(ctx, cfg, root)
+}
+
+// devcontainerUp runs "devcontainer up" for root and attaches to the
+// container it reports. That container outlives the session, as it would
+// for an editor, and its network is the definition's.
+func devcontainerUp(ctx context.Context, cli string, cfg Config, dc *Devcontainer, root string) (*Container, error) {
+	rt, err := findRuntime(cfg.Runtime)
+	if err != nil {
+		return nil, err
+	}
+	args := []string{"up", "--workspace-folder", root, "--docker-path", rt}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, cli, args...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	runErr := cmd.Run()
+
+	// The result is the last line of stdout, on failure too.
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	var res struct {
+		Outcome               string `json:"outcome"`
+		Message               string `json:"message"`
+		ContainerID           string `json:"containerId"`
+		RemoteUser            string `json:"remoteUser"`
+		RemoteWorkspaceFolder string `json:"remoteWorkspaceFolder"`
+	}
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &res); err != nil || res.Outcome != "success" {
+		msg := res.Message
+		if msg == "" {
+			msg = lastLine(stderr.String())
+		}
+		if runErr == nil {
+			runErr = errors.New("no result")
+		}
+		return nil, fmt.Errorf("devcontainer up: %w: %s", runErr, msg)
+	}
+
+	shell := cfg.Shell
+	if shell == "" {
+		shell = DefaultShell
+	}
+	c := &Container{
+		runtime: rt,
+		name:    res.ContainerID,
+		image:   dc.Describe(),
+		shell:   shell,
+		user:    res.RemoteUser,
+		root:    root,
+		mounts:  []Mount{{Host: root, Container: res.RemoteWorkspaceFolder}},
+		keep:    true,
+	}
+	return c, nil
+}
+
+// build builds the definition's Dockerfile and returns the image tag, which
+// is derived from the file's path so rebuilds reuse the runtime's cache.
+func (dc *Devcontainer) build(ctx context.Context, rt string) (string, error) {
+	dockerfile := dc.dockerfile()
+	if dockerfile == "" {
+		return "", fmt.Errorf("%s sets neither image nor build.dockerfile", dc.path)
+	}
+	dir := filepath.Dir(dc.path)
+	sum := sha256.Sum256([]byte(dc.path))
+	tag := "pi-go-devcontainer-" + hex.EncodeToString(sum[:6])
+
+	args := []string{"build", "--tag", tag, "--file", filepath.Join(dir, dockerfile)}
+	if dc.Build.Target != "" {
+		args = append(args, "--target", dc.Build.Target)
+	}
+	names := make([]string, 0, len(dc.Build.Args))
+	for name := range dc.Build.Args {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		args = append(args, "--build-arg", name+"="+dc.Build.Args[name])
+	}
+	buildCtx := dc.Build.Context
+	if buildCtx == "" {
+		buildCtx = "."
+	}
+	args = append(args, filepath.Join(dir, buildCtx))
+	if out, err := exec.CommandContext(ctx, rt, args...).CombinedOutput(); err != nil {
+		return "", fmt.Errorf("building %s: %w: %s", dockerfile, err, lastLine(string(out)))
+	}
+	return tag, nil
+}
+
+func (dc *Devcontainer) dockerfile() string {
+	if dc.Build.Dockerfile != "" {
+		return dc.Build.Dockerfile
+	}
+	return dc.DockerFile
+}
+
+// workspaceFolder returns where the project is mounted: workspaceFolder,
+// or /workspaces/<name> as editors do.
+func (dc *Devcontainer) workspaceFolder(root string) string {
+	if dc.WorkspaceFolder != "" {
+		return dc.expand(dc.WorkspaceFolder, root)
+	}
+	return "/workspaces/" + filepath.Base(root)
+}
+
+var devcontainerVar = regexp.MustCompile(`\$\{([A-Za-z]+)(?::([^}:]*)(?::([^}]*))?)?\}`)
+
+// expand substitutes the ${localEnv:NAME[:default]}, ${localWorkspaceFolder},
+// ${localWorkspaceFolderBasename}, and ${containerWorkspaceFolder} variables
+// of devcontainer.json in s.
+func (dc *Devcontainer) expand(s, root string) string {
+	return devcontainerVar.ReplaceAllStringFunc(s, func(v string) string {
+		m := devcontainerVar.FindStringSubmatch(v)
+		switch m[1] {
+		case "localEnv":
+			if val, ok := os.LookupEnv(m[2]); ok {
+				return val
+			}
+			return m[3]
+		case "localWorkspaceFolder":
+			return root
+		case "localWorkspaceFolderBasename":
+			return filepath.Base(root)
+		case "containerWorkspaceFolder":
+			if dc.WorkspaceFolder == "" || strings.Contains(dc.WorkspaceFolder, "containerWorkspaceFolder") {
+				return "/workspaces/" + filepath.Base(root)
+			}
+			return dc.expand(dc.WorkspaceFolder, root)
+		}
+		return v
+	})
+}
+
+// stripJSONC removes the comments and trailing commas JSON with comments
+// allows, leaving strings alone.
+func stripJSONC(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		switch c := data[i]; {
+		case c == '"':
+			j := i + 1
+			for ; j < len(data) && data[j] != '"'; j++ {
+				if data[j] == '\\' {
+					j++
+				}
+			}
+			end := min(j+1, len(data))
+			out = append(out, data[i:end]...)
+			i = end - 1
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			if i < len(data) {
+				out = append(out, '\n')
+			}
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			end := bytes.Index(data[i+2:], []byte("*/"))
+			if end < 0 {
+				return out
+			}
+			i += end + 3
+		case c == '}' || c == ']':
+			// Drop a comma with only whitespace between it and the bracket.
+			k := len(out) - 1
+			for k >= 0 && (out[k] == ' ' || out[k] == '\t' || out[k] == '\n' || out[k] == '\r') {
+				k--
+			}
+			if k >= 0 && out[k] == ',' {
+				out = append(out[:k], out[k+1:]...)
+			}
+			out = append(out, c)
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// lastLine returns the last non-empty line of s, the usual error summary of
+// a failed build or CLI run.
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,138 @@
+// ABOUTME: Tests for devcontainer support: JSONC parsing, variable expansion, and the runtime commands
+// ABOUTME: The fake runtime from container_test.go stands in for Docker; PATH hides any devcontainer CLI
+
+package container
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadDevcontainer_JSONC(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	dir := filepath.Join(root, ".devcontainer")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	src := `{
+	// The team's toolchain
+	"name": "Go // 1.24",
+	"build": {"dockerfile": "Dockerfile", "args": {"VARIANT": "1.24",},},
+	/* run as the image's user */
+	"remoteUser": "vscode",
+	"containerEnv": {"GOFLAGS": "-mod=mod", "PROJECT": "${localWorkspaceFolderBasename}"},
+}`
+	if err := os.WriteFile(filepath.Join(dir, "devcontainer.json"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	path := FindDevcontainer(root)
+	if path != filepath.Join(dir, "devcontainer.json") {
+		t.Fatalf("FindDevcontainer = %q", path)
+	}
+	dc, err := LoadDevcontainer(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dc.Describe() != "Go // 1.24" || dc.Build.Dockerfile != "Dockerfile" || dc.Build.Args["VARIANT"] != "1.24" || dc.RemoteUser != "vscode" {
+		t.Errorf("parsed %+v", dc)
+	}
+	if got := dc.workspaceFolder(root); got != "/workspaces/"+filepath.Base(root) {
+		t.Errorf("workspaceFolder = %q; want the editors' default", got)
+	}
+	if FindDevcontainer(t.TempDir()) != "" {
+		t.Error("a project without a devcontainer should have none")
+	}
+}
+
+func TestDevcontainer_Expand(t *testing.T) {
+	t.Setenv("PI_GO_TEST_VAR", "set")
+
+	dc := &Devcontainer{WorkspaceFolder: "/src/${localWorkspaceFolderBasename}"}
+	for in, want := range map[string]string{
+		"${localEnv:PI_GO_TEST_VAR}":        "set",
+		"${localEnv:PI_GO_TEST_UNSET:dflt}": "dflt",
+		"${localWorkspaceFolder}/x":         "/home/me/app/x",
+		"${containerWorkspaceFolder}/bin":   "/src/app/bin",
+		"${unknownVariable} and plain text": "${unknownVariable} and plain text",
+	} {
+		if got := dc.expand(in, "/home/me/app"); got != want {
+			t.Errorf("expand(%q) = %q; want %q", in, got, want)
+		}
+	}
+}
+
+func TestStartDevcontainer_BuildsAndRuns(t *testing.T) {
+	bin, log := fakeRuntime(t)
+	t.Setenv("PATH", t.TempDir()) // no devcontainer CLI
+
+	root := t.TempDir()
+	dc := &Devcontainer{WorkspaceFolder: "/work", RemoteUser: "vscode", ContainerEnv: map[string]string{"B": "2", "A": "1"}}
+	dc.Build.Dockerfile = "Dockerfile"
+	dc.path = filepath.Join(root, ".devcontainer", "devcontainer.json")
+
+	c, err := StartDevcontainer(context.Background(), Config{Runtime: bin}, dc, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(log)
+	calls := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(calls) != 2 || !strings.HasPrefix(calls[0], "build --tag pi-go-devcontainer-") {
+		t.Fatalf("calls = %q; want a build, then a run", calls)
+	}
+	for _, want := range []string{"--workdir /work", "--user vscode", "--env A=1 --env B=2", "--volume " + root + ":/work"} {
+		if !strings.Contains(calls[1], want) {
+			t.Errorf("run command %q lacks %q", calls[1], want)
+		}
+	}
+	if got := c.containerPath(filepath.Join(root, "pkg")); got != "/work/pkg" {
+		t.Errorf("containerPath = %q; want /work/pkg", got)
+	}
+	if !c.Writable(filepath.Join(root, "main.go")) {
+		t.Error("the project should stay writable")
+	}
+}
+
+func TestStartDevcontainer_ComposeNeedsCLI(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	dc := &Devcontainer{DockerComposeFile: []byte(`"docker-compose.yml"`)}
+	if _, err := StartDevcontainer(context.Background(), Config{}, dc, t.TempDir()); err == nil || !strings.Contains(err.Error(), "devcontainer CLI") {
+		t.Errorf("err = %v; want a pointer to the devcontainer CLI", err)
+	}
+}
+
+func TestStartDevcontainer_AttachesToCLIContainer(t *testing.T) {
+	bin, log := fakeRuntime(t)
+	dir := t.TempDir()
+	cli := "#!/bin/sh\necho '[1 ms] Start: Run: docker build'\n" +
+		`echo '{"outcome":"success","containerId":"0123456789abcdef0123","remoteUser":"node","remoteWorkspaceFolder":"/workspaces/app"}'` + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "devcontainer"), []byte(cli), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+
+	root := t.TempDir()
+	c, err := StartDevcontainer(context.Background(), Config{Runtime: bin}, &Devcontainer{Name: "Node"}, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Name() != "0123456789abcdef0123" || c.String() != "docker container 0123456789ab (Node)" {
+		t.Errorf("attached to %s (%s)", c.Name(), c)
+	}
+	cmd := c.Command(context.Background(), "npm test", root, nil)
+	if args := strings.Join(cmd.Args[1:], " "); !strings.HasPrefix(args, "exec --interactive --workdir /workspaces/app --user node ") {
+		t.Errorf("exec args = %q", args)
+	}
+	if err := c.Stop(); err != nil {
+		t.Errorf("Stop: %v", err)
+	}
+	if data, _ := os.ReadFile(log); strings.Contains(string(data), "rm") {
+		t.Errorf("Stop removed the devcontainer: %q", data)
+	}
+}
@@ -0,0 +1,262 @@
+// ABOUTME: Tests for eval fixtures, scoring, and the run/report pipeline
+// ABOUTME: Uses tempdir fixtures and a scripted Runner in place of the pi-go binary
+
+package eval
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFixture(t *testing.T, dir, name, taskYAML string, files map[string]string) {
+	t.Helper()
+	fixture := filepath.Join(dir, name)
+	if err := os.MkdirAll(fixture, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(fixture, "task.yaml"), []byte(taskYAML), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for rel, content := range files {
+		path := filepath.Join(fixture, "repo", rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoadTasks(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	writeFixture(t, dir, "zeta", "prompt: second\ntimeout: 30s\n", map[string]string{"main.go": "package main\n"})
+	writeFixture(t, dir, "alpha", "name: first\nprompt: first\nexpect:\n  tools:\n    - name: read\n", nil)
+	if err := os.MkdirAll(filepath.Join(dir, "notes"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	tasks, err := LoadTasks(dir)
+	if err != nil {
+		t.Fatalf("LoadTasks: %v", err)
+	}
+	if len(tasks) != 2 {
+		t.Fatalf("got %d tasks; want 2", len(tasks))
+	}
+	if tasks[0].Name != "first" || tasks[1].Name != "zeta" {
+		t.Errorf("names = %q, %q; want first, zeta", tasks[0].Name, tasks[1].Name)
+	}
+	if tasks[0].Timeout != DefaultTimeout {
+		t.Errorf("default timeout = %s; want %s", tasks[0].Timeout, DefaultTimeout)
+	}
+	if tasks[1].Timeout != 30*time.Second {
+		t.Errorf("timeout = %s; want 30s", tasks[1].Timeout)
+	}
+	if tasks[0].Repo != "" || tasks[1].Repo == "" {
+		t.Errorf("repo = %q, %q; want only the second set", tasks[0].Repo, tasks[1].Repo)
+	}
+}
+
+func TestLoadTask_Invalid(t *testing.T) {
+	t.Parallel()
+	tests := map[string]string{
+		"no prompt":     "expect: {}\n",
+		"bad regexp":    "prompt: x\nexpect:\n  output:\n    matches: ['(']\n",
+		"absolute path": "prompt: x\nexpect:\n  files:\n    - path: /etc/passwd\n",
+		"unnamed tool":  "prompt: x\nexpect:\n  tools:\n    - args: {path: a}\n",
+	}
+	for name, body := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			dir := t.TempDir()
+			writeFixture(t, dir, "task", body, nil)
+			if _, err := LoadTask(filepath.Join(dir, "task")); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+
+	if _, err := LoadTask(t.TempDir()); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("missing task.yaml: err = %v; want ErrNotExist", err)
+	}
+}
+
+func TestScore(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "foo.go"), []byte("func Bar() {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	gone := false
+	task := Task{Expect: Expect{
+		Tools: []ToolExpect{
+			{Name: "edit", Args: map[string]any{"path": "foo.go"}},
+			{Name: "bash", Absent: true},
+			{Name: "write"},
+		},
+		MaxToolCalls: 1,
+		Output:       OutputExpect{Contains: []string{"Bar"}, NotContains: []string{"sorry"}, Matches: []string{`renamed \w+`}},
+		Files: []FileExpect{
+			{Path: "foo.go", Contains: []string{"func Bar("}, NotContains: []string{"func Foo("}},
+			{Path: "old.go", Exists: &gone},
+		},
+	}}
+	out := Output{
+		Text: "I renamed Foo to Bar.",
+		ToolCalls: []ToolCall{
+			{Name: "read", Args: map[string]any{"path": "foo.go"}},
+			{Name: "edit", Args: map[string]any{"path": "/tmp/x/foo.go"}},
+		},
+	}
+
+	checks := Score(task, out, dir, nil)
+	failed := map[string]bool{}
+	for _, c := range checks {
+		if !c.Pass {
+			failed[c.Name] = true
+		}
+	}
+	want := map[string]bool{"write called": true, "at most 1 tool calls (made 2)": true}
+	if len(failed) != len(want) {
+		t.Errorf("failed = %v; want %v", failed, want)
+	}
+	for name := range want {
+		if !failed[name] {
+			t.Errorf("%q passed; want it to fail", name)
+		}
+	}
+	if checks[0].Kind != KindRun || !checks[0].Pass {
+		t.Errorf("first check = %+v; want a passing run check", checks[0])
+	}
+}
+
+func TestScore_RunError(t *testing.T) {
+	t.Parallel()
+	checks := Score(Task{}, Output{}, t.TempDir(), errors.New("timed out after 1s"))
+	if len(checks) != 1 || checks[0].Pass || !strings.Contains(checks[0].Name, "timed out") {
+		t.Errorf("checks = %+v; want one failed run check naming the error", checks)
+	}
+
+	checks = Score(Task{}, Output{Errors: []string{"rate limited"}}, t.TempDir(), nil)
+	if checks[0].Pass {
+		t.Error("a run that reported errors should fail the run check")
+	}
+}
+
+func TestVariants(t *testing.T) {
+	t.Parallel()
+	vs := Variants([]string{"a", "b"}, []string{"v1", "v2"})
+	if len(vs) != 4 {
+		t.Fatalf("got %d variants; want 4", len(vs))
+	}
+	if vs[1].Label() != "a/v2" {
+		t.Errorf("vs[1] = %q; want a/v2", vs[1].Label())
+	}
+	if got := Variants(nil, nil); len(got) != 1 || got[0].Label() != "default/default" {
+		t.Errorf("Variants(nil, nil) = %v; want one default variant", got)
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	writeFixture(t, dir, "rename", "prompt: rename\nexpect:\n  files:\n    - path: foo.go\n      contains: [Bar]\n",
+		map[string]string{"foo.go": "func Foo() {}\n"})
+	tasks, err := LoadTasks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var dirs []string
+	runner := func(_ context.Context, spec RunSpec) (Output, error) {
+		dirs = append(dirs, spec.Dir)
+		if _, err := os.Stat(filepath.Join(spec.Dir, "foo.go")); err != nil {
+			t.Errorf("repo not copied: %v", err)
+		}
+		if spec.Variant.PromptVersion != "" {
+			if _, err := os.Stat(filepath.Join(spec.Dir, ".pi-go", "settings.local.json")); err != nil {
+				t.Errorf("prompt version not written: %v", err)
+			}
+		}
+		if spec.Variant.Model == "good" {
+			os.WriteFile(filepath.Join(spec.Dir, "foo.go"), []byte("func Bar() {}\n"), 0o644)
+		}
+		return Output{Text: "done"}, nil
+	}
+
+	var progress bytes.Buffer
+	variants := Variants([]string{"good", "bad"}, []string{"v1"})
+	rep, err := Run(context.Background(), tasks, variants, runner, Options{Progress: &progress})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(rep.Results) != 2 {
+		t.Fatalf("got %d results; want 2", len(rep.Results))
+	}
+	if !rep.Results[0].Pass() || rep.Results[1].Pass() {
+		t.Errorf("pass = %v, %v; want true, false", rep.Results[0].Pass(), rep.Results[1].Pass())
+	}
+	if !rep.Failed() {
+		t.Error("Failed() = false; want true")
+	}
+	if strings.Count(progress.String(), "\n") != 2 {
+		t.Errorf("progress = %q; want one line per run", progress.String())
+	}
+	for _, d := range dirs {
+		if _, err := os.Stat(d); !os.IsNotExist(err) {
+			t.Errorf("run directory %s was not removed", d)
+		}
+	}
+
+	sums := rep.Summaries()
+	if sums[0].RunsPassed != 1 || sums[1].RunsPassed != 0 {
+		t.Errorf("summaries = %+v", sums)
+	}
+
+	var text bytes.Buffer
+	if err := rep.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"good/v1", "PASS 3/3", "FAIL", "Failed checks:", "foo.go contains \"Bar\""} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("report lacks %q:\n%s", want, text.String())
+		}
+	}
+
+	var js bytes.Buffer
+	if err := rep.WriteJSON(&js); err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		Results   []Result  `json:"results"`
+		Summaries []Summary `json:"summaries"`
+	}
+	if err := json.Unmarshal(js.Bytes(), &decoded); err != nil {
+		t.Fatalf("decoding JSON report: %v", err)
+	}
+	if len(decoded.Results) != 2 || len(decoded.Summaries) != 2 {
+		t.Errorf("JSON report has %d results, %d summaries; want 2, 2", len(decoded.Results), len(decoded.Summaries))
+	}
+}
+
+func TestParseOutput(t *testing.T) {
+	t.Parallel()
+	out, err := parseOutput([]byte("warming up\n{\"text\":\"hi\",\"tool_calls\":[{\"name\":\"read\",\"args\":{\"path\":\"a\"}}]}\n"))
+	if err != nil {
+		t.Fatalf("parseOutput: %v", err)
+	}
+	if out.Text != "hi" || len(out.ToolCalls) != 1 || out.ToolCalls[0].Args["path"] != "a" {
+		t.Errorf("out = %+v", out)
+	}
+	if _, err := parseOutput([]byte("plain text\n")); err == nil {
+		t.Error("expected an error without a JSON line")
+	}
+}
@@ -0,0 +1,135 @@
+// ABOUTME: Task fixtures for `pi-go eval`: a prompt, the assertions its run must meet, and an optional repo
+// ABOUTME: Each fixture is a directory holding task.yaml and, optionally, a repo/ tree the run starts from
+
+package eval
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultTimeout bounds one run of a task without its own timeout.
+const DefaultTimeout = 5 * time.Minute
+
+// Task is one fixture: a prompt and what its run must do.
+type Task struct {
+	Name    string        `yaml:"name"` // defaults to the fixture directory name
+	Prompt  string        `yaml:"prompt"`
+	Timeout time.Duration `yaml:"timeout"` // zero uses DefaultTimeout
+	Expect  Expect        `yaml:"expect"`
+
+	// Repo is the repo/ directory copied into each run's working
+	// directory; empty when the fixture has none.
+	Repo string `yaml:"-"`
+}
+
+// Expect lists the assertions scored for a run.
+type Expect struct {
+	Tools        []ToolExpect `yaml:"tools"`
+	MaxToolCalls int          `yaml:"max_tool_calls"` // 0 = no limit
+	Output       OutputExpect `yaml:"output"`
+	Files        []FileExpect `yaml:"files"`
+}
+
+// ToolExpect asserts a tool call. Args is a subset of the call's
+// arguments: a string matches an argument containing it, other values
+// match when equal. With Absent, no call may match.
+type ToolExpect struct {
+	Name   string         `yaml:"name"`
+	Args   map[string]any `yaml:"args"`
+	Absent bool           `yaml:"absent"`
+}
+
+// OutputExpect asserts the final reply text.
+type OutputExpect struct {
+	Contains    []string `yaml:"contains"`
+	NotContains []string `yaml:"not_contains"`
+	Matches     []string `yaml:"matches"` // regular expressions
+}
+
+// FileExpect asserts a file in the working directory after the run.
+// Exists defaults to true; with Exists false the file must be gone.
+type FileExpect struct {
+	Path        string   `yaml:"path"`
+	Exists      *bool    `yaml:"exists"`
+	Contains    []string `yaml:"contains"`
+	NotContains []string `yaml:"not_contains"`
+}
+
+// LoadTasks reads every fixture under dir: each subdirectory with a
+// task.yaml, sorted by name.
+func LoadTasks(dir string) ([]Task, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading fixtures: %w", err)
+	}
+	var tasks []Task
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		t, err := LoadTask(filepath.Join(dir, e.Name()))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, t)
+	}
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("no fixtures in %s (want <name>/task.yaml)", dir)
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].Name < tasks[j].Name })
+	return tasks, nil
+}
+
+// LoadTask reads the fixture in dir. The error wraps os.ErrNotExist when
+// dir has no task.yaml.
+func LoadTask(dir string) (Task, error) {
+	path := filepath.Join(dir, "task.yaml")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Task{}, fmt.Errorf("reading %s: %w", path, err)
+	}
+	var t Task
+	if err := yaml.Unmarshal(data, &t); err != nil {
+		return Task{}, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if t.Prompt == "" {
+		return Task{}, fmt.Errorf("%s: prompt is required", path)
+	}
+	if t.Name == "" {
+		t.Name = filepath.Base(dir)
+	}
+	if t.Timeout <= 0 {
+		t.Timeout = DefaultTimeout
+	}
+	for _, te := range t.Expect.Tools {
+		if te.Name == "" {
+			return Task{}, fmt.Errorf("%s: a tools entry has no name", path)
+		}
+	}
+	for _, re := range t.Expect.Output.Matches {
+		if _, err := regexp.Compile(re); err != nil {
+			return Task{}, fmt.Errorf("%s: output pattern: %w", path, err)
+		}
+	}
+	for _, fe := range t.Expect.Files {
+		if fe.Path == "" || filepath.IsAbs(fe.Path) {
+			return Task{}, fmt.Errorf("%s: file path %q must be relative", path, fe.Path)
+		}
+	}
+	repo := filepath.Join(dir, "repo")
+	if info, err := os.Stat(repo); err == nil && info.IsDir() {
+		t.Repo = repo
+	}
+	return t, nil
+}
@@ -0,0 +1,162 @@
+// ABOUTME: Eval comparison report: a task × variant matrix, per-variant scores, and the failed checks
+// ABOUTME: Written as aligned text for people or as JSON for tooling that tracks scores over time
+
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+// Summary totals one variant's results. Output counts output and file
+// checks; the "completed" check counts in Checks only.
+type Summary struct {
+	Variant      string        `json:"variant"`
+	Runs         int           `json:"runs"`
+	RunsPassed   int           `json:"runs_passed"`
+	Checks       int           `json:"checks"`
+	ChecksPassed int           `json:"checks_passed"`
+	ToolChecks   int           `json:"tool_checks"`
+	ToolPassed   int           `json:"tool_passed"`
+	OutputChecks int           `json:"output_checks"`
+	OutputPassed int           `json:"output_passed"`
+	ToolCalls    int           `json:"tool_calls"`
+	Duration     time.Duration `json:"duration_ns"`
+}
+
+// Summaries totals the results of each variant, in variant order.
+func (r *Report) Summaries() []Summary {
+	byLabel := make(map[string]*Summary, len(r.Variants))
+	out := make([]Summary, len(r.Variants))
+	for i, v := range r.Variants {
+		out[i].Variant = v.Label()
+		byLabel[v.Label()] = &out[i]
+	}
+	for _, res := range r.Results {
+		s := byLabel[res.Variant]
+		if s == nil {
+			continue
+		}
+		s.Runs++
+		if res.Pass() {
+			s.RunsPassed++
+		}
+		s.ToolCalls += res.ToolCalls
+		s.Duration += res.Duration
+		for _, c := range res.Checks {
+			s.Checks++
+			if c.Pass {
+				s.ChecksPassed++
+			}
+			switch c.Kind {
+			case KindTool:
+				s.ToolChecks++
+				if c.Pass {
+					s.ToolPassed++
+				}
+			case KindOutput, KindFile:
+				s.OutputChecks++
+				if c.Pass {
+					s.OutputPassed++
+				}
+			}
+		}
+	}
+	return out
+}
+
+// Failed reports whether any run failed a check.
+func (r *Report) Failed() bool {
+	for _, res := range r.Results {
+		if !res.Pass() {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteJSON writes the report and its summaries as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	data, err := json.MarshalIndent(struct {
+		*Report
+		Summaries []Summary `json:"summaries"`
+	}{r, r.Summaries()}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding report: %w", err)
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+// WriteText writes the task × variant matrix, the per-variant summary, and
+// the checks each failing run missed.
+func (r *Report) WriteText(w io.Writer) error {
+	cells := make(map[[2]string]Result, len(r.Results))
+	for _, res := range r.Results {
+		cells[[2]string{res.Task, res.Variant}] = res
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprint(tw, "Task")
+	for _, v := range r.Variants {
+		fmt.Fprintf(tw, "\t%s", v.Label())
+	}
+	fmt.Fprintln(tw)
+	for _, task := range r.Tasks {
+		fmt.Fprint(tw, task)
+		for _, v := range r.Variants {
+			res, ok := cells[[2]string{task, v.Label()}]
+			switch {
+			case !ok:
+				fmt.Fprint(tw, "\t-")
+			case res.Pass():
+				fmt.Fprintf(tw, "\tPASS %d/%d", res.Passed(), len(res.Checks))
+			default:
+				fmt.Fprintf(tw, "\tFAIL %d/%d", res.Passed(), len(res.Checks))
+			}
+		}
+		fmt.Fprintln(tw)
+	}
+	fmt.Fprintln(tw)
+
+	fmt.Fprintln(tw, "Variant\tTasks\tChecks\tTool calls correct\tOutput\tCalls\tTime")
+	for _, s := range r.Summaries() {
+		fmt.Fprintf(tw, "%s\t%d/%d\t%s\t%s\t%s\t%d\t%s\n", s.Variant, s.RunsPassed, s.Runs,
+			ratio(s.ChecksPassed, s.Checks), ratio(s.ToolPassed, s.ToolChecks), ratio(s.OutputPassed, s.OutputChecks),
+			s.ToolCalls, s.Duration.Round(100*time.Millisecond))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if !r.Failed() {
+		return nil
+	}
+	fmt.Fprintln(w, "\nFailed checks:")
+	for _, res := range r.Results {
+		if res.Pass() {
+			continue
+		}
+		fmt.Fprintf(w, "  %s × %s\n", res.Task, res.Variant)
+		for _, c := range res.Checks {
+			if !c.Pass {
+				fmt.Fprintf(w, "    - [%s] %s\n", c.Kind, c.Name)
+			}
+		}
+		if res.Dir != "" {
+			fmt.Fprintf(w, "    dir: %s\n", res.Dir)
+		}
+	}
+	return nil
+}
+
+// ratio formats passed/total with a percentage, or "-" when total is 0.
+func ratio(passed, total int) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d/%d %d%%", passed, total, passed*100/total)
+}
@@ -0,0 +1,281 @@
+// ABOUTME: Runs every eval task against every variant (model × prompt version) in a fresh copy of its repo
+// ABOUTME: ExecRunner drives the pi-go binary in print mode with JSON output; tests inject their own Runner
+
+package eval
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/config"
+)
+
+// Variant is one configuration the tasks run under. Empty fields keep
+// pi-go's default model or prompt.
+type Variant struct {
+	Model         string `json:"model,omitempty"`
+	PromptVersion string `json:"prompt_version,omitempty"`
+}
+
+// Label names the variant in reports, as model/version.
+func (v Variant) Label() string {
+	model, version := v.Model, v.PromptVersion
+	if model == "" {
+		model = "default"
+	}
+	if version == "" {
+		version = "default"
+	}
+	return model + "/" + version
+}
+
+// Variants returns every combination of models and prompt versions; an
+// empty list stands for the default.
+func Variants(models, versions []string) []Variant {
+	if len(models) == 0 {
+		models = []string{""}
+	}
+	if len(versions) == 0 {
+		versions = []string{""}
+	}
+	var out []Variant
+	for _, m := range models {
+		for _, v := range versions {
+			out = append(out, Variant{Model: m, PromptVersion: v})
+		}
+	}
+	return out
+}
+
+// RunSpec is one run: a task under a variant, in a prepared directory.
+type RunSpec struct {
+	Task    Task
+	Variant Variant
+	Dir     string
+}
+
+// Runner executes one run and returns its output. An error means the run
+// did not complete; the output may still hold what it produced.
+type Runner func(ctx context.Context, spec RunSpec) (Output, error)
+
+// Result is the scored outcome of one run.
+type Result struct {
+	Task      string        `json:"task"`
+	Variant   string        `json:"variant"`
+	Checks    []Check       `json:"checks"`
+	ToolCalls int           `json:"tool_calls"`
+	Duration  time.Duration `json:"duration_ns"`
+	Dir       string        `json:"dir,omitempty"` // kept working directory
+}
+
+// Passed returns how many checks passed.
+func (r Result) Passed() int {
+	n := 0
+	for _, c := range r.Checks {
+		if c.Pass {
+			n++
+		}
+	}
+	return n
+}
+
+// Pass reports whether every check passed.
+func (r Result) Pass() bool { return r.Passed() == len(r.Checks) }
+
+// Options configures Run.
+type Options struct {
+	// Progress receives one line per finished run. Nilable.
+	Progress io.Writer
+	// KeepDirs leaves each run's working directory in place and records
+	// it in the result.
+	KeepDirs bool
+}
+
+// Report holds the results of an eval, by task and then variant.
+type Report struct {
+	Tasks    []string  `json:"tasks"`
+	Variants []Variant `json:"variants"`
+	Results  []Result  `json:"results"`
+}
+
+// Run runs each task under each variant with runner and scores the runs.
+// It stops early only when ctx is done.
+func Run(ctx context.Context, tasks []Task, variants []Variant, runner Runner, opts Options) (*Report, error) {
+	rep := &Report{Variants: variants}
+	total := len(tasks) * len(variants)
+	for _, t := range tasks {
+		rep.Tasks = append(rep.Tasks, t.Name)
+		for _, v := range variants {
+			if err := ctx.Err(); err != nil {
+				return rep, err
+			}
+			res, err := runOne(ctx, t, v, runner, opts.KeepDirs)
+			if err != nil {
+				return rep, err
+			}
+			rep.Results = append(rep.Results, res)
+			if opts.Progress != nil {
+				status := "PASS"
+				if !res.Pass() {
+					status = "FAIL"
+				}
+				fmt.Fprintf(opts.Progress, "[%d/%d] %s × %s: %s %d/%d (%s)\n", len(rep.Results), total,
+					t.Name, v.Label(), status, res.Passed(), len(res.Checks), res.Duration.Round(100*time.Millisecond))
+			}
+		}
+	}
+	return rep, nil
+}
+
+// runOne prepares a working directory, runs t under v, and scores it. The
+// error is for a directory that could not be prepared; run failures are
+// scored.
+func runOne(ctx context.Context, t Task, v Variant, runner Runner, keep bool) (Result, error) {
+	dir, err := prepareDir(t, v)
+	if err != nil {
+		return Result{}, err
+	}
+	if !keep {
+		defer os.RemoveAll(dir)
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, t.Timeout)
+	defer cancel()
+	start := time.Now()
+	out, runErr := runner(runCtx, RunSpec{Task: t, Variant: v, Dir: dir})
+	res := Result{
+		Task:      t.Name,
+		Variant:   v.Label(),
+		Checks:    Score(t, out, dir, runErr),
+		ToolCalls: len(out.ToolCalls),
+		Duration:  time.Since(start),
+	}
+	if keep {
+		res.Dir = dir
+	}
+	return res, nil
+}
+
+// prepareDir creates the working directory of a run: a copy of the task's
+// repo, with the variant's prompt version in .pi-go/settings.local.json.
+func prepareDir(t Task, v Variant) (string, error) {
+	dir, err := os.MkdirTemp("", "pi-go-eval-*")
+	if err != nil {
+		return "", fmt.Errorf("creating eval directory: %w", err)
+	}
+	if t.Repo != "" {
+		if err := copyTree(t.Repo, dir); err != nil {
+			os.RemoveAll(dir)
+			return "", fmt.Errorf("copying %s: %w", t.Repo, err)
+		}
+	}
+	if v.PromptVersion != "" {
+		prompts := map[string]string{"activeVersion": v.PromptVersion}
+		if err := config.SaveLocalSetting(dir, "prompts", prompts); err != nil {
+			os.RemoveAll(dir)
+			return "", err
+		}
+	}
+	return dir, nil
+}
+
+// copyTree copies the files, directories, and symlinks under src into dst.
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm()|0o700)
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case d.Type().IsRegular():
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			return os.WriteFile(target, data, info.Mode().Perm())
+		}
+		return nil
+	})
+}
+
+// ExecRunner returns a Runner that runs the pi-go binary at bin in print
+// mode with JSON output, permissions skipped and no worktree, in the run's
+// directory.
+func ExecRunner(bin string) Runner {
+	return func(ctx context.Context, spec RunSpec) (Output, error) {
+		args := []string{"-p", spec.Task.Prompt, "--output-format", "json", "--yolo", "--no-worktree"}
+		if spec.Variant.Model != "" {
+			args = append(args, "--model", spec.Variant.Model)
+		}
+		cmd := exec.CommandContext(ctx, bin, args...)
+		cmd.Dir = spec.Dir
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout, cmd.Stderr = &stdout, &stderr
+		runErr := cmd.Run()
+
+		out, parseErr := parseOutput(stdout.Bytes())
+		if runErr != nil {
+			if ctx.Err() != nil {
+				runErr = fmt.Errorf("timed out after %s", spec.Task.Timeout)
+			} else if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				runErr = fmt.Errorf("%w: %s", runErr, lastLine(msg))
+			}
+			return out, runErr
+		}
+		if parseErr != nil {
+			return out, parseErr
+		}
+		return out, nil
+	}
+}
+
+// parseOutput decodes the last JSON object line of print mode's output.
+func parseOutput(stdout []byte) (Output, error) {
+	lines := strings.Split(strings.TrimSpace(string(stdout)), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+		var out Output
+		if err := json.Unmarshal([]byte(line), &out); err != nil {
+			return Output{}, fmt.Errorf("decoding print output: %w", err)
+		}
+		return out, nil
+	}
+	return Output{}, errors.New("no JSON output from print mode")
+}
+
+// lastLine returns the last line of s.
+func lastLine(s string) string {
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		return s[i+1:]
+	}
+	return s
+}
@@ -0,0 +1,150 @@
+// ABOUTME: Scores one eval run: tool-call checks against the calls made, output and file checks against the result
+// ABOUTME: Every assertion becomes a named Check; the run's score is the share that passed
+
+package eval
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Output is what a run produced, as print mode's JSON output reports it.
+type Output struct {
+	Text      string     `json:"text"`
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+	Errors    []string   `json:"errors,omitempty"`
+}
+
+// ToolCall is one tool call of a run.
+type ToolCall struct {
+	Name   string         `json:"name"`
+	Args   map[string]any `json:"args,omitempty"`
+	Result string         `json:"result,omitempty"`
+	Error  bool           `json:"error,omitempty"`
+}
+
+// Check kinds: tool-call correctness, or the outcome of the run.
+const (
+	KindTool   = "tool"
+	KindOutput = "output"
+	KindFile   = "file"
+	KindRun    = "run"
+)
+
+// Check is one scored assertion.
+type Check struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	Pass bool   `json:"pass"`
+}
+
+// Score checks out, the output of a run of t whose working directory was
+// dir, against t's assertions. runErr is the error the run ended with;
+// a run that failed or reported errors fails the "completed" check.
+func Score(t Task, out Output, dir string, runErr error) []Check {
+	var checks []Check
+	add := func(kind string, pass bool, format string, args ...any) {
+		checks = append(checks, Check{Kind: kind, Name: fmt.Sprintf(format, args...), Pass: pass})
+	}
+
+	switch {
+	case runErr != nil:
+		add(KindRun, false, "completed (%v)", runErr)
+	case len(out.Errors) > 0:
+		add(KindRun, false, "completed (%s)", out.Errors[0])
+	default:
+		add(KindRun, true, "completed")
+	}
+
+	for _, te := range t.Expect.Tools {
+		n := countCalls(out.ToolCalls, te)
+		desc := te.Name + formatArgs(te.Args)
+		if te.Absent {
+			add(KindTool, n == 0, "%s not called", desc)
+		} else {
+			add(KindTool, n > 0, "%s called", desc)
+		}
+	}
+	if limit := t.Expect.MaxToolCalls; limit > 0 {
+		add(KindTool, len(out.ToolCalls) <= limit, "at most %d tool calls (made %d)", limit, len(out.ToolCalls))
+	}
+
+	for _, s := range t.Expect.Output.Contains {
+		add(KindOutput, strings.Contains(out.Text, s), "output contains %q", s)
+	}
+	for _, s := range t.Expect.Output.NotContains {
+		add(KindOutput, !strings.Contains(out.Text, s), "output lacks %q", s)
+	}
+	for _, pattern := range t.Expect.Output.Matches {
+		re, err := regexp.Compile(pattern)
+		add(KindOutput, err == nil && re.MatchString(out.Text), "output matches /%s/", pattern)
+	}
+
+	for _, fe := range t.Expect.Files {
+		data, err := os.ReadFile(filepath.Join(dir, fe.Path))
+		exists := err == nil
+		if fe.Exists != nil && !*fe.Exists {
+			add(KindFile, !exists, "%s removed", fe.Path)
+			continue
+		}
+		add(KindFile, exists, "%s exists", fe.Path)
+		for _, s := range fe.Contains {
+			add(KindFile, exists && strings.Contains(string(data), s), "%s contains %q", fe.Path, s)
+		}
+		for _, s := range fe.NotContains {
+			add(KindFile, exists && !strings.Contains(string(data), s), "%s lacks %q", fe.Path, s)
+		}
+	}
+	return checks
+}
+
+// countCalls returns how many calls match te's name and arguments.
+func countCalls(calls []ToolCall, te ToolExpect) int {
+	n := 0
+	for _, c := range calls {
+		if c.Name == te.Name && argsMatch(c.Args, te.Args) {
+			n++
+		}
+	}
+	return n
+}
+
+// argsMatch reports whether every wanted argument matches the call's: a
+// string is contained in it, any other value prints the same.
+func argsMatch(got, want map[string]any) bool {
+	for k, w := range want {
+		g, ok := got[k]
+		if !ok {
+			return false
+		}
+		if ws, ok := w.(string); ok {
+			if !strings.Contains(fmt.Sprint(g), ws) {
+				return false
+			}
+		} else if fmt.Sprint(g) != fmt.Sprint(w) {
+			return false
+		}
+	}
+	return true
+}
+
+// formatArgs renders wanted arguments as "(k=v, ...)", sorted by key.
+func formatArgs(args map[string]any) string {
+	if len(args) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(args))
+	for k := range args {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%s=%v", k, args[k])
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
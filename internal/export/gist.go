@@ -41,6 +41,11 @@ func CreateGist(content string, description string, public bool) (string, error)
 // createGistWithURL is the internal implementation that accepts a configurable API URL,
 // enabling testability via httptest.NewServer.
 func createGistWithURL(apiURL string, content string, description string, public bool) (string, error) {
+	return createGistFileWithURL(apiURL, "session.md", content, description, public)
+}
+
+// createGistFileWithURL posts a single-file gist named filename.
+func createGistFileWithURL(apiURL, filename, content, description string, public bool) (string, error) {
 	token := os.Getenv("GITHUB_TOKEN")
 	if token == "" {
 		return "", fmt.Errorf("GITHUB_TOKEN environment variable is not set")
@@ -50,7 +55,7 @@ func createGistWithURL(apiURL string, content string, description string, public
 		Description: description,
 		Public:      public,
 		Files: map[string]gistFile{
-			"session.md": {Content: content},
+			filename: {Content: content},
 		},
 	}
 
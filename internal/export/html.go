@@ -4,9 +4,11 @@
 package export
 
 import (
+	"fmt"
 	"html/template"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
 )
@@ -14,9 +16,36 @@ import (
 // ExportHTML renders a slice of messages as a styled HTML document to w.
 // The output uses a dark theme with role-specific color indicators:
 // User (blue), Assistant (green), Tool (gray).
-// Tool results are rendered inside collapsible <details> elements.
+// Tool results and thinking are rendered inside collapsible <details>
+// elements; callers apply thinking retention before exporting.
 func ExportHTML(messages []ai.Message, w io.Writer) error {
-	return htmlTmpl.Execute(w, messages)
+	return ExportHTMLWithOptions(messages, w, HTMLOptions{})
+}
+
+// HTMLOptions carries optional metadata for HTML exports.
+type HTMLOptions = Options
+
+// htmlData is the template payload.
+type htmlData struct {
+	Messages        []ai.Message
+	ExportedUTC     string
+	ExportedDisplay string
+	Usage           string
+}
+
+// ExportHTMLWithOptions renders messages like ExportHTML and adds an export
+// header and a usage footer. The machine-readable datetime attribute is always UTC; only the
+// visible text uses opts.FormatTime.
+func ExportHTMLWithOptions(messages []ai.Message, w io.Writer, opts HTMLOptions) error {
+	data := htmlData{Messages: visibleMessages(messages, opts)}
+	if !opts.ExportedAt.IsZero() {
+		data.ExportedUTC = opts.ExportedAt.UTC().Format(time.RFC3339)
+		data.ExportedDisplay = displayTime(opts.ExportedAt, opts.FormatTime)
+	}
+	if opts.Usage != nil {
+		data.Usage = usageLine(*opts.Usage)
+	}
+	return htmlTmpl.Execute(w, data)
 }
 
 // roleClass maps a message role to a CSS class name.
@@ -54,19 +83,52 @@ func isText(ct ai.ContentType) bool {
 	return ct == ai.ContentText
 }
 
+// isThinking checks if content is a thinking type.
+func isThinking(ct ai.ContentType) bool {
+	return ct == ai.ContentThinking
+}
+
 // escapeNewlines converts newlines to <br> for HTML rendering.
 func escapeNewlines(s string) template.HTML {
 	escaped := template.HTMLEscapeString(s)
 	return template.HTML(strings.ReplaceAll(escaped, "\n", "<br>\n"))
 }
 
+// resultHTML renders a tool result, coloring a trailing diff line by line.
+func resultHTML(s string) template.HTML {
+	text, d := splitDiff(s)
+	out := string(escapeNewlines(text))
+	if d == "" {
+		return template.HTML(out)
+	}
+	var b strings.Builder
+	b.WriteString(out)
+	b.WriteString(`<div class="diff">`)
+	for _, line := range strings.Split(strings.TrimRight(d, "\n"), "\n") {
+		class := "diff-line"
+		switch {
+		case strings.HasPrefix(line, "+++ "), strings.HasPrefix(line, "--- "), strings.HasPrefix(line, "@@"):
+			class = "diff-hunk"
+		case strings.HasPrefix(line, "+"):
+			class = "diff-add"
+		case strings.HasPrefix(line, "-"):
+			class = "diff-del"
+		}
+		fmt.Fprintf(&b, `<span class="%s">%s</span>`, class, template.HTMLEscapeString(line))
+	}
+	b.WriteString(`</div>`)
+	return template.HTML(b.String())
+}
+
 var funcMap = template.FuncMap{
 	"roleClass":   roleClass,
 	"inputString": inputString,
 	"isToolResult":   isToolResult,
 	"isToolUse":      isToolUse,
 	"isText":         isText,
+	"isThinking":     isThinking,
 	"escapeNewlines": escapeNewlines,
+	"resultHTML":     resultHTML,
 }
 
 var htmlTmpl = template.Must(template.New("session").Funcs(funcMap).Parse(htmlTemplate))
@@ -157,17 +219,41 @@ const htmlTemplate = `<!DOCTYPE html>
     white-space: pre-wrap;
     word-break: break-all;
   }
+  .thinking summary { font-style: italic; }
+  .thinking .result-content { font-style: italic; }
   .error-result summary { color: #f38ba8; }
   .error-result .result-content { color: #f38ba8; }
+  .export-meta {
+    color: #9399b2;
+    font-size: 12px;
+    margin-bottom: 16px;
+  }
+  .usage {
+    margin-top: 16px;
+    margin-bottom: 0;
+  }
+  .diff { margin-top: 4px; }
+  .diff span { display: block; }
+  .diff-add { color: #a6e3a1; background: #a6e3a11a; }
+  .diff-del { color: #f38ba8; background: #f38ba81a; }
+  .diff-hunk { color: #89b4fa; }
 </style>
 </head>
 <body>
-{{- range . }}
+{{- if .ExportedUTC }}
+<div class="export-meta">Exported <time datetime="{{ .ExportedUTC }}">{{ .ExportedDisplay }}</time></div>
+{{- end }}
+{{- range .Messages }}
 <div class="message {{ roleClass .Role }}">
   <span class="role-badge">{{ .Role }}</span>
   {{- range .Content }}
     {{- if isText .Type }}
   <div class="content-block">{{ escapeNewlines .Text }}</div>
+    {{- else if isThinking .Type }}
+  <details class="thinking">
+    <summary>Thinking</summary>
+    <div class="result-content">{{ escapeNewlines .Thinking }}</div>
+  </details>
     {{- else if isToolUse .Type }}
   <div class="tool-use">
     <span class="tool-name">{{ .Name }}</span>
@@ -176,12 +262,15 @@ const htmlTemplate = `<!DOCTYPE html>
     {{- else if isToolResult .Type }}
   <details{{ if .IsError }} class="error-result"{{ end }}>
     <summary>Tool Result ({{ .ID }}){{ if .IsError }} — error{{ end }}</summary>
-    <div class="result-content">{{ escapeNewlines .ResultText }}</div>
+    <div class="result-content">{{ resultHTML .ResultText }}</div>
   </details>
     {{- end }}
   {{- end }}
 </div>
 {{- end }}
+{{- if .Usage }}
+<div class="export-meta usage">Usage: {{ .Usage }}</div>
+{{- end }}
 </body>
 </html>
 `
@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
 )
@@ -55,6 +56,29 @@ func TestExportHTML_AssistantMessage(t *testing.T) {
 	}
 }
 
+func TestExportHTML_Thinking(t *testing.T) {
+	msgs := []ai.Message{{
+		Role: ai.RoleAssistant,
+		Content: []ai.Content{
+			{Type: ai.ContentThinking, Thinking: "Check the <config> first."},
+			{Type: ai.ContentText, Text: "Done."},
+		},
+	}}
+
+	var buf bytes.Buffer
+	if err := ExportHTML(msgs, &buf); err != nil {
+		t.Fatalf("ExportHTML: %v", err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, `<details class="thinking">`) {
+		t.Error("expected a collapsible thinking block")
+	}
+	if !strings.Contains(out, "Check the &lt;config&gt; first.") {
+		t.Error("expected escaped thinking text")
+	}
+}
+
 func TestExportHTML_ToolUse(t *testing.T) {
 	msgs := []ai.Message{
 		{
@@ -197,3 +221,55 @@ func TestExportHTML_DarkThemeColors(t *testing.T) {
 		t.Error("expected dark theme text color")
 	}
 }
+
+func TestExportHTMLWithOptions_ExportHeader(t *testing.T) {
+	msgs := []ai.Message{ai.NewTextMessage(ai.RoleUser, "hi")}
+	at := time.Date(2026, 2, 3, 4, 5, 6, 0, time.FixedZone("X", 3600))
+
+	var buf bytes.Buffer
+	opts := HTMLOptions{
+		ExportedAt: at,
+		FormatTime: func(time.Time) string { return "local display" },
+	}
+	if err := ExportHTMLWithOptions(msgs, &buf, opts); err != nil {
+		t.Fatalf("ExportHTMLWithOptions: %v", err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, `datetime="2026-02-03T03:05:06Z"`) {
+		t.Error("expected canonical UTC datetime attribute")
+	}
+	if !strings.Contains(out, "local display") {
+		t.Error("expected formatted display time")
+	}
+}
+
+func TestExportHTML_NoExportHeader(t *testing.T) {
+	var buf bytes.Buffer
+	if err := ExportHTML(nil, &buf); err != nil {
+		t.Fatalf("ExportHTML: %v", err)
+	}
+	if strings.Contains(buf.String(), "<time") {
+		t.Error("plain ExportHTML should not render an export header")
+	}
+}
+
+func TestExportHTML_DiffResult(t *testing.T) {
+	msgs := []ai.Message{{Role: ai.RoleUser, Content: []ai.Content{
+		{Type: ai.ContentToolResult, ID: "t1", ResultText: "Match strategy: exact\n--- a.go\n+++ a.go\n-<old>\n+new\n"},
+	}}}
+	var buf bytes.Buffer
+	if err := ExportHTML(msgs, &buf); err != nil {
+		t.Fatalf("ExportHTML: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		`<span class="diff-hunk">--- a.go</span>`,
+		`<span class="diff-del">-&lt;old&gt;</span>`,
+		`<span class="diff-add">+new</span>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q", want)
+		}
+	}
+}
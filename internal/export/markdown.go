@@ -0,0 +1,159 @@
+// ABOUTME: Markdown exporter for chat sessions: a clean transcript with tool calls and diffs
+// ABOUTME: Tool inputs render as JSON blocks, edit diffs as diff blocks, usage as a closing line
+
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/session"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+// Options carries optional metadata for exports.
+type Options struct {
+	ExportedAt time.Time              // zero omits the export line
+	FormatTime func(time.Time) string // display formatter; nil renders UTC RFC3339
+	Usage      *Usage                 // nil omits the usage summary
+
+	// ShowReminders keeps ephemeral system reminders, which are hidden by default.
+	ShowReminders bool
+}
+
+// ExportMarkdown renders messages as a Markdown transcript to w. Thinking
+// is quoted; callers apply thinking retention before exporting.
+func ExportMarkdown(messages []ai.Message, w io.Writer, opts Options) error {
+	_, err := io.WriteString(w, FormatMarkdown(messages, opts))
+	return err
+}
+
+// FormatMarkdown returns the Markdown transcript of messages.
+func FormatMarkdown(messages []ai.Message, opts Options) string {
+	var b strings.Builder
+	if !opts.ExportedAt.IsZero() {
+		fmt.Fprintf(&b, "_Exported %s_\n\n", displayTime(opts.ExportedAt, opts.FormatTime))
+	}
+	for _, msg := range visibleMessages(messages, opts) {
+		fmt.Fprintf(&b, "## %s\n\n", markdownRole(msg))
+		for _, ct := range msg.Content {
+			switch ct.Type {
+			case ai.ContentText:
+				b.WriteString(ct.Text)
+				b.WriteString("\n\n")
+			case ai.ContentThinking:
+				b.WriteString("> *Thinking:* ")
+				b.WriteString(strings.ReplaceAll(strings.TrimSpace(ct.Thinking), "\n", "\n> "))
+				b.WriteString("\n\n")
+			case ai.ContentToolUse:
+				fmt.Fprintf(&b, "**Tool call:** `%s`\n\n", ct.Name)
+				if input := prettyInput(ct.Input); input != "" {
+					writeFenced(&b, "json", input)
+				}
+			case ai.ContentToolResult:
+				label := "Tool result"
+				if ct.IsError {
+					label = "Tool error"
+				}
+				fmt.Fprintf(&b, "**%s** (`%s`)\n\n", label, ct.ID)
+				text, d := splitDiff(ct.ResultText)
+				if text = strings.TrimSpace(text); text != "" {
+					writeFenced(&b, "text", text)
+				}
+				if d != "" {
+					writeFenced(&b, "diff", d)
+				}
+			}
+		}
+	}
+	if u := opts.Usage; u != nil {
+		fmt.Fprintf(&b, "---\n\n**Usage:** %s\n", usageLine(*u))
+	}
+	return b.String()
+}
+
+// visibleMessages drops system reminders unless opts.ShowReminders is set.
+func visibleMessages(messages []ai.Message, opts Options) []ai.Message {
+	if opts.ShowReminders {
+		return messages
+	}
+	visible, _ := session.DropEphemeral(messages)
+	return visible
+}
+
+// markdownRole labels a message; user messages that only carry tool
+// results are shown as "tool".
+func markdownRole(msg ai.Message) string {
+	if msg.Role != ai.RoleUser || len(msg.Content) == 0 {
+		return string(msg.Role)
+	}
+	for _, c := range msg.Content {
+		if c.Type != ai.ContentToolResult {
+			return string(msg.Role)
+		}
+	}
+	return "tool"
+}
+
+// prettyInput indents JSON tool input, falling back to the raw bytes.
+func prettyInput(input json.RawMessage) string {
+	if len(input) == 0 {
+		return ""
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, input, "", "  "); err != nil {
+		return string(input)
+	}
+	return out.String()
+}
+
+// writeFenced writes body in a fenced code block, lengthening the fence
+// past any backtick run inside body.
+func writeFenced(b *strings.Builder, lang, body string) {
+	fence := "```"
+	for strings.Contains(body, fence) {
+		fence += "`"
+	}
+	fmt.Fprintf(b, "%s%s\n%s\n%s\n\n", fence, lang, strings.TrimRight(body, "\n"), fence)
+}
+
+// splitDiff separates a tool result into its text and a trailing diff that
+// starts at a "--- "/"+++ " header pair, as the edit tool reports changes.
+func splitDiff(s string) (text, diff string) {
+	for off := 0; off < len(s); {
+		if strings.HasPrefix(s[off:], "--- ") {
+			if nl := strings.IndexByte(s[off:], '\n'); nl >= 0 && strings.HasPrefix(s[off+nl+1:], "+++ ") {
+				return s[:off], s[off:]
+			}
+		}
+		nl := strings.IndexByte(s[off:], '\n')
+		if nl < 0 {
+			break
+		}
+		off += nl + 1
+	}
+	return s, ""
+}
+
+// usageLine summarizes usage as "model · N input / M output tokens · $cost".
+func usageLine(u Usage) string {
+	var parts []string
+	if u.Model != "" {
+		parts = append(parts, u.Model)
+	}
+	parts = append(parts, fmt.Sprintf("%d input / %d output tokens", u.InputTokens, u.OutputTokens))
+	parts = append(parts, fmt.Sprintf("$%.4f", u.CostUSD))
+	return strings.Join(parts, " · ")
+}
+
+// displayTime formats t with format, or as UTC RFC3339 when format is nil.
+func displayTime(t time.Time, format func(time.Time) string) string {
+	if format != nil {
+		return format(t)
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,157 @@
+// ABOUTME: Tests for the Markdown exporter and transcript rebuilding from session records
+// ABOUTME: Covers tool calls, diffs, usage lines, fences, and export format selection
+
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/session"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+const editResult = "Match strategy: exact\n--- main.go\n+++ main.go\n-old line\n+new line\n"
+
+func toolMessages() []ai.Message {
+	return []ai.Message{
+		ai.NewTextMessage(ai.RoleUser, "Rename it."),
+		{Role: ai.RoleAssistant, Content: []ai.Content{
+			{Type: ai.ContentText, Text: "Editing main.go."},
+			{Type: ai.ContentToolUse, ID: "t1", Name: "edit", Input: json.RawMessage(`{"path":"main.go"}`)},
+		}},
+		{Role: ai.RoleUser, Content: []ai.Content{
+			{Type: ai.ContentToolResult, ID: "t1", ResultText: editResult},
+		}},
+	}
+}
+
+func TestFormatMarkdown_ToolCallsAndDiffs(t *testing.T) {
+	md := FormatMarkdown(toolMessages(), Options{})
+
+	for _, want := range []string{
+		"## user\n\nRename it.",
+		"**Tool call:** `edit`\n\n```json\n{\n  \"path\": \"main.go\"\n}\n```",
+		"## tool\n\n**Tool result** (`t1`)",
+		"```text\nMatch strategy: exact\n```",
+		"```diff\n--- main.go\n+++ main.go\n-old line\n+new line\n```",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("missing %q in:\n%s", want, md)
+		}
+	}
+	if strings.Contains(md, "Usage") || strings.Contains(md, "Exported") {
+		t.Errorf("zero options should add no header or usage:\n%s", md)
+	}
+}
+
+func TestFormatMarkdown_ErrorResultAndFence(t *testing.T) {
+	msgs := []ai.Message{{Role: ai.RoleUser, Content: []ai.Content{
+		{Type: ai.ContentToolResult, ID: "t2", ResultText: "see ```code```", IsError: true},
+	}}}
+	md := FormatMarkdown(msgs, Options{})
+	if !strings.Contains(md, "**Tool error** (`t2`)") || !strings.Contains(md, "````text\nsee ```code```\n````") {
+		t.Errorf("want an error label and a longer fence:\n%s", md)
+	}
+}
+
+func TestFormatMarkdown_HeaderAndUsage(t *testing.T) {
+	md := FormatMarkdown(nil, Options{
+		ExportedAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		Usage:      &Usage{Model: "claude-sonnet-4", InputTokens: 1200, OutputTokens: 300, CostUSD: 0.0081},
+	})
+	if !strings.HasPrefix(md, "_Exported 2026-03-01T12:00:00Z_") {
+		t.Errorf("missing export line:\n%s", md)
+	}
+	if !strings.Contains(md, "**Usage:** claude-sonnet-4 · 1200 input / 300 output tokens · $0.0081") {
+		t.Errorf("missing usage line:\n%s", md)
+	}
+}
+
+func TestSplitDiff(t *testing.T) {
+	text, d := splitDiff(editResult)
+	if text != "Match strategy: exact\n" || !strings.HasPrefix(d, "--- main.go\n+++ main.go") {
+		t.Errorf("splitDiff = %q, %q", text, d)
+	}
+	if text, d := splitDiff("--- not a diff\nplain"); d != "" || text != "--- not a diff\nplain" {
+		t.Errorf("a lone --- line is not a diff; got %q, %q", text, d)
+	}
+}
+
+func record(t *testing.T, typ session.RecordType, data any) session.Record {
+	t.Helper()
+	raw, err := json.Marshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return session.Record{Type: typ, Data: raw}
+}
+
+func TestSessionTranscript(t *testing.T) {
+	records := []session.Record{
+		record(t, session.RecordSessionStart, session.SessionStartData{ID: "s1", Model: "gpt-4o", CWD: "/repo"}),
+		record(t, session.RecordUser, session.UserData{Content: "Rename it."}),
+		record(t, session.RecordToolCall, session.ToolCallData{ID: "t1", Name: "edit", Args: json.RawMessage(`{}`)}),
+		record(t, session.RecordAssistant, session.AssistantData{
+			Content: "Done.", Thinking: "Use edit.", Model: "gpt-4o",
+			Usage: session.UsageData{Input: 1000, Output: 100},
+		}),
+		record(t, session.RecordToolResult, session.ToolResultData{ID: "t1", Content: editResult}),
+		record(t, session.RecordAssistant, session.AssistantData{Content: "All set.", Usage: session.UsageData{Input: 500, Output: 50}}),
+	}
+	tr, err := SessionTranscript(records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tr.ID != "s1" || tr.CWD != "/repo" || len(tr.Messages) != 4 {
+		t.Fatalf("transcript = %+v; want 4 messages", tr)
+	}
+	if c := tr.Messages[1].Content; len(c) != 3 || c[0].Type != ai.ContentToolUse || c[1].Type != ai.ContentThinking || c[2].Text != "Done." {
+		t.Errorf("assistant turn = %+v; want tool call, thinking, text", c)
+	}
+	if c := tr.Messages[2].Content; tr.Messages[2].Role != ai.RoleUser || c[0].Type != ai.ContentToolResult {
+		t.Errorf("tool result message = %+v", tr.Messages[2])
+	}
+	if tr.Usage.InputTokens != 1500 || tr.Usage.OutputTokens != 150 || tr.Usage.Model != "gpt-4o" || tr.Usage.CostUSD <= 0 {
+		t.Errorf("usage = %+v", tr.Usage)
+	}
+
+	var buf bytes.Buffer
+	if err := tr.Write(&buf, FormatHTML, Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "Usage: gpt-4o · 1500 input / 150 output tokens") {
+		t.Error("HTML export should default to the transcript usage")
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for name, want := range map[string]Format{"md": FormatMD, "Markdown": FormatMD, "html": FormatHTML, "htm": FormatHTML} {
+		if got, err := ParseFormat(name); err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseFormat("pdf"); err == nil {
+		t.Error("expected an error for pdf")
+	}
+	if FormatForPath("out.HTML") != FormatHTML || FormatForPath("out.txt") != FormatMD || FormatForPath("") != FormatMD {
+		t.Error("FormatForPath picked the wrong format")
+	}
+}
+
+func TestFormatMarkdown_HidesReminders(t *testing.T) {
+	msgs := []ai.Message{ai.NewTextMessage(ai.RoleUser, "hi"), session.NewReminder("Plan mode is active.")}
+	if md := FormatMarkdown(msgs, Options{}); strings.Contains(md, "system-reminder") {
+		t.Errorf("reminder exported by default:\n%s", md)
+	}
+	if md := FormatMarkdown(msgs, Options{ShowReminders: true}); !strings.Contains(md, "Plan mode is active.") {
+		t.Errorf("ShowReminders should keep it:\n%s", md)
+	}
+	var buf bytes.Buffer
+	if err := ExportHTML(msgs, &buf); err != nil || strings.Contains(buf.String(), "Plan mode") {
+		t.Errorf("reminder in HTML export (err %v)", err)
+	}
+}
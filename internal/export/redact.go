@@ -0,0 +1,172 @@
+// ABOUTME: Redaction of tool results and transcripts: home and project paths, API keys, tokens, private keys
+// ABOUTME: A Redactor adds user patterns to the built-in ones; transcripts are redacted on copies with counts
+
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+// Redacted replaces a removed secret.
+const Redacted = "[REDACTED]"
+
+// Redaction selects what Redact removes.
+type Redaction struct {
+	Paths   bool
+	Secrets bool
+	Home    string // replaced by "~"
+	CWD     string // replaced by "<project>"
+
+	// Redactor finds the secrets; nil uses the built-in patterns.
+	Redactor *Redactor
+}
+
+// RedactionStats counts what Redact replaced.
+type RedactionStats struct {
+	Paths   int
+	Secrets int
+}
+
+// secretPatterns match credentials by shape. A pattern with a group keeps
+// the first group and redacts the rest of the match, up to a second group
+// it keeps as well.
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`),
+	regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{20,}`),                                         // Anthropic, OpenAI
+	regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{30,}|github_pat_\w{30,})`),             // GitHub
+	regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`),                                            // AWS access key ID
+	regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}`),                                         // Google API key
+	regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`),                                  // Slack
+	regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`), // JWT
+	regexp.MustCompile(`(\b[a-z][a-z0-9+.-]*://[^\s:/@]+:)[^\s@/]{3,}(@)`),                // password in a URL
+	regexp.MustCompile(`(?i)(\bbearer\s+)[A-Za-z0-9._~+/-]{16,}=*`),
+	// A credential-named key assigned a quoted literal, as in JSON, YAML,
+	// or source. Unquoted values are code far more often than secrets
+	// (cfg.Token = load(), MaxTokens: opts.MaxTokens), so they are left.
+	regexp.MustCompile(`(?i)(\b\w*(?:api_?key|secret|token|passw(?:or)?d)\w*["']?\s*(?::=|[:=])\s*["'])[^\s"'\\]{8,}(["'])`),
+	// A dotenv or shell assignment starting its line, with no spaces
+	// around "=" and a value that is not an expression.
+	regexp.MustCompile(`(?im)^(\s*(?:export\s+)?\w*(?:api_?key|secret|token|passw(?:or)?d)\w*=)[^\s"'#.,()]{8,}(\s|$)`),
+}
+
+// Redactor replaces secrets matched by the built-in patterns and any
+// added with NewRedactor. The zero value and nil use the built-in ones.
+type Redactor struct {
+	patterns []*regexp.Regexp
+}
+
+// NewRedactor returns a redactor matching extra regular expressions on
+// top of the built-in patterns. As with those, a pattern with a group
+// keeps the first group and redacts the rest of the match.
+func NewRedactor(extra []string) (*Redactor, error) {
+	r := &Redactor{patterns: secretPatterns}
+	if len(extra) > 0 {
+		r.patterns = append([]*regexp.Regexp(nil), secretPatterns...)
+	}
+	for _, p := range extra {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("redaction pattern %q: %w", p, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+// Redact replaces the secrets in s.
+func (r *Redactor) Redact(s string) string {
+	var stats RedactionStats
+	return r.redact(s, &stats)
+}
+
+// Messages returns a copy of messages with their secrets replaced.
+func (r *Redactor) Messages(messages []ai.Message) []ai.Message {
+	out, _ := Redact(messages, Redaction{Secrets: true, Redactor: r})
+	return out
+}
+
+func (r *Redactor) redact(s string, stats *RedactionStats) string {
+	patterns := secretPatterns
+	if r != nil && r.patterns != nil {
+		patterns = r.patterns
+	}
+	for _, re := range patterns {
+		s = re.ReplaceAllStringFunc(s, func(match string) string {
+			stats.Secrets++
+			sub := re.FindStringSubmatch(match)
+			switch {
+			case len(sub) > 2:
+				return sub[1] + Redacted + sub[2]
+			case len(sub) > 1:
+				return sub[1] + Redacted
+			}
+			return Redacted
+		})
+	}
+	return s
+}
+
+// Redact returns a copy of messages with the selected redactions applied
+// to text, thinking, tool inputs, and tool results.
+func Redact(messages []ai.Message, r Redaction) ([]ai.Message, RedactionStats) {
+	var stats RedactionStats
+	if !r.Paths && !r.Secrets {
+		return messages, stats
+	}
+	apply := func(s string) string {
+		if r.Secrets {
+			s = r.Redactor.redact(s, &stats)
+		}
+		if r.Paths {
+			s = redactPaths(s, r, &stats)
+		}
+		return s
+	}
+
+	out := make([]ai.Message, len(messages))
+	for i, msg := range messages {
+		content := make([]ai.Content, len(msg.Content))
+		for j, c := range msg.Content {
+			c.Text = apply(c.Text)
+			c.Thinking = apply(c.Thinking)
+			c.ResultText = apply(c.ResultText)
+			if len(c.Input) > 0 {
+				input := apply(string(c.Input))
+				if json.Valid([]byte(input)) {
+					c.Input = json.RawMessage(input)
+				} else {
+					c.Input = nil
+				}
+			}
+			content[j] = c
+		}
+		msg.Content = content
+		out[i] = msg
+	}
+	return out, stats
+}
+
+// RedactSecrets replaces credentials matched by the built-in patterns in
+// s, as Redact does for transcripts.
+func RedactSecrets(s string) string {
+	return (*Redactor)(nil).Redact(s)
+}
+
+// redactPaths replaces the project directory before the home directory,
+// since the project usually lives inside home.
+func redactPaths(s string, r Redaction, stats *RedactionStats) string {
+	for _, p := range []struct{ dir, repl string }{{r.CWD, "<project>"}, {r.Home, "~"}} {
+		dir := strings.TrimRight(p.dir, "/")
+		if dir == "" {
+			continue
+		}
+		stats.Paths += strings.Count(s, dir)
+		s = strings.ReplaceAll(s, dir, p.repl)
+	}
+	return s
+}
@@ -0,0 +1,281 @@
+// ABOUTME: Session sharing: redacts and encrypts a transcript, uploads it to a gist or paste backend
+// ABOUTME: The AES-GCM key travels only in the link fragment; OpenShare fetches and decrypts a link
+
+package export
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+// shareHeader starts every encrypted upload so OpenShare can recognize it.
+const shareHeader = "pi-go-share-v1"
+
+// maxShareBytes bounds downloads in OpenShare.
+const maxShareBytes = 16 << 20
+
+// ShareOptions configures PrepareShare.
+type ShareOptions struct {
+	Backend   string // "gist" or "paste"
+	PasteURL  string // endpoint for the paste backend
+	Encrypt   bool
+	Redaction Redaction
+	Export    Options // Markdown options for the transcript
+}
+
+// SharePlan is a prepared upload, listed for confirmation before Upload.
+type SharePlan struct {
+	Backend     string
+	Destination string // where the upload goes, for display
+	Messages    int
+	ToolCalls   int
+	ToolResults int
+	Bytes       int // size of the uploaded payload
+	Encrypted   bool
+	Redaction   Redaction
+	Redacted    RedactionStats
+
+	payload  string
+	key      string
+	pasteURL string
+	gistAPI  string
+}
+
+// PrepareShare renders messages as a redacted Markdown transcript and
+// encrypts it, without uploading anything.
+func PrepareShare(messages []ai.Message, opts ShareOptions) (*SharePlan, error) {
+	p := &SharePlan{
+		Backend:   opts.Backend,
+		Encrypted: opts.Encrypt,
+		Redaction: opts.Redaction,
+		pasteURL:  opts.PasteURL,
+		gistAPI:   githubGistAPI,
+	}
+	switch opts.Backend {
+	case "gist":
+		p.Destination = "GitHub gist (secret)"
+	case "paste":
+		if opts.PasteURL == "" {
+			return nil, fmt.Errorf("share backend paste needs share.pasteUrl")
+		}
+		p.Destination = opts.PasteURL
+	default:
+		return nil, fmt.Errorf("unknown share backend %q (want gist or paste)", opts.Backend)
+	}
+
+	messages = visibleMessages(messages, opts.Export)
+	messages, p.Redacted = Redact(messages, opts.Redaction)
+	for _, msg := range messages {
+		for _, c := range msg.Content {
+			switch c.Type {
+			case ai.ContentToolUse:
+				p.ToolCalls++
+			case ai.ContentToolResult:
+				p.ToolResults++
+			}
+		}
+	}
+	p.Messages = len(messages)
+
+	p.payload = FormatMarkdown(messages, opts.Export)
+	if opts.Encrypt {
+		sealed, key, err := Encrypt([]byte(p.payload))
+		if err != nil {
+			return nil, err
+		}
+		p.payload, p.key = sealed, key
+	}
+	p.Bytes = len(p.payload)
+	return p, nil
+}
+
+// Upload sends the prepared payload and returns the shareable link; for an
+// encrypted share the link carries the key in its fragment.
+func (p *SharePlan) Upload() (string, error) {
+	var url string
+	var err error
+	switch p.Backend {
+	case "paste":
+		url, err = PostPaste(p.pasteURL, p.payload)
+	default:
+		name := "session.md"
+		if p.Encrypted {
+			name = "session.md.enc"
+		}
+		url, err = createGistFileWithURL(p.gistAPI, name, p.payload, "Conversation export", false)
+	}
+	if err != nil {
+		return "", err
+	}
+	return ShareLink(url, p.key), nil
+}
+
+// Encrypt seals plaintext with a fresh AES-256-GCM key. It returns the
+// armored ciphertext and the key, both base64url encoded.
+func Encrypt(plaintext []byte) (armored, key string, err error) {
+	k := make([]byte, 32)
+	if _, err := rand.Read(k); err != nil {
+		return "", "", fmt.Errorf("generating key: %w", err)
+	}
+	gcm, err := newGCM(k)
+	if err != nil {
+		return "", "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", "", fmt.Errorf("generating nonce: %w", err)
+	}
+	sealed := gcm.Seal(nonce, nonce, plaintext, []byte(shareHeader))
+	enc := base64.RawURLEncoding
+	return shareHeader + "\n" + enc.EncodeToString(sealed) + "\n", enc.EncodeToString(k), nil
+}
+
+// Decrypt opens armored ciphertext made by Encrypt.
+func Decrypt(armored, key string) ([]byte, error) {
+	body, ok := strings.CutPrefix(strings.TrimSpace(armored), shareHeader+"\n")
+	if !ok {
+		return nil, fmt.Errorf("not an encrypted pi-go share")
+	}
+	enc := base64.RawURLEncoding
+	k, err := enc.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("decoding key: %w", err)
+	}
+	sealed, err := enc.DecodeString(strings.TrimSpace(body))
+	if err != nil {
+		return nil, fmt.Errorf("decoding ciphertext: %w", err)
+	}
+	gcm, err := newGCM(k)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	plain, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], []byte(shareHeader))
+	if err != nil {
+		return nil, fmt.Errorf("decrypting share: wrong key or corrupted data")
+	}
+	return plain, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("creating cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("creating GCM: %w", err)
+	}
+	return gcm, nil
+}
+
+// ShareLink appends key to url as the fragment, which browsers and
+// servers never send over the network.
+func ShareLink(url, key string) string {
+	if key == "" {
+		return url
+	}
+	return url + "#key=" + key
+}
+
+// ParseShareLink splits a link made by ShareLink into its URL and key.
+func ParseShareLink(link string) (url, key string) {
+	url, frag, _ := strings.Cut(link, "#")
+	key, _ = strings.CutPrefix(frag, "key=")
+	return url, key
+}
+
+// PostPaste uploads content to a paste endpoint and returns the link it
+// answers with.
+func PostPaste(endpoint, content string) (string, error) {
+	resp, err := http.Post(endpoint, "text/plain; charset=utf-8", strings.NewReader(content))
+	if err != nil {
+		return "", fmt.Errorf("posting paste: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return "", fmt.Errorf("reading response: %w", err)
+	}
+	if resp.StatusCode/100 != 2 {
+		return "", fmt.Errorf("paste backend returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	link := strings.TrimSpace(string(body))
+	if !strings.HasPrefix(link, "http://") && !strings.HasPrefix(link, "https://") {
+		return "", fmt.Errorf("paste backend returned no link: %q", link)
+	}
+	return link, nil
+}
+
+// OpenShare downloads the share at link and returns its Markdown,
+// decrypting it when the link carries a key.
+func OpenShare(link string) (string, error) {
+	url, key := ParseShareLink(link)
+	if id, ok := gistID(url); ok {
+		url = githubGistAPI + "/" + id
+	}
+	content, err := fetchShare(url)
+	if err != nil {
+		return "", err
+	}
+	if key == "" {
+		return content, nil
+	}
+	plain, err := Decrypt(content, key)
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
+// gistID extracts the gist ID from a gist.github.com page URL.
+func gistID(url string) (string, bool) {
+	rest, ok := strings.CutPrefix(url, "https://gist.github.com/")
+	if !ok {
+		return "", false
+	}
+	parts := strings.Split(strings.Trim(rest, "/"), "/")
+	return parts[len(parts)-1], parts[len(parts)-1] != ""
+}
+
+// fetchShare GETs url; a gist API response yields its first file.
+func fetchShare(url string) (string, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return "", fmt.Errorf("fetching share: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxShareBytes))
+	if err != nil {
+		return "", fmt.Errorf("reading share: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetching share: status %d", resp.StatusCode)
+	}
+	if !strings.HasPrefix(url, githubGistAPI) {
+		return string(body), nil
+	}
+	var gist struct {
+		Files map[string]struct {
+			Content string `json:"content"`
+		} `json:"files"`
+	}
+	if err := json.Unmarshal(body, &gist); err != nil {
+		return "", fmt.Errorf("decoding gist: %w", err)
+	}
+	for _, f := range gist.Files {
+		return f.Content, nil
+	}
+	return "", fmt.Errorf("gist has no files")
+}
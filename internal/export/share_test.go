@@ -0,0 +1,188 @@
+// ABOUTME: Tests for session sharing: encryption round trip, link fragments, and uploads
+// ABOUTME: Paste and gist backends are mocked with httptest servers
+
+package export
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+func TestEncrypt_RoundTrip(t *testing.T) {
+	armored, key, err := Encrypt([]byte("# Session\nhello"))
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if !strings.HasPrefix(armored, shareHeader+"\n") {
+		t.Errorf("missing header: %q", armored)
+	}
+	if strings.Contains(armored, "hello") {
+		t.Error("ciphertext contains plaintext")
+	}
+	plain, err := Decrypt(armored, key)
+	if err != nil {
+		t.Fatalf("Decrypt: %v", err)
+	}
+	if string(plain) != "# Session\nhello" {
+		t.Errorf("plain = %q", plain)
+	}
+
+	_, other, _ := Encrypt(nil)
+	if _, err := Decrypt(armored, other); err == nil {
+		t.Error("Decrypt with wrong key succeeded")
+	}
+	if _, err := Decrypt("plain text", key); err == nil {
+		t.Error("Decrypt of unencrypted text succeeded")
+	}
+}
+
+func TestShareLink_RoundTrip(t *testing.T) {
+	link := ShareLink("https://paste.example/abc", "k3y")
+	if link != "https://paste.example/abc#key=k3y" {
+		t.Errorf("link = %q", link)
+	}
+	url, key := ParseShareLink(link)
+	if url != "https://paste.example/abc" || key != "k3y" {
+		t.Errorf("ParseShareLink = %q, %q", url, key)
+	}
+	if ShareLink("https://x", "") != "https://x" {
+		t.Error("empty key should leave the URL unchanged")
+	}
+}
+
+func shareMessages() []ai.Message {
+	return []ai.Message{
+		ai.NewTextMessage(ai.RoleUser, "read /home/ana/app/main.go with sk-ant-REDACTED"),
+		{Role: ai.RoleAssistant, Content: []ai.Content{
+			{Type: ai.ContentToolUse, ID: "t1", Name: "read", Input: json.RawMessage(`{"path":"/home/ana/app/main.go"}`)},
+		}},
+		{Role: ai.RoleUser, Content: []ai.Content{
+			{Type: ai.ContentToolResult, ID: "t1", ResultText: "package main"},
+		}},
+	}
+}
+
+func TestPrepareShare_Paste(t *testing.T) {
+	var uploaded string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			body, _ := io.ReadAll(r.Body)
+			uploaded = string(body)
+			io.WriteString(w, "http://"+r.Host+"/p/1\n")
+			return
+		}
+		io.WriteString(w, uploaded)
+	}))
+	defer srv.Close()
+
+	plan, err := PrepareShare(shareMessages(), ShareOptions{
+		Backend:   "paste",
+		PasteURL:  srv.URL,
+		Encrypt:   true,
+		Redaction: Redaction{Paths: true, Secrets: true, Home: "/home/ana", CWD: "/home/ana/app"},
+	})
+	if err != nil {
+		t.Fatalf("PrepareShare: %v", err)
+	}
+	if plan.Messages != 3 || plan.ToolCalls != 1 || plan.ToolResults != 1 {
+		t.Errorf("counts = %d/%d/%d", plan.Messages, plan.ToolCalls, plan.ToolResults)
+	}
+	if plan.Redacted.Secrets != 1 || plan.Redacted.Paths != 2 {
+		t.Errorf("redacted = %+v", plan.Redacted)
+	}
+
+	link, err := plan.Upload()
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if !strings.HasPrefix(link, srv.URL+"/p/1#key=") {
+		t.Errorf("link = %q", link)
+	}
+	if len(uploaded) != plan.Bytes || strings.Contains(uploaded, "main.go") {
+		t.Errorf("upload not encrypted or size mismatch: %d bytes vs %d", len(uploaded), plan.Bytes)
+	}
+
+	md, err := OpenShare(link)
+	if err != nil {
+		t.Fatalf("OpenShare: %v", err)
+	}
+	for _, want := range []string{"<project>/main.go", "[REDACTED]", "package main"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("shared transcript missing %q:\n%s", want, md)
+		}
+	}
+	if strings.Contains(md, "/home/ana") || strings.Contains(md, "sk-ant-") {
+		t.Errorf("shared transcript leaks paths or secrets:\n%s", md)
+	}
+}
+
+func TestPrepareShare_Gist(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "test-token")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req gistRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decoding request: %v", err)
+		}
+		if req.Public {
+			t.Error("share gists must be secret")
+		}
+		if _, ok := req.Files["session.md.enc"]; !ok {
+			t.Errorf("files = %v, want session.md.enc", req.Files)
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(gistResponse{HTMLURL: "https://gist.github.com/ana/abc"})
+	}))
+	defer srv.Close()
+
+	plan, err := PrepareShare(shareMessages(), ShareOptions{Backend: "gist", Encrypt: true})
+	if err != nil {
+		t.Fatalf("PrepareShare: %v", err)
+	}
+	plan.gistAPI = srv.URL
+	link, err := plan.Upload()
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if !strings.HasPrefix(link, "https://gist.github.com/ana/abc#key=") {
+		t.Errorf("link = %q", link)
+	}
+}
+
+func TestPrepareShare_Errors(t *testing.T) {
+	if _, err := PrepareShare(nil, ShareOptions{Backend: "paste"}); err == nil {
+		t.Error("paste without URL should fail")
+	}
+	if _, err := PrepareShare(nil, ShareOptions{Backend: "ftp"}); err == nil {
+		t.Error("unknown backend should fail")
+	}
+}
+
+func TestPostPaste_RejectsNonLink(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+	if _, err := PostPaste(srv.URL, "x"); err == nil {
+		t.Error("expected error for a response without a link")
+	}
+}
+
+func TestGistID(t *testing.T) {
+	for url, want := range map[string]string{
+		"https://gist.github.com/ana/abc123": "abc123",
+		"https://gist.github.com/abc123/":    "abc123",
+	} {
+		if got, ok := gistID(url); !ok || got != want {
+			t.Errorf("gistID(%q) = %q, %v", url, got, ok)
+		}
+	}
+	if _, ok := gistID("https://paste.example/abc"); ok {
+		t.Error("non-gist URL matched")
+	}
+}
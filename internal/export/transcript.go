@@ -0,0 +1,173 @@
+// ABOUTME: Rebuilds a full transcript from persisted session records for export
+// ABOUTME: Keeps thinking, tool calls, and tool results, and totals token usage and cost
+
+package export
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/session"
+	"github.com/mauromedda/pi-coding-agent-go/internal/telemetry"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+// Usage is the token and cost summary shown at the end of an export.
+type Usage struct {
+	Model        string
+	InputTokens  int
+	OutputTokens int
+	CostUSD      float64
+}
+
+// Transcript is a session rebuilt for export.
+type Transcript struct {
+	ID       string
+	Model    string
+	CWD      string
+	Messages []ai.Message
+	Usage    Usage
+}
+
+// SessionTranscript rebuilds the messages of a persisted session. Unlike
+// session.BuildSessionContext it ignores compaction and keeps every record
+// an export should show: thinking, tool calls, and tool results.
+func SessionTranscript(records []session.Record) (*Transcript, error) {
+	tr := &Transcript{}
+	for _, rec := range records {
+		switch rec.Type {
+		case session.RecordSessionStart:
+			var sd session.SessionStartData
+			if err := rec.Unmarshal(&sd); err != nil {
+				return nil, fmt.Errorf("unmarshaling session start: %w", err)
+			}
+			tr.ID, tr.Model, tr.CWD = sd.ID, sd.Model, sd.CWD
+
+		case session.RecordUser:
+			var ud session.UserData
+			if err := rec.Unmarshal(&ud); err != nil {
+				return nil, fmt.Errorf("unmarshaling user data: %w", err)
+			}
+			tr.Messages = append(tr.Messages, ai.NewTextMessage(ai.RoleUser, ud.Content))
+
+		case session.RecordAssistant:
+			var ad session.AssistantData
+			if err := rec.Unmarshal(&ad); err != nil {
+				return nil, fmt.Errorf("unmarshaling assistant data: %w", err)
+			}
+			var blocks []ai.Content
+			if ad.Thinking != "" {
+				blocks = append(blocks, ai.Content{Type: ai.ContentThinking, Thinking: ad.Thinking})
+			}
+			if ad.Content != "" {
+				blocks = append(blocks, ai.Content{Type: ai.ContentText, Text: ad.Content})
+			}
+			tr.appendBlocks(ai.RoleAssistant, blocks...)
+			tr.addUsage(ad)
+
+		case session.RecordToolCall:
+			var tc session.ToolCallData
+			if err := rec.Unmarshal(&tc); err != nil {
+				return nil, fmt.Errorf("unmarshaling tool call: %w", err)
+			}
+			tr.appendBlocks(ai.RoleAssistant, ai.Content{Type: ai.ContentToolUse, ID: tc.ID, Name: tc.Name, Input: tc.Args})
+
+		case session.RecordToolResult:
+			var res session.ToolResultData
+			if err := rec.Unmarshal(&res); err != nil {
+				return nil, fmt.Errorf("unmarshaling tool result: %w", err)
+			}
+			tr.appendBlocks(ai.RoleUser, ai.Content{Type: ai.ContentToolResult, ID: res.ID, ResultText: res.Content, IsError: res.IsError})
+		}
+	}
+	if tr.Usage.Model == "" {
+		tr.Usage.Model = tr.Model
+	}
+	return tr, nil
+}
+
+// appendBlocks adds blocks to the last message when it has the same role
+// and holds tool blocks, so a tool call and the reply around it stay in one
+// turn; otherwise it starts a new message.
+func (tr *Transcript) appendBlocks(role ai.Role, blocks ...ai.Content) {
+	if len(blocks) == 0 {
+		return
+	}
+	if n := len(tr.Messages); n > 0 {
+		last := &tr.Messages[n-1]
+		if last.Role == role && hasToolBlocks(last.Content) {
+			last.Content = append(last.Content, blocks...)
+			return
+		}
+	}
+	tr.Messages = append(tr.Messages, ai.Message{Role: role, Content: blocks})
+}
+
+// addUsage totals the tokens of an assistant record and prices them with
+// the model that produced it.
+func (tr *Transcript) addUsage(ad session.AssistantData) {
+	tr.Usage.InputTokens += ad.Usage.Input
+	tr.Usage.OutputTokens += ad.Usage.Output
+	model := ad.Model
+	if model == "" {
+		model = tr.Model
+	}
+	if tr.Usage.Model == "" {
+		tr.Usage.Model = model
+	}
+	tr.Usage.CostUSD += telemetry.EstimateCost(model, ad.Usage.Input, ad.Usage.Output)
+}
+
+func hasToolBlocks(blocks []ai.Content) bool {
+	for _, c := range blocks {
+		if c.Type == ai.ContentToolUse || c.Type == ai.ContentToolResult {
+			return true
+		}
+	}
+	return false
+}
+
+// Format is an export file format.
+type Format string
+
+const (
+	FormatMD   Format = "md"
+	FormatHTML Format = "html"
+)
+
+// ParseFormat validates a format name; "markdown" and "htm" are accepted
+// as aliases.
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(name) {
+	case "md", "markdown":
+		return FormatMD, nil
+	case "html", "htm":
+		return FormatHTML, nil
+	default:
+		return "", fmt.Errorf("unknown export format %q (want md or html)", name)
+	}
+}
+
+// FormatForPath picks the format from the extension of path: HTML for
+// .html and .htm, Markdown otherwise.
+func FormatForPath(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm":
+		return FormatHTML
+	default:
+		return FormatMD
+	}
+}
+
+// Write renders the transcript to w in format.
+func (tr *Transcript) Write(w io.Writer, format Format, opts Options) error {
+	if opts.Usage == nil {
+		opts.Usage = &tr.Usage
+	}
+	if format == FormatHTML {
+		return ExportHTMLWithOptions(tr.Messages, w, opts)
+	}
+	return ExportMarkdown(tr.Messages, w, opts)
+}
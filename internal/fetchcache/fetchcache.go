@@ -0,0 +1,380 @@
+// ABOUTME: Content-addressable cache for web fetch and MCP resource results, keyed by URL or URI
+// ABOUTME: Session entries live in memory; docs pages persist on disk with a longer TTL and ETag revalidation
+
+package fetchcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults used when Options fields are zero.
+const (
+	DefaultTTL        = 15 * time.Minute
+	DefaultDocsTTL    = 24 * time.Hour
+	DefaultMaxEntries = 500
+)
+
+// indexFile holds the persisted entries; blobs live under objects/.
+const indexFile = "index.json"
+
+// Validators are the HTTP cache validators stored with an entry so an
+// expired entry can be revalidated with a conditional request.
+type Validators struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+// entry maps a key to a content blob.
+type entry struct {
+	Hash       string     `json:"hash"` // sha256 of the content; names the blob
+	Size       int        `json:"size"`
+	Validators Validators `json:"validators"`
+	FetchedAt  time.Time  `json:"fetchedAt"`
+	ExpiresAt  time.Time  `json:"expiresAt"`
+	Persist    bool       `json:"persist"`
+}
+
+// Options configures a Store.
+type Options struct {
+	// Dir holds persisted entries across sessions; empty keeps everything in memory.
+	Dir string
+	// TTL is how long an entry stays fresh. Zero uses DefaultTTL.
+	TTL time.Duration
+	// DocsTTL is the freshness of entries that persist across sessions. Zero uses DefaultDocsTTL.
+	DocsTTL time.Duration
+	// MaxEntries bounds the number of keys; the oldest fetch is evicted first.
+	MaxEntries int
+	// DocsDomains are extra hosts whose pages persist, on top of IsDocsURL's defaults.
+	DocsDomains []string
+}
+
+// Lookup is the result of Store.Lookup.
+type Lookup struct {
+	Content    string
+	Found      bool // an entry exists, fresh or stale
+	Fresh      bool // the entry can be served without contacting the origin
+	Validators Validators
+}
+
+// Stats summarizes cache contents and effectiveness for this session.
+type Stats struct {
+	Entries     int
+	Persisted   int
+	Blobs       int   // distinct contents; fewer than Entries when pages are identical
+	Bytes       int64 // total size of distinct contents
+	Hits        int
+	Misses      int
+	Revalidated int // stale entries confirmed unchanged by the origin (HTTP 304)
+}
+
+// Store is a content-addressable cache. All methods are safe for concurrent use.
+type Store struct {
+	mu      sync.Mutex
+	opts    Options
+	entries map[string]*entry
+	blobs   map[string]string // hash -> content; persisted blobs load lazily
+	stats   Stats
+	now     func() time.Time
+}
+
+// New creates a Store, loading unexpired persisted entries from opts.Dir.
+// A missing or corrupt index starts the store empty.
+func New(opts Options) *Store {
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultTTL
+	}
+	if opts.DocsTTL <= 0 {
+		opts.DocsTTL = DefaultDocsTTL
+	}
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = DefaultMaxEntries
+	}
+	s := &Store{
+		opts:    opts,
+		entries: make(map[string]*entry),
+		blobs:   make(map[string]string),
+		now:     time.Now,
+	}
+	s.loadIndex()
+	return s
+}
+
+// Lookup returns the cached content for key and whether it is still fresh.
+// A fresh result counts as a hit; anything else counts as a miss.
+func (s *Store) Lookup(key string) Lookup {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		s.stats.Misses++
+		return Lookup{}
+	}
+	content, ok := s.blob(e.Hash)
+	if !ok {
+		delete(s.entries, key)
+		s.stats.Misses++
+		return Lookup{}
+	}
+	fresh := s.now().Before(e.ExpiresAt)
+	if fresh {
+		s.stats.Hits++
+	} else {
+		s.stats.Misses++
+	}
+	return Lookup{Content: content, Found: true, Fresh: fresh, Validators: e.Validators}
+}
+
+// Put stores content for key with the given validators.
+func (s *Store) Put(key, content string, v Validators) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, exists := s.entries[key]
+	if !exists && len(s.entries) >= s.opts.MaxEntries {
+		s.evictOldest()
+	}
+
+	hash := hashContent(content)
+	now := s.now()
+	persist := s.opts.Dir != "" && s.isDocs(key)
+	ttl := s.opts.TTL
+	if persist {
+		ttl = s.opts.DocsTTL
+	}
+	s.entries[key] = &entry{Hash: hash, Size: len(content), Validators: v, FetchedAt: now, ExpiresAt: now.Add(ttl), Persist: persist}
+	s.blobs[hash] = content
+	if exists && old.Hash != hash {
+		s.releaseBlob(old.Hash)
+	}
+
+	if persist {
+		if err := s.writeBlob(hash, content); err == nil {
+			_ = s.saveIndex()
+		}
+	}
+}
+
+// Revalidate marks a stale entry fresh again after the origin confirmed it
+// unchanged (HTTP 304). It returns false if the key is not cached.
+func (s *Store) Revalidate(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		return false
+	}
+	ttl := s.opts.TTL
+	if e.Persist {
+		ttl = s.opts.DocsTTL
+	}
+	e.ExpiresAt = s.now().Add(ttl)
+	s.stats.Revalidated++
+	if e.Persist {
+		_ = s.saveIndex()
+	}
+	return true
+}
+
+// Stats returns a snapshot of the cache contents and hit counters.
+func (s *Store) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := s.stats
+	st.Entries = len(s.entries)
+	sizes := make(map[string]int)
+	for _, e := range s.entries {
+		if e.Persist {
+			st.Persisted++
+		}
+		sizes[e.Hash] = e.Size
+	}
+	st.Blobs = len(sizes)
+	for _, n := range sizes {
+		st.Bytes += int64(n)
+	}
+	return st
+}
+
+// Clear removes every entry, in memory and on disk, and resets the counters.
+func (s *Store) Clear() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries = make(map[string]*entry)
+	s.blobs = make(map[string]string)
+	s.stats = Stats{}
+	if s.opts.Dir == "" {
+		return nil
+	}
+	if err := os.RemoveAll(s.opts.Dir); err != nil {
+		return fmt.Errorf("clearing %s: %w", s.opts.Dir, err)
+	}
+	return nil
+}
+
+// IsDocsURL reports whether rawURL looks like documentation worth keeping
+// across sessions: docs.* / *.readthedocs.io hosts, well-known reference
+// sites, or a /docs/ path.
+func IsDocsURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	switch {
+	case strings.HasPrefix(host, "docs."), strings.HasSuffix(host, ".readthedocs.io"):
+		return true
+	case host == "pkg.go.dev", host == "developer.mozilla.org", host == "docs.rs":
+		return true
+	}
+	path := strings.ToLower(u.Path)
+	return strings.Contains(path, "/docs/") || strings.HasSuffix(path, "/docs")
+}
+
+func (s *Store) isDocs(key string) bool {
+	if IsDocsURL(key) {
+		return true
+	}
+	u, err := url.Parse(key)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, d := range s.opts.DocsDomains {
+		d = strings.ToLower(strings.TrimPrefix(d, "."))
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// evictOldest drops the entry fetched longest ago. Caller holds mu.
+func (s *Store) evictOldest() {
+	var oldest string
+	var oldestAt time.Time
+	for k, e := range s.entries {
+		if oldest == "" || e.FetchedAt.Before(oldestAt) {
+			oldest, oldestAt = k, e.FetchedAt
+		}
+	}
+	e := s.entries[oldest]
+	delete(s.entries, oldest)
+	s.releaseBlob(e.Hash)
+	if e.Persist {
+		_ = s.saveIndex()
+	}
+}
+
+// blob returns content by hash, loading persisted blobs from disk. Caller holds mu.
+func (s *Store) blob(hash string) (string, bool) {
+	if c, ok := s.blobs[hash]; ok {
+		return c, true
+	}
+	if s.opts.Dir == "" {
+		return "", false
+	}
+	data, err := os.ReadFile(s.blobPath(hash))
+	if err != nil || hashContent(string(data)) != hash {
+		return "", false
+	}
+	s.blobs[hash] = string(data)
+	return string(data), true
+}
+
+// releaseBlob frees a blob, in memory and on disk, once no entry refers to
+// it. Caller holds mu.
+func (s *Store) releaseBlob(hash string) {
+	for _, e := range s.entries {
+		if e.Hash == hash {
+			return
+		}
+	}
+	delete(s.blobs, hash)
+	if s.opts.Dir != "" {
+		_ = os.Remove(s.blobPath(hash))
+	}
+}
+
+func (s *Store) blobPath(hash string) string {
+	return filepath.Join(s.opts.Dir, "objects", hash[:2], hash)
+}
+
+func (s *Store) writeBlob(hash, content string) error {
+	path := s.blobPath(hash)
+	if _, err := os.Stat(path); err == nil {
+		return nil // content-addressed: already stored
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("creating cache dir: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		return fmt.Errorf("writing cache blob: %w", err)
+	}
+	return nil
+}
+
+// saveIndex writes persisted entries to disk. Caller holds mu.
+func (s *Store) saveIndex() error {
+	persisted := make(map[string]*entry)
+	for k, e := range s.entries {
+		if e.Persist {
+			persisted[k] = e
+		}
+	}
+	data, err := json.MarshalIndent(persisted, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding cache index: %w", err)
+	}
+	if err := os.MkdirAll(s.opts.Dir, 0o700); err != nil {
+		return fmt.Errorf("creating cache dir: %w", err)
+	}
+	tmp := filepath.Join(s.opts.Dir, indexFile+".tmp")
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("writing cache index: %w", err)
+	}
+	return os.Rename(tmp, filepath.Join(s.opts.Dir, indexFile))
+}
+
+// loadIndex restores persisted entries that are still usable. Expired
+// entries are kept so they can be revalidated with their ETag.
+func (s *Store) loadIndex() {
+	if s.opts.Dir == "" {
+		return
+	}
+	data, err := os.ReadFile(filepath.Join(s.opts.Dir, indexFile))
+	if err != nil {
+		return
+	}
+	var persisted map[string]*entry
+	if json.Unmarshal(data, &persisted) != nil {
+		return
+	}
+	for k, e := range persisted {
+		if e == nil || len(e.Hash) < 2 {
+			continue
+		}
+		if _, err := os.Stat(s.blobPath(e.Hash)); err != nil {
+			continue
+		}
+		e.Persist = true
+		s.entries[k] = e
+	}
+}
+
+func hashContent(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,155 @@
+// ABOUTME: Tests for the fetch cache: freshness, revalidation, dedup, eviction, persistence, and clearing
+// ABOUTME: Uses a controllable clock and temp dirs for the on-disk store
+
+package fetchcache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time { return c.t }
+
+func newTestStore(opts Options) (*Store, *clock) {
+	s := New(opts)
+	c := &clock{t: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	s.now = c.now
+	return s, c
+}
+
+func TestStore_FreshThenStale(t *testing.T) {
+	t.Parallel()
+	s, c := newTestStore(Options{TTL: time.Minute})
+
+	if got := s.Lookup("https://example.com/a"); got.Found {
+		t.Fatalf("empty store: %+v", got)
+	}
+	s.Put("https://example.com/a", "page", Validators{ETag: `"v1"`})
+
+	got := s.Lookup("https://example.com/a")
+	if !got.Fresh || got.Content != "page" {
+		t.Fatalf("fresh lookup = %+v", got)
+	}
+
+	c.t = c.t.Add(2 * time.Minute)
+	got = s.Lookup("https://example.com/a")
+	if got.Fresh || !got.Found || got.Validators.ETag != `"v1"` {
+		t.Fatalf("stale lookup = %+v, want found with validators", got)
+	}
+	if !s.Revalidate("https://example.com/a") {
+		t.Fatal("Revalidate() = false for a cached key")
+	}
+	if !s.Lookup("https://example.com/a").Fresh {
+		t.Error("revalidated entry should be fresh")
+	}
+
+	st := s.Stats()
+	if st.Hits != 2 || st.Misses != 2 || st.Revalidated != 1 {
+		t.Errorf("stats = %+v, want 2 hits, 2 misses, 1 revalidated", st)
+	}
+}
+
+func TestStore_DeduplicatesContent(t *testing.T) {
+	t.Parallel()
+	s, _ := newTestStore(Options{})
+	s.Put("https://a.example/x", "same body", Validators{})
+	s.Put("https://b.example/y", "same body", Validators{})
+	s.Put("https://c.example/z", "other", Validators{})
+
+	st := s.Stats()
+	if st.Entries != 3 || st.Blobs != 2 || st.Bytes != int64(len("same body")+len("other")) {
+		t.Errorf("stats = %+v, want 3 entries sharing 2 blobs", st)
+	}
+}
+
+func TestStore_EvictsOldest(t *testing.T) {
+	t.Parallel()
+	s, c := newTestStore(Options{MaxEntries: 2})
+	s.Put("k1", "one", Validators{})
+	c.t = c.t.Add(time.Second)
+	s.Put("k2", "two", Validators{})
+	c.t = c.t.Add(time.Second)
+	s.Put("k3", "three", Validators{})
+
+	if s.Lookup("k1").Found {
+		t.Error("oldest entry should be evicted")
+	}
+	if st := s.Stats(); st.Entries != 2 || st.Blobs != 2 {
+		t.Errorf("stats = %+v, want 2 entries and blobs", st)
+	}
+}
+
+func TestStore_PersistsDocsAcrossSessions(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	s, c := newTestStore(Options{Dir: dir, DocsDomains: []string{"internal.example"}})
+	s.Put("https://pkg.go.dev/net/http", "net/http docs", Validators{ETag: `"e"`})
+	s.Put("https://wiki.internal.example/page", "wiki", Validators{})
+	s.Put("https://example.com/blog", "blog post", Validators{})
+
+	if st := s.Stats(); st.Persisted != 2 {
+		t.Fatalf("Persisted = %d, want 2 docs pages", st.Persisted)
+	}
+
+	next := New(Options{Dir: dir})
+	next.now = c.now
+	got := next.Lookup("https://pkg.go.dev/net/http")
+	if !got.Fresh || got.Content != "net/http docs" || got.Validators.ETag != `"e"` {
+		t.Errorf("docs page after restart = %+v", got)
+	}
+	if next.Lookup("https://example.com/blog").Found {
+		t.Error("non-docs pages should not outlive the session")
+	}
+}
+
+func TestStore_ReplacedPersistedBlobIsRemoved(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	s, _ := newTestStore(Options{Dir: dir})
+	s.Put("https://docs.example.com/a", "v1", Validators{})
+	old := s.blobPath(hashContent("v1"))
+	s.Put("https://docs.example.com/a", "v2", Validators{})
+
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Errorf("stale blob still on disk: %v", err)
+	}
+}
+
+func TestStore_Clear(t *testing.T) {
+	t.Parallel()
+	dir := filepath.Join(t.TempDir(), "fetch")
+	s, _ := newTestStore(Options{Dir: dir})
+	s.Put("https://docs.example.com/a", "docs", Validators{})
+	s.Lookup("https://docs.example.com/a")
+
+	if err := s.Clear(); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if st := s.Stats(); st != (Stats{}) {
+		t.Errorf("stats after clear = %+v", st)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("cache dir still exists: %v", err)
+	}
+}
+
+func TestIsDocsURL(t *testing.T) {
+	t.Parallel()
+	cases := map[string]bool{
+		"https://docs.python.org/3/library/os.html": true,
+		"https://pkg.go.dev/strings":                true,
+		"https://requests.readthedocs.io/en/latest": true,
+		"https://example.com/docs/getting-started":  true,
+		"https://example.com/blog/post":             false,
+		"mcp:server:file:///docs/readme":            false,
+	}
+	for in, want := range cases {
+		if got := IsDocsURL(in); got != want {
+			t.Errorf("IsDocsURL(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
@@ -8,8 +8,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os/exec"
 	"time"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/shell"
 )
 
 const hookTimeout = 10 * time.Second
@@ -26,7 +27,11 @@ func runHookCommand(ctx context.Context, command string, input HookInput) (HookO
 		return HookOutput{}, fmt.Errorf("marshal hook input: %w", err)
 	}
 
-	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	sh, err := shell.Current()
+	if err != nil {
+		return HookOutput{}, fmt.Errorf("hook command: %w", err)
+	}
+	cmd := sh.Command(ctx, command)
 	cmd.Stdin = bytes.NewReader(inputJSON)
 	setProcGroup(cmd)
 
@@ -0,0 +1,19 @@
+// ABOUTME: Windows process handling for hook commands: no process groups
+// ABOUTME: Kills only the hook process itself on timeout
+
+//go:build windows
+
+package hooks
+
+import "os/exec"
+
+// setProcGroup is a no-op; Windows has no Setpgid.
+func setProcGroup(*exec.Cmd) {}
+
+// killProcGroup kills the command's process.
+func killProcGroup(cmd *exec.Cmd) error {
+	if cmd.Process != nil {
+		return cmd.Process.Kill()
+	}
+	return nil
+}
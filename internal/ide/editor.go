@@ -1,4 +1,4 @@
-// ABOUTME: Opens content in $EDITOR for editing (Ctrl+G integration)
+// ABOUTME: Opens content or files in $EDITOR for editing (Ctrl+G integration, /memory)
 // ABOUTME: Writes to temp file, launches editor, reads back edited content
 
 package ide
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"strings"
 )
 
 // OpenInEditor writes content to a temp file, launches $EDITOR,
@@ -43,6 +44,21 @@ func OpenInEditor(content string) (string, error) {
 	return string(edited), nil
 }
 
+// EditFileCommand returns a command that opens path in $EDITOR, for callers
+// that run it themselves (e.g. with the TUI suspended). Editor arguments
+// such as "code --wait" are honored.
+func EditFileCommand(path string) *exec.Cmd {
+	args := strings.Fields(getEditor())
+	if len(args) == 0 {
+		args = []string{"vi"}
+	}
+	cmd := exec.Command(args[0], append(args[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd
+}
+
 func getEditor() string {
 	if editor := os.Getenv("EDITOR"); editor != "" {
 		return editor
@@ -0,0 +1,23 @@
+// ABOUTME: Tests for $EDITOR resolution when opening files
+// ABOUTME: Verifies editor arguments are split and the file path is appended
+
+package ide
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestEditFileCommand(t *testing.T) {
+	t.Setenv("EDITOR", "code --wait")
+	cmd := EditFileCommand("/tmp/CLAUDE.md")
+	if want := []string{"code", "--wait", "/tmp/CLAUDE.md"}; !slices.Equal(cmd.Args, want) {
+		t.Errorf("args = %v, want %v", cmd.Args, want)
+	}
+
+	t.Setenv("EDITOR", "")
+	t.Setenv("VISUAL", "")
+	if cmd := EditFileCommand("f.md"); !slices.Equal(cmd.Args, []string{"vi", "f.md"}) {
+		t.Errorf("fallback args = %v", cmd.Args)
+	}
+}
@@ -1,5 +1,5 @@
-// ABOUTME: Parse @file#line-line syntax from user input
-// ABOUTME: Resolves file paths relative to workDir and extracts line ranges
+// ABOUTME: Parse @file#line-line, @dir/, and @Symbol syntax from user input
+// ABOUTME: Resolves paths relative to workDir, summarizes directories, and looks up symbols through a caller's lookup
 
 package ide
 
@@ -17,13 +17,41 @@ type FileMention struct {
 	Path      string
 	StartLine int // 0 if not specified
 	EndLine   int // 0 if not specified
+	IsDir     bool
+	Symbol    string // set when the mention named a symbol, not a path
 }
 
-var mentionRegex = regexp.MustCompile(`@([\w./_-]+(?:#\d+(?:-\d+)?)?)`)
+// Symbol is a definition a @Name mention expands to.
+type Symbol struct {
+	Path      string
+	Kind      string // func, method, struct, ...
+	Name      string
+	StartLine int
+	EndLine   int
+	Others    int // further definitions of the same name, not expanded
+}
+
+// SymbolLookup resolves a function, method (Type.Method), or type name to
+// its definition, reporting false when there is none.
+type SymbolLookup func(name string) (Symbol, bool)
+
+// maxDirEntries caps the entries listed for a @dir/ mention.
+const maxDirEntries = 200
+
+var (
+	mentionRegex = regexp.MustCompile(`@(?:"([^"\n]+)"|([\w./_-]+(?:#\d+(?:-\d+)?)?))`)
+	symbolRegex  = regexp.MustCompile(`^[A-Za-z_]\w*(?:\.[A-Za-z_]\w*)?$`)
+)
 
 // ParseMentions extracts all @file#line-line references from input text.
-// Returns the cleaned text and parsed mentions.
-func ParseMentions(input, workDir string) (string, []FileMention, error) {
+// A path with spaces or other characters is quoted: @"My Notes/a b.txt".
+// A mention of a directory expands to a listing of it. A mention that is
+// not a path, such as @ParseMentions or @Model.Update, is looked up with
+// symbols and expands to that definition's source; a nil symbols skips
+// these. A file secret reports true for, such as .env, is named but not
+// inlined; a nil secret inlines every file. Returns the cleaned text and
+// parsed mentions.
+func ParseMentions(input, workDir string, symbols SymbolLookup, secret func(path string) bool) (string, []FileMention, error) {
 	matches := mentionRegex.FindAllStringSubmatchIndex(input, -1)
 	if len(matches) == 0 {
 		return input, nil, nil
@@ -36,20 +64,59 @@ func ParseMentions(input, workDir string) (string, []FileMention, error) {
 	for i := len(matches) - 1; i >= 0; i-- {
 		fullStart := matches[i][0]
 		fullEnd := matches[i][1]
-		ref := input[matches[i][2]:matches[i][3]]
+		quoted := matches[i][2] >= 0
+		var mention FileMention
+		var ref string
+		if quoted {
+			// A quoted path is taken whole: it has no line range
+			ref = input[matches[i][2]:matches[i][3]]
+			mention = FileMention{Path: ref}
+			if !filepath.IsAbs(ref) {
+				mention.Path = filepath.Join(workDir, ref)
+			}
+		} else {
+			ref = input[matches[i][4]:matches[i][5]]
+			var err error
+			mention, err = parseRef(ref, workDir)
+			if err != nil {
+				continue // Skip invalid mentions
+			}
+		}
 
-		mention, err := parseRef(ref, workDir)
-		if err != nil {
-			continue // Skip invalid mentions
+		info, statErr := os.Stat(mention.Path)
+		switch {
+		case statErr == nil && info.IsDir() && mention.StartLine == 0:
+			mention.IsDir = true
+			mentions = append([]FileMention{mention}, mentions...)
+			cleaned = cleaned[:fullStart] + dirSummary(mention.Path) + cleaned[fullEnd:]
+			continue
+		case statErr != nil && !quoted && symbols != nil && symbolRegex.MatchString(ref) && !afterWord(input, fullStart):
+			if sym, ok := symbols(ref); ok {
+				mentions = append([]FileMention{{Path: sym.Path, StartLine: sym.StartLine, EndLine: sym.EndLine, Symbol: ref}}, mentions...)
+				cleaned = cleaned[:fullStart] + symbolSource(sym) + cleaned[fullEnd:]
+				continue
+			}
 		}
 
 		mentions = append([]FileMention{mention}, mentions...)
 
+		if secret != nil && secret(mention.Path) {
+			withheld := fmt.Sprintf("\n[File: %s (secret file; not included)]\n", mention.Path)
+			cleaned = cleaned[:fullStart] + withheld + cleaned[fullEnd:]
+			continue
+		}
+
 		// Build replacement content
 		content, err := readMentionContent(mention)
 		if err != nil {
 			continue
 		}
+		if strings.ContainsRune(content[:min(len(content), 8000)], 0) {
+			// Binary files, such as dropped images, are named, not inlined
+			binary := fmt.Sprintf("\n[File: %s (binary, %d bytes; not included)]\n", mention.Path, len(content))
+			cleaned = cleaned[:fullStart] + binary + cleaned[fullEnd:]
+			continue
+		}
 
 		replacement := fmt.Sprintf("\n[File: %s", mention.Path)
 		if mention.StartLine > 0 {
@@ -63,6 +130,80 @@ func ParseMentions(input, workDir string) (string, []FileMention, error) {
 	return cleaned, mentions, nil
 }
 
+// afterWord reports whether the "@" at i follows a word character, as in an
+// e-mail address, which is not looked up as a symbol.
+func afterWord(input string, i int) bool {
+	if i == 0 {
+		return false
+	}
+	c := input[i-1]
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// symbolSource returns the replacement for a symbol mention: the lines of
+// its definition.
+func symbolSource(sym Symbol) string {
+	content, err := readMentionContent(FileMention{Path: sym.Path, StartLine: sym.StartLine, EndLine: sym.EndLine})
+	if err != nil {
+		return fmt.Sprintf("\n[Symbol: %s %s not readable: %v]\n", sym.Kind, sym.Name, err)
+	}
+	header := fmt.Sprintf("\n[Symbol: %s %s in %s#%d-%d", sym.Kind, sym.Name, sym.Path, sym.StartLine, sym.EndLine)
+	if sym.Others > 0 {
+		header += fmt.Sprintf("; %d more definitions with this name", sym.Others)
+	}
+	return header + "]\n```\n" + content + "\n```\n"
+}
+
+// dirSummary returns the replacement for a directory mention: its entries,
+// directories first, with each directory's entry count and each file's size.
+func dirSummary(dir string) string {
+	list, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Sprintf("\n[Directory: %s not readable: %v]\n", dir, err)
+	}
+	var dirs, files []string
+	for _, e := range list {
+		if e.Name() == ".git" {
+			continue
+		}
+		if e.IsDir() {
+			sub, _ := os.ReadDir(filepath.Join(dir, e.Name()))
+			dirs = append(dirs, fmt.Sprintf("%s/ (%d entries)", e.Name(), len(sub)))
+			continue
+		}
+		size := "?"
+		if info, err := e.Info(); err == nil {
+			size = formatSize(info.Size())
+		}
+		files = append(files, fmt.Sprintf("%s (%s)", e.Name(), size))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n[Directory: %s, %d directories, %d files]\n```\n", dir, len(dirs), len(files))
+	lines := append(dirs, files...)
+	for i, line := range lines {
+		if i == maxDirEntries {
+			fmt.Fprintf(&b, "... %d more entries\n", len(lines)-maxDirEntries)
+			break
+		}
+		b.WriteString(line + "\n")
+	}
+	b.WriteString("```\n")
+	return b.String()
+}
+
+// formatSize formats a byte count as B, KB, or MB.
+func formatSize(n int64) string {
+	switch {
+	case n < 1024:
+		return fmt.Sprintf("%d B", n)
+	case n < 1024*1024:
+		return fmt.Sprintf("%.1f KB", float64(n)/1024)
+	default:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1024*1024))
+	}
+}
+
 func parseRef(ref, workDir string) (FileMention, error) {
 	parts := strings.SplitN(ref, "#", 2)
 	path := parts[0]
@@ -13,7 +13,7 @@ import (
 func TestParseMentions_NoMentions(t *testing.T) {
 	t.Parallel()
 
-	cleaned, mentions, err := ParseMentions("just a normal prompt", "/tmp")
+	cleaned, mentions, err := ParseMentions("just a normal prompt", "/tmp", nil, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -34,7 +34,7 @@ func TestParseMentions_WithFile(t *testing.T) {
 	}
 
 	input := "explain @test.go#2-4"
-	cleaned, mentions, err := ParseMentions(input, dir)
+	cleaned, mentions, err := ParseMentions(input, dir, nil, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -52,6 +52,70 @@ func TestParseMentions_WithFile(t *testing.T) {
 	}
 }
 
+func TestParseMentions_Directory(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	for _, name := range []string{"api/handlers/a.go", "api/router.go", "api/.git/HEAD"} {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte("package api\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cleaned, mentions, err := ParseMentions("summarize @api/ please", dir, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mentions) != 1 || !mentions[0].IsDir {
+		t.Fatalf("mentions = %+v; want one directory", mentions)
+	}
+	for _, want := range []string{"1 directories, 1 files", "handlers/ (1 entries)", "router.go (12 B)"} {
+		if !strings.Contains(cleaned, want) {
+			t.Errorf("cleaned = %q; want it to contain %q", cleaned, want)
+		}
+	}
+	if strings.Contains(cleaned, ".git") {
+		t.Errorf("cleaned = %q; .git should not be listed", cleaned)
+	}
+}
+
+func TestParseMentions_Symbol(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "run.go")
+	if err := os.WriteFile(path, []byte("package x\n\nfunc Run() {\n\tgo()\n}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var asked []string
+	lookup := func(name string) (Symbol, bool) {
+		asked = append(asked, name)
+		if name != "Run" {
+			return Symbol{}, false
+		}
+		return Symbol{Path: path, Kind: "func", Name: "Run", StartLine: 3, EndLine: 5, Others: 1}, true
+	}
+
+	cleaned, mentions, err := ParseMentions("why does @Run fail? mail me@example.com or see @Missing", dir, lookup, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(cleaned, "[Symbol: func Run in "+path+"#3-5; 1 more definitions with this name]\n```\nfunc Run() {\n\tgo()\n}\n```") {
+		t.Errorf("cleaned = %q; want Run's source", cleaned)
+	}
+	if !strings.Contains(cleaned, "@Missing") {
+		t.Errorf("cleaned = %q; an unknown name should stay as typed", cleaned)
+	}
+	if strings.Join(asked, ",") != "Missing,Run" {
+		t.Errorf("looked up %v; e-mail addresses should not be looked up", asked)
+	}
+	if len(mentions) < 1 || mentions[0].Symbol != "Run" || mentions[0].StartLine != 3 {
+		t.Errorf("mentions = %+v", mentions)
+	}
+}
+
 func TestParseRef_PathOnly(t *testing.T) {
 	t.Parallel()
 
@@ -91,3 +155,54 @@ func TestUnifiedDiff(t *testing.T) {
 		t.Error("expected added line in diff")
 	}
 }
+
+func TestParseMentions_QuotedAndBinary(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "My Notes.txt"), []byte("remember#1"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "logo.png"), []byte("\x89PNG\x00\x00"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cleaned, mentions, err := ParseMentions(`read @"My Notes.txt" and @logo.png`, dir, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mentions) != 2 || mentions[0].Path != filepath.Join(dir, "My Notes.txt") {
+		t.Fatalf("mentions = %+v", mentions)
+	}
+	if !strings.Contains(cleaned, "remember#1") {
+		t.Errorf("cleaned = %q; want the quoted file's content", cleaned)
+	}
+	if !strings.Contains(cleaned, "logo.png (binary, 6 bytes; not included)") || strings.Contains(cleaned, "PNG") {
+		t.Errorf("cleaned = %q; a binary file should be named, not inlined", cleaned)
+	}
+}
+
+func TestParseMentions_WithholdsSecrets(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("API_KEY=hunter2"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	secret := func(p string) bool { return filepath.Base(p) == ".env" }
+
+	cleaned, mentions, err := ParseMentions("compare @.env with @main.go", dir, nil, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mentions) != 2 {
+		t.Fatalf("mentions = %+v", mentions)
+	}
+	if strings.Contains(cleaned, "hunter2") || !strings.Contains(cleaned, ".env (secret file; not included)") {
+		t.Errorf("cleaned = %q; the secret file should be named, not inlined", cleaned)
+	}
+	if !strings.Contains(cleaned, "package main") {
+		t.Errorf("cleaned = %q; want the other file's content", cleaned)
+	}
+}
@@ -0,0 +1,229 @@
+// ABOUTME: Live IDE link: newline-delimited JSON-RPC listener on a per-process Unix socket
+// ABOUTME: Editors push the active file + selection; the next prompt receives it as context
+
+package ide
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Link JSON-RPC methods understood by the listener.
+const (
+	MethodSelectionChanged = "ide/selectionChanged"
+	MethodSelectionCleared = "ide/selectionCleared"
+	MethodPing             = "ping"
+)
+
+// maxSelectionContext caps the selected text injected into a prompt.
+const maxSelectionContext = 8 * 1024
+
+// Selection is the editor state pushed by an IDE extension.
+type Selection struct {
+	File      string `json:"file"`
+	StartLine int    `json:"startLine,omitempty"` // 1-based; 0 when nothing is selected
+	EndLine   int    `json:"endLine,omitempty"`
+	Text      string `json:"text,omitempty"`
+}
+
+// LockInfo is written next to the socket so extensions can discover it by workspace.
+type LockInfo struct {
+	PID       int    `json:"pid"`
+	Workspace string `json:"workspace"`
+	Socket    string `json:"socket"`
+	Protocol  string `json:"protocol"`
+}
+
+// Link accepts IDE connections and tracks the most recent editor selection.
+type Link struct {
+	ln         net.Listener
+	socketPath string
+	lockPath   string
+
+	mu       sync.Mutex
+	current  *Selection
+	consumed bool
+
+	wg sync.WaitGroup
+}
+
+type rpcMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// ListenLink starts a listener at dir/<pid>.sock and writes dir/<pid>.json
+// describing it for the given workspace.
+func ListenLink(dir, workspace string) (*Link, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("creating ide dir: %w", err)
+	}
+	pid := os.Getpid()
+	socketPath := filepath.Join(dir, fmt.Sprintf("%d.sock", pid))
+	_ = os.Remove(socketPath) // stale socket from a crashed process with the same pid
+
+	ln, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("listening on %s: %w", socketPath, err)
+	}
+
+	lockPath := filepath.Join(dir, fmt.Sprintf("%d.json", pid))
+	lock, _ := json.Marshal(LockInfo{PID: pid, Workspace: workspace, Socket: socketPath, Protocol: "jsonrpc-ndjson"})
+	if err := os.WriteFile(lockPath, lock, 0o600); err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("writing ide lock file: %w", err)
+	}
+
+	l := &Link{ln: ln, socketPath: socketPath, lockPath: lockPath}
+	l.wg.Add(1)
+	go l.acceptLoop()
+	return l, nil
+}
+
+// SocketPath returns the Unix socket the link listens on.
+func (l *Link) SocketPath() string {
+	return l.socketPath
+}
+
+// Selection returns the latest editor selection, if any.
+func (l *Link) Selection() (Selection, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.current == nil {
+		return Selection{}, false
+	}
+	return *l.current, true
+}
+
+// TakeContext returns a prompt context block for the latest selection and
+// marks it consumed, so an unchanged selection is injected only once.
+func (l *Link) TakeContext() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.current == nil || l.consumed {
+		return ""
+	}
+	l.consumed = true
+	return FormatSelectionContext(*l.current)
+}
+
+// Close stops the listener and removes the socket and lock file.
+func (l *Link) Close() error {
+	err := l.ln.Close()
+	l.wg.Wait()
+	_ = os.Remove(l.lockPath)
+	_ = os.Remove(l.socketPath)
+	return err
+}
+
+func (l *Link) acceptLoop() {
+	defer l.wg.Done()
+	for {
+		conn, err := l.ln.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+		go l.handleConn(conn)
+	}
+}
+
+func (l *Link) handleConn(conn net.Conn) {
+	defer conn.Close()
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	enc := json.NewEncoder(conn)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var msg rpcMessage
+		if err := json.Unmarshal([]byte(line), &msg); err != nil {
+			_ = enc.Encode(rpcResponse{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{Code: -32700, Message: "parse error"}})
+			continue
+		}
+		result, rerr := l.dispatch(msg)
+		if len(msg.ID) == 0 {
+			continue // notification
+		}
+		_ = enc.Encode(rpcResponse{JSONRPC: "2.0", ID: msg.ID, Result: result, Error: rerr})
+	}
+}
+
+func (l *Link) dispatch(msg rpcMessage) (any, *rpcError) {
+	switch msg.Method {
+	case MethodPing:
+		return "pong", nil
+	case MethodSelectionChanged:
+		var sel Selection
+		if err := json.Unmarshal(msg.Params, &sel); err != nil || sel.File == "" {
+			return nil, &rpcError{Code: -32602, Message: "params must include file"}
+		}
+		l.setSelection(&sel)
+		return true, nil
+	case MethodSelectionCleared:
+		l.setSelection(nil)
+		return true, nil
+	default:
+		return nil, &rpcError{Code: -32601, Message: fmt.Sprintf("method not found: %s", msg.Method)}
+	}
+}
+
+func (l *Link) setSelection(sel *Selection) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.current = sel
+	l.consumed = false
+}
+
+// FormatSelectionContext renders a selection as a context block for a prompt.
+func FormatSelectionContext(sel Selection) string {
+	var b strings.Builder
+	b.WriteString("[IDE context: active editor]\n")
+	fmt.Fprintf(&b, "File: %s", sel.File)
+	if sel.StartLine > 0 {
+		end := max(sel.EndLine, sel.StartLine)
+		fmt.Fprintf(&b, " (lines %d-%d)", sel.StartLine, end)
+	}
+	b.WriteByte('\n')
+	if sel.Text != "" {
+		text := sel.Text
+		if len(text) > maxSelectionContext {
+			cut := maxSelectionContext
+			for cut > 0 && !utf8.RuneStart(text[cut]) {
+				cut--
+			}
+			text = text[:cut] + "\n... (selection truncated)"
+		}
+		b.WriteString("Selection:\n```\n")
+		b.WriteString(text)
+		b.WriteString("\n```\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,114 @@
+// ABOUTME: Tests for the live IDE link listener
+// ABOUTME: Drives the Unix socket with JSON-RPC lines and checks selection context injection
+
+package ide
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func dialLink(t *testing.T, l *Link) (net.Conn, *bufio.Reader) {
+	t.Helper()
+	conn, err := net.Dial("unix", l.SocketPath())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	return conn, bufio.NewReader(conn)
+}
+
+func TestLink_SelectionChangedInjectsOnce(t *testing.T) {
+	dir := t.TempDir()
+	l, err := ListenLink(dir, "/work")
+	if err != nil {
+		t.Fatalf("ListenLink: %v", err)
+	}
+	defer l.Close()
+
+	conn, r := dialLink(t, l)
+	req := `{"jsonrpc":"2.0","id":1,"method":"ide/selectionChanged","params":{"file":"/work/main.go","startLine":3,"endLine":5,"text":"func main() {}"}}` + "\n"
+	if _, err := conn.Write([]byte(req)); err != nil {
+		t.Fatal(err)
+	}
+	line, err := r.ReadString('\n')
+	if err != nil {
+		t.Fatalf("reading response: %v", err)
+	}
+	if !strings.Contains(line, `"result":true`) {
+		t.Fatalf("unexpected response: %s", line)
+	}
+
+	ctx := l.TakeContext()
+	for _, want := range []string{"File: /work/main.go (lines 3-5)", "func main() {}"} {
+		if !strings.Contains(ctx, want) {
+			t.Errorf("context missing %q:\n%s", want, ctx)
+		}
+	}
+	if again := l.TakeContext(); again != "" {
+		t.Errorf("unchanged selection should be injected once, got %q", again)
+	}
+}
+
+func TestLink_UnknownMethodAndPing(t *testing.T) {
+	l, err := ListenLink(t.TempDir(), "/work")
+	if err != nil {
+		t.Fatalf("ListenLink: %v", err)
+	}
+	defer l.Close()
+
+	conn, r := dialLink(t, l)
+	conn.Write([]byte(`{"jsonrpc":"2.0","id":"a","method":"ping"}` + "\n"))
+	conn.Write([]byte(`{"jsonrpc":"2.0","id":"b","method":"nope"}` + "\n"))
+
+	var resp rpcResponse
+	line, _ := r.ReadString('\n')
+	if err := json.Unmarshal([]byte(line), &resp); err != nil || resp.Result != "pong" {
+		t.Errorf("ping response = %s", line)
+	}
+	line, _ = r.ReadString('\n')
+	if !strings.Contains(line, "-32601") {
+		t.Errorf("expected method-not-found error, got %s", line)
+	}
+}
+
+func TestLink_LockFileLifecycle(t *testing.T) {
+	dir := t.TempDir()
+	l, err := ListenLink(dir, "/work")
+	if err != nil {
+		t.Fatalf("ListenLink: %v", err)
+	}
+
+	data, err := os.ReadFile(l.lockPath)
+	if err != nil {
+		t.Fatalf("reading lock file: %v", err)
+	}
+	var info LockInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		t.Fatal(err)
+	}
+	if info.Workspace != "/work" || info.Socket != l.SocketPath() || info.PID != os.Getpid() {
+		t.Errorf("unexpected lock info: %+v", info)
+	}
+
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if _, err := os.Stat(l.lockPath); !os.IsNotExist(err) {
+		t.Error("lock file should be removed on Close")
+	}
+}
+
+func TestFormatSelectionContext_FileOnly(t *testing.T) {
+	t.Parallel()
+	got := FormatSelectionContext(Selection{File: "/a.go"})
+	if got != "[IDE context: active editor]\nFile: /a.go\n" {
+		t.Errorf("got %q", got)
+	}
+}
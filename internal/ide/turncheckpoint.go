@@ -0,0 +1,153 @@
+// ABOUTME: Per-turn file checkpoints: snapshots taken before the agent changes a file
+// ABOUTME: Undo restores every file a turn touched to its content from before the turn
+
+package ide
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	maxCheckpointTurns = 50       // older turns are dropped and can no longer be undone
+	maxSnapshotBytes   = 10 << 20 // larger files are left as they are on undo
+)
+
+// fileSnapshot is a file as it was before the first change of a turn.
+type fileSnapshot struct {
+	path     string
+	existed  bool
+	data     []byte
+	mode     os.FileMode
+	tooLarge bool
+}
+
+// TurnCheckpoints keeps, for each agent turn, the files it changed as they
+// were before the turn, so the turn's edits can be rolled back in one step.
+// Unlike CheckpointStack it does not need git and leaves untouched files
+// alone. Methods are nil-safe and may be called from tool goroutines.
+type TurnCheckpoints struct {
+	mu    sync.Mutex
+	turns [][]fileSnapshot // oldest first
+}
+
+// NewTurnCheckpoints creates an empty checkpoint history.
+func NewTurnCheckpoints() *TurnCheckpoints {
+	return &TurnCheckpoints{}
+}
+
+// BeginTurn starts recording a new turn.
+func (c *TurnCheckpoints) BeginTurn() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.turns) == maxCheckpointTurns {
+		c.turns = c.turns[1:]
+	}
+	c.turns = append(c.turns, nil)
+}
+
+// Snapshot records path as it is now, unless the current turn already has
+// it: undo goes back to the state before the turn's first change.
+func (c *TurnCheckpoints) Snapshot(path string) error {
+	if c == nil {
+		return nil
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.turns) == 0 {
+		return nil // no turn in progress, as for tools run outside a prompt
+	}
+	turn := c.turns[len(c.turns)-1]
+	for _, snap := range turn {
+		if snap.path == path {
+			return nil
+		}
+	}
+
+	snap := fileSnapshot{path: path}
+	info, err := os.Stat(path)
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return fmt.Errorf("checkpointing %s: %w", path, err)
+	case info.Size() > maxSnapshotBytes:
+		snap.existed, snap.tooLarge = true, true
+	default:
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("checkpointing %s: %w", path, err)
+		}
+		snap.existed, snap.data, snap.mode = true, data, info.Mode().Perm()
+	}
+	c.turns[len(c.turns)-1] = append(turn, snap)
+	return nil
+}
+
+// Turns returns how many turns can be undone.
+func (c *TurnCheckpoints) Turns() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.turns)
+}
+
+// Undo removes the latest turn and puts its files back: changed files get
+// their old content and files the turn created are deleted. It returns one
+// line per file; files that cannot be restored are reported in the error
+// while the rest are still restored.
+func (c *TurnCheckpoints) Undo() ([]string, error) {
+	if c == nil {
+		return nil, nil
+	}
+	c.mu.Lock()
+	if len(c.turns) == 0 {
+		c.mu.Unlock()
+		return nil, nil
+	}
+	turn := c.turns[len(c.turns)-1]
+	c.turns = c.turns[:len(c.turns)-1]
+	c.mu.Unlock()
+
+	var summary []string
+	var errs []error
+	for _, snap := range turn {
+		switch {
+		case snap.tooLarge:
+			summary = append(summary, fmt.Sprintf("left %s as is (too large to checkpoint)", snap.path))
+		case !snap.existed:
+			if err := os.Remove(snap.path); err != nil && !os.IsNotExist(err) {
+				errs = append(errs, fmt.Errorf("removing %s: %w", snap.path, err))
+				continue
+			}
+			summary = append(summary, fmt.Sprintf("removed %s", snap.path))
+		default:
+			if err := os.WriteFile(snap.path, snap.data, snap.mode); err != nil {
+				errs = append(errs, fmt.Errorf("restoring %s: %w", snap.path, err))
+				continue
+			}
+			summary = append(summary, fmt.Sprintf("restored %s", snap.path))
+		}
+	}
+	return summary, errors.Join(errs...)
+}
+
+// Reset forgets every turn, as when the conversation is replaced.
+func (c *TurnCheckpoints) Reset() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.turns = nil
+}
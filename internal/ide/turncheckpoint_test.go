@@ -0,0 +1,88 @@
+// ABOUTME: Tests for per-turn file checkpoints: snapshot once per turn, undo newest turn first
+// ABOUTME: Files a turn created are deleted on undo; changed files get their old content back
+
+package ide
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTurnCheckpoints_UndoRestoresTurn(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	edited := filepath.Join(dir, "main.go")
+	created := filepath.Join(dir, "new.go")
+	if err := os.WriteFile(edited, []byte("v1"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewTurnCheckpoints()
+	if err := c.Snapshot(edited); err != nil || c.Turns() != 0 {
+		t.Fatalf("Snapshot outside a turn = %v, turns %d", err, c.Turns())
+	}
+
+	c.BeginTurn()
+	_ = c.Snapshot(edited)
+	_ = os.WriteFile(edited, []byte("v2"), 0o644)
+
+	c.BeginTurn()
+	_ = c.Snapshot(edited)
+	_ = os.WriteFile(edited, []byte("v3"), 0o644)
+	_ = c.Snapshot(edited) // a second change in the same turn keeps v2
+	_ = os.WriteFile(edited, []byte("v4"), 0o644)
+	_ = c.Snapshot(created)
+	_ = os.WriteFile(created, []byte("package main"), 0o644)
+
+	summary, err := c.Undo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(edited); string(got) != "v2" {
+		t.Errorf("main.go = %q; want the content from before the turn", got)
+	}
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Error("a file the turn created should be removed")
+	}
+	if text := strings.Join(summary, "\n"); !strings.Contains(text, "restored "+edited) || !strings.Contains(text, "removed "+created) {
+		t.Errorf("summary = %q", text)
+	}
+
+	if _, err := c.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(edited); string(got) != "v1" {
+		t.Errorf("main.go = %q; want v1 after undoing both turns", got)
+	}
+	if summary, err := c.Undo(); summary != nil || err != nil || c.Turns() != 0 {
+		t.Errorf("Undo with no turns = %v, %v", summary, err)
+	}
+}
+
+func TestTurnCheckpoints_ResetAndNil(t *testing.T) {
+	t.Parallel()
+
+	c := NewTurnCheckpoints()
+	for range maxCheckpointTurns + 5 {
+		c.BeginTurn()
+	}
+	if c.Turns() != maxCheckpointTurns {
+		t.Errorf("Turns() = %d; want the oldest dropped at %d", c.Turns(), maxCheckpointTurns)
+	}
+	c.Reset()
+	if c.Turns() != 0 {
+		t.Errorf("Turns() after Reset = %d", c.Turns())
+	}
+
+	var none *TurnCheckpoints
+	none.BeginTurn()
+	if err := none.Snapshot("x"); err != nil {
+		t.Errorf("nil Snapshot: %v", err)
+	}
+	if summary, err := none.Undo(); summary != nil || err != nil {
+		t.Errorf("nil Undo = %v, %v", summary, err)
+	}
+}
@@ -0,0 +1,70 @@
+// ABOUTME: Loggers carrying structured attributes, and request IDs threaded through a context
+// ABOUTME: One request ID per LLM call ties the call's log records to the tool executions it asked for
+
+package log
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// Logger logs like the package functions, adding its attributes to every
+// record. The zero value adds none.
+type Logger struct {
+	attrs []any
+}
+
+// With returns a logger adding the key/value pairs attrs.
+func With(attrs ...any) *Logger {
+	return &Logger{attrs: attrs}
+}
+
+// With returns a copy of l adding attrs to its own.
+func (l *Logger) With(attrs ...any) *Logger {
+	merged := make([]any, 0, len(l.attrs)+len(attrs))
+	return &Logger{attrs: append(append(merged, l.attrs...), attrs...)}
+}
+
+// Debug logs a debug message with l's attributes.
+func (l *Logger) Debug(format string, args ...any) { emit(LevelDebug, l.attrs, format, args) }
+
+// Info logs an info message with l's attributes.
+func (l *Logger) Info(format string, args ...any) { emit(LevelInfo, l.attrs, format, args) }
+
+// Warn logs a warning with l's attributes.
+func (l *Logger) Warn(format string, args ...any) { emit(LevelWarn, l.attrs, format, args) }
+
+// Error logs an error with l's attributes.
+func (l *Logger) Error(format string, args ...any) { emit(LevelError, l.attrs, format, args) }
+
+type requestIDKey struct{}
+
+// NewRequestID returns a short random ID for one LLM call.
+func NewRequestID() string {
+	b := make([]byte, 6)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// WithRequestID returns ctx carrying id.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID ctx carries, or "". ctx may be nil.
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// FromContext returns a logger tagging records with ctx's request ID, if any.
+func FromContext(ctx context.Context) *Logger {
+	if id := RequestID(ctx); id != "" {
+		return With("request_id", id)
+	}
+	return &Logger{}
+}
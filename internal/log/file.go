@@ -0,0 +1,205 @@
+// ABOUTME: Size-rotated log files: <name>.log rolls over to <name>.log.1, .2, ... keeping a few backups
+// ABOUTME: OpenFile attaches one as the structured sink; Tail reads its end and PruneDir drops old logs
+
+package log
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults for per-session log files.
+const (
+	DefaultMaxSize = 10 << 20 // bytes before a log rotates
+	DefaultBackups = 3        // rotated files kept
+	DefaultMaxAge  = 30 * 24 * time.Hour
+)
+
+// RotatingFile is an append-only file that rotates once it reaches
+// MaxSize bytes. It is safe for concurrent use.
+type RotatingFile struct {
+	path    string
+	maxSize int64
+	backups int
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+}
+
+// NewRotatingFile opens path for appending, creating it and its directory.
+func NewRotatingFile(path string, maxSize int64, backups int) (*RotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("creating log directory: %w", err)
+	}
+	r := &RotatingFile{path: path, maxSize: maxSize, backups: backups}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *RotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("opening log file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("stat log file: %w", err)
+	}
+	r.f, r.size = f, info.Size()
+	return nil
+}
+
+// Path returns the file being written.
+func (r *RotatingFile) Path() string { return r.path }
+
+// Write appends p, rotating first when p would take the file past MaxSize.
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		return 0, os.ErrClosed
+	}
+	if r.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// rotate shifts <path>.N to <path>.N+1, dropping the oldest, and reopens
+// an empty <path>.
+func (r *RotatingFile) rotate() error {
+	if err := r.f.Close(); err != nil {
+		return fmt.Errorf("closing log file: %w", err)
+	}
+	if r.backups > 0 {
+		_ = os.Remove(fmt.Sprintf("%s.%d", r.path, r.backups))
+		for i := r.backups - 1; i >= 1; i-- {
+			_ = os.Rename(fmt.Sprintf("%s.%d", r.path, i), fmt.Sprintf("%s.%d", r.path, i+1))
+		}
+		if err := os.Rename(r.path, r.path+".1"); err != nil {
+			return fmt.Errorf("rotating log file: %w", err)
+		}
+	} else if err := os.Truncate(r.path, 0); err != nil {
+		return fmt.Errorf("truncating log file: %w", err)
+	}
+	return r.open()
+}
+
+// Close closes the file; later writes fail.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		return nil
+	}
+	err := r.f.Close()
+	r.f = nil
+	return err
+}
+
+// current is the file OpenFile attached, for Path.
+var (
+	currentMu sync.Mutex
+	current   *RotatingFile
+)
+
+// OpenFile opens a rotating log at path with the default limits and
+// attaches it as the sink. The returned func detaches and closes it.
+func OpenFile(path string) (func(), error) {
+	r, err := NewRotatingFile(path, DefaultMaxSize, DefaultBackups)
+	if err != nil {
+		return nil, err
+	}
+	currentMu.Lock()
+	current = r
+	currentMu.Unlock()
+	SetOutput(r)
+	return func() {
+		SetOutput(nil)
+		currentMu.Lock()
+		if current == r {
+			current = nil
+		}
+		currentMu.Unlock()
+		_ = r.Close()
+	}, nil
+}
+
+// Path returns the log file OpenFile attached, or "" when logs only go to
+// stderr.
+func Path() string {
+	currentMu.Lock()
+	defer currentMu.Unlock()
+	if current == nil {
+		return ""
+	}
+	return current.Path()
+}
+
+// PruneDir removes log files in dir, rotated ones included, and .llm wire
+// dump directories last written more than maxAge ago. Errors are ignored:
+// pruning is best effort.
+func PruneDir(dir string, maxAge time.Duration) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	cutoff := time.Now().Add(-maxAge)
+	for _, e := range entries {
+		isLog := !e.IsDir() && strings.Contains(e.Name(), ".log")
+		isDump := e.IsDir() && strings.HasSuffix(e.Name(), ".llm")
+		if !isLog && !isDump {
+			continue
+		}
+		if info, err := e.Info(); err == nil && info.ModTime().Before(cutoff) {
+			_ = os.RemoveAll(filepath.Join(dir, e.Name()))
+		}
+	}
+}
+
+// tailWindow bounds how much of a log Tail reads from the end.
+const tailWindow = 256 << 10
+
+// Tail returns up to the last n lines of the file at path.
+func Tail(path string, n int) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening log file: %w", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("stat log file: %w", err)
+	}
+	offset := max(info.Size()-tailWindow, 0)
+	buf := make([]byte, info.Size()-offset)
+	if _, err := f.ReadAt(buf, offset); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("reading log file: %w", err)
+	}
+	text := strings.TrimRight(string(buf), "\n")
+	if text == "" {
+		return nil, nil
+	}
+	lines := strings.Split(text, "\n")
+	if offset > 0 {
+		lines = lines[1:] // the first line may be cut
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines, nil
+}
@@ -0,0 +1,136 @@
+// ABOUTME: Tests for the structured file sink, request-ID loggers, rotation, tailing, and pruning
+// ABOUTME: Log files live in tempdirs; the sink is global, so sink tests do not run in parallel
+
+package log
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOpenFile_StructuredRecords(t *testing.T) {
+	savedLevel := GetLevel()
+	defer SetLevel(savedLevel)
+	SetLevel(LevelInfo)
+
+	path := filepath.Join(t.TempDir(), "logs", "abc.log")
+	closeLog, err := OpenFile(path)
+	if err != nil {
+		t.Fatalf("OpenFile: %v", err)
+	}
+	if Path() != path {
+		t.Errorf("Path = %q; want %q", Path(), path)
+	}
+
+	ctx := WithRequestID(context.Background(), "req1")
+	Debug("below the level: %d", 1)
+	FromContext(ctx).With("tool", "read").Info("tool done")
+	closeLog()
+	if Path() != "" {
+		t.Errorf("Path after close = %q; want empty", Path())
+	}
+
+	lines, err := Tail(path, 10)
+	if err != nil {
+		t.Fatalf("Tail: %v", err)
+	}
+	if len(lines) != 2 {
+		t.Fatalf("lines = %q; want 2 records, debug included", lines)
+	}
+	var rec map[string]any
+	if err := json.Unmarshal([]byte(lines[1]), &rec); err != nil {
+		t.Fatalf("record %q: %v", lines[1], err)
+	}
+	if rec["msg"] != "tool done" || rec["level"] != "INFO" || rec["request_id"] != "req1" || rec["tool"] != "read" {
+		t.Errorf("record = %v", rec)
+	}
+	if !strings.Contains(lines[0], "below the level: 1") {
+		t.Errorf("debug record = %q", lines[0])
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	t.Parallel()
+	if RequestID(context.Background()) != "" {
+		t.Error("empty context should carry no request ID")
+	}
+	a, b := NewRequestID(), NewRequestID()
+	if a == "" || a == b {
+		t.Errorf("NewRequestID = %q, %q; want distinct IDs", a, b)
+	}
+	if got := RequestID(WithRequestID(context.Background(), a)); got != a {
+		t.Errorf("RequestID = %q; want %q", got, a)
+	}
+}
+
+func TestRotatingFile_Rotates(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "s.log")
+	r, err := NewRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := r.Write([]byte(line)); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	r.Close()
+
+	for name, want := range map[string]string{"s.log": "fourth\n", "s.log.1": "third\n", "s.log.2": "second\n"} {
+		got, err := os.ReadFile(filepath.Join(filepath.Dir(path), name))
+		if err != nil || string(got) != want {
+			t.Errorf("%s = %q (%v); want %q", name, got, err, want)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Error("only 2 backups should be kept")
+	}
+}
+
+func TestTail_LastLines(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "t.log")
+	if err := os.WriteFile(path, []byte("a\nb\nc\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	lines, err := Tail(path, 2)
+	if err != nil || strings.Join(lines, ",") != "b,c" {
+		t.Errorf("Tail = %q, %v; want b,c", lines, err)
+	}
+}
+
+func TestPruneDir(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	old, fresh := filepath.Join(dir, "old.log.1"), filepath.Join(dir, "new.log")
+	for _, p := range []string{old, fresh} {
+		if err := os.WriteFile(p, nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	oldDump := filepath.Join(dir, "old.llm")
+	if err := os.MkdirAll(oldDump, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	past := time.Now().Add(-48 * time.Hour)
+	for _, p := range []string{old, oldDump} {
+		if err := os.Chtimes(p, past, past); err != nil {
+			t.Fatal(err)
+		}
+	}
+	PruneDir(dir, 24*time.Hour)
+	for _, p := range []string{old, oldDump} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Errorf("%s should be pruned", filepath.Base(p))
+		}
+	}
+	if _, err := os.Stat(fresh); err != nil {
+		t.Errorf("fresh log should stay: %v", err)
+	}
+}
@@ -1,12 +1,15 @@
-// ABOUTME: Debug logging wrapper around slog for verbose mode output
-// ABOUTME: Global level via SetLevel; writes to stderr to avoid mixing with TUI
+// ABOUTME: Logging wrapper around slog: leveled printf-style output to stderr and a structured file sink
+// ABOUTME: Global level via SetLevel; with a file attached, stderr is quiet unless debugging so the TUI stays clean
 
 package log
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"sync"
 	"sync/atomic"
 )
 
@@ -20,6 +23,12 @@ const (
 
 var level atomic.Int64
 
+// sink is the structured JSON destination; nil until SetOutput.
+var (
+	sinkMu sync.RWMutex
+	sink   *slog.Logger
+)
+
 func init() {
 	level.Store(int64(LevelInfo))
 }
@@ -34,31 +43,61 @@ func GetLevel() slog.Level {
 	return slog.Level(level.Load())
 }
 
-// Debug logs a debug message if the level allows it.
-func Debug(format string, args ...any) {
-	if slog.Level(level.Load()) > LevelDebug {
+// SetOutput attaches w as the structured sink: every record, debug
+// included, is written to it as one JSON line whatever the level. While a
+// sink is attached, stderr only gets records at debug level, so warnings
+// do not draw over the TUI. A nil w detaches the sink.
+func SetOutput(w io.Writer) {
+	sinkMu.Lock()
+	defer sinkMu.Unlock()
+	if w == nil {
+		sink = nil
 		return
 	}
-	fmt.Fprintf(os.Stderr, "[DEBUG] "+format+"\n", args...)
+	sink = slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: LevelDebug}))
+}
+
+// Debug logs a debug message if the level allows it.
+func Debug(format string, args ...any) {
+	emit(LevelDebug, nil, format, args)
 }
 
 // Info logs an info message if the level allows it.
 func Info(format string, args ...any) {
-	if slog.Level(level.Load()) > LevelInfo {
-		return
-	}
-	fmt.Fprintf(os.Stderr, "[INFO] "+format+"\n", args...)
+	emit(LevelInfo, nil, format, args)
 }
 
 // Warn logs a warning message if the level allows it.
 func Warn(format string, args ...any) {
-	if slog.Level(level.Load()) > LevelWarn {
-		return
-	}
-	fmt.Fprintf(os.Stderr, "[WARN] "+format+"\n", args...)
+	emit(LevelWarn, nil, format, args)
 }
 
 // Error logs an error message (always emitted).
 func Error(format string, args ...any) {
-	fmt.Fprintf(os.Stderr, "[ERROR] "+format+"\n", args...)
+	emit(LevelError, nil, format, args)
+}
+
+// emit writes one record to the sink and, level permitting, to stderr.
+// attrs are key/value pairs; stderr shows them after the message.
+func emit(l slog.Level, attrs []any, format string, args []any) {
+	sinkMu.RLock()
+	s := sink
+	sinkMu.RUnlock()
+
+	msg := fmt.Sprintf(format, args...)
+	if s != nil {
+		s.Log(context.Background(), l, msg, attrs...)
+	}
+
+	current := GetLevel()
+	if l < current && l != LevelError {
+		return
+	}
+	if s != nil && current > LevelDebug {
+		return
+	}
+	for i := 0; i+1 < len(attrs); i += 2 {
+		msg += fmt.Sprintf(" %v=%v", attrs[i], attrs[i+1])
+	}
+	fmt.Fprintf(os.Stderr, "[%s] %s\n", l, msg)
 }
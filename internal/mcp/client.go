@@ -1,5 +1,5 @@
 // ABOUTME: MCP client implementing initialize handshake, tool listing, and tool calling
-// ABOUTME: Handles resource listing/reading and notifications/tools/list_changed
+// ABOUTME: Handles resource listing/reading (optionally cached) and notifications/tools/list_changed
 
 package mcp
 
@@ -9,6 +9,8 @@ import (
 	"fmt"
 	"sync"
 	"time"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/fetchcache"
 )
 
 // Client communicates with a single MCP server.
@@ -21,6 +23,7 @@ type Client struct {
 
 	mu        sync.RWMutex
 	connected bool
+	cache     *fetchcache.Store // nil: resources are always read from the server
 
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -158,8 +161,43 @@ func (c *Client) ListResources(ctx context.Context) ([]Resource, error) {
 	return result.Resources, nil
 }
 
-// ReadResource reads a resource from the server.
+// SetResourceCache makes ReadResource serve repeated reads from s.
+// Nil disables caching.
+func (c *Client) SetResourceCache(s *fetchcache.Store) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cache = s
+}
+
+// ReadResource reads a resource from the server, or from the resource
+// cache while a previous read is fresh.
 func (c *Client) ReadResource(ctx context.Context, uri string) (ResourceContent, error) {
+	c.mu.RLock()
+	cache, key := c.cache, "mcp://"+c.serverInfo.Name+"/"+uri
+	c.mu.RUnlock()
+
+	if cache != nil {
+		if hit := cache.Lookup(key); hit.Fresh {
+			var content ResourceContent
+			if json.Unmarshal([]byte(hit.Content), &content) == nil {
+				return content, nil
+			}
+		}
+	}
+
+	content, err := c.readResource(ctx, uri)
+	if err != nil {
+		return ResourceContent{}, err
+	}
+	if cache != nil {
+		if data, err := json.Marshal(content); err == nil {
+			cache.Put(key, string(data), fetchcache.Validators{})
+		}
+	}
+	return content, nil
+}
+
+func (c *Client) readResource(ctx context.Context, uri string) (ResourceContent, error) {
 	params, _ := json.Marshal(map[string]any{"uri": uri})
 
 	resp, err := c.transport.Send(ctx, &Request{
@@ -17,6 +17,7 @@ import (
 	"time"
 
 	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
+	"github.com/mauromedda/pi-coding-agent-go/internal/fetchcache"
 )
 
 // mockTransport implements Transport for testing without spawning processes.
@@ -205,6 +206,46 @@ func TestClient_ListResources(t *testing.T) {
 	}
 }
 
+func TestClient_ReadResource_Cached(t *testing.T) {
+	reads := 0
+	mt := newMockTransport(func(req *Request) *Response {
+		switch req.Method {
+		case "initialize":
+			result, _ := json.Marshal(InitializeResult{ProtocolVersion: "2024-11-05", ServerInfo: ServerInfo{Name: "docs"}})
+			return &Response{ID: req.ID, Result: result}
+		case "resources/read":
+			reads++
+			result, _ := json.Marshal(map[string]any{
+				"contents": []ResourceContent{{URI: "file:///readme.md", MimeType: "text/markdown", Text: "# Readme"}},
+			})
+			return &Response{ID: req.ID, Result: result}
+		default:
+			return &Response{ID: req.ID, Error: &RPCError{Code: -32601, Message: "unknown"}}
+		}
+	})
+
+	c := NewClient(mt)
+	_ = c.Connect(context.Background())
+	cache := fetchcache.New(fetchcache.Options{})
+	c.SetResourceCache(cache)
+
+	for range 2 {
+		rc, err := c.ReadResource(context.Background(), "file:///readme.md")
+		if err != nil {
+			t.Fatalf("ReadResource: %v", err)
+		}
+		if rc.Text != "# Readme" || rc.MimeType != "text/markdown" {
+			t.Errorf("content = %+v", rc)
+		}
+	}
+	if reads != 1 {
+		t.Errorf("server reads = %d, want 1 (second served from cache)", reads)
+	}
+	if !cache.Lookup("mcp://docs/file:///readme.md").Found {
+		t.Error("cache key should include the server name")
+	}
+}
+
 // --- Config tests ---
 
 func TestLoadConfig_Empty(t *testing.T) {
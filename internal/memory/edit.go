@@ -0,0 +1,87 @@
+// ABOUTME: Writable memory targets: project PI.md/CLAUDE.md and user CLAUDE.md files for the '#' shortcut and /memory
+// ABOUTME: Appends remembered facts as bullet lines, creating the file and its directory on first use
+
+package memory
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Scope selects which memory file a fact is written to.
+type Scope string
+
+const (
+	ScopeProject Scope = "project" // ./PI.md or ./CLAUDE.md, shared with the repository
+	ScopeUser    Scope = "user"    // ~/.claude/CLAUDE.md, applies to every project
+)
+
+// Target is a memory file that can be edited or appended to.
+type Target struct {
+	Scope  Scope
+	Path   string
+	Exists bool
+}
+
+// Targets returns the project and user memory files, in that order. The
+// project target is the first existing file Load reads of ./PI.md,
+// .pi-go/PI.md, ./CLAUDE.md, and .claude/CLAUDE.md, else ./CLAUDE.md to be
+// created.
+func Targets(projectDir, homeDir string) []Target {
+	project := filepath.Join(projectDir, "CLAUDE.md")
+	for _, p := range []string{
+		filepath.Join(projectDir, "PI.md"),
+		filepath.Join(projectDir, ".pi-go", "PI.md"),
+		project,
+		filepath.Join(projectDir, ".claude", "CLAUDE.md"),
+	} {
+		if fileExists(p) {
+			project = p
+			break
+		}
+	}
+	user := filepath.Join(homeDir, ".claude", "CLAUDE.md")
+	return []Target{
+		{Scope: ScopeProject, Path: project, Exists: fileExists(project)},
+		{Scope: ScopeUser, Path: user, Exists: fileExists(user)},
+	}
+}
+
+// AppendFact appends fact to the memory file at path as a "- " bullet line,
+// creating the file and its parent directory when missing.
+func AppendFact(path, fact string) error {
+	fact = strings.Join(strings.Fields(fact), " ")
+	if fact == "" {
+		return fmt.Errorf("empty memory fact")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating memory dir: %w", err)
+	}
+
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("reading memory file: %w", err)
+	}
+	line := "- " + fact + "\n"
+	if len(existing) > 0 && existing[len(existing)-1] != '\n' {
+		line = "\n" + line
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening memory file: %w", err)
+	}
+	if _, err := f.WriteString(line); err != nil {
+		f.Close()
+		return fmt.Errorf("appending memory fact: %w", err)
+	}
+	return f.Close()
+}
+
+// fileExists reports whether path is an existing regular file.
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
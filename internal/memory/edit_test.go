@@ -0,0 +1,76 @@
+// ABOUTME: Tests for writable memory targets and fact appending
+// ABOUTME: Verifies target resolution, file creation, newline handling, and that Load sees new facts
+
+package memory
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTargets(t *testing.T) {
+	project := t.TempDir()
+	home := t.TempDir()
+
+	targets := Targets(project, home)
+	if len(targets) != 2 || targets[0].Scope != ScopeProject || targets[1].Scope != ScopeUser {
+		t.Fatalf("targets = %+v", targets)
+	}
+	if targets[0].Path != filepath.Join(project, "CLAUDE.md") || targets[0].Exists {
+		t.Errorf("project target = %+v, want a new ./CLAUDE.md", targets[0])
+	}
+	if targets[1].Path != filepath.Join(home, ".claude", "CLAUDE.md") {
+		t.Errorf("user target = %+v", targets[1])
+	}
+
+	alt := filepath.Join(project, ".claude", "CLAUDE.md")
+	if err := os.MkdirAll(filepath.Dir(alt), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(alt, []byte("rules"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := Targets(project, home)[0]; got.Path != alt || !got.Exists {
+		t.Errorf("project target = %+v, want the existing .claude/CLAUDE.md", got)
+	}
+
+	pi := filepath.Join(project, "PI.md")
+	if err := os.WriteFile(pi, []byte("memory"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := Targets(project, home)[0]; got.Path != pi {
+		t.Errorf("project target = %+v, want the existing PI.md", got)
+	}
+}
+
+func TestAppendFact(t *testing.T) {
+	project := t.TempDir()
+	home := t.TempDir()
+	path := filepath.Join(home, ".claude", "CLAUDE.md")
+
+	if err := AppendFact(path, "  use   tabs in Makefiles "); err != nil {
+		t.Fatalf("AppendFact: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("- use tabs in Makefiles\n# Style"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := AppendFact(path, "prefer table-driven tests"); err != nil {
+		t.Fatalf("AppendFact: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if want := "- use tabs in Makefiles\n# Style\n- prefer table-driven tests\n"; string(data) != want {
+		t.Errorf("file = %q, want %q", data, want)
+	}
+
+	entries, _ := Load(project, home, nil)
+	if len(entries) != 1 || !strings.Contains(entries[0].Content, "prefer table-driven tests") {
+		t.Errorf("Load after append = %+v", entries)
+	}
+
+	if err := AppendFact(path, "   "); err == nil {
+		t.Error("expected error for an empty fact")
+	}
+}
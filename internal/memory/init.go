@@ -0,0 +1,131 @@
+// ABOUTME: /init support: the prompt asking the agent to analyze a project and propose its PI.md
+// ABOUTME: The agent replies with the file between markers; nothing is written until the user approves the diff
+
+package memory
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Markers delimit the proposed PI.md in the agent's reply.
+const (
+	InitBegin = "<pi-md>"
+	InitEnd   = "</pi-md>"
+)
+
+// projectHints are files whose presence tells the agent where to look:
+// build manifests, task runners, CI, linters and formatters, and other
+// agents' instruction files.
+var projectHints = []string{
+	"go.mod", "package.json", "Cargo.toml", "pyproject.toml", "setup.py", "requirements.txt",
+	"pom.xml", "build.gradle", "build.gradle.kts", "Gemfile", "composer.json", "mix.exs",
+	"CMakeLists.txt", "meson.build", "deno.json", "*.csproj", "*.sln",
+	"Makefile", "justfile", "Taskfile.yml", "Dockerfile", "docker-compose.yml", ".devcontainer",
+	".github/workflows", ".gitlab-ci.yml", ".pre-commit-config.yaml",
+	".editorconfig", ".golangci.yml", ".golangci.yaml", ".eslintrc*", "eslint.config.*",
+	".prettierrc*", "ruff.toml", ".rubocop.yml", "rustfmt.toml", "tsconfig.json",
+	"README*", "CONTRIBUTING*", "CLAUDE.md", "AGENTS.md", ".cursorrules", ".github/copilot-instructions.md",
+}
+
+// InitTarget returns the PI.md /init writes: the existing ./PI.md or
+// .pi-go/PI.md, else ./PI.md.
+func InitTarget(projectDir string) string {
+	for _, p := range []string{
+		filepath.Join(projectDir, "PI.md"),
+		filepath.Join(projectDir, ".pi-go", "PI.md"),
+	} {
+		if fileExists(p) {
+			return p
+		}
+	}
+	return filepath.Join(projectDir, "PI.md")
+}
+
+// InitPrompt returns the prompt asking the agent to analyze the project at
+// projectDir and reply with a PI.md for target. focus, when set, is the
+// user's extra instruction. An existing target is to be improved rather
+// than replaced.
+func InitPrompt(projectDir, target, focus string) string {
+	var b strings.Builder
+	b.WriteString("Analyze this project and write its PI.md, the memory file loaded into every session here.\n\n")
+
+	if hints := presentHints(projectDir); len(hints) > 0 {
+		fmt.Fprintf(&b, "Found at the top level: %s.\n", strings.Join(hints, ", "))
+	}
+	rel, err := filepath.Rel(projectDir, target)
+	if err != nil {
+		rel = target
+	}
+	if fileExists(target) {
+		fmt.Fprintf(&b, "%s already exists: read it, keep what is still accurate, and improve the rest.\n", rel)
+	}
+
+	b.WriteString(`
+Use the read-only tools (read, find, grep, ls) to work out:
+- the languages, frameworks, and package managers in use
+- the commands that build, test (all and a single test), lint, and format, taken from manifests, Makefiles, and CI rather than guessed
+- the directory layout and where the main entry points live
+- conventions the configs and code enforce: formatting, naming, error handling, test layout, commit style
+- anything surprising a newcomer would get wrong
+
+Do not change any file. Reply with the complete PI.md between ` + InitBegin + ` and ` + InitEnd + ` lines, using these sections: Overview, Commands, Layout, Conventions, Gotchas. Keep it under 150 lines, concrete, and specific to this project; leave out advice that applies to any project.
+`)
+	if focus = strings.TrimSpace(focus); focus != "" {
+		fmt.Fprintf(&b, "\nThe user adds: %s\n", focus)
+	}
+	return b.String()
+}
+
+// ExtractInit returns the PI.md between the markers in reply, or false when
+// the reply has none.
+func ExtractInit(reply string) (string, bool) {
+	start := strings.LastIndex(reply, InitBegin)
+	if start < 0 {
+		return "", false
+	}
+	body := reply[start+len(InitBegin):]
+	end := strings.Index(body, InitEnd)
+	if end < 0 {
+		return "", false
+	}
+	body = strings.Trim(body[:end], "\n")
+	// A model may fence the file despite the markers.
+	if strings.HasPrefix(body, "```") && strings.HasSuffix(body, "```") {
+		if nl := strings.IndexByte(body, '\n'); nl >= 0 {
+			body = strings.Trim(body[nl+1:len(body)-3], "\n")
+		}
+	}
+	if strings.TrimSpace(body) == "" {
+		return "", false
+	}
+	return body + "\n", true
+}
+
+// WriteInit writes content to the PI.md at path, creating its directory.
+func WriteInit(path, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating memory dir: %w", err)
+	}
+	return os.WriteFile(path, []byte(content), 0o644)
+}
+
+// presentHints returns the projectHints found in dir, sorted.
+func presentHints(dir string) []string {
+	var found []string
+	for _, pattern := range projectHints {
+		matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+		for _, m := range matches {
+			rel, err := filepath.Rel(dir, m)
+			if err != nil {
+				continue
+			}
+			found = append(found, filepath.ToSlash(rel))
+		}
+	}
+	sort.Strings(found)
+	return found
+}
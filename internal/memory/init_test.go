@@ -0,0 +1,84 @@
+// ABOUTME: Tests for /init support: the PI.md target, the analysis prompt, and extracting the proposal
+// ABOUTME: Uses temp directories for the project; nothing touches the real working directory
+
+package memory
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInitTarget(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if got := InitTarget(dir); got != filepath.Join(dir, "PI.md") {
+		t.Errorf("InitTarget = %q; want ./PI.md for a new project", got)
+	}
+	nested := filepath.Join(dir, ".pi-go", "PI.md")
+	if err := WriteInit(nested, "# Notes\n"); err != nil {
+		t.Fatal(err)
+	}
+	if got := InitTarget(dir); got != nested {
+		t.Errorf("InitTarget = %q; want the existing .pi-go/PI.md", got)
+	}
+}
+
+func TestInitPrompt(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for _, name := range []string{"go.mod", "Makefile", "CLAUDE.md", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(dir, ".github", "workflows"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	target := InitTarget(dir)
+	prompt := InitPrompt(dir, target, "  mention the release process ")
+	for _, want := range []string{
+		"Found at the top level: .github/workflows, CLAUDE.md, Makefile, go.mod.",
+		InitBegin, InitEnd, "Do not change any file",
+		"The user adds: mention the release process\n",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt lacks %q:\n%s", want, prompt)
+		}
+	}
+	if strings.Contains(prompt, "notes.txt") || strings.Contains(prompt, "already exists") {
+		t.Errorf("prompt mentions files it should not:\n%s", prompt)
+	}
+
+	if err := WriteInit(target, "# Old\n"); err != nil {
+		t.Fatal(err)
+	}
+	if prompt := InitPrompt(dir, target, ""); !strings.Contains(prompt, "PI.md already exists") || strings.Contains(prompt, "The user adds") {
+		t.Errorf("prompt for an existing PI.md:\n%s", prompt)
+	}
+}
+
+func TestExtractInit(t *testing.T) {
+	t.Parallel()
+
+	for name, tt := range map[string]struct {
+		reply, want string
+		ok          bool
+	}{
+		"plain":     {"Here it is.\n<pi-md>\n# App\n\n## Commands\n</pi-md>\nDone.", "# App\n\n## Commands\n", true},
+		"fenced":    {"<pi-md>\n```markdown\n# App\n```\n</pi-md>", "# App\n", true},
+		"last wins": {"The format is <pi-md>...</pi-md>.\n<pi-md>\n# Real\n</pi-md>", "# Real\n", true},
+		"unclosed":  {"<pi-md>\n# App\n", "", false},
+		"empty":     {"<pi-md>\n\n</pi-md>", "", false},
+		"none":      {"I could not analyze the project.", "", false},
+	} {
+		got, ok := ExtractInit(tt.reply)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("%s: ExtractInit = %q, %v; want %q, %v", name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
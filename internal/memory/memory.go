@@ -1,14 +1,16 @@
-// ABOUTME: Memory hierarchy loading with 5-level resolution and @import expansion
-// ABOUTME: Loads CLAUDE.md, rules dirs, and auto-memory
+// ABOUTME: Memory hierarchy loading with 6-level resolution and @import expansion
+// ABOUTME: Loads PI.md, CLAUDE.md, rules dirs, and auto-memory; imports are cycle-checked and size-capped
 
 package memory
 
 import (
 	"crypto/sha256"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"unicode/utf8"
 
 	"golang.org/x/sync/errgroup"
 )
@@ -19,6 +21,7 @@ type Level int
 
 const (
 	ProjectRules     Level = iota // .pi-go/rules/*.md
+	ProjectPI                     // ./PI.md or ./.pi-go/PI.md
 	ClaudeCompat                  // ./CLAUDE.md or ./.claude/CLAUDE.md
 	ClaudeRules                   // .claude/rules/*.md
 	UserClaudeCompat              // ~/.claude/CLAUDE.md
@@ -27,6 +30,10 @@ const (
 
 const maxImportDepth = 5
 
+// maxFileBytes caps each memory file and each @import; longer files are
+// truncated with a note so one large doc cannot flood the system prompt.
+const maxFileBytes = 64 << 10
+
 // Entry represents a single loaded memory file.
 type Entry struct {
 	Source  string // File path
@@ -36,57 +43,72 @@ type Entry struct {
 }
 
 // levelCount is the number of memory hierarchy levels.
-const levelCount = 5
+const levelCount = 6
 
-// Load reads memory entries from all 5 levels in parallel, returning them sorted by level.
+// Load reads memory entries from all 6 levels in parallel, returning them sorted by level.
 // Each level writes to its own slot in a fixed array; no mutex is needed.
-func Load(projectDir, homeDir string) ([]Entry, error) {
+// @path imports must stay within projectDir and the user memory dirs
+// (~/.claude, ~/.pi-go), and a file isSecret reports is never imported;
+// isSecret may be nil.
+func Load(projectDir, homeDir string, isSecret func(path string) bool) ([]Entry, error) {
 	var levels [levelCount][]Entry
+	scope := newImportScope(projectDir, homeDir, isSecret)
 
 	var g errgroup.Group
 
 	// Level 0: Project rules (.pi-go/rules/*.md)
 	g.Go(func() error {
 		rulesDir := filepath.Join(projectDir, ".pi-go", "rules")
-		if entries, err := loadRulesDir(rulesDir, ProjectRules); err == nil {
+		if entries, err := loadRulesDir(rulesDir, ProjectRules, scope); err == nil {
 			levels[0] = entries
 		}
 		return nil
 	})
 
-	// Level 1: Claude compat project (CLAUDE.md or .claude/CLAUDE.md)
+	// Level 1: Project memory (PI.md or .pi-go/PI.md)
+	g.Go(func() error {
+		if e, ok := loadFirstFile(scope, ProjectPI,
+			filepath.Join(projectDir, "PI.md"),
+			filepath.Join(projectDir, ".pi-go", "PI.md"),
+		); ok {
+			levels[1] = []Entry{e}
+		}
+		return nil
+	})
+
+	// Level 2: Claude compat project (CLAUDE.md or .claude/CLAUDE.md)
 	g.Go(func() error {
-		if e, ok := loadFirstFile(projectDir, ClaudeCompat,
+		if e, ok := loadFirstFile(scope, ClaudeCompat,
 			filepath.Join(projectDir, "CLAUDE.md"),
 			filepath.Join(projectDir, ".claude", "CLAUDE.md"),
 		); ok {
-			levels[1] = []Entry{e}
+			levels[2] = []Entry{e}
 		}
 		return nil
 	})
 
-	// Level 2: Claude rules (.claude/rules/*.md)
+	// Level 3: Claude rules (.claude/rules/*.md)
 	g.Go(func() error {
 		claudeRulesDir := filepath.Join(projectDir, ".claude", "rules")
-		if entries, err := loadRulesDir(claudeRulesDir, ClaudeRules); err == nil {
-			levels[2] = entries
+		if entries, err := loadRulesDir(claudeRulesDir, ClaudeRules, scope); err == nil {
+			levels[3] = entries
 		}
 		return nil
 	})
 
-	// Level 3: User Claude compat (~/.claude/CLAUDE.md)
+	// Level 4: User Claude compat (~/.claude/CLAUDE.md)
 	g.Go(func() error {
-		if e, ok := loadSingleFile(filepath.Join(homeDir, ".claude", "CLAUDE.md"), UserClaudeCompat); ok {
-			levels[3] = []Entry{e}
+		if e, ok := loadSingleFile(filepath.Join(homeDir, ".claude", "CLAUDE.md"), UserClaudeCompat, scope); ok {
+			levels[4] = []Entry{e}
 		}
 		return nil
 	})
 
-	// Level 4: Auto-memory
+	// Level 5: Auto-memory
 	g.Go(func() error {
 		autoDir := AutoMemoryDir(projectDir, homeDir)
-		if entries, err := loadRulesDir(autoDir, AutoMemory); err == nil {
-			levels[4] = entries
+		if entries, err := loadRulesDir(autoDir, AutoMemory, scope); err == nil {
+			levels[5] = entries
 		}
 		return nil
 	})
@@ -136,12 +158,57 @@ func AutoMemoryDir(projectDir, homeDir string) string {
 	return filepath.Join(homeDir, ".pi-go", "projects", hash, "memory")
 }
 
-// expandImports resolves @path references in content.
-// Tracks visited files for cycle detection and enforces max depth.
-func expandImports(content, baseDir string, visited map[string]bool, depth int) (string, error) {
-	if depth > maxImportDepth {
-		return "", fmt.Errorf("import depth exceeds maximum (%d)", maxImportDepth)
+// importScope limits what @path imports may read: files under one of
+// roots that isSecret, when set, does not flag. A nil scope allows any
+// file.
+type importScope struct {
+	home     string
+	roots    []string
+	isSecret func(path string) bool
+}
+
+func newImportScope(projectDir, homeDir string, isSecret func(string) bool) *importScope {
+	scope := &importScope{home: homeDir, isSecret: isSecret}
+	for _, dir := range []string{projectDir, filepath.Join(homeDir, ".claude"), filepath.Join(homeDir, ".pi-go")} {
+		scope.roots = append(scope.roots, realPath(dir))
+	}
+	return scope
+}
+
+// reject returns why the import at absPath may not be read, or "".
+func (s *importScope) reject(absPath string) string {
+	if s == nil {
+		return ""
+	}
+	if s.isSecret != nil && s.isSecret(absPath) {
+		return "import of a secret file"
 	}
+	real := realPath(absPath)
+	for _, root := range s.roots {
+		if rel, err := filepath.Rel(root, real); err == nil && filepath.IsLocal(rel) {
+			return ""
+		}
+	}
+	return "import outside the project and memory dirs"
+}
+
+// realPath returns path with symlinks resolved, or path itself when it
+// cannot be resolved.
+func realPath(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return path
+}
+
+// expandImports resolves @path references in content. A reference is a
+// line holding only "@path"; relative paths resolve against baseDir and
+// "~/" against the home directory. visited holds the files on the current
+// import chain, so a file imported from two places is expanded twice. A
+// missing file, a cycle, an import nested deeper than maxImportDepth, or
+// one scope rejects replaces just its line with a comment. Imported files
+// are capped at maxFileBytes.
+func expandImports(content, baseDir string, scope *importScope, visited map[string]bool, depth int) string {
 	if visited == nil {
 		visited = make(map[string]bool)
 	}
@@ -150,29 +217,27 @@ func expandImports(content, baseDir string, visited map[string]bool, depth int)
 	var result []string
 
 	for _, line := range lines {
-		trimmed := strings.TrimSpace(line)
-		if !strings.HasPrefix(trimmed, "@") || strings.HasPrefix(trimmed, "@@") {
+		importPath, ok := importRef(line)
+		if !ok {
 			result = append(result, line)
 			continue
 		}
 
-		importPath := strings.TrimPrefix(trimmed, "@")
-		if importPath == "" {
-			result = append(result, line)
+		absPath := resolveImport(importPath, baseDir, scope)
+		if reason := scope.reject(absPath); reason != "" {
+			result = append(result, fmt.Sprintf("<!-- %s: %s -->", reason, importPath))
 			continue
 		}
-
-		absPath := importPath
-		if !filepath.IsAbs(importPath) {
-			absPath = filepath.Join(baseDir, importPath)
-		}
-		absPath, _ = filepath.Abs(absPath)
-
 		if visited[absPath] {
-			return "", fmt.Errorf("import cycle detected: %s", absPath)
+			result = append(result, fmt.Sprintf("<!-- import cycle: %s -->", importPath))
+			continue
+		}
+		if depth >= maxImportDepth {
+			result = append(result, fmt.Sprintf("<!-- import depth exceeds %d: %s -->", maxImportDepth, importPath))
+			continue
 		}
 
-		data, err := os.ReadFile(absPath)
+		data, err := readCapped(absPath)
 		if err != nil {
 			// Missing files: insert comment noting the missing import
 			result = append(result, fmt.Sprintf("<!-- import not found: %s -->", importPath))
@@ -180,14 +245,71 @@ func expandImports(content, baseDir string, visited map[string]bool, depth int)
 		}
 
 		visited[absPath] = true
-		expanded, err := expandImports(string(data), filepath.Dir(absPath), visited, depth+1)
-		if err != nil {
-			return "", err
+		result = append(result, expandImports(data, filepath.Dir(absPath), scope, visited, depth+1))
+		delete(visited, absPath)
+	}
+
+	return strings.Join(result, "\n")
+}
+
+// importRef returns the path of an "@path" import line. "@@" escapes a
+// literal "@", and a line with spaces ("@alice please review") is prose.
+func importRef(line string) (string, bool) {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, "@") || strings.HasPrefix(trimmed, "@@") {
+		return "", false
+	}
+	path := trimmed[1:]
+	if path == "" || strings.ContainsAny(path, " \t") {
+		return "", false
+	}
+	return path, true
+}
+
+// resolveImport returns the absolute path of an import found in baseDir.
+func resolveImport(importPath, baseDir string, scope *importScope) string {
+	if rest, ok := strings.CutPrefix(importPath, "~/"); ok {
+		home := ""
+		if scope != nil {
+			home = scope.home
+		} else {
+			home, _ = os.UserHomeDir()
+		}
+		if home != "" {
+			importPath = filepath.Join(home, rest)
 		}
-		result = append(result, expanded)
 	}
+	if !filepath.IsAbs(importPath) {
+		importPath = filepath.Join(baseDir, importPath)
+	}
+	abs, err := filepath.Abs(importPath)
+	if err != nil {
+		return filepath.Clean(importPath)
+	}
+	return abs
+}
+
+// readCapped reads a memory file, truncating it at maxFileBytes with a note.
+func readCapped(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
 
-	return strings.Join(result, "\n"), nil
+	data, err := io.ReadAll(io.LimitReader(f, maxFileBytes+1))
+	if err != nil {
+		return "", err
+	}
+	if len(data) <= maxFileBytes {
+		return string(data), nil
+	}
+	cut := maxFileBytes
+	for cut > 0 && !utf8.RuneStart(data[cut]) {
+		cut-- // do not split a multi-byte rune
+	}
+	data = data[:cut]
+	return string(data) + fmt.Sprintf("\n<!-- truncated: %s exceeds %d KB -->", filepath.Base(path), maxFileBytes>>10), nil
 }
 
 // parseFrontmatter extracts YAML-like frontmatter and returns body + paths.
@@ -265,32 +387,38 @@ func matchAnyFile(patterns []string, files []string) bool {
 }
 
 // loadFirstFile tries paths in order; returns the first that exists.
-func loadFirstFile(baseDir string, level Level, paths ...string) (Entry, bool) {
+func loadFirstFile(scope *importScope, level Level, paths ...string) (Entry, bool) {
 	for _, p := range paths {
-		data, err := os.ReadFile(p)
+		data, err := readCapped(p)
 		if err != nil {
 			continue
 		}
-		content, err := expandImports(string(data), filepath.Dir(p), nil, 0)
-		if err != nil {
-			content = string(data) // Fall back to raw content on expand error
-		}
 		return Entry{
 			Source:  p,
-			Content: content,
+			Content: expandImports(data, filepath.Dir(p), scope, rootVisited(p), 0),
 			Level:   level,
 		}, true
 	}
 	return Entry{}, false
 }
 
+// rootVisited starts an import chain at the memory file path, so a file
+// importing itself is reported as a cycle.
+func rootVisited(path string) map[string]bool {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+	return map[string]bool{abs: true}
+}
+
 // loadSingleFile loads a single file as an entry.
-func loadSingleFile(path string, level Level) (Entry, bool) {
-	return loadFirstFile(filepath.Dir(path), level, path)
+func loadSingleFile(path string, level Level, scope *importScope) (Entry, bool) {
+	return loadFirstFile(scope, level, path)
 }
 
 // loadRulesDir loads all .md files from a rules directory.
-func loadRulesDir(dir string, level Level) ([]Entry, error) {
+func loadRulesDir(dir string, level Level, scope *importScope) ([]Entry, error) {
 	dirEntries, err := os.ReadDir(dir)
 	if err != nil {
 		return nil, err
@@ -303,22 +431,16 @@ func loadRulesDir(dir string, level Level) ([]Entry, error) {
 		}
 
 		path := filepath.Join(dir, de.Name())
-		data, err := os.ReadFile(path)
+		raw, err := readCapped(path)
 		if err != nil {
 			continue
 		}
 
-		raw := string(data)
 		body, paths := parseFrontmatter(raw)
 
-		content, err := expandImports(body, dir, nil, 0)
-		if err != nil {
-			content = body
-		}
-
 		entries = append(entries, Entry{
 			Source:  path,
-			Content: content,
+			Content: expandImports(body, dir, scope, rootVisited(path), 0),
 			Level:   level,
 			Paths:   paths,
 		})
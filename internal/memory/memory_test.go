@@ -1,5 +1,5 @@
 // ABOUTME: Tests for memory hierarchy loading, import expansion, and prompt formatting
-// ABOUTME: Table-driven tests covering 5-level resolution, cycles, depth limits, globs
+// ABOUTME: Table-driven tests covering 6-level resolution, cycles, depth limits, size caps, globs
 
 package memory
 
@@ -8,11 +8,15 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"unicode/utf8"
 )
 
 func TestLevelOrdering(t *testing.T) {
-	if ProjectRules >= ClaudeCompat {
-		t.Error("ProjectRules must be lower than ClaudeCompat")
+	if ProjectRules >= ProjectPI {
+		t.Error("ProjectRules must be lower than ProjectPI")
+	}
+	if ProjectPI >= ClaudeCompat {
+		t.Error("ProjectPI must be lower than ClaudeCompat")
 	}
 	if ClaudeCompat >= ClaudeRules {
 		t.Error("ClaudeCompat must be lower than ClaudeRules")
@@ -29,7 +33,7 @@ func TestLoad_EmptyDirs(t *testing.T) {
 	project := t.TempDir()
 	home := t.TempDir()
 
-	entries, err := Load(project, home)
+	entries, err := Load(project, home, nil)
 	if err != nil {
 		t.Fatalf("Load empty dirs: %v", err)
 	}
@@ -50,7 +54,7 @@ func TestLoad_PIGOMDIgnored(t *testing.T) {
 	writeFile(t, filepath.Join(home, ".pi-go", "PIGOMD.md"), "user ignored")
 	writeFile(t, filepath.Join(project, "PIGOMD.local.md"), "local ignored")
 
-	entries, err := Load(project, home)
+	entries, err := Load(project, home, nil)
 	if err != nil {
 		t.Fatalf("Load: %v", err)
 	}
@@ -70,7 +74,7 @@ func TestLoad_CLAUDEMDCompat(t *testing.T) {
 	mkdirAll(t, filepath.Join(home, ".claude"))
 	writeFile(t, filepath.Join(home, ".claude", "CLAUDE.md"), "claude user")
 
-	entries, err := Load(project, home)
+	entries, err := Load(project, home, nil)
 	if err != nil {
 		t.Fatalf("Load: %v", err)
 	}
@@ -113,7 +117,7 @@ func TestLoad_FullHierarchy(t *testing.T) {
 	mkdirAll(t, filepath.Join(home, ".claude"))
 	writeFile(t, filepath.Join(home, ".claude", "CLAUDE.md"), "user claude")
 
-	entries, err := Load(project, home)
+	entries, err := Load(project, home, nil)
 	if err != nil {
 		t.Fatalf("Load: %v", err)
 	}
@@ -140,10 +144,7 @@ func TestExpandImports_Simple(t *testing.T) {
 	writeFile(t, filepath.Join(dir, "included.md"), "included content")
 
 	input := "before\n@included.md\nafter"
-	got, err := expandImports(input, dir, nil, 0)
-	if err != nil {
-		t.Fatalf("expandImports: %v", err)
-	}
+	got := expandImports(input, dir, nil, nil, 0)
 
 	if !strings.Contains(got, "included content") {
 		t.Errorf("expected expanded content, got %q", got)
@@ -159,10 +160,7 @@ func TestExpandImports_Nested(t *testing.T) {
 	writeFile(t, filepath.Join(dir, "b.md"), "content-b")
 
 	input := "@a.md"
-	got, err := expandImports(input, dir, nil, 0)
-	if err != nil {
-		t.Fatalf("expandImports: %v", err)
-	}
+	got := expandImports(input, dir, nil, nil, 0)
 
 	if !strings.Contains(got, "content-a") || !strings.Contains(got, "content-b") {
 		t.Errorf("expected nested expansion, got %q", got)
@@ -171,32 +169,34 @@ func TestExpandImports_Nested(t *testing.T) {
 
 func TestExpandImports_CycleDetection(t *testing.T) {
 	dir := t.TempDir()
-	writeFile(t, filepath.Join(dir, "a.md"), "@b.md")
+	writeFile(t, filepath.Join(dir, "a.md"), "content-a\n@b.md")
 	writeFile(t, filepath.Join(dir, "b.md"), "@a.md")
 
-	_, err := expandImports("@a.md", dir, nil, 0)
-	if err == nil {
-		t.Error("expected cycle detection error")
-	}
-	if !strings.Contains(err.Error(), "cycle") {
-		t.Errorf("expected cycle error, got %v", err)
+	got := expandImports("@a.md", dir, nil, nil, 0)
+	if !strings.Contains(got, "content-a") || !strings.Contains(got, "<!-- import cycle: a.md -->") {
+		t.Errorf("expected the cyclic line replaced by a comment, got %q", got)
 	}
 }
 
-func TestExpandImports_MaxDepth(t *testing.T) {
+func TestExpandImports_CycleKeepsSiblings(t *testing.T) {
 	dir := t.TempDir()
-
-	// Create chain: d0.md -> d1.md -> ... -> d6.md (depth > 5)
-	for i := range 7 {
-		var content string
-		if i < 6 {
-			content = strings.ReplaceAll("@dNEXT.md", "NEXT", strings.Repeat("x", i+1))
-			// Simpler: just use numbered files
+	root := filepath.Join(dir, "PI.md")
+	writeFile(t, filepath.Join(dir, "loop.md"), "@PI.md")
+	writeFile(t, filepath.Join(dir, "style.md"), "style guide")
+	writeFile(t, filepath.Join(dir, "build.md"), "build steps")
+
+	got := expandImports("@style.md\n@loop.md\n@build.md", dir, nil, rootVisited(root), 0)
+	for _, want := range []string{"style guide", "<!-- import cycle: PI.md -->", "build steps"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in %q", want, got)
 		}
-		_ = content
 	}
+}
 
-	// Simpler: create a chain that's too deep
+func TestExpandImports_MaxDepth(t *testing.T) {
+	dir := t.TempDir()
+
+	// Chain d0.md -> d1.md -> ... -> d6.md is deeper than maxImportDepth.
 	writeFile(t, filepath.Join(dir, "d0.md"), "@d1.md")
 	writeFile(t, filepath.Join(dir, "d1.md"), "@d2.md")
 	writeFile(t, filepath.Join(dir, "d2.md"), "@d3.md")
@@ -205,12 +205,9 @@ func TestExpandImports_MaxDepth(t *testing.T) {
 	writeFile(t, filepath.Join(dir, "d5.md"), "@d6.md")
 	writeFile(t, filepath.Join(dir, "d6.md"), "end")
 
-	_, err := expandImports("@d0.md", dir, nil, 0)
-	if err == nil {
-		t.Error("expected max depth error")
-	}
-	if !strings.Contains(err.Error(), "depth") {
-		t.Errorf("expected depth error, got %v", err)
+	got := expandImports("@d0.md", dir, nil, nil, 0)
+	if !strings.Contains(got, "<!-- import depth exceeds 5: d5.md -->") || strings.Contains(got, "end") {
+		t.Errorf("expected the too-deep import replaced by a comment, got %q", got)
 	}
 }
 
@@ -219,23 +216,130 @@ func TestExpandImports_MissingFile(t *testing.T) {
 
 	// Missing files should be skipped with a comment, not fail
 	input := "before\n@missing.md\nafter"
-	got, err := expandImports(input, dir, nil, 0)
-	if err != nil {
-		t.Fatalf("expandImports should not fail on missing file: %v", err)
-	}
+	got := expandImports(input, dir, nil, nil, 0)
 	if !strings.Contains(got, "before") || !strings.Contains(got, "after") {
 		t.Errorf("surrounding content should be preserved, got %q", got)
 	}
 }
 
+func TestLoad_PIMDImportsDocs(t *testing.T) {
+	project := t.TempDir()
+	home := t.TempDir()
+
+	mkdirAll(t, filepath.Join(project, "docs"))
+	writeFile(t, filepath.Join(project, "docs", "architecture.md"), "layered architecture\n@../PI.md")
+	writeFile(t, filepath.Join(project, "PI.md"), "project memory\n@docs/architecture.md")
+	writeFile(t, filepath.Join(project, "CLAUDE.md"), "claude memory")
+
+	entries, err := Load(project, home, nil)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	pi := findLevel(entries, ProjectPI)
+	if pi == nil || findLevel(entries, ClaudeCompat) == nil {
+		t.Fatalf("expected both PI.md and CLAUDE.md entries, got %+v", entries)
+	}
+	// docs/architecture.md imports PI.md back: only that line is dropped.
+	if !strings.Contains(pi.Content, "layered architecture") || !strings.Contains(pi.Content, "<!-- import cycle: ../PI.md -->") {
+		t.Errorf("cyclic import should keep the rest of the expansion, got %q", pi.Content)
+	}
+}
+
+func TestLoad_ImportsConfined(t *testing.T) {
+	base := t.TempDir()
+	project, home := filepath.Join(base, "project"), filepath.Join(base, "home")
+	mkdirAll(t, filepath.Join(project, "docs"))
+	mkdirAll(t, filepath.Join(home, ".aws"))
+	mkdirAll(t, filepath.Join(home, ".claude"))
+	writeFile(t, filepath.Join(project, "docs", "ok.md"), "project doc")
+	writeFile(t, filepath.Join(project, ".env"), "API_KEY=project-secret")
+	writeFile(t, filepath.Join(home, ".claude", "notes.md"), "user notes")
+	writeFile(t, filepath.Join(home, ".aws", "credentials"), "aws-secret")
+	writeFile(t, filepath.Join(base, "outside.md"), "outside-secret")
+	if err := os.Symlink(filepath.Join(home, ".aws", "credentials"), filepath.Join(project, "docs", "link.md")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	writeFile(t, filepath.Join(project, "PI.md"),
+		"@docs/ok.md\n@~/.claude/notes.md\n@~/.aws/credentials\n@../outside.md\n@docs/link.md\n@.env")
+
+	isSecret := func(path string) bool { return filepath.Base(path) == ".env" }
+	entries, err := Load(project, home, isSecret)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	pi := findLevel(entries, ProjectPI)
+	if pi == nil {
+		t.Fatal("expected a PI.md entry")
+	}
+	for _, want := range []string{
+		"project doc",
+		"user notes",
+		"<!-- import outside the project and memory dirs: ~/.aws/credentials -->",
+		"<!-- import outside the project and memory dirs: ../outside.md -->",
+		"<!-- import outside the project and memory dirs: docs/link.md -->",
+		"<!-- import of a secret file: .env -->",
+	} {
+		if !strings.Contains(pi.Content, want) {
+			t.Errorf("missing %q in %q", want, pi.Content)
+		}
+	}
+	for _, secret := range []string{"aws-secret", "outside-secret", "project-secret"} {
+		if strings.Contains(pi.Content, secret) {
+			t.Errorf("%s leaked into memory: %q", secret, pi.Content)
+		}
+	}
+}
+
+func TestExpandImports_DiamondIsNotACycle(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.md"), "@shared.md")
+	writeFile(t, filepath.Join(dir, "b.md"), "@shared.md")
+	writeFile(t, filepath.Join(dir, "shared.md"), "shared content")
+
+	got := expandImports("@a.md\n@b.md", dir, nil, nil, 0)
+	if strings.Count(got, "shared content") != 2 {
+		t.Errorf("expected shared file expanded twice, got %q", got)
+	}
+}
+
+func TestExpandImports_SelfImport(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "PI.md")
+	writeFile(t, root, "@PI.md")
+
+	if got := expandImports("@PI.md", dir, nil, rootVisited(root), 0); got != "<!-- import cycle: PI.md -->" {
+		t.Errorf("expected a cycle comment for a self-import, got %q", got)
+	}
+}
+
+func TestExpandImports_ProseIsNotImport(t *testing.T) {
+	dir := t.TempDir()
+	input := "@alice owns the parser\n@@escaped"
+	if got := expandImports(input, dir, nil, nil, 0); got != input {
+		t.Errorf("expandImports = %q; want input unchanged", got)
+	}
+}
+
+func TestExpandImports_SizeCap(t *testing.T) {
+	dir := t.TempDir()
+	big := strings.Repeat("é", maxFileBytes) // 2 bytes per rune
+	writeFile(t, filepath.Join(dir, "big.md"), big)
+
+	got := expandImports("@big.md", dir, nil, nil, 0)
+	body, note, ok := strings.Cut(got, "\n<!-- truncated: big.md exceeds 64 KB -->")
+	if !ok || note != "" {
+		t.Fatalf("expected truncation note, got tail %q", got[max(len(got)-80, 0):])
+	}
+	if len(body) > maxFileBytes || !utf8.ValidString(body) {
+		t.Errorf("truncated body: %d bytes, valid UTF-8 %v", len(body), utf8.ValidString(body))
+	}
+}
+
 func TestExpandImports_NoImports(t *testing.T) {
 	dir := t.TempDir()
 
 	input := "plain content\nno imports here"
-	got, err := expandImports(input, dir, nil, 0)
-	if err != nil {
-		t.Fatalf("expandImports: %v", err)
-	}
+	got := expandImports(input, dir, nil, nil, 0)
 	if got != input {
 		t.Errorf("content should be unchanged, got %q", got)
 	}
@@ -340,7 +444,7 @@ func TestLoadRules(t *testing.T) {
 	writeFile(t, filepath.Join(rulesDir, "coding.md"), "# Coding rules\nUse TDD")
 	writeFile(t, filepath.Join(rulesDir, "style.md"), "---\npaths: [\"*.go\"]\n---\n# Style\nGo style")
 
-	entries, err := loadRulesDir(rulesDir, ProjectRules)
+	entries, err := loadRulesDir(rulesDir, ProjectRules, nil)
 	if err != nil {
 		t.Fatalf("loadRulesDir: %v", err)
 	}
@@ -435,7 +539,7 @@ func TestLoad_Parallel_LevelOrderPreserved(t *testing.T) {
 	mkdirAll(t, autoDir)
 	writeFile(t, filepath.Join(autoDir, "auto.md"), "auto memory")
 
-	entries, err := Load(project, home)
+	entries, err := Load(project, home, nil)
 	if err != nil {
 		t.Fatalf("Load: %v", err)
 	}
@@ -467,7 +571,7 @@ func TestLoad_Parallel_RaceDetector(t *testing.T) {
 
 	// Run 50 times; with -race flag this catches data races
 	for range 50 {
-		entries, err := Load(project, home)
+		entries, err := Load(project, home, nil)
 		if err != nil {
 			t.Fatalf("Load: %v", err)
 		}
@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -21,11 +22,15 @@ import (
 	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
 	"github.com/mauromedda/pi-coding-agent-go/internal/commands"
 	"github.com/mauromedda/pi-coding-agent-go/internal/ide"
+	pilog "github.com/mauromedda/pi-coding-agent-go/internal/log"
 	"github.com/mauromedda/pi-coding-agent-go/internal/config"
 	"github.com/mauromedda/pi-coding-agent-go/internal/perf"
 	"github.com/mauromedda/pi-coding-agent-go/internal/permission"
 	"github.com/mauromedda/pi-coding-agent-go/internal/session"
+	"github.com/mauromedda/pi-coding-agent-go/internal/shell"
+	"github.com/mauromedda/pi-coding-agent-go/internal/tools"
 	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai/provider/gemini"
 )
 
 // Mode represents the current editing mode.
@@ -59,12 +64,21 @@ type gitBranchMsg struct{ branch string }
 // is single-threaded, and the goroutine only writes via Program.Send.
 type shared struct {
 	program     *tea.Program
+	screen      *screen // paints the frames when pi-go drives the terminal; nil under Bubble Tea's renderer
+	pane        int     // ID of this app's pane in a split layout; 0 outside one
 	activeAgent atomic.Pointer[agent.Agent]
 	ctx         context.Context
 	cancel      context.CancelFunc
 	bgManager   *BackgroundManager
 	fgTaskID    atomic.Value // string: current foreground task ID
 	taskCancels sync.Map     // map[string]context.CancelFunc: per-task cancellation
+	readCache   *tools.ReadCache
+
+	// Read by reminder sources while an agent runs; see syncReminders.
+	planMode atomic.Bool
+	costBits atomic.Uint64 // math.Float64bits of the session cost in USD
+
+	suspended atomic.Bool // set while suspendCmd has the process stopped
 }
 
 // AppModel is the root Bubble Tea model for the interactive TUI.
@@ -74,6 +88,7 @@ type AppModel struct {
 	// State
 	mode          Mode
 	agentRunning  bool
+	spinning      bool // a SpinnerTickMsg is scheduled
 	messages      []ai.Message
 	width, height int
 
@@ -93,6 +108,7 @@ type AppModel struct {
 	// Token stats
 	totalInputTokens  int
 	totalOutputTokens int
+	thinkingChars     int // streamed thinking, counted for the cost dashboard's estimate
 
 	// Session metadata
 	gitBranch     string
@@ -107,16 +123,40 @@ type AppModel struct {
 
 	// Prompt queue and history
 	promptQueue    []string // prompts waiting to run after current agent finishes
+	queueNext      int      // leading promptQueue entries flagged to run next
 	promptHistory  []string // all submitted prompts (most recent last)
 	historyIndex   int      // -1 = composing new; 0+ = browsing history (0 = most recent)
 	savedDraft     string   // editor text saved before entering history mode
 	queueEditIndex int      // -1 = not editing queue; 0+ = browsing queue items
 
+	// Checklists shown above the editor
+	plan  PlanProgressModel // approved plan being carried out, one step per turn
+	todos TodoListModel     // task list the model keeps with todo_write
+
+	// Custom status line
+	statusLineRunning bool // a status line run is in flight
+	lastExitCode      *int // exit code of the last ! command; nil before the first
+
 	// Auto-accept mode
 	autoAccept bool
 
+	// reloadPending defers a settings reload until the running turn ends.
+	reloadPending bool
+
+	// initTarget is the PI.md the running /init turn proposes; "" otherwise.
+	initTarget string
+
 	// Compaction state
 	compacting bool
+	evicted    session.EvictionStats // tool results shrunk this session, for /context
+	prewarmed  bool                  // provider prewarmed since the last turn ended
+	titling    bool                  // a session title is being generated
+	suggestion string                // predicted next prompt, shown as ghost text
+	undoFloor  int                   // /undo keeps messages before this index (compaction summary and kept turns)
+	editTarget *editPoint            // past prompt being edited; the next submit re-runs from it
+
+	// Prompt size the provider reported for the latest call, for /context
+	lastPromptTokens int
 
 	// Retry state
 	retryCount int       // number of retries attempted for current error
@@ -173,18 +213,47 @@ func NewAppModel(deps AppDeps) AppModel {
 		initialMode = ModeEdit
 	}
 
+	thinking := savedThinkingLevel(deps)
 	footer := NewFooterModel().
 		WithPath("").
 		WithModel(modelName).
 		WithModeLabel(initialMode.String()).
 		WithPermissionMode(permLabel).
-		WithShowImages(true)
+		WithShowImages(true).
+		WithThinking(thinking).
+		WithThinkingBudget(thinkingBudgetLabel(thinking, deps.Model))
+	if deps.Minion != nil {
+		footer = footer.WithMinionMode(deps.Minion.Mode().String())
+	}
+	if deps.Offline {
+		footer = footer.WithOffline(true)
+	}
+
+	if deps.Session != nil {
+		deps.Session.ThinkingMode = deps.ThinkingRetention.EffectiveMode()
+	}
 
 	welcome := NewWelcomeModel(deps.Version, modelName, "", toolCount)
 
-	return AppModel{
-		sh:           &shared{ctx: ctx, cancel: cancel},
+	// First run in this project: ask how autonomous the agent should be.
+	var overlay tea.Model
+	if deps.PermissionOnboarding && deps.ApplyAutonomy != nil {
+		overlay = NewPermOnboardingModel(80)
+	}
+	// The last run in this directory died mid-turn: offer to restore it.
+	if overlay == nil && deps.Interrupted != nil && deps.Session != nil {
+		overlay = NewRecoverConfirmModel(deps.Interrupted, 80)
+	}
+	// The project has a devcontainer: offer to run bash in it.
+	if overlay == nil && deps.Devcontainer != "" && deps.StartDevcontainer != nil {
+		overlay = NewDevcontainerConfirmModel(deps.Devcontainer, 80)
+	}
+
+	m := AppModel{
+		overlay:      overlay,
+		sh:           &shared{ctx: ctx, cancel: cancel, readCache: tools.NewReadCache()},
 		mode:         initialMode,
+		thinkingLevel: thinking,
 		editor:       editor,
 		footer:       footer,
 		content:      []tea.Model{welcome},
@@ -194,6 +263,11 @@ func NewAppModel(deps AppDeps) AppModel {
 		historyIndex:   -1,
 		queueEditIndex: -1,
 	}
+	for _, cmd := range deps.PluginCommands {
+		_ = m.cmdRegistry.Register(cmd) // collisions are reported before the TUI starts
+	}
+	wireReminders(m.sh, deps)
+	return m.syncReminders()
 }
 
 // Init returns startup commands: detect git branch, git CWD, and probe model latency.
@@ -231,17 +305,20 @@ func (m AppModel) Init() tea.Cmd {
 		return ProbeResultMsg{Profile: profile}
 	}
 
-	return tea.Batch(gitBranchCmd, gitCWDCmd, probeCmd)
+	return tea.Batch(gitBranchCmd, gitCWDCmd, probeCmd, m.statusLineTick())
 }
 
 // Update routes messages to the appropriate handler.
 func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	// Ctrl+O: toggle tool call expand/collapse; bypass overlay routing
-	// so it works regardless of whether an overlay is active.
-	if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.String() == "ctrl+o" {
-		for i := range m.content {
-			updated, _ := m.content[i].Update(msg)
-			m.content[i] = updated
+	// Ctrl+O: expand/collapse the latest tool call with folded output;
+	// bypass overlay routing so it works regardless of whether an overlay
+	// is active.
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && m.deps.Keys.action(keyMsg) == actionExpandOutput {
+		for i := len(m.content) - 1; i >= 0; i-- {
+			if am, ok := m.content[i].(*AssistantMsgModel); ok && am.foldingCall() >= 0 {
+				m.content[i], _ = am.Update(msg)
+				break
+			}
 		}
 		return m, nil
 	}
@@ -251,7 +328,9 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
-		m.cachedSep = strings.Repeat("─", msg.Width)
+		m.cachedSep = strings.Repeat(glyphs.HRule, msg.Width)
+		m.plan = m.plan.WithWidth(msg.Width)
+		m.todos = m.todos.WithWidth(msg.Width)
 		m = m.propagateSize(msg)
 		// Propagate to overlay so it can track width/height
 		if m.overlay != nil {
@@ -311,6 +390,8 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.deps.Model.Name = msg.Model.Name
 		m.deps.Model.ID = msg.Model.ID
 		m.footer = m.footer.WithModel(msg.Model.Name)
+		m.thinkingLevel = savedThinkingLevel(m.deps)
+		m = m.showThinking()
 		return m, nil
 
 	case ModelSelectorDismissMsg:
@@ -329,32 +410,141 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.editor = m.editor.SetFocused(true)
 		return m, nil
 
+	case EditPromptSelectedMsg:
+		m.overlay = nil
+		m.editor = m.editor.SetFocused(true)
+		m = m.startEdit(editPoint{msg: msg.MsgIndex, content: msg.ContentIndex, prompt: msg.Prompt})
+		return m, nil
+
+	case RerunPromptMsg:
+		m.overlay = nil
+		m.editor = m.editor.SetFocused(true)
+		if !msg.Rerun {
+			return m, nil // keep editing
+		}
+		return m.rerun(msg.Text)
+
+	case ForkPointSelectedMsg:
+		m.overlay = nil
+		m.editor = m.editor.SetFocused(true)
+		var notice string
+		m, notice = m.forkAt(msg.Turn, msg.Prompt)
+		return m.applyEffects(&cmdSideEffects{}, notice)
+
+	case SessionSearchResultMsg:
+		if ss, ok := m.overlay.(SessionSelectorModel); ok {
+			updated, _ := ss.Update(msg)
+			m.overlay = updated
+		}
+		return m, nil
+
 	// --- Queue overlay results ---
 	case QueueUpdatedMsg:
 		m.overlay = nil
-		m.promptQueue = msg.Items
-		m.footer = m.footer.WithQueuedCount(len(m.promptQueue))
+		m = m.setQueue(msg.Items, msg.Next)
 		m.editor = m.editor.SetFocused(true)
 		// Resume drain if agent finished while overlay was open
-		if !m.agentRunning && len(m.promptQueue) > 0 {
-			next := m.promptQueue[0]
-			m.promptQueue = m.promptQueue[1:]
-			m.footer = m.footer.WithQueuedCount(len(m.promptQueue))
-			return m.submitPrompt(next)
+		if !m.agentRunning {
+			return m.drainQueue()
 		}
 		return m, nil
 
 	case QueueEditMsg:
 		m.overlay = nil
-		// Remove the item from queue
-		if msg.Index >= 0 && msg.Index < len(m.promptQueue) {
-			m.promptQueue = append(m.promptQueue[:msg.Index], m.promptQueue[msg.Index+1:]...)
+		// Remove the item from the queue as the overlay left it
+		items, next := msg.Items, msg.Next
+		if msg.Index >= 0 && msg.Index < len(items) {
+			items = slices.Delete(slices.Clone(items), msg.Index, msg.Index+1)
+			if msg.Index < next {
+				next--
+			}
 		}
-		m.footer = m.footer.WithQueuedCount(len(m.promptQueue))
+		m = m.setQueue(items, next)
 		m.editor = m.editor.SetFocused(true).SetText(msg.Text)
 		return m, nil
 
+	// --- Pin overlay results ---
+	case MessagePinMsg:
+		m = m.setPinned(msg.Index, msg.Pinned)
+		return m, nil
+
+	case PinViewDismissMsg:
+		m.overlay = nil
+		m.editor = m.editor.SetFocused(true)
+		return m, nil
+
+	// --- Share confirmation results ---
+	case ShareConfirmedMsg:
+		m.overlay = nil
+		m.editor = m.editor.SetFocused(true)
+		return m, uploadShareCmd(msg.Plan)
+
+	case RecoverSessionMsg:
+		m.overlay = nil
+		m.editor = m.editor.SetFocused(true)
+		var notice string
+		m, notice = m.answerRecovery(msg.Recover)
+		return m.applyEffects(&cmdSideEffects{}, notice)
+
+	case InitReviewMsg:
+		m.overlay = nil
+		m.editor = m.editor.SetFocused(true)
+		return m.answerInit(msg)
+
+	case DevcontainerAnswerMsg:
+		m.overlay = nil
+		m.editor = m.editor.SetFocused(true)
+		return m.answerDevcontainer(msg.Use)
+
+	case DevcontainerStartedMsg:
+		if msg.Err != nil {
+			return m.applyEffects(&cmdSideEffects{}, fmt.Sprintf("Could not start the devcontainer: %v\nBash keeps running on this machine.", msg.Err))
+		}
+		return m.applyEffects(&cmdSideEffects{}, fmt.Sprintf("Bash commands now run in the %s.", msg.Desc))
+
+	case ShareDoneMsg:
+		if msg.Err != nil {
+			return m.applyEffects(&cmdSideEffects{}, fmt.Sprintf("Share failed: %v", msg.Err))
+		}
+		return m.applyEffects(&cmdSideEffects{}, fmt.Sprintf("Shared: %s", msg.Link))
+
+	// --- Memory picker results ---
+	case MemoryFileSelectedMsg:
+		return m.handleMemorySelected(msg)
+
+	case ThemePreviewMsg:
+		return m.previewTheme(msg.Name)
+
+	case ThemeSelectedMsg:
+		return m.selectTheme(msg.Name)
+
+	case ThemePickerDismissMsg:
+		return m.dismissThemePicker(msg.Original)
+
+	case ThemeChangedMsg:
+		return m.restyle(msg), nil
+
+	case MemoryEditedMsg:
+		if msg.Err != nil {
+			return m.applyEffects(&cmdSideEffects{}, fmt.Sprintf("Error: editor: %v", msg.Err))
+		}
+		return m.reloadMemory(fmt.Sprintf("Memory reloaded from %s.", shortenHome(msg.Path, m.deps.Memory.HomeDir)))
+
 	// --- Worktree exit ---
+	case PermOnboardingMsg:
+		m.overlay = nil
+		p, err := m.deps.ApplyAutonomy(msg.Level)
+		text := ""
+		if err != nil {
+			text = fmt.Sprintf("Error: %v", err)
+		} else {
+			m = m.withPermissions(p)
+			text = "Saved starter permissions to .pi-go/settings.json.\n" + config.ExplainPermissions(p) +
+				"\nChange them any time with /permissions."
+		}
+		model, cmd := m.applyEffects(&cmdSideEffects{}, text)
+		return model, cmd
+
 	case WorktreeExitMsg:
 		m.overlay = nil
 		m.worktreeExitAction = msg.Action
@@ -362,9 +552,16 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	// --- Background task lifecycle ---
 	case BackgroundTaskDoneMsg:
+		task := &BackgroundTask{ID: msg.TaskID, Prompt: msg.Prompt, Status: BGDone}
+		if msg.Err != nil {
+			task.Status = BGFailed
+		}
 		if m.sh.bgManager != nil {
 			m.sh.bgManager.MarkDone(msg.TaskID, msg.Messages, msg.Err)
 			m.footer = m.footer.WithBackgroundCount(m.sh.bgManager.Count())
+			if t := m.sh.bgManager.Get(msg.TaskID); t != nil {
+				task = t
+			}
 		}
 		// Inline notification
 		label := "✓"
@@ -373,9 +570,9 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		m = m.ensureAssistantMsg()
 		m = m.updateLastAssistant(AgentTextMsg{
-			Text: fmt.Sprintf("\n%s Background task [%s] completed", label, msg.TaskID),
+			Text: fmt.Sprintf("\n%s Background task [%s] completed", label, task.Label()),
 		})
-		return m, nil
+		return m, notifyTaskDone(m.deps.Notify, *task, os.Stdout)
 
 	case BackgroundTaskReviewMsg:
 		m.overlay = nil
@@ -413,6 +610,9 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case MinionFanOutMsg:
+		return m.trackFanOut(msg.Progress), nil
+
 	case BackgroundTaskCancelMsg:
 		if v, ok := m.sh.taskCancels.Load(msg.TaskID); ok {
 			if cancelFn, ok := v.(context.CancelFunc); ok {
@@ -429,38 +629,73 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		bom.SetExitCode(msg.ExitCode)
 		bom.width = m.width
 		m.content = append(m.content, bom)
-		return m, nil
+		code := msg.ExitCode
+		m.lastExitCode = &code
+		return m.refreshStatusLine()
 
 	case AgentTextMsg:
+		_ = m.journal().Text(msg.Text)
 		m = m.ensureAssistantMsg()
 		m = m.updateLastAssistant(msg)
 		return m, nil
 
 	case AgentThinkingMsg:
+		m.thinkingChars += len(msg.Text)
 		m = m.ensureAssistantMsg()
 		m = m.updateLastAssistant(msg)
 		return m, nil
 
 	case AgentToolStartMsg:
+		args, _ := json.Marshal(msg.Args)
+		_ = m.journal().ToolStart(msg.ToolID, msg.ToolName, string(args))
+		if msg.ToolName == tools.TodoWriteToolName {
+			m = m.applyTodoWrite(msg.Args)
+		}
 		m = m.ensureAssistantMsg()
 		m = m.updateLastAssistant(msg)
+		if !m.spinning {
+			m.spinning = true
+			return m, spinnerTick()
+		}
 		return m, nil
 
 	case AgentToolUpdateMsg:
 		m = m.updateLastAssistant(msg)
 		return m, nil
 
+	case SpinnerTickMsg:
+		m = m.updateLastAssistant(msg)
+		// Calls cut short by an abort never end; stop with the agent.
+		if n := len(m.content); n > 0 && m.agentRunning {
+			if am, ok := m.content[n-1].(*AssistantMsgModel); ok && am.running() {
+				return m, spinnerTick()
+			}
+		}
+		m.spinning = false
+		return m, nil
+
 	case AgentToolEndMsg:
+		if msg.Result != nil {
+			_ = m.journal().ToolEnd(msg.ToolID, msg.Result.Content, msg.Result.IsError)
+		} else {
+			_ = m.journal().ToolEnd(msg.ToolID, msg.Text, false)
+		}
 		m = m.updateLastAssistant(msg)
 		return m, nil
 
+	case AgentRouteMsg:
+		m.footer = m.footer.WithModelRoute(msg.Route)
+		return m, nil
+
 	case AgentUsageMsg:
 		if msg.Usage != nil {
 			m.totalInputTokens += msg.Usage.InputTokens
 			m.totalOutputTokens += msg.Usage.OutputTokens
+			m.lastPromptTokens = msg.Usage.InputTokens + msg.Usage.CacheRead + msg.Usage.CacheCreate
 		}
 		updated, _ := m.footer.Update(msg)
 		m.footer = updated.(FooterModel)
+		m = m.refreshContextView().syncReminders()
 
 		// Update context window usage percentage and allocation
 		if m.deps.Model != nil {
@@ -502,6 +737,7 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Non-retriable or max-retries-exhausted error: stop the agent run
 		// so the editor unlocks and the user can type again.
 		m.agentRunning = false
+		m.plan = m.plan.Fail()
 		m = m.ensureAssistantMsg()
 		m = m.updateLastAssistant(msg)
 		return m, nil
@@ -528,33 +764,70 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case AgentDoneMsg:
 		m.agentRunning = false
+		m.prewarmed = false
 		if len(msg.Messages) > 0 {
-			// Persist new assistant messages to session
+			// Persist this run's assistant replies; msg.Messages is the
+			// whole conversation, so earlier replies are already saved.
 			if m.deps.Session != nil {
-				for _, am := range msg.Messages {
-					if am.Role == ai.RoleAssistant {
+				for _, am := range msg.Messages[min(len(m.messages), len(msg.Messages)):] {
+					if am.Role == ai.RoleAssistant && hasText(am) {
 						m.deps.Session.AddAssistantMessage(&ai.AssistantMessage{
 							Content: am.Content,
 						})
 					}
 				}
+				_ = m.journal().EndTurn()
 			}
 			m.messages = msg.Messages
 		}
+		m = m.refreshContextView()
+		if m.todos.AllDone() {
+			m.todos = m.todos.WithItems(nil)
+		}
+		var titleCmd, statusCmd tea.Cmd
+		m, titleCmd = m.titleSession(false)
+		m, statusCmd = m.refreshStatusLine()
+		titleCmd = tea.Batch(titleCmd, statusCmd)
+		var notice string
+		if m, notice = m.finishPlanStep(); notice != "" {
+			m, _ = m.applyNotice(notice)
+		}
+		if m, notice = m.finishInit(); notice != "" {
+			m, _ = m.applyNotice(notice)
+		}
+		if m.reloadPending {
+			var reloadCmd tea.Cmd
+			m, reloadCmd = m.settingsChanged()
+			titleCmd = tea.Batch(titleCmd, reloadCmd)
+		}
 		// Drain next queued prompt; skip if queue overlay is open or inline editing active
 		if _, editing := m.overlay.(QueueViewModel); !editing && m.queueEditIndex == -1 && len(m.promptQueue) > 0 {
-			next := m.promptQueue[0]
-			m.promptQueue = m.promptQueue[1:]
-			m.footer = m.footer.WithQueuedCount(len(m.promptQueue))
-			return m.submitPrompt(next)
+			updated, cmd := m.drainQueue()
+			return updated, tea.Batch(cmd, titleCmd)
+		}
+		// Queued prompts go first; the plan continues once they are done.
+		if m.plan.Ready() {
+			updated, cmd := m.runPlanStep()
+			return updated, tea.Batch(cmd, titleCmd)
+		}
+		var suggestCmd tea.Cmd
+		m, suggestCmd = m.suggestNext()
+		return m.offerPlan(), tea.Batch(titleCmd, suggestCmd)
+
+	case SessionTitleMsg:
+		m.titling = false
+		if msg.Err == nil && m.deps.Session != nil && m.deps.Session.ID == msg.SessionID {
+			_ = m.deps.Session.SetTitle(msg.Title, true)
 		}
 		return m, nil
 
+	case SuggestionMsg:
+		return m.applySuggestion(msg), nil
+
 	// --- Plan overlay results ---
 	case PlanApprovedMsg:
 		m.overlay = nil
-		// Plan approved; could trigger execute mode in future phases
-		return m, nil
+		return m.approvePlan(msg.Plan)
 
 	case PlanRejectedMsg:
 		m.overlay = nil
@@ -644,11 +917,29 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case gitBranchMsg:
 		m.gitBranch = msg.branch
 		m.footer = m.footer.WithGitBranch(msg.branch)
+		// The first status line run waits for the branch it reports.
+		return m.refreshStatusLine()
+
+	case StatusLineMsg:
+		m.statusLineRunning = false
+		if msg.Err == nil {
+			m.footer = m.footer.WithStatusLine(msg.Text)
+		}
 		return m, nil
 
+	case StatusLineTickMsg:
+		m, cmd := m.refreshStatusLine()
+		return m, tea.Batch(cmd, m.statusLineTick())
+
 	case gitCWDMsg:
 		m.gitCWD = msg.cwd
 		m.footer = m.footer.WithPath(msg.cwd)
+		// Dropped files are mentioned relative to where mentions resolve
+		mentionRoot := msg.cwd
+		if mentionRoot == "" {
+			mentionRoot, _ = os.Getwd()
+		}
+		m.editor = m.editor.SetMentionRoot(mentionRoot)
 		// Update welcome model if it's the first content item
 		if len(m.content) > 0 {
 			if _, ok := m.content[0].(WelcomeModel); ok {
@@ -663,36 +954,19 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case SessionLoadedMsg:
-		m.messages = msg.Messages
-		// Rebuild content from loaded messages
-		for _, am := range msg.Messages {
-			switch am.Role {
-			case ai.RoleUser:
-				text := ""
-				for _, c := range am.Content {
-					if c.Type == "text" {
-						text += c.Text
-					}
-				}
-				m.content = append(m.content, NewUserMsgModel(text))
-			case ai.RoleAssistant:
-				assistantModel := NewAssistantMsgModel()
-				assistantModel.width = m.width
-				text := ""
-				for _, c := range am.Content {
-					if c.Type == "text" {
-						text += c.Text
-					}
-				}
-				updated, _ := assistantModel.Update(AgentTextMsg{Text: text})
-				m.content = append(m.content, updated.(*AssistantMsgModel))
-			}
-		}
+		m = m.loadMessages(msg.Messages)
 		return m, nil
 
 	case SessionSavedMsg:
 		return m, nil
 
+	case PaneFocusMsg:
+		m.editor = m.editor.SetFocused(msg.Focused)
+		return m, nil
+
+	case NoticeMsg:
+		return m.applyNotice(msg.Text)
+
 	case AutoCompactMsg:
 		return m.autoCompact()
 
@@ -701,6 +975,10 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if len(msg.Messages) > 0 {
 			m.messages = msg.Messages
 		}
+		m.lastPromptTokens = 0
+		// Turns the summary replaced cannot be undone, nor their files restored.
+		m.undoFloor = len(m.messages)
+		m.deps.Checkpoints.Reset()
 		// Persist compaction to session if wired
 		if m.deps.Session != nil && m.deps.Session.Writer != nil {
 			_ = m.deps.Session.Writer.WriteCompaction(session.CompactionData{
@@ -710,11 +988,15 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		// Show visible feedback
 		feedback := fmt.Sprintf("Context compacted: %d tokens saved.", msg.TokensSaved)
+		if msg.PinnedKept > 0 {
+			feedback += fmt.Sprintf(" %d pinned messages kept verbatim.", msg.PinnedKept)
+		}
 		am := NewAssistantMsgModel()
 		am.width = m.width
 		updated, _ := am.Update(AgentTextMsg{Text: feedback})
 		m.content = append(m.content, updated.(*AssistantMsgModel))
-		return m, nil
+		// The summary reflects where the session went; retitle from it.
+		return m.titleSession(true)
 
 	// --- Phase 8: TUI enhancement messages ---
 	case ModeTransitionMsg:
@@ -722,13 +1004,22 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case SettingsChangedMsg:
-		// Re-render footer; more wiring in Phase 9
-		return m, nil
+		return m.settingsChanged()
 
 	case PlanGeneratedMsg:
 		m.overlay = NewPlanViewModel(msg.Plan)
 		return m, nil
 
+	case SuspendRequestMsg:
+		return m, m.suspendCmd()
+
+	case tea.ResumeMsg:
+		// RestoreTerminal already re-entered raw mode and queued a repaint.
+		return m, nil
+
+	case ContinuedMsg:
+		return m, m.continued()
+
 	// --- Key routing ---
 	case tea.KeyMsg:
 		return m.handleKey(msg)
@@ -743,6 +1034,16 @@ const maxVisibleContent = 50
 
 // View renders the full TUI layout.
 func (m AppModel) View() string {
+	// Below the minimum size any layout would wrap into garbage.
+	if m.tooSmall() {
+		return tooSmallView(m.width, m.height)
+	}
+
+	// The transcript pager takes the whole screen.
+	if pager, ok := m.overlay.(TranscriptPagerModel); ok {
+		return pager.View()
+	}
+
 	var sections []string
 
 	// Only render the last N content models to avoid unbounded allocations.
@@ -782,6 +1083,15 @@ func (m AppModel) View() string {
 		}
 	}
 
+	// The model's todo list and the running plan's checklist sit just
+	// above the editor.
+	if todoView := m.todos.View(); todoView != "" {
+		sections = append(sections, todoView)
+	}
+	if planView := m.plan.View(); planView != "" {
+		sections = append(sections, planView)
+	}
+
 	// Use cached separator string (recomputed only on WindowSizeMsg)
 	sep := m.cachedSep
 	sections = append(sections,
@@ -796,8 +1106,11 @@ func (m AppModel) View() string {
 
 	main := lipgloss.JoinVertical(lipgloss.Left, sections...)
 
-	// Centered overlays (cost view, plan view, etc.)
+	// Centered overlays (cost view, plan view, etc.); full screen when narrow.
 	if m.overlay != nil && !isDropdownOverlay(m.overlay) && !isInlineOverlay(m.overlay) {
+		if m.compact() {
+			return fullScreenOverlay(m.overlay.View(), m.width, m.height)
+		}
 		return overlayRender(main, m.overlay.View(), m.width, m.height)
 	}
 
@@ -807,10 +1120,12 @@ func (m AppModel) View() string {
 // --- Key handling ---
 
 func (m AppModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "ctrl+c":
+	// Rebindable actions (settings.keybindings)
+	switch m.deps.Keys.action(msg) {
+	case actionAbort:
 		if m.agentRunning {
 			m.abortAgent()
+			m.plan = m.plan.Fail()
 			return m, nil
 		}
 		if m.deps.WorktreeSession != nil {
@@ -828,51 +1143,54 @@ func (m AppModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.content = append(m.content, welcome)
 		return m, nil
 
-	case "ctrl+d":
+	case actionSuspend:
+		return m, m.suspendCmd()
+
+	case actionExit:
 		if m.deps.WorktreeSession != nil && !m.agentRunning {
 			m.overlay = NewWorktreeDialogModel(m.deps.WorktreeSession.Info.Branch, m.width)
 			return m, nil
 		}
 		return m, tea.Quit
 
-	case "esc":
-		// Always forward ESC to editor first so the split-OSC guard
-		// can arm itself. If terminal sent \x1b]…\x1b\, BubbleTea
-		// delivers KeyEscape then plain ']'; the editor needs to see
-		// the ESC to suppress the ']' that follows.
-		editorUpdated, editorCmd := m.editor.Update(msg)
-		m.editor = editorUpdated.(EditorModel)
-
-		if m.agentRunning {
-			m.abortAgent()
-			return m, tea.Batch(editorCmd, func() tea.Msg { return AgentCancelMsg{} })
-		}
-		// NOTE: ESC on an idle prompt is intentionally a no-op to the user.
-		// The editor starts a split-ESC timer (200ms) for OSC safety. This is
-		// by design: if no ']' follows, the timeout fires and clears the state.
-		return m, editorCmd
-
-	case "ctrl+l":
+	case actionClear:
 		// Clear viewport; keep only a fresh welcome
 		m.content = m.content[:0]
 		welcome := NewWelcomeModel(m.deps.Version, m.modelName(), m.gitCWD, len(m.deps.Tools))
 		m.content = append(m.content, welcome)
 		return m, nil
 
-	case "shift+tab":
+	case actionAutoAccept:
 		m.autoAccept = !m.autoAccept
 		m.footer = m.footer.WithAutoAccept(m.autoAccept)
 		return m, nil
 
-	case "alt+p":
+	case actionToggleMode:
 		m = m.toggleMode()
 		return m, nil
 
-	case "alt+t":
+	case actionCycleThinking:
 		m = m.cycleThinking()
 		return m, nil
 
-	case "ctrl+t":
+	case actionExpandThinking:
+		for i := len(m.content) - 1; i >= 0; i-- {
+			if am, ok := m.content[i].(*AssistantMsgModel); ok && am.hasThinking() {
+				m.content[i], _ = am.Update(ToggleThinkingMsg{})
+				break
+			}
+		}
+		return m, nil
+
+	case actionTranscript:
+		// Page through the full saved transcript
+		if m.deps.Session == nil {
+			return m.applyEffects(&cmdSideEffects{}, "No saved transcript to page through.")
+		}
+		m.overlay = NewTranscriptPagerModel(m.width, m.height)
+		return m, loadTranscriptCmd(m.sessionsDir(), m.deps.Session.ID)
+
+	case actionCost:
 		// Toggle cost dashboard
 		if m.overlay != nil {
 			m.overlay = nil
@@ -880,16 +1198,26 @@ func (m AppModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.overlay = NewCostViewModel(
 				m.totalInputTokens, m.totalOutputTokens, 0,
 				m.footer.cost, 0, 0,
-			)
+			).WithThinkingTokens(session.EstimateTokensForChars(m.thinkingChars))
 		}
 		return m, nil
 
-	case "alt+m":
+	case actionModelSelector:
 		// Open model selector overlay
 		m.overlay = NewModelSelectorModel(m.deps.AvailableModels)
 		return m, nil
 
-	case "alt+i":
+	case actionEditPrompt:
+		// Edit a past prompt and re-run from it
+		if m.overlay == nil {
+			var notice string
+			if m, notice = m.openEditPicker(); notice != "" {
+				return m.applyEffects(&cmdSideEffects{}, notice)
+			}
+		}
+		return m, nil
+
+	case actionToggleImages:
 		m.showImages = !m.showImages
 		m.footer = m.footer.WithShowImages(m.showImages)
 		// Propagate toggle to all content models
@@ -900,18 +1228,18 @@ func (m AppModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case "ctrl+b":
+	case actionBackground:
 		if m.agentRunning {
-			return m.detachToBackground()
+			return m.detachToBackground("")
 		}
 		if m.sh.bgManager != nil && m.sh.bgManager.Count() > 0 {
 			m.overlay = NewBackgroundViewModel(m.sh.bgManager.List(), m.width, m.height)
 		}
 		return m, nil
 
-	case "ctrl+e":
+	case actionQueue:
 		if len(m.promptQueue) > 0 {
-			m.overlay = NewQueueViewModel(m.promptQueue, m.width)
+			m.overlay = NewQueueViewModel(m.promptQueue, m.width).WithNext(m.queueNext)
 			return m, nil
 		}
 		// No queue: fall through to editor (end-of-line)
@@ -919,22 +1247,14 @@ func (m AppModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.editor = updated.(EditorModel)
 		return m, cmd
 
-	case "shift+enter":
-		// Alternative submit: same behavior as Enter for message submission.
-		// Distinct from Enter in terminals that support Kitty keyboard protocol.
-		if !m.editor.IsEmpty() {
-			return m.submitOrEnqueue()
-		}
-		updated, cmd := m.editor.Update(msg)
-		m.editor = updated.(EditorModel)
-		return m, cmd
-
-	case "alt+enter":
+	case actionQueueFollowUp:
 		// Force-enqueue: always adds to queue without submitting,
 		// even when the agent is idle.
 		return m.enqueuePrompt()
 
-	case "enter":
+	case actionSubmit:
+		// Enter, and Shift+Enter in terminals that tell them apart
+		// (Kitty keyboard protocol).
 		if !m.editor.IsEmpty() {
 			return m.submitOrEnqueue()
 		}
@@ -942,6 +1262,30 @@ func (m AppModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		updated, cmd := m.editor.Update(msg)
 		m.editor = updated.(EditorModel)
 		return m, cmd
+	}
+
+	// Fixed keys
+	switch msg.String() {
+	case "esc":
+		// Always forward ESC to editor first so the split-OSC guard
+		// can arm itself. If terminal sent \x1b]…\x1b\, BubbleTea
+		// delivers KeyEscape then plain ']'; the editor needs to see
+		// the ESC to suppress the ']' that follows.
+		editorUpdated, editorCmd := m.editor.Update(msg)
+		m.editor = editorUpdated.(EditorModel)
+
+		if m.agentRunning {
+			m.abortAgent()
+			m.plan = m.plan.Fail()
+			return m, tea.Batch(editorCmd, func() tea.Msg { return AgentCancelMsg{} })
+		}
+		if m.editTarget != nil {
+			return m.cancelEdit(), editorCmd
+		}
+		// NOTE: ESC on an idle prompt is intentionally a no-op to the user.
+		// The editor starts a split-ESC timer (200ms) for OSC safety. This is
+		// by design: if no ']' follows, the timeout fires and clears the state.
+		return m, editorCmd
 
 	case "tab":
 		// Tab accepts ghost text when no overlay is open
@@ -1045,10 +1389,47 @@ func (m AppModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.editor = updated.(EditorModel)
 		// Compute ghost text after each editor update
 		m.editor = m.editor.SetGhostText(m.computeGhostText())
+		if warm := m.prewarmCmd(); warm != nil {
+			m.prewarmed = true
+			return m, tea.Batch(cmd, warm)
+		}
 		return m, cmd
 	}
 }
 
+// prewarmCmd returns a command that prepares the provider for the next turn
+// while the user types a follow-up, or nil when prewarm is off, already done
+// since the last turn, or the agent is busy. Failures only cost the head start.
+func (m AppModel) prewarmCmd() tea.Cmd {
+	mode := m.deps.Prewarm.EffectiveMode()
+	if mode != config.PrewarmConnection && mode != config.PrewarmPrefix {
+		return nil
+	}
+	pw, ok := m.deps.Provider.(ai.Prewarmer)
+	if !ok || m.deps.Model == nil || m.prewarmed || m.agentRunning || m.editor.IsEmpty() {
+		return nil
+	}
+	model := m.deps.Model
+	llmCtx := &ai.Context{
+		System:   m.deps.SystemPrompt,
+		Messages: slices.Clone(m.messages),
+		Tools:    buildAITools(m.deps.Tools),
+	}
+	prefix := mode == config.PrewarmPrefix
+	parent := m.sh.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(parent, 30*time.Second)
+		defer cancel()
+		if err := pw.Prewarm(ctx, model, llmCtx, prefix); err != nil {
+			pilog.Debug("prewarm: %v", err)
+		}
+		return nil
+	}
+}
+
 // --- Prompt submission ---
 
 // submitOrEnqueue handles enter/shift+enter: if the editor has text, submit
@@ -1063,28 +1444,45 @@ func (m AppModel) submitOrEnqueue() (AppModel, tea.Cmd) {
 	// Queue edit takes priority: finish editing even if agent stopped
 	if m.queueEditIndex >= 0 {
 		if m.queueEditIndex < len(m.promptQueue) {
-			m.promptQueue[m.queueEditIndex] = text
+			items := slices.Clone(m.promptQueue)
+			items[m.queueEditIndex] = text
+			m = m.setQueue(items, m.queueNext)
 		}
 		m.queueEditIndex = -1
 		m.savedDraft = ""
 		m.editor = m.resetEditor()
 		// If agent stopped while editing, resume draining
-		if !m.agentRunning && len(m.promptQueue) > 0 {
-			next := m.promptQueue[0]
-			m.promptQueue = m.promptQueue[1:]
-			m.footer = m.footer.WithQueuedCount(len(m.promptQueue))
-			return m.submitPrompt(next)
+		if !m.agentRunning {
+			return m.drainQueue()
 		}
 		return m, nil
 	}
 
+	// Submitting an edited past prompt re-runs from it, once confirmed
+	if m.editTarget != nil && !m.agentRunning && !commands.IsCommand(text) {
+		var notice string
+		if m, notice = m.confirmRerun(text); notice != "" {
+			return m.applyNotice(notice)
+		}
+		return m, nil
+	}
+
+	// '#' facts go to memory right away instead of waiting in the queue
+	if _, ok := memoryFact(text); ok && m.deps.Memory != nil {
+		return m.submitPrompt(text)
+	}
+
+	// /detach and /tasks act on the running turn, so they never wait in the queue
+	if m.agentRunning && runsWhileBusy(text) {
+		return m.submitPrompt(text)
+	}
+
 	if m.agentRunning {
 		// Enqueue for later; history is populated when drain calls submitPrompt
-		m.promptQueue = append(m.promptQueue, text)
+		m = m.enqueue(text)
 		m.historyIndex = -1
 		m.savedDraft = ""
 		m.editor = m.resetEditor()
-		m.footer = m.footer.WithQueuedCount(len(m.promptQueue))
 		return m, nil
 	}
 
@@ -1098,17 +1496,16 @@ func (m AppModel) enqueuePrompt() (AppModel, tea.Cmd) {
 		return m, nil
 	}
 
-	text := m.editor.Text()
-	m.promptQueue = append(m.promptQueue, text)
+	m = m.enqueue(m.editor.Text())
 	m.historyIndex = -1
 	m.savedDraft = ""
 	m.editor = m.resetEditor()
-	m.footer = m.footer.WithQueuedCount(len(m.promptQueue))
 	return m, nil
 }
 
 func (m AppModel) submitPrompt(text string) (AppModel, tea.Cmd) {
 	m.editor = m.resetEditor()
+	m.suggestion = ""
 
 	// Track history
 	m.promptHistory = append(m.promptHistory, text)
@@ -1116,6 +1513,11 @@ func (m AppModel) submitPrompt(text string) (AppModel, tea.Cmd) {
 	m.queueEditIndex = -1
 	m.savedDraft = ""
 
+	// "# fact" remembers fact in a memory file chosen from the picker
+	if fact, ok := memoryFact(text); ok && m.deps.Memory != nil {
+		return m.openMemoryPicker(fact), nil
+	}
+
 	// Check for commands BEFORE adding to messages/content to avoid
 	// slash command text polluting the AI conversation.
 	if commands.IsCommand(text) {
@@ -1123,6 +1525,9 @@ func (m AppModel) submitPrompt(text string) (AppModel, tea.Cmd) {
 			// Bash commands: show in content but don't add to AI messages
 			um := NewUserMsgModel(text)
 			m.content = append(m.content, um)
+			if strings.HasPrefix(text, "!!") {
+				return m.handleInteractiveBash(text[2:])
+			}
 			return m.handleBashCommand(text[1:])
 		}
 		// Slash commands: no user message in content or AI messages
@@ -1133,31 +1538,69 @@ func (m AppModel) submitPrompt(text string) (AppModel, tea.Cmd) {
 	um := NewUserMsgModel(text)
 	m.content = append(m.content, um)
 
-	// Expand @file mentions before sending to AI
+	// Expand @file, @dir/, and @Symbol mentions before sending to AI
 	expandedText := text
 	if strings.Contains(text, "@") {
 		workDir := m.gitCWD
 		if workDir == "" {
 			workDir, _ = os.Getwd()
 		}
-		if cleaned, _, err := ide.ParseMentions(text, workDir); err == nil {
+		if cleaned, _, err := ide.ParseMentions(text, workDir, mentionSymbols(workDir), tools.IsSecretFile); err == nil {
 			expandedText = cleaned
 		}
 	}
 
+	// Prepend skills whose trigger keywords appear for the first time
+	if m.deps.Skills != nil {
+		if skillCtx := m.deps.Skills.Activate(text); skillCtx != "" {
+			expandedText = skillCtx + expandedText
+		}
+	}
+
+	// Prepend the live editor selection pushed by a connected IDE, if new
+	if m.deps.IDELink != nil {
+		if ideCtx := m.deps.IDELink.TakeContext(); ideCtx != "" {
+			expandedText = ideCtx + "\n" + expandedText
+		}
+	}
+
 	// Add to conversation history (with expanded file content)
 	m.messages = append(m.messages, ai.NewTextMessage(ai.RoleUser, expandedText))
+	m = m.evictOldToolResults()
 
 	// Persist user message to session (if wired)
 	if m.deps.Session != nil {
 		_ = m.deps.Session.AddUserMessage(text)
+		_ = m.journal().BeginTurn(text)
 	}
+	m.deps.Checkpoints.BeginTurn()
 
 	// Start agent
 	m.agentRunning = true
 	return m, m.startAgentCmd()
 }
 
+// evictOldToolResults shrinks large tool results from turns older than the
+// configured age and adds what was removed to the session totals.
+func (m AppModel) evictOldToolResults() AppModel {
+	ce := m.deps.ContextEviction
+	if !ce.IsEnabled() {
+		return m
+	}
+	pinned := make(map[string]bool)
+	for _, name := range ce.EffectivePinnedTools() {
+		pinned[name] = true
+	}
+	msgs, stats := session.EvictToolResults(m.messages, session.EvictionPolicy{
+		AfterTurns:     ce.EffectiveAfterTurns(),
+		MaxResultBytes: ce.EffectiveMaxResultBytes(),
+		PinnedTools:    pinned,
+	})
+	m.messages = msgs
+	m.evicted.Add(stats)
+	return m
+}
+
 func (m AppModel) handleBashCommand(command string) (AppModel, tea.Cmd) {
 	m.bashRunning = true
 	cmd := command
@@ -1171,18 +1614,48 @@ func (m AppModel) handleBashCommand(command string) (AppModel, tea.Cmd) {
 	}
 }
 
-func runBashCommand(command string) (string, int) {
-	// Use /bin/bash with full path to avoid PATH issues
-	cmd := exec.Command("/bin/bash", "-c", command)
-	output, err := cmd.CombinedOutput()
+// interactiveBashNote stands in for the output of a "!!" command, which
+// went to the terminal.
+const interactiveBashNote = "(ran interactively; output not captured)"
+
+// handleInteractiveBash runs "!!command" in the foreground with the
+// terminal handed over, for programs that need one (pagers, editors,
+// git rebase -i). The TUI is repainted in full when it exits.
+func (m AppModel) handleInteractiveBash(command string) (AppModel, tea.Cmd) {
+	sh, err := shell.Current()
 	if err != nil {
-		exitCode := 1
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			exitCode = exitErr.ExitCode()
+		return m, func() tea.Msg { return BashDoneMsg{Command: command, Output: err.Error(), ExitCode: 1} }
+	}
+	m.bashRunning = true
+	cmd := sh.Command(context.Background(), command)
+	return m, m.execProcess(cmd, func(err error) tea.Msg {
+		return BashDoneMsg{
+			Command:  command,
+			Output:   interactiveBashNote,
+			ExitCode: bashExitCode(err),
 		}
-		return string(output), exitCode
+	})
+}
+
+func runBashCommand(command string) (string, int) {
+	sh, err := shell.Current()
+	if err != nil {
+		return err.Error(), 1
 	}
-	return string(output), 0
+	output, err := sh.Command(context.Background(), command).CombinedOutput()
+	return string(output), bashExitCode(err)
+}
+
+// bashExitCode returns the exit status err reports: 0 for nil, 1 when the
+// command did not run to an exit.
+func bashExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return exitErr.ExitCode()
+	}
+	return 1
 }
 
 func (m AppModel) handleSlashCommand(text string) (AppModel, tea.Cmd) {
@@ -1205,7 +1678,6 @@ func generateTaskID() string {
 }
 
 func (m AppModel) startAgentCmd() tea.Cmd {
-	program := m.sh.program
 	sh := m.sh // shared pointer for agent assignment
 	deps := m.deps
 	messages := make([]ai.Message, len(m.messages))
@@ -1232,7 +1704,7 @@ func (m AppModel) startAgentCmd() tea.Cmd {
 	}
 
 	return func() tea.Msg {
-		if program == nil {
+		if sh.program == nil {
 			return AgentErrorMsg{Err: fmt.Errorf("program reference not set")}
 		}
 		if deps.Provider == nil || deps.Model == nil {
@@ -1253,6 +1725,7 @@ func (m AppModel) startAgentCmd() tea.Cmd {
 		}
 		if thinkingLevel != config.ThinkingOff && deps.Model.SupportsThinking {
 			opts.Thinking = true
+			opts.ThinkingLevel = thinkingLevel.String()
 		}
 
 		// Per-agent child context: cancelled when agent completes to prevent goroutine leaks.
@@ -1270,7 +1743,11 @@ func (m AppModel) startAgentCmd() tea.Cmd {
 		permCheckFn := func(tool string, args map[string]any) error {
 			currentFG, _ := sh.fgTaskID.Load().(string)
 			if currentFG != taskID {
-				return fmt.Errorf("permission denied: task running in background")
+				err := fmt.Errorf("permission denied: task running in background")
+				if deps.Checker != nil {
+					deps.Checker.Record(permission.Decision{Tool: tool, Args: args, Source: permission.SourceMode, Reason: err.Error()})
+				}
+				return err
 			}
 			if deps.Checker == nil {
 				return nil
@@ -1282,27 +1759,39 @@ func (m AppModel) startAgentCmd() tea.Cmd {
 
 			// Checker says "needs approval": ask the user via the TUI dialog.
 			replyCh := make(chan PermissionReply, 1)
-			program.Send(PermissionRequestMsg{
+			sh.send(PermissionRequestMsg{
 				Tool:    tool,
 				Args:    args,
 				ReplyCh: replyCh,
 			})
 			select {
 			case reply := <-replyCh:
+				decision := permission.Decision{Tool: tool, Args: args, Allowed: reply.Allowed, Source: permission.SourceUser}
 				if !reply.Allowed {
-					return fmt.Errorf("tool %q denied by user", tool)
+					err := fmt.Errorf("tool %q denied by user", tool)
+					decision.Reason = err.Error()
+					deps.Checker.Record(decision)
+					return err
 				}
+				deps.Checker.Record(decision)
 				if reply.Always {
 					deps.Checker.AddAllowRule(permission.Rule{Tool: tool})
 				}
 				return nil
 			case <-agCtx.Done():
+				deps.Checker.Record(permission.Decision{Tool: tool, Args: args, Source: permission.SourceUser, Reason: "permission check cancelled"})
 				return fmt.Errorf("permission check cancelled")
 			}
 		}
 
 		ag := agent.NewWithPermissions(deps.Provider, deps.Model, deps.Tools, permCheckFn)
 		sh.activeAgent.Store(ag) // enable cancellation via abortAgent()
+		if deps.Minion != nil {
+			ag.SetMinion(deps.Minion)
+		}
+		if deps.Reminders != nil {
+			ag.SetReminders(deps.Reminders)
+		}
 
 		// Wire adaptive performance if probe has completed
 		if profile != nil {
@@ -1311,21 +1800,25 @@ func (m AppModel) startAgentCmd() tea.Cmd {
 			})
 		}
 
-		events := ag.Prompt(agCtx, llmCtx, opts)
+		// Reads whose results left the conversation must be served in full again.
+		sh.readCache.Retain(messages)
+		// File changes are checkpointed per pane, for /undo.
+		toolCtx := tools.WithCheckpoints(tools.WithReadCache(agCtx, sh.readCache), deps.Checkpoints)
+		events := ag.Prompt(toolCtx, llmCtx, opts)
 
 		// Route events based on foreground/background state.
 		// If fgTaskID still matches, we're foreground: send to program.
 		// If it changed (detached via Ctrl+B), events are silently discarded.
 		// The agent still runs to completion; results come via BackgroundTaskDoneMsg.
-		for evt := range events {
-			msg := bridgeEventToMsg(evt)
-			if msg == nil {
-				continue
-			}
+		// Text deltas are batched into one message per frame.
+		stats := forwardEvents(events, func(msg tea.Msg) {
 			currentFG, _ := sh.fgTaskID.Load().(string)
 			if currentFG == taskID {
-				program.Send(msg)
+				sh.send(msg)
 			}
+		}, streamFrameInterval)
+		if stats.Deltas > 0 {
+			pilog.Debug("stream: %s", stats)
 		}
 
 		sh.activeAgent.Store(nil) // clear agent reference after completion
@@ -1336,8 +1829,8 @@ func (m AppModel) startAgentCmd() tea.Cmd {
 			return AgentDoneMsg{Messages: llmCtx.Messages}
 		}
 
-		// We were backgrounded: notify via program.Send.
-		program.Send(BackgroundTaskDoneMsg{
+		// We were backgrounded: notify via the program.
+		sh.send(BackgroundTaskDoneMsg{
 			TaskID:   taskID,
 			Prompt:   promptText,
 			Messages: llmCtx.Messages,
@@ -1426,10 +1919,22 @@ func (m AppModel) autoCompact() (tea.Model, tea.Cmd) {
 			Messages:    result.Messages,
 			Summary:     result.Summary,
 			TokensSaved: tokensBefore - tokensAfter,
+			PinnedKept:  result.PinnedKept,
 		}
 	}
 }
 
+// setPinned sets the pinned flag of message i, copying the slice so models
+// sharing it, such as a running agent's context, are unaffected.
+func (m AppModel) setPinned(i int, pinned bool) AppModel {
+	if i < 0 || i >= len(m.messages) || m.messages[i].Pinned == pinned {
+		return m
+	}
+	m.messages = slices.Clone(m.messages)
+	m.messages[i].Pinned = pinned
+	return m
+}
+
 // --- Internal helpers ---
 
 // isDropdownOverlay returns true for overlays that should render inline
@@ -1523,25 +2028,114 @@ func (m AppModel) toggleMode() AppModel {
 		m.mode = ModePlan
 	}
 	m.footer = m.footer.WithModeLabel(m.mode.String())
-	return m
+	return m.syncReminders()
 }
 
 func (m AppModel) cycleThinking() AppModel {
 	next := (m.thinkingLevel.Index() + 1) % 6
 	m.thinkingLevel = config.ThinkingLevelFromIndex(next)
-	m.footer = m.footer.WithThinking(m.thinkingLevel)
+	m.saveThinkingLevel()
+	return m.showThinking()
+}
+
+// showThinking puts the thinking level and its provider budget in the footer.
+func (m AppModel) showThinking() AppModel {
+	m.footer = m.footer.WithThinking(m.thinkingLevel).
+		WithThinkingBudget(thinkingBudgetLabel(m.thinkingLevel, m.deps.Model))
 	return m
 }
 
+// saveThinkingLevel records the thinking level for the current model, so
+// switching back to it, or the next run, starts at that level.
+func (m AppModel) saveThinkingLevel() {
+	if m.deps.Model == nil || m.deps.Model.ID == "" {
+		return
+	}
+	level := m.thinkingLevel.String()
+	if m.deps.ThinkingLevels == nil {
+		m.deps.ThinkingLevels = make(map[string]string)
+	}
+	m.deps.ThinkingLevels[m.deps.Model.ID] = level
+	if save := m.deps.OnThinkingChange; save != nil {
+		if err := save(m.deps.Model.ID, level); err != nil {
+			pilog.Warn("saving thinking level: %v", err)
+		}
+	}
+}
+
+// savedThinkingLevel returns the thinking level saved for the model of
+// deps, or ThinkingOff.
+func savedThinkingLevel(deps AppDeps) config.ThinkingLevel {
+	if deps.Model == nil {
+		return config.ThinkingOff
+	}
+	return config.ThinkingLevelFromString(deps.ThinkingLevels[deps.Model.ID])
+}
+
+// thinkingBudgetLabel describes what a thinking level asks of the model's
+// provider: OpenAI's reasoning effort, or a thinking token budget, which
+// Gemini caps lower than Anthropic.
+func thinkingBudgetLabel(level config.ThinkingLevel, model *ai.Model) string {
+	if level == config.ThinkingOff || model == nil {
+		return ""
+	}
+	if !model.SupportsThinking {
+		return "not supported"
+	}
+	budget := ai.ThinkingBudget(level.String())
+	switch model.Api {
+	case ai.ApiOpenAI:
+		return "effort " + ai.ReasoningEffort(level.String())
+	case ai.ApiGoogle, ai.ApiVertex:
+		budget = min(budget, gemini.MaxThinkingBudget)
+	}
+	return formatTokens(budget)
+}
+
 func (m AppModel) abortAgent() {
 	if ag := m.sh.activeAgent.Load(); ag != nil {
 		ag.Abort()
 	}
 }
 
+// trackFanOut mirrors a minion fan-out into the background task list so its
+// progress is visible in the background view while the main agent waits.
+func (m AppModel) trackFanOut(p agent.FanOutProgress) AppModel {
+	if m.sh.bgManager == nil {
+		return m
+	}
+	progress := fmt.Sprintf("%d/%d files", p.Done, p.Total)
+	if p.Failed > 0 {
+		progress += fmt.Sprintf(", %d failed", p.Failed)
+	}
+	if !m.sh.bgManager.SetProgress(p.ID, progress) {
+		task := &BackgroundTask{
+			ID:        p.ID,
+			Prompt:    "minion fan-out: " + p.Instruction,
+			StartedAt: time.Now(),
+			Status:    BGRunning,
+			Progress:  progress,
+		}
+		if err := m.sh.bgManager.Add(task); err != nil {
+			// At the task limit; the tool call still streams per-file progress.
+			return m
+		}
+	}
+	if p.Finished {
+		var err error
+		if p.Failed > 0 {
+			err = fmt.Errorf("%d of %d files failed", p.Failed, p.Total)
+		}
+		m.sh.bgManager.MarkDone(p.ID, nil, err)
+	}
+	m.footer = m.footer.WithBackgroundCount(m.sh.bgManager.Count())
+	return m
+}
+
 // detachToBackground moves the currently running foreground agent into
-// the background task list so the user can continue typing.
-func (m AppModel) detachToBackground() (AppModel, tea.Cmd) {
+// the background task list so the user can continue typing. A non-empty
+// name labels the task instead of its ID.
+func (m AppModel) detachToBackground(name string) (AppModel, tea.Cmd) {
 	if m.sh.bgManager == nil {
 		return m, nil
 	}
@@ -1573,6 +2167,7 @@ func (m AppModel) detachToBackground() (AppModel, tea.Cmd) {
 
 	task := &BackgroundTask{
 		ID:        taskID,
+		Name:      name,
 		Prompt:    promptText,
 		StartedAt: time.Now(),
 		Status:    BGRunning,
@@ -1592,7 +2187,7 @@ func (m AppModel) detachToBackground() (AppModel, tea.Cmd) {
 
 	// Inline notification
 	m = m.ensureAssistantMsg()
-	m = m.updateLastAssistant(AgentTextMsg{Text: "\n⏎ Task detached to background [" + taskID + "]"})
+	m = m.updateLastAssistant(AgentTextMsg{Text: "\n⏎ Task detached to background [" + task.Label() + "]"})
 
 	// Update footer
 	m.footer = m.footer.WithBackgroundCount(m.sh.bgManager.Count())
@@ -1617,10 +2212,14 @@ func (m AppModel) modelName() string {
 	return ""
 }
 
-// computeGhostText returns the completion suffix for the current editor text.
-// Only active when text starts with "/" and has no spaces.
+// computeGhostText returns the completion suffix for the current editor text:
+// the rest of a suggested next prompt the text begins, or the rest of a
+// slash command when text starts with "/" and has no spaces.
 func (m AppModel) computeGhostText() string {
 	text := m.editor.Text()
+	if ghost := m.suggestionGhost(text); ghost != "" {
+		return ghost
+	}
 	if !strings.HasPrefix(text, "/") || strings.Contains(text, " ") {
 		return ""
 	}
@@ -1679,3 +2278,14 @@ func detectGitCWD() string {
 	}
 	return strings.TrimSpace(string(out))
 }
+
+// hasText reports whether msg carries any non-blank text; replies that only
+// call tools are not saved, since session records keep text alone.
+func hasText(msg ai.Message) bool {
+	for _, c := range msg.Content {
+		if c.Type == ai.ContentText && strings.TrimSpace(c.Text) != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -78,3 +78,28 @@ func TestAppModel_AgentCancelMsg(t *testing.T) {
 		t.Fatal("expected content after AgentCancelMsg")
 	}
 }
+
+func TestAppModel_CtrlZWithoutProgramIsNoop(t *testing.T) {
+	m := NewAppModel(testDeps())
+	m.editor = m.editor.SetText("draft")
+
+	result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlZ})
+	model := result.(AppModel)
+
+	if cmd != nil {
+		t.Error("cmd != nil; want no suspend without a running program")
+	}
+	if got := model.editor.Text(); got != "draft" {
+		t.Errorf("editor text = %q; Ctrl+Z must not reach the editor", got)
+	}
+}
+
+func TestAppModel_ResumeMsgKeepsState(t *testing.T) {
+	m := NewAppModel(testDeps())
+	m.agentRunning = true
+
+	result, _ := m.Update(tea.ResumeMsg{})
+	if !result.(AppModel).agentRunning {
+		t.Error("resume must not reset a running agent turn")
+	}
+}
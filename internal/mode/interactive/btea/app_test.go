@@ -6,6 +6,8 @@ package btea
 import (
 	"fmt"
 	"os"
+	"os/exec"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -459,6 +461,91 @@ func TestAppModel_CycleThinking(t *testing.T) {
 	}
 }
 
+func TestAppModel_CycleThinkingSavesLevelPerModel(t *testing.T) {
+	deps := testDeps()
+	deps.Model = &ai.Model{ID: "m1", Name: "M1", Api: ai.ApiAnthropic, SupportsThinking: true}
+	deps.ThinkingLevels = map[string]string{"m2": "high"}
+	var saved []string
+	deps.OnThinkingChange = func(model, level string) error {
+		saved = append(saved, model+"="+level)
+		return nil
+	}
+	m := NewAppModel(deps)
+
+	key := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'t'}, Alt: true}
+	result, _ := m.Update(key)
+	result, _ = result.(AppModel).Update(key)
+	m = result.(AppModel)
+	if m.thinkingLevel != config.ThinkingLow {
+		t.Fatalf("thinking = %v; want ThinkingLow", m.thinkingLevel)
+	}
+	if want := []string{"m1=minimal", "m1=low"}; !slices.Equal(saved, want) {
+		t.Errorf("saved = %v; want %v", saved, want)
+	}
+	if view := m.footer.View(); !strings.Contains(view, "[low, 4K]") {
+		t.Errorf("footer missing thinking budget; got %q", view)
+	}
+
+	result, _ = m.Update(ModelSelectedMsg{Model: ModelEntry{ID: "m2", Name: "M2"}})
+	m = result.(AppModel)
+	if m.thinkingLevel != config.ThinkingHigh {
+		t.Errorf("after switching to m2: thinking = %v; want its saved ThinkingHigh", m.thinkingLevel)
+	}
+	result, _ = m.Update(ModelSelectedMsg{Model: ModelEntry{ID: "m1", Name: "M1"}})
+	m = result.(AppModel)
+	if m.thinkingLevel != config.ThinkingLow {
+		t.Errorf("after switching back to m1: thinking = %v; want ThinkingLow", m.thinkingLevel)
+	}
+}
+
+func TestAppModel_ExpandThinkingAndCountTokens(t *testing.T) {
+	m := NewAppModel(testDeps())
+	m.width = 80
+	result, _ := m.Update(AgentThinkingMsg{Text: strings.Repeat("z", 400)})
+	result, _ = result.(AppModel).Update(AgentTextMsg{Text: "done"})
+	m = result.(AppModel)
+
+	key := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'k'}, Alt: true}
+	result, _ = m.Update(key)
+	m = result.(AppModel)
+	am, ok := m.content[len(m.content)-1].(*AssistantMsgModel)
+	if !ok || !am.thinkingExpanded {
+		t.Fatal("alt+k did not expand the latest thinking")
+	}
+	if got := m.lastAssistantText(); got != "done" {
+		t.Errorf("lastAssistantText() = %q; want the reply without thinking", got)
+	}
+
+	result, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	m = result.(AppModel)
+	cv, ok := m.overlay.(CostViewModel)
+	if !ok {
+		t.Fatalf("overlay = %T; want CostViewModel", m.overlay)
+	}
+	if cv.totalThinking != 100 {
+		t.Errorf("totalThinking = %d; want 100", cv.totalThinking)
+	}
+}
+
+func TestThinkingBudgetLabel(t *testing.T) {
+	tests := []struct {
+		level config.ThinkingLevel
+		model *ai.Model
+		want  string
+	}{
+		{config.ThinkingOff, &ai.Model{Api: ai.ApiAnthropic, SupportsThinking: true}, ""},
+		{config.ThinkingHigh, &ai.Model{Api: ai.ApiAnthropic, SupportsThinking: true}, "24K"},
+		{config.ThinkingXHigh, &ai.Model{Api: ai.ApiGoogle, SupportsThinking: true}, "24K"},
+		{config.ThinkingXHigh, &ai.Model{Api: ai.ApiOpenAI, SupportsThinking: true}, "effort high"},
+		{config.ThinkingLow, &ai.Model{Api: ai.ApiAnthropic}, "not supported"},
+	}
+	for _, tt := range tests {
+		if got := thinkingBudgetLabel(tt.level, tt.model); got != tt.want {
+			t.Errorf("thinkingBudgetLabel(%v, %s) = %q; want %q", tt.level, tt.model.Api, got, tt.want)
+		}
+	}
+}
+
 func TestAppModel_OverlayRoutesMessages(t *testing.T) {
 	m := NewAppModel(testDeps())
 	ch := make(chan PermissionReply, 1)
@@ -1187,6 +1274,25 @@ func TestAppModel_HandleBashCommandReturnsCmd(t *testing.T) {
 	}
 }
 
+func TestAppModel_InteractiveBashRunsInForeground(t *testing.T) {
+	m := NewAppModel(testDeps())
+	m.width = 80
+	m.editor = m.editor.SetText("!!exit 3")
+
+	result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model := result.(AppModel)
+	if cmd == nil || !model.bashRunning {
+		t.Fatalf("!! should start a foreground command; cmd=%v running=%v", cmd, model.bashRunning)
+	}
+
+	if _, ok := model.content[len(model.content)-1].(UserMsgModel); !ok {
+		t.Error("the command should be shown like a ! command")
+	}
+	if got := bashExitCode(exec.Command("/bin/bash", "-c", "exit 3").Run()); got != 3 {
+		t.Errorf("exit code = %d; want 3", got)
+	}
+}
+
 func TestAppModel_BashDoneMsgCreatesOutputModel(t *testing.T) {
 	m := NewAppModel(testDeps())
 	m.width = 80
@@ -1663,3 +1769,35 @@ func TestAppModel_OSCTimeoutRoutedToEditor(t *testing.T) {
 		t.Error("body timeout should propagate chained timeout cmd")
 	}
 }
+
+func TestAppModel_SpinnerTicksWhileToolsRun(t *testing.T) {
+	m := NewAppModel(testDeps())
+	m.agentRunning = true
+
+	result, cmd := m.Update(AgentToolStartMsg{ToolID: "t1", ToolName: "Bash", Args: map[string]any{"command": "sleep 5"}})
+	m = result.(AppModel)
+	if cmd == nil || !m.spinning {
+		t.Fatal("starting a tool call should schedule a spinner tick")
+	}
+	result, cmd = m.Update(AgentToolStartMsg{ToolID: "t2", ToolName: "Bash"})
+	m = result.(AppModel)
+	if cmd != nil {
+		t.Error("a second tool call should not schedule another tick")
+	}
+
+	result, cmd = m.Update(SpinnerTickMsg{})
+	m = result.(AppModel)
+	if cmd == nil {
+		t.Error("tick should reschedule while a call runs")
+	}
+
+	for _, id := range []string{"t1", "t2"} {
+		result, _ = m.Update(AgentToolEndMsg{ToolID: id, Text: "ok"})
+		m = result.(AppModel)
+	}
+	result, cmd = m.Update(SpinnerTickMsg{})
+	m = result.(AppModel)
+	if cmd != nil || m.spinning {
+		t.Error("ticks should stop once no call runs")
+	}
+}
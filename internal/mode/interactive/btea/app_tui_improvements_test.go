@@ -465,10 +465,11 @@ func TestAppModel_CtrlO_TogglesToolCallExpand(t *testing.T) {
 	})
 	m = result.(AppModel)
 
+	contents := strings.Repeat("file contents here\n", 30)
 	result, _ = m.Update(AgentToolEndMsg{
 		ToolID: "t1",
-		Text:   "file contents here",
-		Result: &agent.ToolResult{Content: "file contents here"},
+		Text:   contents,
+		Result: &agent.ToolResult{Content: contents},
 	})
 	m = result.(AppModel)
 
@@ -520,17 +521,22 @@ func TestAppModel_CtrlO_ExpandedOutputVisibleInView(t *testing.T) {
 		Args:     map[string]any{"path": "/tmp/test.go"},
 	})
 	m = result.(AppModel)
+	// The marker sits past the preview, so it is folded until Ctrl+O
+	output := strings.Repeat("preview line\n", 20) + "UNIQUE_OUTPUT_MARKER_FOR_TEST"
 	result, _ = m.Update(AgentToolEndMsg{
 		ToolID: "t1",
-		Text:   "UNIQUE_OUTPUT_MARKER_FOR_TEST",
-		Result: &agent.ToolResult{Content: "UNIQUE_OUTPUT_MARKER_FOR_TEST"},
+		Text:   output,
+		Result: &agent.ToolResult{Content: output},
 	})
 	m = result.(AppModel)
 
-	// Before Ctrl+O: output should NOT be visible
+	// Before Ctrl+O: only the preview should be visible
 	viewBefore := m.View()
 	if strings.Contains(viewBefore, "UNIQUE_OUTPUT_MARKER_FOR_TEST") {
-		t.Error("output should not be visible when collapsed")
+		t.Error("output past the preview should not be visible when collapsed")
+	}
+	if !strings.Contains(viewBefore, "preview line") || !strings.Contains(viewBefore, "21 lines, 11 more folded") {
+		t.Error("collapsed view should show the preview under a summary header")
 	}
 	if !strings.Contains(viewBefore, "Ctrl+O to expand") {
 		t.Error("collapsed view should show expand hint")
@@ -1,5 +1,5 @@
 // ABOUTME: AssistantMsgModel is a Bubble Tea leaf that renders assistant responses
-// ABOUTME: Uses ordered content blocks to preserve chronological text/tool interleaving
+// ABOUTME: Uses ordered content blocks to preserve chronological text/thinking/tool interleaving
 
 package btea
 
@@ -9,23 +9,25 @@ import (
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mauromedda/pi-coding-agent-go/internal/session"
 	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/width"
 )
 
-// blockKind distinguishes text blocks from tool call blocks in the content stream.
+// blockKind distinguishes text, thinking, and tool call blocks in the content stream.
 type blockKind int
 
 const (
 	blockText blockKind = iota
 	blockTool
+	blockThinking
 )
 
 // contentBlock represents a single chronological unit in the assistant response:
-// either a text segment or a reference to a tool call.
+// a text segment, a thinking segment, or a reference to a tool call.
 type contentBlock struct {
 	kind blockKind
 
-	// blockText fields
+	// blockText and blockThinking fields
 	text        string
 	cachedLines []string
 	cachedWidth int
@@ -36,15 +38,15 @@ type contentBlock struct {
 }
 
 // AssistantMsgModel renders an assistant's response with streamed text,
-// thinking indicator, error messages, and inline tool call sub-models.
-// Content blocks preserve chronological ordering of text and tool calls.
+// collapsible thinking, error messages, and inline tool call sub-models.
+// Content blocks preserve chronological ordering of text, thinking, and tool calls.
 type AssistantMsgModel struct {
-	blocks    []contentBlock
-	curText   strings.Builder // accumulator for current text block
-	thinking  string
-	errors    []string
-	toolCalls []ToolCallModel
-	width     int
+	blocks           []contentBlock
+	curText          strings.Builder // accumulator for current text block
+	thinkingExpanded bool            // show thinking text instead of the one-line summary
+	errors           []string
+	toolCalls        []ToolCallModel
+	width            int
 
 	// Markdown rendering (lazily initialized)
 	mdRenderer *MarkdownRenderer
@@ -107,7 +109,17 @@ func (m *AssistantMsgModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.flushCurText()
 
 	case AgentThinkingMsg:
-		m.thinking = msg.Text
+		m.appendThinking(msg.Text)
+
+	case ToggleThinkingMsg:
+		m.thinkingExpanded = !m.thinkingExpanded
+
+	case ThemeChangedMsg:
+		// Rendered Markdown carries the old palette; render again.
+		m.mdRenderer = nil
+		for i := range m.blocks {
+			m.blocks[i].cachedLines = nil
+		}
 
 	case AgentToolStartMsg:
 		// Flush any pending text into its block, then start a new text accumulator
@@ -141,11 +153,20 @@ func (m *AssistantMsgModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+	case SpinnerTickMsg:
+		for i := range m.toolCalls {
+			if !m.toolCalls[i].done {
+				updated, _ := m.toolCalls[i].Update(msg)
+				m.toolCalls[i] = updated.(ToolCallModel)
+			}
+		}
+
 	case AgentErrorMsg:
 		m.errors = append(m.errors, msg.Err.Error())
 
 	case tea.KeyMsg:
-		for i := range m.toolCalls {
+		// Ctrl+O expands (or collapses) only the latest call with folded output
+		if i := m.foldingCall(); i >= 0 {
 			updated, _ := m.toolCalls[i].Update(msg)
 			m.toolCalls[i] = updated.(ToolCallModel)
 		}
@@ -167,6 +188,60 @@ func (m *AssistantMsgModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// appendThinking adds streamed thinking to the last block when it is a
+// thinking block, or starts one; text after it starts a new text block.
+func (m *AssistantMsgModel) appendThinking(text string) {
+	if text == "" {
+		return
+	}
+	n := len(m.blocks)
+	if n > 0 && m.blocks[n-1].kind == blockThinking {
+		m.blocks[n-1].text += text
+		return
+	}
+	m.flushCurText()
+	m.curText.Reset()
+	m.blocks = append(m.blocks, contentBlock{kind: blockThinking, text: text})
+}
+
+// hasThinking reports whether the response holds any thinking, so the
+// expand-thinking key has something to toggle.
+func (m *AssistantMsgModel) hasThinking() bool {
+	for i := range m.blocks {
+		if m.blocks[i].kind == blockThinking {
+			return true
+		}
+	}
+	return false
+}
+
+// thinkingLines renders a thinking block: dimmed and wrapped when expanded,
+// otherwise one line with its estimated size.
+func (m *AssistantMsgModel) thinkingLines(block *contentBlock) []string {
+	s := Styles()
+	if !m.thinkingExpanded {
+		label := fmt.Sprintf("▸ Thinking… (%s tokens)", formatThinkingTokens(session.EstimateTokens(block.text)))
+		return []string{s.Dim.Render(label)}
+	}
+	w := m.width
+	if w <= 0 {
+		w = 80
+	}
+	lines := []string{s.Dim.Render("▾ Thinking")}
+	for _, line := range width.WrapTextWithAnsi(strings.TrimSpace(block.text), max(w-2, 20)) {
+		lines = append(lines, s.Dim.Render(line))
+	}
+	return lines
+}
+
+// formatThinkingTokens formats a token count as 850 or 1.2k.
+func formatThinkingTokens(n int) string {
+	if n < 1000 {
+		return fmt.Sprintf("%d", n)
+	}
+	return fmt.Sprintf("%.1fk", float64(n)/1000)
+}
+
 // ensureRenderer lazily creates the markdown renderer.
 func (m *AssistantMsgModel) ensureRenderer() *MarkdownRenderer {
 	if m.mdRenderer == nil {
@@ -175,6 +250,22 @@ func (m *AssistantMsgModel) ensureRenderer() *MarkdownRenderer {
 	return m.mdRenderer
 }
 
+// renderMarkdown renders text in two parts split at its last stable
+// paragraph boundary. The settled part comes from the renderer's cache, so
+// while a reply streams only the paragraph being written is rendered again.
+func (m *AssistantMsgModel) renderMarkdown(text string, w int) string {
+	r := m.ensureRenderer()
+	split := stableSplit(text)
+	if split == 0 {
+		return r.Render(text, w)
+	}
+	head, tail := r.Render(text[:split], w), r.Render(text[split:], w)
+	if head == "" || tail == "" {
+		return head + tail
+	}
+	return head + "\n" + tail
+}
+
 // wrapBlockLines returns cached wrapped lines for a content block,
 // refreshing the cache when text or width changes.
 func (m *AssistantMsgModel) wrapBlockLines(block *contentBlock) []string {
@@ -198,7 +289,7 @@ func (m *AssistantMsgModel) wrapBlockLines(block *contentBlock) []string {
 	}
 
 	// Use markdown renderer for styled output
-	rendered := m.ensureRenderer().Render(block.text, contentWidth)
+	rendered := m.renderMarkdown(block.text, contentWidth)
 	if rendered != "" {
 		block.cachedLines = strings.Split(rendered, "\n")
 	} else {
@@ -209,7 +300,7 @@ func (m *AssistantMsgModel) wrapBlockLines(block *contentBlock) []string {
 	return block.cachedLines
 }
 
-// View renders the assistant message with thinking indicator, text, and tool calls.
+// View renders the assistant message with thinking, text, and tool calls.
 // Content blocks are rendered in chronological order to preserve interleaving.
 func (m *AssistantMsgModel) View() string {
 	s := Styles()
@@ -220,18 +311,6 @@ func (m *AssistantMsgModel) View() string {
 	// Blank line before assistant content
 	b.WriteString("\n")
 
-	// Thinking indicator
-	if m.thinking != "" {
-		b.WriteString(fmt.Sprintf("%s %s %s\n", borderChar, s.Info.Render("⠋"), s.Dim.Render("Thinking...")))
-	}
-
-	// Divider between thinking and text when both present
-	if m.thinking != "" && m.hasText() {
-		divWidth := max(m.width-2, 1)
-		divider := s.AssistantBorder.Render("─")
-		b.WriteString(fmt.Sprintf("%s %s\n", borderChar, strings.Repeat(divider, divWidth)))
-	}
-
 	// Content blocks in chronological order
 	for i := range m.blocks {
 		block := &m.blocks[i]
@@ -241,6 +320,10 @@ func (m *AssistantMsgModel) View() string {
 			for _, line := range lines {
 				b.WriteString(fmt.Sprintf("%s %s\n", borderChar, line))
 			}
+		case blockThinking:
+			for _, line := range m.thinkingLines(block) {
+				b.WriteString(fmt.Sprintf("%s %s\n", borderChar, line))
+			}
 		case blockTool:
 			if block.toolIdx < len(m.toolCalls) {
 				b.WriteString("\n")
@@ -257,3 +340,24 @@ func (m *AssistantMsgModel) View() string {
 
 	return b.String()
 }
+
+// running reports whether any of the message's tool calls is still running.
+func (m *AssistantMsgModel) running() bool {
+	for i := range m.toolCalls {
+		if !m.toolCalls[i].done {
+			return true
+		}
+	}
+	return false
+}
+
+// foldingCall returns the index of the latest tool call whose output is
+// longer than the collapsed preview, or -1 when there is none.
+func (m *AssistantMsgModel) foldingCall() int {
+	for i := len(m.toolCalls) - 1; i >= 0; i-- {
+		if m.toolCalls[i].folds() {
+			return i
+		}
+	}
+	return -1
+}
@@ -260,20 +260,54 @@ func TestAssistantMsgModel_ViewHasLeftBorder(t *testing.T) {
 	}
 }
 
-func TestAssistantMsgModel_ThinkingTextDivider(t *testing.T) {
+func TestAssistantMsgModel_ThinkingCollapsedBeforeText(t *testing.T) {
 	m := &AssistantMsgModel{}
 	m.width = 80
 
-	// Add thinking then text
-	updated, _ := m.Update(AgentThinkingMsg{Text: "reasoning"})
-	m1 := updated.(*AssistantMsgModel)
-	updated2, _ := m1.Update(AgentTextMsg{Text: "Here is my answer"})
-	m2 := updated2.(*AssistantMsgModel)
+	m.Update(AgentTextMsg{Text: "First. "})
+	m.Update(AgentThinkingMsg{Text: strings.Repeat("x", 2000)})
+	m.Update(AgentThinkingMsg{Text: strings.Repeat("y", 2800)})
+	m.Update(AgentTextMsg{Text: "Then the answer."})
 
-	view := m2.View()
-	// Should have a divider between thinking and text sections
-	if !strings.Contains(view, "─") {
-		t.Errorf("View() missing divider between thinking and text; got %q", view)
+	view := m.View()
+	summary := strings.Index(view, "Thinking… (1.2k tokens)")
+	if summary < 0 {
+		t.Fatalf("View() missing collapsed thinking summary; got %q", view)
+	}
+	if strings.Contains(view, "xxxx") {
+		t.Errorf("collapsed View() shows the thinking text; got %q", view)
+	}
+	if first, answer := strings.Index(view, "First."), strings.Index(view, "Then the answer."); first > summary || answer < summary {
+		t.Errorf("thinking not between the two text blocks; got %q", view)
+	}
+	if got := m.Text(); got != "First. Then the answer." {
+		t.Errorf("Text() = %q; want the text without thinking", got)
+	}
+}
+
+func TestAssistantMsgModel_ToggleThinking(t *testing.T) {
+	m := &AssistantMsgModel{}
+	m.width = 80
+	m.Update(AgentThinkingMsg{Text: "weighing the options"})
+	if !m.hasThinking() {
+		t.Fatal("hasThinking() = false after a thinking delta")
+	}
+
+	m.Update(ToggleThinkingMsg{})
+	if view := m.View(); !strings.Contains(view, "weighing the options") {
+		t.Errorf("expanded View() missing thinking text; got %q", view)
+	}
+	m.Update(ToggleThinkingMsg{})
+	if view := m.View(); strings.Contains(view, "weighing the options") {
+		t.Errorf("collapsed View() shows thinking text; got %q", view)
+	}
+}
+
+func TestFormatThinkingTokens(t *testing.T) {
+	for n, want := range map[int]string{0: "0", 850: "850", 1000: "1.0k", 1234: "1.2k", 15000: "15.0k"} {
+		if got := formatThinkingTokens(n); got != want {
+			t.Errorf("formatThinkingTokens(%d) = %q; want %q", n, got, want)
+		}
 	}
 }
 
@@ -338,10 +372,11 @@ func TestAssistantMsgModel_KeyMsgForwardedToToolCalls(t *testing.T) {
 	// Add a completed tool call
 	updated, _ := m.Update(AgentToolStartMsg{ToolID: "t1", ToolName: "Read", Args: map[string]any{}})
 	m1 := updated.(*AssistantMsgModel)
+	contents := strings.Repeat("file contents\n", 30)
 	updated2, _ := m1.Update(AgentToolEndMsg{
 		ToolID: "t1",
-		Text:   "file contents",
-		Result: &agent.ToolResult{Content: "file contents"},
+		Text:   contents,
+		Result: &agent.ToolResult{Content: contents},
 	})
 	m2 := updated2.(*AssistantMsgModel)
 
@@ -429,3 +464,43 @@ func TestAssistantMsgModel_TextMethodConcatenatesBlocks(t *testing.T) {
 		t.Errorf("Text() = %q; want %q", got, "Part1 Part2")
 	}
 }
+
+func TestAssistantMsgModel_StreamingRendersSettledParagraphsOnce(t *testing.T) {
+	m := NewAssistantMsgModel()
+	m.width = 60
+	m.Update(AgentTextMsg{Text: "First paragraph is done.\n\nSecond is"})
+	_ = m.View()
+	m.Update(AgentTextMsg{Text: " still streaming."})
+	view := m.View()
+
+	if !strings.Contains(view, "First paragraph is done.") || !strings.Contains(view, "Second is still streaming.") {
+		t.Errorf("View() = %q; want both paragraphs", view)
+	}
+	head := "First paragraph is done.\n\n"
+	if _, ok := m.mdRenderer.cache[cacheKey(head, 58)]; !ok {
+		t.Error("the settled paragraph should be rendered on its own and cached")
+	}
+	if _, ok := m.mdRenderer.cache[cacheKey(m.Text(), 58)]; ok {
+		t.Error("the whole reply should not be rendered again for each delta")
+	}
+}
+
+func TestAssistantMsgModel_CtrlOExpandsLatestFoldedCall(t *testing.T) {
+	m := &AssistantMsgModel{}
+	m.width = 80
+	long := strings.Repeat("line\n", 30)
+	for _, call := range []struct{ id, text string }{{"t1", long}, {"t2", long}, {"t3", "short"}} {
+		m.Update(AgentToolStartMsg{ToolID: call.id, ToolName: "Read", Args: map[string]any{}})
+		m.Update(AgentToolEndMsg{ToolID: call.id, Text: call.text, Result: &agent.ToolResult{Content: call.text}})
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlO})
+	if m.toolCalls[0].expanded || !m.toolCalls[1].expanded || m.toolCalls[2].expanded {
+		t.Errorf("Ctrl+O should expand only the latest folded call; expanded = %v, %v, %v",
+			m.toolCalls[0].expanded, m.toolCalls[1].expanded, m.toolCalls[2].expanded)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlO})
+	if m.toolCalls[1].expanded {
+		t.Error("a second Ctrl+O should collapse it again")
+	}
+}
@@ -44,12 +44,22 @@ func (s BackgroundStatus) String() string {
 // BackgroundTask holds metadata and results for a detached agent run.
 type BackgroundTask struct {
 	ID        string
+	Name      string // optional, given with /detach <name> or /tasks name
 	Prompt    string
 	StartedAt time.Time
 	Status    BackgroundStatus
 	Messages  []ai.Message // populated on completion
 	Err       error
 	Cancel    context.CancelFunc
+	Progress  string // optional, e.g. "12/40 files" for minion fan-outs
+}
+
+// Label returns the task's name, or its ID when it has none.
+func (t *BackgroundTask) Label() string {
+	if t.Name != "" {
+		return t.Name
+	}
+	return t.ID
 }
 
 // Snapshot returns a shallow copy of the task with a copied Messages slice.
@@ -111,6 +121,36 @@ func (m *BackgroundManager) Get(id string) *BackgroundTask {
 	return &snap
 }
 
+// Find returns a snapshot copy of the task whose ID or name is ref, or nil.
+// IDs are matched first.
+func (m *BackgroundManager) Find(ref string) *BackgroundTask {
+	if t := m.Get(ref); t != nil {
+		return t
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, t := range m.tasks {
+		if t.Name == ref {
+			snap := t.Snapshot()
+			return &snap
+		}
+	}
+	return nil
+}
+
+// Rename sets a task's name. Returns false if the task is unknown.
+func (m *BackgroundManager) Rename(id, name string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, ok := m.tasks[id]
+	if !ok {
+		return false
+	}
+	t.Name = name
+	return true
+}
+
 // List returns snapshot copies of all tasks. Safe to read after the lock is released.
 func (m *BackgroundManager) List() []BackgroundTask {
 	m.mu.Lock()
@@ -144,6 +184,19 @@ func (m *BackgroundManager) RunningCount() int {
 	return n
 }
 
+// SetProgress updates a task's progress label. Returns false if the task is unknown.
+func (m *BackgroundManager) SetProgress(id, progress string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, ok := m.tasks[id]
+	if !ok {
+		return false
+	}
+	t.Progress = progress
+	return true
+}
+
 // MarkDone updates a task's status to BGDone (or BGFailed if err != nil)
 // and stores the result messages.
 func (m *BackgroundManager) MarkDone(id string, messages []ai.Message, err error) {
@@ -266,3 +266,41 @@ func TestBackgroundTask_Snapshot(t *testing.T) {
 type bgTestError struct{ msg string }
 
 func (e *bgTestError) Error() string { return e.msg }
+
+func TestBackgroundManager_SetProgress(t *testing.T) {
+	mgr := NewBackgroundManager(nil)
+	if mgr.SetProgress("missing", "1/2") {
+		t.Error("SetProgress on unknown task should return false")
+	}
+	_ = mgr.Add(&BackgroundTask{ID: "fan-1", Status: BGRunning})
+	if !mgr.SetProgress("fan-1", "1/2 files") {
+		t.Fatal("SetProgress on known task should return true")
+	}
+	if got := mgr.Get("fan-1").Progress; got != "1/2 files" {
+		t.Errorf("Progress = %q", got)
+	}
+}
+
+func TestBackgroundManager_FindAndRename(t *testing.T) {
+	mgr := NewBackgroundManager(nil)
+	_ = mgr.Add(&BackgroundTask{ID: "bg-001", Prompt: "run the suite", Status: BGRunning})
+
+	task := mgr.Get("bg-001")
+	if task.Label() != "bg-001" {
+		t.Errorf("Label() = %q; want the ID while unnamed", task.Label())
+	}
+	if !mgr.Rename("bg-001", "tests") {
+		t.Fatal("Rename() = false; want true")
+	}
+	if mgr.Rename("bg-404", "x") {
+		t.Error("Rename() of an unknown task = true; want false")
+	}
+	for _, ref := range []string{"bg-001", "tests"} {
+		if got := mgr.Find(ref); got == nil || got.ID != "bg-001" || got.Label() != "tests" {
+			t.Errorf("Find(%q) = %+v; want the named task", ref, got)
+		}
+	}
+	if got := mgr.Find("missing"); got != nil {
+		t.Errorf("Find(missing) = %+v; want nil", got)
+	}
+}
@@ -1,4 +1,4 @@
-// ABOUTME: BackgroundViewModel is a Bubble Tea overlay listing background tasks
+// ABOUTME: BackgroundViewModel is a Bubble Tea overlay listing background tasks by name or ID
 // ABOUTME: Navigate (j/k), review completed (Enter), dismiss (d), cancel running (c), close (Esc)
 
 package btea
@@ -145,23 +145,24 @@ func (m BackgroundViewModel) View() string {
 	if len(m.tasks) == 0 {
 		writeBoxLine(&b, border, s.Dim.Render("(no background tasks)"), contentWidth)
 	} else {
-		maxW := contentWidth - 12 // status icon + ID prefix
-		if maxW < 10 {
-			maxW = 10
-		}
 		for i, task := range m.tasks {
 			prefix := "  "
 			if i == m.cursor {
 				prefix = "> "
 			}
 
+			// Room left after the cursor, status icon, and bracketed label
+			maxW := max(contentWidth-6-width.VisibleWidth(task.Label()), 10)
 			icon := statusIcon(task.Status)
 			prompt := task.Prompt
+			if task.Progress != "" {
+				prompt = task.Progress + " · " + prompt
+			}
 			if width.VisibleWidth(prompt) > maxW {
 				prompt = width.TruncateToWidth(prompt, maxW-3) + "..."
 			}
 
-			line := fmt.Sprintf("%s%s [%s] %s", prefix, icon, task.ID, prompt)
+			line := fmt.Sprintf("%s%s [%s] %s", prefix, icon, task.Label(), prompt)
 			if i == m.cursor {
 				writeBoxLine(&b, border, s.Selection.Render(line), contentWidth)
 			} else {
@@ -184,7 +185,7 @@ func (m BackgroundViewModel) View() string {
 func statusIcon(s BackgroundStatus) string {
 	switch s {
 	case BGRunning:
-		return "⠋"
+		return glyphs.Spinner[0]
 	case BGDone:
 		return "✓"
 	case BGFailed:
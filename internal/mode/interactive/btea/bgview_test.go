@@ -180,3 +180,13 @@ func TestBackgroundViewModel_EmptyView(t *testing.T) {
 		t.Error("View missing empty message")
 	}
 }
+
+func TestBackgroundViewModel_ShowsProgress(t *testing.T) {
+	tasks := []BackgroundTask{
+		{ID: "fan-1", Prompt: "minion fan-out: add docs", Status: BGRunning, Progress: "3/40 files", StartedAt: time.Now()},
+	}
+	view := NewBackgroundViewModel(tasks, 120, 24).View()
+	if !strings.Contains(view, "3/40 files · minion fan-out") {
+		t.Errorf("View() missing progress:\n%s", view)
+	}
+}
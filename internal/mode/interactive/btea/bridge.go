@@ -29,7 +29,7 @@ func bridgeEventToMsg(evt agent.AgentEvent) tea.Msg {
 			Args:     evt.ToolArgs,
 		}
 	case agent.EventToolUpdate:
-		return AgentToolUpdateMsg{ToolID: evt.ToolID, Text: evt.Text}
+		return AgentToolUpdateMsg{ToolID: evt.ToolID, Text: evt.Text, Step: evt.ToolStep}
 	case agent.EventToolEnd:
 		msg := AgentToolEndMsg{
 			ToolID: evt.ToolID,
@@ -42,6 +42,11 @@ func bridgeEventToMsg(evt agent.AgentEvent) tea.Msg {
 		return msg
 	case agent.EventUsageUpdate:
 		return AgentUsageMsg{Usage: evt.Usage}
+	case agent.EventModelRoute:
+		if evt.Route == nil {
+			return nil
+		}
+		return AgentRouteMsg{Route: *evt.Route}
 	case agent.EventError:
 		return AgentErrorMsg{Err: evt.Error}
 	default:
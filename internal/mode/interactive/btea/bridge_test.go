@@ -105,6 +105,20 @@ func TestRunAgentBridge_EventMapping(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:  "composite step update carries the step",
+			event: agent.AgentEvent{Type: agent.EventToolUpdate, ToolID: "t2", ToolStep: &agent.ToolStep{Index: 1, Tool: "read"}},
+			check: func(t *testing.T, msg tea.Msg) {
+				t.Helper()
+				m, ok := msg.(AgentToolUpdateMsg)
+				if !ok {
+					t.Fatalf("got %T; want AgentToolUpdateMsg", msg)
+				}
+				if m.Step == nil || m.Step.Index != 1 || m.Step.Tool != "read" {
+					t.Errorf("Step = %+v; want index 1, tool read", m.Step)
+				}
+			},
+		},
 		{
 			name: "tool end maps to AgentToolEndMsg",
 			event: agent.AgentEvent{
@@ -141,6 +155,20 @@ func TestRunAgentBridge_EventMapping(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:  "model route maps to AgentRouteMsg",
+			event: agent.AgentEvent{Type: agent.EventModelRoute, Route: &agent.ModelRoute{Model: "Mini", Minion: true, Reason: "auto"}},
+			check: func(t *testing.T, msg tea.Msg) {
+				t.Helper()
+				m, ok := msg.(AgentRouteMsg)
+				if !ok {
+					t.Fatalf("got %T; want AgentRouteMsg", msg)
+				}
+				if !m.Route.Minion || m.Route.Model != "Mini" {
+					t.Errorf("Route = %+v; want minion Mini", m.Route)
+				}
+			},
+		},
 		{
 			name:  "error maps to AgentErrorMsg",
 			event: agent.AgentEvent{Type: agent.EventError, Error: errTest},
@@ -7,16 +7,29 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
 	"github.com/mauromedda/pi-coding-agent-go/internal/commands"
 	"github.com/mauromedda/pi-coding-agent-go/internal/config"
 	"github.com/mauromedda/pi-coding-agent-go/internal/export"
+	"github.com/mauromedda/pi-coding-agent-go/internal/fetchcache"
+	pilog "github.com/mauromedda/pi-coding-agent-go/internal/log"
+	"github.com/mauromedda/pi-coding-agent-go/internal/memory"
+	"github.com/mauromedda/pi-coding-agent-go/internal/offline"
+	"github.com/mauromedda/pi-coding-agent-go/internal/permission"
+	"github.com/mauromedda/pi-coding-agent-go/internal/personality"
 	"github.com/mauromedda/pi-coding-agent-go/internal/session"
 	"github.com/mauromedda/pi-coding-agent-go/internal/revert"
+	"github.com/mauromedda/pi-coding-agent-go/internal/timefmt"
+	"github.com/mauromedda/pi-coding-agent-go/internal/tools"
 	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
 	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/clipboard"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/theme"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/width"
 )
 
 // cmdSideEffects captures signals from command callbacks that need to
@@ -26,6 +39,29 @@ type cmdSideEffects struct {
 	clearTUI    bool
 	modeToggled bool
 	modelName   string // non-empty = model changed
+
+	systemPrompt string // non-empty = system prompt rebuilt (output style changed)
+	minionMode   string // non-empty = minion mode changed
+
+	permissions *config.PermissionsConfig // non-nil = permissions block rewritten
+
+	pinView     bool                // open the pin overlay
+	contextView bool                // open the /context overlay
+	debugLog    bool                // open the /debug log overlay
+	memoryView  bool                // open the memory file picker
+	themeView   bool                // open the theme picker
+	theme       string              // non-empty = apply and save this theme
+	share       *export.SharePlan   // non-nil = confirm and upload a share
+	forkPicker  bool                // open the /fork picker
+	forked      *session.ForkResult // non-nil = switch to this fork
+	undo        bool                // drop the last turn and restore its files
+	detach      *string             // non-nil = detach the running turn under this name
+	tasksView   bool                // open the background tasks overlay
+	split       *string             // non-nil = open a pane beside this one, running this prompt
+	initProject *string             // non-nil = run the /init analysis with this extra instruction
+	planAction  string              // non-empty = pause, resume, or stop the running plan
+	pin         *MessagePinMsg      // non-nil = pin or unpin one message
+	reload      bool                // re-read settings and apply what changed
 }
 
 // buildCommandContext creates a CommandContext with ALL callbacks wired as
@@ -35,6 +71,7 @@ func (m AppModel) buildCommandContext() (*commands.CommandContext, *cmdSideEffec
 
 	// Mutable mode copy for toggle within closure scope.
 	currentMode := m.mode
+	readStats := m.sh.readCache.Stats()
 
 	ctx := &commands.CommandContext{
 		Model:       m.modelName(),
@@ -45,6 +82,13 @@ func (m AppModel) buildCommandContext() (*commands.CommandContext, *cmdSideEffec
 		TotalTokens: m.totalInputTokens + m.totalOutputTokens,
 		Messages:    len(m.messages),
 
+		EvictedResults: m.evicted.Results,
+		EvictedBytes:   m.evicted.Bytes,
+
+		ReadCacheReads: readStats.Reads,
+		ReadCacheHits:  readStats.Hits,
+		ReadCacheSaved: readStats.BytesSaved,
+
 		// --- Core callbacks ---
 
 		ExitFn: func() {
@@ -64,7 +108,7 @@ func (m AppModel) buildCommandContext() (*commands.CommandContext, *cmdSideEffec
 				return "Nothing to compact."
 			}
 			if m.sh.program != nil {
-				m.sh.program.Send(AutoCompactMsg{})
+				m.sh.send(AutoCompactMsg{})
 			}
 			return "Compacting context..."
 		},
@@ -93,28 +137,34 @@ func (m AppModel) buildCommandContext() (*commands.CommandContext, *cmdSideEffec
 
 		// --- Session management ---
 
-		RenameSession: func(name string) {
-			// Session rename is a no-op placeholder; JSONL files are named by ID.
-			// Future: add metadata record with display name.
-		},
 		ResumeSession: nil, // requires overlay flow
 		ListSessionsFn: func() string {
-			sessions, err := session.ListSessions()
+			sessions, err := session.ListSessionsInDir(m.sessionsDir())
 			if err != nil {
 				return fmt.Sprintf("Error listing sessions: %v", err)
 			}
 			if len(sessions) == 0 {
 				return "No sessions found."
 			}
+			tf := timefmt.New(m.deps.Display)
 			var b strings.Builder
 			b.WriteString("Sessions:\n")
 			for _, s := range sessions {
-				fmt.Fprintf(&b, "  %s (model: %s, cwd: %s)\n", s.ID, s.Model, s.CWD)
+				title := ""
+				if s.Title != "" {
+					title = fmt.Sprintf(" %q", s.Title)
+				}
+				fmt.Fprintf(&b, "  %s %s%s (model: %s, cwd: %s)\n", s.ID, tf.Format(s.StartedAt), title, s.Model, s.CWD)
 			}
 			return b.String()
 		},
-		SessionTreeFn: nil,
-		ForkSessionFn: nil,
+		AgentsFn: func() string {
+			return formatAgentDefinitions(m.deps.Agents, m.deps.Tools)
+		},
+
+		SessionTreeFn: func() string {
+			return m.sessionTreeText()
+		},
 
 		NewSessionFn: func() {
 			effects.clearTUI = true
@@ -126,6 +176,8 @@ func (m AppModel) buildCommandContext() (*commands.CommandContext, *cmdSideEffec
 			return fmt.Sprintf("Permission mode: %s", m.deps.PermissionMode.String())
 		},
 
+		KeybindingsFn: m.deps.Keys.hotkeys,
+
 		ToggleVim:  nil, // vim mode not yet implemented in editor
 		VimEnabled: nil,
 
@@ -163,11 +215,22 @@ func (m AppModel) buildCommandContext() (*commands.CommandContext, *cmdSideEffec
 			return b.String()
 		},
 
-		PermissionManagerFn: func() string {
+		PermissionManagerFn: func(arg string) (string, error) {
 			if m.deps.Checker == nil {
-				return "No permission checker configured."
+				return "No permission checker configured.", nil
+			}
+			if arg == "" {
+				return formatPermissions(m.deps.PermissionMode, m.deps.Permissions, m.deps.ApplyAutonomy != nil), nil
 			}
-			return fmt.Sprintf("Permission mode: %s\nChecker active: yes", m.deps.PermissionMode.String())
+			if m.deps.ApplyAutonomy == nil {
+				return "Changing permissions is not available.", nil
+			}
+			p, err := m.deps.ApplyAutonomy(arg)
+			if err != nil {
+				return "", err
+			}
+			effects.permissions = p
+			return "Saved to project settings.\n" + config.ExplainPermissions(p), nil
 		},
 
 		GetSettings: func() string {
@@ -205,7 +268,7 @@ func (m AppModel) buildCommandContext() (*commands.CommandContext, *cmdSideEffec
 		// --- Export ---
 
 		ExportConversation: func(path string) error {
-			return exportMessagesAsMarkdown(m.messages, path)
+			return m.exportMessagesAsMarkdown(path)
 		},
 
 		ExportHTMLFn: func(path string) error {
@@ -214,16 +277,19 @@ func (m AppModel) buildCommandContext() (*commands.CommandContext, *cmdSideEffec
 				return fmt.Errorf("create file: %w", err)
 			}
 			defer f.Close()
-			return export.ExportHTML(m.messages, f)
+			return export.ExportHTMLWithOptions(m.redactedExport(), f, m.exportOptions())
 		},
 
 		ShareFn: func() string {
-			md := formatMessagesAsMarkdown(m.messages)
-			url, err := export.CreateGist(md, "Conversation export", false)
+			if m.deps.Offline {
+				return fmt.Sprintf("Share failed: %v", offline.Unavailable("/share (uploads a GitHub gist)", "use /export <file>.html to save the conversation locally"))
+			}
+			plan, err := m.prepareShare()
 			if err != nil {
 				return fmt.Sprintf("Share failed: %v", err)
 			}
-			return fmt.Sprintf("Shared: %s", url)
+			effects.share = plan
+			return ""
 		},
 
 		// --- Diff / Revert ---
@@ -244,16 +310,371 @@ func (m AppModel) buildCommandContext() (*commands.CommandContext, *cmdSideEffec
 			return revert.FormatSummary(summary), nil
 		},
 
+		UndoTurnFn: func() (string, error) {
+			effects.undo = true
+			return "", nil
+		},
+
 		// --- Reload ---
 
 		ReloadFn: func() (string, error) {
-			return "Config reloaded.", nil
+			if m.deps.ReloadSettings == nil {
+				return "Reload not available.", nil
+			}
+			effects.reload = true
+			return "", nil
 		},
 	}
 
+	if engine := m.deps.Personality; engine != nil {
+		ctx.ListOutputStylesFn = func() string {
+			return formatOutputStyles(engine)
+		}
+		ctx.SetOutputStyleFn = func(name string) error {
+			if err := engine.SetOutputStyle(name); err != nil {
+				return err
+			}
+			if m.deps.OnOutputStyleChange == nil {
+				return nil
+			}
+			sys, err := m.deps.OnOutputStyleChange(name)
+			if err != nil {
+				return err
+			}
+			effects.systemPrompt = sys
+			return nil
+		}
+	}
+
+	ctx.ThemeFn = func(name string) (string, error) {
+		if name == "" {
+			effects.themeView = true
+			return "", nil
+		}
+		if _, err := theme.Resolve(name, m.deps.ThemeDirs); err != nil {
+			return "", fmt.Errorf("%w (available: %s)", err, strings.Join(theme.Available(m.deps.ThemeDirs), ", "))
+		}
+		effects.theme = name
+		return "", nil
+	}
+
+	if minion := m.deps.Minion; minion != nil {
+		ctx.MinionFn = func(arg string) (string, error) {
+			if arg == "" {
+				return formatMinionStatus(minion), nil
+			}
+			mode := agent.MinionOff
+			if arg == "toggle" {
+				if minion.Mode() == agent.MinionOff {
+					mode = agent.MinionAuto
+				}
+			} else {
+				var err error
+				if mode, err = agent.ParseMinionMode(arg); err != nil {
+					return "", err
+				}
+			}
+			minion.SetMode(mode)
+			effects.minionMode = mode.String()
+			return formatMinionStatus(minion), nil
+		}
+	}
+
+	if m.deps.Session != nil {
+		ctx.RenameSession = func(name string) {
+			_ = m.deps.Session.SetTitle(name, false)
+		}
+		ctx.ForkPickerFn = func() {
+			effects.forkPicker = true
+		}
+		ctx.ForkSessionFn = func() (string, error) {
+			res, err := session.Fork(m.sessionsDir(), m.deps.Session.ID)
+			if err != nil {
+				return "", err
+			}
+			effects.forked = res
+			return res.NewID, nil
+		}
+	}
+
+	if m.sh.bgManager != nil {
+		ctx.TasksFn = func(arg string) (string, error) {
+			if arg == "" {
+				effects.tasksView = true
+				return "", nil
+			}
+			return m.nameTask(arg)
+		}
+		ctx.DetachFn = func(name string) (string, error) {
+			if !m.agentRunning {
+				return "Nothing is running to detach.", nil
+			}
+			effects.detach = &name
+			return "", nil
+		}
+	}
+
+	ctx.PlanFn = func(action string) (string, error) {
+		out, err := m.planAction(action)
+		if err == nil && m.plan.Active() {
+			effects.planAction = action
+		}
+		return out, err
+	}
+
+	if m.sh.pane != 0 {
+		ctx.SplitFn = func(prompt string) (string, error) {
+			effects.split = &prompt
+			return "", nil
+		}
+	}
+
+	ctx.InitFn = func(focus string) (string, error) {
+		effects.initProject = &focus
+		return "", nil
+	}
+
+	ctx.PinnedMessages, ctx.PinnedTokens = session.PinnedStats(m.messages)
+	ctx.ContextViewFn = func() {
+		effects.contextView = true
+	}
+	if mem := m.deps.Memory; mem != nil {
+		entries, _ := memory.Load(mem.ProjectDir, mem.HomeDir, tools.IsSecretFile)
+		for _, e := range entries {
+			ctx.MemoryEntries = append(ctx.MemoryEntries, shortenHome(e.Source, mem.HomeDir))
+		}
+		ctx.MemoryEditFn = func() {
+			effects.memoryView = true
+		}
+	}
+	if pilog.Path() != "" {
+		ctx.DebugLogFn = func() {
+			effects.debugLog = true
+		}
+	}
+	ctx.PinFn = func(arg string) (string, error) {
+		if arg == "list" {
+			return formatPinned(m.messages), nil
+		}
+		if m.agentRunning {
+			return "Wait for the agent to finish before changing pins.", nil
+		}
+		if len(m.messages) == 0 {
+			return "No messages to pin.", nil
+		}
+		if arg == "" {
+			effects.pinView = true
+			return "", nil
+		}
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 1 || n > len(m.messages) {
+			return "", fmt.Errorf("no message %q (use 1-%d, list, or no argument to pick)", arg, len(m.messages))
+		}
+		pinned := !m.messages[n-1].Pinned
+		effects.pin = &MessagePinMsg{Index: n - 1, Pinned: pinned}
+		if pinned {
+			return fmt.Sprintf("Pinned message %d: compaction will keep it verbatim.", n), nil
+		}
+		return fmt.Sprintf("Unpinned message %d.", n), nil
+	}
+
+	if cache := m.deps.FetchCache; cache != nil {
+		ctx.CacheFn = func(arg string) (string, error) {
+			switch arg {
+			case "", "stats":
+				return formatCacheStats(cache.Stats()), nil
+			case "clear":
+				if err := cache.Clear(); err != nil {
+					return "", err
+				}
+				return "Fetch cache cleared.", nil
+			default:
+				return "", fmt.Errorf("unknown subcommand %q (use stats or clear)", arg)
+			}
+		}
+	}
+
+	if m.deps.Settings != nil && m.deps.Settings.Safety.DeniesSecretReads() {
+		ctx.AllowReadFn = func(path string) (string, error) {
+			tools.AllowSecretFile(path)
+			return fmt.Sprintf("Allowed reading %s for this session.", path), nil
+		}
+	}
+
+	if pools := m.deps.KeyPools; len(pools) > 0 {
+		ctx.KeyUsageFn = func() string {
+			return formatKeyUsage(pools)
+		}
+	}
+
 	return ctx, effects
 }
 
+// formatPinned lists the pinned messages with their /pin numbers.
+func formatPinned(msgs []ai.Message) string {
+	var b strings.Builder
+	for i, msg := range msgs {
+		if !msg.Pinned {
+			continue
+		}
+		if b.Len() == 0 {
+			b.WriteString("Pinned messages:")
+		}
+		label := messageLabel(msg)
+		if width.VisibleWidth(label) > 80 {
+			label = width.TruncateToWidth(label, 77) + "..."
+		}
+		fmt.Fprintf(&b, "\n  %d. %s", i+1, label)
+	}
+	if b.Len() == 0 {
+		return "No pinned messages. Use /pin to choose some."
+	}
+	return b.String()
+}
+
+// formatCacheStats summarizes fetch cache contents and this session's hit rate.
+func formatCacheStats(st fetchcache.Stats) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Fetch cache: %d entries (%d persisted for docs), %d distinct, %.1f KB\n",
+		st.Entries, st.Persisted, st.Blobs, float64(st.Bytes)/1024)
+	fmt.Fprintf(&b, "This session: %d hits, %d misses, %d revalidated", st.Hits, st.Misses, st.Revalidated)
+	return b.String()
+}
+
+// formatKeyUsage lists each pooled key's token use against its cap and
+// whether it is currently skipped.
+func formatKeyUsage(pools []*ai.KeyPool) string {
+	var b strings.Builder
+	for i, pool := range pools {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		fmt.Fprintf(&b, "API keys (%s):", pool.Api())
+		for _, u := range pool.Usage() {
+			used := u.InputTokens + u.OutputTokens
+			fmt.Fprintf(&b, "\n  %-12s %d requests, %d in / %d out tokens", u.Name, u.Requests, u.InputTokens, u.OutputTokens)
+			if u.MaxTokens > 0 {
+				fmt.Fprintf(&b, " (%.0f%% of %d)", 100*float64(used)/float64(u.MaxTokens), u.MaxTokens)
+			}
+			switch {
+			case u.Exhausted:
+				b.WriteString(", exhausted")
+			case u.CoolingDown:
+				b.WriteString(", rate-limited")
+			}
+			if u.RateLimited > 0 {
+				fmt.Fprintf(&b, ", %d rejected", u.RateLimited)
+			}
+		}
+	}
+	return b.String()
+}
+
+// formatMinionStatus describes the minion model and its routing mode.
+func formatMinionStatus(minion *agent.Minion) string {
+	var desc string
+	switch minion.Mode() {
+	case agent.MinionAuto:
+		desc = "serves turns until context grows or a tool fails, then escalates"
+	case agent.MinionForce:
+		desc = "serves every call; escalates only if it fails"
+	case agent.MinionPlural:
+		desc = "like auto, and the main model can fan per-file edits out to parallel minions"
+	default:
+		desc = "every call goes to the main model"
+	}
+	return fmt.Sprintf("Minion %s: %s (%s)", minion.Model.Name, minion.Mode(), desc)
+}
+
+// formatAgentDefinitions lists sub-agent definitions with their model, tools,
+// source, and any validation problems.
+func formatAgentDefinitions(defs []agent.Definition, tools []*agent.AgentTool) string {
+	if len(defs) == 0 {
+		return "No agent definitions loaded."
+	}
+	toolNames := make([]string, 0, len(tools))
+	for _, t := range tools {
+		toolNames = append(toolNames, t.Name)
+	}
+	resolveModel := func(name string) error {
+		_, err := config.ResolveModel(name)
+		return err
+	}
+
+	var b strings.Builder
+	invalid := 0
+	b.WriteString("Agents (delegate with the task tool):\n")
+	for _, d := range defs {
+		problems := d.Validate(toolNames, resolveModel)
+		status := "ok"
+		if len(problems) > 0 {
+			status = fmt.Sprintf("%d problem(s)", len(problems))
+			invalid++
+		}
+		source := d.Source
+		if source == "" {
+			source = "builtin"
+		}
+		model := d.Model
+		if model == "" {
+			model = agent.ModelInherit
+		}
+		toolList := "all"
+		if allow := d.ToolAllowlist(); len(allow) > 0 {
+			toolList = strings.Join(allow, ",")
+		}
+		fmt.Fprintf(&b, "\n  %s [%s]\n", d.Name, status)
+		if d.Description != "" {
+			fmt.Fprintf(&b, "    %s\n", d.Description)
+		}
+		fmt.Fprintf(&b, "    model: %s  tools: %s  source: %s\n", model, toolList, source)
+		for _, p := range problems {
+			fmt.Fprintf(&b, "    ! %s\n", p)
+		}
+	}
+	if invalid > 0 {
+		fmt.Fprintf(&b, "\n%d of %d definitions have problems.\n", invalid, len(defs))
+	}
+	return b.String()
+}
+
+// formatOutputStyles lists the engine's output styles, marking the active one.
+func formatOutputStyles(engine *personality.Engine) string {
+	active := engine.ActiveOutputStyle()
+	var b strings.Builder
+	b.WriteString("Output styles (/output-style <name> to switch):\n")
+	for _, s := range engine.OutputStyles() {
+		marker := " "
+		if active != nil && s.Name == active.Name {
+			marker = "*"
+		}
+		fmt.Fprintf(&b, "  %s %-12s %s\n", marker, s.Name, s.Description)
+	}
+	return b.String()
+}
+
+// formatPermissions renders the /permissions overview: mode, rules, and how to change them.
+func formatPermissions(mode permission.Mode, p *config.PermissionsConfig, adjustable bool) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Permission mode: %s\n", mode.String())
+	b.WriteString(config.ExplainPermissions(p))
+	if adjustable {
+		fmt.Fprintf(&b, "\n\nAdjust with /permissions %s.", strings.Join(config.AutonomyLevels, "|"))
+	}
+	return b.String()
+}
+
+// withPermissions records a rewritten permissions block and refreshes the
+// footer from the checker, whose mode the block may have changed.
+func (m AppModel) withPermissions(p *config.PermissionsConfig) AppModel {
+	m.deps.Permissions = p
+	if m.deps.Checker != nil {
+		m.deps.PermissionMode = m.deps.Checker.Mode()
+	}
+	m.footer = m.footer.WithPermissionMode(m.deps.PermissionMode.String())
+	return m
+}
+
 // applyEffects reads the side-effect flags and mutates AppModel accordingly.
 // Returns the updated model and optional tea.Cmd.
 func (m AppModel) applyEffects(effects *cmdSideEffects, result string) (tea.Model, tea.Cmd) {
@@ -264,16 +685,103 @@ func (m AppModel) applyEffects(effects *cmdSideEffects, result string) (tea.Mode
 	if effects.clearTUI {
 		m.messages = nil
 		m.content = m.content[:0]
+		m.lastPromptTokens = 0
 		m.totalInputTokens = 0
 		m.totalOutputTokens = 0
+		m.thinkingChars = 0
 		m.footer = m.footer.WithCost(0)
-		return m, nil
+		m.undoFloor = 0
+		m.todos = m.todos.WithItems(nil)
+		m.deps.Checkpoints.Reset()
+		return m.syncReminders(), nil
 	}
 
 	if effects.modeToggled {
 		m = m.toggleMode()
 	}
 
+	if effects.systemPrompt != "" {
+		m.deps.SystemPrompt = effects.systemPrompt
+	}
+
+	if effects.minionMode != "" {
+		m.footer = m.footer.WithMinionMode(effects.minionMode)
+	}
+
+	if effects.permissions != nil {
+		m = m.withPermissions(effects.permissions)
+	}
+
+	if effects.pin != nil {
+		m = m.setPinned(effects.pin.Index, effects.pin.Pinned)
+	}
+
+	if effects.pinView {
+		m.overlay = NewPinViewModel(m.messages, m.width)
+	}
+
+	if effects.contextView {
+		m.overlay = NewContextViewModel(m.contextBreakdown(), m.width)
+	}
+
+	var debugCmd tea.Cmd
+	if effects.debugLog {
+		m.overlay = NewDebugLogModel(pilog.Path(), m.width, m.height)
+		debugCmd = debugLogTick()
+	}
+
+	if effects.memoryView {
+		m = m.openMemoryPicker("")
+	}
+
+	if effects.themeView {
+		m = m.openThemePicker()
+	}
+
+	var themeCmd tea.Cmd
+	if effects.theme != "" {
+		m, themeCmd, result = m.keepTheme(effects.theme)
+	}
+
+	var reloadCmd tea.Cmd
+	if effects.reload {
+		if m, reloadCmd, result = m.reloadSettings(); result == "" {
+			result = "Settings reloaded; nothing changed."
+		}
+	}
+
+	if effects.share != nil {
+		m.overlay = NewShareConfirmModel(effects.share, m.width)
+	}
+
+	if effects.forkPicker {
+		var notice string
+		if m, notice = m.openForkPicker(); notice != "" {
+			result = notice
+		}
+	}
+
+	if effects.undo {
+		m, result = m.undoTurn()
+	}
+
+	if effects.detach != nil {
+		m, _ = m.detachToBackground(*effects.detach)
+	}
+
+	if effects.tasksView {
+		m.overlay = NewBackgroundViewModel(m.sh.bgManager.List(), m.width, m.height)
+	}
+
+	if effects.forked != nil {
+		var err error
+		if m, err = m.switchToFork(effects.forked); err != nil {
+			result += fmt.Sprintf("\nCould not switch to the fork: %v", err)
+		} else {
+			result += " (now on the new branch)"
+		}
+	}
+
 	if effects.modelName != "" {
 		// Model change will be applied when full model resolution is wired
 		m.footer = m.footer.WithModel(effects.modelName)
@@ -286,7 +794,20 @@ func (m AppModel) applyEffects(effects *cmdSideEffects, result string) (tea.Mode
 		m.content = append(m.content, updated.(*AssistantMsgModel))
 	}
 
-	return m, nil
+	if effects.split != nil {
+		prompt := *effects.split
+		return m, func() tea.Msg { return SplitPaneMsg{Prompt: prompt} }
+	}
+
+	if effects.planAction != "" {
+		return m.applyPlanAction(effects.planAction)
+	}
+
+	if effects.initProject != nil {
+		return m.startInit(*effects.initProject)
+	}
+
+	return m, tea.Batch(themeCmd, reloadCmd, debugCmd)
 }
 
 // lastAssistantText walks content backward and returns the text of the last AssistantMsgModel.
@@ -319,24 +840,41 @@ func runGitDiff() (string, error) {
 	return strings.TrimSpace(result), nil
 }
 
-// formatMessagesAsMarkdown renders conversation messages as a markdown string.
-func formatMessagesAsMarkdown(messages []ai.Message) string {
-	var b strings.Builder
-	for _, msg := range messages {
-		fmt.Fprintf(&b, "## %s\n\n", msg.Role)
-		for _, ct := range msg.Content {
-			if ct.Type == "text" {
-				b.WriteString(ct.Text)
-				b.WriteByte('\n')
-			}
-		}
-		b.WriteByte('\n')
+// exportMessages returns the conversation with thinking retained per the
+// configured mode, for /export and /share.
+func (m AppModel) exportMessages() []ai.Message {
+	return session.RetainThinkingMessages(m.messages, m.deps.ThinkingRetention.EffectiveMode())
+}
+
+// redactedExport returns the messages to export with their secrets
+// replaced, unless redaction is off.
+func (m AppModel) redactedExport() []ai.Message {
+	msgs := m.exportMessages()
+	if m.deps.Redactor == nil {
+		return msgs
+	}
+	return m.deps.Redactor.Messages(msgs)
+}
+
+// exportOptions returns the export header and usage summary of this session.
+func (m AppModel) exportOptions() export.Options {
+	u := &export.Usage{
+		InputTokens:  m.totalInputTokens,
+		OutputTokens: m.totalOutputTokens,
+		CostUSD:      m.footer.cost,
+	}
+	if m.deps.Model != nil {
+		u.Model = m.deps.Model.ID
+	}
+	return export.Options{
+		ExportedAt: time.Now(),
+		FormatTime: timefmt.New(m.deps.Display).Absolute,
+		Usage:      u,
 	}
-	return b.String()
 }
 
-// exportMessagesAsMarkdown writes conversation messages to a file as markdown.
-func exportMessagesAsMarkdown(messages []ai.Message, path string) error {
-	md := formatMessagesAsMarkdown(messages)
+// exportMessagesAsMarkdown writes the conversation to path as Markdown.
+func (m AppModel) exportMessagesAsMarkdown(path string) error {
+	md := export.FormatMarkdown(m.redactedExport(), m.exportOptions())
 	return os.WriteFile(path, []byte(md), 0o644)
 }
@@ -0,0 +1,258 @@
+// ABOUTME: /context overlay: proportional bar of the context window by category with token counts
+// ABOUTME: System prompt, memory, tool schemas, conversation, and free space; refreshed on usage events
+
+package btea
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mauromedda/pi-coding-agent-go/internal/session"
+	"github.com/mauromedda/pi-coding-agent-go/internal/tools"
+)
+
+// contextBreakdown splits the context window into token categories.
+type contextBreakdown struct {
+	Window       int // model context window; 0 when unknown
+	System       int // system prompt, excluding memory
+	Memory       int // memory files embedded in the system prompt
+	Tools        int // tool names, descriptions, and schemas
+	Conversation int // messages, or the provider-reported remainder when larger
+
+	PinnedMessages int
+	PinnedTokens   int
+	Evicted        session.EvictionStats
+	ReadCache      tools.ReadCacheStats
+}
+
+// Used returns the tokens taken by all categories.
+func (b contextBreakdown) Used() int {
+	return b.System + b.Memory + b.Tools + b.Conversation
+}
+
+// Free returns the tokens left in the window.
+func (b contextBreakdown) Free() int {
+	return max(b.Window-b.Used(), 0)
+}
+
+// contextBreakdown estimates the current window usage. The conversation
+// estimate is raised to the provider-reported prompt size of the latest
+// call, which includes tool turns of a running agent not yet in m.messages.
+func (m AppModel) contextBreakdown() contextBreakdown {
+	b := contextBreakdown{
+		Memory:       session.EstimateTokens(m.deps.MemoryPrompt),
+		Conversation: session.EstimateMessagesTokens(m.messages),
+		Evicted:      m.evicted,
+		ReadCache:    m.sh.readCache.Stats(),
+	}
+	if m.deps.Model != nil {
+		b.Window = m.deps.Model.EffectiveContextWindow()
+	}
+	b.System = max(session.EstimateTokens(m.deps.SystemPrompt)-b.Memory, 0)
+	for _, t := range m.deps.Tools {
+		b.Tools += session.EstimateTokens(t.Name + t.Description + string(t.Parameters))
+	}
+	if reported := m.lastPromptTokens - b.System - b.Memory - b.Tools; reported > b.Conversation {
+		b.Conversation = reported
+	}
+	b.PinnedMessages, b.PinnedTokens = session.PinnedStats(m.messages)
+	return b
+}
+
+// refreshContextView updates an open /context overlay with current usage.
+func (m AppModel) refreshContextView() AppModel {
+	if cv, ok := m.overlay.(ContextViewModel); ok {
+		cv.breakdown = m.contextBreakdown()
+		m.overlay = cv
+	}
+	return m
+}
+
+// ContextViewModel displays the context window breakdown.
+type ContextViewModel struct {
+	breakdown contextBreakdown
+	width     int
+}
+
+// NewContextViewModel creates a /context overlay for b.
+func NewContextViewModel(b contextBreakdown, w int) ContextViewModel {
+	return ContextViewModel{breakdown: b, width: w}
+}
+
+// Init returns nil; no startup commands needed.
+func (m ContextViewModel) Init() tea.Cmd { return nil }
+
+// Update handles key events for the context overlay.
+func (m ContextViewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc", "q", "enter":
+			return m, func() tea.Msg { return DismissOverlayMsg{} }
+		}
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+	}
+	return m, nil
+}
+
+// contextCategory is one labelled segment of the context bar.
+type contextCategory struct {
+	label  string
+	tokens int
+	style  lipgloss.Style
+	fill   string
+}
+
+// categories returns the bar segments in display order, free space last.
+func (m ContextViewModel) categories(s ThemeStyles) []contextCategory {
+	b := m.breakdown
+	cats := []contextCategory{
+		{"System prompt", b.System, s.Primary, glyphs.BarFull},
+		{"Memory", b.Memory, s.Accent, glyphs.BarFull},
+		{"Tool schemas", b.Tools, s.Warning, glyphs.BarFull},
+		{"Conversation", b.Conversation, s.Success, glyphs.BarFull},
+	}
+	if b.Window > 0 {
+		cats = append(cats, contextCategory{"Free", b.Free(), s.Dim, glyphs.BarEmpty})
+	}
+	return cats
+}
+
+// View renders the breakdown as a bordered box with a proportional bar.
+func (m ContextViewModel) View() string {
+	s := Styles()
+	bs := s.OverlayBorder
+
+	const (
+		dash    = "─"
+		vBorder = "│"
+		tl      = "╭"
+		tr      = "╮"
+		bl      = "╰"
+		br      = "╯"
+	)
+
+	boxWidth := max(m.width*3/5, 50)
+	if boxWidth > m.width-4 {
+		boxWidth = max(m.width-4, 50)
+	}
+	innerWidth := max(boxWidth-2, 0)
+	contentWidth := max(boxWidth-4, 20)
+	border := bs.Render(vBorder)
+
+	var b strings.Builder
+
+	titleText := " Context Window "
+	title := s.OverlayTitle.Render(titleText)
+	dashesLeft := max((innerWidth-len(titleText))/2, 0)
+	dashesRight := max(innerWidth-len(titleText)-dashesLeft, 0)
+	b.WriteString(bs.Render(tl))
+	b.WriteString(bs.Render(strings.Repeat(dash, dashesLeft)))
+	b.WriteString(title)
+	b.WriteString(bs.Render(strings.Repeat(dash, dashesRight)))
+	b.WriteString(bs.Render(tr))
+	b.WriteByte('\n')
+
+	bd := m.breakdown
+	total := max(bd.Window, bd.Used())
+	if bd.Window > 0 {
+		writeBoxLine(&b, border, fmt.Sprintf("%s of %s tokens used (%.1f%%)",
+			formatNumber(bd.Used()), formatNumber(bd.Window), percent(bd.Used(), total)), contentWidth)
+	} else {
+		writeBoxLine(&b, border, fmt.Sprintf("%s tokens used (window unknown)", formatNumber(bd.Used())), contentWidth)
+	}
+
+	cats := m.categories(s)
+	tokens := make([]int, len(cats))
+	for i, c := range cats {
+		tokens[i] = c.tokens
+	}
+	var bar strings.Builder
+	for i, cells := range barCells(tokens, contentWidth) {
+		bar.WriteString(cats[i].style.Render(strings.Repeat(cats[i].fill, cells)))
+	}
+	writeBoxLine(&b, border, bar.String(), contentWidth)
+	writeBoxLine(&b, border, "", contentWidth)
+
+	for _, c := range cats {
+		line := fmt.Sprintf("%s %-14s %9s  %5.1f%%",
+			c.style.Render(c.fill), c.label, formatNumber(c.tokens), percent(c.tokens, total))
+		writeBoxLine(&b, border, line, contentWidth)
+	}
+
+	writeBoxLine(&b, border, "", contentWidth)
+	writeBoxLine(&b, border, s.Dim.Render(fmt.Sprintf("Pinned: %d messages (~%s tokens)",
+		bd.PinnedMessages, formatNumber(bd.PinnedTokens))), contentWidth)
+	writeBoxLine(&b, border, s.Dim.Render(fmt.Sprintf("Evicted: %d tool results (%.1f KB)",
+		bd.Evicted.Results, float64(bd.Evicted.Bytes)/1024)), contentWidth)
+	writeBoxLine(&b, border, s.Dim.Render(fmt.Sprintf("Read cache: %d of %d reads unchanged (%.1f KB saved)",
+		bd.ReadCache.Hits, bd.ReadCache.Reads, float64(bd.ReadCache.BytesSaved)/1024)), contentWidth)
+	writeBoxLine(&b, border, s.Muted.Render("esc:close"), contentWidth)
+
+	b.WriteString(bs.Render(bl))
+	b.WriteString(bs.Render(strings.Repeat(dash, innerWidth)))
+	b.WriteString(bs.Render(br))
+
+	return b.String()
+}
+
+// percent returns part as a percentage of whole, or 0 when whole is 0.
+func percent(part, whole int) float64 {
+	if whole <= 0 {
+		return 0
+	}
+	return float64(part) * 100 / float64(whole)
+}
+
+// barCells divides width cells among tokens in proportion, using the
+// largest remainder so the cells always sum to width. Any non-zero
+// category gets at least one cell while cells remain.
+func barCells(tokens []int, width int) []int {
+	cells := make([]int, len(tokens))
+	total := 0
+	for _, t := range tokens {
+		total += t
+	}
+	if total == 0 || width <= 0 {
+		return cells
+	}
+
+	used := 0
+	rem := make([]int, len(tokens))
+	for i, t := range tokens {
+		cells[i] = t * width / total
+		rem[i] = t * width % total
+		used += cells[i]
+	}
+	for ; used < width; used++ {
+		best := 0
+		for i := range rem {
+			if rem[i] > rem[best] {
+				best = i
+			}
+		}
+		cells[best]++
+		rem[best] = -1
+	}
+
+	// Give tiny categories a visible cell, taken from the largest.
+	for i, t := range tokens {
+		if t == 0 || cells[i] > 0 {
+			continue
+		}
+		largest := 0
+		for j := range cells {
+			if cells[j] > cells[largest] {
+				largest = j
+			}
+		}
+		if cells[largest] > 1 {
+			cells[largest]--
+			cells[i]++
+		}
+	}
+	return cells
+}
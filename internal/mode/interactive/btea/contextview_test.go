@@ -0,0 +1,86 @@
+// ABOUTME: Tests for the /context overlay: category breakdown, bar allocation, and live refresh
+// ABOUTME: Verifies memory is split from the system prompt and usage events update the open overlay
+
+package btea
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+// Compile-time check: ContextViewModel must satisfy tea.Model.
+var _ tea.Model = ContextViewModel{}
+
+func contextTestModel() AppModel {
+	deps := testDeps()
+	deps.Model = &ai.Model{Name: "test-model", ContextWindow: 10000}
+	deps.MemoryPrompt = strings.Repeat("m", 400)                     // 100 tokens
+	deps.SystemPrompt = strings.Repeat("s", 800) + deps.MemoryPrompt // 300 tokens
+	deps.Tools = []*agent.AgentTool{{Name: "read", Description: strings.Repeat("d", 196)}}
+	m := NewAppModel(deps)
+	m.messages = []ai.Message{ai.NewTextMessage(ai.RoleUser, strings.Repeat("c", 400))}
+	return m
+}
+
+func TestContextBreakdown_Categories(t *testing.T) {
+	b := contextTestModel().contextBreakdown()
+	if b.Window != 10000 || b.System != 200 || b.Memory != 100 || b.Tools != 50 {
+		t.Errorf("breakdown = %+v; want system 200, memory 100, tools 50", b)
+	}
+	if b.Conversation == 0 || b.Free() != b.Window-b.Used() {
+		t.Errorf("conversation = %d, free = %d", b.Conversation, b.Free())
+	}
+}
+
+func TestContextView_RefreshesOnUsage(t *testing.T) {
+	m := contextTestModel()
+	m.overlay = NewContextViewModel(m.contextBreakdown(), 80)
+
+	result, _ := m.Update(AgentUsageMsg{Usage: &ai.Usage{InputTokens: 1000, CacheRead: 4000}})
+	m = result.(AppModel)
+	cv, ok := m.overlay.(ContextViewModel)
+	if !ok {
+		t.Fatalf("overlay = %T; want ContextViewModel", m.overlay)
+	}
+	if want := 5000 - 350; cv.breakdown.Conversation != want {
+		t.Errorf("conversation = %d; want reported remainder %d", cv.breakdown.Conversation, want)
+	}
+	view := cv.View()
+	for _, want := range []string{"5,000 of 10,000 tokens used (50.0%)", "Conversation", "4,650", "Free", "Memory"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q:\n%s", want, view)
+		}
+	}
+
+	m.overlay = nil
+	result, _ = m.Update(CompactDoneMsg{Messages: m.messages})
+	if b := result.(AppModel).contextBreakdown(); b.Conversation >= 4650 {
+		t.Errorf("compaction should drop the stale reported size, got %d", b.Conversation)
+	}
+}
+
+func TestBarCells(t *testing.T) {
+	cases := []struct {
+		tokens []int
+		width  int
+		want   []int
+	}{
+		{[]int{50, 25, 25}, 8, []int{4, 2, 2}},
+		{[]int{1, 0, 999}, 10, []int{1, 0, 9}},
+		{[]int{1, 1, 1}, 10, []int{4, 3, 3}},
+		{[]int{0, 0}, 10, []int{0, 0}},
+	}
+	for _, tc := range cases {
+		got := barCells(tc.tokens, tc.width)
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("barCells(%v, %d) = %v; want %v", tc.tokens, tc.width, got, tc.want)
+				break
+			}
+		}
+	}
+}
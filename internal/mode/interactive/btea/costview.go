@@ -14,6 +14,7 @@ import (
 type CostViewModel struct {
 	totalInput    int
 	totalOutput   int
+	totalThinking int // estimated; providers bill it as output
 	totalCost     float64
 	callCount     int
 	budgetUSD     float64
@@ -34,6 +35,13 @@ func NewCostViewModel(input, output, calls int, cost, budget, budgetPct float64)
 	}
 }
 
+// WithThinkingTokens returns the dashboard with the estimated thinking
+// tokens, shown as their own line since they are part of the output.
+func (m CostViewModel) WithThinkingTokens(n int) CostViewModel {
+	m.totalThinking = n
+	return m
+}
+
 // Init returns nil; no startup commands needed.
 func (m CostViewModel) Init() tea.Cmd { return nil }
 
@@ -88,6 +96,9 @@ func (m CostViewModel) View() string {
 	// Content lines
 	writeBoxLine(&b, border, fmt.Sprintf("Input tokens:  %s", formatNumber(m.totalInput)), contentWidth)
 	writeBoxLine(&b, border, fmt.Sprintf("Output tokens: %s", formatNumber(m.totalOutput)), contentWidth)
+	if m.totalThinking > 0 {
+		writeBoxLine(&b, border, fmt.Sprintf("  thinking:    ~%s", formatNumber(m.totalThinking)), contentWidth)
+	}
 	writeBoxLine(&b, border, fmt.Sprintf("Total cost:    $%.2f", m.totalCost), contentWidth)
 	writeBoxLine(&b, border, fmt.Sprintf("API calls:     %d", m.callCount), contentWidth)
 	writeBoxLine(&b, border, fmt.Sprintf("Budget:        $%.2f", m.budgetUSD), contentWidth)
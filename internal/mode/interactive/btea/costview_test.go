@@ -78,6 +78,18 @@ func TestCostViewModel_View_ShowsTokens(t *testing.T) {
 	}
 }
 
+func TestCostViewModel_View_ShowsThinkingTokens(t *testing.T) {
+	t.Parallel()
+	m := NewCostViewModel(100, 5000, 1, 0, 0, 0)
+	if strings.Contains(m.View(), "thinking") {
+		t.Error("View() shows a thinking line without thinking tokens")
+	}
+	m = m.WithThinkingTokens(1234)
+	if view := m.View(); !strings.Contains(view, "thinking:    ~1,234") {
+		t.Errorf("View() missing thinking tokens; got %q", view)
+	}
+}
+
 func TestCostViewModel_View_ShowsCost(t *testing.T) {
 	t.Parallel()
 	m := NewCostViewModel(100, 50, 5, 0.42, 10.0, 4.2)
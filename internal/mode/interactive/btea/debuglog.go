@@ -0,0 +1,188 @@
+// ABOUTME: /debug overlay: tails the session's structured log, re-reading it every second
+// ABOUTME: JSON records render as "time LEVEL message key=value"; j/k scroll, the bottom follows new lines
+
+package btea
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	pilog "github.com/mauromedda/pi-coding-agent-go/internal/log"
+)
+
+// debugLogLines is how many log lines the overlay keeps.
+const debugLogLines = 500
+
+// debugLogRefresh is how often an open overlay re-reads the log.
+const debugLogRefresh = time.Second
+
+// DebugLogTickMsg asks an open /debug overlay to re-read its log.
+type DebugLogTickMsg struct{}
+
+// debugLogTick schedules the next re-read.
+func debugLogTick() tea.Cmd {
+	return tea.Tick(debugLogRefresh, func(time.Time) tea.Msg { return DebugLogTickMsg{} })
+}
+
+// DebugLogModel shows the end of a log file.
+type DebugLogModel struct {
+	path   string
+	lines  []string // formatted records, oldest first
+	err    error
+	scroll int // lines scrolled up from the bottom
+	width  int
+	height int
+}
+
+// NewDebugLogModel opens the overlay on the log at path, already read.
+func NewDebugLogModel(path string, w, h int) DebugLogModel {
+	return DebugLogModel{path: path, width: w, height: h}.reload()
+}
+
+// reload re-reads the log's tail.
+func (m DebugLogModel) reload() DebugLogModel {
+	raw, err := pilog.Tail(m.path, debugLogLines)
+	m.err = err
+	m.lines = make([]string, len(raw))
+	for i, l := range raw {
+		m.lines[i] = formatLogRecord(l)
+	}
+	return m
+}
+
+// formatLogRecord renders a JSON record compactly; other lines pass through.
+func formatLogRecord(line string) string {
+	var rec map[string]any
+	if err := json.Unmarshal([]byte(line), &rec); err != nil {
+		return line
+	}
+	var b strings.Builder
+	if ts, ok := rec["time"].(string); ok {
+		if t, err := time.Parse(time.RFC3339Nano, ts); err == nil {
+			b.WriteString(t.Format("15:04:05") + " ")
+		}
+	}
+	fmt.Fprintf(&b, "%-5v %v", rec["level"], rec["msg"])
+	keys := make([]string, 0, len(rec))
+	for k := range rec {
+		if k != "time" && k != "level" && k != "msg" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&b, " %s=%v", k, rec[k])
+	}
+	return b.String()
+}
+
+// Init returns nil; the opener schedules the first tick.
+func (m DebugLogModel) Init() tea.Cmd { return nil }
+
+// Update handles refresh ticks, scrolling, and dismissal.
+func (m DebugLogModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case DebugLogTickMsg:
+		return m.reload(), debugLogTick()
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc", "q":
+			return m, func() tea.Msg { return DismissOverlayMsg{} }
+		case "up", "k":
+			m.scroll = min(m.scroll+1, m.maxScroll())
+		case "down", "j":
+			m.scroll = max(m.scroll-1, 0)
+		case "pgup":
+			m.scroll = min(m.scroll+m.bodyHeight(), m.maxScroll())
+		case "pgdown":
+			m.scroll = max(m.scroll-m.bodyHeight(), 0)
+		case "home", "g":
+			m.scroll = m.maxScroll()
+		case "end", "G":
+			m.scroll = 0
+		}
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	}
+	return m, nil
+}
+
+// bodyHeight is how many log lines fit inside the box.
+func (m DebugLogModel) bodyHeight() int {
+	return max(m.height-6, 3)
+}
+
+func (m DebugLogModel) maxScroll() int {
+	return max(len(m.lines)-m.bodyHeight(), 0)
+}
+
+// View renders the visible lines in a bordered box.
+func (m DebugLogModel) View() string {
+	s := Styles()
+	bs := s.OverlayBorder
+
+	const (
+		dash    = "─"
+		vBorder = "│"
+		tl      = "╭"
+		tr      = "╮"
+		bl      = "╰"
+		br      = "╯"
+	)
+
+	boxWidth := max(m.width-4, 40)
+	innerWidth := boxWidth - 2
+	contentWidth := boxWidth - 4
+	border := bs.Render(vBorder)
+
+	var b strings.Builder
+
+	titleText := " Debug Log "
+	title := s.OverlayTitle.Render(titleText)
+	dashesLeft := max((innerWidth-len(titleText))/2, 0)
+	dashesRight := max(innerWidth-len(titleText)-dashesLeft, 0)
+	b.WriteString(bs.Render(tl))
+	b.WriteString(bs.Render(strings.Repeat(dash, dashesLeft)))
+	b.WriteString(title)
+	b.WriteString(bs.Render(strings.Repeat(dash, dashesRight)))
+	b.WriteString(bs.Render(tr))
+	b.WriteByte('\n')
+
+	writeBoxLine(&b, border, s.Dim.Render(truncateVisual(m.path, contentWidth)), contentWidth)
+
+	body := m.bodyHeight()
+	end := len(m.lines) - min(m.scroll, m.maxScroll())
+	start := max(end-body, 0)
+	visible := m.lines[start:end]
+	switch {
+	case m.err != nil:
+		visible = []string{s.Error.Render(truncateVisual(m.err.Error(), contentWidth))}
+	case len(m.lines) == 0:
+		visible = []string{s.Dim.Render("(log is empty)")}
+	}
+	for i := range body {
+		line := ""
+		if i < len(visible) {
+			line = truncateVisual(visible[i], contentWidth)
+		}
+		writeBoxLine(&b, border, line, contentWidth)
+	}
+
+	hint := "j/k:scroll  g/G:top/bottom  esc:close"
+	if m.scroll > 0 {
+		hint = fmt.Sprintf("%d lines up  G:follow  esc:close", m.scroll)
+	}
+	writeBoxLine(&b, border, s.Muted.Render(hint), contentWidth)
+
+	b.WriteString(bs.Render(bl))
+	b.WriteString(bs.Render(strings.Repeat(dash, innerWidth)))
+	b.WriteString(bs.Render(br))
+
+	return b.String()
+}
@@ -0,0 +1,77 @@
+// ABOUTME: Tests for the /debug overlay: record formatting, tailing on refresh ticks, and scrolling
+// ABOUTME: Logs are written to tempdirs; the overlay is driven through Update like the app does
+
+package btea
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Compile-time check: DebugLogModel must satisfy tea.Model.
+var _ tea.Model = DebugLogModel{}
+
+func TestFormatLogRecord(t *testing.T) {
+	line := `{"time":"2026-01-02T15:04:05.123Z","level":"DEBUG","msg":"agent: tool read done","tool_id":"t1","request_id":"ab12"}`
+	got := formatLogRecord(line)
+	if !strings.HasSuffix(got, "DEBUG agent: tool read done request_id=ab12 tool_id=t1") {
+		t.Errorf("formatLogRecord = %q", got)
+	}
+	if formatLogRecord("not json") != "not json" {
+		t.Error("non-JSON lines should pass through")
+	}
+}
+
+func TestDebugLog_TailsAndScrolls(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "s.log")
+	var lines []string
+	for i := range 20 {
+		lines = append(lines, `{"level":"INFO","msg":"line `+string(rune('a'+i))+`"}`)
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewDebugLogModel(path, 80, 12)
+	if view := m.View(); !strings.Contains(view, "line t") || strings.Contains(view, "line a") {
+		t.Errorf("view should show the newest lines:\n%s", view)
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'g'}})
+	m = updated.(DebugLogModel)
+	if view := m.View(); !strings.Contains(view, "line a") || !strings.Contains(view, "G:follow") {
+		t.Errorf("g should scroll to the oldest lines:\n%s", view)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"level":"WARN","msg":"fresh"}` + "\n")
+	f.Close()
+	updated, cmd := m.Update(DebugLogTickMsg{})
+	m = updated.(DebugLogModel)
+	if cmd == nil || len(m.lines) != 21 {
+		t.Errorf("tick should re-read the log and reschedule: %d lines, cmd %v", len(m.lines), cmd != nil)
+	}
+
+	updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if cmd == nil {
+		t.Fatal("esc should dismiss")
+	}
+	if _, ok := cmd().(DismissOverlayMsg); !ok {
+		t.Error("esc should send DismissOverlayMsg")
+	}
+	_ = updated
+}
+
+func TestDebugLog_MissingFile(t *testing.T) {
+	m := NewDebugLogModel(filepath.Join(t.TempDir(), "gone.log"), 80, 12)
+	if !strings.Contains(m.View(), "opening log file") {
+		t.Errorf("view should report the error:\n%s", m.View())
+	}
+}
@@ -5,9 +5,16 @@ package btea
 
 import (
 	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
+	"github.com/mauromedda/pi-coding-agent-go/internal/commands"
 	"github.com/mauromedda/pi-coding-agent-go/internal/config"
+	"github.com/mauromedda/pi-coding-agent-go/internal/export"
+	"github.com/mauromedda/pi-coding-agent-go/internal/fetchcache"
 	"github.com/mauromedda/pi-coding-agent-go/internal/git"
+	"github.com/mauromedda/pi-coding-agent-go/internal/ide"
 	"github.com/mauromedda/pi-coding-agent-go/internal/permission"
+	"github.com/mauromedda/pi-coding-agent-go/internal/personality"
+	"github.com/mauromedda/pi-coding-agent-go/internal/prompt"
+	"github.com/mauromedda/pi-coding-agent-go/internal/reminder"
 	"github.com/mauromedda/pi-coding-agent-go/internal/session"
 	"github.com/mauromedda/pi-coding-agent-go/internal/statusline"
 	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
@@ -20,6 +27,7 @@ type AppDeps struct {
 	Tools                []*agent.AgentTool
 	Checker              *permission.Checker
 	SystemPrompt         string
+	MemoryPrompt         string // memory section within SystemPrompt, broken out by /context
 	Version              string
 	StatusEngine         *statusline.Engine
 	AutoCompactThreshold int
@@ -27,6 +35,112 @@ type AppDeps struct {
 	ScopedModels         *config.ScopedModelsConfig
 	PermissionMode       permission.Mode
 	Session              *session.Session
+	SessionsDir          string                 // where Session is saved; "" = config.SessionsDir()
+	Interrupted          *session.Interrupted   // turn a crashed run left in this cwd; offered for recovery
+	Devcontainer         string                 // name of the project's devcontainer; offered at startup when set
+	StartDevcontainer    func() (string, error) // starts the devcontainer for bash, describing it; nilable
 	AvailableModels      []ModelEntry
 	WorktreeSession      *git.SessionWorktree
+	Display              *config.DisplaySettings
+	IDELink              *ide.Link
+	Suspend              *config.SuspendSettings
+	Notify               *config.NotifySettings          // nil sends OSC 777 when a background task finishes
+	ContextEviction      *config.ContextEvictionSettings // nil uses the defaults
+	Prewarm              *config.PrewarmSettings         // nil: no speculative prewarm
+	Skills               *prompt.SkillActivator
+	Agents               []agent.Definition
+
+	// Personality provides the output styles for /output-style. Nilable.
+	Personality *personality.Engine
+	// OnOutputStyleChange persists the newly active style and returns the
+	// rebuilt system prompt. Nilable; the prompt is left unchanged when nil.
+	OnOutputStyleChange func(name string) (string, error)
+
+	// ASCII draws the interface with ASCII characters only (--ascii).
+	ASCII bool
+	// Terminal holds rendering settings such as the tool output preview length. Nilable.
+	Terminal *config.TerminalSettings
+	// Keys binds key chords to actions (settings.keybindings); the zero
+	// KeyMap has the defaults.
+	Keys KeyMap
+
+	// ThemeDirs are searched for JSON themes by /theme, in resolution order.
+	ThemeDirs []string
+	// OnThemeChange saves the theme picked with /theme. Nilable; the choice
+	// then lasts for this run only.
+	OnThemeChange func(name string) error
+
+	// ThinkingLevels holds the thinking level saved for each model ID
+	// (settings.thinkingLevels); a model without one starts with thinking off.
+	ThinkingLevels map[string]string
+	// OnThinkingChange saves the level alt+t picks for a model. Nilable; the
+	// level then lasts for this run only.
+	OnThinkingChange func(model, level string) error
+
+	// Minion routes simple turns to a cheaper model; /minion changes its mode. Nilable.
+	Minion *agent.Minion
+
+	// ThinkingRetention limits thinking kept in session records and exports. Nil summarizes.
+	ThinkingRetention *config.ThinkingRetentionSettings
+
+	// Redactor scrubs secrets from /export files and /share uploads. Nil
+	// exports as is; /share then uses the built-in patterns.
+	Redactor *export.Redactor
+
+	// FetchCache backs webfetch and MCP resource reads; /cache shows or clears it. Nilable.
+	FetchCache *fetchcache.Store
+
+	// Checkpoints holds the files each turn changed, as they were before
+	// it, for /undo. Nilable; /undo then leaves files alone.
+	Checkpoints *ide.TurnCheckpoints
+
+	// Offline labels the footer and makes network-only commands fail fast.
+	Offline bool
+
+	// Settings are the merged settings in effect; a reload compares the
+	// fresh ones against them to find what changed. Nilable.
+	Settings *config.Settings
+	// ReloadSettings re-reads the merged settings from disk for /reload and
+	// for changes to SettingsFiles. Nilable; settings then never reload.
+	ReloadSettings func() (*config.Settings, error)
+	// SettingsFiles are polled while the TUI runs; a change reloads settings.
+	SettingsFiles []string
+
+	// Permissions is the merged permissions block shown by /permissions. Nilable.
+	Permissions *config.PermissionsConfig
+	// ApplyAutonomy saves the starter permissions for an autonomy level to
+	// project settings, applies them to Checker, and returns the new block.
+	// Nilable; /permissions is then read-only.
+	ApplyAutonomy func(level string) (*config.PermissionsConfig, error)
+	// PermissionOnboarding opens the autonomy question on startup.
+	PermissionOnboarding bool
+
+	// Memory locates the memory files for /memory and the '#' shortcut. Nilable; both are then unavailable.
+	Memory *MemoryAccess
+
+	// Reminders injects system reminders into agent turns. Nilable; none are sent then.
+	Reminders *reminder.Injector
+	// BudgetUSD is the session budget the budget reminder watches; 0 disables it.
+	BudgetUSD float64
+	// BudgetWarnPct is the share of BudgetUSD at which the reminder starts.
+	BudgetWarnPct int
+
+	// Share configures /share uploads. Nilable; defaults apply.
+	Share *config.ShareSettings
+
+	// KeyPools are the providers' API key pools; /cost reports their per-key use.
+	KeyPools []*ai.KeyPool
+
+	// PluginCommands are slash commands served by plugins, added next to
+	// the built-ins. Names must not collide with them.
+	PluginCommands []*commands.Command
+}
+
+// MemoryAccess locates editable memory files and reloads them into the system prompt.
+type MemoryAccess struct {
+	ProjectDir string
+	HomeDir    string
+	// Reload re-reads memory from disk and returns the rebuilt system prompt
+	// and its memory section. Nilable; edits then apply from the next session.
+	Reload func() (systemPrompt, memoryPrompt string, err error)
 }
@@ -0,0 +1,105 @@
+// ABOUTME: DevcontainerConfirmModel overlay offering, at startup, to run bash in the project's devcontainer
+// ABOUTME: Accepting starts the container off the UI goroutine; later bash commands and file changes go through it
+
+package btea
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// DevcontainerConfirmModel names the project's devcontainer and waits for
+// y/enter to use it or n/esc to stay on the host.
+type DevcontainerConfirmModel struct {
+	name  string
+	width int
+}
+
+// NewDevcontainerConfirmModel creates the question for the devcontainer name.
+func NewDevcontainerConfirmModel(name string, w int) DevcontainerConfirmModel {
+	return DevcontainerConfirmModel{name: name, width: w}
+}
+
+// Init returns nil; no startup commands needed.
+func (m DevcontainerConfirmModel) Init() tea.Cmd { return nil }
+
+// Update handles key events for the question.
+func (m DevcontainerConfirmModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "y", "enter":
+			return m, func() tea.Msg { return DevcontainerAnswerMsg{Use: true} }
+		case "n", "esc", "q":
+			return m, func() tea.Msg { return DevcontainerAnswerMsg{Use: false} }
+		}
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+	}
+	return m, nil
+}
+
+// View renders the question as a bordered box.
+func (m DevcontainerConfirmModel) View() string {
+	s := Styles()
+	bs := s.OverlayBorder
+
+	const (
+		dash    = "─"
+		vBorder = "│"
+		tl      = "╭"
+		tr      = "╮"
+		bl      = "╰"
+		br      = "╯"
+	)
+
+	boxWidth := 64
+	if boxWidth > m.width-4 {
+		boxWidth = max(m.width-4, 40)
+	}
+	innerWidth := max(boxWidth-2, 0)
+	contentWidth := max(boxWidth-4, 20)
+	border := bs.Render(vBorder)
+
+	var b strings.Builder
+
+	const titleText = " Use the devcontainer? "
+	title := s.OverlayTitle.Render(titleText)
+	dashesLeft := max((innerWidth-len(titleText))/2, 0)
+	dashesRight := max(innerWidth-len(titleText)-dashesLeft, 0)
+	b.WriteString(bs.Render(tl))
+	b.WriteString(bs.Render(strings.Repeat(dash, dashesLeft)))
+	b.WriteString(title)
+	b.WriteString(bs.Render(strings.Repeat(dash, dashesRight)))
+	b.WriteString(bs.Render(tr))
+	b.WriteByte('\n')
+
+	writeBoxLine(&b, border, fmt.Sprintf("This project defines a devcontainer: %s.", m.name), contentWidth)
+	writeBoxLine(&b, border, "Run bash commands inside it, with the team's toolchain?", contentWidth)
+	writeBoxLine(&b, border, "", contentWidth)
+	writeBoxLine(&b, border, s.Muted.Render("Always or never: set container.devcontainer in settings"), contentWidth)
+	writeBoxLine(&b, border, s.Muted.Render("y/enter:use it  n/esc:stay on this machine"), contentWidth)
+
+	b.WriteString(bs.Render(bl))
+	b.WriteString(bs.Render(strings.Repeat(dash, innerWidth)))
+	b.WriteString(bs.Render(br))
+
+	return b.String()
+}
+
+// answerDevcontainer starts the offered devcontainer when use is set. It
+// is offered once a session.
+func (m AppModel) answerDevcontainer(use bool) (tea.Model, tea.Cmd) {
+	name, start := m.deps.Devcontainer, m.deps.StartDevcontainer
+	m.deps.Devcontainer = ""
+	if !use || start == nil {
+		return m, nil
+	}
+	updated, cmd := m.applyEffects(&cmdSideEffects{}, fmt.Sprintf("Starting the devcontainer %s; a first build can take a few minutes.", name))
+	return updated, tea.Batch(cmd, func() tea.Msg {
+		desc, err := start()
+		return DevcontainerStartedMsg{Desc: desc, Err: err}
+	})
+}
@@ -0,0 +1,93 @@
+// ABOUTME: Tests for the devcontainer offer: the startup overlay, its keys, and starting the container
+// ABOUTME: StartDevcontainer is a stub; no container runtime is involved
+
+package btea
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestNewAppModel_DevcontainerOverlay(t *testing.T) {
+	t.Parallel()
+	start := func() (string, error) { return "", nil }
+
+	m := NewAppModel(AppDeps{Devcontainer: "Go", StartDevcontainer: start})
+	if _, ok := m.overlay.(DevcontainerConfirmModel); !ok {
+		t.Errorf("overlay = %T; want DevcontainerConfirmModel", m.overlay)
+	}
+	if m := NewAppModel(AppDeps{StartDevcontainer: start}); m.overlay != nil {
+		t.Errorf("overlay = %T without a devcontainer; want nil", m.overlay)
+	}
+	if !strings.Contains(NewDevcontainerConfirmModel("Go", 80).View(), "devcontainer: Go") {
+		t.Error("the question should name the devcontainer")
+	}
+}
+
+func TestDevcontainerConfirmModel_Keys(t *testing.T) {
+	t.Parallel()
+
+	for key, use := range map[string]bool{"y": true, "enter": true, "n": false, "esc": false} {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		switch key {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		}
+		_, cmd := NewDevcontainerConfirmModel("Go", 80).Update(msg)
+		if cmd == nil {
+			t.Fatalf("%s: no answer", key)
+		}
+		if got := cmd().(DevcontainerAnswerMsg); got.Use != use {
+			t.Errorf("%s: Use = %v; want %v", key, got.Use, use)
+		}
+	}
+}
+
+func TestAppModel_DevcontainerAnswer(t *testing.T) {
+	t.Parallel()
+
+	started := 0
+	m := newTestAppModel()
+	m.deps.Devcontainer = "Go"
+	m.deps.StartDevcontainer = func() (string, error) {
+		started++
+		return "docker container 0123456789ab (Go)", nil
+	}
+	m.overlay = NewDevcontainerConfirmModel("Go", 80)
+
+	updated, cmd := m.Update(DevcontainerAnswerMsg{Use: true})
+	got := updated.(AppModel)
+	if got.overlay != nil || got.deps.Devcontainer != "" {
+		t.Error("the offer should close and not come back")
+	}
+	if !strings.Contains(got.lastAssistantText(), "Starting the devcontainer Go") {
+		t.Errorf("notice = %q", got.lastAssistantText())
+	}
+	var done DevcontainerStartedMsg
+	for _, msg := range collectBatchMsgs(cmd) {
+		if d, ok := msg.(DevcontainerStartedMsg); ok {
+			done = d
+		}
+	}
+	if started != 1 || done.Desc == "" {
+		t.Fatalf("started %d times, result %+v", started, done)
+	}
+	updated, _ = got.Update(done)
+	if text := updated.(AppModel).lastAssistantText(); !strings.Contains(text, "Bash commands now run in the docker container") {
+		t.Errorf("notice = %q", text)
+	}
+
+	updated, _ = got.Update(DevcontainerStartedMsg{Err: errors.New("no docker")})
+	if text := updated.(AppModel).lastAssistantText(); !strings.Contains(text, "no docker") || !strings.Contains(text, "on this machine") {
+		t.Errorf("failure notice = %q", text)
+	}
+
+	m.deps.StartDevcontainer = func() (string, error) { t.Error("declining started the container"); return "", nil }
+	_, cmd = m.Update(DevcontainerAnswerMsg{Use: false})
+	collectBatchMsgs(cmd)
+}
@@ -1,5 +1,5 @@
 // ABOUTME: Diff rendering utilities for edit tool output
-// ABOUTME: Colors unified diff lines (red/green) and computes simple line diffs
+// ABOUTME: Colors unified diff lines (red/green), detects diffs in tool output, and computes simple line diffs
 
 package btea
 
@@ -38,6 +38,28 @@ func RenderDiff(diff string, s ThemeStyles) string {
 	return strings.TrimRight(b.String(), "\n")
 }
 
+// LooksLikeDiff reports whether text is a unified diff, such as the output
+// of git diff run through bash: a "diff --git" line, or "---"/"+++" file
+// headers followed by a hunk header.
+func LooksLikeDiff(text string) bool {
+	var minus, plus bool
+	for line := range strings.SplitSeq(text, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			return true
+		case strings.HasPrefix(line, "--- "):
+			minus = true
+		case strings.HasPrefix(line, "+++ "):
+			plus = minus
+		case strings.HasPrefix(line, "@@ -"):
+			if plus {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // IsEditTool returns true if the tool name is a file-editing tool.
 func IsEditTool(name string) bool {
 	lower := strings.ToLower(name)
@@ -120,3 +120,25 @@ func TestComputeSimpleDiff_Identical(t *testing.T) {
 		t.Errorf("identical content should return empty diff; got %q", diff)
 	}
 }
+
+func TestLooksLikeDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want bool
+	}{
+		{"git diff", "diff --git a/x.go b/x.go\nindex 1..2\n", true},
+		{"unified", "--- a/x.go\n+++ b/x.go\n@@ -1 +1 @@\n-a\n+b", true},
+		{"headers without hunk", "--- a/x.go\n+++ b/x.go\n", false},
+		{"hunk without headers", "@@ -1 +1 @@\n-a\n+b", false},
+		{"markdown list", "- one\n+ two\n--- \nplain", false},
+		{"plain", "ok\n", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LooksLikeDiff(tt.text); got != tt.want {
+				t.Errorf("LooksLikeDiff(%q) = %v; want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
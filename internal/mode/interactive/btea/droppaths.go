@@ -0,0 +1,124 @@
+// ABOUTME: Recognizes files dragged into the terminal: file:// URIs, quoted paths, and backslash-escaped paths
+// ABOUTME: A paste made only of existing paths becomes @mention tokens that the editor shows as chips
+
+package btea
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// plainMentionRegex matches paths that need no quoting in an @mention.
+var plainMentionRegex = regexp.MustCompile(`^[\w./_-]+$`)
+
+// droppedPaths returns the files a paste names when it consists of nothing
+// but paths to existing files or directories, as terminals paste them on
+// drag and drop: file:// URIs, single- or double-quoted paths, and paths
+// with backslash-escaped spaces, separated by whitespace. ok is false for
+// any other text, which is pasted as typed.
+func droppedPaths(text string) (paths []string, ok bool) {
+	tokens, ok := splitDropped(strings.TrimSpace(text))
+	if !ok || len(tokens) == 0 {
+		return nil, false
+	}
+	for _, tok := range tokens {
+		p, ok := droppedPath(tok)
+		if !ok {
+			return nil, false
+		}
+		paths = append(paths, p)
+	}
+	return paths, true
+}
+
+// splitDropped splits text into words the way a shell would: quotes group
+// and a backslash escapes the next character (not on Windows, where it
+// separates paths). ok is false for an unterminated quote.
+func splitDropped(text string) ([]string, bool) {
+	var words []string
+	var cur strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, r := range text {
+		switch {
+		case escaped:
+			cur.WriteRune(r)
+			escaped = false
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '\\' && runtime.GOOS != "windows":
+			escaped, inWord = true, true
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inWord {
+				words = append(words, cur.String())
+				cur.Reset()
+				inWord = false
+			}
+		default:
+			cur.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, false
+	}
+	if inWord {
+		words = append(words, cur.String())
+	}
+	return words, true
+}
+
+// droppedPath resolves one word of a paste to an existing absolute path:
+// a file:// URI, a path under "~/", or an absolute path.
+func droppedPath(word string) (string, bool) {
+	p := word
+	if strings.HasPrefix(word, "file://") {
+		u, err := url.Parse(word)
+		if err != nil || (u.Host != "" && u.Host != "localhost") {
+			return "", false
+		}
+		p = u.Path
+		if runtime.GOOS == "windows" {
+			p = filepath.FromSlash(strings.TrimPrefix(p, "/"))
+		}
+	} else if rest, ok := strings.CutPrefix(word, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", false
+		}
+		p = filepath.Join(home, rest)
+	}
+	if !filepath.IsAbs(p) {
+		return "", false
+	}
+	if _, err := os.Stat(p); err != nil {
+		return "", false
+	}
+	return filepath.Clean(p), true
+}
+
+// mentionToken returns the @mention for path: relative to root when it lies
+// inside it, and quoted when it holds characters a bare mention cannot.
+func mentionToken(path, root string) string {
+	if root != "" {
+		if rel, err := filepath.Rel(root, path); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+			path = rel
+		}
+	}
+	path = filepath.ToSlash(path)
+	if plainMentionRegex.MatchString(path) {
+		return "@" + path
+	}
+	return `@"` + path + `"`
+}
@@ -0,0 +1,97 @@
+// ABOUTME: Tests for drag-and-drop paste handling: file URIs, quoted and escaped paths, and @mention tokens
+// ABOUTME: Also checks that the editor inserts mentions for a dropped file and renders them as chips
+
+package btea
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestDroppedPaths(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("terminals on Windows paste quoted paths without backslash escapes")
+	}
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "main.go")
+	spaced := filepath.Join(dir, "My Notes.txt")
+	for _, p := range []string{plain, spaced} {
+		if err := os.WriteFile(p, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tc := range []struct {
+		paste string
+		want  []string
+	}{
+		{plain + " ", []string{plain}},
+		{"file://" + strings.ReplaceAll(spaced, " ", "%20"), []string{spaced}},
+		{"'" + spaced + "' " + plain, []string{spaced, plain}},
+		{`"` + spaced + `"`, []string{spaced}},
+		{strings.ReplaceAll(spaced, " ", `\ `), []string{spaced}},
+		{plain + "\n" + dir, []string{plain, dir}},
+		{"look at " + plain, nil},
+		{filepath.Join(dir, "missing.go"), nil},
+		{"main.go", nil},
+		{"'" + spaced, nil},
+		{"file://host/" + plain, nil},
+	} {
+		got, ok := droppedPaths(tc.paste)
+		if ok != (tc.want != nil) || strings.Join(got, "|") != strings.Join(tc.want, "|") {
+			t.Errorf("droppedPaths(%q) = %q, %v; want %q", tc.paste, got, ok, tc.want)
+		}
+	}
+}
+
+func TestMentionToken(t *testing.T) {
+	root := filepath.FromSlash("/work/repo")
+	for path, want := range map[string]string{
+		"/work/repo/cmd/main.go":   "@cmd/main.go",
+		"/work/repo/My Notes.txt":  `@"My Notes.txt"`,
+		"/elsewhere/a.go":          "@/elsewhere/a.go",
+		"/work/repository/file.go": "@/work/repository/file.go",
+	} {
+		if got := mentionToken(filepath.FromSlash(path), root); got != filepath.ToSlash(want) {
+			t.Errorf("mentionToken(%q) = %q; want %q", path, got, want)
+		}
+	}
+}
+
+func TestEditorModel_DropInsertsMentionChip(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "My Notes.txt")
+	if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewEditorModel().SetMentionRoot(dir).SetText("explain")
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("'" + path + "' "), Paste: true})
+	m = updated.(EditorModel)
+	if got, want := m.Text(), `explain @"My Notes.txt" `; got != want {
+		t.Errorf("Text() = %q; want %q", got, want)
+	}
+
+	s := ThemeStyles{Selection: lipgloss.NewStyle().Transform(func(t string) string { return "[" + t + "]" })}
+	if got := m.renderChips("explain @\"My Notes.txt\" ", s); got != `explain [@"My Notes.txt"] ` {
+		t.Errorf("renderChips = %q; want the mention as a chip", got)
+	}
+
+	// Undo removes the whole drop at once.
+	undone, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlUnderscore})
+	if got := undone.(EditorModel).Text(); got != "explain" {
+		t.Errorf("Text() after undo = %q; want %q", got, "explain")
+	}
+
+	// Pasted text that is not only paths is inserted as typed.
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("see " + path), Paste: true})
+	if got := updated.(EditorModel).Text(); !strings.HasSuffix(got, "see "+path) {
+		t.Errorf("Text() = %q; want the raw paste", got)
+	}
+}
@@ -4,6 +4,8 @@
 package btea
 
 import (
+	"regexp"
+	"slices"
 	"strings"
 	"time"
 
@@ -115,6 +117,8 @@ type EditorModel struct {
 	oscEscPending     bool   // true after bare ESC; if ']' follows within timeout, enter suppression
 	oscGen            uint64 // generation counter; stale timeouts carry an older gen and are ignored
 	oscChainedCooldown bool  // true after OSC terminates; widens split-ESC window for chained sequences
+	mentionRoot string   // directory dropped files are mentioned relative to
+	chips       []string // @mention tokens inserted for dropped files, shown as chips
 }
 
 // NewEditorModel creates a new empty editor.
@@ -199,8 +203,9 @@ func (m EditorModel) View() string {
 	s := Styles()
 	ew := max(m.width-m.promptWidth, 1)
 
-	// Placeholder: shown when empty, focused, and placeholder is set
-	if m.focused && m.isEmpty() && m.placeholder != "" {
+	// Placeholder: shown when empty, focused, and placeholder is set; ghost
+	// text (a suggested prompt) takes its place
+	if m.focused && m.isEmpty() && m.placeholder != "" && m.ghostText == "" {
 		return m.prompt + CursorMarker + s.Dim.Render(m.placeholder)
 	}
 
@@ -232,7 +237,7 @@ func (m EditorModel) View() string {
 		}
 	}
 
-	return b.String()
+	return m.renderChips(b.String(), s)
 }
 
 // --- Public methods (value receivers, return new model) ---
@@ -256,6 +261,14 @@ func (m EditorModel) SetText(s string) EditorModel {
 	}
 	m.row = len(m.lines) - 1
 	m.col = len(m.lines[m.row])
+	m.chips = slices.DeleteFunc(m.chips, func(t string) bool { return !strings.Contains(s, t) })
+	return m
+}
+
+// SetMentionRoot sets the directory that @mentions of dropped files are
+// relative to, which should be where mentions are resolved.
+func (m EditorModel) SetMentionRoot(dir string) EditorModel {
+	m.mentionRoot = dir
 	return m
 }
 
@@ -462,14 +475,22 @@ func (m *EditorModel) dispatchKey(msg tea.KeyMsg) tea.Cmd {
 			// Drop Alt+rune sequences: normal typing never produces these.
 			// They are terminal escape artefacts (OSC body fragments parsed
 			// as ESC+char by BubbleTea). The app handles known Alt shortcuts
-			// (alt+t, alt+m, alt+i) before reaching the editor.
+			// (alt+t, alt+m, alt+i, alt+e) before reaching the editor.
 			if msg.Alt {
 				return oscCleanupCmd // nil when no cleanup pending
 			}
+			// A paste of nothing but file paths is a drag and drop: insert
+			// @mentions instead of the terminal's escaped text.
+			if msg.Paste || len(msg.Runes) > 1 {
+				if paths, ok := droppedPaths(string(msg.Runes)); ok {
+					m.insertDropped(paths)
+					return oscCleanupCmd
+				}
+			}
 			// Insert all runes, filtering C0 control characters and DEL.
 			// Multi-rune messages occur during paste or when the terminal
 			// delivers batched input. Save undo once for the whole batch
-			// so Ctrl+Z undoes the entire paste in one step.
+			// so Ctrl+_ undoes the entire paste in one step.
 			m.saveUndo()
 			for _, r := range msg.Runes {
 				if r < 0x20 || r == 0x7F {
@@ -512,7 +533,7 @@ func (m *EditorModel) dispatchKey(msg tea.KeyMsg) tea.Cmd {
 		m.killToEnd()
 	case tea.KeyCtrlY:
 		m.yank()
-	case tea.KeyCtrlZ:
+	case tea.KeyCtrlUnderscore:
 		m.doUndo()
 	case tea.KeyCtrlV:
 		pasteCmd := m.pasteImageCmd()
@@ -540,6 +561,37 @@ func (m *EditorModel) dispatchKey(msg tea.KeyMsg) tea.Cmd {
 	return oscCleanupCmd // nil when no cleanup pending
 }
 
+// insertDropped inserts an @mention for each dropped path, separated from
+// the text around it by spaces, as one undo step.
+func (m *EditorModel) insertDropped(paths []string) {
+	m.saveUndo()
+	for _, p := range paths {
+		if m.col > 0 && m.lines[m.row][m.col-1] != ' ' {
+			m.insertRuneNoUndo(' ')
+		}
+		token := mentionToken(p, m.mentionRoot)
+		m.insertTextNoUndo(token + " ")
+		if !slices.Contains(m.chips, token) {
+			m.chips = append(m.chips, token)
+		}
+	}
+}
+
+// renderChips highlights the dropped-file mentions in rendered editor text.
+// Longer tokens are tried first, so one that extends another wins.
+func (m *EditorModel) renderChips(out string, s ThemeStyles) string {
+	if len(m.chips) == 0 {
+		return out
+	}
+	tokens := slices.Clone(m.chips)
+	slices.SortFunc(tokens, func(a, b string) int { return len(b) - len(a) })
+	for i, t := range tokens {
+		tokens[i] = regexp.QuoteMeta(t)
+	}
+	re := regexp.MustCompile(strings.Join(tokens, "|"))
+	return re.ReplaceAllStringFunc(out, func(t string) string { return s.Selection.Render(t) })
+}
+
 // acceptGhostText inserts the ghost text at the cursor position and clears it.
 func (m *EditorModel) acceptGhostText() {
 	if m.ghostText == "" {
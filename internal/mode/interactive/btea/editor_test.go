@@ -214,7 +214,7 @@ func TestEditorModel_Undo(t *testing.T) {
 		t.Fatalf("after insert 'a': Text() = %q; want %q", got, "a")
 	}
 	// Undo
-	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlZ})
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlUnderscore})
 	m = updated.(EditorModel)
 	if got := m.Text(); got != "" {
 		t.Errorf("after undo: Text() = %q; want empty", got)
@@ -0,0 +1,344 @@
+// ABOUTME: Editing a past prompt: Alt+E picks a prompt on screen and loads it into the editor
+// ABOUTME: Submitting asks to confirm, then drops the turns from there on and re-runs the new text
+
+package btea
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/width"
+)
+
+// editPoint is a past prompt that can be edited: its index in the
+// conversation, its UserMsgModel on screen, and the text the user typed.
+type editPoint struct {
+	msg     int
+	content int
+	prompt  string
+}
+
+// editablePrompts pairs the prompts on screen with their messages, oldest
+// first. Prompts cleared from the screen or folded into a compaction
+// summary are left out.
+func (m AppModel) editablePrompts() []editPoint {
+	var points []editPoint
+	c, i := len(m.content)-1, len(m.messages)-1
+	for c >= 0 && i >= m.undoFloor {
+		um, ok := m.content[c].(UserMsgModel)
+		if !ok || strings.HasPrefix(um.text, "!") {
+			c--
+			continue
+		}
+		if !isPrompt(m.messages[i]) {
+			i--
+			continue
+		}
+		points = append(points, editPoint{msg: i, content: c, prompt: um.text})
+		c--
+		i--
+	}
+	for l, r := 0, len(points)-1; l < r; l, r = l+1, r-1 {
+		points[l], points[r] = points[r], points[l]
+	}
+	return points
+}
+
+// openEditPicker lists the prompts that can be edited. It returns a notice
+// instead when there are none.
+func (m AppModel) openEditPicker() (AppModel, string) {
+	if m.agentRunning {
+		return m, "Wait for the agent to finish (or press Esc to stop it) before editing a prompt."
+	}
+	points := m.editablePrompts()
+	if len(points) == 0 {
+		return m, "No prompts on screen to edit."
+	}
+	m.overlay = NewEditPromptPickerModel(points, m.width)
+	return m, ""
+}
+
+// startEdit loads p into the editor. The next submit re-runs from p.
+func (m AppModel) startEdit(p editPoint) AppModel {
+	if m.editTarget == nil {
+		m.savedDraft = m.editor.Text()
+	}
+	m.editTarget = &p
+	m.editor = m.editor.SetText(p.prompt)
+	return m
+}
+
+// cancelEdit leaves edit mode and restores the draft it replaced.
+func (m AppModel) cancelEdit() AppModel {
+	m.editTarget = nil
+	m.editor = m.editor.SetText(m.savedDraft)
+	m.savedDraft = ""
+	return m
+}
+
+// confirmRerun asks before text replaces the edited prompt. The turns
+// from there on are dropped only once the user agrees.
+func (m AppModel) confirmRerun(text string) (AppModel, string) {
+	p := m.editTarget
+	if !m.editStillValid() {
+		m.editTarget = nil
+		return m, "The conversation changed since you picked that prompt; press Alt+E to pick it again."
+	}
+	turns := 0
+	for _, msg := range m.messages[p.msg:] {
+		if isPrompt(msg) {
+			turns++
+		}
+	}
+	m.overlay = NewRerunConfirmModel(text, turns, len(m.messages)-p.msg, m.deps.Checkpoints.Turns() > 0, m.width)
+	return m, ""
+}
+
+// editStillValid reports whether the edited prompt is still where it was
+// picked: nothing removed or redrew the conversation since.
+func (m AppModel) editStillValid() bool {
+	p := m.editTarget
+	if p == nil || p.msg >= len(m.messages) || p.content >= len(m.content) || !isPrompt(m.messages[p.msg]) {
+		return false
+	}
+	um, ok := m.content[p.content].(UserMsgModel)
+	return ok && um.text == p.prompt
+}
+
+// rerun drops the edited prompt and everything after it, then submits text
+// in its place.
+func (m AppModel) rerun(text string) (AppModel, tea.Cmd) {
+	if !m.editStillValid() {
+		m.editTarget = nil
+		return m.applyNotice("The conversation changed since you picked that prompt; press Alt+E to pick it again.")
+	}
+	p := *m.editTarget
+	m.editTarget = nil
+	m.savedDraft = ""
+	m, notice := m.rewind(p.msg, p.content)
+	m, _ = m.applyNotice(fmt.Sprintf("Re-running from edited prompt %q.", truncateForNotice(p.prompt)) + notice)
+	return m.submitPrompt(text)
+}
+
+// applyNotice shows text as a notice in the conversation.
+func (m AppModel) applyNotice(text string) (AppModel, tea.Cmd) {
+	model, cmd := m.applyEffects(&cmdSideEffects{}, text)
+	return model.(AppModel), cmd
+}
+
+// truncateForNotice shortens a prompt to one line for a notice.
+func truncateForNotice(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if width.VisibleWidth(s) > 40 {
+		s = width.TruncateToWidth(s, 37) + "..."
+	}
+	return s
+}
+
+// EditPromptPickerModel lists the prompts on screen, newest selected.
+// Selecting one loads it into the editor for editing.
+type EditPromptPickerModel struct {
+	points []editPoint
+	cursor int
+	width  int
+}
+
+// NewEditPromptPickerModel creates the picker for points with the cursor on
+// the newest prompt.
+func NewEditPromptPickerModel(points []editPoint, w int) EditPromptPickerModel {
+	return EditPromptPickerModel{points: points, cursor: len(points) - 1, width: w}
+}
+
+// Init returns nil; no startup commands needed.
+func (m EditPromptPickerModel) Init() tea.Cmd { return nil }
+
+// Update handles key events for the picker.
+func (m EditPromptPickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "j", "down":
+			if m.cursor < len(m.points)-1 {
+				m.cursor++
+			}
+		case "k", "up":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "enter", "e":
+			p := m.points[m.cursor]
+			return m, func() tea.Msg {
+				return EditPromptSelectedMsg{MsgIndex: p.msg, ContentIndex: p.content, Prompt: p.prompt}
+			}
+		case "esc", "q":
+			return m, func() tea.Msg { return DismissOverlayMsg{} }
+		}
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+	}
+	return m, nil
+}
+
+// View renders the prompts as a bordered box.
+func (m EditPromptPickerModel) View() string {
+	s := Styles()
+	bs := s.OverlayBorder
+
+	const (
+		dash    = "─"
+		vBorder = "│"
+		tl      = "╭"
+		tr      = "╮"
+		bl      = "╰"
+		br      = "╯"
+	)
+
+	boxWidth := max(m.width*3/5, 40)
+	if boxWidth > m.width-4 {
+		boxWidth = max(m.width-4, 40)
+	}
+	innerWidth := max(boxWidth-2, 0)
+	contentWidth := max(boxWidth-4, 20)
+	border := bs.Render(vBorder)
+
+	var b strings.Builder
+
+	const titleText = " Edit Prompt "
+	title := s.OverlayTitle.Render(titleText)
+	dashesLeft := max((innerWidth-len(titleText))/2, 0)
+	dashesRight := max(innerWidth-len(titleText)-dashesLeft, 0)
+	b.WriteString(bs.Render(tl))
+	b.WriteString(bs.Render(strings.Repeat(dash, dashesLeft)))
+	b.WriteString(title)
+	b.WriteString(bs.Render(strings.Repeat(dash, dashesRight)))
+	b.WriteString(bs.Render(tr))
+	b.WriteByte('\n')
+
+	writeBoxLine(&b, border, s.Dim.Render("Edit a prompt, then press Enter to re-run from it."), contentWidth)
+	maxW := max(contentWidth-8, 10)
+	for i, p := range m.points {
+		prefix := "  "
+		if i == m.cursor {
+			prefix = "> "
+		}
+		label := fmt.Sprintf("%d. %s", i+1, strings.Join(strings.Fields(p.prompt), " "))
+		if width.VisibleWidth(label) > maxW {
+			label = width.TruncateToWidth(label, maxW-3) + "..."
+		}
+		if i == m.cursor {
+			writeBoxLine(&b, border, s.Selection.Render(prefix+label), contentWidth)
+		} else {
+			writeBoxLine(&b, border, s.Dim.Render(prefix+label), contentWidth)
+		}
+	}
+	writeBoxLine(&b, border, s.Muted.Render("j/k:nav  enter:edit  esc:cancel"), contentWidth)
+
+	b.WriteString(bs.Render(bl))
+	b.WriteString(bs.Render(strings.Repeat(dash, innerWidth)))
+	b.WriteString(bs.Render(br))
+
+	return b.String()
+}
+
+// RerunConfirmModel asks whether to drop the turns from the edited prompt
+// on and re-run with the new text: y/enter confirms, n/esc keeps editing.
+type RerunConfirmModel struct {
+	text     string
+	turns    int
+	messages int
+	files    bool // file checkpoints exist, so changed files are restored
+	width    int
+}
+
+// NewRerunConfirmModel creates the question for re-running text in place of
+// the edited prompt, dropping turns turns and messages messages.
+func NewRerunConfirmModel(text string, turns, messages int, files bool, w int) RerunConfirmModel {
+	return RerunConfirmModel{text: text, turns: turns, messages: messages, files: files, width: w}
+}
+
+// Init returns nil; no startup commands needed.
+func (m RerunConfirmModel) Init() tea.Cmd { return nil }
+
+// Update handles key events for the question.
+func (m RerunConfirmModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "y", "enter":
+			return m, func() tea.Msg { return RerunPromptMsg{Rerun: true, Text: m.text} }
+		case "n", "esc", "q":
+			return m, func() tea.Msg { return RerunPromptMsg{Rerun: false} }
+		}
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+	}
+	return m, nil
+}
+
+// View renders the question as a bordered box.
+func (m RerunConfirmModel) View() string {
+	s := Styles()
+	bs := s.OverlayBorder
+
+	const (
+		dash    = "─"
+		vBorder = "│"
+		tl      = "╭"
+		tr      = "╮"
+		bl      = "╰"
+		br      = "╯"
+	)
+
+	boxWidth := max(m.width*3/5, 50)
+	if boxWidth > m.width-4 {
+		boxWidth = max(m.width-4, 50)
+	}
+	innerWidth := max(boxWidth-2, 0)
+	contentWidth := max(boxWidth-4, 20)
+	border := bs.Render(vBorder)
+
+	var b strings.Builder
+
+	const titleText = " Re-run from this prompt? "
+	title := s.OverlayTitle.Render(titleText)
+	dashesLeft := max((innerWidth-len(titleText))/2, 0)
+	dashesRight := max(innerWidth-len(titleText)-dashesLeft, 0)
+	b.WriteString(bs.Render(tl))
+	b.WriteString(bs.Render(strings.Repeat(dash, dashesLeft)))
+	b.WriteString(title)
+	b.WriteString(bs.Render(strings.Repeat(dash, dashesRight)))
+	b.WriteString(bs.Render(tr))
+	b.WriteByte('\n')
+
+	fit := func(line string) string {
+		if width.VisibleWidth(line) > contentWidth {
+			line = width.TruncateToWidth(line, max(contentWidth-3, 1)) + "..."
+		}
+		return line
+	}
+	turns := "1 turn"
+	if m.turns != 1 {
+		turns = fmt.Sprintf("%d turns", m.turns)
+	}
+	files := "unchanged (no checkpoints)"
+	if m.files {
+		files = "restored from checkpoints"
+	}
+	for _, line := range []string{
+		"The new prompt replaces the old one.",
+		fmt.Sprintf("Drops:   %s, %d messages", turns, m.messages),
+		"Files:   " + files,
+		"Prompt:  " + strings.Join(strings.Fields(m.text), " "),
+	} {
+		writeBoxLine(&b, border, s.Dim.Render(fit(line)), contentWidth)
+	}
+	writeBoxLine(&b, border, "", contentWidth)
+	writeBoxLine(&b, border, s.Muted.Render("y/enter:re-run  n/esc:keep editing"), contentWidth)
+
+	b.WriteString(bs.Render(bl))
+	b.WriteString(bs.Render(strings.Repeat(dash, innerWidth)))
+	b.WriteString(bs.Render(br))
+
+	return b.String()
+}
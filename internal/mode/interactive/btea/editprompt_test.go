@@ -0,0 +1,132 @@
+// ABOUTME: Tests for editing a past prompt: picking it, confirming, and re-running from it
+// ABOUTME: Later turns leave the conversation, the session, and the screen only after confirmation
+
+package btea
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mauromedda/pi-coding-agent-go/internal/ide"
+	"github.com/mauromedda/pi-coding-agent-go/internal/session"
+)
+
+// Compile-time checks: the edit overlays must satisfy tea.Model.
+var (
+	_ tea.Model = EditPromptPickerModel{}
+	_ tea.Model = RerunConfirmModel{}
+)
+
+// editModel returns a model with three finished turns, each writing its
+// number to file.
+func editModel(t *testing.T) (AppModel, AppDeps, string) {
+	t.Helper()
+	deps := testDepsWithSession(t)
+	deps.Checkpoints = ide.NewTurnCheckpoints()
+	file := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(file, []byte("0"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m := NewAppModel(deps)
+	for _, n := range []string{"1", "2", "3"} {
+		m = runTurn(t, m, "prompt "+n, "reply "+n, file, n)
+	}
+	return m, deps, file
+}
+
+// pressKey sends key to m and runs the command it returns, if any.
+func pressKey(t *testing.T, m AppModel, key tea.KeyMsg) AppModel {
+	t.Helper()
+	result, cmd := m.Update(key)
+	m = result.(AppModel)
+	if cmd != nil {
+		if msg := cmd(); msg != nil {
+			result, _ = m.Update(msg)
+			m = result.(AppModel)
+		}
+	}
+	return m
+}
+
+func TestAppModel_EditPromptReruns(t *testing.T) {
+	m, deps, file := editModel(t)
+
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e"), Alt: true})
+	picker, ok := m.overlay.(EditPromptPickerModel)
+	if !ok || len(picker.points) != 3 {
+		t.Fatalf("overlay = %T with %d prompts; want the edit picker over 3", m.overlay, len(picker.points))
+	}
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyUp})
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.overlay != nil || m.editor.Text() != "prompt 2" {
+		t.Fatalf("overlay = %T, editor = %q; want prompt 2 loaded for editing", m.overlay, m.editor.Text())
+	}
+
+	// Submitting asks first; declining keeps the conversation and the edit.
+	m.editor = m.editor.SetText("prompt 2, better")
+	m, _ = m.submitOrEnqueue()
+	confirm, ok := m.overlay.(RerunConfirmModel)
+	if !ok {
+		t.Fatalf("overlay = %T; want the re-run question", m.overlay)
+	}
+	if view := confirm.View(); !strings.Contains(view, "2 turns, 8 messages") || !strings.Contains(view, "restored from checkpoints") {
+		t.Errorf("question = %q", view)
+	}
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if len(m.messages) != 12 || m.editTarget == nil {
+		t.Fatalf("declined: %d messages, editing %v", len(m.messages), m.editTarget != nil)
+	}
+
+	m, _ = m.submitOrEnqueue()
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if m.editTarget != nil || !m.agentRunning {
+		t.Fatalf("editing %v, running %v; want the edited prompt submitted", m.editTarget != nil, m.agentRunning)
+	}
+	if got, _ := os.ReadFile(file); string(got) != "1" {
+		t.Errorf("notes.txt = %q; want the state after turn 1", got)
+	}
+	if len(m.messages) != 5 || m.messages[4].Content[0].Text != "prompt 2, better" {
+		t.Errorf("messages = %+v; want turn 1 and the edited prompt", m.messages)
+	}
+	var prompts []string
+	for _, c := range m.content {
+		if um, ok := c.(UserMsgModel); ok {
+			prompts = append(prompts, um.text)
+		}
+	}
+	if strings.Join(prompts, "|") != "prompt 1|prompt 2, better" {
+		t.Errorf("prompts on screen = %q", prompts)
+	}
+	records, _ := session.ReadRecordsInDir(filepath.Join(deps.Session.CWD, "sessions"), deps.Session.ID)
+	msgs, _ := session.BuildSessionContext(records)
+	if len(msgs) != 3 || msgs[2].Content[0].Text != "prompt 2, better" {
+		t.Errorf("saved session = %+v; want turn 1 and the edited prompt", msgs)
+	}
+}
+
+func TestAppModel_EditPromptCancelAndStale(t *testing.T) {
+	m, _, _ := editModel(t)
+	m.editor = m.editor.SetText("draft")
+	m, _ = m.openEditPicker()
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.editor.Text() != "prompt 3" {
+		t.Fatalf("editor = %q; want prompt 3", m.editor.Text())
+	}
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.editTarget != nil || m.editor.Text() != "draft" {
+		t.Errorf("after esc: editing %v, editor %q; want the draft back", m.editTarget != nil, m.editor.Text())
+	}
+
+	// A conversation that changed under the edit is not re-run.
+	m, _ = m.openEditPicker()
+	m = pressKey(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	m, _ = m.undoTurn()
+	m.editor = m.editor.SetText("prompt 3, again")
+	m, _ = m.submitOrEnqueue()
+	if m.overlay != nil || m.editTarget != nil || !strings.Contains(m.lastAssistantText(), "conversation changed") {
+		t.Errorf("stale edit: overlay %T, notice %q", m.overlay, m.lastAssistantText())
+	}
+}
@@ -0,0 +1,42 @@
+// ABOUTME: Tests for mirroring plural-minion fan-out progress into the background task list
+// ABOUTME: Covers task creation, progress updates, and done/failed status on completion
+
+package btea
+
+import (
+	"testing"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
+)
+
+func TestAppModel_TrackFanOut(t *testing.T) {
+	t.Parallel()
+	m := newTestAppModel()
+	m.sh.bgManager = NewBackgroundManager(nil)
+
+	updated, _ := m.Update(MinionFanOutMsg{Progress: agent.FanOutProgress{ID: "fan-1", Instruction: "add docs", Total: 3}})
+	m = updated.(AppModel)
+	task := m.sh.bgManager.Get("fan-1")
+	if task == nil || task.Status != BGRunning || task.Progress != "0/3 files" || task.Prompt != "minion fan-out: add docs" {
+		t.Fatalf("task after start = %+v", task)
+	}
+	if m.footer.backgroundCount != 1 {
+		t.Errorf("footer background count = %d, want 1", m.footer.backgroundCount)
+	}
+
+	updated, _ = m.Update(MinionFanOutMsg{Progress: agent.FanOutProgress{ID: "fan-1", Total: 3, Done: 2, Failed: 1, File: "b.go"}})
+	m = updated.(AppModel)
+	if got := m.sh.bgManager.Get("fan-1").Progress; got != "2/3 files, 1 failed" {
+		t.Errorf("Progress = %q", got)
+	}
+
+	updated, _ = m.Update(MinionFanOutMsg{Progress: agent.FanOutProgress{ID: "fan-1", Total: 3, Done: 3, Failed: 1, Finished: true}})
+	m = updated.(AppModel)
+	task = m.sh.bgManager.Get("fan-1")
+	if task.Status != BGFailed || task.Err == nil {
+		t.Errorf("finished task = %+v, want failed with error", task)
+	}
+	if m.sh.bgManager.Count() != 1 {
+		t.Errorf("Count() = %d; updates must not add duplicate tasks", m.sh.bgManager.Count())
+	}
+}
@@ -1,16 +1,14 @@
-// ABOUTME: Async file scanning for @file mention autocomplete
-// ABOUTME: Uses git ls-files for speed; falls back to os.ReadDir for non-git dirs
+// ABOUTME: Async file scanning for @file mention autocomplete, and symbol lookup for @Symbol mentions
+// ABOUTME: Reads the tools' shared project index, refreshed incrementally, so each '@' costs a stat pass
 
 package btea
 
 import (
-	"os"
-	"os/exec"
 	"path/filepath"
-	"strings"
-	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mauromedda/pi-coding-agent-go/internal/ide"
+	"github.com/mauromedda/pi-coding-agent-go/internal/tools"
 )
 
 // FileScanResultMsg carries the scanned file list back to the Update loop.
@@ -18,111 +16,50 @@ type FileScanResultMsg struct {
 	Items []FileInfo
 }
 
-// scanProjectFilesCmd returns a tea.Cmd that scans the project directory
-// for files. Uses `git ls-files` when inside a git repo (fast, respects
-// .gitignore), falls back to a shallow directory walk otherwise.
+// scanProjectFilesCmd returns a tea.Cmd that lists the project's files
+// and directories from the shared index of root, which respects .gitignore
+// and the excluded directories. The first scan walks the tree; later ones
+// re-read only the directories that changed.
 func scanProjectFilesCmd(root string) tea.Cmd {
 	return func() tea.Msg {
-		items := scanGitFiles(root)
-		if items == nil {
-			items = scanDirFiles(root)
-		}
-		return FileScanResultMsg{Items: items}
+		return FileScanResultMsg{Items: scanIndexFiles(tools.ProjectIndex(root))}
 	}
 }
 
-// scanGitFiles runs `git ls-files` and returns FileInfo entries.
-// Returns nil if git is unavailable or root is not a git repo.
-func scanGitFiles(root string) []FileInfo {
-	cmd := exec.Command("git", "ls-files", "--cached", "--others", "--exclude-standard")
-	cmd.Dir = root
-	out, err := cmd.Output()
-	if err != nil {
-		return nil
-	}
-
-	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
-	if len(lines) == 0 || (len(lines) == 1 && lines[0] == "") {
-		return nil
-	}
-
-	items := make([]FileInfo, 0, len(lines))
-	for _, rel := range lines {
-		if rel == "" {
-			continue
-		}
-		abs := filepath.Join(root, rel)
-		info, err := os.Stat(abs)
-		if err != nil {
-			// File listed by git but stat failed; include with zero metadata
-			items = append(items, FileInfo{
-				Path:    abs,
-				RelPath: rel,
-				Name:    filepath.Base(rel),
-				Dir:     filepath.Dir(rel),
-			})
-			continue
-		}
+// scanIndexFiles converts the index's entries to FileInfo items.
+func scanIndexFiles(x *tools.FileIndex) []FileInfo {
+	entries, _ := x.Entries()
+	items := make([]FileInfo, 0, len(entries))
+	for _, e := range entries {
 		items = append(items, FileInfo{
-			Path:    abs,
-			RelPath: rel,
-			Name:    filepath.Base(rel),
-			Dir:     filepath.Dir(rel),
-			Size:    info.Size(),
-			ModTime: info.ModTime(),
-			IsDir:   info.IsDir(),
+			Path:    e.Path,
+			RelPath: e.RelPath,
+			Name:    filepath.Base(e.RelPath),
+			Dir:     filepath.Dir(e.RelPath),
+			Size:    e.Size,
+			ModTime: e.ModTime,
+			IsDir:   e.IsDir,
 		})
 	}
 	return items
 }
 
-// scanDirFiles performs a shallow walk (max 2 levels) as a fallback.
-func scanDirFiles(root string) []FileInfo {
-	var items []FileInfo
-	maxDepth := 2
-
-	_ = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			return nil // skip errors
+// mentionSymbols returns the lookup ide.ParseMentions uses for @Symbol
+// mentions: the first definition under root, in path order.
+func mentionSymbols(root string) ide.SymbolLookup {
+	return func(name string) (ide.Symbol, bool) {
+		defs := tools.FindSymbols(root, name)
+		if len(defs) == 0 {
+			return ide.Symbol{}, false
 		}
-
-		rel, _ := filepath.Rel(root, path)
-		if rel == "." {
-			return nil
-		}
-
-		// Skip noise directories; allow dotfile dirs like .claude/ but skip .git/
-		name := d.Name()
-		if name == ".git" || name == "node_modules" || name == "vendor" || name == "__pycache__" {
-			if d.IsDir() {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-
-		depth := strings.Count(rel, string(filepath.Separator))
-		if d.IsDir() && depth >= maxDepth {
-			return filepath.SkipDir
-		}
-
-		info, _ := d.Info()
-		var size int64
-		var modTime time.Time
-		if info != nil {
-			size = info.Size()
-			modTime = info.ModTime()
-		}
-
-		items = append(items, FileInfo{
-			Path:    path,
-			RelPath: rel,
-			Name:    name,
-			Dir:     filepath.Dir(rel),
-			Size:    size,
-			ModTime: modTime,
-			IsDir:   d.IsDir(),
-		})
-		return nil
-	})
-	return items
+		d := defs[0]
+		return ide.Symbol{
+			Path:      d.Path,
+			Kind:      d.Kind,
+			Name:      d.Name,
+			StartLine: d.StartLine,
+			EndLine:   d.EndLine,
+			Others:    len(defs) - 1,
+		}, true
+	}
 }
@@ -1,4 +1,4 @@
-// ABOUTME: Tests for async file scanning commands (git ls-files and fallback)
+// ABOUTME: Tests for async file scanning over the shared project file index
 // ABOUTME: Verifies scanProjectFilesCmd returns FileScanResultMsg with files
 
 package btea
@@ -8,6 +8,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/tools"
 )
 
 func TestScanProjectFilesCmd_ReturnsFileScanResultMsg(t *testing.T) {
@@ -51,7 +53,7 @@ func TestScanProjectFilesCmd_ReturnsFileScanResultMsg(t *testing.T) {
 	}
 }
 
-func TestScanDirFiles_SkipsGitAndVendor(t *testing.T) {
+func TestScanIndexFiles_SkipsGitAndVendor(t *testing.T) {
 	tmp := t.TempDir()
 	// Create some files
 	os.WriteFile(filepath.Join(tmp, "main.go"), []byte("package main"), 0644)
@@ -61,11 +63,11 @@ func TestScanDirFiles_SkipsGitAndVendor(t *testing.T) {
 	os.WriteFile(filepath.Join(tmp, "node_modules", "pkg.js"), []byte(""), 0644)
 	os.WriteFile(filepath.Join(tmp, ".hidden"), []byte("secret"), 0644)
 
-	items := scanDirFiles(tmp)
+	items := scanIndexFiles(tools.NewFileIndex(tmp))
 
 	for _, item := range items {
 		if strings.HasPrefix(item.RelPath, ".git") || strings.HasPrefix(item.RelPath, "node_modules") {
-			t.Errorf("scanDirFiles should skip %q", item.RelPath)
+			t.Errorf("scan should skip %q", item.RelPath)
 		}
 	}
 
@@ -81,21 +83,21 @@ func TestScanDirFiles_SkipsGitAndVendor(t *testing.T) {
 		}
 	}
 	if !foundHidden {
-		t.Error("scanDirFiles should include .hidden (dotfiles are allowed)")
+		t.Error("scan should include .hidden (dotfiles are allowed)")
 	}
 	if !foundMain {
-		t.Error("scanDirFiles should include main.go")
+		t.Error("scan should include main.go")
 	}
 }
 
-func TestScanDirFiles_IncludesDotfileDirs(t *testing.T) {
+func TestScanIndexFiles_IncludesDotfileDirs(t *testing.T) {
 	tmp := t.TempDir()
 	// .claude/ is a user config dir that should be scannable
 	os.MkdirAll(filepath.Join(tmp, ".claude"), 0755)
 	os.WriteFile(filepath.Join(tmp, ".claude", "LICENSE"), []byte("MIT"), 0644)
 	os.WriteFile(filepath.Join(tmp, "main.go"), []byte("package main"), 0644)
 
-	items := scanDirFiles(tmp)
+	items := scanIndexFiles(tools.NewFileIndex(tmp))
 
 	found := false
 	for _, item := range items {
@@ -109,11 +111,11 @@ func TestScanDirFiles_IncludesDotfileDirs(t *testing.T) {
 		for i, item := range items {
 			names[i] = item.RelPath
 		}
-		t.Errorf("scanDirFiles should include .claude/LICENSE; got: %v", names)
+		t.Errorf("scan should include .claude/LICENSE; got: %v", names)
 	}
 }
 
-func TestScanDirFiles_StillSkipsGitAndNodeModules(t *testing.T) {
+func TestScanIndexFiles_StillSkipsGitAndNodeModules(t *testing.T) {
 	tmp := t.TempDir()
 	os.MkdirAll(filepath.Join(tmp, ".git", "objects"), 0755)
 	os.MkdirAll(filepath.Join(tmp, "node_modules", "pkg"), 0755)
@@ -121,19 +123,36 @@ func TestScanDirFiles_StillSkipsGitAndNodeModules(t *testing.T) {
 	os.WriteFile(filepath.Join(tmp, "node_modules", "pkg", "index.js"), []byte(""), 0644)
 	os.WriteFile(filepath.Join(tmp, "main.go"), []byte("package main"), 0644)
 
-	items := scanDirFiles(tmp)
+	items := scanIndexFiles(tools.NewFileIndex(tmp))
 
 	for _, item := range items {
 		if strings.HasPrefix(item.RelPath, ".git") || strings.HasPrefix(item.RelPath, "node_modules") {
-			t.Errorf("scanDirFiles should skip %q", item.RelPath)
+			t.Errorf("scan should skip %q", item.RelPath)
 		}
 	}
 }
 
-func TestScanGitFiles_NonGitDir_ReturnsNil(t *testing.T) {
+func TestScanIndexFiles_RespectsGitignore(t *testing.T) {
 	tmp := t.TempDir()
-	result := scanGitFiles(tmp)
-	if result != nil {
-		t.Errorf("scanGitFiles in non-git dir should return nil; got %d items", len(result))
+	os.MkdirAll(filepath.Join(tmp, ".git"), 0755)
+	os.MkdirAll(filepath.Join(tmp, "out"), 0755)
+	os.WriteFile(filepath.Join(tmp, ".gitignore"), []byte("out/\n*.log\n"), 0644)
+	os.WriteFile(filepath.Join(tmp, "out", "bin"), []byte(""), 0644)
+	os.WriteFile(filepath.Join(tmp, "debug.log"), []byte(""), 0644)
+	os.WriteFile(filepath.Join(tmp, "main.go"), []byte("package main"), 0644)
+
+	x := tools.NewFileIndex(tmp)
+	var names []string
+	for _, item := range scanIndexFiles(x) {
+		names = append(names, item.RelPath)
+	}
+	if got := strings.Join(names, " "); got != ".gitignore main.go" {
+		t.Errorf("scan = %q; want the unignored files", got)
+	}
+
+	// A new file shows up in the next scan of the same index.
+	os.WriteFile(filepath.Join(tmp, "util.go"), []byte("package main"), 0644)
+	if items := scanIndexFiles(x); len(items) != 3 || items[2].RelPath != "util.go" {
+		t.Errorf("rescan = %+v; want util.go added", items)
 	}
 }
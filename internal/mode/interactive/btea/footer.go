@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
 	"github.com/mauromedda/pi-coding-agent-go/internal/config"
 	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/width"
 )
@@ -33,6 +34,7 @@ type FooterModel struct {
 	contextUsed     int // Used tokens in context window
 	contextTotal    int // Total context window size in tokens
 	thinking       config.ThinkingLevel
+	thinkingBudget string // what the level asks of the provider, e.g. "24K" or "effort high"
 	permissionMode string
 	queuedCount    int
 	latencyClass   string
@@ -41,6 +43,10 @@ type FooterModel struct {
 	activeChecks    []string // Abbreviations of active checks (e.g., ["SEC", "QUAL", "ARCH"])
 	backgroundCount int      // Number of background tasks
 	autoAccept      bool     // Auto-accept permission requests
+	minionMode      string   // "off", "auto", "force"; "" when no minion is configured
+	route           agent.ModelRoute // Last routing decision; zero until the first routed call
+	offline         bool     // --offline: local models only
+	statusLine      string           // output of the custom status line script, colors kept
 	width           int
 }
 
@@ -70,7 +76,7 @@ func (m FooterModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.intentLabel = msg.To
 
 	case SettingsChangedMsg:
-		// No-op for now; could trigger re-render in future phases
+		// AppModel re-applies settings and refreshes the footer's fields
 
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
@@ -79,6 +85,13 @@ func (m FooterModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// WithStatusLine returns a FooterModel showing line, the custom status line
+// script's output, below the built-in lines.
+func (m FooterModel) WithStatusLine(line string) FooterModel {
+	m.statusLine = line
+	return m
+}
+
 // WithPath returns a FooterModel with the path set.
 func (m FooterModel) WithPath(p string) FooterModel {
 	m.path = p
@@ -123,6 +136,13 @@ func (m FooterModel) WithThinking(level config.ThinkingLevel) FooterModel {
 	return m
 }
 
+// WithThinkingBudget returns a FooterModel that shows label, the provider
+// budget of the thinking level, next to the level.
+func (m FooterModel) WithThinkingBudget(label string) FooterModel {
+	m.thinkingBudget = label
+	return m
+}
+
 // WithPermissionMode returns a FooterModel with the permission mode set.
 func (m FooterModel) WithPermissionMode(mode string) FooterModel {
 	m.permissionMode = mode
@@ -177,21 +197,104 @@ func (m FooterModel) WithAutoAccept(on bool) FooterModel {
 	return m
 }
 
-// View renders the two-line footer.
+// WithMinionMode returns a FooterModel with the minion mode indicator set.
+func (m FooterModel) WithMinionMode(mode string) FooterModel {
+	m.minionMode = mode
+	return m
+}
+
+// WithOffline returns a FooterModel with the offline indicator set.
+func (m FooterModel) WithOffline(on bool) FooterModel {
+	m.offline = on
+	return m
+}
+
+// WithModelRoute returns a FooterModel showing which model served the last call.
+func (m FooterModel) WithModelRoute(r agent.ModelRoute) FooterModel {
+	m.route = r
+	return m
+}
+
+// routeIndicator renders the per-turn minion/escalation marker, or "".
+func (m FooterModel) routeIndicator() string {
+	s := Styles()
+	switch {
+	case m.route.Model == "":
+		return ""
+	case m.route.Minion:
+		return s.Success.Render("[minion " + m.route.Model + "]")
+	case m.minionMode != "" && m.minionMode != "off":
+		return s.Warning.Render("[escalated]")
+	}
+	return ""
+}
+
+// footerPart is one footer segment. When a line is too narrow, segments
+// with the lowest priority are hidden first.
+type footerPart struct {
+	text     string
+	priority int
+}
+
+// Footer segment priorities, most expendable first.
+const (
+	footerOptional = iota
+	footerUseful
+	footerImportant
+	footerEssential
+)
+
+// compactFooterWidth is the width below which the context gauge drops its
+// bar and token counts.
+const compactFooterWidth = 60
+
+// fitFooterParts joins parts with sep, hiding the lowest-priority parts
+// (latest first among equals) until the line fits w. A line that still
+// does not fit is truncated. w <= 0 means unlimited.
+func fitFooterParts(parts []footerPart, sep string, w int) string {
+	join := func() string {
+		texts := make([]string, len(parts))
+		for i, p := range parts {
+			texts[i] = p.text
+		}
+		return strings.Join(texts, sep)
+	}
+	line := join()
+	for w > 0 && width.VisibleWidth(line) > w && len(parts) > 1 {
+		drop := len(parts) - 1
+		for i := len(parts) - 1; i >= 0; i-- {
+			if parts[i].priority < parts[drop].priority {
+				drop = i
+			}
+		}
+		parts = append(parts[:drop:drop], parts[drop+1:]...)
+		line = join()
+	}
+	if w > 0 && width.VisibleWidth(line) > w {
+		line = width.TruncateToWidth(line, w)
+	}
+	return line
+}
+
+// View renders the two-line footer. On narrow terminals the least
+// important segments are hidden first.
 func (m FooterModel) View() string {
 	s := Styles()
 
 	// === Line 1: path + branch + model + cost ===
-	var parts []string
+	var parts []footerPart
 
 	if m.path != "" {
-		parts = append(parts, s.FooterPath.Render(m.path))
+		parts = append(parts, footerPart{s.FooterPath.Render(m.path), footerUseful})
 	}
 	if m.gitBranch != "" {
-		parts = append(parts, s.FooterBranch.Render("\ue0a0 "+m.gitBranch))
+		parts = append(parts, footerPart{s.FooterBranch.Render("\ue0a0 " + m.gitBranch), footerOptional})
 	}
 	if m.model != "" {
-		parts = append(parts, s.FooterModel.Render(m.model))
+		parts = append(parts, footerPart{s.FooterModel.Render(m.model), footerEssential})
+	}
+	if ind := m.routeIndicator(); ind != "" {
+		parts = append(parts, footerPart{ind, footerUseful})
 	}
 	if m.latencyClass != "" {
 		latencyStyle := s.Info
@@ -201,16 +304,20 @@ func (m FooterModel) View() string {
 		case "slow":
 			latencyStyle = s.Warning
 		}
-		parts = append(parts, latencyStyle.Render("["+m.latencyClass+"]"))
+		parts = append(parts, footerPart{latencyStyle.Render("[" + m.latencyClass + "]"), footerOptional})
 	}
 	if m.cost > 0 {
-		parts = append(parts, s.FooterCost.Render(fmt.Sprintf("$%.2f", m.cost)))
+		parts = append(parts, footerPart{s.FooterCost.Render(fmt.Sprintf("$%.2f", m.cost)), footerImportant})
 	}
 
-	line1 := strings.Join(parts, s.Muted.Render("  "))
+	line1 := fitFooterParts(parts, s.Muted.Render("  "), m.width)
 
 	// === Line 2: mode + permissions + context% + queued + thinking ===
-	var line2Parts []string
+	var line2Parts []footerPart
+
+	if m.offline {
+		line2Parts = append(line2Parts, footerPart{s.Warning.Render("[offline]"), footerEssential})
+	}
 
 	if m.permissionMode != "" {
 		permStyle := s.Warning
@@ -220,7 +327,7 @@ func (m FooterModel) View() string {
 		case "normal", "plan":
 			permStyle = s.FooterPerm
 		}
-		line2Parts = append(line2Parts, permStyle.Render("▸▸ "+m.permissionMode))
+		line2Parts = append(line2Parts, footerPart{permStyle.Render("▸▸ " + m.permissionMode), footerEssential})
 	}
 
 	if m.intentLabel != "" {
@@ -237,16 +344,16 @@ func (m FooterModel) View() string {
 		case "refactor":
 			intentStyle = s.Warning
 		}
-		line2Parts = append(line2Parts, intentStyle.Render("["+m.intentLabel+"]"))
+		line2Parts = append(line2Parts, footerPart{intentStyle.Render("[" + m.intentLabel + "]"), footerOptional})
 	}
 
 	if len(m.activeChecks) > 0 {
 		checksStr := "[" + strings.Join(m.activeChecks, "|") + "]"
-		line2Parts = append(line2Parts, s.Muted.Render(checksStr))
+		line2Parts = append(line2Parts, footerPart{s.Muted.Render(checksStr), footerOptional})
 	}
 
 	if m.modeLabel != "" {
-		line2Parts = append(line2Parts, s.Warning.Render(m.modeLabel))
+		line2Parts = append(line2Parts, footerPart{s.Warning.Render(m.modeLabel), footerEssential})
 	}
 
 	if m.contextPct > 0 {
@@ -264,54 +371,63 @@ func (m FooterModel) View() string {
 			barStyle = s.Warning // yellow 50-79%
 		}
 
-		filledStr := strings.Repeat("█", filled)
-		emptyStr := strings.Repeat("░", empty)
-		bar := barStyle.Render(filledStr) + s.Dim.Render(emptyStr)
 		pct := barStyle.Render(fmt.Sprintf("%d%%", m.contextPct))
-		
-		// Build allocation string (e.g., "12K/20K") if we have context info
-		allocStr := ""
-		if m.contextTotal > 0 {
-			usedStr := formatTokens(m.contextUsed)
-			totalStr := formatTokens(m.contextTotal)
-			allocStr = fmt.Sprintf(" %s/%s", usedStr, totalStr)
+		if m.width > 0 && m.width < compactFooterWidth {
+			// Narrow: the percentage alone.
+			line2Parts = append(line2Parts, footerPart{"ctx " + pct, footerImportant})
+		} else {
+			filledStr := strings.Repeat("█", filled)
+			emptyStr := strings.Repeat("░", empty)
+			bar := barStyle.Render(filledStr) + s.Dim.Render(emptyStr)
+
+			// Build allocation string (e.g., "12K/20K") if we have context info
+			allocStr := ""
+			if m.contextTotal > 0 {
+				usedStr := formatTokens(m.contextUsed)
+				totalStr := formatTokens(m.contextTotal)
+				allocStr = fmt.Sprintf(" %s/%s", usedStr, totalStr)
+			}
+
+			line2Parts = append(line2Parts, footerPart{fmt.Sprintf("ctx %s %s%s", bar, pct, allocStr), footerImportant})
 		}
-		
-		line2Parts = append(line2Parts, fmt.Sprintf("ctx %s %s%s", bar, pct, allocStr))
 	}
 
 	if m.queuedCount > 0 {
-		line2Parts = append(line2Parts, s.Warning.Render(fmt.Sprintf("[%d queued]", m.queuedCount)))
+		line2Parts = append(line2Parts, footerPart{s.Warning.Render(fmt.Sprintf("[%d queued]", m.queuedCount)), footerImportant})
 	}
 
 	if m.backgroundCount > 0 {
-		line2Parts = append(line2Parts, s.Info.Render(fmt.Sprintf("[%d bg]", m.backgroundCount)))
+		line2Parts = append(line2Parts, footerPart{s.Info.Render(fmt.Sprintf("[%d bg]", m.backgroundCount)), footerUseful})
+	}
+
+	if m.minionMode != "" && m.minionMode != "off" {
+		line2Parts = append(line2Parts, footerPart{s.Muted.Render("[minion:" + m.minionMode + "]"), footerOptional})
 	}
 
 	if m.autoAccept {
-		line2Parts = append(line2Parts, s.Success.Render("[auto-accept]"))
+		line2Parts = append(line2Parts, footerPart{s.Success.Render("[auto-accept]"), footerImportant})
 	}
 
 	if m.showImages {
-		line2Parts = append(line2Parts, s.Info.Render("[img]"))
+		line2Parts = append(line2Parts, footerPart{s.Info.Render("[img]"), footerOptional})
 	}
 
 	if m.thinking != config.ThinkingOff {
-		line2Parts = append(line2Parts, s.Info.Render("["+m.thinking.String()+"]"))
+		thinking := m.thinking.String()
+		if m.thinkingBudget != "" {
+			thinking += ", " + m.thinkingBudget
+		}
+		line2Parts = append(line2Parts, footerPart{s.Info.Render("[" + thinking + "]"), footerUseful})
 	}
 
-	line2 := strings.Join(line2Parts, " ")
+	line2 := fitFooterParts(line2Parts, " ", m.width)
 
-	// Truncate if needed
-	if m.width > 0 {
-		line1W := width.VisibleWidth(line1)
-		if line1W > m.width {
-			line1 = width.TruncateToWidth(line1, m.width)
-		}
-		line2W := width.VisibleWidth(line2)
-		if line2W > m.width {
-			line2 = width.TruncateToWidth(line2, m.width)
+	if m.statusLine != "" {
+		line3 := m.statusLine
+		if m.width > 0 && width.VisibleWidth(line3) > m.width {
+			line3 = width.TruncateToWidth(line3, m.width)
 		}
+		return line1 + "\n" + line2 + "\n" + line3
 	}
 
 	return line1 + "\n" + line2
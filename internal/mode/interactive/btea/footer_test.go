@@ -8,8 +8,10 @@ import (
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
 	"github.com/mauromedda/pi-coding-agent-go/internal/config"
 	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/width"
 )
 
 // Compile-time check: FooterModel must satisfy tea.Model.
@@ -219,6 +221,14 @@ func TestFooterModel_ViewContainsThinking(t *testing.T) {
 	}
 }
 
+func TestFooterModel_ViewContainsThinkingBudget(t *testing.T) {
+	m := NewFooterModel().WithThinking(config.ThinkingHigh).WithThinkingBudget("24K")
+	m.width = 80
+	if view := m.View(); !strings.Contains(view, "[high, 24K]") {
+		t.Errorf("View() missing thinking budget; got %q", view)
+	}
+}
+
 func TestFooterModel_ViewHidesCostWhenZero(t *testing.T) {
 	m := NewFooterModel()
 	m = m.WithCost(0)
@@ -396,3 +406,80 @@ func TestFooterModel_ViewPermissionMode(t *testing.T) {
 		})
 	}
 }
+
+func TestFooterModel_MinionRouteIndicator(t *testing.T) {
+	m := NewFooterModel().WithModel("opus").WithMinionMode("auto")
+
+	if view := m.View(); !strings.Contains(view, "[minion:auto]") || strings.Contains(view, "escalated") {
+		t.Errorf("before any route, view = %q", view)
+	}
+
+	m = m.WithModelRoute(agent.ModelRoute{Model: "haiku", Minion: true, Reason: "auto"})
+	if view := m.View(); !strings.Contains(view, "[minion haiku]") {
+		t.Errorf("minion route not shown: %q", view)
+	}
+
+	m = m.WithModelRoute(agent.ModelRoute{Model: "opus", Reason: "tool error"})
+	if view := m.View(); !strings.Contains(view, "[escalated]") {
+		t.Errorf("escalation not shown: %q", view)
+	}
+
+	m = m.WithMinionMode("off")
+	if view := m.View(); strings.Contains(view, "escalated") || strings.Contains(view, "minion") {
+		t.Errorf("off mode should hide minion indicators: %q", view)
+	}
+}
+
+func TestFooterModel_OfflineLabel(t *testing.T) {
+	m := NewFooterModel().WithPermissionMode("normal")
+	if strings.Contains(m.View(), "[offline]") {
+		t.Error("offline label shown while online")
+	}
+	if !strings.Contains(m.WithOffline(true).View(), "[offline]") {
+		t.Error("offline label missing")
+	}
+}
+
+func TestFooterModel_NarrowHidesLowPrioritySegments(t *testing.T) {
+	m := NewFooterModel().
+		WithPath("/home/test/project").
+		WithGitBranch("feature/very-long-branch").
+		WithModel("claude-opus-4").
+		WithCost(1.23).
+		WithPermissionMode("normal").
+		WithContextPct(45).
+		WithThinking(config.ThinkingMedium)
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 40, Height: 20})
+	m = updated.(FooterModel)
+
+	view := m.View()
+	for _, want := range []string{"claude-opus-4", "$1.23", "normal", "45%"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("narrow footer lost %q:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "feature/very-long-branch") || strings.Contains(view, "░") {
+		t.Errorf("narrow footer should hide the branch and the context bar:\n%s", view)
+	}
+	for _, line := range strings.Split(view, "\n") {
+		if w := width.VisibleWidth(line); w > 40 {
+			t.Errorf("line %q is %d columns wide; want at most 40", line, w)
+		}
+	}
+}
+
+func TestFitFooterParts(t *testing.T) {
+	parts := []footerPart{{"aaaa", footerUseful}, {"bbbb", footerOptional}, {"cccc", footerEssential}, {"dddd", footerOptional}}
+	if got := fitFooterParts(parts, " ", 0); got != "aaaa bbbb cccc dddd" {
+		t.Errorf("unlimited = %q", got)
+	}
+	if got := fitFooterParts(parts, " ", 14); got != "aaaa bbbb cccc" {
+		t.Errorf("width 14 = %q; want the last optional part dropped first", got)
+	}
+	if got := fitFooterParts(parts, " ", 9); got != "aaaa cccc" {
+		t.Errorf("width 9 = %q; want both optional parts dropped", got)
+	}
+	if got := fitFooterParts(parts, " ", 4); got != "cccc" {
+		t.Errorf("width 4 = %q; want only the essential part", got)
+	}
+}
@@ -0,0 +1,286 @@
+// ABOUTME: ForkPickerModel overlay for /fork: pick a past prompt and branch the session before it
+// ABOUTME: AppModel handlers fork the saved session at that turn and switch the TUI to the new branch
+
+package btea
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mauromedda/pi-coding-agent-go/internal/config"
+	"github.com/mauromedda/pi-coding-agent-go/internal/session"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/width"
+)
+
+// forkPoint is one place the session can be forked: before a user turn,
+// or after everything (turn -1).
+type forkPoint struct {
+	turn   int
+	prompt string
+}
+
+// ForkPickerModel lists the user turns of the saved session. Selecting a
+// turn forks before it; the last entry forks the whole session.
+type ForkPickerModel struct {
+	points []forkPoint
+	cursor int
+	width  int
+}
+
+// NewForkPickerModel creates the picker for points with the cursor on the
+// whole-session entry.
+func NewForkPickerModel(points []forkPoint, w int) ForkPickerModel {
+	points = append(points, forkPoint{turn: -1})
+	return ForkPickerModel{points: points, cursor: len(points) - 1, width: w}
+}
+
+// Init returns nil; no startup commands needed.
+func (m ForkPickerModel) Init() tea.Cmd { return nil }
+
+// Update handles key events for the picker.
+func (m ForkPickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "j", "down":
+			if m.cursor < len(m.points)-1 {
+				m.cursor++
+			}
+		case "k", "up":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "enter":
+			p := m.points[m.cursor]
+			return m, func() tea.Msg { return ForkPointSelectedMsg{Turn: p.turn, Prompt: p.prompt} }
+		case "esc", "q":
+			return m, func() tea.Msg { return DismissOverlayMsg{} }
+		}
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+	}
+	return m, nil
+}
+
+// View renders the fork points as a bordered box.
+func (m ForkPickerModel) View() string {
+	s := Styles()
+	bs := s.OverlayBorder
+
+	const (
+		dash    = "─"
+		vBorder = "│"
+		tl      = "╭"
+		tr      = "╮"
+		bl      = "╰"
+		br      = "╯"
+	)
+
+	boxWidth := max(m.width*3/5, 40)
+	if boxWidth > m.width-4 {
+		boxWidth = max(m.width-4, 40)
+	}
+	innerWidth := max(boxWidth-2, 0)
+	contentWidth := max(boxWidth-4, 20)
+	border := bs.Render(vBorder)
+
+	var b strings.Builder
+
+	const titleText = " Fork Session "
+	title := s.OverlayTitle.Render(titleText)
+	dashesLeft := max((innerWidth-len(titleText))/2, 0)
+	dashesRight := max(innerWidth-len(titleText)-dashesLeft, 0)
+	b.WriteString(bs.Render(tl))
+	b.WriteString(bs.Render(strings.Repeat(dash, dashesLeft)))
+	b.WriteString(title)
+	b.WriteString(bs.Render(strings.Repeat(dash, dashesRight)))
+	b.WriteString(bs.Render(tr))
+	b.WriteByte('\n')
+
+	writeBoxLine(&b, border, s.Dim.Render("Branch before a prompt; it returns to the editor for a new attempt."), contentWidth)
+	maxW := max(contentWidth-8, 10)
+	for i, p := range m.points {
+		prefix := "  "
+		if i == m.cursor {
+			prefix = "> "
+		}
+		label := "(end) keep the whole conversation"
+		if p.turn >= 0 {
+			label = fmt.Sprintf("%d. %s", p.turn+1, strings.Join(strings.Fields(p.prompt), " "))
+		}
+		if width.VisibleWidth(label) > maxW {
+			label = width.TruncateToWidth(label, maxW-3) + "..."
+		}
+		if i == m.cursor {
+			writeBoxLine(&b, border, s.Selection.Render(prefix+label), contentWidth)
+		} else {
+			writeBoxLine(&b, border, s.Dim.Render(prefix+label), contentWidth)
+		}
+	}
+	writeBoxLine(&b, border, s.Muted.Render("j/k:nav  enter:fork  esc:cancel"), contentWidth)
+
+	b.WriteString(bs.Render(bl))
+	b.WriteString(bs.Render(strings.Repeat(dash, innerWidth)))
+	b.WriteString(bs.Render(br))
+
+	return b.String()
+}
+
+// forkPoints lists the user turns of a session's records.
+func forkPoints(records []session.Record) []forkPoint {
+	var points []forkPoint
+	for _, rec := range records {
+		if rec.Type != session.RecordUser {
+			continue
+		}
+		var ud session.UserData
+		if err := rec.Unmarshal(&ud); err != nil {
+			continue
+		}
+		points = append(points, forkPoint{turn: len(points), prompt: ud.Content})
+	}
+	return points
+}
+
+// sessionsDir is where the saved session lives.
+func (m AppModel) sessionsDir() string {
+	if m.deps.SessionsDir != "" {
+		return m.deps.SessionsDir
+	}
+	return config.SessionsDir()
+}
+
+// openForkPicker opens the picker over the saved session's turns. It
+// returns a notice instead when there is nothing to fork.
+func (m AppModel) openForkPicker() (AppModel, string) {
+	records, err := session.ReadRecordsInDir(m.sessionsDir(), m.deps.Session.ID)
+	if err != nil {
+		return m, fmt.Sprintf("Cannot fork: %v", err)
+	}
+	points := forkPoints(records)
+	if len(points) == 0 {
+		return m, "Nothing to fork yet."
+	}
+	m.overlay = NewForkPickerModel(points, m.width)
+	return m, ""
+}
+
+// forkAt forks the saved session before turn (-1: after everything) and
+// switches to the new branch. The prompt of the turn goes back to the editor.
+func (m AppModel) forkAt(turn int, prompt string) (AppModel, string) {
+	res, err := session.ForkAt(m.sessionsDir(), m.deps.Session.ID, turn)
+	if err != nil {
+		return m, fmt.Sprintf("Fork failed: %v", err)
+	}
+	m, err = m.switchToFork(res)
+	if err != nil {
+		return m, fmt.Sprintf("Forked session %s, but could not switch to it: %v", res.NewID, err)
+	}
+	if prompt != "" {
+		m.editor = m.editor.SetText(prompt)
+	}
+	return m, fmt.Sprintf("Forked session %s from %s after %d turns; now on the new branch.", res.NewID, res.OriginalID, res.Turns)
+}
+
+// switchToFork loads the forked session's history, points the session
+// writer at it, and redraws the conversation.
+func (m AppModel) switchToFork(res *session.ForkResult) (AppModel, error) {
+	return m.switchSession(res.NewID)
+}
+
+// switchSession makes the saved session id current: its history replaces
+// the conversation and later turns are appended to it.
+func (m AppModel) switchSession(id string) (AppModel, error) {
+	dir := m.sessionsDir()
+	records, err := session.ReadRecordsInDir(dir, id)
+	if err != nil {
+		return m, err
+	}
+	msgs, err := session.BuildSessionContext(records)
+	if err != nil {
+		return m, err
+	}
+	if err := m.deps.Session.Reopen(dir, id, msgs); err != nil {
+		return m, err
+	}
+	if td, ok := session.TitleFromRecords(records); ok {
+		m.deps.Session.Title, m.deps.Session.TitleSet = td.Title, !td.Auto
+	}
+	m.content = m.content[:0]
+	m.lastPromptTokens = 0
+	m.undoFloor = 0
+	if slices.ContainsFunc(records, func(r session.Record) bool { return r.Type == session.RecordCompaction }) {
+		m.undoFloor = 2 // the summary and its acknowledgment open the history
+	}
+	m.deps.Checkpoints.Reset()
+	m = m.setQueue(m.promptQueue, m.queueNext) // the queue moves to the new journal
+	m = m.loadMessages(msgs)
+	return m.syncReminders(), nil
+}
+
+// loadMessages sets the conversation to msgs and renders it.
+func (m AppModel) loadMessages(msgs []ai.Message) AppModel {
+	m.messages = msgs
+	return m.renderMessages(msgs)
+}
+
+// renderMessages appends the text of msgs to the content area.
+func (m AppModel) renderMessages(msgs []ai.Message) AppModel {
+	for _, am := range msgs {
+		text := ""
+		for _, c := range am.Content {
+			if c.Type == ai.ContentText {
+				text += c.Text
+			}
+		}
+		switch am.Role {
+		case ai.RoleUser:
+			m.content = append(m.content, NewUserMsgModel(text))
+		case ai.RoleAssistant:
+			assistantModel := NewAssistantMsgModel()
+			assistantModel.width = m.width
+			updated, _ := assistantModel.Update(AgentTextMsg{Text: text})
+			m.content = append(m.content, updated.(*AssistantMsgModel))
+		}
+	}
+	return m
+}
+
+// sessionTreeText renders the forks related to the current session: its
+// oldest saved ancestor and every branch below it.
+func (m AppModel) sessionTreeText() string {
+	if m.deps.Session == nil {
+		return "Session tree not available: this session is not saved."
+	}
+	sessions, err := session.ListSessionsInDir(m.sessionsDir())
+	if err != nil {
+		return fmt.Sprintf("Error listing sessions: %v", err)
+	}
+	root := session.ForkTree(sessions, m.deps.Session.ID)
+	if root == nil {
+		return "Session tree not available: this session is not saved."
+	}
+	tree := NewSessionTreeModel([]*SessionNode{forkTreeNode(root, 0, m.deps.Session.ID)})
+	return "Session branches:\n\n```\n" + tree.Text() + "\n```"
+}
+
+// forkTreeNode converts a fork tree into SessionTree nodes.
+func forkTreeNode(f *session.ForkNode, level int, current string) *SessionNode {
+	node := &SessionNode{
+		ID:       f.ID,
+		ParentID: f.ParentID,
+		Model:    f.Model,
+		Level:    level,
+		IsBranch: len(f.Children) > 0,
+		Forked:   f.ParentID != "",
+		ForkTurn: f.ForkTurn,
+		Current:  f.ID == current,
+	}
+	for _, c := range f.Children {
+		node.Children = append(node.Children, forkTreeNode(c, level+1, current))
+	}
+	return node
+}
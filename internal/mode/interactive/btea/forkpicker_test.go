@@ -0,0 +1,161 @@
+// ABOUTME: Tests for /fork: the fork-point picker, forking at a turn, and switching to the branch
+// ABOUTME: Also covers /tree rendering fork families with the current session marked
+
+package btea
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mauromedda/pi-coding-agent-go/internal/session"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+// Compile-time check: ForkPickerModel must satisfy tea.Model.
+var _ tea.Model = ForkPickerModel{}
+
+// forkTestModel returns an AppModel whose session "s1" is saved in a temp
+// dir with two user turns.
+func forkTestModel(t *testing.T) AppModel {
+	t.Helper()
+	dir := t.TempDir()
+	w, err := session.NewWriterInDir(dir, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRecord(session.RecordSessionStart, session.SessionStartData{ID: "s1", Model: "test-model"}); err != nil {
+		t.Fatal(err)
+	}
+	sess := &session.Session{ID: "s1", Model: &ai.Model{Name: "test-model"}, Writer: w}
+	t.Cleanup(func() { sess.Close() })
+	for _, turn := range []struct{ q, a string }{{"first question", "first answer"}, {"second question", "second answer"}} {
+		if err := sess.AddUserMessage(turn.q); err != nil {
+			t.Fatal(err)
+		}
+		if err := sess.AddAssistantMessage(&ai.AssistantMessage{Content: []ai.Content{{Type: ai.ContentText, Text: turn.a}}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deps := testDeps()
+	deps.Session = sess
+	deps.SessionsDir = dir
+	m := NewAppModel(deps)
+	m.messages = sess.Messages
+	return m
+}
+
+func TestForkPickerModel_Keys(t *testing.T) {
+	m := NewForkPickerModel([]forkPoint{{turn: 0, prompt: "a"}, {turn: 1, prompt: "b"}}, 80)
+	if m.cursor != 2 {
+		t.Fatalf("cursor = %d; want the whole-session entry (2)", m.cursor)
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'k'}})
+	m = updated.(ForkPickerModel)
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("enter should emit a command")
+	}
+	sel, ok := cmd().(ForkPointSelectedMsg)
+	if !ok || sel.Turn != 1 || sel.Prompt != "b" {
+		t.Errorf("enter = %#v; want turn 1 %q", sel, "b")
+	}
+
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if cmd == nil {
+		t.Fatal("esc should emit a command")
+	}
+	if _, ok := cmd().(DismissOverlayMsg); !ok {
+		t.Error("esc should dismiss the picker")
+	}
+
+	view := m.View()
+	for _, want := range []string{"Fork Session", "1. a", "2. b", "(end)"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View() missing %q", want)
+		}
+	}
+}
+
+func TestAppModel_ForkOpensPicker(t *testing.T) {
+	m := forkTestModel(t)
+	m, _ = m.handleSlashCommand("/fork")
+	picker, ok := m.overlay.(ForkPickerModel)
+	if !ok {
+		t.Fatalf("overlay = %T; want ForkPickerModel", m.overlay)
+	}
+	if len(picker.points) != 3 || picker.points[1].prompt != "second question" {
+		t.Errorf("points = %+v", picker.points)
+	}
+}
+
+func TestAppModel_ForkPointSelectedSwitchesBranch(t *testing.T) {
+	m := forkTestModel(t)
+	result, _ := m.Update(ForkPointSelectedMsg{Turn: 1, Prompt: "second question"})
+	m = result.(AppModel)
+
+	if m.deps.Session.ID == "s1" {
+		t.Fatal("session should be switched to the fork")
+	}
+	if len(m.messages) != 2 || len(m.deps.Session.Messages) != 2 {
+		t.Errorf("messages = %d, session messages = %d; want the first turn only", len(m.messages), len(m.deps.Session.Messages))
+	}
+	if got := m.editor.Text(); got != "second question" {
+		t.Errorf("editor = %q; want the forked-from prompt", got)
+	}
+	if !strings.Contains(m.lastAssistantText(), "after 1 turns") {
+		t.Errorf("notice = %q", m.lastAssistantText())
+	}
+
+	// New turns land in the fork, not the original.
+	if err := m.deps.Session.AddUserMessage("another try"); err != nil {
+		t.Fatal(err)
+	}
+	records, err := session.ReadRecordsInDir(m.sessionsDir(), m.deps.Session.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if points := forkPoints(records); len(points) != 2 || points[1].prompt != "another try" {
+		t.Errorf("fork turns = %+v", points)
+	}
+}
+
+func TestAppModel_ForkAllAndTree(t *testing.T) {
+	m := forkTestModel(t)
+	m, _ = m.handleSlashCommand("/fork all")
+	if m.deps.Session.ID == "s1" {
+		t.Fatal("/fork all should switch to the fork")
+	}
+	if len(m.messages) != 4 {
+		t.Errorf("messages = %d; want the whole conversation", len(m.messages))
+	}
+	if !strings.Contains(m.lastAssistantText(), "now on the new branch") {
+		t.Errorf("result = %q", m.lastAssistantText())
+	}
+
+	m, _ = m.handleSlashCommand("/tree")
+	tree := m.lastAssistantText()
+	for _, want := range []string{"s1", m.deps.Session.ID + " [test-model] forked after turn 2 (current)"} {
+		if !strings.Contains(tree, want) {
+			t.Errorf("/tree missing %q:\n%s", want, tree)
+		}
+	}
+}
+
+func TestAppModel_AgentDoneSavesOnlyNewReplies(t *testing.T) {
+	m := forkTestModel(t)
+	before := len(m.deps.Session.Messages)
+	msgs := append(append([]ai.Message{}, m.messages...),
+		ai.NewTextMessage(ai.RoleUser, "third question"),
+		ai.NewTextMessage(ai.RoleAssistant, "third answer"),
+	)
+	m.agentRunning = true
+	result, _ := m.Update(AgentDoneMsg{Messages: msgs})
+	m = result.(AppModel)
+
+	if got := len(m.deps.Session.Messages) - before; got != 1 {
+		t.Errorf("saved %d messages; want only the new reply", got)
+	}
+}
@@ -0,0 +1,56 @@
+// ABOUTME: Terminal-dependent glyphs and color profile for the TUI chrome
+// ABOUTME: Defaults to Unicode and truecolor; Run applies the detected (or --ascii) set so limited terminals degrade cleanly
+
+package btea
+
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+
+	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/termcap"
+)
+
+// glyphs holds the separator, spinner, and tree characters in use.
+// Set once by applyTermcap before the program starts.
+var glyphs = termcap.Capabilities{Unicode: true}.Glyphs()
+
+// colorDepth is the color depth theme colors are downsampled to,
+// asciiOnly folds every frame to ASCII, and hyperlinks makes Markdown links
+// clickable with OSC 8. All are set by applyTermcap.
+var (
+	colorDepth = termcap.ColorTrue
+	asciiOnly  bool
+	hyperlinks bool
+)
+
+// applyTermcap adopts the detected glyph set and color depth.
+func applyTermcap(caps termcap.Capabilities) {
+	glyphs = caps.Glyphs()
+	colorDepth = caps.Colors
+	asciiOnly = !caps.Unicode
+	hyperlinks = caps.Hyperlinks
+	lipgloss.SetColorProfile(colorProfile(caps.Colors))
+}
+
+// foldGlyphs rewrites a rendered frame for terminals without Unicode,
+// covering box borders, status symbols, and rendered Markdown alike.
+func foldGlyphs(frame string) string {
+	if !asciiOnly {
+		return frame
+	}
+	return termcap.FoldASCII(frame)
+}
+
+// colorProfile maps a detected color depth onto the lipgloss rendering profile.
+func colorProfile(d termcap.ColorDepth) termenv.Profile {
+	switch d {
+	case termcap.ColorTrue:
+		return termenv.TrueColor
+	case termcap.Color256:
+		return termenv.ANSI256
+	case termcap.Color16:
+		return termenv.ANSI
+	default:
+		return termenv.Ascii
+	}
+}
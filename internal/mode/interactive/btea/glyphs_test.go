@@ -0,0 +1,90 @@
+// ABOUTME: Tests for terminal-dependent glyph selection and color profile mapping
+// ABOUTME: Covers ASCII fallbacks, the lipgloss profile mapping, theme downsampling, and ASCII frame folding
+
+package btea
+
+import (
+	"testing"
+
+	"github.com/muesli/termenv"
+
+	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/termcap"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/theme"
+)
+
+func TestColorProfile(t *testing.T) {
+	tests := map[termcap.ColorDepth]termenv.Profile{
+		termcap.ColorTrue: termenv.TrueColor,
+		termcap.Color256:  termenv.ANSI256,
+		termcap.Color16:   termenv.ANSI,
+		termcap.ColorNone: termenv.Ascii,
+	}
+	for depth, want := range tests {
+		if got := colorProfile(depth); got != want {
+			t.Errorf("colorProfile(%v) = %v; want %v", depth, got, want)
+		}
+	}
+}
+
+func TestGlyphs_ASCIITreePrefix(t *testing.T) {
+	saved := glyphs
+	t.Cleanup(func() { glyphs = saved })
+	glyphs = termcap.Capabilities{Unicode: false}.Glyphs()
+
+	flat := []*SessionNode{
+		{ID: "root", Level: 0},
+		{ID: "child", Level: 1},
+	}
+	line := formatTreeNode(flat[1], flat, 1)
+	if want := "`-- "; len(line) < len(want) || line[:len(want)] != want {
+		t.Errorf("tree line = %q; want prefix %q", line, want)
+	}
+}
+
+func TestDownsampleTheme(t *testing.T) {
+	th := theme.Builtin("dark")
+	if got := downsampleTheme(th, termcap.ColorTrue); got != th {
+		t.Error("truecolor should keep the theme as is")
+	}
+
+	basic := downsampleTheme(th, termcap.Color16)
+	if got := basic.Palette.Selection.Code(); got != "\x1b[100m" {
+		t.Errorf("16-color selection = %q; want bright black background", got)
+	}
+	plain := downsampleTheme(th, termcap.ColorNone)
+	if got := plain.Palette.Selection.Code(); got != "\x1b[7m" {
+		t.Errorf("no-color selection = %q; want reverse video", got)
+	}
+	if got := th.Palette.Selection.Code(); got != "\x1b[48;5;236m" {
+		t.Errorf("original selection = %q; downsampling must not modify the theme", got)
+	}
+}
+
+func TestStyles_FollowColorDepth(t *testing.T) {
+	keepDefaultTheme(t)
+	saved := colorDepth
+	t.Cleanup(func() { colorDepth = saved })
+	theme.Set(theme.Builtin("dark"))
+
+	colorDepth = termcap.ColorTrue
+	full := Styles()
+	colorDepth = termcap.ColorNone
+	if plain := Styles(); plain.Selection.GetReverse() == full.Selection.GetReverse() {
+		t.Error("Styles should be rebuilt when the color depth changes")
+	}
+}
+
+func TestFoldGlyphs(t *testing.T) {
+	saved := asciiOnly
+	t.Cleanup(func() { asciiOnly = saved })
+
+	frame := "╭─ Theme ─╮\n│ ✓ done │"
+	asciiOnly = false
+	if got := foldGlyphs(frame); got != frame {
+		t.Errorf("foldGlyphs = %q; want the frame untouched with Unicode", got)
+	}
+	asciiOnly = true
+	if got := foldGlyphs(frame); got != "+- Theme -+\n| + done |" {
+		t.Errorf("foldGlyphs = %q", got)
+	}
+}
@@ -0,0 +1,130 @@
+// ABOUTME: Theme-aware syntax highlighting for fenced code in assistant Markdown
+// ABOUTME: Registers a chroma style built from the theme palette and picks the formatter for the color depth
+
+package btea
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/alecthomas/chroma/v2"
+	chromastyles "github.com/alecthomas/chroma/v2/styles"
+	"github.com/charmbracelet/glamour/ansi"
+	"github.com/charmbracelet/glamour/styles"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/termcap"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/theme"
+	"golang.org/x/term"
+)
+
+// codeStyles maps a theme to the name of the chroma style registered for
+// it. Chroma keeps styles in a global registry, so each theme is
+// registered once, under its own name.
+var codeStyles = struct {
+	sync.Mutex
+	names map[*theme.Theme]string
+}{names: make(map[*theme.Theme]string)}
+
+// stdoutIsTerminal reports whether output goes to a terminal; like
+// glamour's auto style, Markdown is rendered plain when it does not.
+var stdoutIsTerminal = func() bool { return term.IsTerminal(int(os.Stdout.Fd())) }
+
+// markdownStyle returns the glamour style for the active theme and color
+// depth, with code blocks highlighted in the theme's colors.
+func markdownStyle() ansi.StyleConfig {
+	t := theme.Current()
+	switch {
+	case colorDepth == termcap.ColorNone || !stdoutIsTerminal():
+		return styles.NoTTYStyleConfig
+	case t.Light || !lipgloss.HasDarkBackground():
+		return withCodeStyle(styles.LightStyleConfig, t)
+	default:
+		return withCodeStyle(styles.DarkStyleConfig, t)
+	}
+}
+
+// withCodeStyle makes cfg highlight code blocks with t's chroma style.
+// Glamour registers its own Chroma settings only once per process, so they
+// are cleared in favor of a named style that follows theme changes.
+func withCodeStyle(cfg ansi.StyleConfig, t *theme.Theme) ansi.StyleConfig {
+	cfg.CodeBlock.Chroma = nil
+	cfg.CodeBlock.Theme = codeStyle(t)
+	return cfg
+}
+
+// chromaFormatter returns the chroma terminal formatter for a color depth.
+func chromaFormatter(depth termcap.ColorDepth) string {
+	switch depth {
+	case termcap.Color256:
+		return "terminal256"
+	case termcap.Color16, termcap.ColorNone:
+		return "terminal16"
+	default:
+		return "terminal16m"
+	}
+}
+
+// codeStyle returns the name of the chroma style for t, registering it on
+// first use.
+func codeStyle(t *theme.Theme) string {
+	codeStyles.Lock()
+	defer codeStyles.Unlock()
+	if name, ok := codeStyles.names[t]; ok {
+		return name
+	}
+	name := fmt.Sprintf("pi-go-%d-%s", len(codeStyles.names), t.Name)
+	chromastyles.Register(chroma.MustNewStyle(name, codeEntries(t.Palette)))
+	codeStyles.names[t] = name
+	return name
+}
+
+// codeEntries maps token types onto the palette, so code reads like the
+// rest of the TUI: keywords in the accent color, strings as success,
+// numbers as warning, and diff lines in the same colors as tool diffs.
+func codeEntries(p theme.Palette) chroma.StyleEntries {
+	entry := func(c theme.Color, extra string) string {
+		hex := colorHex(c)
+		if hex == "" {
+			return extra
+		}
+		return strings.TrimSpace(hex + " " + extra)
+	}
+	return chroma.StyleEntries{
+		chroma.Comment:           entry(p.Secondary, "italic"),
+		chroma.CommentPreproc:    entry(p.Info, ""),
+		chroma.Keyword:           entry(p.Accent, "bold"),
+		chroma.KeywordType:       entry(p.Info, ""),
+		chroma.NameFunction:      entry(p.Info, ""),
+		chroma.NameClass:         entry(p.Warning, "bold"),
+		chroma.NameBuiltin:       entry(p.Accent, ""),
+		chroma.NameTag:           entry(p.Accent, ""),
+		chroma.NameAttribute:     entry(p.Info, ""),
+		chroma.LiteralString:     entry(p.Success, ""),
+		chroma.LiteralNumber:     entry(p.Warning, ""),
+		chroma.Error:             entry(p.Error, ""),
+		chroma.GenericInserted:   entry(p.Success, ""),
+		chroma.GenericDeleted:    entry(p.Error, ""),
+		chroma.GenericSubheading: entry(p.Secondary, ""),
+		chroma.GenericHeading:    entry(p.Info, "bold"),
+		chroma.GenericEmph:       "italic",
+		chroma.GenericStrong:     "bold",
+	}
+}
+
+// colorHex returns c's color as "#rrggbb", or "" for attribute-only
+// codes. Chroma formatters downsample it to the terminal's depth.
+func colorHex(c theme.Color) string {
+	spec := extractColor(c.Code())
+	if spec == "" || strings.HasPrefix(spec, "#") {
+		return spec
+	}
+	idx, err := strconv.Atoi(spec)
+	if err != nil {
+		return ""
+	}
+	rgb := termcap.PaletteRGB(idx)
+	return fmt.Sprintf("#%02x%02x%02x", rgb[0], rgb[1], rgb[2])
+}
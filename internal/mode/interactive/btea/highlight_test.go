@@ -0,0 +1,108 @@
+// ABOUTME: Tests for syntax highlighting of fenced code in theme colors
+// ABOUTME: Covers palette-to-hex mapping, formatter choice, theme switches, and plain output without color
+
+package btea
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/termcap"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/theme"
+)
+
+// highlightOnTerminal renders Markdown as if on a terminal of the given
+// depth, restoring the globals when the test ends.
+func highlightOnTerminal(t *testing.T, depth termcap.ColorDepth) {
+	t.Helper()
+	keepDefaultTheme(t)
+	savedTTY, savedDepth := stdoutIsTerminal, colorDepth
+	t.Cleanup(func() { stdoutIsTerminal, colorDepth = savedTTY, savedDepth })
+	stdoutIsTerminal = func() bool { return true }
+	colorDepth = depth
+}
+
+// truecolorFg returns the SGR sequence chroma's truecolor formatter emits
+// for c.
+func truecolorFg(t *testing.T, c theme.Color) string {
+	t.Helper()
+	hex := colorHex(c)
+	if hex == "" {
+		t.Fatalf("color %q has no hex value", c.Code())
+	}
+	var r, g, b int
+	if _, err := fmt.Sscanf(hex, "#%02x%02x%02x", &r, &g, &b); err != nil {
+		t.Fatalf("parse %q: %v", hex, err)
+	}
+	return fmt.Sprintf("\x1b[38;2;%d;%d;%dm", r, g, b)
+}
+
+func TestColorHex(t *testing.T) {
+	tests := map[string]string{
+		"\x1b[38;5;208m":       "#ff8700",
+		"\x1b[32m":             "#00cd00",
+		"\x1b[38;2;1;2;3m":     "#010203",
+		"\x1b[1m":              "",
+		"\x1b[1m\x1b[38;5;16m": "#000000",
+	}
+	for code, want := range tests {
+		if got := colorHex(theme.NewColor(code)); got != want {
+			t.Errorf("colorHex(%q) = %q; want %q", code, got, want)
+		}
+	}
+}
+
+func TestChromaFormatter(t *testing.T) {
+	tests := map[termcap.ColorDepth]string{
+		termcap.ColorTrue: "terminal16m",
+		termcap.Color256:  "terminal256",
+		termcap.Color16:   "terminal16",
+	}
+	for depth, want := range tests {
+		if got := chromaFormatter(depth); got != want {
+			t.Errorf("chromaFormatter(%v) = %q; want %q", depth, got, want)
+		}
+	}
+}
+
+func TestMarkdownRenderer_HighlightsCodeInThemeColors(t *testing.T) {
+	highlightOnTerminal(t, termcap.ColorTrue)
+	theme.Set(theme.Builtin("dark"))
+	md := "```go\nfunc main() { println(\"hi\") }\n```"
+
+	dark := NewMarkdownRenderer().Render(md, 80)
+	p := theme.Builtin("dark").Palette
+	if want := "\x1b[1m" + truecolorFg(t, p.Accent) + "func"; !strings.Contains(dark, want) {
+		t.Errorf("keyword not in the accent color; got %q", dark)
+	}
+	if want := truecolorFg(t, p.Success) + `"hi"`; !strings.Contains(dark, want) {
+		t.Errorf("string not in the success color; got %q", dark)
+	}
+
+	theme.Set(theme.Builtin("light"))
+	light := NewMarkdownRenderer().Render(md, 80)
+	if want := truecolorFg(t, theme.Builtin("light").Palette.Accent) + "func"; !strings.Contains(light, want) {
+		t.Errorf("after a theme change the keyword should use the new accent; got %q", light)
+	}
+}
+
+func TestMarkdownRenderer_DiffFenceMatchesToolDiffs(t *testing.T) {
+	highlightOnTerminal(t, termcap.ColorTrue)
+	theme.Set(theme.Builtin("dark"))
+	p := theme.Current().Palette
+
+	out := NewMarkdownRenderer().Render("```diff\n-old\n+new\n```", 80)
+	if !strings.Contains(out, truecolorFg(t, p.Error)+"-old") || !strings.Contains(out, truecolorFg(t, p.Success)+"+new") {
+		t.Errorf("diff lines should use the error and success colors; got %q", out)
+	}
+}
+
+func TestMarkdownRenderer_NoColorIsPlain(t *testing.T) {
+	highlightOnTerminal(t, termcap.ColorNone)
+
+	out := NewMarkdownRenderer().Render("```go\nfunc main() {}\n```", 80)
+	if strings.Contains(out, "\x1b[") || !strings.Contains(out, "func main() {}") {
+		t.Errorf("code should render without escapes; got %q", out)
+	}
+}
@@ -0,0 +1,217 @@
+// ABOUTME: InitReviewModel overlay showing the diff of the PI.md /init proposes before anything is written
+// ABOUTME: AppModel handlers start the analysis turn, pick the proposal out of its reply, and write it on approval
+
+package btea
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/diff"
+	"github.com/mauromedda/pi-coding-agent-go/internal/memory"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/width"
+)
+
+// InitReviewModel shows the proposed PI.md as a diff against the current
+// file and waits for y/enter to write it or n/esc to discard it.
+type InitReviewModel struct {
+	path    string // file to write
+	content string // proposed content
+	lines   []string
+	offset  int
+	width   int
+	height  int
+}
+
+// NewInitReviewModel creates the review of content for the file at path,
+// shown relative to root.
+func NewInitReviewModel(path, root, content string, w, h int) InitReviewModel {
+	old, _ := os.ReadFile(path)
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		rel = path
+	}
+	d := diff.Unified(filepath.ToSlash(rel), string(old), content)
+	return InitReviewModel{
+		path:    path,
+		content: content,
+		lines:   strings.Split(strings.TrimRight(d, "\n"), "\n"),
+		width:   w,
+		height:  h,
+	}
+}
+
+// Init returns nil; no startup commands needed.
+func (m InitReviewModel) Init() tea.Cmd { return nil }
+
+// Update handles scrolling and the decision.
+func (m InitReviewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "y", "enter":
+			path, content := m.path, m.content
+			return m, func() tea.Msg { return InitReviewMsg{Path: path, Content: content, Write: true} }
+		case "n", "esc", "q":
+			path := m.path
+			return m, func() tea.Msg { return InitReviewMsg{Path: path} }
+		case "j", "down":
+			m.offset = min(m.offset+1, m.maxOffset())
+		case "k", "up":
+			m.offset = max(m.offset-1, 0)
+		case "pgdown", " ":
+			m.offset = min(m.offset+m.visible(), m.maxOffset())
+		case "pgup":
+			m.offset = max(m.offset-m.visible(), 0)
+		}
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.offset = min(m.offset, m.maxOffset())
+	}
+	return m, nil
+}
+
+// visible returns how many diff lines fit in the box.
+func (m InitReviewModel) visible() int {
+	if m.height <= 0 {
+		return 20
+	}
+	return max(m.height-8, 5)
+}
+
+func (m InitReviewModel) maxOffset() int {
+	return max(len(m.lines)-m.visible(), 0)
+}
+
+// View renders the diff as a bordered, scrollable box.
+func (m InitReviewModel) View() string {
+	s := Styles()
+	bs := s.OverlayBorder
+
+	const (
+		dash    = "─"
+		vBorder = "│"
+		tl      = "╭"
+		tr      = "╮"
+		bl      = "╰"
+		br      = "╯"
+	)
+
+	boxWidth := max(m.width*4/5, 60)
+	if boxWidth > m.width-4 {
+		boxWidth = max(m.width-4, 40)
+	}
+	innerWidth := max(boxWidth-2, 0)
+	contentWidth := max(boxWidth-4, 20)
+	border := bs.Render(vBorder)
+
+	var b strings.Builder
+
+	const titleText = " Write PI.md? "
+	title := s.OverlayTitle.Render(titleText)
+	dashesLeft := max((innerWidth-len(titleText))/2, 0)
+	dashesRight := max(innerWidth-len(titleText)-dashesLeft, 0)
+	b.WriteString(bs.Render(tl))
+	b.WriteString(bs.Render(strings.Repeat(dash, dashesLeft)))
+	b.WriteString(title)
+	b.WriteString(bs.Render(strings.Repeat(dash, dashesRight)))
+	b.WriteString(bs.Render(tr))
+	b.WriteByte('\n')
+
+	end := min(m.offset+m.visible(), len(m.lines))
+	for _, line := range m.lines[m.offset:end] {
+		line = strings.ReplaceAll(line, "\t", "    ")
+		if width.VisibleWidth(line) > contentWidth {
+			line = width.TruncateToWidth(line, contentWidth)
+		}
+		writeBoxLine(&b, border, RenderDiff(line, s), contentWidth)
+	}
+	writeBoxLine(&b, border, "", contentWidth)
+	status := "y/enter:write  n/esc:discard"
+	if len(m.lines) > m.visible() {
+		status = fmt.Sprintf("lines %d-%d of %d  j/k:scroll  %s", m.offset+1, end, len(m.lines), status)
+	}
+	writeBoxLine(&b, border, s.Muted.Render(status), contentWidth)
+
+	b.WriteString(bs.Render(bl))
+	b.WriteString(bs.Render(strings.Repeat(dash, innerWidth)))
+	b.WriteString(bs.Render(br))
+
+	return b.String()
+}
+
+// startInit starts the /init analysis turn; focus is the user's extra
+// instruction. The reply's proposal is reviewed when the turn ends.
+func (m AppModel) startInit(focus string) (AppModel, tea.Cmd) {
+	if m.agentRunning {
+		m, _ = m.applyNotice("/init needs the agent idle; run it again when this turn ends.")
+		return m, nil
+	}
+	root := m.gitCWD
+	if root == "" {
+		root, _ = os.Getwd()
+	}
+	m.initTarget = memory.InitTarget(root)
+	return m.submitPrompt(memory.InitPrompt(root, m.initTarget, focus))
+}
+
+// finishInit opens the review of the PI.md the /init turn proposed, if it
+// was that turn that ended.
+func (m AppModel) finishInit() (AppModel, string) {
+	target := m.initTarget
+	if target == "" {
+		return m, ""
+	}
+	m.initTarget = ""
+	content, ok := memory.ExtractInit(lastReplyText(m.messages))
+	if !ok {
+		return m, "/init: the reply held no PI.md between " + memory.InitBegin + " markers; nothing was written."
+	}
+	root := m.gitCWD
+	if root == "" {
+		root = filepath.Dir(target)
+	}
+	if old, err := os.ReadFile(target); err == nil && string(old) == content {
+		return m, fmt.Sprintf("/init: %s is already up to date.", filepath.Base(target))
+	}
+	m.overlay = NewInitReviewModel(target, root, content, m.width, m.height)
+	return m, ""
+}
+
+// lastReplyText returns the text of the last assistant message in msgs.
+func lastReplyText(msgs []ai.Message) string {
+	for i := len(msgs) - 1; i >= 0; i-- {
+		if msgs[i].Role != ai.RoleAssistant || !hasText(msgs[i]) {
+			continue
+		}
+		var b strings.Builder
+		for _, c := range msgs[i].Content {
+			if c.Type == ai.ContentText {
+				b.WriteString(c.Text)
+			}
+		}
+		return b.String()
+	}
+	return ""
+}
+
+// answerInit writes the reviewed PI.md when approved and reloads memory,
+// so the next turn sees it.
+func (m AppModel) answerInit(msg InitReviewMsg) (tea.Model, tea.Cmd) {
+	if !msg.Write {
+		return m.applyEffects(&cmdSideEffects{}, fmt.Sprintf("Discarded the proposed %s.", filepath.Base(msg.Path)))
+	}
+	if err := memory.WriteInit(msg.Path, msg.Content); err != nil {
+		return m.applyEffects(&cmdSideEffects{}, fmt.Sprintf("Could not write %s: %v", msg.Path, err))
+	}
+	done := fmt.Sprintf("Wrote %s.", msg.Path)
+	if m.deps.Memory == nil {
+		return m.applyEffects(&cmdSideEffects{}, done)
+	}
+	return m.reloadMemory(done)
+}
@@ -0,0 +1,100 @@
+// ABOUTME: Tests for /init: the analysis turn, the diff review of the proposed PI.md, and writing it
+// ABOUTME: Agent replies are simulated with AgentDoneMsg; files go to a temp project directory
+
+package btea
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+// initReply returns the conversation after an /init turn answered reply.
+func initReply(m AppModel, reply string) AgentDoneMsg {
+	msgs := append([]ai.Message{}, m.messages...)
+	msgs = append(msgs, ai.Message{Role: ai.RoleAssistant, Content: []ai.Content{{Type: ai.ContentText, Text: reply}}})
+	return AgentDoneMsg{Messages: msgs}
+}
+
+func TestAppModel_InitProposesAndWritesPIMD(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	target := filepath.Join(root, "PI.md")
+	if err := os.WriteFile(target, []byte("# App\n\nOld notes.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m := newTestAppModel()
+	m.gitCWD = root
+
+	m, _ = m.startInit("keep it short")
+	if m.initTarget != target {
+		t.Fatalf("initTarget = %q; want %q", m.initTarget, target)
+	}
+	if len(m.messages) == 0 || !strings.Contains(m.messages[len(m.messages)-1].Content[0].Text, "keep it short") {
+		t.Fatal("the analysis prompt was not sent")
+	}
+
+	updated, _ := m.Update(initReply(m, "Analysis done.\n<pi-md>\n# App\n\n## Commands\n- make test\n</pi-md>"))
+	m = updated.(AppModel)
+	review, ok := m.overlay.(InitReviewModel)
+	if !ok {
+		t.Fatalf("overlay = %T; want InitReviewModel", m.overlay)
+	}
+	if view := review.View(); !strings.Contains(view, "-Old notes.") || !strings.Contains(view, "+- make test") {
+		t.Errorf("review does not show the diff:\n%s", view)
+	}
+	if data, _ := os.ReadFile(target); string(data) != "# App\n\nOld notes.\n" {
+		t.Fatal("PI.md changed before approval")
+	}
+
+	_, cmd := review.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	updated, _ = m.Update(cmd())
+	m = updated.(AppModel)
+	if data, _ := os.ReadFile(target); string(data) != "# App\n\n## Commands\n- make test\n" {
+		t.Errorf("PI.md = %q after approval", data)
+	}
+	if m.overlay != nil || m.initTarget != "" || !strings.Contains(m.lastAssistantText(), "Wrote") {
+		t.Errorf("after approval: overlay %T, target %q, notice %q", m.overlay, m.initTarget, m.lastAssistantText())
+	}
+}
+
+func TestAppModel_InitDiscardAndMissingProposal(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	m := newTestAppModel()
+	m.gitCWD = root
+
+	m, _ = m.startInit("")
+	updated, _ := m.Update(initReply(m, "I need more information first."))
+	m = updated.(AppModel)
+	if m.overlay != nil || !strings.Contains(m.lastAssistantText(), "nothing was written") {
+		t.Errorf("overlay %T, notice %q; want a notice and no review", m.overlay, m.lastAssistantText())
+	}
+
+	m, _ = m.startInit("")
+	updated, _ = m.Update(initReply(m, "<pi-md>\n# App\n</pi-md>"))
+	m = updated.(AppModel)
+	review := m.overlay.(InitReviewModel)
+	_, cmd := review.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	updated, _ = m.Update(cmd())
+	m = updated.(AppModel)
+	if _, err := os.Stat(filepath.Join(root, "PI.md")); !os.IsNotExist(err) {
+		t.Error("a discarded proposal was written")
+	}
+	if !strings.Contains(m.lastAssistantText(), "Discarded") {
+		t.Errorf("notice = %q", m.lastAssistantText())
+	}
+
+	// A later, ordinary turn is not taken for an /init reply.
+	updated, _ = m.Update(initReply(m, "<pi-md>\n# Unrelated\n</pi-md>"))
+	if updated.(AppModel).overlay != nil {
+		t.Error("a turn after /init opened a review")
+	}
+}
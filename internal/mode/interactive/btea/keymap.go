@@ -0,0 +1,230 @@
+// ABOUTME: KeyMap: interactive-mode actions bound to key chords, rebindable under settings.keybindings
+// ABOUTME: ParseKeyMap validates the overrides at startup; /hotkeys lists the effective map
+
+package btea
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// keyAction names a rebindable interactive-mode action.
+type keyAction string
+
+const (
+	actionSubmit         keyAction = "submit"
+	actionQueueFollowUp  keyAction = "queue-follow-up"
+	actionAbort          keyAction = "abort"
+	actionExit           keyAction = "exit"
+	actionSuspend        keyAction = "suspend"
+	actionClear          keyAction = "clear"
+	actionToggleMode     keyAction = "toggle-mode"
+	actionAutoAccept     keyAction = "auto-accept"
+	actionCycleThinking  keyAction = "cycle-thinking"
+	actionModelSelector  keyAction = "model-selector"
+	actionEditPrompt     keyAction = "edit-prompt"
+	actionBackground     keyAction = "background"
+	actionQueue          keyAction = "queue"
+	actionTranscript     keyAction = "transcript"
+	actionCost           keyAction = "cost"
+	actionToggleImages   keyAction = "toggle-images"
+	actionExpandOutput   keyAction = "expand-output"
+	actionExpandThinking keyAction = "expand-thinking"
+)
+
+// keyActions lists the rebindable actions in /hotkeys order, with their
+// default chords.
+var keyActions = []struct {
+	action keyAction
+	keys   []string
+	desc   string
+}{
+	{actionSubmit, []string{"enter", "shift+enter"}, "Send the message, or queue it while the agent works"},
+	{actionQueueFollowUp, []string{"alt+enter"}, "Queue the message without sending it"},
+	{actionAbort, []string{"ctrl+c"}, "Abort the turn; when idle, clear, and twice to exit"},
+	{actionExit, []string{"ctrl+d"}, "Exit"},
+	{actionSuspend, []string{"ctrl+z"}, "Suspend to the shell"},
+	{actionClear, []string{"ctrl+l"}, "Clear the screen"},
+	{actionToggleMode, []string{"alt+p"}, "Switch between plan and edit mode"},
+	{actionAutoAccept, []string{"shift+tab"}, "Toggle auto-accept"},
+	{actionCycleThinking, []string{"alt+t"}, "Cycle the thinking level"},
+	{actionModelSelector, []string{"alt+m"}, "Pick a model"},
+	{actionEditPrompt, []string{"alt+e"}, "Edit a past prompt and re-run from it"},
+	{actionBackground, []string{"ctrl+b"}, "Move the turn to the background, or list background tasks"},
+	{actionQueue, []string{"ctrl+e"}, "Show queued prompts"},
+	{actionTranscript, []string{"ctrl+r"}, "Page through the full transcript"},
+	{actionCost, []string{"ctrl+t"}, "Toggle the cost dashboard"},
+	{actionToggleImages, []string{"alt+i"}, "Show or hide images"},
+	{actionExpandOutput, []string{"ctrl+o"}, "Expand or collapse the latest folded tool output"},
+	{actionExpandThinking, []string{"alt+k"}, "Expand or collapse the thinking of the latest reply that has some"},
+}
+
+// namedKeys are the multi-character key names a chord may end in, as
+// Bubble Tea spells them, with the aliases accepted for them.
+var namedKeys = map[string]string{
+	"enter": "enter", "return": "enter", "tab": "tab", "esc": "esc", "escape": "esc",
+	"space": " ", "backspace": "backspace", "delete": "delete", "insert": "insert",
+	"up": "up", "down": "down", "left": "left", "right": "right", "home": "home", "end": "end",
+	"pgup": "pgup", "pageup": "pgup", "pgdown": "pgdown", "pagedown": "pgdown",
+}
+
+// KeyMap binds key chords to actions. The zero KeyMap has the default
+// bindings.
+type KeyMap struct {
+	chords map[keyAction][]string
+	byKey  map[string]keyAction
+}
+
+// defaultKeys is the KeyMap with no overrides.
+var defaultKeys, _ = ParseKeyMap(nil)
+
+// ParseKeyMap applies overrides, action name to chords, to the default
+// bindings. It rejects unknown actions, malformed chords, and a chord
+// bound to two actions. An empty chord list unbinds the action.
+func ParseKeyMap(overrides map[string][]string) (KeyMap, error) {
+	k := KeyMap{
+		chords: make(map[keyAction][]string, len(keyActions)),
+		byKey:  make(map[string]keyAction, len(keyActions)),
+	}
+	for _, b := range keyActions {
+		k.chords[b.action] = b.keys
+	}
+	for _, name := range slices.Sorted(maps.Keys(overrides)) {
+		action := keyAction(name)
+		if _, ok := k.chords[action]; !ok {
+			return KeyMap{}, fmt.Errorf("unknown action %q; /hotkeys lists them", name)
+		}
+		chords := make([]string, 0, len(overrides[name]))
+		for _, c := range overrides[name] {
+			norm, err := normalizeChord(c)
+			if err != nil {
+				return KeyMap{}, fmt.Errorf("%s: %w", name, err)
+			}
+			chords = append(chords, norm)
+		}
+		k.chords[action] = chords
+	}
+	for _, b := range keyActions {
+		for _, c := range k.chords[b.action] {
+			if other, ok := k.byKey[c]; ok && other != b.action {
+				return KeyMap{}, fmt.Errorf("%s is bound to both %s and %s", displayChord(c), other, b.action)
+			}
+			k.byKey[c] = b.action
+		}
+	}
+	return k, nil
+}
+
+// normalizeChord checks a chord such as "ctrl+x" or "Alt+Enter" and spells
+// it the way tea.KeyMsg.String does.
+func normalizeChord(chord string) (string, error) {
+	parts := strings.Split(strings.TrimSpace(chord), "+")
+	base := parts[len(parts)-1]
+	var ctrl, alt, shift bool
+	for _, mod := range parts[:len(parts)-1] {
+		switch strings.ToLower(mod) {
+		case "ctrl":
+			ctrl = true
+		case "alt":
+			alt = true
+		case "shift":
+			shift = true
+		default:
+			return "", fmt.Errorf("chord %q: unknown modifier %q", chord, mod)
+		}
+	}
+
+	if r := []rune(base); len(r) == 1 && unicode.IsPrint(r[0]) && r[0] != ' ' {
+		switch {
+		case ctrl:
+			base = string(unicode.ToLower(r[0]))
+		case shift:
+			base, shift = string(unicode.ToUpper(r[0])), false
+		}
+	} else if name, ok := namedKeys[strings.ToLower(base)]; ok {
+		base = name
+	} else if n, ok := strings.CutPrefix(strings.ToLower(base), "f"); ok && n != "" && strings.Trim(n, "0123456789") == "" {
+		base = "f" + n
+	} else {
+		return "", fmt.Errorf("chord %q: unknown key %q", chord, base)
+	}
+
+	var b strings.Builder
+	if alt {
+		b.WriteString("alt+")
+	}
+	if ctrl {
+		b.WriteString("ctrl+")
+	}
+	if shift {
+		b.WriteString("shift+")
+	}
+	b.WriteString(base)
+	return b.String(), nil
+}
+
+// resolved returns k, or the default bindings for the zero KeyMap.
+func (k KeyMap) resolved() KeyMap {
+	if k.byKey == nil {
+		return defaultKeys
+	}
+	return k
+}
+
+// action returns the action bound to msg, or "" when it has none.
+func (k KeyMap) action(msg tea.KeyMsg) keyAction {
+	return k.resolved().byKey[msg.String()]
+}
+
+// hotkeys lists the effective bindings for /hotkeys, marking the ones
+// changed in settings.
+func (k KeyMap) hotkeys() string {
+	k = k.resolved()
+	var b strings.Builder
+	b.WriteString("Keyboard shortcuts:\n\n")
+	for _, a := range keyActions {
+		chords := k.chords[a.action]
+		keys := "(unbound)"
+		if len(chords) > 0 {
+			shown := make([]string, len(chords))
+			for i, c := range chords {
+				shown[i] = displayChord(c)
+			}
+			keys = strings.Join(shown, ", ")
+		}
+		desc := a.desc
+		if !slices.Equal(chords, a.keys) {
+			desc += " (custom)"
+		}
+		fmt.Fprintf(&b, "  %-20s %-16s %s\n", keys, a.action, desc)
+	}
+	b.WriteString("\nRebind under \"keybindings\" in settings.json, e.g. {\"background\": [\"ctrl+x\"]}.")
+	return b.String()
+}
+
+// displayChord capitalizes a chord for display: "ctrl+b" becomes "Ctrl+B".
+func displayChord(chord string) string {
+	if chord == " " {
+		return "Space"
+	}
+	parts := strings.Split(chord, "+")
+	for i, p := range parts {
+		switch {
+		case p == " ":
+			parts[i] = "Space"
+		case p == "pgup":
+			parts[i] = "PgUp"
+		case p == "pgdown":
+			parts[i] = "PgDown"
+		case p != "":
+			r := []rune(p)
+			parts[i] = string(unicode.ToUpper(r[0])) + string(r[1:])
+		}
+	}
+	return strings.Join(parts, "+")
+}
@@ -0,0 +1,148 @@
+// ABOUTME: Tests for KeyMap: default bindings, settings overrides, chord validation, and /hotkeys output
+// ABOUTME: Also drives AppModel with a rebound action to check the old chord is released
+
+package btea
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestKeyMap_ZeroValueHasDefaults(t *testing.T) {
+	var k KeyMap
+	tests := []struct {
+		msg  tea.KeyMsg
+		want keyAction
+	}{
+		{tea.KeyMsg{Type: tea.KeyEnter}, actionSubmit},
+		{tea.KeyMsg{Type: tea.KeyEnter, Alt: true}, actionQueueFollowUp},
+		{tea.KeyMsg{Type: tea.KeyCtrlB}, actionBackground},
+		{tea.KeyMsg{Type: tea.KeyShiftTab}, actionAutoAccept},
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("m"), Alt: true}, actionModelSelector},
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("m")}, ""},
+		{tea.KeyMsg{Type: tea.KeyEsc}, ""},
+	}
+	for _, tt := range tests {
+		if got := k.action(tt.msg); got != tt.want {
+			t.Errorf("action(%q) = %q; want %q", tt.msg.String(), got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeChord(t *testing.T) {
+	tests := []struct {
+		chord, want string
+	}{
+		{"ctrl+x", "ctrl+x"},
+		{"Ctrl+X", "ctrl+x"},
+		{"ctrl+alt+x", "alt+ctrl+x"},
+		{"Alt+Enter", "alt+enter"},
+		{"shift+tab", "shift+tab"},
+		{"escape", "esc"},
+		{"alt+space", "alt+ "},
+		{"PageUp", "pgup"},
+		{"shift+g", "G"},
+		{"F5", "f5"},
+		{"@", "@"},
+	}
+	for _, tt := range tests {
+		got, err := normalizeChord(tt.chord)
+		if err != nil || got != tt.want {
+			t.Errorf("normalizeChord(%q) = %q, %v; want %q", tt.chord, got, err, tt.want)
+		}
+	}
+	for _, bad := range []string{"", "meta+x", "ctrl+", "ctrl+foo", "fx"} {
+		if got, err := normalizeChord(bad); err == nil {
+			t.Errorf("normalizeChord(%q) = %q; want an error", bad, got)
+		}
+	}
+}
+
+func TestParseKeyMap_Overrides(t *testing.T) {
+	k, err := ParseKeyMap(map[string][]string{
+		"background":     {"Ctrl+X"},
+		"model-selector": {},
+	})
+	if err != nil {
+		t.Fatalf("ParseKeyMap: %v", err)
+	}
+	if got := k.action(tea.KeyMsg{Type: tea.KeyCtrlX}); got != actionBackground {
+		t.Errorf("ctrl+x = %q; want background", got)
+	}
+	if got := k.action(tea.KeyMsg{Type: tea.KeyCtrlB}); got != "" {
+		t.Errorf("ctrl+b should be released by the rebinding; got %q", got)
+	}
+	if got := k.action(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("m"), Alt: true}); got != "" {
+		t.Errorf("an empty list should unbind model-selector; alt+m = %q", got)
+	}
+	if got := k.action(tea.KeyMsg{Type: tea.KeyCtrlR}); got != actionTranscript {
+		t.Errorf("unlisted actions keep their defaults; ctrl+r = %q", got)
+	}
+}
+
+func TestParseKeyMap_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides map[string][]string
+		want      string
+	}{
+		{"unknown action", map[string][]string{"launch": {"ctrl+x"}}, `unknown action "launch"`},
+		{"bad chord", map[string][]string{"submit": {"hyper+enter"}}, `submit: chord "hyper+enter": unknown modifier`},
+		{"conflict with a default", map[string][]string{"background": {"ctrl+t"}}, "Ctrl+T is bound to both background and cost"},
+		{"conflict between overrides", map[string][]string{"clear": {"f2"}, "cost": {"F2"}}, "F2 is bound to both clear and cost"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseKeyMap(tt.overrides)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseKeyMap error = %v; want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyMap_Hotkeys(t *testing.T) {
+	k, err := ParseKeyMap(map[string][]string{"background": {"ctrl+x"}, "cost": {}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := k.hotkeys()
+	for _, want := range []string{
+		"Enter, Shift+Enter",
+		"Ctrl+X               background",
+		"(unbound)            cost",
+		"list background tasks (custom)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("hotkeys missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Ctrl+B") {
+		t.Errorf("hotkeys should show the effective map, not the defaults:\n%s", out)
+	}
+}
+
+func TestAppModel_ReboundKeys(t *testing.T) {
+	deps := testDeps()
+	keys, err := ParseKeyMap(map[string][]string{"model-selector": {"ctrl+x"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	deps.Keys = keys
+
+	m := press(NewAppModel(deps), tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("m"), Alt: true})
+	if m.overlay != nil {
+		t.Errorf("the default chord should no longer open the model selector; overlay %T", m.overlay)
+	}
+	m = press(m, tea.KeyMsg{Type: tea.KeyCtrlX})
+	if _, ok := m.overlay.(ModelSelectorModel); !ok {
+		t.Errorf("the new chord should open the model selector; overlay %T", m.overlay)
+	}
+
+	ctx, _ := m.buildCommandContext()
+	if out := ctx.KeybindingsFn(); !strings.Contains(out, "Ctrl+X") {
+		t.Errorf("/hotkeys should show the rebound chord:\n%s", out)
+	}
+}
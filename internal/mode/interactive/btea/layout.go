@@ -0,0 +1,67 @@
+// ABOUTME: Responsive layout limits: a placeholder below the minimum size, full-screen overlays when narrow
+// ABOUTME: Keeps rendering legible instead of letting boxes and columns wrap into garbage on tiny terminals
+
+package btea
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/width"
+)
+
+// Minimum terminal size the layout renders at; below it a placeholder is shown.
+const (
+	minTermWidth  = 40
+	minTermHeight = 10
+)
+
+// compactWidth is the width below which centered overlays take the whole
+// screen instead of floating over the conversation.
+const compactWidth = 60
+
+// tooSmall reports whether the known terminal size is below the minimum.
+// Before the first resize the size is unknown and not too small.
+func (m AppModel) tooSmall() bool {
+	return m.width > 0 && m.height > 0 && (m.width < minTermWidth || m.height < minTermHeight)
+}
+
+// compact reports whether the terminal is narrow enough for full-screen overlays.
+func (m AppModel) compact() bool {
+	return m.width > 0 && m.width < compactWidth
+}
+
+// tooSmallView fills a w×h screen with a centered notice naming the
+// minimum and current sizes, wrapped to fit however narrow it is.
+func tooSmallView(w, h int) string {
+	s := Styles()
+	msg := fmt.Sprintf("terminal too small (min %dx%d, now %dx%d)", minTermWidth, minTermHeight, w, h)
+	lines := width.WrapTextWithAnsi(msg, max(w, 1))
+	top := max((h-len(lines))/2, 0)
+
+	out := make([]string, 0, max(h, len(lines)))
+	for range top {
+		out = append(out, "")
+	}
+	for _, l := range lines {
+		pad := max((w-width.VisibleWidth(l))/2, 0)
+		out = append(out, strings.Repeat(" ", pad)+s.Warning.Render(l))
+	}
+	if h > 0 && len(out) > h {
+		out = out[:h]
+	}
+	return strings.Join(out, "\n")
+}
+
+// fullScreenOverlay shows an overlay alone, its lines cut to w columns and
+// h rows, as small terminals cannot fit it over the conversation.
+func fullScreenOverlay(view string, w, h int) string {
+	lines := strings.Split(view, "\n")
+	if h > 0 && len(lines) > h {
+		lines = lines[:h]
+	}
+	for i, l := range lines {
+		lines[i] = truncateVisual(l, w)
+	}
+	return strings.Join(lines, "\n")
+}
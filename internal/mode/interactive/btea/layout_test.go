@@ -0,0 +1,57 @@
+// ABOUTME: Tests for responsive layout limits: the too-small placeholder and full-screen overlays
+// ABOUTME: Drives AppModel through WindowSizeMsg and checks every rendered line fits the screen
+
+package btea
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/width"
+)
+
+func resized(m AppModel, w, h int) AppModel {
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: w, Height: h})
+	return updated.(AppModel)
+}
+
+func TestView_TooSmallPlaceholder(t *testing.T) {
+	m := resized(NewAppModel(testDeps()), 30, 8)
+	view := m.View()
+	if !strings.Contains(strings.ReplaceAll(view, "\n", " "), "min 40x10") {
+		t.Errorf("view should show the placeholder:\n%s", view)
+	}
+	lines := strings.Split(view, "\n")
+	if len(lines) > 8 {
+		t.Errorf("placeholder is %d rows; want at most 8", len(lines))
+	}
+	for _, l := range lines {
+		if width.VisibleWidth(l) > 30 {
+			t.Errorf("line %q wider than 30 columns", l)
+		}
+	}
+
+	m = resized(m, 80, 24)
+	if strings.Contains(m.View(), "terminal too small") {
+		t.Error("a normal size should render the layout")
+	}
+}
+
+func TestView_CompactOverlayFullScreen(t *testing.T) {
+	m := resized(NewAppModel(testDeps()), 50, 20)
+	m.overlay = NewCostViewModel(1000, 200, 3, 0.5, 10, 5)
+	view := m.View()
+	if !strings.Contains(view, "Token & Cost") {
+		t.Fatalf("overlay missing:\n%s", view)
+	}
+	if first := strings.Split(view, "\n")[0]; !strings.Contains(first, "Token & Cost") {
+		t.Errorf("narrow overlay should start at the top of the screen, got first line %q", first)
+	}
+	for _, l := range strings.Split(view, "\n") {
+		if width.VisibleWidth(l) > 50 {
+			t.Errorf("line %q wider than 50 columns", l)
+		}
+	}
+}
@@ -1,5 +1,5 @@
 // ABOUTME: Markdown renderer wrapper around glamour for terminal output
-// ABOUTME: Caches rendered results keyed by content hash + width
+// ABOUTME: Caches rendered results keyed by content hash + width; code is highlighted and links can be clickable
 
 package btea
 
@@ -36,21 +36,25 @@ func (r *MarkdownRenderer) Render(md string, width int) string {
 	}
 
 	renderer, err := glamour.NewTermRenderer(
-		glamour.WithAutoStyle(),
+		glamour.WithStyles(markdownStyle()),
+		glamour.WithChromaFormatter(chromaFormatter(colorDepth)),
 		glamour.WithWordWrap(width),
+		glamour.WithColorProfile(colorProfile(colorDepth)),
+		glamour.WithInlineTableLinks(hyperlinks),
 	)
 	if err != nil {
 		// Fallback: return raw text
 		return md
 	}
 
-	rendered, err := renderer.Render(md)
+	prepared, urls := prepareMarkdown(md, width, hyperlinks)
+	rendered, err := renderer.Render(prepared)
 	if err != nil {
 		return md
 	}
 
 	// Trim trailing whitespace that glamour adds
-	rendered = strings.TrimRight(rendered, "\n ")
+	rendered = hyperlinkMarks(strings.TrimRight(rendered, "\n "), urls)
 
 	r.cache[key] = rendered
 	return rendered
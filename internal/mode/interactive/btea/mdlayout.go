@@ -0,0 +1,279 @@
+// ABOUTME: Markdown preparation around glamour: wide tables become nested lists, streams split at stable blocks
+// ABOUTME: Inline links are marked before rendering and turned into OSC 8 hyperlinks afterwards when supported
+
+package btea
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// mdLink matches an inline link "[text](url)" or "[text](url "title")".
+var mdLink = regexp.MustCompile(`\[([^\[\]\n]+)\]\(([^()\s]+)(?:\s+"[^"\n]*")?\)`)
+
+// linkMark matches the placeholders markLinks leaves in rendered output:
+// "ESC [ n y" opens link n and "ESC [ y" closes it. They are CSI sequences
+// with an unused final byte, so glamour's wrapping treats them as zero width.
+var linkMark = regexp.MustCompile(`\x1b\[(\d*)y`)
+
+// linkSchemes are the URL schemes worth making clickable; relative paths
+// and anchors have nothing for the terminal to open.
+var linkSchemes = []string{"http://", "https://", "mailto:", "file://"}
+
+// prepareMarkdown rewrites md for a pane width cols wide: tables that do not
+// fit become lists, and when links is set inline links are marked for
+// hyperlinkMarks. It returns the rewritten Markdown and the marked URLs.
+func prepareMarkdown(md string, cols int, links bool) (string, []string) {
+	lines := strings.Split(md, "\n")
+	var out []string
+	var urls []string
+	fence := ""
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if f := fenceMarker(line); f != "" {
+			switch {
+			case fence == "":
+				fence = f
+			case strings.HasPrefix(f, fence):
+				fence = ""
+			}
+			out = append(out, line)
+			continue
+		}
+		if fence != "" {
+			out = append(out, line)
+			continue
+		}
+		if rows, n := tableAt(lines, i); n > 0 {
+			if tableWidth(rows) > cols {
+				out = append(out, tableAsList(rows)...)
+				i += n - 1
+				continue
+			}
+		}
+		if links {
+			line = markLinks(line, &urls)
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n"), urls
+}
+
+// listItem matches the marker that starts a list item.
+var listItem = regexp.MustCompile(`^([-*+]|\d+[.)])(\s|$)`)
+
+// stableSplit returns the offset of the last block boundary in md that text
+// appended later cannot change: a blank line outside code fences followed
+// by an unindented line that does not continue a list. It returns 0 when
+// there is none. The two sides render the same apart as together.
+func stableSplit(md string) int {
+	split, offset := 0, 0
+	fence := ""
+	prevBlank := false
+	for _, line := range strings.SplitAfter(md, "\n") {
+		line = strings.TrimSuffix(line, "\n")
+		if fence == "" && prevBlank && line != "" &&
+			line[0] != ' ' && line[0] != '\t' && !listItem.MatchString(line) {
+			split = offset
+		}
+		if f := fenceMarker(line); f != "" {
+			switch {
+			case fence == "":
+				fence = f
+			case strings.HasPrefix(f, fence):
+				fence = ""
+			}
+		}
+		prevBlank = fence == "" && strings.TrimSpace(line) == ""
+		offset += len(line) + 1
+	}
+	return split
+}
+
+// fenceMarker returns the backtick or tilde run opening or closing a code
+// fence on line, or "".
+func fenceMarker(line string) string {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 {
+		return ""
+	}
+	for _, c := range []string{"`", "~"} {
+		n := len(trimmed) - len(strings.TrimLeft(trimmed, c))
+		if n >= 3 {
+			return strings.Repeat(c, n)
+		}
+	}
+	return ""
+}
+
+// tableAt parses a GFM table starting at lines[i]: a header row, a
+// delimiter row, and the body rows that follow. It returns the cells of the
+// header and body rows and the number of lines consumed, or 0 when there is
+// no table.
+func tableAt(lines []string, i int) ([][]string, int) {
+	if i+1 >= len(lines) || !strings.Contains(lines[i], "|") {
+		return nil, 0
+	}
+	header := tableCells(lines[i])
+	delim := tableCells(lines[i+1])
+	if len(header) == 0 || len(delim) != len(header) {
+		return nil, 0
+	}
+	for _, d := range delim {
+		if strings.Trim(d, ":-") != "" || !strings.Contains(d, "-") {
+			return nil, 0
+		}
+	}
+	rows := [][]string{header}
+	n := 2
+	for ; i+n < len(lines); n++ {
+		line := lines[i+n]
+		if strings.TrimSpace(line) == "" || !strings.Contains(line, "|") {
+			break
+		}
+		rows = append(rows, tableCells(line))
+	}
+	return rows, n
+}
+
+// tableCells splits a table row on unescaped pipes, dropping the optional
+// leading and trailing pipe.
+func tableCells(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+	var cells []string
+	var cell strings.Builder
+	for j := 0; j < len(line); j++ {
+		switch {
+		case line[j] == '\\' && j+1 < len(line) && line[j+1] == '|':
+			cell.WriteString(`\|`)
+			j++
+		case line[j] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[j])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// tableWidth estimates the columns glamour needs to lay out rows without
+// wrapping: each cell padded by a space on both sides, a separator between
+// cells, and the document margin.
+func tableWidth(rows [][]string) int {
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for c := range min(len(row), len(widths)) {
+			widths[c] = max(widths[c], runewidth.StringWidth(row[c]))
+		}
+	}
+	total := 4 + len(widths) - 1
+	for _, w := range widths {
+		total += w + 2
+	}
+	return total
+}
+
+// tableAsList renders each body row as a list item titled by its first
+// cell, with the other cells nested under it as "header: value".
+func tableAsList(rows [][]string) []string {
+	header := rows[0]
+	var out []string
+	for _, row := range rows[1:] {
+		title := ""
+		if len(row) > 0 {
+			title = row[0]
+		}
+		out = append(out, fmt.Sprintf("- **%s**", title))
+		for c := 1; c < len(header); c++ {
+			value := ""
+			if c < len(row) {
+				value = row[c]
+			}
+			out = append(out, fmt.Sprintf("  - %s: %s", header[c], value))
+		}
+	}
+	return append(out, "")
+}
+
+// markLinks replaces the inline links on line, outside code spans and
+// images, with their text between link placeholders, appending each URL to
+// urls. The target becomes an anchor so glamour does not print it after
+// the text.
+func markLinks(line string, urls *[]string) string {
+	parts := strings.Split(line, "`")
+	for p := 0; p < len(parts); p += 2 { // odd parts are inside code spans
+		text := parts[p]
+		var b strings.Builder
+		last := 0
+		for _, m := range mdLink.FindAllStringSubmatchIndex(text, -1) {
+			url := text[m[4]:m[5]]
+			if (m[0] > 0 && text[m[0]-1] == '!') || !clickable(url) {
+				continue
+			}
+			*urls = append(*urls, url)
+			b.WriteString(text[last:m[0]])
+			fmt.Fprintf(&b, "[\x1b\\[%dy%s\x1b\\[y](#)", len(*urls), text[m[2]:m[3]])
+			last = m[1]
+		}
+		b.WriteString(text[last:])
+		parts[p] = b.String()
+	}
+	return strings.Join(parts, "`")
+}
+
+// clickable reports whether url has a scheme the terminal can open.
+func clickable(url string) bool {
+	for _, s := range linkSchemes {
+		if strings.HasPrefix(url, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// hyperlinkMarks turns the link placeholders in rendered output into OSC 8
+// hyperlinks to urls. A link wrapped across lines is closed at the end of
+// each line and reopened on the next, so the border and padding around the
+// text never become part of it.
+func hyperlinkMarks(rendered string, urls []string) string {
+	if len(urls) == 0 {
+		return rendered
+	}
+	lines := strings.Split(rendered, "\n")
+	open := ""
+	for i, line := range lines {
+		prefix := ""
+		if open != "" {
+			prefix = osc8(open)
+		}
+		line = linkMark.ReplaceAllStringFunc(line, func(mark string) string {
+			n, err := strconv.Atoi(linkMark.FindStringSubmatch(mark)[1])
+			if err != nil || n < 1 || n > len(urls) {
+				open = ""
+				return osc8("")
+			}
+			open = urls[n-1]
+			return osc8(open)
+		})
+		if open != "" {
+			line += osc8("")
+		}
+		lines[i] = prefix + line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// osc8 returns the sequence that starts a hyperlink to url, or ends the
+// current one when url is empty.
+func osc8(url string) string {
+	return "\x1b]8;;" + url + "\x1b\\"
+}
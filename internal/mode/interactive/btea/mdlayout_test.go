@@ -0,0 +1,110 @@
+// ABOUTME: Tests for Markdown preparation: wide tables as lists and OSC 8 hyperlinks
+// ABOUTME: Covers code fences and spans, images, relative links, and links wrapped across lines
+
+package btea
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+const wideTable = "| Name | Value | Description |\n|---|---:|---|\n| alpha | 1 | the first entry in the table |\n| beta \\| gamma | 22 | second |"
+
+func TestPrepareMarkdown_WideTableBecomesList(t *testing.T) {
+	got, _ := prepareMarkdown("Results:\n"+wideTable+"\n\nDone.", 30, false)
+	want := "Results:\n" +
+		"- **alpha**\n  - Value: 1\n  - Description: the first entry in the table\n" +
+		"- **beta \\| gamma**\n  - Value: 22\n  - Description: second\n" +
+		"\n\nDone."
+	if got != want {
+		t.Errorf("prepareMarkdown =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestPrepareMarkdown_FittingTableKept(t *testing.T) {
+	if got, _ := prepareMarkdown(wideTable, 80, false); got != wideTable {
+		t.Errorf("a table that fits should be left to glamour; got %q", got)
+	}
+}
+
+func TestPrepareMarkdown_SkipsFencedCode(t *testing.T) {
+	md := "```md\n" + wideTable + "\n[x](https://a.dev)\n```"
+	got, urls := prepareMarkdown(md, 20, true)
+	if got != md || len(urls) != 0 {
+		t.Errorf("fenced code should be untouched; got %q, urls %q", got, urls)
+	}
+}
+
+func TestMarkLinks(t *testing.T) {
+	var urls []string
+	line := "See [docs](https://a.dev \"Docs\"), `[code](https://b.dev)`, ![img](https://c.dev/i.png), [local](README.md) and [mail](mailto:x@y.z)"
+	got := markLinks(line, &urls)
+
+	if want := []string{"https://a.dev", "mailto:x@y.z"}; !slices.Equal(urls, want) {
+		t.Errorf("urls = %q; want %q", urls, want)
+	}
+	for _, keep := range []string{"`[code](https://b.dev)`", "![img](https://c.dev/i.png)", "[local](README.md)"} {
+		if !strings.Contains(got, keep) {
+			t.Errorf("markLinks should keep %q; got %q", keep, got)
+		}
+	}
+	if !strings.Contains(got, "[\x1b\\[1ydocs\x1b\\[y](#)") {
+		t.Errorf("docs link not marked; got %q", got)
+	}
+}
+
+func TestHyperlinkMarks_ReopensAcrossLines(t *testing.T) {
+	rendered := "  see \x1b[1ythe long\n  docs\x1b[y here"
+	got := hyperlinkMarks(rendered, []string{"https://a.dev"})
+	want := "  see " + osc8("https://a.dev") + "the long" + osc8("") + "\n" +
+		osc8("https://a.dev") + "  docs" + osc8("") + " here"
+	if got != want {
+		t.Errorf("hyperlinkMarks = %q; want %q", got, want)
+	}
+}
+
+func TestMarkdownRenderer_Hyperlinks(t *testing.T) {
+	saved := hyperlinks
+	t.Cleanup(func() { hyperlinks = saved })
+	md := "Read [the guide](https://go.dev/doc) first."
+
+	hyperlinks = true
+	out := NewMarkdownRenderer().Render(md, 80)
+	if !strings.Contains(out, osc8("https://go.dev/doc")+"the guide"+osc8("")) {
+		t.Errorf("link text should be an OSC 8 hyperlink; got %q", out)
+	}
+	if strings.Count(out, "https://go.dev/doc") != 1 || strings.Contains(out, "\x1b[1y") {
+		t.Errorf("the URL should only appear inside the hyperlink; got %q", out)
+	}
+
+	hyperlinks = false
+	out = NewMarkdownRenderer().Render(md, 80)
+	if strings.Contains(out, "\x1b]8;;") || !strings.Contains(out, "https://go.dev/doc") {
+		t.Errorf("without OSC 8 the URL should be printed; got %q", out)
+	}
+}
+
+func TestStableSplit(t *testing.T) {
+	tests := []struct {
+		name string
+		md   string
+		want string // the text after the split
+	}{
+		{"single paragraph", "still writing", "still writing"},
+		{"after a paragraph", "One.\n\nTwo is stre", "Two is stre"},
+		{"last boundary wins", "One.\n\nTwo.\n\n# Three", "# Three"},
+		{"not inside a fence", "One.\n\n```\na\n\nb", "```\na\n\nb"},
+		{"after a closed fence", "```\na\n\nb\n```\n\nDone", "Done"},
+		{"not before a list item", "Intro\n\n- a\n\n- b", "Intro\n\n- a\n\n- b"},
+		{"not before indented text", "- a\n\n  more", "- a\n\n  more"},
+		{"trailing blank", "One.\n\n", "One.\n\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.md[stableSplit(tt.md):]; got != tt.want {
+				t.Errorf("tail = %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+// ABOUTME: MemoryPickerModel overlay choosing a memory file for /memory ($EDITOR) or a '#' fact (append)
+// ABOUTME: AppModel handlers append facts, open files with the TUI suspended, and reload memory afterwards
+
+package btea
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mauromedda/pi-coding-agent-go/internal/ide"
+	"github.com/mauromedda/pi-coding-agent-go/internal/memory"
+	"github.com/mauromedda/pi-coding-agent-go/internal/tools"
+)
+
+// memoryChoice is one selectable memory file.
+type memoryChoice struct {
+	label string
+	path  string
+}
+
+// MemoryPickerModel lists memory files. With a fact, selecting a file
+// appends the fact to it; otherwise the file is opened in $EDITOR.
+type MemoryPickerModel struct {
+	choices []memoryChoice
+	cursor  int
+	fact    string
+	width   int
+}
+
+// NewMemoryPickerModel creates a picker over choices for fact ("" to edit).
+func NewMemoryPickerModel(choices []memoryChoice, fact string, w int) MemoryPickerModel {
+	return MemoryPickerModel{choices: choices, fact: fact, width: w}
+}
+
+// Init returns nil; no startup commands needed.
+func (m MemoryPickerModel) Init() tea.Cmd { return nil }
+
+// Update handles key events for the picker.
+func (m MemoryPickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "j", "down":
+			if m.cursor < len(m.choices)-1 {
+				m.cursor++
+			}
+		case "k", "up":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "enter":
+			if len(m.choices) == 0 {
+				return m, nil
+			}
+			sel := MemoryFileSelectedMsg{Path: m.choices[m.cursor].path, Fact: m.fact}
+			return m, func() tea.Msg { return sel }
+		case "esc", "q":
+			return m, func() tea.Msg { return DismissOverlayMsg{} }
+		}
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+	}
+	return m, nil
+}
+
+// View renders the picker as a bordered box.
+func (m MemoryPickerModel) View() string {
+	s := Styles()
+	bs := s.OverlayBorder
+
+	const (
+		dash    = "─"
+		vBorder = "│"
+		tl      = "╭"
+		tr      = "╮"
+		bl      = "╰"
+		br      = "╯"
+	)
+
+	boxWidth := max(m.width*3/5, 50)
+	if boxWidth > m.width-4 {
+		boxWidth = max(m.width-4, 50)
+	}
+	innerWidth := max(boxWidth-2, 0)
+	contentWidth := max(boxWidth-4, 20)
+	border := bs.Render(vBorder)
+
+	var b strings.Builder
+
+	titleText := " Edit Memory "
+	if m.fact != "" {
+		titleText = " Remember In "
+	}
+	title := s.OverlayTitle.Render(titleText)
+	dashesLeft := max((innerWidth-len(titleText))/2, 0)
+	dashesRight := max(innerWidth-len(titleText)-dashesLeft, 0)
+	b.WriteString(bs.Render(tl))
+	b.WriteString(bs.Render(strings.Repeat(dash, dashesLeft)))
+	b.WriteString(title)
+	b.WriteString(bs.Render(strings.Repeat(dash, dashesRight)))
+	b.WriteString(bs.Render(tr))
+	b.WriteByte('\n')
+
+	if m.fact != "" {
+		writeBoxLine(&b, border, s.Dim.Render("- "+m.fact), contentWidth)
+	}
+	for i, c := range m.choices {
+		if i == m.cursor {
+			writeBoxLine(&b, border, s.Selection.Render("> "+c.label), contentWidth)
+		} else {
+			writeBoxLine(&b, border, s.Dim.Render("  "+c.label), contentWidth)
+		}
+	}
+
+	hint := "j/k:nav  enter:open in $EDITOR  esc:close"
+	if m.fact != "" {
+		hint = "j/k:nav  enter:append  esc:cancel"
+	}
+	writeBoxLine(&b, border, s.Muted.Render(hint), contentWidth)
+
+	b.WriteString(bs.Render(bl))
+	b.WriteString(bs.Render(strings.Repeat(dash, innerWidth)))
+	b.WriteString(bs.Render(br))
+
+	return b.String()
+}
+
+// memoryFact returns the fact of a "# fact" prompt. A '#' without a
+// following space ("#123") is an ordinary prompt.
+func memoryFact(text string) (string, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(text), "# ")
+	rest = strings.TrimSpace(rest)
+	if !ok || rest == "" {
+		return "", false
+	}
+	return rest, true
+}
+
+// memoryChoices lists the project and user memory files, plus every other
+// loaded memory file when editing (rules and auto-memory are not append targets).
+func (m AppModel) memoryChoices(editing bool) []memoryChoice {
+	mem := m.deps.Memory
+	var choices []memoryChoice
+	seen := make(map[string]bool)
+	for _, t := range memory.Targets(mem.ProjectDir, mem.HomeDir) {
+		label := fmt.Sprintf("%-8s %s", t.Scope, shortenHome(t.Path, mem.HomeDir))
+		if !t.Exists {
+			label += " (new)"
+		}
+		choices = append(choices, memoryChoice{label: label, path: t.Path})
+		seen[t.Path] = true
+	}
+	if !editing {
+		return choices
+	}
+	entries, _ := memory.Load(mem.ProjectDir, mem.HomeDir, tools.IsSecretFile)
+	for _, e := range entries {
+		if seen[e.Source] {
+			continue
+		}
+		seen[e.Source] = true
+		choices = append(choices, memoryChoice{
+			label: fmt.Sprintf("%-8s %s", "rules", shortenHome(e.Source, mem.HomeDir)),
+			path:  e.Source,
+		})
+	}
+	return choices
+}
+
+// openMemoryPicker shows the memory picker for fact ("" to edit a file).
+func (m AppModel) openMemoryPicker(fact string) AppModel {
+	m.overlay = NewMemoryPickerModel(m.memoryChoices(fact == ""), fact, m.width)
+	return m
+}
+
+// handleMemorySelected appends a '#' fact to the chosen file, or opens the
+// file in $EDITOR with the TUI suspended and reloads memory on return.
+func (m AppModel) handleMemorySelected(msg MemoryFileSelectedMsg) (tea.Model, tea.Cmd) {
+	m.overlay = nil
+	m.editor = m.editor.SetFocused(true)
+	if msg.Fact == "" {
+		path := msg.Path
+		return m, m.execProcess(ide.EditFileCommand(path), func(err error) tea.Msg {
+			return MemoryEditedMsg{Path: path, Err: err}
+		})
+	}
+	if err := memory.AppendFact(msg.Path, msg.Fact); err != nil {
+		return m.applyEffects(&cmdSideEffects{}, fmt.Sprintf("Error: %v", err))
+	}
+	return m.reloadMemory(fmt.Sprintf("Remembered in %s.", shortenHome(msg.Path, m.deps.Memory.HomeDir)))
+}
+
+// reloadMemory rebuilds the system prompt from the memory files on disk and
+// reports done, or the reload error.
+func (m AppModel) reloadMemory(done string) (tea.Model, tea.Cmd) {
+	if reload := m.deps.Memory.Reload; reload != nil {
+		sys, mem, err := reload()
+		if err != nil {
+			return m.applyEffects(&cmdSideEffects{}, fmt.Sprintf("%s Reloading memory failed: %v", done, err))
+		}
+		m.deps.SystemPrompt = sys
+		m.deps.MemoryPrompt = mem
+	}
+	return m.applyEffects(&cmdSideEffects{}, done)
+}
+
+// shortenHome replaces a leading home directory with "~".
+func shortenHome(path, home string) string {
+	if home == "" {
+		return path
+	}
+	if rel, err := filepath.Rel(home, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.Join("~", rel)
+	}
+	return path
+}
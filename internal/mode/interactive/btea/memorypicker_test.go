@@ -0,0 +1,145 @@
+// ABOUTME: Tests for the memory picker overlay, the '#' fact shortcut, and memory reload after edits
+// ABOUTME: Uses temp project and home dirs with a stub Reload to observe the rebuilt system prompt
+
+package btea
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Compile-time check: MemoryPickerModel must satisfy tea.Model.
+var _ tea.Model = MemoryPickerModel{}
+
+// memoryTestModel returns an AppModel with memory access over temp dirs.
+// The returned counter tracks calls to the stub Reload.
+func memoryTestModel(t *testing.T) (AppModel, *int) {
+	t.Helper()
+	reloads := 0
+	deps := testDeps()
+	deps.Memory = &MemoryAccess{
+		ProjectDir: t.TempDir(),
+		HomeDir:    t.TempDir(),
+		Reload: func() (string, string, error) {
+			reloads++
+			return "system with memory", "memory", nil
+		},
+	}
+	m := NewAppModel(deps)
+	m.width = 80
+	return m, &reloads
+}
+
+func TestMemoryFact(t *testing.T) {
+	cases := []struct {
+		text string
+		fact string
+		ok   bool
+	}{
+		{"# use pnpm, not npm", "use pnpm, not npm", true},
+		{"  #   tabs in Makefiles ", "tabs in Makefiles", true},
+		{"#123 is the bug", "", false},
+		{"# ", "", false},
+		{"fix # comment", "", false},
+	}
+	for _, tc := range cases {
+		fact, ok := memoryFact(tc.text)
+		if fact != tc.fact || ok != tc.ok {
+			t.Errorf("memoryFact(%q) = %q, %v; want %q, %v", tc.text, fact, ok, tc.fact, tc.ok)
+		}
+	}
+}
+
+func TestMemoryPicker_SelectEmitsPath(t *testing.T) {
+	choices := []memoryChoice{{label: "project CLAUDE.md", path: "/p/CLAUDE.md"}, {label: "user", path: "/h/CLAUDE.md"}}
+	m := NewMemoryPickerModel(choices, "be terse", 80)
+	if !strings.Contains(m.View(), "- be terse") || !strings.Contains(m.View(), "Remember In") {
+		t.Errorf("fact picker should show the fact:\n%s", m.View())
+	}
+
+	result, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	_, cmd := result.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	sel, ok := cmd().(MemoryFileSelectedMsg)
+	if !ok || sel.Path != "/h/CLAUDE.md" || sel.Fact != "be terse" {
+		t.Errorf("msg = %+v; want the user file with the fact", sel)
+	}
+
+	_, cmd = result.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if _, ok := cmd().(DismissOverlayMsg); !ok {
+		t.Error("esc should dismiss the picker")
+	}
+}
+
+func TestAppModel_HashPromptAppendsFact(t *testing.T) {
+	m, reloads := memoryTestModel(t)
+	m.editor = m.editor.SetText("# run make lint before committing")
+
+	result, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = result.(AppModel)
+	if _, ok := m.overlay.(MemoryPickerModel); !ok {
+		t.Fatalf("'#' prompt should open the memory picker, got %T", m.overlay)
+	}
+	if len(m.messages) != 0 {
+		t.Error("'#' prompt must not be sent to the model")
+	}
+
+	// Second choice is user scope.
+	result, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	m = result.(AppModel)
+	result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = result.(AppModel)
+	result, _ = m.Update(cmd())
+	m = result.(AppModel)
+
+	if m.overlay != nil {
+		t.Error("picker should close after selection")
+	}
+	data, err := os.ReadFile(filepath.Join(m.deps.Memory.HomeDir, ".claude", "CLAUDE.md"))
+	if err != nil || string(data) != "- run make lint before committing\n" {
+		t.Errorf("user memory = %q, %v", data, err)
+	}
+	if *reloads != 1 || m.deps.SystemPrompt != "system with memory" || m.deps.MemoryPrompt != "memory" {
+		t.Errorf("reloads = %d, prompt = %q; want memory reloaded", *reloads, m.deps.SystemPrompt)
+	}
+}
+
+func TestAppModel_HashPromptWithoutMemoryIsPrompt(t *testing.T) {
+	m := NewAppModel(testDeps())
+	m, _ = m.submitPrompt("# heading")
+	if m.overlay != nil || len(m.messages) != 1 {
+		t.Errorf("without memory access '#' is an ordinary prompt; overlay = %T, messages = %d", m.overlay, len(m.messages))
+	}
+}
+
+func TestAppModel_MemoryCommandOpensPicker(t *testing.T) {
+	m, reloads := memoryTestModel(t)
+	rules := filepath.Join(m.deps.Memory.ProjectDir, ".claude", "rules")
+	if err := os.MkdirAll(rules, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(rules, "go.md"), []byte("gofmt"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, _ = m.handleSlashCommand("/memory")
+	picker, ok := m.overlay.(MemoryPickerModel)
+	if !ok {
+		t.Fatalf("/memory should open the picker, got %T", m.overlay)
+	}
+	if len(picker.choices) != 3 || !strings.HasSuffix(picker.choices[2].path, "go.md") {
+		t.Errorf("choices = %+v; want project, user, and the rules file", picker.choices)
+	}
+	if !strings.Contains(picker.View(), "(new)") {
+		t.Errorf("missing files should be marked new:\n%s", picker.View())
+	}
+
+	result, _ := m.Update(MemoryEditedMsg{Path: picker.choices[2].path})
+	m = result.(AppModel)
+	if *reloads != 1 || m.deps.SystemPrompt != "system with memory" {
+		t.Errorf("editor exit should reload memory; reloads = %d", *reloads)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
+	"github.com/mauromedda/pi-coding-agent-go/internal/export"
 	"github.com/mauromedda/pi-coding-agent-go/internal/perf"
 	"github.com/mauromedda/pi-coding-agent-go/internal/types"
 	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
@@ -31,6 +32,7 @@ type AgentToolStartMsg struct {
 type AgentToolUpdateMsg struct {
 	ToolID string
 	Text   string
+	Step   *agent.ToolStep // composite tool step; nil for plain output
 }
 
 // AgentToolEndMsg signals that a tool execution has completed.
@@ -44,6 +46,9 @@ type AgentToolEndMsg struct {
 // AgentUsageMsg carries token usage statistics.
 type AgentUsageMsg struct{ Usage *ai.Usage }
 
+// AgentRouteMsg reports which model serves the next LLM call (minion routing).
+type AgentRouteMsg struct{ Route agent.ModelRoute }
+
 // AgentDoneMsg signals the agent loop has finished.
 type AgentDoneMsg struct{ Messages []ai.Message }
 
@@ -92,13 +97,33 @@ type CompactDoneMsg struct {
 	Messages    []ai.Message // compacted message list
 	Summary     string       // generated summary text
 	TokensSaved int          // tokens freed
+	PinnedKept  int          // pinned messages kept verbatim
+}
+
+// SessionTitleMsg carries a generated title for session SessionID.
+type SessionTitleMsg struct {
+	SessionID string
+	Title     string
+	Err       error
+}
+
+// SuggestionMsg carries a predicted next prompt for the conversation as it
+// stood with After messages.
+type SuggestionMsg struct {
+	After int
+	Text  string
+	Err   error
 }
 
+// ToggleThinkingMsg expands or collapses the thinking of an assistant message.
+type ToggleThinkingMsg struct{}
+
 // ToggleImagesMsg signals all tool call models to show/hide images.
 type ToggleImagesMsg struct{ Show bool }
 
-// SpinnerTickMsg drives the spinner animation.
-type SpinnerTickMsg struct{}
+// SpinnerTickMsg advances the spinners and elapsed times of running tool
+// calls.
+type SpinnerTickMsg struct{ Time time.Time }
 
 // ProbeResultMsg carries the TTFB probe result from the background probe.
 type ProbeResultMsg struct {
@@ -114,7 +139,8 @@ type ModeTransitionMsg struct {
 	Reason string // Why the transition occurred
 }
 
-// SettingsChangedMsg signals that configuration was reloaded.
+// SettingsChangedMsg signals that a settings file changed on disk; the app
+// reloads and re-applies the settings.
 type SettingsChangedMsg struct {
 	Section string // Which section changed (e.g., "personality", "intent", "prompts")
 }
@@ -127,14 +153,54 @@ type PlanGeneratedMsg struct {
 // --- Queue overlay messages ---
 
 // QueueUpdatedMsg carries the updated queue items after the overlay closes.
+// The first Next items are flagged to run next.
 type QueueUpdatedMsg struct {
 	Items []string
+	Next  int
 }
 
 // QueueEditMsg signals that a queue item should be popped into the editor for editing.
+// Items and Next carry the queue as the overlay left it, Index included.
 type QueueEditMsg struct {
 	Text  string
 	Index int
+	Items []string
+	Next  int
+}
+
+// --- Pin overlay messages ---
+
+// MessagePinMsg sets the pinned flag of the conversation message at Index.
+type MessagePinMsg struct {
+	Index  int
+	Pinned bool
+}
+
+// PinViewDismissMsg signals that the pin overlay was closed.
+type PinViewDismissMsg struct{}
+
+// MemoryFileSelectedMsg is emitted when a memory file is chosen in the
+// memory picker. A non-empty Fact is appended; otherwise Path is edited.
+type MemoryFileSelectedMsg struct {
+	Path string
+	Fact string
+}
+
+// MemoryEditedMsg is sent when $EDITOR exits after editing a memory file.
+type MemoryEditedMsg struct {
+	Path string
+	Err  error
+}
+
+// ShareConfirmedMsg is emitted when the user confirms a /share upload.
+type ShareConfirmedMsg struct {
+	Plan *export.SharePlan
+}
+
+// ShareDoneMsg carries the link of a finished /share upload, or its error.
+type ShareDoneMsg struct {
+	Link string
+	Err  error
 }
 
 // --- Background task lifecycle ---
@@ -162,6 +228,9 @@ type BackgroundTaskCancelMsg struct {
 	TaskID string
 }
 
+// MinionFanOutMsg reports progress of a plural-minion fan-out, tracked as a background task.
+type MinionFanOutMsg struct{ Progress agent.FanOutProgress }
+
 // --- Async I/O results ---
 
 // BashDoneMsg carries the result of an asynchronous bash command execution.
@@ -182,6 +251,54 @@ type SessionLoadedMsg struct {
 	Messages  []ai.Message
 }
 
+// RecoverSessionMsg answers the startup recovery question: restore the
+// interrupted turn, or discard it.
+type RecoverSessionMsg struct {
+	Recover bool
+}
+
+// InitReviewMsg answers the review of the PI.md /init proposed: Write it
+// to Path, or discard it.
+type InitReviewMsg struct {
+	Path    string
+	Content string
+	Write   bool
+}
+
+// DevcontainerAnswerMsg answers the startup devcontainer question.
+type DevcontainerAnswerMsg struct {
+	Use bool
+}
+
+// DevcontainerStartedMsg reports the devcontainer started for bash, or the
+// error that kept it from starting.
+type DevcontainerStartedMsg struct {
+	Desc string
+	Err  error
+}
+
+// ForkPointSelectedMsg is emitted by the fork picker: fork before Turn
+// (-1: after everything) and put Prompt back in the editor.
+type ForkPointSelectedMsg struct {
+	Turn   int
+	Prompt string
+}
+
+// EditPromptSelectedMsg is emitted by the edit picker: load the prompt at
+// message MsgIndex, shown at content index ContentIndex, into the editor.
+type EditPromptSelectedMsg struct {
+	MsgIndex     int
+	ContentIndex int
+	Prompt       string
+}
+
+// RerunPromptMsg answers the re-run question: drop the turns from the
+// edited prompt on and submit Text, or keep editing.
+type RerunPromptMsg struct {
+	Rerun bool
+	Text  string
+}
+
 // SessionSavedMsg confirms that the session was persisted to disk.
 type SessionSavedMsg struct {
 	SessionID string
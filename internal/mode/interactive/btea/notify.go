@@ -0,0 +1,68 @@
+// ABOUTME: Desktop notification when a background task finishes: OSC 777, terminal bell, or a command
+// ABOUTME: The method comes from the notify settings; the command gets the task in PI_TASK_* variables
+
+package btea
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mauromedda/pi-coding-agent-go/internal/config"
+)
+
+// notifyCommandTimeout bounds a configured notification command.
+const notifyCommandTimeout = 10 * time.Second
+
+// notifyTaskDone returns a command that announces task's completion on out
+// (the terminal) or through the configured command. It returns nil when
+// notifications are off.
+func notifyTaskDone(cfg *config.NotifySettings, task BackgroundTask, out io.Writer) tea.Cmd {
+	body := "Background task [" + task.Label() + "] " + task.Status.String()
+	switch cfg.EffectiveMethod() {
+	case "osc777":
+		// Terminals without OSC 777 ignore the sequence.
+		seq := "\x1b]777;notify;pi-go;" + oscSafe(body) + "\a"
+		return func() tea.Msg {
+			_, _ = io.WriteString(out, seq)
+			return nil
+		}
+	case "bell":
+		return func() tea.Msg {
+			_, _ = io.WriteString(out, "\a")
+			return nil
+		}
+	case "command":
+		command := cfg.Command
+		return func() tea.Msg {
+			ctx, cancel := context.WithTimeout(context.Background(), notifyCommandTimeout)
+			defer cancel()
+			c := exec.CommandContext(ctx, "sh", "-c", command)
+			c.Env = append(os.Environ(),
+				"PI_TASK_ID="+task.ID,
+				"PI_TASK_NAME="+task.Name,
+				"PI_TASK_STATUS="+task.Status.String(),
+				"PI_TASK_PROMPT="+task.Prompt,
+				"PI_NOTIFY_MESSAGE="+body,
+			)
+			_ = c.Run() // a failed notification must not disturb the session
+			return nil
+		}
+	}
+	return nil
+}
+
+// oscSafe drops control characters, which would end or corrupt an OSC
+// sequence.
+func oscSafe(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,46 @@
+// ABOUTME: Tests for background task notifications: OSC 777, bell, command, and off
+// ABOUTME: Terminal output goes to a buffer; the command writes its environment to a file
+
+package btea
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/config"
+)
+
+func TestNotifyTaskDone(t *testing.T) {
+	task := BackgroundTask{ID: "bg-1", Name: "tests\x1b", Prompt: "run tests", Status: BGDone}
+
+	var out bytes.Buffer
+	notifyTaskDone(nil, task, &out)()
+	if got, want := out.String(), "\x1b]777;notify;pi-go;Background task [tests] done\a"; got != want {
+		t.Errorf("osc777 wrote %q; want %q", got, want)
+	}
+
+	out.Reset()
+	notifyTaskDone(&config.NotifySettings{Method: "bell"}, task, &out)()
+	if out.String() != "\a" {
+		t.Errorf("bell wrote %q", out.String())
+	}
+
+	if cmd := notifyTaskDone(&config.NotifySettings{Method: "off"}, task, &out); cmd != nil {
+		t.Error("off should not notify")
+	}
+
+	file := filepath.Join(t.TempDir(), "env")
+	cfg := &config.NotifySettings{Method: "command", Command: `echo "$PI_TASK_ID $PI_TASK_STATUS $PI_NOTIFY_MESSAGE" > ` + file}
+	task.Name, task.Status = "", BGFailed
+	notifyTaskDone(cfg, task, &out)()
+	got, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(string(got)) != "bg-1 failed Background task [bg-1] failed" {
+		t.Errorf("command saw %q", got)
+	}
+}
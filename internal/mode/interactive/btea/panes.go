@@ -0,0 +1,325 @@
+// ABOUTME: PaneSetModel: side-by-side panes, each an AppModel with its own agent, messages, and queue
+// ABOUTME: Messages are tagged with their pane so agent events reach the right one; Alt+O switches focus
+
+package btea
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mauromedda/pi-coding-agent-go/internal/ide"
+	"github.com/mauromedda/pi-coding-agent-go/internal/session"
+)
+
+// maxPanes is the most panes a split layout holds.
+const maxPanes = 2
+
+// PaneMsg carries a message produced by the pane with ID Pane.
+type PaneMsg struct {
+	Pane int
+	Msg  tea.Msg
+}
+
+// SplitPaneMsg asks for a new pane next to the current one; a non-empty
+// Prompt is submitted there.
+type SplitPaneMsg struct{ Prompt string }
+
+// PaneFocusMsg tells a pane whether it has the keyboard.
+type PaneFocusMsg struct{ Focused bool }
+
+// NoticeMsg shows Text as a notice in the conversation.
+type NoticeMsg struct{ Text string }
+
+// send delivers msg through the program, tagged with this app's pane.
+func (sh *shared) send(msg tea.Msg) {
+	sh.program.Send(wrapPaneMsg(sh.pane, msg))
+}
+
+// teaPkg is the import path of Bubble Tea's own messages (quit, exec,
+// sequences), which are meant for the program rather than a pane.
+var teaPkg = reflect.TypeOf(tea.QuitMsg{}).PkgPath()
+
+// cmdType is the reflect type of tea.Cmd.
+var cmdType = reflect.TypeOf((*tea.Cmd)(nil)).Elem()
+
+// wrapPaneMsg tags msg as coming from pane. Batches and sequences are
+// unpacked so each command they hold is tagged in turn; other Bubble Tea
+// messages pass through, except quitting, which the pane set decides.
+func wrapPaneMsg(pane int, msg tea.Msg) tea.Msg {
+	if pane == 0 || msg == nil {
+		return msg
+	}
+	if _, ok := msg.(tea.QuitMsg); ok {
+		return PaneMsg{Pane: pane, Msg: msg}
+	}
+	t := reflect.TypeOf(msg)
+	if t.PkgPath() != teaPkg {
+		return PaneMsg{Pane: pane, Msg: msg}
+	}
+	if v := reflect.ValueOf(msg); t.Kind() == reflect.Slice && t.Elem() == cmdType {
+		cmds := reflect.MakeSlice(t, v.Len(), v.Len())
+		for i := range v.Len() {
+			cmd, _ := v.Index(i).Interface().(tea.Cmd)
+			cmds.Index(i).Set(reflect.ValueOf(paneCmd(pane, cmd)))
+		}
+		return cmds.Interface()
+	}
+	return msg
+}
+
+// paneCmd tags the message cmd produces as coming from pane.
+func paneCmd(pane int, cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg { return wrapPaneMsg(pane, cmd()) }
+}
+
+// PaneSetModel lays out one or more panes side by side. Keys go to the
+// focused pane and tagged messages to the pane that produced them; other
+// messages go to the focused pane. Quitting the first pane quits pi-go,
+// quitting another closes it.
+type PaneSetModel struct {
+	panes  []AppModel
+	focus  int
+	nextID int
+	width  int
+	height int
+}
+
+// NewPaneSetModel creates a pane set holding first, which must already have
+// its program set.
+func NewPaneSetModel(first AppModel) PaneSetModel {
+	first.sh.pane = 1
+	return PaneSetModel{panes: []AppModel{first}, nextID: 2}
+}
+
+// Init starts the first pane.
+func (m PaneSetModel) Init() tea.Cmd {
+	return paneCmd(m.panes[0].sh.pane, m.panes[0].Init())
+}
+
+// Update routes msg to its pane and handles splitting, focus, and closing.
+func (m PaneSetModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		return m.resize()
+
+	case tea.KeyMsg:
+		if msg.String() == "alt+o" && len(m.panes) > 1 {
+			return m.focusPane((m.focus + 1) % len(m.panes))
+		}
+		return m.updatePane(m.focus, msg)
+
+	case SettingsChangedMsg:
+		return m.broadcast(msg) // every pane holds its own copy of the settings
+
+	case PaneMsg:
+		i := m.paneIndex(msg.Pane)
+		if i < 0 {
+			return m, nil // the pane was closed
+		}
+		switch inner := msg.Msg.(type) {
+		case tea.QuitMsg:
+			if i == 0 {
+				return m, tea.Quit
+			}
+			return m.closePane(i)
+		case SplitPaneMsg:
+			return m.split(i, inner.Prompt)
+		case ThemeChangedMsg:
+			return m.broadcast(inner) // the theme is shared by every pane
+		}
+		return m.updatePane(i, msg.Msg)
+	}
+	return m.updatePane(m.focus, msg)
+}
+
+// View renders the panes side by side, each under a one-line header, in
+// ASCII when the terminal lacks Unicode.
+func (m PaneSetModel) View() string {
+	return foldGlyphs(m.view())
+}
+
+// view renders the panes before glyph folding.
+func (m PaneSetModel) view() string {
+	if len(m.panes) == 1 {
+		return m.panes[0].View()
+	}
+	s := Styles()
+	height := max(m.height, 2)
+	sep := strings.TrimSuffix(strings.Repeat(s.Border.Render("│")+"\n", height), "\n")
+	cols := make([]string, 0, 2*len(m.panes)-1)
+	for i, p := range m.panes {
+		if i > 0 {
+			cols = append(cols, sep)
+		}
+		header := s.Dim.Render(" " + p.paneTitle(i+1))
+		if i == m.focus {
+			header = s.OverlayTitle.Render(" " + p.paneTitle(i+1))
+		}
+		lines := strings.Split(p.View(), "\n")
+		lines = lines[max(len(lines)-(height-1), 0):] // keep the bottom, as a full screen would
+		body := append([]string{header}, lines...)
+		cols = append(cols, lipgloss.NewStyle().Width(p.width).Height(height).MaxHeight(height).Render(strings.Join(body, "\n")))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, cols...)
+}
+
+// paneTitle labels the pane numbered n with what its agent is doing.
+func (m AppModel) paneTitle(n int) string {
+	state := "idle"
+	switch {
+	case m.overlay != nil && isInlineOverlay(m.overlay):
+		state = "needs approval"
+	case m.agentRunning:
+		state = "running"
+	}
+	if len(m.promptQueue) > 0 {
+		state += fmt.Sprintf(", %d queued", len(m.promptQueue))
+	}
+	title := fmt.Sprintf("Pane %d · %s", n, state)
+	if m.deps.Session != nil && m.deps.Session.Title != "" {
+		title += " · " + m.deps.Session.Title
+	}
+	return title
+}
+
+// updatePane sends msg to pane i and tags the command it returns.
+func (m PaneSetModel) updatePane(i int, msg tea.Msg) (tea.Model, tea.Cmd) {
+	updated, cmd := m.panes[i].Update(msg)
+	m.panes[i] = updated.(AppModel)
+	return m, paneCmd(m.panes[i].sh.pane, cmd)
+}
+
+// broadcast sends msg to every pane.
+func (m PaneSetModel) broadcast(msg tea.Msg) (tea.Model, tea.Cmd) {
+	cmds := make([]tea.Cmd, len(m.panes))
+	for i := range m.panes {
+		updated, cmd := m.panes[i].Update(msg)
+		m.panes[i] = updated.(AppModel)
+		cmds[i] = paneCmd(m.panes[i].sh.pane, cmd)
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// paneIndex returns the index of the pane with ID id, or -1.
+func (m PaneSetModel) paneIndex(id int) int {
+	for i, p := range m.panes {
+		if p.sh.pane == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// focusPane gives the keyboard to pane i.
+func (m PaneSetModel) focusPane(i int) (tea.Model, tea.Cmd) {
+	m.focus = i
+	for j := range m.panes {
+		updated, _ := m.panes[j].Update(PaneFocusMsg{Focused: j == i})
+		m.panes[j] = updated.(AppModel)
+	}
+	return m, nil
+}
+
+// resize divides the width between the panes, leaving a column for each
+// separator and a row for the headers once split.
+func (m PaneSetModel) resize() (tea.Model, tea.Cmd) {
+	n := len(m.panes)
+	height := m.height
+	if n > 1 {
+		height = max(m.height-1, 1)
+	}
+	free := max(m.width-(n-1), n)
+	for i := range m.panes {
+		w := free / n
+		if i == n-1 {
+			w = free - (n-1)*(free/n)
+		}
+		updated, _ := m.panes[i].Update(tea.WindowSizeMsg{Width: w, Height: height})
+		m.panes[i] = updated.(AppModel)
+	}
+	return m, nil
+}
+
+// split opens a pane next to pane i with a fresh session and focuses it.
+func (m PaneSetModel) split(i int, prompt string) (tea.Model, tea.Cmd) {
+	if len(m.panes) >= maxPanes {
+		return m.updatePane(i, NoticeMsg{Text: fmt.Sprintf("Already showing %d panes; quit one (Ctrl+D or /exit) first.", maxPanes)})
+	}
+	deps, err := m.panes[i].splitDeps()
+	if err != nil {
+		return m.updatePane(i, NoticeMsg{Text: fmt.Sprintf("Could not open a pane: %v", err)})
+	}
+	pane := NewAppModel(deps)
+	pane.sh.program = m.panes[0].sh.program
+	pane.sh.screen = m.panes[0].sh.screen
+	pane.sh.bgManager = NewBackgroundManager(pane.sh.program)
+	pane.sh.pane = m.nextID
+	m.nextID++
+	m.panes = append(m.panes, pane)
+
+	cmds := []tea.Cmd{paneCmd(pane.sh.pane, pane.Init())}
+	model, _ := m.resize()
+	m = model.(PaneSetModel)
+	model, _ = m.focusPane(len(m.panes) - 1)
+	m = model.(PaneSetModel)
+	if prompt != "" {
+		last := len(m.panes) - 1
+		updated, cmd := m.panes[last].submitPrompt(prompt)
+		m.panes[last] = updated
+		cmds = append(cmds, paneCmd(updated.sh.pane, cmd))
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// closePane stops pane i's agent, saves its session, and removes it.
+func (m PaneSetModel) closePane(i int) (tea.Model, tea.Cmd) {
+	PaneSetModel{panes: []AppModel{{}, m.panes[i]}}.closeSplits()
+	m.panes = append(m.panes[:i:i], m.panes[i+1:]...)
+	model, _ := m.resize()
+	m = model.(PaneSetModel)
+	return m.focusPane(min(m.focus, len(m.panes)-1))
+}
+
+// closeSplits stops the agents of all panes but the first and saves their
+// sessions.
+func (m PaneSetModel) closeSplits() {
+	for _, p := range m.panes[1:] {
+		p.abortAgent()
+		p.sh.cancel()
+		if p.deps.Session != nil {
+			_ = p.deps.Session.Close()
+		}
+	}
+}
+
+// splitDeps returns the dependencies for a new pane: the same model, tools,
+// and settings, with a session and file checkpoints of its own, so /undo
+// in a pane restores only the files its turns changed. Crash recovery and
+// worktree exit stay with the first pane.
+func (m AppModel) splitDeps() (AppDeps, error) {
+	deps := m.deps
+	deps.Interrupted = nil
+	deps.Devcontainer = ""
+	deps.WorktreeSession = nil
+	deps.Checkpoints = ide.NewTurnCheckpoints()
+	if old := m.deps.Session; old != nil {
+		sess, err := session.StartInDir(m.sessionsDir(), old.Model, old.Provider, old.CWD)
+		if err != nil {
+			return deps, fmt.Errorf("starting a session: %w", err)
+		}
+		sess.ContextWindow, sess.Compaction = old.ContextWindow, old.Compaction
+		sess.ThinkingMode, sess.Profile = old.ThinkingMode, old.Profile
+		if old.Journal != nil {
+			sess.Journal, _ = session.OpenJournal(m.sessionsDir(), sess.ID)
+		}
+		deps.Session = sess
+	}
+	return deps, nil
+}
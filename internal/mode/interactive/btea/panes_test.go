@@ -0,0 +1,196 @@
+// ABOUTME: Tests for split panes: message tagging, routing to the right pane, focus, and closing
+// ABOUTME: Each pane gets its own session and file checkpoints in the sessions directory
+
+package btea
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mauromedda/pi-coding-agent-go/internal/ide"
+	"github.com/mauromedda/pi-coding-agent-go/internal/tools"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+func TestWrapPaneMsg(t *testing.T) {
+	t.Parallel()
+
+	if got := wrapPaneMsg(0, AgentTextMsg{Text: "x"}); got != (AgentTextMsg{Text: "x"}) {
+		t.Errorf("outside a pane set = %#v; want the message unchanged", got)
+	}
+	if got, ok := wrapPaneMsg(2, AgentTextMsg{Text: "x"}).(PaneMsg); !ok || got.Pane != 2 {
+		t.Errorf("agent message = %#v; want it tagged with pane 2", got)
+	}
+	if got, ok := wrapPaneMsg(2, tea.QuitMsg{}).(PaneMsg); !ok || got.Pane != 2 {
+		t.Errorf("quit = %#v; want it tagged so the pane set decides", got)
+	}
+
+	batch, ok := wrapPaneMsg(2, tea.Batch(
+		func() tea.Msg { return AgentTextMsg{Text: "a"} },
+		func() tea.Msg { return AgentThinkingMsg{Text: "b"} },
+	)()).(tea.BatchMsg)
+	if !ok || len(batch) != 2 {
+		t.Fatalf("batch = %#v; want a batch of two commands", batch)
+	}
+	for _, cmd := range batch {
+		if got, ok := cmd().(PaneMsg); !ok || got.Pane != 2 {
+			t.Errorf("batched command produced %#v; want it tagged with pane 2", got)
+		}
+	}
+}
+
+func paneSet(t *testing.T) PaneSetModel {
+	t.Helper()
+	deps := testDepsWithSession(t)
+	deps.SessionsDir = t.TempDir()
+	set := NewPaneSetModel(NewAppModel(deps))
+	model, _ := set.Update(tea.WindowSizeMsg{Width: 81, Height: 24})
+	return model.(PaneSetModel)
+}
+
+func updateSet(t *testing.T, m PaneSetModel, msg tea.Msg) (PaneSetModel, tea.Cmd) {
+	t.Helper()
+	model, cmd := m.Update(msg)
+	return model.(PaneSetModel), cmd
+}
+
+func TestPaneSetModel_SplitAndFocus(t *testing.T) {
+	m := paneSet(t)
+	m, _ = updateSet(t, m, PaneMsg{Pane: 1, Msg: SplitPaneMsg{}})
+	if len(m.panes) != 2 || m.focus != 1 {
+		t.Fatalf("after split: %d panes, focus %d; want 2 panes with the new one focused", len(m.panes), m.focus)
+	}
+	first, second := m.panes[0], m.panes[1]
+	if second.deps.Session == nil || second.deps.Session.ID == first.deps.Session.ID {
+		t.Error("new pane should have a session of its own")
+	}
+	if first.width+second.width+1 != 81 || first.height != 23 {
+		t.Errorf("pane sizes = %dx%d and %d wide; want the width split around a separator and a header row",
+			first.width, first.height, second.width)
+	}
+	if first.editor.focused || !second.editor.focused {
+		t.Error("only the new pane's editor should be focused")
+	}
+	if view := m.View(); !strings.Contains(view, "Pane 1") || !strings.Contains(view, "Pane 2") {
+		t.Errorf("view lacks pane headers:\n%s", view)
+	}
+
+	m, _ = updateSet(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o"), Alt: true})
+	if m.focus != 0 || !m.panes[0].editor.focused {
+		t.Errorf("Alt+O focus = %d; want the first pane", m.focus)
+	}
+
+	// Messages tagged for a pane reach it, whichever pane has focus.
+	m, _ = updateSet(t, m, PaneMsg{Pane: 2, Msg: NoticeMsg{Text: "for pane two"}})
+	if !strings.Contains(m.panes[1].lastAssistantText(), "for pane two") ||
+		strings.Contains(m.panes[0].lastAssistantText(), "for pane two") {
+		t.Error("tagged message should reach only pane 2")
+	}
+}
+
+func TestPaneSetModel_SplitLimit(t *testing.T) {
+	m := paneSet(t)
+	m, _ = updateSet(t, m, PaneMsg{Pane: 1, Msg: SplitPaneMsg{}})
+	m, _ = updateSet(t, m, PaneMsg{Pane: 2, Msg: SplitPaneMsg{}})
+	if len(m.panes) != maxPanes {
+		t.Fatalf("panes = %d; want at most %d", len(m.panes), maxPanes)
+	}
+	if !strings.Contains(m.panes[1].lastAssistantText(), "Already showing") {
+		t.Errorf("notice = %q; want the pane limit explained", m.panes[1].lastAssistantText())
+	}
+}
+
+func TestPaneSetModel_Quit(t *testing.T) {
+	m := paneSet(t)
+	m, _ = updateSet(t, m, PaneMsg{Pane: 1, Msg: SplitPaneMsg{}})
+
+	m, cmd := updateSet(t, m, PaneMsg{Pane: 2, Msg: tea.QuitMsg{}})
+	if len(m.panes) != 1 || m.focus != 0 || cmd != nil {
+		t.Fatalf("quitting pane 2: %d panes, focus %d; want it closed and pi-go still running", len(m.panes), m.focus)
+	}
+	if m.panes[0].width != 81 || m.panes[0].height != 24 {
+		t.Errorf("remaining pane = %dx%d; want the full screen back", m.panes[0].width, m.panes[0].height)
+	}
+	m, _ = updateSet(t, m, PaneMsg{Pane: 2, Msg: NoticeMsg{Text: "late"}})
+	if strings.Contains(m.panes[0].lastAssistantText(), "late") {
+		t.Error("a message for a closed pane should be dropped")
+	}
+
+	_, cmd = updateSet(t, m, PaneMsg{Pane: 1, Msg: tea.QuitMsg{}})
+	if cmd == nil {
+		t.Fatal("quitting the first pane should quit pi-go")
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Error("quitting the first pane should return tea.Quit")
+	}
+}
+
+func TestSplitCommand(t *testing.T) {
+	m := paneSet(t)
+	_, cmd := m.panes[0].handleSlashCommand("/split review the diff")
+	if cmd == nil {
+		t.Fatal("/split returned no command")
+	}
+	if msg, ok := cmd().(SplitPaneMsg); !ok || msg.Prompt != "review the diff" {
+		t.Errorf("/split produced %#v; want a split running the prompt", msg)
+	}
+
+	standalone := NewAppModel(testDeps())
+	standalone, _ = standalone.handleSlashCommand("/split")
+	if text := standalone.lastAssistantText(); !strings.Contains(text, "not available") {
+		t.Errorf("/split outside a pane set = %q; want not available", text)
+	}
+}
+
+// paneWrite runs a turn in pane i that writes content to path through the
+// shared registry's write tool, with the context the pane's agent runs in.
+func paneWrite(t *testing.T, m PaneSetModel, reg *tools.Registry, i int, path, content string) PaneSetModel {
+	t.Helper()
+	p := m.panes[i]
+	p, _ = p.submitPrompt("write " + filepath.Base(path))
+	ctx := tools.WithCheckpoints(context.Background(), p.deps.Checkpoints)
+	if res, err := reg.Get("write").Execute(ctx, "w", map[string]any{"path": path, "content": content}, nil); err != nil || res.IsError {
+		t.Fatalf("write = %+v, %v", res, err)
+	}
+	msgs := append(append([]ai.Message{}, p.messages...), ai.NewTextMessage(ai.RoleAssistant, "wrote it"))
+	result, _ := p.Update(AgentDoneMsg{Messages: msgs})
+	m.panes[i] = result.(AppModel)
+	return m
+}
+
+func TestPaneSetModel_UndoStaysInPane(t *testing.T) {
+	reg := tools.NewRegistry()
+	reg.EnableCheckpoints()
+	deps := testDepsWithSession(t)
+	deps.SessionsDir = t.TempDir()
+	deps.Checkpoints = ide.NewTurnCheckpoints()
+	m := NewPaneSetModel(NewAppModel(deps))
+	m, _ = updateSet(t, m, tea.WindowSizeMsg{Width: 81, Height: 24})
+	m, _ = updateSet(t, m, PaneMsg{Pane: 1, Msg: SplitPaneMsg{}})
+	if m.panes[1].deps.Checkpoints == nil || m.panes[1].deps.Checkpoints == m.panes[0].deps.Checkpoints {
+		t.Fatal("a split pane needs checkpoints of its own")
+	}
+
+	dir := t.TempDir()
+	first, second := filepath.Join(dir, "first.txt"), filepath.Join(dir, "second.txt")
+	if err := os.WriteFile(first, []byte("v1"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m = paneWrite(t, m, reg, 0, first, "v2")
+	m = paneWrite(t, m, reg, 1, second, "from pane 2")
+
+	m.panes[0], _ = m.panes[0].handleSlashCommand("/undo")
+	if got, _ := os.ReadFile(first); string(got) != "v1" {
+		t.Errorf("first.txt = %q; pane 1's undo should restore it", got)
+	}
+	if got, _ := os.ReadFile(second); string(got) != "from pane 2" {
+		t.Errorf("second.txt = %q; pane 1's undo must not touch pane 2's files", got)
+	}
+	if notice := m.panes[0].lastAssistantText(); strings.Contains(notice, "second.txt") {
+		t.Errorf("pane 1's undo notice lists pane 2's file:\n%s", notice)
+	}
+}
@@ -0,0 +1,116 @@
+// ABOUTME: PermOnboardingModel is the first-run overlay asking how autonomous the agent should be
+// ABOUTME: Presents cautious/balanced/autonomous presets; sends PermOnboardingMsg on selection
+
+package btea
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/config"
+)
+
+// PermOnboardingMsg carries the autonomy level chosen during onboarding.
+type PermOnboardingMsg struct {
+	Level string // one of config.AutonomyLevels
+}
+
+// PermOnboardingModel renders a centered dialog with one autonomy question.
+type PermOnboardingModel struct {
+	width int
+}
+
+// NewPermOnboardingModel creates the onboarding dialog.
+func NewPermOnboardingModel(w int) PermOnboardingModel {
+	return PermOnboardingModel{width: w}
+}
+
+// Init returns nil; no startup commands needed.
+func (m PermOnboardingModel) Init() tea.Cmd { return nil }
+
+// Update handles key events for the autonomy choice.
+func (m PermOnboardingModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+	}
+	return m, nil
+}
+
+func (m PermOnboardingModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	level := ""
+	switch msg.String() {
+	case "1", "c":
+		level = config.AutonomyCautious
+	case "2", "b":
+		level = config.AutonomyBalanced
+	case "3", "a":
+		level = config.AutonomyAutonomous
+	case "esc":
+		return m, func() tea.Msg { return DismissOverlayMsg{} }
+	default:
+		return m, nil
+	}
+	return m, func() tea.Msg { return PermOnboardingMsg{Level: level} }
+}
+
+// View renders the onboarding dialog.
+func (m PermOnboardingModel) View() string {
+	s := Styles()
+	bs := s.OverlayBorder
+
+	const (
+		dash    = "─"
+		vBorder = "│"
+		tl      = "╭"
+		tr      = "╮"
+		bl      = "╰"
+		br      = "╯"
+	)
+
+	boxWidth := 60
+	if boxWidth > m.width-4 {
+		boxWidth = max(m.width-4, 40)
+	}
+	innerWidth := max(boxWidth-2, 0)
+	contentWidth := max(boxWidth-4, 20)
+	border := bs.Render(vBorder)
+
+	var b strings.Builder
+
+	// Top border with title
+	const titleText = " Permissions for this project "
+	title := s.OverlayTitle.Render(titleText)
+	dashesLeft := max((innerWidth-len(titleText))/2, 0)
+	dashesRight := max(innerWidth-len(titleText)-dashesLeft, 0)
+	b.WriteString(bs.Render(tl))
+	b.WriteString(bs.Render(strings.Repeat(dash, dashesLeft)))
+	b.WriteString(title)
+	b.WriteString(bs.Render(strings.Repeat(dash, dashesRight)))
+	b.WriteString(bs.Render(tr))
+	b.WriteByte('\n')
+
+	writeBoxLine(&b, border, "How autonomous should the agent be here?", contentWidth)
+	writeBoxLine(&b, border, "", contentWidth)
+
+	// Options
+	writeBoxLine(&b, border, s.Success.Render("[1]")+" Cautious: ask before edits and commands", contentWidth)
+	writeBoxLine(&b, border, s.Info.Render("[2]")+" Balanced: apply edits, ask for risky commands", contentWidth)
+	writeBoxLine(&b, border, s.Warning.Render("[3]")+" Autonomous: run freely, ask before push/rm -rf", contentWidth)
+
+	writeBoxLine(&b, border, "", contentWidth)
+
+	// Hint
+	writeBoxLine(&b, border, s.Muted.Render("Saved to .pi-go/settings.json; change later with /permissions"), contentWidth)
+	writeBoxLine(&b, border, s.Muted.Render("esc: decide later"), contentWidth)
+
+	// Bottom border
+	b.WriteString(bs.Render(bl))
+	b.WriteString(bs.Render(strings.Repeat(dash, innerWidth)))
+	b.WriteString(bs.Render(br))
+
+	return b.String()
+}
@@ -0,0 +1,103 @@
+// ABOUTME: Tests for PermOnboardingModel overlay: key handling, View rendering, and app wiring
+// ABOUTME: Validates the autonomy level keys, esc to defer, and the startup overlay gate
+
+package btea
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/config"
+	"github.com/mauromedda/pi-coding-agent-go/internal/permission"
+)
+
+func TestPermOnboardingModel_LevelKeys(t *testing.T) {
+	t.Parallel()
+	for key, want := range map[rune]string{
+		'1': config.AutonomyCautious,
+		'2': config.AutonomyBalanced,
+		'3': config.AutonomyAutonomous,
+		'a': config.AutonomyAutonomous,
+	} {
+		m := NewPermOnboardingModel(80)
+		_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{key}})
+		if cmd == nil {
+			t.Fatalf("key %q: cmd = nil; want PermOnboardingMsg", key)
+		}
+		msg, ok := cmd().(PermOnboardingMsg)
+		if !ok || msg.Level != want {
+			t.Errorf("key %q: msg = %+v; want level %q", key, msg, want)
+		}
+	}
+}
+
+func TestPermOnboardingModel_EscDefers(t *testing.T) {
+	t.Parallel()
+	m := NewPermOnboardingModel(80)
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEscape})
+	if cmd == nil {
+		t.Fatal("cmd = nil; want DismissOverlayMsg")
+	}
+	if _, ok := cmd().(DismissOverlayMsg); !ok {
+		t.Errorf("cmd() = %T; want DismissOverlayMsg", cmd())
+	}
+
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	if cmd != nil {
+		t.Error("unbound key should do nothing")
+	}
+}
+
+func TestPermOnboardingModel_View(t *testing.T) {
+	t.Parallel()
+	view := NewPermOnboardingModel(80).View()
+	for _, want := range []string{"How autonomous should the agent be here?", "Cautious", "Balanced", "Autonomous", "/permissions"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View() missing %q", want)
+		}
+	}
+}
+
+func TestNewAppModel_PermissionOnboardingOverlay(t *testing.T) {
+	t.Parallel()
+	apply := func(string) (*config.PermissionsConfig, error) { return nil, nil }
+
+	m := NewAppModel(AppDeps{PermissionOnboarding: true, ApplyAutonomy: apply})
+	if _, ok := m.overlay.(PermOnboardingModel); !ok {
+		t.Errorf("overlay = %T; want PermOnboardingModel", m.overlay)
+	}
+	if m := NewAppModel(AppDeps{ApplyAutonomy: apply}); m.overlay != nil {
+		t.Errorf("overlay = %T without onboarding; want nil", m.overlay)
+	}
+}
+
+func TestAppModel_PermOnboardingMsgAppliesLevel(t *testing.T) {
+	t.Parallel()
+	m := newTestAppModel()
+	m.deps.Checker = permission.NewChecker(permission.ModeNormal, nil)
+	m.deps.ApplyAutonomy = func(level string) (*config.PermissionsConfig, error) {
+		p, err := config.StarterPermissions(level)
+		if err == nil {
+			m.deps.Checker.SetMode(permission.ModeAcceptEdits)
+		}
+		return p, err
+	}
+	m.overlay = NewPermOnboardingModel(80)
+
+	updated, _ := m.Update(PermOnboardingMsg{Level: config.AutonomyBalanced})
+	got := updated.(AppModel)
+	if got.overlay != nil {
+		t.Error("overlay should close after a choice")
+	}
+	if got.deps.PermissionMode != permission.ModeAcceptEdits {
+		t.Errorf("PermissionMode = %v; want acceptEdits", got.deps.PermissionMode)
+	}
+	if got.deps.Permissions == nil || got.deps.Permissions.DefaultMode != "acceptEdits" {
+		t.Errorf("Permissions = %+v", got.deps.Permissions)
+	}
+	if !strings.Contains(got.lastAssistantText(), "Always asks") {
+		t.Errorf("explanation not shown: %q", got.lastAssistantText())
+	}
+}
@@ -0,0 +1,185 @@
+// ABOUTME: PinViewModel is a Bubble Tea overlay for pinning conversation messages against compaction
+// ABOUTME: Vim-style navigation (j/k), toggle pin (p/space), close (esc/q); emits MessagePinMsg per toggle
+
+package btea
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/width"
+)
+
+// pinItem is one conversation message as shown in the pin overlay.
+type pinItem struct {
+	label  string
+	pinned bool
+}
+
+// PinViewModel lists conversation messages and toggles their pinned flag.
+type PinViewModel struct {
+	items  []pinItem
+	cursor int
+	width  int
+}
+
+// NewPinViewModel creates a pin overlay for msgs with the cursor on the latest message.
+func NewPinViewModel(msgs []ai.Message, w int) PinViewModel {
+	items := make([]pinItem, len(msgs))
+	for i, msg := range msgs {
+		items[i] = pinItem{label: messageLabel(msg), pinned: msg.Pinned}
+	}
+	return PinViewModel{
+		items:  items,
+		cursor: max(len(items)-1, 0),
+		width:  w,
+	}
+}
+
+// Init returns nil; no startup commands needed.
+func (m PinViewModel) Init() tea.Cmd { return nil }
+
+// Update handles key events for pin management.
+func (m PinViewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+	}
+	return m, nil
+}
+
+func (m PinViewModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "j", "down":
+		if m.cursor < len(m.items)-1 {
+			m.cursor++
+		}
+		return m, nil
+
+	case "k", "up":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+		return m, nil
+
+	case "p", " ":
+		if len(m.items) == 0 {
+			return m, nil
+		}
+		items := make([]pinItem, len(m.items))
+		copy(items, m.items)
+		items[m.cursor].pinned = !items[m.cursor].pinned
+		m.items = items
+		pin := MessagePinMsg{Index: m.cursor, Pinned: items[m.cursor].pinned}
+		return m, func() tea.Msg { return pin }
+
+	case "esc", "q":
+		return m, func() tea.Msg { return PinViewDismissMsg{} }
+	}
+
+	return m, nil
+}
+
+// View renders the pin overlay as a bordered box.
+func (m PinViewModel) View() string {
+	s := Styles()
+	bs := s.OverlayBorder
+
+	const (
+		dash    = "─"
+		vBorder = "│"
+		tl      = "╭"
+		tr      = "╮"
+		bl      = "╰"
+		br      = "╯"
+	)
+
+	boxWidth := max(m.width*3/5, 40)
+	if boxWidth > m.width-4 {
+		boxWidth = max(m.width-4, 40)
+	}
+	innerWidth := max(boxWidth-2, 0)
+	contentWidth := max(boxWidth-4, 20)
+	border := bs.Render(vBorder)
+
+	var b strings.Builder
+
+	titleText := " Pin Messages "
+	title := s.OverlayTitle.Render(titleText)
+	dashesLeft := max((innerWidth-len(titleText))/2, 0)
+	dashesRight := max(innerWidth-len(titleText)-dashesLeft, 0)
+	b.WriteString(bs.Render(tl))
+	b.WriteString(bs.Render(strings.Repeat(dash, dashesLeft)))
+	b.WriteString(title)
+	b.WriteString(bs.Render(strings.Repeat(dash, dashesRight)))
+	b.WriteString(bs.Render(tr))
+	b.WriteByte('\n')
+
+	if len(m.items) == 0 {
+		writeBoxLine(&b, border, s.Dim.Render("(no messages)"), contentWidth)
+	} else {
+		maxW := max(contentWidth-10, 10) // cursor + pin mark + number
+		for i, item := range m.items {
+			prefix := "  "
+			if i == m.cursor {
+				prefix = "> "
+			}
+			mark := "  "
+			if item.pinned {
+				mark = "* "
+			}
+			display := item.label
+			if width.VisibleWidth(display) > maxW {
+				display = width.TruncateToWidth(display, maxW-3) + "..."
+			}
+			line := fmt.Sprintf("%s%s%d. %s", prefix, mark, i+1, display)
+			if i == m.cursor {
+				writeBoxLine(&b, border, s.Selection.Render(line), contentWidth)
+			} else {
+				writeBoxLine(&b, border, s.Dim.Render(line), contentWidth)
+			}
+		}
+	}
+
+	writeBoxLine(&b, border, s.Muted.Render("j/k:nav  p:pin/unpin  esc:close"), contentWidth)
+
+	b.WriteString(bs.Render(bl))
+	b.WriteString(bs.Render(strings.Repeat(dash, innerWidth)))
+	b.WriteString(bs.Render(br))
+
+	return b.String()
+}
+
+// messageLabel summarizes a message on one line: its role and first text,
+// or the tools it calls or answers.
+func messageLabel(msg ai.Message) string {
+	who := "you"
+	if msg.Role == ai.RoleAssistant {
+		who = "assistant"
+	}
+	var tools []string
+	results := 0
+	for _, c := range msg.Content {
+		switch c.Type {
+		case ai.ContentText:
+			if text := strings.Join(strings.Fields(c.Text), " "); text != "" {
+				return who + ": " + text
+			}
+		case ai.ContentToolUse:
+			tools = append(tools, c.Name)
+		case ai.ContentToolResult:
+			results++
+		}
+	}
+	switch {
+	case len(tools) > 0:
+		return who + ": [calls " + strings.Join(tools, ", ") + "]"
+	case results > 0:
+		return fmt.Sprintf("tool results (%d)", results)
+	}
+	return who + ": (empty)"
+}
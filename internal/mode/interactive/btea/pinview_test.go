@@ -0,0 +1,89 @@
+// ABOUTME: Tests for PinViewModel overlay: navigation, pin toggling, close, and message labels
+// ABOUTME: Verifies MessagePinMsg emission and that the app applies pins to its messages
+
+package btea
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+// Compile-time check: PinViewModel must satisfy tea.Model.
+var _ tea.Model = PinViewModel{}
+
+func pinTestMessages() []ai.Message {
+	return []ai.Message{
+		ai.NewTextMessage(ai.RoleUser, "use  the\nv2 API"),
+		{Role: ai.RoleAssistant, Content: []ai.Content{{Type: ai.ContentToolUse, ID: "c1", Name: "read"}}},
+		{Role: ai.RoleUser, Content: []ai.Content{{Type: ai.ContentToolResult, ID: "c1", ResultText: "ok"}}},
+	}
+}
+
+func TestPinViewModel_TogglesSelectedMessage(t *testing.T) {
+	m := NewPinViewModel(pinTestMessages(), 80)
+	if m.cursor != 2 {
+		t.Fatalf("initial cursor = %d; want the latest message", m.cursor)
+	}
+
+	result, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'k'}})
+	result, _ = result.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'k'}})
+	result, cmd := result.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}})
+	m = result.(PinViewModel)
+	if cmd == nil {
+		t.Fatal("p should emit a command")
+	}
+	pin, ok := cmd().(MessagePinMsg)
+	if !ok || pin.Index != 0 || !pin.Pinned {
+		t.Errorf("msg = %+v; want pin of message 0", pin)
+	}
+	if !m.items[0].pinned || !strings.Contains(m.View(), "* 1. you: use the v2 API") {
+		t.Errorf("view should mark the pin:\n%s", m.View())
+	}
+
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}})
+	if pin := cmd().(MessagePinMsg); pin.Pinned {
+		t.Error("second p should unpin")
+	}
+
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if _, ok := cmd().(PinViewDismissMsg); !ok {
+		t.Error("esc should dismiss the overlay")
+	}
+}
+
+func TestMessageLabel(t *testing.T) {
+	msgs := pinTestMessages()
+	cases := []struct {
+		msg  ai.Message
+		want string
+	}{
+		{msgs[0], "you: use the v2 API"},
+		{msgs[1], "assistant: [calls read]"},
+		{msgs[2], "tool results (1)"},
+	}
+	for _, tc := range cases {
+		if got := messageLabel(tc.msg); got != tc.want {
+			t.Errorf("messageLabel() = %q; want %q", got, tc.want)
+		}
+	}
+}
+
+func TestAppModel_MessagePinMsg(t *testing.T) {
+	m := NewAppModel(testDeps())
+	m.messages = pinTestMessages()
+	m.overlay = NewPinViewModel(m.messages, 80)
+
+	result, _ := m.Update(MessagePinMsg{Index: 1, Pinned: true})
+	m = result.(AppModel)
+	if !m.messages[1].Pinned {
+		t.Error("MessagePinMsg should pin the message")
+	}
+
+	result, _ = m.Update(PinViewDismissMsg{})
+	if result.(AppModel).overlay != nil {
+		t.Error("PinViewDismissMsg should close the overlay")
+	}
+}
@@ -0,0 +1,299 @@
+// ABOUTME: PlanProgressModel: runs an approved plan one step per turn and shows a step checklist
+// ABOUTME: Steps are parsed from numbered or bulleted plan lines; a failed or paused step halts the run
+
+package btea
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+// numberedStep matches "1. x", "2) x", and "Step 3: x" plan lines.
+var numberedStep = regexp.MustCompile(`^(?:[Ss]tep\s+)?\d+[.):]\s+(.+)$`)
+
+// bulletStep matches top-level "- x" and "* x" plan lines.
+var bulletStep = regexp.MustCompile(`^[-*]\s+(.+)$`)
+
+// numberedSteps returns the text of plan's top-level numbered lines.
+func numberedSteps(plan string) []string {
+	return matchSteps(plan, numberedStep)
+}
+
+// parsePlanSteps splits plan into steps: its numbered lines, else its
+// bulleted lines, else the whole plan as a single step.
+func parsePlanSteps(plan string) []string {
+	if steps := numberedSteps(plan); len(steps) > 0 {
+		return steps
+	}
+	if steps := matchSteps(plan, bulletStep); len(steps) > 0 {
+		return steps
+	}
+	if plan = strings.TrimSpace(plan); plan != "" {
+		return []string{plan}
+	}
+	return nil
+}
+
+// matchSteps collects the first group of every unindented line of plan that
+// re matches, without Markdown emphasis.
+func matchSteps(plan string, re *regexp.Regexp) []string {
+	var steps []string
+	for line := range strings.SplitSeq(plan, "\n") {
+		if line == "" || line[0] == ' ' || line[0] == '\t' {
+			continue // blank, or detail under a step
+		}
+		if sm := re.FindStringSubmatch(strings.TrimSpace(line)); sm != nil {
+			steps = append(steps, strings.Trim(sm[1], "*_ "))
+		}
+	}
+	return steps
+}
+
+// PlanProgressModel tracks an approved plan being carried out, one turn
+// per step. The zero value is no plan.
+type PlanProgressModel struct {
+	steps   []string
+	done    int  // steps completed; steps[done] is the current one
+	running bool // a turn for the current step is in flight
+	paused  bool // do not start another step
+	failed  bool // the running step's turn failed or was cancelled
+	width   int
+}
+
+// NewPlanProgressModel starts tracking steps.
+func NewPlanProgressModel(steps []string) PlanProgressModel {
+	return PlanProgressModel{steps: steps}
+}
+
+// Active reports whether steps remain to be done.
+func (m PlanProgressModel) Active() bool {
+	return m.done < len(m.steps)
+}
+
+// Ready reports whether the next step should start now.
+func (m PlanProgressModel) Ready() bool {
+	return m.Active() && !m.running && !m.paused
+}
+
+// Start marks the current step as running.
+func (m PlanProgressModel) Start() PlanProgressModel {
+	m.running, m.failed = true, false
+	return m
+}
+
+// Fail records that the running step did not finish; the plan pauses on it.
+func (m PlanProgressModel) Fail() PlanProgressModel {
+	if m.running {
+		m.failed = true
+	}
+	return m
+}
+
+// Finish ends the running step's turn, completing the step unless it failed.
+func (m PlanProgressModel) Finish() PlanProgressModel {
+	if !m.running {
+		return m
+	}
+	m.running = false
+	if m.failed {
+		m.paused = true
+	} else {
+		m.done++
+	}
+	return m
+}
+
+// Pause stops the plan after the running step.
+func (m PlanProgressModel) Pause() PlanProgressModel {
+	m.paused = true
+	return m
+}
+
+// Resume lets the plan continue; a failed step is retried.
+func (m PlanProgressModel) Resume() PlanProgressModel {
+	m.paused, m.failed = false, false
+	return m
+}
+
+// WithWidth sets the render width.
+func (m PlanProgressModel) WithWidth(w int) PlanProgressModel {
+	m.width = w
+	return m
+}
+
+// Current returns the 1-based number and text of the current step.
+func (m PlanProgressModel) Current() (int, string) {
+	if !m.Active() {
+		return len(m.steps), ""
+	}
+	return m.done + 1, m.steps[m.done]
+}
+
+// Len returns the number of steps.
+func (m PlanProgressModel) Len() int {
+	return len(m.steps)
+}
+
+// StepPrompt is the prompt for the current step. It restates the plan as a
+// checklist so the agent knows what is done and what comes later.
+func (m PlanProgressModel) StepPrompt() string {
+	n, text := m.Current()
+	var b strings.Builder
+	fmt.Fprintf(&b, "Carry out step %d of %d of the approved plan:\n\n%s\n\nPlan:\n", n, len(m.steps), text)
+	for i, step := range m.steps {
+		mark := "[ ]"
+		if i < m.done {
+			mark = "[x]"
+		}
+		fmt.Fprintf(&b, "%s %d. %s", mark, i+1, step)
+		if i == m.done {
+			b.WriteString("  <- this step")
+		}
+		b.WriteByte('\n')
+	}
+	b.WriteString("\nDo only this step and stop; the next one follows in a separate turn.")
+	return b.String()
+}
+
+// View renders a header and one line per step: done, current, or to do.
+func (m PlanProgressModel) View() string {
+	if !m.Active() {
+		return ""
+	}
+	s := Styles()
+	n, _ := m.Current()
+	state := ""
+	switch {
+	case m.failed || (m.paused && !m.running):
+		state = " · paused (/plan resume)"
+	case m.paused:
+		state = " · pausing after this step"
+	}
+	lines := []string{s.OverlayTitle.Render(fmt.Sprintf("Plan %d/%d", n, len(m.steps))) + s.Dim.Render(state)}
+	for i, step := range m.steps {
+		line := fmt.Sprintf("  %d. %s", i+1, step)
+		if m.width > 0 {
+			line = truncateVisual(line, m.width-2)
+		}
+		switch {
+		case i < m.done:
+			lines = append(lines, s.Dim.Render("✓"+line))
+		case i == m.done:
+			lines = append(lines, s.Selection.Render("▸"+line))
+		default:
+			lines = append(lines, s.Muted.Render(" "+line))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// approvePlan switches to Edit mode and starts carrying out plan.
+func (m AppModel) approvePlan(plan string) (AppModel, tea.Cmd) {
+	steps := parsePlanSteps(plan)
+	if len(steps) == 0 {
+		return m, nil
+	}
+	if m.mode == ModePlan {
+		m = m.toggleMode()
+	}
+	m.plan = NewPlanProgressModel(steps).WithWidth(m.width)
+	if m.agentRunning {
+		return m, nil // the first step starts when the running turn ends
+	}
+	return m.runPlanStep()
+}
+
+// runPlanStep submits the current plan step as a prompt.
+func (m AppModel) runPlanStep() (AppModel, tea.Cmd) {
+	if !m.plan.Ready() {
+		return m, nil
+	}
+	prompt := m.plan.StepPrompt()
+	m.plan = m.plan.Start()
+	return m.submitPrompt(prompt)
+}
+
+// finishPlanStep records the end of a turn for the running plan step and
+// returns a notice when the plan completes or pauses.
+func (m AppModel) finishPlanStep() (AppModel, string) {
+	if !m.plan.running {
+		return m, ""
+	}
+	m.plan = m.plan.Finish()
+	n, text := m.plan.Current()
+	switch {
+	case !m.plan.Active():
+		m.plan = PlanProgressModel{}
+		return m, fmt.Sprintf("Plan complete: all %d steps done.", n)
+	case m.plan.failed:
+		return m, fmt.Sprintf("Plan paused: step %d did not finish. /plan resume retries it, /plan stop ends the plan.", n)
+	case m.plan.paused:
+		return m, fmt.Sprintf("Plan paused before step %d of %d (%s). /plan resume continues.", n, m.plan.Len(), text)
+	}
+	return m, ""
+}
+
+// planAction handles /plan pause, resume, and stop for the running plan.
+func (m AppModel) planAction(action string) (string, error) {
+	switch action {
+	case "pause", "resume", "stop":
+	default:
+		return "", fmt.Errorf("unknown argument %q (use /plan, or /plan pause|resume|stop)", action)
+	}
+	if !m.plan.Active() {
+		return "No plan is running.", nil
+	}
+	switch action {
+	case "pause":
+		if m.plan.running {
+			return "The plan will pause after the current step.", nil
+		}
+		return "Plan paused.", nil
+	case "resume":
+		return "Resuming the plan.", nil
+	}
+	n, _ := m.plan.Current()
+	return fmt.Sprintf("Plan stopped with %d of %d steps done.", n-1, m.plan.Len()), nil
+}
+
+// applyPlanAction applies a /plan action that planAction accepted.
+func (m AppModel) applyPlanAction(action string) (AppModel, tea.Cmd) {
+	switch action {
+	case "pause":
+		m.plan = m.plan.Pause()
+	case "resume":
+		m.plan = m.plan.Resume()
+		if !m.agentRunning && len(m.promptQueue) == 0 {
+			return m.runPlanStep()
+		}
+	case "stop":
+		m.plan = PlanProgressModel{}
+	}
+	return m, nil
+}
+
+// offerPlan opens the plan review when a Plan mode turn ended with a reply
+// of at least two numbered steps.
+func (m AppModel) offerPlan() AppModel {
+	if m.mode != ModePlan || m.plan.Active() || m.overlay != nil || len(m.messages) == 0 {
+		return m
+	}
+	last := m.messages[len(m.messages)-1]
+	if last.Role != ai.RoleAssistant {
+		return m
+	}
+	var b strings.Builder
+	for _, c := range last.Content {
+		if c.Type == ai.ContentText {
+			b.WriteString(c.Text)
+		}
+	}
+	if reply := b.String(); len(numberedSteps(reply)) >= 2 {
+		m.overlay, _ = NewPlanViewModel(reply).Update(tea.WindowSizeMsg{Width: m.width, Height: m.height})
+	}
+	return m
+}
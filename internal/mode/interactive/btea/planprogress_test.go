@@ -0,0 +1,190 @@
+// ABOUTME: Tests for approved-plan execution: step parsing, per-step progress, pausing, and failures
+// ABOUTME: Drives AppModel through approval and AgentDoneMsg turns without a real agent
+
+package btea
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+func TestParsePlanSteps(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		plan string
+		want []string
+	}{
+		{
+			name: "numbered with details",
+			plan: "Plan:\n1. Add the parser\n   - handle tabs\n2) **Wire it up**\n\nStep 3: Test it",
+			want: []string{"Add the parser", "Wire it up", "Test it"},
+		},
+		{
+			name: "bullets",
+			plan: "- read config\n* write config",
+			want: []string{"read config", "write config"},
+		},
+		{name: "plain text", plan: "  just do it  ", want: []string{"just do it"}},
+		{name: "empty", plan: " \n", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := parsePlanSteps(tt.plan); !slices.Equal(got, tt.want) {
+				t.Errorf("parsePlanSteps = %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanProgressModel(t *testing.T) {
+	t.Parallel()
+
+	p := NewPlanProgressModel([]string{"one", "two"})
+	if !p.Ready() {
+		t.Fatal("a new plan should be ready to start")
+	}
+	p = p.Start()
+	if !strings.Contains(p.StepPrompt(), "step 1 of 2") || !strings.Contains(p.StepPrompt(), "[ ] 2. two") {
+		t.Errorf("StepPrompt = %q", p.StepPrompt())
+	}
+	p = p.Pause().Finish()
+	if n, _ := p.Current(); n != 2 || p.Ready() {
+		t.Fatalf("after a paused step: current %d, ready %v; want step 2 waiting", n, p.Ready())
+	}
+	if !strings.Contains(p.View(), "Plan 2/2") || !strings.Contains(p.View(), "paused") {
+		t.Errorf("View = %q", p.View())
+	}
+
+	p = p.Resume().Start().Fail().Finish()
+	if n, _ := p.Current(); n != 2 || !p.failed || p.Ready() {
+		t.Fatalf("after a failed step: current %d; want step 2 kept and the plan paused", n)
+	}
+	p = p.Resume().Start().Finish()
+	if p.Active() || p.View() != "" {
+		t.Error("plan should be complete")
+	}
+}
+
+// userTexts returns the text of every user message on screen.
+func userTexts(m AppModel) []string {
+	var texts []string
+	for _, c := range m.content {
+		if um, ok := c.(UserMsgModel); ok {
+			texts = append(texts, um.text)
+		}
+	}
+	return texts
+}
+
+func approvedPlan(t *testing.T) AppModel {
+	t.Helper()
+	m := NewAppModel(testDeps())
+	m.mode = ModePlan
+	result, _ := m.Update(PlanApprovedMsg{Plan: "1. Add the flag\n2. Document it"})
+	m = result.(AppModel)
+	if m.mode != ModeEdit || !m.agentRunning {
+		t.Fatalf("mode %v, running %v; want Edit mode with step 1 running", m.mode, m.agentRunning)
+	}
+	return m
+}
+
+func endStepTurn(m AppModel) AppModel {
+	result, _ := m.Update(AgentDoneMsg{})
+	return result.(AppModel)
+}
+
+func TestAppModel_PlanRunsStepByStep(t *testing.T) {
+	m := approvedPlan(t)
+	if texts := userTexts(m); len(texts) != 1 || !strings.Contains(texts[0], "step 1 of 2") {
+		t.Fatalf("prompts = %q; want step 1", texts)
+	}
+	if !strings.Contains(m.View(), "Plan 1/2") {
+		t.Error("view should show the plan checklist")
+	}
+
+	m = endStepTurn(m)
+	if texts := userTexts(m); len(texts) != 2 || !strings.Contains(texts[1], "[x] 1. Add the flag") {
+		t.Fatalf("prompts = %q; want step 2 with step 1 checked", texts)
+	}
+
+	m = endStepTurn(m)
+	if m.plan.Active() || m.agentRunning {
+		t.Fatal("plan should be complete")
+	}
+	if !strings.Contains(m.lastAssistantText(), "Plan complete") {
+		t.Errorf("notice = %q", m.lastAssistantText())
+	}
+}
+
+func TestAppModel_PlanPauseAndResume(t *testing.T) {
+	m := approvedPlan(t)
+	m.editor = m.editor.SetText("/plan pause")
+	m, _ = m.submitOrEnqueue()
+	if len(m.promptQueue) != 0 || !strings.Contains(m.lastAssistantText(), "pause after the current step") {
+		t.Fatalf("queue %q, notice %q; want /plan pause run at once", m.promptQueue, m.lastAssistantText())
+	}
+
+	m = endStepTurn(m)
+	if m.agentRunning || !strings.Contains(m.lastAssistantText(), "Plan paused before step 2") {
+		t.Fatalf("running %v, notice %q; want the plan paused", m.agentRunning, m.lastAssistantText())
+	}
+
+	m, _ = m.handleSlashCommand("/plan resume")
+	if !m.agentRunning || len(userTexts(m)) != 2 {
+		t.Fatal("/plan resume should start step 2")
+	}
+
+	m, _ = m.handleSlashCommand("/plan stop")
+	m = endStepTurn(m)
+	if m.plan.Active() || len(userTexts(m)) != 2 {
+		t.Error("/plan stop should end the plan after the running step")
+	}
+
+	m, _ = m.handleSlashCommand("/plan resume")
+	if got := m.lastAssistantText(); got != "No plan is running." {
+		t.Errorf("/plan resume without a plan = %q", got)
+	}
+}
+
+func TestAppModel_PlanStepFailurePauses(t *testing.T) {
+	m := approvedPlan(t)
+	result, _ := m.Update(AgentErrorMsg{Err: errors.New("boom")})
+	m = endStepTurn(result.(AppModel))
+	if n, _ := m.plan.Current(); n != 1 || m.agentRunning {
+		t.Fatalf("current step %d, running %v; want step 1 kept and nothing running", n, m.agentRunning)
+	}
+	if !strings.Contains(m.lastAssistantText(), "step 1 did not finish") {
+		t.Errorf("notice = %q", m.lastAssistantText())
+	}
+}
+
+func TestAppModel_OffersPlanAfterPlanModeTurn(t *testing.T) {
+	m := NewAppModel(testDeps())
+	m.mode = ModePlan
+	reply := ai.Message{Role: ai.RoleAssistant, Content: []ai.Content{{Type: ai.ContentText, Text: "1. Read\n2. Write"}}}
+	result, _ := m.Update(AgentDoneMsg{Messages: []ai.Message{ai.NewTextMessage(ai.RoleUser, "plan it"), reply}})
+	m = result.(AppModel)
+	pv, ok := m.overlay.(PlanViewModel)
+	if !ok {
+		t.Fatalf("overlay = %T; want the plan review", m.overlay)
+	}
+
+	_, cmd := pv.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if msg, ok := cmd().(PlanApprovedMsg); !ok || msg.Plan != "1. Read\n2. Write" {
+		t.Errorf("approval = %#v; want it to carry the plan", msg)
+	}
+
+	m = NewAppModel(testDeps())
+	result, _ = m.Update(AgentDoneMsg{Messages: []ai.Message{reply}})
+	if result.(AppModel).overlay != nil {
+		t.Error("Edit mode turns should not open the plan review")
+	}
+}
@@ -10,8 +10,8 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-// PlanApprovedMsg signals the user approved the plan.
-type PlanApprovedMsg struct{}
+// PlanApprovedMsg signals the user approved Plan.
+type PlanApprovedMsg struct{ Plan string }
 
 // PlanRejectedMsg signals the user rejected the plan.
 type PlanRejectedMsg struct{}
@@ -40,7 +40,8 @@ func (m PlanViewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "y", "enter":
-			return m, func() tea.Msg { return PlanApprovedMsg{} }
+			plan := m.plan
+			return m, func() tea.Msg { return PlanApprovedMsg{Plan: plan} }
 		case "n", "esc":
 			return m, func() tea.Msg { return PlanRejectedMsg{} }
 		case "j", "down":
@@ -116,7 +117,7 @@ func (m PlanViewModel) View() string {
 	b.WriteByte('\n')
 
 	// Keybinding hints line
-	hints := s.Dim.Render("y=approve  n=reject  j/k=scroll")
+	hints := s.Dim.Render("y=approve and run  n=reject  j/k=scroll")
 	writeBoxLine(&b, border, hints, contentWidth)
 
 	// Separator
@@ -0,0 +1,105 @@
+// ABOUTME: Tests for speculative provider prewarm while the user types a follow-up
+// ABOUTME: Uses a recording provider; checks the mode gate and once-per-idle-period behavior
+
+package btea
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/config"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+// prewarmProvider records Prewarm calls; Stream is never used.
+type prewarmProvider struct {
+	mu       sync.Mutex
+	prefixes []bool
+	messages int
+}
+
+func (p *prewarmProvider) Api() ai.Api { return ai.ApiAnthropic }
+
+func (p *prewarmProvider) Stream(context.Context, *ai.Model, *ai.Context, *ai.StreamOptions) *ai.EventStream {
+	return nil
+}
+
+func (p *prewarmProvider) Prewarm(_ context.Context, _ *ai.Model, llmCtx *ai.Context, prefix bool) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.prefixes = append(p.prefixes, prefix)
+	p.messages = len(llmCtx.Messages)
+	return nil
+}
+
+func typeRune(t *testing.T, m AppModel, r rune) (AppModel, tea.Cmd) {
+	t.Helper()
+	updated, cmd := m.handleKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	return updated.(AppModel), cmd
+}
+
+// runBatch executes cmd and, for a batch, each of its commands.
+func runBatch(cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	if batch, ok := cmd().(tea.BatchMsg); ok {
+		for _, c := range batch {
+			runBatch(c)
+		}
+	}
+}
+
+func TestPrewarm_OncePerIdlePeriod(t *testing.T) {
+	t.Parallel()
+
+	provider := &prewarmProvider{}
+	deps := testDeps()
+	deps.Provider = provider
+	deps.Prewarm = &config.PrewarmSettings{Mode: config.PrewarmPrefix}
+	m := NewAppModel(deps)
+	m.messages = []ai.Message{ai.NewTextMessage(ai.RoleUser, "hi"), ai.NewTextMessage(ai.RoleAssistant, "hello")}
+
+	m, cmd := typeRune(t, m, 'a')
+	runBatch(cmd)
+	m, cmd = typeRune(t, m, 'b')
+	runBatch(cmd)
+
+	if len(provider.prefixes) != 1 || !provider.prefixes[0] || provider.messages != 2 {
+		t.Fatalf("prewarm calls = %v with %d messages, want one prefix call with the history", provider.prefixes, provider.messages)
+	}
+
+	updated, _ := m.Update(AgentDoneMsg{})
+	m = updated.(AppModel)
+	m.editor = m.editor.SetText("")
+	_, cmd = typeRune(t, m, 'c')
+	runBatch(cmd)
+	if len(provider.prefixes) != 2 {
+		t.Errorf("prewarm calls = %d, want another after the turn ended", len(provider.prefixes))
+	}
+}
+
+func TestPrewarm_OffOrBusy(t *testing.T) {
+	t.Parallel()
+
+	provider := &prewarmProvider{}
+	deps := testDeps()
+	deps.Provider = provider
+	m := NewAppModel(deps)
+	if m.editor = m.editor.SetText("x"); m.prewarmCmd() != nil {
+		t.Error("prewarm must be off by default")
+	}
+
+	m.deps.Prewarm = &config.PrewarmSettings{Mode: config.PrewarmConnection}
+	m.agentRunning = true
+	if m.prewarmCmd() != nil {
+		t.Error("no prewarm while the agent is running")
+	}
+	m.agentRunning = false
+	if m.prewarmCmd() == nil {
+		t.Error("connection mode should prewarm when idle")
+	}
+}
@@ -0,0 +1,59 @@
+// ABOUTME: Prompt queue state: run-next flags, duplicate removal, and draining in order
+// ABOUTME: Every change is written to the crash journal so queued prompts survive a crash mid-run
+
+package btea
+
+import (
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// setQueue replaces the queue with items, the first next of which are
+// flagged to run next. Duplicates are dropped; the footer and the crash
+// journal follow the new queue.
+func (m AppModel) setQueue(items []string, next int) AppModel {
+	m.promptQueue, m.queueNext = dedupeQueue(items, next)
+	m.footer = m.footer.WithQueuedCount(len(m.promptQueue))
+	_ = m.journal().Queue(m.promptQueue, m.queueNext)
+	return m
+}
+
+// enqueue appends text to the queue. A prompt already waiting is not
+// queued twice.
+func (m AppModel) enqueue(text string) AppModel {
+	return m.setQueue(append(slices.Clone(m.promptQueue), text), m.queueNext)
+}
+
+// drainQueue submits the first queued prompt. It returns a nil command when
+// the queue is empty.
+func (m AppModel) drainQueue() (AppModel, tea.Cmd) {
+	if len(m.promptQueue) == 0 {
+		return m, nil
+	}
+	next := m.promptQueue[0]
+	m = m.setQueue(m.promptQueue[1:], m.queueNext-1)
+	return m.submitPrompt(next)
+}
+
+// dedupeQueue drops prompts identical to an earlier one, ignoring
+// surrounding whitespace, and recounts the run-next prompts at the front.
+func dedupeQueue(items []string, next int) ([]string, int) {
+	next = min(max(next, 0), len(items))
+	seen := make(map[string]bool, len(items))
+	out := make([]string, 0, len(items))
+	flagged := 0
+	for i, item := range items {
+		key := strings.TrimSpace(item)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, item)
+		if i < next {
+			flagged++
+		}
+	}
+	return out, flagged
+}
@@ -0,0 +1,97 @@
+// ABOUTME: Tests for the prompt queue: duplicate removal, run-next order, and crash-safe persistence
+// ABOUTME: A crash is simulated by reading the journal the live session leaves behind
+
+package btea
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/session"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+func TestDedupeQueue(t *testing.T) {
+	items, next := dedupeQueue([]string{"a", "b", " a ", "c", "b"}, 3)
+	if strings.Join(items, ",") != "a,b,c" || next != 2 {
+		t.Errorf("dedupeQueue = %v, %d; want a,b,c with 2 flagged", items, next)
+	}
+	if _, next := dedupeQueue([]string{"a"}, 5); next != 1 {
+		t.Errorf("next = %d; want it clamped to the queue", next)
+	}
+}
+
+func TestAppModel_QueueDedupesAndRunsNextFirst(t *testing.T) {
+	m := NewAppModel(testDeps())
+	m.agentRunning = true
+	for _, p := range []string{"lint", "test", "lint"} {
+		m.editor = m.editor.SetText(p)
+		m, _ = m.submitOrEnqueue()
+	}
+	if strings.Join(m.promptQueue, ",") != "lint,test" || m.footer.queuedCount != 2 {
+		t.Fatalf("queue = %v, footer %d; want the duplicate dropped", m.promptQueue, m.footer.queuedCount)
+	}
+
+	// Flag "test" to run next in the overlay.
+	result, _ := m.Update(QueueUpdatedMsg{Items: []string{"test", "lint"}, Next: 1})
+	m = result.(AppModel)
+	result, _ = m.Update(AgentDoneMsg{})
+	m = result.(AppModel)
+	if !m.agentRunning || strings.Join(m.promptQueue, ",") != "lint" || m.queueNext != 0 {
+		t.Errorf("after drain: running %v, queue %v, next %d; want test running first", m.agentRunning, m.promptQueue, m.queueNext)
+	}
+	if got := m.promptHistory[len(m.promptHistory)-1]; got != "test" {
+		t.Errorf("last submitted = %q; want the run-next prompt", got)
+	}
+}
+
+func TestAppModel_QueueSurvivesCrash(t *testing.T) {
+	dir := t.TempDir()
+	sess := journaledSession(t, dir, "crashed")
+	deps := testDeps()
+	deps.Session = sess
+	deps.SessionsDir = dir
+	m := NewAppModel(deps)
+
+	m, _ = m.submitPrompt("refactor")
+	m.editor = m.editor.SetText("then test")
+	m, _ = m.enqueuePrompt()
+	msgs := append(append([]ai.Message{}, m.messages...), ai.NewTextMessage(ai.RoleAssistant, "done"))
+	result, _ := m.Update(AgentDoneMsg{Messages: msgs})
+	m = result.(AppModel)
+	m.editor = m.editor.SetText("and commit")
+	m, _ = m.enqueuePrompt()
+	_ = sess.Writer.Close() // the process dies mid-turn; the journal stays
+
+	in, err := session.ReadJournal(filepath.Join(dir, "crashed.journal"))
+	if err != nil || in == nil {
+		t.Fatalf("ReadJournal = %+v, %v", in, err)
+	}
+	if in.Prompt != "then test" || strings.Join(in.Queue, ",") != "and commit" {
+		t.Fatalf("journal = %+v; want the running prompt and the queue", in)
+	}
+
+	fresh := journaledSession(t, dir, "fresh")
+	t.Cleanup(func() { fresh.Close() })
+	deps = testDeps()
+	deps.Session = fresh
+	deps.SessionsDir = dir
+	deps.Interrupted = in
+	m = NewAppModel(deps)
+	if view := m.overlay.View(); !strings.Contains(view, "Queued:  1 prompt") {
+		t.Errorf("recovery question should count the queue:\n%s", view)
+	}
+	result, _ = m.Update(RecoverSessionMsg{Recover: true})
+	m = result.(AppModel)
+	if m.agentRunning || strings.Join(m.promptQueue, ",") != "and commit" {
+		t.Errorf("recovered: running %v, queue %v; want the queue restored and waiting", m.agentRunning, m.promptQueue)
+	}
+	if !strings.Contains(m.lastAssistantText(), "Restored 1 prompt to the queue") {
+		t.Errorf("notice = %q", m.lastAssistantText())
+	}
+	in, _ = session.ReadJournal(filepath.Join(dir, "crashed.journal"))
+	if in == nil || in.HasTurn() || len(in.Queue) != 1 {
+		t.Errorf("journal after recovery = %+v; want the restored queue kept", in)
+	}
+}
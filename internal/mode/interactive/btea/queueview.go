@@ -1,10 +1,11 @@
 // ABOUTME: QueueViewModel is a Bubble Tea overlay for viewing, editing, and reordering queued prompts
-// ABOUTME: Vim-style navigation (j/k), delete (d), swap (J/K), edit (e/Enter), close (esc/q)
+// ABOUTME: Vim-style navigation (j/k), delete (d), swap (J/K), run next (n), edit (e/Enter), close (esc/q)
 
 package btea
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -12,8 +13,11 @@ import (
 )
 
 // QueueViewModel displays queued prompts with vim-style navigation and editing.
+// The first next items are flagged to run next; J/K move an item within its
+// group and n moves it between the groups.
 type QueueViewModel struct {
 	items  []string
+	next   int
 	cursor int
 	width  int
 }
@@ -28,6 +32,12 @@ func NewQueueViewModel(items []string, w int) QueueViewModel {
 	}
 }
 
+// WithNext returns a QueueViewModel whose first n items are flagged to run next.
+func (m QueueViewModel) WithNext(n int) QueueViewModel {
+	m.next = min(max(n, 0), len(m.items))
+	return m
+}
+
 // Init returns nil; no startup commands needed.
 func (m QueueViewModel) Init() tea.Cmd { return nil }
 
@@ -63,6 +73,9 @@ func (m QueueViewModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 		m.items = append(m.items[:m.cursor], m.items[m.cursor+1:]...)
+		if m.cursor < m.next {
+			m.next--
+		}
 		if len(m.items) == 0 {
 			return m, m.closeCmd()
 		}
@@ -71,22 +84,40 @@ func (m QueueViewModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	// Swap down (Shift+J)
+	// Swap down (Shift+J), staying among run-next or regular items
 	case "J":
-		if m.cursor < len(m.items)-1 {
+		if m.cursor < len(m.items)-1 && m.cursor != m.next-1 {
 			m.items[m.cursor], m.items[m.cursor+1] = m.items[m.cursor+1], m.items[m.cursor]
 			m.cursor++
 		}
 		return m, nil
 
-	// Swap up (Shift+K)
+	// Swap up (Shift+K), staying among run-next or regular items
 	case "K":
-		if m.cursor > 0 {
+		if m.cursor > 0 && m.cursor != m.next {
 			m.items[m.cursor], m.items[m.cursor-1] = m.items[m.cursor-1], m.items[m.cursor]
 			m.cursor--
 		}
 		return m, nil
 
+	// Toggle run next: flagged items go last among the run-next ones,
+	// unflagged items first among the rest
+	case "n":
+		if len(m.items) == 0 {
+			return m, nil
+		}
+		item := m.items[m.cursor]
+		m.items = slices.Delete(m.items, m.cursor, m.cursor+1)
+		if m.cursor < m.next {
+			m.next--
+			m.cursor = m.next
+		} else {
+			m.cursor = m.next
+			m.next++
+		}
+		m.items = slices.Insert(m.items, m.cursor, item)
+		return m, nil
+
 	// Edit item: pop into editor
 	case "e", "enter":
 		if len(m.items) == 0 {
@@ -94,8 +125,10 @@ func (m QueueViewModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		text := m.items[m.cursor]
 		idx := m.cursor
+		items := slices.Clone(m.items)
+		next := m.next
 		return m, func() tea.Msg {
-			return QueueEditMsg{Text: text, Index: idx}
+			return QueueEditMsg{Text: text, Index: idx, Items: items, Next: next}
 		}
 
 	// Close overlay
@@ -109,8 +142,9 @@ func (m QueueViewModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m QueueViewModel) closeCmd() func() tea.Msg {
 	items := make([]string, len(m.items))
 	copy(items, m.items)
+	next := m.next
 	return func() tea.Msg {
-		return QueueUpdatedMsg{Items: items}
+		return QueueUpdatedMsg{Items: items, Next: next}
 	}
 }
 
@@ -163,6 +197,9 @@ func (m QueueViewModel) View() string {
 				prefix = "> "
 			}
 			display := item
+			if i < m.next {
+				display = "[next] " + display
+			}
 			if width.VisibleWidth(display) > maxW {
 				display = width.TruncateToWidth(display, maxW-3) + "..."
 			}
@@ -176,7 +213,7 @@ func (m QueueViewModel) View() string {
 	}
 
 	// Hint line
-	writeBoxLine(&b, border, s.Muted.Render("j/k:nav  d:del  J/K:move  e:edit  esc:close"), contentWidth)
+	writeBoxLine(&b, border, s.Muted.Render("j/k:nav  d:del  J/K:move  n:run next  e:edit  esc:close"), contentWidth)
 
 	// Bottom border
 	b.WriteString(bs.Render(bl))
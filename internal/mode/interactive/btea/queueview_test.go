@@ -4,6 +4,7 @@
 package btea
 
 import (
+	"strings"
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -187,6 +188,46 @@ func TestQueueViewModel_SwapUpAtTop(t *testing.T) {
 	}
 }
 
+func TestQueueViewModel_RunNext(t *testing.T) {
+	m := NewQueueViewModel([]string{"a", "b", "c"}, 80)
+	press := func(key string) {
+		t.Helper()
+		result, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		m = result.(QueueViewModel)
+	}
+	press("j")
+	press("j")
+	press("n")
+	if strings.Join(m.items, ",") != "c,a,b" || m.next != 1 || m.cursor != 0 {
+		t.Fatalf("flag c: items %v, next %d, cursor %d; want c first and flagged", m.items, m.next, m.cursor)
+	}
+
+	// J/K keep an item in its group.
+	press("J")
+	press("j")
+	press("K")
+	if strings.Join(m.items, ",") != "c,a,b" {
+		t.Errorf("moves across the run-next boundary: items %v", m.items)
+	}
+
+	press("n")
+	press("k")
+	press("k")
+	press("n")
+	if strings.Join(m.items, ",") != "a,c,b" || m.next != 1 || m.cursor != 1 {
+		t.Errorf("unflag c: items %v, next %d, cursor %d; want c first among the rest", m.items, m.next, m.cursor)
+	}
+	if view := m.View(); !strings.Contains(view, "[next] a") || strings.Contains(view, "[next] c") {
+		t.Errorf("View() should mark run-next prompts:\n%s", view)
+	}
+
+	result, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = result.(QueueViewModel)
+	if msg, ok := cmd().(QueueUpdatedMsg); !ok || msg.Next != 1 || strings.Join(msg.Items, ",") != "a,c,b" {
+		t.Errorf("close = %+v; want the order and run-next count", msg)
+	}
+}
+
 func TestQueueViewModel_EditItem(t *testing.T) {
 	m := NewQueueViewModel([]string{"first", "second"}, 80)
 	m.cursor = 0
@@ -365,7 +406,7 @@ func TestAppModel_QueueEditMsg(t *testing.T) {
 	m.promptQueue = []string{"edit me", "keep me"}
 	m.overlay = NewQueueViewModel(m.promptQueue, 80)
 
-	result, _ := m.Update(QueueEditMsg{Text: "edit me", Index: 0})
+	result, _ := m.Update(QueueEditMsg{Text: "edit me", Index: 0, Items: m.promptQueue})
 	model := result.(AppModel)
 
 	if model.overlay != nil {
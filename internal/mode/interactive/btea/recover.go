@@ -0,0 +1,190 @@
+// ABOUTME: RecoverConfirmModel overlay asking, at startup, whether to restore a turn a crashed run left behind
+// ABOUTME: Recovery reopens that session, appends the partial reply, and restores queued prompts; declining discards the journal
+
+package btea
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mauromedda/pi-coding-agent-go/internal/session"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/width"
+)
+
+// RecoverConfirmModel describes an interrupted turn and waits for y/enter
+// to restore it or n/esc to discard it.
+type RecoverConfirmModel struct {
+	in    *session.Interrupted
+	width int
+}
+
+// NewRecoverConfirmModel creates the recovery question for in.
+func NewRecoverConfirmModel(in *session.Interrupted, w int) RecoverConfirmModel {
+	return RecoverConfirmModel{in: in, width: w}
+}
+
+// Init returns nil; no startup commands needed.
+func (m RecoverConfirmModel) Init() tea.Cmd { return nil }
+
+// Update handles key events for the question.
+func (m RecoverConfirmModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "y", "enter":
+			return m, func() tea.Msg { return RecoverSessionMsg{Recover: true} }
+		case "n", "esc", "q":
+			return m, func() tea.Msg { return RecoverSessionMsg{Recover: false} }
+		}
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+	}
+	return m, nil
+}
+
+// View renders the interrupted turn as a bordered box.
+func (m RecoverConfirmModel) View() string {
+	s := Styles()
+	bs := s.OverlayBorder
+
+	const (
+		dash    = "─"
+		vBorder = "│"
+		tl      = "╭"
+		tr      = "╮"
+		bl      = "╰"
+		br      = "╯"
+	)
+
+	boxWidth := max(m.width*3/5, 50)
+	if boxWidth > m.width-4 {
+		boxWidth = max(m.width-4, 50)
+	}
+	innerWidth := max(boxWidth-2, 0)
+	contentWidth := max(boxWidth-4, 20)
+	border := bs.Render(vBorder)
+
+	var b strings.Builder
+
+	const titleText = " Recover interrupted session? "
+	title := s.OverlayTitle.Render(titleText)
+	dashesLeft := max((innerWidth-len(titleText))/2, 0)
+	dashesRight := max(innerWidth-len(titleText)-dashesLeft, 0)
+	b.WriteString(bs.Render(tl))
+	b.WriteString(bs.Render(strings.Repeat(dash, dashesLeft)))
+	b.WriteString(title)
+	b.WriteString(bs.Render(strings.Repeat(dash, dashesRight)))
+	b.WriteString(bs.Render(tr))
+	b.WriteByte('\n')
+
+	for _, line := range recoverSummary(m.in, contentWidth) {
+		writeBoxLine(&b, border, s.Dim.Render(line), contentWidth)
+	}
+	writeBoxLine(&b, border, "", contentWidth)
+	writeBoxLine(&b, border, s.Muted.Render("y/enter:recover  n/esc:discard"), contentWidth)
+
+	b.WriteString(bs.Render(bl))
+	b.WriteString(bs.Render(strings.Repeat(dash, innerWidth)))
+	b.WriteString(bs.Render(br))
+
+	return b.String()
+}
+
+// recoverSummary describes the interrupted turn in lines of at most w cells.
+func recoverSummary(in *session.Interrupted, w int) []string {
+	fit := func(s string) string {
+		s = strings.Join(strings.Fields(s), " ")
+		if width.VisibleWidth(s) > w {
+			s = width.TruncateToWidth(s, max(w-3, 1)) + "..."
+		}
+		return s
+	}
+	if !in.HasTurn() {
+		return []string{
+			"A previous run in this directory stopped with prompts queued.",
+			fit("Session: " + in.SessionID),
+			fmt.Sprintf("Queued:  %s", queuedCount(len(in.Queue))),
+		}
+	}
+	lines := []string{"A previous run in this directory stopped mid-turn."}
+	when := ""
+	if !in.At.IsZero() {
+		when = " (" + in.At.Local().Format("2006-01-02 15:04") + ")"
+	}
+	lines = append(lines, fit("Session: "+in.SessionID+when))
+	lines = append(lines, fit("Prompt:  "+in.Prompt))
+	done := 0
+	for _, t := range in.Tools {
+		if t.Done {
+			done++
+		}
+	}
+	lines = append(lines, fmt.Sprintf("Saved:   %d characters of reply, %d of %d tool calls finished", len([]rune(in.Text)), done, len(in.Tools)))
+	if len(in.Queue) > 0 {
+		lines = append(lines, fmt.Sprintf("Queued:  %s", queuedCount(len(in.Queue))))
+	}
+	return lines
+}
+
+// queuedCount describes n queued prompts.
+func queuedCount(n int) string {
+	if n == 1 {
+		return "1 prompt"
+	}
+	return fmt.Sprintf("%d prompts", n)
+}
+
+// journal returns the crash journal of the saved session, or nil.
+func (m AppModel) journal() *session.Journal {
+	if m.deps.Session == nil {
+		return nil
+	}
+	return m.deps.Session.Journal
+}
+
+// answerRecovery restores the interrupted turn, switching to its session,
+// or discards it. The session started for this run is deleted when the
+// switch leaves it empty.
+func (m AppModel) answerRecovery(restore bool) (AppModel, string) {
+	in := m.deps.Interrupted
+	m.deps.Interrupted = nil
+	if in == nil || m.deps.Session == nil {
+		return m, ""
+	}
+	dir := m.sessionsDir()
+	if !restore {
+		if err := session.DiscardJournal(dir, in.SessionID); err != nil {
+			return m, fmt.Sprintf("Could not discard the interrupted turn: %v", err)
+		}
+		return m, ""
+	}
+
+	fresh := m.deps.Session.ID
+	unused := len(m.deps.Session.Messages) == 0
+	m, err := m.switchSession(in.SessionID)
+	if err != nil {
+		return m, fmt.Sprintf("Could not recover session %s: %v", in.SessionID, err)
+	}
+	if unused {
+		_ = session.RemoveInDir(dir, fresh)
+	}
+
+	var notice string
+	if in.HasTurn() {
+		partial := in.Message()
+		_ = m.deps.Session.AddAssistantMessage(&ai.AssistantMessage{Content: partial.Content})
+		m.messages = m.deps.Session.Messages
+		m = m.renderMessages([]ai.Message{partial})
+		notice = fmt.Sprintf("Recovered session %s with its interrupted reply.", in.SessionID)
+	} else {
+		notice = fmt.Sprintf("Recovered session %s.", in.SessionID)
+	}
+	if len(in.Queue) > 0 {
+		// Restored prompts wait: closing the queue view (Ctrl+E) runs them.
+		m = m.setQueue(append(in.Queue, m.promptQueue...), in.QueueNext)
+		notice += fmt.Sprintf(" Restored %s to the queue; press Ctrl+E to review them, closing the list runs them.", queuedCount(len(in.Queue)))
+	}
+	return m, notice
+}
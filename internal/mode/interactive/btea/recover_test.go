@@ -0,0 +1,170 @@
+// ABOUTME: Tests for crash recovery: journaling the turn in flight and the startup recovery question
+// ABOUTME: A crashed session is simulated with a journal left next to its saved records
+
+package btea
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
+	"github.com/mauromedda/pi-coding-agent-go/internal/session"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+// Compile-time check: RecoverConfirmModel must satisfy tea.Model.
+var _ tea.Model = RecoverConfirmModel{}
+
+// journaledSession starts session id in dir with a journal.
+func journaledSession(t *testing.T, dir, id string) *session.Session {
+	t.Helper()
+	w, err := session.NewWriterInDir(dir, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRecord(session.RecordSessionStart, session.SessionStartData{ID: id, CWD: "/work"}); err != nil {
+		t.Fatal(err)
+	}
+	j, err := session.OpenJournal(dir, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &session.Session{ID: id, Model: &ai.Model{Name: "test-model"}, Writer: w, Journal: j}
+}
+
+// recoveryModel returns an AppModel on a fresh session, started after
+// session "crashed" died mid-turn in the same directory.
+func recoveryModel(t *testing.T) AppModel {
+	t.Helper()
+	dir := t.TempDir()
+	crashed := journaledSession(t, dir, "crashed")
+	if err := crashed.AddUserMessage("fix the build"); err != nil {
+		t.Fatal(err)
+	}
+	_ = crashed.Journal.BeginTurn("fix the build")
+	_ = crashed.Journal.Text("Looking at the error")
+	_ = crashed.Journal.ToolStart("t1", "bash", `{"command":"go build"}`)
+	_ = crashed.Writer.Close() // the process dies; the journal stays
+
+	in, err := session.FindInterrupted(dir, "/work")
+	if err != nil || in == nil {
+		t.Fatalf("FindInterrupted = %+v, %v", in, err)
+	}
+	fresh := journaledSession(t, dir, "fresh")
+	t.Cleanup(func() { fresh.Close() })
+
+	deps := testDeps()
+	deps.Session = fresh
+	deps.SessionsDir = dir
+	deps.Interrupted = in
+	return NewAppModel(deps)
+}
+
+func TestRecoverConfirmModel_Keys(t *testing.T) {
+	m := NewRecoverConfirmModel(&session.Interrupted{SessionID: "s1", Prompt: "fix it", Text: "partial"}, 80)
+	for key, want := range map[string]bool{"y": true, "enter": true, "n": false, "esc": false} {
+		var msg tea.KeyMsg
+		switch key {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		}
+		_, cmd := m.Update(msg)
+		if cmd == nil {
+			t.Fatalf("%s: no command", key)
+		}
+		if got, ok := cmd().(RecoverSessionMsg); !ok || got.Recover != want {
+			t.Errorf("%s = %#v; want Recover %v", key, got, want)
+		}
+	}
+	view := m.View()
+	for _, want := range []string{"Recover interrupted session?", "s1", "fix it", "7 characters of reply"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View() missing %q", want)
+		}
+	}
+}
+
+func TestAppModel_RecoversInterruptedTurn(t *testing.T) {
+	m := recoveryModel(t)
+	if _, ok := m.overlay.(RecoverConfirmModel); !ok {
+		t.Fatalf("overlay = %T; want RecoverConfirmModel at startup", m.overlay)
+	}
+
+	result, _ := m.Update(RecoverSessionMsg{Recover: true})
+	m = result.(AppModel)
+	if m.overlay != nil || m.deps.Session.ID != "crashed" {
+		t.Fatalf("overlay = %T, session = %s; want the crashed session", m.overlay, m.deps.Session.ID)
+	}
+	if len(m.messages) != 2 {
+		t.Fatalf("messages = %d; want the prompt and the partial reply", len(m.messages))
+	}
+	reply := m.messages[1].Content[0].Text
+	if !strings.Contains(reply, "Looking at the error") || !strings.Contains(reply, "bash") {
+		t.Errorf("partial reply = %q", reply)
+	}
+	if _, err := os.Stat(filepath.Join(m.sessionsDir(), "fresh.jsonl")); !os.IsNotExist(err) {
+		t.Error("the unused fresh session should be deleted")
+	}
+
+	// The partial reply is saved, and nothing is offered again.
+	records, _ := session.ReadRecordsInDir(m.sessionsDir(), "crashed")
+	msgs, _ := session.BuildSessionContext(records)
+	if len(msgs) != 2 || !strings.Contains(msgs[1].Content[0].Text, "Interrupted") {
+		t.Errorf("saved messages = %+v", msgs)
+	}
+	if in, _ := session.FindInterrupted(m.sessionsDir(), "/work"); in != nil {
+		t.Errorf("recovered turn still offered: %+v", in)
+	}
+}
+
+func TestAppModel_DeclineRecoveryDiscardsJournal(t *testing.T) {
+	m := recoveryModel(t)
+	result, _ := m.Update(RecoverSessionMsg{Recover: false})
+	m = result.(AppModel)
+	if m.overlay != nil || m.deps.Session.ID != "fresh" {
+		t.Errorf("overlay = %T, session = %s; want to stay on the fresh session", m.overlay, m.deps.Session.ID)
+	}
+	if in, _ := session.FindInterrupted(m.sessionsDir(), "/work"); in != nil {
+		t.Errorf("declined turn still offered: %+v", in)
+	}
+}
+
+func TestAppModel_JournalsTurnInFlight(t *testing.T) {
+	dir := t.TempDir()
+	sess := journaledSession(t, dir, "live")
+	t.Cleanup(func() { sess.Close() })
+	deps := testDeps()
+	deps.Session = sess
+	deps.SessionsDir = dir
+	m := NewAppModel(deps)
+
+	m, _ = m.submitPrompt("run the tests")
+	result, _ := m.Update(AgentTextMsg{Text: "Running"})
+	m = result.(AppModel)
+	result, _ = m.Update(AgentToolStartMsg{ToolID: "t1", ToolName: "bash", Args: map[string]any{"command": "go test"}})
+	m = result.(AppModel)
+	result, _ = m.Update(AgentToolEndMsg{ToolID: "t1", Result: &agent.ToolResult{Content: "ok"}})
+	m = result.(AppModel)
+
+	path := filepath.Join(dir, "live.journal")
+	in, err := session.ReadJournal(path)
+	if err != nil || in == nil {
+		t.Fatalf("ReadJournal = %+v, %v", in, err)
+	}
+	if in.Prompt != "run the tests" || in.Text != "Running" || len(in.Tools) != 1 || !in.Tools[0].Done {
+		t.Errorf("journal = %+v", in)
+	}
+
+	msgs := append(append([]ai.Message{}, m.messages...), ai.NewTextMessage(ai.RoleAssistant, "Running"))
+	m.Update(AgentDoneMsg{Messages: msgs})
+	if in, _ := session.ReadJournal(path); in != nil {
+		t.Errorf("journal should be cleared once the turn is saved: %+v", in)
+	}
+}
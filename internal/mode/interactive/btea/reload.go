@@ -0,0 +1,191 @@
+// ABOUTME: Settings reload: re-reads settings on /reload or when a settings file changes on disk
+// ABOUTME: Applies theme, permissions, hooks, status line, and model overrides; other keys wait for a restart
+
+package btea
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mauromedda/pi-coding-agent-go/internal/config"
+	"github.com/mauromedda/pi-coding-agent-go/internal/permission"
+	"github.com/mauromedda/pi-coding-agent-go/internal/statusline"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/theme"
+)
+
+// permissionKeys are the settings keys that feed the permission checker.
+var permissionKeys = map[string]bool{
+	"allow": true, "deny": true, "ask": true, "defaultMode": true, "permissions": true,
+}
+
+// settingsChanged re-applies settings after a settings file changed on
+// disk. During a turn the reload waits until the turn ends, so its notice
+// does not land in the middle of the reply.
+func (m AppModel) settingsChanged() (AppModel, tea.Cmd) {
+	if m.deps.ReloadSettings == nil {
+		return m, nil
+	}
+	if m.agentRunning {
+		m.reloadPending = true
+		return m, nil
+	}
+	m.reloadPending = false
+	m, cmd, notice := m.reloadSettings()
+	if notice != "" {
+		m, _ = m.applyNotice(notice)
+	}
+	return m, cmd
+}
+
+// reloadSettings re-reads the settings and applies the keys that can change
+// in a running session. The notice lists what was applied and what waits
+// for a restart; it is empty when nothing changed.
+func (m AppModel) reloadSettings() (AppModel, tea.Cmd, string) {
+	fresh, err := m.deps.ReloadSettings()
+	if err != nil {
+		return m, nil, fmt.Sprintf("Settings not reloaded: %v", err)
+	}
+	old := m.deps.Settings
+	if old == nil {
+		old = &config.Settings{}
+	}
+	m.deps.Settings = fresh
+
+	var applied, restart, failed []string
+	var cmds []tea.Cmd
+	reloadPermissions := false
+	for _, key := range config.ChangedKeys(old, fresh) {
+		switch {
+		case key == "theme" && fresh.Theme == "auto":
+			restart = append(restart, key)
+		case key == "theme":
+			var cmd tea.Cmd
+			var changed bool
+			if m, cmd, changed, err = m.reloadTheme(fresh.Theme); err != nil {
+				failed = append(failed, fmt.Sprintf("theme: %v", err))
+			} else if changed {
+				applied = append(applied, key)
+				cmds = append(cmds, cmd)
+			}
+		case permissionKeys[key]:
+			// /permissions already applied a block it saved itself.
+			if key == "permissions" && reflect.DeepEqual(fresh.Permissions, m.deps.Permissions) {
+				continue
+			}
+			reloadPermissions = true
+			applied = append(applied, key)
+		case key == "hooks":
+			m.deps.Hooks = fresh.Hooks
+			applied = append(applied, key)
+		case key == "suggestions":
+			// Read from m.deps.Settings after each turn.
+			applied = append(applied, key)
+		case key == "statusLine":
+			var cmd tea.Cmd
+			m, cmd = m.reloadStatusLine(fresh.StatusLine)
+			applied = append(applied, key)
+			cmds = append(cmds, cmd)
+		case key == "modelOverrides":
+			if m, err = m.reloadModelOverrides(fresh.ModelOverrides); err != nil {
+				failed = append(failed, fmt.Sprintf("modelOverrides: %v", err))
+			} else {
+				applied = append(applied, key)
+			}
+		default:
+			restart = append(restart, key)
+		}
+	}
+	if reloadPermissions {
+		m = m.reloadPermissions(old, fresh)
+	}
+
+	if len(applied)+len(restart)+len(failed) == 0 {
+		return m, nil, ""
+	}
+	var b strings.Builder
+	b.WriteString("Settings reloaded.")
+	if len(applied) > 0 {
+		fmt.Fprintf(&b, "\n  Applied: %s", strings.Join(applied, ", "))
+	}
+	if len(restart) > 0 {
+		fmt.Fprintf(&b, "\n  Takes effect after a restart: %s", strings.Join(restart, ", "))
+	}
+	for _, f := range failed {
+		fmt.Fprintf(&b, "\n  Not applied: %s", f)
+	}
+	return m, tea.Batch(cmds...), b.String()
+}
+
+// reloadTheme switches to the named theme, "" meaning the default. It
+// reports no change when that theme is already active, e.g. after /theme
+// saved it.
+func (m AppModel) reloadTheme(name string) (AppModel, tea.Cmd, bool, error) {
+	if name == "" {
+		name = "default"
+	}
+	th, err := theme.Resolve(name, m.deps.ThemeDirs)
+	if err != nil {
+		return m, nil, false, err
+	}
+	if cur := theme.Current(); cur != nil && cur.Name == th.Name {
+		return m, nil, false, nil
+	}
+	m, cmd := m.setTheme(th)
+	return m, cmd, true, nil
+}
+
+// reloadPermissions replaces the checker's rules. The permission mode only
+// changes when the configured default did, so a mode picked with Shift+Tab
+// survives unrelated edits.
+func (m AppModel) reloadPermissions(old, fresh *config.Settings) AppModel {
+	if c := m.deps.Checker; c != nil {
+		c.SetGlobRules(fresh.EffectivePermissions())
+		if fresh.EffectiveDefaultMode() != old.EffectiveDefaultMode() {
+			if mode, err := permission.ParseMode(fresh.EffectiveDefaultMode()); err == nil {
+				c.SetMode(mode)
+			}
+		}
+	}
+	return m.withPermissions(fresh.Permissions)
+}
+
+// reloadStatusLine swaps the status line engine and runs it once. An
+// interval refresh already scheduled picks up the new engine on its next
+// tick; otherwise a new one starts.
+func (m AppModel) reloadStatusLine(sl *config.StatusLineConfig) (AppModel, tea.Cmd) {
+	ticking := m.statusLineTick() != nil
+	m.deps.StatusEngine = nil
+	if sl != nil && sl.Command != "" {
+		m.deps.StatusEngine = statusline.New(sl.Command, sl.Padding).
+			WithRefresh(time.Duration(sl.RefreshInterval) * time.Second)
+	}
+	m.footer = m.footer.WithStatusLine("")
+	m, cmd := m.refreshStatusLine()
+	if !ticking {
+		cmd = tea.Batch(cmd, m.statusLineTick())
+	}
+	return m, cmd
+}
+
+// reloadModelOverrides re-applies overrides to the active model's built-in
+// definition, so a removed override reverts. The base URL stays: the
+// provider was built for it, and a new endpoint needs a restart.
+func (m AppModel) reloadModelOverrides(overrides map[string]config.ModelOverride) (AppModel, error) {
+	if m.deps.Model == nil {
+		return m, nil
+	}
+	base, err := config.ResolveModel(m.deps.Model.ID)
+	if err != nil {
+		return m, err
+	}
+	model := *base
+	model.CustomHeaders = maps.Clone(base.CustomHeaders)
+	config.ApplyModelOverrides(&model, &config.Settings{ModelOverrides: overrides})
+	model.BaseURL = m.deps.Model.BaseURL
+	m.deps.Model = &model
+	return m, nil
+}
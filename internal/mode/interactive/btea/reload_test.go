@@ -0,0 +1,168 @@
+// ABOUTME: Tests for settings reload: /reload and file changes re-apply theme, permissions, hooks, status line, model overrides
+// ABOUTME: Also checks that a reload during a turn waits for it and that a failed reload changes nothing
+
+package btea
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/config"
+	"github.com/mauromedda/pi-coding-agent-go/internal/permission"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/theme"
+)
+
+// reloadDeps returns deps whose settings reload to *next, and counts reloads.
+func reloadDeps(next **config.Settings, calls *int) AppDeps {
+	deps := testDeps()
+	deps.Settings = &config.Settings{}
+	deps.ReloadSettings = func() (*config.Settings, error) {
+		*calls++
+		return *next, nil
+	}
+	return deps
+}
+
+func TestAppModel_ReloadCommandAppliesSettings(t *testing.T) {
+	keepDefaultTheme(t)
+	fresh := &config.Settings{
+		Theme:       "monochrome",
+		Permissions: &config.PermissionsConfig{Deny: []string{"write"}},
+		Hooks:       map[string][]config.HookDef{"PostToolUse": {{Command: "gofmt -w"}}},
+		Keybindings: map[string][]string{"background": {"ctrl+x"}},
+	}
+	var calls int
+	deps := reloadDeps(&fresh, &calls)
+	deps.Checker = permission.NewChecker(permission.ModeYolo, nil)
+	m := NewAppModel(deps)
+
+	if err := deps.Checker.Check("write", map[string]any{"path": "a.go"}); err != nil {
+		t.Fatalf("write should be allowed before the reload: %v", err)
+	}
+	m, cmd := m.handleSlashCommand("/reload")
+	m = settle(m, cmd)
+
+	if theme.Current().Name != "monochrome" {
+		t.Errorf("theme = %q; want monochrome", theme.Current().Name)
+	}
+	if err := deps.Checker.Check("write", map[string]any{"path": "a.go"}); err == nil {
+		t.Error("the reloaded deny rule should block write")
+	}
+	if deps.Checker.Mode() != permission.ModeYolo {
+		t.Errorf("mode = %v; an unchanged defaultMode should keep the running mode", deps.Checker.Mode())
+	}
+	if m.deps.Permissions != fresh.Permissions {
+		t.Error("/permissions should show the reloaded block")
+	}
+	if len(m.deps.Hooks["PostToolUse"]) != 1 {
+		t.Errorf("hooks = %v; want the reloaded hook", m.deps.Hooks)
+	}
+	got := m.lastAssistantText()
+	for _, want := range []string{"Applied: hooks, permissions, theme", "Takes effect after a restart: keybindings"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("notice missing %q:\n%s", want, got)
+		}
+	}
+
+	m, _ = m.handleSlashCommand("/reload")
+	if got := m.lastAssistantText(); got != "Settings reloaded; nothing changed." {
+		t.Errorf("second /reload notice = %q", got)
+	}
+	if calls != 2 {
+		t.Errorf("reloads = %d; want 2", calls)
+	}
+}
+
+func TestAppModel_SettingsFileChangeReloads(t *testing.T) {
+	fresh := &config.Settings{StatusLine: &config.StatusLineConfig{Command: "echo main"}}
+	var calls int
+	m := NewAppModel(reloadDeps(&fresh, &calls))
+
+	result, _ := m.Update(SettingsChangedMsg{Section: "settings"})
+	m = result.(AppModel)
+	if !m.deps.StatusEngine.HasCommand() {
+		t.Error("the reloaded status line should have a command")
+	}
+	if got := m.lastAssistantText(); !strings.Contains(got, "Applied: statusLine") {
+		t.Errorf("notice = %q; want the status line listed", got)
+	}
+
+	before := len(m.content)
+	result, _ = m.Update(SettingsChangedMsg{Section: "settings"})
+	m = result.(AppModel)
+	if len(m.content) != before {
+		t.Error("a change that applies nothing should not add a notice")
+	}
+}
+
+func TestAppModel_ReloadWaitsForTurn(t *testing.T) {
+	fresh := &config.Settings{Hooks: map[string][]config.HookDef{"Stop": {{Command: "say done"}}}}
+	var calls int
+	m := NewAppModel(reloadDeps(&fresh, &calls))
+	m.agentRunning = true
+
+	result, _ := m.Update(SettingsChangedMsg{Section: "settings"})
+	m = result.(AppModel)
+	if calls != 0 || !m.reloadPending {
+		t.Fatalf("reloads = %d, pending = %v; want the reload deferred", calls, m.reloadPending)
+	}
+
+	result, _ = m.Update(AgentDoneMsg{})
+	m = result.(AppModel)
+	if calls != 1 || m.reloadPending || len(m.deps.Hooks["Stop"]) != 1 {
+		t.Errorf("reloads = %d, pending = %v, hooks = %v; want the reload applied when the turn ends", calls, m.reloadPending, m.deps.Hooks)
+	}
+}
+
+func TestAppModel_ReloadErrorKeepsSettings(t *testing.T) {
+	deps := testDeps()
+	deps.Settings = &config.Settings{Theme: "dark"}
+	deps.ReloadSettings = func() (*config.Settings, error) {
+		return nil, errors.New(`.pi-go/settings.json sets "model", which is locked`)
+	}
+	m := NewAppModel(deps)
+
+	m, _ = m.handleSlashCommand("/reload")
+	if got := m.lastAssistantText(); !strings.Contains(got, "Settings not reloaded") || !strings.Contains(got, "locked") {
+		t.Errorf("notice = %q; want the load error", got)
+	}
+	if m.deps.Settings.Theme != "dark" {
+		t.Error("a failed reload should keep the settings in effect")
+	}
+}
+
+func TestAppModel_ReloadModelOverrides(t *testing.T) {
+	model := ai.ModelClaude4Sonnet
+	model.BaseURL = "https://proxy.example"
+	fresh := &config.Settings{ModelOverrides: map[string]config.ModelOverride{
+		model.ID: {ContextWindow: 50000, BaseURL: "https://elsewhere.example"},
+	}}
+	var calls int
+	deps := reloadDeps(&fresh, &calls)
+	deps.Model = &model
+	m := NewAppModel(deps)
+
+	m, _ = m.handleSlashCommand("/reload")
+	if m.deps.Model.ContextWindow != 50000 {
+		t.Errorf("context window = %d; want the override", m.deps.Model.ContextWindow)
+	}
+	if m.deps.Model.BaseURL != "https://proxy.example" {
+		t.Errorf("base URL = %q; the provider's endpoint should stay until a restart", m.deps.Model.BaseURL)
+	}
+
+	fresh = &config.Settings{}
+	m, _ = m.handleSlashCommand("/reload")
+	if m.deps.Model.ContextWindow != ai.ModelClaude4Sonnet.ContextWindow {
+		t.Errorf("context window = %d; removing the override should revert it", m.deps.Model.ContextWindow)
+	}
+}
+
+func TestAppModel_ReloadUnavailable(t *testing.T) {
+	m := NewAppModel(testDeps())
+	m, _ = m.handleSlashCommand("/reload")
+	if got := m.lastAssistantText(); got != "Reload not available." {
+		t.Errorf("notice = %q", got)
+	}
+}
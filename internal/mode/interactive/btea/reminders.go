@@ -0,0 +1,36 @@
+// ABOUTME: Wires system reminder sources (plan mode, session budget, external edits) to the app's live state
+// ABOUTME: Sources read atomics in shared, which syncReminders refreshes after each state change
+
+package btea
+
+import (
+	"math"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/reminder"
+)
+
+// wireReminders registers the plan mode and budget sources on
+// deps.Reminders. They read sh, so they stay current across value copies.
+// Files changed outside the agent are dropped from the read cache.
+func wireReminders(sh *shared, deps AppDeps) {
+	if deps.Reminders == nil {
+		return
+	}
+	if files := deps.Reminders.Files(); files != nil {
+		files.OnChange(sh.readCache.Forget)
+	}
+	deps.Reminders.Add(reminder.PlanMode(sh.planMode.Load))
+	if deps.BudgetUSD > 0 {
+		spent := func() (float64, float64) {
+			return math.Float64frombits(sh.costBits.Load()), deps.BudgetUSD
+		}
+		deps.Reminders.Add(reminder.Budget(spent, float64(deps.BudgetWarnPct)/100))
+	}
+}
+
+// syncReminders publishes the mode and session cost to the reminder sources.
+func (m AppModel) syncReminders() AppModel {
+	m.sh.planMode.Store(m.mode == ModePlan)
+	m.sh.costBits.Store(math.Float64bits(m.footer.cost))
+	return m
+}
@@ -0,0 +1,92 @@
+// ABOUTME: Tests for reminder wiring: plan mode, session cost, and external edits reach their consumers
+// ABOUTME: Toggles the mode and feeds usage, then collects from the injector
+
+package btea
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/reminder"
+	"github.com/mauromedda/pi-coding-agent-go/internal/tools"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+func collectReminder(in *reminder.Injector) string {
+	msg, ok := in.Collect()
+	if !ok {
+		return ""
+	}
+	return msg.Content[0].Text
+}
+
+func TestReminders_PlanModeFollowsToggle(t *testing.T) {
+	deps := testDeps()
+	deps.Reminders = reminder.New()
+	m := NewAppModel(deps)
+
+	if got := collectReminder(deps.Reminders); got != "" {
+		t.Errorf("edit mode sent %q", got)
+	}
+	m.toggleMode() // publishes through shared state
+	if got := collectReminder(deps.Reminders); !strings.Contains(got, "Plan mode is active") {
+		t.Errorf("plan mode reminder = %q", got)
+	}
+}
+
+func TestReminders_BudgetFollowsCost(t *testing.T) {
+	deps := testDeps()
+	deps.Reminders = reminder.New()
+	deps.BudgetUSD = 1
+	deps.BudgetWarnPct = 50
+	m := NewAppModel(deps)
+
+	// The footer prices output at $15/M: 40k tokens is $0.60.
+	m.Update(AgentUsageMsg{Usage: &ai.Usage{OutputTokens: 40_000}})
+	if got := collectReminder(deps.Reminders); !strings.Contains(got, "Over 60% of the $1.00 session budget") {
+		t.Errorf("budget reminder = %q", got)
+	}
+}
+
+func TestReminders_ExternalEditForgetsRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(path, []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	deps := testDeps()
+	deps.Reminders = reminder.New()
+	deps.Reminders.TrackFiles()
+	m := NewAppModel(deps)
+
+	ctx := tools.WithReadCache(context.Background(), m.sh.readCache)
+	read := tools.NewReadTool()
+	args := map[string]any{"path": path}
+	if _, err := read.Execute(ctx, "r1", args, nil); err != nil {
+		t.Fatal(err)
+	}
+	deps.Reminders.ToolDone("read", args)
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if got := collectReminder(deps.Reminders); !strings.Contains(got, path+" changed on disk") {
+		t.Errorf("reminder = %q", got)
+	}
+	// Restore the recorded mtime: only the reminder can have dropped the entry.
+	if err := os.Chtimes(path, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+	res, _ := read.Execute(ctx, "r2", args, nil)
+	if res.Content != "package main\n" {
+		t.Errorf("read after an external edit = %q; want the content", res.Content)
+	}
+}
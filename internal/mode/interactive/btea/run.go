@@ -10,7 +10,11 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
+	"github.com/mauromedda/pi-coding-agent-go/internal/config"
 	"github.com/mauromedda/pi-coding-agent-go/internal/git"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/termcap"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/terminal"
 )
 
 // Run starts the Bubble Tea interactive app. Blocks until the user exits.
@@ -21,13 +25,27 @@ func Run(deps AppDeps) error {
 	// Bubble Tea input parser and appear as garbled text in the editor.
 	lipgloss.SetHasDarkBackground(true)
 
+	// Degrade glyphs and colors to what the terminal can actually render.
+	caps := termcap.Detect()
+	if deps.ASCII {
+		caps.Unicode = false
+	}
+	applyTermcap(caps)
+	toolOutputLines = deps.Terminal.EffectiveToolOutputLines()
+
 	m := NewAppModel(deps)
 
-	p := tea.NewProgram(
-		m,
-		tea.WithAltScreen(),
-		tea.WithOutput(os.Stderr),
-	)
+	// On a Unix terminal pi-go paints the screen itself and only sends the
+	// lines that changed; Bubble Tea then just reads input.
+	var model tea.Model = NewPaneSetModel(m)
+	opts := []tea.ProgramOption{tea.WithAltScreen(), tea.WithOutput(os.Stderr)}
+	if screenSupported() {
+		m.sh.screen = newScreen(terminal.NewProcessTerminal())
+		model = screenModel{Model: model, s: m.sh.screen}
+		opts = []tea.ProgramOption{tea.WithoutRenderer()}
+	}
+
+	p := tea.NewProgram(model, opts...)
 
 	// Inject the program reference into the shared state.
 	// Safe because NewAppModel allocates sh as a pointer and tea.NewProgram
@@ -37,15 +55,45 @@ func Run(deps AppDeps) error {
 	m.sh.bgManager = NewBackgroundManager(p)
 	defer m.sh.cancel() // cancel root context when program exits
 
+	// Plural-minion fan-outs show their progress in the background view.
+	if deps.Minion != nil {
+		deps.Minion.SetFanOutHook(func(fp agent.FanOutProgress) { p.Send(MinionFanOutMsg{Progress: fp}) })
+		defer deps.Minion.SetFanOutHook(nil)
+	}
+
+	if scr := m.sh.screen; scr != nil {
+		resize := func(w, h int) { p.Send(tea.WindowSizeMsg{Width: w, Height: h}) }
+		if err := scr.open(resize, screenFrameInterval); err != nil {
+			return err
+		}
+		defer scr.close()
+	}
+
+	// Edits to the settings files apply without a restart, like /reload.
+	if deps.ReloadSettings != nil && len(deps.SettingsFiles) > 0 {
+		w := config.NewWatcher(deps.SettingsFiles, func() { p.Send(SettingsChangedMsg{Section: "settings"}) })
+		w.Start()
+		defer w.Stop()
+	}
+
+	// Turn external SIGTSTP into a clean suspend (Ctrl+Z arrives as a key in raw mode).
+	stopSuspendWatch := watchSuspendSignals(p)
+	defer stopSuspendWatch()
+
 	finalModel, err := p.Run()
 	if err != nil {
 		return fmt.Errorf("bubble tea: %w", err)
 	}
 
-	// Handle worktree cleanup after the program exits.
-	if deps.WorktreeSession != nil {
-		if appModel, ok := finalModel.(AppModel); ok {
-			handleWorktreeExit(deps.WorktreeSession, appModel.worktreeExitAction)
+	// Panes opened with /split end with the program; the first pane's
+	// worktree cleanup runs after it exits.
+	if sm, ok := finalModel.(screenModel); ok {
+		finalModel = sm.Model
+	}
+	if set, ok := finalModel.(PaneSetModel); ok {
+		set.closeSplits()
+		if deps.WorktreeSession != nil {
+			handleWorktreeExit(deps.WorktreeSession, set.panes[0].worktreeExitAction)
 		}
 	}
 
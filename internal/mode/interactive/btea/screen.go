@@ -0,0 +1,276 @@
+// ABOUTME: Diff-rendered screen: pi-go owns the terminal and paints each View through render.Renderer
+// ABOUTME: Bubble Tea runs without its renderer and only reads input; suspend and $EDITOR hand the terminal back
+
+package btea
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"golang.org/x/term"
+
+	pilog "github.com/mauromedda/pi-coding-agent-go/internal/log"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/render"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/terminal"
+)
+
+// screenFrameInterval caps how often frames are painted, like Bubble Tea's
+// own 60 fps renderer.
+const screenFrameInterval = time.Second / 60
+
+const (
+	screenEnter = "\x1b[?1049h\x1b[?25l\x1b[?2004h" // alt screen, hide cursor, bracketed paste
+	screenLeave = "\x1b[?2004l\x1b[?25h\x1b[?1049l"
+)
+
+// screen paints the program's frames on a terminal it puts in raw mode and
+// the alternate screen. Only the regions that changed since the previous
+// frame are written, which keeps long histories cheap over SSH.
+type screen struct {
+	term     terminal.Terminal
+	onResize func(width, height int)
+
+	mu     sync.Mutex
+	r      *render.Renderer
+	frame  string
+	dirty  bool
+	active bool // false while another process has the terminal
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// screenSupported reports whether pi-go can drive the terminal itself:
+// stdin and stdout must be terminals, and Windows consoles need Bubble
+// Tea's own input setup.
+func screenSupported() bool {
+	return runtime.GOOS != "windows" &&
+		term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// newScreen returns a screen for t; nothing is written until open.
+func newScreen(t terminal.Terminal) *screen {
+	return &screen{term: t, r: render.New(t)}
+}
+
+// open takes over the terminal and starts painting every interval.
+// onResize is called with the initial size and on every resize, so the
+// program can be sent a tea.WindowSizeMsg.
+func (s *screen) open(onResize func(width, height int), interval time.Duration) error {
+	s.onResize = onResize
+	if err := s.acquire(); err != nil {
+		return err
+	}
+	s.term.OnResize(s.resize)
+	s.stop, s.done = make(chan struct{}), make(chan struct{})
+	go s.loop(interval)
+	return nil
+}
+
+// close stops painting and gives the terminal back to the shell. Calling
+// it again does nothing.
+func (s *screen) close() {
+	if s == nil || s.stop == nil {
+		return
+	}
+	close(s.stop)
+	<-s.done
+	s.stop = nil
+	if err := s.release(); err != nil {
+		pilog.Debug("screen: %v", err)
+	}
+	st := s.r.Stats()
+	pilog.Debug("screen: %d frames, %d lines rewritten, %d bytes", st.Frames, st.Lines, st.Bytes)
+}
+
+// paint queues frame for the next tick.
+func (s *screen) paint(frame string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if frame != s.frame {
+		s.frame, s.dirty = frame, true
+	}
+}
+
+// resize adopts a new terminal size and tells the program.
+func (s *screen) resize(width, height int) {
+	s.mu.Lock()
+	s.r.Resize(width, height)
+	s.dirty = true
+	s.mu.Unlock()
+	if s.onResize != nil {
+		go s.onResize(width, height)
+	}
+}
+
+// release hands the terminal to another process: painting pauses and the
+// shell's screen, cursor, and line mode come back.
+func (s *screen) release() error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.active {
+		return nil
+	}
+	s.active = false
+	if _, err := io.WriteString(s.term, screenLeave); err != nil {
+		return fmt.Errorf("leave screen: %w", err)
+	}
+	if err := s.term.ExitRawMode(); err != nil {
+		return fmt.Errorf("leave screen: %w", err)
+	}
+	return nil
+}
+
+// restore takes the terminal back after release and repaints it in full,
+// at its current size, which may have changed in the meantime.
+func (s *screen) restore() error {
+	if s == nil {
+		return nil
+	}
+	if err := s.acquire(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.r.Invalidate()
+	s.mu.Unlock()
+	return nil
+}
+
+// refresh takes the terminal back and repaints it in full while the screen
+// is active, e.g. on SIGCONT after the shell reset the terminal behind
+// pi-go's back. A released screen is left to whoever released it.
+func (s *screen) refresh() error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	active := s.active
+	s.mu.Unlock()
+	if !active {
+		return nil
+	}
+	return s.restore()
+}
+
+// acquire enters raw mode and the alternate screen and reads the size.
+func (s *screen) acquire() error {
+	if err := s.term.EnterRawMode(); err != nil {
+		return fmt.Errorf("open screen: %w", err)
+	}
+	if _, err := io.WriteString(s.term, screenEnter); err != nil {
+		return fmt.Errorf("open screen: %w", err)
+	}
+	s.mu.Lock()
+	s.active, s.dirty = true, true
+	s.mu.Unlock()
+	if w, h, err := s.term.Size(); err == nil {
+		s.resize(w, h)
+	}
+	return nil
+}
+
+// loop paints the latest frame every interval until close.
+func (s *screen) loop(interval time.Duration) {
+	defer close(s.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			s.flush()
+			return
+		case <-ticker.C:
+			s.flush()
+		}
+	}
+}
+
+// flush paints the queued frame, if any.
+func (s *screen) flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.dirty || !s.active {
+		return
+	}
+	s.dirty = false
+	if err := s.r.Render(s.frame); err != nil {
+		pilog.Debug("screen: %v", err)
+	}
+}
+
+// screenModel wraps the root model so its View goes to the screen. Bubble
+// Tea still calls View after every update; it gets an empty string back,
+// since its renderer is disabled.
+type screenModel struct {
+	tea.Model
+	s *screen
+}
+
+// Update forwards to the wrapped model.
+func (m screenModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.Model.Update(msg)
+	m.Model = next
+	return m, cmd
+}
+
+// View queues the wrapped model's frame for painting.
+func (m screenModel) View() string {
+	m.s.paint(m.Model.View())
+	return ""
+}
+
+// execProcess runs cmd in the foreground like tea.ExecProcess, handing the
+// screen to it for the duration when pi-go paints the terminal itself.
+func (m AppModel) execProcess(cmd *exec.Cmd, fn tea.ExecCallback) tea.Cmd {
+	if m.sh.screen == nil {
+		return tea.ExecProcess(cmd, fn)
+	}
+	return tea.Exec(&screenExec{cmd: cmd, s: m.sh.screen}, fn)
+}
+
+// screenExec is a tea.ExecCommand that releases the screen around cmd.
+type screenExec struct {
+	cmd *exec.Cmd
+	s   *screen
+}
+
+// Run releases the screen, runs the command, and takes the screen back.
+func (e *screenExec) Run() error {
+	if err := e.s.release(); err != nil {
+		return err
+	}
+	err := e.cmd.Run()
+	if rerr := e.s.restore(); err == nil {
+		err = rerr
+	}
+	return err
+}
+
+// SetStdin sets the command's stdin unless it already has one.
+func (e *screenExec) SetStdin(r io.Reader) {
+	if e.cmd.Stdin == nil {
+		e.cmd.Stdin = r
+	}
+}
+
+// SetStdout sets the command's stdout unless it already has one.
+func (e *screenExec) SetStdout(w io.Writer) {
+	if e.cmd.Stdout == nil {
+		e.cmd.Stdout = w
+	}
+}
+
+// SetStderr sets the command's stderr unless it already has one.
+func (e *screenExec) SetStderr(w io.Writer) {
+	if e.cmd.Stderr == nil {
+		e.cmd.Stderr = w
+	}
+}
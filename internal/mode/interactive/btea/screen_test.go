@@ -0,0 +1,205 @@
+// ABOUTME: Tests for the diff-rendered screen: terminal hand-over, resize, and painting through screenModel
+// ABOUTME: Uses a VirtualTerminal and paints with explicit flushes instead of the frame ticker
+
+package btea
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/terminal"
+)
+
+// openTestScreen opens a screen on a virtual terminal whose ticker never
+// fires, recording the sizes reported to the program.
+func openTestScreen(t *testing.T) (*screen, *terminal.VirtualTerminal, chan tea.WindowSizeMsg) {
+	t.Helper()
+	vt := terminal.NewVirtualTerminal(20, 4)
+	sizes := make(chan tea.WindowSizeMsg, 4)
+	s := newScreen(vt)
+	if err := s.open(func(w, h int) { sizes <- tea.WindowSizeMsg{Width: w, Height: h} }, time.Hour); err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(s.close)
+	return s, vt, sizes
+}
+
+// stubView is a model whose View is its text.
+type stubView struct{ text string }
+
+func (m stubView) Init() tea.Cmd { return nil }
+
+func (m stubView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if k, ok := msg.(tea.KeyMsg); ok {
+		m.text += k.String()
+	}
+	return m, nil
+}
+
+func (m stubView) View() string { return m.text }
+
+func TestScreen_OpenTakesTheTerminal(t *testing.T) {
+	s, vt, sizes := openTestScreen(t)
+	if !vt.IsRawMode() || !strings.HasPrefix(vt.Output(), screenEnter) {
+		t.Errorf("open should enter raw mode and the alt screen; output %q", vt.Output())
+	}
+	if got := <-sizes; got.Width != 20 || got.Height != 4 {
+		t.Errorf("initial size = %+v; want 20x4", got)
+	}
+
+	s.close()
+	if vt.IsRawMode() || !strings.HasSuffix(vt.Output(), screenLeave) {
+		t.Errorf("close should restore the terminal; output %q", vt.Output())
+	}
+}
+
+func TestScreen_PaintsOnlyChanges(t *testing.T) {
+	s, vt, _ := openTestScreen(t)
+	vt.Reset()
+
+	var model tea.Model = screenModel{Model: stubView{text: "> "}, s: s}
+	model.View()
+	s.flush()
+	if out := vt.Output(); !strings.Contains(out, "\x1b[1;1H> ") {
+		t.Fatalf("first frame not painted; got %q", out)
+	}
+
+	vt.Reset()
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("l")})
+	if got := model.View(); got != "" {
+		t.Errorf("screenModel.View should leave Bubble Tea nothing to draw; got %q", got)
+	}
+	s.flush()
+	if out := vt.Output(); !strings.Contains(out, "\x1b[1;3Hl") || strings.Contains(out, ">") {
+		t.Errorf("only the typed character should be sent; got %q", out)
+	}
+
+	vt.Reset()
+	model.View()
+	s.flush()
+	if out := vt.Output(); out != "" {
+		t.Errorf("an unchanged view should write nothing; got %q", out)
+	}
+}
+
+func TestScreen_ResizeRepaints(t *testing.T) {
+	s, vt, sizes := openTestScreen(t)
+	<-sizes
+	s.paint("hello")
+	s.flush()
+	vt.Reset()
+
+	vt.SetSize(30, 10)
+	if got := <-sizes; got.Width != 30 || got.Height != 10 {
+		t.Errorf("resize reported %+v; want 30x10", got)
+	}
+	s.flush()
+	if out := vt.Output(); !strings.Contains(out, "\x1b[1;1Hhello") {
+		t.Errorf("resize should repaint the frame; got %q", out)
+	}
+}
+
+func TestScreen_ReleasePausesPainting(t *testing.T) {
+	s, vt, _ := openTestScreen(t)
+	s.paint("frame")
+	s.flush()
+
+	if err := s.release(); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	vt.Reset()
+	s.paint("changed while away")
+	s.flush()
+	if out := vt.Output(); out != "" {
+		t.Errorf("a released screen must not paint; got %q", out)
+	}
+
+	if err := s.restore(); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	s.flush()
+	out := vt.Output()
+	if !vt.IsRawMode() || !strings.HasPrefix(out, screenEnter) || !strings.Contains(out, "\x1b[1;1Hchanged while away") {
+		t.Errorf("restore should re-enter the screen and repaint in full; got %q", out)
+	}
+}
+
+func TestScreenExec_ReleasesAroundCommand(t *testing.T) {
+	s, vt, _ := openTestScreen(t)
+	vt.Reset()
+
+	e := &screenExec{cmd: exec.Command("true"), s: s}
+	if err := e.Run(); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	out := vt.Output()
+	if !strings.HasPrefix(out, screenLeave) || !strings.Contains(out, screenEnter) {
+		t.Errorf("the command should run outside the screen; got %q", out)
+	}
+	if vt.ExitCount() != 1 || !vt.IsRawMode() {
+		t.Errorf("raw mode should be left for the command and restored; exits=%d raw=%v", vt.ExitCount(), vt.IsRawMode())
+	}
+}
+
+func TestScreen_RefreshRepaintsInFull(t *testing.T) {
+	s, vt, _ := openTestScreen(t)
+	s.paint("frame")
+	s.flush()
+	vt.Reset()
+
+	if err := s.refresh(); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	s.flush()
+	out := vt.Output()
+	if !vt.IsRawMode() || !strings.HasPrefix(out, screenEnter) || !strings.Contains(out, "\x1b[1;1Hframe") {
+		t.Errorf("refresh should re-enter the screen and repaint the unchanged frame; got %q", out)
+	}
+	if vt.ExitCount() != 0 {
+		t.Errorf("refresh must not leave raw mode; exits=%d", vt.ExitCount())
+	}
+}
+
+func TestScreen_RefreshLeavesReleasedScreen(t *testing.T) {
+	s, vt, _ := openTestScreen(t)
+	if err := s.release(); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	vt.Reset()
+	enters := vt.EnterCount()
+
+	if err := s.refresh(); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if vt.Output() != "" || vt.EnterCount() != enters {
+		t.Errorf("a released screen belongs to another process; got %q, enters %d -> %d", vt.Output(), enters, vt.EnterCount())
+	}
+}
+
+func TestAppModel_ContinuedRepaintsUnlessSelfSuspended(t *testing.T) {
+	s, vt, _ := openTestScreen(t)
+	m := NewAppModel(testDeps())
+	m.sh.screen = s
+
+	m.sh.suspended.Store(true)
+	if cmd := m.continued(); cmd != nil {
+		t.Error("the SIGCONT ending our own suspend should be left to suspendCmd")
+	}
+	if m.sh.suspended.Load() {
+		t.Error("continued should clear the suspended flag")
+	}
+
+	vt.Reset()
+	cmd := m.continued()
+	if cmd == nil {
+		t.Fatal("an external SIGCONT should repaint")
+	}
+	cmd()
+	if !strings.HasPrefix(vt.Output(), screenEnter) {
+		t.Errorf("repaint should re-enter the alt screen; got %q", vt.Output())
+	}
+}
@@ -1,5 +1,5 @@
 // ABOUTME: SessionSelectorModel is a Bubble Tea overlay for selecting a session to resume
-// ABOUTME: Returns SessionSelectedMsg on enter, SessionSelectorDismissMsg on esc
+// ABOUTME: Typing filters by title, ID, model, and cwd, plus full-text matches from an async session search
 
 package btea
 
@@ -13,6 +13,7 @@ import (
 // SessionEntry represents a session available for resumption.
 type SessionEntry struct {
 	ID    string
+	Title string // "" when the session has no title yet
 	Model string
 	CWD   string
 }
@@ -23,21 +24,47 @@ type SessionSelectedMsg struct{ Session SessionEntry }
 // SessionSelectorDismissMsg is returned when the user dismisses the selector.
 type SessionSelectorDismissMsg struct{}
 
+// SessionSearchResultMsg carries the IDs of sessions whose transcript
+// contains Query, from the selector's full-text search.
+type SessionSearchResultMsg struct {
+	Query string
+	IDs   map[string]bool
+}
+
+// SessionSearchFunc returns the IDs of sessions whose transcript contains query.
+type SessionSearchFunc func(query string) (map[string]bool, error)
+
+// minFullTextQuery is the shortest query sent to the full-text search;
+// shorter ones match on title, ID, model, and cwd only.
+const minFullTextQuery = 2
+
 // SessionSelectorModel displays a list of sessions for selection.
 // Implements tea.Model with value semantics.
 type SessionSelectorModel struct {
 	sessions []SessionEntry
-	selected int
+	shown    []SessionEntry // sessions matching query
+	selected int            // index into shown
 	width    int
+
+	query    string
+	search   SessionSearchFunc // nil = filter on metadata only
+	textHits map[string]bool   // full-text matches for query
 }
 
 // NewSessionSelectorModel creates a SessionSelectorModel with the given sessions.
 func NewSessionSelectorModel(sessions []SessionEntry) SessionSelectorModel {
 	return SessionSelectorModel{
 		sessions: sessions,
+		shown:    sessions,
 	}
 }
 
+// WithSearch enables full-text filtering through search as the user types.
+func (m SessionSelectorModel) WithSearch(search SessionSearchFunc) SessionSelectorModel {
+	m.search = search
+	return m
+}
+
 // Init returns nil; no commands needed at startup.
 func (m SessionSelectorModel) Init() tea.Cmd {
 	return nil
@@ -53,13 +80,27 @@ func (m SessionSelectorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case tea.KeyDown:
 			m.moveDown()
 		case tea.KeyEnter:
-			if len(m.sessions) == 0 {
+			if len(m.shown) == 0 {
 				return m, nil
 			}
-			selected := m.sessions[m.selected]
+			selected := m.shown[m.selected]
 			return m, func() tea.Msg { return SessionSelectedMsg{Session: selected} }
 		case tea.KeyEsc:
+			if m.query != "" {
+				return m.setQuery("")
+			}
 			return m, func() tea.Msg { return SessionSelectorDismissMsg{} }
+		case tea.KeyBackspace:
+			if r := []rune(m.query); len(r) > 0 {
+				return m.setQuery(string(r[:len(r)-1]))
+			}
+		case tea.KeyRunes, tea.KeySpace:
+			return m.setQuery(m.query + string(msg.Runes))
+		}
+	case SessionSearchResultMsg:
+		if msg.Query == m.query {
+			m.textHits = msg.IDs
+			m.refilter()
 		}
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
@@ -81,7 +122,19 @@ func (m SessionSelectorModel) View() string {
 		return b.String()
 	}
 
-	for i, sess := range m.sessions {
+	if m.query == "" {
+		b.WriteString(s.Muted.Render("  type to search"))
+	} else {
+		b.WriteString(fmt.Sprintf("  Search: %s  %s", m.query, s.Muted.Render(fmt.Sprintf("(%d of %d)", len(m.shown), len(m.sessions)))))
+	}
+	b.WriteByte('\n')
+
+	if len(m.shown) == 0 {
+		b.WriteString(s.Muted.Render("\n  No matching sessions"))
+		return b.String()
+	}
+
+	for i, sess := range m.shown {
 		b.WriteByte('\n')
 
 		var prefix string
@@ -92,6 +145,9 @@ func (m SessionSelectorModel) View() string {
 		}
 
 		line := fmt.Sprintf("%s%s  %s  %s", prefix, sess.ID, s.Muted.Render(sess.Model), s.Dim.Render(sess.CWD))
+		if sess.Title != "" {
+			line = fmt.Sprintf("%s%s  %s  %s", prefix, sess.Title, s.Muted.Render(sess.ID+" · "+sess.Model), s.Dim.Render(sess.CWD))
+		}
 		if i == m.selected {
 			line = s.Bold.Render(s.Selection.Render(line))
 		}
@@ -110,7 +166,50 @@ func (m *SessionSelectorModel) moveUp() {
 }
 
 func (m *SessionSelectorModel) moveDown() {
-	if m.selected < len(m.sessions)-1 {
+	if m.selected < len(m.shown)-1 {
 		m.selected++
 	}
 }
+
+// setQuery filters on the new query at once and, when full-text search is
+// enabled, starts a search whose result arrives as SessionSearchResultMsg.
+func (m SessionSelectorModel) setQuery(q string) (tea.Model, tea.Cmd) {
+	m.query = q
+	m.textHits = nil
+	m.refilter()
+	if m.search == nil || len([]rune(strings.TrimSpace(q))) < minFullTextQuery {
+		return m, nil
+	}
+	search := m.search
+	return m, func() tea.Msg {
+		ids, _ := search(q)
+		return SessionSearchResultMsg{Query: q, IDs: ids}
+	}
+}
+
+// refilter rebuilds shown from query and the full-text hits, keeping the
+// selection on the same session when it is still shown.
+func (m *SessionSelectorModel) refilter() {
+	var current string
+	if m.selected < len(m.shown) {
+		current = m.shown[m.selected].ID
+	}
+	q := strings.ToLower(strings.TrimSpace(m.query))
+	if q == "" {
+		m.shown = m.sessions
+	} else {
+		m.shown = nil
+		for _, sess := range m.sessions {
+			meta := strings.ToLower(sess.Title + " " + sess.ID + " " + sess.Model + " " + sess.CWD)
+			if strings.Contains(meta, q) || m.textHits[sess.ID] {
+				m.shown = append(m.shown, sess)
+			}
+		}
+	}
+	m.selected = 0
+	for i, sess := range m.shown {
+		if sess.ID == current {
+			m.selected = i
+		}
+	}
+}
@@ -166,3 +166,109 @@ func TestSessionSelectorModel_EnterEmptyList(t *testing.T) {
 		t.Errorf("Update(enter) on empty list returned non-nil cmd; want nil")
 	}
 }
+
+// typeQuery types text into the selector and returns the last command.
+func typeQuery(m SessionSelectorModel, text string) (SessionSelectorModel, tea.Cmd) {
+	var cmd tea.Cmd
+	for _, r := range text {
+		var updated tea.Model
+		updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		m = updated.(SessionSelectorModel)
+	}
+	return m, cmd
+}
+
+func shownIDs(m SessionSelectorModel) []string {
+	var ids []string
+	for _, s := range m.shown {
+		ids = append(ids, s.ID)
+	}
+	return ids
+}
+
+func TestSessionSelectorModel_FiltersOnMetadata(t *testing.T) {
+	m, cmd := typeQuery(NewSessionSelectorModel(testSessions()), "project")
+	if cmd != nil {
+		t.Error("no search command expected without a search function")
+	}
+	if got := shownIDs(m); len(got) != 2 || got[0] != "sess-001" || got[1] != "sess-002" {
+		t.Errorf("shown = %v; want sess-001, sess-002", got)
+	}
+	if !strings.Contains(m.View(), "Search: project") || !strings.Contains(m.View(), "(2 of 3)") {
+		t.Errorf("view missing search line:\n%s", m.View())
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m = updated.(SessionSelectorModel)
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if sel := cmd().(SessionSelectedMsg); sel.Session.ID != "sess-002" {
+		t.Errorf("selected %q; want sess-002 from the filtered list", sel.Session.ID)
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	m = updated.(SessionSelectorModel)
+	if m.query != "projec" {
+		t.Errorf("query after backspace = %q", m.query)
+	}
+	updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(SessionSelectorModel)
+	if m.query != "" || len(m.shown) != 3 || cmd != nil {
+		t.Errorf("esc should clear the query first; query = %q, shown = %d", m.query, len(m.shown))
+	}
+}
+
+func TestSessionSelectorModel_FullTextSearch(t *testing.T) {
+	var queries []string
+	search := func(q string) (map[string]bool, error) {
+		queries = append(queries, q)
+		return map[string]bool{"sess-003": true}, nil
+	}
+	m, cmd := typeQuery(NewSessionSelectorModel(testSessions()).WithSearch(search), "flaky")
+	if len(m.shown) != 0 {
+		t.Errorf("shown before search result = %v", shownIDs(m))
+	}
+	if cmd == nil {
+		t.Fatal("typing should start a full-text search")
+	}
+	result := cmd()
+
+	// A result for an older query is ignored.
+	updated, _ := m.Update(SessionSearchResultMsg{Query: "fla", IDs: map[string]bool{"sess-001": true}})
+	m = updated.(SessionSelectorModel)
+	if len(m.shown) != 0 {
+		t.Errorf("stale result applied: %v", shownIDs(m))
+	}
+
+	updated, _ = m.Update(result)
+	m = updated.(SessionSelectorModel)
+	if got := shownIDs(m); len(got) != 1 || got[0] != "sess-003" {
+		t.Errorf("shown = %v; want sess-003", got)
+	}
+	if queries[len(queries)-1] != "flaky" {
+		t.Errorf("searched %q", queries)
+	}
+}
+
+func TestSessionSelectorModel_NoMatches(t *testing.T) {
+	m, _ := typeQuery(NewSessionSelectorModel(testSessions()), "zzz")
+	if !strings.Contains(m.View(), "No matching sessions") {
+		t.Errorf("view:\n%s", m.View())
+	}
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd != nil {
+		t.Error("enter with no matches should do nothing")
+	}
+}
+
+func TestSessionSelectorModel_ShowsAndFiltersTitles(t *testing.T) {
+	sessions := testSessions()
+	sessions[2].Title = "Fix flaky retry test"
+	m := NewSessionSelectorModel(sessions)
+	if view := m.View(); !strings.Contains(view, "Fix flaky retry test") || !strings.Contains(view, "sess-003") {
+		t.Errorf("view should show the title and the ID:\n%s", view)
+	}
+
+	m, _ = typeQuery(m, "flaky")
+	if got := shownIDs(m); len(got) != 1 || got[0] != "sess-003" {
+		t.Errorf("shown = %v; want sess-003 matched by title", got)
+	}
+}
@@ -0,0 +1,64 @@
+// ABOUTME: Automatic session titles: after the first turns, and after compaction, a model names the session
+// ABOUTME: Uses the minion model when configured; titles the user set with /rename are kept
+
+package btea
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mauromedda/pi-coding-agent-go/internal/session"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+const (
+	titleAfterTurns = 2 // user turns before a session is first titled
+	titleTimeout    = 30 * time.Second
+)
+
+// titleSession starts generating a title for the saved session. Without
+// refresh it only runs once the session has titleAfterTurns user turns and
+// no title yet; with refresh (after /compact) it replaces a generated one.
+func (m AppModel) titleSession(refresh bool) (AppModel, tea.Cmd) {
+	sess := m.deps.Session
+	if sess == nil || sess.TitleSet || m.titling {
+		return m, nil
+	}
+	if !refresh && (sess.Title != "" || userTurns(m.messages) < titleAfterTurns) {
+		return m, nil
+	}
+	provider, model := m.sideModel()
+	if provider == nil || model == nil || len(m.messages) == 0 {
+		return m, nil
+	}
+	m.titling = true
+	id, msgs := sess.ID, slices.Clone(m.messages)
+	return m, func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), titleTimeout)
+		defer cancel()
+		title, err := session.GenerateTitle(ctx, provider, model, msgs)
+		return SessionTitleMsg{SessionID: id, Title: title, Err: err}
+	}
+}
+
+// sideModel returns the model for side calls such as titles and
+// suggestions: the minion when one is configured, else the main model.
+func (m AppModel) sideModel() (ai.ApiProvider, *ai.Model) {
+	if mn := m.deps.Minion; mn != nil && mn.Provider != nil && mn.Model != nil {
+		return mn.Provider, mn.Model
+	}
+	return m.deps.Provider, m.deps.Model
+}
+
+// userTurns counts the prompts in msgs, skipping tool results and reminders.
+func userTurns(msgs []ai.Message) int {
+	n := 0
+	for _, msg := range msgs {
+		if msg.Role == ai.RoleUser && !msg.Ephemeral && hasText(msg) {
+			n++
+		}
+	}
+	return n
+}
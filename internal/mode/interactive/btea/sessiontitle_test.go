@@ -0,0 +1,129 @@
+// ABOUTME: Tests for automatic session titles: when they are generated, which model runs, /rename precedence
+// ABOUTME: Uses a saved temp session and a stub provider that replies with a fixed title
+
+package btea
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
+	"github.com/mauromedda/pi-coding-agent-go/internal/session"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+// titleStubProvider replies with title and counts calls.
+type titleStubProvider struct {
+	title string
+	calls int
+}
+
+func (p *titleStubProvider) Api() ai.Api { return ai.ApiAnthropic }
+
+func (p *titleStubProvider) Stream(context.Context, *ai.Model, *ai.Context, *ai.StreamOptions) *ai.EventStream {
+	p.calls++
+	stream := ai.NewEventStream(4)
+	go func() {
+		stream.Send(ai.StreamEvent{Type: ai.EventContentDelta, Text: p.title})
+		stream.Finish(&ai.AssistantMessage{})
+	}()
+	return stream
+}
+
+// finishTurn delivers AgentDoneMsg for the current conversation and runs
+// the title command it returns, if any.
+func finishTurn(t *testing.T, m AppModel) (AppModel, bool) {
+	t.Helper()
+	m.agentRunning = true
+	result, cmd := m.Update(AgentDoneMsg{Messages: m.messages})
+	m = result.(AppModel)
+	if cmd == nil {
+		return m, false
+	}
+	raw := cmd()
+	msg, ok := raw.(SessionTitleMsg)
+	if !ok {
+		t.Fatalf("command returned %T; want SessionTitleMsg", raw)
+	}
+	result, _ = m.Update(msg)
+	return result.(AppModel), true
+}
+
+func TestAppModel_TitlesSessionAfterTurns(t *testing.T) {
+	m := forkTestModel(t)
+	main := &titleStubProvider{title: "Main model title"}
+	m.deps.Provider = main
+
+	short := m
+	short.messages = m.messages[:2]
+	if _, titled := finishTurn(t, short); titled {
+		t.Error("a one-turn session should not be titled yet")
+	}
+
+	m, titled := finishTurn(t, m)
+	if !titled || m.deps.Session.Title != "Main model title" || m.titling {
+		t.Fatalf("titled = %v, Title = %q, titling = %v", titled, m.deps.Session.Title, m.titling)
+	}
+	if _, again := finishTurn(t, m); again {
+		t.Error("a titled session should not be retitled after every turn")
+	}
+
+	sessions, err := session.ListSessionsInDir(m.sessionsDir())
+	if err != nil || len(sessions) != 1 || sessions[0].Title != "Main model title" {
+		t.Errorf("listed sessions = %+v, %v; want the saved title", sessions, err)
+	}
+	ctx, _ := m.buildCommandContext()
+	if listing := ctx.ListSessionsFn(); !strings.Contains(listing, `"Main model title"`) {
+		t.Errorf("/resume listing does not show the title:\n%s", listing)
+	}
+}
+
+func TestAppModel_TitleUsesMinion(t *testing.T) {
+	m := forkTestModel(t)
+	main := &titleStubProvider{title: "Main"}
+	minion := &titleStubProvider{title: "Minion title"}
+	m.deps.Provider = main
+	m.deps.Minion = agent.NewMinion(minion, &ai.Model{Name: "small"}, agent.MinionOff)
+
+	m, _ = finishTurn(t, m)
+	if main.calls != 0 || minion.calls != 1 || m.deps.Session.Title != "Minion title" {
+		t.Errorf("main calls = %d, minion calls = %d, title = %q", main.calls, minion.calls, m.deps.Session.Title)
+	}
+}
+
+func TestAppModel_RenameKeepsUserTitle(t *testing.T) {
+	m := forkTestModel(t)
+	p := &titleStubProvider{title: "Generated"}
+	m.deps.Provider = p
+
+	m, _ = m.handleSlashCommand("/rename Parser rewrite")
+	if m.deps.Session.Title != "Parser rewrite" || !m.deps.Session.TitleSet {
+		t.Fatalf("Title = %q, TitleSet = %v", m.deps.Session.Title, m.deps.Session.TitleSet)
+	}
+	if _, titled := finishTurn(t, m); titled || p.calls != 0 {
+		t.Error("a renamed session should not be titled automatically")
+	}
+	result, _ := m.Update(CompactDoneMsg{Messages: m.messages})
+	if result.(AppModel).deps.Session.Title != "Parser rewrite" || p.calls != 0 {
+		t.Error("compaction should not replace a title set with /rename")
+	}
+}
+
+func TestAppModel_CompactRefreshesTitle(t *testing.T) {
+	m := forkTestModel(t)
+	p := &titleStubProvider{title: "First title"}
+	m.deps.Provider = p
+	m, _ = finishTurn(t, m)
+
+	p.title = "After compaction"
+	result, cmd := m.Update(CompactDoneMsg{Messages: m.messages})
+	m = result.(AppModel)
+	if cmd == nil {
+		t.Fatal("compaction should refresh the title")
+	}
+	result, _ = m.Update(cmd())
+	if got := result.(AppModel).deps.Session.Title; got != "After compaction" {
+		t.Errorf("Title = %q; want the refreshed title", got)
+	}
+}
@@ -20,6 +20,9 @@ type SessionNode struct {
 	Children []*SessionNode
 	Level    int
 	IsBranch bool
+	Forked   bool // created by /fork from ParentID
+	ForkTurn int  // user turns the fork kept from its parent
+	Current  bool // the session the TUI is on
 }
 
 // SessionTreeModel displays a tree of sessions with filter and navigation.
@@ -98,6 +101,15 @@ func (m SessionTreeModel) View() string {
 	return b.String()
 }
 
+// Text renders the visible nodes as plain lines, without selection styling.
+func (m SessionTreeModel) Text() string {
+	lines := make([]string, len(m.flat))
+	for i, node := range m.flat {
+		lines[i] = formatTreeNode(node, m.flat, i)
+	}
+	return strings.Join(lines, "\n")
+}
+
 // SetFilter sets the fuzzy filter string and rebuilds visible nodes. Returns a new model.
 func (m SessionTreeModel) SetFilter(f string) SessionTreeModel {
 	m.filter = f
@@ -201,9 +213,9 @@ func formatTreeNode(node *SessionNode, flat []*SessionNode, idx int) string {
 		// Determine if this is the last child at its level
 		isLast := isLastSibling(flat, idx)
 		if isLast {
-			prefix = indent + "└── "
+			prefix = indent + glyphs.TreeEnd
 		} else {
-			prefix = indent + "├── "
+			prefix = indent + glyphs.TreeMid
 		}
 	}
 
@@ -212,7 +224,15 @@ func formatTreeNode(node *SessionNode, flat []*SessionNode, idx int) string {
 		countStr = fmt.Sprintf(" (%d)", node.Count)
 	}
 
-	return fmt.Sprintf("%s%s [%s]%s", prefix, node.ID, node.Model, countStr)
+	var notes string
+	if node.Forked {
+		notes += fmt.Sprintf(" forked after turn %d", node.ForkTurn)
+	}
+	if node.Current {
+		notes += " (current)"
+	}
+
+	return fmt.Sprintf("%s%s [%s]%s%s", prefix, node.ID, node.Model, countStr, notes)
 }
 
 // isLastSibling checks if the node at idx is the last sibling at its level
@@ -237,3 +237,22 @@ func TestSessionTreeModel_EscClearsFilter(t *testing.T) {
 		t.Errorf("after esc: filter = %q; want empty", m.filter)
 	}
 }
+
+func TestSessionTreeModel_TextMarksForks(t *testing.T) {
+	roots := buildTestTree()
+	roots[0].Children[0].Forked = true
+	roots[0].Children[0].ForkTurn = 2
+	roots[0].Children[1].Current = true
+	m := NewSessionTreeModel(roots)
+
+	lines := strings.Split(m.Text(), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Text() = %d lines; want 3:\n%s", len(lines), m.Text())
+	}
+	if !strings.Contains(lines[1], "child-1") || !strings.Contains(lines[1], "forked after turn 2") {
+		t.Errorf("line 1 = %q; want fork marker", lines[1])
+	}
+	if !strings.Contains(lines[2], "child-2") || !strings.Contains(lines[2], "(current)") {
+		t.Errorf("line 2 = %q; want current marker", lines[2])
+	}
+}
@@ -0,0 +1,147 @@
+// ABOUTME: ShareConfirmModel overlay listing what /share will upload before anything leaves the machine
+// ABOUTME: AppModel handlers prepare the redacted, encrypted transcript and upload it on confirmation
+
+package btea
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mauromedda/pi-coding-agent-go/internal/export"
+)
+
+// ShareConfirmModel shows a prepared share and waits for y/enter to
+// upload it or n/esc to cancel.
+type ShareConfirmModel struct {
+	plan  *export.SharePlan
+	width int
+}
+
+// NewShareConfirmModel creates the confirmation overlay for plan.
+func NewShareConfirmModel(plan *export.SharePlan, w int) ShareConfirmModel {
+	return ShareConfirmModel{plan: plan, width: w}
+}
+
+// Init returns nil; no startup commands needed.
+func (m ShareConfirmModel) Init() tea.Cmd { return nil }
+
+// Update handles key events for the confirmation.
+func (m ShareConfirmModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "y", "enter":
+			plan := m.plan
+			return m, func() tea.Msg { return ShareConfirmedMsg{Plan: plan} }
+		case "n", "esc", "q":
+			return m, func() tea.Msg { return DismissOverlayMsg{} }
+		}
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+	}
+	return m, nil
+}
+
+// View renders the upload summary as a bordered box.
+func (m ShareConfirmModel) View() string {
+	s := Styles()
+	bs := s.OverlayBorder
+
+	const (
+		dash    = "─"
+		vBorder = "│"
+		tl      = "╭"
+		tr      = "╮"
+		bl      = "╰"
+		br      = "╯"
+	)
+
+	boxWidth := max(m.width*3/5, 50)
+	if boxWidth > m.width-4 {
+		boxWidth = max(m.width-4, 50)
+	}
+	innerWidth := max(boxWidth-2, 0)
+	contentWidth := max(boxWidth-4, 20)
+	border := bs.Render(vBorder)
+
+	var b strings.Builder
+
+	const titleText = " Share Session "
+	title := s.OverlayTitle.Render(titleText)
+	dashesLeft := max((innerWidth-len(titleText))/2, 0)
+	dashesRight := max(innerWidth-len(titleText)-dashesLeft, 0)
+	b.WriteString(bs.Render(tl))
+	b.WriteString(bs.Render(strings.Repeat(dash, dashesLeft)))
+	b.WriteString(title)
+	b.WriteString(bs.Render(strings.Repeat(dash, dashesRight)))
+	b.WriteString(bs.Render(tr))
+	b.WriteByte('\n')
+
+	for _, line := range shareSummary(m.plan) {
+		writeBoxLine(&b, border, s.Dim.Render(line), contentWidth)
+	}
+	writeBoxLine(&b, border, "", contentWidth)
+	writeBoxLine(&b, border, s.Muted.Render("y/enter:upload  n/esc:cancel"), contentWidth)
+
+	b.WriteString(bs.Render(bl))
+	b.WriteString(bs.Render(strings.Repeat(dash, innerWidth)))
+	b.WriteString(bs.Render(br))
+
+	return b.String()
+}
+
+// shareSummary lists what the upload contains and how it is protected.
+func shareSummary(p *export.SharePlan) []string {
+	lines := []string{
+		"Upload to: " + p.Destination,
+		fmt.Sprintf("Content:   %d messages, %d tool calls, %d tool results", p.Messages, p.ToolCalls, p.ToolResults),
+		fmt.Sprintf("Size:      %d bytes", p.Bytes),
+	}
+	if p.Encrypted {
+		lines = append(lines, "Encrypted: yes; the key is only in the link")
+	} else {
+		lines = append(lines, "Encrypted: no; anyone with the link can read it")
+	}
+	var redact []string
+	if p.Redaction.Paths {
+		redact = append(redact, fmt.Sprintf("%d paths", p.Redacted.Paths))
+	}
+	if p.Redaction.Secrets {
+		redact = append(redact, fmt.Sprintf("%d secrets", p.Redacted.Secrets))
+	}
+	if len(redact) == 0 {
+		lines = append(lines, "Redacted:  nothing (redaction is off)")
+	} else {
+		lines = append(lines, "Redacted:  "+strings.Join(redact, ", "))
+	}
+	return lines
+}
+
+// prepareShare builds the share plan for the current conversation from
+// the /share settings.
+func (m AppModel) prepareShare() (*export.SharePlan, error) {
+	sh := m.deps.Share
+	home, _ := os.UserHomeDir()
+	return export.PrepareShare(m.exportMessages(), export.ShareOptions{
+		Backend:  sh.EffectiveBackend(),
+		PasteURL: sh.EffectivePasteURL(),
+		Encrypt:  sh.EncryptEnabled(),
+		Redaction: export.Redaction{
+			Paths:    sh.RedactPathsEnabled(),
+			Secrets:  sh.RedactSecretsEnabled(),
+			Home:     home,
+			CWD:      m.gitCWD,
+			Redactor: m.deps.Redactor,
+		},
+	})
+}
+
+// uploadShareCmd uploads plan off the UI goroutine.
+func uploadShareCmd(plan *export.SharePlan) tea.Cmd {
+	return func() tea.Msg {
+		link, err := plan.Upload()
+		return ShareDoneMsg{Link: link, Err: err}
+	}
+}
@@ -0,0 +1,96 @@
+// ABOUTME: Tests for the /share confirmation overlay and the upload round trip
+// ABOUTME: A paste backend on httptest receives the encrypted transcript
+
+package btea
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mauromedda/pi-coding-agent-go/internal/config"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+// Compile-time check: ShareConfirmModel must satisfy tea.Model.
+var _ tea.Model = ShareConfirmModel{}
+
+func shareTestModel(t *testing.T, pasteURL string) AppModel {
+	t.Helper()
+	deps := testDeps()
+	deps.Share = &config.ShareSettings{Backend: config.ShareBackendPaste, PasteURL: pasteURL}
+	m := NewAppModel(deps)
+	m.width = 80
+	m.messages = append(m.messages, ai.NewTextMessage(ai.RoleUser, "token=abcdefghijklmnop please"))
+	return m
+}
+
+func TestShare_ConfirmThenUpload(t *testing.T) {
+	var uploads []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		uploads = append(uploads, string(body))
+		io.WriteString(w, "https://paste.example/p/1")
+	}))
+	defer srv.Close()
+
+	m := shareTestModel(t, srv.URL)
+	m, _ = m.handleSlashCommand("/share")
+	confirm, ok := m.overlay.(ShareConfirmModel)
+	if !ok {
+		t.Fatalf("/share should open the confirmation, got %T", m.overlay)
+	}
+	view := confirm.View()
+	for _, want := range []string{"Share Session", srv.URL, "1 messages", "Encrypted: yes", "1 secrets"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("confirmation missing %q:\n%s", want, view)
+		}
+	}
+	if len(uploads) != 0 {
+		t.Fatal("nothing may be uploaded before confirmation")
+	}
+
+	_, cmd := confirm.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	confirmed, ok := cmd().(ShareConfirmedMsg)
+	if !ok {
+		t.Fatal("enter should confirm the share")
+	}
+	result, cmd := m.Update(confirmed)
+	m = result.(AppModel)
+	if m.overlay != nil || cmd == nil {
+		t.Fatal("confirming should close the overlay and start the upload")
+	}
+	result, _ = m.Update(cmd())
+	m = result.(AppModel)
+
+	if len(uploads) != 1 || strings.Contains(uploads[0], "please") {
+		t.Errorf("expected one encrypted upload, got %q", uploads)
+	}
+	if got := m.lastAssistantText(); !strings.HasPrefix(got, "Shared: https://paste.example/p/1#key=") {
+		t.Errorf("notice = %q", got)
+	}
+}
+
+func TestShare_CancelUploadsNothing(t *testing.T) {
+	m := shareTestModel(t, "http://127.0.0.1:1")
+	m, _ = m.handleSlashCommand("/share")
+	confirm := m.overlay.(ShareConfirmModel)
+	_, cmd := confirm.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if _, ok := cmd().(DismissOverlayMsg); !ok {
+		t.Error("esc should dismiss the confirmation")
+	}
+}
+
+func TestShare_BadConfigReportsError(t *testing.T) {
+	m := shareTestModel(t, "")
+	m, _ = m.handleSlashCommand("/share")
+	if m.overlay != nil {
+		t.Fatal("a paste backend without URL should not open the confirmation")
+	}
+	if got := m.lastAssistantText(); !strings.Contains(got, "Share failed") {
+		t.Errorf("notice = %q", got)
+	}
+}
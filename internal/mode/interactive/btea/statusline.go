@@ -0,0 +1,72 @@
+// ABOUTME: Custom status line: runs the configured script with the session as JSON and shows its line in the footer
+// ABOUTME: Re-runs after each turn and ! command, and on the configured refresh interval; one run at a time
+
+package btea
+
+import (
+	"context"
+	"os"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mauromedda/pi-coding-agent-go/internal/statusline"
+)
+
+// StatusLineMsg carries the output of a status line run.
+type StatusLineMsg struct {
+	Text string
+	Err  error
+}
+
+// StatusLineTickMsg asks for a refresh on the configured interval.
+type StatusLineTickMsg struct{}
+
+// statusLineInput describes the session to the status line script.
+func (m AppModel) statusLineInput() statusline.Input {
+	cwd, _ := os.Getwd()
+	in := statusline.Input{
+		CWD:       cwd,
+		Version:   m.deps.Version,
+		Workspace: statusline.WorkspaceInfo{CurrentDir: cwd, ProjectDir: m.gitCWD},
+		Mode:      m.mode.String(),
+		GitBranch: m.gitBranch,
+		Cost:      statusline.CostInfo{TotalCostUSD: m.footer.cost},
+		ContextWindow: statusline.ContextInfo{
+			Used:    m.footer.contextUsed,
+			Total:   m.footer.contextTotal,
+			UsedPct: m.footer.contextPct,
+		},
+		ExitCode: m.lastExitCode,
+	}
+	if m.deps.Session != nil {
+		in.SessionID = m.deps.Session.ID
+	}
+	if md := m.deps.Model; md != nil {
+		in.Model = statusline.ModelInfo{ID: md.ID, DisplayName: md.Name, Name: md.Name, API: string(md.Api)}
+	}
+	return in
+}
+
+// refreshStatusLine runs the status line script unless it is not
+// configured or a run is already in flight.
+func (m AppModel) refreshStatusLine() (AppModel, tea.Cmd) {
+	engine := m.deps.StatusEngine
+	if !engine.HasCommand() || m.statusLineRunning {
+		return m, nil
+	}
+	m.statusLineRunning = true
+	input := m.statusLineInput()
+	return m, func() tea.Msg {
+		text, err := engine.Execute(context.Background(), input)
+		return StatusLineMsg{Text: text, Err: err}
+	}
+}
+
+// statusLineTick schedules the next interval refresh; nil without one.
+func (m AppModel) statusLineTick() tea.Cmd {
+	every := m.deps.StatusEngine.Refresh()
+	if !m.deps.StatusEngine.HasCommand() || every <= 0 {
+		return nil
+	}
+	return tea.Tick(every, func(time.Time) tea.Msg { return StatusLineTickMsg{} })
+}
@@ -0,0 +1,75 @@
+// ABOUTME: Tests for the custom status line: the JSON the script receives, refresh triggers, and footer rendering
+// ABOUTME: Uses cat as the script so the payload comes back as the status line
+
+package btea
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/statusline"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+func TestAppModel_StatusLineRuns(t *testing.T) {
+	deps := testDeps()
+	deps.Model = &ai.Model{ID: "m-1", Name: "Model One", Api: ai.ApiAnthropic}
+	deps.StatusEngine = statusline.New("cat", 0)
+	m := NewAppModel(deps)
+
+	result, cmd := m.Update(gitBranchMsg{branch: "main"})
+	m = result.(AppModel)
+	if cmd == nil || !m.statusLineRunning {
+		t.Fatal("learning the branch should run the status line")
+	}
+	if _, again := m.refreshStatusLine(); again != nil {
+		t.Error("a second run should wait for the first")
+	}
+	msg := cmd().(StatusLineMsg)
+	for _, want := range []string{`"git_branch":"main"`, `"display_name":"Model One"`, `"mode":"Edit"`} {
+		if !strings.Contains(msg.Text, want) {
+			t.Errorf("payload lacks %s: %s", want, msg.Text)
+		}
+	}
+	result, _ = m.Update(msg)
+	m = result.(AppModel)
+	if m.statusLineRunning || !strings.Contains(m.footer.View(), `"git_branch":"main"`) {
+		t.Error("the script's output should show in the footer")
+	}
+
+	_, cmd = m.Update(BashDoneMsg{Command: "false", ExitCode: 3})
+	if cmd == nil {
+		t.Fatal("a ! command should re-run the status line")
+	}
+	if text := cmd().(StatusLineMsg).Text; !strings.Contains(text, `"exit_code":3`) {
+		t.Errorf("payload lacks the last exit code: %s", text)
+	}
+}
+
+func TestAppModel_StatusLineTick(t *testing.T) {
+	m := NewAppModel(testDeps())
+	if m.statusLineTick() != nil {
+		t.Error("no status line, no tick")
+	}
+
+	deps := testDeps()
+	deps.StatusEngine = statusline.New("true", 0).WithRefresh(time.Second)
+	m = NewAppModel(deps)
+	result, cmd := m.Update(StatusLineTickMsg{})
+	if cmd == nil || !result.(AppModel).statusLineRunning {
+		t.Error("a tick should run the status line and schedule the next one")
+	}
+}
+
+func TestFooterModel_StatusLine(t *testing.T) {
+	f := NewFooterModel().WithStatusLine("\x1b[32mall good\x1b[0m and more text")
+	f.width = 12
+	lines := strings.Split(f.View(), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("footer lines = %d; want the status line as a third", len(lines))
+	}
+	if !strings.HasPrefix(lines[2], "\x1b[32mall good") || strings.Contains(lines[2], "more text") {
+		t.Errorf("status line = %q; want colors kept and the line truncated", lines[2])
+	}
+}
@@ -0,0 +1,91 @@
+// ABOUTME: Streaming throttle between the agent and the TUI: text deltas are batched into one message per frame
+// ABOUTME: Counts deltas, frames, and frames the UI was too busy to take on time, for the debug log
+
+package btea
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
+)
+
+// streamFrameInterval is how long text deltas are collected before they
+// are sent as one AgentTextMsg. Fast local models emit hundreds of tokens a
+// second; repainting for each one makes the terminal flicker.
+const streamFrameInterval = 30 * time.Millisecond
+
+// streamStats describes one forwarded stream.
+type streamStats struct {
+	Deltas   int // text deltas received from the agent
+	Frames   int // AgentTextMsg batches sent
+	Dropped  int // frames the UI took longer than one interval to accept
+	MaxBatch int // most deltas merged into one frame
+}
+
+// String formats the stats for the debug log.
+func (s streamStats) String() string {
+	return fmt.Sprintf("%d deltas in %d frames (max %d per frame), %d dropped", s.Deltas, s.Frames, s.MaxBatch, s.Dropped)
+}
+
+// forwardEvents converts agent events to messages and passes them to send,
+// merging text deltas that arrive within interval of each other into a
+// single AgentTextMsg. Pending text is flushed before any other message so
+// ordering is preserved. It returns when events is closed.
+func forwardEvents(events <-chan agent.AgentEvent, send func(tea.Msg), interval time.Duration) streamStats {
+	var (
+		stats   streamStats
+		pending strings.Builder
+		batch   int
+		timer   *time.Timer
+		tick    <-chan time.Time
+	)
+	flush := func() {
+		if timer != nil {
+			timer.Stop()
+			timer, tick = nil, nil
+		}
+		if pending.Len() == 0 {
+			return
+		}
+		start := time.Now()
+		send(AgentTextMsg{Text: pending.String()})
+		if time.Since(start) > interval {
+			stats.Dropped++
+		}
+		stats.Frames++
+		stats.MaxBatch = max(stats.MaxBatch, batch)
+		pending.Reset()
+		batch = 0
+	}
+
+	for {
+		select {
+		case evt, ok := <-events:
+			if !ok {
+				flush()
+				return stats
+			}
+			msg := bridgeEventToMsg(evt)
+			if text, isText := msg.(AgentTextMsg); isText {
+				pending.WriteString(text.Text)
+				stats.Deltas++
+				batch++
+				if timer == nil {
+					timer = time.NewTimer(interval)
+					tick = timer.C
+				}
+				continue
+			}
+			flush()
+			if msg != nil {
+				send(msg)
+			}
+		case <-tick:
+			timer, tick = nil, nil
+			flush()
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// ABOUTME: Tests for batching streamed text deltas into one message per frame
+// ABOUTME: Covers merging, ordering around other events, flush on close, and dropped-frame counting
+
+package btea
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
+)
+
+func textEvent(s string) agent.AgentEvent {
+	return agent.AgentEvent{Type: agent.EventAssistantText, Text: s}
+}
+
+func TestForwardEvents_MergesDeltasAndKeepsOrder(t *testing.T) {
+	ch := make(chan agent.AgentEvent, 6)
+	ch <- textEvent("Hel")
+	ch <- textEvent("lo ")
+	ch <- agent.AgentEvent{Type: agent.EventToolStart, ToolID: "t1", ToolName: "read"}
+	ch <- textEvent("wor")
+	ch <- textEvent("ld")
+	ch <- agent.AgentEvent{Type: agent.EventAgentEnd}
+	close(ch)
+
+	var got []tea.Msg
+	stats := forwardEvents(ch, func(msg tea.Msg) { got = append(got, msg) }, time.Hour)
+
+	if len(got) != 3 {
+		t.Fatalf("got %d messages %v; want text, tool start, text", len(got), got)
+	}
+	if text, ok := got[0].(AgentTextMsg); !ok || text.Text != "Hello " {
+		t.Errorf("msg[0] = %#v; want the first deltas merged", got[0])
+	}
+	if _, ok := got[1].(AgentToolStartMsg); !ok {
+		t.Errorf("msg[1] = %T; want the tool start after the pending text", got[1])
+	}
+	if text, ok := got[2].(AgentTextMsg); !ok || text.Text != "world" {
+		t.Errorf("msg[2] = %#v; want the trailing deltas flushed on close", got[2])
+	}
+	want := streamStats{Deltas: 4, Frames: 2, MaxBatch: 2}
+	if stats != want {
+		t.Errorf("stats = %+v; want %+v", stats, want)
+	}
+}
+
+func TestForwardEvents_FlushesOnTick(t *testing.T) {
+	ch := make(chan agent.AgentEvent)
+	sent := make(chan tea.Msg, 1)
+	done := make(chan streamStats)
+	go func() {
+		done <- forwardEvents(ch, func(msg tea.Msg) { sent <- msg }, 5*time.Millisecond)
+	}()
+
+	ch <- textEvent("a")
+	ch <- textEvent("b")
+	select {
+	case msg := <-sent:
+		if text, ok := msg.(AgentTextMsg); !ok || text.Text != "ab" {
+			t.Errorf("frame = %#v; want the deltas merged", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("pending text was not flushed by the frame timer")
+	}
+	close(ch)
+	if stats := <-done; stats.Frames != 1 {
+		t.Errorf("stats = %+v; want one frame", stats)
+	}
+}
+
+func TestForwardEvents_CountsDroppedFrames(t *testing.T) {
+	ch := make(chan agent.AgentEvent, 1)
+	ch <- textEvent("x")
+	close(ch)
+
+	stats := forwardEvents(ch, func(tea.Msg) { time.Sleep(5 * time.Millisecond) }, time.Millisecond)
+	if stats.Dropped != 1 {
+		t.Errorf("stats = %+v; want the slow frame counted as dropped", stats)
+	}
+	if got := stats.String(); got != "1 deltas in 1 frames (max 1 per frame), 1 dropped" {
+		t.Errorf("String() = %q", got)
+	}
+}
@@ -11,12 +11,14 @@ import (
 	"sync/atomic"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/termcap"
 	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/theme"
 )
 
-// themeStylesEntry pairs a theme pointer with its pre-built styles.
+// themeStylesEntry pairs a theme pointer and color depth with its pre-built styles.
 type themeStylesEntry struct {
 	theme  *theme.Theme
+	depth  termcap.ColorDepth
 	styles ThemeStyles
 }
 
@@ -64,6 +66,19 @@ func parseColorParams(params []string) string {
 		return params[2]
 	}
 
+	// Truecolor: 38;2;R;G;B (fg) or 48;2;R;G;B (bg)
+	if len(params) >= 5 && (params[0] == "38" || params[0] == "48") && params[1] == "2" {
+		var rgb [3]int
+		for i := range rgb {
+			v, err := strconv.Atoi(params[2+i])
+			if err != nil {
+				return ""
+			}
+			rgb[i] = v
+		}
+		return fmt.Sprintf("#%02x%02x%02x", rgb[0], rgb[1], rgb[2])
+	}
+
 	// Single param: basic fg/bg color or attribute
 	if len(params) == 1 {
 		n, err := strconv.Atoi(params[0])
@@ -122,7 +137,7 @@ func isBackground(code string) bool {
 	matches := sgrRe.FindAllStringSubmatch(code, -1)
 	for _, m := range matches {
 		params := strings.Split(m[1], ";")
-		if len(params) >= 3 && params[0] == "48" && params[1] == "5" {
+		if len(params) >= 3 && params[0] == "48" && (params[1] == "5" || params[1] == "2") {
 			return true
 		}
 		if len(params) == 1 {
@@ -219,15 +234,23 @@ type ThemeStyles struct {
 // the theme pointer has not changed. This avoids rebuilding 32 lipgloss styles
 // (each requiring 3 regex scans) on every View() call.
 func Styles() ThemeStyles {
-	t := theme.Current()
-	if e := cachedStyles.Load(); e != nil && e.theme == t {
+	t, depth := theme.Current(), colorDepth
+	if e := cachedStyles.Load(); e != nil && e.theme == t && e.depth == depth {
 		return e.styles
 	}
-	s := buildStyles(t)
-	cachedStyles.Store(&themeStylesEntry{theme: t, styles: s})
+	s := buildStyles(downsampleTheme(t, depth))
+	cachedStyles.Store(&themeStylesEntry{theme: t, depth: depth, styles: s})
 	return s
 }
 
+// downsampleTheme fits the theme's colors to the terminal's color depth.
+func downsampleTheme(t *theme.Theme, depth termcap.ColorDepth) *theme.Theme {
+	if depth == termcap.ColorTrue {
+		return t
+	}
+	return t.MapColors(func(code string) string { return termcap.Downsample(code, depth) })
+}
+
 // buildStyles constructs ThemeStyles from a theme's palette.
 func buildStyles(t *theme.Theme) ThemeStyles {
 	p := t.Palette
@@ -285,3 +285,12 @@ func TestStylesCacheInvalidatedOnThemeChange(t *testing.T) {
 		t.Error("Styles() after theme change should match buildStyles(Current())")
 	}
 }
+
+func TestExtractColor_Truecolor(t *testing.T) {
+	if got := extractColor("\x1b[38;2;255;135;0m"); got != "#ff8700" {
+		t.Errorf("extractColor(truecolor fg) = %q; want #ff8700", got)
+	}
+	if !isBackground("\x1b[48;2;48;48;48m") {
+		t.Error("a truecolor background should be detected as background")
+	}
+}
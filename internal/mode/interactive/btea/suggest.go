@@ -0,0 +1,148 @@
+// ABOUTME: Next-prompt suggestions: after a turn a model predicts the user's follow-up, shown as ghost text
+// ABOUTME: Opt-in via settings.suggestions; uses the minion model when configured, and Tab accepts it
+
+package btea
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+const (
+	suggestTimeout       = 20 * time.Second
+	suggestMaxTokens     = 64
+	maxSuggestRunes      = 120  // longer replies are not a prompt
+	maxSuggestInputRunes = 6000 // conversation tail sent to the model
+)
+
+const suggestSystemPrompt = "You predict what the user of a coding agent will type next. " +
+	"Given the end of the conversation, reply with the single most likely next prompt, " +
+	"written as the user would type it, in at most twelve words: an action such as " +
+	"\"run the tests\" or \"commit these changes\". No quotes, nothing else. " +
+	"If no follow-up is likely, reply NONE."
+
+// suggestNext starts predicting the user's next prompt when suggestions
+// are enabled and the conversation has a finished turn.
+func (m AppModel) suggestNext() (AppModel, tea.Cmd) {
+	m.suggestion = ""
+	if m.deps.Settings == nil || !m.deps.Settings.Suggestions.IsEnabled() || m.agentRunning || len(m.messages) == 0 {
+		return m, nil
+	}
+	provider, model := m.sideModel()
+	if provider == nil || model == nil {
+		return m, nil
+	}
+	msgs := slices.Clone(m.messages)
+	return m, func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), suggestTimeout)
+		defer cancel()
+		text, err := generateSuggestion(ctx, provider, model, msgs)
+		return SuggestionMsg{After: len(msgs), Text: text, Err: err}
+	}
+}
+
+// applySuggestion shows msg as ghost text unless the conversation moved on
+// while it was generated.
+func (m AppModel) applySuggestion(msg SuggestionMsg) AppModel {
+	if msg.Err != nil || msg.Text == "" || m.agentRunning || len(m.messages) != msg.After {
+		return m
+	}
+	m.suggestion = msg.Text
+	m.editor = m.editor.SetGhostText(m.computeGhostText())
+	return m
+}
+
+// suggestionGhost returns the rest of the suggestion when the editor holds
+// a prefix of it, so it stays visible while the user types it out.
+func (m AppModel) suggestionGhost(text string) string {
+	if m.suggestion == "" || !strings.HasPrefix(m.suggestion, text) {
+		return ""
+	}
+	return m.suggestion[len(text):]
+}
+
+// generateSuggestion asks model for the likely next prompt after messages.
+// An empty string with no error means the model saw no likely follow-up.
+func generateSuggestion(ctx context.Context, provider ai.ApiProvider, model *ai.Model, messages []ai.Message) (string, error) {
+	excerpt := suggestionExcerpt(messages)
+	if excerpt == "" {
+		return "", fmt.Errorf("no conversation text to continue")
+	}
+	llmCtx := &ai.Context{
+		System:   suggestSystemPrompt,
+		Messages: []ai.Message{ai.NewTextMessage(ai.RoleUser, "Conversation:\n\n"+excerpt+"\n\nNext prompt:")},
+	}
+	stream := provider.Stream(ctx, model, llmCtx, &ai.StreamOptions{MaxTokens: suggestMaxTokens})
+
+	var text strings.Builder
+	var streamErr error
+	for ev := range stream.Events() {
+		switch ev.Type {
+		case ai.EventContentDelta:
+			text.WriteString(ev.Text)
+		case ai.EventError:
+			if streamErr == nil {
+				streamErr = ev.Error
+			}
+		}
+	}
+	if streamErr != nil {
+		return "", fmt.Errorf("generating suggestion: %w", streamErr)
+	}
+	return cleanSuggestion(text.String()), nil
+}
+
+// suggestionExcerpt renders the text of messages as "role: text" lines,
+// keeping the end of the conversation when it is too long.
+func suggestionExcerpt(messages []ai.Message) string {
+	var lines []string
+	size := 0
+	for _, msg := range slices.Backward(messages) {
+		if msg.Ephemeral {
+			continue
+		}
+		var text strings.Builder
+		for _, c := range msg.Content {
+			if c.Type == ai.ContentText {
+				text.WriteString(c.Text)
+			}
+		}
+		t := strings.Join(strings.Fields(text.String()), " ")
+		if t == "" {
+			continue
+		}
+		line := string(msg.Role) + ": " + t
+		if r := []rune(line); size+len(r) > maxSuggestInputRunes {
+			if len(lines) == 0 {
+				lines = append(lines, "…"+string(r[len(r)-maxSuggestInputRunes:]))
+			}
+			break
+		}
+		size += len([]rune(line))
+		lines = append(lines, line)
+	}
+	slices.Reverse(lines)
+	return strings.Join(lines, "\n")
+}
+
+// cleanSuggestion reduces a reply to one prompt line, or "" when the model
+// declined or wrote something that is not a prompt.
+func cleanSuggestion(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	s = strings.TrimSpace(strings.TrimPrefix(s, "Next prompt:"))
+	s = strings.Trim(s, "\"'`*")
+	s = strings.Join(strings.Fields(s), " ")
+	if strings.EqualFold(strings.TrimRight(s, "."), "none") || len([]rune(s)) > maxSuggestRunes {
+		return ""
+	}
+	return s
+}
@@ -0,0 +1,125 @@
+// ABOUTME: Tests for next-prompt suggestions: opt-in, ghost text, Tab acceptance, and stale replies
+// ABOUTME: Reuses the title stub provider to reply with a fixed suggestion
+
+package btea
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mauromedda/pi-coding-agent-go/internal/config"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+// suggestTestModel returns a model after one finished turn, with
+// suggestions on and a provider replying reply.
+func suggestTestModel(reply string) (AppModel, *titleStubProvider) {
+	on := true
+	stub := &titleStubProvider{title: reply}
+	deps := testDeps()
+	deps.Provider = stub
+	deps.Settings = &config.Settings{Suggestions: &config.SuggestionsSettings{Enabled: &on}}
+	m := NewAppModel(deps)
+	m.messages = []ai.Message{
+		ai.NewTextMessage(ai.RoleUser, "fix the failing parser test"),
+		ai.NewTextMessage(ai.RoleAssistant, "Fixed the off-by-one in parser.go."),
+	}
+	return m, stub
+}
+
+// suggestionFrom runs the commands of an AgentDoneMsg and returns the
+// SuggestionMsg among their results.
+func suggestionFrom(t *testing.T, m AppModel) (AppModel, SuggestionMsg, bool) {
+	t.Helper()
+	m.agentRunning = true
+	result, cmd := m.Update(AgentDoneMsg{Messages: m.messages})
+	m = result.(AppModel)
+	for _, msg := range collectBatchMsgs(cmd) {
+		if s, ok := msg.(SuggestionMsg); ok {
+			return m, s, true
+		}
+	}
+	return m, SuggestionMsg{}, false
+}
+
+func TestAppModel_SuggestsNextPrompt(t *testing.T) {
+	m, _ := suggestTestModel(`"run the tests"`)
+	m, msg, ok := suggestionFrom(t, m)
+	if !ok {
+		t.Fatal("no suggestion requested after the turn")
+	}
+	result, _ := m.Update(msg)
+	m = result.(AppModel)
+	if got := m.editor.GhostText(); got != "run the tests" {
+		t.Fatalf("ghost text = %q; want the suggestion", got)
+	}
+
+	// Typing the start of the suggestion keeps the rest visible.
+	for _, r := range "run " {
+		result, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		m = result.(AppModel)
+	}
+	if got := m.editor.GhostText(); got != "the tests" {
+		t.Errorf("ghost text after typing = %q; want the rest", got)
+	}
+
+	result, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m = result.(AppModel)
+	if got := m.editor.Text(); got != "run the tests" {
+		t.Errorf("editor = %q after Tab; want the suggestion accepted", got)
+	}
+
+	m, _ = m.submitPrompt("run the tests")
+	if m.suggestion != "" {
+		t.Error("submitting a prompt should clear the suggestion")
+	}
+}
+
+func TestAppModel_SuggestionsOptIn(t *testing.T) {
+	m, stub := suggestTestModel("run the tests")
+	m.deps.Settings = &config.Settings{}
+	if _, _, ok := suggestionFrom(t, m); ok || stub.calls != 0 {
+		t.Error("suggestions should be off unless enabled")
+	}
+}
+
+func TestAppModel_StaleSuggestionIgnored(t *testing.T) {
+	m, _ := suggestTestModel("run the tests")
+	m, msg, ok := suggestionFrom(t, m)
+	if !ok {
+		t.Fatal("no suggestion requested after the turn")
+	}
+	m.messages = append(m.messages, ai.NewTextMessage(ai.RoleUser, "something else"))
+	result, _ := m.Update(msg)
+	if got := result.(AppModel).editor.GhostText(); got != "" {
+		t.Errorf("ghost text = %q; a suggestion for an older conversation should be dropped", got)
+	}
+}
+
+func TestCleanSuggestion(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"run the tests", "run the tests"},
+		{"  \"commit these changes\"\nBecause the fix is done.", "commit these changes"},
+		{"Next prompt: add a regression test", "add a regression test"},
+		{"NONE", ""},
+		{"none.", ""},
+		{strings.Repeat("word ", 40), ""},
+	}
+	for _, tt := range tests {
+		if got := cleanSuggestion(tt.in); got != tt.want {
+			t.Errorf("cleanSuggestion(%q) = %q; want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSuggestionExcerpt_KeepsTheEnd(t *testing.T) {
+	msgs := []ai.Message{
+		ai.NewTextMessage(ai.RoleUser, "early "+strings.Repeat("x", maxSuggestInputRunes)),
+		ai.NewTextMessage(ai.RoleAssistant, "latest answer"),
+	}
+	got := suggestionExcerpt(msgs)
+	if !strings.HasSuffix(got, "assistant: latest answer") || strings.Contains(got, "early") {
+		t.Errorf("excerpt = %.80q…; want the latest messages", got)
+	}
+}
@@ -0,0 +1,78 @@
+// ABOUTME: Ctrl+Z / SIGTSTP suspend: releases the terminal, stops the process, repaints on SIGCONT
+// ABOUTME: A SIGCONT after a stop pi-go did not initiate also takes the terminal back and repaints
+// ABOUTME: suspend.pauseTurns decides whether running tool subprocesses are stopped too
+
+package btea
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	pilog "github.com/mauromedda/pi-coding-agent-go/internal/log"
+)
+
+// SuspendRequestMsg asks the app to suspend, e.g. after an external SIGTSTP.
+type SuspendRequestMsg struct{}
+
+// ContinuedMsg reports a SIGCONT. After a stop pi-go did not start itself
+// (e.g. `kill -STOP`), the shell may have reset the terminal, so the app
+// takes it back and repaints in full.
+type ContinuedMsg struct{}
+
+// suspendCmd releases the terminal, stops the process until SIGCONT, then
+// restores raw mode and the alt screen (which triggers a full repaint).
+// Unless suspend.pauseTurns is set, only pi-go itself is stopped, so tool
+// commands started by a running turn keep executing in the background and
+// their results are picked up on resume.
+func (m AppModel) suspendCmd() tea.Cmd {
+	sh := m.sh
+	p, scr := sh.program, sh.screen
+	group := m.deps.Suspend.ShouldPauseTurns()
+	if p == nil || !suspendSupported {
+		return nil
+	}
+	return func() tea.Msg {
+		if err := p.ReleaseTerminal(); err != nil {
+			return nil
+		}
+		_ = scr.release()
+		sh.suspended.Store(true) // the SIGCONT that resumes us is handled here
+		suspendProcess(group)
+		_ = scr.restore()
+		_ = p.RestoreTerminal()
+		return tea.ResumeMsg{}
+	}
+}
+
+// continued handles a SIGCONT: the one ending pi-go's own suspend is
+// ignored, since suspendCmd restores the terminal itself; any other gets a
+// full repaint.
+func (m AppModel) continued() tea.Cmd {
+	if m.sh.suspended.Swap(false) {
+		return nil
+	}
+	return m.repaintCmd()
+}
+
+// repaintCmd re-enters raw mode and the alt screen and redraws everything.
+func (m AppModel) repaintCmd() tea.Cmd {
+	p, scr := m.sh.program, m.sh.screen
+	if scr != nil {
+		return func() tea.Msg {
+			if err := scr.refresh(); err != nil {
+				pilog.Debug("screen: %v", err)
+			}
+			return nil
+		}
+	}
+	if p == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		// Bubble Tea re-initializes the terminal and repaints on restore.
+		if err := p.ReleaseTerminal(); err != nil {
+			return nil
+		}
+		_ = p.RestoreTerminal()
+		return nil
+	}
+}
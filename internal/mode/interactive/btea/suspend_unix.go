@@ -0,0 +1,63 @@
+// ABOUTME: Unix process suspension for the TUI via SIGSTOP and SIGCONT
+// ABOUTME: Also forwards external SIGTSTP and SIGCONT to the program as SuspendRequestMsg and ContinuedMsg
+
+//go:build unix
+
+package btea
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const suspendSupported = true
+
+// suspendProcess stops the process (or its whole process group when group is
+// true) and blocks until SIGCONT. SIGSTOP is used because SIGTSTP is caught
+// by watchSuspendSignals; shells report both as a stopped job.
+func suspendProcess(group bool) {
+	cont := make(chan os.Signal, 1)
+	signal.Notify(cont, syscall.SIGCONT)
+	defer signal.Stop(cont)
+
+	pid := os.Getpid()
+	if group {
+		pid = 0
+	}
+	_ = syscall.Kill(pid, syscall.SIGSTOP)
+	<-cont
+}
+
+// watchSuspendSignals turns SIGTSTP (e.g. `kill -TSTP`) into a clean suspend
+// instead of stopping with the terminal still in raw mode, and SIGCONT into
+// a ContinuedMsg so a stop by SIGSTOP ends with a repaint. Call stop to
+// detach.
+func watchSuspendSignals(p *tea.Program) (stop func()) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGTSTP)
+	contCh := make(chan os.Signal, 1)
+	signal.Notify(contCh, syscall.SIGCONT)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-sigCh:
+				p.Send(SuspendRequestMsg{})
+			case <-contCh:
+				p.Send(ContinuedMsg{})
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(sigCh)
+		signal.Stop(contCh)
+		close(done)
+	}
+}
@@ -0,0 +1,16 @@
+// ABOUTME: Windows placeholder for TUI suspension
+// ABOUTME: Windows consoles have no job control, so Ctrl+Z suspend is a no-op
+
+//go:build windows
+
+package btea
+
+import tea "github.com/charmbracelet/bubbletea"
+
+const suspendSupported = false
+
+func suspendProcess(bool) {}
+
+func watchSuspendSignals(*tea.Program) (stop func()) {
+	return func() {}
+}
@@ -0,0 +1,37 @@
+// ABOUTME: /tasks and /detach: list background tasks, name them, and detach the running turn
+// ABOUTME: Both run at once while the agent works instead of waiting in the prompt queue
+
+package btea
+
+import (
+	"fmt"
+	"strings"
+)
+
+// runsWhileBusy reports whether text is a command that acts on the running
+// turn: /detach, /tasks with no arguments, or /plan pause, resume, or stop.
+func runsWhileBusy(text string) bool {
+	name, args, _ := strings.Cut(strings.TrimSpace(text), " ")
+	args = strings.TrimSpace(args)
+	return name == "/detach" || (name == "/tasks" && args == "") || (name == "/plan" && args != "")
+}
+
+// nameTask handles "/tasks name <task> <name>", where task is an ID or a
+// current name.
+func (m AppModel) nameTask(arg string) (string, error) {
+	rest, ok := strings.CutPrefix(arg, "name ")
+	if !ok {
+		return "", fmt.Errorf("unknown argument %q (use /tasks, or /tasks name <task> <name>)", arg)
+	}
+	ref, name, _ := strings.Cut(strings.TrimSpace(rest), " ")
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("usage: /tasks name <task> <name>")
+	}
+	task := m.sh.bgManager.Find(ref)
+	if task == nil {
+		return "", fmt.Errorf("no background task %q", ref)
+	}
+	m.sh.bgManager.Rename(task.ID, name)
+	return fmt.Sprintf("Named background task %s %q.", task.ID, name), nil
+}
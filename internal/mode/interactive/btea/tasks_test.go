@@ -0,0 +1,92 @@
+// ABOUTME: Tests for /tasks and /detach: naming background tasks and detaching the running turn
+// ABOUTME: Both commands must run while the agent works instead of joining the prompt queue
+
+package btea
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/config"
+)
+
+func TestRunsWhileBusy(t *testing.T) {
+	for text, want := range map[string]bool{
+		"/detach":             true,
+		"/detach nightly run": true,
+		"/tasks":              true,
+		"/tasks name bg-1 x":  false,
+		"/detached":           false,
+		"fix /detach":         false,
+		"/plan pause":         true,
+		"/plan":               false,
+	} {
+		if got := runsWhileBusy(text); got != want {
+			t.Errorf("runsWhileBusy(%q) = %v; want %v", text, got, want)
+		}
+	}
+}
+
+func TestAppModel_DetachNamedTask(t *testing.T) {
+	deps := testDeps()
+	deps.Notify = &config.NotifySettings{Method: "bell"}
+	m := NewAppModel(deps)
+	m.sh.bgManager = NewBackgroundManager(nil)
+	m.agentRunning = true
+	m.sh.fgTaskID.Store("bg-test123")
+	m.width = 80
+
+	m.editor = m.editor.SetText("/detach nightly build")
+	m, _ = m.submitOrEnqueue()
+	if m.agentRunning || len(m.promptQueue) != 0 {
+		t.Fatalf("running %v, queue %v; want the turn detached, not queued", m.agentRunning, m.promptQueue)
+	}
+	task := m.sh.bgManager.Get("bg-test123")
+	if task == nil || task.Name != "nightly build" {
+		t.Fatalf("task = %+v; want it named", task)
+	}
+	if !strings.Contains(m.lastAssistantText(), "[nightly build]") {
+		t.Errorf("detach notice = %q", m.lastAssistantText())
+	}
+
+	result, cmd := m.Update(BackgroundTaskDoneMsg{TaskID: "bg-test123"})
+	m = result.(AppModel)
+	if !strings.Contains(m.lastAssistantText(), "Background task [nightly build] completed") {
+		t.Errorf("done notice = %q", m.lastAssistantText())
+	}
+	if cmd == nil {
+		t.Error("cmd = nil; want the completion notification")
+	}
+
+	m, _ = m.handleSlashCommand("/detach")
+	if got := m.lastAssistantText(); got != "Nothing is running to detach." {
+		t.Errorf("idle /detach = %q", got)
+	}
+}
+
+func TestAppModel_TasksCommand(t *testing.T) {
+	m := NewAppModel(testDeps())
+	m.sh.bgManager = NewBackgroundManager(nil)
+	_ = m.sh.bgManager.Add(&BackgroundTask{ID: "bg-abc", Prompt: "lint", Status: BGRunning})
+
+	m.agentRunning = true
+	m.editor = m.editor.SetText("/tasks")
+	m, _ = m.submitOrEnqueue()
+	if _, ok := m.overlay.(BackgroundViewModel); !ok || len(m.promptQueue) != 0 {
+		t.Fatalf("overlay = %T, queue %v; want the task list at once", m.overlay, m.promptQueue)
+	}
+	m.overlay = nil
+	m.agentRunning = false
+
+	m, _ = m.handleSlashCommand("/tasks name bg-abc linter")
+	if task := m.sh.bgManager.Find("linter"); task == nil || task.ID != "bg-abc" {
+		t.Errorf("task after naming = %+v", task)
+	}
+	if view := NewBackgroundViewModel(m.sh.bgManager.List(), 80, 24).View(); !strings.Contains(view, "[linter]") {
+		t.Errorf("task list should show the name:\n%s", view)
+	}
+	m, _ = m.handleSlashCommand("/tasks name nope x")
+	if got := m.lastAssistantText(); !strings.Contains(got, `no background task "nope"`) {
+		t.Errorf("unknown task = %q", got)
+	}
+}
@@ -0,0 +1,239 @@
+// ABOUTME: ThemePickerModel overlay for /theme: moving the cursor previews a theme, enter keeps it, esc reverts
+// ABOUTME: Applying a theme swaps the global palette and broadcasts ThemeChangedMsg so cached renders re-style
+
+package btea
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/theme"
+)
+
+// ThemePreviewMsg asks to show the named theme while the picker is open.
+type ThemePreviewMsg struct{ Name string }
+
+// ThemeSelectedMsg is sent when the user keeps the named theme.
+type ThemeSelectedMsg struct{ Name string }
+
+// ThemePickerDismissMsg is sent when the picker is closed without a choice;
+// Original is the theme active when it opened.
+type ThemePickerDismissMsg struct{ Original *theme.Theme }
+
+// ThemeChangedMsg announces that the active theme changed. Models that
+// cache styled output drop it so the next render uses the new palette.
+type ThemeChangedMsg struct{ Theme *theme.Theme }
+
+// ThemePickerModel lists the available themes with a sample of the
+// highlighted one, which is previewed live behind the overlay.
+type ThemePickerModel struct {
+	names    []string
+	cursor   int
+	original *theme.Theme
+	width    int
+}
+
+// NewThemePickerModel creates a picker over names with the cursor on the
+// active theme.
+func NewThemePickerModel(names []string, w int) ThemePickerModel {
+	current := theme.Current()
+	return ThemePickerModel{
+		names:    names,
+		cursor:   max(slices.Index(names, current.Name), 0),
+		original: current,
+		width:    w,
+	}
+}
+
+// Init returns nil; no startup commands needed.
+func (m ThemePickerModel) Init() tea.Cmd { return nil }
+
+// Update moves the cursor (previewing each theme), keeps a theme on enter,
+// and reverts on esc.
+func (m ThemePickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "j", "down":
+			return m.move(1)
+		case "k", "up":
+			return m.move(-1)
+		case "enter":
+			if len(m.names) == 0 {
+				return m, nil
+			}
+			name := m.names[m.cursor]
+			return m, func() tea.Msg { return ThemeSelectedMsg{Name: name} }
+		case "esc", "q":
+			original := m.original
+			return m, func() tea.Msg { return ThemePickerDismissMsg{Original: original} }
+		}
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+	}
+	return m, nil
+}
+
+// move shifts the cursor by delta and previews the theme under it.
+func (m ThemePickerModel) move(delta int) (tea.Model, tea.Cmd) {
+	next := m.cursor + delta
+	if next < 0 || next >= len(m.names) {
+		return m, nil
+	}
+	m.cursor = next
+	name := m.names[next]
+	return m, func() tea.Msg { return ThemePreviewMsg{Name: name} }
+}
+
+// View renders the theme list and a color sample as a bordered box.
+func (m ThemePickerModel) View() string {
+	s := Styles()
+	bs := s.OverlayBorder
+
+	const (
+		dash    = "─"
+		vBorder = "│"
+		tl      = "╭"
+		tr      = "╮"
+		bl      = "╰"
+		br      = "╯"
+	)
+
+	boxWidth := max(m.width*2/5, 44)
+	if boxWidth > m.width-4 {
+		boxWidth = max(m.width-4, 44)
+	}
+	innerWidth := max(boxWidth-2, 0)
+	contentWidth := max(boxWidth-4, 20)
+	border := bs.Render(vBorder)
+
+	var b strings.Builder
+
+	titleText := " Theme "
+	title := s.OverlayTitle.Render(titleText)
+	dashesLeft := max((innerWidth-len(titleText))/2, 0)
+	dashesRight := max(innerWidth-len(titleText)-dashesLeft, 0)
+	b.WriteString(bs.Render(tl))
+	b.WriteString(bs.Render(strings.Repeat(dash, dashesLeft)))
+	b.WriteString(title)
+	b.WriteString(bs.Render(strings.Repeat(dash, dashesRight)))
+	b.WriteString(bs.Render(tr))
+	b.WriteByte('\n')
+
+	for i, name := range m.names {
+		label := name
+		if name == m.original.Name {
+			label += " (current)"
+		}
+		if i == m.cursor {
+			writeBoxLine(&b, border, s.Selection.Render("> "+label), contentWidth)
+		} else {
+			writeBoxLine(&b, border, s.Dim.Render("  "+label), contentWidth)
+		}
+	}
+
+	writeBoxLine(&b, border, "", contentWidth)
+	writeBoxLine(&b, border, themeSample(s), contentWidth)
+	writeBoxLine(&b, border, s.Muted.Render("j/k:preview  enter:keep  esc:revert"), contentWidth)
+
+	b.WriteString(bs.Render(bl))
+	b.WriteString(bs.Render(strings.Repeat(dash, innerWidth)))
+	b.WriteString(bs.Render(br))
+
+	return b.String()
+}
+
+// themeSample shows the semantic colors of the active theme side by side.
+func themeSample(s ThemeStyles) string {
+	samples := []struct {
+		style lipgloss.Style
+		text  string
+	}{
+		{s.Accent, "accent"},
+		{s.Success, "ok"},
+		{s.Warning, "warn"},
+		{s.Error, "error"},
+		{s.Info, "info"},
+		{s.Muted, "muted"},
+	}
+	parts := make([]string, len(samples))
+	for i, sm := range samples {
+		parts[i] = sm.style.Render(sm.text)
+	}
+	return strings.Join(parts, " ")
+}
+
+// openThemePicker shows the theme picker over the conversation.
+func (m AppModel) openThemePicker() AppModel {
+	m.overlay = NewThemePickerModel(theme.Available(m.deps.ThemeDirs), m.width)
+	return m
+}
+
+// setTheme makes th the active theme and broadcasts ThemeChangedMsg to
+// every pane.
+func (m AppModel) setTheme(th *theme.Theme) (AppModel, tea.Cmd) {
+	theme.Set(th)
+	return m, func() tea.Msg { return ThemeChangedMsg{Theme: th} }
+}
+
+// previewTheme applies the named theme without saving it.
+func (m AppModel) previewTheme(name string) (AppModel, tea.Cmd) {
+	th, err := theme.Resolve(name, m.deps.ThemeDirs)
+	if err != nil {
+		return m, nil
+	}
+	return m.setTheme(th)
+}
+
+// selectTheme keeps the theme picked in the overlay and closes it.
+func (m AppModel) selectTheme(name string) (tea.Model, tea.Cmd) {
+	m.overlay = nil
+	m.editor = m.editor.SetFocused(true)
+	m, changed, notice := m.keepTheme(name)
+	result, cmd := m.applyNotice(notice)
+	return result, tea.Batch(changed, cmd)
+}
+
+// dismissThemePicker closes the picker and reverts to original, the theme
+// active when it opened.
+func (m AppModel) dismissThemePicker(original *theme.Theme) (AppModel, tea.Cmd) {
+	m.overlay = nil
+	m.editor = m.editor.SetFocused(true)
+	if original == nil || original == theme.Current() {
+		return m, nil
+	}
+	return m.setTheme(original)
+}
+
+// keepTheme applies the named theme and saves it, returning a notice.
+func (m AppModel) keepTheme(name string) (AppModel, tea.Cmd, string) {
+	th, err := theme.Resolve(name, m.deps.ThemeDirs)
+	if err != nil {
+		return m, nil, fmt.Sprintf("Error: %v", err)
+	}
+	m, cmd := m.setTheme(th)
+	save := m.deps.OnThemeChange
+	if save == nil {
+		return m, cmd, fmt.Sprintf("Theme set to %s.", name)
+	}
+	if err := save(name); err != nil {
+		return m, cmd, fmt.Sprintf("Theme set to %s, but it was not saved: %v", name, err)
+	}
+	return m, cmd, fmt.Sprintf("Theme set to %s (saved).", name)
+}
+
+// restyle drops the styled output cached by the conversation and the
+// overlay after a theme change.
+func (m AppModel) restyle(msg ThemeChangedMsg) AppModel {
+	for i := range m.content {
+		updated, _ := m.content[i].Update(msg)
+		m.content[i] = updated
+	}
+	if m.overlay != nil {
+		m.overlay, _ = m.overlay.Update(msg)
+	}
+	return m
+}
@@ -0,0 +1,153 @@
+// ABOUTME: Tests for /theme: live preview in the picker, keeping and reverting, and direct switching
+// ABOUTME: Also checks that ThemeChangedMsg drops cached Markdown in every pane; tests reset the global theme
+
+package btea
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/theme"
+)
+
+// keepDefaultTheme restores the default theme when the test ends, since
+// the active theme is process-wide.
+func keepDefaultTheme(t *testing.T) {
+	t.Helper()
+	original := theme.Current()
+	t.Cleanup(func() { theme.Set(original) })
+}
+
+// settle feeds the messages cmd produces back into m, one level deep.
+func settle(m AppModel, cmd tea.Cmd) AppModel {
+	for _, msg := range collectBatchMsgs(cmd) {
+		result, _ := m.Update(msg)
+		m = result.(AppModel)
+	}
+	return m
+}
+
+// press sends a key to m and settles the messages it produces.
+func press(m AppModel, key tea.KeyMsg) AppModel {
+	result, cmd := m.Update(key)
+	m = result.(AppModel)
+	for _, msg := range collectBatchMsgs(cmd) {
+		result, cmd := m.Update(msg)
+		m = settle(result.(AppModel), cmd)
+	}
+	return m
+}
+
+// renderedAssistant adds a rendered Markdown reply to m and returns it.
+func renderedAssistant(m AppModel) (AppModel, *AssistantMsgModel) {
+	am := NewAssistantMsgModel()
+	am.width = 80
+	am.Update(AgentTextMsg{Text: "# Title\n\nsome *text*"})
+	_ = am.View()
+	m.content = append(m.content, am)
+	return m, am
+}
+
+func TestThemePicker_PreviewAndRevert(t *testing.T) {
+	keepDefaultTheme(t)
+	m := NewAppModel(testDeps())
+	m, am := renderedAssistant(m)
+	if am.blocks[0].cachedLines == nil {
+		t.Fatal("the reply should be rendered and cached")
+	}
+
+	m, _ = m.handleSlashCommand("/theme")
+	picker, ok := m.overlay.(ThemePickerModel)
+	if !ok {
+		t.Fatalf("overlay = %T; want the theme picker", m.overlay)
+	}
+	if picker.names[picker.cursor] != "default" {
+		t.Errorf("cursor on %q; want the active theme", picker.names[picker.cursor])
+	}
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyDown})
+	if theme.Current().Name != "dark" {
+		t.Fatalf("previewed theme = %q; want dark", theme.Current().Name)
+	}
+	if am.blocks[0].cachedLines != nil {
+		t.Error("the preview should drop cached Markdown")
+	}
+	if view := m.overlay.View(); !strings.Contains(view, "default (current)") || !strings.Contains(view, "> dark") {
+		t.Errorf("picker view = %q; want dark highlighted and the opening theme marked", view)
+	}
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.overlay != nil || theme.Current().Name != "default" {
+		t.Errorf("after esc: overlay %T, theme %q; want the picker closed and default restored", m.overlay, theme.Current().Name)
+	}
+}
+
+func TestThemePicker_KeepSaves(t *testing.T) {
+	keepDefaultTheme(t)
+	deps := testDeps()
+	var saved []string
+	deps.OnThemeChange = func(name string) error {
+		saved = append(saved, name)
+		return nil
+	}
+	m := NewAppModel(deps)
+
+	m, _ = m.handleSlashCommand("/theme")
+	m = press(m, tea.KeyMsg{Type: tea.KeyDown})
+	m = press(m, tea.KeyMsg{Type: tea.KeyDown})
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.overlay != nil || theme.Current().Name != "light" {
+		t.Fatalf("after enter: overlay %T, theme %q; want light kept", m.overlay, theme.Current().Name)
+	}
+	if len(saved) != 1 || saved[0] != "light" {
+		t.Errorf("saved = %q; want only the kept theme saved", saved)
+	}
+	if got := m.lastAssistantText(); got != "Theme set to light (saved)." {
+		t.Errorf("notice = %q", got)
+	}
+}
+
+func TestAppModel_ThemeCommand(t *testing.T) {
+	keepDefaultTheme(t)
+	deps := testDeps()
+	deps.OnThemeChange = func(string) error { return errors.New("read-only home") }
+	m := NewAppModel(deps)
+
+	m, cmd := m.handleSlashCommand("/theme monochrome")
+	m = settle(m, cmd)
+	if theme.Current().Name != "monochrome" || m.overlay != nil {
+		t.Fatalf("theme = %q; want monochrome without the picker", theme.Current().Name)
+	}
+	if got := m.lastAssistantText(); !strings.Contains(got, "not saved: read-only home") {
+		t.Errorf("notice = %q; want the save error reported", got)
+	}
+
+	m, _ = m.handleSlashCommand("/theme nope")
+	if got := m.lastAssistantText(); !strings.Contains(got, `unknown theme "nope"`) || !strings.Contains(got, "default, dark, light, monochrome") {
+		t.Errorf("notice = %q; want the available themes listed", got)
+	}
+	if theme.Current().Name != "monochrome" {
+		t.Error("an unknown theme should leave the active one alone")
+	}
+}
+
+func TestPaneSetModel_ThemeChangeReachesEveryPane(t *testing.T) {
+	keepDefaultTheme(t)
+	m := paneSet(t)
+	m, _ = updateSet(t, m, PaneMsg{Pane: 1, Msg: SplitPaneMsg{}})
+	var replies []*AssistantMsgModel
+	for i := range m.panes {
+		var am *AssistantMsgModel
+		m.panes[i], am = renderedAssistant(m.panes[i])
+		replies = append(replies, am)
+	}
+
+	m, _ = updateSet(t, m, PaneMsg{Pane: m.panes[1].sh.pane, Msg: ThemeChangedMsg{Theme: theme.Builtin("light")}})
+	for i, am := range replies {
+		if am.blocks[0].cachedLines != nil {
+			t.Errorf("pane %d kept Markdown rendered with the old theme", i+1)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// ABOUTME: TodoListModel: live checklist of the model's todo_write task list, shown above the editor
+// ABOUTME: Updated from each todo_write call; a fully done list is cleared when the turn ends
+
+package btea
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/tools"
+)
+
+// TodoListModel renders the task list the model keeps with todo_write.
+// The zero value is an empty list and renders nothing.
+type TodoListModel struct {
+	items []tools.TodoItem
+	width int
+}
+
+// WithItems replaces the list.
+func (m TodoListModel) WithItems(items []tools.TodoItem) TodoListModel {
+	m.items = items
+	return m
+}
+
+// WithWidth sets the render width.
+func (m TodoListModel) WithWidth(w int) TodoListModel {
+	m.width = w
+	return m
+}
+
+// Done returns how many items are done.
+func (m TodoListModel) Done() int {
+	n := 0
+	for _, it := range m.items {
+		if it.Status == tools.TodoDone {
+			n++
+		}
+	}
+	return n
+}
+
+// AllDone reports whether the list has items and all of them are done.
+func (m TodoListModel) AllDone() bool {
+	return len(m.items) > 0 && m.Done() == len(m.items)
+}
+
+// View renders a header and one line per item: done, in progress, or
+// pending.
+func (m TodoListModel) View() string {
+	if len(m.items) == 0 {
+		return ""
+	}
+	s := Styles()
+	lines := []string{s.OverlayTitle.Render(fmt.Sprintf("Todos %d/%d", m.Done(), len(m.items)))}
+	for _, it := range m.items {
+		line := " " + it.Content
+		if m.width > 0 {
+			line = truncateVisual(line, m.width-2)
+		}
+		switch it.Status {
+		case tools.TodoDone:
+			lines = append(lines, s.Dim.Render("✓"+line))
+		case tools.TodoInProgress:
+			lines = append(lines, s.Selection.Render("▸"+line))
+		default:
+			lines = append(lines, s.Muted.Render("○"+line))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// applyTodoWrite shows the list from a todo_write call. Invalid calls are
+// left to the tool, which reports the error to the model.
+func (m AppModel) applyTodoWrite(args map[string]any) AppModel {
+	if items, err := tools.ParseTodos(args); err == nil {
+		m.todos = m.todos.WithItems(items)
+	}
+	return m
+}
@@ -0,0 +1,54 @@
+// ABOUTME: Tests for the todo list widget: updates from todo_write calls, rendering, and clearing
+// ABOUTME: Drives AppModel with tool start and agent done messages
+
+package btea
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/tools"
+)
+
+func todoArgs(items ...[2]string) map[string]any {
+	todos := make([]any, 0, len(items))
+	for _, it := range items {
+		todos = append(todos, map[string]any{"content": it[0], "status": it[1]})
+	}
+	return map[string]any{"todos": todos}
+}
+
+func TestAppModel_TodoWriteShowsChecklist(t *testing.T) {
+	m := NewAppModel(testDeps())
+	m.agentRunning = true
+	result, _ := m.Update(AgentToolStartMsg{ToolID: "t1", ToolName: tools.TodoWriteToolName,
+		Args: todoArgs([2]string{"parse flags", "done"}, [2]string{"wire it up", "in_progress"}, [2]string{"write docs", "pending"})})
+	m = result.(AppModel)
+
+	view := m.View()
+	for _, want := range []string{"Todos 1/3", "✓ parse flags", "▸ wire it up", "○ write docs"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view lacks %q", want)
+		}
+	}
+
+	// An invalid call leaves the list as it was.
+	result, _ = m.Update(AgentToolStartMsg{ToolID: "t2", ToolName: tools.TodoWriteToolName, Args: todoArgs([2]string{"x", "blocked"})})
+	m = result.(AppModel)
+	if len(m.todos.items) != 3 {
+		t.Errorf("todos = %d after an invalid call; want 3", len(m.todos.items))
+	}
+
+	// A list with work left survives the end of the turn; a finished one does not.
+	result, _ = m.Update(AgentDoneMsg{})
+	m = result.(AppModel)
+	if len(m.todos.items) != 3 {
+		t.Fatal("an unfinished list should stay after the turn")
+	}
+	result, _ = m.Update(AgentToolStartMsg{ToolID: "t3", ToolName: tools.TodoWriteToolName,
+		Args: todoArgs([2]string{"parse flags", "done"}, [2]string{"wire it up", "done"}, [2]string{"write docs", "done"})})
+	result, _ = result.(AppModel).Update(AgentDoneMsg{})
+	if view := result.(AppModel).View(); strings.Contains(view, "Todos") {
+		t.Error("a finished list should be cleared when the turn ends")
+	}
+}
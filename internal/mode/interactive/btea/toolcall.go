@@ -1,5 +1,5 @@
 // ABOUTME: ToolCallModel is a Bubble Tea leaf that renders a tool invocation box
-// ABOUTME: Port of components/tool_call.go; handles streaming updates, expand/collapse
+// ABOUTME: Port of components/tool_call.go; handles streaming updates, a live tail while running, expand/collapse
 
 package btea
 
@@ -7,9 +7,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
+	"github.com/mauromedda/pi-coding-agent-go/internal/config"
 	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/width"
 )
 
@@ -39,8 +42,35 @@ func toolColorFromStyles(name string, s ThemeStyles) lipgloss.Style {
 	}
 }
 
+// toolOutputLines is how many lines of a tool result a collapsed call
+// shows before folding the rest (settings.terminal.toolOutputLines). Set
+// once by Run before the program starts.
+var toolOutputLines = (*config.TerminalSettings)(nil).EffectiveToolOutputLines()
+
+// spinnerInterval is how often a running tool call's spinner and elapsed
+// time advance.
+const spinnerInterval = 100 * time.Millisecond
+
+// liveOutputMax bounds the streamed output a running call keeps; only its
+// tail is shown, and the tool's result replaces it when the call ends.
+const liveOutputMax = 64 << 10
+
+// spinnerTick schedules the next SpinnerTickMsg.
+func spinnerTick() tea.Cmd {
+	return tea.Tick(spinnerInterval, func(t time.Time) tea.Msg { return SpinnerTickMsg{Time: t} })
+}
+
+// formatElapsed renders d as "12s" or "1m05s".
+func formatElapsed(d time.Duration) string {
+	secs := int(d / time.Second)
+	if secs < 60 {
+		return fmt.Sprintf("%ds", secs)
+	}
+	return fmt.Sprintf("%dm%02ds", secs/60, secs%60)
+}
+
 // ToolCallModel renders a tool invocation with Claude-style bordered box,
-// status indicator, and expand/collapse support for output.
+// status indicator, and output folded beyond a preview until Ctrl+O expands it.
 type ToolCallModel struct {
 	id             string
 	name           string
@@ -51,8 +81,14 @@ type ToolCallModel struct {
 	expanded       bool
 	width          int
 	images         []ImageViewModel
+	steps          []agent.ToolStep // composite tool steps, in run order
 	showImages     bool
 	cachedFilePath string // extracted once at creation, not per View()
+
+	started time.Time     // when the call started, for the elapsed time
+	elapsed time.Duration // as of the last spinner tick
+	frame   int           // spinner frame
+	dropped int           // streamed lines trimmed from the front of output
 }
 
 // NewToolCallModel creates a ToolCallModel for the given tool invocation.
@@ -64,6 +100,7 @@ func NewToolCallModel(id, name, args string) ToolCallModel {
 		args:           args,
 		showImages:     true,
 		cachedFilePath: extractFilePath(args),
+		started:        time.Now(),
 	}
 }
 
@@ -77,7 +114,18 @@ func (m ToolCallModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case AgentToolUpdateMsg:
 		if msg.ToolID == m.id {
-			m.output += msg.Text
+			if msg.Step != nil {
+				m.steps = upsertStep(m.steps, *msg.Step)
+			}
+			var dropped int
+			m.output, dropped = trimLiveOutput(m.output + msg.Text)
+			m.dropped += dropped
+		}
+
+	case SpinnerTickMsg:
+		if !m.done {
+			m.frame++
+			m.elapsed = msg.Time.Sub(m.started)
 		}
 
 	case AgentToolEndMsg:
@@ -112,6 +160,60 @@ func (m ToolCallModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// folds reports whether the output is longer than the collapsed preview,
+// so Ctrl+O has something to expand.
+func (m ToolCallModel) folds() bool {
+	return m.output != "" && strings.Count(strings.TrimRight(m.output, "\n"), "\n")+1 > toolOutputLines
+}
+
+// trimLiveOutput drops whole lines from the front of streamed output
+// beyond liveOutputMax, returning what is left and how many lines went.
+func trimLiveOutput(s string) (string, int) {
+	if len(s) <= liveOutputMax {
+		return s, 0
+	}
+	cut := len(s) - liveOutputMax
+	if i := strings.IndexByte(s[cut:], '\n'); i >= 0 {
+		cut += i + 1
+	}
+	return s[cut:], strings.Count(s[:cut], "\n")
+}
+
+// upsertStep records a step report, replacing an earlier report of the
+// same step.
+func upsertStep(steps []agent.ToolStep, step agent.ToolStep) []agent.ToolStep {
+	for i := range steps {
+		if steps[i].Index == step.Index {
+			steps[i] = step
+			return steps
+		}
+	}
+	return append(steps, step)
+}
+
+// stepLine renders one composite tool step as a nested tool call line.
+func stepLine(step agent.ToolStep, last bool, s ThemeStyles, maxWidth int) string {
+	branch := "├"
+	if last {
+		branch = "└"
+	}
+	var status string
+	switch {
+	case step.Done && step.IsError:
+		status = s.Error.Render("✗")
+	case step.Done:
+		status = "✓"
+	default:
+		status = glyphs.Spinner[0]
+	}
+	line := fmt.Sprintf("%s %s %s %s", s.Dim.Render(branch), status, toolColorFromStyles(step.Tool, s).Render(step.Tool), step.Args)
+	if step.IsError {
+		first, _, _ := strings.Cut(strings.TrimSpace(step.Output), "\n")
+		line += " " + s.Error.Render(first)
+	}
+	return width.TruncateToWidth(line, maxWidth)
+}
+
 // extractFilePath attempts to extract a file path from the tool args JSON.
 // It checks common keys: file_path, path, filename.
 func extractFilePath(argsJSON string) string {
@@ -173,11 +275,15 @@ func (m ToolCallModel) View() string {
 	case m.done:
 		status = "✓"
 	default:
-		status = "⠋"
+		status = glyphs.Spinner[m.frame%len(glyphs.Spinner)]
 	}
 
-	// Tool info line
-	toolInfo := fmt.Sprintf("%s %s %s", status, nameStyle.Render(m.name), m.args)
+	// Tool info line, with the elapsed time while the call runs
+	name := nameStyle.Render(m.name)
+	if !m.done && m.elapsed >= time.Second {
+		name += " " + s.Dim.Render(formatElapsed(m.elapsed))
+	}
+	toolInfo := fmt.Sprintf("%s %s %s", status, name, m.args)
 	toolInfo = strings.TrimSpace(toolInfo)
 
 	// Border characters (each is 1 visible column wide)
@@ -226,6 +332,11 @@ func (m ToolCallModel) View() string {
 		writeBoxLine(&b, border, s.Dim.Render(m.cachedFilePath), contentWidth)
 	}
 
+	// Composite tool steps, nested under the call
+	for i, step := range m.steps {
+		writeBoxLine(&b, border, stepLine(step, i == len(m.steps)-1, s, contentWidth), contentWidth)
+	}
+
 	// Image blocks
 	if m.showImages {
 		for i := range m.images {
@@ -247,23 +358,35 @@ func (m ToolCallModel) View() string {
 		writeBoxLine(&b, border, s.Error.Render(m.errMsg), contentWidth)
 	}
 
-	// Expanded output
-	if m.expanded && m.output != "" {
-		// Separator line inside box
-		writeBoxLine(&b, border, bs.Render(strings.Repeat(borderChar, contentWidth)), contentWidth)
-
-		// Color edit tool output as a diff
+	// Output: in full when expanded or short, otherwise a summary header
+	// and the first toolOutputLines lines. While the call runs, the last
+	// toolOutputLines lines of what it has streamed so far.
+	if m.output != "" {
+		// Color edit tool output, and diffs from any other tool, as a diff
 		outputText := m.output
-		if IsEditTool(m.name) {
+		if IsEditTool(m.name) || LooksLikeDiff(outputText) {
 			outputText = RenderDiff(outputText, s)
 		}
+		lines := strings.Split(strings.TrimRight(outputText, "\n"), "\n")
+
+		var separator string
+		switch {
+		case !m.done && len(lines) > toolOutputLines:
+			separator = s.Dim.Render(fmt.Sprintf(" %d lines so far ", m.dropped+len(lines)))
+			lines = lines[len(lines)-toolOutputLines:]
+		case !m.expanded && len(lines) > toolOutputLines:
+			separator = s.Dim.Render(fmt.Sprintf(" %d lines, %d more folded ", len(lines), len(lines)-toolOutputLines))
+			lines = lines[:toolOutputLines]
+		}
+		dashes := max(contentWidth-width.VisibleWidth(separator), 0)
+		writeBoxLine(&b, border, separator+bs.Render(strings.Repeat(borderChar, dashes)), contentWidth)
 
-		lines := strings.SplitSeq(strings.TrimRight(outputText, "\n"), "\n")
-		for line := range lines {
+		for _, line := range lines {
 			writeBoxLine(&b, border, line, contentWidth)
 		}
-
-		writeBoxLine(&b, border, "", contentWidth)
+		if len(lines) > 0 {
+			writeBoxLine(&b, border, "", contentWidth)
+		}
 	}
 
 	// Bottom border: same innerWidth as top, using visual column count (1 per corner)
@@ -272,8 +395,9 @@ func (m ToolCallModel) View() string {
 	b.WriteString(bs.Render(cornerBottomRight))
 	b.WriteByte('\n')
 
-	// Expand/collapse hint (only shown when tool is done)
-	if m.done {
+	// Expand/collapse hint (only shown when the tool is done and its output
+	// is longer than the preview)
+	if m.done && m.folds() {
 		if m.expanded {
 			b.WriteString(s.Dim.Render("  Press Ctrl+O to collapse"))
 		} else {
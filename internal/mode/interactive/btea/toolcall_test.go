@@ -1,16 +1,19 @@
 // ABOUTME: Tests for ToolCallModel Bubble Tea leaf component
-// ABOUTME: Verifies tool rendering, Update message routing, expand/collapse toggle
+// ABOUTME: Verifies tool rendering, Update message routing, live tail, expand/collapse toggle
 
 package btea
 
 import (
+	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
 	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/width"
+	"github.com/muesli/termenv"
 )
 
 // Compile-time check: ToolCallModel must satisfy tea.Model.
@@ -118,6 +121,37 @@ func TestToolCallModel_UpdateAgentToolUpdateMsg(t *testing.T) {
 	}
 }
 
+func TestToolCallModel_CompositeStepsNested(t *testing.T) {
+	m := NewToolCallModel("t1", "lint_fix", `{"dir":"."}`)
+	m.width = 100
+
+	var model tea.Model = m
+	for _, step := range []agent.ToolStep{
+		{Index: 0, Tool: "bash", Args: `{"command":"lint"}`},
+		{Index: 0, Tool: "bash", Args: `{"command":"lint"}`, Done: true, Output: "a.go:1: x"},
+		{Index: 1, Tool: "read", Args: `{"path":"a.go"}`, Done: true, IsError: true, Output: "no such file\nmore"},
+	} {
+		model, _ = model.Update(AgentToolUpdateMsg{ToolID: "t1", Step: &step})
+	}
+	tc := model.(ToolCallModel)
+	if len(tc.steps) != 2 {
+		t.Fatalf("steps = %d; want 2 (a finished step replaces its start)", len(tc.steps))
+	}
+	if tc.output != "" {
+		t.Errorf("step reports should not add to output, got %q", tc.output)
+	}
+
+	view := width.StripANSI(tc.View())
+	for _, want := range []string{`├ ✓ bash {"command":"lint"}`, `└ ✗ read {"path":"a.go"} no such file`} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View() missing %q; got:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "more") {
+		t.Error("only the first line of a step error should be shown")
+	}
+}
+
 func TestToolCallModel_ToggleExpand(t *testing.T) {
 	m := NewToolCallModel("t1", "Read", "args")
 	m.width = 80
@@ -168,7 +202,7 @@ func TestToolCallModel_ToggleExpandWorksWhenNotDone(t *testing.T) {
 func TestToolCallModel_HintOnlyShownWhenDone(t *testing.T) {
 	m := NewToolCallModel("t1", "Read", `{"path":"/tmp"}`)
 	m.width = 80
-	m.output = "some output"
+	m.output = strings.Repeat("some output\n", 30)
 	// Not done: hint should not appear
 	view := m.View()
 	if strings.Contains(view, "Ctrl+O") {
@@ -466,3 +500,154 @@ func TestToolCallModel_ToolColorFromStyles(t *testing.T) {
 
 // Suppress unused import lint for lipgloss (used in compile-time type check above).
 var _ = lipgloss.Style{}
+
+func TestToolCallModel_BashDiffOutputIsColored(t *testing.T) {
+	saved := lipgloss.ColorProfile()
+	t.Cleanup(func() { lipgloss.SetColorProfile(saved) })
+	lipgloss.SetColorProfile(termenv.TrueColor)
+
+	m := NewToolCallModel("t1", "bash", `{"command":"git diff"}`)
+	m.width = 80
+	m.done = true
+	m.expanded = true
+	m.output = "--- a/x.go\n+++ b/x.go\n@@ -1 +1 @@\n-old\n+new"
+
+	s := Styles()
+	view := m.View()
+	if !strings.Contains(view, s.DiffAdded.Render("+new")) || !strings.Contains(view, s.DiffRemoved.Render("-old")) {
+		t.Errorf("diff from bash should be colored like edit diffs; got %q", view)
+	}
+}
+
+func TestToolCallModel_FoldsOutputBeyondPreview(t *testing.T) {
+	var lines []string
+	for i := range 2000 {
+		lines = append(lines, fmt.Sprintf("row %d", i))
+	}
+	m := NewToolCallModel("t1", "Read", `{"path":"/tmp/big"}`)
+	m.width = 80
+	m.done = true
+	m.output = strings.Join(lines, "\n")
+
+	view := width.StripANSI(m.View())
+	if !strings.Contains(view, "row 9 ") || strings.Contains(view, "row 10 ") {
+		t.Errorf("collapsed call should show the first %d lines only; got\n%s", toolOutputLines, view)
+	}
+	if !strings.Contains(view, "2000 lines, 1990 more folded") || !strings.Contains(view, "Ctrl+O to expand") {
+		t.Errorf("collapsed call should summarize the folded lines; got\n%s", view)
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlO})
+	if view := width.StripANSI(updated.(ToolCallModel).View()); !strings.Contains(view, "row 1999 ") || strings.Contains(view, "more folded") {
+		t.Error("Ctrl+O should show the whole output")
+	}
+}
+
+func TestToolCallModel_ShortOutputIsNotFolded(t *testing.T) {
+	m := NewToolCallModel("t1", "Bash", `{"command":"ls"}`)
+	m.width = 80
+	m.done = true
+	m.output = "a.go\nb.go\n"
+
+	view := m.View()
+	if !strings.Contains(view, "a.go") || !strings.Contains(view, "b.go") {
+		t.Errorf("output within the preview should be shown; got\n%s", view)
+	}
+	if strings.Contains(view, "Ctrl+O") || strings.Contains(view, "folded") {
+		t.Errorf("nothing is folded, so there is nothing to expand; got\n%s", view)
+	}
+}
+
+func TestToolCallModel_PreviewLengthIsConfigurable(t *testing.T) {
+	defer func(n int) { toolOutputLines = n }(toolOutputLines)
+	m := NewToolCallModel("t1", "Bash", `{"command":"ls"}`)
+	m.width = 80
+	m.done = true
+	m.output = "a.go\nb.go\nc.go"
+
+	toolOutputLines = 2
+	if view := m.View(); !strings.Contains(view, "b.go") || strings.Contains(view, "c.go") || !strings.Contains(view, "3 lines, 1 more folded") {
+		t.Errorf("a 2-line preview should fold the third line; got\n%s", view)
+	}
+	toolOutputLines = 0
+	if view := m.View(); strings.Contains(view, "a.go") || !strings.Contains(view, "3 lines, 3 more folded") {
+		t.Errorf("a zero preview should fold all output; got\n%s", view)
+	}
+}
+
+func TestToolCallModel_SpinnerTickAdvancesWhileRunning(t *testing.T) {
+	m := NewToolCallModel("t1", "Bash", "sleep 90")
+	m.width = 80
+
+	updated, _ := m.Update(SpinnerTickMsg{Time: m.started.Add(65 * time.Second)})
+	tc := updated.(ToolCallModel)
+	view := tc.View()
+	if !strings.Contains(view, glyphs.Spinner[1]) {
+		t.Errorf("spinner did not advance:\n%s", view)
+	}
+	if !strings.Contains(view, "1m05s") {
+		t.Errorf("elapsed time missing:\n%s", view)
+	}
+
+	updated, _ = tc.Update(AgentToolEndMsg{ToolID: "t1", Text: "done"})
+	updated, _ = updated.(ToolCallModel).Update(SpinnerTickMsg{Time: m.started.Add(70 * time.Second)})
+	if view := updated.(ToolCallModel).View(); strings.Contains(view, "1m") {
+		t.Errorf("finished call still shows elapsed time:\n%s", view)
+	}
+}
+
+func TestToolCallModel_LiveTailWhileRunning(t *testing.T) {
+	m := NewToolCallModel("t1", "Bash", "seq 50")
+	m.width = 80
+
+	var out strings.Builder
+	for i := 1; i <= 50; i++ {
+		fmt.Fprintf(&out, "line %d\n", i)
+	}
+	updated, _ := m.Update(AgentToolUpdateMsg{ToolID: "t1", Text: out.String()})
+	view := updated.(ToolCallModel).View()
+	if !strings.Contains(view, "50 lines so far") {
+		t.Errorf("missing live separator:\n%s", view)
+	}
+	if !strings.Contains(view, "line 50") || strings.Contains(view, "line 1 ") {
+		t.Errorf("running call should show the tail of its output:\n%s", view)
+	}
+
+	updated, _ = updated.(ToolCallModel).Update(AgentToolEndMsg{ToolID: "t1", Text: out.String()})
+	view = updated.(ToolCallModel).View()
+	if !strings.Contains(view, "line 1") || strings.Contains(view, "so far") {
+		t.Errorf("finished call should show the head of its output:\n%s", view)
+	}
+}
+
+func TestTrimLiveOutput(t *testing.T) {
+	line := strings.Repeat("x", 99) + "\n"
+	s := strings.Repeat(line, liveOutputMax/len(line)+10)
+
+	got, dropped := trimLiveOutput(s)
+	if len(got) > liveOutputMax {
+		t.Errorf("len = %d; want at most %d", len(got), liveOutputMax)
+	}
+	if !strings.HasPrefix(got, "x") {
+		t.Errorf("trimmed output should start at a line boundary")
+	}
+	if want := strings.Count(s, "\n") - strings.Count(got, "\n"); dropped != want {
+		t.Errorf("dropped = %d; want %d", dropped, want)
+	}
+	if got, dropped := trimLiveOutput("short"); got != "short" || dropped != 0 {
+		t.Errorf("short output changed: %q, %d", got, dropped)
+	}
+}
+
+func TestFormatElapsed(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		3 * time.Second:                   "3s",
+		59*time.Second + time.Millisecond: "59s",
+		65 * time.Second:                  "1m05s",
+		10 * time.Minute:                  "10m00s",
+	} {
+		if got := formatElapsed(d); got != want {
+			t.Errorf("formatElapsed(%v) = %q; want %q", d, got, want)
+		}
+	}
+}
@@ -0,0 +1,366 @@
+// ABOUTME: TranscriptPagerModel: full-screen pager over the whole saved transcript, opened with Ctrl+R
+// ABOUTME: Reads the session file, so nothing is lost to the live view's content cap; / searches, n/N jump between matches
+
+package btea
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/session"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/width"
+)
+
+// TranscriptLoadedMsg carries the session records read for the pager.
+type TranscriptLoadedMsg struct {
+	Records []session.Record
+	Err     error
+}
+
+// loadTranscriptCmd reads the saved session in the background.
+func loadTranscriptCmd(dir, id string) tea.Cmd {
+	return func() tea.Msg {
+		records, err := session.ReadRecordsInDir(dir, id)
+		return TranscriptLoadedMsg{Records: records, Err: err}
+	}
+}
+
+// pagerLineKind selects how a transcript line is styled.
+type pagerLineKind int
+
+const (
+	pagerText pagerLineKind = iota
+	pagerUser
+	pagerAssistant
+	pagerTool
+	pagerResult
+	pagerError
+	pagerMeta
+)
+
+// pagerLine is one logical transcript line before wrapping.
+type pagerLine struct {
+	text   string
+	kind   pagerLineKind
+	indent int
+}
+
+// TranscriptPagerModel pages through the full transcript: every prompt,
+// reply, tool call, and untruncated tool result in the session file.
+type TranscriptPagerModel struct {
+	source  []pagerLine
+	lines   []pagerLine // source wrapped to width
+	width   int
+	height  int
+	top     int // first visible line
+	loading bool
+	err     error
+
+	searching bool   // typing a query after "/"
+	input     string // query being typed
+	query     string // active search, lower-cased
+	matches   []int  // lines containing query
+	match     int    // index into matches of the current match
+}
+
+// NewTranscriptPagerModel creates the pager; the transcript arrives in a
+// TranscriptLoadedMsg.
+func NewTranscriptPagerModel(w, h int) TranscriptPagerModel {
+	return TranscriptPagerModel{width: w, height: h, loading: true}
+}
+
+// Init returns nil; no startup commands needed.
+func (m TranscriptPagerModel) Init() tea.Cmd { return nil }
+
+// Update handles loading, resizing, scrolling, and search keys.
+func (m TranscriptPagerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case TranscriptLoadedMsg:
+		m.loading, m.err = false, msg.Err
+		m.source = transcriptLines(msg.Records)
+		m = m.rewrap()
+		m.top = m.maxTop()
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m = m.rewrap()
+	case tea.KeyMsg:
+		if m.searching {
+			return m.handleSearchKey(msg), nil
+		}
+		return m.handleKey(msg)
+	}
+	return m, nil
+}
+
+func (m TranscriptPagerModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	page := m.bodyHeight()
+	switch msg.String() {
+	case "q", "esc", "ctrl+r":
+		return m, func() tea.Msg { return DismissOverlayMsg{} }
+	case "j", "down", "enter":
+		m.top++
+	case "k", "up":
+		m.top--
+	case " ", "pgdown", "ctrl+f":
+		m.top += page
+	case "b", "pgup", "ctrl+b":
+		m.top -= page
+	case "ctrl+d":
+		m.top += page / 2
+	case "ctrl+u":
+		m.top -= page / 2
+	case "g", "home":
+		m.top = 0
+	case "G", "end":
+		m.top = m.maxTop()
+	case "/":
+		m.searching, m.input = true, ""
+	case "n":
+		m = m.jump(1)
+	case "N":
+		m = m.jump(-1)
+	}
+	m.top = max(min(m.top, m.maxTop()), 0)
+	return m, nil
+}
+
+// handleSearchKey edits the query; Enter runs it, Esc abandons it.
+func (m TranscriptPagerModel) handleSearchKey(msg tea.KeyMsg) TranscriptPagerModel {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.searching = false
+		m.query = strings.ToLower(m.input)
+		m = m.findMatches()
+		m.match = -1
+		for i, line := range m.matches {
+			if line >= m.top {
+				m.match = i - 1
+				break
+			}
+		}
+		return m.jump(1)
+	case tea.KeyEsc:
+		m.searching = false
+	case tea.KeyBackspace:
+		if r := []rune(m.input); len(r) > 0 {
+			m.input = string(r[:len(r)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.input += string(msg.Runes)
+	}
+	return m
+}
+
+// jump moves to the next (dir 1) or previous (dir -1) match, wrapping
+// around, and scrolls it into view.
+func (m TranscriptPagerModel) jump(dir int) TranscriptPagerModel {
+	if len(m.matches) == 0 {
+		return m
+	}
+	m.match = (m.match + dir + len(m.matches)) % len(m.matches)
+	line := m.matches[m.match]
+	if line < m.top || line >= m.top+m.bodyHeight() {
+		m.top = max(min(line-m.bodyHeight()/3, m.maxTop()), 0)
+	}
+	return m
+}
+
+// findMatches lists the wrapped lines containing the active query.
+func (m TranscriptPagerModel) findMatches() TranscriptPagerModel {
+	m.matches = nil
+	if m.query == "" {
+		return m
+	}
+	for i, l := range m.lines {
+		if strings.Contains(strings.ToLower(l.text), m.query) {
+			m.matches = append(m.matches, i)
+		}
+	}
+	m.match = min(m.match, len(m.matches)-1)
+	return m
+}
+
+// rewrap wraps the transcript to the current width, keeping the search.
+func (m TranscriptPagerModel) rewrap() TranscriptPagerModel {
+	w := max(m.width, 20)
+	lines := make([]pagerLine, 0, len(m.source))
+	for _, l := range m.source {
+		for _, part := range width.WrapTextWithAnsi(l.text, max(w-l.indent, 10)) {
+			lines = append(lines, pagerLine{text: part, kind: l.kind, indent: l.indent})
+		}
+	}
+	m.lines = lines
+	m = m.findMatches()
+	m.top = max(min(m.top, m.maxTop()), 0)
+	return m
+}
+
+// bodyHeight is the number of transcript rows above the status line.
+func (m TranscriptPagerModel) bodyHeight() int {
+	return max(m.height-1, 1)
+}
+
+// maxTop is the scroll offset that shows the last page.
+func (m TranscriptPagerModel) maxTop() int {
+	return max(len(m.lines)-m.bodyHeight(), 0)
+}
+
+// View renders one screen of the transcript and a status line.
+func (m TranscriptPagerModel) View() string {
+	s := Styles()
+	body := make([]string, 0, m.bodyHeight())
+	switch {
+	case m.loading:
+		body = append(body, s.Muted.Render("Loading transcript..."))
+	case m.err != nil:
+		body = append(body, s.Error.Render(fmt.Sprintf("Cannot read the transcript: %v", m.err)))
+	default:
+		end := min(m.top+m.bodyHeight(), len(m.lines))
+		for _, l := range m.lines[m.top:end] {
+			body = append(body, strings.Repeat(" ", l.indent)+m.renderLine(l))
+		}
+	}
+	for len(body) < m.bodyHeight() {
+		body = append(body, "")
+	}
+	return strings.Join(append(body, m.statusLine()), "\n")
+}
+
+// renderLine styles a line, highlighting occurrences of the query.
+func (m TranscriptPagerModel) renderLine(l pagerLine) string {
+	style := pagerStyle(l.kind)
+	if m.query == "" {
+		return style.Render(l.text)
+	}
+	var b strings.Builder
+	rest := l.text
+	for {
+		at := strings.Index(strings.ToLower(rest), m.query)
+		if at < 0 || at+len(m.query) > len(rest) {
+			break
+		}
+		b.WriteString(style.Render(rest[:at]))
+		b.WriteString(Styles().Selection.Render(rest[at : at+len(m.query)]))
+		rest = rest[at+len(m.query):]
+	}
+	b.WriteString(style.Render(rest))
+	return b.String()
+}
+
+// statusLine shows the position, search state, and key hints.
+func (m TranscriptPagerModel) statusLine() string {
+	s := Styles()
+	if m.searching {
+		return s.Accent.Render("/") + m.input + s.Muted.Render(glyphs.BarFull)
+	}
+	pos := "transcript"
+	if n := len(m.lines); n > 0 {
+		last := min(m.top+m.bodyHeight(), n)
+		pos = fmt.Sprintf("transcript  lines %d-%d of %d  %d%%", m.top+1, last, n, last*100/n)
+	}
+	if m.query != "" {
+		if len(m.matches) == 0 {
+			pos += fmt.Sprintf("  /%s: no matches", m.query)
+		} else {
+			pos += fmt.Sprintf("  /%s: %d of %d", m.query, m.match+1, len(m.matches))
+		}
+	}
+	return s.Muted.Render(pos + "  " + glyphs.Bullet + " / search  n/N next/prev  q close")
+}
+
+// pagerStyle returns the style for a line kind.
+func pagerStyle(kind pagerLineKind) lipgloss.Style {
+	s := Styles()
+	switch kind {
+	case pagerUser:
+		return s.Accent.Bold(true)
+	case pagerAssistant:
+		return s.Info.Bold(true)
+	case pagerTool:
+		return s.ToolOther
+	case pagerResult, pagerMeta:
+		return s.Muted
+	case pagerError:
+		return s.Error
+	default:
+		return lipgloss.NewStyle()
+	}
+}
+
+// transcriptLines lays out session records as pager lines: a header per
+// prompt and reply, tool calls with their arguments, and tool results in
+// full.
+func transcriptLines(records []session.Record) []pagerLine {
+	var out []pagerLine
+	add := func(kind pagerLineKind, indent int, text string) {
+		text = strings.ReplaceAll(strings.TrimRight(text, "\n"), "\t", "    ")
+		for _, line := range strings.Split(text, "\n") {
+			out = append(out, pagerLine{text: line, kind: kind, indent: indent})
+		}
+	}
+	for i := range records {
+		rec := &records[i]
+		switch rec.Type {
+		case session.RecordSessionStart:
+			var d session.SessionStartData
+			if rec.Unmarshal(&d) == nil {
+				add(pagerMeta, 0, fmt.Sprintf("Session %s %s %s %s %s", d.ID, glyphs.Bullet, d.Model, glyphs.Bullet, d.CWD))
+			}
+		case session.RecordUser:
+			var d session.UserData
+			if rec.Unmarshal(&d) == nil {
+				add(pagerText, 0, "")
+				add(pagerUser, 0, "You")
+				add(pagerText, 2, d.Content)
+			}
+		case session.RecordAssistant:
+			var d session.AssistantData
+			if rec.Unmarshal(&d) == nil && strings.TrimSpace(d.Content) != "" {
+				add(pagerText, 0, "")
+				add(pagerAssistant, 0, "Assistant")
+				add(pagerText, 2, d.Content)
+			}
+		case session.RecordToolCall:
+			var d session.ToolCallData
+			if rec.Unmarshal(&d) == nil {
+				add(pagerTool, 2, strings.TrimSpace(d.Name+" "+compactJSON(d.Args)))
+			}
+		case session.RecordToolResult:
+			var d session.ToolResultData
+			if rec.Unmarshal(&d) == nil {
+				kind := pagerResult
+				if d.IsError {
+					kind = pagerError
+				}
+				add(kind, 4, d.Content)
+			}
+		case session.RecordCompaction:
+			var d session.CompactionData
+			if rec.Unmarshal(&d) == nil {
+				add(pagerText, 0, "")
+				add(pagerMeta, 0, "Earlier turns compacted into:")
+				add(pagerMeta, 2, d.Summary)
+			}
+		case session.RecordUndo:
+			add(pagerMeta, 0, "Last turn undone")
+		}
+	}
+	return out
+}
+
+// compactJSON returns raw on one line, or "" when empty.
+func compactJSON(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var b bytes.Buffer
+	if err := json.Compact(&b, raw); err != nil {
+		return string(raw)
+	}
+	return b.String()
+}
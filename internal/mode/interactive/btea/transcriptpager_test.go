@@ -0,0 +1,193 @@
+// ABOUTME: Tests for the Ctrl+R transcript pager: layout of session records, scrolling, and search
+// ABOUTME: Checks that history beyond the live view's content cap is reachable from the session file
+
+package btea
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/session"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/width"
+)
+
+// record builds a session record holding data.
+func record(t *testing.T, typ session.RecordType, data any) session.Record {
+	t.Helper()
+	raw, err := json.Marshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return session.Record{Type: typ, Data: raw}
+}
+
+// loadedPager returns a pager of the given size showing records.
+func loadedPager(w, h int, records []session.Record) TranscriptPagerModel {
+	updated, _ := NewTranscriptPagerModel(w, h).Update(TranscriptLoadedMsg{Records: records})
+	return updated.(TranscriptPagerModel)
+}
+
+// pagerKeys sends keys to the pager in order.
+func pagerKeys(m TranscriptPagerModel, keys ...tea.KeyMsg) TranscriptPagerModel {
+	for _, k := range keys {
+		updated, _ := m.Update(k)
+		m = updated.(TranscriptPagerModel)
+	}
+	return m
+}
+
+func runes(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+
+func TestTranscriptLines(t *testing.T) {
+	output := strings.Repeat("line of output\n", 40)
+	records := []session.Record{
+		record(t, session.RecordSessionStart, session.SessionStartData{ID: "s1", Model: "m", CWD: "/w"}),
+		record(t, session.RecordUser, session.UserData{Content: "list files"}),
+		record(t, session.RecordToolCall, session.ToolCallData{ID: "1", Name: "bash", Args: json.RawMessage(`{ "command": "ls" }`)}),
+		record(t, session.RecordToolResult, session.ToolResultData{ID: "1", Content: output}),
+		record(t, session.RecordToolResult, session.ToolResultData{ID: "2", Content: "boom", IsError: true}),
+		record(t, session.RecordAssistant, session.AssistantData{Content: "Here\tthey are."}),
+		record(t, session.RecordCompaction, session.CompactionData{Summary: "we listed files"}),
+	}
+	lines := transcriptLines(records)
+
+	kinds := map[string]pagerLineKind{}
+	results := 0
+	for _, l := range lines {
+		kinds[l.text] = l.kind
+		if l.text == "line of output" {
+			results++
+		}
+	}
+	for text, kind := range map[string]pagerLineKind{
+		"You":                   pagerUser,
+		"list files":            pagerText,
+		`bash {"command":"ls"}`: pagerTool,
+		"boom":                  pagerError,
+		"Assistant":             pagerAssistant,
+		"Here    they are.":     pagerText,
+		"we listed files":       pagerMeta,
+	} {
+		if got, ok := kinds[text]; !ok || got != kind {
+			t.Errorf("line %q: kind %v, present %v; want kind %v", text, got, ok, kind)
+		}
+	}
+	if results != 40 {
+		t.Errorf("tool output should be shown in full; got %d of 40 lines", results)
+	}
+}
+
+func TestTranscriptPager_Scrolling(t *testing.T) {
+	var records []session.Record
+	for i := range 30 {
+		records = append(records, record(t, session.RecordUser, session.UserData{Content: fmt.Sprintf("prompt %d", i)}))
+	}
+	m := loadedPager(40, 11, records)
+	if m.top != m.maxTop() || !strings.Contains(m.View(), "prompt 29") {
+		t.Fatalf("the pager should open at the end; top=%d view:\n%s", m.top, m.View())
+	}
+	if got := len(strings.Split(m.View(), "\n")); got != 11 {
+		t.Errorf("view has %d rows; want the full height 11", got)
+	}
+
+	m = pagerKeys(m, runes("g"))
+	if m.top != 0 || !strings.Contains(m.View(), "prompt 0") {
+		t.Errorf("g should go to the top; top=%d", m.top)
+	}
+	m = pagerKeys(m, runes("k"))
+	if m.top != 0 {
+		t.Errorf("scrolling above the top should clamp; top=%d", m.top)
+	}
+	m = pagerKeys(m, runes(" "))
+	if m.top != 10 {
+		t.Errorf("space should page down by the body height; top=%d", m.top)
+	}
+	m = pagerKeys(m, runes("G"), runes("j"))
+	if m.top != m.maxTop() {
+		t.Errorf("scrolling past the end should clamp; top=%d, max %d", m.top, m.maxTop())
+	}
+}
+
+func TestTranscriptPager_Search(t *testing.T) {
+	var records []session.Record
+	for i := range 30 {
+		text := fmt.Sprintf("prompt %d", i)
+		if i == 3 || i == 20 {
+			text = "the Needle is here"
+		}
+		records = append(records, record(t, session.RecordUser, session.UserData{Content: text}))
+	}
+	m := pagerKeys(loadedPager(40, 11, records), runes("g"), runes("/"), runes("needle"))
+	if !m.searching || !strings.Contains(width.StripANSI(m.statusLine()), "/needle") {
+		t.Fatalf("typing after / should edit the query; status %q", m.statusLine())
+	}
+
+	m = pagerKeys(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if len(m.matches) != 2 || m.match != 0 {
+		t.Fatalf("matches = %v, current %d; want 2 matches, the first current", m.matches, m.match)
+	}
+	if status := width.StripANSI(m.statusLine()); !strings.Contains(status, "/needle: 1 of 2") {
+		t.Errorf("status = %q; want the match position", status)
+	}
+
+	m = pagerKeys(m, runes("n"))
+	second := m.matches[1]
+	if m.match != 1 || second < m.top || second >= m.top+m.bodyHeight() {
+		t.Errorf("n should scroll the second match into view; top=%d match line %d", m.top, second)
+	}
+	if !strings.Contains(m.View(), Styles().Selection.Render("Needle")) {
+		t.Errorf("the match should be highlighted; view:\n%q", m.View())
+	}
+	m = pagerKeys(m, runes("n"))
+	if m.match != 0 {
+		t.Errorf("n should wrap around to the first match; match=%d", m.match)
+	}
+	m = pagerKeys(m, runes("N"))
+	if m.match != 1 {
+		t.Errorf("N should go back to the last match; match=%d", m.match)
+	}
+}
+
+func TestTranscriptPager_CtrlRShowsHistoryBeyondTheLiveView(t *testing.T) {
+	m := forkTestModel(t)
+	for i := range maxVisibleContent {
+		if err := m.deps.Session.AddUserMessage(fmt.Sprintf("later prompt %d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	result, _ := m.Update(tea.WindowSizeMsg{Width: 80, Height: 20})
+	m = result.(AppModel)
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyCtrlR})
+	pager, ok := m.overlay.(TranscriptPagerModel)
+	if !ok || pager.loading {
+		t.Fatalf("ctrl+r should open the loaded pager; overlay %T", m.overlay)
+	}
+	m = press(m, runes("g"))
+	if view := m.View(); !strings.Contains(view, "first question") || !strings.Contains(view, "first answer") {
+		t.Errorf("the pager should start from the first turn; view:\n%s", view)
+	}
+
+	m = press(m, runes("q"))
+	if m.overlay != nil {
+		t.Errorf("q should close the pager; overlay %T", m.overlay)
+	}
+}
+
+func TestTranscriptPager_CtrlRWithoutSession(t *testing.T) {
+	m := press(NewAppModel(testDeps()), tea.KeyMsg{Type: tea.KeyCtrlR})
+	if m.overlay != nil {
+		t.Errorf("without a saved session there is nothing to page; overlay %T", m.overlay)
+	}
+}
+
+func TestTranscriptPager_ReadError(t *testing.T) {
+	updated, _ := NewTranscriptPagerModel(40, 5).Update(TranscriptLoadedMsg{Err: fmt.Errorf("gone")})
+	if view := updated.(TranscriptPagerModel).View(); !strings.Contains(view, "Cannot read the transcript: gone") {
+		t.Errorf("a read error should be shown; view:\n%s", view)
+	}
+}
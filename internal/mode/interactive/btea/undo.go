@@ -0,0 +1,107 @@
+// ABOUTME: /undo: drops the last turn from the conversation and restores the files it changed
+// ABOUTME: Files come back from the turn's checkpoints; the prompt goes back to the editor
+
+package btea
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+// undoTurn removes the last prompt and everything after it from the
+// conversation, the saved session, and the screen, and puts back the files
+// the turn changed. Edits made through bash are not tracked and stay.
+func (m AppModel) undoTurn() (AppModel, string) {
+	if m.agentRunning {
+		return m, "Wait for the agent to finish (or press Esc to stop it) before undoing."
+	}
+	start := -1
+	for i := len(m.messages) - 1; i >= m.undoFloor; i-- {
+		if isPrompt(m.messages[i]) {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		if m.undoFloor > 0 {
+			return m, "Nothing to undo since the last compaction."
+		}
+		return m, "Nothing to undo."
+	}
+
+	cut, prompt := -1, ""
+	for i := len(m.content) - 1; i >= 0; i-- {
+		if um, ok := m.content[i].(UserMsgModel); ok && !strings.HasPrefix(um.text, "!") {
+			cut, prompt = i, um.text
+			break
+		}
+	}
+	m, notice := m.rewind(start, cut)
+	if prompt != "" && m.editor.Text() == "" {
+		m.editor = m.editor.SetText(prompt)
+	}
+	return m, "Undid the last turn." + notice
+}
+
+// rewind drops the turns from message start on: from the conversation, from
+// the saved session, and from the screen at content index cut (-1 leaves
+// the screen). Files the turns changed are restored from their checkpoints.
+// It returns what was restored, as lines to append to a notice.
+func (m AppModel) rewind(start, cut int) (AppModel, string) {
+	turns, usedBash := 0, false
+	for _, msg := range m.messages[start:] {
+		if isPrompt(msg) {
+			turns++
+		}
+		for _, c := range msg.Content {
+			usedBash = usedBash || (c.Type == ai.ContentToolUse && c.Name == "bash")
+		}
+	}
+
+	var b strings.Builder
+	if m.deps.Checkpoints.Turns() > 0 {
+		var restored []string
+		var errs []string
+		for range min(turns, m.deps.Checkpoints.Turns()) {
+			lines, err := m.deps.Checkpoints.Undo()
+			restored = append(restored, lines...)
+			if err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+		if len(restored) > 0 {
+			b.WriteString("\n  " + strings.Join(restored, "\n  "))
+		}
+		if len(errs) > 0 {
+			fmt.Fprintf(&b, "\nSome files could not be restored: %s", strings.Join(errs, "; "))
+		}
+	} else {
+		b.WriteString(" No file checkpoints were kept, so files are unchanged.")
+	}
+	if usedBash {
+		b.WriteString("\nChanges made by bash commands are not rolled back; check `git status`.")
+	}
+
+	m.messages = m.messages[:start:start]
+	if m.deps.Session != nil {
+		for range turns {
+			if _, err := m.deps.Session.UndoTurn(); err != nil {
+				fmt.Fprintf(&b, "\nCould not save the undo in the session: %v", err)
+				break
+			}
+		}
+	}
+	if cut >= 0 {
+		m.content = m.content[:cut]
+	}
+	m.lastPromptTokens = 0
+	return m.syncReminders(), b.String()
+}
+
+// isPrompt reports whether msg is a prompt the user typed, as opposed to
+// tool results and reminders.
+func isPrompt(msg ai.Message) bool {
+	return msg.Role == ai.RoleUser && !msg.Ephemeral && hasText(msg)
+}
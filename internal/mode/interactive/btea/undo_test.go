@@ -0,0 +1,109 @@
+// ABOUTME: Tests for /undo: the last turn leaves the conversation, the session, and the screen
+// ABOUTME: Files the turn changed are restored from its checkpoints and the prompt returns to the editor
+
+package btea
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/ide"
+	"github.com/mauromedda/pi-coding-agent-go/internal/session"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+// runTurn submits prompt and finishes the turn with reply; during the turn
+// change writes path, as the write tool would.
+func runTurn(t *testing.T, m AppModel, prompt, reply, path, content string) AppModel {
+	t.Helper()
+	m, _ = m.submitPrompt(prompt)
+	if err := m.deps.Checkpoints.Snapshot(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	msgs := append([]ai.Message{}, m.messages...)
+	msgs = append(msgs,
+		ai.Message{Role: ai.RoleAssistant, Content: []ai.Content{{Type: ai.ContentToolUse, ID: "t1", Name: "bash"}}},
+		ai.Message{Role: ai.RoleUser, Content: []ai.Content{{Type: ai.ContentToolResult, ID: "t1", ResultText: "ok"}}},
+		ai.NewTextMessage(ai.RoleAssistant, reply),
+	)
+	result, _ := m.Update(AgentDoneMsg{Messages: msgs})
+	return result.(AppModel)
+}
+
+func TestAppModel_UndoRollsBackTurn(t *testing.T) {
+	deps := testDepsWithSession(t)
+	deps.Checkpoints = ide.NewTurnCheckpoints()
+	file := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(file, []byte("v1"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m := NewAppModel(deps)
+	m = runTurn(t, m, "make it v2", "done", file, "v2")
+	m = runTurn(t, m, "make it v3", "done again", file, "v3")
+
+	m, _ = m.handleSlashCommand("/undo")
+	if got, _ := os.ReadFile(file); string(got) != "v2" {
+		t.Errorf("main.go = %q; want v2, from before the undone turn", got)
+	}
+	if len(m.messages) != 4 || m.messages[len(m.messages)-1].Content[0].Text != "done" {
+		t.Errorf("messages = %+v; want the first turn only", m.messages)
+	}
+	if len(deps.Session.Messages) != 2 {
+		t.Errorf("session messages = %d; want the first turn only", len(deps.Session.Messages))
+	}
+	if got := m.editor.Text(); got != "make it v3" {
+		t.Errorf("editor = %q; want the undone prompt back", got)
+	}
+	notice := m.lastAssistantText()
+	for _, want := range []string{"Undid the last turn", "restored " + file, "bash commands are not rolled back"} {
+		if !strings.Contains(notice, want) {
+			t.Errorf("notice missing %q:\n%s", want, notice)
+		}
+	}
+	for _, c := range m.content {
+		if um, ok := c.(UserMsgModel); ok && um.text == "make it v3" {
+			t.Error("the undone prompt is still on screen")
+		}
+	}
+
+	records, _ := session.ReadRecordsInDir(filepath.Join(deps.Session.CWD, "sessions"), deps.Session.ID)
+	if msgs, _ := session.BuildSessionContext(records); len(msgs) != 2 {
+		t.Errorf("resumed session has %d messages; want the undone turn left out", len(msgs))
+	}
+
+	m.editor = m.editor.SetText("")
+	m, _ = m.handleSlashCommand("/undo")
+	if got, _ := os.ReadFile(file); string(got) != "v1" || len(m.messages) != 0 {
+		t.Errorf("after second undo: main.go = %q, %d messages", got, len(m.messages))
+	}
+	m, _ = m.handleSlashCommand("/undo")
+	if got := m.lastAssistantText(); got != "Nothing to undo." {
+		t.Errorf("third undo = %q", got)
+	}
+}
+
+func TestAppModel_UndoWaitsForAgentAndCompaction(t *testing.T) {
+	m := NewAppModel(testDeps())
+	m.messages = []ai.Message{
+		ai.NewTextMessage(ai.RoleUser, "[Context Summary]\n..."),
+		ai.NewTextMessage(ai.RoleAssistant, "I understand the context."),
+	}
+	m.agentRunning = true
+	m, _ = m.handleSlashCommand("/undo")
+	if !strings.Contains(m.lastAssistantText(), "Wait for the agent") {
+		t.Errorf("undo while running = %q", m.lastAssistantText())
+	}
+
+	m.agentRunning = false
+	result, _ := m.Update(CompactDoneMsg{Messages: m.messages})
+	m = result.(AppModel)
+	m, _ = m.handleSlashCommand("/undo")
+	if len(m.messages) != 2 || !strings.Contains(m.lastAssistantText(), "since the last compaction") {
+		t.Errorf("undo past compaction: %d messages, %q", len(m.messages), m.lastAssistantText())
+	}
+}
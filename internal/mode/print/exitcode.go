@@ -0,0 +1,53 @@
+// ABOUTME: Exit-code contract for print mode so scripts can branch on the run outcome
+// ABOUTME: ExitError carries the code; ExitCode maps any error returned by RunWithConfig
+
+package print
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Process exit codes returned by print mode.
+const (
+	ExitSuccess          = 0 // Agent finished normally
+	ExitFailure          = 1 // Generic failure (bad flags, config, I/O)
+	ExitMaxTurns         = 2 // --max-turns limit reached
+	ExitBudgetExceeded   = 3 // --max-budget-usd limit reached
+	ExitPermissionDenied = 4 // The run ended on a tool call blocked by the permission checker
+	ExitProviderError    = 5 // Provider, network, or authentication failure
+)
+
+// ExitError reports a non-successful print-mode outcome together with the
+// process exit code the CLI should terminate with.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+// Error implements the error interface.
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit status %d", e.Code)
+	}
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying cause.
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode maps an error returned by RunWithConfig to a process exit code.
+// nil maps to ExitSuccess; errors without an ExitError in their chain map
+// to ExitFailure.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitSuccess
+	}
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	return ExitFailure
+}
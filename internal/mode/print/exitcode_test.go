@@ -0,0 +1,228 @@
+// ABOUTME: Tests for the print-mode exit-code contract
+// ABOUTME: Covers ExitCode mapping, limit precedence, permission denials, and provider errors
+
+package print
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+func TestExitCode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, ExitSuccess},
+		{"plain error", errors.New("boom"), ExitFailure},
+		{"exit error", &ExitError{Code: ExitBudgetExceeded}, ExitBudgetExceeded},
+		{"wrapped exit error", fmt.Errorf("run: %w", &ExitError{Code: ExitProviderError}), ExitProviderError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := ExitCode(tt.err); got != tt.want {
+				t.Errorf("ExitCode(%v) = %d; want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExitError_Unwrap(t *testing.T) {
+	t.Parallel()
+
+	cause := errors.New("401 unauthorized")
+	err := &ExitError{Code: ExitProviderError, Err: cause}
+	if !errors.Is(err, cause) {
+		t.Error("expected ExitError to unwrap to its cause")
+	}
+	if err.Error() != "401 unauthorized" {
+		t.Errorf("Error() = %q; want cause message", err.Error())
+	}
+}
+
+func TestLimitExitCode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		cfg     Config
+		turns   int
+		costUSD float64
+		want    int
+	}{
+		{"no limits", Config{}, 10, 10, ExitSuccess},
+		{"turn limit", Config{MaxTurns: 2}, 2, 0, ExitMaxTurns},
+		{"budget limit", Config{MaxBudgetUSD: 1}, 0, 1, ExitBudgetExceeded},
+		{"both hit prefers turns", Config{MaxTurns: 1, MaxBudgetUSD: 1}, 1, 2, ExitMaxTurns},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := limitExitCode(tt.cfg, tt.turns, tt.costUSD); got != tt.want {
+				t.Errorf("limitExitCode() = %d; want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunWithConfig_PermissionDeniedExitCode(t *testing.T) {
+	provider := &mockProvider{
+		responses: []*ai.AssistantMessage{
+			{
+				Content: []ai.Content{
+					{Type: ai.ContentToolUse, ID: "t1", Name: "bash", Input: json.RawMessage(`{"command":"rm -rf /"}`)},
+				},
+				StopReason: ai.StopToolUse,
+			},
+			{
+				Content:    []ai.Content{{Type: ai.ContentText, Text: "I could not run that."}},
+				StopReason: ai.StopEndTurn,
+			},
+		},
+	}
+
+	bashTool := &agent.AgentTool{
+		Name: "bash",
+		Execute: func(_ context.Context, _ string, _ map[string]any, _ func(agent.ToolUpdate)) (agent.ToolResult, error) {
+			t.Error("denied tool must not execute")
+			return agent.ToolResult{}, nil
+		},
+	}
+
+	deps := Deps{
+		Provider: provider,
+		Model:    newTestModel(),
+		Tools:    []*agent.AgentTool{bashTool},
+		PermCheck: func(tool string, _ map[string]any) error {
+			return fmt.Errorf("tool %q denied by rule", tool)
+		},
+	}
+
+	var err error
+	_ = captureStdout(t, func() {
+		err = RunWithConfig(context.Background(), Config{OutputFormat: "text"}, deps, "clean up")
+	})
+
+	if code := ExitCode(err); code != ExitPermissionDenied {
+		t.Errorf("ExitCode = %d; want %d (err: %v)", code, ExitPermissionDenied, err)
+	}
+}
+
+func TestRunWithConfig_DeniedThenCompleted(t *testing.T) {
+	provider := &mockProvider{
+		responses: []*ai.AssistantMessage{
+			{
+				Content: []ai.Content{
+					{Type: ai.ContentToolUse, ID: "t1", Name: "bash", Input: json.RawMessage(`{"command":"rm -rf build"}`)},
+				},
+				StopReason: ai.StopToolUse,
+			},
+			{
+				Content: []ai.Content{
+					{Type: ai.ContentToolUse, ID: "t2", Name: "read", Input: json.RawMessage(`{"path":"Makefile"}`)},
+				},
+				StopReason: ai.StopToolUse,
+			},
+			{
+				Content:    []ai.Content{{Type: ai.ContentText, Text: "Run make clean instead."}},
+				StopReason: ai.StopEndTurn,
+			},
+		},
+	}
+
+	ran := false
+	readTool := &agent.AgentTool{
+		Name:     "read",
+		ReadOnly: true,
+		Execute: func(_ context.Context, _ string, _ map[string]any, _ func(agent.ToolUpdate)) (agent.ToolResult, error) {
+			ran = true
+			return agent.ToolResult{Content: "clean:"}, nil
+		},
+	}
+	bashTool := &agent.AgentTool{
+		Name: "bash",
+		Execute: func(_ context.Context, _ string, _ map[string]any, _ func(agent.ToolUpdate)) (agent.ToolResult, error) {
+			t.Error("denied tool must not execute")
+			return agent.ToolResult{}, nil
+		},
+	}
+
+	deps := Deps{
+		Provider: provider,
+		Model:    newTestModel(),
+		Tools:    []*agent.AgentTool{bashTool, readTool},
+		PermCheck: func(tool string, _ map[string]any) error {
+			if tool == "bash" {
+				return fmt.Errorf("tool %q denied by rule", tool)
+			}
+			return nil
+		},
+	}
+
+	var err error
+	_ = captureStdout(t, func() {
+		err = RunWithConfig(context.Background(), Config{OutputFormat: "text"}, deps, "clean up")
+	})
+
+	if !ran {
+		t.Error("allowed tool did not run after the denial")
+	}
+	if code := ExitCode(err); code != ExitSuccess {
+		t.Errorf("ExitCode = %d; want %d for a run that completed after a denial (err: %v)", code, ExitSuccess, err)
+	}
+}
+
+func TestRunWithConfig_ProviderErrorExitCode(t *testing.T) {
+	// An exhausted mock provider fails the stream, standing in for a
+	// network or authentication failure.
+	provider := &mockProvider{}
+
+	var err error
+	_ = captureStderr(t, func() {
+		_ = captureStdout(t, func() {
+			err = RunWithConfig(context.Background(), Config{OutputFormat: "text"}, Deps{
+				Provider: provider,
+				Model:    newTestModel(),
+			}, "hello")
+		})
+	})
+
+	if code := ExitCode(err); code != ExitProviderError {
+		t.Errorf("ExitCode = %d; want %d (err: %v)", code, ExitProviderError, err)
+	}
+}
+
+func TestRunWithConfig_SuccessExitCode(t *testing.T) {
+	provider := &mockProvider{
+		responses: []*ai.AssistantMessage{
+			{
+				Content:    []ai.Content{{Type: ai.ContentText, Text: "ok"}},
+				StopReason: ai.StopEndTurn,
+			},
+		},
+	}
+
+	var err error
+	_ = captureStdout(t, func() {
+		err = RunWithConfig(context.Background(), Config{OutputFormat: "text"}, Deps{
+			Provider: provider,
+			Model:    newTestModel(),
+		}, "hello")
+	})
+
+	if code := ExitCode(err); code != ExitSuccess {
+		t.Errorf("ExitCode = %d; want %d (err: %v)", code, ExitSuccess, err)
+	}
+}
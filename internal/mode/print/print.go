@@ -38,9 +38,11 @@ const (
 
 // Deps provides dependencies for print mode.
 type Deps struct {
-	Provider ai.ApiProvider
-	Model    *ai.Model
-	Tools    []*agent.AgentTool
+	Provider  ai.ApiProvider
+	Model     *ai.Model
+	Tools     []*agent.AgentTool
+	PermCheck agent.PermCheckFunc // optional; nil allows every tool; denials go back to the model
+	Input     io.Reader           // optional; defaults to os.Stdin
 }
 
 // Run executes the agent in non-interactive mode with the given configuration.
@@ -51,22 +53,52 @@ func Run(ctx context.Context, provider ai.ApiProvider, model *ai.Model, prompt s
 }
 
 // RunWithConfig executes print mode with full configuration.
+// A non-successful outcome is reported as an *ExitError; use ExitCode to
+// translate the returned error into a process exit code.
 func RunWithConfig(ctx context.Context, cfg Config, deps Deps, prompt string) error {
+	input := deps.Input
+	if input == nil {
+		input = os.Stdin
+	}
+
+	if cfg.OutputFormat == "" {
+		cfg.OutputFormat = "text"
+	}
+
+	if cfg.InputFormat == "stream-json" {
+		return runStreamInput(ctx, cfg, deps, input, prompt)
+	}
+	if cfg.InputFormat != "" && cfg.InputFormat != "text" {
+		return fmt.Errorf("unknown input format %q (want text or stream-json)", cfg.InputFormat)
+	}
+
 	if prompt == "" {
-		data, err := io.ReadAll(os.Stdin)
+		data, err := io.ReadAll(input)
 		if err != nil {
 			return fmt.Errorf("reading stdin: %w", err)
 		}
 		prompt = string(data)
 	}
 
-	if cfg.OutputFormat == "" {
-		cfg.OutputFormat = "text"
+	formatter := newFormatter(cfg.OutputFormat)
+
+	llmCtx := newLLMContext(cfg, deps)
+	llmCtx.Messages = []ai.Message{ai.NewTextMessage(ai.RoleUser, prompt)}
+
+	opts := &ai.StreamOptions{MaxTokens: 4096}
+
+	// If we have tools, use the full agent loop
+	if len(deps.Tools) > 0 {
+		return runAgentLoop(ctx, cfg, deps, llmCtx, opts, formatter, &runState{})
 	}
 
-	formatter := newFormatter(cfg.OutputFormat)
+	// Simple streaming without tools
+	return runSimpleStream(ctx, deps, llmCtx, opts, formatter)
+}
 
-	// Build system prompt
+// newLLMContext builds the system prompt and tool definitions shared by
+// every turn of a print-mode run.
+func newLLMContext(cfg Config, deps Deps) *ai.Context {
 	system := cfg.SystemPrompt
 	if cfg.AppendSystemPrompt != "" {
 		if system != "" {
@@ -75,45 +107,38 @@ func RunWithConfig(ctx context.Context, cfg Config, deps Deps, prompt string) er
 		system += cfg.AppendSystemPrompt
 	}
 
-	llmCtx := &ai.Context{
-		System: system,
-		Messages: []ai.Message{
-			ai.NewTextMessage(ai.RoleUser, prompt),
-		},
-	}
-
-	// Add tools to context
-	if len(deps.Tools) > 0 {
-		for _, t := range deps.Tools {
-			schema := t.Parameters
-			if schema == nil {
-				schema = json.RawMessage(`{}`)
-			}
-			llmCtx.Tools = append(llmCtx.Tools, ai.Tool{
-				Name:        t.Name,
-				Description: t.Description,
-				Parameters:  schema,
-			})
+	llmCtx := &ai.Context{System: system}
+	for _, t := range deps.Tools {
+		schema := t.Parameters
+		if schema == nil {
+			schema = json.RawMessage(`{}`)
 		}
+		llmCtx.Tools = append(llmCtx.Tools, ai.Tool{
+			Name:        t.Name,
+			Description: t.Description,
+			Parameters:  schema,
+		})
 	}
+	return llmCtx
+}
 
-	opts := &ai.StreamOptions{MaxTokens: 4096}
-
-	// If we have tools, use the full agent loop
-	if len(deps.Tools) > 0 {
-		return runAgentLoop(ctx, cfg, deps, llmCtx, opts, formatter)
-	}
-
-	// Simple streaming without tools
-	return runSimpleStream(ctx, deps, llmCtx, opts, formatter)
+// runState accumulates turn and cost counters across agent runs so that
+// limits apply to a whole stream-json session, not just a single message.
+type runState struct {
+	turns   int
+	costUSD float64
 }
 
-func runAgentLoop(ctx context.Context, cfg Config, deps Deps, llmCtx *ai.Context, opts *ai.StreamOptions, f formatter) error {
-	ag := agent.New(deps.Provider, deps.Model, deps.Tools)
+func runAgentLoop(ctx context.Context, cfg Config, deps Deps, llmCtx *ai.Context, opts *ai.StreamOptions, f formatter, state *runState) error {
+	ag := agent.NewWithPermissions(deps.Provider, deps.Model, deps.Tools, deps.PermCheck)
 	events := ag.Prompt(ctx, llmCtx, opts)
 
-	turns := 0
-	var cumulativeCostUSD float64
+	var providerErr error
+	// A denied call ends without having started. Only a denial that was
+	// the run's last tool call fails it: one the model worked around is
+	// reported back to the model and the run still succeeds.
+	started := make(map[string]bool)
+	var lastDenial string
 	f.start()
 
 	for evt := range events {
@@ -121,30 +146,45 @@ func runAgentLoop(ctx context.Context, cfg Config, deps Deps, llmCtx *ai.Context
 		case agent.EventAssistantText:
 			f.text(evt.Text)
 		case agent.EventToolStart:
+			started[evt.ToolID] = true
 			f.toolStart(evt.ToolName, evt.ToolArgs)
 		case agent.EventToolEnd:
 			if evt.ToolResult != nil {
 				f.toolEnd(evt.ToolName, evt.ToolResult)
 			}
-			turns++
+			lastDenial = ""
+			if !started[evt.ToolID] && evt.ToolResult != nil {
+				lastDenial = evt.ToolResult.Content
+			}
+			state.turns++
 
 			// Budget tracking: estimate cost per turn using conservative defaults.
 			// The agent events don't carry token usage, so we use fixed estimates.
-			cumulativeCostUSD += estimateTurnCost(defaultInputTokensPerTurn, defaultOutputTokensPerTurn)
+			state.costUSD += estimateTurnCost(defaultInputTokensPerTurn, defaultOutputTokensPerTurn)
 
-			if shouldAbort(cfg, turns, cumulativeCostUSD) {
+			if code := limitExitCode(cfg, state.turns, state.costUSD); code != ExitSuccess {
 				ag.Abort()
 				// Drain remaining events to allow the agent goroutine to finish cleanly.
 				drainEvents(events)
 				f.end()
-				return nil
+				return limitError(code, cfg)
 			}
 		case agent.EventError:
 			f.err(evt.Error)
+			if providerErr == nil {
+				providerErr = evt.Error
+			}
 		}
 	}
 
 	f.end()
+
+	if providerErr != nil {
+		return &ExitError{Code: ExitProviderError, Err: providerErr}
+	}
+	if lastDenial != "" {
+		return &ExitError{Code: ExitPermissionDenied, Err: fmt.Errorf("permission denied: %s", lastDenial)}
+	}
 	return nil
 }
 
@@ -155,13 +195,28 @@ func estimateTurnCost(inputTokens, outputTokens int) float64 {
 
 // shouldAbort returns true when the agent should stop due to turn or budget limits.
 func shouldAbort(cfg Config, turns int, costUSD float64) bool {
+	return limitExitCode(cfg, turns, costUSD) != ExitSuccess
+}
+
+// limitExitCode returns ExitMaxTurns or ExitBudgetExceeded when the matching
+// limit has been reached, or ExitSuccess when the agent may continue.
+// The turn limit takes precedence when both are hit at once.
+func limitExitCode(cfg Config, turns int, costUSD float64) int {
 	if cfg.MaxTurns > 0 && turns >= cfg.MaxTurns {
-		return true
+		return ExitMaxTurns
 	}
 	if cfg.MaxBudgetUSD > 0 && costUSD >= cfg.MaxBudgetUSD {
-		return true
+		return ExitBudgetExceeded
 	}
-	return false
+	return ExitSuccess
+}
+
+// limitError builds the ExitError reported when a turn or budget limit stops the agent.
+func limitError(code int, cfg Config) error {
+	if code == ExitMaxTurns {
+		return &ExitError{Code: code, Err: fmt.Errorf("max turns reached (%d)", cfg.MaxTurns)}
+	}
+	return &ExitError{Code: code, Err: fmt.Errorf("budget exceeded ($%.2f)", cfg.MaxBudgetUSD)}
 }
 
 // drainEvents consumes remaining events from the channel so the agent
@@ -174,6 +229,7 @@ func drainEvents(events <-chan agent.AgentEvent) {
 func runSimpleStream(ctx context.Context, deps Deps, llmCtx *ai.Context, opts *ai.StreamOptions, f formatter) error {
 	stream := deps.Provider.Stream(ctx, deps.Model, llmCtx, opts)
 
+	var providerErr error
 	f.start()
 	for event := range stream.Events() {
 		switch event.Type {
@@ -181,9 +237,16 @@ func runSimpleStream(ctx context.Context, deps Deps, llmCtx *ai.Context, opts *a
 			f.text(event.Text)
 		case ai.EventError:
 			f.err(event.Error)
+			if providerErr == nil {
+				providerErr = event.Error
+			}
 		}
 	}
 	f.end()
+
+	if providerErr != nil {
+		return &ExitError{Code: ExitProviderError, Err: providerErr}
+	}
 	return nil
 }
 
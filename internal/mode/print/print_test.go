@@ -194,8 +194,8 @@ func TestRunWithConfig_MaxTurns(t *testing.T) {
 	stderr := captureStderr(t, func() {
 		_ = captureStdout(t, func() {
 			err := RunWithConfig(context.Background(), cfg, deps, "read a file")
-			if err != nil {
-				t.Errorf("unexpected error: %v", err)
+			if code := ExitCode(err); code != ExitMaxTurns {
+				t.Errorf("ExitCode = %d; want %d (err: %v)", code, ExitMaxTurns, err)
 			}
 		})
 	})
@@ -344,8 +344,8 @@ func TestRunWithConfig_MaxBudget(t *testing.T) {
 	stderr := captureStderr(t, func() {
 		_ = captureStdout(t, func() {
 			err := RunWithConfig(context.Background(), cfg, deps, "read files")
-			if err != nil {
-				t.Errorf("unexpected error: %v", err)
+			if code := ExitCode(err); code != ExitBudgetExceeded {
+				t.Errorf("ExitCode = %d; want %d (err: %v)", code, ExitBudgetExceeded, err)
 			}
 		})
 	})
@@ -0,0 +1,161 @@
+// ABOUTME: stream-json input for print mode: one JSON user message per stdin line
+// ABOUTME: Each line runs as a turn on a shared conversation; events go out as stream-json
+
+package print
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+// maxInputLineBytes caps a single stream-json input line.
+const maxInputLineBytes = 10 * 1024 * 1024
+
+// streamInput is one line of stream-json input. Two shapes are accepted:
+//
+//	{"type":"user","text":"fix the tests"}
+//	{"type":"user","message":{"role":"user","content":"fix the tests"}}
+//
+// where message.content may also be an array of {"type":"text","text":...} blocks.
+type streamInput struct {
+	Type    string              `json:"type"`
+	Text    string              `json:"text,omitempty"`
+	Message *streamInputMessage `json:"message,omitempty"`
+}
+
+type streamInputMessage struct {
+	Role    string          `json:"role"`
+	Content json.RawMessage `json:"content"`
+}
+
+// parseStreamInput decodes a stream-json line into the user prompt text.
+func parseStreamInput(line []byte) (string, error) {
+	var in streamInput
+	if err := json.Unmarshal(line, &in); err != nil {
+		return "", fmt.Errorf("invalid stream-json input: %w", err)
+	}
+	if in.Type != "" && in.Type != "user" {
+		return "", fmt.Errorf("unsupported stream-json input type %q", in.Type)
+	}
+
+	text := in.Text
+	if text == "" && in.Message != nil {
+		if in.Message.Role != "" && in.Message.Role != string(ai.RoleUser) {
+			return "", fmt.Errorf("unsupported message role %q", in.Message.Role)
+		}
+		var err error
+		text, err = messageContentText(in.Message.Content)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if strings.TrimSpace(text) == "" {
+		return "", fmt.Errorf("stream-json input has no text")
+	}
+	return text, nil
+}
+
+// messageContentText flattens a message content field that is either a
+// plain string or an array of text blocks.
+func messageContentText(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 {
+		return "", nil
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s, nil
+	}
+
+	var blocks []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(raw, &blocks); err != nil {
+		return "", fmt.Errorf("invalid message content: %w", err)
+	}
+
+	var b strings.Builder
+	for _, blk := range blocks {
+		if blk.Type != "" && blk.Type != string(ai.ContentText) {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(blk.Text)
+	}
+	return b.String(), nil
+}
+
+// runStreamInput reads JSON user messages from r, one per line, and runs each
+// as a turn against a single conversation. Malformed lines produce an error
+// event and are skipped. Turn and budget limits apply to the whole session
+// and end it immediately; other failures (permission denials, provider
+// errors) are reported once input is exhausted.
+func runStreamInput(ctx context.Context, cfg Config, deps Deps, r io.Reader, prompt string) error {
+	if cfg.OutputFormat != "stream-json" {
+		return fmt.Errorf("--input-format stream-json requires --output-format stream-json")
+	}
+
+	f := &streamJSONFormatter{}
+	llmCtx := newLLMContext(cfg, deps)
+	state := &runState{}
+	var firstErr error
+
+	runTurn := func(text string) error {
+		llmCtx.Messages = append(llmCtx.Messages, ai.NewTextMessage(ai.RoleUser, text))
+		opts := &ai.StreamOptions{MaxTokens: 4096}
+		err := runAgentLoop(ctx, cfg, deps, llmCtx, opts, f, state)
+		switch code := ExitCode(err); code {
+		case ExitSuccess:
+			return nil
+		case ExitMaxTurns, ExitBudgetExceeded:
+			return err
+		default:
+			if firstErr == nil {
+				firstErr = err
+			}
+			return nil
+		}
+	}
+
+	if prompt != "" {
+		if err := runTurn(prompt); err != nil {
+			return err
+		}
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxInputLineBytes)
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		text, err := parseStreamInput([]byte(line))
+		if err != nil {
+			f.err(err)
+			continue
+		}
+		if err := runTurn(text); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading stdin: %w", err)
+	}
+
+	return firstErr
+}
@@ -0,0 +1,156 @@
+// ABOUTME: Tests for stream-json input: line parsing and multi-turn execution over stdin
+// ABOUTME: Verifies conversation continuity, malformed-line handling, and format validation
+
+package print
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+// historyProvider wraps mockProvider and records the message count of each call.
+type historyProvider struct {
+	mockProvider
+	mu     sync.Mutex
+	counts []int
+}
+
+func (h *historyProvider) Stream(ctx context.Context, model *ai.Model, llmCtx *ai.Context, opts *ai.StreamOptions) *ai.EventStream {
+	h.mu.Lock()
+	h.counts = append(h.counts, len(llmCtx.Messages))
+	h.mu.Unlock()
+	return h.mockProvider.Stream(ctx, model, llmCtx, opts)
+}
+
+func TestParseStreamInput(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		line    string
+		want    string
+		wantErr bool
+	}{
+		{"text shorthand", `{"type":"user","text":"hello"}`, "hello", false},
+		{"type omitted", `{"text":"hello"}`, "hello", false},
+		{"message string", `{"type":"user","message":{"role":"user","content":"hi there"}}`, "hi there", false},
+		{"message blocks", `{"type":"user","message":{"role":"user","content":[{"type":"text","text":"a"},{"type":"image"},{"type":"text","text":"b"}]}}`, "a\nb", false},
+		{"invalid json", `{not json`, "", true},
+		{"wrong type", `{"type":"control","text":"x"}`, "", true},
+		{"wrong role", `{"type":"user","message":{"role":"assistant","content":"x"}}`, "", true},
+		{"empty text", `{"type":"user","text":"  "}`, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := parseStreamInput([]byte(tt.line))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseStreamInput(%s) error = %v; wantErr %v", tt.line, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseStreamInput(%s) = %q; want %q", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunWithConfig_StreamJSONInput_MultiTurn(t *testing.T) {
+	provider := &historyProvider{mockProvider: mockProvider{
+		responses: []*ai.AssistantMessage{
+			{Content: []ai.Content{{Type: ai.ContentText, Text: "first answer"}}, StopReason: ai.StopEndTurn},
+			{Content: []ai.Content{{Type: ai.ContentText, Text: "second answer"}}, StopReason: ai.StopEndTurn},
+		},
+	}}
+
+	input := strings.Join([]string{
+		`{"type":"user","text":"first"}`,
+		``,
+		`garbage`,
+		`{"type":"user","message":{"role":"user","content":"second"}}`,
+	}, "\n")
+
+	var err error
+	output := captureStdout(t, func() {
+		err = RunWithConfig(context.Background(), Config{
+			OutputFormat: "stream-json",
+			InputFormat:  "stream-json",
+		}, Deps{
+			Provider: provider,
+			Model:    newTestModel(),
+			Input:    strings.NewReader(input),
+		}, "")
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var starts, ends, errs int
+	var texts []string
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		var evt streamEvent
+		if jerr := json.Unmarshal([]byte(line), &evt); jerr != nil {
+			t.Fatalf("invalid output line %q: %v", line, jerr)
+		}
+		switch evt.Type {
+		case "start":
+			starts++
+		case "end":
+			ends++
+		case "error":
+			errs++
+		case "text":
+			texts = append(texts, evt.Text)
+		}
+	}
+
+	if starts != 2 || ends != 2 {
+		t.Errorf("expected 2 start/end pairs, got %d/%d", starts, ends)
+	}
+	if errs != 1 {
+		t.Errorf("expected 1 error event for the malformed line, got %d", errs)
+	}
+	if strings.Join(texts, "|") != "first answer|second answer" {
+		t.Errorf("unexpected text events: %v", texts)
+	}
+
+	// Second turn must see: user, assistant, user.
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+	if len(provider.counts) != 2 || provider.counts[0] != 1 || provider.counts[1] != 3 {
+		t.Errorf("expected message counts [1 3], got %v", provider.counts)
+	}
+}
+
+func TestRunWithConfig_StreamJSONInput_RequiresStreamJSONOutput(t *testing.T) {
+	t.Parallel()
+
+	err := RunWithConfig(context.Background(), Config{
+		OutputFormat: "text",
+		InputFormat:  "stream-json",
+	}, Deps{
+		Provider: &mockProvider{},
+		Model:    newTestModel(),
+		Input:    strings.NewReader(""),
+	}, "")
+	if err == nil || !strings.Contains(err.Error(), "--output-format stream-json") {
+		t.Errorf("expected output-format error, got %v", err)
+	}
+}
+
+func TestRunWithConfig_UnknownInputFormat(t *testing.T) {
+	t.Parallel()
+
+	err := RunWithConfig(context.Background(), Config{InputFormat: "xml"}, Deps{
+		Provider: &mockProvider{},
+		Model:    newTestModel(),
+	}, "hi")
+	if err == nil || !strings.Contains(err.Error(), "unknown input format") {
+		t.Errorf("expected unknown input format error, got %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+// ABOUTME: Event types and per-session fan-out hub for the headless HTTP server
+// ABOUTME: Translates agent events to JSON and delivers them to SSE subscribers
+
+package serve
+
+import (
+	"sync"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+// subscriberBuffer is the per-subscriber event buffer. A subscriber that
+// falls this far behind is disconnected rather than stalling the agent.
+const subscriberBuffer = 1024
+
+// Event is the JSON payload of a single server-sent event.
+type Event struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text,omitempty"`
+	ToolID    string          `json:"tool_id,omitempty"`
+	Tool      string          `json:"tool,omitempty"`
+	Args      map[string]any  `json:"args,omitempty"`
+	IsError   bool            `json:"is_error,omitempty"`
+	Step      *agent.ToolStep `json:"step,omitempty"` // composite tool step
+	Usage     *ai.Usage       `json:"usage,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	RequestID string          `json:"request_id,omitempty"` // permission request ID
+}
+
+// Event type names.
+const (
+	EventAgentStart        = "agent_start"
+	EventAgentEnd          = "agent_end"
+	EventText              = "text"
+	EventThinking          = "thinking"
+	EventToolStart         = "tool_start"
+	EventToolUpdate        = "tool_update"
+	EventToolEnd           = "tool_end"
+	EventUsage             = "usage"
+	EventError             = "error"
+	EventPermissionRequest = "permission_request"
+	EventPermissionReply   = "permission_reply"
+)
+
+// fromAgentEvent converts an agent event into its wire representation.
+// Returns false for events that have no wire mapping.
+func fromAgentEvent(evt agent.AgentEvent) (Event, bool) {
+	switch evt.Type {
+	case agent.EventAgentStart:
+		return Event{Type: EventAgentStart}, true
+	case agent.EventAgentEnd:
+		return Event{Type: EventAgentEnd}, true
+	case agent.EventAssistantText:
+		return Event{Type: EventText, Text: evt.Text}, true
+	case agent.EventAssistantThinking:
+		return Event{Type: EventThinking, Text: evt.Text}, true
+	case agent.EventToolStart:
+		return Event{Type: EventToolStart, ToolID: evt.ToolID, Tool: evt.ToolName, Args: evt.ToolArgs}, true
+	case agent.EventToolUpdate:
+		return Event{Type: EventToolUpdate, ToolID: evt.ToolID, Tool: evt.ToolName, Text: evt.Text, Step: evt.ToolStep}, true
+	case agent.EventToolEnd:
+		out := Event{Type: EventToolEnd, ToolID: evt.ToolID, Tool: evt.ToolName}
+		if evt.ToolResult != nil {
+			out.Text = evt.ToolResult.Content
+			out.IsError = evt.ToolResult.IsError
+		}
+		return out, true
+	case agent.EventUsageUpdate:
+		return Event{Type: EventUsage, Usage: evt.Usage}, true
+	case agent.EventError:
+		out := Event{Type: EventError}
+		if evt.Error != nil {
+			out.Error = evt.Error.Error()
+		}
+		return out, true
+	default:
+		return Event{}, false
+	}
+}
+
+// hub fans events out to any number of subscribers.
+type hub struct {
+	mu   sync.Mutex
+	subs map[chan Event]struct{}
+}
+
+func newHub() *hub {
+	return &hub{subs: make(map[chan Event]struct{})}
+}
+
+// subscribe registers a new subscriber. The returned cancel func must be
+// called when the subscriber goes away.
+func (h *hub) subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+	h.mu.Lock()
+	h.subs[ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.subs[ch]; ok {
+			delete(h.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// publish delivers evt to every subscriber without blocking. Subscribers
+// whose buffer is full are dropped.
+func (h *hub) publish(evt Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs {
+		select {
+		case ch <- evt:
+		default:
+			delete(h.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// closeAll disconnects every subscriber.
+func (h *hub) closeAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs {
+		delete(h.subs, ch)
+		close(ch)
+	}
+}
@@ -0,0 +1,419 @@
+// ABOUTME: Headless HTTP server mode (pi-go serve) exposing the agent core over REST and SSE
+// ABOUTME: Routes: sessions CRUD, send message, event stream, permission replies, abort
+
+package serve
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
+	pilog "github.com/mauromedda/pi-coding-agent-go/internal/log"
+	"github.com/mauromedda/pi-coding-agent-go/internal/permission"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+// DefaultAddr binds to loopback only; exposing the agent on other interfaces
+// must be an explicit choice.
+const DefaultAddr = "127.0.0.1:8787"
+
+// maxBodyBytes caps request bodies.
+const maxBodyBytes = 1 << 20
+
+// sseKeepalive is the interval between SSE comment keepalives.
+const sseKeepalive = 15 * time.Second
+
+// Config configures the HTTP server.
+type Config struct {
+	Addr  string // listen address; defaults to DefaultAddr
+	Token string // bearer token required on every request; generated when empty
+}
+
+// GenerateToken returns a random bearer token for a server started
+// without one.
+func GenerateToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating serve token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Deps provides the agent dependencies shared by all sessions.
+type Deps struct {
+	Provider     ai.ApiProvider
+	Model        *ai.Model
+	Tools        []*agent.AgentTool
+	Checker      *permission.Checker // optional; nil allows every tool
+	SystemPrompt string
+}
+
+// Server serves the agent API for any number of concurrent sessions.
+type Server struct {
+	cfg  Config
+	deps *Deps
+	ctx  context.Context // parent context for agent turns
+
+	mu       sync.RWMutex
+	sessions map[string]*session
+}
+
+// NewServer creates a Server. Agent turns are cancelled when ctx is done.
+// A config without a token gets a generated one, read back with Token, so
+// the API is never open.
+func NewServer(ctx context.Context, cfg Config, deps Deps) (*Server, error) {
+	if cfg.Addr == "" {
+		cfg.Addr = DefaultAddr
+	}
+	if cfg.Token == "" {
+		token, err := GenerateToken()
+		if err != nil {
+			return nil, err
+		}
+		cfg.Token = token
+	}
+	return &Server{
+		cfg:      cfg,
+		deps:     &deps,
+		ctx:      ctx,
+		sessions: make(map[string]*session),
+	}, nil
+}
+
+// Token returns the bearer token clients must send.
+func (s *Server) Token() string {
+	return s.cfg.Token
+}
+
+// Handler returns the HTTP handler with all routes registered.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/health", s.handleHealth)
+	mux.HandleFunc("GET /v1/sessions", s.handleListSessions)
+	mux.HandleFunc("POST /v1/sessions", s.handleCreateSession)
+	mux.HandleFunc("GET /v1/sessions/{id}", s.handleGetSession)
+	mux.HandleFunc("DELETE /v1/sessions/{id}", s.handleDeleteSession)
+	mux.HandleFunc("POST /v1/sessions/{id}/messages", s.handleSendMessage)
+	mux.HandleFunc("GET /v1/sessions/{id}/events", s.handleEvents)
+	mux.HandleFunc("POST /v1/sessions/{id}/permissions/{req}", s.handlePermission)
+	mux.HandleFunc("POST /v1/sessions/{id}/abort", s.handleAbort)
+	return s.guard(mux)
+}
+
+// Run listens on the configured address and serves until ctx is cancelled.
+func Run(ctx context.Context, cfg Config, deps Deps) error {
+	srv, err := NewServer(ctx, cfg, deps)
+	if err != nil {
+		return err
+	}
+	ln, err := net.Listen("tcp", srv.cfg.Addr)
+	if err != nil {
+		return fmt.Errorf("listening on %s: %w", srv.cfg.Addr, err)
+	}
+
+	httpSrv := &http.Server{
+		Handler:           srv.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- httpSrv.Serve(ln) }()
+	pilog.Info("serve: listening on http://%s", ln.Addr())
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	srv.closeSessions()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return httpSrv.Shutdown(shutdownCtx)
+}
+
+// guard rejects requests a web page could have sent: a Host that is not
+// this server (DNS rebinding), a cross-origin Origin, a missing or wrong
+// bearer token, and a POST whose body is not declared as JSON (a form or
+// text/plain request needs no CORS preflight).
+func (s *Server) guard(next http.Handler) http.Handler {
+	want := []byte("Bearer " + s.cfg.Token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.allowedHost(r.Host) {
+			writeError(w, http.StatusForbidden, "host not allowed: "+r.Host)
+			return
+		}
+		if origin := r.Header.Get("Origin"); origin != "" && !sameOrigin(origin, r.Host) {
+			writeError(w, http.StatusForbidden, "cross-origin request refused")
+			return
+		}
+		got := []byte(r.Header.Get("Authorization"))
+		if subtle.ConstantTimeCompare(got, want) != 1 {
+			writeError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+			return
+		}
+		if r.Method == http.MethodPost {
+			if mt, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mt != "application/json" {
+				writeError(w, http.StatusUnsupportedMediaType, "Content-Type must be application/json")
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allowedHost reports whether hostport names this server: localhost or a
+// loopback address, or, when listening beyond loopback, the listen host or
+// any IP literal. DNS rebinding needs a host name, so IP literals are safe.
+func (s *Server) allowedHost(hostport string) bool {
+	host := hostport
+	if h, _, err := net.SplitHostPort(hostport); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	if ip != nil && ip.IsLoopback() {
+		return true
+	}
+	listenHost, _, err := net.SplitHostPort(s.cfg.Addr)
+	if err != nil {
+		return false
+	}
+	if lip := net.ParseIP(listenHost); listenHost == "localhost" || (lip != nil && lip.IsLoopback()) {
+		return false
+	}
+	return ip != nil || strings.EqualFold(host, listenHost)
+}
+
+// sameOrigin reports whether origin is an http(s) origin for host.
+func sameOrigin(origin, host string) bool {
+	u, err := url.Parse(origin)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+	return strings.EqualFold(u.Host, host)
+}
+
+func (s *Server) lookup(id string) (*session, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	sess, ok := s.sessions[id]
+	if !ok {
+		return nil, errSessionNotFound
+	}
+	return sess, nil
+}
+
+func (s *Server) closeSessions() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, sess := range s.sessions {
+		sess.close()
+		delete(s.sessions, id)
+	}
+}
+
+// --- Handlers ---
+
+func (s *Server) handleHealth(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok", "model": s.deps.Model.ID})
+}
+
+func (s *Server) handleListSessions(w http.ResponseWriter, _ *http.Request) {
+	s.mu.RLock()
+	infos := make([]SessionInfo, 0, len(s.sessions))
+	for _, sess := range s.sessions {
+		infos = append(infos, sess.info())
+	}
+	s.mu.RUnlock()
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Created < infos[j].Created })
+	writeJSON(w, http.StatusOK, map[string]any{"sessions": infos})
+}
+
+func (s *Server) handleCreateSession(w http.ResponseWriter, _ *http.Request) {
+	sess, err := newSession(s.deps)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.mu.Lock()
+	s.sessions[sess.id] = sess
+	s.mu.Unlock()
+	writeJSON(w, http.StatusCreated, sess.info())
+}
+
+func (s *Server) handleGetSession(w http.ResponseWriter, r *http.Request) {
+	sess, err := s.lookup(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, sess.info())
+}
+
+func (s *Server) handleDeleteSession(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	s.mu.Lock()
+	sess, ok := s.sessions[id]
+	delete(s.sessions, id)
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, errSessionNotFound.Error())
+		return
+	}
+	sess.close()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// sendMessageRequest is the body of POST /v1/sessions/{id}/messages.
+type sendMessageRequest struct {
+	Text string `json:"text"`
+}
+
+func (s *Server) handleSendMessage(w http.ResponseWriter, r *http.Request) {
+	sess, err := s.lookup(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	var req sendMessageRequest
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if strings.TrimSpace(req.Text) == "" {
+		writeError(w, http.StatusBadRequest, "text is required")
+		return
+	}
+
+	if err := sess.send(s.ctx, req.Text); err != nil {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusAccepted, sess.info())
+}
+
+// permissionRequest is the body of POST /v1/sessions/{id}/permissions/{req}.
+type permissionRequest struct {
+	Allow  bool `json:"allow"`
+	Always bool `json:"always,omitempty"`
+}
+
+func (s *Server) handlePermission(w http.ResponseWriter, r *http.Request) {
+	sess, err := s.lookup(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	var req permissionRequest
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := sess.reply(r.PathValue("req"), permissionReply{Allow: req.Allow, Always: req.Always}); err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleAbort(w http.ResponseWriter, r *http.Request) {
+	sess, err := s.lookup(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]bool{"aborted": sess.abort()})
+}
+
+// handleEvents streams session events as server-sent events until the
+// client disconnects or the session is closed.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	sess, err := s.lookup(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming unsupported")
+		return
+	}
+
+	events, unsubscribe := sess.hub.subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	ticker := time.NewTicker(sseKeepalive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			fmt.Fprint(w, ": keepalive\n\n")
+			flusher.Flush()
+		case evt, ok := <-events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(evt)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", evt.Type, data)
+			flusher.Flush()
+		}
+	}
+}
+
+// --- Helpers ---
+
+func decodeBody(r *http.Request, v any) error {
+	dec := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxBodyBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return fmt.Errorf("request body too large")
+		}
+		return fmt.Errorf("invalid JSON body: %w", err)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
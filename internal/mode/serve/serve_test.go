@@ -0,0 +1,426 @@
+// ABOUTME: Tests for the headless HTTP server: session lifecycle, SSE events, approvals
+// ABOUTME: Uses httptest and a scripted provider; no network or real LLM calls
+
+package serve
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
+	"github.com/mauromedda/pi-coding-agent-go/internal/permission"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+// scriptedProvider replays canned responses, one per Stream call.
+type scriptedProvider struct {
+	responses []*ai.AssistantMessage
+	calls     atomic.Int32
+}
+
+func (p *scriptedProvider) Api() ai.Api { return ai.ApiAnthropic }
+
+func (p *scriptedProvider) Stream(_ context.Context, _ *ai.Model, _ *ai.Context, _ *ai.StreamOptions) *ai.EventStream {
+	idx := int(p.calls.Add(1)) - 1
+	stream := ai.NewEventStream(16)
+	go func() {
+		if idx >= len(p.responses) {
+			stream.FinishWithError(fmt.Errorf("no more responses"))
+			return
+		}
+		msg := p.responses[idx]
+		for _, c := range msg.Content {
+			if c.Type == ai.ContentText {
+				stream.Send(ai.StreamEvent{Type: ai.EventContentDelta, Text: c.Text})
+			}
+		}
+		stream.Finish(msg)
+	}()
+	return stream
+}
+
+// testToken is the bearer token doJSON and subscribe send.
+const testToken = "test-token"
+
+// newTestServer starts a server requiring token, or testToken when empty.
+func newTestServer(t *testing.T, deps Deps, token string) *httptest.Server {
+	t.Helper()
+	if deps.Model == nil {
+		deps.Model = &ai.Model{ID: "test-model", Name: "Test", Api: ai.ApiAnthropic}
+	}
+	if token == "" {
+		token = testToken
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	srv, err := NewServer(ctx, Config{Token: token}, deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(func() {
+		cancel()
+		srv.closeSessions()
+		ts.Close()
+	})
+	return ts
+}
+
+func doJSON(t *testing.T, method, url string, body any, out any) int {
+	t.Helper()
+	var r *bytes.Reader
+	if body != nil {
+		data, _ := json.Marshal(body)
+		r = bytes.NewReader(data)
+	} else {
+		r = bytes.NewReader(nil)
+	}
+	req, err := http.NewRequest(method, url, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	if method == http.MethodPost {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		_ = json.NewDecoder(resp.Body).Decode(out)
+	}
+	return resp.StatusCode
+}
+
+// eventReader subscribes to a session's SSE stream.
+type eventReader struct {
+	resp *http.Response
+	sc   *bufio.Scanner
+}
+
+func subscribe(t *testing.T, url string) *eventReader {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("events status = %d", resp.StatusCode)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return &eventReader{resp: resp, sc: bufio.NewScanner(resp.Body)}
+}
+
+// next returns the next data event, failing the test after a timeout.
+func (e *eventReader) next(t *testing.T) Event {
+	t.Helper()
+	done := make(chan Event, 1)
+	go func() {
+		for e.sc.Scan() {
+			line := e.sc.Text()
+			if data, ok := strings.CutPrefix(line, "data: "); ok {
+				var evt Event
+				_ = json.Unmarshal([]byte(data), &evt)
+				done <- evt
+				return
+			}
+		}
+		close(done)
+	}()
+	select {
+	case evt, ok := <-done:
+		if !ok {
+			t.Fatal("event stream closed")
+		}
+		return evt
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for event")
+	}
+	return Event{}
+}
+
+// until reads events until one of the given type arrives.
+func (e *eventReader) until(t *testing.T, typ string) Event {
+	t.Helper()
+	for {
+		evt := e.next(t)
+		if evt.Type == typ {
+			return evt
+		}
+	}
+}
+
+func TestServer_SessionLifecycle(t *testing.T) {
+	provider := &scriptedProvider{responses: []*ai.AssistantMessage{
+		{Content: []ai.Content{{Type: ai.ContentText, Text: "hello there"}}, StopReason: ai.StopEndTurn},
+	}}
+	ts := newTestServer(t, Deps{Provider: provider}, "")
+
+	var info SessionInfo
+	if code := doJSON(t, http.MethodPost, ts.URL+"/v1/sessions", nil, &info); code != http.StatusCreated {
+		t.Fatalf("create status = %d", code)
+	}
+	if info.ID == "" {
+		t.Fatal("expected session ID")
+	}
+
+	events := subscribe(t, ts.URL+"/v1/sessions/"+info.ID+"/events")
+
+	if code := doJSON(t, http.MethodPost, ts.URL+"/v1/sessions/"+info.ID+"/messages", map[string]string{"text": "hi"}, nil); code != http.StatusAccepted {
+		t.Fatalf("send status = %d", code)
+	}
+
+	if evt := events.until(t, EventText); evt.Text != "hello there" {
+		t.Errorf("text event = %+v", evt)
+	}
+	events.until(t, EventAgentEnd)
+
+	// History is stored once the turn completes.
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		doJSON(t, http.MethodGet, ts.URL+"/v1/sessions/"+info.ID, nil, &info)
+		if !info.Running || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if info.Messages != 2 {
+		t.Errorf("messages = %d; want 2", info.Messages)
+	}
+
+	var list struct {
+		Sessions []SessionInfo `json:"sessions"`
+	}
+	doJSON(t, http.MethodGet, ts.URL+"/v1/sessions", nil, &list)
+	if len(list.Sessions) != 1 {
+		t.Errorf("list = %+v", list)
+	}
+
+	if code := doJSON(t, http.MethodDelete, ts.URL+"/v1/sessions/"+info.ID, nil, nil); code != http.StatusNoContent {
+		t.Errorf("delete status = %d", code)
+	}
+	if code := doJSON(t, http.MethodGet, ts.URL+"/v1/sessions/"+info.ID, nil, nil); code != http.StatusNotFound {
+		t.Errorf("get after delete status = %d", code)
+	}
+}
+
+func TestServer_PermissionApproval(t *testing.T) {
+	provider := &scriptedProvider{responses: []*ai.AssistantMessage{
+		{Content: []ai.Content{{Type: ai.ContentToolUse, ID: "t1", Name: "bash", Input: json.RawMessage(`{"command":"ls"}`)}}, StopReason: ai.StopToolUse},
+		{Content: []ai.Content{{Type: ai.ContentText, Text: "done"}}, StopReason: ai.StopEndTurn},
+	}}
+	var ran atomic.Bool
+	bash := &agent.AgentTool{
+		Name: "bash",
+		Execute: func(_ context.Context, _ string, _ map[string]any, _ func(agent.ToolUpdate)) (agent.ToolResult, error) {
+			ran.Store(true)
+			return agent.ToolResult{Content: "file.txt"}, nil
+		},
+	}
+	ts := newTestServer(t, Deps{
+		Provider: provider,
+		Tools:    []*agent.AgentTool{bash},
+		Checker:  permission.NewChecker(permission.ModeNormal, nil),
+	}, "")
+
+	var info SessionInfo
+	doJSON(t, http.MethodPost, ts.URL+"/v1/sessions", nil, &info)
+	events := subscribe(t, ts.URL+"/v1/sessions/"+info.ID+"/events")
+	doJSON(t, http.MethodPost, ts.URL+"/v1/sessions/"+info.ID+"/messages", map[string]string{"text": "list files"}, nil)
+
+	req := events.until(t, EventPermissionRequest)
+	if req.Tool != "bash" || req.RequestID == "" {
+		t.Fatalf("permission request = %+v", req)
+	}
+
+	if code := doJSON(t, http.MethodPost, ts.URL+"/v1/sessions/"+info.ID+"/permissions/bogus", map[string]bool{"allow": true}, nil); code != http.StatusNotFound {
+		t.Errorf("unknown request status = %d; want 404", code)
+	}
+	if code := doJSON(t, http.MethodPost, ts.URL+"/v1/sessions/"+info.ID+"/permissions/"+req.RequestID, map[string]bool{"allow": true}, nil); code != http.StatusNoContent {
+		t.Fatalf("approve status = %d", code)
+	}
+
+	end := events.until(t, EventToolEnd)
+	if end.IsError || end.Text != "file.txt" {
+		t.Errorf("tool end = %+v", end)
+	}
+	if !ran.Load() {
+		t.Error("approved tool did not run")
+	}
+	events.until(t, EventAgentEnd)
+}
+
+func TestServer_RejectsConcurrentTurn(t *testing.T) {
+	block := make(chan struct{})
+	provider := &scriptedProvider{responses: []*ai.AssistantMessage{
+		{Content: []ai.Content{{Type: ai.ContentToolUse, ID: "t1", Name: "wait"}}, StopReason: ai.StopToolUse},
+		{Content: []ai.Content{{Type: ai.ContentText, Text: "ok"}}, StopReason: ai.StopEndTurn},
+	}}
+	wait := &agent.AgentTool{
+		Name:     "wait",
+		ReadOnly: true,
+		Execute: func(ctx context.Context, _ string, _ map[string]any, _ func(agent.ToolUpdate)) (agent.ToolResult, error) {
+			select {
+			case <-block:
+			case <-ctx.Done():
+			}
+			return agent.ToolResult{Content: "waited"}, nil
+		},
+	}
+	ts := newTestServer(t, Deps{Provider: provider, Tools: []*agent.AgentTool{wait}}, "")
+
+	var info SessionInfo
+	doJSON(t, http.MethodPost, ts.URL+"/v1/sessions", nil, &info)
+	events := subscribe(t, ts.URL+"/v1/sessions/"+info.ID+"/events")
+	doJSON(t, http.MethodPost, ts.URL+"/v1/sessions/"+info.ID+"/messages", map[string]string{"text": "one"}, nil)
+	events.until(t, EventToolStart)
+
+	if code := doJSON(t, http.MethodPost, ts.URL+"/v1/sessions/"+info.ID+"/messages", map[string]string{"text": "two"}, nil); code != http.StatusConflict {
+		t.Errorf("second send status = %d; want 409", code)
+	}
+
+	var aborted map[string]bool
+	doJSON(t, http.MethodPost, ts.URL+"/v1/sessions/"+info.ID+"/abort", nil, &aborted)
+	if !aborted["aborted"] {
+		t.Error("expected abort to report true")
+	}
+	close(block)
+	events.until(t, EventAgentEnd)
+}
+
+func TestServer_BearerToken(t *testing.T) {
+	ts := newTestServer(t, Deps{Provider: &scriptedProvider{}}, "s3cret")
+
+	if code := doJSON(t, http.MethodGet, ts.URL+"/v1/health", nil, nil); code != http.StatusUnauthorized {
+		t.Errorf("wrong token status = %d; want 401", code)
+	}
+	if code := rawRequest(t, http.MethodGet, ts.URL+"/v1/health", nil); code != http.StatusUnauthorized {
+		t.Errorf("unauthenticated status = %d; want 401", code)
+	}
+	if code := rawRequest(t, http.MethodGet, ts.URL+"/v1/health", map[string]string{"Authorization": "Bearer s3cret"}); code != http.StatusOK {
+		t.Errorf("authenticated status = %d; want 200", code)
+	}
+}
+
+func TestServer_GeneratesTokenWhenNoneGiven(t *testing.T) {
+	srv, err := NewServer(context.Background(), Config{}, Deps{Model: &ai.Model{ID: "m"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(srv.Token()) < 32 {
+		t.Fatalf("Token() = %q; want a generated token", srv.Token())
+	}
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+	if code := rawRequest(t, http.MethodGet, ts.URL+"/v1/health", nil); code != http.StatusUnauthorized {
+		t.Errorf("status without token = %d; want 401", code)
+	}
+}
+
+// rawRequest sends an empty-bodied request with exactly headers and
+// returns the status.
+func rawRequest(t *testing.T, method, url string, headers map[string]string) int {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range headers {
+		if k == "Host" {
+			req.Host = v
+			continue
+		}
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestServer_RejectsBrowserRequests(t *testing.T) {
+	ts := newTestServer(t, Deps{Provider: &scriptedProvider{}}, "")
+	auth := "Bearer " + testToken
+	port := ts.URL[strings.LastIndex(ts.URL, ":"):]
+
+	tests := []struct {
+		name    string
+		method  string
+		headers map[string]string
+		want    int
+	}{
+		{"rebound host", http.MethodGet, map[string]string{"Authorization": auth, "Host": "attacker.example" + port}, http.StatusForbidden},
+		{"lan ip host on loopback bind", http.MethodGet, map[string]string{"Authorization": auth, "Host": "192.168.1.5" + port}, http.StatusForbidden},
+		{"cross origin", http.MethodGet, map[string]string{"Authorization": auth, "Origin": "https://attacker.example"}, http.StatusForbidden},
+		{"form post", http.MethodPost, map[string]string{"Authorization": auth, "Content-Type": "application/x-www-form-urlencoded"}, http.StatusUnsupportedMediaType},
+		{"text post", http.MethodPost, map[string]string{"Authorization": auth, "Content-Type": "text/plain"}, http.StatusUnsupportedMediaType},
+		{"untyped post", http.MethodPost, map[string]string{"Authorization": auth}, http.StatusUnsupportedMediaType},
+		{"localhost host", http.MethodGet, map[string]string{"Authorization": auth, "Host": "localhost" + port}, http.StatusOK},
+		{"same origin", http.MethodGet, map[string]string{"Authorization": auth, "Origin": ts.URL}, http.StatusOK},
+		{"json post", http.MethodPost, map[string]string{"Authorization": auth, "Content-Type": "application/json; charset=utf-8"}, http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := ts.URL + "/v1/health"
+			if tt.method == http.MethodPost {
+				url = ts.URL + "/v1/sessions"
+			}
+			if code := rawRequest(t, tt.method, url, tt.headers); code != tt.want {
+				t.Errorf("status = %d; want %d", code, tt.want)
+			}
+		})
+	}
+}
+
+func TestServer_AllowedHostBeyondLoopback(t *testing.T) {
+	srv := &Server{cfg: Config{Addr: "0.0.0.0:8787"}}
+	for host, want := range map[string]bool{
+		"10.0.0.7:8787":       true,
+		"[::1]:8787":          true,
+		"localhost:8787":      true,
+		"attacker.example:80": false,
+		"build-box.lan:8787":  false,
+	} {
+		if got := srv.allowedHost(host); got != want {
+			t.Errorf("allowedHost(%q) = %v; want %v", host, got, want)
+		}
+	}
+	named := &Server{cfg: Config{Addr: "build-box.lan:8787"}}
+	if !named.allowedHost("build-box.lan:8787") {
+		t.Error("the listen host itself should be allowed")
+	}
+}
+
+func TestServer_BadRequests(t *testing.T) {
+	ts := newTestServer(t, Deps{Provider: &scriptedProvider{}}, "")
+
+	if code := doJSON(t, http.MethodPost, ts.URL+"/v1/sessions/nope/messages", map[string]string{"text": "x"}, nil); code != http.StatusNotFound {
+		t.Errorf("unknown session status = %d; want 404", code)
+	}
+
+	var info SessionInfo
+	doJSON(t, http.MethodPost, ts.URL+"/v1/sessions", nil, &info)
+	if code := doJSON(t, http.MethodPost, ts.URL+"/v1/sessions/"+info.ID+"/messages", map[string]string{"text": "  "}, nil); code != http.StatusBadRequest {
+		t.Errorf("empty text status = %d; want 400", code)
+	}
+	if code := doJSON(t, http.MethodPost, ts.URL+"/v1/sessions/"+info.ID+"/messages", map[string]string{"prompt": "x"}, nil); code != http.StatusBadRequest {
+		t.Errorf("unknown field status = %d; want 400", code)
+	}
+}
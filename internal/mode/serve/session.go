@@ -0,0 +1,242 @@
+// ABOUTME: Server-side conversation state: message history, running turn, pending approvals
+// ABOUTME: Each turn runs the shared agent loop and bridges permission asks to HTTP clients
+
+package serve
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
+	"github.com/mauromedda/pi-coding-agent-go/internal/permission"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+var (
+	errBusy            = errors.New("agent is already running")
+	errUnknownRequest  = errors.New("unknown permission request")
+	errSessionNotFound = errors.New("session not found")
+)
+
+// SessionInfo is the JSON description of a server session.
+type SessionInfo struct {
+	ID       string   `json:"id"`
+	Created  string   `json:"created"`
+	Running  bool     `json:"running"`
+	Messages int      `json:"messages"`
+	Pending  []string `json:"pending_permissions,omitempty"`
+}
+
+// permissionReply is a client's answer to a permission request.
+type permissionReply struct {
+	Allow  bool
+	Always bool
+}
+
+// session holds one conversation driven over HTTP.
+type session struct {
+	id      string
+	created time.Time
+	deps    *Deps
+	hub     *hub
+
+	mu       sync.Mutex
+	messages []ai.Message
+	running  bool
+	cancel   context.CancelFunc
+	pending  map[string]chan permissionReply
+}
+
+func newSession(deps *Deps) (*session, error) {
+	id, err := randomID(8)
+	if err != nil {
+		return nil, err
+	}
+	return &session{
+		id:      id,
+		created: time.Now(),
+		deps:    deps,
+		hub:     newHub(),
+		pending: make(map[string]chan permissionReply),
+	}, nil
+}
+
+// info returns a snapshot of the session state.
+func (s *session) info() SessionInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	info := SessionInfo{
+		ID:       s.id,
+		Created:  s.created.UTC().Format(time.RFC3339),
+		Running:  s.running,
+		Messages: len(s.messages),
+	}
+	for id := range s.pending {
+		info.Pending = append(info.Pending, id)
+	}
+	return info
+}
+
+// send appends a user message and starts an agent turn in the background.
+// Returns errBusy if a turn is already running.
+func (s *session) send(parent context.Context, text string) error {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return errBusy
+	}
+	s.messages = append(s.messages, ai.NewTextMessage(ai.RoleUser, text))
+	messages := make([]ai.Message, len(s.messages))
+	copy(messages, s.messages)
+
+	ctx, cancel := context.WithCancel(parent)
+	s.running = true
+	s.cancel = cancel
+	s.mu.Unlock()
+
+	go s.run(ctx, cancel, messages)
+	return nil
+}
+
+// run executes one agent turn and stores the resulting history.
+func (s *session) run(ctx context.Context, cancel context.CancelFunc, messages []ai.Message) {
+	defer cancel()
+
+	llmCtx := &ai.Context{
+		System:   s.deps.SystemPrompt,
+		Messages: messages,
+		Tools:    aiTools(s.deps.Tools),
+	}
+	opts := &ai.StreamOptions{MaxTokens: 16384}
+	if s.deps.Model.MaxOutputTokens > 0 {
+		opts.MaxTokens = s.deps.Model.MaxOutputTokens
+	}
+
+	ag := agent.NewWithPermissions(s.deps.Provider, s.deps.Model, s.deps.Tools, s.permCheck(ctx))
+	for evt := range ag.Prompt(ctx, llmCtx, opts) {
+		if out, ok := fromAgentEvent(evt); ok {
+			s.hub.publish(out)
+		}
+	}
+
+	s.mu.Lock()
+	s.messages = llmCtx.Messages
+	s.running = false
+	s.cancel = nil
+	s.mu.Unlock()
+}
+
+// permCheck builds the agent permission callback. Tools the checker marks
+// as needing approval are surfaced to clients as permission_request events
+// and block until a reply arrives or the turn is cancelled.
+func (s *session) permCheck(ctx context.Context) agent.PermCheckFunc {
+	return func(tool string, args map[string]any) error {
+		if s.deps.Checker == nil {
+			return nil
+		}
+		err := s.deps.Checker.Check(tool, args)
+		if err == nil || !permission.IsNeedsApproval(err) {
+			return err
+		}
+
+		reqID, err := randomID(6)
+		if err != nil {
+			return fmt.Errorf("permission check failed: %w", err)
+		}
+		replyCh := make(chan permissionReply, 1)
+		s.mu.Lock()
+		s.pending[reqID] = replyCh
+		s.mu.Unlock()
+		defer func() {
+			s.mu.Lock()
+			delete(s.pending, reqID)
+			s.mu.Unlock()
+		}()
+
+		s.hub.publish(Event{Type: EventPermissionRequest, RequestID: reqID, Tool: tool, Args: args})
+
+		select {
+		case reply := <-replyCh:
+			s.hub.publish(Event{Type: EventPermissionReply, RequestID: reqID, Tool: tool, IsError: !reply.Allow})
+			decision := permission.Decision{Tool: tool, Args: args, Allowed: reply.Allow, Source: permission.SourceUser}
+			if !reply.Allow {
+				err := fmt.Errorf("tool %q denied by user", tool)
+				decision.Reason = err.Error()
+				s.deps.Checker.Record(decision)
+				return err
+			}
+			s.deps.Checker.Record(decision)
+			if reply.Always {
+				s.deps.Checker.AddAllowRule(permission.Rule{Tool: tool})
+			}
+			return nil
+		case <-ctx.Done():
+			s.deps.Checker.Record(permission.Decision{Tool: tool, Args: args, Source: permission.SourceUser, Reason: "permission check cancelled"})
+			return fmt.Errorf("permission check cancelled")
+		}
+	}
+}
+
+// reply answers a pending permission request.
+func (s *session) reply(reqID string, r permissionReply) error {
+	s.mu.Lock()
+	ch, ok := s.pending[reqID]
+	if ok {
+		delete(s.pending, reqID)
+	}
+	s.mu.Unlock()
+	if !ok {
+		return errUnknownRequest
+	}
+	ch <- r
+	return nil
+}
+
+// abort cancels the running turn. Returns false if nothing was running.
+func (s *session) abort() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.running || s.cancel == nil {
+		return false
+	}
+	s.cancel()
+	return true
+}
+
+// close aborts any running turn and disconnects subscribers.
+func (s *session) close() {
+	s.abort()
+	s.hub.closeAll()
+}
+
+// aiTools converts agent tools into ai.Tool definitions for the LLM context.
+func aiTools(tools []*agent.AgentTool) []ai.Tool {
+	out := make([]ai.Tool, 0, len(tools))
+	for _, t := range tools {
+		schema := t.Parameters
+		if schema == nil {
+			schema = json.RawMessage(`{}`)
+		}
+		out = append(out, ai.Tool{
+			Name:        t.Name,
+			Description: t.Description,
+			Parameters:  schema,
+		})
+	}
+	return out
+}
+
+// randomID returns n random bytes hex-encoded.
+func randomID(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("reading random bytes: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,64 @@
+// ABOUTME: Offline mode helpers: local URL detection and actionable errors for remote-only features
+// ABOUTME: --offline restricts models to local servers and fails network features fast instead of timing out
+
+package offline
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// ErrOffline marks an operation refused because offline mode is active.
+var ErrOffline = errors.New("offline mode")
+
+// Unavailable returns an ErrOffline-wrapping error naming the feature and
+// telling the user how to proceed.
+func Unavailable(feature, hint string) error {
+	return fmt.Errorf("%w: %s needs network access; %s", ErrOffline, feature, hint)
+}
+
+// IsLocalURL reports whether raw points at this machine or a private network:
+// localhost, loopback, RFC 1918 / unique-local addresses, link-local, or
+// *.local / *.internal / *.lan host names.
+func IsLocalURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	return IsLocalHost(u.Hostname())
+}
+
+// IsLocalHost reports whether host (a name or IP, no port) is local.
+func IsLocalHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	for _, suffix := range []string{".local", ".internal", ".lan", ".home.arpa"} {
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsUnspecified()
+}
+
+// CheckModelURL returns an actionable error unless baseURL is local.
+// An empty baseURL means the provider's hosted default endpoint.
+func CheckModelURL(modelID, baseURL string) error {
+	if baseURL == "" {
+		return Unavailable(fmt.Sprintf("model %q (hosted endpoint)", modelID),
+			"point it at a local server with --base-url http://localhost:11434 (or base_url in settings), or drop --offline")
+	}
+	if !IsLocalURL(baseURL) {
+		return Unavailable(fmt.Sprintf("model %q at %s", modelID, baseURL),
+			"use a localhost or private-network base URL, or drop --offline")
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+// ABOUTME: Tests for offline mode helpers: local URL classification and error wrapping
+// ABOUTME: Covers loopback, private ranges, local TLDs, hosted endpoints, and empty base URLs
+
+package offline
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestIsLocalURL(t *testing.T) {
+	t.Parallel()
+	cases := map[string]bool{
+		"http://localhost:11434":       true,
+		"http://127.0.0.1:8000/v1":     true,
+		"http://[::1]:8080":            true,
+		"http://192.168.1.20:11434":    true,
+		"http://10.0.0.5":              true,
+		"http://gpu-box.local:8000":    true,
+		"http://llm.internal":          true,
+		"https://api.anthropic.com":    false,
+		"https://8.8.8.8":              false,
+		"http://localhost.example.com": false,
+		"localhost:11434":              false, // no scheme: host does not parse
+		"":                             false,
+	}
+	for raw, want := range cases {
+		if got := IsLocalURL(raw); got != want {
+			t.Errorf("IsLocalURL(%q) = %v, want %v", raw, got, want)
+		}
+	}
+}
+
+func TestCheckModelURL(t *testing.T) {
+	t.Parallel()
+	if err := CheckModelURL("llama3", "http://localhost:11434"); err != nil {
+		t.Errorf("local URL rejected: %v", err)
+	}
+
+	err := CheckModelURL("claude-sonnet-4", "")
+	if !errors.Is(err, ErrOffline) {
+		t.Fatalf("empty base URL: err = %v, want ErrOffline", err)
+	}
+	if !strings.Contains(err.Error(), "--base-url") {
+		t.Errorf("error should say how to fix it: %v", err)
+	}
+
+	if err := CheckModelURL("gpt-4o", "https://api.openai.com"); !errors.Is(err, ErrOffline) {
+		t.Errorf("hosted URL: err = %v, want ErrOffline", err)
+	}
+}
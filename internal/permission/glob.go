@@ -4,8 +4,10 @@
 package permission
 
 import (
+	"maps"
 	"net/url"
 	"path/filepath"
+	"slices"
 	"strings"
 )
 
@@ -107,7 +109,7 @@ func ExtractSpecifier(toolName string, args map[string]any) string {
 	switch strings.ToLower(toolName) {
 	case "bash":
 		if cmd, ok := args["command"].(string); ok {
-			return cmd
+			return bashSpecifier(cmd, args)
 		}
 	case "edit", "write", "read":
 		if path, ok := args["file_path"].(string); ok {
@@ -128,6 +130,35 @@ func ExtractSpecifier(toolName string, args map[string]any) string {
 	return ""
 }
 
+// bashSpecifier returns cmd as the shell would see it with the bash tool's
+// env and cwd parameters written inline: "cd dir && NAME=value cmd". A
+// rule such as Bash(go test*) then matches a call only when it would also
+// match the command with those prefixes typed out.
+func bashSpecifier(cmd string, args map[string]any) string {
+	var b strings.Builder
+	if cwd, ok := args["cwd"].(string); ok && cwd != "" {
+		b.WriteString("cd " + shellWord(cwd) + " && ")
+	}
+	if env, ok := args["env"].(map[string]any); ok {
+		for _, name := range slices.Sorted(maps.Keys(env)) {
+			value, _ := env[name].(string)
+			b.WriteString(name + "=" + shellWord(value) + " ")
+		}
+	}
+	b.WriteString(cmd)
+	return b.String()
+}
+
+// shellWord returns s as one POSIX shell word, quoting it only when needed.
+func shellWord(s string) string {
+	if s != "" && !strings.ContainsFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:=,+@%", r))
+	}) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
 // evaluateGlobRules evaluates rules in deny-first, ask-second, allow-third order.
 func evaluateGlobRules(rules []GlobRule, toolName, specifier string) Action {
 	// Pass 1: Deny
@@ -154,7 +185,21 @@ func evaluateGlobRules(rules []GlobRule, toolName, specifier string) Action {
 // NewCheckerFromSettings creates a Checker with glob-based rules from settings.
 func NewCheckerFromSettings(mode Mode, askFn AskFunc, allow, deny, ask []string) *Checker {
 	c := NewChecker(mode, askFn)
+	c.globRules = parseGlobRules(allow, deny, ask)
+	return c
+}
+
+// SetGlobRules replaces all glob-based rules, e.g. after the project's
+// permissions block is rewritten mid-session.
+func (c *Checker) SetGlobRules(allow, deny, ask []string) {
+	rules := parseGlobRules(allow, deny, ask)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.globRules = rules
+}
 
+// parseGlobRules parses settings rule strings into GlobRules.
+func parseGlobRules(allow, deny, ask []string) []GlobRule {
 	var rules []GlobRule
 	for _, s := range deny {
 		rules = append(rules, parseGlobRule(s, ActionDeny))
@@ -165,7 +210,5 @@ func NewCheckerFromSettings(mode Mode, askFn AskFunc, allow, deny, ask []string)
 	for _, s := range allow {
 		rules = append(rules, parseGlobRule(s, ActionAllow))
 	}
-
-	c.globRules = rules
-	return c
+	return rules
 }
@@ -84,6 +84,8 @@ func TestExtractSpecifier(t *testing.T) {
 		want   string
 	}{
 		{"bash", map[string]any{"command": "npm run test"}, "npm run test"},
+		{"bash", map[string]any{"command": "go test ./...", "env": map[string]any{"LD_PRELOAD": "/tmp/x.so", "CGO_ENABLED": "0"}}, "CGO_ENABLED=0 LD_PRELOAD=/tmp/x.so go test ./..."},
+		{"bash", map[string]any{"command": "ls", "cwd": "/tmp/my dir", "env": map[string]any{"GOFLAGS": "-toolexec=it's"}}, `cd '/tmp/my dir' && GOFLAGS='-toolexec=it'\''s' ls`},
 		{"edit", map[string]any{"file_path": "/src/main.go"}, "/src/main.go"},
 		{"write", map[string]any{"file_path": "/src/out.go"}, "/src/out.go"},
 		{"read", map[string]any{"file_path": "/src/in.go"}, "/src/in.go"},
@@ -166,3 +168,42 @@ func TestNewCheckerFromSettings(t *testing.T) {
 		t.Errorf("npm run test should be allowed: %v", err)
 	}
 }
+
+func TestChecker_BashEnvAndCwdNotAllowedByPrefix(t *testing.T) {
+	checker := NewCheckerFromSettings(ModeNormal, nil, []string{"Bash(go test*)"}, nil, nil)
+	if err := checker.Check("bash", map[string]any{"command": "go test ./..."}); err != nil {
+		t.Fatalf("go test should be allowed: %v", err)
+	}
+
+	for _, args := range []map[string]any{
+		{"command": "go test ./...", "env": map[string]any{"LD_PRELOAD": "/tmp/x.so"}},
+		{"command": "go test ./...", "env": map[string]any{"GOFLAGS": "-toolexec=/tmp/x"}},
+		{"command": "go test ./...", "cwd": "/tmp/elsewhere"},
+	} {
+		if err := checker.Check("bash", args); !IsNeedsApproval(err) {
+			t.Errorf("Check(%v) = %v; want approval, not the allow rule", args, err)
+		}
+	}
+
+	deny := NewCheckerFromSettings(ModeNormal, nil, nil, []string{"Bash(LD_PRELOAD=*)"}, nil)
+	err := deny.Check("bash", map[string]any{"command": "make", "env": map[string]any{"LD_PRELOAD": "/tmp/x.so"}})
+	if err == nil || IsNeedsApproval(err) {
+		t.Errorf("deny rule should see env passed as a parameter, got %v", err)
+	}
+}
+
+func TestChecker_SetGlobRules(t *testing.T) {
+	checker := NewCheckerFromSettings(ModeNormal, nil, []string{"Bash(npm run *)"}, nil, nil)
+	npm := map[string]any{"command": "npm run test"}
+	if err := checker.Check("bash", npm); err != nil {
+		t.Fatalf("npm run test should be allowed: %v", err)
+	}
+
+	checker.SetGlobRules(nil, []string{"Bash(npm *)"}, nil)
+	if err := checker.Check("bash", npm); err == nil {
+		t.Error("replaced rules should deny npm")
+	}
+	if IsNeedsApproval(checker.Check("bash", npm)) {
+		t.Error("deny rule should not fall through to approval")
+	}
+}
@@ -85,6 +85,28 @@ type Rule struct {
 // AskFunc is called when user confirmation is needed in normal mode.
 type AskFunc func(tool string, args map[string]any) (bool, error)
 
+// Source names what settled a permission check.
+type Source string
+
+const (
+	SourceRule Source = "rule" // an allow, deny, or glob rule
+	SourceMode Source = "mode" // the permission mode, such as yolo or plan
+	SourceUser Source = "user" // the user, or the ask function, answered a prompt
+)
+
+// Decision is the outcome of one permission check, reported to the
+// DecisionFunc set with SetDecisionFn.
+type Decision struct {
+	Tool    string
+	Args    map[string]any
+	Allowed bool
+	Source  Source
+	Reason  string // why a call was denied; "" when allowed
+}
+
+// DecisionFunc observes permission decisions, as an audit log does.
+type DecisionFunc func(Decision)
+
 // Checker validates tool execution permissions.
 // All exported methods are safe for concurrent use.
 type Checker struct {
@@ -94,6 +116,7 @@ type Checker struct {
 	denyRules  []Rule
 	globRules  []GlobRule
 	askFn      AskFunc
+	decisionFn DecisionFunc
 }
 
 // NewChecker creates a Checker with the given mode and ask function.
@@ -118,6 +141,26 @@ func (c *Checker) SetAskFn(fn AskFunc) {
 	c.askFn = fn
 }
 
+// SetDecisionFn sets the function told of every decision Check makes.
+// Callers that ask the user themselves after ErrNeedsApproval report the
+// answer with Record.
+func (c *Checker) SetDecisionFn(fn DecisionFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.decisionFn = fn
+}
+
+// Record reports a decision made outside Check, such as the user's answer
+// to an approval dialog, to the DecisionFunc.
+func (c *Checker) Record(d Decision) {
+	c.mu.RLock()
+	fn := c.decisionFn
+	c.mu.RUnlock()
+	if fn != nil {
+		fn(d)
+	}
+}
+
 // Mode returns the current permission mode.
 func (c *Checker) Mode() Mode {
 	c.mu.RLock()
@@ -153,8 +196,9 @@ func (c *Checker) AddDenyRule(rule Rule) {
 }
 
 // readOnlyTools lists tools that are always allowed in plan mode.
+// todo_write only updates the task list shown in the TUI.
 var readOnlyTools = map[string]bool{
-	"read": true, "grep": true, "find": true, "ls": true,
+	"read": true, "grep": true, "find": true, "ls": true, "todo_write": true,
 }
 
 // Check validates whether a tool can execute.
@@ -163,15 +207,36 @@ var readOnlyTools = map[string]bool{
 // interactive approval but no AskFunc is configured.
 func (c *Checker) Check(tool string, args map[string]any) error {
 	// Evaluate rules under read lock; capture askFn for potential callback.
-	verdict, askFn := c.evaluate(tool, args)
-	switch verdict {
-	case verdictAllow:
+	v, askFn := c.evaluate(tool, args)
+	err := c.decide(tool, args, v, askFn)
+	// An ErrNeedsApproval is no decision yet: the caller asks and records.
+	if v.kind != verdictAsk.kind || askFn != nil {
+		d := Decision{Tool: tool, Args: args, Allowed: err == nil, Source: v.source}
+		if v.kind == verdictAsk.kind {
+			d.Source = SourceUser
+		}
+		if err != nil {
+			d.Reason = err.Error()
+		}
+		c.Record(d)
+	}
+	return err
+}
+
+// decide turns a verdict into Check's result, asking askFn when needed.
+func (c *Checker) decide(tool string, args map[string]any, v verdict, askFn AskFunc) error {
+	switch v.kind {
+	case verdictAllow.kind:
 		return nil
-	case verdictAsk:
+	case verdictAsk.kind:
 		if askFn == nil {
 			return fmt.Errorf("tool %q: %w", tool, ErrNeedsApproval)
 		}
 		allowed, err := askFn(tool, args)
+		var denied *DeniedError
+		if errors.As(err, &denied) {
+			return fmt.Errorf("tool %q denied: %w", tool, denied)
+		}
 		if err != nil {
 			return fmt.Errorf("permission check failed: %w", err)
 		}
@@ -181,22 +246,26 @@ func (c *Checker) Check(tool string, args map[string]any) error {
 		return nil
 	default:
 		// verdictDeny carries the error message
-		return verdict.err
+		return v.err
 	}
 }
 
 // verdict is the result of rule evaluation.
 type verdict struct {
-	kind int // 0=deny, 1=allow, 2=ask
-	err  error
+	kind   int // 0=deny, 1=allow, 2=ask
+	err    error
+	source Source // what settled an allow or deny
 }
 
 var (
-	verdictAllow = verdict{kind: 1}
+	verdictAllow = verdict{kind: 1, source: SourceMode}
 	verdictAsk   = verdict{kind: 2}
+	ruleAllow    = verdict{kind: 1, source: SourceRule}
 )
 
-func denyVerdict(err error) verdict { return verdict{kind: 0, err: err} }
+func denyVerdict(err error) verdict { return verdict{kind: 0, err: err, source: SourceMode} }
+
+func ruleDeny(err error) verdict { return verdict{kind: 0, err: err, source: SourceRule} }
 
 // evaluate checks rules under RLock and returns a verdict plus the askFn.
 // The caller invokes askFn (if needed) outside the lock to avoid blocking
@@ -217,14 +286,14 @@ func (c *Checker) evaluate(tool string, args map[string]any) (verdict, AskFunc)
 			if msg == "" {
 				msg = fmt.Sprintf("tool %q denied by rule", tool)
 			}
-			return denyVerdict(fmt.Errorf("%s", msg)), nil
+			return ruleDeny(fmt.Errorf("%s", msg)), nil
 		}
 	}
 
 	// Check allow rules
 	for _, rule := range c.allowRules {
 		if matchTool(rule.Tool, tool) {
-			return verdictAllow, nil
+			return ruleAllow, nil
 		}
 	}
 
@@ -233,9 +302,9 @@ func (c *Checker) evaluate(tool string, args map[string]any) (verdict, AskFunc)
 		specifier := ExtractSpecifier(tool, args)
 		switch evaluateGlobRules(c.globRules, tool, specifier) {
 		case ActionDeny:
-			return denyVerdict(fmt.Errorf("tool %q with specifier %q denied by glob rule", tool, specifier)), nil
+			return ruleDeny(fmt.Errorf("tool %q with specifier %q denied by glob rule", tool, specifier)), nil
 		case ActionAllow:
-			return verdictAllow, nil
+			return ruleAllow, nil
 		case ActionAsk:
 			return verdictAsk, c.askFn
 		}
@@ -6,6 +6,7 @@ package permission
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -20,6 +21,9 @@ func TestChecker_PlanMode(t *testing.T) {
 	if err := c.Check("grep", nil); err != nil {
 		t.Errorf("grep should be allowed in plan mode: %v", err)
 	}
+	if err := c.Check("todo_write", nil); err != nil {
+		t.Errorf("todo_write should be allowed in plan mode: %v", err)
+	}
 	if err := c.Check("write", nil); err == nil {
 		t.Error("write should be blocked in plan mode")
 	}
@@ -82,6 +86,44 @@ func TestChecker_AllowRule(t *testing.T) {
 	}
 }
 
+func TestChecker_DecisionFn(t *testing.T) {
+	t.Parallel()
+
+	var got []Decision
+	c := NewChecker(ModeNormal, func(string, map[string]any) (bool, error) { return false, nil })
+	c.SetDecisionFn(func(d Decision) { got = append(got, d) })
+	c.AddAllowRule(Rule{Tool: "write"})
+	c.AddDenyRule(Rule{Tool: "web_fetch", Message: "no network"})
+
+	_ = c.Check("read", nil)
+	_ = c.Check("write", nil)
+	_ = c.Check("web_fetch", nil)
+	_ = c.Check("bash", map[string]any{"command": "ls"})
+
+	want := []Decision{
+		{Tool: "read", Allowed: true, Source: SourceMode},
+		{Tool: "write", Allowed: true, Source: SourceRule},
+		{Tool: "web_fetch", Allowed: false, Source: SourceRule, Reason: "no network"},
+		{Tool: "bash", Allowed: false, Source: SourceUser, Reason: `tool "bash" denied by user`},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("decisions = %+v; want %d", got, len(want))
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.Tool != w.Tool || g.Allowed != w.Allowed || g.Source != w.Source || g.Reason != w.Reason {
+			t.Errorf("decision %d = %+v; want %+v", i, g, w)
+		}
+	}
+
+	// Without an ask function the caller asks, so Check records nothing.
+	c.SetAskFn(nil)
+	got = nil
+	if err := c.Check("bash", nil); !IsNeedsApproval(err) || len(got) != 0 {
+		t.Errorf("Check = %v with decisions %+v; want ErrNeedsApproval and none", err, got)
+	}
+}
+
 func TestMatchTool(t *testing.T) {
 	t.Parallel()
 
@@ -174,6 +216,32 @@ func TestSandbox_SymlinkResolution(t *testing.T) {
 	}
 }
 
+func TestFoldWindowsPath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in, want string
+	}{
+		{`C:\Users\Dev\Repo`, `c:\users\dev\repo`},
+		{`c:/users/dev/repo`, `c:\users\dev\repo`},
+		{`\\?\C:\Users\Dev\Repo`, `c:\users\dev\repo`},
+		{`\\?\UNC\Server\Share\dir`, `\\server\share\dir`},
+		{`\\server\share\\dir`, `\\server\share\dir`},
+		{`//server/share/dir`, `\\server\share\dir`},
+	}
+	for _, tt := range tests {
+		if got := foldWindowsPath(tt.in); got != tt.want {
+			t.Errorf("foldWindowsPath(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	// Folding keeps the separator boundary: a sibling sharing a prefix stays outside.
+	root := foldWindowsPath(`C:\Repo`) + `\`
+	if strings.HasPrefix(foldWindowsPath(`c:\repoevil\x`)+`\`, root) {
+		t.Error("c:\\repoevil should not fold inside C:\\Repo")
+	}
+}
+
 func TestMode_String_AllModes(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,124 @@
+// ABOUTME: Permission prompt tool: delegates approval to a shell command or a registered (e.g. MCP) tool
+// ABOUTME: Sends the request as JSON and reads back {"behavior": "allow"|"deny", "message": ...}
+
+package permission
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/types"
+)
+
+// promptTimeout bounds one permission decision; an external policy that
+// does not answer in time denies the call.
+const promptTimeout = 30 * time.Second
+
+// PromptRequest is what a permission prompt tool receives for each tool
+// call that needs approval.
+type PromptRequest struct {
+	ToolName string         `json:"tool_name"`
+	Input    map[string]any `json:"input"`
+}
+
+// PromptDecision is a permission prompt tool's answer.
+type PromptDecision struct {
+	Behavior string `json:"behavior"`          // "allow" or "deny"
+	Message  string `json:"message,omitempty"` // reason shown to the model on deny
+}
+
+// DeniedError is returned by an AskFunc that refuses a call with a reason.
+type DeniedError struct {
+	Reason string
+}
+
+// Error implements the error interface.
+func (e *DeniedError) Error() string { return e.Reason }
+
+// NewPromptTool returns an AskFunc that delegates approval to spec: a
+// tool named mcp__<server>__<tool>, looked up with lookup, or otherwise a
+// shell command. Errors and malformed answers deny the call.
+func NewPromptTool(spec string, lookup func(name string) *types.AgentTool) (AskFunc, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, fmt.Errorf("empty permission prompt tool")
+	}
+	if strings.HasPrefix(spec, "mcp__") {
+		tool := lookup(spec)
+		if tool == nil {
+			return nil, fmt.Errorf("permission prompt tool %q is not a registered tool", spec)
+		}
+		return toolPrompt(tool), nil
+	}
+	return commandPrompt(spec), nil
+}
+
+// commandPrompt runs command with sh -c per request, the request JSON on
+// stdin and the decision JSON on stdout.
+func commandPrompt(command string) AskFunc {
+	return func(tool string, args map[string]any) (bool, error) {
+		req, err := json.Marshal(PromptRequest{ToolName: tool, Input: args})
+		if err != nil {
+			return false, fmt.Errorf("marshal permission request: %w", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), promptTimeout)
+		defer cancel()
+
+		cmd := exec.CommandContext(ctx, "sh", "-c", command)
+		cmd.Stdin = bytes.NewReader(req)
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout, cmd.Stderr = &stdout, &stderr
+		if err := cmd.Run(); err != nil {
+			if ctx.Err() != nil {
+				return false, fmt.Errorf("permission prompt command timed out after %v", promptTimeout)
+			}
+			return false, fmt.Errorf("permission prompt command: %w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return parseDecision(stdout.Bytes())
+	}
+}
+
+// toolPrompt calls tool with the request as its arguments and reads the
+// decision from its result.
+func toolPrompt(tool *types.AgentTool) AskFunc {
+	return func(name string, args map[string]any) (bool, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), promptTimeout)
+		defer cancel()
+
+		params := map[string]any{"tool_name": name, "input": args}
+		res, err := tool.Execute(ctx, "permission-prompt", params, nil)
+		if err != nil {
+			return false, fmt.Errorf("permission prompt tool %s: %w", tool.Name, err)
+		}
+		if res.IsError {
+			return false, fmt.Errorf("permission prompt tool %s: %s", tool.Name, res.Content)
+		}
+		return parseDecision([]byte(res.Content))
+	}
+}
+
+// parseDecision reads a PromptDecision. A deny carries its message, or a
+// generic reason, as a DeniedError.
+func parseDecision(out []byte) (bool, error) {
+	var d PromptDecision
+	if err := json.Unmarshal(bytes.TrimSpace(out), &d); err != nil {
+		return false, fmt.Errorf("parse permission decision (raw: %q): %w", out, err)
+	}
+	switch d.Behavior {
+	case "allow":
+		return true, nil
+	case "deny":
+		reason := d.Message
+		if reason == "" {
+			reason = "denied by the permission prompt tool"
+		}
+		return false, &DeniedError{Reason: reason}
+	default:
+		return false, fmt.Errorf("permission decision: unknown behavior %q; want allow or deny", d.Behavior)
+	}
+}
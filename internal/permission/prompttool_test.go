@@ -0,0 +1,104 @@
+// ABOUTME: Tests for the permission prompt tool: shell command and registered-tool deciders
+// ABOUTME: Checks allow/deny round-trips through Checker.Check and that failures deny the call
+
+package permission
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/types"
+)
+
+// promptChecker returns a normal-mode checker that asks spec.
+func promptChecker(t *testing.T, spec string, lookup func(string) *types.AgentTool) *Checker {
+	t.Helper()
+	ask, err := NewPromptTool(spec, lookup)
+	if err != nil {
+		t.Fatalf("NewPromptTool(%q): %v", spec, err)
+	}
+	return NewChecker(ModeNormal, ask)
+}
+
+func noTools(string) *types.AgentTool { return nil }
+
+func TestPromptTool_CommandDecides(t *testing.T) {
+	t.Parallel()
+
+	// Allow reads of anything, deny rm, based on the request on stdin.
+	policy := `req=$(cat); case "$req" in
+  *'"tool_name":"bash"'*'rm -rf'*) echo '{"behavior":"deny","message":"rm is not allowed in CI"}' ;;
+  *) echo '{"behavior":"allow"}' ;;
+esac`
+	c := promptChecker(t, policy, noTools)
+
+	if err := c.Check("bash", map[string]any{"command": "go test ./..."}); err != nil {
+		t.Errorf("policy should allow go test: %v", err)
+	}
+	err := c.Check("bash", map[string]any{"command": "rm -rf /"})
+	if err == nil || !strings.Contains(err.Error(), `tool "bash" denied: rm is not allowed in CI`) {
+		t.Errorf("policy should deny rm with its message; got %v", err)
+	}
+}
+
+func TestPromptTool_CommandFailuresDeny(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name, command, want string
+	}{
+		{"non-zero exit", "echo broken >&2; exit 3", "broken"},
+		{"not JSON", "echo yes", "parse permission decision"},
+		{"unknown behavior", `echo '{"behavior":"maybe"}'`, `unknown behavior "maybe"`},
+		{"deny without message", `echo '{"behavior":"deny"}'`, "denied by the permission prompt tool"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := promptChecker(t, tt.command, noTools).Check("bash", map[string]any{"command": "ls"})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Check error = %v; want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPromptTool_RegisteredTool(t *testing.T) {
+	t.Parallel()
+
+	var got map[string]any
+	policy := &types.AgentTool{
+		Name: "mcp__policy__approve",
+		Execute: func(_ context.Context, _ string, params map[string]any, _ func(types.ToolUpdate)) (types.ToolResult, error) {
+			got = params
+			return types.ToolResult{Content: `{"behavior":"allow"}`}, nil
+		},
+	}
+	lookup := func(name string) *types.AgentTool {
+		if name == policy.Name {
+			return policy
+		}
+		return nil
+	}
+	c := promptChecker(t, "mcp__policy__approve", lookup)
+
+	if err := c.Check("write", map[string]any{"path": "a.go"}); err != nil {
+		t.Fatalf("tool should allow: %v", err)
+	}
+	input, _ := got["input"].(map[string]any)
+	if got["tool_name"] != "write" || input["path"] != "a.go" {
+		t.Errorf("tool received %v; want the tool name and its input", got)
+	}
+}
+
+func TestNewPromptTool_UnknownTool(t *testing.T) {
+	t.Parallel()
+
+	if _, err := NewPromptTool("mcp__policy__missing", noTools); err == nil || !strings.Contains(err.Error(), "not a registered tool") {
+		t.Errorf("an unknown mcp tool should be rejected up front; got %v", err)
+	}
+	if _, err := NewPromptTool("  ", noTools); err == nil {
+		t.Error("an empty spec should be rejected")
+	}
+}
@@ -1,5 +1,5 @@
 // ABOUTME: Path validation to restrict file access to allowed directories
-// ABOUTME: Rejects path traversal, symlink escapes, and enforces separator-aware prefix matching
+// ABOUTME: Rejects path traversal, symlink escapes, and separator-aware prefix bypasses; Windows paths are case-folded
 
 package permission
 
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 )
 
@@ -29,6 +30,7 @@ func NewSandbox(allowedDirs []string) (*Sandbox, error) {
 		if err != nil {
 			return nil, fmt.Errorf("resolving path %q: %w", dir, err)
 		}
+		abs = ComparablePath(abs)
 		// Ensure trailing separator for correct prefix matching:
 		// "/tmp" + "/" prevents "/tmpevil" from matching
 		if !strings.HasSuffix(abs, string(filepath.Separator)) {
@@ -51,6 +53,7 @@ func (s *Sandbox) ValidatePath(path string) error {
 	if err != nil {
 		return fmt.Errorf("resolving path %q: %w", path, err)
 	}
+	resolved = ComparablePath(resolved)
 
 	// Check prefix against allowed directories with separator boundary
 	for _, allowed := range s.allowed {
@@ -97,3 +100,36 @@ func containsTraversal(path string) bool {
 	}
 	return false
 }
+
+// ComparablePath folds an absolute path for prefix comparison. Unix paths
+// are returned as is. Windows paths are case-insensitive and may use
+// either separator or the \\?\ long-path prefix, so C:\Repo, c:/repo,
+// and \\?\C:\repo all fold to c:\repo, and \\?\UNC\server\share to
+// \\server\share.
+func ComparablePath(path string) string {
+	if runtime.GOOS != "windows" {
+		return path
+	}
+	return foldWindowsPath(path)
+}
+
+// foldWindowsPath is ComparablePath's Windows folding, separate so it can
+// be tested on any OS.
+func foldWindowsPath(path string) string {
+	p := strings.ReplaceAll(path, "/", `\`)
+	switch {
+	case strings.HasPrefix(p, `\\?\UNC\`):
+		p = `\\` + p[len(`\\?\UNC\`):]
+	case strings.HasPrefix(p, `\\?\`):
+		p = p[len(`\\?\`):]
+	}
+	// Collapse doubled separators, keeping the leading pair of a UNC path.
+	lead := ""
+	if strings.HasPrefix(p, `\\`) {
+		lead, p = `\\`, p[2:]
+	}
+	for strings.Contains(p, `\\`) {
+		p = strings.ReplaceAll(p, `\\`, `\`)
+	}
+	return strings.ToLower(lead + p)
+}
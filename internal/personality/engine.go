@@ -19,6 +19,8 @@ type Engine struct {
 	mu       sync.RWMutex
 	profiles map[string]*Profile
 	active   *Profile
+	styles   map[string]*OutputStyle
+	style    *OutputStyle
 }
 
 // NewEngine creates an engine with built-in profiles.
@@ -26,6 +28,7 @@ type Engine struct {
 func NewEngine(profilesDir string) (*Engine, error) {
 	e := &Engine{
 		profiles: builtinProfiles(),
+		styles:   builtinOutputStyles(),
 	}
 
 	if profilesDir != "" {
@@ -37,6 +40,7 @@ func NewEngine(profilesDir string) (*Engine, error) {
 	}
 
 	e.active = e.profiles["base"]
+	e.style = e.styles[DefaultOutputStyle]
 	return e, nil
 }
 
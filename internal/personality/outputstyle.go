@@ -0,0 +1,168 @@
+// ABOUTME: Output-style layer for the personality engine: how responses are formatted
+// ABOUTME: Built-in styles plus YAML-defined ones; composes the system prompt section
+
+package personality
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultOutputStyle is the style that adds no formatting instructions.
+const DefaultOutputStyle = "default"
+
+// OutputStyle is a response formatting persona layered on top of a profile.
+type OutputStyle struct {
+	Name         string   `yaml:"name"`
+	Description  string   `yaml:"description"`
+	Instructions []string `yaml:"instructions"`
+}
+
+// Validate checks that an output style has a name and at least one instruction.
+func (s *OutputStyle) Validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("output style name is required")
+	}
+	if s.Name != DefaultOutputStyle && len(s.Instructions) == 0 {
+		return fmt.Errorf("output style %q has no instructions", s.Name)
+	}
+	return nil
+}
+
+// LoadOutputStyles reads all YAML output styles from a directory.
+// A missing directory yields no styles and no error.
+func LoadOutputStyles(dir string) (map[string]*OutputStyle, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read output styles directory %s: %w", dir, err)
+	}
+
+	styles := make(map[string]*OutputStyle)
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read output style %s: %w", path, err)
+		}
+		var s OutputStyle
+		if err := yaml.Unmarshal(data, &s); err != nil {
+			return nil, fmt.Errorf("parse output style %s: %w", path, err)
+		}
+		if err := s.Validate(); err != nil {
+			return nil, fmt.Errorf("validate output style %s: %w", path, err)
+		}
+		styles[s.Name] = &s
+	}
+	return styles, nil
+}
+
+// AddOutputStyles registers additional styles, replacing built-ins with the same name.
+func (e *Engine) AddOutputStyles(styles map[string]*OutputStyle) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for name, s := range styles {
+		e.styles[name] = s
+	}
+}
+
+// SetOutputStyle activates an output style by name.
+func (e *Engine) SetOutputStyle(name string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	s, ok := e.styles[name]
+	if !ok {
+		return fmt.Errorf("unknown output style %q", name)
+	}
+	e.style = s
+	return nil
+}
+
+// ActiveOutputStyle returns the currently active output style.
+func (e *Engine) ActiveOutputStyle() *OutputStyle {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.style
+}
+
+// OutputStyles returns all available output styles sorted by name.
+func (e *Engine) OutputStyles() []*OutputStyle {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	out := make([]*OutputStyle, 0, len(e.styles))
+	for _, s := range e.styles {
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// ComposeOutputStyle generates the system prompt section for the active
+// output style. Returns "" for the default style.
+func (e *Engine) ComposeOutputStyle() string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.style == nil || len(e.style.Instructions) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "## Output Style: %s", e.style.Name)
+	if e.style.Description != "" {
+		b.WriteString("\n" + e.style.Description)
+	}
+	for _, instr := range e.style.Instructions {
+		b.WriteString("\n- " + instr)
+	}
+	return b.String()
+}
+
+func builtinOutputStyles() map[string]*OutputStyle {
+	return map[string]*OutputStyle{
+		DefaultOutputStyle: {
+			Name:        DefaultOutputStyle,
+			Description: "Standard formatting; no extra instructions.",
+		},
+		"explanatory": {
+			Name:        "explanatory",
+			Description: "Completes the task while explaining the codebase and the choices made.",
+			Instructions: []string{
+				"Before and after significant changes, add a short \"Insight\" block explaining why the approach fits this codebase.",
+				"Point out relevant patterns, conventions, and trade-offs you noticed while reading the code.",
+				"Keep insights specific to the code at hand; skip general programming trivia.",
+			},
+		},
+		"terse": {
+			Name:        "terse",
+			Description: "Minimal prose; results first.",
+			Instructions: []string{
+				"Answer in as few words as possible; no preamble or recap.",
+				"Prefer code, commands, and bullet lists over paragraphs.",
+				"Do not restate the question or summarize what you just did unless asked.",
+			},
+		},
+		"teaching": {
+			Name:        "teaching",
+			Description: "Pair-programming mode that leaves hands-on pieces for the user.",
+			Instructions: []string{
+				"Implement the scaffolding and the parts that need broad context yourself.",
+				"For small, instructive pieces (a function body, a condition, a test case), leave a `TODO(human)` comment describing exactly what to write instead of writing it.",
+				"Leave at most one or two TODO(human) markers per response and list them at the end with a hint for each.",
+				"Explain the concepts the user needs to complete each TODO(human).",
+			},
+		},
+	}
+}
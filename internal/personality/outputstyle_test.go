@@ -0,0 +1,123 @@
+// ABOUTME: Tests for the output-style layer of the personality engine
+// ABOUTME: Covers built-in styles, switching, YAML loading, and prompt section composition
+
+package personality
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEngine_OutputStyle_DefaultComposesNothing(t *testing.T) {
+	t.Parallel()
+	e, err := NewEngine("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := e.ActiveOutputStyle().Name; got != DefaultOutputStyle {
+		t.Errorf("ActiveOutputStyle().Name = %q; want %q", got, DefaultOutputStyle)
+	}
+	if got := e.ComposeOutputStyle(); got != "" {
+		t.Errorf("ComposeOutputStyle() = %q; want empty for default", got)
+	}
+}
+
+func TestEngine_OutputStyle_Builtins(t *testing.T) {
+	t.Parallel()
+	e, err := NewEngine("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, s := range e.OutputStyles() {
+		names = append(names, s.Name)
+	}
+	if got := strings.Join(names, ","); got != "default,explanatory,teaching,terse" {
+		t.Errorf("OutputStyles() = %s", got)
+	}
+}
+
+func TestEngine_SetOutputStyle_Teaching(t *testing.T) {
+	t.Parallel()
+	e, err := NewEngine("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.SetOutputStyle("teaching"); err != nil {
+		t.Fatalf("SetOutputStyle() error = %v", err)
+	}
+	got := e.ComposeOutputStyle()
+	if !strings.HasPrefix(got, "## Output Style: teaching") {
+		t.Errorf("missing header: %q", got)
+	}
+	if !strings.Contains(got, "TODO(human)") {
+		t.Errorf("teaching style should mention TODO(human) markers: %q", got)
+	}
+}
+
+func TestEngine_SetOutputStyle_Unknown(t *testing.T) {
+	t.Parallel()
+	e, err := NewEngine("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.SetOutputStyle("nonexistent"); err == nil {
+		t.Error("expected error for unknown style")
+	}
+	if got := e.ActiveOutputStyle().Name; got != DefaultOutputStyle {
+		t.Errorf("active style changed on error: %q", got)
+	}
+}
+
+func TestLoadOutputStyles(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	custom := "name: reviewer\ndescription: Code review voice\ninstructions:\n  - Lead with blocking issues.\n"
+	if err := os.WriteFile(filepath.Join(dir, "reviewer.yaml"), []byte(custom), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	styles, err := LoadOutputStyles(dir)
+	if err != nil {
+		t.Fatalf("LoadOutputStyles() error = %v", err)
+	}
+	if len(styles) != 1 || styles["reviewer"] == nil {
+		t.Fatalf("styles = %v", styles)
+	}
+
+	e, err := NewEngine("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.AddOutputStyles(styles)
+	if err := e.SetOutputStyle("reviewer"); err != nil {
+		t.Fatalf("SetOutputStyle(reviewer) error = %v", err)
+	}
+	if got := e.ComposeOutputStyle(); !strings.Contains(got, "- Lead with blocking issues.") {
+		t.Errorf("ComposeOutputStyle() = %q", got)
+	}
+}
+
+func TestLoadOutputStyles_MissingDir(t *testing.T) {
+	t.Parallel()
+	styles, err := LoadOutputStyles(filepath.Join(t.TempDir(), "absent"))
+	if err != nil || len(styles) != 0 {
+		t.Errorf("LoadOutputStyles(missing) = %v, %v; want empty, nil", styles, err)
+	}
+}
+
+func TestLoadOutputStyles_RejectsEmptyInstructions(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "bad.yml"), []byte("name: bad\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadOutputStyles(dir); err == nil {
+		t.Error("expected validation error")
+	}
+}
@@ -0,0 +1,214 @@
+// ABOUTME: Package authoring: pi-package.json manifest, validation, and the package init scaffold
+// ABOUTME: A package ships prompts, commands, and themes directories described by its manifest
+
+package pkgmanager
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/theme"
+)
+
+// PackageManifestFile is the manifest at the root of a package.
+const PackageManifestFile = "pi-package.json"
+
+// PackageManifest describes a package for publishing. Prompts, Commands,
+// and Themes name directories relative to the package root.
+type PackageManifest struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Description string `json:"description"`
+	Author      string `json:"author,omitempty"`
+	License     string `json:"license,omitempty"`
+	Registry    string `json:"registry,omitempty"` // publish target; default npm's configured registry
+	Prompts     string `json:"prompts,omitempty"`
+	Commands    string `json:"commands,omitempty"`
+	Themes      string `json:"themes,omitempty"`
+}
+
+var (
+	// packageNameRe follows npm's rules, so packages install with the npm source.
+	packageNameRe = regexp.MustCompile(`^(@[a-z0-9-~][a-z0-9-._~]*/)?[a-z0-9-~][a-z0-9-._~]*$`)
+	semverRe      = regexp.MustCompile(`^\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`)
+)
+
+// LoadPackageManifest reads the manifest of the package in dir.
+func LoadPackageManifest(dir string) (*PackageManifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, PackageManifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", PackageManifestFile, err)
+	}
+	var m PackageManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", PackageManifestFile, err)
+	}
+	return &m, nil
+}
+
+// contentDirs returns the manifest's content directories by kind, skipping
+// undeclared ones.
+func (m *PackageManifest) contentDirs() map[string]string {
+	dirs := map[string]string{}
+	for kind, dir := range map[string]string{"prompts": m.Prompts, "commands": m.Commands, "themes": m.Themes} {
+		if dir != "" {
+			dirs[kind] = dir
+		}
+	}
+	return dirs
+}
+
+// ValidatePackage checks the manifest and the content of the package in
+// dir, and returns every problem found at once.
+func ValidatePackage(dir string, m *PackageManifest) error {
+	var errs []error
+	switch {
+	case m.Name == "":
+		errs = append(errs, errors.New("name is required"))
+	case len(m.Name) > 214 || !packageNameRe.MatchString(m.Name):
+		errs = append(errs, fmt.Errorf("name %q must be lowercase letters, digits, '-', '.', or '_', optionally @scope/name", m.Name))
+	}
+	if !semverRe.MatchString(m.Version) {
+		errs = append(errs, fmt.Errorf("version %q is not a semantic version such as 1.2.0", m.Version))
+	}
+	if strings.TrimSpace(m.Description) == "" {
+		errs = append(errs, errors.New("description is required"))
+	}
+
+	dirs := m.contentDirs()
+	if len(dirs) == 0 {
+		errs = append(errs, errors.New("declare at least one of prompts, commands, or themes"))
+	}
+	files := 0
+	for _, kind := range slices.Sorted(maps.Keys(dirs)) {
+		n, err := validateContentDir(dir, kind, dirs[kind])
+		files += n
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(dirs) > 0 && files == 0 && len(errs) == 0 {
+		errs = append(errs, errors.New("the package has no prompts, commands, or themes"))
+	}
+	return errors.Join(errs...)
+}
+
+// validateContentDir checks one content directory and returns how many
+// content files it holds: Markdown for prompts and commands, theme JSON
+// for themes.
+func validateContentDir(root, kind, rel string) (int, error) {
+	if !filepath.IsLocal(rel) {
+		return 0, fmt.Errorf("%s: %q must be a directory inside the package", kind, rel)
+	}
+	entries, err := os.ReadDir(filepath.Join(root, rel))
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", kind, err)
+	}
+	ext := ".md"
+	if kind == "themes" {
+		ext = ".json"
+	}
+	var errs []error
+	n := 0
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ext {
+			continue
+		}
+		n++
+		path := filepath.Join(root, rel, e.Name())
+		if kind == "themes" {
+			if _, err := theme.LoadFile(path); err != nil {
+				errs = append(errs, fmt.Errorf("%s/%s: %w", rel, e.Name(), err))
+			}
+		}
+	}
+	return n, errors.Join(errs...)
+}
+
+// scaffoldFiles is the layout package init writes, keyed by relative path.
+// "{{name}}" is replaced by the package name and "{{short}}" by its last
+// part, without the scope.
+var scaffoldFiles = map[string]string{
+	"prompts/review.md": `---
+description: Review the current changes against the team's conventions
+---
+Review the uncommitted changes. Flag anything that breaks our conventions
+and suggest a fix for each finding.
+`,
+	"commands/changelog.md": `---
+description: Draft a changelog entry for the current branch
+---
+Summarize the commits on this branch as a changelog entry: one line per
+user-visible change, newest first.
+`,
+	"themes/{{short}}.json": `{
+  "name": "{{short}}",
+  "palette": {
+    "primary": "#7aa2f7",
+    "accent": "#bb9af7"
+  }
+}
+`,
+	"README.md": `# {{name}}
+
+Prompts, commands, and themes for pi-go.
+
+Install with ` + "`pi-go install {{name}}`" + `.
+`,
+}
+
+// InitPackage scaffolds a package named name in dir: a manifest plus
+// example prompts, commands, and themes. It refuses to overwrite an
+// existing manifest.
+func InitPackage(dir, name string) (*PackageManifest, error) {
+	if _, err := os.Stat(filepath.Join(dir, PackageManifestFile)); err == nil {
+		return nil, fmt.Errorf("%s already exists in %s", PackageManifestFile, dir)
+	}
+	if name == "" {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, fmt.Errorf("resolving %s: %w", dir, err)
+		}
+		name = strings.ToLower(filepath.Base(abs))
+	}
+	if !packageNameRe.MatchString(name) {
+		return nil, fmt.Errorf("invalid package name %q: use lowercase letters, digits, '-', '.', or '_'", name)
+	}
+
+	m := &PackageManifest{
+		Name:        name,
+		Version:     "0.1.0",
+		Description: "Prompts, commands, and themes for pi-go",
+		Prompts:     "prompts",
+		Commands:    "commands",
+		Themes:      "themes",
+	}
+	expand := strings.NewReplacer("{{name}}", name, "{{short}}", name[strings.LastIndex(name, "/")+1:]).Replace
+	for rel, content := range scaffoldFiles {
+		path := filepath.Join(dir, filepath.FromSlash(expand(rel)))
+		if _, err := os.Stat(path); err == nil {
+			continue // keep the author's file
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil, fmt.Errorf("creating %s: %w", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(expand(content)), 0o644); err != nil {
+			return nil, fmt.Errorf("writing %s: %w", path, err)
+		}
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshaling manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, PackageManifestFile), append(data, '\n'), 0o644); err != nil {
+		return nil, fmt.Errorf("writing %s: %w", PackageManifestFile, err)
+	}
+	return m, nil
+}
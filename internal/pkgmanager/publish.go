@@ -0,0 +1,235 @@
+// ABOUTME: Package publishing: packs a validated package into an npm-style tarball and pushes it
+// ABOUTME: CLI for "package init" and "package publish [--registry url] [--dry-run]"
+
+package pkgmanager
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// packMtime is the modification time of every tarball entry, so packing
+// the same content twice yields the same bytes.
+var packMtime = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// npmPublish pushes a tarball to a registry, "" meaning npm's configured
+// one. Replaceable in tests.
+var npmPublish = func(ctx context.Context, tarball, registry string) error {
+	args := []string{"publish", tarball}
+	if registry != "" {
+		args = append(args, "--registry", registry)
+	}
+	cmd := exec.CommandContext(ctx, "npm", args...)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("npm publish: %w", err)
+	}
+	return nil
+}
+
+// packageFiles lists the files a package ships, relative to dir and
+// slash-separated: the manifest, README and LICENSE files, and every
+// non-hidden file under the content directories.
+func packageFiles(dir string, m *PackageManifest) ([]string, error) {
+	files := []string{PackageManifestFile}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", dir, err)
+	}
+	for _, e := range entries {
+		upper := strings.ToUpper(e.Name())
+		if !e.IsDir() && (strings.HasPrefix(upper, "README") || strings.HasPrefix(upper, "LICENSE")) {
+			files = append(files, e.Name())
+		}
+	}
+	for _, rel := range m.contentDirs() {
+		root := filepath.Join(dir, rel)
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if strings.HasPrefix(d.Name(), ".") && path != root {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.Type().IsRegular() {
+				r, err := filepath.Rel(dir, path)
+				if err != nil {
+					return err
+				}
+				files = append(files, filepath.ToSlash(r))
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("listing %s: %w", rel, err)
+		}
+	}
+	slices.Sort(files)
+	return slices.Compact(files), nil
+}
+
+// tarballName returns npm pack's file name for a package:
+// "@team/prompts" at 1.0.0 packs to "team-prompts-1.0.0.tgz".
+func tarballName(m *PackageManifest) string {
+	name := strings.ReplaceAll(strings.TrimPrefix(m.Name, "@"), "/", "-")
+	return name + "-" + m.Version + ".tgz"
+}
+
+// PackPackage validates the package in dir and writes its tarball to
+// outDir. Files sit under "package/" next to a generated package.json, the
+// layout npm registries expect, so the result installs with the npm source.
+func PackPackage(dir, outDir string) (string, []string, error) {
+	m, err := LoadPackageManifest(dir)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := ValidatePackage(dir, m); err != nil {
+		return "", nil, fmt.Errorf("invalid package:\n%w", err)
+	}
+	files, err := packageFiles(dir, m)
+	if err != nil {
+		return "", nil, err
+	}
+
+	pkgJSON, err := json.MarshalIndent(struct {
+		Name        string   `json:"name"`
+		Version     string   `json:"version"`
+		Description string   `json:"description"`
+		Author      string   `json:"author,omitempty"`
+		License     string   `json:"license,omitempty"`
+		Keywords    []string `json:"keywords"`
+		Files       []string `json:"files"`
+	}{m.Name, m.Version, m.Description, m.Author, m.License, []string{"pi-package"}, files}, "", "  ")
+	if err != nil {
+		return "", nil, fmt.Errorf("marshaling package.json: %w", err)
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	add := func(name string, data []byte) error {
+		hdr := &tar.Header{Name: "package/" + name, Mode: 0o644, Size: int64(len(data)), ModTime: packMtime, Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+	if err := add("package.json", append(pkgJSON, '\n')); err != nil {
+		return "", nil, fmt.Errorf("packing package.json: %w", err)
+	}
+	for _, f := range files {
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(f)))
+		if err != nil {
+			return "", nil, fmt.Errorf("reading %s: %w", f, err)
+		}
+		if err := add(f, data); err != nil {
+			return "", nil, fmt.Errorf("packing %s: %w", f, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return "", nil, fmt.Errorf("closing tarball: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return "", nil, fmt.Errorf("compressing tarball: %w", err)
+	}
+
+	path := filepath.Join(outDir, tarballName(m))
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return "", nil, fmt.Errorf("writing %s: %w", path, err)
+	}
+	return path, append([]string{"package.json"}, files...), nil
+}
+
+// RunPackageCLI dispatches package authoring subcommands:
+//
+//	package init [dir] [--name name]
+//	package publish [dir] [--registry url] [--dry-run]
+//
+// publish pushes to --registry, else the manifest's registry, else npm's
+// configured one. --dry-run validates and packs without pushing.
+func RunPackageCLI(args []string, w io.Writer) error {
+	const usage = "usage: package init [dir] [--name name] | package publish [dir] [--registry url] [--dry-run]"
+	if len(args) == 0 {
+		return fmt.Errorf("%s", usage)
+	}
+	fset := flag.NewFlagSet("package "+args[0], flag.ContinueOnError)
+	fset.SetOutput(io.Discard)
+	name := fset.String("name", "", "Package name (default: the directory name)")
+	registry := fset.String("registry", "", "Registry URL to publish to")
+	dryRun := fset.Bool("dry-run", false, "Validate and pack without publishing")
+
+	// Accept the directory before or after the flags.
+	rest := args[1:]
+	dir := "."
+	if len(rest) > 0 && !strings.HasPrefix(rest[0], "-") {
+		dir, rest = rest[0], rest[1:]
+	}
+	if err := fset.Parse(rest); err != nil {
+		return fmt.Errorf("package %s: %w", args[0], err)
+	}
+	if fset.NArg() > 0 {
+		dir = fset.Arg(0)
+	}
+
+	switch args[0] {
+	case "init":
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("creating %s: %w", dir, err)
+		}
+		m, err := InitPackage(dir, *name)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "created package %s in %s\n", m.Name, dir)
+		fmt.Fprintf(w, "edit %s, add prompts, commands, and themes, then run: pi-go package publish %s --dry-run\n", PackageManifestFile, dir)
+		return nil
+	case "publish":
+		tarball, files, err := PackPackage(dir, dir)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "packed %s (%d files)\n", tarball, len(files))
+		for _, f := range files {
+			fmt.Fprintf(w, "  %s\n", f)
+		}
+		if *dryRun {
+			return nil
+		}
+		target := *registry
+		if target == "" {
+			m, err := LoadPackageManifest(dir)
+			if err != nil {
+				return err
+			}
+			target = m.Registry
+		}
+		if err := npmPublish(context.Background(), tarball, target); err != nil {
+			return err
+		}
+		if target == "" {
+			target = "the default npm registry"
+		}
+		fmt.Fprintf(w, "published %s to %s\n", filepath.Base(tarball), target)
+		return nil
+	default:
+		return fmt.Errorf("unknown subcommand %q: expected init or publish", args[0])
+	}
+}
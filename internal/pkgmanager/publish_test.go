@@ -0,0 +1,256 @@
+// ABOUTME: Tests for package authoring: init scaffold, manifest validation, tarball packing, publish
+// ABOUTME: Stubs npm publish to check the registry precedence and that --dry-run never pushes
+
+package pkgmanager
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// tarContents returns the files of a gzipped tarball by name.
+func tarContents(t *testing.T, path string) map[string]string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	files := map[string]string{}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(tr)
+		files[hdr.Name] = string(body)
+	}
+}
+
+func TestInitPackage_ScaffoldValidates(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	m, err := InitPackage(dir, "@team/prompts")
+	if err != nil {
+		t.Fatalf("InitPackage: %v", err)
+	}
+	for _, f := range []string{PackageManifestFile, "prompts/review.md", "commands/changelog.md", "themes/prompts.json", "README.md"} {
+		if _, err := os.Stat(filepath.Join(dir, f)); err != nil {
+			t.Errorf("scaffold missing %s: %v", f, err)
+		}
+	}
+	loaded, err := LoadPackageManifest(dir)
+	if err != nil {
+		t.Fatalf("LoadPackageManifest: %v", err)
+	}
+	if *loaded != *m {
+		t.Errorf("manifest on disk = %+v; want %+v", loaded, m)
+	}
+	if err := ValidatePackage(dir, loaded); err != nil {
+		t.Errorf("the scaffold should validate: %v", err)
+	}
+
+	if _, err := InitPackage(dir, "other"); err == nil {
+		t.Error("init should refuse to overwrite an existing manifest")
+	}
+}
+
+func TestInitPackage_NameFromDir(t *testing.T) {
+	t.Parallel()
+	dir := filepath.Join(t.TempDir(), "Team-Kit")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	m, err := InitPackage(dir, "")
+	if err != nil {
+		t.Fatalf("InitPackage: %v", err)
+	}
+	if m.Name != "team-kit" {
+		t.Errorf("name = %q; want the lowercased directory name", m.Name)
+	}
+	if _, err := InitPackage(t.TempDir(), "Bad Name"); err == nil {
+		t.Error("an invalid name should be rejected")
+	}
+}
+
+func TestValidatePackage_ReportsEveryProblem(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "themes"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "themes", "broken.json"), []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := ValidatePackage(dir, &PackageManifest{
+		Name:     "Team Prompts",
+		Version:  "1.0",
+		Prompts:  "../outside",
+		Commands: "missing",
+		Themes:   "themes",
+	})
+	if err == nil {
+		t.Fatal("ValidatePackage accepted a broken package")
+	}
+	for _, want := range []string{"name \"Team Prompts\"", "version \"1.0\"", "description is required", "inside the package", "commands:", "themes/broken.json"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error missing %q:\n%v", want, err)
+		}
+	}
+
+	empty := t.TempDir()
+	if err := os.Mkdir(filepath.Join(empty, "prompts"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	err = ValidatePackage(empty, &PackageManifest{Name: "x", Version: "1.0.0", Description: "d", Prompts: "prompts"})
+	if err == nil || !strings.Contains(err.Error(), "no prompts, commands, or themes") {
+		t.Errorf("an empty package should be rejected; got %v", err)
+	}
+}
+
+func TestPackPackage_Layout(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	if _, err := InitPackage(dir, "@team/prompts"); err != nil {
+		t.Fatal(err)
+	}
+	for path, content := range map[string]string{
+		"prompts/.draft.md":  "hidden",
+		"prompts/sub/fix.md": "nested",
+		"notes.txt":          "not shipped",
+	} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, path)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, path), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out := t.TempDir()
+	tarball, files, err := PackPackage(dir, out)
+	if err != nil {
+		t.Fatalf("PackPackage: %v", err)
+	}
+	if filepath.Base(tarball) != "team-prompts-0.1.0.tgz" {
+		t.Errorf("tarball = %s; want npm pack's name", tarball)
+	}
+	want := []string{"package.json", "README.md", "commands/changelog.md", PackageManifestFile, "prompts/review.md", "prompts/sub/fix.md", "themes/prompts.json"}
+	if !slices.Equal(files, want) {
+		t.Errorf("files = %v; want %v", files, want)
+	}
+
+	contents := tarContents(t, tarball)
+	if len(contents) != len(want) {
+		t.Errorf("tarball holds %d files; want %d: %v", len(contents), len(want), contents)
+	}
+	var pkg struct {
+		Name     string   `json:"name"`
+		Version  string   `json:"version"`
+		Keywords []string `json:"keywords"`
+	}
+	if err := json.Unmarshal([]byte(contents["package/package.json"]), &pkg); err != nil {
+		t.Fatalf("package.json: %v", err)
+	}
+	if pkg.Name != "@team/prompts" || pkg.Version != "0.1.0" || !slices.Contains(pkg.Keywords, "pi-package") {
+		t.Errorf("package.json = %+v", pkg)
+	}
+
+	again, _, err := PackPackage(dir, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, _ := os.ReadFile(tarball)
+	b, _ := os.ReadFile(again)
+	if !bytes.Equal(a, b) {
+		t.Error("packing the same content twice should give the same bytes")
+	}
+}
+
+// stubPublish records npm publish calls for the test.
+func stubPublish(t *testing.T) *[]string {
+	t.Helper()
+	var registries []string
+	orig := npmPublish
+	npmPublish = func(_ context.Context, tarball, registry string) error {
+		if _, err := os.Stat(tarball); err != nil {
+			t.Errorf("publishing a missing tarball: %v", err)
+		}
+		registries = append(registries, registry)
+		return nil
+	}
+	t.Cleanup(func() { npmPublish = orig })
+	return &registries
+}
+
+func TestRunPackageCLI_Publish(t *testing.T) {
+	calls := stubPublish(t)
+	dir := filepath.Join(t.TempDir(), "kit")
+	var out bytes.Buffer
+
+	if err := RunPackageCLI([]string{"init", dir}, &out); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if err := RunPackageCLI([]string{"publish", dir, "--dry-run"}, &out); err != nil {
+		t.Fatalf("publish --dry-run: %v", err)
+	}
+	if len(*calls) != 0 {
+		t.Errorf("--dry-run published %d times", len(*calls))
+	}
+	if !strings.Contains(out.String(), "kit-0.1.0.tgz") {
+		t.Errorf("output should name the tarball:\n%s", out.String())
+	}
+
+	if err := RunPackageCLI([]string{"publish", dir}, &out); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	m, _ := LoadPackageManifest(dir)
+	m.Registry = "https://npm.team.example"
+	data, _ := json.Marshal(m)
+	if err := os.WriteFile(filepath.Join(dir, PackageManifestFile), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := RunPackageCLI([]string{"publish", dir}, &out); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if err := RunPackageCLI([]string{"publish", "--registry", "http://localhost:4873", dir}, &out); err != nil {
+		t.Fatalf("publish --registry: %v", err)
+	}
+	want := []string{"", "https://npm.team.example", "http://localhost:4873"}
+	if !slices.Equal(*calls, want) {
+		t.Errorf("registries = %q; want the default, then the manifest's, then the flag's", *calls)
+	}
+}
+
+func TestRunPackageCLI_PublishInvalid(t *testing.T) {
+	calls := stubPublish(t)
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, PackageManifestFile), []byte(`{"name": "kit"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	err := RunPackageCLI([]string{"publish", dir}, io.Discard)
+	if err == nil || !strings.Contains(err.Error(), "invalid package") {
+		t.Errorf("publish of an invalid package = %v; want a validation error", err)
+	}
+	if len(*calls) != 0 {
+		t.Error("an invalid package must not be published")
+	}
+}
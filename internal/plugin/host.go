@@ -0,0 +1,500 @@
+// ABOUTME: Plugin host: starts plugin processes and speaks newline-delimited JSON with them
+// ABOUTME: Bridges plugin tools and slash commands; tool events run as registry middleware
+
+package plugin
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/hooks"
+	pilog "github.com/mauromedda/pi-coding-agent-go/internal/log"
+	"github.com/mauromedda/pi-coding-agent-go/internal/tools"
+	"github.com/mauromedda/pi-coding-agent-go/internal/types"
+)
+
+// ProtocolVersion is sent with initialize; it changes only on
+// incompatible protocol changes.
+const ProtocolVersion = 1
+
+const (
+	maxLineBytes   = 10 * 1024 * 1024 // largest message a plugin may send
+	maxEventResult = 64 * 1024        // tool output carried by PostToolUse
+	startTimeout   = 10 * time.Second
+	eventTimeout   = 10 * time.Second // a PreToolUse answer; silence allows the call
+	commandTimeout = 30 * time.Second
+	stopTimeout    = 2 * time.Second
+)
+
+// supportedEvents are the lifecycle events plugins may subscribe to.
+var supportedEvents = []hooks.HookEvent{hooks.PreToolUse, hooks.PostToolUse, hooks.SessionStart, hooks.SessionEnd}
+
+// ToolSpec is a tool a plugin registers. Parameters is a JSON schema.
+type ToolSpec struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Parameters  json.RawMessage `json:"parameters,omitempty"`
+	ReadOnly    bool            `json:"readOnly,omitempty"`
+}
+
+// CommandSpec is a slash command a plugin registers.
+type CommandSpec struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Capabilities is a plugin's answer to initialize.
+type Capabilities struct {
+	Tools    []ToolSpec        `json:"tools,omitempty"`
+	Commands []CommandSpec     `json:"commands,omitempty"`
+	Events   []hooks.HookEvent `json:"events,omitempty"`
+}
+
+// Event is the payload of an event message: the hook input plus, for
+// PostToolUse, the tool's output.
+type Event struct {
+	hooks.HookInput
+	Result  string `json:"result,omitempty"`
+	IsError bool   `json:"is_error,omitempty"`
+}
+
+// request is a host-to-plugin message; without an ID it is a
+// notification and gets no reply.
+type request struct {
+	ID     int64  `json:"id,omitempty"`
+	Method string `json:"method"`
+	Params any    `json:"params,omitempty"`
+}
+
+// response is a plugin's reply to the request with the same ID.
+type response struct {
+	ID     int64           `json:"id"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// Plugin is a running plugin.
+type Plugin struct {
+	Name string
+	Caps Capabilities
+
+	cmd     *exec.Cmd // nil when connected to in-process pipes
+	stdin   io.WriteCloser
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	pending map[int64]chan response
+	nextID  atomic.Int64
+	done    chan struct{} // closed when the plugin's output ends
+}
+
+// Start launches a plugin in the working directory cwd and initializes it.
+func Start(ctx context.Context, def Def, cwd string) (*Plugin, error) {
+	cmd := exec.Command(def.Command, def.Args...)
+	cmd.Dir = cwd
+	cmd.Env = os.Environ()
+	if def.Dir != "" {
+		cmd.Env = append(cmd.Env, "PI_PLUGIN_DIR="+def.Dir)
+	}
+	for _, k := range slices.Sorted(maps.Keys(def.Env)) {
+		cmd.Env = append(cmd.Env, k+"="+def.Env[k])
+	}
+	cmd.Stderr = &stderrLog{name: def.Name}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: stdin pipe: %w", def.Name, err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: stdout pipe: %w", def.Name, err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("plugin %s: starting %s: %w", def.Name, def.Command, err)
+	}
+
+	p := connect(def.Name, stdout, stdin)
+	p.cmd = cmd
+	if err := p.initialize(ctx, cwd); err != nil {
+		p.Close()
+		return nil, err
+	}
+	return p, nil
+}
+
+// connect speaks the protocol over r and w without a process.
+func connect(name string, r io.Reader, w io.WriteCloser) *Plugin {
+	p := &Plugin{
+		Name:    name,
+		stdin:   w,
+		pending: make(map[int64]chan response),
+		done:    make(chan struct{}),
+	}
+	go p.readLoop(r)
+	return p
+}
+
+// initialize exchanges the protocol version for the plugin's capabilities.
+func (p *Plugin) initialize(ctx context.Context, cwd string) error {
+	ctx, cancel := context.WithTimeout(ctx, startTimeout)
+	defer cancel()
+	params := map[string]any{"protocolVersion": ProtocolVersion, "name": p.Name, "cwd": cwd}
+	if err := p.call(ctx, "initialize", params, &p.Caps); err != nil {
+		return fmt.Errorf("plugin %s: initialize: %w", p.Name, err)
+	}
+	for _, t := range p.Caps.Tools {
+		if t.Name == "" {
+			return fmt.Errorf("plugin %s: a tool has no name", p.Name)
+		}
+	}
+	for _, c := range p.Caps.Commands {
+		if c.Name == "" {
+			return fmt.Errorf("plugin %s: a command has no name", p.Name)
+		}
+	}
+	for _, e := range p.Caps.Events {
+		if !slices.Contains(supportedEvents, e) {
+			return fmt.Errorf("plugin %s: unsupported event %q (supported: %v)", p.Name, e, supportedEvents)
+		}
+	}
+	return nil
+}
+
+// readLoop routes replies to their waiting calls until the output ends.
+func (p *Plugin) readLoop(r io.Reader) {
+	defer close(p.done)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineBytes)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var resp response
+		if err := json.Unmarshal(line, &resp); err != nil || resp.ID == 0 {
+			pilog.Debug("plugin %s: ignoring output line %q", p.Name, line)
+			continue
+		}
+		p.mu.Lock()
+		ch, ok := p.pending[resp.ID]
+		delete(p.pending, resp.ID)
+		p.mu.Unlock()
+		if ok {
+			ch <- resp
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		pilog.Debug("plugin %s: reading output: %v", p.Name, err)
+	}
+}
+
+// call sends a request and decodes the reply's result into out.
+func (p *Plugin) call(ctx context.Context, method string, params, out any) error {
+	id := p.nextID.Add(1)
+	ch := make(chan response, 1)
+	p.mu.Lock()
+	p.pending[id] = ch
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		delete(p.pending, id)
+		p.mu.Unlock()
+	}()
+
+	if err := p.send(request{ID: id, Method: method, Params: params}); err != nil {
+		return err
+	}
+	var resp response
+	select {
+	case resp = <-ch:
+	case <-p.done:
+		// The reply may have been the plugin's last line.
+		select {
+		case resp = <-ch:
+		default:
+			return fmt.Errorf("plugin exited")
+		}
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if resp.Error != nil {
+		return fmt.Errorf("%s", resp.Error.Message)
+	}
+	if out == nil || len(resp.Result) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Result, out); err != nil {
+		return fmt.Errorf("decoding %s result: %w", method, err)
+	}
+	return nil
+}
+
+// notify sends a request that gets no reply.
+func (p *Plugin) notify(method string, params any) error {
+	return p.send(request{Method: method, Params: params})
+}
+
+func (p *Plugin) send(req request) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("marshaling %s: %w", req.Method, err)
+	}
+	p.writeMu.Lock()
+	defer p.writeMu.Unlock()
+	if _, err := p.stdin.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("writing %s: %w", req.Method, err)
+	}
+	return nil
+}
+
+// subscribes reports whether the plugin asked for event.
+func (p *Plugin) subscribes(event hooks.HookEvent) bool {
+	return slices.Contains(p.Caps.Events, event)
+}
+
+// Close closes the plugin's stdin, its signal to exit, and kills it if it
+// is still running after a grace period.
+func (p *Plugin) Close() error {
+	p.stdin.Close()
+	if p.cmd == nil {
+		return nil
+	}
+	select {
+	case <-p.done:
+	case <-time.After(stopTimeout):
+		_ = p.cmd.Process.Kill()
+		<-p.done
+	}
+	return p.cmd.Wait()
+}
+
+// tool bridges one of the plugin's tools into an agent tool.
+func (p *Plugin) tool(spec ToolSpec) *types.AgentTool {
+	params := spec.Parameters
+	if len(params) == 0 {
+		params = json.RawMessage(`{"type":"object","properties":{}}`)
+	}
+	return &types.AgentTool{
+		Name:        spec.Name,
+		Label:       spec.Name,
+		Description: spec.Description,
+		Parameters:  params,
+		ReadOnly:    spec.ReadOnly,
+		Execute: func(ctx context.Context, _ string, args map[string]any, _ func(types.ToolUpdate)) (types.ToolResult, error) {
+			var res struct {
+				Content string `json:"content"`
+				IsError bool   `json:"isError"`
+			}
+			if err := p.call(ctx, "tool", map[string]any{"name": spec.Name, "args": args}, &res); err != nil {
+				return types.ToolResult{Content: fmt.Sprintf("plugin %s: %v", p.Name, err), IsError: true}, nil
+			}
+			return types.ToolResult{Content: res.Content, IsError: res.IsError}, nil
+		},
+	}
+}
+
+// Command is a slash command served by a plugin.
+type Command struct {
+	Plugin      string
+	Name        string
+	Description string
+	Run         func(args string) (string, error)
+}
+
+// Host holds the running plugins.
+type Host struct {
+	plugins []*Plugin
+	cwd     string
+}
+
+// StartAll starts every plugin in defs concurrently. Plugins that fail to
+// start are left out and reported as errors.
+func StartAll(ctx context.Context, defs map[string]Def, cwd string) (*Host, []error) {
+	names := slices.Sorted(maps.Keys(defs))
+	plugins := make([]*Plugin, len(names))
+	errs := make([]error, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			plugins[i], errs[i] = Start(ctx, defs[name], cwd)
+		}()
+	}
+	wg.Wait()
+
+	h := &Host{cwd: cwd}
+	var failed []error
+	for i := range names {
+		if errs[i] != nil {
+			failed = append(failed, errs[i])
+			continue
+		}
+		h.plugins = append(h.plugins, plugins[i])
+	}
+	return h, failed
+}
+
+// Plugins returns the running plugins, sorted by name.
+func (h *Host) Plugins() []*Plugin {
+	return h.plugins
+}
+
+// Tools bridges every plugin tool into an agent tool. A tool named like
+// an existing one (exists reports true) or like an earlier plugin's tool
+// is skipped and reported as an error.
+func (h *Host) Tools(exists func(name string) bool) ([]*types.AgentTool, []error) {
+	var tools []*types.AgentTool
+	var errs []error
+	seen := make(map[string]string)
+	for _, p := range h.plugins {
+		for _, spec := range p.Caps.Tools {
+			if other, ok := seen[spec.Name]; ok {
+				errs = append(errs, fmt.Errorf("plugin %s: tool %q is already registered by plugin %s", p.Name, spec.Name, other))
+				continue
+			}
+			if exists(spec.Name) {
+				errs = append(errs, fmt.Errorf("plugin %s: tool %q is already a built-in tool", p.Name, spec.Name))
+				continue
+			}
+			seen[spec.Name] = p.Name
+			tools = append(tools, p.tool(spec))
+		}
+	}
+	return tools, errs
+}
+
+// Commands returns every plugin's slash commands.
+func (h *Host) Commands() []Command {
+	var cmds []Command
+	for _, p := range h.plugins {
+		for _, spec := range p.Caps.Commands {
+			cmds = append(cmds, Command{
+				Plugin:      p.Name,
+				Name:        spec.Name,
+				Description: spec.Description,
+				Run: func(args string) (string, error) {
+					ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+					defer cancel()
+					var res struct {
+						Output string `json:"output"`
+					}
+					if err := p.call(ctx, "command", map[string]any{"name": spec.Name, "args": args}, &res); err != nil {
+						return "", fmt.Errorf("plugin %s: %w", p.Name, err)
+					}
+					return res.Output, nil
+				},
+			})
+		}
+	}
+	return cmds
+}
+
+// subscribers returns the plugins that asked for event.
+func (h *Host) subscribers(event hooks.HookEvent) []*Plugin {
+	var out []*Plugin
+	for _, p := range h.plugins {
+		if p.subscribes(event) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// Middleware returns tool middleware through which plugins subscribed to
+// PreToolUse can block calls and those subscribed to PostToolUse see their
+// results. It returns nil when no plugin subscribes to either.
+func (h *Host) Middleware() tools.Middleware {
+	pre, post := h.subscribers(hooks.PreToolUse), h.subscribers(hooks.PostToolUse)
+	if len(pre) == 0 && len(post) == 0 {
+		return nil
+	}
+	return func(next tools.Handler) tools.Handler {
+		return func(call *tools.ToolCall) (types.ToolResult, error) {
+			input := hooks.HookInput{Tool: call.Name, Args: call.Args, WorkDir: h.cwd}
+			for _, p := range pre {
+				input.Event = hooks.PreToolUse
+				ctx, cancel := context.WithTimeout(call.Ctx, eventTimeout)
+				var out hooks.HookOutput
+				err := p.call(ctx, "event", Event{HookInput: input}, &out)
+				cancel()
+				if err != nil {
+					pilog.Debug("plugin %s: PreToolUse for %s: %v", p.Name, call.Name, err)
+					continue
+				}
+				if out.Blocked {
+					msg := fmt.Sprintf("blocked by plugin %s", p.Name)
+					if out.Message != "" {
+						msg += ": " + out.Message
+					}
+					return types.ToolResult{Content: msg, IsError: true}, nil
+				}
+			}
+
+			result, err := next(call)
+			if len(post) > 0 {
+				input.Event = hooks.PostToolUse
+				ev := Event{HookInput: input, Result: result.Content, IsError: result.IsError || err != nil}
+				if err != nil {
+					ev.Result = err.Error()
+				}
+				if len(ev.Result) > maxEventResult {
+					ev.Result = ev.Result[:maxEventResult]
+				}
+				for _, p := range post {
+					if nerr := p.notify("event", ev); nerr != nil {
+						pilog.Debug("plugin %s: PostToolUse: %v", p.Name, nerr)
+					}
+				}
+			}
+			return result, err
+		}
+	}
+}
+
+// Emit notifies the subscribed plugins of a session event.
+func (h *Host) Emit(event hooks.HookEvent) {
+	for _, p := range h.subscribers(event) {
+		ev := Event{HookInput: hooks.HookInput{Event: event, WorkDir: h.cwd}}
+		if err := p.notify("event", ev); err != nil {
+			pilog.Debug("plugin %s: %s: %v", p.Name, event, err)
+		}
+	}
+}
+
+// Close stops every plugin.
+func (h *Host) Close() {
+	var wg sync.WaitGroup
+	for _, p := range h.plugins {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := p.Close(); err != nil {
+				pilog.Debug("plugin %s: exit: %v", p.Name, err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// stderrLog forwards a plugin's stderr to the debug log.
+type stderrLog struct {
+	name string
+}
+
+func (l *stderrLog) Write(b []byte) (int, error) {
+	pilog.Debug("plugin %s: %s", l.name, bytes.TrimRight(b, "\n"))
+	return len(b), nil
+}
@@ -0,0 +1,263 @@
+// ABOUTME: Tests for the plugin host against an in-process fake plugin and a shell script plugin
+// ABOUTME: Covers initialize, tool and command calls, PreToolUse blocking, and event delivery
+
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/hooks"
+	"github.com/mauromedda/pi-coding-agent-go/internal/tools"
+	"github.com/mauromedda/pi-coding-agent-go/internal/types"
+)
+
+// fakePlugin answers requests with handle, which returns the result or
+// an error message. Notifications are recorded.
+type fakePlugin struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (f *fakePlugin) serve(t *testing.T, r io.Reader, w io.WriteCloser, handle func(method string, params json.RawMessage) (any, string)) {
+	defer w.Close()
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		var req struct {
+			ID     int64           `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			t.Errorf("host sent invalid JSON: %v", err)
+			return
+		}
+		if req.ID == 0 {
+			var ev Event
+			_ = json.Unmarshal(req.Params, &ev)
+			f.mu.Lock()
+			f.events = append(f.events, ev)
+			f.mu.Unlock()
+			continue
+		}
+		result, errMsg := handle(req.Method, req.Params)
+		resp := map[string]any{"id": req.ID}
+		if errMsg != "" {
+			resp["error"] = map[string]string{"message": errMsg}
+		} else {
+			resp["result"] = result
+		}
+		data, _ := json.Marshal(resp)
+		if _, err := w.Write(append(data, '\n')); err != nil {
+			return
+		}
+	}
+}
+
+func (f *fakePlugin) received() []Event {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Event(nil), f.events...)
+}
+
+// startFake connects a Plugin to a fake serving handle and initializes it.
+func startFake(t *testing.T, name string, handle func(method string, params json.RawMessage) (any, string)) (*Plugin, *fakePlugin) {
+	t.Helper()
+	hostR, pluginW := io.Pipe()
+	pluginR, hostW := io.Pipe()
+	f := &fakePlugin{}
+	go f.serve(t, pluginR, pluginW, handle)
+	p := connect(name, hostR, hostW)
+	t.Cleanup(func() { p.Close() })
+	if err := p.initialize(context.Background(), "/work"); err != nil {
+		t.Fatalf("initialize: %v", err)
+	}
+	return p, f
+}
+
+// linter is a fake plugin with a tool, a command, and tool hooks that
+// block bash commands containing "rm -rf".
+func linter(method string, params json.RawMessage) (any, string) {
+	switch method {
+	case "initialize":
+		return Capabilities{
+			Tools:    []ToolSpec{{Name: "lint", Description: "Run the linter", ReadOnly: true}},
+			Commands: []CommandSpec{{Name: "lint-report", Description: "Show the last lint report"}},
+			Events:   []hooks.HookEvent{hooks.PreToolUse, hooks.PostToolUse, hooks.SessionStart},
+		}, ""
+	case "tool":
+		var p struct {
+			Name string         `json:"name"`
+			Args map[string]any `json:"args"`
+		}
+		_ = json.Unmarshal(params, &p)
+		if p.Args["path"] == "" {
+			return nil, "path is required"
+		}
+		return map[string]any{"content": "2 issues in " + p.Args["path"].(string), "isError": true}, ""
+	case "command":
+		var p struct{ Args string }
+		_ = json.Unmarshal(params, &p)
+		return map[string]any{"output": "report for " + p.Args}, ""
+	case "event":
+		var ev Event
+		_ = json.Unmarshal(params, &ev)
+		cmd, _ := ev.Args["command"].(string)
+		return hooks.HookOutput{Blocked: strings.Contains(cmd, "rm -rf"), Message: "destructive command"}, ""
+	}
+	return nil, "unknown method " + method
+}
+
+func TestHost_ToolsAndCommands(t *testing.T) {
+	t.Parallel()
+	p, _ := startFake(t, "linter", linter)
+	h := &Host{plugins: []*Plugin{p}, cwd: "/work"}
+
+	bridged, errs := h.Tools(func(name string) bool { return false })
+	if len(errs) != 0 || len(bridged) != 1 {
+		t.Fatalf("Tools = %v, %v", bridged, errs)
+	}
+	lint := bridged[0]
+	if lint.Name != "lint" || !lint.ReadOnly || !json.Valid(lint.Parameters) {
+		t.Errorf("lint tool = %+v", lint)
+	}
+	res, err := lint.Execute(context.Background(), "1", map[string]any{"path": "main.go"}, nil)
+	if err != nil || res.Content != "2 issues in main.go" || !res.IsError {
+		t.Errorf("lint = %+v, %v", res, err)
+	}
+	res, _ = lint.Execute(context.Background(), "2", map[string]any{"path": ""}, nil)
+	if !res.IsError || !strings.Contains(res.Content, "plugin linter: path is required") {
+		t.Errorf("a plugin error should become a tool error: %+v", res)
+	}
+
+	if _, errs := h.Tools(func(name string) bool { return name == "lint" }); len(errs) != 1 {
+		t.Errorf("a tool shadowing a built-in should be refused; errs = %v", errs)
+	}
+
+	cmds := h.Commands()
+	if len(cmds) != 1 || cmds[0].Name != "lint-report" || cmds[0].Plugin != "linter" {
+		t.Fatalf("Commands = %+v", cmds)
+	}
+	if out, err := cmds[0].Run("./..."); err != nil || out != "report for ./..." {
+		t.Errorf("Run = %q, %v", out, err)
+	}
+}
+
+func TestHost_MiddlewareAndEmit(t *testing.T) {
+	t.Parallel()
+	p, f := startFake(t, "guard", linter)
+	h := &Host{plugins: []*Plugin{p}, cwd: "/work"}
+
+	ran := 0
+	bash := h.Middleware()(func(call *tools.ToolCall) (types.ToolResult, error) {
+		ran++
+		return types.ToolResult{Content: "ok: " + call.Args["command"].(string)}, nil
+	})
+	call := func(command string) *tools.ToolCall {
+		return &tools.ToolCall{Ctx: context.Background(), Name: "bash", Args: map[string]any{"command": command}}
+	}
+
+	res, _ := bash(call("rm -rf /"))
+	if !res.IsError || res.Content != "blocked by plugin guard: destructive command" || ran != 0 {
+		t.Errorf("blocked call = %+v (ran %d times)", res, ran)
+	}
+	res, _ = bash(call("ls"))
+	if res.Content != "ok: ls" || ran != 1 {
+		t.Errorf("allowed call = %+v", res)
+	}
+
+	h.Emit(hooks.SessionStart)
+	h.Emit(hooks.SessionEnd) // not subscribed
+
+	var events []Event
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if events = f.received(); len(events) >= 2 {
+			break
+		}
+	}
+	if len(events) != 2 {
+		t.Fatalf("events = %+v; want PostToolUse and SessionStart", events)
+	}
+	if ev := events[0]; ev.Event != hooks.PostToolUse || ev.Tool != "bash" || ev.Result != "ok: ls" || ev.WorkDir != "/work" {
+		t.Errorf("PostToolUse = %+v", ev)
+	}
+	if events[1].Event != hooks.SessionStart {
+		t.Errorf("second event = %+v; want SessionStart", events[1])
+	}
+
+	if (&Host{}).Middleware() != nil {
+		t.Error("without subscribers there should be no middleware")
+	}
+}
+
+func TestInitialize_RejectsUnsupportedEvent(t *testing.T) {
+	t.Parallel()
+	hostR, pluginW := io.Pipe()
+	pluginR, hostW := io.Pipe()
+	f := &fakePlugin{}
+	go f.serve(t, pluginR, pluginW, func(string, json.RawMessage) (any, string) {
+		return Capabilities{Events: []hooks.HookEvent{"Compact"}}, ""
+	})
+	p := connect("odd", hostR, hostW)
+	defer p.Close()
+	err := p.initialize(context.Background(), "/work")
+	if err == nil || !strings.Contains(err.Error(), `unsupported event "Compact"`) {
+		t.Errorf("initialize = %v; want an unsupported event error", err)
+	}
+}
+
+func TestStartAll_Process(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test plugin is a shell script")
+	}
+	t.Parallel()
+	dir := t.TempDir()
+	script := filepath.Join(dir, "echo-plugin")
+	// Answers initialize with one tool, then echoes PI_GREETING for any call.
+	err := os.WriteFile(script, []byte(`#!/bin/sh
+while read -r line; do
+  id=$(printf '%s' "$line" | sed -n 's/^{"id":\([0-9]*\).*/\1/p')
+  [ -z "$id" ] && continue
+  case "$line" in
+    *'"method":"initialize"'*) printf '{"id":%s,"result":{"tools":[{"name":"greet","description":"Say hello"}]}}\n' "$id" ;;
+    *) printf '{"id":%s,"result":{"content":"%s from %s"}}\n' "$id" "$PI_GREETING" "$(basename "$PWD")" ;;
+  esac
+done
+`), 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	work := filepath.Join(dir, "project")
+	if err := os.Mkdir(work, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	h, errs := StartAll(context.Background(), map[string]Def{
+		"echo":    {Name: "echo", Command: script, Env: map[string]string{"PI_GREETING": "hello"}},
+		"missing": {Name: "missing", Command: filepath.Join(dir, "nope")},
+	}, work)
+	defer h.Close()
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "plugin missing") {
+		t.Errorf("errs = %v; want the missing plugin reported", errs)
+	}
+	if len(h.Plugins()) != 1 {
+		t.Fatalf("plugins = %d; want 1", len(h.Plugins()))
+	}
+	bridged, _ := h.Tools(func(string) bool { return false })
+	if len(bridged) != 1 {
+		t.Fatalf("tools = %v", bridged)
+	}
+	res, err := bridged[0].Execute(context.Background(), "1", nil, nil)
+	if err != nil || res.Content != "hello from project" {
+		t.Errorf("greet = %+v, %v; want the plugin's env and working directory", res, err)
+	}
+}
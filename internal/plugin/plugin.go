@@ -0,0 +1,130 @@
+// ABOUTME: Plugin discovery: plugin.json manifests in the plugins directories merged with settings
+// ABOUTME: A plugin is a subprocess adding tools, slash commands, and event hooks over JSON lines
+
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/config"
+)
+
+// ManifestFile describes a plugin inside its directory.
+const ManifestFile = "plugin.json"
+
+// Manifest is the plugin.json of a discovered plugin. Name defaults to the
+// directory name; a relative Command is resolved against the directory.
+type Manifest struct {
+	Name        string            `json:"name,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Command     string            `json:"command"`
+	Args        []string          `json:"args,omitempty"`
+	Env         map[string]string `json:"env,omitempty"`
+}
+
+// Def is a plugin ready to start.
+type Def struct {
+	Name    string
+	Command string
+	Args    []string
+	Env     map[string]string
+	Dir     string // plugin directory; "" for plugins declared only in settings
+}
+
+// Discover returns the plugins in dirs, each holding one subdirectory per
+// plugin, merged with the ones declared in settings. Later directories
+// override earlier ones and settings override both; a disabled settings
+// entry removes the plugin. Broken manifests are returned as errors and
+// skipped.
+func Discover(dirs []string, declared map[string]config.PluginDef) (map[string]Def, []error) {
+	defs := make(map[string]Def)
+	var errs []error
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+				continue
+			}
+			def, err := loadManifest(filepath.Join(dir, e.Name()))
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			defs[def.Name] = def
+		}
+	}
+
+	for name, pd := range declared {
+		if pd.Disabled {
+			delete(defs, name)
+			continue
+		}
+		def := defs[name]
+		def.Name = name
+		if pd.Command != "" {
+			def.Command, def.Args = resolveCommand(def.Dir, pd.Command), pd.Args
+		}
+		if len(pd.Env) > 0 {
+			env := maps.Clone(def.Env)
+			if env == nil {
+				env = make(map[string]string)
+			}
+			maps.Copy(env, pd.Env)
+			def.Env = env
+		}
+		if def.Command == "" {
+			errs = append(errs, fmt.Errorf("plugin %q: command is required", name))
+			delete(defs, name)
+			continue
+		}
+		defs[name] = def
+	}
+	return defs, errs
+}
+
+// loadManifest reads the plugin in dir. A missing manifest returns an
+// error satisfying os.IsNotExist.
+func loadManifest(dir string) (Def, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return Def{}, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return Def{}, fmt.Errorf("plugin %s: parsing %s: %w", dir, ManifestFile, err)
+	}
+	if m.Command == "" {
+		return Def{}, fmt.Errorf("plugin %s: %s has no command", dir, ManifestFile)
+	}
+	name := m.Name
+	if name == "" {
+		name = filepath.Base(dir)
+	}
+	return Def{
+		Name:    name,
+		Command: resolveCommand(dir, m.Command),
+		Args:    m.Args,
+		Env:     m.Env,
+		Dir:     dir,
+	}, nil
+}
+
+// resolveCommand makes a relative command path such as "./bin/plugin"
+// absolute against the plugin directory. Bare names are looked up on PATH.
+func resolveCommand(dir, command string) string {
+	if dir == "" || filepath.IsAbs(command) || !strings.ContainsRune(command, '/') {
+		return command
+	}
+	return filepath.Join(dir, filepath.FromSlash(command))
+}
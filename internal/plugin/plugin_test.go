@@ -0,0 +1,83 @@
+// ABOUTME: Tests for plugin discovery from plugin directories and settings
+// ABOUTME: Covers name defaults, relative commands, overrides, disabling, and broken manifests
+
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/config"
+)
+
+func writeManifest(t *testing.T, dir, content string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ManifestFile), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDiscover_Directories(t *testing.T) {
+	t.Parallel()
+	user, global := t.TempDir(), t.TempDir()
+	writeManifest(t, filepath.Join(user, "lint"), `{"command": "./bin/lint", "args": ["--serve"]}`)
+	writeManifest(t, filepath.Join(user, "jira"), `{"name": "tickets", "command": "jira-plugin"}`)
+	writeManifest(t, filepath.Join(global, "lint"), `{"command": "/opt/lint"}`)
+	writeManifest(t, filepath.Join(user, "broken"), `{"command": `)
+	writeManifest(t, filepath.Join(user, "empty"), `{}`)
+	if err := os.MkdirAll(filepath.Join(user, "notes"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	defs, errs := Discover([]string{user, global, filepath.Join(user, "missing")}, nil)
+	if len(errs) != 2 {
+		t.Errorf("errs = %v; want the broken and the empty manifest", errs)
+	}
+	if len(defs) != 2 {
+		t.Fatalf("defs = %v; want lint and tickets", defs)
+	}
+	if got := defs["lint"].Command; got != "/opt/lint" {
+		t.Errorf("lint command = %q; the later directory should win", got)
+	}
+	tickets := defs["tickets"]
+	if tickets.Command != "jira-plugin" || tickets.Dir != filepath.Join(user, "jira") {
+		t.Errorf("tickets = %+v; want the manifest name and a PATH command", tickets)
+	}
+
+	defs, _ = Discover([]string{user}, nil)
+	if got := defs["lint"]; got.Command != filepath.Join(user, "lint", "bin", "lint") || len(got.Args) != 1 {
+		t.Errorf("lint = %+v; want the command resolved against its directory", got)
+	}
+}
+
+func TestDiscover_Settings(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	writeManifest(t, filepath.Join(dir, "lint"), `{"command": "./lint", "env": {"LEVEL": "warn", "MODE": "fast"}}`)
+	writeManifest(t, filepath.Join(dir, "noisy"), `{"command": "noisy"}`)
+
+	defs, errs := Discover([]string{dir}, map[string]config.PluginDef{
+		"lint":   {Env: map[string]string{"LEVEL": "error"}},
+		"noisy":  {Disabled: true},
+		"search": {Command: "search-plugin", Args: []string{"-v"}},
+		"bad":    {Args: []string{"x"}},
+	})
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), `"bad"`) {
+		t.Errorf("errs = %v; want one for the plugin without a command", errs)
+	}
+	if _, ok := defs["noisy"]; ok {
+		t.Error("a disabled plugin should be removed")
+	}
+	lint := defs["lint"]
+	if lint.Command != filepath.Join(dir, "lint", "lint") || lint.Env["LEVEL"] != "error" || lint.Env["MODE"] != "fast" {
+		t.Errorf("lint = %+v; want the manifest merged with the settings env", lint)
+	}
+	if s := defs["search"]; s.Command != "search-plugin" || s.Dir != "" {
+		t.Errorf("search = %+v; want the settings-only plugin", s)
+	}
+}
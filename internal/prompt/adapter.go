@@ -0,0 +1,154 @@
+// ABOUTME: Per-model prompt adapters: tone and format sections tuned to each model family
+// ABOUTME: Family detection from the model ID, API, and base URL; overridable via PromptsSettings
+
+package prompt
+
+import (
+	"strings"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/config"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+// Model families with a built-in prompt adapter.
+const (
+	FamilyClaude     = "claude"
+	FamilyGPT        = "gpt"
+	FamilyGemini     = "gemini"
+	FamilySmallLocal = "small-local"
+)
+
+// Adapter holds the tone and format sections written into the system
+// prompt for one model family. The zero Adapter writes nothing.
+type Adapter struct {
+	Family string
+	Tone   string
+	Format string
+}
+
+// builtinAdapters are the default sections per family.
+var builtinAdapters = map[string]Adapter{
+	FamilyClaude: {
+		Family: FamilyClaude,
+		Tone:   "Be direct and collaborative. Briefly explain your reasoning when it helps the user decide, and say so when you are unsure.",
+		Format: "Use GitHub-flavored Markdown. Put code in fenced blocks with a language tag and wrap paths, identifiers, and commands in backticks. Use headings only for long answers.",
+	},
+	FamilyGPT: {
+		Family: FamilyGPT,
+		Tone:   "Be concise and direct. Do not restate the request or end with offers of further help.",
+		Format: "Use Markdown with short bullet lists. Put code in fenced blocks with a language tag. Show only the changed parts of long files.",
+	},
+	FamilyGemini: {
+		Family: FamilyGemini,
+		Tone:   "Be precise and factual. Stay on the task and do not speculate about code you have not read.",
+		Format: "Use Markdown. Put code in fenced blocks with a language tag. Give procedures as numbered steps.",
+	},
+	FamilySmallLocal: {
+		Family: FamilySmallLocal,
+		Tone:   "Be brief. Follow the instructions exactly and do not add extra commentary.",
+		Format: "Answer in short plain text or simple Markdown. Call one tool at a time and wait for its result before the next step.",
+	},
+}
+
+// openWeightPrefixes mark model IDs of open-weight models usually run locally.
+var openWeightPrefixes = []string{
+	"llama", "codellama", "qwen", "mistral", "mixtral", "codestral", "phi",
+	"gemma", "deepseek", "starcoder", "granite", "smollm",
+}
+
+// ModelFamily detects the adapter family of model from its ID, then its
+// base URL and API. It returns "" when the family is unknown.
+func ModelFamily(model *ai.Model) string {
+	if model == nil {
+		return ""
+	}
+	id := strings.ToLower(model.ID)
+	if i := strings.LastIndex(id, "/"); i >= 0 {
+		id = id[i+1:] // "openai/gpt-4o", "meta-llama/llama-3"
+	}
+	switch {
+	case strings.HasPrefix(id, "claude"):
+		return FamilyClaude
+	case strings.HasPrefix(id, "gpt"), strings.HasPrefix(id, "chatgpt"),
+		len(id) > 1 && id[0] == 'o' && id[1] >= '1' && id[1] <= '9': // o1, o3-mini, o4-mini
+		return FamilyGPT
+	case strings.HasPrefix(id, "gemini"):
+		return FamilyGemini
+	}
+	for _, p := range openWeightPrefixes {
+		if strings.HasPrefix(id, p) {
+			return FamilySmallLocal
+		}
+	}
+	if isLocalURL(model.BaseURL) {
+		return FamilySmallLocal
+	}
+	switch model.Api {
+	case ai.ApiAnthropic:
+		return FamilyClaude
+	case ai.ApiOpenAI:
+		return FamilyGPT
+	case ai.ApiGoogle, ai.ApiVertex:
+		return FamilyGemini
+	}
+	return ""
+}
+
+// isLocalURL reports whether baseURL points at this machine.
+func isLocalURL(baseURL string) bool {
+	for _, host := range []string{"://localhost", "://127.0.0.1", "://0.0.0.0", "://[::1]"} {
+		if strings.Contains(baseURL, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// AdapterFor returns the built-in adapter of family, or the zero Adapter.
+func AdapterFor(family string) Adapter {
+	return builtinAdapters[family]
+}
+
+// ResolveAdapter picks the adapter for model per ps: detected for "auto",
+// forced for a family name, none for "none". Non-empty overrides in
+// ps.Adapters replace the built-in sections; they also apply to a family
+// without a built-in adapter.
+func ResolveAdapter(ps *config.PromptsSettings, model *ai.Model) Adapter {
+	family := ps.EffectiveModelFamily()
+	switch family {
+	case "none":
+		return Adapter{}
+	case "auto":
+		family = ModelFamily(model)
+	}
+	if family == "" {
+		return Adapter{}
+	}
+	a := AdapterFor(family)
+	a.Family = family
+	if ps != nil {
+		if o, ok := ps.Adapters[family]; ok {
+			if o.Tone != "" {
+				a.Tone = o.Tone
+			}
+			if o.Format != "" {
+				a.Format = o.Format
+			}
+		}
+	}
+	return a
+}
+
+// writeAdapter writes the adapter's tone and format sections.
+func writeAdapter(b *strings.Builder, a Adapter) {
+	if a.Tone != "" {
+		b.WriteString("# Tone\n")
+		b.WriteString(a.Tone)
+		b.WriteString("\n\n")
+	}
+	if a.Format != "" {
+		b.WriteString("# Format\n")
+		b.WriteString(a.Format)
+		b.WriteString("\n\n")
+	}
+}
@@ -0,0 +1,88 @@
+// ABOUTME: Tests for per-model prompt adapters: family detection, settings overrides, and prompt output
+// ABOUTME: Covers auto/forced/none selection and the tone/format sections in BuildSystem
+
+package prompt
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/config"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+func TestModelFamily(t *testing.T) {
+	cases := []struct {
+		model *ai.Model
+		want  string
+	}{
+		{&ai.Model{ID: "claude-sonnet-4-20250514", Api: ai.ApiAnthropic}, FamilyClaude},
+		{&ai.Model{ID: "gpt-4o", Api: ai.ApiOpenAI}, FamilyGPT},
+		{&ai.Model{ID: "o3-mini", Api: ai.ApiOpenAI}, FamilyGPT},
+		{&ai.Model{ID: "openai/gpt-4.1", Api: ai.ApiOpenAI}, FamilyGPT},
+		{&ai.Model{ID: "gemini-2.5-pro", Api: ai.ApiVertex}, FamilyGemini},
+		{&ai.Model{ID: "llama3.1:8b", Api: ai.ApiOpenAI}, FamilySmallLocal},
+		{&ai.Model{ID: "Qwen2.5-Coder", Api: ai.ApiOpenAI}, FamilySmallLocal},
+		{&ai.Model{ID: "my-finetune", Api: ai.ApiOpenAI, BaseURL: "http://localhost:8000/v1"}, FamilySmallLocal},
+		{&ai.Model{ID: "house-model", Api: ai.ApiAnthropic}, FamilyClaude},
+		{&ai.Model{ID: "omni", Api: "custom"}, ""},
+		{nil, ""},
+	}
+	for _, tc := range cases {
+		if got := ModelFamily(tc.model); got != tc.want {
+			t.Errorf("ModelFamily(%+v) = %q; want %q", tc.model, got, tc.want)
+		}
+	}
+}
+
+func TestResolveAdapter(t *testing.T) {
+	claude := &ai.Model{ID: "claude-opus-4", Api: ai.ApiAnthropic}
+
+	if a := ResolveAdapter(nil, claude); a != AdapterFor(FamilyClaude) {
+		t.Errorf("nil settings = %+v; want the detected claude adapter", a)
+	}
+
+	forced := ResolveAdapter(&config.PromptsSettings{ModelFamily: FamilySmallLocal}, claude)
+	if forced != AdapterFor(FamilySmallLocal) {
+		t.Errorf("forced family = %+v; want small-local", forced)
+	}
+
+	if a := ResolveAdapter(&config.PromptsSettings{ModelFamily: "none"}, claude); a != (Adapter{}) {
+		t.Errorf("none = %+v; want no adapter", a)
+	}
+
+	ps := &config.PromptsSettings{Adapters: map[string]config.PromptAdapterSettings{
+		FamilyClaude: {Tone: "Be playful."},
+		"mistral":    {Format: "Plain text only."},
+	}}
+	a := ResolveAdapter(ps, claude)
+	if a.Tone != "Be playful." || a.Format != AdapterFor(FamilyClaude).Format {
+		t.Errorf("override = %+v; want custom tone and built-in format", a)
+	}
+
+	ps.ModelFamily = "mistral"
+	a = ResolveAdapter(ps, claude)
+	if a.Family != "mistral" || a.Tone != "" || a.Format != "Plain text only." {
+		t.Errorf("custom family = %+v; want only the configured format", a)
+	}
+}
+
+func TestBuildSystem_Adapter(t *testing.T) {
+	a := Adapter{Family: FamilyGPT, Tone: "Be concise.", Format: "Use bullets."}
+	got := BuildSystem(SystemOpts{CWD: "/tmp", ToolNames: []string{"read"}, Adapter: a})
+
+	tone := strings.Index(got, "# Tone\nBe concise.")
+	format := strings.Index(got, "# Format\nUse bullets.")
+	tools := strings.Index(got, "Available tools: read")
+	if tone < 0 || format < tone || tools > tone {
+		t.Errorf("want tone then format after the tool list, got:\n%s", got)
+	}
+
+	lean := BuildSystem(SystemOpts{CWD: "/tmp", Lean: true, Adapter: a})
+	if strings.Contains(lean, "# Tone") {
+		t.Error("lean prompt should not include adapter sections")
+	}
+	if plain := BuildSystem(SystemOpts{CWD: "/tmp"}); strings.Contains(plain, "# Tone") || strings.Contains(plain, "# Format") {
+		t.Error("zero adapter should write nothing")
+	}
+}
@@ -18,8 +18,12 @@ type Skill struct {
 	Name         string   `yaml:"name"`
 	Description  string   `yaml:"description"`
 	AllowedTools []string `yaml:"allowed-tools"`
+	Triggers     []string `yaml:"triggers"` // keywords that activate the skill from a prompt
+	Globs        []string `yaml:"globs"`    // project file patterns that activate the skill
+	Always       bool     `yaml:"always"`   // always inject into the system prompt
 	Content      string   // Markdown body after frontmatter
 	SourcePath   string   // File path this was loaded from
+	Resources    []string // Extra files bundled next to SKILL.md (relative paths)
 }
 
 // LoadSkills loads skills from all resolution paths, merging by name.
@@ -61,6 +65,7 @@ func loadSkillsFromDir(dir string) ([]Skill, error) {
 			if err != nil {
 				continue
 			}
+			skill.Resources = listSkillResources(filepath.Join(dir, entry.Name()))
 			skills = append(skills, skill)
 		} else if strings.HasSuffix(entry.Name(), ".md") {
 			skillPath := filepath.Join(dir, entry.Name())
@@ -79,6 +84,9 @@ type skillFrontmatter struct {
 	Name         string   `yaml:"name"`
 	Description  string   `yaml:"description"`
 	AllowedTools []string `yaml:"allowed-tools"`
+	Triggers     []string `yaml:"triggers"`
+	Globs        []string `yaml:"globs"`
+	Always       bool     `yaml:"always"`
 }
 
 func parseSkillFile(path string) (Skill, error) {
@@ -98,6 +106,9 @@ func parseSkillFile(path string) (Skill, error) {
 		skill.Name = fm.Name
 		skill.Description = fm.Description
 		skill.AllowedTools = fm.AllowedTools
+		skill.Triggers = fm.Triggers
+		skill.Globs = fm.Globs
+		skill.Always = fm.Always
 		skill.Content = strings.TrimSpace(body)
 	}
 
@@ -115,6 +126,36 @@ func parseSkillFile(path string) (Skill, error) {
 	return skill, nil
 }
 
+// maxSkillResources caps how many bundled files are listed per skill.
+const maxSkillResources = 100
+
+// listSkillResources returns files under a skill directory other than
+// SKILL.md, as slash-separated paths relative to the directory.
+func listSkillResources(skillDir string) []string {
+	var out []string
+	_ = filepath.WalkDir(skillDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if len(out) >= maxSkillResources {
+			return filepath.SkipAll
+		}
+		if d.IsDir() {
+			if path != skillDir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(skillDir, path)
+		if err != nil || rel == "SKILL.md" {
+			return nil
+		}
+		out = append(out, filepath.ToSlash(rel))
+		return nil
+	})
+	return out
+}
+
 // skillNameRe matches valid skill names: lowercase alphanumeric with single hyphens.
 var skillNameRe = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
 
@@ -135,3 +135,36 @@ func TestDetectCollisions(t *testing.T) {
 		t.Fatalf("DetectCollisions returned %d warnings; want 1", len(warnings))
 	}
 }
+
+func TestLoadSkillsFromDir_TriggersAndResources(t *testing.T) {
+	dir := t.TempDir()
+	skillDir := filepath.Join(dir, "terraform")
+	os.MkdirAll(filepath.Join(skillDir, "templates"), 0o755)
+	os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte(`---
+name: terraform
+description: Terraform conventions
+triggers:
+  - terraform
+  - tf plan
+globs:
+  - "**/*.tf"
+---
+Run fmt first.
+`), 0o644)
+	os.WriteFile(filepath.Join(skillDir, "templates", "module.tf"), []byte("module {}"), 0o644)
+
+	skills, err := loadSkillsFromDir(dir)
+	if err != nil {
+		t.Fatalf("loadSkillsFromDir: %v", err)
+	}
+	if len(skills) != 1 {
+		t.Fatalf("got %d skills, want 1", len(skills))
+	}
+	s := skills[0]
+	if len(s.Triggers) != 2 || len(s.Globs) != 1 {
+		t.Errorf("Triggers = %v, Globs = %v", s.Triggers, s.Globs)
+	}
+	if len(s.Resources) != 1 || s.Resources[0] != "templates/module.tf" {
+		t.Errorf("Resources = %v; want [templates/module.tf]", s.Resources)
+	}
+}
@@ -0,0 +1,182 @@
+// ABOUTME: Skill activation by trigger keywords (prompt text) and globs (project files)
+// ABOUTME: SkillActivator injects each newly matched skill once per session
+
+package prompt
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// skipScanDirs are directories never walked when matching skill globs.
+var skipScanDirs = map[string]bool{
+	".git": true, "node_modules": true, "vendor": true, ".pi-go": true, ".pi": true,
+}
+
+// MatchesText reports whether any trigger keyword occurs in text as a whole
+// word (case-insensitive). Multi-word triggers match as phrases.
+func (s Skill) MatchesText(text string) bool {
+	if len(s.Triggers) == 0 || text == "" {
+		return false
+	}
+	lower := strings.ToLower(text)
+	for _, trig := range s.Triggers {
+		trig = strings.ToLower(strings.TrimSpace(trig))
+		if trig != "" && containsWord(lower, trig) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchesFiles reports whether any glob matches one of the slash-separated
+// relative paths. Patterns without a slash match the base name; a leading
+// "**/" matches at any depth.
+func (s Skill) MatchesFiles(files []string) bool {
+	for _, g := range s.Globs {
+		for _, f := range files {
+			if matchGlob(g, f) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// containsWord reports whether word occurs in s bounded by non-word runes.
+func containsWord(s, word string) bool {
+	for i := 0; ; {
+		idx := strings.Index(s[i:], word)
+		if idx < 0 {
+			return false
+		}
+		start := i + idx
+		end := start + len(word)
+		if isBoundary(s, start-1) && isBoundary(s, end) {
+			return true
+		}
+		i = start + 1
+	}
+}
+
+func isBoundary(s string, i int) bool {
+	if i < 0 || i >= len(s) {
+		return true
+	}
+	r := rune(s[i])
+	return !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_')
+}
+
+func matchGlob(pattern, file string) bool {
+	pattern = filepath.ToSlash(pattern)
+	if rest, ok := strings.CutPrefix(pattern, "**/"); ok {
+		pattern = rest
+		if !strings.Contains(rest, "/") {
+			ok, _ := path.Match(rest, path.Base(file))
+			return ok
+		}
+		// Try the pattern against every suffix of the path.
+		parts := strings.Split(file, "/")
+		for i := range parts {
+			if ok, _ := path.Match(pattern, strings.Join(parts[i:], "/")); ok {
+				return true
+			}
+		}
+		return false
+	}
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(file))
+		return ok
+	}
+	ok, _ := path.Match(pattern, file)
+	return ok
+}
+
+// ScanProjectFiles returns up to limit slash-separated file paths under root,
+// skipping VCS, dependency, and agent config directories.
+func ScanProjectFiles(root string, limit int) []string {
+	var files []string
+	_ = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if len(files) >= limit {
+			return filepath.SkipAll
+		}
+		if d.IsDir() {
+			if p != root && skipScanDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if rel, err := filepath.Rel(root, p); err == nil {
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	return files
+}
+
+// SelectSkills returns the skills to preload into the system prompt: those
+// marked always, those whose globs match files, and those triggered by text.
+// The result is sorted by name.
+func SelectSkills(skills []Skill, text string, files []string) []Skill {
+	var out []Skill
+	for _, s := range skills {
+		if s.Always || s.MatchesFiles(files) || s.MatchesText(text) {
+			out = append(out, s)
+		}
+	}
+	slices.SortFunc(out, func(a, b Skill) int { return strings.Compare(a.Name, b.Name) })
+	return out
+}
+
+// SkillRefs converts skills into system prompt references.
+func SkillRefs(skills []Skill) []SkillRef {
+	refs := make([]SkillRef, 0, len(skills))
+	for _, s := range skills {
+		refs = append(refs, SkillRef{Name: s.Name, Content: s.Content})
+	}
+	return refs
+}
+
+// SkillActivator tracks which skills are already in context and returns
+// newly triggered ones for injection alongside the next user prompt.
+type SkillActivator struct {
+	mu     sync.Mutex
+	skills []Skill
+	active map[string]bool
+}
+
+// NewSkillActivator creates an activator; preloaded skills (already in the
+// system prompt) are never injected again.
+func NewSkillActivator(skills, preloaded []Skill) *SkillActivator {
+	active := make(map[string]bool, len(preloaded))
+	for _, s := range preloaded {
+		active[s.Name] = true
+	}
+	return &SkillActivator{skills: skills, active: active}
+}
+
+// Activate returns a context block with every not-yet-active skill whose
+// triggers match text, marking them active. Returns "" when nothing matched.
+func (a *SkillActivator) Activate(text string) string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var b strings.Builder
+	for _, s := range a.skills {
+		if a.active[s.Name] || !s.MatchesText(text) {
+			continue
+		}
+		a.active[s.Name] = true
+		fmt.Fprintf(&b, "[Skill activated: %s]\n%s\n\n", s.Name, s.Content)
+	}
+	return b.String()
+}
@@ -0,0 +1,113 @@
+// ABOUTME: Tests for skill trigger matching, project file scanning, and one-shot activation
+// ABOUTME: Covers keyword word boundaries, glob forms, and SkillActivator dedup
+
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSkill_MatchesText(t *testing.T) {
+	t.Parallel()
+	s := Skill{Triggers: []string{"terraform", "db migration"}}
+
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"please update the Terraform module", true},
+		{"write a DB migration for users", true},
+		{"terraformer is a different tool", false},
+		{"nothing relevant", false},
+	}
+	for _, tt := range tests {
+		if got := s.MatchesText(tt.text); got != tt.want {
+			t.Errorf("MatchesText(%q) = %v; want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestSkill_MatchesFiles(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		glob string
+		file string
+		want bool
+	}{
+		{"*.tf", "infra/main.tf", true},
+		{"**/*.proto", "api/v1/svc.proto", true},
+		{"**/migrations/*.sql", "db/migrations/001.sql", true},
+		{"cmd/*.go", "cmd/main.go", true},
+		{"cmd/*.go", "internal/cmd/main.go", false},
+		{"*.tf", "main.go", false},
+	}
+	for _, tt := range tests {
+		s := Skill{Globs: []string{tt.glob}}
+		if got := s.MatchesFiles([]string{tt.file}); got != tt.want {
+			t.Errorf("glob %q vs %q = %v; want %v", tt.glob, tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestScanProjectFiles_SkipsVendorAndLimit(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "node_modules", "x"), 0o755)
+	os.WriteFile(filepath.Join(root, "node_modules", "x", "a.js"), nil, 0o644)
+	os.WriteFile(filepath.Join(root, "main.tf"), nil, 0o644)
+	os.WriteFile(filepath.Join(root, "b.go"), nil, 0o644)
+
+	files := ScanProjectFiles(root, 10)
+	for _, f := range files {
+		if strings.HasPrefix(f, "node_modules") {
+			t.Errorf("node_modules should be skipped, got %q", f)
+		}
+	}
+	if len(files) != 2 {
+		t.Errorf("files = %v; want 2 entries", files)
+	}
+	if got := ScanProjectFiles(root, 1); len(got) != 1 {
+		t.Errorf("limit not applied: %v", got)
+	}
+}
+
+func TestSelectSkills(t *testing.T) {
+	t.Parallel()
+	skills := []Skill{
+		{Name: "tf", Globs: []string{"*.tf"}},
+		{Name: "style", Always: true},
+		{Name: "sql", Triggers: []string{"sql"}},
+		{Name: "unused", Triggers: []string{"kubernetes"}},
+	}
+	got := SelectSkills(skills, "fix the SQL query", []string{"main.tf"})
+	var names []string
+	for _, s := range got {
+		names = append(names, s.Name)
+	}
+	if strings.Join(names, ",") != "sql,style,tf" {
+		t.Errorf("selected = %v; want [sql style tf]", names)
+	}
+}
+
+func TestSkillActivator_InjectsOnce(t *testing.T) {
+	t.Parallel()
+	skills := []Skill{
+		{Name: "sql", Triggers: []string{"sql"}, Content: "Use parameterized queries."},
+		{Name: "pre", Triggers: []string{"sql"}, Content: "already loaded"},
+	}
+	a := NewSkillActivator(skills, skills[1:])
+
+	first := a.Activate("write some SQL")
+	if !strings.Contains(first, "[Skill activated: sql]") || !strings.Contains(first, "parameterized") {
+		t.Errorf("first activation = %q", first)
+	}
+	if strings.Contains(first, "already loaded") {
+		t.Error("preloaded skill must not be injected again")
+	}
+	if again := a.Activate("more SQL please"); again != "" {
+		t.Errorf("second activation = %q; want empty", again)
+	}
+}
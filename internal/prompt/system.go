@@ -1,5 +1,5 @@
 // ABOUTME: System prompt construction with tools, context files, skills, date/cwd
-// ABOUTME: Assembles the system prompt dynamically based on session state
+// ABOUTME: Assembles the system prompt dynamically based on session state and model family
 
 package prompt
 
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/mauromedda/pi-coding-agent-go/internal/prompts"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
 )
 
 // defaultLoader is a singleton prompts.Loader created once via sync.Once.
@@ -35,14 +36,9 @@ func BuildSystem(opts SystemOpts) string {
 	}
 
 	// Base prompt: versioned loader or hardcoded fallback
+	vars := templateVars(opts)
 	if opts.PromptVersion != "" {
 		loader := getDefaultLoader()
-		vars := map[string]string{
-			"DATE":      time.Now().Format("2006-01-02"),
-			"CWD":       opts.CWD,
-			"TOOL_LIST": strings.Join(opts.ToolNames, ", "),
-			"MODE":      modeForVersion(opts),
-		}
 		if composed, err := loader.Compose(opts.PromptVersion, vars); err == nil {
 			b.WriteString(composed)
 			b.WriteString("\n\n")
@@ -68,6 +64,14 @@ func BuildSystem(opts SystemOpts) string {
 		b.WriteString("\n\n")
 	}
 
+	// Model-family tool guidance
+	if len(opts.ToolNames) > 0 {
+		writeToolUsage(&b, vars)
+	}
+
+	// Model-family tone and format
+	writeAdapter(&b, opts.Adapter)
+
 	// Skills
 	for _, skill := range opts.Skills {
 		b.WriteString(fmt.Sprintf("# Skill: %s\n%s\n\n", skill.Name, skill.Content))
@@ -80,6 +84,13 @@ func BuildSystem(opts SystemOpts) string {
 		b.WriteString("\n\n")
 	}
 
+	// Output style layer (formatting persona on top of the personality)
+	if opts.OutputStylePrompt != "" {
+		b.WriteString("# Output Style\n")
+		b.WriteString(opts.OutputStylePrompt)
+		b.WriteString("\n\n")
+	}
+
 	// Memory entries
 	if opts.MemorySection != "" {
 		b.WriteString(opts.MemorySection)
@@ -105,6 +116,38 @@ func writeHardcodedHeader(b *strings.Builder, cwd string) {
 	b.WriteString(fmt.Sprintf("Working directory: %s\n\n", cwd))
 }
 
+// templateVars returns the variables prompt templates are rendered with.
+// FAMILY, the adapter's model family, also selects the families/<FAMILY>/
+// variants of versioned fragments.
+func templateVars(opts SystemOpts) map[string]string {
+	vars := map[string]string{
+		"DATE":      time.Now().Format("2006-01-02"),
+		"CWD":       opts.CWD,
+		"TOOL_LIST": strings.Join(opts.ToolNames, ", "),
+		"MODE":      modeForVersion(opts),
+		"FAMILY":    opts.Adapter.Family,
+	}
+	if opts.Model != nil {
+		vars["API"] = string(opts.Model.Api)
+		vars["MODEL"] = opts.Model.ID
+	}
+	return vars
+}
+
+// writeToolUsage writes the model family's tool-usage template
+// (families/<family>/tool-usage.md). Families without one get no section.
+func writeToolUsage(b *strings.Builder, vars map[string]string) {
+	text, err := getDefaultLoader().FamilyTemplate(vars["FAMILY"], "tool-usage.md", vars)
+	if err != nil {
+		return
+	}
+	if text = strings.TrimSpace(text); text != "" {
+		b.WriteString("# Tool Usage\n")
+		b.WriteString(text)
+		b.WriteString("\n\n")
+	}
+}
+
 // modeForVersion maps SystemOpts to a mode string for prompt variable substitution.
 func modeForVersion(opts SystemOpts) string {
 	if opts.PlanMode {
@@ -130,6 +173,16 @@ type SystemOpts struct {
 
 	// PersonalityPrompt is an injected personality prompt fragment.
 	PersonalityPrompt string
+
+	// OutputStylePrompt is the active output style section from the personality engine.
+	OutputStylePrompt string
+
+	// Adapter adds tone and format sections tuned to the model family. Its
+	// family also picks the tool-usage template and fragment variants.
+	Adapter Adapter
+
+	// Model fills the API and MODEL template variables. Nilable.
+	Model *ai.Model
 }
 
 // SkillRef is a reference to a loaded skill.
@@ -7,6 +7,8 @@ import (
 	"os"
 	"strings"
 	"testing"
+
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
 )
 
 func TestStyleInstructions_Concise(t *testing.T) {
@@ -120,6 +122,31 @@ func TestBuildSystem_PersonalityPrompt_Empty(t *testing.T) {
 	}
 }
 
+func TestBuildSystem_OutputStyleAfterPersonality(t *testing.T) {
+	opts := SystemOpts{
+		CWD:               "/tmp/test",
+		PersonalityPrompt: "Be thorough.",
+		OutputStylePrompt: "## Output Style: terse\n- Answer briefly.",
+	}
+	result := BuildSystem(opts)
+
+	persIdx := strings.Index(result, "# Personality")
+	styleIdx := strings.Index(result, "# Output Style\n")
+	if styleIdx < 0 {
+		t.Fatal("expected output style section header")
+	}
+	if persIdx >= styleIdx {
+		t.Errorf("output style (%d) should follow personality (%d)", styleIdx, persIdx)
+	}
+	if !strings.Contains(result, "- Answer briefly.") {
+		t.Error("expected output style instructions in output")
+	}
+
+	if strings.Contains(BuildSystem(SystemOpts{CWD: "/tmp/test"}), "# Output Style") {
+		t.Error("empty output style prompt should not produce a section")
+	}
+}
+
 func TestBuildSystem_PersonalityAfterSkills(t *testing.T) {
 	opts := SystemOpts{
 		CWD:               "/tmp/test",
@@ -171,6 +198,47 @@ func TestBuildSystem_NoPromptVersion(t *testing.T) {
 	}
 }
 
+func TestBuildSystem_FamilyToolUsage(t *testing.T) {
+	base := SystemOpts{CWD: "/tmp/test", ToolNames: []string{"read", "edit"}}
+
+	gemini := base
+	gemini.Adapter = AdapterFor(FamilyGemini)
+	gemini.Model = &ai.Model{ID: "gemini-2.5-pro", Api: ai.ApiGoogle}
+	claude := base
+	claude.Adapter = AdapterFor(FamilyClaude)
+
+	g, c := BuildSystem(gemini), BuildSystem(claude)
+	if !strings.Contains(g, "# Tool Usage\n") || !strings.Contains(c, "# Tool Usage\n") {
+		t.Fatal("expected a tool usage section for a known family")
+	}
+	if !strings.Contains(g, "exact parameter names") || strings.Contains(c, "exact parameter names") {
+		t.Error("expected the Gemini guidance for Gemini only")
+	}
+	if strings.Contains(BuildSystem(base), "# Tool Usage") {
+		t.Error("expected no tool usage section without a model family")
+	}
+
+	plan := claude
+	plan.PlanMode = true
+	if !strings.Contains(BuildSystem(plan), "do not call tools that change files") {
+		t.Error("expected the template to render the plan-mode line")
+	}
+}
+
+func TestTemplateVars(t *testing.T) {
+	vars := templateVars(SystemOpts{
+		CWD:     "/w",
+		Adapter: Adapter{Family: FamilyGPT},
+		Model:   &ai.Model{ID: "gpt-4o", Api: ai.ApiOpenAI},
+	})
+	want := map[string]string{"CWD": "/w", "FAMILY": "gpt", "API": "openai", "MODEL": "gpt-4o", "MODE": "execute"}
+	for k, v := range want {
+		if vars[k] != v {
+			t.Errorf("vars[%s] = %q; want %q", k, vars[k], v)
+		}
+	}
+}
+
 func TestBuildSystem_Lean(t *testing.T) {
 	opts := SystemOpts{
 		CWD:               "/tmp/test",
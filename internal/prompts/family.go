@@ -0,0 +1,66 @@
+// ABOUTME: Per-model-family prompt variants: families/<family>/ fragments that replace the generic ones
+// ABOUTME: Versioned fragments fall back to the shared file; version-independent templates back the default prompt
+
+package prompts
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// familyDir holds the per-family variants, in a version directory or at the
+// prompts root.
+const familyDir = "families"
+
+// LoadFamilyFragment loads the family's variant of a fragment, at
+// families/<family>/<path> in the version, and falls back to the fragment
+// itself when the family has none. An empty family loads the fragment.
+func (l *Loader) LoadFamilyFragment(version, family, fragment string) ([]byte, error) {
+	if family != "" {
+		if data, err := l.LoadFragment(version, path.Join(familyDir, family, fragment)); err == nil {
+			return data, nil
+		}
+	}
+	return l.LoadFragment(version, fragment)
+}
+
+// FamilyTemplate renders the version-independent template name of a model
+// family: families/<family>/<name> in the overrides dir, the disk prompts
+// dir, or the embedded templates, in that order. The error wraps
+// fs.ErrNotExist when no family template of that name exists.
+func (l *Loader) FamilyTemplate(family, name string, vars map[string]string) (string, error) {
+	if family == "" {
+		return "", fmt.Errorf("family template %s: no model family: %w", name, fs.ErrNotExist)
+	}
+	rel := filepath.Join(familyDir, family, name)
+	data, err := l.readFamilyFile(rel)
+	if err != nil {
+		return "", err
+	}
+	out, err := RenderVariables(string(data), vars)
+	if err != nil {
+		return "", fmt.Errorf("render %s: %w", rel, err)
+	}
+	return out, nil
+}
+
+// readFamilyFile reads rel from the overrides dir, the disk prompts dir,
+// then the embedded templates.
+func (l *Loader) readFamilyFile(rel string) ([]byte, error) {
+	for _, dir := range []string{l.overrides, l.diskDir} {
+		if dir == "" {
+			continue
+		}
+		if data, err := os.ReadFile(filepath.Join(dir, rel)); err == nil {
+			return data, nil
+		}
+	}
+	data, err := fs.ReadFile(l.embedded, filepath.ToSlash(rel))
+	if err != nil {
+		return nil, fmt.Errorf("family template %s: not found in overrides, disk, or embedded: %w", rel, err)
+	}
+	return data, nil
+}
@@ -0,0 +1,87 @@
+// ABOUTME: Tests for per-model-family prompt variants and version-independent family templates
+// ABOUTME: Validates variant precedence in Compose and the overrides/disk/embedded lookup order
+
+package prompts
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoader_Compose_FamilyVariant(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "v1.0.0", "families", "gemini", "modes", "execute.md"),
+		"GEMINI EXECUTE for {{.MODEL}}")
+	l := NewLoader(dir, "/nonexistent/overrides")
+
+	got, err := l.Compose("v1.0.0", map[string]string{"MODE": "execute", "FAMILY": "gemini", "MODEL": "gemini-2.5-pro"})
+	if err != nil {
+		t.Fatalf("Compose() error = %v", err)
+	}
+	if !strings.Contains(got, "GEMINI EXECUTE for gemini-2.5-pro") {
+		t.Errorf("expected the gemini variant of modes/execute.md; got %q", got)
+	}
+	if !strings.Contains(got, "pi-go") {
+		t.Errorf("expected the shared system.md without a variant; got %q", got)
+	}
+
+	got, err = l.Compose("v1.0.0", map[string]string{"MODE": "execute", "FAMILY": "claude"})
+	if err != nil {
+		t.Fatalf("Compose() error = %v", err)
+	}
+	if strings.Contains(got, "GEMINI EXECUTE") || !strings.Contains(got, "EXECUTE mode") {
+		t.Errorf("expected the shared fragment for a family without a variant; got %q", got)
+	}
+}
+
+func TestLoader_FamilyTemplate(t *testing.T) {
+	t.Parallel()
+
+	l := NewLoader("/nonexistent/prompts", "/nonexistent/overrides")
+	got, err := l.FamilyTemplate("gpt", "tool-usage.md", map[string]string{"CWD": "/work", "MODE": "plan"})
+	if err != nil {
+		t.Fatalf("FamilyTemplate() error = %v", err)
+	}
+	if !strings.Contains(got, "relative to /work") || !strings.Contains(got, "do not call tools that change files") {
+		t.Errorf("expected the rendered embedded gpt template; got %q", got)
+	}
+
+	if _, err := l.FamilyTemplate("unknown", "tool-usage.md", nil); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("FamilyTemplate(unknown) error = %v; want fs.ErrNotExist", err)
+	}
+	if _, err := l.FamilyTemplate("", "tool-usage.md", nil); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("FamilyTemplate(\"\") error = %v; want fs.ErrNotExist", err)
+	}
+}
+
+func TestLoader_FamilyTemplate_Precedence(t *testing.T) {
+	t.Parallel()
+
+	disk, over := t.TempDir(), t.TempDir()
+	writeFile(t, filepath.Join(disk, "families", "claude", "tool-usage.md"), "DISK {{.FAMILY}}")
+	l := NewLoader(disk, over)
+	if got, _ := l.FamilyTemplate("claude", "tool-usage.md", map[string]string{"FAMILY": "claude"}); got != "DISK claude" {
+		t.Errorf("FamilyTemplate() = %q; want the disk template", got)
+	}
+
+	writeFile(t, filepath.Join(over, "families", "claude", "tool-usage.md"), "OVERRIDE")
+	if got, _ := l.FamilyTemplate("claude", "tool-usage.md", nil); got != "OVERRIDE" {
+		t.Errorf("FamilyTemplate() = %q; want the overrides template", got)
+	}
+}
@@ -88,7 +88,8 @@ func (l *Loader) LoadFragment(version, path string) ([]byte, error) {
 
 // Compose assembles the full system prompt from a manifest and variables.
 // It loads the manifest, resolves composition_order paths (substituting variables),
-// loads each fragment, renders template variables, and concatenates.
+// loads each fragment, renders template variables, and concatenates. With a
+// FAMILY variable, a fragment's families/<FAMILY>/ variant replaces it.
 // If a Cache is set, results are cached by version+vars to skip repeated file I/O.
 func (l *Loader) Compose(version string, vars map[string]string) (string, error) {
 	// Check cache first.
@@ -123,7 +124,7 @@ func (l *Loader) Compose(version string, vars map[string]string) (string, error)
 			resolvedPath = strings.ReplaceAll(resolvedPath, "{{"+k+"}}", v)
 		}
 
-		fragment, err := l.LoadFragment(version, resolvedPath)
+		fragment, err := l.LoadFamilyFragment(version, merged["FAMILY"], resolvedPath)
 		if err != nil {
 			return "", fmt.Errorf("compose fragment %q: %w", resolvedPath, err)
 		}
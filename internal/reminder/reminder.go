@@ -0,0 +1,296 @@
+// ABOUTME: System reminder injector: collects transient notes for the model before each LLM call
+// ABOUTME: Built-in sources for plan mode, a nearly exhausted budget, and files changed on disk with their lines
+
+package reminder
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/session"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+// Reminder is one note for the model. Key identifies the condition; a
+// reminder is sent again only when its text changes or the condition
+// clears and returns.
+type Reminder struct {
+	Key  string
+	Text string
+}
+
+// Source reports the reminders that currently apply.
+type Source func() []Reminder
+
+// Injector turns the reminders of its sources into ephemeral messages.
+// It is safe for concurrent use.
+type Injector struct {
+	mu      sync.Mutex
+	sources []Source
+	sent    map[string]string // key -> text last sent
+	files   *FileTracker
+}
+
+// New returns an Injector over sources.
+func New(sources ...Source) *Injector {
+	return &Injector{sources: sources, sent: make(map[string]string)}
+}
+
+// Add registers another source.
+func (in *Injector) Add(src Source) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.sources = append(in.sources, src)
+}
+
+// Files returns the tracker started by TrackFiles, or nil.
+func (in *Injector) Files() *FileTracker {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return in.files
+}
+
+// TrackFiles reports files the agent read or wrote that later change on
+// disk, and returns the tracker fed by ToolDone.
+func (in *Injector) TrackFiles() *FileTracker {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if in.files == nil {
+		in.files = NewFileTracker()
+		in.sources = append(in.sources, in.files.Source())
+	}
+	return in.files
+}
+
+// ToolDone records a finished tool call for the file tracker, if any.
+func (in *Injector) ToolDone(name string, args map[string]any) {
+	in.mu.Lock()
+	files := in.files
+	in.mu.Unlock()
+	if files == nil {
+		return
+	}
+	if path, ok := args["path"].(string); ok && fileTools[name] {
+		files.Record(path)
+	}
+}
+
+// fileTools are the tools whose "path" argument the file tracker records.
+var fileTools = map[string]bool{"read": true, "edit": true, "write": true}
+
+// Collect returns one ephemeral message with the reminders that are new
+// or changed since the last call, or false when there are none.
+func (in *Injector) Collect() (ai.Message, bool) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	active := make(map[string]bool)
+	var texts []string
+	for _, src := range in.sources {
+		for _, r := range src() {
+			active[r.Key] = true
+			if prev, ok := in.sent[r.Key]; ok && prev == r.Text {
+				continue
+			}
+			in.sent[r.Key] = r.Text
+			texts = append(texts, r.Text)
+		}
+	}
+	for key := range in.sent {
+		if !active[key] {
+			delete(in.sent, key) // cleared; report again if it returns
+		}
+	}
+	if len(texts) == 0 {
+		return ai.Message{}, false
+	}
+	return session.NewReminder(texts...), true
+}
+
+// PlanMode reminds the model that only read-only tools may run while
+// active reports true.
+func PlanMode(active func() bool) Source {
+	return func() []Reminder {
+		if !active() {
+			return nil
+		}
+		return []Reminder{{
+			Key:  "plan-mode",
+			Text: "Plan mode is active. Only read-only tools are available: explore and write a plan, but do not modify files or run commands that change state.",
+		}}
+	}
+}
+
+// budgetStep is the granularity of budget reminders, in percent, so the
+// reminder repeats as spending grows rather than on every cent.
+const budgetStep = 10
+
+// Budget warns when spent reports at least threshold (0-1) of a positive
+// budget in USD.
+func Budget(spent func() (costUSD, budgetUSD float64), threshold float64) Source {
+	return func() []Reminder {
+		cost, budget := spent()
+		if budget <= 0 || cost < budget*threshold {
+			return nil
+		}
+		if cost >= budget {
+			return []Reminder{{
+				Key:  "budget",
+				Text: fmt.Sprintf("The session budget of $%.2f is exhausted ($%.2f spent). Stop starting new work: summarize the state and what remains.", budget, cost),
+			}}
+		}
+		pct := int(cost/budget*100) / budgetStep * budgetStep
+		return []Reminder{{
+			Key:  "budget",
+			Text: fmt.Sprintf("Over %d%% of the $%.2f session budget is spent. Prefer finishing the current step over new exploration, and avoid unnecessary tool calls.", pct, budget),
+		}}
+	}
+}
+
+// maxSnapshotBytes caps the content FileTracker keeps per file to name the
+// changed lines; larger files are reported without them.
+const maxSnapshotBytes = 256 * 1024
+
+// FileTracker remembers the modification time and content of files the
+// agent has seen, so a change made outside the agent can be reported with
+// the lines it touched.
+type FileTracker struct {
+	mu       sync.Mutex
+	seen     map[string]fileState
+	stat     func(string) (os.FileInfo, error)
+	onChange []func(path string)
+}
+
+// fileState is a file as the agent last saw it.
+type fileState struct {
+	mtime   time.Time
+	content []byte // nil when too large to keep
+}
+
+// NewFileTracker returns an empty tracker.
+func NewFileTracker() *FileTracker {
+	return &FileTracker{seen: make(map[string]fileState), stat: os.Stat}
+}
+
+// OnChange registers fn to run for each changed file whenever the tracker
+// reports it, such as to drop cached reads of it.
+func (t *FileTracker) OnChange(fn func(path string)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onChange = append(t.onChange, fn)
+}
+
+// Record stores the current modification time and content of path. Called
+// after the agent reads or writes it, so its own edits are not reported.
+func (t *FileTracker) Record(path string) {
+	info, err := t.stat(path)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err != nil {
+		delete(t.seen, path)
+		return
+	}
+	t.seen[path] = fileState{mtime: info.ModTime(), content: snapshot(path, info)}
+}
+
+// snapshot returns the content of a small regular file, or nil.
+func snapshot(path string, info os.FileInfo) []byte {
+	if !info.Mode().IsRegular() || info.Size() > maxSnapshotBytes {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || len(data) > maxSnapshotBytes {
+		return nil
+	}
+	return data
+}
+
+// Changed returns the tracked files modified or removed since they were
+// recorded, sorted.
+func (t *FileTracker) Changed() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var changed []string
+	for path, seen := range t.seen {
+		info, err := t.stat(path)
+		if err != nil || !info.ModTime().Equal(seen.mtime) {
+			changed = append(changed, path)
+		}
+	}
+	slices.Sort(changed)
+	return changed
+}
+
+// describe says how path differs from what the agent last saw: removed,
+// the changed line range, or nothing more when it cannot tell.
+func (t *FileTracker) describe(path string) string {
+	t.mu.Lock()
+	before := t.seen[path].content
+	t.mu.Unlock()
+	info, err := t.stat(path)
+	if err != nil {
+		return "was removed since you last read or wrote it."
+	}
+	const changed = "changed on disk since you last read or wrote it"
+	after := snapshot(path, info)
+	if before == nil || after == nil {
+		return changed + "."
+	}
+	return changed + changedLines(string(before), string(after)) + "."
+}
+
+// changedLines names the range of after's lines that differ from before,
+// found by trimming the lines both share at the start and the end.
+func changedLines(before, after string) string {
+	old, cur := strings.Split(before, "\n"), strings.Split(after, "\n")
+	prefix := 0
+	for prefix < len(old) && prefix < len(cur) && old[prefix] == cur[prefix] {
+		prefix++
+	}
+	if prefix == len(old) && prefix == len(cur) {
+		return " (content unchanged)"
+	}
+	suffix := 0
+	for suffix < len(old)-prefix && suffix < len(cur)-prefix && old[len(old)-1-suffix] == cur[len(cur)-1-suffix] {
+		suffix++
+	}
+	first, last := prefix+1, len(cur)-suffix
+	if first > last {
+		// Only deletions: name the removed lines by their old numbers.
+		return ": " + lineRange(first, len(old)-suffix) + " deleted"
+	}
+	return ": " + lineRange(first, last)
+}
+
+// lineRange formats 1-based lines first..last.
+func lineRange(first, last int) string {
+	if first == last {
+		return fmt.Sprintf("line %d", first)
+	}
+	return fmt.Sprintf("lines %d-%d", first, last)
+}
+
+// Source reports each changed file, with the lines that changed, until the
+// agent records it again. OnChange callbacks run for each one.
+func (t *FileTracker) Source() Source {
+	return func() []Reminder {
+		var out []Reminder
+		for _, path := range t.Changed() {
+			t.mu.Lock()
+			callbacks := slices.Clone(t.onChange)
+			t.mu.Unlock()
+			for _, fn := range callbacks {
+				fn(path)
+			}
+			out = append(out, Reminder{
+				Key:  "file:" + path,
+				Text: fmt.Sprintf("%s %s Read it again before editing it.", path, t.describe(path)),
+			})
+		}
+		return out
+	}
+}
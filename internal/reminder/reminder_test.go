@@ -0,0 +1,161 @@
+// ABOUTME: Tests for the system reminder injector and its plan mode, budget, and file sources
+// ABOUTME: Checks dedupe by key and text, re-sending after a condition clears, and file tracking with changed lines
+
+package reminder
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func reminderText(t *testing.T, in *Injector) string {
+	t.Helper()
+	msg, ok := in.Collect()
+	if !ok {
+		return ""
+	}
+	if !msg.Ephemeral {
+		t.Error("reminder message must be ephemeral")
+	}
+	return msg.Content[0].Text
+}
+
+func TestInjector_DedupesUntilCleared(t *testing.T) {
+	plan := true
+	in := New(PlanMode(func() bool { return plan }))
+
+	if got := reminderText(t, in); !strings.Contains(got, "<system-reminder>\nPlan mode is active.") {
+		t.Errorf("first collect = %q; want the plan mode reminder", got)
+	}
+	if got := reminderText(t, in); got != "" {
+		t.Errorf("unchanged reminder repeated: %q", got)
+	}
+
+	plan = false
+	if got := reminderText(t, in); got != "" {
+		t.Errorf("cleared condition sent %q", got)
+	}
+	plan = true
+	if got := reminderText(t, in); !strings.Contains(got, "Plan mode") {
+		t.Error("reminder should return after the condition clears and comes back")
+	}
+}
+
+func TestBudget(t *testing.T) {
+	cost := 5.0
+	src := Budget(func() (float64, float64) { return cost, 10 }, 0.8)
+	if got := src(); got != nil {
+		t.Errorf("under threshold = %+v; want nothing", got)
+	}
+
+	cost = 8.5
+	if got := src(); len(got) != 1 || !strings.Contains(got[0].Text, "Over 80% of the $10.00") {
+		t.Errorf("over threshold = %+v", got)
+	}
+	cost = 8.9
+	first := src()[0].Text
+	if src()[0].Text != first {
+		t.Error("text should only change per 10% step")
+	}
+
+	cost = 10.2
+	if got := src(); !strings.Contains(got[0].Text, "is exhausted ($10.20 spent)") {
+		t.Errorf("exhausted = %+v", got)
+	}
+	if got := Budget(func() (float64, float64) { return 99, 0 }, 0.8)(); got != nil {
+		t.Error("no budget means no reminder")
+	}
+}
+
+func TestInjector_FileChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(path, []byte("package main"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	in := New()
+	in.TrackFiles()
+
+	in.ToolDone("bash", map[string]any{"path": path}) // not a file tool
+	if got := reminderText(t, in); got != "" {
+		t.Errorf("untracked file reported: %q", got)
+	}
+
+	in.ToolDone("read", map[string]any{"path": path})
+	if got := reminderText(t, in); got != "" {
+		t.Errorf("unchanged file reported: %q", got)
+	}
+
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if got := reminderText(t, in); !strings.Contains(got, path+" changed on disk") {
+		t.Errorf("changed file = %q; want a reminder", got)
+	}
+
+	// Reading it again clears the reminder.
+	in.ToolDone("read", map[string]any{"path": path})
+	if changed := in.TrackFiles().Changed(); len(changed) != 0 {
+		t.Errorf("Changed() = %v after re-read", changed)
+	}
+	if got := reminderText(t, in); got != "" {
+		t.Errorf("re-read file still reported: %q", got)
+	}
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if got := reminderText(t, in); !strings.Contains(got, path) {
+		t.Error("a removed file should be reported")
+	}
+}
+
+func TestFileTracker_ChangedLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.go")
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a\nb\nc\nd\ne\n")
+	in := New()
+	files := in.TrackFiles()
+	var notified []string
+	files.OnChange(func(p string) { notified = append(notified, p) })
+	in.ToolDone("edit", map[string]any{"path": path})
+
+	write("a\nB\nC\nd\ne\n")
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if got := reminderText(t, in); !strings.Contains(got, path+" changed on disk since you last read or wrote it: lines 2-3.") {
+		t.Errorf("reminder = %q; want the changed lines", got)
+	}
+	if len(notified) != 1 || notified[0] != path {
+		t.Errorf("OnChange saw %v", notified)
+	}
+	if in.Files() != files {
+		t.Error("Files should return the tracker")
+	}
+}
+
+func TestChangedLines(t *testing.T) {
+	tests := []struct {
+		before, after, want string
+	}{
+		{"a\nb\nc", "a\nx\nc", ": line 2"},
+		{"a\nb\nc", "a\nb\nc\nd\ne", ": lines 4-5"},
+		{"a\nb\nc\nd", "a\nd", ": lines 2-3 deleted"},
+		{"a\nb", "x\ny\nz", ": lines 1-3"},
+		{"a\nb", "a\nb", " (content unchanged)"},
+	}
+	for _, tt := range tests {
+		if got := changedLines(tt.before, tt.after); got != tt.want {
+			t.Errorf("changedLines(%q, %q) = %q; want %q", tt.before, tt.after, got, tt.want)
+		}
+	}
+}
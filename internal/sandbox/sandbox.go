@@ -10,6 +10,8 @@ import (
 	"runtime"
 	"slices"
 	"strings"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/permission"
 )
 
 // Sandbox wraps commands with OS-level isolation.
@@ -70,13 +72,15 @@ func validateWritePath(path string, opts Opts) error {
 	allowed := []string{opts.WorkDir}
 	allowed = append(allowed, opts.AdditionalDirs...)
 
+	abs = permission.ComparablePath(abs)
 	for _, dir := range allowed {
 		dirAbs, _ := filepath.Abs(dir)
 		if dirAbs == "" {
 			continue
 		}
+		dirAbs = permission.ComparablePath(dirAbs)
 		// Add separator boundary to prevent /tmpevil matching /tmp
-		dirWithSep := dirAbs + string(filepath.Separator)
+		dirWithSep := strings.TrimSuffix(dirAbs, string(filepath.Separator)) + string(filepath.Separator)
 		if strings.HasPrefix(abs+string(filepath.Separator), dirWithSep) || abs == dirAbs {
 			return nil
 		}
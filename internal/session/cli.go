@@ -0,0 +1,143 @@
+// ABOUTME: CLI dispatch for session subcommands: pi-go sessions list, import <file>, search <query>
+// ABOUTME: Parses subcommand flags and prints one line per session or action, or matches with context
+
+package session
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// RunCLI dispatches session subcommands.
+// args contains the subcommand followed by its arguments.
+func RunCLI(args []string, sessionsDir string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: sessions <list|import|search> [flags] [args...]")
+	}
+
+	subcmd := args[0]
+	rest := args[1:]
+
+	switch subcmd {
+	case "list":
+		return runList(rest, sessionsDir, os.Stdout)
+	case "import":
+		return runImport(rest, sessionsDir)
+	case "search":
+		return runSearch(rest, sessionsDir, os.Stdout)
+	default:
+		return fmt.Errorf("unknown subcommand %q: expected list, import, or search", subcmd)
+	}
+}
+
+func runList(args []string, sessionsDir string, w io.Writer) error {
+	fs := flag.NewFlagSet("sessions list", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	limitFlag := fs.Int("limit", 20, "Maximum number of sessions, newest first (0 = all)")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("sessions list: %w", err)
+	}
+
+	sessions, err := ListSessionsInDir(sessionsDir)
+	if err != nil {
+		return err
+	}
+	if len(sessions) == 0 {
+		fmt.Fprintln(w, "no sessions")
+		return nil
+	}
+	slices.SortFunc(sessions, func(a, b SessionStartData) int { return b.StartedAt.Compare(a.StartedAt) })
+	shown := sessions
+	if *limitFlag > 0 && len(shown) > *limitFlag {
+		shown = shown[:*limitFlag]
+	}
+	for _, s := range shown {
+		when := "                "
+		if !s.StartedAt.IsZero() {
+			when = s.StartedAt.Local().Format("2006-01-02 15:04")
+		}
+		title := s.Title
+		if title == "" {
+			title = "(untitled)"
+		}
+		fmt.Fprintf(w, "%s  %s  %s  (%s, %s)\n", s.ID, when, title, s.Model, s.CWD)
+	}
+	if len(shown) < len(sessions) {
+		fmt.Fprintf(w, "\n(%d of %d sessions; use --limit to see more)\n", len(shown), len(sessions))
+	}
+	return nil
+}
+
+func runImport(args []string, sessionsDir string) error {
+	fs := flag.NewFlagSet("sessions import", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	formatFlag := fs.String("format", "auto", "Transcript format: auto, claude, aider")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("sessions import: %w", err)
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("usage: sessions import [--format claude|aider] <file>...")
+	}
+
+	format, err := ParseImportFormat(*formatFlag)
+	if err != nil {
+		return err
+	}
+
+	for _, path := range fs.Args() {
+		res, err := Import(path, format, sessionsDir)
+		if err != nil {
+			return fmt.Errorf("importing %s: %w", path, err)
+		}
+		fmt.Printf("imported %d messages from %s (%s) as session %s\n", res.Messages, path, res.Format, res.SessionID)
+	}
+	return nil
+}
+
+func runSearch(args []string, sessionsDir string, w io.Writer) error {
+	fs := flag.NewFlagSet("sessions search", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	contextFlag := fs.Int("C", 1, "Lines of context around each match")
+	limitFlag := fs.Int("limit", 50, "Maximum number of matches (0 = all)")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("sessions search: %w", err)
+	}
+	query := strings.Join(fs.Args(), " ")
+	if strings.TrimSpace(query) == "" {
+		return fmt.Errorf("usage: sessions search [-C lines] [--limit n] <query>")
+	}
+
+	matches, err := Search(sessionsDir, query, SearchOptions{Context: max(*contextFlag, 0), Limit: *limitFlag})
+	if err != nil {
+		return err
+	}
+	if len(matches) == 0 {
+		fmt.Fprintf(w, "no matches for %q\n", query)
+		return nil
+	}
+	for i, m := range matches {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		when := ""
+		if !m.TS.IsZero() {
+			when = m.TS.Local().Format("2006-01-02 15:04") + "  "
+		}
+		fmt.Fprintf(w, "%s  %s%s (line %d)\n", m.SessionID, when, m.Type, m.Line)
+		for _, l := range m.Before {
+			fmt.Fprintf(w, "    %s\n", l)
+		}
+		fmt.Fprintf(w, "  > %s\n", m.Text)
+		for _, l := range m.After {
+			fmt.Fprintf(w, "    %s\n", l)
+		}
+	}
+	if *limitFlag > 0 && len(matches) == *limitFlag {
+		fmt.Fprintf(w, "\n(stopped at %d matches; use --limit to see more)\n", *limitFlag)
+	}
+	return nil
+}
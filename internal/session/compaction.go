@@ -20,11 +20,13 @@ type CompactionConfig struct {
 
 // CompactResult holds the output of a compaction operation.
 type CompactResult struct {
-	Messages       []ai.Message    // compacted message list (summary + ack + kept)
-	Summary        string          // the generated summary text
-	FileOps        CompactionEntry // cumulative file tracking
-	TokensBefore   int             // token count before compaction
-	FirstKeptIndex int             // index into original messages where kept portion starts
+	Messages         []ai.Message    // compacted message list (summary + ack + kept)
+	Summary          string          // the generated summary text
+	FileOps          CompactionEntry // cumulative file tracking
+	TokensBefore     int             // token count before compaction
+	FirstKeptIndex   int             // index into original messages where kept portion starts
+	PinnedKept       int             // pinned messages from the compacted span kept verbatim
+	RemindersDropped int             // ephemeral system reminders removed before summarizing
 }
 
 // SummarizerFunc is an injectable function that produces a summary from messages.
@@ -171,24 +173,75 @@ func hasToolResult(msg ai.Message) bool {
 	return false
 }
 
+// splitPinned separates pinned messages from the rest, keeping each pinned
+// tool_use or tool_result together with its partner so the pair stays valid.
+func splitPinned(messages []ai.Message) (pinned, rest []ai.Message) {
+	keep := make([]bool, len(messages))
+	for i, msg := range messages {
+		if !msg.Pinned {
+			continue
+		}
+		keep[i] = true
+		if hasToolUse(msg) && i+1 < len(messages) && hasToolResult(messages[i+1]) {
+			keep[i+1] = true
+		}
+		if hasToolResult(msg) && i > 0 && hasToolUse(messages[i-1]) {
+			keep[i-1] = true
+		}
+	}
+	for i, msg := range messages {
+		if keep[i] {
+			pinned = append(pinned, msg)
+		} else {
+			rest = append(rest, msg)
+		}
+	}
+	return pinned, rest
+}
+
+// PinnedStats returns the number of pinned messages and their estimated tokens.
+func PinnedStats(messages []ai.Message) (count, tokens int) {
+	for _, msg := range messages {
+		if msg.Pinned {
+			count++
+			tokens += EstimateMessageTokens(msg)
+		}
+	}
+	return count, tokens
+}
+
 // CompactWithLLM performs compaction using an injected summarizer function.
 // It finds a cut point, summarizes the older messages, and returns a new
 // message list with summary + acknowledgment + kept recent messages.
+// Pinned messages before the cut are not summarized; they follow the
+// acknowledgment verbatim, in their original order. System reminders are
+// dropped first and never summarized; FirstKeptIndex counts without them.
 func CompactWithLLM(ctx context.Context, messages []ai.Message, cfg CompactionConfig, summarize SummarizerFunc) (*CompactResult, error) {
 	tokensBefore := EstimateMessagesTokens(messages)
+	messages, dropped := DropEphemeral(messages)
 	cutIdx := FindCutPoint(messages, cfg.KeepRecentTokens)
 
 	if cutIdx == 0 {
 		// Nothing to compact
 		return &CompactResult{
-			Messages:       messages,
-			TokensBefore:   tokensBefore,
-			FirstKeptIndex: 0,
+			Messages:         messages,
+			TokensBefore:     tokensBefore,
+			FirstKeptIndex:   0,
+			RemindersDropped: dropped,
 		}, nil
 	}
 
-	oldMessages := messages[:cutIdx]
+	pinned, oldMessages := splitPinned(messages[:cutIdx])
 	recentMessages := messages[cutIdx:]
+	if len(oldMessages) == 0 {
+		// Everything before the cut is pinned
+		return &CompactResult{
+			Messages:         messages,
+			TokensBefore:     tokensBefore,
+			FirstKeptIndex:   0,
+			RemindersDropped: dropped,
+		}, nil
+	}
 
 	// Extract file ops from the compacted span
 	fileOps := ExtractFileOps(oldMessages)
@@ -219,17 +272,20 @@ func CompactWithLLM(ctx context.Context, messages []ai.Message, cfg CompactionCo
 	summaryText := fmt.Sprintf("[Context Summary]\n%s%s\n[End Summary]", summary, fileTags.String())
 
 	// Build compacted message list
-	compacted := make([]ai.Message, 0, len(recentMessages)+2)
+	compacted := make([]ai.Message, 0, len(pinned)+len(recentMessages)+2)
 	compacted = append(compacted, ai.NewTextMessage(ai.RoleUser, summaryText))
 	compacted = append(compacted, ai.NewTextMessage(ai.RoleAssistant,
 		"I understand the context. Let me continue from where we left off."))
+	compacted = append(compacted, pinned...)
 	compacted = append(compacted, recentMessages...)
 
 	return &CompactResult{
-		Messages:       compacted,
-		Summary:        summary,
-		FileOps:        fileOps,
-		TokensBefore:   tokensBefore,
-		FirstKeptIndex: cutIdx,
+		Messages:         compacted,
+		Summary:          summary,
+		FileOps:          fileOps,
+		TokensBefore:     tokensBefore,
+		FirstKeptIndex:   cutIdx,
+		PinnedKept:       len(pinned),
+		RemindersDropped: dropped,
 	}, nil
 }
@@ -333,3 +333,115 @@ func TestCompactWithLLM_UsesInjectedSummarizer(t *testing.T) {
 		t.Error("First message should contain the summary")
 	}
 }
+
+func TestCompactWithLLM_KeepsPinnedVerbatim(t *testing.T) {
+	msgs := make([]ai.Message, 20)
+	for i := range msgs {
+		role := ai.RoleUser
+		if i%2 == 1 {
+			role = ai.RoleAssistant
+		}
+		msgs[i] = ai.NewTextMessage(role, strings.Repeat("x", 100))
+	}
+	msgs[2] = ai.NewTextMessage(ai.RoleUser, "always use table-driven tests")
+	msgs[2].Pinned = true
+	msgs[4] = ai.Message{Role: ai.RoleAssistant, Pinned: true, Content: []ai.Content{
+		{Type: ai.ContentToolUse, ID: "t1", Name: "read", Input: json.RawMessage(`{"path":"spec.md"}`)},
+	}}
+	msgs[5] = ai.Message{Role: ai.RoleUser, Content: []ai.Content{
+		{Type: ai.ContentToolResult, ID: "t1", ResultText: "the spec"},
+	}}
+
+	var summarized []ai.Message
+	summarizer := func(_ context.Context, m []ai.Message, _ string) (string, error) {
+		summarized = m
+		return "summary", nil
+	}
+
+	result, err := CompactWithLLM(context.Background(), msgs, CompactionConfig{KeepRecentTokens: 200}, summarizer)
+	if err != nil {
+		t.Fatalf("CompactWithLLM returned error: %v", err)
+	}
+	if result.PinnedKept != 3 {
+		t.Fatalf("PinnedKept = %d; want the pinned text plus the tool pair", result.PinnedKept)
+	}
+	for _, m := range summarized {
+		if m.Pinned || hasToolUse(m) || hasToolResult(m) {
+			t.Errorf("pinned message or its pair was summarized: %+v", m)
+		}
+	}
+	if got := result.Messages[2].Content[0].Text; got != "always use table-driven tests" || !result.Messages[2].Pinned {
+		t.Errorf("Messages[2] = %+v; want the pinned message right after the ack", result.Messages[2])
+	}
+	if !hasToolUse(result.Messages[3]) || result.Messages[4].Content[0].ResultText != "the spec" {
+		t.Error("pinned tool call must keep its result next to it")
+	}
+}
+
+func TestCompactWithLLM_AllPinnedIsNoop(t *testing.T) {
+	msgs := make([]ai.Message, 10)
+	for i := range msgs {
+		msgs[i] = ai.NewTextMessage(ai.RoleUser, strings.Repeat("x", 100))
+		msgs[i].Pinned = i < 8
+	}
+	summarizer := func(context.Context, []ai.Message, string) (string, error) {
+		t.Fatal("summarizer must not run when only pinned messages are old")
+		return "", nil
+	}
+
+	result, err := CompactWithLLM(context.Background(), msgs, CompactionConfig{KeepRecentTokens: 50}, summarizer)
+	if err != nil {
+		t.Fatalf("CompactWithLLM returned error: %v", err)
+	}
+	if len(result.Messages) != len(msgs) {
+		t.Errorf("len(Messages) = %d; want unchanged %d", len(result.Messages), len(msgs))
+	}
+	if count, tokens := PinnedStats(msgs); count != 8 || tokens == 0 {
+		t.Errorf("PinnedStats = %d, %d", count, tokens)
+	}
+}
+
+func TestCompactWithLLM_DropsReminders(t *testing.T) {
+	msgs := make([]ai.Message, 20)
+	for i := range msgs {
+		role := ai.RoleUser
+		if i%2 == 1 {
+			role = ai.RoleAssistant
+		}
+		msgs[i] = ai.NewTextMessage(role, strings.Repeat("x", 100))
+	}
+	msgs[3] = NewReminder("Plan mode is active.")
+	msgs[18] = NewReminder("file changed")
+
+	var summarized []ai.Message
+	summarizer := func(_ context.Context, m []ai.Message, _ string) (string, error) {
+		summarized = m
+		return "summary", nil
+	}
+	result, err := CompactWithLLM(context.Background(), msgs, CompactionConfig{KeepRecentTokens: 200}, summarizer)
+	if err != nil {
+		t.Fatalf("CompactWithLLM returned error: %v", err)
+	}
+	if result.RemindersDropped != 2 {
+		t.Errorf("RemindersDropped = %d; want 2", result.RemindersDropped)
+	}
+	for _, m := range append(summarized, result.Messages...) {
+		if m.Ephemeral {
+			t.Errorf("reminder survived compaction: %+v", m)
+		}
+	}
+}
+
+func TestDropEphemeral(t *testing.T) {
+	msgs := []ai.Message{ai.NewTextMessage(ai.RoleUser, "hi"), NewReminder("a", "b")}
+	kept, n := DropEphemeral(msgs)
+	if n != 1 || len(kept) != 1 || len(msgs) != 2 {
+		t.Errorf("DropEphemeral = %d kept, %d dropped; input len %d", len(kept), n, len(msgs))
+	}
+	if got := msgs[1].Content[0].Text; got != "<system-reminder>\na\nb\n</system-reminder>" {
+		t.Errorf("reminder text = %q", got)
+	}
+	if same, n := DropEphemeral(kept); n != 0 || &same[0] != &kept[0] {
+		t.Error("no reminders should return the input slice")
+	}
+}
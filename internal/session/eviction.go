@@ -0,0 +1,160 @@
+// ABOUTME: Context eviction: caps the retained size of tool results older than N user turns
+// ABOUTME: File tool outputs become a stub naming the path; others keep a truncated head
+
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+// evictedNote marks a result that was already evicted, so it is never cut twice.
+const evictedNote = "evicted from context"
+
+// EvictionPolicy controls which tool results are shrunk.
+type EvictionPolicy struct {
+	AfterTurns     int             // results older than this many user turns are eligible
+	MaxResultBytes int             // eligible results larger than this are shrunk
+	PinnedTools    map[string]bool // results of these tools are never shrunk
+}
+
+// EvictionStats counts what one or more eviction passes removed.
+type EvictionStats struct {
+	Results int // tool results shrunk
+	Bytes   int // bytes removed from tool results
+}
+
+// IsEvicted reports whether a tool result's text was shrunk by eviction.
+func IsEvicted(text string) bool {
+	return strings.Contains(text, evictedNote)
+}
+
+// Add accumulates other into s.
+func (s *EvictionStats) Add(other EvictionStats) {
+	s.Results += other.Results
+	s.Bytes += other.Bytes
+}
+
+// EvictToolResults shrinks tool results that are older than p.AfterTurns user
+// turns and larger than p.MaxResultBytes. A turn starts at each user message
+// with text, so the current and recent turns stay intact. Outputs of file
+// tools become a stub naming the path to re-read; other outputs keep their
+// first MaxResultBytes. Pinned messages are left intact. The input slice is
+// not modified.
+func EvictToolResults(messages []ai.Message, p EvictionPolicy) ([]ai.Message, EvictionStats) {
+	var stats EvictionStats
+	if p.AfterTurns <= 0 || p.MaxResultBytes <= 0 {
+		return messages, stats
+	}
+
+	// Index of the first message of the turn that is AfterTurns turns old;
+	// results before it are eligible.
+	cutoff, turns := -1, 0
+	for i := len(messages) - 1; i >= 0; i-- {
+		if isUserPrompt(messages[i]) {
+			turns++
+			if turns == p.AfterTurns {
+				cutoff = i
+				break
+			}
+		}
+	}
+	if cutoff <= 0 {
+		return messages, stats
+	}
+
+	calls := toolCallsByID(messages[:cutoff])
+	out := messages
+	copied := false
+	for i := range cutoff {
+		msg := messages[i]
+		if msg.Pinned {
+			continue
+		}
+		var content []ai.Content
+		for j, c := range msg.Content {
+			if c.Type != ai.ContentToolResult || len(c.ResultText) <= p.MaxResultBytes ||
+				strings.Contains(c.ResultText, evictedNote) {
+				continue
+			}
+			call := calls[c.ID]
+			if p.PinnedTools[call.Name] {
+				continue
+			}
+			if content == nil {
+				content = append([]ai.Content(nil), msg.Content...)
+			}
+			before := len(c.ResultText)
+			content[j].ResultText = evictedText(c.ResultText, call, p)
+			stats.Results++
+			stats.Bytes += before - len(content[j].ResultText)
+		}
+		if content == nil {
+			continue
+		}
+		if !copied {
+			out = append([]ai.Message(nil), messages...)
+			copied = true
+		}
+		out[i].Content = content
+	}
+	return out, stats
+}
+
+// evictedText returns the replacement for an evicted tool result.
+func evictedText(text string, call ai.Content, p EvictionPolicy) string {
+	if path := toolInputPath(call.Input); path != "" {
+		return fmt.Sprintf("[%s output (%d bytes) for %s %s after %d turns; run %s again if you need it]",
+			call.Name, len(text), path, evictedNote, p.AfterTurns, call.Name)
+	}
+	head := text[:p.MaxResultBytes]
+	for len(head) > 0 && !utf8.RuneStart(text[len(head)]) {
+		head = head[:len(head)-1]
+	}
+	return fmt.Sprintf("%s\n[%d more bytes %s after %d turns]", head, len(text)-len(head), evictedNote, p.AfterTurns)
+}
+
+// isUserPrompt reports whether msg is a user message carrying text rather
+// than only tool results. System reminders do not start a turn.
+func isUserPrompt(msg ai.Message) bool {
+	if msg.Role != ai.RoleUser || msg.Ephemeral {
+		return false
+	}
+	for _, c := range msg.Content {
+		if c.Type == ai.ContentText {
+			return true
+		}
+	}
+	return false
+}
+
+// toolCallsByID maps tool_use IDs to their content blocks.
+func toolCallsByID(messages []ai.Message) map[string]ai.Content {
+	calls := make(map[string]ai.Content)
+	for _, msg := range messages {
+		for _, c := range msg.Content {
+			if c.Type == ai.ContentToolUse {
+				calls[c.ID] = c
+			}
+		}
+	}
+	return calls
+}
+
+// toolInputPath returns the file path argument of a tool call, if any.
+func toolInputPath(input json.RawMessage) string {
+	if len(input) == 0 {
+		return ""
+	}
+	var args struct {
+		Path string `json:"path"`
+	}
+	if err := json.Unmarshal(input, &args); err == nil && args.Path != "" {
+		return args.Path
+	}
+	return extractFilePath(input)
+}
@@ -0,0 +1,146 @@
+// ABOUTME: Tests for context eviction: age cutoff, file stubs, head truncation, pinning, idempotency
+// ABOUTME: Builds synthetic conversations of prompt, tool call, and tool result turns
+
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+// toolTurn returns a prompt, an assistant tool call, and its result.
+func toolTurn(n int, tool, input, result string) []ai.Message {
+	id := fmt.Sprintf("call-%d", n)
+	return []ai.Message{
+		ai.NewTextMessage(ai.RoleUser, fmt.Sprintf("prompt %d", n)),
+		{Role: ai.RoleAssistant, Content: []ai.Content{{Type: ai.ContentToolUse, ID: id, Name: tool, Input: json.RawMessage(input)}}},
+		{Role: ai.RoleUser, Content: []ai.Content{{Type: ai.ContentToolResult, ID: id, ResultText: result}}},
+	}
+}
+
+func conversation(turns ...[]ai.Message) []ai.Message {
+	var msgs []ai.Message
+	for _, t := range turns {
+		msgs = append(msgs, t...)
+	}
+	return msgs
+}
+
+func resultText(msgs []ai.Message, turn int) string {
+	return msgs[turn*3+2].Content[0].ResultText
+}
+
+func TestEvictToolResults_StubsOldFileReads(t *testing.T) {
+	t.Parallel()
+
+	big := strings.Repeat("x", 1000)
+	msgs := conversation(
+		toolTurn(0, "read", `{"path":"main.go"}`, big),
+		toolTurn(1, "read", `{"path":"util.go"}`, big),
+		toolTurn(2, "read", `{"path":"new.go"}`, big),
+	)
+
+	got, stats := EvictToolResults(msgs, EvictionPolicy{AfterTurns: 2, MaxResultBytes: 100})
+
+	stub := resultText(got, 0)
+	if !strings.Contains(stub, "main.go") || !strings.Contains(stub, "1000 bytes") || !strings.Contains(stub, evictedNote) {
+		t.Errorf("stub = %q, want path, size, and note", stub)
+	}
+	if resultText(got, 1) != big || resultText(got, 2) != big {
+		t.Error("the two most recent turns must stay intact")
+	}
+	if stats.Results != 1 || stats.Bytes != len(big)-len(stub) {
+		t.Errorf("stats = %+v", stats)
+	}
+	if resultText(msgs, 0) != big {
+		t.Error("input messages must not be modified")
+	}
+}
+
+func TestEvictToolResults_TruncatesOtherOutput(t *testing.T) {
+	t.Parallel()
+
+	out := strings.Repeat("é", 100) // 200 bytes, 2 per rune
+	msgs := conversation(
+		toolTurn(0, "bash", `{"command":"make"}`, out),
+		toolTurn(1, "bash", `{"command":"ls"}`, "ok"),
+	)
+
+	got, stats := EvictToolResults(msgs, EvictionPolicy{AfterTurns: 1, MaxResultBytes: 51})
+
+	text := resultText(got, 0)
+	if !strings.HasPrefix(text, strings.Repeat("é", 25)+"\n[") {
+		t.Errorf("head = %q, want 25 whole runes then the note", text[:60])
+	}
+	if !strings.Contains(text, "150 more bytes") {
+		t.Errorf("note = %q", text)
+	}
+	if stats.Results != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	again, stats := EvictToolResults(got, EvictionPolicy{AfterTurns: 1, MaxResultBytes: 51})
+	if stats.Results != 0 || resultText(again, 0) != text {
+		t.Error("already evicted results must not be cut again")
+	}
+}
+
+func TestEvictToolResults_SkipsPinnedAndSmall(t *testing.T) {
+	t.Parallel()
+
+	big := strings.Repeat("s", 500)
+	msgs := conversation(
+		toolTurn(0, "skill", `{"name":"tdd"}`, big),
+		toolTurn(1, "read", `{"path":"a.go"}`, "small"),
+		toolTurn(2, "read", `{"path":"b.go"}`, big),
+	)
+
+	got, stats := EvictToolResults(msgs, EvictionPolicy{
+		AfterTurns: 1, MaxResultBytes: 100, PinnedTools: map[string]bool{"skill": true},
+	})
+	if resultText(got, 0) != big || resultText(got, 1) != "small" {
+		t.Error("pinned and small results must stay intact")
+	}
+	if stats.Results != 0 {
+		t.Errorf("stats = %+v, want nothing evicted", stats)
+	}
+}
+
+func TestEvictToolResults_SkipsPinnedMessages(t *testing.T) {
+	t.Parallel()
+
+	big := strings.Repeat("x", 500)
+	msgs := conversation(toolTurn(0, "read", `{"path":"a.go"}`, big), toolTurn(1, "ls", `{}`, ""))
+	msgs[2].Pinned = true
+
+	got, stats := EvictToolResults(msgs, EvictionPolicy{AfterTurns: 1, MaxResultBytes: 100})
+	if resultText(got, 0) != big || stats.Results != 0 {
+		t.Errorf("pinned result was evicted: %+v", stats)
+	}
+}
+
+func TestEvictToolResults_RemindersDoNotStartTurns(t *testing.T) {
+	t.Parallel()
+
+	big := strings.Repeat("x", 500)
+	msgs := conversation(toolTurn(0, "read", `{"path":"a.go"}`, big))
+	msgs = append(msgs, NewReminder("a.go changed on disk"))
+
+	got, stats := EvictToolResults(msgs, EvictionPolicy{AfterTurns: 1, MaxResultBytes: 100})
+	if resultText(got, 0) != big || stats.Results != 0 {
+		t.Errorf("a reminder counted as a user turn: %+v", stats)
+	}
+}
+
+func TestEvictToolResults_Disabled(t *testing.T) {
+	t.Parallel()
+
+	msgs := conversation(toolTurn(0, "read", `{"path":"a.go"}`, strings.Repeat("x", 500)), toolTurn(1, "ls", `{}`, ""))
+	if _, stats := EvictToolResults(msgs, EvictionPolicy{}); stats.Results != 0 {
+		t.Errorf("zero policy evicted %+v", stats)
+	}
+}
@@ -1,5 +1,5 @@
 // ABOUTME: Session forking and PR linking for branching conversations
-// ABOUTME: Copies session JSONL to new ID; tracks PR-to-session mappings atomically
+// ABOUTME: Forks copy a session up to a chosen turn and record the parent; tracks PR-to-session mappings
 
 package session
 
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 )
 
@@ -17,23 +18,74 @@ import (
 type ForkResult struct {
 	OriginalID string
 	NewID      string
+	Turns      int    // user turns the fork kept
 	Branch     string // git branch associated with fork, if any
 }
 
-// Fork duplicates a session's messages file under a new random ID.
-// The original session is left unchanged.
+// Fork duplicates a session under a new random ID. The copy records the
+// original as its parent; the original session is left unchanged.
 func Fork(sessionDir, sessionID string) (*ForkResult, error) {
-	srcPath := filepath.Join(sessionDir, sessionID+".jsonl")
+	return ForkAt(sessionDir, sessionID, -1)
+}
 
-	data, err := os.ReadFile(srcPath)
+// ForkAt starts a new session holding the records of sessionID that come
+// before its turn-th user message (0-based), so the conversation can take
+// another path from there. A negative turn keeps every record. The new
+// session_start names sessionID as parent; the original is left unchanged.
+func ForkAt(sessionDir, sessionID string, turn int) (*ForkResult, error) {
+	records, err := ReadRecordsInDir(sessionDir, sessionID)
 	if err != nil {
 		return nil, fmt.Errorf("reading session %s: %w", sessionID, err)
 	}
+	if len(records) == 0 || records[0].Type != RecordSessionStart {
+		return nil, fmt.Errorf("session %s has no session_start record", sessionID)
+	}
+	var start SessionStartData
+	if err := records[0].Unmarshal(&start); err != nil {
+		return nil, fmt.Errorf("parsing session start: %w", err)
+	}
+
+	kept := records[1:]
+	turns := 0
+	for i, rec := range kept {
+		if rec.Type != RecordUser {
+			continue
+		}
+		if turns == turn {
+			kept = kept[:i]
+			break
+		}
+		turns++
+	}
+	if turn > turns {
+		return nil, fmt.Errorf("session %s has %d turns; cannot fork before turn %d", sessionID, turns, turn+1)
+	}
 
 	newID, err := generateSessionID()
 	if err != nil {
 		return nil, fmt.Errorf("generating session ID: %w", err)
 	}
+	start.ID = newID
+	start.ParentID = sessionID
+	start.ForkTurn = turns
+	startData, err := json.Marshal(start)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling session start: %w", err)
+	}
+	head := records[0]
+	head.Data = startData
+
+	var data []byte
+	for _, rec := range append([]Record{head}, kept...) {
+		if rec.Type == RecordSessionEnd {
+			continue
+		}
+		line, err := json.Marshal(rec)
+		if err != nil {
+			return nil, fmt.Errorf("marshaling record: %w", err)
+		}
+		data = append(append(data, line...), '\n')
+	}
 
 	dstPath := filepath.Join(sessionDir, newID+".jsonl")
 	if err := os.WriteFile(dstPath, data, 0o600); err != nil {
@@ -43,9 +95,46 @@ func Fork(sessionDir, sessionID string) (*ForkResult, error) {
 	return &ForkResult{
 		OriginalID: sessionID,
 		NewID:      newID,
+		Turns:      turns,
 	}, nil
 }
 
+// ForkNode is one session of a fork tree.
+type ForkNode struct {
+	SessionStartData
+	Children []*ForkNode // forks of this session, oldest first
+}
+
+// ForkTree returns the tree of forks containing sessionID, rooted at its
+// oldest listed ancestor. It returns nil when sessionID is not in sessions.
+func ForkTree(sessions []SessionStartData, sessionID string) *ForkNode {
+	nodes := make(map[string]*ForkNode, len(sessions))
+	for _, s := range sessions {
+		nodes[s.ID] = &ForkNode{SessionStartData: s}
+	}
+	root, ok := nodes[sessionID]
+	if !ok {
+		return nil
+	}
+	for seen := map[string]bool{root.ID: true}; ; {
+		parent, ok := nodes[root.ParentID]
+		if !ok || seen[parent.ID] {
+			break
+		}
+		seen[parent.ID] = true
+		root = parent
+	}
+
+	sorted := slices.Clone(sessions)
+	slices.SortStableFunc(sorted, func(a, b SessionStartData) int { return a.StartedAt.Compare(b.StartedAt) })
+	for _, s := range sorted {
+		if parent, ok := nodes[s.ParentID]; ok && s.ID != root.ID {
+			parent.Children = append(parent.Children, nodes[s.ID])
+		}
+	}
+	return root
+}
+
 // LinkPR associates a PR number with a session ID.
 // Creates or updates pr_links.json in sessionDir atomically.
 func LinkPR(sessionDir string, prNumber int, sessionID string) error {
@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func writeTestSession(t *testing.T, dir, sessionID string) {
@@ -62,18 +63,105 @@ func TestFork(t *testing.T) {
 		t.Errorf("original session file missing: %v", err)
 	}
 
-	// Verify new session file exists with same content
-	newPath := filepath.Join(dir, result.NewID+".jsonl")
-	origData, err := os.ReadFile(origPath)
+	// The fork keeps every record and names the original as its parent
+	orig, err := ReadRecordsInDir(dir, sessionID)
 	if err != nil {
 		t.Fatalf("reading original: %v", err)
 	}
-	newData, err := os.ReadFile(newPath)
+	forked, err := ReadRecordsInDir(dir, result.NewID)
 	if err != nil {
 		t.Fatalf("reading fork: %v", err)
 	}
-	if string(origData) != string(newData) {
-		t.Error("forked session content differs from original")
+	if len(forked) != len(orig) {
+		t.Fatalf("fork has %d records; want %d", len(forked), len(orig))
+	}
+	for i := 1; i < len(orig); i++ {
+		if string(forked[i].Data) != string(orig[i].Data) || forked[i].TS != orig[i].TS {
+			t.Errorf("record %d differs: %s vs %s", i, forked[i].Data, orig[i].Data)
+		}
+	}
+	var start SessionStartData
+	if err := forked[0].Unmarshal(&start); err != nil {
+		t.Fatal(err)
+	}
+	if start.ID != result.NewID || start.ParentID != sessionID || start.ForkTurn != 1 || start.Model != "test" {
+		t.Errorf("fork session_start = %+v", start)
+	}
+}
+
+func TestForkAt_KeepsTurnsBeforePoint(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "sessions")
+	w, err := NewWriterInDir(dir, "orig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = w.WriteRecord(RecordSessionStart, SessionStartData{ID: "orig", Model: "m"})
+	for _, q := range []string{"one", "two", "three"} {
+		_ = w.WriteRecord(RecordUser, UserData{Content: q})
+		_ = w.WriteRecord(RecordAssistant, AssistantData{Content: "re " + q})
+	}
+	_ = w.WriteRecord(RecordSessionEnd, nil)
+	_ = w.Close()
+
+	res, err := ForkAt(dir, "orig", 2)
+	if err != nil {
+		t.Fatalf("ForkAt: %v", err)
+	}
+	if res.Turns != 2 {
+		t.Errorf("Turns = %d; want 2", res.Turns)
+	}
+	records, _ := ReadRecordsInDir(dir, res.NewID)
+	msgs, err := BuildSessionContext(records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 4 || msgs[3].Content[0].Text != "re two" {
+		t.Errorf("fork messages = %+v; want the first two turns", msgs)
+	}
+
+	all, err := ForkAt(dir, "orig", -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, _ = ReadRecordsInDir(dir, all.NewID)
+	if all.Turns != 3 || records[len(records)-1].Type == RecordSessionEnd {
+		t.Errorf("whole fork: turns=%d, last record %s; want 3 turns without session_end", all.Turns, records[len(records)-1].Type)
+	}
+
+	if _, err := ForkAt(dir, "orig", 5); err == nil {
+		t.Error("ForkAt past the last turn should fail")
+	}
+}
+
+func TestForkTree(t *testing.T) {
+	t.Parallel()
+
+	at := func(min int) time.Time { return time.Date(2026, 1, 1, 0, min, 0, 0, time.UTC) }
+	sessions := []SessionStartData{
+		{ID: "b2", ParentID: "a", ForkTurn: 3, StartedAt: at(5)},
+		{ID: "a", StartedAt: at(0)},
+		{ID: "b1", ParentID: "a", ForkTurn: 1, StartedAt: at(2)},
+		{ID: "c", ParentID: "b1", StartedAt: at(9)},
+		{ID: "other", StartedAt: at(1)},
+	}
+
+	root := ForkTree(sessions, "c")
+	if root == nil || root.ID != "a" {
+		t.Fatalf("root = %+v; want a", root)
+	}
+	if len(root.Children) != 2 || root.Children[0].ID != "b1" || root.Children[1].ID != "b2" {
+		t.Fatalf("children of a = %+v; want b1, b2 by start time", root.Children)
+	}
+	if len(root.Children[0].Children) != 1 || root.Children[0].Children[0].ID != "c" {
+		t.Errorf("children of b1 = %+v; want c", root.Children[0].Children)
+	}
+	if ForkTree(sessions, "missing") != nil {
+		t.Error("unknown session should have no tree")
+	}
+	if r := ForkTree(sessions, "other"); r == nil || len(r.Children) != 0 {
+		t.Errorf("unrelated session tree = %+v", r)
 	}
 }
 
@@ -200,3 +288,32 @@ func TestListPRLinks(t *testing.T) {
 		}
 	}
 }
+
+func TestSession_ReopenAppendsToFork(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "sessions")
+	writeTestSession(t, dir, "orig")
+	res, err := Fork(dir, "orig")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &Session{ID: "orig"}
+	if err := s.Reopen(dir, res.NewID, nil); err != nil {
+		t.Fatalf("Reopen: %v", err)
+	}
+	if err := s.AddUserMessage("on the branch"); err != nil {
+		t.Fatal(err)
+	}
+	_ = s.Close()
+
+	if s.ID != res.NewID {
+		t.Errorf("ID = %q; want %q", s.ID, res.NewID)
+	}
+	forked, _ := ReadRecordsInDir(dir, res.NewID)
+	orig, _ := ReadRecordsInDir(dir, "orig")
+	if len(forked) != len(orig)+1 {
+		t.Errorf("fork has %d records; want the original %d plus the new message", len(forked), len(orig))
+	}
+}
@@ -0,0 +1,382 @@
+// ABOUTME: Imports Claude Code JSONL and aider chat-history transcripts as pi-go sessions
+// ABOUTME: Parses each format into session records and writes them under a fresh session ID
+
+package session
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ImportFormat identifies the source tool of an imported transcript.
+type ImportFormat string
+
+const (
+	ImportAuto       ImportFormat = ""       // Detect from file name and content
+	ImportClaudeCode ImportFormat = "claude" // Claude Code project JSONL
+	ImportAider      ImportFormat = "aider"  // aider .aider.chat.history.md
+)
+
+// ParseImportFormat converts a CLI value to an ImportFormat.
+func ParseImportFormat(s string) (ImportFormat, error) {
+	switch strings.ToLower(s) {
+	case "", "auto":
+		return ImportAuto, nil
+	case "claude", "claude-code":
+		return ImportClaudeCode, nil
+	case "aider":
+		return ImportAider, nil
+	default:
+		return "", fmt.Errorf("unknown import format %q: expected claude or aider", s)
+	}
+}
+
+// ImportResult describes a completed import.
+type ImportResult struct {
+	SessionID string
+	Path      string
+	Format    ImportFormat
+	Messages  int // user + assistant records written
+}
+
+// importRecord is a single record awaiting persistence.
+type importRecord struct {
+	Type RecordType
+	TS   time.Time
+	Data any
+}
+
+// transcript is the format-neutral result of parsing a foreign history file.
+type transcript struct {
+	Model   string
+	CWD     string
+	Records []importRecord
+}
+
+// Import converts the transcript at path into a new pi-go session stored in
+// sessionsDir. With ImportAuto the format is detected from the file.
+func Import(path string, format ImportFormat, sessionsDir string) (*ImportResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	if format == ImportAuto {
+		format = DetectImportFormat(path, data)
+	}
+
+	var tr *transcript
+	switch format {
+	case ImportClaudeCode:
+		tr, err = parseClaudeCode(bytes.NewReader(data))
+	case ImportAider:
+		tr, err = parseAider(bytes.NewReader(data))
+	default:
+		return nil, fmt.Errorf("cannot detect transcript format of %s; pass --format", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s transcript: %w", format, err)
+	}
+
+	messages := 0
+	for _, rec := range tr.Records {
+		if rec.Type == RecordUser || rec.Type == RecordAssistant {
+			messages++
+		}
+	}
+	if messages == 0 {
+		return nil, fmt.Errorf("no messages found in %s", path)
+	}
+
+	id, err := generateSessionID()
+	if err != nil {
+		return nil, fmt.Errorf("generating session ID: %w", err)
+	}
+
+	w, err := NewWriterInDir(sessionsDir, id)
+	if err != nil {
+		return nil, err
+	}
+	defer w.Close()
+
+	start := time.Now()
+	if len(tr.Records) > 0 && !tr.Records[0].TS.IsZero() {
+		start = tr.Records[0].TS
+	}
+	abs, _ := filepath.Abs(path)
+	if err := w.WriteRecordAt(RecordSessionStart, SessionStartData{
+		ID:           id,
+		Model:        tr.Model,
+		CWD:          tr.CWD,
+		ImportedFrom: string(format) + ":" + abs,
+	}, start); err != nil {
+		return nil, fmt.Errorf("writing session start: %w", err)
+	}
+
+	last := start
+	for _, rec := range tr.Records {
+		ts := rec.TS
+		if ts.IsZero() {
+			ts = last
+		}
+		last = ts
+		if err := w.WriteRecordAt(rec.Type, rec.Data, ts); err != nil {
+			return nil, fmt.Errorf("writing %s record: %w", rec.Type, err)
+		}
+	}
+
+	return &ImportResult{
+		SessionID: id,
+		Path:      filepath.Join(sessionsDir, id+".jsonl"),
+		Format:    format,
+		Messages:  messages,
+	}, nil
+}
+
+// DetectImportFormat guesses the transcript format from the file name and
+// leading content. Returns ImportAuto when the format is unrecognized.
+func DetectImportFormat(path string, data []byte) ImportFormat {
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case strings.HasSuffix(path, ".md"), bytes.HasPrefix(trimmed, []byte("# aider chat started")):
+		return ImportAider
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		return ImportClaudeCode
+	default:
+		return ImportAuto
+	}
+}
+
+// --- Claude Code ---
+
+// claudeLine is one entry of a Claude Code project JSONL file.
+type claudeLine struct {
+	Type      string         `json:"type"`
+	Timestamp string         `json:"timestamp"`
+	CWD       string         `json:"cwd"`
+	IsMeta    bool           `json:"isMeta"`
+	Message   *claudeMessage `json:"message"`
+}
+
+type claudeMessage struct {
+	ID         string          `json:"id"`
+	Role       string          `json:"role"`
+	Model      string          `json:"model"`
+	Content    json.RawMessage `json:"content"`
+	StopReason string          `json:"stop_reason"`
+	Usage      struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+type claudeBlock struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text"`
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Input     json.RawMessage `json:"input"`
+	ToolUseID string          `json:"tool_use_id"`
+	Content   json.RawMessage `json:"content"`
+	IsError   bool            `json:"is_error"`
+}
+
+// parseClaudeCode converts a Claude Code session JSONL stream. Summary,
+// system, and meta lines are skipped; consecutive assistant lines sharing a
+// message ID (Claude Code writes one line per content block) are merged.
+func parseClaudeCode(r io.Reader) (*transcript, error) {
+	tr := &transcript{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, scannerInitialBuf), scannerMaxBuf)
+
+	lastAssistantID := ""
+	lastAssistantIdx := -1
+
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var cl claudeLine
+		if err := json.Unmarshal(line, &cl); err != nil {
+			continue // tolerate partial or foreign lines
+		}
+		if cl.Message == nil || cl.IsMeta {
+			continue
+		}
+		if tr.CWD == "" {
+			tr.CWD = cl.CWD
+		}
+		ts, _ := time.Parse(time.RFC3339Nano, cl.Timestamp)
+
+		blocks, text := decodeClaudeContent(cl.Message.Content)
+
+		switch cl.Type {
+		case "user":
+			lastAssistantID = ""
+			for _, b := range blocks {
+				if b.Type == "tool_result" {
+					tr.Records = append(tr.Records, importRecord{
+						Type: RecordToolResult, TS: ts,
+						Data: ToolResultData{ID: b.ToolUseID, Content: claudeResultText(b.Content), IsError: b.IsError},
+					})
+				}
+			}
+			if strings.TrimSpace(text) != "" {
+				tr.Records = append(tr.Records, importRecord{Type: RecordUser, TS: ts, Data: UserData{Content: text}})
+			}
+
+		case "assistant":
+			if tr.Model == "" {
+				tr.Model = cl.Message.Model
+			}
+			for _, b := range blocks {
+				if b.Type == "tool_use" {
+					tr.Records = append(tr.Records, importRecord{
+						Type: RecordToolCall, TS: ts,
+						Data: ToolCallData{ID: b.ID, Name: b.Name, Args: b.Input},
+					})
+				}
+			}
+			if strings.TrimSpace(text) == "" {
+				continue
+			}
+			if cl.Message.ID != "" && cl.Message.ID == lastAssistantID && lastAssistantIdx >= 0 {
+				ad := tr.Records[lastAssistantIdx].Data.(AssistantData)
+				ad.Content += text
+				ad.StopReason = cl.Message.StopReason
+				tr.Records[lastAssistantIdx].Data = ad
+				continue
+			}
+			tr.Records = append(tr.Records, importRecord{Type: RecordAssistant, TS: ts, Data: AssistantData{
+				Content:    text,
+				Model:      cl.Message.Model,
+				Usage:      UsageData{Input: cl.Message.Usage.InputTokens, Output: cl.Message.Usage.OutputTokens},
+				StopReason: cl.Message.StopReason,
+			}})
+			lastAssistantID = cl.Message.ID
+			lastAssistantIdx = len(tr.Records) - 1
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scanning: %w", err)
+	}
+	return tr, nil
+}
+
+// decodeClaudeContent returns the content blocks and the concatenated text
+// of a Claude message content field, which is either a string or an array.
+func decodeClaudeContent(raw json.RawMessage) ([]claudeBlock, string) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return nil, s
+	}
+	var blocks []claudeBlock
+	if err := json.Unmarshal(raw, &blocks); err != nil {
+		return nil, ""
+	}
+	var text strings.Builder
+	for _, b := range blocks {
+		if b.Type == "text" {
+			text.WriteString(b.Text)
+		}
+	}
+	return blocks, text.String()
+}
+
+// claudeResultText flattens a tool_result content field (string or blocks).
+func claudeResultText(raw json.RawMessage) string {
+	_, text := decodeClaudeContent(raw)
+	return text
+}
+
+// --- aider ---
+
+const (
+	aiderSessionHeader = "# aider chat started at "
+	aiderUserPrefix    = "#### "
+	aiderToolPrefix    = ">"
+	aiderTimeLayout    = "2006-01-02 15:04:05"
+)
+
+// parseAider converts an aider chat history markdown file. Lines starting
+// with "#### " are user input, "> " lines are aider tool output (kept as
+// tool results), and everything else is assistant text.
+func parseAider(r io.Reader) (*transcript, error) {
+	tr := &transcript{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, scannerInitialBuf), scannerMaxBuf)
+
+	var ts time.Time
+	var kind RecordType
+	var buf []string
+
+	flush := func() {
+		text := strings.TrimSpace(strings.Join(buf, "\n"))
+		buf = buf[:0]
+		if text == "" {
+			return
+		}
+		var data any
+		switch kind {
+		case RecordUser:
+			data = UserData{Content: text}
+		case RecordAssistant:
+			data = AssistantData{Content: text}
+		case RecordToolResult:
+			data = ToolResultData{Content: text}
+		default:
+			return
+		}
+		tr.Records = append(tr.Records, importRecord{Type: kind, TS: ts, Data: data})
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		var lineKind RecordType
+		content := line
+		switch {
+		case strings.HasPrefix(line, aiderSessionHeader):
+			flush()
+			kind = ""
+			if t, err := time.ParseInLocation(aiderTimeLayout, strings.TrimSpace(strings.TrimPrefix(line, aiderSessionHeader)), time.Local); err == nil {
+				ts = t
+			}
+			continue
+		case strings.HasPrefix(line, aiderUserPrefix) || line == "####":
+			lineKind = RecordUser
+			content = strings.TrimPrefix(strings.TrimPrefix(line, "####"), " ")
+		case strings.HasPrefix(line, aiderToolPrefix):
+			lineKind = RecordToolResult
+			content = strings.TrimPrefix(strings.TrimPrefix(line, aiderToolPrefix), " ")
+		case strings.TrimSpace(line) == "":
+			// Blank lines belong to whatever block is open.
+			if kind != "" {
+				buf = append(buf, "")
+			}
+			continue
+		default:
+			lineKind = RecordAssistant
+		}
+
+		if lineKind != kind {
+			flush()
+			kind = lineKind
+		}
+		buf = append(buf, content)
+	}
+	flush()
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scanning: %w", err)
+	}
+	return tr, nil
+}
@@ -0,0 +1,209 @@
+// ABOUTME: Tests for importing Claude Code and aider transcripts as pi-go sessions
+// ABOUTME: Covers format detection, per-format parsing, and the written session file
+
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const claudeFixture = `{"type":"summary","summary":"Fix tests","leafUuid":"x"}
+{"type":"user","timestamp":"2025-03-01T10:00:00.000Z","cwd":"/work/app","message":{"role":"user","content":"fix the failing test"}}
+{"type":"assistant","timestamp":"2025-03-01T10:00:05.000Z","message":{"id":"msg_1","role":"assistant","model":"claude-sonnet-4","content":[{"type":"text","text":"Let me look. "}],"usage":{"input_tokens":100,"output_tokens":20}}}
+{"type":"assistant","timestamp":"2025-03-01T10:00:06.000Z","message":{"id":"msg_1","role":"assistant","model":"claude-sonnet-4","content":[{"type":"tool_use","id":"tu_1","name":"Bash","input":{"command":"go test ./..."}}],"stop_reason":"tool_use"}}
+{"type":"user","timestamp":"2025-03-01T10:00:09.000Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"tu_1","content":[{"type":"text","text":"FAIL foo_test.go"}],"is_error":true}]}}
+{"type":"user","timestamp":"2025-03-01T10:00:10.000Z","isMeta":true,"message":{"role":"user","content":"<command-name>/clear</command-name>"}}
+{"type":"assistant","timestamp":"2025-03-01T10:00:15.000Z","message":{"id":"msg_2","role":"assistant","model":"claude-sonnet-4","content":[{"type":"text","text":"Fixed."}],"stop_reason":"end_turn"}}
+not json at all
+`
+
+const aiderFixture = `
+# aider chat started at 2025-03-02 09:30:00
+
+> Aider v0.50.0
+> Model: gpt-4o
+
+#### add a README
+#### with install steps
+
+Sure, here is a README.
+
+It has two sections.
+
+> Applied edit to README.md
+
+#### thanks
+
+You're welcome!
+`
+
+func TestDetectImportFormat(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		path string
+		data string
+		want ImportFormat
+	}{
+		{"aider by extension", "/x/.aider.chat.history.md", "whatever", ImportAider},
+		{"aider by header", "/x/history", "\n# aider chat started at 2025-01-01 00:00:00\n", ImportAider},
+		{"claude jsonl", "/x/abc.jsonl", `{"type":"user"}`, ImportClaudeCode},
+		{"unknown", "/x/notes.txt", "hello", ImportAuto},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := DetectImportFormat(tt.path, []byte(tt.data)); got != tt.want {
+				t.Errorf("DetectImportFormat() = %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseImportFormat(t *testing.T) {
+	t.Parallel()
+
+	for in, want := range map[string]ImportFormat{"": ImportAuto, "auto": ImportAuto, "claude-code": ImportClaudeCode, "Aider": ImportAider} {
+		got, err := ParseImportFormat(in)
+		if err != nil || got != want {
+			t.Errorf("ParseImportFormat(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseImportFormat("cursor"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestParseClaudeCode(t *testing.T) {
+	t.Parallel()
+
+	tr, err := parseClaudeCode(strings.NewReader(claudeFixture))
+	if err != nil {
+		t.Fatalf("parseClaudeCode() error: %v", err)
+	}
+
+	if tr.CWD != "/work/app" || tr.Model != "claude-sonnet-4" {
+		t.Errorf("metadata = (%q, %q); want (/work/app, claude-sonnet-4)", tr.CWD, tr.Model)
+	}
+
+	var types []string
+	for _, r := range tr.Records {
+		types = append(types, string(r.Type))
+	}
+	want := "user,assistant,tool_call,tool_result,assistant"
+	if got := strings.Join(types, ","); got != want {
+		t.Fatalf("record types = %s; want %s", got, want)
+	}
+
+	ad := tr.Records[1].Data.(AssistantData)
+	if ad.Content != "Let me look. " || ad.Usage.Input != 100 {
+		t.Errorf("assistant data = %+v", ad)
+	}
+	tc := tr.Records[2].Data.(ToolCallData)
+	if tc.Name != "Bash" || !strings.Contains(string(tc.Args), "go test") {
+		t.Errorf("tool call = %+v", tc)
+	}
+	res := tr.Records[3].Data.(ToolResultData)
+	if res.ID != "tu_1" || res.Content != "FAIL foo_test.go" || !res.IsError {
+		t.Errorf("tool result = %+v", res)
+	}
+}
+
+func TestParseAider(t *testing.T) {
+	t.Parallel()
+
+	tr, err := parseAider(strings.NewReader(aiderFixture))
+	if err != nil {
+		t.Fatalf("parseAider() error: %v", err)
+	}
+
+	var got []string
+	for _, r := range tr.Records {
+		switch d := r.Data.(type) {
+		case UserData:
+			got = append(got, "user:"+d.Content)
+		case AssistantData:
+			got = append(got, "assistant:"+d.Content)
+		case ToolResultData:
+			got = append(got, "tool:"+d.Content)
+		}
+		if r.TS.IsZero() {
+			t.Errorf("record %s has zero timestamp", r.Type)
+		}
+	}
+
+	want := []string{
+		"tool:Aider v0.50.0\nModel: gpt-4o",
+		"user:add a README\nwith install steps",
+		"assistant:Sure, here is a README.\n\nIt has two sections.",
+		"tool:Applied edit to README.md",
+		"user:thanks",
+		"assistant:You're welcome!",
+	}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("records =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestImport_WritesResumableSession(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	src := filepath.Join(dir, "claude.jsonl")
+	if err := os.WriteFile(src, []byte(claudeFixture), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	sessionsDir := filepath.Join(dir, "sessions")
+
+	res, err := Import(src, ImportAuto, sessionsDir)
+	if err != nil {
+		t.Fatalf("Import() error: %v", err)
+	}
+	if res.Format != ImportClaudeCode || res.Messages != 3 {
+		t.Errorf("result = %+v; want claude format with 3 messages", res)
+	}
+
+	records, err := ReadRecordsFromPath(res.Path)
+	if err != nil {
+		t.Fatalf("ReadRecordsFromPath() error: %v", err)
+	}
+	if records[0].Type != RecordSessionStart {
+		t.Fatalf("first record = %s; want session_start", records[0].Type)
+	}
+	var start SessionStartData
+	if err := records[0].Unmarshal(&start); err != nil {
+		t.Fatal(err)
+	}
+	if start.ID != res.SessionID || !strings.HasPrefix(start.ImportedFrom, "claude:") {
+		t.Errorf("session start = %+v", start)
+	}
+	if records[1].TS != "2025-03-01T10:00:00Z" {
+		t.Errorf("user record ts = %q; want original timestamp", records[1].TS)
+	}
+
+	msgs, err := BuildSessionContext(records)
+	if err != nil {
+		t.Fatalf("BuildSessionContext() error: %v", err)
+	}
+	if len(msgs) != 3 || msgs[0].Content[0].Text != "fix the failing test" {
+		t.Errorf("rebuilt context = %+v", msgs)
+	}
+}
+
+func TestImport_EmptyTranscript(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	src := filepath.Join(dir, "empty.jsonl")
+	if err := os.WriteFile(src, []byte(`{"type":"summary"}`+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Import(src, ImportAuto, filepath.Join(dir, "sessions")); err == nil {
+		t.Error("expected error for transcript without messages")
+	}
+}
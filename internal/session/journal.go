@@ -0,0 +1,329 @@
+// ABOUTME: Crash journal: append-only log of the in-flight turn and prompt queue, fsynced at tool boundaries
+// ABOUTME: A journal left behind by a dead process is recovered as a partial assistant reply and its queue
+
+package session
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+const (
+	journalExt           = ".journal"
+	maxJournalToolOutput = 4096 // tool output kept per call; the rest is elided
+)
+
+// journalEntry is one line of a journal.
+type journalEntry struct {
+	Type    string `json:"type"` // turn, text, tool_start, tool_end, queue
+	PID     int    `json:"pid,omitempty"`
+	TS      string `json:"ts,omitempty"`
+	Prompt  string `json:"prompt,omitempty"`
+	Text    string `json:"text,omitempty"`
+	ToolID  string `json:"tool_id,omitempty"`
+	Tool    string `json:"tool,omitempty"`
+	Args    string `json:"args,omitempty"`
+	IsError bool   `json:"is_error,omitempty"`
+
+	Queue     []string `json:"queue,omitempty"`      // queue: prompts waiting to run
+	QueueNext int      `json:"queue_next,omitempty"` // queue: leading prompts flagged to run next
+}
+
+// Journal records the turn in progress and the prompt queue next to the
+// session file, so a crash mid-turn loses nothing that was streamed or
+// queued. It holds one turn at a time: EndTurn empties it once the turn is
+// saved, keeping the queue, and Close deletes it. Methods are nil-safe so
+// callers need not check for a journal.
+type Journal struct {
+	path  string
+	file  *os.File
+	queue *journalEntry // latest queue entry, rewritten whenever the file is emptied
+}
+
+// OpenJournal creates an empty journal for session id under dir.
+func OpenJournal(dir, id string) (*Journal, error) {
+	if !validSessionID.MatchString(id) {
+		return nil, fmt.Errorf("invalid session ID %q: must match [a-zA-Z0-9_-]+", id)
+	}
+	path := filepath.Join(dir, id+journalExt)
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("opening journal: %w", err)
+	}
+	return &Journal{path: path, file: f}, nil
+}
+
+// BeginTurn starts a turn for prompt, discarding the previous one.
+func (j *Journal) BeginTurn(prompt string) error {
+	if j == nil {
+		return nil
+	}
+	if err := j.reset(); err != nil {
+		return err
+	}
+	return j.write(journalEntry{
+		Type:   "turn",
+		PID:    os.Getpid(),
+		TS:     time.Now().UTC().Format(time.RFC3339),
+		Prompt: prompt,
+	}, true)
+}
+
+// Text records streamed assistant text. It is not synced: a process crash
+// keeps it, and the next tool boundary makes it durable.
+func (j *Journal) Text(delta string) error {
+	if j == nil || delta == "" {
+		return nil
+	}
+	return j.write(journalEntry{Type: "text", Text: delta}, false)
+}
+
+// ToolStart records a tool call about to run.
+func (j *Journal) ToolStart(id, name, args string) error {
+	if j == nil {
+		return nil
+	}
+	return j.write(journalEntry{Type: "tool_start", ToolID: id, Tool: name, Args: args}, true)
+}
+
+// ToolEnd records a finished tool call.
+func (j *Journal) ToolEnd(id, output string, isError bool) error {
+	if j == nil {
+		return nil
+	}
+	if len(output) > maxJournalToolOutput {
+		output = output[:maxJournalToolOutput] + "\n[...]"
+	}
+	return j.write(journalEntry{Type: "tool_end", ToolID: id, Text: output, IsError: isError}, true)
+}
+
+// Queue records the prompts waiting to run; the first next of them are
+// flagged to run next. An empty queue clears the previous one.
+func (j *Journal) Queue(prompts []string, next int) error {
+	if j == nil {
+		return nil
+	}
+	if len(prompts) == 0 && j.queue == nil {
+		return nil
+	}
+	e := journalEntry{
+		Type:      "queue",
+		PID:       os.Getpid(),
+		TS:        time.Now().UTC().Format(time.RFC3339),
+		Queue:     slices.Clone(prompts),
+		QueueNext: next,
+	}
+	j.queue = &e
+	if len(prompts) == 0 {
+		j.queue = nil
+	}
+	return j.write(e, true)
+}
+
+// EndTurn empties the journal once the turn is saved in the session. The
+// queue is kept.
+func (j *Journal) EndTurn() error {
+	if j == nil {
+		return nil
+	}
+	return j.reset()
+}
+
+// reset empties the file and writes the queue back.
+func (j *Journal) reset() error {
+	if err := j.file.Truncate(0); err != nil {
+		return fmt.Errorf("truncating journal: %w", err)
+	}
+	if j.queue == nil {
+		return nil
+	}
+	return j.write(*j.queue, true)
+}
+
+// Close closes and deletes the journal; nothing is left to recover.
+func (j *Journal) Close() error {
+	if j == nil {
+		return nil
+	}
+	err := j.file.Close()
+	if rmErr := os.Remove(j.path); rmErr != nil && !os.IsNotExist(rmErr) && err == nil {
+		err = rmErr
+	}
+	return err
+}
+
+func (j *Journal) write(e journalEntry, sync bool) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("marshaling journal entry: %w", err)
+	}
+	if _, err := j.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("writing journal: %w", err)
+	}
+	if sync {
+		if err := j.file.Sync(); err != nil {
+			return fmt.Errorf("syncing journal: %w", err)
+		}
+	}
+	return nil
+}
+
+// Interrupted is a turn that never finished: what was streamed before the
+// process died.
+type Interrupted struct {
+	SessionID string
+	Prompt    string
+	Text      string // assistant text streamed before the interruption
+	Tools     []InterruptedTool
+	At        time.Time // when the turn started
+	Queue     []string  // prompts that were waiting to run
+	QueueNext int       // leading Queue prompts flagged to run next
+	pid       int
+}
+
+// HasTurn reports whether a turn was in flight; every turn starts with a
+// prompt. Without one, only the queue is left to recover.
+func (in *Interrupted) HasTurn() bool { return in.Prompt != "" }
+
+// InterruptedTool is a tool call of an interrupted turn.
+type InterruptedTool struct {
+	Name    string
+	Args    string
+	Output  string
+	Done    bool
+	IsError bool
+}
+
+// Message renders the interrupted turn as an assistant message: the text,
+// each tool call with its output, and a note that the reply was cut short.
+func (in *Interrupted) Message() ai.Message {
+	var b strings.Builder
+	b.WriteString(strings.TrimSpace(in.Text))
+	if len(in.Tools) > 0 {
+		b.WriteString("\n\n")
+		for _, t := range in.Tools {
+			status := "interrupted"
+			switch {
+			case t.Done && t.IsError:
+				status = "error"
+			case t.Done:
+				status = "done"
+			}
+			fmt.Fprintf(&b, "[%s %s: %s]\n", t.Name, t.Args, status)
+			if out := strings.TrimSpace(t.Output); out != "" {
+				fmt.Fprintf(&b, "```\n%s\n```\n", out)
+			}
+		}
+	}
+	b.WriteString("\n\n(Interrupted: pi-go stopped before this reply finished.)")
+	return ai.NewTextMessage(ai.RoleAssistant, strings.TrimSpace(b.String()))
+}
+
+// ReadJournal replays the journal at path. It returns nil when the journal
+// holds neither a turn nor queued prompts.
+func ReadJournal(path string) (*Interrupted, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening journal: %w", err)
+	}
+	defer f.Close()
+
+	buf := scannerBufPool.Get().([]byte)
+	defer scannerBufPool.Put(buf)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(buf[:0], scannerMaxBuf)
+
+	in := &Interrupted{SessionID: strings.TrimSuffix(filepath.Base(path), journalExt)}
+	var text strings.Builder
+	turn := false
+	open := make(map[string]int) // tool ID -> index in in.Tools
+	for scanner.Scan() {
+		var e journalEntry
+		if json.Unmarshal(scanner.Bytes(), &e) != nil {
+			continue // a torn last line from the crash
+		}
+		switch e.Type {
+		case "turn":
+			in.Prompt, in.pid, in.Tools, turn = e.Prompt, e.PID, nil, true
+			in.At, _ = time.Parse(time.RFC3339, e.TS)
+			text.Reset()
+			clear(open)
+			continue
+		case "queue":
+			in.Queue, in.QueueNext = e.Queue, e.QueueNext
+			if !turn { // a queue alone is dated by its own entry
+				in.pid = e.PID
+				in.At, _ = time.Parse(time.RFC3339, e.TS)
+			}
+			continue
+		}
+		if !turn {
+			continue
+		}
+		switch e.Type {
+		case "text":
+			text.WriteString(e.Text)
+		case "tool_start":
+			open[e.ToolID] = len(in.Tools)
+			in.Tools = append(in.Tools, InterruptedTool{Name: e.Tool, Args: e.Args})
+		case "tool_end":
+			if i, ok := open[e.ToolID]; ok {
+				in.Tools[i].Done, in.Tools[i].IsError, in.Tools[i].Output = true, e.IsError, e.Text
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading journal: %w", err)
+	}
+	if !turn && len(in.Queue) == 0 {
+		return nil, nil
+	}
+	in.Text = text.String()
+	return in, nil
+}
+
+// FindInterrupted returns the newest interrupted turn of a session started
+// in cwd, or nil. Journals of running processes are skipped.
+func FindInterrupted(dir, cwd string) (*Interrupted, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+journalExt))
+	if err != nil {
+		return nil, fmt.Errorf("listing journals: %w", err)
+	}
+	var newest *Interrupted
+	for _, path := range paths {
+		in, err := ReadJournal(path)
+		if err != nil || in == nil {
+			continue
+		}
+		if in.pid != 0 && in.pid != os.Getpid() && processAlive(in.pid) {
+			continue
+		}
+		start, err := readFirstLine(filepath.Join(dir, in.SessionID+".jsonl"))
+		if err != nil || start.CWD != cwd {
+			continue
+		}
+		if newest == nil || in.At.After(newest.At) {
+			newest = in
+		}
+	}
+	return newest, nil
+}
+
+// DiscardJournal deletes the journal of session id under dir.
+func DiscardJournal(dir, id string) error {
+	if !validSessionID.MatchString(id) {
+		return fmt.Errorf("invalid session ID %q: must match [a-zA-Z0-9_-]+", id)
+	}
+	if err := os.Remove(filepath.Join(dir, id+journalExt)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removing journal: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+// ABOUTME: Tests for the crash journal: replaying a turn, clearing it, and finding interrupted sessions
+// ABOUTME: Journals of live processes and other directories are not offered for recovery
+
+package session
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestJournal_ReplaysInterruptedTurn(t *testing.T) {
+	dir := t.TempDir()
+	j, err := OpenJournal(dir, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	must(j.BeginTurn("old prompt"))
+	must(j.Text("discarded"))
+	must(j.BeginTurn("fix the build"))
+	must(j.Text("Let me "))
+	must(j.Text("look."))
+	must(j.ToolStart("t1", "bash", `{"command":"go build"}`))
+	must(j.ToolEnd("t1", "undefined: foo", true))
+	must(j.ToolStart("t2", "read", `{"path":"main.go"}`))
+
+	in, err := ReadJournal(filepath.Join(dir, "s1"+journalExt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if in == nil || in.SessionID != "s1" || in.Prompt != "fix the build" || in.Text != "Let me look." {
+		t.Fatalf("interrupted = %+v", in)
+	}
+	if len(in.Tools) != 2 || !in.Tools[0].Done || !in.Tools[0].IsError || in.Tools[1].Done {
+		t.Errorf("tools = %+v", in.Tools)
+	}
+	text := in.Message().Content[0].Text
+	for _, want := range []string{"Let me look.", `[bash {"command":"go build"}: error]`, "undefined: foo", `[read {"path":"main.go"}: interrupted]`, "(Interrupted:"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("message missing %q:\n%s", want, text)
+		}
+	}
+
+	must(j.EndTurn())
+	if in, _ := ReadJournal(filepath.Join(dir, "s1"+journalExt)); in != nil {
+		t.Errorf("journal should be empty after EndTurn, got %+v", in)
+	}
+	must(j.Close())
+	if _, err := os.Stat(filepath.Join(dir, "s1"+journalExt)); !os.IsNotExist(err) {
+		t.Error("Close should delete the journal")
+	}
+
+	var nilJournal *Journal
+	if err := nilJournal.Text("x"); err != nil {
+		t.Errorf("nil journal: %v", err)
+	}
+}
+
+func TestJournal_KeepsQueueAcrossTurns(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "s1"+journalExt)
+	j, err := OpenJournal(dir, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	must(j.BeginTurn("first"))
+	must(j.Queue([]string{"urgent", "second", "third"}, 1))
+	must(j.EndTurn())
+
+	in, err := ReadJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if in == nil || in.HasTurn() || strings.Join(in.Queue, "|") != "urgent|second|third" || in.QueueNext != 1 {
+		t.Fatalf("after EndTurn: %+v; want the queue alone", in)
+	}
+	if in.pid != os.Getpid() || in.At.IsZero() {
+		t.Errorf("queue alone: pid %d, at %v; want this process and a time", in.pid, in.At)
+	}
+
+	must(j.BeginTurn("urgent"))
+	must(j.Queue([]string{"second", "third"}, 0))
+	in, _ = ReadJournal(path)
+	if in == nil || !in.HasTurn() || in.Prompt != "urgent" || len(in.Queue) != 2 || in.QueueNext != 0 {
+		t.Fatalf("mid-turn: %+v", in)
+	}
+
+	must(j.Queue(nil, 0))
+	must(j.EndTurn())
+	if in, _ := ReadJournal(path); in != nil {
+		t.Errorf("empty queue, no turn: got %+v; want nothing to recover", in)
+	}
+}
+
+// writeCrashedSession saves session id started in cwd with a journal
+// whose turn was written by process pid.
+func writeCrashedSession(t *testing.T, dir, id, cwd string, pid int) {
+	t.Helper()
+	w, err := NewWriterInDir(dir, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if err := w.WriteRecord(RecordSessionStart, SessionStartData{ID: id, CWD: cwd}); err != nil {
+		t.Fatal(err)
+	}
+	line, _ := json.Marshal(journalEntry{Type: "turn", PID: pid, TS: "2026-01-02T03:04:05Z", Prompt: "prompt of " + id})
+	if err := os.WriteFile(filepath.Join(dir, id+journalExt), append(line, '\n'), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFindInterrupted(t *testing.T) {
+	dir := t.TempDir()
+	writeCrashedSession(t, dir, "here", "/work", os.Getpid())
+	writeCrashedSession(t, dir, "elsewhere", "/other", os.Getpid())
+	writeCrashedSession(t, dir, "running", "/work", os.Getppid())
+
+	in, err := FindInterrupted(dir, "/work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if in == nil || in.SessionID != "here" || in.Prompt != "prompt of here" {
+		t.Fatalf("FindInterrupted = %+v; want session here (others are elsewhere or still running)", in)
+	}
+
+	if err := DiscardJournal(dir, "here"); err != nil {
+		t.Fatal(err)
+	}
+	if in, _ := FindInterrupted(dir, "/work"); in != nil {
+		t.Errorf("discarded journal still offered: %+v", in)
+	}
+}
+
+func TestSession_ReopenMovesJournal(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewWriterInDir(dir, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	j, err := OpenJournal(dir, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Session{ID: "a", Writer: w, Journal: j}
+	if err := s.Reopen(dir, "b", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a"+journalExt)); !os.IsNotExist(err) {
+		t.Error("the old journal should be deleted")
+	}
+	if err := s.Journal.BeginTurn("next"); err != nil {
+		t.Fatal(err)
+	}
+	if in, _ := ReadJournal(filepath.Join(dir, "b"+journalExt)); in == nil || in.Prompt != "next" {
+		t.Errorf("turn not journaled for the reopened session: %+v", in)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := RemoveInDir(dir, "b"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "b.jsonl")); !os.IsNotExist(err) {
+		t.Error("RemoveInDir should delete the session file")
+	}
+}
@@ -0,0 +1,17 @@
+// ABOUTME: Unix liveness check for crash journals: signal 0 probes whether the writer still runs
+// ABOUTME: EPERM means the process exists but belongs to another user
+
+//go:build unix
+
+package session
+
+import (
+	"errors"
+	"syscall"
+)
+
+// processAlive reports whether process pid exists.
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -0,0 +1,18 @@
+// ABOUTME: Windows liveness check for crash journals
+// ABOUTME: FindProcess only succeeds for a process that exists
+
+//go:build windows
+
+package session
+
+import "os"
+
+// processAlive reports whether process pid exists.
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	_ = p.Release()
+	return true
+}
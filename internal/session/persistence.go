@@ -45,6 +45,8 @@ const (
 	RecordCheckpoint   RecordType = "checkpoint"
 	RecordCompaction   RecordType = "compaction"
 	RecordBranch       RecordType = "branch"
+	RecordTitle        RecordType = "session_title"
+	RecordUndo         RecordType = "undo"
 	RecordSessionEnd   RecordType = "session_end"
 )
 
@@ -66,9 +68,17 @@ func (r *Record) Unmarshal(v any) error {
 
 // SessionStartData holds session_start metadata.
 type SessionStartData struct {
-	ID    string `json:"id"`
-	Model string `json:"model"`
-	CWD   string `json:"cwd"`
+	ID           string `json:"id"`
+	Model        string `json:"model"`
+	CWD          string `json:"cwd"`
+	ImportedFrom string `json:"imported_from,omitempty"` // source transcript for imported sessions
+	ParentID     string `json:"parent_id,omitempty"`     // session this one was forked from
+	ForkTurn     int    `json:"fork_turn,omitempty"`     // user turns of the parent the fork kept
+
+	// StartedAt is filled from the record envelope (UTC) when listing; not persisted in data.
+	StartedAt time.Time `json:"-"`
+	// Title is filled from the latest session_title record when listing.
+	Title string `json:"-"`
 }
 
 // UserData holds user message data.
@@ -87,11 +97,26 @@ type MentionData struct {
 // AssistantData holds assistant response data.
 type AssistantData struct {
 	Content    string    `json:"content"`
+	Thinking   string    `json:"thinking,omitempty"` // per the thinking retention mode
 	Model      string    `json:"model"`
 	Usage      UsageData `json:"usage"`
 	StopReason string    `json:"stop_reason"`
 }
 
+// ToolCallData holds a tool invocation issued by the assistant.
+type ToolCallData struct {
+	ID   string          `json:"id"`
+	Name string          `json:"name"`
+	Args json.RawMessage `json:"args,omitempty"`
+}
+
+// ToolResultData holds the output of a tool invocation.
+type ToolResultData struct {
+	ID      string `json:"id"`
+	Content string `json:"content"`
+	IsError bool   `json:"is_error,omitempty"`
+}
+
 // UsageData holds token usage.
 type UsageData struct {
 	Input  int `json:"input"`
@@ -125,10 +150,14 @@ func NewWriterFromFile(f *os.File) *Writer {
 
 // NewWriter creates a Writer for the given session ID.
 func NewWriter(sessionID string) (*Writer, error) {
+	return NewWriterInDir(config.SessionsDir(), sessionID)
+}
+
+// NewWriterInDir creates a Writer for the given session ID under dir.
+func NewWriterInDir(dir, sessionID string) (*Writer, error) {
 	if !validSessionID.MatchString(sessionID) {
 		return nil, fmt.Errorf("invalid session ID %q: must match [a-zA-Z0-9_-]+", sessionID)
 	}
-	dir := config.SessionsDir()
 	if err := config.EnsureDir(dir); err != nil {
 		return nil, fmt.Errorf("creating sessions dir: %w", err)
 	}
@@ -144,7 +173,14 @@ func NewWriter(sessionID string) (*Writer, error) {
 
 // WriteRecord appends a record to the session file.
 func (w *Writer) WriteRecord(recType RecordType, data any) error {
-	dataBytes, err := json.Marshal(data)
+	return w.WriteRecordAt(recType, data, time.Now())
+}
+
+// WriteRecordAt appends a record stamped with the given time instead of now.
+// Used when importing transcripts that carry their own timestamps.
+// Payloads are scrubbed first so a bad tool output cannot corrupt the file.
+func (w *Writer) WriteRecordAt(recType RecordType, data any, ts time.Time) error {
+	dataBytes, err := json.Marshal(scrubRecordData(data))
 	if err != nil {
 		return fmt.Errorf("marshaling record data: %w", err)
 	}
@@ -152,7 +188,7 @@ func (w *Writer) WriteRecord(recType RecordType, data any) error {
 	rec := Record{
 		Version: CurrentRecordVersion,
 		Type:    recType,
-		TS:      time.Now().UTC().Format(time.RFC3339),
+		TS:      ts.UTC().Format(time.RFC3339),
 		Data:    dataBytes,
 	}
 
@@ -215,6 +251,25 @@ func ReadRecords(sessionID string) ([]Record, error) {
 	return records, nil
 }
 
+// ReadRecordsInDir reads all records of the session sessionID under dir.
+func ReadRecordsInDir(dir, sessionID string) ([]Record, error) {
+	if !validSessionID.MatchString(sessionID) {
+		return nil, fmt.Errorf("invalid session ID %q: must match [a-zA-Z0-9_-]+", sessionID)
+	}
+	return ReadRecordsFromPath(filepath.Join(dir, sessionID+".jsonl"))
+}
+
+// RemoveInDir deletes session sessionID under dir, with its journal.
+func RemoveInDir(dir, sessionID string) error {
+	if !validSessionID.MatchString(sessionID) {
+		return fmt.Errorf("invalid session ID %q: must match [a-zA-Z0-9_-]+", sessionID)
+	}
+	if err := os.Remove(filepath.Join(dir, sessionID+".jsonl")); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removing session: %w", err)
+	}
+	return DiscardJournal(dir, sessionID)
+}
+
 // ReadRecordsFromPath reads all records from a JSONL file at the given path.
 // It accepts records of any version for backward compatibility.
 func ReadRecordsFromPath(path string) ([]Record, error) {
@@ -250,7 +305,11 @@ func ReadRecordsFromPath(path string) ([]Record, error) {
 
 // ListSessions scans the sessions directory and returns session start records.
 func ListSessions() ([]SessionStartData, error) {
-	dir := config.SessionsDir()
+	return ListSessionsInDir(config.SessionsDir())
+}
+
+// ListSessionsInDir returns the session_start metadata of every session under dir.
+func ListSessionsInDir(dir string) ([]SessionStartData, error) {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -270,6 +329,7 @@ func ListSessions() ([]SessionStartData, error) {
 		if err != nil {
 			continue
 		}
+		data.Title = readTitle(path)
 		sessions = append(sessions, data)
 	}
 	return sessions, nil
@@ -296,5 +356,8 @@ func readFirstLine(path string) (SessionStartData, error) {
 	if err := json.Unmarshal(rec.Data, &start); err != nil {
 		return SessionStartData{}, fmt.Errorf("parsing session start: %w", err)
 	}
+	if ts, err := time.Parse(time.RFC3339, rec.TS); err == nil {
+		start.StartedAt = ts.UTC()
+	}
 	return start, nil
 }
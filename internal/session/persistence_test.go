@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
 )
@@ -56,6 +57,24 @@ func TestWriter_WriteAndRead(t *testing.T) {
 	}
 }
 
+func TestReadFirstLine_StartedAtUTC(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "s.jsonl")
+	line := `{"v":3,"type":"session_start","ts":"2025-06-01T10:30:00Z","data":{"id":"s","model":"m","cwd":"/tmp"}}` + "\n"
+	if err := os.WriteFile(path, []byte(line), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	start, err := readFirstLine(path)
+	if err != nil {
+		t.Fatalf("readFirstLine: %v", err)
+	}
+	want := time.Date(2025, 6, 1, 10, 30, 0, 0, time.UTC)
+	if !start.StartedAt.Equal(want) || start.StartedAt.Location() != time.UTC {
+		t.Errorf("StartedAt = %v, want %v", start.StartedAt, want)
+	}
+}
+
 func TestSessionID_Validation(t *testing.T) {
 	t.Parallel()
 
@@ -197,6 +216,26 @@ func TestReadRecordsFromPath_BackwardCompat_V1(t *testing.T) {
 	}
 }
 
+func TestReadRecordsInDir(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewWriterInDir(dir, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRecord(RecordUser, UserData{Content: "hi"}); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
+	recs, err := ReadRecordsInDir(dir, "s1")
+	if err != nil || len(recs) != 1 || recs[0].Type != RecordUser {
+		t.Errorf("records = %+v, %v; want one user record", recs, err)
+	}
+	if _, err := ReadRecordsInDir(dir, "../etc/passwd"); err == nil {
+		t.Error("expected an error for an invalid session ID")
+	}
+}
+
 func TestCurrentRecordVersion_IsThree(t *testing.T) {
 	t.Parallel()
 	if CurrentRecordVersion != 3 {
@@ -0,0 +1,34 @@
+// ABOUTME: System reminders: transient notes to the model carried as ephemeral user messages
+// ABOUTME: Wrapped in <system-reminder> tags; dropped before compaction summarizes the history
+
+package session
+
+import (
+	"strings"
+
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+// NewReminder returns an ephemeral user message holding texts, one per
+// line, inside <system-reminder> tags.
+func NewReminder(texts ...string) ai.Message {
+	msg := ai.NewTextMessage(ai.RoleUser,
+		"<system-reminder>\n"+strings.Join(texts, "\n")+"\n</system-reminder>")
+	msg.Ephemeral = true
+	return msg
+}
+
+// DropEphemeral returns messages without the ephemeral ones and how many
+// were dropped. The input slice is not modified.
+func DropEphemeral(messages []ai.Message) ([]ai.Message, int) {
+	kept := make([]ai.Message, 0, len(messages))
+	for _, msg := range messages {
+		if !msg.Ephemeral {
+			kept = append(kept, msg)
+		}
+	}
+	if len(kept) == len(messages) {
+		return messages, 0
+	}
+	return kept, len(messages) - len(kept)
+}
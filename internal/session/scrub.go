@@ -0,0 +1,94 @@
+// ABOUTME: Session scrubber applied before persistence: strips ANSI from tool output, fixes UTF-8
+// ABOUTME: Drops empty content blocks and invalid raw JSON so saved sessions always resume/export
+
+package session
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/width"
+)
+
+// ScrubText replaces invalid UTF-8 with U+FFFD and removes NUL bytes.
+func ScrubText(s string) string {
+	if !utf8.ValidString(s) {
+		s = strings.ToValidUTF8(s, "\uFFFD")
+	}
+	if strings.IndexByte(s, 0) >= 0 {
+		s = strings.ReplaceAll(s, "\x00", "")
+	}
+	return s
+}
+
+// ScrubToolOutput strips ANSI escape sequences and then applies ScrubText.
+func ScrubToolOutput(s string) string {
+	return ScrubText(width.StripANSI(s))
+}
+
+// ScrubContent returns a cleaned copy of blocks: text fields are normalized,
+// tool results lose ANSI escapes, invalid tool inputs are dropped, and blocks
+// that carry no payload at all are removed.
+func ScrubContent(blocks []ai.Content) []ai.Content {
+	out := make([]ai.Content, 0, len(blocks))
+	for _, c := range blocks {
+		c.Text = ScrubText(c.Text)
+		if thinking := ScrubText(c.Thinking); thinking != c.Thinking {
+			// The provider's signature covers the original thinking only
+			c.Thinking, c.Signature = thinking, ""
+		}
+		c.ResultText = ScrubToolOutput(c.ResultText)
+		if len(c.Input) > 0 && !json.Valid(c.Input) {
+			c.Input = nil
+		}
+		if isEmptyBlock(c) {
+			continue
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// isEmptyBlock reports whether a content block has nothing worth persisting.
+// Tool use/result blocks are kept even when empty since their IDs pair up.
+func isEmptyBlock(c ai.Content) bool {
+	switch c.Type {
+	case ai.ContentText:
+		return c.Text == ""
+	case ai.ContentThinking:
+		return c.Thinking == ""
+	case ai.ContentImage:
+		return c.Data == ""
+	default:
+		return false
+	}
+}
+
+// scrubRecordData cleans known record payloads before they are marshaled.
+// Unknown types pass through unchanged.
+func scrubRecordData(data any) any {
+	switch d := data.(type) {
+	case UserData:
+		d.Content = ScrubText(d.Content)
+		return d
+	case AssistantData:
+		d.Content = ScrubText(d.Content)
+		d.Thinking = ScrubText(d.Thinking)
+		return d
+	case ToolCallData:
+		if len(d.Args) > 0 && !json.Valid(d.Args) {
+			d.Args = nil
+		}
+		return d
+	case ToolResultData:
+		d.Content = ScrubToolOutput(d.Content)
+		return d
+	case CompactionData:
+		d.Summary = ScrubText(d.Summary)
+		return d
+	default:
+		return data
+	}
+}
@@ -0,0 +1,97 @@
+// ABOUTME: Tests for the pre-persistence session scrubber
+// ABOUTME: Covers ANSI stripping, UTF-8 normalization, empty block removal, and writer integration
+
+package session
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+func TestScrubText_InvalidUTF8AndNUL(t *testing.T) {
+	t.Parallel()
+
+	got := ScrubText("ok\xff\xfe\x00done")
+	if got != "ok�done" {
+		t.Errorf("ScrubText() = %q", got)
+	}
+}
+
+func TestScrubToolOutput_StripsANSI(t *testing.T) {
+	t.Parallel()
+
+	got := ScrubToolOutput("\x1b[31mFAIL\x1b[0m pkg\x1b]0;title\x07")
+	if got != "FAIL pkg" {
+		t.Errorf("ScrubToolOutput() = %q", got)
+	}
+}
+
+func TestScrubContent_DropsEmptyBlocks(t *testing.T) {
+	t.Parallel()
+
+	in := []ai.Content{
+		{Type: ai.ContentText, Text: ""},
+		{Type: ai.ContentThinking},
+		{Type: ai.ContentText, Text: "keep"},
+		{Type: ai.ContentToolUse, ID: "t1", Name: "bash", Input: json.RawMessage(`{bad`)},
+		{Type: ai.ContentToolResult, ID: "t1", ResultText: "\x1b[1mbold\x1b[0m"},
+	}
+	got := ScrubContent(in)
+	if len(got) != 3 {
+		t.Fatalf("got %d blocks, want 3: %+v", len(got), got)
+	}
+	if got[0].Text != "keep" {
+		t.Errorf("first block = %+v", got[0])
+	}
+	if got[1].Input != nil {
+		t.Errorf("invalid tool input should be dropped, got %s", got[1].Input)
+	}
+	if got[2].ResultText != "bold" {
+		t.Errorf("tool result = %q, want ANSI stripped", got[2].ResultText)
+	}
+	if in[4].ResultText != "\x1b[1mbold\x1b[0m" {
+		t.Error("ScrubContent must not mutate its input")
+	}
+}
+
+func TestWriter_ScrubsBeforeSave(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	w, err := NewWriterInDir(dir, "scrub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRecord(RecordToolResult, ToolResultData{ID: "t1", Content: "\x1b[32mok\x1b[0m\xff"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRecord(RecordToolCall, ToolCallData{ID: "t1", Name: "bash", Args: json.RawMessage(`{"cmd":`)}); err != nil {
+		t.Fatalf("invalid args should be scrubbed, not fail: %v", err)
+	}
+	w.Close()
+
+	records, err := ReadRecordsFromPath(filepath.Join(dir, "scrub.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+	var res ToolResultData
+	if err := records[0].Unmarshal(&res); err != nil {
+		t.Fatal(err)
+	}
+	if res.Content != "ok�" {
+		t.Errorf("persisted content = %q", res.Content)
+	}
+
+	raw, _ := os.ReadFile(filepath.Join(dir, "scrub.jsonl"))
+	if strings.Contains(string(raw), "\\u001b") {
+		t.Error("ANSI escapes leaked into the session file")
+	}
+}
@@ -0,0 +1,419 @@
+// ABOUTME: Full-text search across session JSONL files with case-insensitive line matches and context
+// ABOUTME: A per-file trigram bloom filter in .search-index.json skips sessions that cannot match
+
+package session
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// searchIndexFile holds the bloom filters, next to the session files.
+const searchIndexFile = ".search-index.json"
+
+// searchIndexVersion is bumped when the bloom layout changes.
+const searchIndexVersion = 1
+
+// maxSnippet bounds the length of a printed match line.
+const maxSnippet = 160
+
+// SearchOptions tunes Search.
+type SearchOptions struct {
+	Context    int // lines of context before and after each matching line
+	Limit      int // stop after this many matches; 0 = no limit
+	PerSession int // matches kept per session; 0 = no limit
+}
+
+// SearchMatch is one matching line in a session record.
+type SearchMatch struct {
+	SessionID string
+	Line      int // 1-based line of the record in the JSONL file
+	Type      RecordType
+	TS        time.Time
+	Text      string   // the matching line, clipped around the match
+	Before    []string // context lines before Text
+	After     []string // context lines after Text
+}
+
+// Search finds query, case-insensitively, in the messages, tool calls, tool
+// results, and compaction summaries of the sessions under dir. Sessions are
+// searched newest first; matches never span lines.
+func Search(dir, query string, opts SearchOptions) ([]SearchMatch, error) {
+	q := strings.ToLower(strings.TrimSpace(query))
+	if q == "" {
+		return nil, fmt.Errorf("empty search query")
+	}
+	files, err := sessionFilesByAge(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	idx := loadSearchIndex(dir)
+	dirty := false
+	grams := trigrams(q)
+
+	var matches []SearchMatch
+	for _, f := range files {
+		entry, fresh := idx.Files[f.name]
+		fresh = fresh && entry.Size == f.size && entry.ModTime == f.modTime
+		if fresh && !entry.mayContain(grams) {
+			continue
+		}
+
+		limit := opts.PerSession
+		if opts.Limit > 0 && (limit == 0 || opts.Limit-len(matches) < limit) {
+			limit = opts.Limit - len(matches)
+		}
+		found, bloom, err := scanSession(filepath.Join(dir, f.name), q, opts.Context, limit, !fresh)
+		if err != nil {
+			continue
+		}
+		if bloom != nil {
+			idx.Files[f.name] = indexEntry{Size: f.size, ModTime: f.modTime, Bloom: bloom}
+			dirty = true
+		}
+		matches = append(matches, found...)
+		if opts.Limit > 0 && len(matches) >= opts.Limit {
+			break
+		}
+	}
+
+	if dirty {
+		idx.prune(files)
+		// The index is a cache; failing to save it only costs speed.
+		_ = idx.save(dir)
+	}
+	return matches, nil
+}
+
+// SearchSessionIDs returns the IDs of the sessions under dir containing query.
+func SearchSessionIDs(dir, query string) (map[string]bool, error) {
+	matches, err := Search(dir, query, SearchOptions{PerSession: 1})
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]bool, len(matches))
+	for _, m := range matches {
+		ids[m.SessionID] = true
+	}
+	return ids, nil
+}
+
+// sessionFile is a session JSONL file with the stat fields the index keys on.
+type sessionFile struct {
+	name    string
+	size    int64
+	modTime int64
+}
+
+// sessionFilesByAge lists the session files under dir, newest first.
+func sessionFilesByAge(dir string) ([]sessionFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading sessions dir: %w", err)
+	}
+	var files []sessionFile
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".jsonl" {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, sessionFile{name: e.Name(), size: info.Size(), modTime: info.ModTime().UnixNano()})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime > files[j].modTime })
+	return files, nil
+}
+
+// scanSession streams the session at path and returns up to limit matches
+// (0 = all). With buildIndex it reads the whole file and also returns the
+// bloom filter of its searchable text.
+func scanSession(path, q string, context, limit int, buildIndex bool) ([]SearchMatch, []byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	id := strings.TrimSuffix(filepath.Base(path), ".jsonl")
+	var grams map[uint64]struct{}
+	if buildIndex {
+		grams = make(map[uint64]struct{})
+	}
+
+	buf := scannerBufPool.Get().([]byte)
+	defer scannerBufPool.Put(buf)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(buf[:0], scannerMaxBuf)
+
+	var matches []SearchMatch
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		if limit > 0 && len(matches) >= limit && !buildIndex {
+			break
+		}
+		var rec Record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			continue
+		}
+		text := searchableText(&rec)
+		if text == "" {
+			continue
+		}
+		lower := strings.ToLower(text)
+		if grams != nil {
+			addTrigrams(grams, lower)
+		}
+		if (limit > 0 && len(matches) >= limit) || !strings.Contains(lower, q) {
+			continue
+		}
+		ts, _ := time.Parse(time.RFC3339, rec.TS)
+		lines := strings.Split(text, "\n")
+		for i, line := range lines {
+			if limit > 0 && len(matches) >= limit {
+				break
+			}
+			if !strings.Contains(strings.ToLower(line), q) {
+				continue
+			}
+			matches = append(matches, SearchMatch{
+				SessionID: id,
+				Line:      lineNum,
+				Type:      rec.Type,
+				TS:        ts,
+				Text:      clipAround(line, q),
+				Before:    clipLines(lines[max(i-context, 0):i]),
+				After:     clipLines(lines[i+1 : min(i+1+context, len(lines))]),
+			})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return matches, nil, fmt.Errorf("scanning %s: %w", path, err)
+	}
+	if grams == nil {
+		return matches, nil, nil
+	}
+	return matches, newBloom(grams), nil
+}
+
+// searchableText returns the text of a record that search looks at.
+func searchableText(rec *Record) string {
+	switch rec.Type {
+	case RecordUser:
+		var d UserData
+		if rec.Unmarshal(&d) == nil {
+			return d.Content
+		}
+	case RecordAssistant:
+		var d AssistantData
+		if rec.Unmarshal(&d) == nil {
+			return strings.TrimSpace(d.Thinking + "\n" + d.Content)
+		}
+	case RecordToolCall:
+		var d ToolCallData
+		if rec.Unmarshal(&d) == nil {
+			return d.Name + " " + string(d.Args)
+		}
+	case RecordToolResult:
+		var d ToolResultData
+		if rec.Unmarshal(&d) == nil {
+			return d.Content
+		}
+	case RecordCompaction:
+		var d CompactionData
+		if rec.Unmarshal(&d) == nil {
+			return d.Summary
+		}
+	}
+	return ""
+}
+
+// clipAround trims line to maxSnippet bytes centred on the first match of q.
+func clipAround(line, q string) string {
+	line = strings.TrimSpace(line)
+	if len(line) <= maxSnippet {
+		return line
+	}
+	at := strings.Index(strings.ToLower(line), q)
+	start := max(at-(maxSnippet-len(q))/2, 0)
+	end := min(start+maxSnippet, len(line))
+	start = max(end-maxSnippet, 0)
+	for start > 0 && !isRuneStart(line[start]) {
+		start--
+	}
+	for end < len(line) && !isRuneStart(line[end]) {
+		end++
+	}
+	out := line[start:end]
+	if start > 0 {
+		out = "…" + out
+	}
+	if end < len(line) {
+		out += "…"
+	}
+	return out
+}
+
+// clipLines trims each context line to maxSnippet bytes.
+func clipLines(lines []string) []string {
+	if len(lines) == 0 {
+		return nil
+	}
+	out := make([]string, len(lines))
+	for i, l := range lines {
+		out[i] = clipAround(l, "")
+	}
+	return out
+}
+
+func isRuneStart(b byte) bool { return b&0xC0 != 0x80 }
+
+// --- Trigram bloom index ---
+
+// searchIndex maps session file names to the bloom filters of their text.
+type searchIndex struct {
+	Version int                   `json:"version"`
+	Files   map[string]indexEntry `json:"files"`
+}
+
+// indexEntry is valid while the file keeps the recorded size and mtime.
+type indexEntry struct {
+	Size    int64  `json:"size"`
+	ModTime int64  `json:"mtime"`
+	Bloom   []byte `json:"bloom"`
+}
+
+// loadSearchIndex reads the index under dir, or returns an empty one.
+func loadSearchIndex(dir string) *searchIndex {
+	idx := &searchIndex{Version: searchIndexVersion, Files: make(map[string]indexEntry)}
+	data, err := os.ReadFile(filepath.Join(dir, searchIndexFile))
+	if err != nil {
+		return idx
+	}
+	var loaded searchIndex
+	if json.Unmarshal(data, &loaded) != nil || loaded.Version != searchIndexVersion || loaded.Files == nil {
+		return idx
+	}
+	return &loaded
+}
+
+// prune drops entries for session files that no longer exist.
+func (idx *searchIndex) prune(files []sessionFile) {
+	live := make(map[string]bool, len(files))
+	for _, f := range files {
+		live[f.name] = true
+	}
+	for name := range idx.Files {
+		if !live[name] {
+			delete(idx.Files, name)
+		}
+	}
+}
+
+// save writes the index atomically.
+func (idx *searchIndex) save(dir string) error {
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return fmt.Errorf("marshalling search index: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, searchIndexFile+".*")
+	if err != nil {
+		return fmt.Errorf("writing search index: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing search index: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing search index: %w", err)
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dir, searchIndexFile))
+}
+
+// Bloom filter sizing: about ten bits per trigram, which keeps false
+// positives near 1% with three probes, between 128 bytes and 64KB.
+const (
+	bloomBitsPerGram = 10
+	bloomMinBits     = 1 << 10
+	bloomMaxBits     = 1 << 19
+	bloomProbes      = 3
+)
+
+// trigrams returns the hashes of the byte trigrams of s.
+func trigrams(s string) []uint64 {
+	var out []uint64
+	for i := 0; i+3 <= len(s); i++ {
+		out = append(out, hashGram(s[i:i+3]))
+	}
+	return out
+}
+
+func addTrigrams(set map[uint64]struct{}, s string) {
+	for i := 0; i+3 <= len(s); i++ {
+		set[hashGram(s[i:i+3])] = struct{}{}
+	}
+}
+
+func hashGram(g string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(g))
+	return h.Sum64()
+}
+
+// newBloom builds a bloom filter over the trigram hashes in set. The bit
+// count is a power of two so probes can mask instead of divide.
+func newBloom(set map[uint64]struct{}) []byte {
+	bits := bloomMinBits
+	for bits < len(set)*bloomBitsPerGram && bits < bloomMaxBits {
+		bits <<= 1
+	}
+	bloom := make([]byte, bits/8)
+	for h := range set {
+		for _, bit := range bloomBits(h, bits) {
+			bloom[bit/8] |= 1 << (bit % 8)
+		}
+	}
+	return bloom
+}
+
+// mayContain reports whether the file can contain text with every trigram
+// in grams. Queries shorter than a trigram always may.
+func (e indexEntry) mayContain(grams []uint64) bool {
+	bits := len(e.Bloom) * 8
+	if bits == 0 {
+		return true
+	}
+	for _, h := range grams {
+		for _, bit := range bloomBits(h, bits) {
+			if e.Bloom[bit/8]&(1<<(bit%8)) == 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// bloomBits derives the probe positions of h by double hashing.
+func bloomBits(h uint64, bits int) [bloomProbes]int {
+	h1, h2 := uint32(h), uint32(h>>32)|1
+	var out [bloomProbes]int
+	for i := range out {
+		out[i] = int((h1 + uint32(i)*h2) & uint32(bits-1))
+	}
+	return out
+}
@@ -0,0 +1,169 @@
+// ABOUTME: Tests for full-text session search, its trigram bloom index, and the search subcommand
+// ABOUTME: Sessions are written to temp dirs with NewWriterInDir
+
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeSearchSession writes a session with the given records to dir.
+func writeSearchSession(t *testing.T, dir, id string, recs ...func(*Writer) error) {
+	t.Helper()
+	w, err := NewWriterInDir(dir, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if err := w.WriteRecord(RecordSessionStart, SessionStartData{ID: id, Model: "m"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, rec := range recs {
+		if err := rec(w); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func userText(text string) func(*Writer) error {
+	return func(w *Writer) error { return w.WriteRecord(RecordUser, UserData{Content: text}) }
+}
+
+func TestSearch_FindsLinesWithContext(t *testing.T) {
+	dir := t.TempDir()
+	writeSearchSession(t, dir, "s1",
+		userText("first line\nthe Flaky test again\nlast line"),
+		func(w *Writer) error {
+			return w.WriteRecord(RecordToolCall, ToolCallData{ID: "t1", Name: "bash", Args: json.RawMessage(`{"command":"go test -run Flaky"}`)})
+		},
+		func(w *Writer) error {
+			return w.WriteRecord(RecordToolResult, ToolResultData{ID: "t1", Content: "ok"})
+		},
+	)
+	writeSearchSession(t, dir, "s2", userText("nothing relevant"))
+
+	matches, err := Search(dir, "flaky", SearchOptions{Context: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 2 {
+		t.Fatalf("matches = %+v; want 2", matches)
+	}
+	m := matches[0]
+	if m.SessionID != "s1" || m.Type != RecordUser || m.Line != 2 || m.Text != "the Flaky test again" {
+		t.Errorf("first match = %+v", m)
+	}
+	if len(m.Before) != 1 || m.Before[0] != "first line" || len(m.After) != 1 || m.After[0] != "last line" {
+		t.Errorf("context = %q / %q", m.Before, m.After)
+	}
+	if matches[1].Type != RecordToolCall {
+		t.Errorf("second match type = %s; want tool_call", matches[1].Type)
+	}
+}
+
+func TestSearch_LimitsAndPerSession(t *testing.T) {
+	dir := t.TempDir()
+	writeSearchSession(t, dir, "a", userText("x needle"), userText("y needle"))
+	writeSearchSession(t, dir, "b", userText("z needle"))
+
+	all, _ := Search(dir, "needle", SearchOptions{})
+	if len(all) != 3 {
+		t.Errorf("unlimited = %d matches; want 3", len(all))
+	}
+	limited, _ := Search(dir, "needle", SearchOptions{Limit: 2})
+	if len(limited) != 2 {
+		t.Errorf("limited = %d matches; want 2", len(limited))
+	}
+	ids, err := SearchSessionIDs(dir, "NEEDLE")
+	if err != nil || len(ids) != 2 || !ids["a"] || !ids["b"] {
+		t.Errorf("SearchSessionIDs = %v, %v", ids, err)
+	}
+}
+
+func TestSearch_IndexSkipsAndRefreshes(t *testing.T) {
+	dir := t.TempDir()
+	writeSearchSession(t, dir, "s1", userText("alpha beta"))
+
+	if got, _ := Search(dir, "gamma", SearchOptions{}); len(got) != 0 {
+		t.Fatalf("unexpected matches %+v", got)
+	}
+	idx := loadSearchIndex(dir)
+	entry, ok := idx.Files["s1.jsonl"]
+	if !ok || len(entry.Bloom) == 0 {
+		t.Fatalf("index entry missing after search: %+v", idx)
+	}
+	if entry.mayContain(trigrams("gamma")) {
+		t.Error("bloom should rule out a trigram-disjoint query")
+	}
+	if !entry.mayContain(trigrams("beta")) {
+		t.Error("bloom must not rule out text the session contains")
+	}
+
+	// Appending changes size and mtime, so the stale entry is rebuilt.
+	w, err := NewWriterInDir(dir, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.WriteRecord(RecordUser, UserData{Content: "now gamma too"})
+	w.Close()
+	later := time.Now().Add(time.Second)
+	os.Chtimes(filepath.Join(dir, "s1.jsonl"), later, later)
+
+	if got, _ := Search(dir, "gamma", SearchOptions{}); len(got) != 1 {
+		t.Errorf("appended text not found: %+v", got)
+	}
+
+	os.Remove(filepath.Join(dir, "s1.jsonl"))
+	writeSearchSession(t, dir, "s2", userText("delta"))
+	Search(dir, "delta", SearchOptions{})
+	if _, ok := loadSearchIndex(dir).Files["s1.jsonl"]; ok {
+		t.Error("index should drop deleted sessions")
+	}
+}
+
+func TestSearch_EmptyQuery(t *testing.T) {
+	if _, err := Search(t.TempDir(), "  ", SearchOptions{}); err == nil {
+		t.Error("expected an error for an empty query")
+	}
+}
+
+func TestClipAround(t *testing.T) {
+	line := strings.Repeat("a", 300) + "NEEDLE" + strings.Repeat("b", 300)
+	got := clipAround(line, "needle")
+	if !strings.Contains(got, "NEEDLE") || !strings.HasPrefix(got, "…") || !strings.HasSuffix(got, "…") {
+		t.Errorf("clipAround = %q", got)
+	}
+	if len(got) > maxSnippet+2*len("…") {
+		t.Errorf("clipped length %d exceeds %d", len(got), maxSnippet)
+	}
+}
+
+func TestRunSearch_PrintsMatches(t *testing.T) {
+	dir := t.TempDir()
+	writeSearchSession(t, dir, "s1", userText("before\nuse the retry budget\nafter"))
+
+	var out bytes.Buffer
+	if err := runSearch([]string{"-C", "1", "retry", "budget"}, dir, &out); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"s1", "user (line 2)", "    before", "  > use the retry budget", "    after"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	runSearch([]string{"absent"}, dir, &out)
+	if !strings.Contains(out.String(), `no matches for "absent"`) {
+		t.Errorf("output = %q", out.String())
+	}
+	if err := runSearch(nil, dir, &out); err == nil {
+		t.Error("expected usage error without a query")
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/mauromedda/pi-coding-agent-go/internal/config"
 	"github.com/mauromedda/pi-coding-agent-go/internal/perf"
 	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
 )
@@ -23,11 +24,20 @@ type Session struct {
 	ContextWindow int                // model's context window size in tokens
 	Compaction    CompactionConfig   // compaction settings
 	Profile       *perf.ModelProfile // runtime model profile (set after probe)
+	ThinkingMode  string             // thinking retention for records; "" summarizes
+	Title         string             // latest title; "" until generated or set
+	TitleSet      bool               // Title was set by the user; generated titles leave it
+	Journal       *Journal           // in-flight turn for crash recovery; nil = not journaled
 }
 
 // NewSession creates a new session with the given model and provider.
 func NewSession(id string, model *ai.Model, provider ai.ApiProvider, cwd string) (*Session, error) {
-	writer, err := NewWriter(id)
+	return newSessionInDir(config.SessionsDir(), id, model, provider, cwd)
+}
+
+// newSessionInDir creates session id under dir.
+func newSessionInDir(dir, id string, model *ai.Model, provider ai.ApiProvider, cwd string) (*Session, error) {
+	writer, err := NewWriterInDir(dir, id)
 	if err != nil {
 		return nil, fmt.Errorf("creating session writer: %w", err)
 	}
@@ -52,6 +62,47 @@ func NewSession(id string, model *ai.Model, provider ai.ApiProvider, cwd string)
 	return s, nil
 }
 
+// Start creates and saves a new session under a fresh random ID.
+func Start(model *ai.Model, provider ai.ApiProvider, cwd string) (*Session, error) {
+	id, err := generateSessionID()
+	if err != nil {
+		return nil, fmt.Errorf("generating session ID: %w", err)
+	}
+	return NewSession(id, model, provider, cwd)
+}
+
+// StartInDir is Start for sessions saved under dir.
+func StartInDir(dir string, model *ai.Model, provider ai.ApiProvider, cwd string) (*Session, error) {
+	id, err := generateSessionID()
+	if err != nil {
+		return nil, fmt.Errorf("generating session ID: %w", err)
+	}
+	return newSessionInDir(dir, id, model, provider, cwd)
+}
+
+// Reopen points the session at the existing session id under dir, as after
+// a fork: later records are appended there and Messages is replaced. The
+// title is reset; callers restore it with TitleFromRecords. A journaled
+// session gets a fresh journal for id.
+func (s *Session) Reopen(dir, id string, messages []ai.Message) error {
+	w, err := NewWriterInDir(dir, id)
+	if err != nil {
+		return fmt.Errorf("opening session %s: %w", id, err)
+	}
+	if s.Writer != nil {
+		_ = s.Writer.Close()
+	}
+	if s.Journal != nil {
+		_ = s.Journal.Close()
+		if s.Journal, err = OpenJournal(dir, id); err != nil {
+			s.Journal = nil
+		}
+	}
+	s.ID, s.Writer, s.Messages = id, w, messages
+	s.Title, s.TitleSet = "", false
+	return nil
+}
+
 // AddUserMessage appends a user message and persists it.
 func (s *Session) AddUserMessage(content string) error {
 	msg := ai.NewTextMessage(ai.RoleUser, content)
@@ -60,7 +111,8 @@ func (s *Session) AddUserMessage(content string) error {
 	return s.Writer.WriteRecord(RecordUser, UserData{Content: content})
 }
 
-// AddAssistantMessage appends an assistant message and persists it.
+// AddAssistantMessage appends an assistant message and persists it. The
+// record keeps thinking only as allowed by s.ThinkingMode.
 func (s *Session) AddAssistantMessage(msg *ai.AssistantMessage) error {
 	// Extract text content
 	var text strings.Builder
@@ -72,11 +124,12 @@ func (s *Session) AddAssistantMessage(msg *ai.AssistantMessage) error {
 
 	s.Messages = append(s.Messages, ai.Message{
 		Role:    ai.RoleAssistant,
-		Content: msg.Content,
+		Content: ScrubContent(msg.Content),
 	})
 
 	return s.Writer.WriteRecord(RecordAssistant, AssistantData{
 		Content:    text.String(),
+		Thinking:   thinkingText(RetainThinking(msg.Content, s.ThinkingMode)),
 		Model:      msg.Model,
 		Usage:      UsageData{Input: msg.Usage.InputTokens, Output: msg.Usage.OutputTokens},
 		StopReason: string(msg.StopReason),
@@ -147,7 +200,8 @@ func buildFromCompaction(records []Record, compactionIdx int) ([]ai.Message, err
 	return msgs, nil
 }
 
-// buildFromAll converts user and assistant records into ai.Messages.
+// buildFromAll converts user and assistant records into ai.Messages. An
+// undo record drops the turn before it.
 func buildFromAll(records []Record) ([]ai.Message, error) {
 	var msgs []ai.Message
 	for _, rec := range records {
@@ -164,11 +218,36 @@ func buildFromAll(records []Record) ([]ai.Message, error) {
 				return nil, fmt.Errorf("unmarshaling assistant data: %w", err)
 			}
 			msgs = append(msgs, ai.NewTextMessage(ai.RoleAssistant, ad.Content))
+		case RecordUndo:
+			msgs = dropLastTurn(msgs)
 		}
 	}
 	return msgs, nil
 }
 
+// UndoTurn drops the last user message and the replies after it, and
+// records the undo so a resumed session leaves the turn out too. It
+// reports whether there was a turn to drop.
+func (s *Session) UndoTurn() (bool, error) {
+	msgs := dropLastTurn(s.Messages)
+	if len(msgs) == len(s.Messages) {
+		return false, nil
+	}
+	s.Messages = msgs
+	return true, s.Writer.WriteRecord(RecordUndo, nil)
+}
+
+// dropLastTurn cuts msgs before the last user message; msgs without one
+// are returned unchanged.
+func dropLastTurn(msgs []ai.Message) []ai.Message {
+	for i := len(msgs) - 1; i >= 0; i-- {
+		if msgs[i].Role == ai.RoleUser {
+			return msgs[:i]
+		}
+	}
+	return msgs
+}
+
 // BuildContext creates the LLM context from current session state.
 func (s *Session) BuildContext(systemPrompt string) *ai.Context {
 	return &ai.Context{
@@ -193,7 +272,9 @@ func (s *Session) Stream(ctx context.Context, systemPrompt string, tools []ai.To
 	return s.Provider.Stream(ctx, s.Model, llmCtx, opts)
 }
 
-// Close closes the session writer.
+// Close closes the session writer and deletes its journal: the session
+// ended cleanly.
 func (s *Session) Close() error {
+	_ = s.Journal.Close()
 	return s.Writer.Close()
 }
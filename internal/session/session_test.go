@@ -0,0 +1,73 @@
+// ABOUTME: Tests for Session turn handling: undoing a turn in memory and in the saved records
+// ABOUTME: A resumed session rebuilt from records leaves undone turns out
+
+package session
+
+import (
+	"testing"
+
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+func TestSession_UndoTurn(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	w, err := NewWriterInDir(dir, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Session{ID: "s1", Writer: w}
+	if undone, err := s.UndoTurn(); undone || err != nil {
+		t.Fatalf("UndoTurn on an empty session = %v, %v", undone, err)
+	}
+	_ = s.AddUserMessage("first")
+	_ = s.AddAssistantMessage(&ai.AssistantMessage{Content: []ai.Content{{Type: ai.ContentText, Text: "one"}}})
+	_ = s.AddUserMessage("second")
+	_ = s.AddAssistantMessage(&ai.AssistantMessage{Content: []ai.Content{{Type: ai.ContentText, Text: "two"}}})
+
+	if undone, err := s.UndoTurn(); !undone || err != nil {
+		t.Fatalf("UndoTurn = %v, %v", undone, err)
+	}
+	if len(s.Messages) != 2 {
+		t.Errorf("Messages = %d; want the first turn only", len(s.Messages))
+	}
+	_ = s.AddUserMessage("third")
+	_ = s.Close()
+
+	records, _ := ReadRecordsInDir(dir, "s1")
+	msgs, err := BuildSessionContext(records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var texts []string
+	for _, m := range msgs {
+		texts = append(texts, m.Content[0].Text)
+	}
+	if len(texts) != 3 || texts[0] != "first" || texts[1] != "one" || texts[2] != "third" {
+		t.Errorf("rebuilt messages = %q; want the undone turn left out", texts)
+	}
+}
+
+func TestStartInDir(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	a, err := StartInDir(dir, &ai.Model{ID: "m"}, nil, "/work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := StartInDir(dir, &ai.Model{ID: "m"}, nil, "/work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = a.Close()
+	_ = b.Close()
+	if a.ID == b.ID {
+		t.Fatalf("two sessions share ID %q", a.ID)
+	}
+	records, err := ReadRecordsInDir(dir, a.ID)
+	if err != nil || len(records) == 0 || records[0].Type != RecordSessionStart {
+		t.Errorf("records in dir = %+v, %v; want a session start", records, err)
+	}
+}
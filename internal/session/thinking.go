@@ -0,0 +1,103 @@
+// ABOUTME: Thinking retention: keeps, summarizes, or strips reasoning content before it is stored
+// ABOUTME: Applied to session records and exports; the live conversation keeps thinking untouched
+
+package session
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/config"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+// thinkingSummaryBytes caps the extract kept by summarized retention.
+const thinkingSummaryBytes = 200
+
+// SummarizeThinking returns the first sentence of text, capped at
+// thinkingSummaryBytes, followed by a note of how much was omitted.
+// A single short sentence is returned unchanged.
+func SummarizeThinking(text string) string {
+	text = strings.TrimSpace(text)
+	head := text[:sentenceEnd(text)]
+	if len(head) > thinkingSummaryBytes {
+		head = head[:thinkingSummaryBytes]
+		for len(head) > 0 && !utf8.RuneStart(text[len(head)]) {
+			head = head[:len(head)-1]
+		}
+	}
+	head = strings.TrimSpace(head)
+	if head == text {
+		return text
+	}
+	return fmt.Sprintf("%s [%d more bytes of thinking omitted]", head, len(text)-len(head))
+}
+
+// sentenceEnd returns the length of the first sentence of text: through the
+// first . ! or ? followed by whitespace, or up to the first newline, so
+// "main.go" is not a sentence break.
+func sentenceEnd(text string) int {
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '\n':
+			return i
+		case '.', '!', '?':
+			if i+1 == len(text) || text[i+1] == ' ' || text[i+1] == '\n' || text[i+1] == '\t' {
+				return i + 1
+			}
+		}
+	}
+	return len(text)
+}
+
+// RetainThinking returns blocks with thinking handled per mode: kept for
+// config.ThinkingFull, dropped for config.ThinkingStripped, and summarized
+// otherwise. The input slice is not modified.
+func RetainThinking(blocks []ai.Content, mode string) []ai.Content {
+	if mode == config.ThinkingFull {
+		return blocks
+	}
+	out := make([]ai.Content, 0, len(blocks))
+	for _, c := range blocks {
+		if c.Type != ai.ContentThinking {
+			out = append(out, c)
+			continue
+		}
+		if mode == config.ThinkingStripped || strings.TrimSpace(c.Thinking) == "" {
+			continue
+		}
+		// A summary no longer matches the provider's signature
+		c.Thinking, c.Signature = SummarizeThinking(c.Thinking), ""
+		out = append(out, c)
+	}
+	return out
+}
+
+// RetainThinkingMessages applies RetainThinking to every message, dropping
+// messages left without content. The input slice is not modified.
+func RetainThinkingMessages(messages []ai.Message, mode string) []ai.Message {
+	if mode == config.ThinkingFull {
+		return messages
+	}
+	out := make([]ai.Message, 0, len(messages))
+	for _, msg := range messages {
+		msg.Content = RetainThinking(msg.Content, mode)
+		if len(msg.Content) == 0 {
+			continue
+		}
+		out = append(out, msg)
+	}
+	return out
+}
+
+// thinkingText joins the thinking blocks of content.
+func thinkingText(content []ai.Content) string {
+	var parts []string
+	for _, c := range content {
+		if c.Type == ai.ContentThinking && c.Thinking != "" {
+			parts = append(parts, c.Thinking)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
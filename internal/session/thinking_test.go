@@ -0,0 +1,107 @@
+// ABOUTME: Tests for thinking retention: summaries, per-mode filtering, and persisted assistant records
+// ABOUTME: Verifies stripped sessions never contain reasoning text on disk
+
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/config"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+const longThinking = "The user wants a retry loop. I should check the backoff helper first, " +
+	"then look at how errors are wrapped in the client package before changing anything."
+
+func TestSummarizeThinking(t *testing.T) {
+	t.Parallel()
+
+	if got := SummarizeThinking("  short thought  "); got != "short thought" {
+		t.Errorf("short = %q, want unchanged", got)
+	}
+	got := SummarizeThinking(longThinking)
+	if !strings.HasPrefix(got, "The user wants a retry loop. [") || !strings.Contains(got, "more bytes of thinking omitted") {
+		t.Errorf("summary = %q, want the first sentence and an omission note", got)
+	}
+
+	runes := strings.Repeat("é", 150) // 300 bytes, no sentence break
+	if got := SummarizeThinking(runes); !strings.HasPrefix(got, strings.Repeat("é", 100)+" [") {
+		t.Errorf("capped summary split a rune: %q", got[:40])
+	}
+}
+
+func TestRetainThinking_Modes(t *testing.T) {
+	t.Parallel()
+
+	blocks := []ai.Content{
+		{Type: ai.ContentThinking, Thinking: longThinking},
+		{Type: ai.ContentText, Text: "Here is the fix."},
+	}
+
+	if got := RetainThinking(blocks, config.ThinkingFull); got[0].Thinking != longThinking {
+		t.Error("full must keep thinking verbatim")
+	}
+	if got := RetainThinking(blocks, config.ThinkingStripped); len(got) != 1 || got[0].Type != ai.ContentText {
+		t.Errorf("stripped = %+v, want only the text block", got)
+	}
+	got := RetainThinking(blocks, "")
+	if len(got) != 2 || got[0].Thinking == longThinking || !strings.Contains(got[0].Thinking, "omitted") {
+		t.Errorf("default = %+v, want a summarized thinking block", got)
+	}
+	if blocks[0].Thinking != longThinking {
+		t.Error("RetainThinking must not mutate its input")
+	}
+
+	msgs := []ai.Message{
+		{Role: ai.RoleAssistant, Content: blocks[:1]},
+		ai.NewTextMessage(ai.RoleUser, "thanks"),
+	}
+	if got := RetainThinkingMessages(msgs, config.ThinkingStripped); len(got) != 1 || got[0].Role != ai.RoleUser {
+		t.Errorf("stripped messages = %+v, want thinking-only message dropped", got)
+	}
+}
+
+func TestAddAssistantMessage_PersistsThinkingPerMode(t *testing.T) {
+	t.Parallel()
+
+	for _, mode := range []string{config.ThinkingFull, config.ThinkingSummarized, config.ThinkingStripped} {
+		dir := t.TempDir()
+		w, err := NewWriterInDir(dir, "think")
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := &Session{Writer: w, ThinkingMode: mode}
+		err = s.AddAssistantMessage(&ai.AssistantMessage{Content: []ai.Content{
+			{Type: ai.ContentThinking, Thinking: longThinking},
+			{Type: ai.ContentText, Text: "done"},
+		}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Close()
+
+		if s.Messages[0].Content[0].Thinking != longThinking {
+			t.Errorf("%s: in-memory conversation must keep full thinking", mode)
+		}
+		raw, _ := os.ReadFile(filepath.Join(dir, "think.jsonl"))
+		full := strings.Contains(string(raw), "before changing anything")
+		first := strings.Contains(string(raw), "retry loop")
+		switch mode {
+		case config.ThinkingFull:
+			if !full {
+				t.Errorf("full: thinking missing from %s", raw)
+			}
+		case config.ThinkingSummarized:
+			if full || !first {
+				t.Errorf("summarized: want only the first sentence in %s", raw)
+			}
+		case config.ThinkingStripped:
+			if first || strings.Contains(string(raw), `"thinking"`) {
+				t.Errorf("stripped: thinking leaked into %s", raw)
+			}
+		}
+	}
+}
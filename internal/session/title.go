@@ -0,0 +1,178 @@
+// ABOUTME: Session titles: short summaries generated by a model or set with /rename, stored as records
+// ABOUTME: The latest session_title record wins; user titles are never replaced by generated ones
+
+package session
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+const (
+	maxTitleRunes      = 60   // longer generated titles are cut at a word
+	maxTitleInputRunes = 6000 // transcript excerpt sent to the title model
+	titleMaxTokens     = 32
+)
+
+// titleSystemPrompt asks for a bare title, so the reply needs little cleanup.
+const titleSystemPrompt = "You name coding sessions. Reply with a title of at most six words " +
+	"that says what the user is working on. No quotes, no trailing punctuation, nothing else."
+
+// TitleData holds a session_title record.
+type TitleData struct {
+	Title string `json:"title"`
+	Auto  bool   `json:"auto,omitempty"` // generated; a later generated title may replace it
+}
+
+// SetTitle records title as the session's title. Generated titles (auto)
+// are ignored once the user has named the session, and when unchanged.
+func (s *Session) SetTitle(title string, auto bool) error {
+	title = strings.TrimSpace(title)
+	if title == "" || auto && (s.TitleSet || title == s.Title) {
+		return nil
+	}
+	s.Title, s.TitleSet = title, !auto
+	if s.Writer == nil {
+		return nil
+	}
+	return s.Writer.WriteRecord(RecordTitle, TitleData{Title: title, Auto: auto})
+}
+
+// TitleFromRecords returns the latest title in records, if any.
+func TitleFromRecords(records []Record) (TitleData, bool) {
+	for i := len(records) - 1; i >= 0; i-- {
+		if records[i].Type != RecordTitle {
+			continue
+		}
+		var td TitleData
+		if err := records[i].Unmarshal(&td); err == nil && td.Title != "" {
+			return td, true
+		}
+	}
+	return TitleData{}, false
+}
+
+// readTitle returns the latest title in the session file at path, or "".
+// Only lines that look like title records are decoded.
+func readTitle(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	buf := scannerBufPool.Get().([]byte)
+	defer scannerBufPool.Put(buf)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(buf[:0], scannerMaxBuf)
+
+	marker := []byte(`"type":"` + string(RecordTitle) + `"`)
+	var title string
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if !bytes.Contains(line, marker) {
+			continue
+		}
+		var rec Record
+		if json.Unmarshal(line, &rec) != nil {
+			continue
+		}
+		if td, ok := TitleFromRecords([]Record{rec}); ok {
+			title = td.Title
+		}
+	}
+	return title
+}
+
+// GenerateTitle asks model for a short title summarizing messages.
+func GenerateTitle(ctx context.Context, provider ai.ApiProvider, model *ai.Model, messages []ai.Message) (string, error) {
+	excerpt := titleExcerpt(messages)
+	if excerpt == "" {
+		return "", fmt.Errorf("no conversation text to title")
+	}
+	llmCtx := &ai.Context{
+		System:   titleSystemPrompt,
+		Messages: []ai.Message{ai.NewTextMessage(ai.RoleUser, "Conversation:\n\n"+excerpt+"\n\nTitle:")},
+	}
+	stream := provider.Stream(ctx, model, llmCtx, &ai.StreamOptions{MaxTokens: titleMaxTokens})
+
+	var text strings.Builder
+	var streamErr error
+	for ev := range stream.Events() {
+		switch ev.Type {
+		case ai.EventContentDelta:
+			text.WriteString(ev.Text)
+		case ai.EventError:
+			if streamErr == nil {
+				streamErr = ev.Error
+			}
+		}
+	}
+	if streamErr != nil {
+		return "", fmt.Errorf("generating title: %w", streamErr)
+	}
+	title := cleanTitle(text.String())
+	if title == "" {
+		return "", fmt.Errorf("generating title: empty reply")
+	}
+	return title, nil
+}
+
+// titleExcerpt renders the text of messages as "role: text" lines, keeping
+// the start of the conversation when it is too long.
+func titleExcerpt(messages []ai.Message) string {
+	var b strings.Builder
+	for _, msg := range messages {
+		if msg.Ephemeral {
+			continue
+		}
+		var text strings.Builder
+		for _, c := range msg.Content {
+			if c.Type == ai.ContentText {
+				text.WriteString(c.Text)
+			}
+		}
+		t := strings.Join(strings.Fields(text.String()), " ")
+		if t == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "%s: %s\n", msg.Role, t)
+		if utf8.RuneCountInString(b.String()) >= maxTitleInputRunes {
+			break
+		}
+	}
+	out := b.String()
+	if r := []rune(out); len(r) > maxTitleInputRunes {
+		out = string(r[:maxTitleInputRunes])
+	}
+	return strings.TrimSpace(out)
+}
+
+// cleanTitle reduces a model reply to one line without quotes, a "Title:"
+// prefix, or trailing punctuation, cut to maxTitleRunes at a word boundary.
+func cleanTitle(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	s = strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(s, "Title:"), "title:"))
+	s = strings.Trim(s, "\"'`*#")
+	s = strings.TrimRight(strings.TrimSpace(s), ".!:;,")
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > maxTitleRunes {
+		cut := string(r[:maxTitleRunes])
+		if i := strings.LastIndexByte(cut, ' '); i > 0 {
+			cut = cut[:i]
+		}
+		s = cut + "…"
+	}
+	return s
+}
@@ -0,0 +1,157 @@
+// ABOUTME: Tests for session titles: recording, precedence of user titles, listing, and generation
+// ABOUTME: Generation runs against a stub provider that replies with fixed text
+
+package session
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+// titleProvider replies with text, or fails with err, and records the request.
+type titleProvider struct {
+	text string
+	err  error
+	ctx  *ai.Context
+}
+
+func (p *titleProvider) Api() ai.Api { return ai.ApiAnthropic }
+
+func (p *titleProvider) Stream(_ context.Context, _ *ai.Model, llmCtx *ai.Context, _ *ai.StreamOptions) *ai.EventStream {
+	p.ctx = llmCtx
+	stream := ai.NewEventStream(4)
+	go func() {
+		if p.err != nil {
+			stream.Send(ai.StreamEvent{Type: ai.EventError, Error: p.err})
+			stream.FinishWithError(p.err)
+			return
+		}
+		stream.Send(ai.StreamEvent{Type: ai.EventContentDelta, Text: p.text})
+		stream.Finish(&ai.AssistantMessage{Content: []ai.Content{{Type: ai.ContentText, Text: p.text}}})
+	}()
+	return stream
+}
+
+func TestSession_SetTitle(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewWriterInDir(dir, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Session{ID: "s1", Writer: w}
+	defer s.Close()
+
+	steps := []struct {
+		title string
+		auto  bool
+		want  string
+	}{
+		{"Generated one", true, "Generated one"},
+		{"Generated two", true, "Generated two"},
+		{"My name", false, "My name"},
+		{"Generated three", true, "My name"}, // user titles win
+		{"Renamed", false, "Renamed"},
+	}
+	for _, st := range steps {
+		if err := s.SetTitle(st.title, st.auto); err != nil {
+			t.Fatal(err)
+		}
+		if s.Title != st.want {
+			t.Errorf("after SetTitle(%q, %v): Title = %q; want %q", st.title, st.auto, s.Title, st.want)
+		}
+	}
+
+	records, err := ReadRecordsInDir(dir, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 4 {
+		t.Errorf("got %d records; the ignored generated title should not be written", len(records))
+	}
+	td, ok := TitleFromRecords(records)
+	if !ok || td.Title != "Renamed" || td.Auto {
+		t.Errorf("TitleFromRecords = %+v, %v", td, ok)
+	}
+}
+
+func TestListSessionsInDir_Titles(t *testing.T) {
+	dir := t.TempDir()
+	writeSearchSession(t, dir, "s1",
+		func(w *Writer) error { return w.WriteRecord(RecordTitle, TitleData{Title: "First", Auto: true}) },
+		userText("hello"),
+		func(w *Writer) error { return w.WriteRecord(RecordTitle, TitleData{Title: "Second", Auto: true}) },
+	)
+	writeSearchSession(t, dir, "s2", userText("no title here"))
+
+	sessions, err := ListSessionsInDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	titles := map[string]string{}
+	for _, s := range sessions {
+		titles[s.ID] = s.Title
+	}
+	if titles["s1"] != "Second" || titles["s2"] != "" {
+		t.Errorf("titles = %v; want the latest title for s1 and none for s2", titles)
+	}
+
+	var out bytes.Buffer
+	if err := runList(nil, dir, &out); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"s1", "Second", "s2", "(untitled)"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("list output missing %q:\n%s", want, out.String())
+		}
+	}
+	out.Reset()
+	if err := runList([]string{"--limit", "1"}, dir, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "(1 of 2 sessions") {
+		t.Errorf("limited list output = %q", out.String())
+	}
+}
+
+func TestGenerateTitle(t *testing.T) {
+	msgs := []ai.Message{
+		ai.NewTextMessage(ai.RoleUser, "the retry test is flaky"),
+		{Role: ai.RoleUser, Ephemeral: true, Content: []ai.Content{{Type: ai.ContentText, Text: "reminder text"}}},
+		ai.NewTextMessage(ai.RoleAssistant, "I will look at it"),
+	}
+	p := &titleProvider{text: "Title: \"Fix flaky retry test.\"\nextra"}
+	title, err := GenerateTitle(context.Background(), p, &ai.Model{}, msgs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if title != "Fix flaky retry test" {
+		t.Errorf("title = %q", title)
+	}
+	prompt := p.ctx.Messages[0].Content[0].Text
+	if !strings.Contains(prompt, "user: the retry test is flaky") || strings.Contains(prompt, "reminder text") {
+		t.Errorf("prompt should hold the conversation without reminders:\n%s", prompt)
+	}
+
+	if _, err := GenerateTitle(context.Background(), &titleProvider{err: errors.New("boom")}, &ai.Model{}, msgs); err == nil {
+		t.Error("expected provider error")
+	}
+	if _, err := GenerateTitle(context.Background(), p, &ai.Model{}, nil); err == nil {
+		t.Error("expected error without conversation text")
+	}
+}
+
+func TestCleanTitle(t *testing.T) {
+	long := strings.Repeat("word ", 20)
+	got := cleanTitle(long)
+	if len([]rune(got)) > maxTitleRunes+1 || !strings.HasSuffix(got, "word…") {
+		t.Errorf("cleanTitle(long) = %q", got)
+	}
+	if got := cleanTitle("  **Refactor   parser**  "); got != "Refactor parser" {
+		t.Errorf("cleanTitle = %q", got)
+	}
+}
@@ -10,10 +10,16 @@ import (
 // EstimateTokens returns an approximate token count for a text string.
 // Uses the chars ÷ 4 heuristic which is accurate within ~10% for English text.
 func EstimateTokens(text string) int {
-	if text == "" {
+	return EstimateTokensForChars(len(text))
+}
+
+// EstimateTokensForChars is EstimateTokens for n bytes of text, for callers
+// that count streamed text without keeping it.
+func EstimateTokensForChars(n int) int {
+	if n <= 0 {
 		return 0
 	}
-	return (len(text) + 3) / 4 // ceiling division
+	return (n + 3) / 4 // ceiling division
 }
 
 // EstimateContentTokens estimates tokens for a single content block.
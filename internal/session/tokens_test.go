@@ -34,6 +34,14 @@ func TestEstimateTokens(t *testing.T) {
 	}
 }
 
+func TestEstimateTokensForChars(t *testing.T) {
+	for n, want := range map[int]int{-1: 0, 0: 0, 1: 1, 4: 1, 5: 2, 4000: 1000} {
+		if got := EstimateTokensForChars(n); got != want {
+			t.Errorf("EstimateTokensForChars(%d) = %d; want %d", n, got, want)
+		}
+	}
+}
+
 func TestEstimateContentTokens(t *testing.T) {
 	tests := []struct {
 		name    string
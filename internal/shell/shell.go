@@ -0,0 +1,169 @@
+// ABOUTME: The shell commands run in, shared by the bash tool, the ! escape, hooks, and the status line
+// ABOUTME: Detected (bash, else PowerShell or cmd on Windows) or set by terminal.shell; rc files are skipped
+
+package shell
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode/utf16"
+)
+
+// Kind is how a shell takes a command string.
+type Kind string
+
+const (
+	KindPOSIX      Kind = "posix"      // bash, zsh, fish, nu, sh: -c command
+	KindPowerShell Kind = "powershell" // pwsh, powershell: -EncodedCommand
+	KindCmd        Kind = "cmd"        // cmd.exe: /d /s /c "command"
+)
+
+// Shell is an interpreter commands run in.
+type Shell struct {
+	Path string
+	Kind Kind
+	// Args go before the command; nil uses the shell's defaults.
+	Args []string
+}
+
+// defaultArgs are the arguments before the command for shells that need
+// more than -c. They keep rc files from running: commands inherit pi-go's
+// environment, which the user's interactive shell already set up, and a
+// prompt theme or greeting in an rc file would end up in tool output.
+var defaultArgs = map[string][]string{
+	"zsh":        {"-f", "-c"},
+	"fish":       {"--no-config", "-c"},
+	"nu":         {"--no-config-file", "-c"},
+	"pwsh":       {"-NoLogo", "-NoProfile", "-NonInteractive", "-EncodedCommand"},
+	"powershell": {"-NoLogo", "-NoProfile", "-NonInteractive", "-EncodedCommand"},
+	"cmd":        {"/d", "/s", "/c"},
+}
+
+// errNoShell is returned when none of the candidate shells is installed.
+var errNoShell = errors.New("no shell found on PATH")
+
+// configured is the shell set by Configure; nil means detect.
+var configured atomic.Pointer[Shell]
+
+// Configure sets the shell Current returns. path is an executable or a
+// name looked up on PATH; args, when non-nil, replace its default
+// arguments and the command is appended after them. An empty path goes
+// back to detection. Call it once at startup, before commands run.
+func Configure(path string, args []string) error {
+	if path == "" {
+		if args != nil {
+			return errors.New("shell args need a shell path")
+		}
+		configured.Store(nil)
+		return nil
+	}
+	resolved, err := exec.LookPath(path)
+	if err != nil {
+		return fmt.Errorf("shell %q: %w", path, err)
+	}
+	configured.Store(&Shell{Path: resolved, Kind: kindOf(resolved), Args: args})
+	return nil
+}
+
+// Current returns the configured shell, or else the detected one.
+func Current() (Shell, error) {
+	if s := configured.Load(); s != nil {
+		return *s, nil
+	}
+	return detect()
+}
+
+// detect finds the first installed shell in candidates order, once.
+var detect = sync.OnceValues(Detect)
+
+// Detect returns the first installed shell in candidates order: bash
+// everywhere, then sh on Unix, or PowerShell and cmd on Windows.
+func Detect() (Shell, error) {
+	for _, name := range candidates() {
+		if path, err := exec.LookPath(name); err == nil && usable(path) {
+			return Shell{Path: path, Kind: kindOf(path)}, nil
+		}
+	}
+	return Shell{}, errNoShell
+}
+
+// kindOf infers a shell's kind from its executable name.
+func kindOf(path string) Kind {
+	switch strings.ToLower(baseName(path)) {
+	case "pwsh", "powershell":
+		return KindPowerShell
+	case "cmd":
+		return KindCmd
+	}
+	return KindPOSIX
+}
+
+// Name returns the shell's executable name, e.g. "bash" or "pwsh".
+func (s Shell) Name() string {
+	return baseName(s.Path)
+}
+
+// baseName returns path's last element without its extension, splitting
+// on both separators so Windows paths parse on any OS.
+func baseName(path string) string {
+	base := path[strings.LastIndexAny(path, `/\`)+1:]
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// Command returns a command running command in s.
+func (s Shell) Command(ctx context.Context, command string) *exec.Cmd {
+	args := s.args(command)
+	cmd := exec.CommandContext(ctx, s.Path, args...)
+	if s.Kind == KindCmd {
+		setRawCommandLine(cmd, cmdCommandLine(s.Path, args))
+	}
+	return cmd
+}
+
+// args returns the arguments s runs command with: s.Args or the shell's
+// defaults, then the command, encoded when PowerShell expects that.
+func (s Shell) args(command string) []string {
+	args := s.Args
+	if args == nil {
+		args = defaultArgs[strings.ToLower(s.Name())]
+	}
+	if args == nil {
+		args = []string{"-c"}
+	}
+	if s.Kind == KindPowerShell && len(args) > 0 && strings.EqualFold(args[len(args)-1], "-EncodedCommand") {
+		command = encodePowerShell(command)
+	}
+	return append(slices.Clone(args), command)
+}
+
+// encodePowerShell encodes command for -EncodedCommand (base64 of
+// UTF-16LE), which PowerShell decodes verbatim: no quote or escape in the
+// command can be misread by argument parsing.
+func encodePowerShell(command string) string {
+	units := utf16.Encode([]rune(command))
+	b := make([]byte, 0, 2*len(units))
+	for _, u := range units {
+		b = append(b, byte(u), byte(u>>8))
+	}
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// cmdCommandLine is the command line cmd.exe gets for args, whose last
+// element is the command. cmd does not parse arguments like other
+// programs: with /s it strips the outer quotes of what follows /c and runs
+// the rest as typed, so the command must not be escaped the way Go
+// escapes arguments.
+func cmdCommandLine(path string, args []string) string {
+	parts := []string{`"` + path + `"`}
+	parts = append(parts, args[:len(args)-1]...)
+	parts = append(parts, `"`+args[len(args)-1]+`"`)
+	return strings.Join(parts, " ")
+}
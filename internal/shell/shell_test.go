@@ -0,0 +1,146 @@
+// ABOUTME: Tests for shell selection: kinds, default and configured arguments, PowerShell encoding, cmd lines
+// ABOUTME: Windows-only behaviour is checked through the portable helpers that build each invocation
+
+package shell
+
+import (
+	"context"
+	"encoding/base64"
+	"slices"
+	"testing"
+	"unicode/utf16"
+)
+
+func TestKindOf(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]Kind{
+		"/bin/bash":                              KindPOSIX,
+		"/usr/bin/fish":                          KindPOSIX,
+		`C:\Program Files\Git\bin\bash.exe`:      KindPOSIX,
+		`C:\Program Files\PowerShell\7\pwsh.exe`: KindPowerShell,
+		"powershell.exe":                         KindPowerShell,
+		`C:\Windows\System32\cmd.exe`:            KindCmd,
+		"CMD.EXE":                                KindCmd,
+	}
+	for path, want := range tests {
+		if got := kindOf(path); got != want {
+			t.Errorf("kindOf(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestShell_DefaultArgsSkipRCFiles(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"/bin/bash", []string{"-c", "ls"}},
+		{"/bin/sh", []string{"-c", "ls"}},
+		{"/usr/bin/zsh", []string{"-f", "-c", "ls"}},
+		{"/usr/bin/fish", []string{"--no-config", "-c", "ls"}},
+		{"/usr/bin/nu", []string{"--no-config-file", "-c", "ls"}},
+	}
+	for _, tt := range tests {
+		s := Shell{Path: tt.path, Kind: kindOf(tt.path)}
+		if got := s.args("ls"); !slices.Equal(got, tt.want) {
+			t.Errorf("%s args = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestShell_ConfiguredArgsReplaceDefaults(t *testing.T) {
+	t.Parallel()
+
+	s := Shell{Path: "/usr/bin/zsh", Kind: KindPOSIX, Args: []string{"-l", "-c"}}
+	if got, want := s.args("ls"), []string{"-l", "-c", "ls"}; !slices.Equal(got, want) {
+		t.Errorf("args = %q, want %q", got, want)
+	}
+
+	// PowerShell with its own arguments gets the command as typed unless
+	// they end in -EncodedCommand.
+	ps := Shell{Path: "pwsh", Kind: KindPowerShell, Args: []string{"-Command"}}
+	if got := ps.args("echo hi"); got[len(got)-1] != "echo hi" {
+		t.Errorf("powershell -Command args = %q", got)
+	}
+}
+
+func TestEncodePowerShell_RoundTrips(t *testing.T) {
+	t.Parallel()
+
+	command := `Write-Output "it's $env:USERPROFILE" | Select-String 'ü'`
+	raw, err := base64.StdEncoding.DecodeString(encodePowerShell(command))
+	if err != nil {
+		t.Fatalf("not base64: %v", err)
+	}
+	if len(raw)%2 != 0 {
+		t.Fatalf("UTF-16LE needs an even byte count; got %d", len(raw))
+	}
+	units := make([]uint16, len(raw)/2)
+	for i := range units {
+		units[i] = uint16(raw[2*i]) | uint16(raw[2*i+1])<<8
+	}
+	if got := string(utf16.Decode(units)); got != command {
+		t.Errorf("decoded %q, want %q", got, command)
+	}
+}
+
+func TestShell_CommandArgs(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	posix := Shell{Path: "/bin/bash", Kind: KindPOSIX}.Command(ctx, "echo hi")
+	if want := []string{"/bin/bash", "-c", "echo hi"}; !slices.Equal(posix.Args, want) {
+		t.Errorf("posix args = %q, want %q", posix.Args, want)
+	}
+
+	ps := Shell{Path: "pwsh", Kind: KindPowerShell}.Command(ctx, "echo hi")
+	if n := len(ps.Args); n < 2 || ps.Args[n-2] != "-EncodedCommand" || ps.Args[n-1] != encodePowerShell("echo hi") {
+		t.Errorf("powershell args = %q; want the command encoded last", ps.Args)
+	}
+	if !slices.Contains(ps.Args, "-NoProfile") {
+		t.Errorf("powershell should skip profiles; args = %q", ps.Args)
+	}
+}
+
+func TestCmdCommandLine_KeepsQuotesVerbatim(t *testing.T) {
+	t.Parallel()
+
+	got := cmdCommandLine(`C:\Windows\System32\cmd.exe`, []string{"/d", "/s", "/c", `dir "C:\Program Files" && echo "done"`})
+	want := `"C:\Windows\System32\cmd.exe" /d /s /c "dir "C:\Program Files" && echo "done""`
+	if got != want {
+		t.Errorf("cmdCommandLine = %s\nwant %s", got, want)
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	t.Cleanup(func() { _ = Configure("", nil) })
+
+	if err := Configure("sh", []string{"-e", "-c"}); err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+	s, err := Current()
+	if err != nil || s.Name() != "sh" || !slices.Equal(s.Args, []string{"-e", "-c"}) {
+		t.Errorf("Current = %+v, %v; want the configured sh", s, err)
+	}
+	out, err := s.Command(context.Background(), "echo configured").Output()
+	if err != nil || string(out) != "configured\n" {
+		t.Errorf("output = %q, %v", out, err)
+	}
+
+	if err := Configure("no-such-shell-pi-go", nil); err == nil {
+		t.Error("an unknown shell should be rejected")
+	}
+	if err := Configure("", []string{"-c"}); err == nil {
+		t.Error("args without a path should be rejected")
+	}
+
+	if err := Configure("", nil); err != nil {
+		t.Fatalf("Configure reset: %v", err)
+	}
+	if s, err := Current(); err != nil || s.Path == "" {
+		t.Errorf("Current after reset = %+v, %v; want a detected shell", s, err)
+	}
+}
@@ -0,0 +1,19 @@
+// ABOUTME: Unix shell candidates: bash, then sh
+// ABOUTME: Command lines are argument vectors here, so no raw command line is needed
+
+//go:build unix
+
+package shell
+
+import "os/exec"
+
+// candidates lists the shells Detect tries, in order.
+func candidates() []string {
+	return []string{"bash", "sh"}
+}
+
+// usable reports whether a shell found on PATH can be used.
+func usable(string) bool { return true }
+
+// setRawCommandLine is a no-op: Unix passes arguments as a vector.
+func setRawCommandLine(*exec.Cmd, string) {}
@@ -0,0 +1,42 @@
+// ABOUTME: Windows shell candidates: Git Bash, PowerShell 7, Windows PowerShell, then cmd
+// ABOUTME: WSL's bash.exe launcher is skipped, since it runs commands inside Linux with different paths
+
+//go:build windows
+
+package shell
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// candidates lists the shells Detect tries, in order.
+func candidates() []string {
+	comspec := os.Getenv("ComSpec")
+	if comspec == "" {
+		comspec = "cmd"
+	}
+	return []string{"bash", "pwsh", "powershell", comspec}
+}
+
+// usable reports whether a shell found on PATH can be used: the
+// bash.exe in System32 starts WSL rather than a Windows shell.
+func usable(path string) bool {
+	root := os.Getenv("SystemRoot")
+	if root == "" || kindOf(path) != KindPOSIX {
+		return true
+	}
+	return !strings.EqualFold(filepath.Dir(path), filepath.Join(root, "System32"))
+}
+
+// setRawCommandLine gives cmd the command line verbatim instead of one
+// built by escaping its arguments.
+func setRawCommandLine(cmd *exec.Cmd, line string) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.CmdLine = line
+}
@@ -1,5 +1,5 @@
 // ABOUTME: External command engine for custom status line content
-// ABOUTME: Pipes JSON input to shell command, captures stdout, applies padding
+// ABOUTME: Pipes a Claude Code-style JSON payload to a shell command; keeps its first line and colors
 
 package statusline
 
@@ -8,38 +8,60 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os/exec"
 	"strings"
 	"time"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/shell"
 )
 
 // Input contains the data piped to the external status line command as JSON.
+// The field names follow Claude Code's status line payload, so its scripts
+// work unchanged.
 type Input struct {
-	CWD           string      `json:"cwd"`
-	SessionID     string      `json:"session_id,omitempty"`
-	Model         ModelInfo   `json:"model"`
-	Workspace     string      `json:"workspace,omitempty"`
-	Mode          string      `json:"mode"`
-	GitBranch     string      `json:"git_branch,omitempty"`
-	ContextWindow ContextInfo `json:"context_window"`
+	HookEventName string        `json:"hook_event_name"` // always "Status"
+	CWD           string        `json:"cwd"`
+	SessionID     string        `json:"session_id,omitempty"`
+	Version       string        `json:"version,omitempty"`
+	Model         ModelInfo     `json:"model"`
+	Workspace     WorkspaceInfo `json:"workspace"`
+	Mode          string        `json:"mode"`
+	GitBranch     string        `json:"git_branch,omitempty"`
+	Cost          CostInfo      `json:"cost"`
+	ContextWindow ContextInfo   `json:"context_window"`
+	ExitCode      *int          `json:"exit_code,omitempty"` // last ! shell command; omitted before the first
 }
 
 // ModelInfo describes the active model.
 type ModelInfo struct {
-	Name string `json:"name"`
-	API  string `json:"api,omitempty"`
+	ID          string `json:"id,omitempty"`
+	DisplayName string `json:"display_name,omitempty"`
+	Name        string `json:"name"`
+	API         string `json:"api,omitempty"`
+}
+
+// WorkspaceInfo describes the directories of the session.
+type WorkspaceInfo struct {
+	CurrentDir string `json:"current_dir"`
+	ProjectDir string `json:"project_dir,omitempty"`
+}
+
+// CostInfo reports the session's spend so far.
+type CostInfo struct {
+	TotalCostUSD float64 `json:"total_cost_usd"`
 }
 
 // ContextInfo tracks context window usage.
 type ContextInfo struct {
-	Used  int `json:"used"`
-	Total int `json:"total"`
+	Used    int `json:"used"`
+	Total   int `json:"total"`
+	UsedPct int `json:"used_percentage"`
 }
 
 // Engine executes an external command to produce status line content.
 type Engine struct {
 	command string
 	padding int
+	refresh time.Duration
 }
 
 // New creates a status line engine with the given shell command and padding.
@@ -50,13 +72,30 @@ func New(command string, padding int) *Engine {
 	}
 }
 
+// WithRefresh sets how often the status line is re-run while idle; zero
+// re-runs it only when the session changes.
+func (e *Engine) WithRefresh(d time.Duration) *Engine {
+	e.refresh = d
+	return e
+}
+
+// Refresh returns the idle refresh interval; zero means none.
+func (e *Engine) Refresh() time.Duration {
+	if e == nil {
+		return 0
+	}
+	return e.refresh
+}
+
 // HasCommand reports whether an external command is configured.
 func (e *Engine) HasCommand() bool {
-	return e.command != ""
+	return e != nil && e.command != ""
 }
 
 // Execute runs the configured command, piping the Input as JSON to stdin.
-// Returns the trimmed stdout output with padding applied.
+// Returns the first line of stdout with padding applied. Colors and other
+// SGR sequences, and OSC 8 hyperlinks, pass through; other control
+// sequences are dropped so they cannot disturb the screen.
 // Respects the provided context for cancellation; applies a 5-second default timeout.
 func (e *Engine) Execute(ctx context.Context, input Input) (string, error) {
 	if e.command == "" {
@@ -70,12 +109,17 @@ func (e *Engine) Execute(ctx context.Context, input Input) (string, error) {
 		defer cancel()
 	}
 
+	input.HookEventName = "Status"
 	data, err := json.Marshal(input)
 	if err != nil {
 		return "", fmt.Errorf("marshaling input: %w", err)
 	}
 
-	cmd := exec.CommandContext(ctx, "sh", "-c", e.command)
+	sh, err := shell.Current()
+	if err != nil {
+		return "", fmt.Errorf("running status line command: %w", err)
+	}
+	cmd := sh.Command(ctx, e.command)
 	cmd.Stdin = bytes.NewReader(data)
 
 	var stdout bytes.Buffer
@@ -85,7 +129,8 @@ func (e *Engine) Execute(ctx context.Context, input Input) (string, error) {
 		return "", fmt.Errorf("running status line command: %w", err)
 	}
 
-	result := strings.TrimSpace(stdout.String())
+	line, _, _ := strings.Cut(strings.TrimSpace(stdout.String()), "\n")
+	result := sanitize(strings.TrimRight(line, "\r"))
 
 	if e.padding > 0 {
 		result = strings.Repeat(" ", e.padding) + result
@@ -93,3 +138,54 @@ func (e *Engine) Execute(ctx context.Context, input Input) (string, error) {
 
 	return result, nil
 }
+
+// sanitize keeps printable text, SGR sequences (ESC [ ... m), and OSC 8
+// hyperlinks, and drops every other control character or sequence.
+func sanitize(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == 0x1b && i+1 < len(s) && s[i+1] == '[':
+			j := i + 2
+			for j < len(s) && (s[j] < 0x40 || s[j] > 0x7e) {
+				j++
+			}
+			if j < len(s) && s[j] == 'm' {
+				b.WriteString(s[i : j+1])
+			}
+			i = j + 1
+		case c == 0x1b && i+1 < len(s) && s[i+1] == ']':
+			end, next := oscEnd(s, i+2)
+			if strings.HasPrefix(s[i+2:end], "8;") {
+				b.WriteString(s[i:next])
+			}
+			i = next
+		case c == 0x1b:
+			i += 2 // a two-byte escape
+		case c < 0x20 || c == 0x7f:
+			if c == '\t' {
+				b.WriteByte(' ')
+			}
+			i++
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String()
+}
+
+// oscEnd finds the terminator of the OSC sequence whose body starts at i.
+// It returns where the body ends and where the next text starts.
+func oscEnd(s string, i int) (end, next int) {
+	for j := i; j < len(s); j++ {
+		switch {
+		case s[j] == 0x07:
+			return j, j + 1
+		case s[j] == 0x1b && j+1 < len(s) && s[j+1] == '\\':
+			return j, j + 2
+		}
+	}
+	return len(s), len(s)
+}
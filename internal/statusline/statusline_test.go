@@ -148,3 +148,54 @@ func TestInput_JSONFields(t *testing.T) {
 		t.Error("unexpected model name")
 	}
 }
+
+func TestEngine_Execute_ClaudeCodePayload(t *testing.T) {
+	t.Parallel()
+
+	code := 2
+	e := New("cat", 0)
+	result, err := e.Execute(context.Background(), Input{
+		Model:         ModelInfo{ID: "claude-sonnet-4", DisplayName: "Sonnet"},
+		Workspace:     WorkspaceInfo{CurrentDir: "/w"},
+		Cost:          CostInfo{TotalCostUSD: 0.5},
+		ContextWindow: ContextInfo{UsedPct: 25},
+		ExitCode:      &code,
+	})
+	if err != nil {
+		t.Fatalf("Execute() error: %v", err)
+	}
+	for _, want := range []string{
+		`"hook_event_name":"Status"`, `"display_name":"Sonnet"`, `"current_dir":"/w"`,
+		`"total_cost_usd":0.5`, `"used_percentage":25`, `"exit_code":2`,
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("payload lacks %s: %s", want, result)
+		}
+	}
+}
+
+func TestEngine_Execute_FirstLineAndANSI(t *testing.T) {
+	t.Parallel()
+
+	e := New(`printf '\033[32mok\033[0m \033]8;;https://x.dev\033\\link\033]8;;\033\\\033[2J\033]0;title\007\tdone\nsecond line\n'`, 0)
+	result, err := e.Execute(context.Background(), Input{})
+	if err != nil {
+		t.Fatalf("Execute() error: %v", err)
+	}
+	want := "\x1b[32mok\x1b[0m \x1b]8;;https://x.dev\x1b\\link\x1b]8;;\x1b\\ done"
+	if result != want {
+		t.Errorf("Execute() = %q, want %q", result, want)
+	}
+}
+
+func TestEngine_Refresh(t *testing.T) {
+	t.Parallel()
+
+	var nilEngine *Engine
+	if nilEngine.HasCommand() || nilEngine.Refresh() != 0 {
+		t.Error("a nil engine has no command and no refresh")
+	}
+	if got := New("date", 0).WithRefresh(5 * time.Second).Refresh(); got != 5*time.Second {
+		t.Errorf("Refresh() = %v, want 5s", got)
+	}
+}
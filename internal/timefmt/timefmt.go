@@ -0,0 +1,95 @@
+// ABOUTME: Locale-aware rendering of canonical UTC timestamps for listings and exports
+// ABOUTME: Combines a configurable absolute layout/zone with short relative labels ("2h ago")
+
+package timefmt
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/config"
+)
+
+// Formatter renders timestamps in the user's display zone and layout.
+// Data files always store UTC; Formatter only affects presentation.
+type Formatter struct {
+	Layout   string
+	Location *time.Location
+	Now      func() time.Time // nil uses time.Now
+}
+
+// New builds a Formatter from display settings; nil settings use defaults.
+func New(d *config.DisplaySettings) Formatter {
+	return Formatter{
+		Layout:   d.EffectiveTimeFormat(),
+		Location: d.EffectiveLocation(),
+	}
+}
+
+// Absolute renders t in the configured zone and layout.
+func (f Formatter) Absolute(t time.Time) string {
+	loc := f.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	layout := f.Layout
+	if layout == "" {
+		layout = (*config.DisplaySettings)(nil).EffectiveTimeFormat()
+	}
+	return t.In(loc).Format(layout)
+}
+
+// Format renders t as "<absolute> (<relative>)". Zero times render as "unknown".
+func (f Formatter) Format(t time.Time) string {
+	if t.IsZero() {
+		return "unknown"
+	}
+	return fmt.Sprintf("%s (%s)", f.Absolute(t), Relative(t, f.now()))
+}
+
+// FormatRFC3339 parses a stored RFC3339 timestamp and formats it.
+// Unparseable input is returned unchanged.
+func (f Formatter) FormatRFC3339(ts string) string {
+	t, err := time.Parse(time.RFC3339, ts)
+	if err != nil {
+		return ts
+	}
+	return f.Format(t)
+}
+
+func (f Formatter) now() time.Time {
+	if f.Now != nil {
+		return f.Now()
+	}
+	return time.Now()
+}
+
+// Relative returns a short human label for the distance between t and now,
+// such as "just now", "5m ago", "2h ago", "3d ago", or "in 10m".
+func Relative(t, now time.Time) string {
+	d := now.Sub(t)
+	suffix := " ago"
+	prefix := ""
+	if d < 0 {
+		d = -d
+		suffix = ""
+		prefix = "in "
+	}
+
+	var label string
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		label = fmt.Sprintf("%dm", int(d/time.Minute))
+	case d < 24*time.Hour:
+		label = fmt.Sprintf("%dh", int(d/time.Hour))
+	case d < 30*24*time.Hour:
+		label = fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	case d < 365*24*time.Hour:
+		label = fmt.Sprintf("%dmo", int(d/(30*24*time.Hour)))
+	default:
+		label = fmt.Sprintf("%dy", int(d/(365*24*time.Hour)))
+	}
+	return prefix + label + suffix
+}
@@ -0,0 +1,83 @@
+// ABOUTME: Tests for timestamp display formatting and relative labels
+// ABOUTME: Uses fixed clocks and explicit zones for deterministic output
+
+package timefmt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/config"
+)
+
+func TestRelative(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		t    time.Time
+		want string
+	}{
+		{"just now", now.Add(-20 * time.Second), "just now"},
+		{"minutes", now.Add(-5 * time.Minute), "5m ago"},
+		{"hours", now.Add(-2 * time.Hour), "2h ago"},
+		{"days", now.Add(-3 * 24 * time.Hour), "3d ago"},
+		{"months", now.Add(-65 * 24 * time.Hour), "2mo ago"},
+		{"years", now.Add(-800 * 24 * time.Hour), "2y ago"},
+		{"future", now.Add(10 * time.Minute), "in 10m"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := Relative(tt.t, now); got != tt.want {
+				t.Errorf("Relative() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatter_Format(t *testing.T) {
+	t.Parallel()
+	rome, err := time.LoadLocation("Europe/Rome")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	f := Formatter{Layout: "2006-01-02 15:04 MST", Location: rome, Now: func() time.Time { return now }}
+
+	got := f.Format(now.Add(-2 * time.Hour))
+	want := "2026-03-10 11:00 CET (2h ago)"
+	if got != want {
+		t.Errorf("Format() = %q, want %q", got, want)
+	}
+}
+
+func TestFormatter_FormatRFC3339(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	f := Formatter{Layout: time.Kitchen, Location: time.UTC, Now: func() time.Time { return now }}
+
+	if got := f.FormatRFC3339("2026-03-10T11:30:00Z"); got != "11:30AM (30m ago)" {
+		t.Errorf("FormatRFC3339() = %q", got)
+	}
+	if got := f.FormatRFC3339("garbage"); got != "garbage" {
+		t.Errorf("unparseable input should pass through, got %q", got)
+	}
+}
+
+func TestFormatter_ZeroTime(t *testing.T) {
+	t.Parallel()
+	if got := New(nil).Format(time.Time{}); got != "unknown" {
+		t.Errorf("Format(zero) = %q, want unknown", got)
+	}
+}
+
+func TestNew_UsesDisplaySettings(t *testing.T) {
+	t.Parallel()
+	f := New(&config.DisplaySettings{TimeFormat: "15:04", TimeZone: "UTC"})
+	ts := time.Date(2026, 1, 2, 3, 4, 0, 0, time.UTC)
+	if got := f.Absolute(ts); got != "03:04" {
+		t.Errorf("Absolute() = %q, want 03:04", got)
+	}
+}
@@ -1,19 +1,24 @@
-// ABOUTME: Bash tool: executes shell commands via /bin/bash -c
-// ABOUTME: Captures combined stdout+stderr; respects configurable timeout
+// ABOUTME: Bash tool: executes shell commands in the configured shell, bash -c by default
+// ABOUTME: Captures combined stdout+stderr; respects a configurable timeout and per-call cwd and env
 
 package tools
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"maps"
+	"os"
 	"os/exec"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
+	"github.com/mauromedda/pi-coding-agent-go/internal/permission"
+	"github.com/mauromedda/pi-coding-agent-go/internal/shell"
 )
 
 const (
@@ -56,63 +61,188 @@ func (lw *limitedWriter) Write(p []byte) (int, error) {
 
 // NewBashTool creates a tool that executes shell commands.
 func NewBashTool() *agent.AgentTool {
+	return newBashTool(nil)
+}
+
+// NewBashToolWithSandbox creates a bash tool whose cwd parameter must name
+// a directory inside the sandbox.
+func NewBashToolWithSandbox(sb *permission.Sandbox) *agent.AgentTool {
+	return newBashTool(sb)
+}
+
+func newBashTool(sb *permission.Sandbox) *agent.AgentTool {
 	return &agent.AgentTool{
 		Name:        "bash",
 		Label:       "Run Shell Command",
-		Description: "Execute a shell command via /bin/bash -c. Captures stdout and stderr.",
+		Description: bashDescription(),
 		Parameters: json.RawMessage(`{
 			"type": "object",
 			"required": ["command"],
 			"properties": {
 				"command":    {"type": "string", "description": "Shell command to execute"},
-				"timeout_ms": {"type": "integer", "description": "Timeout in milliseconds (default 120000)"}
+				"timeout_ms": {"type": "integer", "description": "Timeout in milliseconds (default 120000, within the tool's time limit)"},
+				"cwd":        {"type": "string", "description": "Directory to run the command in, instead of prefixing it with cd (default: the project directory)"},
+				"env":        {"type": "object", "additionalProperties": {"type": "string"}, "description": "Environment variables to set for this command only, instead of prefixing it with VAR=value"}
 			}
 		}`),
 		ReadOnly: false,
-		Execute:  executeBash,
+		Execute: func(ctx context.Context, id string, params map[string]any, onUpdate func(agent.ToolUpdate)) (agent.ToolResult, error) {
+			return executeBash(sb, ctx, id, params, onUpdate)
+		},
+	}
+}
+
+// bashDescription names the shell commands run in, so the model writes
+// them in its syntax.
+func bashDescription() string {
+	if c := activeContainer.Load(); c != nil {
+		return fmt.Sprintf("Execute a shell command via %s -c in the %s, with the project mounted at its own path. Captures stdout and stderr.", c.Shell(), c)
+	}
+	sh, err := shell.Current()
+	switch {
+	case err != nil:
+		return "Execute a shell command via bash -c. Captures stdout and stderr."
+	case sh.Kind == shell.KindPowerShell:
+		return fmt.Sprintf("Execute a PowerShell command (%s). Captures stdout and stderr.", sh.Name())
+	case sh.Kind == shell.KindCmd:
+		return "Execute a Windows cmd.exe command. Captures stdout and stderr."
 	}
+	return fmt.Sprintf("Execute a shell command via %s -c. Captures stdout and stderr.", sh.Name())
 }
 
-func executeBash(ctx context.Context, _ string, params map[string]any, onUpdate func(agent.ToolUpdate)) (agent.ToolResult, error) {
+func executeBash(sb *permission.Sandbox, ctx context.Context, _ string, params map[string]any, onUpdate func(agent.ToolUpdate)) (agent.ToolResult, error) {
 	command, err := requireStringParam(params, "command")
 	if err != nil {
 		return errResult(err), nil
 	}
+	dir, err := bashDir(sb, stringParam(params, "cwd", ""))
+	if err != nil {
+		return errResult(err), nil
+	}
+	if err := checkNeverModifyCommand(command, dir); err != nil {
+		return errResult(err), nil
+	}
+	env, err := bashEnv(params)
+	if err != nil {
+		return errResult(err), nil
+	}
 
-	timeoutMs := intParam(params, "timeout_ms", defaultBashTimeoutMs)
-	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeoutMs)*time.Millisecond)
+	timeout := time.Duration(intParam(params, "timeout_ms", defaultBashTimeoutMs)) * time.Millisecond
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	result, err := runBashCommand(ctx, command)
-	if err != nil {
+	// The registry caps how much of the output the model sees.
+	result, err := runBashCommand(runCtx, command, dir, env, onUpdate)
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil:
+		return agent.ToolResult{Content: bashTimedOut(result, timeout), IsError: true}, nil
+	case errors.Is(err, context.DeadlineExceeded):
+		// The caller's deadline, such as the tool's limit: it reports
+		// the timeout.
+		return agent.ToolResult{Content: result, IsError: true}, nil
+	case err != nil:
 		return errResult(fmt.Errorf("executing command: %w", err)), nil
 	}
+	return agent.ToolResult{Content: result}, nil
+}
 
-	if onUpdate != nil {
-		onUpdate(agent.ToolUpdate{Output: result})
+// bashTimedOut reports a command killed at its timeout_ms, after the
+// output it printed.
+func bashTimedOut(output string, timeout time.Duration) string {
+	msg := fmt.Sprintf("command timed out after %s. %s Or pass a larger timeout_ms.", timeout, timeoutHint("bash"))
+	if out := strings.TrimRight(output, "\n"); out != "" {
+		msg = out + "\n\n" + msg
 	}
-
-	result = truncateOutput(result, maxReadOutput)
-	return agent.ToolResult{Content: result}, nil
+	return msg
 }
 
-// runBashCommand executes a command string and returns combined stdout+stderr.
-// Output is capped at maxBashOutput bytes; the process is killed if exceeded.
-func runBashCommand(ctx context.Context, command string) (string, error) {
-	bashPath, err := exec.LookPath("bash")
+// bashDir resolves the cwd parameter against the working directory and
+// checks it is a directory the sandbox allows. An empty cwd means the
+// working directory itself.
+func bashDir(sb *permission.Sandbox, cwd string) (string, error) {
+	if cwd == "" {
+		return "", nil
+	}
+	wd, _ := os.Getwd()
+	dir := ResolveToCwd(cwd, wd)
+	if sb != nil {
+		if err := sb.ValidatePath(dir); err != nil {
+			return "", err
+		}
+	}
+	info, err := os.Stat(dir)
 	if err != nil {
-		return "", fmt.Errorf("bash not found on PATH: %w", err)
+		return "", fmt.Errorf("cwd: %w", err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("cwd %s is not a directory", dir)
+	}
+	return dir, nil
+}
+
+// bashEnv returns the env parameter's variables as sorted NAME=value
+// pairs, or nil when there are none.
+func bashEnv(params map[string]any) ([]string, error) {
+	v, ok := params["env"]
+	if !ok || v == nil {
+		return nil, nil
+	}
+	vars, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("parameter %q must be an object, got %T", "env", v)
+	}
+	if len(vars) == 0 {
+		return nil, nil
+	}
+	var env []string
+	for _, name := range slices.Sorted(maps.Keys(vars)) {
+		if name == "" || strings.ContainsAny(name, "=\x00") {
+			return nil, fmt.Errorf("env: invalid variable name %q", name)
+		}
+		value, ok := vars[name].(string)
+		if !ok {
+			return nil, fmt.Errorf("env: %s must be a string, got %T", name, vars[name])
+		}
+		if strings.ContainsRune(value, 0) {
+			return nil, fmt.Errorf("env: %s contains a NUL byte", name)
+		}
+		env = append(env, name+"="+value)
+	}
+	return env, nil
+}
+
+// runBashCommand executes a command string in dir (the working directory
+// when empty) with env, NAME=value pairs, added to the process environment,
+// and returns combined stdout+stderr. In container mode (see SetContainer)
+// the command runs in the container, where only env is set. Output is capped at maxBashOutput bytes; the
+// process is killed if exceeded. While the command runs, new output goes
+// to onUpdate, when set, at most every bashUpdateInterval.
+func runBashCommand(ctx context.Context, command, dir string, env []string, onUpdate func(agent.ToolUpdate)) (string, error) {
+	var cmd *exec.Cmd
+	if c := activeContainer.Load(); c != nil {
+		cmd = c.Command(ctx, command, dir, env)
+	} else {
+		sh, err := shell.Current()
+		if err != nil {
+			return "", err
+		}
+		cmd = sh.Command(ctx, command)
+		cmd.Dir = dir
+		if env != nil {
+			// exec uses the last value of a duplicated name.
+			cmd.Env = append(os.Environ(), env...)
+		}
 	}
-	cmd := exec.CommandContext(ctx, bashPath, "-c", command)
 
-	var buf bytes.Buffer
-	lw := &limitedWriter{w: &buf, limit: maxBashOutput}
+	out := newOutputStreamer(onUpdate, bashUpdateInterval)
+	lw := &limitedWriter{w: out, limit: maxBashOutput}
 	cmd.Stdout = lw
 	cmd.Stderr = lw
 
-	err = cmd.Run()
+	err := cmd.Run()
+	out.close()
 
-	output := buf.String()
+	output := out.String()
 
 	if lw.exceeded {
 		output += "\n... [output truncated: exceeded 10MB limit]"
@@ -6,10 +6,13 @@ package tools
 import (
 	"bytes"
 	"context"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
+	"github.com/mauromedda/pi-coding-agent-go/internal/permission"
 )
 
 func TestBashTool_SimpleCommand(t *testing.T) {
@@ -81,6 +84,9 @@ func TestBashTool_Timeout(t *testing.T) {
 	if !strings.Contains(result.Content, "timed out") {
 		t.Errorf("expected 'timed out' in error, got %q", result.Content)
 	}
+	if !strings.Contains(result.Content, "background") || !strings.Contains(result.Content, "timeout_ms") {
+		t.Errorf("expected a background and timeout_ms hint, got %q", result.Content)
+	}
 }
 
 func TestBashTool_MissingCommand(t *testing.T) {
@@ -160,7 +166,7 @@ func TestBashTool_UpdateCallback(t *testing.T) {
 	_, err := tool.Execute(context.Background(), "id1", map[string]any{
 		"command": "echo callback_test",
 	}, func(u agent.ToolUpdate) {
-		received = u.Output
+		received += u.Output
 	})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -169,3 +175,134 @@ func TestBashTool_UpdateCallback(t *testing.T) {
 		t.Errorf("expected update callback with output, got %q", received)
 	}
 }
+
+func TestBashTool_StreamsOutputWhileRunning(t *testing.T) {
+	t.Parallel()
+
+	var updates []string
+	tool := NewBashTool()
+	result, err := tool.Execute(context.Background(), "id1", map[string]any{
+		"command": "echo first; sleep 0.5; echo second",
+	}, func(u agent.ToolUpdate) {
+		updates = append(updates, u.Output)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(updates) < 2 {
+		t.Fatalf("updates = %q; want the output in at least two chunks", updates)
+	}
+	if updates[0] != "first\n" {
+		t.Errorf("first update = %q; want %q", updates[0], "first\n")
+	}
+	if got := strings.Join(updates, ""); got != "first\nsecond\n" {
+		t.Errorf("streamed output = %q; want %q", got, "first\nsecond\n")
+	}
+	if result.Content != "first\nsecond\n" {
+		t.Errorf("result = %q; want the full output", result.Content)
+	}
+}
+
+func TestCompleteRunes(t *testing.T) {
+	t.Parallel()
+
+	euro := []byte("€") // three bytes
+	tests := []struct {
+		in   []byte
+		want int
+	}{
+		{[]byte("abc"), 3},
+		{[]byte("ab€"), 5},
+		{append([]byte("ab"), euro[:1]...), 2},
+		{append([]byte("ab"), euro[:2]...), 2},
+		{[]byte{}, 0},
+	}
+	for _, tt := range tests {
+		if got := completeRunes(tt.in); got != tt.want {
+			t.Errorf("completeRunes(%q) = %d; want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestBashTool_CwdAndEnv(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	sub := filepath.Join(root, "pkg")
+	if err := os.Mkdir(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	sb, err := permission.NewSandbox([]string{root})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tool := NewBashToolWithSandbox(sb)
+	result, err := tool.Execute(context.Background(), "id1", map[string]any{
+		"command": `echo "$(basename "$PWD") $PI_TEST_MODE"`,
+		"cwd":     sub,
+		"env":     map[string]any{"PI_TEST_MODE": "unit"},
+	}, nil)
+	if err != nil || result.IsError {
+		t.Fatalf("Execute: %v %s", err, result.Content)
+	}
+	if got := strings.TrimSpace(result.Content); got != "pkg unit" {
+		t.Errorf("output = %q; want the command run in pkg with the variable set", got)
+	}
+}
+
+func TestBashTool_CwdValidation(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	file := filepath.Join(root, "notes.txt")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sb, err := permission.NewSandbox([]string{root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tool := NewBashToolWithSandbox(sb)
+
+	for name, cwd := range map[string]string{
+		"outside sandbox": t.TempDir(),
+		"traversal":       filepath.Join(root, "..", "elsewhere"),
+		"missing":         filepath.Join(root, "missing"),
+		"not a directory": file,
+	} {
+		result, err := tool.Execute(context.Background(), "id1", map[string]any{
+			"command": "echo ran",
+			"cwd":     cwd,
+		}, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !result.IsError || strings.Contains(result.Content, "ran") {
+			t.Errorf("%s: cwd %s should be rejected before running; got %q", name, cwd, result.Content)
+		}
+	}
+}
+
+func TestBashTool_EnvValidation(t *testing.T) {
+	t.Parallel()
+
+	tool := NewBashTool()
+	for _, env := range []any{
+		"FOO=bar",
+		map[string]any{"A=B": "x"},
+		map[string]any{"": "x"},
+		map[string]any{"N": 3.0},
+	} {
+		result, err := tool.Execute(context.Background(), "id1", map[string]any{
+			"command": "echo ran",
+			"env":     env,
+		}, nil)
+		if err != nil {
+			t.Fatalf("env %v: %v", env, err)
+		}
+		if !result.IsError {
+			t.Errorf("env %v should be rejected; got %q", env, result.Content)
+		}
+	}
+}
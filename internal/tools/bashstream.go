@@ -0,0 +1,111 @@
+// ABOUTME: Streams bash output to the tool's update callback while the command runs
+// ABOUTME: Chunks are batched per interval and never split a UTF-8 sequence; the full output is kept for the result
+
+package tools
+
+import (
+	"bytes"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
+)
+
+// bashUpdateInterval is the most often a running command's new output is
+// reported, so a chatty command does not flood the TUI with redraws.
+const bashUpdateInterval = 100 * time.Millisecond
+
+// outputStreamer collects a command's output and reports what arrived
+// since the last report to onUpdate, from its own goroutine.
+type outputStreamer struct {
+	onUpdate func(agent.ToolUpdate)
+
+	mu   sync.Mutex
+	buf  bytes.Buffer
+	sent int // bytes of buf already reported
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// newOutputStreamer starts reporting every interval; a nil onUpdate only
+// collects. Call close once the command has exited.
+func newOutputStreamer(onUpdate func(agent.ToolUpdate), interval time.Duration) *outputStreamer {
+	s := &outputStreamer{onUpdate: onUpdate, stop: make(chan struct{}), done: make(chan struct{})}
+	if onUpdate == nil {
+		close(s.done)
+		return s
+	}
+	go s.run(interval)
+	return s
+}
+
+func (s *outputStreamer) run(interval time.Duration) {
+	defer close(s.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.flush(false)
+		case <-s.stop:
+			s.flush(true)
+			return
+		}
+	}
+}
+
+// Write appends p to the output.
+func (s *outputStreamer) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buf.Write(p)
+}
+
+// flush reports the unreported output. Unless final, a rune cut off at
+// the end waits for the rest of its bytes.
+func (s *outputStreamer) flush(final bool) {
+	s.mu.Lock()
+	chunk := s.buf.Bytes()[s.sent:]
+	if !final {
+		chunk = chunk[:completeRunes(chunk)]
+	}
+	s.sent += len(chunk)
+	text := string(chunk)
+	s.mu.Unlock()
+	if text != "" {
+		s.onUpdate(agent.ToolUpdate{Output: text})
+	}
+}
+
+// completeRunes returns the length of b without a trailing incomplete
+// UTF-8 sequence.
+func completeRunes(b []byte) int {
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+			if !utf8.FullRune(b[i:]) {
+				return i
+			}
+			break
+		}
+	}
+	return len(b)
+}
+
+// close reports the rest of the output and stops reporting.
+func (s *outputStreamer) close() {
+	select {
+	case <-s.stop:
+	default:
+		close(s.stop)
+	}
+	<-s.done
+}
+
+// String returns all the output.
+func (s *outputStreamer) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buf.String()
+}
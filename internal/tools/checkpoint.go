@@ -0,0 +1,65 @@
+// ABOUTME: File checkpoints for the tool registry: file-writing tools snapshot their target first
+// ABOUTME: The snapshots go to the checkpoints on the call's context, so each conversation undoes only its own changes
+
+package tools
+
+import (
+	"context"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
+	"github.com/mauromedda/pi-coding-agent-go/internal/ide"
+	"github.com/mauromedda/pi-coding-agent-go/internal/permission"
+)
+
+// fileWritingTools are the built-in tools that change the file at their
+// "path" argument. bash is not among them: what it changes is unknown.
+var fileWritingTools = []string{"write", "edit", "notebook_edit"}
+
+// EnableCheckpoints makes the registered file-writing tools snapshot their
+// target file, before changing it, in the checkpoints carried by the call's
+// context (see WithCheckpoints). Call it after tools are replaced
+// (SetEditStrategies) and before they are handed to sub-agents.
+func (r *Registry) EnableCheckpoints() {
+	for _, name := range fileWritingTools {
+		if t := r.raw[name]; t != nil {
+			r.Register(withCheckpoint(t, r.sandbox))
+		}
+	}
+}
+
+type checkpointsKey struct{}
+
+// WithCheckpoints returns a context whose file-writing tool calls snapshot
+// into cp. Tools share one registry across split panes; each pane's turns
+// carry its own checkpoints, so /undo in one never touches another's files.
+// Sub-agents inherit the context and record into their parent's turn.
+func WithCheckpoints(ctx context.Context, cp *ide.TurnCheckpoints) context.Context {
+	return context.WithValue(ctx, checkpointsKey{}, cp)
+}
+
+// checkpointsFrom returns the checkpoints stored by WithCheckpoints, or nil.
+func checkpointsFrom(ctx context.Context) *ide.TurnCheckpoints {
+	cp, _ := ctx.Value(checkpointsKey{}).(*ide.TurnCheckpoints)
+	return cp
+}
+
+func withCheckpoint(tool *agent.AgentTool, sb *permission.Sandbox) *agent.AgentTool {
+	inner := tool.Execute
+	if inner == nil {
+		return tool
+	}
+	wrapped := *tool
+	wrapped.Execute = func(ctx context.Context, id string, params map[string]any, onUpdate func(agent.ToolUpdate)) (agent.ToolResult, error) {
+		cp := checkpointsFrom(ctx)
+		if raw, ok := params["path"].(string); ok && raw != "" && cp != nil {
+			path := ExpandPath(raw)
+			// Paths the sandbox rejects are never written; restoring them
+			// later could clobber changes made outside pi-go.
+			if sb == nil || sb.ValidatePath(path) == nil {
+				_ = cp.Snapshot(path) // a file that cannot be read is not undoable, but still written
+			}
+		}
+		return inner(ctx, id, params, onUpdate)
+	}
+	return &wrapped
+}
@@ -0,0 +1,80 @@
+// ABOUTME: Tests for registry file checkpoints: write and edit snapshot their target before running
+// ABOUTME: Undoing the turn restores the edited file and removes the written one
+
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/ide"
+)
+
+func TestRegistry_EnableCheckpoints(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	edited := filepath.Join(dir, "a.txt")
+	written := filepath.Join(dir, "sub", "b.txt")
+	if err := os.WriteFile(edited, []byte("hello world\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewRegistry()
+	cp := ide.NewTurnCheckpoints()
+	r.EnableCheckpoints()
+	cp.BeginTurn()
+
+	ctx := WithCheckpoints(context.Background(), cp)
+	if res, err := r.Get("edit").Execute(ctx, "1", map[string]any{"path": edited, "old_string": "world", "new_string": "there"}, nil); err != nil || res.IsError {
+		t.Fatalf("edit = %+v, %v", res, err)
+	}
+	if res, err := r.Get("write").Execute(ctx, "2", map[string]any{"path": written, "content": "new"}, nil); err != nil || res.IsError {
+		t.Fatalf("write = %+v, %v", res, err)
+	}
+
+	if _, err := cp.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(edited); string(got) != "hello world\n" {
+		t.Errorf("a.txt = %q; want the content from before the edit", got)
+	}
+	if _, err := os.Stat(written); !os.IsNotExist(err) {
+		t.Error("b.txt should be removed: the turn created it")
+	}
+}
+
+func TestRegistry_CheckpointsPerContext(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	mine, theirs := filepath.Join(dir, "mine.txt"), filepath.Join(dir, "theirs.txt")
+	r := NewRegistry()
+	r.EnableCheckpoints()
+	a, b := ide.NewTurnCheckpoints(), ide.NewTurnCheckpoints()
+	a.BeginTurn()
+	b.BeginTurn()
+
+	write := r.Get("write").Execute
+	if _, err := write(WithCheckpoints(context.Background(), a), "1", map[string]any{"path": mine, "content": "a"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := write(WithCheckpoints(context.Background(), b), "2", map[string]any{"path": theirs, "content": "b"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := write(context.Background(), "3", map[string]any{"path": filepath.Join(dir, "none.txt"), "content": "c"}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := a.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(mine); !os.IsNotExist(err) {
+		t.Error("mine.txt should be undone")
+	}
+	if got, _ := os.ReadFile(theirs); string(got) != "b" {
+		t.Errorf("theirs.txt = %q; another context's write must survive the undo", got)
+	}
+}
@@ -0,0 +1,39 @@
+// ABOUTME: Container mode for the tools: bash runs in the session's container, file changes stay in its mounts
+// ABOUTME: write, edit, and notebook_edit run on the host but refuse paths the container cannot see
+
+package tools
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/container"
+)
+
+var activeContainer atomic.Pointer[container.Container]
+
+// SetContainer runs bash commands in c and confines the file-changing tools
+// to its writable mounts; nil runs everything on the host. Call it once at
+// startup, before the tools run.
+func SetContainer(c *container.Container) {
+	activeContainer.Store(c)
+}
+
+// checkWritePolicy fails when a tool may not change p: it is protected by
+// safety.neverModify, or container mode is on and p is outside the
+// container's writable mounts.
+func checkWritePolicy(p string) error {
+	if err := checkNeverModify(p); err != nil {
+		return err
+	}
+	c := activeContainer.Load()
+	if c == nil {
+		return nil
+	}
+	cwd, _ := os.Getwd()
+	if abs := ResolveToCwd(p, cwd); !c.Writable(abs) {
+		return fmt.Errorf("%w: %s is outside the container's writable mounts; container mode keeps changes inside them", ErrSafetyPolicy, abs)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+// ABOUTME: Tests for container mode in the tools: bash goes through the runtime, writes stay in the mounts
+// ABOUTME: Not parallel: the container is process-wide; a fake runtime script stands in for Docker
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/container"
+)
+
+// startFakeContainer sets a container whose runtime is a script echoing its
+// arguments, with root mounted read-write.
+func startFakeContainer(t *testing.T, root string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake runtime is a shell script")
+	}
+	bin := filepath.Join(t.TempDir(), "docker")
+	if err := os.WriteFile(bin, []byte("#!/bin/sh\necho \"runtime: $*\"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	c, err := container.Start(context.Background(), container.Config{Runtime: bin}, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetContainer(c)
+	t.Cleanup(func() { SetContainer(nil) })
+}
+
+func TestCheckWritePolicy_Container(t *testing.T) {
+	root := t.TempDir()
+	startFakeContainer(t, root)
+
+	if err := checkWritePolicy(filepath.Join(root, "src", "main.go")); err != nil {
+		t.Errorf("a path in the project mount was refused: %v", err)
+	}
+	outside := filepath.Join(t.TempDir(), "notes.txt")
+	if err := checkWritePolicy(outside); !errors.Is(err, ErrSafetyPolicy) {
+		t.Errorf("checkWritePolicy(%q) = %v; want a safety policy error", outside, err)
+	}
+
+	result, err := NewWriteTool().Execute(context.Background(), "id", map[string]any{"path": outside, "content": "x"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.IsError || !strings.Contains(result.Content, "writable mounts") {
+		t.Errorf("write outside the mounts = %+v; want it refused", result)
+	}
+	if _, err := os.Stat(outside); !os.IsNotExist(err) {
+		t.Error("the file outside the mounts was written")
+	}
+}
+
+func TestBash_RunsInContainer(t *testing.T) {
+	root := t.TempDir()
+	startFakeContainer(t, root)
+
+	tool := NewBashTool()
+	if !strings.Contains(tool.Description, "docker container") {
+		t.Errorf("description = %q; want it to name the container", tool.Description)
+	}
+	result, err := tool.Execute(context.Background(), "id", map[string]any{"command": "uname -a", "cwd": root}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(result.Content, "runtime: exec --interactive --workdir "+root) || !strings.Contains(result.Content, "uname -a") {
+		t.Errorf("output = %q; want the command sent to the runtime", result.Content)
+	}
+}
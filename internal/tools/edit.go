@@ -1,5 +1,5 @@
 // ABOUTME: Edit tool: surgical text replacement within existing files
-// ABOUTME: Validates paths via sandbox; supports single and replace-all modes
+// ABOUTME: Validates paths via sandbox; single and replace-all modes; pluggable match strategies
 
 package tools
 
@@ -17,15 +17,20 @@ import (
 
 // NewEditTool creates a tool that performs text replacement in files.
 func NewEditTool() *agent.AgentTool {
-	return newEditTool(nil)
+	return newEditTool(nil, nil)
 }
 
 // NewEditToolWithSandbox creates an edit tool that validates paths against the sandbox.
 func NewEditToolWithSandbox(sb *permission.Sandbox) *agent.AgentTool {
-	return newEditTool(sb)
+	return newEditTool(sb, nil)
 }
 
-func newEditTool(sb *permission.Sandbox) *agent.AgentTool {
+// newEditTool creates the edit tool. strategies is the match policy tried
+// in order; nil uses DefaultEditStrategies.
+func newEditTool(sb *permission.Sandbox, strategies []MatchStrategy) *agent.AgentTool {
+	if len(strategies) == 0 {
+		strategies = DefaultEditStrategies
+	}
 	return &agent.AgentTool{
 		Name:        "edit",
 		Label:       "Edit File",
@@ -35,19 +40,23 @@ Usage:
 - The edit will FAIL if old_string is not found in the file
 - The edit will FAIL if old_string is not unique in the file (appears more than once)
   unless replace_all is set to true
-- Exact whitespace matching is required: old_string must match the file content exactly,
-  including indentation (tabs vs spaces) and line endings
+- Aim for an exact match, including indentation (tabs vs spaces) and line endings.
+  Match strategies are tried in order until one finds old_string:
+  exact (byte for byte), whitespace (whitespace runs compare equal), and
+  anchor (whole lines from the first to the last line of old_string; the lines
+  between may differ; new_string replaces those whole lines)
 - Use replace_all for renaming variables or replacing repeated patterns across the file
 
 Output:
-- Returns a unified diff showing the changes made
+- Returns the match strategy that succeeded, then a unified diff of the changes
 - Maximum file size: 10MB
 
 Parameters:
 - path (required): Absolute path to the file to modify
 - old_string (required): The exact text to find in the file
 - new_string (required): The replacement text (must differ from old_string)
-- replace_all: Set to true to replace all occurrences (default: false)`,
+- replace_all: Set to true to replace all occurrences (default: false)
+- match: Use only this strategy: exact, whitespace, or anchor (default: the configured order)`,
 		Parameters: json.RawMessage(`{
 			"type": "object",
 			"required": ["path", "old_string", "new_string"],
@@ -55,17 +64,18 @@ Parameters:
 				"path":        {"type": "string", "description": "Absolute path to the file"},
 				"old_string":  {"type": "string", "description": "Text to find"},
 				"new_string":  {"type": "string", "description": "Replacement text"},
-				"replace_all": {"type": "boolean", "description": "Replace all occurrences (default false)"}
+				"replace_all": {"type": "boolean", "description": "Replace all occurrences (default false)"},
+				"match":       {"type": "string", "enum": ["exact", "whitespace", "anchor"], "description": "Use only this match strategy"}
 			}
 		}`),
 		ReadOnly: false,
 		Execute: func(ctx context.Context, id string, params map[string]any, onUpdate func(agent.ToolUpdate)) (agent.ToolResult, error) {
-			return executeEdit(sb, ctx, id, params, onUpdate)
+			return executeEdit(sb, strategies, ctx, id, params, onUpdate)
 		},
 	}
 }
 
-func executeEdit(sb *permission.Sandbox, _ context.Context, _ string, params map[string]any, _ func(agent.ToolUpdate)) (agent.ToolResult, error) {
+func executeEdit(sb *permission.Sandbox, strategies []MatchStrategy, _ context.Context, _ string, params map[string]any, _ func(agent.ToolUpdate)) (agent.ToolResult, error) {
 	rawPath, err := requireStringParam(params, "path")
 	if err != nil {
 		return errResult(err), nil
@@ -73,6 +83,9 @@ func executeEdit(sb *permission.Sandbox, _ context.Context, _ string, params map
 
 	path := ExpandPath(rawPath)
 
+	if err := checkWritePolicy(path); err != nil {
+		return errResult(err), nil
+	}
 	if sb != nil {
 		if err := sb.ValidatePath(path); err != nil {
 			return errResult(err), nil
@@ -91,6 +104,14 @@ func executeEdit(sb *permission.Sandbox, _ context.Context, _ string, params map
 
 	replaceAll := boolParam(params, "replace_all", false)
 
+	if m := stringParam(params, "match", ""); m != "" {
+		override, err := ParseMatchStrategies([]string{m})
+		if err != nil {
+			return errResult(err), nil
+		}
+		strategies = override
+	}
+
 	info, err := os.Stat(path)
 	if err != nil {
 		return errResult(fmt.Errorf("stat file %s: %w", path, err)), nil
@@ -105,7 +126,7 @@ func executeEdit(sb *permission.Sandbox, _ context.Context, _ string, params map
 	}
 
 	original := string(data)
-	result, err := applyReplacement(original, oldStr, newStr, replaceAll)
+	result, used, err := applyReplacement(original, oldStr, newStr, replaceAll, strategies)
 	if err != nil {
 		return errResult(err), nil
 	}
@@ -115,22 +136,29 @@ func executeEdit(sb *permission.Sandbox, _ context.Context, _ string, params map
 	}
 
 	d := diff.Simple(path, original, result)
-	return agent.ToolResult{Content: d}, nil
+	return agent.ToolResult{Content: fmt.Sprintf("Match strategy: %s\n%s", used, d)}, nil
 }
 
-// applyReplacement performs the string substitution with uniqueness checks.
-func applyReplacement(content, oldStr, newStr string, replaceAll bool) (string, error) {
-	count := strings.Count(content, oldStr)
-	if count == 0 {
-		return "", fmt.Errorf("old_string not found in file")
-	}
-	if count > 1 && !replaceAll {
-		return "", fmt.Errorf("old_string found %d times; set replace_all=true to replace all", count)
-	}
-
-	if replaceAll {
-		return strings.ReplaceAll(content, oldStr, newStr), nil
+// applyReplacement performs the substitution with the first strategy that
+// finds oldStr, enforcing uniqueness unless replaceAll is set. It returns
+// the strategy used; on failure the error lists what each strategy found.
+func applyReplacement(content, oldStr, newStr string, replaceAll bool, strategies []MatchStrategy) (string, MatchStrategy, error) {
+	var tried []string
+	for _, strategy := range strategies {
+		spans, repl := findMatches(strategy, content, oldStr, newStr)
+		switch {
+		case len(spans) == 0:
+			tried = append(tried, fmt.Sprintf("%s: no match", strategy))
+			continue
+		case len(spans) > 1 && (!replaceAll || strategy == MatchAnchor):
+			if strategy == MatchExact {
+				return "", strategy, fmt.Errorf("old_string found %d times; set replace_all=true to replace all", len(spans))
+			}
+			// A looser strategy is ambiguous here; never guess between regions.
+			tried = append(tried, fmt.Sprintf("%s: %d matches", strategy, len(spans)))
+			continue
+		}
+		return replaceSpans(content, spans, repl), strategy, nil
 	}
-
-	return strings.Replace(content, oldStr, newStr, 1), nil
+	return "", "", fmt.Errorf("old_string not found in file (%s)", strings.Join(tried, "; "))
 }
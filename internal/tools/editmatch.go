@@ -0,0 +1,195 @@
+// ABOUTME: Edit tool match strategies: exact, whitespace-insensitive, and anchor-based location of old_string
+// ABOUTME: A policy tries strategies in order; the one that matched is reported in the tool result
+
+package tools
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// MatchStrategy locates old_string in a file for the edit tool.
+type MatchStrategy string
+
+const (
+	// MatchExact requires old_string to appear byte for byte.
+	MatchExact MatchStrategy = "exact"
+	// MatchWhitespace treats any run of whitespace as equal to any other
+	// and ignores leading and trailing whitespace of old_string.
+	MatchWhitespace MatchStrategy = "whitespace"
+	// MatchAnchor matches whole lines from the first to the last non-blank
+	// line of old_string, compared without indentation; the lines between
+	// may differ. It never replaces more than one region.
+	MatchAnchor MatchStrategy = "anchor"
+)
+
+// DefaultEditStrategies is the policy used when none is configured.
+var DefaultEditStrategies = []MatchStrategy{MatchExact, MatchWhitespace}
+
+// anchorMaxStretch bounds an anchor region at this many times the line
+// count of old_string, so distant repeats of an anchor line do not match.
+const anchorMaxStretch = 2
+
+// ParseMatchStrategies validates strategy names. An empty list yields
+// DefaultEditStrategies.
+func ParseMatchStrategies(names []string) ([]MatchStrategy, error) {
+	if len(names) == 0 {
+		return DefaultEditStrategies, nil
+	}
+	out := make([]MatchStrategy, 0, len(names))
+	for _, n := range names {
+		s := MatchStrategy(strings.TrimSpace(n))
+		switch s {
+		case MatchExact, MatchWhitespace, MatchAnchor:
+			out = append(out, s)
+		default:
+			return nil, fmt.Errorf("unknown edit match strategy %q (want exact, whitespace, or anchor)", n)
+		}
+	}
+	return out, nil
+}
+
+// span is a byte range [start, end) of the file content.
+type span struct{ start, end int }
+
+// findMatches returns the non-overlapping regions of content that strategy
+// matches for oldStr, and newStr adjusted for that strategy.
+func findMatches(strategy MatchStrategy, content, oldStr, newStr string) ([]span, string) {
+	switch strategy {
+	case MatchWhitespace:
+		return matchWhitespace(content, oldStr, newStr)
+	case MatchAnchor:
+		return matchAnchor(content, oldStr), newStr
+	default:
+		return matchExact(content, oldStr), newStr
+	}
+}
+
+func matchExact(content, oldStr string) []span {
+	if oldStr == "" {
+		return nil
+	}
+	var spans []span
+	for off := 0; ; {
+		i := strings.Index(content[off:], oldStr)
+		if i < 0 {
+			return spans
+		}
+		spans = append(spans, span{off + i, off + i + len(oldStr)})
+		off += i + len(oldStr)
+	}
+}
+
+// normalized is content with each whitespace run collapsed to one space,
+// keeping the original byte range of every normalized byte.
+type normalized struct {
+	text  string
+	start []int
+	end   []int
+}
+
+func normalizeWhitespace(s string) normalized {
+	var n normalized
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		if isSpaceByte(s[i]) {
+			j := i
+			for j < len(s) && isSpaceByte(s[j]) {
+				j++
+			}
+			b.WriteByte(' ')
+			n.start = append(n.start, i)
+			n.end = append(n.end, j)
+			i = j
+			continue
+		}
+		b.WriteByte(s[i])
+		n.start = append(n.start, i)
+		n.end = append(n.end, i+1)
+		i++
+	}
+	n.text = b.String()
+	return n
+}
+
+func isSpaceByte(c byte) bool {
+	return c < 0x80 && unicode.IsSpace(rune(c))
+}
+
+func matchWhitespace(content, oldStr, newStr string) ([]span, string) {
+	trimmed := strings.TrimSpace(oldStr)
+	if trimmed == "" {
+		return nil, newStr
+	}
+	// Drop the surrounding whitespace old_string carried into new_string,
+	// so a mis-indented first line is not indented twice.
+	lead := oldStr[:len(oldStr)-len(strings.TrimLeftFunc(oldStr, unicode.IsSpace))]
+	trail := oldStr[len(strings.TrimRightFunc(oldStr, unicode.IsSpace)):]
+	newStr = strings.TrimSuffix(strings.TrimPrefix(newStr, lead), trail)
+
+	c := normalizeWhitespace(content)
+	needle := normalizeWhitespace(trimmed).text
+	var spans []span
+	for off := 0; ; {
+		i := strings.Index(c.text[off:], needle)
+		if i < 0 {
+			return spans, newStr
+		}
+		first, last := off+i, off+i+len(needle)-1
+		spans = append(spans, span{c.start[first], c.end[last]})
+		off = last + 1
+	}
+}
+
+func matchAnchor(content, oldStr string) []span {
+	var want []string
+	for _, l := range strings.Split(oldStr, "\n") {
+		if t := strings.TrimSpace(l); t != "" {
+			want = append(want, t)
+		}
+	}
+	if len(want) < 2 {
+		return nil // a single line has no second anchor
+	}
+	first, last := want[0], want[len(want)-1]
+	maxLines := len(want) * anchorMaxStretch
+
+	lines := strings.SplitAfter(content, "\n")
+	offsets := make([]int, len(lines)+1)
+	for i, l := range lines {
+		offsets[i+1] = offsets[i] + len(l)
+	}
+
+	var spans []span
+	for i := 0; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) != first {
+			continue
+		}
+		for j := i + 1; j < len(lines) && j-i < maxLines; j++ {
+			if strings.TrimSpace(lines[j]) == last {
+				end := offsets[j+1]
+				if strings.HasSuffix(lines[j], "\n") {
+					end-- // keep the final newline
+				}
+				spans = append(spans, span{offsets[i], end})
+				i = j
+				break
+			}
+		}
+	}
+	return spans
+}
+
+// replaceSpans substitutes newStr for each span of content.
+func replaceSpans(content string, spans []span, newStr string) string {
+	var b strings.Builder
+	prev := 0
+	for _, s := range spans {
+		b.WriteString(content[prev:s.start])
+		b.WriteString(newStr)
+		prev = s.end
+	}
+	b.WriteString(content[prev:])
+	return b.String()
+}
@@ -0,0 +1,151 @@
+// ABOUTME: Tests for edit match strategies: exact, whitespace-insensitive, anchor, and policy fallback
+// ABOUTME: Checks the strategy reported in tool results and per-call overrides via the match parameter
+
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApplyReplacement_Strategies(t *testing.T) {
+	t.Parallel()
+
+	content := "func main() {\n\tif ok {\n\t\trun()\n\t}\n}\n"
+	tests := []struct {
+		name       string
+		old, new   string
+		strategies []MatchStrategy
+		want       string
+		used       MatchStrategy
+		wantErr    string
+	}{
+		{
+			name: "exact", old: "run()", new: "start()",
+			strategies: DefaultEditStrategies,
+			want:       "func main() {\n\tif ok {\n\t\tstart()\n\t}\n}\n", used: MatchExact,
+		},
+		{
+			name: "whitespace fallback with spaces for tabs", old: "    if ok {\n        run()", new: "    if ok {\n        stop()",
+			strategies: DefaultEditStrategies,
+			want:       "func main() {\n\tif ok {\n        stop()\n\t}\n}\n", used: MatchWhitespace,
+		},
+		{
+			name: "anchor tolerates a stale middle", old: "if ok {\n\tgo run()\n}", new: "\tif !ok {\n\t\treturn\n\t}",
+			strategies: []MatchStrategy{MatchExact, MatchWhitespace, MatchAnchor},
+			want:       "func main() {\n\tif !ok {\n\t\treturn\n\t}\n}\n", used: MatchAnchor,
+		},
+		{
+			name: "exact only reports each strategy tried", old: "if  ok {", new: "x",
+			strategies: []MatchStrategy{MatchExact},
+			wantErr:    "not found in file (exact: no match)",
+		},
+		{
+			name: "anchor needs two lines", old: "run()  ", new: "x",
+			strategies: []MatchStrategy{MatchAnchor},
+			wantErr:    "anchor: no match",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, used, err := applyReplacement(content, tt.old, tt.new, false, tt.strategies)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want || used != tt.used {
+				t.Errorf("got %q via %s, want %q via %s", got, used, tt.want, tt.used)
+			}
+		})
+	}
+}
+
+func TestApplyReplacement_LooseAmbiguityIsNotGuessed(t *testing.T) {
+	t.Parallel()
+
+	content := "a  =  1\na = 1 \n"
+	_, _, err := applyReplacement(content, "a =\t1", "b = 2", false, DefaultEditStrategies)
+	if err == nil || !strings.Contains(err.Error(), "whitespace: 2 matches") {
+		t.Fatalf("err = %v, want ambiguous whitespace match reported", err)
+	}
+
+	got, used, err := applyReplacement(content, "a =\t1", "b = 2", true, DefaultEditStrategies)
+	if err != nil || got != "b = 2\nb = 2 \n" || used != MatchWhitespace {
+		t.Errorf("replace_all = %q via %s, %v", got, used, err)
+	}
+}
+
+func TestParseMatchStrategies(t *testing.T) {
+	t.Parallel()
+
+	got, err := ParseMatchStrategies(nil)
+	if err != nil || len(got) != len(DefaultEditStrategies) {
+		t.Errorf("empty = %v, %v; want the default", got, err)
+	}
+	got, err = ParseMatchStrategies([]string{"anchor", " exact"})
+	if err != nil || len(got) != 2 || got[0] != MatchAnchor || got[1] != MatchExact {
+		t.Errorf("got %v, %v", got, err)
+	}
+	if _, err := ParseMatchStrategies([]string{"fuzzy"}); err == nil {
+		t.Error("expected error for an unknown strategy")
+	}
+}
+
+func TestEditTool_MatchOverrideAndReport(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "edit.go")
+	if err := os.WriteFile(path, []byte("x :=  1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tool := NewEditTool()
+	params := map[string]any{"path": path, "old_string": "x := 1", "new_string": "x := 2", "match": "exact"}
+	result, _ := tool.Execute(context.Background(), "id1", params, nil)
+	if !result.IsError || !strings.Contains(result.Content, "exact: no match") {
+		t.Fatalf("match=exact should fail, got %q", result.Content)
+	}
+
+	delete(params, "match")
+	result, _ = tool.Execute(context.Background(), "id2", params, nil)
+	if result.IsError || !strings.HasPrefix(result.Content, "Match strategy: whitespace\n") {
+		t.Fatalf("default policy should report whitespace, got %q", result.Content)
+	}
+
+	params["match"] = "fuzzy"
+	if result, _ = tool.Execute(context.Background(), "id3", params, nil); !result.IsError {
+		t.Error("unknown match strategy should be an error")
+	}
+}
+
+func TestRegistry_SetEditStrategies(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(path, []byte("one\ntwo\nthree\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewRegistry()
+	r.SetEditStrategies([]MatchStrategy{MatchAnchor})
+	result, _ := r.Get("edit").Execute(context.Background(), "id1", map[string]any{
+		"path": path, "old_string": "one\nTWO\nthree", "new_string": "1\n2\n3",
+	}, nil)
+	if result.IsError || !strings.Contains(result.Content, "Match strategy: anchor") {
+		t.Fatalf("anchor policy should apply, got %q", result.Content)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "1\n2\n3\n" {
+		t.Errorf("file = %q", data)
+	}
+}
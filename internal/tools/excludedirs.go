@@ -0,0 +1,87 @@
+// ABOUTME: Directories pruned by file scans: grep, find, code navigation tools, and @-mention completion
+// ABOUTME: Defaults cover dependency and build output; the excludeDirs setting adds names or globs or keeps defaults
+
+package tools
+
+import (
+	"maps"
+	"path"
+	"slices"
+	"strings"
+	"sync/atomic"
+)
+
+// DefaultExcludedDirs are the directory names file scans prune unless the
+// excludeDirs setting keeps them.
+var DefaultExcludedDirs = []string{
+	".git", "node_modules", "vendor", "__pycache__", ".venv", ".tox", "dist", "build", "target",
+}
+
+// dirExclusions is a parsed excludeDirs setting.
+type dirExclusions struct {
+	names    map[string]bool
+	patterns []string // entries with glob metacharacters, matched with path.Match
+}
+
+var excludedDirs atomic.Pointer[dirExclusions]
+
+func init() {
+	SetExcludedDirs(nil)
+}
+
+// SetExcludedDirs sets the directories pruned by file scans: the defaults
+// plus spec, whose entries are directory names or globs such as
+// "*.egg-info". An entry "!name" scans a default directory again. ".git"
+// is always pruned. Call it once at startup, before the tools run.
+func SetExcludedDirs(spec []string) {
+	ex := &dirExclusions{names: make(map[string]bool)}
+	for _, name := range DefaultExcludedDirs {
+		ex.names[name] = true
+	}
+	for _, entry := range spec {
+		entry = strings.Trim(strings.TrimSpace(entry), "/")
+		switch {
+		case entry == "" || entry == "!":
+		case strings.HasPrefix(entry, "!"):
+			delete(ex.names, entry[1:])
+		case strings.ContainsAny(entry, "*?["):
+			ex.patterns = append(ex.patterns, entry)
+		default:
+			ex.names[entry] = true
+		}
+	}
+	ex.names[".git"] = true
+	excludedDirs.Store(ex)
+}
+
+// IsExcludedDir reports whether file scans prune a directory named name.
+func IsExcludedDir(name string) bool {
+	ex := excludedDirs.Load()
+	if ex.names[name] {
+		return true
+	}
+	for _, p := range ex.patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ExcludedDirs returns the pruned directory names and globs, sorted.
+func ExcludedDirs() []string {
+	ex := excludedDirs.Load()
+	out := append(slices.Collect(maps.Keys(ex.names)), ex.patterns...)
+	slices.Sort(out)
+	return out
+}
+
+// rgExcludeArgs returns ripgrep --glob arguments pruning the excluded
+// directories, which ripgrep only skips when they are gitignored.
+func rgExcludeArgs() []string {
+	var args []string
+	for _, name := range ExcludedDirs() {
+		args = append(args, "--glob", "!"+name+"/")
+	}
+	return args
+}
@@ -0,0 +1,58 @@
+// ABOUTME: Tests for excluded directories: defaults, added names and globs, "!" re-includes, and rg arguments
+// ABOUTME: Not parallel: the exclusions are process-wide and restored after each test
+
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSetExcludedDirs(t *testing.T) {
+	t.Cleanup(func() { SetExcludedDirs(nil) })
+	SetExcludedDirs([]string{".gradle/", "*.egg-info", "!vendor", "!.git"})
+
+	for name, want := range map[string]bool{
+		"node_modules": true,
+		"target":       true,
+		".gradle":      true,
+		"pkg.egg-info": true,
+		"vendor":       false,
+		".git":         true, // always pruned
+		"src":          false,
+	} {
+		if got := IsExcludedDir(name); got != want {
+			t.Errorf("IsExcludedDir(%q) = %v; want %v", name, got, want)
+		}
+	}
+	if args := rgExcludeArgs(); !slices.Contains(args, "!.gradle/") || slices.Contains(args, "!vendor/") {
+		t.Errorf("rgExcludeArgs = %v", args)
+	}
+}
+
+func TestFindBuiltin_PrunesExcludedAndIgnored(t *testing.T) {
+	t.Cleanup(func() { SetExcludedDirs(nil) })
+	SetExcludedDirs([]string{"generated"})
+
+	dir := t.TempDir()
+	for _, sub := range []string{".git", "generated", "target", "logs", "src"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeTestFile(t, filepath.Join(dir, ".gitignore"), "logs/\n")
+	for _, f := range []string{"src/main.go", "generated/api.go", "target/out.go", "logs/run.go", ".hidden.go"} {
+		writeTestFile(t, filepath.Join(dir, f), "package x\n")
+	}
+
+	out, err := findBuiltin("**/*.go", dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(out); got != filepath.Join(dir, "src", "main.go") {
+		t.Errorf("find = %q; want only src/main.go", got)
+	}
+}
@@ -0,0 +1,58 @@
+// ABOUTME: Minion fan-out tool: the main model maps one instruction over many files via parallel minions
+// ABOUTME: Returns per-file minion summaries so the main model can review and merge the results
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
+)
+
+// NewMinionFanOutTool creates the minion_fanout tool. Sub-agents run on the
+// minion model with the file tools from allTools; the tool only works while
+// the minion is in plural mode.
+func NewMinionFanOutTool(minion *agent.Minion, allTools []*agent.AgentTool) *agent.AgentTool {
+	return &agent.AgentTool{
+		Name:  "minion_fanout",
+		Label: "Minion Fan-Out",
+		Description: "Apply one instruction to many files in parallel, one cheap minion sub-agent per file " +
+			"(e.g. \"add doc comments to every exported function\"). Only for independent per-file edits; " +
+			"works only when the user has enabled plural minion mode. Returns each minion's summary: " +
+			"review the changed files and fix or redo anything wrong before answering.",
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"required": ["instruction", "files"],
+			"properties": {
+				"instruction": {"type": "string", "description": "What to do to each file; self-contained, the minions see nothing else"},
+				"files":       {"type": "array", "items": {"type": "string"}, "description": "Paths of the files to process, one minion each"}
+			}
+		}`),
+		Execute: func(ctx context.Context, _ string, params map[string]any, onUpdate func(agent.ToolUpdate)) (agent.ToolResult, error) {
+			instruction, err := requireStringParam(params, "instruction")
+			if err != nil {
+				return errResult(err), nil
+			}
+			files, err := requireStringSliceParam(params, "files")
+			if err != nil {
+				return errResult(err), nil
+			}
+
+			results, err := minion.FanOut(WithReadCache(ctx, nil), instruction, files, allTools, func(p agent.FanOutProgress) {
+				if onUpdate != nil && p.File != "" {
+					onUpdate(agent.ToolUpdate{Output: fmt.Sprintf("[%d/%d] %s\n", p.Done, p.Total, p.File)})
+				}
+			})
+			if errors.Is(err, agent.ErrNotPlural) {
+				return errResult(fmt.Errorf("%w; edit the files directly, or ask the user to run /minion plural", err)), nil
+			}
+			if err != nil {
+				return errResult(err), nil
+			}
+			return agent.ToolResult{Content: agent.FormatFanOutResults(results)}, nil
+		},
+	}
+}
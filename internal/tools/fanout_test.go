@@ -0,0 +1,77 @@
+// ABOUTME: Tests for the minion_fanout tool: plural-mode gating, argument checks, and result formatting
+// ABOUTME: Uses a stub provider that ends every minion turn with a fixed summary
+
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+type summaryProvider struct{}
+
+func (summaryProvider) Api() ai.Api { return ai.ApiAnthropic }
+
+func (summaryProvider) Stream(context.Context, *ai.Model, *ai.Context, *ai.StreamOptions) *ai.EventStream {
+	stream := ai.NewEventStream(4)
+	go func() {
+		stream.Send(ai.StreamEvent{Type: ai.EventContentDelta, Text: "Added docs."})
+		stream.Finish(&ai.AssistantMessage{
+			Content:    []ai.Content{{Type: ai.ContentText, Text: "Added docs."}},
+			StopReason: ai.StopEndTurn,
+		})
+	}()
+	return stream
+}
+
+func newFanOutMinion(mode agent.MinionMode) *agent.Minion {
+	return agent.NewMinion(summaryProvider{}, &ai.Model{ID: "mini", Name: "Mini", Api: ai.ApiAnthropic}, mode)
+}
+
+func TestMinionFanOutTool_RequiresPluralMode(t *testing.T) {
+	t.Parallel()
+
+	tool := NewMinionFanOutTool(newFanOutMinion(agent.MinionAuto), nil)
+	res, err := tool.Execute(context.Background(), "id", map[string]any{"instruction": "add docs", "files": []any{"a.go"}}, nil)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if !res.IsError || !strings.Contains(res.Content, "/minion plural") {
+		t.Errorf("expected actionable plural-mode error, got %+v", res)
+	}
+}
+
+func TestMinionFanOutTool_RequiresFiles(t *testing.T) {
+	t.Parallel()
+
+	tool := NewMinionFanOutTool(newFanOutMinion(agent.MinionPlural), nil)
+	res, _ := tool.Execute(context.Background(), "id", map[string]any{"instruction": "add docs", "files": []any{}}, nil)
+	if !res.IsError {
+		t.Errorf("expected error for empty files, got %+v", res)
+	}
+}
+
+func TestMinionFanOutTool_ReturnsPerFileSummaries(t *testing.T) {
+	t.Parallel()
+
+	tool := NewMinionFanOutTool(newFanOutMinion(agent.MinionPlural), nil)
+	var updates []string
+	res, err := tool.Execute(context.Background(), "id",
+		map[string]any{"instruction": "add docs", "files": []any{"a.go", "b.go"}},
+		func(u agent.ToolUpdate) { updates = append(updates, u.Output) })
+	if err != nil || res.IsError {
+		t.Fatalf("Execute: %v %+v", err, res)
+	}
+	for _, want := range []string{"## a.go (ok)", "## b.go (ok)", "Added docs."} {
+		if !strings.Contains(res.Content, want) {
+			t.Errorf("result missing %q:\n%s", want, res.Content)
+		}
+	}
+	if len(updates) != 2 || !strings.HasPrefix(updates[1], "[2/2]") {
+		t.Errorf("updates = %q, want one per file", updates)
+	}
+}
@@ -0,0 +1,208 @@
+// ABOUTME: Cached, incrementally refreshed index of a project's files, shared by find and @-mention completion
+// ABOUTME: A refresh re-reads only directories whose mtime or .gitignore changed; gitignore and excludeDirs apply
+
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxIndexEntries caps an index so that a tree such as a home directory
+// stays bounded. A capped index is incomplete, and find walks instead.
+const maxIndexEntries = 100_000
+
+// mtimeSlack is how long after its last change a directory is re-read on
+// every refresh, since a change made within the mtime resolution of the
+// file system leaves the mtime as it was.
+const mtimeSlack = 2 * time.Second
+
+// IndexEntry is one file or directory of a FileIndex.
+type IndexEntry struct {
+	Path    string // absolute path
+	RelPath string // path relative to the index root
+	Size    int64
+	ModTime time.Time
+	IsDir   bool
+}
+
+// indexDir is the cached listing of one directory: the entries left after
+// gitignore rules and excluded directories are applied.
+type indexDir struct {
+	modTime   time.Time      // directory mtime when read
+	readAt    time.Time      // when it was read
+	ignoreMod time.Time      // mtime of its .gitignore; zero when there is none
+	parent    *ignoreMatcher // rules the listing was filtered with
+	ignore    *ignoreMatcher // rules for its entries, passed to subdirectories
+	names     []indexName    // in lexical order
+}
+
+type indexName struct {
+	name  string
+	isDir bool
+}
+
+// FileIndex lists the files and directories under a root, hidden ones
+// included, leaving out gitignored paths and excluded directories. Entries
+// rescans the tree by stat: a directory whose mtime and .gitignore are
+// unchanged keeps its cached listing, so a refresh costs a stat per entry
+// instead of a directory read and ignore-file parse per directory.
+type FileIndex struct {
+	root string
+	top  *ignoreMatcher
+
+	mu       sync.Mutex
+	dirs     map[string]*indexDir // by absolute path
+	entries  []IndexEntry
+	complete bool
+}
+
+// NewFileIndex creates an empty index of root; the first Entries call
+// builds it.
+func NewFileIndex(root string) *FileIndex {
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs
+	}
+	return &FileIndex{root: root, top: newIgnoreMatcher(root), dirs: make(map[string]*indexDir)}
+}
+
+// Root returns the absolute directory the index covers.
+func (x *FileIndex) Root() string { return x.root }
+
+// Entries brings the index up to date and returns its entries in path
+// order, each directory before its contents. complete is false when the
+// tree has more than maxIndexEntries entries and the list stops there.
+func (x *FileIndex) Entries() (entries []IndexEntry, complete bool) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	seen := make(map[string]bool)
+	x.entries = x.entries[:0]
+	x.complete = true
+	x.scan(x.root, x.top, seen)
+	for dir := range x.dirs {
+		if !seen[dir] {
+			delete(x.dirs, dir)
+		}
+	}
+	return append([]IndexEntry(nil), x.entries...), x.complete
+}
+
+// scan appends the entries under dir, re-reading dir only when its cached
+// listing may be out of date.
+func (x *FileIndex) scan(dir string, parent *ignoreMatcher, seen map[string]bool) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return
+	}
+	seen[dir] = true
+	ignoreMod := fileModTime(filepath.Join(dir, ".gitignore"))
+	d := x.dirs[dir]
+	if d == nil || d.parent != parent || !d.modTime.Equal(info.ModTime()) ||
+		!d.ignoreMod.Equal(ignoreMod) || d.readAt.Sub(d.modTime) < mtimeSlack {
+		d = readIndexDir(dir, parent, info.ModTime(), ignoreMod)
+		x.dirs[dir] = d
+	}
+
+	for _, n := range d.names {
+		if len(x.entries) >= maxIndexEntries {
+			x.complete = false
+			return
+		}
+		fpath := filepath.Join(dir, n.name)
+		info, err := os.Stat(fpath)
+		if err != nil {
+			continue
+		}
+		rel, _ := filepath.Rel(x.root, fpath)
+		x.entries = append(x.entries, IndexEntry{
+			Path:    fpath,
+			RelPath: rel,
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+			IsDir:   n.isDir,
+		})
+		if n.isDir {
+			x.scan(fpath, d.ignore, seen)
+		}
+	}
+}
+
+// readIndexDir lists dir, dropping what parent's rules or dir's own
+// .gitignore ignore and the excluded directories. Like walkFiles, it keeps
+// regular files and directories only.
+func readIndexDir(dir string, parent *ignoreMatcher, modTime, ignoreMod time.Time) *indexDir {
+	d := &indexDir{modTime: modTime, readAt: time.Now(), ignoreMod: ignoreMod, parent: parent, ignore: parent.child(dir)}
+	list, err := os.ReadDir(dir)
+	if err != nil {
+		return d
+	}
+	for _, e := range list {
+		fpath := filepath.Join(dir, e.Name())
+		switch {
+		case e.IsDir():
+			if !IsExcludedDir(e.Name()) && !d.ignore.ignored(fpath, true) {
+				d.names = append(d.names, indexName{name: e.Name(), isDir: true})
+			}
+		case e.Type().IsRegular():
+			if !d.ignore.ignored(fpath, false) {
+				d.names = append(d.names, indexName{name: e.Name()})
+			}
+		}
+	}
+	return d
+}
+
+// fileModTime returns the mtime of name, or the zero time when it is missing.
+func fileModTime(name string) time.Time {
+	info, err := os.Stat(name)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+var (
+	projectIndexMu sync.Mutex
+	projectIndexes = map[string]*FileIndex{}
+)
+
+// ProjectIndex returns the process-wide index of root, creating it on first
+// use. The find tool answers from it for paths under root.
+func ProjectIndex(root string) *FileIndex {
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs
+	}
+	projectIndexMu.Lock()
+	defer projectIndexMu.Unlock()
+	x, ok := projectIndexes[root]
+	if !ok {
+		x = NewFileIndex(root)
+		projectIndexes[root] = x
+	}
+	return x
+}
+
+// projectIndexFor returns the deepest project index whose root contains
+// path, or nil when there is none.
+func projectIndexFor(path string) *FileIndex {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil
+	}
+	projectIndexMu.Lock()
+	defer projectIndexMu.Unlock()
+	var best *FileIndex
+	for root, x := range projectIndexes {
+		rel, err := filepath.Rel(root, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if best == nil || len(root) > len(best.root) {
+			best = x
+		}
+	}
+	return best
+}
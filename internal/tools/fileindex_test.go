@@ -0,0 +1,155 @@
+// ABOUTME: Tests for the project file index: incremental refresh by directory mtime, gitignore, and the cap
+// ABOUTME: Also checks that find answers from an index covering its path and matches the walk's results
+
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func indexPaths(t *testing.T, x *FileIndex) string {
+	t.Helper()
+	entries, complete := x.Entries()
+	if !complete {
+		t.Fatal("index incomplete")
+	}
+	var paths []string
+	for _, e := range entries {
+		p := filepath.ToSlash(e.RelPath)
+		if e.IsDir {
+			p += "/"
+		}
+		paths = append(paths, p)
+	}
+	return strings.Join(paths, " ")
+}
+
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFileIndex_Entries(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		".git/HEAD":         "ref: refs/heads/main\n",
+		".gitignore":        "*.log\n",
+		".github/ci.yml":    "",
+		"main.go":           "",
+		"debug.log":         "",
+		"node_modules/a.js": "",
+		"pkg/b/b.go":        "",
+	})
+	want := ".github/ .github/ci.yml .gitignore main.go pkg/ pkg/b/ pkg/b/b.go"
+	if got := indexPaths(t, NewFileIndex(root)); got != want {
+		t.Errorf("Entries = %q; want %q", got, want)
+	}
+}
+
+func TestFileIndex_Incremental(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{".git/HEAD": "", "a.go": "", "sub/b.go": ""})
+	sub := filepath.Join(root, "sub")
+	past := time.Now().Add(-time.Hour)
+	for _, dir := range []string{root, sub} {
+		if err := os.Chtimes(dir, past, past); err != nil {
+			t.Fatal(err)
+		}
+	}
+	x := NewFileIndex(root)
+	if got := indexPaths(t, x); got != "a.go sub/ sub/b.go" {
+		t.Fatalf("Entries = %q", got)
+	}
+
+	// A directory whose mtime is unchanged keeps its cached listing.
+	writeTree(t, root, map[string]string{"sub/c.go": ""})
+	if err := os.Chtimes(sub, past, past); err != nil {
+		t.Fatal(err)
+	}
+	if got := indexPaths(t, x); got != "a.go sub/ sub/b.go" {
+		t.Errorf("Entries re-read an unchanged directory: %q", got)
+	}
+
+	// A changed mtime, or a changed .gitignore, re-reads it.
+	now := time.Now()
+	if err := os.Chtimes(sub, now, now); err != nil {
+		t.Fatal(err)
+	}
+	if got := indexPaths(t, x); got != "a.go sub/ sub/b.go sub/c.go" {
+		t.Errorf("Entries after a directory change = %q", got)
+	}
+	writeTree(t, root, map[string]string{".gitignore": "b.go\n"})
+	if got := indexPaths(t, x); got != ".gitignore a.go sub/ sub/c.go" {
+		t.Errorf("Entries after a .gitignore change = %q", got)
+	}
+	if err := os.RemoveAll(sub); err != nil {
+		t.Fatal(err)
+	}
+	if got := indexPaths(t, x); got != ".gitignore a.go" {
+		t.Errorf("Entries after removing a directory = %q", got)
+	}
+}
+
+func TestFindIndexed(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		".git/HEAD":       "",
+		".gitignore":      "gen/\n",
+		".hidden/x.go":    "",
+		"main.go":         "",
+		"gen/out.go":      "",
+		"sub/deep/two.go": "",
+		"sub/one.go":      "",
+	})
+	x := ProjectIndex(root)
+	t.Cleanup(func() {
+		projectIndexMu.Lock()
+		delete(projectIndexes, x.Root())
+		projectIndexMu.Unlock()
+	})
+
+	for _, tc := range []struct{ pattern, path string }{
+		{"*.go", root},
+		{"**/*.go", root},
+		{"*.go", filepath.Join(root, "sub")},
+		{"*.go", filepath.Join(root, ".hidden")},
+	} {
+		got, ok := findIndexed(tc.pattern, tc.path, 0)
+		if !ok {
+			t.Errorf("findIndexed(%q, %q) did not use the index", tc.pattern, tc.path)
+			continue
+		}
+		want, err := findBuiltin(tc.pattern, tc.path, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("findIndexed(%q, %q) = %q; want the walk's %q", tc.pattern, tc.path, got, want)
+		}
+	}
+	for _, path := range []string{filepath.Join(root, "gen"), t.TempDir()} {
+		if _, ok := findIndexed("*.go", path, 0); ok {
+			t.Errorf("findIndexed answered for %s, which the index does not cover", path)
+		}
+	}
+
+	res, err := NewFindTool(false).Execute(context.Background(), "f1", map[string]any{"pattern": "*.go", "path": root}, nil)
+	if err != nil || !strings.Contains(res.Content, filepath.Join(root, "sub", "one.go")) || strings.Contains(res.Content, "out.go") {
+		t.Errorf("find = %q, %v", res.Content, err)
+	}
+}
@@ -1,5 +1,5 @@
 // ABOUTME: Find tool: discovers files matching a glob pattern in a directory tree
-// ABOUTME: Supports ** globs, mod-time sorting (newest first), head_limit; uses rg or stdlib
+// ABOUTME: Supports ** globs, mod-time sorting (newest first), head_limit; uses the project index, rg, or stdlib
 
 package tools
 
@@ -30,7 +30,7 @@ Features:
 - ** glob patterns: Match files in any subdirectory depth
 - Modification time sorting: Results are sorted newest-first
 - head_limit: Limit the number of results returned
-- Automatically skips common non-source directories (.git, node_modules, vendor, etc.)`,
+- Skips hidden files, paths ignored by .gitignore, and dependency and build directories (node_modules, vendor, target, etc.; configurable with the excludeDirs setting)`,
 		Parameters: json.RawMessage(`{
 			"type": "object",
 			"required": ["pattern"],
@@ -55,10 +55,12 @@ func makeFindExecutor(hasRg bool) func(context.Context, string, map[string]any,
 		path := stringParam(params, "path", ".")
 		headLimit := intParam(params, "head_limit", 0)
 
-		var output string
-		if hasRg {
+		output, indexed := findIndexed(pattern, path, headLimit)
+		switch {
+		case indexed:
+		case hasRg:
 			output, err = findWithRg(ctx, pattern, path, headLimit)
-		} else {
+		default:
 			output, err = findBuiltin(pattern, path, headLimit)
 		}
 		if err != nil {
@@ -72,7 +74,8 @@ func makeFindExecutor(hasRg bool) func(context.Context, string, map[string]any,
 
 // findWithRg uses ripgrep's --files mode with a glob filter, sorted by mod time.
 func findWithRg(ctx context.Context, pattern, path string, headLimit int) (string, error) {
-	args := []string{"--files", "--glob", pattern, "--sortr", "modified", path}
+	args := append([]string{"--files", "--glob", pattern, "--sortr", "modified"}, rgExcludeArgs()...)
+	args = append(args, path)
 	cmd := exec.CommandContext(ctx, "rg", args...)
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
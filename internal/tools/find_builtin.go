@@ -1,13 +1,14 @@
-// ABOUTME: Built-in find fallback using filepath.WalkDir with ** glob support
+// ABOUTME: Built-in find over the project file index, or the gitignore-aware file walk, with ** glob support
 // ABOUTME: Sorts results by modification time (newest first), supports head_limit
 
 package tools
 
 import (
+	"cmp"
 	"fmt"
 	"os"
 	"path/filepath"
-	"sort"
+	"slices"
 	"strings"
 )
 
@@ -18,59 +19,115 @@ type fileEntry struct {
 }
 
 // findBuiltin searches for files matching a glob pattern using the standard library.
-// Results are sorted by modification time (newest first).
+// It walks the same files as ripgrep --files (see walkFiles). Results are
+// sorted by modification time, newest first, then by path.
 func findBuiltin(pattern, path string, headLimit int) (string, error) {
-	hasDoubleStar := strings.Contains(pattern, "**")
 	var entries []fileEntry
-
-	err := filepath.WalkDir(path, func(fpath string, d os.DirEntry, err error) error {
-		if err != nil {
-			return nil
-		}
-		if d.IsDir() {
-			if shouldSkipDir(d.Name()) {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-
+	err := walkFiles(path, func(fpath string, d os.DirEntry) {
 		// Use relative path for pattern matching so "sub/**/*.go" works
 		relPath, relErr := filepath.Rel(path, fpath)
 		if relErr != nil {
 			relPath = fpath
 		}
-
-		var matched bool
-		if hasDoubleStar {
-			matched = matchDoubleStarGlob(relPath, pattern)
-		} else {
-			matched = matchGlob(filepath.Base(relPath), pattern)
+		if !findMatch(pattern, relPath) {
+			return
 		}
-
-		if matched {
-			info, statErr := d.Info()
-			if statErr != nil {
-				return nil
-			}
-			entries = append(entries, fileEntry{
-				Path:    fpath,
-				ModTime: info.ModTime().UnixNano(),
-			})
+		info, statErr := d.Info()
+		if statErr != nil {
+			return
 		}
-		return nil
+		entries = append(entries, fileEntry{
+			Path:    fpath,
+			ModTime: info.ModTime().UnixNano(),
+		})
 	})
 
 	if err != nil {
 		return "", fmt.Errorf("walking %s: %w", path, err)
 	}
+	return formatFindEntries(entries, headLimit), nil
+}
 
+// findIndexed answers a search of path from the project index that covers
+// it, listing what findBuiltin would. ok is false when no complete index
+// covers path, or path is a directory the index leaves out.
+func findIndexed(pattern, path string, headLimit int) (output string, ok bool) {
+	x := projectIndexFor(path)
+	if x == nil {
+		return "", false
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+	all, complete := x.Entries()
+	if !complete {
+		return "", false
+	}
+	prefix, _ := filepath.Rel(x.Root(), abs)
+	covered := prefix == "."
+	if covered {
+		prefix = ""
+	} else {
+		prefix += string(filepath.Separator)
+	}
+
+	var entries []fileEntry
+	for _, e := range all {
+		if e.IsDir {
+			covered = covered || e.RelPath+string(filepath.Separator) == prefix
+			continue
+		}
+		if !strings.HasPrefix(e.RelPath, prefix) {
+			continue
+		}
+		relPath := e.RelPath[len(prefix):]
+		if isHiddenPath(relPath) || !findMatch(pattern, relPath) {
+			continue
+		}
+		entries = append(entries, fileEntry{
+			Path:    filepath.Join(path, relPath),
+			ModTime: e.ModTime.UnixNano(),
+		})
+	}
+	if !covered {
+		return "", false
+	}
+	return formatFindEntries(entries, headLimit), true
+}
+
+// findMatch reports whether relPath, relative to the searched directory,
+// matches pattern: a ** pattern matches the whole path, any other the name.
+func findMatch(pattern, relPath string) bool {
+	if strings.Contains(pattern, "**") {
+		return matchDoubleStarGlob(filepath.ToSlash(relPath), pattern)
+	}
+	return matchGlob(filepath.Base(relPath), pattern)
+}
+
+// isHiddenPath reports whether any element of a relative path is dot-named,
+// which walkFiles and ripgrep skip.
+func isHiddenPath(rel string) bool {
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		if strings.HasPrefix(part, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// formatFindEntries sorts entries newest first, then by path, and lists the
+// first headLimit of them (all when headLimit is 0).
+func formatFindEntries(entries []fileEntry, headLimit int) string {
 	if len(entries) == 0 {
-		return "no files found", nil
+		return "no files found"
 	}
 
-	// Sort by modification time, newest first
-	sort.Slice(entries, func(i, j int) bool {
-		return entries[i].ModTime > entries[j].ModTime
+	slices.SortFunc(entries, func(a, b fileEntry) int {
+		if c := cmp.Compare(b.ModTime, a.ModTime); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Path, b.Path)
 	})
 
 	if headLimit > 0 && headLimit < len(entries) {
@@ -81,5 +138,5 @@ func findBuiltin(pattern, path string, headLimit int) (string, error) {
 	for _, e := range entries {
 		fmt.Fprintln(&b, e.Path)
 	}
-	return b.String(), nil
+	return b.String()
 }
@@ -0,0 +1,206 @@
+// ABOUTME: Gitignore-aware file walk for the built-in grep and find: .gitignore files, .git/info/exclude, negation
+// ABOUTME: Rules load per directory as a walk descends; the last rule matching a path decides, as in git
+
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// walkFiles calls visit for each regular file under root in lexical order,
+// leaving out what ripgrep leaves out: hidden files and directories and
+// gitignored paths. Excluded directories (see SetExcludedDirs) are pruned.
+func walkFiles(root string, visit func(fpath string, d os.DirEntry)) error {
+	matchers := map[string]*ignoreMatcher{}
+	top := newIgnoreMatcher(root)
+	return filepath.WalkDir(root, func(fpath string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if fpath == root {
+			matchers[fpath] = top.child(fpath)
+			return nil
+		}
+		ignore := matchers[filepath.Dir(fpath)]
+		hidden := strings.HasPrefix(d.Name(), ".")
+		if d.IsDir() {
+			if hidden || shouldSkipDir(d.Name()) || ignore.ignored(fpath, true) {
+				return filepath.SkipDir
+			}
+			matchers[fpath] = ignore.child(fpath)
+			return nil
+		}
+		if d.Type().IsRegular() && !hidden && !ignore.ignored(fpath, false) {
+			visit(fpath, d)
+		}
+		return nil
+	})
+}
+
+// ignoreRule is one pattern line of an ignore file.
+type ignoreRule struct {
+	base     string   // directory the pattern is relative to
+	segments []string // pattern split on "/"
+	negate   bool     // "!pattern" re-includes a path
+	dirOnly  bool     // "pattern/" matches directories only
+	anchored bool     // a pattern with a "/" matches from base, not at any depth
+}
+
+// ignoreMatcher holds the rules in effect for one directory of a walk. A
+// nil matcher, used outside a git repository, ignores nothing.
+type ignoreMatcher struct {
+	rules []ignoreRule
+}
+
+// newIgnoreMatcher returns the rules that apply above root: the repository's
+// info/exclude and the .gitignore files of root's ancestors inside it. The
+// walker adds root's own .gitignore through child. It returns nil when root
+// is not inside a git repository, as ripgrep ignores .gitignore there.
+func newIgnoreMatcher(root string) *ignoreMatcher {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil
+	}
+	top := abs
+	for {
+		if _, err := os.Stat(filepath.Join(top, ".git")); err == nil {
+			break
+		}
+		parent := filepath.Dir(top)
+		if parent == top {
+			return nil
+		}
+		top = parent
+	}
+
+	m := &ignoreMatcher{rules: loadIgnoreFile(top, filepath.Join(top, ".git", "info", "exclude"))}
+	if abs == top {
+		return m
+	}
+	rel, _ := filepath.Rel(top, filepath.Dir(abs))
+	dir := top
+	m = m.child(dir)
+	if rel != "." {
+		for _, part := range strings.Split(rel, string(filepath.Separator)) {
+			dir = filepath.Join(dir, part)
+			m = m.child(dir)
+		}
+	}
+	return m
+}
+
+// child returns the matcher for the entries of dir: m plus the rules of
+// dir's .gitignore, or m itself when there is none.
+func (m *ignoreMatcher) child(dir string) *ignoreMatcher {
+	if m == nil {
+		return nil
+	}
+	rules := loadIgnoreFile(dir, filepath.Join(dir, ".gitignore"))
+	if len(rules) == 0 {
+		return m
+	}
+	return &ignoreMatcher{rules: append(m.rules[:len(m.rules):len(m.rules)], rules...)}
+}
+
+// ignored reports whether p is excluded. Rules are absolute to their base,
+// so p is resolved before matching.
+func (m *ignoreMatcher) ignored(p string, isDir bool) bool {
+	if m == nil || len(m.rules) == 0 {
+		return false
+	}
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return false
+	}
+	ignored := false
+	for _, r := range m.rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		rel, err := filepath.Rel(r.base, abs)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		var match bool
+		if r.anchored {
+			match = matchSegments(r.segments, strings.Split(rel, "/"))
+		} else {
+			match, _ = path.Match(r.segments[0], path.Base(rel))
+		}
+		if match {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
+
+// loadIgnoreFile parses the ignore file at name, whose patterns are
+// relative to base. A missing file has no rules.
+func loadIgnoreFile(base, name string) []ignoreRule {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil
+	}
+	return parseIgnore(base, data)
+}
+
+// parseIgnore parses gitignore syntax: comments, blank lines, "!" negation,
+// a trailing "/" for directories, and "**" for any number of directories.
+func parseIgnore(base string, data []byte) []ignoreRule {
+	var rules []ignoreRule
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(strings.TrimSuffix(scanner.Text(), "\r"), " \t")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		r := ignoreRule{base: base}
+		if strings.HasPrefix(line, "!") {
+			r.negate = true
+			line = line[1:]
+		}
+		line = strings.TrimPrefix(line, `\`) // "\#" and "\!" are literal
+		if strings.HasSuffix(line, "/") {
+			r.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		r.anchored = strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+		if line == "" {
+			continue
+		}
+		r.segments = strings.Split(line, "/")
+		rules = append(rules, r)
+	}
+	return rules
+}
+
+// matchSegments matches path segments against pattern segments, where a
+// "**" segment matches zero or more path segments.
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			rest := pattern[1:]
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(rest, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
@@ -0,0 +1,75 @@
+// ABOUTME: Tests for gitignore matching: anchoring, directory-only rules, negation, and "**"
+// ABOUTME: Also checks rules from ancestor directories and that paths outside a repository are never ignored
+
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIgnoreMatcher_Rules(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	m := &ignoreMatcher{rules: parseIgnore(root, []byte(`# build output
+*.log
+!keep.log
+/bin
+tmp/
+docs/**/*.html
+\#notes
+`))}
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"app.log", false, true},
+		{"sub/deep/app.log", false, true},
+		{"keep.log", false, false},
+		{"bin", true, true},
+		{"sub/bin", true, false}, // anchored to the root
+		{"tmp", true, true},
+		{"sub/tmp", true, true},
+		{"tmp", false, false}, // directories only
+		{"docs/index.html", false, true},
+		{"docs/api/v1/index.html", false, true},
+		{"site/docs/index.html", false, false},
+		{"#notes", false, true},
+		{"main.go", false, false},
+	}
+	for _, tt := range tests {
+		if got := m.ignored(filepath.Join(root, tt.path), tt.isDir); got != tt.want {
+			t.Errorf("ignored(%q, dir=%v) = %v; want %v", tt.path, tt.isDir, got, tt.want)
+		}
+	}
+}
+
+func TestNewIgnoreMatcher_Ancestors(t *testing.T) {
+	t.Parallel()
+	repo := t.TempDir()
+	for _, dir := range []string{".git/info", "pkg/api"} {
+		if err := os.MkdirAll(filepath.Join(repo, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeTestFile(t, filepath.Join(repo, ".gitignore"), "*.gen.go\n")
+	writeTestFile(t, filepath.Join(repo, "pkg", ".gitignore"), "!keep.gen.go\n")
+	writeTestFile(t, filepath.Join(repo, ".git", "info", "exclude"), "scratch.txt\n")
+
+	api := filepath.Join(repo, "pkg", "api")
+	m := newIgnoreMatcher(api).child(api)
+	for name, want := range map[string]bool{"types.gen.go": true, "keep.gen.go": false, "scratch.txt": true, "api.go": false} {
+		if got := m.ignored(filepath.Join(api, name), false); got != want {
+			t.Errorf("ignored(%s) = %v; want %v", name, got, want)
+		}
+	}
+
+	outside := t.TempDir()
+	writeTestFile(t, filepath.Join(outside, ".gitignore"), "*\n")
+	if m := newIgnoreMatcher(outside).child(outside); m.ignored(filepath.Join(outside, "a.go"), false) {
+		t.Error(".gitignore outside a repository should not apply")
+	}
+}
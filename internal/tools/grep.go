@@ -1,5 +1,5 @@
 // ABOUTME: Grep tool: searches file contents using ripgrep or built-in fallback
-// ABOUTME: Supports output modes, context lines, case-insensitive, multiline, head_limit/offset
+// ABOUTME: Supports output modes, context lines, case-insensitive, multiline, and capped, path-sorted pages
 
 package tools
 
@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os/exec"
+	"slices"
 	"strings"
 
 	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
@@ -39,6 +40,12 @@ func (o grepOptions) effectiveOutputMode() string {
 	return o.OutputMode
 }
 
+// contextual reports whether content output has context lines, so its
+// entries are groups of lines rather than single lines.
+func (o grepOptions) contextual() bool {
+	return o.effectiveOutputMode() == "content" && (o.effectiveBefore() > 0 || o.effectiveAfter() > 0)
+}
+
 // effectiveBefore returns the before-context lines, considering Context override.
 func (o grepOptions) effectiveBefore() int {
 	if o.Context > 0 {
@@ -83,9 +90,15 @@ Context lines (content mode only):
 - -B: Lines before each match
 - -C/context: Lines before and after (overrides -A/-B)
 
+Files searched:
+- Like ripgrep: hidden files and paths ignored by .gitignore are skipped unless path names them
+- Binary files are skipped
+
 Pagination:
-- head_limit: Limit output to first N entries (0 = unlimited)
-- offset: Skip first N entries before applying head_limit`,
+- Results are sorted by file path, so pages are stable between calls
+- head_limit: Limit output to first N entries (default and maximum 500)
+- offset: Skip first N entries before applying head_limit
+- When the 500-entry cap cuts the results, a note gives the offset of the next page`,
 		Parameters: json.RawMessage(`{
 			"type": "object",
 			"required": ["pattern"],
@@ -102,7 +115,7 @@ Pagination:
 				"context":     {"type": "integer", "description": "Number of lines before and after each match (overrides -A/-B)"},
 				"-i":          {"type": "boolean", "description": "Case insensitive search"},
 				"-n":          {"type": "boolean", "description": "Show line numbers (content mode, default true)"},
-				"head_limit":  {"type": "integer", "description": "Limit output to first N entries (0 = unlimited)"},
+				"head_limit":  {"type": "integer", "description": "Limit output to first N entries (default and maximum 500)"},
 				"offset":      {"type": "integer", "description": "Skip first N entries before applying head_limit (0 = no skip)"},
 				"multiline":   {"type": "boolean", "description": "Enable multiline mode where . matches newlines"}
 			}
@@ -153,6 +166,9 @@ func makeGrepExecutor(hasRg bool) func(context.Context, string, map[string]any,
 		if err != nil {
 			return errResult(err), nil
 		}
+		if err := checkSecretRead(opts.Path); err != nil {
+			return errResult(err), nil
+		}
 
 		var output string
 		if hasRg {
@@ -185,16 +201,21 @@ func grepWithRg(ctx context.Context, opts grepOptions) (string, error) {
 		if cmd.ProcessState != nil && cmd.ProcessState.ExitCode() == 1 {
 			return "no matches found", nil
 		}
-		return "", fmt.Errorf("ripgrep failed: %s: %w", stderr.String(), err)
+		// Exit code 2 with output means some files could not be read.
+		if stdout.Len() == 0 {
+			return "", fmt.Errorf("ripgrep failed: %s: %w", stderr.String(), err)
+		}
 	}
 
-	raw := stdout.String()
-	return applyPagination(raw, mode, opts.Offset, opts.HeadLimit), nil
+	entries := parseRgOutput(stdout.String(), mode, opts.LineNumbers, opts.contextual())
+	entries = slices.DeleteFunc(entries, func(e grepEntry) bool { return IsSecretFile(e.path) })
+	return formatGrepEntries(entries, opts, true), nil
 }
 
-// buildRgArgs constructs ripgrep CLI arguments from grepOptions.
+// buildRgArgs constructs ripgrep CLI arguments from grepOptions. Paths are
+// NUL-terminated so parseRgOutput can group and sort the output by file.
 func buildRgArgs(opts grepOptions, mode string) []string {
-	var args []string
+	args := []string{"--null", "--with-filename"}
 
 	switch mode {
 	case "files_with_matches":
@@ -225,45 +246,108 @@ func buildRgArgs(opts grepOptions, mode string) []string {
 	if opts.FileType != "" {
 		args = append(args, "--type", opts.FileType)
 	}
+	args = append(args, rgExcludeArgs()...)
 
 	args = append(args, "-e", opts.Pattern)
 	return args
 }
 
-// applyPagination splits output into entries, applies offset and head_limit, and rejoins.
-// For content mode with context, entries are separated by "--\n".
-// For files_with_matches and count, each line is an entry.
-func applyPagination(raw, mode string, offset, headLimit int) string {
-	if offset == 0 && headLimit == 0 {
-		return raw
-	}
+// grepEntry is one unit of grep output for ordering and pagination: a
+// path, a count, a matching line, or a group of lines with context.
+type grepEntry struct {
+	path string
+	text string
+}
 
-	raw = strings.TrimRight(raw, "\n")
-	if raw == "" {
-		return "no matches found"
+// parseRgOutput splits rg --null output into entries sorted by path.
+// ripgrep searches files in parallel, so its file order varies between
+// runs; sorting keeps offset pages stable.
+func parseRgOutput(out, mode string, lineNumbers, contextual bool) []grepEntry {
+	var entries []grepEntry
+	if mode == "files_with_matches" {
+		for _, p := range strings.Split(out, "\x00") {
+			if p = strings.TrimSpace(p); p != "" {
+				entries = append(entries, grepEntry{path: p, text: p})
+			}
+		}
+		slices.SortStableFunc(entries, compareGrepEntries)
+		return entries
 	}
 
-	var entries []string
-	if mode == "content" {
-		entries = strings.Split(raw, "\n--\n")
-	} else {
-		entries = strings.Split(raw, "\n")
+	newGroup := true
+	for _, line := range strings.Split(strings.TrimRight(out, "\n"), "\n") {
+		if line == "--" {
+			newGroup = true
+			continue
+		}
+		path, rest, ok := strings.Cut(line, "\x00")
+		if !ok {
+			continue
+		}
+		sep := ":"
+		if lineNumbers && mode == "content" {
+			if digits := len(rest) - len(strings.TrimLeft(rest, "0123456789")); digits > 0 && digits < len(rest) {
+				sep = rest[digits : digits+1]
+			}
+		}
+		text := path + sep + rest
+		if n := len(entries); contextual && !newGroup && entries[n-1].path == path {
+			entries[n-1].text += "\n" + text
+			continue
+		}
+		entries = append(entries, grepEntry{path: path, text: text})
+		newGroup = false
 	}
+	slices.SortStableFunc(entries, compareGrepEntries)
+	return entries
+}
 
-	if offset > 0 {
-		if offset >= len(entries) {
-			return "no matches found"
-		}
-		entries = entries[offset:]
+// compareGrepEntries orders entries by path.
+func compareGrepEntries(a, b grepEntry) int {
+	return strings.Compare(a.path, b.path)
+}
+
+// maxGrepEntries caps the entries one call returns; head_limit may lower it.
+const maxGrepEntries = 500
+
+// formatGrepEntries applies offset and head_limit and joins the entries.
+// Context groups are separated by "--". When the cap cuts the results, a
+// note gives the offset of the next page; complete is false when the
+// search stopped early, so the total is a lower bound.
+func formatGrepEntries(entries []grepEntry, opts grepOptions, complete bool) string {
+	total := len(entries)
+	offset := max(opts.Offset, 0)
+	if offset >= total {
+		return "no matches found"
 	}
+	entries = entries[offset:]
 
-	if headLimit > 0 && headLimit < len(entries) {
-		entries = entries[:headLimit]
+	limit, capped := opts.HeadLimit, false
+	if limit <= 0 || limit > maxGrepEntries {
+		limit, capped = maxGrepEntries, true
+	}
+	cut := len(entries) > limit
+	if cut {
+		entries = entries[:limit]
 	}
 
 	sep := "\n"
-	if mode == "content" {
+	if opts.contextual() {
 		sep = "\n--\n"
 	}
-	return strings.Join(entries, sep) + "\n"
+	texts := make([]string, len(entries))
+	for i, e := range entries {
+		texts[i] = e.text
+	}
+	out := strings.Join(texts, sep) + "\n"
+
+	if cut && capped || !complete {
+		count := fmt.Sprint(total)
+		if !complete {
+			count = "at least " + count
+		}
+		out += fmt.Sprintf("\n[Showing entries %d-%d of %s. Pass offset: %d for the next page, or narrow the search with path, glob, or type.]\n",
+			offset+1, offset+len(entries), count, offset+len(entries))
+	}
+	return out
 }
@@ -1,22 +1,22 @@
-// ABOUTME: Built-in grep fallback using stdlib regexp and filepath.WalkDir
+// ABOUTME: Built-in grep fallback using stdlib regexp over a gitignore-aware walk, searching files in parallel
 // ABOUTME: Supports all output modes, context lines, case-insensitive, multiline, glob/type filtering
 
 package tools
 
 import (
-	"bufio"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
 )
 
+// maxMatches stops the built-in search once this many entries are found.
 const maxMatches = 10000
 
-// errMatchLimitReached is a sentinel error used to stop walking early.
-var errMatchLimitReached = fmt.Errorf("match limit reached (%d)", maxMatches)
-
 // grepBuiltin searches for pattern matches using the standard library.
 func grepBuiltin(opts grepOptions) (string, error) {
 	mode := opts.effectiveOutputMode()
@@ -34,75 +34,67 @@ func grepBuiltin(opts grepOptions) (string, error) {
 		return "", fmt.Errorf("compiling pattern %q: %w", opts.Pattern, err)
 	}
 
-	if opts.Multiline {
-		return grepMultiline(re, opts, mode)
+	files, err := grepFiles(opts)
+	if err != nil {
+		return "", err
 	}
+	entries, complete := searchFiles(re, files, opts, mode)
+	return formatGrepEntries(entries, opts, complete), nil
+}
 
-	var entries []string
-	matchCount := 0
-
-	walkErr := filepath.WalkDir(opts.Path, func(fpath string, d os.DirEntry, err error) error {
-		if err != nil {
-			return nil
-		}
-		if d.IsDir() {
-			if shouldSkipDir(d.Name()) {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-		if !matchesGrepFilter(fpath, opts) {
-			return nil
-		}
-
-		switch mode {
-		case "files_with_matches":
-			matched, scanErr := fileHasMatch(re, fpath)
-			if scanErr != nil {
-				return nil
-			}
-			if matched {
-				entries = append(entries, fpath)
-				matchCount++
-			}
-		case "count":
-			count, scanErr := countFileMatches(re, fpath)
-			if scanErr != nil {
-				return nil
-			}
-			if count > 0 {
-				entries = append(entries, fmt.Sprintf("%s:%d", fpath, count))
-				matchCount++
-			}
-		default: // "content"
-			fileEntries, scanErr := grepFileContent(re, fpath, opts)
-			if scanErr != nil {
-				return nil
-			}
-			entries = append(entries, fileEntries...)
-			matchCount += len(fileEntries)
-		}
+// grepFiles lists the files a search of opts.Path covers, in path order.
+// A file named directly is searched even when walkFiles would skip it.
+func grepFiles(opts grepOptions) ([]string, error) {
+	info, err := os.Stat(opts.Path)
+	if err != nil {
+		return nil, fmt.Errorf("walking %s: %w", opts.Path, err)
+	}
+	if !info.IsDir() {
+		return []string{opts.Path}, nil
+	}
 
-		if matchCount >= maxMatches {
-			return errMatchLimitReached
+	var files []string
+	err = walkFiles(opts.Path, func(fpath string, _ os.DirEntry) {
+		if matchesGrepFilter(fpath, opts) && !IsSecretFile(fpath) {
+			files = append(files, fpath)
 		}
-		return nil
 	})
-
-	if walkErr != nil && walkErr != errMatchLimitReached {
-		return "", fmt.Errorf("walking %s: %w", opts.Path, walkErr)
+	if err != nil {
+		return nil, fmt.Errorf("walking %s: %w", opts.Path, err)
 	}
+	return files, nil
+}
 
-	if len(entries) == 0 {
-		return "no matches found", nil
+// searchFiles searches files concurrently and returns their entries in
+// file order. Files are claimed in order, so when maxMatches stops the
+// search early the entries are still a prefix of the full result; complete
+// is false then.
+func searchFiles(re *regexp.Regexp, files []string, opts grepOptions, mode string) ([]grepEntry, bool) {
+	results := make([][]grepEntry, len(files))
+	var next, found atomic.Int64
+	var wg sync.WaitGroup
+	for range min(runtime.GOMAXPROCS(0), len(files)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for found.Load() < maxMatches {
+				i := int(next.Add(1)) - 1
+				if i >= len(files) {
+					return
+				}
+				results[i] = grepFile(re, files[i], opts, mode)
+				found.Add(int64(len(results[i])))
+			}
+		}()
 	}
+	wg.Wait()
 
-	result := applyEntryPagination(entries, mode, opts.Offset, opts.HeadLimit)
-
-	if matchCount >= maxMatches {
-		result += fmt.Sprintf("\n... [truncated: %d matches shown, limit reached]\n", maxMatches)
+	var entries []grepEntry
+	for _, r := range results {
+		entries = append(entries, r...)
 	}
-	return result, nil
+	complete := found.Load() < maxMatches || int(next.Load()) >= len(files)
+	return entries, complete
 }
 
 // matchesGrepFilter checks if a file path passes glob and type filters.
@@ -202,241 +194,107 @@ func matchFileType(ext, fileType string) bool {
 	return false
 }
 
-// fileHasMatch scans a file and returns true if any line matches.
-func fileHasMatch(re *regexp.Regexp, path string) (bool, error) {
-	f, err := os.Open(path)
-	if err != nil {
-		return false, err
-	}
-	defer f.Close()
-
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		if re.MatchString(scanner.Text()) {
-			return true, nil
-		}
-	}
-	return false, scanner.Err()
-}
-
-// countFileMatches counts the number of matching lines in a file.
-func countFileMatches(re *regexp.Regexp, path string) (int, error) {
-	f, err := os.Open(path)
-	if err != nil {
-		return 0, err
-	}
-	defer f.Close()
-
-	count := 0
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		if re.MatchString(scanner.Text()) {
-			count++
-		}
-	}
-	return count, scanner.Err()
-}
-
-// grepFileContent returns matching lines with optional context for a single file.
-func grepFileContent(re *regexp.Regexp, path string, opts grepOptions) ([]string, error) {
-	before := opts.effectiveBefore()
-	after := opts.effectiveAfter()
-
-	if before > 0 || after > 0 {
-		return grepFileWithContext(re, path, opts, before, after)
-	}
-
-	f, err := os.Open(path)
-	if err != nil {
-		return nil, err
-	}
-	defer f.Close()
-
-	var entries []string
-	scanner := bufio.NewScanner(f)
-	lineNum := 0
-	for scanner.Scan() {
-		lineNum++
-		line := scanner.Text()
-		if re.MatchString(line) {
-			if opts.LineNumbers {
-				entries = append(entries, fmt.Sprintf("%s:%d:%s", path, lineNum, line))
-			} else {
-				entries = append(entries, fmt.Sprintf("%s:%s", path, line))
-			}
-		}
-	}
-	return entries, scanner.Err()
-}
-
-// grepFileWithContext returns matching lines with before/after context lines.
-// Groups of overlapping context are merged and separated by "--".
-func grepFileWithContext(re *regexp.Regexp, path string, opts grepOptions, before, after int) ([]string, error) {
+// grepFile returns one file's entries for mode. Unreadable and binary
+// files have none.
+func grepFile(re *regexp.Regexp, path string, opts grepOptions, mode string) []grepEntry {
 	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
+	if err != nil || isBinary(data) {
+		return nil
+	}
+	content := string(data)
+	if opts.Multiline {
+		return grepMultiline(re, path, content, opts, mode)
 	}
 
-	lines := strings.Split(string(data), "\n")
-	// Remove trailing empty line from final newline
-	if len(lines) > 0 && lines[len(lines)-1] == "" {
+	lines := strings.Split(content, "\n")
+	if lines[len(lines)-1] == "" {
 		lines = lines[:len(lines)-1]
 	}
-
-	// Find all matching line indices
-	var matchIndices []int
+	var matched []int
 	for i, line := range lines {
-		if re.MatchString(line) {
-			matchIndices = append(matchIndices, i)
+		lines[i] = strings.TrimSuffix(line, "\r")
+		if re.MatchString(lines[i]) {
+			matched = append(matched, i)
 		}
 	}
-
-	if len(matchIndices) == 0 {
-		return nil, nil
+	if len(matched) == 0 {
+		return nil
 	}
 
-	// Build ranges [start, end] for each match with context
-	type lineRange struct{ start, end int }
-	ranges := make([]lineRange, 0, len(matchIndices))
-	for _, idx := range matchIndices {
-		start := idx - before
-		if start < 0 {
-			start = 0
-		}
-		end := idx + after
-		if end >= len(lines) {
-			end = len(lines) - 1
-		}
-		ranges = append(ranges, lineRange{start, end})
+	switch mode {
+	case "files_with_matches":
+		return []grepEntry{{path: path, text: path}}
+	case "count":
+		return []grepEntry{{path: path, text: fmt.Sprintf("%s:%d", path, len(matched))}}
 	}
-
-	// Merge overlapping ranges
-	merged := []lineRange{ranges[0]}
-	for i := 1; i < len(ranges); i++ {
-		last := &merged[len(merged)-1]
-		if ranges[i].start <= last.end+1 {
-			if ranges[i].end > last.end {
-				last.end = ranges[i].end
-			}
-		} else {
-			merged = append(merged, ranges[i])
-		}
+	before, after := opts.effectiveBefore(), opts.effectiveAfter()
+	if before > 0 || after > 0 {
+		return grepContextGroups(path, lines, matched, opts.LineNumbers, before, after)
 	}
-
-	// Build match set for highlighting
-	matchSet := make(map[int]bool, len(matchIndices))
-	for _, idx := range matchIndices {
-		matchSet[idx] = true
+	entries := make([]grepEntry, 0, len(matched))
+	for _, i := range matched {
+		entries = append(entries, grepEntry{path: path, text: grepLine(path, ":", i, lines[i], opts.LineNumbers)})
 	}
+	return entries
+}
 
-	// Emit groups separated by "--"
-	var groups []string
-	for _, r := range merged {
-		var groupLines []string
-		for i := r.start; i <= r.end; i++ {
-			sep := "-"
-			if matchSet[i] {
-				sep = ":"
-			}
-			if opts.LineNumbers {
-				groupLines = append(groupLines, fmt.Sprintf("%s%s%d%s%s", path, sep, i+1, sep, lines[i]))
-			} else {
-				groupLines = append(groupLines, fmt.Sprintf("%s%s%s", path, sep, lines[i]))
-			}
-		}
-		groups = append(groups, strings.Join(groupLines, "\n"))
+// grepLine formats one output line; sep is ":" for a match and "-" for context.
+func grepLine(path, sep string, i int, line string, lineNumbers bool) string {
+	if lineNumbers {
+		return fmt.Sprintf("%s%s%d%s%s", path, sep, i+1, sep, line)
 	}
-
-	// Return as a single entry with groups separated by "--"
-	return []string{strings.Join(groups, "\n--\n")}, nil
+	return path + sep + line
 }
 
-// grepMultiline reads entire files and matches across line boundaries.
-func grepMultiline(re *regexp.Regexp, opts grepOptions, mode string) (string, error) {
-	var entries []string
-	matchCount := 0
-
-	walkErr := filepath.WalkDir(opts.Path, func(fpath string, d os.DirEntry, err error) error {
-		if err != nil {
-			return nil
-		}
-		if d.IsDir() {
-			if shouldSkipDir(d.Name()) {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-		if !matchesGrepFilter(fpath, opts) {
-			return nil
-		}
-
-		data, readErr := os.ReadFile(fpath)
-		if readErr != nil {
-			return nil
+// grepContextGroups returns one entry per group of matches whose before and
+// after context lines overlap or touch.
+func grepContextGroups(path string, lines []string, matched []int, lineNumbers bool, before, after int) []grepEntry {
+	isMatch := make(map[int]bool, len(matched))
+	for _, i := range matched {
+		isMatch[i] = true
+	}
+
+	var entries []grepEntry
+	var group []string
+	end := -1 // last line of the current group
+	for _, idx := range matched {
+		start := max(idx-before, 0)
+		if start > end+1 && group != nil {
+			entries = append(entries, grepEntry{path: path, text: strings.Join(group, "\n")})
+			group = nil
 		}
-		content := string(data)
-
-		matches := re.FindAllStringIndex(content, -1)
-		if len(matches) == 0 {
-			return nil
-		}
-
-		switch mode {
-		case "files_with_matches":
-			entries = append(entries, fpath)
-			matchCount++
-		case "count":
-			entries = append(entries, fmt.Sprintf("%s:%d", fpath, len(matches)))
-			matchCount++
-		default: // "content"
-			for _, loc := range matches {
-				matchText := content[loc[0]:loc[1]]
-				startLine := strings.Count(content[:loc[0]], "\n") + 1
-				if opts.LineNumbers {
-					entries = append(entries, fmt.Sprintf("%s:%d:%s", fpath, startLine, matchText))
-				} else {
-					entries = append(entries, fmt.Sprintf("%s:%s", fpath, matchText))
-				}
-				matchCount++
+		start = max(start, end+1)
+		end = min(idx+after, len(lines)-1)
+		for i := start; i <= end; i++ {
+			sep := "-"
+			if isMatch[i] {
+				sep = ":"
 			}
+			group = append(group, grepLine(path, sep, i, lines[i], lineNumbers))
 		}
-
-		if matchCount >= maxMatches {
-			return errMatchLimitReached
-		}
-		return nil
-	})
-
-	if walkErr != nil && walkErr != errMatchLimitReached {
-		return "", fmt.Errorf("walking %s: %w", opts.Path, walkErr)
-	}
-
-	if len(entries) == 0 {
-		return "no matches found", nil
 	}
-
-	return applyEntryPagination(entries, mode, opts.Offset, opts.HeadLimit), nil
+	return append(entries, grepEntry{path: path, text: strings.Join(group, "\n")})
 }
 
-// applyEntryPagination applies offset and head_limit to a slice of entries.
-func applyEntryPagination(entries []string, mode string, offset, headLimit int) string {
-	if offset > 0 {
-		if offset >= len(entries) {
-			return "no matches found"
-		}
-		entries = entries[offset:]
+// grepMultiline matches across line boundaries; each match is one content entry.
+func grepMultiline(re *regexp.Regexp, path, content string, opts grepOptions, mode string) []grepEntry {
+	matches := re.FindAllStringIndex(content, -1)
+	if len(matches) == 0 {
+		return nil
 	}
 
-	if headLimit > 0 && headLimit < len(entries) {
-		entries = entries[:headLimit]
+	switch mode {
+	case "files_with_matches":
+		return []grepEntry{{path: path, text: path}}
+	case "count":
+		return []grepEntry{{path: path, text: fmt.Sprintf("%s:%d", path, len(matches))}}
 	}
-
-	sep := "\n"
-	if mode == "content" {
-		// For content entries that may already contain context groups, use simple newline
-		sep = "\n"
+	entries := make([]grepEntry, 0, len(matches))
+	for _, loc := range matches {
+		startLine := strings.Count(content[:loc[0]], "\n")
+		entries = append(entries, grepEntry{path: path, text: grepLine(path, ":", startLine, content[loc[0]:loc[1]], opts.LineNumbers)})
 	}
-	return strings.Join(entries, sep) + "\n"
+	return entries
 }
 
 // matchGlob checks if name matches the given glob pattern.
@@ -444,4 +302,3 @@ func matchGlob(name, pattern string) bool {
 	matched, err := filepath.Match(pattern, name)
 	return err == nil && matched
 }
-
@@ -1,5 +1,5 @@
 // ABOUTME: Tests for grep tool: covers all output modes, context lines, case-insensitive,
-// ABOUTME: multiline, head_limit, offset, glob alias, and file type filtering (builtin path).
+// ABOUTME: multiline, head_limit, offset, the entry cap, gitignore, and rg output parsing.
 
 package tools
 
@@ -409,3 +409,98 @@ func TestGrepBuiltin_LineNumbersOff(t *testing.T) {
 		t.Errorf("expected 'hello' in output, got:\n%s", out)
 	}
 }
+
+func TestGrepBuiltin_SkipsIgnoredHiddenAndBinary(t *testing.T) {
+	dir := t.TempDir()
+	for _, sub := range []string{".git", ".cache", "gen"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeTestFile(t, filepath.Join(dir, ".gitignore"), "gen/\n*.tmp\n")
+	writeTestFile(t, filepath.Join(dir, "main.go"), "needle\n")
+	writeTestFile(t, filepath.Join(dir, "gen", "out.go"), "needle\n")
+	writeTestFile(t, filepath.Join(dir, "scratch.tmp"), "needle\n")
+	writeTestFile(t, filepath.Join(dir, ".cache", "c.go"), "needle\n")
+	writeTestFile(t, filepath.Join(dir, ".env"), "needle\n")
+	writeTestFile(t, filepath.Join(dir, "blob.bin"), "needle\x00\n")
+
+	out, err := grepBuiltin(grepOptions{Pattern: "needle", Path: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "main.go") + "\n"; out != want {
+		t.Errorf("out = %q; want only main.go", out)
+	}
+
+	// A file named directly is searched even when ignored.
+	out, _ = grepBuiltin(grepOptions{Pattern: "needle", Path: filepath.Join(dir, "scratch.tmp")})
+	if !strings.Contains(out, "scratch.tmp") {
+		t.Errorf("explicit path = %q", out)
+	}
+}
+
+func TestGrepBuiltin_ContextGroups(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.txt")
+	writeTestFile(t, path, "x\nhit\ny\nz\nw\nhit\n")
+
+	out, err := grepBuiltin(grepOptions{Pattern: "hit", Path: path, OutputMode: "content", Before: 1, LineNumbers: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := path + "-1-x\n" + path + ":2:hit\n--\n" + path + "-5-w\n" + path + ":6:hit\n"
+	if out != want {
+		t.Errorf("out =\n%s\nwant\n%s", out, want)
+	}
+
+	out, _ = grepBuiltin(grepOptions{Pattern: "hit", Path: path, OutputMode: "content", Before: 1, LineNumbers: true, Offset: 1})
+	if want := path + "-5-w\n" + path + ":6:hit\n"; out != want {
+		t.Errorf("offset 1 = %q; want the second group", out)
+	}
+}
+
+func TestGrepBuiltin_CapNotesNextPage(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "many.txt")
+	writeTestFile(t, path, strings.Repeat("match\n", maxGrepEntries+20))
+
+	out, err := grepBuiltin(grepOptions{Pattern: "match", Path: path, OutputMode: "content", LineNumbers: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(out, ":match") != maxGrepEntries {
+		t.Errorf("got %d entries; want the cap of %d", strings.Count(out, ":match"), maxGrepEntries)
+	}
+	if !strings.Contains(out, "Showing entries 1-500 of 520. Pass offset: 500 for the next page") {
+		t.Errorf("missing next-page note: %q", out[len(out)-200:])
+	}
+
+	out, _ = grepBuiltin(grepOptions{Pattern: "match", Path: path, OutputMode: "content", LineNumbers: true, Offset: 500})
+	if strings.Count(out, ":match") != 20 || strings.Contains(out, "Showing") {
+		t.Errorf("last page = %q", out)
+	}
+}
+
+func TestParseRgOutput(t *testing.T) {
+	files := parseRgOutput("b.go\x00a.go\x00", "files_with_matches", true, false)
+	if len(files) != 2 || files[0].text != "a.go" || files[1].text != "b.go" {
+		t.Errorf("files = %+v; want sorted paths", files)
+	}
+
+	counts := parseRgOutput("b.go\x003\na.go\x001\n", "count", true, false)
+	if len(counts) != 2 || counts[0].text != "a.go:1" {
+		t.Errorf("counts = %+v", counts)
+	}
+
+	out := "b.go\x001-before\nb.go\x002:hit\n--\na.go\x007:hit\na.go\x008-after\n"
+	groups := parseRgOutput(out, "content", true, true)
+	if len(groups) != 2 || groups[0].text != "a.go:7:hit\na.go-8-after" || groups[1].text != "b.go-1-before\nb.go:2:hit" {
+		t.Errorf("groups = %+v", groups)
+	}
+
+	lines := parseRgOutput("a.go\x003:one\na.go\x009:two\n", "content", true, false)
+	if len(lines) != 2 || lines[1].text != "a.go:9:two" {
+		t.Errorf("lines = %+v", lines)
+	}
+}
@@ -98,19 +98,6 @@ func requireStringSliceParam(params map[string]any, key string) ([]string, error
 	return out, nil
 }
 
-// skipDirs contains directory names to skip during recursive file walks.
-var skipDirs = map[string]bool{
-	".git":         true,
-	"vendor":       true,
-	"node_modules": true,
-	"__pycache__":  true,
-	".venv":        true,
-	".tox":         true,
-	"dist":         true,
-	"build":        true,
-}
-
-// shouldSkipDir reports whether a directory name should be skipped during walks.
 func shouldSkipDir(name string) bool {
-	return skipDirs[name]
+	return IsExcludedDir(name)
 }
@@ -0,0 +1,163 @@
+// ABOUTME: Per-tool execution limits: a timeout for each call and a cap on the output the model sees
+// ABOUTME: Timed-out calls suggest a background alternative; cut output stays readable through read_tool_output
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
+)
+
+// Limits bounds one tool's calls. Zero fields mean no limit.
+type Limits struct {
+	Timeout        time.Duration // how long a call may run
+	MaxOutputBytes int           // how much of a result the model sees
+}
+
+// DefaultLimits applies to every tool until SetLimits says otherwise.
+var DefaultLimits = Limits{Timeout: 10 * time.Minute, MaxOutputBytes: maxReadOutput}
+
+// timeoutGrace is how long a timed-out call has to return what it has
+// before the call is answered without it.
+const timeoutGrace = 2 * time.Second
+
+// SetLimits bounds each tool's calls by limits(tool name), for tools
+// registered now or later. Like Use, it does not reach tools handed out
+// before the call.
+func (r *Registry) SetLimits(limits func(tool string) Limits) {
+	r.limits = limits
+	for name, t := range r.raw {
+		r.tools[name] = r.wrap(t)
+	}
+}
+
+// limitsFor returns the limits for the named tool.
+func (r *Registry) limitsFor(name string) Limits {
+	if r.limits == nil {
+		return DefaultLimits
+	}
+	return r.limits(name)
+}
+
+// limitCalls returns next bounded by l. Output over the cap is cut in the
+// middle, and the whole of it kept in store for read_tool_output.
+func limitCalls(l Limits, store *OutputStore, next Handler) Handler {
+	if l == (Limits{}) {
+		return next
+	}
+	return func(call *ToolCall) (agent.ToolResult, error) {
+		result, timedOut, err := runWithTimeout(call, l.Timeout, next)
+		if timedOut {
+			result, err = timeoutResult(call.Name, l.Timeout, result, err), nil
+		}
+		if err == nil && l.MaxOutputBytes > 0 && len(result.Content) > l.MaxOutputBytes {
+			result.Content = capOutput(call.ID, result.Content, l.MaxOutputBytes, store)
+		}
+		return result, err
+	}
+}
+
+// runWithTimeout runs next with call's context bounded by timeout and
+// reports whether the bound cut the call short. A call that ignores the
+// cancellation is abandoned after timeoutGrace; its later updates are
+// dropped.
+func runWithTimeout(call *ToolCall, timeout time.Duration, next Handler) (agent.ToolResult, bool, error) {
+	if timeout <= 0 {
+		result, err := next(call)
+		return result, false, err
+	}
+	parent := call.Ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+
+	bounded := *call
+	bounded.Ctx = ctx
+	var abandoned atomic.Bool
+	if call.OnUpdate != nil {
+		bounded.OnUpdate = func(u agent.ToolUpdate) {
+			if !abandoned.Load() {
+				call.OnUpdate(u)
+			}
+		}
+	}
+
+	type outcome struct {
+		result agent.ToolResult
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		result, err := next(&bounded)
+		done <- outcome{result, err}
+	}()
+
+	expired := func() bool {
+		return errors.Is(ctx.Err(), context.DeadlineExceeded) && parent.Err() == nil
+	}
+	select {
+	case o := <-done:
+		// A call that finished right at the deadline keeps its result.
+		return o.result, expired() && (o.err != nil || o.result.IsError), o.err
+	case <-ctx.Done():
+	}
+	if !expired() {
+		// Aborted by the caller: wait for the tool, as without a limit.
+		o := <-done
+		return o.result, false, o.err
+	}
+	select {
+	case o := <-done:
+		return o.result, true, o.err
+	case <-time.After(timeoutGrace):
+		abandoned.Store(true)
+		return agent.ToolResult{}, true, nil
+	}
+}
+
+// timeoutResult reports a call cut short after timeout, keeping whatever
+// output it returned.
+func timeoutResult(tool string, timeout time.Duration, partial agent.ToolResult, err error) agent.ToolResult {
+	msg := fmt.Sprintf("%s timed out after %s (toolLimits in settings). %s", tool, timeout, timeoutHint(tool))
+	if out := strings.TrimRight(partial.Content, "\n"); err == nil && out != "" {
+		msg = out + "\n\n" + msg
+	}
+	return agent.ToolResult{Content: msg, IsError: true}
+}
+
+// timeoutHint suggests how to run work that outlasts a tool's timeout.
+func timeoutHint(tool string) string {
+	switch tool {
+	case "bash":
+		return "Start long-running commands in the background, redirecting their output to a log file, and read the log later."
+	case "task":
+		return "Pass background: true to run the agent in the background."
+	}
+	return "Try a narrower request."
+}
+
+// capOutput cuts content to about maxBytes, keeping its head and tail,
+// and keeps the whole in store under id so the middle can be read back.
+func capOutput(id, content string, maxBytes int, store *OutputStore) string {
+	head := truncateToUTF8Boundary(content, maxBytes/2)
+	tail := truncateTailToUTF8Boundary(content[len(head):], maxBytes-len(head))
+	omitted := len(content) - len(head) - len(tail)
+
+	where := ""
+	if _, summarized := store.Get(id); !summarized {
+		// Long-line handling may have stored the original already; its
+		// offsets differ from these, so only name the id then.
+		store.Put(id, content)
+		where = fmt.Sprintf(" offset=%d length=%d", len(head), omitted)
+	}
+	return fmt.Sprintf("%s\n\n... [%d of %d bytes omitted (toolLimits in settings); read_tool_output with id=%q%s reads them] ...\n\n%s",
+		head, omitted, len(content), id, where, tail)
+}
@@ -0,0 +1,132 @@
+// ABOUTME: Tests for per-tool limits: timeouts with background hints and output capped in the middle
+// ABOUTME: Capped output must stay readable through read_tool_output
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
+)
+
+// waitTool blocks until its context ends, then fails with what it has.
+func waitTool(name string) *agent.AgentTool {
+	return &agent.AgentTool{
+		Name: name,
+		Execute: func(ctx context.Context, _ string, _ map[string]any, _ func(agent.ToolUpdate)) (agent.ToolResult, error) {
+			<-ctx.Done()
+			return agent.ToolResult{Content: "partial", IsError: true}, nil
+		},
+	}
+}
+
+func TestLimits_TimeoutSuggestsBackground(t *testing.T) {
+	t.Parallel()
+	r := NewRegistry()
+	r.Register(waitTool("task"))
+	r.SetLimits(func(string) Limits { return Limits{Timeout: 50 * time.Millisecond} })
+
+	res, err := r.Get("task").Execute(context.Background(), "1", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.IsError {
+		t.Error("timed-out call should be an error")
+	}
+	for _, want := range []string{"partial", "task timed out after 50ms", "background: true"} {
+		if !strings.Contains(res.Content, want) {
+			t.Errorf("result %q lacks %q", res.Content, want)
+		}
+	}
+}
+
+func TestLimits_AbortIsNotATimeout(t *testing.T) {
+	t.Parallel()
+	r := NewRegistry()
+	r.Register(waitTool("slow"))
+	r.SetLimits(func(string) Limits { return Limits{Timeout: time.Minute} })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	res, err := r.Get("slow").Execute(ctx, "1", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Content != "partial" {
+		t.Errorf("aborted call = %q; want the tool's own result", res.Content)
+	}
+}
+
+func TestLimits_CapsOutputReadableLater(t *testing.T) {
+	t.Parallel()
+	r := NewRegistry()
+	content := strings.Repeat("a", 100) + strings.Repeat("m", 800) + strings.Repeat("z", 100)
+	r.Register(&agent.AgentTool{
+		Name: "big",
+		Execute: func(context.Context, string, map[string]any, func(agent.ToolUpdate)) (agent.ToolResult, error) {
+			return agent.ToolResult{Content: content}, nil
+		},
+	})
+	r.SetLimits(func(tool string) Limits {
+		if tool == "big" {
+			return Limits{MaxOutputBytes: 200}
+		}
+		return DefaultLimits
+	})
+
+	res, err := r.Get("big").Execute(context.Background(), "call-1", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(res.Content, strings.Repeat("a", 100)) || !strings.HasSuffix(res.Content, strings.Repeat("z", 100)) {
+		t.Errorf("capped output should keep head and tail: %q", res.Content)
+	}
+	if !strings.Contains(res.Content, "800 of 1000 bytes omitted") || !strings.Contains(res.Content, "offset=100 length=800") {
+		t.Errorf("missing truncation marker: %q", res.Content)
+	}
+
+	back, _ := r.Get("read_tool_output").Execute(context.Background(), "2", map[string]any{
+		"id": "call-1", "offset": float64(100), "length": float64(800),
+	}, nil)
+	if !strings.HasSuffix(back.Content, strings.Repeat("m", 800)) {
+		t.Errorf("read_tool_output = %q; want the omitted middle", back.Content)
+	}
+}
+
+func TestLimits_ToolErrorsPassThrough(t *testing.T) {
+	t.Parallel()
+	r := NewRegistry()
+	boom := errors.New("boom")
+	r.Register(&agent.AgentTool{
+		Name: "fails",
+		Execute: func(context.Context, string, map[string]any, func(agent.ToolUpdate)) (agent.ToolResult, error) {
+			return agent.ToolResult{}, boom
+		},
+	})
+	if _, err := r.Get("fails").Execute(context.Background(), "1", nil, nil); !errors.Is(err, boom) {
+		t.Errorf("err = %v; want the tool's error", err)
+	}
+}
+
+func TestLimits_BashTimeoutKeepsOutput(t *testing.T) {
+	t.Parallel()
+	r := NewRegistry()
+	r.SetLimits(func(string) Limits { return Limits{Timeout: 300 * time.Millisecond} })
+
+	res, err := r.Get("bash").Execute(context.Background(), "1", map[string]any{
+		"command": "echo started; sleep 10",
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.IsError || !strings.HasPrefix(res.Content, "started") {
+		t.Errorf("result = %+v; want an error after the partial output", res)
+	}
+	if !strings.Contains(res.Content, "in the background") {
+		t.Errorf("result %q lacks the background hint", res.Content)
+	}
+}
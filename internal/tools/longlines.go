@@ -0,0 +1,232 @@
+// ABOUTME: Long single-line output handling: pretty-prints small JSON, summarizes pathological lines
+// ABOUTME: Keeps full outputs in a bounded OutputStore so read_tool_output can fetch ranges on demand
+
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
+)
+
+const (
+	longLineThreshold    = 2000      // bytes; lines longer than this are rewritten
+	prettyJSONMaxBytes   = 8 * 1024  // single-line JSON up to this size is pretty-printed
+	longLinePreviewBytes = 120       // bytes of the original line kept in the placeholder
+	outputStoreCapacity  = 64        // full outputs retained for read_tool_output
+	defaultReadLength    = 4000      // default bytes returned by read_tool_output
+	maxReadLength        = 16 * 1024 // upper bound on bytes returned by read_tool_output
+)
+
+// OutputStore retains the original content of tool outputs whose long lines
+// were summarized. It is bounded; the oldest entries are evicted first.
+type OutputStore struct {
+	mu      sync.Mutex
+	entries map[string]string
+	order   []string
+	cap     int
+}
+
+// NewOutputStore creates an empty store holding at most capacity outputs.
+func NewOutputStore(capacity int) *OutputStore {
+	if capacity <= 0 {
+		capacity = outputStoreCapacity
+	}
+	return &OutputStore{entries: make(map[string]string), cap: capacity}
+}
+
+// Put stores content under id, evicting the oldest entry when full.
+func (s *OutputStore) Put(id, content string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.entries[id]; !ok {
+		s.order = append(s.order, id)
+	}
+	s.entries[id] = content
+	for len(s.order) > s.cap {
+		delete(s.entries, s.order[0])
+		s.order = s.order[1:]
+	}
+}
+
+// Get returns the stored content for id.
+func (s *OutputStore) Get(id string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.entries[id]
+	return c, ok
+}
+
+// NormalizeLongLines rewrites lines longer than longLineThreshold.
+// Small single-line JSON is pretty-printed; anything else is replaced by a
+// placeholder describing the line and how to fetch it with read_tool_output.
+// Returns the rewritten content and whether any line was summarized.
+func NormalizeLongLines(id, content string) (string, bool) {
+	if len(content) <= longLineThreshold {
+		return content, false
+	}
+
+	lines := strings.Split(content, "\n")
+	changed, summarized := false, false
+	for i, line := range lines {
+		if len(line) <= longLineThreshold {
+			continue
+		}
+		changed = true
+		if pretty, ok := prettyJSON(line); ok {
+			lines[i] = pretty
+			continue
+		}
+		summarized = true
+		lines[i] = longLinePlaceholder(id, i+1, line)
+	}
+	if !changed {
+		return content, false
+	}
+	return strings.Join(lines, "\n"), summarized
+}
+
+// prettyJSON indents line when it is a JSON object or array small enough to be useful.
+func prettyJSON(line string) (string, bool) {
+	trimmed := strings.TrimSpace(line)
+	if len(trimmed) > prettyJSONMaxBytes || trimmed == "" || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return "", false
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(trimmed), "", "  "); err != nil {
+		return "", false
+	}
+	return buf.String(), true
+}
+
+// longLinePlaceholder summarizes a pathological line.
+func longLinePlaceholder(id string, lineNo int, line string) string {
+	preview := truncateToUTF8Boundary(line, longLinePreviewBytes)
+	return fmt.Sprintf("[line %d: %d bytes of %s omitted; starts with %q; use read_tool_output with id=%q line=%d to read ranges]",
+		lineNo, len(line), classifyLongLine(line), preview, id, lineNo)
+}
+
+// classifyLongLine gives a short description of what a long line looks like.
+func classifyLongLine(line string) string {
+	trimmed := strings.TrimSpace(line)
+	if trimmed != "" && (trimmed[0] == '{' || trimmed[0] == '[') && json.Valid([]byte(trimmed)) {
+		return "minified JSON"
+	}
+	if looksLikeBase64(trimmed) {
+		return "base64-like data"
+	}
+	return "single-line text"
+}
+
+// looksLikeBase64 reports whether s consists solely of base64 alphabet characters.
+func looksLikeBase64(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9':
+		case c == '+', c == '/', c == '=', c == '-', c == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// withLongLineHandling wraps a tool so its output passes through NormalizeLongLines.
+// The original output is kept in store whenever a line was summarized.
+func withLongLineHandling(tool *agent.AgentTool, store *OutputStore) *agent.AgentTool {
+	inner := tool.Execute
+	if inner == nil {
+		return tool
+	}
+	wrapped := *tool
+	wrapped.Execute = func(ctx context.Context, id string, params map[string]any, onUpdate func(agent.ToolUpdate)) (agent.ToolResult, error) {
+		result, err := inner(ctx, id, params, onUpdate)
+		if err != nil {
+			return result, err
+		}
+		content, summarized := NormalizeLongLines(id, result.Content)
+		if summarized {
+			store.Put(id, result.Content)
+		}
+		result.Content = content
+		return result, nil
+	}
+	return &wrapped
+}
+
+// NewReadToolOutputTool creates the read_tool_output tool backed by store.
+func NewReadToolOutputTool(store *OutputStore) *agent.AgentTool {
+	return &agent.AgentTool{
+		Name:  "read_tool_output",
+		Label: "Read Tool Output",
+		Description: `Reads a byte range of a previous tool output whose long lines were summarized.
+
+Use this when a tool result contains a placeholder such as
+"[line 3: 183442 bytes of minified JSON omitted; ... use read_tool_output with id=... line=3 ...]".
+
+Parameters:
+- id (required): Tool call ID named in the placeholder
+- line: 1-based line number to read from (0 = whole output)
+- offset: Byte offset within the line (or output) to start at
+- length: Number of bytes to return (default 4000, max 16384)`,
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"required": ["id"],
+			"properties": {
+				"id":     {"type": "string", "description": "Tool call ID from the placeholder"},
+				"line":   {"type": "integer", "description": "1-based line number (0 = whole output)"},
+				"offset": {"type": "integer", "description": "Byte offset to start reading at"},
+				"length": {"type": "integer", "description": "Number of bytes to return"}
+			}
+		}`),
+		ReadOnly: true,
+		Execute: func(_ context.Context, _ string, params map[string]any, _ func(agent.ToolUpdate)) (agent.ToolResult, error) {
+			return executeReadToolOutput(store, params)
+		},
+	}
+}
+
+func executeReadToolOutput(store *OutputStore, params map[string]any) (agent.ToolResult, error) {
+	id, err := requireStringParam(params, "id")
+	if err != nil {
+		return errResult(err), nil
+	}
+	content, ok := store.Get(id)
+	if !ok {
+		return errResult(fmt.Errorf("no stored output for tool call %q", id)), nil
+	}
+
+	lineNo := intParam(params, "line", 0)
+	if lineNo > 0 {
+		lines := strings.Split(content, "\n")
+		if lineNo > len(lines) {
+			return errResult(fmt.Errorf("line %d out of range (output has %d lines)", lineNo, len(lines))), nil
+		}
+		content = lines[lineNo-1]
+	}
+
+	offset := max(intParam(params, "offset", 0), 0)
+	length := intParam(params, "length", defaultReadLength)
+	if length <= 0 {
+		length = defaultReadLength
+	}
+	length = min(length, maxReadLength)
+
+	if offset >= len(content) {
+		return errResult(fmt.Errorf("offset %d beyond end (%d bytes)", offset, len(content))), nil
+	}
+	end := min(offset+length, len(content))
+	chunk := content[offset:end]
+
+	header := fmt.Sprintf("[bytes %d-%d of %d]\n", offset, end, len(content))
+	return agent.ToolResult{Content: header + chunk}, nil
+}
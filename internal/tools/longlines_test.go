@@ -0,0 +1,138 @@
+// ABOUTME: Tests for long single-line output handling and the read_tool_output tool
+// ABOUTME: Covers JSON pretty-printing, placeholders, store eviction, and range reads
+
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
+)
+
+func TestNormalizeLongLines_ShortContentUnchanged(t *testing.T) {
+	t.Parallel()
+
+	content := "line1\nline2"
+	got, summarized := NormalizeLongLines("id", content)
+	if got != content || summarized {
+		t.Errorf("got (%q, %v); want unchanged", got, summarized)
+	}
+}
+
+func TestNormalizeLongLines_PrettyPrintsSmallJSON(t *testing.T) {
+	t.Parallel()
+
+	items := make([]string, 300)
+	for i := range items {
+		items[i] = `"item"`
+	}
+	line := "[" + strings.Join(items, ",") + "]"
+	got, summarized := NormalizeLongLines("id", "before\n"+line)
+	if summarized {
+		t.Error("small JSON should be pretty-printed, not summarized")
+	}
+	if !strings.HasPrefix(got, "before\n[\n  \"item\",") {
+		t.Errorf("expected indented JSON, got prefix %q", got[:min(40, len(got))])
+	}
+}
+
+func TestNormalizeLongLines_SummarizesBase64(t *testing.T) {
+	t.Parallel()
+
+	line := strings.Repeat("QUJD", 5000)
+	got, summarized := NormalizeLongLines("call_7", "ok\n"+line+"\ndone")
+	if !summarized {
+		t.Fatal("expected summarized = true")
+	}
+	for _, want := range []string{"ok\n", "[line 2: 20000 bytes of base64-like data omitted", `id="call_7" line=2`, "\ndone"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+	if len(got) > 500 {
+		t.Errorf("placeholder output too long: %d bytes", len(got))
+	}
+}
+
+func TestNormalizeLongLines_LargeJSONSummarized(t *testing.T) {
+	t.Parallel()
+
+	line := `{"data":"` + strings.Repeat("x ", 6000) + `"}`
+	got, summarized := NormalizeLongLines("id", line)
+	if !summarized {
+		t.Fatal("JSON above the pretty-print cap should be summarized")
+	}
+	if !strings.Contains(got, "minified JSON") {
+		t.Errorf("expected minified JSON classification, got %q", got)
+	}
+}
+
+func TestOutputStore_Evicts(t *testing.T) {
+	t.Parallel()
+
+	s := NewOutputStore(2)
+	s.Put("a", "1")
+	s.Put("b", "2")
+	s.Put("c", "3")
+	if _, ok := s.Get("a"); ok {
+		t.Error("oldest entry should be evicted")
+	}
+	if v, ok := s.Get("c"); !ok || v != "3" {
+		t.Errorf("Get(c) = (%q, %v)", v, ok)
+	}
+}
+
+func TestReadToolOutput_ReadsLineRange(t *testing.T) {
+	t.Parallel()
+
+	s := NewOutputStore(4)
+	s.Put("call_1", "header\n"+strings.Repeat("abcdefghij", 10))
+	tool := NewReadToolOutputTool(s)
+
+	res, err := tool.Execute(context.Background(), "x", map[string]any{
+		"id": "call_1", "line": float64(2), "offset": float64(10), "length": float64(5),
+	}, nil)
+	if err != nil || res.IsError {
+		t.Fatalf("unexpected error: %v / %q", err, res.Content)
+	}
+	if res.Content != "[bytes 10-15 of 100]\nabcde" {
+		t.Errorf("Content = %q", res.Content)
+	}
+}
+
+func TestReadToolOutput_UnknownID(t *testing.T) {
+	t.Parallel()
+
+	tool := NewReadToolOutputTool(NewOutputStore(1))
+	res, _ := tool.Execute(context.Background(), "x", map[string]any{"id": "missing"}, nil)
+	if !res.IsError {
+		t.Error("expected error for unknown id")
+	}
+}
+
+func TestWithLongLineHandling_StoresOriginal(t *testing.T) {
+	t.Parallel()
+
+	long := strings.Repeat("Z", 5000)
+	inner := &agent.AgentTool{
+		Name: "fake",
+		Execute: func(context.Context, string, map[string]any, func(agent.ToolUpdate)) (agent.ToolResult, error) {
+			return agent.ToolResult{Content: long}, nil
+		},
+	}
+	store := NewOutputStore(4)
+	wrapped := withLongLineHandling(inner, store)
+
+	res, err := wrapped.Execute(context.Background(), "call_9", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(res.Content, long) {
+		t.Error("long line should have been replaced")
+	}
+	if got, ok := store.Get("call_9"); !ok || got != long {
+		t.Error("original output should be retained in the store")
+	}
+}
@@ -0,0 +1,149 @@
+// ABOUTME: Tool middleware: wraps every registered tool's execution for logging, metrics, and policy
+// ABOUTME: Around-style Middleware plus Before and After helpers; the first added runs outermost
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
+	"github.com/mauromedda/pi-coding-agent-go/internal/audit"
+	pilog "github.com/mauromedda/pi-coding-agent-go/internal/log"
+)
+
+// ToolCall is one tool execution as seen by middleware. Middleware may
+// replace Args before calling the next handler.
+type ToolCall struct {
+	Ctx      context.Context
+	ID       string
+	Name     string
+	Args     map[string]any
+	OnUpdate func(agent.ToolUpdate)
+}
+
+// Handler runs a tool call: the tool itself or the rest of the chain.
+type Handler func(call *ToolCall) (agent.ToolResult, error)
+
+// Middleware wraps a Handler; it runs code around next and may skip it
+// to answer the call itself, as a cache or a policy does.
+type Middleware func(next Handler) Handler
+
+// Before returns middleware that runs fn ahead of every call. An error from
+// fn fails the call with the error as its content instead of running it.
+func Before(fn func(call *ToolCall) error) Middleware {
+	return func(next Handler) Handler {
+		return func(call *ToolCall) (agent.ToolResult, error) {
+			if err := fn(call); err != nil {
+				return agent.ToolResult{Content: err.Error(), IsError: true}, nil
+			}
+			return next(call)
+		}
+	}
+}
+
+// After returns middleware that runs fn once every call finishes. fn sees
+// the result and error and may rewrite the result.
+func After(fn func(call *ToolCall, result *agent.ToolResult, err error)) Middleware {
+	return func(next Handler) Handler {
+		return func(call *ToolCall) (agent.ToolResult, error) {
+			result, err := next(call)
+			fn(call, &result, err)
+			return result, err
+		}
+	}
+}
+
+// Use adds middleware around every tool, registered now or later. The
+// first middleware added is the outermost. Tools handed out before the
+// call, such as a sub-agent's tool set, keep their old chain.
+func (r *Registry) Use(mw ...Middleware) {
+	r.middleware = append(r.middleware, mw...)
+	for name, t := range r.raw {
+		r.tools[name] = r.wrap(t)
+	}
+}
+
+// wrap returns tool with the registry's middleware around its Execute,
+// and its limits innermost.
+func (r *Registry) wrap(tool *agent.AgentTool) *agent.AgentTool {
+	limits := r.limitsFor(tool.Name)
+	if len(r.middleware) == 0 && limits == (Limits{}) {
+		return tool
+	}
+	h := Handler(func(call *ToolCall) (agent.ToolResult, error) {
+		return tool.Execute(call.Ctx, call.ID, call.Args, call.OnUpdate)
+	})
+	h = limitCalls(limits, r.outputs, h)
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		h = r.middleware[i](h)
+	}
+	wrapped := *tool
+	wrapped.Execute = func(ctx context.Context, id string, args map[string]any, onUpdate func(agent.ToolUpdate)) (agent.ToolResult, error) {
+		return h(&ToolCall{Ctx: ctx, ID: id, Name: tool.Name, Args: args, OnUpdate: onUpdate})
+	}
+	return &wrapped
+}
+
+// LogCalls is middleware that writes each call's tool, duration, and
+// outcome to the debug log.
+func LogCalls(next Handler) Handler {
+	return func(call *ToolCall) (agent.ToolResult, error) {
+		start := time.Now()
+		result, err := next(call)
+		outcome := fmt.Sprintf("%d bytes", len(result.Content))
+		switch {
+		case err != nil:
+			outcome = "error: " + err.Error()
+		case result.IsError:
+			outcome = "failed, " + outcome
+		}
+		pilog.FromContext(call.Ctx).Debug("tool %s (%s): %s in %s", call.Name, call.ID, outcome, time.Since(start).Round(time.Millisecond))
+		return result, err
+	}
+}
+
+// AuditCalls returns middleware recording each finished call, its
+// arguments' hash, and its status in the audit log.
+func AuditCalls(log *audit.Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(call *ToolCall) (agent.ToolResult, error) {
+			start := time.Now()
+			result, err := next(call)
+			status := "ok"
+			switch {
+			case err != nil:
+				status = "failed"
+			case result.IsError:
+				status = "error"
+			}
+			log.Tool(call.ID, call.Name, call.Args, status, time.Since(start))
+			return result, err
+		}
+	}
+}
+
+// RedactResults returns middleware passing each call's result, and the
+// output it streams while running, through redact, so secrets never reach
+// the model or the session file.
+func RedactResults(redact func(string) string) Middleware {
+	return func(next Handler) Handler {
+		return func(call *ToolCall) (agent.ToolResult, error) {
+			if onUpdate := call.OnUpdate; onUpdate != nil {
+				call.OnUpdate = func(u agent.ToolUpdate) {
+					u.Output = redact(u.Output)
+					if u.Step != nil {
+						step := *u.Step
+						step.Output = redact(step.Output)
+						u.Step = &step
+					}
+					onUpdate(u)
+				}
+			}
+			result, err := next(call)
+			result.Content = redact(result.Content)
+			return result, err
+		}
+	}
+}
@@ -0,0 +1,175 @@
+// ABOUTME: Tests for tool middleware: ordering, Before/After helpers, tools registered later, redaction, and auditing
+// ABOUTME: Also checks that replacing a tool internally does not stack the middleware twice
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
+	"github.com/mauromedda/pi-coding-agent-go/internal/audit"
+)
+
+func echoNamed(name string) *agent.AgentTool {
+	return &agent.AgentTool{
+		Name: name,
+		Execute: func(_ context.Context, _ string, args map[string]any, _ func(agent.ToolUpdate)) (agent.ToolResult, error) {
+			msg, _ := args["msg"].(string)
+			return agent.ToolResult{Content: name + ": " + msg}, nil
+		},
+	}
+}
+
+func TestRegistry_UseOrder(t *testing.T) {
+	t.Parallel()
+	r := NewRegistry()
+	r.Register(echoNamed("echo"))
+
+	var trace []string
+	tag := func(label string) Middleware {
+		return func(next Handler) Handler {
+			return func(call *ToolCall) (agent.ToolResult, error) {
+				trace = append(trace, label+">")
+				res, err := next(call)
+				trace = append(trace, "<"+label)
+				return res, err
+			}
+		}
+	}
+	r.Use(tag("outer"), tag("inner"))
+	r.Register(echoNamed("late"))
+
+	for _, name := range []string{"echo", "late"} {
+		trace = nil
+		res, err := r.Get(name).Execute(context.Background(), "1", map[string]any{"msg": "hi"}, nil)
+		if err != nil || res.Content != name+": hi" {
+			t.Errorf("%s = %+v, %v", name, res, err)
+		}
+		if want := []string{"outer>", "inner>", "<inner", "<outer"}; !slices.Equal(trace, want) {
+			t.Errorf("%s trace = %v; want %v", name, trace, want)
+		}
+	}
+}
+
+func TestRegistry_BeforeAfter(t *testing.T) {
+	t.Parallel()
+	r := NewRegistry()
+	r.Register(echoNamed("echo"))
+
+	var seen []string
+	r.Use(
+		Before(func(call *ToolCall) error {
+			if call.Args["msg"] == "secret" {
+				return errors.New("policy: no secrets")
+			}
+			call.Args = map[string]any{"msg": strings.ToUpper(call.Args["msg"].(string))}
+			return nil
+		}),
+		After(func(call *ToolCall, result *agent.ToolResult, err error) {
+			seen = append(seen, call.Name+"="+result.Content)
+			result.Content += "!"
+		}),
+	)
+
+	echo := r.Get("echo")
+	res, _ := echo.Execute(context.Background(), "1", map[string]any{"msg": "hi"}, nil)
+	if res.Content != "echo: HI!" {
+		t.Errorf("result = %q; want the args rewritten and the result amended", res.Content)
+	}
+	res, _ = echo.Execute(context.Background(), "2", map[string]any{"msg": "secret"}, nil)
+	if !res.IsError || res.Content != "policy: no secrets" {
+		t.Errorf("blocked result = %+v", res)
+	}
+	if !slices.Equal(seen, []string{"echo=echo: HI"}) {
+		t.Errorf("After saw %v; a blocked call never reaches it", seen)
+	}
+}
+
+func TestRegistry_UseAppliesOnce(t *testing.T) {
+	t.Parallel()
+	r := NewRegistry()
+	calls := 0
+	r.Use(Before(func(*ToolCall) error { calls++; return nil }))
+	r.DisableNetworkTools()
+	r.Remove("read")
+	if r.Get("read") != nil {
+		t.Error("Remove left the tool")
+	}
+
+	if _, err := r.Get("webfetch").Execute(context.Background(), "1", map[string]any{"url": "https://example.com"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Errorf("middleware ran %d times; replacing a tool should not wrap it twice", calls)
+	}
+}
+
+func TestRedactResults(t *testing.T) {
+	t.Parallel()
+	r := NewRegistry()
+	r.Register(&agent.AgentTool{
+		Name: "leaky",
+		Execute: func(_ context.Context, _ string, _ map[string]any, onUpdate func(agent.ToolUpdate)) (agent.ToolResult, error) {
+			onUpdate(agent.ToolUpdate{Output: "token=abc", Step: &agent.ToolStep{Output: "token=def"}})
+			return agent.ToolResult{Content: "token=ghi"}, nil
+		},
+	})
+	r.Use(RedactResults(func(s string) string { return strings.ReplaceAll(s, "token=", "token=[REDACTED]#") }))
+
+	var updates []agent.ToolUpdate
+	res, err := r.Get("leaky").Execute(context.Background(), "1", nil, func(u agent.ToolUpdate) { updates = append(updates, u) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Content != "token=[REDACTED]#ghi" {
+		t.Errorf("result = %q", res.Content)
+	}
+	if len(updates) != 1 || updates[0].Output != "token=[REDACTED]#abc" || updates[0].Step.Output != "token=[REDACTED]#def" {
+		t.Errorf("updates = %+v", updates)
+	}
+}
+
+func TestAuditCalls(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	log, err := audit.Open(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewRegistry()
+	r.Register(echoNamed("echo"))
+	r.Register(&agent.AgentTool{
+		Name: "broken",
+		Execute: func(context.Context, string, map[string]any, func(agent.ToolUpdate)) (agent.ToolResult, error) {
+			return agent.ToolResult{Content: "no such file", IsError: true}, nil
+		},
+	})
+	r.Use(AuditCalls(log))
+
+	r.Get("echo").Execute(context.Background(), "1", map[string]any{"msg": "hi"}, nil)
+	r.Get("broken").Execute(context.Background(), "2", nil, nil)
+	log.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("audit log = %q; want 2 lines", data)
+	}
+	for i, want := range []string{`"tool":"echo","callId":"1"`, `"tool":"broken","callId":"2"`} {
+		if !strings.Contains(lines[i], want) {
+			t.Errorf("line %d = %s; want %s", i, lines[i], want)
+		}
+	}
+	if !strings.Contains(lines[0], `"status":"ok"`) || !strings.Contains(lines[1], `"status":"error"`) {
+		t.Errorf("statuses wrong:\n%s", data)
+	}
+}
@@ -0,0 +1,316 @@
+// ABOUTME: Safety policy: files matching safety.neverModify globs are never written, whatever the permission mode
+// ABOUTME: write, edit, and notebook_edit check their path; bash commands are scanned for redirections and file-changing commands
+
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+)
+
+// ErrSafetyPolicy marks a call refused because it would change a file the
+// safety.neverModify setting protects.
+var ErrSafetyPolicy = errors.New("blocked by safety policy")
+
+var neverModify atomic.Pointer[[]string]
+
+// SetNeverModify sets the globs of files no tool may change. A pattern
+// without a slash matches a file or directory name at any depth of the
+// project, such as ".env" or "*.pem"; one with a slash matches the path
+// from the project root, or from / when absolute, such as "deploy/prod/**".
+// "**" matches any number of directories, and a protected directory
+// protects everything below it. Call it once at startup, before the tools
+// run.
+func SetNeverModify(patterns []string) {
+	clean := make([]string, 0, len(patterns))
+	for _, p := range patterns {
+		p = strings.TrimSuffix(strings.TrimSpace(filepath.ToSlash(p)), "/")
+		if rooted, ok := strings.CutPrefix(p, "./"); ok && !strings.Contains(rooted, "/") {
+			// "./name" is the name at the project root only.
+			p = rooted + "/**"
+		}
+		if p != "" && p != "." {
+			clean = append(clean, strings.TrimPrefix(p, "./"))
+		}
+	}
+	neverModify.Store(&clean)
+}
+
+// checkNeverModify fails with ErrSafetyPolicy when p, resolved against the
+// working directory, is protected. Symlinks are followed, so a link does
+// not open a way around the policy.
+func checkNeverModify(p string) error {
+	patterns := neverModify.Load()
+	if patterns == nil || len(*patterns) == 0 {
+		return nil
+	}
+	root, _ := os.Getwd()
+	abs := ResolveToCwd(p, root)
+	candidates := []string{abs}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil && resolved != abs {
+		candidates = append(candidates, resolved)
+	}
+	for _, c := range candidates {
+		if pattern, ok := protectedBy(*patterns, root, c); ok {
+			return fmt.Errorf("%w: %s matches the neverModify pattern %q and must not be changed by any tool; tell the user instead of working around it", ErrSafetyPolicy, p, pattern)
+		}
+	}
+	return nil
+}
+
+// protectedBy returns the first pattern protecting the absolute path abs.
+func protectedBy(patterns []string, root, abs string) (string, bool) {
+	absSlash := filepath.ToSlash(abs)
+	rel, err := filepath.Rel(root, abs)
+	inside := err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+	relSlash := filepath.ToSlash(rel)
+
+	for _, p := range patterns {
+		switch {
+		case !strings.Contains(p, "/"):
+			// A name: any directory or file on the way down from the root,
+			// or just the file itself outside the project.
+			names := []string{path.Base(absSlash)}
+			if inside {
+				names = strings.Split(relSlash, "/")
+			}
+			for _, name := range names {
+				if ok, _ := path.Match(p, name); ok {
+					return p, true
+				}
+			}
+		case path.IsAbs(p) || filepath.IsAbs(p):
+			if matchPathOrParent(p, absSlash) {
+				return p, true
+			}
+		case inside:
+			if matchPathOrParent(p, relSlash) {
+				return p, true
+			}
+		}
+	}
+	return "", false
+}
+
+// matchPathOrParent reports whether pattern matches name or one of the
+// directories containing it.
+func matchPathOrParent(pattern, name string) bool {
+	pat := strings.Split(strings.TrimPrefix(pattern, "/"), "/")
+	segs := strings.Split(strings.TrimPrefix(name, "/"), "/")
+	for n := len(segs); n > 0; n-- {
+		if matchSegments(pat, segs[:n]) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkNeverModifyCommand fails with ErrSafetyPolicy when a shell command
+// run in dir (the working directory when empty) looks like it writes a
+// protected file. It is a heuristic: it catches redirections and the
+// common file-changing commands, not everything a shell can do.
+func checkNeverModifyCommand(command, dir string) error {
+	if patterns := neverModify.Load(); patterns == nil || len(*patterns) == 0 {
+		return nil
+	}
+	for _, target := range bashWriteTargets(command) {
+		p := target
+		if dir != "" {
+			p = ResolveToCwd(target, dir)
+		}
+		if err := checkNeverModify(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fileCommands lists commands whose file arguments they change; lastOnly
+// ones change only their last argument, the destination.
+var fileCommands = map[string]struct{ lastOnly bool }{
+	"rm": {}, "mv": {}, "touch": {}, "truncate": {}, "tee": {}, "chmod": {}, "chown": {},
+	"sed": {}, "perl": {}, "shred": {}, "unlink": {},
+	"cp": {lastOnly: true}, "ln": {lastOnly: true}, "install": {lastOnly: true},
+}
+
+// bashWriteTargets guesses the files command writes: redirection targets,
+// dd's of=, and the file arguments of commands in fileCommands. sed and
+// perl count only with -i.
+func bashWriteTargets(command string) []string {
+	var targets []string
+	for _, seg := range splitShellSegments(shellWords(command)) {
+		var args []string
+		for i := 0; i < len(seg); i++ {
+			w := seg[i]
+			if isRedirect(w) {
+				if i+1 < len(seg) && !strings.HasPrefix(seg[i+1], "&") && seg[i+1] != "/dev/null" {
+					targets = append(targets, seg[i+1])
+				}
+				i++
+				continue
+			}
+			args = append(args, w)
+		}
+		// Skip VAR=value prefixes and sudo to find the command.
+		for len(args) > 0 && (args[0] == "sudo" || (strings.Contains(args[0], "=") && !strings.HasPrefix(args[0], "-"))) {
+			args = args[1:]
+		}
+		if len(args) == 0 {
+			continue
+		}
+		name := path.Base(filepath.ToSlash(args[0]))
+		if name == "dd" {
+			for _, a := range args[1:] {
+				if of, ok := strings.CutPrefix(a, "of="); ok {
+					targets = append(targets, of)
+				}
+			}
+			continue
+		}
+		spec, ok := fileCommands[name]
+		if !ok {
+			continue
+		}
+		var files []string
+		editor := name == "sed" || name == "perl"
+		inPlace, script := false, false
+		rest := args[1:]
+		for i := 0; i < len(rest); i++ {
+			a := rest[i]
+			if strings.HasPrefix(a, "-") {
+				inPlace = inPlace || strings.HasPrefix(a, "-i") || strings.HasPrefix(a, "--in-place") || (name == "perl" && strings.Contains(a, "i"))
+				// A script given with -e or -f is not a file to change.
+				if editor && (strings.HasSuffix(a, "e") || strings.HasSuffix(a, "f") || a == "--expression" || a == "--file") {
+					script = true
+					i++
+				}
+				continue
+			}
+			files = append(files, a)
+		}
+		if editor {
+			if !inPlace {
+				continue
+			}
+			if !script && len(files) > 0 {
+				files = files[1:]
+			}
+		}
+		if spec.lastOnly && len(files) > 0 {
+			files = files[len(files)-1:]
+		}
+		targets = append(targets, files...)
+	}
+	return targets
+}
+
+// isRedirect reports whether w is an output redirection operator.
+func isRedirect(w string) bool {
+	w = strings.TrimLeft(w, "0123456789&")
+	return w == ">" || w == ">>" || w == ">|"
+}
+
+// shellWords splits a command into words and operators, honoring quotes.
+// Operators (;, &, |, redirections, newlines) are words of their own.
+func shellWords(command string) []string {
+	var (
+		words []string
+		cur   strings.Builder
+		quote rune
+		has   bool
+	)
+	flush := func() {
+		if has {
+			words = append(words, cur.String())
+			cur.Reset()
+			has = false
+		}
+	}
+	runes := []rune(command)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else if r == '\\' && quote == '"' && i+1 < len(runes) {
+				i++
+				cur.WriteRune(runes[i])
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, has = r, true
+		case r == '\\' && i+1 < len(runes):
+			i++
+			cur.WriteRune(runes[i])
+			has = true
+		case r == ' ' || r == '\t':
+			flush()
+		case r == '>':
+			// Keep a descriptor prefix such as 2> or &> with the operator.
+			prefix := ""
+			if has && strings.Trim(cur.String(), "0123456789&") == "" {
+				prefix = cur.String()
+				cur.Reset()
+				has = false
+			}
+			flush()
+			op := prefix + ">"
+			if i+1 < len(runes) && (runes[i+1] == '>' || runes[i+1] == '|') {
+				i++
+				op += string(runes[i])
+			}
+			words = append(words, op)
+			// A descriptor duplication such as >&2 is the operator's target.
+			if i+1 < len(runes) && runes[i+1] == '&' {
+				j := i + 2
+				for j < len(runes) && (runes[j] == '-' || (runes[j] >= '0' && runes[j] <= '9')) {
+					j++
+				}
+				words = append(words, string(runes[i+1:j]))
+				i = j - 1
+			}
+		case r == ';' || r == '|' || r == '&' || r == '\n' || r == '(' || r == ')':
+			if r == '&' && i+1 < len(runes) && runes[i+1] == '>' {
+				flush()
+				cur.WriteRune(r)
+				has = true
+				continue
+			}
+			flush()
+			words = append(words, string(r))
+		default:
+			cur.WriteRune(r)
+			has = true
+		}
+	}
+	flush()
+	return words
+}
+
+// splitShellSegments splits words at command separators.
+func splitShellSegments(words []string) [][]string {
+	var segs [][]string
+	var cur []string
+	for _, w := range words {
+		switch w {
+		case ";", "|", "&", "\n", "(", ")":
+			if len(cur) > 0 {
+				segs = append(segs, cur)
+			}
+			cur = nil
+		default:
+			cur = append(cur, w)
+		}
+	}
+	if len(cur) > 0 {
+		segs = append(segs, cur)
+	}
+	return segs
+}
@@ -0,0 +1,114 @@
+// ABOUTME: Tests for the neverModify safety policy: glob matching, the write/edit/bash checks, and the bash heuristics
+// ABOUTME: Not parallel: the policy is process-wide and cleared after each test
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
+)
+
+func TestCheckNeverModify(t *testing.T) {
+	t.Cleanup(func() { SetNeverModify(nil) })
+	SetNeverModify([]string{".env", "*.pem", "deploy/prod/**", "./migrations/", "/etc/hosts"})
+
+	wd, _ := os.Getwd()
+	for p, blocked := range map[string]bool{
+		".env":                         true,
+		"sub/.env":                     true,
+		"certs/server.pem":             true,
+		"deploy/prod/values.yaml":      true,
+		"deploy/staging/values.yaml":   false,
+		"migrations/0001_init.sql":     true,
+		"sub/migrations/0001_init.sql": false,
+		filepath.Join(wd, "a", ".env"): true,
+		"/etc/hosts":                   true,
+		"/tmp/elsewhere/.env":          true,
+		"main.go":                      false,
+		".envrc":                       false,
+	} {
+		err := checkNeverModify(p)
+		if got := errors.Is(err, ErrSafetyPolicy); got != blocked {
+			t.Errorf("checkNeverModify(%q) = %v; want blocked %v", p, err, blocked)
+		}
+	}
+}
+
+func TestCheckNeverModify_FollowsSymlinks(t *testing.T) {
+	t.Cleanup(func() { SetNeverModify(nil) })
+	dir := t.TempDir()
+	secret := filepath.Join(dir, "secret.key")
+	if err := os.WriteFile(secret, []byte("k"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "innocent.txt")
+	if err := os.Symlink(secret, link); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+	SetNeverModify([]string{"*.key"})
+
+	if err := checkNeverModify(link); !errors.Is(err, ErrSafetyPolicy) {
+		t.Errorf("checkNeverModify(link) = %v; want the policy error", err)
+	}
+}
+
+func TestNeverModify_BlocksTools(t *testing.T) {
+	t.Cleanup(func() { SetNeverModify(nil) })
+	SetNeverModify([]string{"*.lock"})
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "deps.lock")
+	if err := os.WriteFile(path, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	calls := []struct {
+		tool   *agent.AgentTool
+		params map[string]any
+	}{
+		{NewWriteTool(), map[string]any{"path": path, "content": "new"}},
+		{NewEditTool(), map[string]any{"path": path, "old_string": "old", "new_string": "new"}},
+		{NewBashTool(), map[string]any{"command": "echo new > " + path}},
+	}
+	for _, c := range calls {
+		result, err := c.tool.Execute(context.Background(), "id", c.params, nil)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", c.tool.Name, err)
+		}
+		if !result.IsError || !strings.Contains(result.Content, "blocked by safety policy") {
+			t.Errorf("%s: got %+v; want a safety policy error", c.tool.Name, result)
+		}
+	}
+	if data, _ := os.ReadFile(path); string(data) != "old" {
+		t.Errorf("protected file changed to %q", data)
+	}
+}
+
+func TestBashWriteTargets(t *testing.T) {
+	for cmd, want := range map[string][]string{
+		"echo hi > out.txt":                       {"out.txt"},
+		"echo hi >>'log file' 2>&1":               {"log file"},
+		"make 2>err.log &>all.log":                {"err.log", "all.log"},
+		"cat a | tee -a b c":                      {"b", "c"},
+		"rm -rf build dist; touch x":              {"build", "dist", "x"},
+		"cp -r src/a src/b dst":                   {"dst"},
+		"sed -i 's/a/b/' f.txt":                   {"f.txt"},
+		"sed 's/a/b/' f.txt":                      nil,
+		"perl -pi -e 's/a/b/' g.txt h.txt":        {"g.txt", "h.txt"},
+		"sudo FOO=1 mv a b":                       {"a", "b"},
+		"dd if=/dev/zero of=disk.img bs=1M":       {"disk.img"},
+		"echo '> not.txt' && grep x y >/dev/null": nil,
+		"cat .env": nil,
+	} {
+		if got := bashWriteTargets(cmd); !slices.Equal(got, want) {
+			t.Errorf("bashWriteTargets(%q) = %q; want %q", cmd, got, want)
+		}
+	}
+}
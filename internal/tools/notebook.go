@@ -58,6 +58,9 @@ func executeNotebookEdit(params map[string]any) (agent.ToolResult, error) {
 	if err != nil {
 		return errResult(err), nil
 	}
+	if err := checkWritePolicy(path); err != nil {
+		return errResult(err), nil
+	}
 
 	cellNum := intParam(params, "cell_number", -999)
 	if cellNum == -999 {
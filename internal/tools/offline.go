@@ -0,0 +1,35 @@
+// ABOUTME: Offline mode for the tool registry: network tools are replaced with stubs
+// ABOUTME: Stubs keep their schema but fail immediately with an actionable error
+
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
+	"github.com/mauromedda/pi-coding-agent-go/internal/offline"
+)
+
+// networkTools are the built-in tools that require outbound network access.
+var networkTools = []string{"webfetch", "websearch"}
+
+// DisableNetworkTools replaces registered network tools with stubs that fail
+// fast in offline mode, so the model learns why instead of waiting on a timeout.
+func (r *Registry) DisableNetworkTools() {
+	for _, name := range networkTools {
+		if t := r.raw[name]; t != nil {
+			r.Register(offlineStub(t))
+		}
+	}
+}
+
+func offlineStub(t *agent.AgentTool) *agent.AgentTool {
+	stub := *t
+	stub.Description = "Unavailable: pi-go is running with --offline. Do not call this tool; work from local files instead."
+	stub.Execute = func(context.Context, string, map[string]any, func(agent.ToolUpdate)) (agent.ToolResult, error) {
+		return errResult(offline.Unavailable(fmt.Sprintf("the %s tool", t.Name),
+			"answer from local files, or ask the user to restart without --offline")), nil
+	}
+	return &stub
+}
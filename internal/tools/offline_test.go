@@ -0,0 +1,41 @@
+// ABOUTME: Tests for offline tool stubs: network tools fail fast with an actionable error
+// ABOUTME: Verifies local tools are untouched and the stub keeps the original schema
+
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRegistry_DisableNetworkTools(t *testing.T) {
+	t.Parallel()
+
+	r := NewRegistry()
+	origParams := string(r.Get("webfetch").Parameters)
+	r.DisableNetworkTools()
+
+	for _, name := range []string{"webfetch", "websearch"} {
+		tool := r.Get(name)
+		if tool == nil {
+			t.Fatalf("%s removed; want an offline stub", name)
+		}
+		if !strings.Contains(tool.Description, "--offline") {
+			t.Errorf("%s description = %q, want offline notice", name, tool.Description)
+		}
+		res, err := tool.Execute(context.Background(), "id", map[string]any{"url": "https://example.com", "query": "go"}, nil)
+		if err != nil {
+			t.Fatalf("%s Execute error = %v", name, err)
+		}
+		if !res.IsError || !strings.Contains(res.Content, "offline mode") || !strings.Contains(res.Content, "restart without --offline") {
+			t.Errorf("%s result = %+v, want actionable offline error", name, res)
+		}
+	}
+	if got := string(r.Get("webfetch").Parameters); got != origParams {
+		t.Error("stub should keep the original parameter schema")
+	}
+	if strings.Contains(r.Get("read").Description, "--offline") {
+		t.Error("local tools must not be stubbed")
+	}
+}
@@ -0,0 +1,372 @@
+// ABOUTME: Composite tools: a configured pipeline chains existing tools with templated arguments
+// ABOUTME: Each step is reported as a nested tool call; outputs feed later steps via .steps and .prev
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
+)
+
+// maxPipelineItems bounds how many times a ForEach step runs per call.
+const maxPipelineItems = 100
+
+// Pipeline declares a composite tool.
+type Pipeline struct {
+	Name        string
+	Description string
+	Params      []PipelineParam
+	Steps       []PipelineStep
+}
+
+// PipelineParam is one parameter the model passes to a pipeline.
+type PipelineParam struct {
+	Name        string
+	Type        string // JSON schema type; "" means string
+	Description string
+	Required    bool
+}
+
+// PipelineStep runs one tool. String values in Args, and ForEach, are
+// text/template sources rendered with .params, .steps, .prev, and .item.
+type PipelineStep struct {
+	Name            string // key in .steps; defaults to Tool
+	Tool            string
+	Args            map[string]any
+	ForEach         string // run once per distinct non-empty rendered line
+	Match           string // regexp selecting ForEach lines; group 1 (or the match) is the item
+	ContinueOnError bool
+}
+
+type compiledStep struct {
+	PipelineStep
+	args    any // Args with string leaves replaced by templates
+	forEach *template.Template
+	match   *regexp.Regexp
+}
+
+// pipelineFuncs are available in pipeline templates.
+var pipelineFuncs = template.FuncMap{
+	"trim":       strings.TrimSpace,
+	"shellquote": shellQuote,
+}
+
+// NewPipelineTool creates the composite tool for p. Step tools are resolved
+// with lookup on every call, so tools registered later still work. Steps
+// may not call the pipeline itself, nor any name in pipelines.
+func NewPipelineTool(p Pipeline, pipelines []string, lookup func(string) *agent.AgentTool) (*agent.AgentTool, error) {
+	if p.Name == "" {
+		return nil, fmt.Errorf("pipeline has no name")
+	}
+	if len(p.Steps) == 0 {
+		return nil, fmt.Errorf("pipeline %s has no steps", p.Name)
+	}
+	steps := make([]compiledStep, len(p.Steps))
+	readOnly := true
+	for i, st := range p.Steps {
+		cs, err := compileStep(st)
+		if err != nil {
+			return nil, fmt.Errorf("pipeline %s: step %d: %w", p.Name, i+1, err)
+		}
+		if st.Tool == p.Name || slices.Contains(pipelines, st.Tool) {
+			return nil, fmt.Errorf("pipeline %s: step %d: pipelines cannot call pipelines (%s)", p.Name, i+1, st.Tool)
+		}
+		if t := lookup(st.Tool); t == nil || !t.ReadOnly {
+			readOnly = false
+		}
+		steps[i] = cs
+	}
+
+	description := p.Description
+	if description == "" {
+		description = "Composite tool"
+	}
+	var names []string
+	for _, st := range p.Steps {
+		names = append(names, st.Tool)
+	}
+	description += " (runs " + strings.Join(names, " → ") + ")"
+
+	return &agent.AgentTool{
+		Name:        p.Name,
+		Label:       p.Name,
+		Description: description,
+		Parameters:  pipelineSchema(p.Params),
+		ReadOnly:    readOnly,
+		Execute: func(ctx context.Context, id string, params map[string]any, onUpdate func(agent.ToolUpdate)) (agent.ToolResult, error) {
+			return runPipeline(ctx, p, steps, id, params, lookup, onUpdate), nil
+		},
+	}, nil
+}
+
+func compileStep(st PipelineStep) (compiledStep, error) {
+	cs := compiledStep{PipelineStep: st}
+	if st.Tool == "" {
+		return cs, fmt.Errorf("no tool")
+	}
+	if cs.Name == "" {
+		cs.Name = st.Tool
+	}
+	args, err := compileArgs(st.Args)
+	if err != nil {
+		return cs, err
+	}
+	cs.args = args
+	if st.ForEach != "" {
+		if cs.forEach, err = newPipelineTemplate(st.ForEach); err != nil {
+			return cs, fmt.Errorf("forEach: %w", err)
+		}
+	}
+	if st.Match != "" {
+		if cs.match, err = regexp.Compile(st.Match); err != nil {
+			return cs, fmt.Errorf("match: %w", err)
+		}
+	}
+	return cs, nil
+}
+
+func newPipelineTemplate(src string) (*template.Template, error) {
+	return template.New("").Funcs(pipelineFuncs).Option("missingkey=error").Parse(src)
+}
+
+// compileArgs parses every string in v as a template, keeping the shape.
+func compileArgs(v any) (any, error) {
+	switch v := v.(type) {
+	case string:
+		return newPipelineTemplate(v)
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, e := range v {
+			c, err := compileArgs(e)
+			if err != nil {
+				return nil, fmt.Errorf("arg %s: %w", k, err)
+			}
+			out[k] = c
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			c, err := compileArgs(e)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = c
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
+// renderArgs executes the templates in a compiled argument tree.
+func renderArgs(v any, data map[string]any) (any, error) {
+	switch v := v.(type) {
+	case *template.Template:
+		return renderTemplate(v, data)
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, e := range v {
+			r, err := renderArgs(e, data)
+			if err != nil {
+				return nil, fmt.Errorf("arg %s: %w", k, err)
+			}
+			out[k] = r
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			r, err := renderArgs(e, data)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = r
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
+func renderTemplate(t *template.Template, data map[string]any) (string, error) {
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// pipelineSchema builds the JSON schema of a pipeline's parameters.
+func pipelineSchema(params []PipelineParam) json.RawMessage {
+	props := make(map[string]any, len(params))
+	required := []string{}
+	for _, p := range params {
+		typ := p.Type
+		if typ == "" {
+			typ = "string"
+		}
+		prop := map[string]any{"type": typ}
+		if p.Description != "" {
+			prop["description"] = p.Description
+		}
+		props[p.Name] = prop
+		if p.Required {
+			required = append(required, p.Name)
+		}
+	}
+	schema, _ := json.Marshal(map[string]any{
+		"type":       "object",
+		"properties": props,
+		"required":   required,
+	})
+	return schema
+}
+
+// runPipeline executes the steps in order. A failing step ends the run
+// unless it has ContinueOnError; the result lists every step's output.
+func runPipeline(ctx context.Context, p Pipeline, steps []compiledStep, id string, params map[string]any,
+	lookup func(string) *agent.AgentTool, onUpdate func(agent.ToolUpdate)) agent.ToolResult {
+	input := make(map[string]any, len(p.Params)+len(params))
+	for _, pp := range p.Params {
+		if pp.Required {
+			if _, ok := params[pp.Name]; !ok {
+				return errResult(fmt.Errorf("missing required parameter %q", pp.Name))
+			}
+		}
+		input[pp.Name] = ""
+	}
+	for k, v := range params {
+		input[k] = v
+	}
+	outputs := make(map[string]string, len(steps))
+	data := map[string]any{"params": input, "steps": outputs, "prev": "", "item": ""}
+	check := agent.PermCheckFrom(ctx)
+
+	var report strings.Builder
+	index := 0
+	fail := func(format string, args ...any) agent.ToolResult {
+		fmt.Fprintf(&report, format, args...)
+		return agent.ToolResult{Content: strings.TrimRight(report.String(), "\n"), IsError: true}
+	}
+
+	for i, st := range steps {
+		if err := ctx.Err(); err != nil {
+			return fail("pipeline cancelled: %v\n", err)
+		}
+		tool := lookup(st.Tool)
+		if tool == nil {
+			return fail("step %d: unknown tool %q\n", i+1, st.Tool)
+		}
+
+		items := []string{""}
+		if st.forEach != nil {
+			text, err := renderTemplate(st.forEach, data)
+			if err != nil {
+				return fail("step %d (%s): forEach: %v\n", i+1, st.Tool, err)
+			}
+			items = pipelineItems(text, st.match)
+			if len(items) > maxPipelineItems {
+				fmt.Fprintf(&report, "step %d (%s): %d items, running the first %d\n\n", i+1, st.Tool, len(items), maxPipelineItems)
+				items = items[:maxPipelineItems]
+			}
+		}
+
+		var outs []string
+		for _, item := range items {
+			data["item"] = item
+			rendered, err := renderArgs(st.args, data)
+			if err != nil {
+				return fail("step %d (%s): %v\n", i+1, st.Tool, err)
+			}
+			args, _ := rendered.(map[string]any)
+			if args == nil {
+				args = map[string]any{}
+			}
+			argsJSON, _ := json.Marshal(args)
+			step := agent.ToolStep{Index: index, Tool: st.Tool, Args: string(argsJSON)}
+			index++
+			if onUpdate != nil {
+				s := step
+				onUpdate(agent.ToolUpdate{Step: &s})
+			}
+
+			res := runPipelineStep(ctx, tool, check, fmt.Sprintf("%s/%d", id, step.Index), args)
+			step.Done, step.IsError, step.Output = true, res.IsError, res.Content
+			if onUpdate != nil {
+				s := step
+				onUpdate(agent.ToolUpdate{Step: &s})
+			}
+
+			status := "ok"
+			if res.IsError {
+				status = "error"
+			}
+			fmt.Fprintf(&report, "step %d %s %s: %s\n%s\n\n", step.Index+1, st.Tool, step.Args, status, strings.TrimRight(res.Content, "\n"))
+			if res.IsError && !st.ContinueOnError {
+				return agent.ToolResult{Content: strings.TrimRight(report.String(), "\n"), IsError: true}
+			}
+			outs = append(outs, res.Content)
+		}
+		out := strings.Join(outs, "\n")
+		outputs[st.Name] = out
+		data["prev"] = out
+	}
+	if index == 0 {
+		report.WriteString("no steps ran")
+	}
+	return agent.ToolResult{Content: strings.TrimRight(report.String(), "\n")}
+}
+
+// runPipelineStep runs one step under the caller's permission rules. Steps
+// bypass the read cache: their output feeds later steps, not the model.
+func runPipelineStep(ctx context.Context, tool *agent.AgentTool, check agent.PermCheckFunc, id string, args map[string]any) agent.ToolResult {
+	if check != nil {
+		if err := check(tool.Name, args); err != nil {
+			return errResult(err)
+		}
+	}
+	res, err := tool.Execute(WithReadCache(ctx, nil), id, args, func(agent.ToolUpdate) {})
+	if err != nil {
+		return errResult(err)
+	}
+	return res
+}
+
+// pipelineItems splits ForEach output into distinct non-empty lines. With
+// match, only matching lines count and the item is the first group.
+func pipelineItems(text string, match *regexp.Regexp) []string {
+	var items []string
+	seen := make(map[string]bool)
+	for line := range strings.SplitSeq(text, "\n") {
+		item := strings.TrimSpace(line)
+		if match != nil {
+			m := match.FindStringSubmatch(item)
+			if m == nil {
+				continue
+			}
+			item = m[0]
+			if len(m) > 1 {
+				item = m[1]
+			}
+		}
+		if item == "" || seen[item] {
+			continue
+		}
+		seen[item] = true
+		items = append(items, item)
+	}
+	return items
+}
+
+// shellQuote quotes s for use as one POSIX shell word.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
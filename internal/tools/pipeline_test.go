@@ -0,0 +1,194 @@
+// ABOUTME: Tests for composite pipeline tools: templating, forEach expansion, step reporting, errors
+// ABOUTME: Steps run stub tools that echo their arguments
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
+)
+
+// echoTool returns fn(params) as its output.
+func echoTool(name string, readOnly bool, fn func(map[string]any) (string, bool)) *agent.AgentTool {
+	return &agent.AgentTool{
+		Name:     name,
+		ReadOnly: readOnly,
+		Execute: func(_ context.Context, _ string, params map[string]any, _ func(agent.ToolUpdate)) (agent.ToolResult, error) {
+			out, isErr := fn(params)
+			return agent.ToolResult{Content: out, IsError: isErr}, nil
+		},
+	}
+}
+
+func pipelineLookup(tools ...*agent.AgentTool) func(string) *agent.AgentTool {
+	return func(name string) *agent.AgentTool {
+		for _, t := range tools {
+			if t.Name == name {
+				return t
+			}
+		}
+		return nil
+	}
+}
+
+func lintFixPipeline() Pipeline {
+	return Pipeline{
+		Name:        "lint_fix",
+		Description: "Lint and open offending files",
+		Params:      []PipelineParam{{Name: "dir", Required: true}},
+		Steps: []PipelineStep{
+			{Name: "lint", Tool: "bash", Args: map[string]any{"command": "lint {{shellquote .params.dir}}"}},
+			{Tool: "read", ForEach: "{{.steps.lint}}", Match: `^([^:]+):\d+:`, Args: map[string]any{"path": "{{.item}}"}},
+		},
+	}
+}
+
+func TestPipelineTool_ChainsStepsWithTemplates(t *testing.T) {
+	t.Parallel()
+
+	var commands []string
+	bash := echoTool("bash", false, func(p map[string]any) (string, bool) {
+		commands = append(commands, p["command"].(string))
+		return "a.go:3: unused\nb.go:9: shadow\na.go:7: unused\nok\n", false
+	})
+	var reads []string
+	read := echoTool("read", true, func(p map[string]any) (string, bool) {
+		reads = append(reads, p["path"].(string))
+		return "contents of " + p["path"].(string), false
+	})
+
+	tool, err := NewPipelineTool(lintFixPipeline(), nil, pipelineLookup(bash, read))
+	if err != nil {
+		t.Fatalf("NewPipelineTool: %v", err)
+	}
+	if tool.ReadOnly {
+		t.Error("pipeline with a bash step should not be read-only")
+	}
+
+	var steps []agent.ToolStep
+	res, _ := tool.Execute(context.Background(), "t1", map[string]any{"dir": "my dir"}, func(u agent.ToolUpdate) {
+		if u.Step != nil {
+			steps = append(steps, *u.Step)
+		}
+	})
+	if res.IsError {
+		t.Fatalf("unexpected error: %s", res.Content)
+	}
+	if len(commands) != 1 || commands[0] != "lint 'my dir'" {
+		t.Errorf("commands = %q", commands)
+	}
+	if strings.Join(reads, ",") != "a.go,b.go" {
+		t.Errorf("reads = %q, want distinct matched files", reads)
+	}
+	// Each step is reported started and finished: 3 steps, 6 updates.
+	if len(steps) != 6 {
+		t.Fatalf("got %d step updates, want 6: %+v", len(steps), steps)
+	}
+	last := steps[5]
+	if last.Index != 2 || last.Tool != "read" || !last.Done || last.Output != "contents of b.go" {
+		t.Errorf("last step = %+v", last)
+	}
+	if !strings.Contains(res.Content, "contents of a.go") || !strings.Contains(res.Content, "step 3 read") {
+		t.Errorf("result does not report every step:\n%s", res.Content)
+	}
+}
+
+func TestPipelineTool_StopsOnStepError(t *testing.T) {
+	t.Parallel()
+
+	ran := false
+	fail := echoTool("bash", false, func(map[string]any) (string, bool) { return "boom", true })
+	next := echoTool("read", true, func(map[string]any) (string, bool) { ran = true; return "", false })
+	p := Pipeline{Name: "p", Steps: []PipelineStep{{Tool: "bash"}, {Tool: "read"}}}
+
+	tool, err := NewPipelineTool(p, nil, pipelineLookup(fail, next))
+	if err != nil {
+		t.Fatalf("NewPipelineTool: %v", err)
+	}
+	res, _ := tool.Execute(context.Background(), "t1", nil, nil)
+	if !res.IsError || !strings.Contains(res.Content, "boom") {
+		t.Errorf("expected failing result, got %+v", res)
+	}
+	if ran {
+		t.Error("step after a failure should not run")
+	}
+
+	p.Steps[0].ContinueOnError = true
+	tool, _ = NewPipelineTool(p, nil, pipelineLookup(fail, next))
+	res, _ = tool.Execute(context.Background(), "t2", nil, nil)
+	if res.IsError || !ran {
+		t.Errorf("ContinueOnError should run the next step; got %+v, ran=%v", res, ran)
+	}
+}
+
+func TestPipelineTool_ChecksStepPermissions(t *testing.T) {
+	t.Parallel()
+
+	ran := false
+	bash := echoTool("bash", false, func(map[string]any) (string, bool) { ran = true; return "", false })
+	tool, _ := NewPipelineTool(Pipeline{Name: "p", Steps: []PipelineStep{{Tool: "bash"}}}, nil, pipelineLookup(bash))
+
+	ctx := agent.WithPermCheck(context.Background(), func(name string, _ map[string]any) error {
+		return errors.New("denied: " + name)
+	})
+	res, _ := tool.Execute(ctx, "t1", nil, nil)
+	if ran || !res.IsError || !strings.Contains(res.Content, "denied: bash") {
+		t.Errorf("step should be denied; ran=%v result=%+v", ran, res)
+	}
+}
+
+func TestPipelineTool_Validation(t *testing.T) {
+	t.Parallel()
+
+	lookup := pipelineLookup()
+	tests := []struct {
+		name string
+		p    Pipeline
+		want string
+	}{
+		{"no steps", Pipeline{Name: "p"}, "no steps"},
+		{"no tool", Pipeline{Name: "p", Steps: []PipelineStep{{}}}, "no tool"},
+		{"self call", Pipeline{Name: "p", Steps: []PipelineStep{{Tool: "p"}}}, "cannot call pipelines"},
+		{"other pipeline", Pipeline{Name: "p", Steps: []PipelineStep{{Tool: "q"}}}, "cannot call pipelines"},
+		{"bad template", Pipeline{Name: "p", Steps: []PipelineStep{{Tool: "bash", Args: map[string]any{"command": "{{"}}}}, "arg command"},
+		{"bad match", Pipeline{Name: "p", Steps: []PipelineStep{{Tool: "bash", Match: "("}}}, "match"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPipelineTool(tt.p, []string{"p", "q"}, lookup)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPipelineTool_RequiredParamAndUnknownTool(t *testing.T) {
+	t.Parallel()
+
+	tool, err := NewPipelineTool(lintFixPipeline(), nil, pipelineLookup())
+	if err != nil {
+		t.Fatalf("NewPipelineTool: %v", err)
+	}
+	if res, _ := tool.Execute(context.Background(), "t1", nil, nil); !res.IsError || !strings.Contains(res.Content, `"dir"`) {
+		t.Errorf("expected missing parameter error, got %+v", res)
+	}
+	if res, _ := tool.Execute(context.Background(), "t2", map[string]any{"dir": "."}, nil); !res.IsError || !strings.Contains(res.Content, `unknown tool "bash"`) {
+		t.Errorf("expected unknown tool error, got %+v", res)
+	}
+}
+
+func TestPipelineSchema(t *testing.T) {
+	t.Parallel()
+
+	got := string(pipelineSchema([]PipelineParam{{Name: "n", Type: "integer", Required: true}, {Name: "s", Description: "a string"}}))
+	for _, want := range []string{`"n":{"type":"integer"}`, `"s":{"description":"a string","type":"string"}`, `"required":["n"]`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("schema %s missing %s", got, want)
+		}
+	}
+}
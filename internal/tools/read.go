@@ -73,7 +73,7 @@ Parameters:
 	}
 }
 
-func executeRead(sb *permission.Sandbox, _ context.Context, _ string, params map[string]any, _ func(agent.ToolUpdate)) (agent.ToolResult, error) {
+func executeRead(sb *permission.Sandbox, ctx context.Context, id string, params map[string]any, _ func(agent.ToolUpdate)) (agent.ToolResult, error) {
 	rawPath, err := requireStringParam(params, "path")
 	if err != nil {
 		return errResult(err), nil
@@ -82,6 +82,9 @@ func executeRead(sb *permission.Sandbox, _ context.Context, _ string, params map
 	cwd, _ := os.Getwd()
 	path := ResolveReadPath(rawPath, cwd)
 
+	if err := checkSecretRead(path); err != nil {
+		return errResult(err), nil
+	}
 	if sb != nil {
 		if err := sb.ValidatePath(path); err != nil {
 			return errResult(err), nil
@@ -94,6 +97,16 @@ func executeRead(sb *permission.Sandbox, _ context.Context, _ string, params map
 	}
 	defer f.Close()
 
+	// An unchanged range already read in this conversation gets a stub.
+	cache := readCacheFrom(ctx)
+	info, statErr := f.Stat()
+	key := readKey(path, intParam(params, "offset", 0), intParam(params, "limit", 0))
+	if cache != nil && statErr == nil {
+		if res, ok := cache.lookup(key, info); ok {
+			return res, nil
+		}
+	}
+
 	data, err := io.ReadAll(io.LimitReader(f, maxFileReadSize))
 	if err != nil {
 		return errResult(fmt.Errorf("reading file %s: %w", path, err)), nil
@@ -108,6 +121,9 @@ func executeRead(sb *permission.Sandbox, _ context.Context, _ string, params map
 
 	content := applyOffsetLimit(string(data), params)
 	content = truncateOutput(content, maxReadOutput)
+	if cache != nil && statErr == nil {
+		cache.store(key, id, info, len(content))
+	}
 
 	return agent.ToolResult{Content: content}, nil
 }
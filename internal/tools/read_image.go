@@ -47,6 +47,9 @@ func executeReadImage(sb *permission.Sandbox, params map[string]any) (agent.Tool
 	cwd, _ := os.Getwd()
 	path := ResolveReadPath(rawPath, cwd)
 
+	if err := checkSecretRead(path); err != nil {
+		return errResult(err), nil
+	}
 	if sb != nil {
 		if err := sb.ValidatePath(path); err != nil {
 			return errResult(err), nil
@@ -0,0 +1,142 @@
+// ABOUTME: Per-conversation read cache: a repeated read of an unchanged file range returns a short stub
+// ABOUTME: Entries are keyed by path and range, checked against mtime and size, and pruned to results still in context
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
+	"github.com/mauromedda/pi-coding-agent-go/internal/session"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+// ReadCacheStats counts the text reads seen by a ReadCache.
+type ReadCacheStats struct {
+	Reads      int // text reads, served in full or as a stub
+	Hits       int // reads answered with the unchanged stub
+	BytesSaved int // content bytes the stubs stood in for
+}
+
+// readEntry records the last full read of one file range.
+type readEntry struct {
+	modTime time.Time
+	size    int64
+	callID  string // tool call whose result holds the content
+	bytes   int    // length of that content
+	stubbed bool   // a stub was already served for this read
+}
+
+// ReadCache remembers which file ranges a conversation has read, so reading
+// one again while the file is unchanged costs a line instead of the content.
+// A stub is served once per full read: asking again for the same range
+// returns the content, in case the earlier result is no longer visible.
+type ReadCache struct {
+	mu      sync.Mutex
+	entries map[string]*readEntry
+	stats   ReadCacheStats
+}
+
+// NewReadCache creates an empty ReadCache.
+func NewReadCache() *ReadCache {
+	return &ReadCache{entries: make(map[string]*readEntry)}
+}
+
+type readCacheKey struct{}
+
+// WithReadCache returns a context whose read tool calls use c. A nil c
+// turns caching off, as for sub-agents, which start with an empty context.
+func WithReadCache(ctx context.Context, c *ReadCache) context.Context {
+	return context.WithValue(ctx, readCacheKey{}, c)
+}
+
+// readCacheFrom returns the cache stored by WithReadCache, or nil.
+func readCacheFrom(ctx context.Context) *ReadCache {
+	c, _ := ctx.Value(readCacheKey{}).(*ReadCache)
+	return c
+}
+
+// readKey identifies a file range; limit 0 means to the end of the file.
+func readKey(path string, offset, limit int) string {
+	return fmt.Sprintf("%s\x00%d\x00%d", path, offset, max(limit, 0))
+}
+
+// lookup returns the unchanged stub when key was read in full, the file
+// still has the recorded mtime and size, and no stub was served since.
+func (c *ReadCache) lookup(key string, info os.FileInfo) (agent.ToolResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok || e.stubbed || !e.modTime.Equal(info.ModTime()) || e.size != info.Size() {
+		return agent.ToolResult{}, false
+	}
+	e.stubbed = true
+	c.stats.Reads++
+	c.stats.Hits++
+	c.stats.BytesSaved += e.bytes
+	return agent.ToolResult{Content: fmt.Sprintf(
+		"File unchanged since it was last read (tool call %s); that result is still current. "+
+			"If it is no longer in your context, read the same range again to get the content.",
+		e.callID)}, true
+}
+
+// store records a full read of key made by tool call callID.
+func (c *ReadCache) store(key, callID string, info os.FileInfo, bytes int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = &readEntry{modTime: info.ModTime(), size: info.Size(), callID: callID, bytes: bytes}
+	c.stats.Reads++
+}
+
+// Forget drops every cached range of path, as when it changed outside the
+// agent. A relative path is resolved like the read tool resolves it.
+func (c *ReadCache) Forget(path string) {
+	cwd, _ := os.Getwd()
+	prefix := ResolveReadPath(path, cwd) + "\x00"
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// Retain drops entries whose full read is no longer among the tool results
+// in messages, because the conversation was cleared, compacted, rewound,
+// or the result was evicted. Call it before each prompt.
+func (c *ReadCache) Retain(messages []ai.Message) {
+	if c == nil {
+		return
+	}
+	live := make(map[string]bool)
+	for _, msg := range messages {
+		for _, b := range msg.Content {
+			if b.Type == ai.ContentToolResult && !b.IsError && !session.IsEvicted(b.ResultText) {
+				live[b.ID] = true
+			}
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, e := range c.entries {
+		if !live[e.callID] {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// Stats returns the counts since the cache was created; zero for nil.
+func (c *ReadCache) Stats() ReadCacheStats {
+	if c == nil {
+		return ReadCacheStats{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
@@ -0,0 +1,141 @@
+// ABOUTME: Tests for the read cache: unchanged stubs, mtime and range invalidation, and pruning
+// ABOUTME: Also checks that reads without a cache in the context, such as sub-agents', are never stubbed
+
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+func readWith(t *testing.T, ctx context.Context, id string, params map[string]any) agent.ToolResult {
+	t.Helper()
+	res, err := NewReadTool().Execute(ctx, id, params, nil)
+	if err != nil || res.IsError {
+		t.Fatalf("read %s = %+v, %v", id, res, err)
+	}
+	return res
+}
+
+func isStub(res agent.ToolResult) bool {
+	return strings.HasPrefix(res.Content, "File unchanged since it was last read")
+}
+
+func TestReadCache_UnchangedStub(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(path, []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cache := NewReadCache()
+	ctx := WithReadCache(context.Background(), cache)
+	params := map[string]any{"path": path}
+
+	if res := readWith(t, ctx, "r1", params); res.Content != "package main\n" {
+		t.Fatalf("first read = %q", res.Content)
+	}
+	res := readWith(t, ctx, "r2", params)
+	if !isStub(res) || !strings.Contains(res.Content, "r1") {
+		t.Errorf("second read = %q; want a stub naming r1", res.Content)
+	}
+	if res := readWith(t, ctx, "r3", params); res.Content != "package main\n" {
+		t.Errorf("read after a stub = %q; want the content again", res.Content)
+	}
+	if res := readWith(t, ctx, "r4", map[string]any{"path": path, "limit": float64(1)}); isStub(res) {
+		t.Error("a different range should be read in full")
+	}
+
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if res := readWith(t, ctx, "r5", params); isStub(res) {
+		t.Error("a file with a new mtime should be read in full")
+	}
+
+	if got, want := cache.Stats(), (ReadCacheStats{Reads: 5, Hits: 1, BytesSaved: len("package main\n")}); got != want {
+		t.Errorf("Stats = %+v; want %+v", got, want)
+	}
+}
+
+func TestReadCache_Retain(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("notes\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cache := NewReadCache()
+	ctx := WithReadCache(context.Background(), cache)
+	params := map[string]any{"path": path}
+	readWith(t, ctx, "r1", params)
+
+	result := func(id, text string) ai.Message {
+		return ai.Message{Role: ai.RoleUser, Content: []ai.Content{{Type: ai.ContentToolResult, ID: id, ResultText: text}}}
+	}
+	cache.Retain([]ai.Message{result("r1", "notes\n")})
+	if res := readWith(t, ctx, "r2", params); !isStub(res) {
+		t.Error("a read still in the conversation should be stubbed")
+	}
+
+	readWith(t, ctx, "r3", params)
+	cache.Retain([]ai.Message{result("r3", "[read output (6 bytes) for notes.txt evicted from context after 3 turns]")})
+	if res := readWith(t, ctx, "r4", params); isStub(res) {
+		t.Error("an evicted read should be served in full")
+	}
+	cache.Retain(nil)
+	if res := readWith(t, ctx, "r5", params); isStub(res) {
+		t.Error("a cleared conversation should start with an empty cache")
+	}
+}
+
+func TestReadCache_Disabled(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(path, []byte("a\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cache := NewReadCache()
+	ctx := WithReadCache(WithReadCache(context.Background(), cache), nil)
+	for _, id := range []string{"r1", "r2"} {
+		if res := readWith(t, ctx, id, map[string]any{"path": path}); isStub(res) {
+			t.Errorf("%s was stubbed without a cache in the context", id)
+		}
+	}
+	if (*ReadCache)(nil).Stats() != (ReadCacheStats{}) || cache.Stats().Reads != 0 {
+		t.Error("a disabled cache should record nothing")
+	}
+}
+
+func TestReadCache_Forget(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.txt")
+	other := filepath.Join(dir, "a.txt.bak")
+	for _, p := range []string{path, other} {
+		if err := os.WriteFile(p, []byte("a\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cache := NewReadCache()
+	ctx := WithReadCache(context.Background(), cache)
+	readWith(t, ctx, "r1", map[string]any{"path": path})
+	readWith(t, ctx, "r2", map[string]any{"path": path, "offset": float64(1)})
+	readWith(t, ctx, "r3", map[string]any{"path": other})
+
+	cache.Forget(path)
+	for _, params := range []map[string]any{{"path": path}, {"path": path, "offset": float64(1)}} {
+		if res := readWith(t, ctx, "r4", params); isStub(res) {
+			t.Errorf("read %v after Forget was stubbed", params)
+		}
+	}
+	if res := readWith(t, ctx, "r5", map[string]any{"path": other}); !isStub(res) {
+		t.Error("Forget dropped a different file")
+	}
+}
@@ -20,9 +20,13 @@ var (
 
 // Registry manages the collection of available agent tools.
 type Registry struct {
-	tools   map[string]*agent.AgentTool
-	hasRg   bool
-	sandbox *permission.Sandbox
+	tools      map[string]*agent.AgentTool // as handed out, wrapped in middleware
+	raw        map[string]*agent.AgentTool // as registered
+	middleware []Middleware
+	limits     func(tool string) Limits // nil = DefaultLimits
+	hasRg      bool
+	sandbox    *permission.Sandbox
+	outputs    *OutputStore
 }
 
 // NewRegistry creates a Registry, auto-detects ripgrep, and registers built-in tools.
@@ -34,16 +38,20 @@ func NewRegistry() *Registry {
 func NewRegistryWithSandbox(sb *permission.Sandbox) *Registry {
 	r := &Registry{
 		tools:   make(map[string]*agent.AgentTool),
+		raw:     make(map[string]*agent.AgentTool),
 		hasRg:   detectRipgrep(),
 		sandbox: sb,
+		outputs: NewOutputStore(outputStoreCapacity),
 	}
 	r.registerBuiltins()
 	return r
 }
 
 // Register adds a tool to the registry, replacing any existing tool with the same name.
+// The tool runs inside the registry's middleware.
 func (r *Registry) Register(tool *agent.AgentTool) {
-	r.tools[tool.Name] = tool
+	r.raw[tool.Name] = tool
+	r.tools[tool.Name] = r.wrap(tool)
 }
 
 // Get returns a tool by name, or nil if not found.
@@ -79,6 +87,7 @@ func (r *Registry) Remove(spec string) {
 		name = spec[:idx]
 	}
 	delete(r.tools, name)
+	delete(r.raw, name)
 }
 
 // HasRipgrep reports whether ripgrep (rg) was found on PATH.
@@ -86,14 +95,20 @@ func (r *Registry) HasRipgrep() bool {
 	return r.hasRg
 }
 
+// Outputs returns the store holding full outputs whose long lines were summarized.
+func (r *Registry) Outputs() *OutputStore {
+	return r.outputs
+}
+
 // registerBuiltins adds all built-in tools to the registry.
+// Built-in outputs pass through long-line handling; read_tool_output reads them back.
 func (r *Registry) registerBuiltins() {
 	builtins := []*agent.AgentTool{
 		newReadTool(r.sandbox),
 		newReadImageTool(r.sandbox),
 		newWriteTool(r.sandbox),
-		newEditTool(r.sandbox),
-		NewBashTool(),
+		newEditTool(r.sandbox, nil),
+		newBashTool(r.sandbox),
 		NewGrepTool(r.hasRg),
 		NewFindTool(r.hasRg),
 		NewLsTool(),
@@ -104,10 +119,17 @@ func (r *Registry) registerBuiltins() {
 		NewFindReferencesTool(r.hasRg),
 		NewDependencyGraphTool(),
 		NewSearchDefinitionsTool(),
+		NewTodoWriteTool(),
 	}
 	for _, t := range builtins {
-		r.Register(t)
+		r.Register(withLongLineHandling(t, r.outputs))
 	}
+	r.Register(NewReadToolOutputTool(r.outputs))
+}
+
+// SetEditStrategies replaces the edit tool's match policy, tried in order.
+func (r *Registry) SetEditStrategies(strategies []MatchStrategy) {
+	r.Register(withLongLineHandling(newEditTool(r.sandbox, strategies), r.outputs))
 }
 
 // detectRipgrep checks whether rg is available on PATH.
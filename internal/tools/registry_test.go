@@ -18,6 +18,7 @@ func TestNewRegistry_RegistersAllBuiltins(t *testing.T) {
 	expectedTools := []string{
 		"read", "write", "edit", "bash", "grep", "find", "ls", "webfetch", "websearch",
 		"file_info", "validate_paths", "find_references", "dependency_graph", "search_definitions",
+		"read_tool_output", "todo_write",
 	}
 	if len(all) < len(expectedTools) {
 		t.Errorf("expected at least %d tools, got %d", len(expectedTools), len(all))
@@ -56,6 +57,7 @@ func TestRegistry_ReadOnly_FiltersCorrectly(t *testing.T) {
 		"read": true, "read_image": true, "grep": true, "find": true, "ls": true, "webfetch": true, "websearch": true,
 		"file_info": true, "validate_paths": true, "find_references": true,
 		"dependency_graph": true, "search_definitions": true,
+		"read_tool_output": true, "todo_write": true,
 	}
 	for _, tool := range roTools {
 		if !expectedReadOnly[tool.Name] {
@@ -118,6 +120,7 @@ func TestRegistry_ToolMetadata(t *testing.T) {
 		{"find_references", true},
 		{"dependency_graph", true},
 		{"search_definitions", true},
+		{"read_tool_output", true},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,142 @@
+// ABOUTME: Secret file guard: read and grep refuse files matching gitignore-style secret patterns
+// ABOUTME: Patterns support "!" re-includes and "**"; the user can allow single paths for the rest of the session
+
+package tools
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// secretGuard holds the secret file rules and the paths the user allowed
+// this session. The zero value guards nothing.
+type secretGuard struct {
+	mu      sync.RWMutex
+	rules   []ignoreRule
+	allowed map[string]bool
+}
+
+var secrets secretGuard
+
+// SetSecretFiles sets the gitignore-style patterns of files read and grep
+// refuse, as in .gitignore: a pattern without a slash matches a name at
+// any depth, one with a slash matches from the working directory, and the
+// last matching pattern wins, so "!.env.example" re-includes a template.
+// Nil turns the guard off. Call it once at startup, before the tools run.
+func SetSecretFiles(patterns []string) {
+	root, _ := os.Getwd()
+	rules := parseIgnore(root, []byte(strings.Join(patterns, "\n")))
+	secrets.mu.Lock()
+	defer secrets.mu.Unlock()
+	secrets.rules = rules
+	secrets.allowed = nil
+}
+
+// AllowSecretFile lets read and grep open path, or everything under it
+// when it is a directory, for the rest of the session.
+func AllowSecretFile(p string) {
+	cwd, _ := os.Getwd()
+	abs := ResolveToCwd(p, cwd)
+	secrets.mu.Lock()
+	defer secrets.mu.Unlock()
+	if secrets.allowed == nil {
+		secrets.allowed = make(map[string]bool)
+	}
+	secrets.allowed[abs] = true
+}
+
+// IsSecretFile reports whether p matches the secret file patterns and the
+// user has not allowed it. Symlinks are followed, so a link to a secret is
+// a secret too.
+func IsSecretFile(p string) bool {
+	secrets.mu.RLock()
+	defer secrets.mu.RUnlock()
+	if len(secrets.rules) == 0 {
+		return false
+	}
+	cwd, _ := os.Getwd()
+	abs := ResolveToCwd(p, cwd)
+	candidates := []string{abs}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil && resolved != abs {
+		candidates = append(candidates, resolved)
+	}
+	for _, c := range candidates {
+		if secrets.isAllowed(c) {
+			return false
+		}
+	}
+	for _, c := range candidates {
+		if secrets.matches(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkSecretRead fails with ErrSafetyPolicy when p is a secret file.
+func checkSecretRead(p string) error {
+	if !IsSecretFile(p) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s matches the secret file patterns and is not readable; ask the user to run /allow-read %s if it is needed", ErrSafetyPolicy, p, p)
+}
+
+// isAllowed reports whether abs or a directory containing it was allowed.
+// The caller holds the lock.
+func (g *secretGuard) isAllowed(abs string) bool {
+	for dir := abs; ; dir = filepath.Dir(dir) {
+		if g.allowed[dir] {
+			return true
+		}
+		if filepath.Dir(dir) == dir {
+			return false
+		}
+	}
+}
+
+// matches applies the rules in order to abs; the last match decides. Unlike
+// a walk, which prunes ignored directories, a single path is checked
+// against each of its directories too. The caller holds the lock.
+func (g *secretGuard) matches(abs string) bool {
+	secret := false
+	for _, r := range g.rules {
+		if secretRuleMatches(r, abs) {
+			secret = !r.negate
+		}
+	}
+	return secret
+}
+
+// secretRuleMatches reports whether r matches abs or one of its directories.
+// An unanchored rule matches a name anywhere, even outside its base, so
+// ~/.ssh/id_rsa is covered as well as the project's files; inside the base
+// only the path below it counts.
+func secretRuleMatches(r ignoreRule, abs string) bool {
+	segs := strings.Split(strings.TrimPrefix(filepath.ToSlash(abs), "/"), "/")
+	rel, err := filepath.Rel(r.base, abs)
+	inside := err == nil && rel != "." && !strings.HasPrefix(rel, "..")
+	switch {
+	case inside:
+		// The directories above the base do not count.
+		segs = strings.Split(filepath.ToSlash(rel), "/")
+	case r.anchored:
+		return false
+	}
+	for n := len(segs); n > 0; n-- {
+		if r.dirOnly && n == len(segs) {
+			continue
+		}
+		if r.anchored {
+			if matchSegments(r.segments, segs[:n]) {
+				return true
+			}
+		} else if ok, _ := path.Match(r.segments[0], segs[n-1]); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+// ABOUTME: Tests for the secret file guard: gitignore-style matching, session allows, and the read and grep tools
+// ABOUTME: Not parallel: the guard is process-wide and cleared after each test
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIsSecretFile(t *testing.T) {
+	t.Cleanup(func() { SetSecretFiles(nil) })
+	SetSecretFiles([]string{".env", ".env.*", "!.env.example", "*.pem", "id_rsa", "/config/credentials.json", "vault/"})
+
+	home, _ := os.UserHomeDir()
+	for p, want := range map[string]bool{
+		".env":                                true,
+		"api/.env.production":                 true,
+		".env.example":                        false,
+		"certs/server.pem":                    true,
+		filepath.Join(home, ".ssh", "id_rsa"): true,
+		"config/credentials.json":             true,
+		"sub/config/credentials.json":         false,
+		"vault/token":                         true,
+		"vault":                               false, // the directory itself, only its contents
+		"main.go":                             false,
+	} {
+		if got := IsSecretFile(p); got != want {
+			t.Errorf("IsSecretFile(%q) = %v; want %v", p, got, want)
+		}
+	}
+}
+
+func TestIsSecretFile_Off(t *testing.T) {
+	SetSecretFiles(nil)
+	if IsSecretFile(".env") {
+		t.Error("no patterns should guard nothing")
+	}
+}
+
+func TestAllowSecretFile(t *testing.T) {
+	t.Cleanup(func() { SetSecretFiles(nil) })
+	SetSecretFiles([]string{"*.key"})
+
+	AllowSecretFile("deploy/app.key")
+	AllowSecretFile("keys")
+	if IsSecretFile("deploy/app.key") || IsSecretFile("keys/b.key") {
+		t.Error("allowed paths should be readable")
+	}
+	if !IsSecretFile("deploy/other.key") {
+		t.Error("allowing one file should not allow its neighbours")
+	}
+
+	SetSecretFiles([]string{"*.key"})
+	if !IsSecretFile("deploy/app.key") {
+		t.Error("SetSecretFiles should reset the allowed paths")
+	}
+}
+
+func TestSecretFiles_ReadAndGrep(t *testing.T) {
+	t.Cleanup(func() { SetSecretFiles(nil) })
+	SetSecretFiles([]string{".env"})
+
+	dir := t.TempDir()
+	secret := filepath.Join(dir, ".env")
+	if err := os.WriteFile(secret, []byte("TOKEN=hunter2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "app.txt"), []byte("TOKEN is read from the environment\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := NewReadTool().Execute(context.Background(), "id", map[string]any{"path": secret}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.IsError || !strings.Contains(result.Content, "/allow-read") {
+		t.Errorf("read of a secret file = %+v; want a policy error naming /allow-read", result)
+	}
+	if err := checkSecretRead(secret); !errors.Is(err, ErrSafetyPolicy) {
+		t.Errorf("checkSecretRead = %v; want ErrSafetyPolicy", err)
+	}
+
+	// Hidden files are skipped anyway, so grep is checked with a visible one.
+	if err := os.WriteFile(filepath.Join(dir, "server.pem"), []byte("TOKEN=hunter2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	SetSecretFiles([]string{".env", "*.pem"})
+	backends := []bool{false}
+	if _, err := exec.LookPath("rg"); err == nil {
+		backends = append(backends, true)
+	}
+	for _, hasRg := range backends {
+		result, err := NewGrepTool(hasRg).Execute(context.Background(), "id", map[string]any{"pattern": "TOKEN", "path": dir}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(result.Content, "hunter2") || !strings.Contains(result.Content, "app.txt") {
+			t.Errorf("grep (rg %v) = %q; want matches from app.txt only", hasRg, result.Content)
+		}
+	}
+
+	AllowSecretFile(secret)
+	result, _ = NewReadTool().Execute(context.Background(), "id", map[string]any{"path": secret}, nil)
+	if result.IsError || !strings.Contains(result.Content, "hunter2") {
+		t.Errorf("read after /allow-read = %+v; want the content", result)
+	}
+}
@@ -0,0 +1,96 @@
+// ABOUTME: Skill tool: lets the model load a skill's instructions or one of its bundled resources
+// ABOUTME: The tool description lists available skills with their descriptions and triggers
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
+	"github.com/mauromedda/pi-coding-agent-go/internal/prompt"
+)
+
+// NewSkillTool creates a read-only tool exposing the given skills by name.
+func NewSkillTool(skills []prompt.Skill) *agent.AgentTool {
+	byName := make(map[string]prompt.Skill, len(skills))
+	for _, s := range skills {
+		byName[s.Name] = s
+	}
+
+	return &agent.AgentTool{
+		Name:        "skill",
+		Label:       "Skill",
+		Description: skillToolDescription(skills),
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"required": ["name"],
+			"properties": {
+				"name":     {"type": "string", "description": "Skill name"},
+				"resource": {"type": "string", "description": "Optional bundled resource path to read instead of SKILL.md"}
+			}
+		}`),
+		ReadOnly: true,
+		Execute: func(_ context.Context, _ string, params map[string]any, _ func(agent.ToolUpdate)) (agent.ToolResult, error) {
+			return executeSkill(byName, params)
+		},
+	}
+}
+
+func skillToolDescription(skills []prompt.Skill) string {
+	var b strings.Builder
+	b.WriteString("Loads a skill: a reusable instruction bundle for a specific domain or procedure.\n")
+	b.WriteString("Call with the skill name before working on a matching task; pass resource to read a bundled file.\n\nAvailable skills:\n")
+	sorted := slices.Clone(skills)
+	slices.SortFunc(sorted, func(a, b prompt.Skill) int { return strings.Compare(a.Name, b.Name) })
+	for _, s := range sorted {
+		fmt.Fprintf(&b, "- %s", s.Name)
+		if s.Description != "" {
+			fmt.Fprintf(&b, ": %s", s.Description)
+		}
+		if len(s.Triggers) > 0 {
+			fmt.Fprintf(&b, " (triggers: %s)", strings.Join(s.Triggers, ", "))
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func executeSkill(byName map[string]prompt.Skill, params map[string]any) (agent.ToolResult, error) {
+	name, err := requireStringParam(params, "name")
+	if err != nil {
+		return errResult(err), nil
+	}
+	s, ok := byName[name]
+	if !ok {
+		return errResult(fmt.Errorf("unknown skill %q", name)), nil
+	}
+
+	resource := stringParam(params, "resource", "")
+	if resource == "" {
+		var b strings.Builder
+		fmt.Fprintf(&b, "# Skill: %s\n\n%s\n", s.Name, s.Content)
+		if len(s.Resources) > 0 {
+			b.WriteString("\nBundled resources (read with the resource parameter):\n")
+			for _, r := range s.Resources {
+				fmt.Fprintf(&b, "- %s\n", r)
+			}
+		}
+		return agent.ToolResult{Content: b.String()}, nil
+	}
+
+	if !slices.Contains(s.Resources, resource) {
+		return errResult(fmt.Errorf("skill %q has no resource %q", name, resource)), nil
+	}
+	path := filepath.Join(filepath.Dir(s.SourcePath), filepath.FromSlash(resource))
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return errResult(fmt.Errorf("reading skill resource: %w", err)), nil
+	}
+	return agent.ToolResult{Content: TruncateHead(string(data), DefaultMaxLines, DefaultMaxBytes).Content}, nil
+}
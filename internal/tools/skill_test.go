@@ -0,0 +1,71 @@
+// ABOUTME: Tests for the skill tool: listing, loading instructions, and reading resources
+// ABOUTME: Uses temp skill directories with bundled resource files
+
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/prompt"
+)
+
+func testSkill(t *testing.T) prompt.Skill {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "deploy")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "checklist.md"), []byte("1. tag release"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return prompt.Skill{
+		Name:        "deploy",
+		Description: "Release procedure",
+		Triggers:    []string{"deploy"},
+		Content:     "Follow the checklist.",
+		SourcePath:  filepath.Join(dir, "SKILL.md"),
+		Resources:   []string{"checklist.md"},
+	}
+}
+
+func TestSkillTool_DescriptionListsSkills(t *testing.T) {
+	t.Parallel()
+	tool := NewSkillTool([]prompt.Skill{testSkill(t)})
+	if !strings.Contains(tool.Description, "- deploy: Release procedure (triggers: deploy)") {
+		t.Errorf("description missing skill entry:\n%s", tool.Description)
+	}
+	if !tool.ReadOnly {
+		t.Error("skill tool should be read-only")
+	}
+}
+
+func TestSkillTool_LoadsContentAndResource(t *testing.T) {
+	t.Parallel()
+	tool := NewSkillTool([]prompt.Skill{testSkill(t)})
+
+	res, _ := tool.Execute(context.Background(), "1", map[string]any{"name": "deploy"}, nil)
+	if res.IsError || !strings.Contains(res.Content, "Follow the checklist.") || !strings.Contains(res.Content, "- checklist.md") {
+		t.Errorf("unexpected content: %q", res.Content)
+	}
+
+	res, _ = tool.Execute(context.Background(), "2", map[string]any{"name": "deploy", "resource": "checklist.md"}, nil)
+	if res.IsError || res.Content != "1. tag release" {
+		t.Errorf("resource content = %q (error=%v)", res.Content, res.IsError)
+	}
+}
+
+func TestSkillTool_RejectsUnknownAndTraversal(t *testing.T) {
+	t.Parallel()
+	tool := NewSkillTool([]prompt.Skill{testSkill(t)})
+
+	if res, _ := tool.Execute(context.Background(), "1", map[string]any{"name": "nope"}, nil); !res.IsError {
+		t.Error("expected error for unknown skill")
+	}
+	if res, _ := tool.Execute(context.Background(), "2", map[string]any{"name": "deploy", "resource": "../../etc/passwd"}, nil); !res.IsError {
+		t.Error("expected error for resource outside the bundle")
+	}
+}
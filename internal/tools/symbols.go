@@ -0,0 +1,205 @@
+// ABOUTME: Symbol lookup for @symbol mentions: finds where a function, method, or type is defined, with its line span
+// ABOUTME: Go files use the AST; other languages use search_definitions' patterns with brace or indentation spans
+
+package tools
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxSymbolLines caps the span of a definition found by pattern, where the
+// end is a guess.
+const maxSymbolLines = 400
+
+// SymbolDef is one definition found by FindSymbols.
+type SymbolDef struct {
+	Path      string // file path, under the searched root
+	Kind      string // func, method, struct, interface, type, or def
+	Name      string
+	StartLine int // first line, including a Go doc comment
+	EndLine   int
+}
+
+// FindSymbols returns the definitions of name under root, in path order,
+// over the files find and grep search. name is a plain identifier, or
+// Type.Method for a Go method.
+func FindSymbols(root, name string) []SymbolDef {
+	want := name
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		want = name[i+1:]
+	}
+	var defs []SymbolDef
+	_ = walkFiles(root, func(fpath string, _ os.DirEntry) {
+		ext := filepath.Ext(fpath)
+		if ext != ".go" && langForExt(ext) == "" {
+			return
+		}
+		data, err := os.ReadFile(fpath)
+		if err != nil || !bytes.Contains(data, []byte(want)) {
+			return
+		}
+		if ext == ".go" {
+			defs = append(defs, goSymbols(fpath, data, name)...)
+		} else if !strings.Contains(name, ".") {
+			defs = append(defs, patternSymbols(fpath, data, name)...)
+		}
+	})
+	return defs
+}
+
+// goSymbols returns the functions, methods, and types named name in a Go
+// file. A Type.Method name matches that method only.
+func goSymbols(fpath string, data []byte, name string) []SymbolDef {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, fpath, data, parser.ParseComments)
+	if err != nil {
+		return nil
+	}
+	recv, want, isMethod := strings.Cut(name, ".")
+	if !isMethod {
+		want = name
+	}
+	span := func(node ast.Node, doc *ast.CommentGroup) (int, int) {
+		start := node.Pos()
+		if doc != nil {
+			start = doc.Pos()
+		}
+		return fset.Position(start).Line, fset.Position(node.End()).Line
+	}
+
+	var defs []SymbolDef
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Name.Name != want {
+				continue
+			}
+			def := SymbolDef{Path: fpath, Kind: "func", Name: d.Name.Name}
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				r := receiverTypeName(d.Recv.List[0].Type)
+				def.Kind, def.Name = "method", r+"."+d.Name.Name
+				if isMethod && r != recv {
+					continue
+				}
+			} else if isMethod {
+				continue
+			}
+			def.StartLine, def.EndLine = span(d, d.Doc)
+			defs = append(defs, def)
+		case *ast.GenDecl:
+			if d.Tok != token.TYPE || isMethod {
+				continue
+			}
+			for _, spec := range d.Specs {
+				ts, ok := spec.(*ast.TypeSpec)
+				if !ok || ts.Name.Name != want {
+					continue
+				}
+				kind := "type"
+				switch ts.Type.(type) {
+				case *ast.StructType:
+					kind = "struct"
+				case *ast.InterfaceType:
+					kind = "interface"
+				}
+				def := SymbolDef{Path: fpath, Kind: kind, Name: ts.Name.Name}
+				if len(d.Specs) == 1 {
+					def.StartLine, def.EndLine = span(d, d.Doc)
+				} else {
+					def.StartLine, def.EndLine = span(ts, ts.Doc)
+				}
+				defs = append(defs, def)
+			}
+		}
+	}
+	return defs
+}
+
+// langForExt returns the language of langPatterns that a file extension
+// belongs to, or "".
+func langForExt(ext string) string {
+	for lang, exts := range langExtensions {
+		for _, e := range exts {
+			if e == ext {
+				return lang
+			}
+		}
+	}
+	return ""
+}
+
+// indentLanguages end a definition at the next line indented no deeper.
+var indentLanguages = map[string]bool{"python": true, "ruby": true}
+
+// patternSymbols returns the definitions named name in a file of a
+// language that search_definitions matches by pattern.
+func patternSymbols(fpath string, data []byte, name string) []SymbolDef {
+	lang := langForExt(filepath.Ext(fpath))
+	re := langPatterns[lang]
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	var defs []SymbolDef
+	for i, line := range lines {
+		m := re.FindStringSubmatch(line)
+		if m == nil || m[len(m)-1] != name {
+			continue
+		}
+		end := braceEnd(lines, i)
+		if indentLanguages[lang] {
+			end = indentEnd(lines, i, lang == "ruby")
+		}
+		defs = append(defs, SymbolDef{Path: fpath, Kind: "def", Name: name, StartLine: i + 1, EndLine: end + 1})
+	}
+	return defs
+}
+
+// braceEnd returns the index of the line closing the braces opened from
+// line start on. A statement ending with ";" before any brace ends there.
+func braceEnd(lines []string, start int) int {
+	depth, opened := 0, false
+	for i := start; i < len(lines) && i < start+maxSymbolLines; i++ {
+		for _, r := range lines[i] {
+			switch r {
+			case '{':
+				depth++
+				opened = true
+			case '}':
+				depth--
+			}
+		}
+		if opened && depth <= 0 {
+			return i
+		}
+		if !opened && strings.HasSuffix(strings.TrimSpace(lines[i]), ";") {
+			return i
+		}
+	}
+	return min(start+maxSymbolLines, len(lines)) - 1
+}
+
+// indentEnd returns the index of the last line of the block that starts at
+// line start: the lines indented deeper than it, plus a closing "end" line
+// for Ruby.
+func indentEnd(lines []string, start int, closingEnd bool) int {
+	indent := func(s string) int { return len(s) - len(strings.TrimLeft(s, " \t")) }
+	base := indent(lines[start])
+	last := start
+	for i := start + 1; i < len(lines) && i < start+maxSymbolLines; i++ {
+		if strings.TrimSpace(lines[i]) == "" {
+			continue
+		}
+		if indent(lines[i]) <= base {
+			if closingEnd && strings.TrimSpace(lines[i]) == "end" {
+				return i
+			}
+			return last
+		}
+		last = i
+	}
+	return last
+}
@@ -0,0 +1,94 @@
+// ABOUTME: Tests for FindSymbols: Go functions, methods, and types with doc comments, and pattern-matched languages
+// ABOUTME: Checks the brace and indentation spans used for languages without a parser
+
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindSymbols_Go(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	src := `package x
+
+// Model is the app.
+type Model struct {
+	n int
+}
+
+// Update steps the model.
+func (m *Model) Update() {
+	m.n++
+}
+
+func Update() {}
+
+type (
+	A int
+	B string
+)
+`
+	if err := os.WriteFile(filepath.Join(root, "x.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(root, "x.go")
+
+	for _, tc := range []struct {
+		name string
+		want []SymbolDef
+	}{
+		{"Model", []SymbolDef{{Path: path, Kind: "struct", Name: "Model", StartLine: 3, EndLine: 6}}},
+		{"Model.Update", []SymbolDef{{Path: path, Kind: "method", Name: "Model.Update", StartLine: 8, EndLine: 11}}},
+		{"Update", []SymbolDef{
+			{Path: path, Kind: "method", Name: "Model.Update", StartLine: 8, EndLine: 11},
+			{Path: path, Kind: "func", Name: "Update", StartLine: 13, EndLine: 13},
+		}},
+		{"B", []SymbolDef{{Path: path, Kind: "type", Name: "B", StartLine: 17, EndLine: 17}}},
+		{"Other.Update", nil},
+	} {
+		got := FindSymbols(root, tc.name)
+		if len(got) != len(tc.want) {
+			t.Errorf("FindSymbols(%q) = %+v; want %+v", tc.name, got, tc.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("FindSymbols(%q)[%d] = %+v; want %+v", tc.name, i, got[i], tc.want[i])
+			}
+		}
+	}
+}
+
+func TestFindSymbols_Patterns(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	files := map[string]string{
+		"app.ts": "export function render(a: number) {\n  if (a) {\n    return 1;\n  }\n}\nconst other = 2;\n",
+		"lib.py": "class Parser:\n    def parse(self):\n        pass\n\n    x = 1\n\ndef main():\n    pass\n",
+		"run.rb": "def start\n  puts 1\nend\n",
+	}
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tc := range []struct {
+		name       string
+		start, end int
+	}{
+		{"render", 1, 5},
+		{"other", 6, 6},
+		{"Parser", 1, 5},
+		{"parse", 2, 3},
+		{"start", 1, 3},
+	} {
+		got := FindSymbols(root, tc.name)
+		if len(got) != 1 || got[0].StartLine != tc.start || got[0].EndLine != tc.end {
+			t.Errorf("FindSymbols(%q) = %+v; want lines %d-%d", tc.name, got, tc.start, tc.end)
+		}
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
 )
@@ -16,7 +18,7 @@ func NewTaskTool(deps agent.SpawnDeps, defs map[string]agent.Definition) *agent.
 	return &agent.AgentTool{
 		Name:        "task",
 		Label:       "Launch Sub-Agent",
-		Description: "Launch a specialized agent to handle a task. Available agents: explore, plan, bash_agent, or custom-defined agents.",
+		Description: taskToolDescription(defs),
 		Parameters: json.RawMessage(`{
 			"type": "object",
 			"required": ["agent", "prompt"],
@@ -50,13 +52,14 @@ func NewTaskTool(deps agent.SpawnDeps, defs map[string]agent.Definition) *agent.
 				Description:     def.Description,
 				Model:           def.Model,
 				SystemPrompt:    def.SystemPrompt,
-				Tools:           def.Tools,
+				Tools:           def.ToolAllowlist(),
 				DisallowedTools: def.DisallowedTools,
 				MaxTurns:        def.MaxTurns,
 				Background:      background,
 			}
 
-			handle, err := agent.Spawn(ctx, cfg, prompt, deps)
+			// The sub-agent has not seen the parent's reads.
+			handle, err := agent.Spawn(WithReadCache(ctx, nil), cfg, prompt, deps)
 			if err != nil {
 				return errResult(fmt.Errorf("spawning agent %q: %w", agentName, err)), nil
 			}
@@ -80,3 +83,23 @@ func NewTaskTool(deps agent.SpawnDeps, defs map[string]agent.Definition) *agent.
 		},
 	}
 }
+
+// taskToolDescription lists the available agents so the model can pick one.
+func taskToolDescription(defs map[string]agent.Definition) string {
+	var b strings.Builder
+	b.WriteString("Launch a specialized sub-agent with its own model, tools, and system prompt to handle a self-contained task. ")
+	b.WriteString("The agent starts with no conversation history: put everything it needs in the prompt. Available agents:\n")
+	names := make([]string, 0, len(defs))
+	for name := range defs {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		fmt.Fprintf(&b, "- %s", name)
+		if d := defs[name].Description; d != "" {
+			fmt.Fprintf(&b, ": %s", d)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
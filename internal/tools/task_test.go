@@ -0,0 +1,45 @@
+// ABOUTME: Tests for the task tool delegating to named sub-agent definitions
+// ABOUTME: Covers the generated agent listing and unknown-agent errors
+
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
+)
+
+func TestTaskTool_DescriptionListsAgents(t *testing.T) {
+	t.Parallel()
+
+	defs := map[string]agent.Definition{
+		"reviewer": {Name: "reviewer", Description: "Reviews diffs"},
+		"explore":  {Name: "explore", Description: "Explores code"},
+	}
+	tool := NewTaskTool(agent.SpawnDeps{}, defs)
+
+	desc := tool.Description
+	ex := strings.Index(desc, "- explore: Explores code")
+	rv := strings.Index(desc, "- reviewer: Reviews diffs")
+	if ex < 0 || rv < 0 {
+		t.Fatalf("description missing agents:\n%s", desc)
+	}
+	if ex > rv {
+		t.Error("agents should be listed alphabetically")
+	}
+}
+
+func TestTaskTool_UnknownAgent(t *testing.T) {
+	t.Parallel()
+
+	tool := NewTaskTool(agent.SpawnDeps{}, map[string]agent.Definition{})
+	res, err := tool.Execute(context.Background(), "id", map[string]any{"agent": "ghost", "prompt": "hi"}, nil)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if !res.IsError || !strings.Contains(res.Content, "ghost") {
+		t.Errorf("expected unknown agent error, got %+v", res)
+	}
+}
@@ -0,0 +1,106 @@
+// ABOUTME: todo_write tool: the model keeps a task list for the current job, replaced whole on each call
+// ABOUTME: ParseTodos is shared with the TUI, which renders the list as a live checklist
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
+)
+
+// TodoWriteToolName is the name of the todo_write tool.
+const TodoWriteToolName = "todo_write"
+
+// Todo statuses.
+const (
+	TodoPending    = "pending"
+	TodoInProgress = "in_progress"
+	TodoDone       = "done"
+)
+
+// TodoItem is one entry of the task list.
+type TodoItem struct {
+	Content string `json:"content"`
+	Status  string `json:"status"`
+}
+
+// NewTodoWriteTool creates a tool that replaces the current task list.
+// It has no side effects, so it is available in plan mode too.
+func NewTodoWriteTool() *agent.AgentTool {
+	return &agent.AgentTool{
+		Name:  TodoWriteToolName,
+		Label: "Todo List",
+		Description: "Maintain a task list for the current job. Each call replaces the whole list. " +
+			"Use it for work of three or more steps: add the steps, mark one in_progress before " +
+			"starting it, and mark it done as soon as it is finished.",
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"required": ["todos"],
+			"properties": {
+				"todos": {
+					"type": "array",
+					"description": "The complete task list, in order",
+					"items": {
+						"type": "object",
+						"required": ["content", "status"],
+						"properties": {
+							"content": {"type": "string", "description": "What the task is"},
+							"status":  {"type": "string", "enum": ["pending", "in_progress", "done"]}
+						}
+					}
+				}
+			}
+		}`),
+		ReadOnly: true,
+		Execute:  executeTodoWrite,
+	}
+}
+
+func executeTodoWrite(_ context.Context, _ string, params map[string]any, _ func(agent.ToolUpdate)) (agent.ToolResult, error) {
+	todos, err := ParseTodos(params)
+	if err != nil {
+		return errResult(err), nil
+	}
+	counts := map[string]int{}
+	for _, t := range todos {
+		counts[t.Status]++
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Todo list updated: %d done, %d in progress, %d pending.\n",
+		counts[TodoDone], counts[TodoInProgress], counts[TodoPending])
+	for _, t := range todos {
+		fmt.Fprintf(&b, "[%s] %s\n", t.Status, t.Content)
+	}
+	return agent.ToolResult{Content: strings.TrimSuffix(b.String(), "\n")}, nil
+}
+
+// ParseTodos reads the todos parameter of a todo_write call.
+func ParseTodos(params map[string]any) ([]TodoItem, error) {
+	raw, ok := params["todos"].([]any)
+	if !ok {
+		return nil, fmt.Errorf("missing required parameter %q", "todos")
+	}
+	todos := make([]TodoItem, 0, len(raw))
+	for i, v := range raw {
+		entry, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("todos[%d]: expected an object", i)
+		}
+		content, _ := entry["content"].(string)
+		if content = strings.TrimSpace(content); content == "" {
+			return nil, fmt.Errorf("todos[%d]: content is empty", i)
+		}
+		status, _ := entry["status"].(string)
+		switch status {
+		case TodoPending, TodoInProgress, TodoDone:
+		default:
+			return nil, fmt.Errorf("todos[%d]: status %q is not pending, in_progress, or done", i, status)
+		}
+		todos = append(todos, TodoItem{Content: content, Status: status})
+	}
+	return todos, nil
+}
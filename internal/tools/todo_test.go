@@ -0,0 +1,55 @@
+// ABOUTME: Tests for the todo_write tool: list parsing, validation, and the summary it returns
+// ABOUTME: The tool is stateless; each call carries the whole list
+
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func todoParams(items ...[2]string) map[string]any {
+	todos := make([]any, 0, len(items))
+	for _, it := range items {
+		todos = append(todos, map[string]any{"content": it[0], "status": it[1]})
+	}
+	return map[string]any{"todos": todos}
+}
+
+func TestTodoWrite_Summary(t *testing.T) {
+	t.Parallel()
+
+	tool := NewTodoWriteTool()
+	params := todoParams([2]string{"parse flags", "done"}, [2]string{"wire it", "in_progress"}, [2]string{"docs", "pending"})
+	result, err := tool.Execute(context.Background(), "", params, nil)
+	if err != nil || result.IsError {
+		t.Fatalf("Execute = %+v, %v", result, err)
+	}
+	for _, want := range []string{"1 done, 1 in progress, 1 pending", "[in_progress] wire it"} {
+		if !strings.Contains(result.Content, want) {
+			t.Errorf("expected %q in output:\n%s", want, result.Content)
+		}
+	}
+}
+
+func TestParseTodos_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]map[string]any{
+		"missing list":   {},
+		"not an object":  {"todos": []any{"x"}},
+		"empty content":  todoParams([2]string{"  ", "pending"}),
+		"unknown status": todoParams([2]string{"x", "blocked"}),
+	}
+	for name, params := range tests {
+		if _, err := ParseTodos(params); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	todos, err := ParseTodos(todoParams())
+	if err != nil || len(todos) != 0 {
+		t.Errorf("empty list = %v, %v; want it accepted to clear the list", todos, err)
+	}
+}
@@ -1,4 +1,4 @@
-// ABOUTME: Tests for WebFetch HTML-to-markdown, WebSearch result formatting, and cache
+// ABOUTME: Tests for WebFetch HTML-to-markdown, WebSearch result formatting, and caching
 // ABOUTME: Uses in-process test HTTP server to avoid external dependencies
 
 package tools
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
+	"github.com/mauromedda/pi-coding-agent-go/internal/fetchcache"
 )
 
 func TestHtmlToMarkdown_Basic(t *testing.T) {
@@ -159,38 +160,56 @@ func TestWebSearch_MissingAPIKey(t *testing.T) {
 	}
 }
 
-func TestWebCache_Basic(t *testing.T) {
-	c := newWebCache()
+func TestWebFetch_RevalidatesStaleEntry(t *testing.T) {
+	var conditional []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if inm := r.Header.Get("If-None-Match"); inm != "" {
+			conditional = append(conditional, inm)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`<html><body><p>Versioned content</p></body></html>`))
+	}))
+	defer srv.Close()
 
-	c.Set("key1", "value1")
-	v, ok := c.Get("key1")
-	if !ok || v != "value1" {
-		t.Errorf("expected value1, got %q (ok=%v)", v, ok)
-	}
-}
+	prev := fetchCache
+	defer SetFetchCache(prev)
+	SetFetchCache(fetchcache.New(fetchcache.Options{TTL: time.Nanosecond}))
 
-func TestWebCache_Miss(t *testing.T) {
-	c := newWebCache()
-	_, ok := c.Get("nonexistent")
-	if ok {
-		t.Error("expected cache miss")
+	tool := NewWebFetchTool()
+	_, _ = tool.Execute(context.Background(), "t1", map[string]any{"url": srv.URL}, nil)
+	time.Sleep(time.Millisecond)
+	result, _ := tool.Execute(context.Background(), "t2", map[string]any{"url": srv.URL}, nil)
+
+	if len(conditional) != 1 || conditional[0] != `"v1"` {
+		t.Fatalf("conditional requests = %v, want one with the stored ETag", conditional)
+	}
+	if !strings.Contains(result.Content, "Versioned content") {
+		t.Errorf("304 should serve cached content, got %q", result.Content)
+	}
+	if st := fetchCache.Stats(); st.Revalidated != 1 {
+		t.Errorf("Revalidated = %d, want 1", st.Revalidated)
 	}
 }
 
-func TestWebCache_Eviction(t *testing.T) {
-	c := newWebCache()
-
-	// Fill to capacity
-	for i := range cacheMaxEntries + 5 {
-		c.Set(string(rune('a'+i%26))+string(rune('0'+i/26)), "v")
-	}
+func TestWebFetch_NilCacheAlwaysFetches(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write([]byte(`<html><body><p>Uncached</p></body></html>`))
+	}))
+	defer srv.Close()
 
-	c.mu.Lock()
-	count := len(c.entries)
-	c.mu.Unlock()
+	prev := fetchCache
+	defer SetFetchCache(prev)
+	SetFetchCache(nil)
 
-	if count > cacheMaxEntries {
-		t.Errorf("cache should not exceed %d entries, has %d", cacheMaxEntries, count)
+	tool := NewWebFetchTool()
+	_, _ = tool.Execute(context.Background(), "t1", map[string]any{"url": srv.URL}, nil)
+	_, _ = tool.Execute(context.Background(), "t2", map[string]any{"url": srv.URL}, nil)
+	if calls != 2 {
+		t.Errorf("expected 2 server calls with caching disabled, got %d", calls)
 	}
 }
 
@@ -1,5 +1,5 @@
 // ABOUTME: WebFetch tool: fetches URLs, extracts readable content, converts to markdown
-// ABOUTME: Uses golang.org/x/net/html for parsing; 15-minute LRU cache
+// ABOUTME: Uses golang.org/x/net/html for parsing; results cached by URL with ETag revalidation
 
 package tools
 
@@ -13,10 +13,19 @@ import (
 	"time"
 
 	"github.com/mauromedda/pi-coding-agent-go/internal/agent"
+	"github.com/mauromedda/pi-coding-agent-go/internal/fetchcache"
 	"golang.org/x/net/html"
 )
 
-var fetchCache = newWebCache()
+// fetchCache holds fetched pages; an in-memory store until SetFetchCache
+// replaces it at startup.
+var fetchCache = fetchcache.New(fetchcache.Options{})
+
+// SetFetchCache replaces the store used by webfetch. Nil disables caching.
+// Call before any tool runs.
+func SetFetchCache(s *fetchcache.Store) {
+	fetchCache = s
+}
 
 // NewWebFetchTool creates a tool that fetches and extracts content from URLs.
 func NewWebFetchTool() *agent.AgentTool {
@@ -48,9 +57,12 @@ func executeWebFetch(ctx context.Context, _ string, params map[string]any, _ fun
 		url = "https://" + url[7:]
 	}
 
-	// Check cache
-	if cached, ok := fetchCache.Get(url); ok {
-		return agent.ToolResult{Content: cached}, nil
+	var cached fetchcache.Lookup
+	if fetchCache != nil {
+		cached = fetchCache.Lookup(url)
+		if cached.Fresh {
+			return agent.ToolResult{Content: cached.Content}, nil
+		}
 	}
 
 	client := &http.Client{Timeout: 30 * time.Second}
@@ -59,6 +71,15 @@ func executeWebFetch(ctx context.Context, _ string, params map[string]any, _ fun
 		return errResult(fmt.Errorf("creating request: %w", err)), nil
 	}
 	req.Header.Set("User-Agent", "pi-go/1.0")
+	// A stale entry is revalidated instead of refetched.
+	if cached.Found {
+		if cached.Validators.ETag != "" {
+			req.Header.Set("If-None-Match", cached.Validators.ETag)
+		}
+		if cached.Validators.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.Validators.LastModified)
+		}
+	}
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -66,6 +87,11 @@ func executeWebFetch(ctx context.Context, _ string, params map[string]any, _ fun
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified && cached.Found {
+		fetchCache.Revalidate(url)
+		return agent.ToolResult{Content: cached.Content}, nil
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		return errResult(fmt.Errorf("HTTP %d from %s", resp.StatusCode, url)), nil
 	}
@@ -78,7 +104,12 @@ func executeWebFetch(ctx context.Context, _ string, params map[string]any, _ fun
 	content := htmlToMarkdown(string(body))
 	content = truncateOutput(content, maxReadOutput)
 
-	fetchCache.Set(url, content)
+	if fetchCache != nil {
+		fetchCache.Put(url, content, fetchcache.Validators{
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+		})
+	}
 	return agent.ToolResult{Content: content}, nil
 }
 
@@ -52,6 +52,9 @@ func executeWrite(sb *permission.Sandbox, _ context.Context, _ string, params ma
 
 	path := ExpandPath(rawPath)
 
+	if err := checkWritePolicy(path); err != nil {
+		return errResult(err), nil
+	}
 	if sb != nil {
 		if err := sb.ValidatePath(path); err != nil {
 			return errResult(err), nil
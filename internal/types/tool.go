@@ -28,6 +28,18 @@ type ToolResult struct {
 // ToolUpdate carries incremental output from a running tool.
 type ToolUpdate struct {
 	Output string
+	Step   *ToolStep // set by composite tools to report one of their steps
+}
+
+// ToolStep is one step of a composite tool, reported when it starts and
+// again when it finishes. Steps are shown as nested tool calls.
+type ToolStep struct {
+	Index   int    `json:"index"` // position in the run; forEach expansions count separately
+	Tool    string `json:"tool"`
+	Args    string `json:"args,omitempty"` // JSON
+	Done    bool   `json:"done,omitempty"`
+	IsError bool   `json:"is_error,omitempty"`
+	Output  string `json:"output,omitempty"` // set when Done
 }
 
 // AgentTool defines a tool that the agent can invoke during its loop.
@@ -0,0 +1,347 @@
+// ABOUTME: Provider cassettes: record every streamed response to a JSON file and replay it later without the API
+// ABOUTME: Replays match a request by model and messages, fall back to recording order, and keep the recorded timing
+
+package ai
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// cassetteVersion is the version of the cassette file format.
+const cassetteVersion = 1
+
+// Cassette is a recorded sequence of provider calls.
+type Cassette struct {
+	Version      int           `json:"version"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is one recorded call: the request key, the streamed events
+// with their offsets from the start of the call, and the final message.
+type Interaction struct {
+	Key    string            `json:"key"`
+	Api    Api               `json:"api"`
+	Model  string            `json:"model"`
+	Events []RecordedEvent   `json:"events"`
+	Result *AssistantMessage `json:"result,omitempty"`
+}
+
+// RecordedEvent is a StreamEvent in serializable form.
+type RecordedEvent struct {
+	AtMs       int64      `json:"at_ms"`
+	Type       string     `json:"type"`
+	Text       string     `json:"text,omitempty"`
+	ToolID     string     `json:"tool_id,omitempty"`
+	ToolName   string     `json:"tool_name,omitempty"`
+	ToolInput  string     `json:"tool_input,omitempty"`
+	Usage      *Usage     `json:"usage,omitempty"`
+	StopReason StopReason `json:"stop_reason,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// eventTypeNames names each StreamEventType in cassette files.
+var eventTypeNames = map[StreamEventType]string{
+	EventContentDelta:  "content_delta",
+	EventContentDone:   "content_done",
+	EventToolUseStart:  "tool_use_start",
+	EventToolUseDelta:  "tool_use_delta",
+	EventToolUseDone:   "tool_use_done",
+	EventThinkingDelta: "thinking_delta",
+	EventMessageStart:  "message_start",
+	EventMessageDelta:  "message_delta",
+	EventMessageDone:   "message_done",
+	EventPing:          "ping",
+	EventError:         "error",
+}
+
+// recordEvent converts ev, received at offset from the start of the call.
+func recordEvent(ev StreamEvent, offset time.Duration) RecordedEvent {
+	rec := RecordedEvent{
+		AtMs:       offset.Milliseconds(),
+		Type:       eventTypeNames[ev.Type],
+		Text:       ev.Text,
+		ToolID:     ev.ToolID,
+		ToolName:   ev.ToolName,
+		ToolInput:  ev.ToolInput,
+		Usage:      ev.Usage,
+		StopReason: ev.StopReason,
+	}
+	if ev.Error != nil {
+		rec.Error = ev.Error.Error()
+	}
+	return rec
+}
+
+// event converts rec back into a StreamEvent.
+func (rec RecordedEvent) event() (StreamEvent, error) {
+	ev := StreamEvent{
+		Text:       rec.Text,
+		ToolID:     rec.ToolID,
+		ToolName:   rec.ToolName,
+		ToolInput:  rec.ToolInput,
+		Usage:      rec.Usage,
+		StopReason: rec.StopReason,
+	}
+	found := false
+	for t, name := range eventTypeNames {
+		if name == rec.Type {
+			ev.Type, found = t, true
+			break
+		}
+	}
+	if !found {
+		return StreamEvent{}, fmt.Errorf("unknown event type %q", rec.Type)
+	}
+	if rec.Error != "" {
+		ev.Error = errors.New(rec.Error)
+	}
+	return ev, nil
+}
+
+// requestKey identifies a request by model and messages. The system prompt
+// is left out: it embeds the date, git status, and other details that
+// change between recording and replay.
+func requestKey(model *Model, llmCtx *Context) string {
+	h := sha256.New()
+	if model != nil {
+		h.Write([]byte(model.ID))
+	}
+	h.Write([]byte{0})
+	if llmCtx != nil {
+		data, _ := json.Marshal(llmCtx.Messages)
+		h.Write(data)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// LoadCassette reads the cassette at path.
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading cassette: %w", err)
+	}
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("parsing cassette %s: %w", path, err)
+	}
+	if c.Version != cassetteVersion {
+		return nil, fmt.Errorf("cassette %s: unsupported version %d", path, c.Version)
+	}
+	return &c, nil
+}
+
+// Save writes the cassette to path, replacing it atomically.
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding cassette: %w", err)
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("creating cassette directory: %w", err)
+		}
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing cassette: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// Recorder appends each finished call of the providers it wraps to a
+// cassette file. The file is rewritten after every call, so an interrupted
+// session keeps what it recorded.
+type Recorder struct {
+	path string
+
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// NewRecorder records to path. An existing file is replaced on the first
+// finished call.
+func NewRecorder(path string) *Recorder {
+	return &Recorder{path: path, cassette: Cassette{Version: cassetteVersion}}
+}
+
+// Wrap returns a provider that passes calls through to inner and records
+// them.
+func (r *Recorder) Wrap(inner ApiProvider) ApiProvider {
+	return &recordingProvider{rec: r, inner: inner}
+}
+
+// add appends it to the cassette and saves the file. A save failure is
+// not the call's failure; the next call tries again.
+func (r *Recorder) add(it Interaction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, it)
+	_ = r.cassette.Save(r.path)
+}
+
+// Flush writes the cassette recorded so far.
+func (r *Recorder) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cassette.Save(r.path)
+}
+
+// recordingProvider is the ApiProvider view of a Recorder for one provider.
+type recordingProvider struct {
+	rec   *Recorder
+	inner ApiProvider
+}
+
+// Api returns the wrapped provider's API.
+func (rp *recordingProvider) Api() Api { return rp.inner.Api() }
+
+// Stream forwards the call's events unchanged while recording them.
+func (rp *recordingProvider) Stream(ctx context.Context, model *Model, llmCtx *Context, opts *StreamOptions) *EventStream {
+	bufSize := 64
+	if opts != nil && opts.StreamBufferSize > 0 {
+		bufSize = opts.StreamBufferSize
+	}
+	out := NewEventStream(bufSize)
+	it := Interaction{Key: requestKey(model, llmCtx), Api: rp.inner.Api()}
+	if model != nil {
+		it.Model = model.ID
+	}
+	start := time.Now()
+	in := rp.inner.Stream(ctx, model, llmCtx, opts)
+	go func() {
+		for ev := range in.Events() {
+			it.Events = append(it.Events, recordEvent(ev, time.Since(start)))
+			out.Send(ev)
+		}
+		it.Result = in.Result()
+		rp.rec.add(it)
+		out.Finish(it.Result)
+	}()
+	return out
+}
+
+// ReplayOptions configures a Replayer.
+type ReplayOptions struct {
+	// Speed scales the recorded delays between events: 1 replays in real
+	// time, 2 twice as fast, 0 without delays.
+	Speed float64
+	// Strict fails a call that matches no recorded request instead of
+	// replaying the next unused interaction.
+	Strict bool
+}
+
+// Replayer answers calls from a cassette, never contacting the API.
+type Replayer struct {
+	opts ReplayOptions
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewReplayer creates a Replayer over c.
+func NewReplayer(c *Cassette, opts ReplayOptions) *Replayer {
+	return &Replayer{opts: opts, interactions: c.Interactions, used: make([]bool, len(c.Interactions))}
+}
+
+// Provider returns an ApiProvider for api that replays from the cassette.
+func (p *Replayer) Provider(api Api) ApiProvider {
+	return &replayProvider{rep: p, api: api}
+}
+
+// Remaining returns how many recorded calls have not been replayed.
+func (p *Replayer) Remaining() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := 0
+	for _, u := range p.used {
+		if !u {
+			n++
+		}
+	}
+	return n
+}
+
+// next claims the first unused interaction with key or, unless strict,
+// the first unused one.
+func (p *Replayer) next(key string) (Interaction, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fallback := -1
+	for i, it := range p.interactions {
+		if p.used[i] {
+			continue
+		}
+		if it.Key == key {
+			p.used[i] = true
+			return it, true
+		}
+		if fallback < 0 {
+			fallback = i
+		}
+	}
+	if fallback < 0 || p.opts.Strict {
+		return Interaction{}, false
+	}
+	p.used[fallback] = true
+	return p.interactions[fallback], true
+}
+
+// play sends the events of it at their recorded offsets, scaled by Speed.
+func (p *Replayer) play(ctx context.Context, out *EventStream, it Interaction) {
+	start := time.Now()
+	for _, rec := range it.Events {
+		if p.opts.Speed > 0 {
+			at := time.Duration(float64(rec.AtMs)*float64(time.Millisecond)/p.opts.Speed) - time.Since(start)
+			if at > 0 {
+				select {
+				case <-time.After(at):
+				case <-ctx.Done():
+					out.FinishWithError(ctx.Err())
+					return
+				}
+			}
+		}
+		ev, err := rec.event()
+		if err != nil {
+			out.FinishWithError(fmt.Errorf("cassette: %w", err))
+			return
+		}
+		out.Send(ev)
+	}
+	out.Finish(it.Result)
+}
+
+// replayProvider is the ApiProvider view of a Replayer for one API.
+type replayProvider struct {
+	rep *Replayer
+	api Api
+}
+
+// Api returns the API the provider stands in for.
+func (rp *replayProvider) Api() Api { return rp.api }
+
+// Stream replays the recorded call matching the request.
+func (rp *replayProvider) Stream(ctx context.Context, model *Model, llmCtx *Context, opts *StreamOptions) *EventStream {
+	bufSize := 64
+	if opts != nil && opts.StreamBufferSize > 0 {
+		bufSize = opts.StreamBufferSize
+	}
+	out := NewEventStream(bufSize)
+	it, ok := rp.rep.next(requestKey(model, llmCtx))
+	if !ok {
+		out.FinishWithError(errors.New("cassette: no recorded response for this request"))
+		return out
+	}
+	go rp.rep.play(ctx, out, it)
+	return out
+}
@@ -0,0 +1,172 @@
+// ABOUTME: Tests for cassette recording and replay: round-trip, request matching, ordering fallback, timing
+// ABOUTME: A scripted provider answers each call with a reply derived from the last user message
+
+package ai
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// echoProvider replies with the last message's text, or fails when the
+// text is "fail".
+type echoProvider struct{ calls int }
+
+func (p *echoProvider) Api() Api { return ApiOpenAI }
+
+func (p *echoProvider) Stream(_ context.Context, _ *Model, llmCtx *Context, _ *StreamOptions) *EventStream {
+	p.calls++
+	s := NewEventStream(4)
+	text := llmCtx.Messages[len(llmCtx.Messages)-1].Content[0].Text
+	go func() {
+		if text == "fail" {
+			s.FinishWithError(errors.New("rate limited"))
+			return
+		}
+		s.Send(StreamEvent{Type: EventContentDelta, Text: "echo: "})
+		time.Sleep(20 * time.Millisecond)
+		s.Send(StreamEvent{Type: EventContentDelta, Text: text})
+		s.Finish(&AssistantMessage{
+			Content:    []Content{{Type: ContentText, Text: "echo: " + text}},
+			StopReason: StopEndTurn,
+			Usage:      Usage{InputTokens: 10, OutputTokens: 2},
+		})
+	}()
+	return s
+}
+
+func ask(text string) *Context {
+	return &Context{Messages: []Message{NewTextMessage(RoleUser, text)}}
+}
+
+// collect drains a stream into its text, result, and error.
+func collect(t *testing.T, s *EventStream) (string, *AssistantMessage, error) {
+	t.Helper()
+	var b strings.Builder
+	var err error
+	for ev := range s.Events() {
+		switch ev.Type {
+		case EventContentDelta:
+			b.WriteString(ev.Text)
+		case EventError:
+			err = ev.Error
+		}
+	}
+	return b.String(), s.Result(), err
+}
+
+func recordCassette(t *testing.T, prompts ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "cassettes", "session.json")
+	inner := &echoProvider{}
+	p := NewRecorder(path).Wrap(inner)
+	model := &Model{ID: "test-model"}
+	for _, prompt := range prompts {
+		collect(t, p.Stream(context.Background(), model, ask(prompt), nil))
+	}
+	if inner.calls != len(prompts) {
+		t.Fatalf("inner calls = %d; want %d", inner.calls, len(prompts))
+	}
+	return path
+}
+
+func TestRecorder_RoundTrip(t *testing.T) {
+	t.Parallel()
+	path := recordCassette(t, "one", "two", "fail")
+
+	c, err := LoadCassette(path)
+	if err != nil {
+		t.Fatalf("LoadCassette: %v", err)
+	}
+	if len(c.Interactions) != 3 {
+		t.Fatalf("got %d interactions; want 3", len(c.Interactions))
+	}
+	if c.Interactions[0].Model != "test-model" || c.Interactions[0].Api != ApiOpenAI {
+		t.Errorf("interaction = %+v", c.Interactions[0])
+	}
+	if last := c.Interactions[1].Events[1]; last.AtMs < 15 {
+		t.Errorf("second event at %dms; want the recorded delay", last.AtMs)
+	}
+
+	rep := NewReplayer(c, ReplayOptions{})
+	p := rep.Provider(ApiOpenAI)
+	model := &Model{ID: "test-model"}
+
+	// Out of order: requests match by key, not position.
+	text, res, err := collect(t, p.Stream(context.Background(), model, ask("two"), nil))
+	if err != nil || text != "echo: two" {
+		t.Errorf("replay two = %q, %v", text, err)
+	}
+	if res == nil || res.Usage.InputTokens != 10 || res.StopReason != StopEndTurn {
+		t.Errorf("result = %+v", res)
+	}
+	_, res, err = collect(t, p.Stream(context.Background(), model, ask("fail"), nil))
+	if err == nil || err.Error() != "rate limited" || res != nil {
+		t.Errorf("replay fail = %v, %+v; want the recorded error", err, res)
+	}
+	if rep.Remaining() != 1 {
+		t.Errorf("Remaining = %d; want 1", rep.Remaining())
+	}
+}
+
+func TestReplayer_Fallback(t *testing.T) {
+	t.Parallel()
+	c, err := LoadCassette(recordCassette(t, "one"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	strict := NewReplayer(c, ReplayOptions{Strict: true}).Provider(ApiOpenAI)
+	if _, _, err := collect(t, strict.Stream(context.Background(), &Model{ID: "test-model"}, ask("changed"), nil)); err == nil {
+		t.Error("strict replay of an unrecorded request should fail")
+	}
+
+	loose := NewReplayer(c, ReplayOptions{}).Provider(ApiOpenAI)
+	text, _, err := collect(t, loose.Stream(context.Background(), &Model{ID: "test-model"}, ask("changed"), nil))
+	if err != nil || text != "echo: one" {
+		t.Errorf("fallback replay = %q, %v; want the next recorded call", text, err)
+	}
+	if _, _, err := collect(t, loose.Stream(context.Background(), &Model{ID: "test-model"}, ask("again"), nil)); err == nil {
+		t.Error("replay past the end of the cassette should fail")
+	}
+}
+
+func TestReplayer_Timing(t *testing.T) {
+	t.Parallel()
+	c := &Cassette{Version: cassetteVersion, Interactions: []Interaction{{
+		Key: "k",
+		Events: []RecordedEvent{
+			{AtMs: 0, Type: "content_delta", Text: "a"},
+			{AtMs: 200, Type: "content_delta", Text: "b"},
+		},
+		Result: &AssistantMessage{},
+	}}}
+
+	start := time.Now()
+	collect(t, NewReplayer(c, ReplayOptions{Speed: 2}).Provider(ApiAnthropic).Stream(context.Background(), nil, nil, nil))
+	if d := time.Since(start); d < 90*time.Millisecond {
+		t.Errorf("replay at speed 2 took %s; want about 100ms", d)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err := collect(t, NewReplayer(c, ReplayOptions{Speed: 1}).Provider(ApiAnthropic).Stream(ctx, nil, nil, nil))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled replay err = %v; want context.Canceled", err)
+	}
+}
+
+func TestLoadCassette_Version(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "c.json")
+	if err := (&Cassette{Version: 99}).Save(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadCassette(path); err == nil {
+		t.Error("expected an error for an unknown version")
+	}
+}
@@ -4,15 +4,15 @@
 package httputil
 
 import (
+	"bytes"
 	"context"
-	"crypto/tls"
 	"fmt"
 	"io"
 	"math"
-	"net"
 	"net/http"
 	"time"
 
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
 	"github.com/mauromedda/pi-coding-agent-go/pkg/ai/internal/sse"
 )
 
@@ -32,27 +32,18 @@ type Client struct {
 // NewClient creates a new HTTP client with the given base URL and default headers.
 // Proxy support comes from the stdlib's default transport (HTTP_PROXY, HTTPS_PROXY).
 func NewClient(baseURL string, headers map[string]string) *Client {
+	return NewClientWithOptions(baseURL, headers, ai.HTTPOptions{})
+}
+
+// NewClientWithOptions is NewClient with explicit transport timeouts and keepalive.
+func NewClientWithOptions(baseURL string, headers map[string]string, opts ai.HTTPOptions) *Client {
 	if headers == nil {
 		headers = make(map[string]string)
 	}
 	return &Client{
-		httpClient: &http.Client{
-			Timeout: 5 * time.Minute,
-			Transport: &http.Transport{
-				Proxy: http.ProxyFromEnvironment,
-				DialContext: (&net.Dialer{
-					Timeout:   30 * time.Second,
-					KeepAlive: 30 * time.Second,
-				}).DialContext,
-				TLSClientConfig:       &tls.Config{MinVersion: tls.VersionTLS12},
-				TLSHandshakeTimeout:   10 * time.Second,
-				ResponseHeaderTimeout: 30 * time.Second,
-				MaxIdleConns:          100,
-				IdleConnTimeout:       90 * time.Second,
-			},
-		},
-		baseURL: baseURL,
-		headers: headers,
+		httpClient: opts.NewClient(),
+		baseURL:    baseURL,
+		headers:    headers,
 	}
 }
 
@@ -131,6 +122,42 @@ func (c *Client) StreamSSE(
 	return sse.NewReader(resp.Body), resp, nil
 }
 
+// Warm opens a pooled connection to the base URL so the next request skips
+// DNS, TCP, and TLS setup. Any HTTP response counts as success.
+func (c *Client) Warm(ctx context.Context) error {
+	req, err := c.buildRequest(ctx, http.MethodHead, "/", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("warming connection: %w", err)
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.Body.Close()
+}
+
+// PostDiscard sends body to path once, without retries, and drains the
+// response so the connection returns to the pool. It returns an error for
+// non-2xx statuses.
+func (c *Client) PostDiscard(ctx context.Context, path string, body []byte) error {
+	req, err := c.buildRequest(ctx, http.MethodPost, path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("http request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("status %d: %s", resp.StatusCode, msg)
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
 // buildRequest creates an http.Request with default headers applied.
 func (c *Client) buildRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
 	url := c.baseURL + path
@@ -260,3 +260,40 @@ func TestClientDoRespectsContext(t *testing.T) {
 		t.Fatal("expected error from cancelled context, got nil")
 	}
 }
+
+func TestClientWarmAndPostDiscard(t *testing.T) {
+	t.Parallel()
+
+	var heads, posts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodHead:
+			heads.Add(1)
+			w.WriteHeader(http.StatusNotFound) // any response warms the connection
+		case http.MethodPost:
+			posts.Add(1)
+			if r.URL.Path == "/fail" {
+				w.WriteHeader(http.StatusInternalServerError)
+				_, _ = w.Write([]byte("boom"))
+				return
+			}
+			_, _ = w.Write([]byte(`{"ok":true}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	client := NewClient(srv.URL, nil)
+	if err := client.Warm(context.Background()); err != nil {
+		t.Fatalf("Warm() error = %v", err)
+	}
+	if err := client.PostDiscard(context.Background(), "/ok", []byte("{}")); err != nil {
+		t.Fatalf("PostDiscard() error = %v", err)
+	}
+	err := client.PostDiscard(context.Background(), "/fail", []byte("{}"))
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("PostDiscard(/fail) error = %v, want status and body", err)
+	}
+	if heads.Load() != 1 || posts.Load() != 2 {
+		t.Errorf("heads = %d, posts = %d; want 1 and 2 (no retries)", heads.Load(), posts.Load())
+	}
+}
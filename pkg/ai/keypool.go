@@ -0,0 +1,244 @@
+// ABOUTME: KeyPool: several API keys for one provider with per-key token caps and rotation
+// ABOUTME: A rate-limited or exhausted key is skipped; usage per key is reported for /cost
+
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// keyCooldown is how long a rate-limited key is skipped.
+const keyCooldown = time.Minute
+
+// PoolKey is one key of a KeyPool.
+type PoolKey struct {
+	Name      string // label in usage reports; never the key itself
+	Key       string
+	MaxTokens int // input plus output tokens this key may use; 0 = uncapped
+}
+
+// KeyUsage is the consumption of one pooled key.
+type KeyUsage struct {
+	Name         string
+	InputTokens  int
+	OutputTokens int
+	Requests     int
+	RateLimited  int // requests rejected for rate limits or quota
+	MaxTokens    int
+	Exhausted    bool // cap reached, or the provider reported no quota left
+	CoolingDown  bool // rate-limited recently and skipped for now
+}
+
+// KeyPool spreads calls for one API over several keys. Calls use the
+// current key until it is rate-limited or reaches its cap, then move to
+// the next available key. State is shared by every Provider of the pool.
+type KeyPool struct {
+	api         Api
+	newProvider func(apiKey, baseURL string) ApiProvider
+	now         func() time.Time
+
+	mu        sync.Mutex
+	keys      []*pooledKey
+	current   int
+	providers map[string][]ApiProvider // per base URL, one per key
+}
+
+type pooledKey struct {
+	PoolKey
+	usage     KeyUsage
+	coolUntil time.Time
+}
+
+// NewKeyPool creates a pool over keys. newProvider builds the provider for
+// one key and base URL.
+func NewKeyPool(api Api, keys []PoolKey, newProvider func(apiKey, baseURL string) ApiProvider) *KeyPool {
+	p := &KeyPool{
+		api:         api,
+		newProvider: newProvider,
+		now:         time.Now,
+		providers:   make(map[string][]ApiProvider),
+	}
+	for i, k := range keys {
+		if k.Name == "" {
+			k.Name = fmt.Sprintf("key-%d", i+1)
+		}
+		p.keys = append(p.keys, &pooledKey{PoolKey: k, usage: KeyUsage{Name: k.Name, MaxTokens: k.MaxTokens}})
+	}
+	return p
+}
+
+// Api returns the API the pool serves.
+func (p *KeyPool) Api() Api { return p.api }
+
+// Provider returns an ApiProvider for baseURL that draws keys from the
+// pool. It matches ProviderFactory, so a pool can be registered directly.
+func (p *KeyPool) Provider(baseURL string) ApiProvider {
+	return &pooledProvider{pool: p, baseURL: baseURL}
+}
+
+// Usage returns the consumption of every key, in configuration order.
+func (p *KeyPool) Usage() []KeyUsage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	out := make([]KeyUsage, len(p.keys))
+	for i, k := range p.keys {
+		out[i] = k.usage
+		out[i].CoolingDown = now.Before(k.coolUntil)
+	}
+	return out
+}
+
+// acquire returns the index and provider of the next usable key, skipping
+// tried ones, or -1 when every key is exhausted, cooling down, or tried.
+func (p *KeyPool) acquire(baseURL string, tried map[int]bool) (int, ApiProvider) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	for n := range len(p.keys) {
+		i := (p.current + n) % len(p.keys)
+		k := p.keys[i]
+		if tried[i] || k.usage.Exhausted || now.Before(k.coolUntil) {
+			continue
+		}
+		p.current = i
+		provs, ok := p.providers[baseURL]
+		if !ok {
+			provs = make([]ApiProvider, len(p.keys))
+			p.providers[baseURL] = provs
+		}
+		if provs[i] == nil {
+			provs[i] = p.newProvider(k.Key, baseURL)
+		}
+		return i, provs[i]
+	}
+	return -1, nil
+}
+
+// record adds a finished call's usage to key i and retires the key once
+// it reaches its cap.
+func (p *KeyPool) record(i int, u Usage) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	k := p.keys[i]
+	k.usage.Requests++
+	k.usage.InputTokens += u.InputTokens + u.CacheRead + u.CacheCreate
+	k.usage.OutputTokens += u.OutputTokens
+	if k.MaxTokens > 0 && k.usage.InputTokens+k.usage.OutputTokens >= k.MaxTokens {
+		k.usage.Exhausted = true
+	}
+}
+
+// reject notes that key i was refused: out of quota retires it, a rate
+// limit sets it aside for keyCooldown.
+func (p *KeyPool) reject(i int, quota bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	k := p.keys[i]
+	k.usage.RateLimited++
+	if quota {
+		k.usage.Exhausted = true
+	} else {
+		k.coolUntil = p.now().Add(keyCooldown)
+	}
+}
+
+// pooledProvider is the ApiProvider view of a KeyPool for one base URL.
+type pooledProvider struct {
+	pool    *KeyPool
+	baseURL string
+}
+
+// Api returns the pool's API.
+func (pp *pooledProvider) Api() Api { return pp.pool.api }
+
+// Stream runs the call on the current key. When the provider rejects the
+// key before sending anything, the call is retried on the next key.
+func (pp *pooledProvider) Stream(ctx context.Context, model *Model, llmCtx *Context, opts *StreamOptions) *EventStream {
+	bufSize := 64
+	if opts != nil && opts.StreamBufferSize > 0 {
+		bufSize = opts.StreamBufferSize
+	}
+	out := NewEventStream(bufSize)
+	go pp.run(ctx, out, model, llmCtx, opts)
+	return out
+}
+
+func (pp *pooledProvider) run(ctx context.Context, out *EventStream, model *Model, llmCtx *Context, opts *StreamOptions) {
+	tried := make(map[int]bool)
+	var lastErr error
+	for {
+		i, prov := pp.pool.acquire(pp.baseURL, tried)
+		if i < 0 {
+			if lastErr == nil {
+				lastErr = fmt.Errorf("all %d %s keys are exhausted or rate-limited", len(pp.pool.keys), pp.pool.api)
+			} else {
+				lastErr = fmt.Errorf("all %d %s keys are exhausted or rate-limited: %w", len(pp.pool.keys), pp.pool.api, lastErr)
+			}
+			out.FinishWithError(lastErr)
+			return
+		}
+		tried[i] = true
+
+		in := prov.Stream(ctx, model, llmCtx, opts)
+		forwarded := false
+		var streamErr error
+		for ev := range in.Events() {
+			if ev.Type == EventError && !forwarded {
+				streamErr = ev.Error
+				continue
+			}
+			forwarded = true
+			out.Send(ev)
+		}
+		result := in.Result()
+
+		if streamErr != nil {
+			if limited, quota := keyRejected(streamErr); limited && ctx.Err() == nil {
+				pp.pool.reject(i, quota)
+				lastErr = streamErr
+				continue
+			}
+			out.FinishWithError(streamErr)
+			return
+		}
+		if result != nil {
+			pp.pool.record(i, result.Usage)
+		}
+		out.Finish(result)
+		return
+	}
+}
+
+// Prewarm warms the current key's provider when it supports prewarming.
+func (pp *pooledProvider) Prewarm(ctx context.Context, model *Model, llmCtx *Context, prefix bool) error {
+	i, prov := pp.pool.acquire(pp.baseURL, nil)
+	if i < 0 {
+		return nil
+	}
+	if w, ok := prov.(Prewarmer); ok {
+		return w.Prewarm(ctx, model, llmCtx, prefix)
+	}
+	return nil
+}
+
+// keyRejected classifies a provider error: limited when the key was
+// refused for rate or quota reasons, quota when it has no capacity left.
+// Overload errors concern the service, not the key, and are not rotated.
+func keyRejected(err error) (limited, quota bool) {
+	msg := strings.ToLower(err.Error())
+	quota = strings.Contains(msg, "insufficient_quota") ||
+		strings.Contains(msg, "quota exceeded") ||
+		strings.Contains(msg, "credit balance") ||
+		strings.Contains(msg, "resource_exhausted") ||
+		strings.Contains(msg, "status 402")
+	limited = quota ||
+		strings.Contains(msg, "status 429") ||
+		strings.Contains(msg, "rate limit") ||
+		strings.Contains(msg, "rate_limit")
+	return limited, quota
+}
@@ -0,0 +1,171 @@
+// ABOUTME: Tests for KeyPool rotation on rate limits and quota, token caps, and usage reports
+// ABOUTME: A scripted provider answers each call per key with usage or an error
+
+package ai
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// keyedProvider answers calls for one key from a shared script.
+type keyedProvider struct {
+	key    string
+	script *keyScript
+}
+
+// keyScript maps keys to the error they return (nil = success) and
+// records the keys used, in order.
+type keyScript struct {
+	mu   sync.Mutex
+	errs map[string]error
+	used []string
+}
+
+func (p *keyedProvider) Api() Api { return Api("pool-test") }
+
+func (p *keyedProvider) Stream(_ context.Context, _ *Model, _ *Context, _ *StreamOptions) *EventStream {
+	s := NewEventStream(4)
+	p.script.mu.Lock()
+	p.script.used = append(p.script.used, p.key)
+	err := p.script.errs[p.key]
+	p.script.mu.Unlock()
+	go func() {
+		if err != nil {
+			s.FinishWithError(err)
+			return
+		}
+		s.Send(StreamEvent{Type: EventContentDelta, Text: "hi from " + p.key})
+		s.Finish(&AssistantMessage{Usage: Usage{InputTokens: 100, OutputTokens: 20}})
+	}()
+	return s
+}
+
+func newTestPool(script *keyScript, keys ...PoolKey) *KeyPool {
+	return NewKeyPool(Api("pool-test"), keys, func(apiKey, _ string) ApiProvider {
+		return &keyedProvider{key: apiKey, script: script}
+	})
+}
+
+// callPool runs one call and returns the streamed text and error.
+func callPool(t *testing.T, p ApiProvider) (string, error) {
+	t.Helper()
+	s := p.Stream(context.Background(), &Model{}, &Context{}, nil)
+	var text strings.Builder
+	var err error
+	for ev := range s.Events() {
+		switch ev.Type {
+		case EventContentDelta:
+			text.WriteString(ev.Text)
+		case EventError:
+			err = ev.Error
+		}
+	}
+	s.Result()
+	return text.String(), err
+}
+
+func TestKeyPool_RotatesOnRateLimit(t *testing.T) {
+	script := &keyScript{errs: map[string]error{
+		"k1": errors.New("anthropic API error (status 429): rate_limit_error"),
+	}}
+	pool := newTestPool(script, PoolKey{Name: "a", Key: "k1"}, PoolKey{Name: "b", Key: "k2"})
+	prov := pool.Provider("")
+
+	text, err := callPool(t, prov)
+	if err != nil || text != "hi from k2" {
+		t.Fatalf("call = %q, %v; want the second key's answer", text, err)
+	}
+	// The limited key cools down, so the next call goes straight to k2.
+	callPool(t, prov)
+	if got := strings.Join(script.used, ","); got != "k1,k2,k2" {
+		t.Errorf("keys used = %s; want k1,k2,k2", got)
+	}
+
+	usage := pool.Usage()
+	if usage[0].RateLimited != 1 || !usage[0].CoolingDown || usage[0].Requests != 0 {
+		t.Errorf("key a usage = %+v", usage[0])
+	}
+	if usage[1].Requests != 2 || usage[1].InputTokens != 200 || usage[1].OutputTokens != 40 {
+		t.Errorf("key b usage = %+v", usage[1])
+	}
+
+	// After the cooldown the first key is usable again.
+	pool.now = func() time.Time { return time.Now().Add(2 * keyCooldown) }
+	delete(script.errs, "k1")
+	pool.mu.Lock()
+	pool.current = 0
+	pool.mu.Unlock()
+	if text, _ := callPool(t, prov); text != "hi from k1" {
+		t.Errorf("after cooldown = %q; want k1", text)
+	}
+}
+
+func TestKeyPool_CapExhaustsKey(t *testing.T) {
+	script := &keyScript{errs: map[string]error{}}
+	pool := newTestPool(script, PoolKey{Name: "small", Key: "k1", MaxTokens: 100}, PoolKey{Name: "big", Key: "k2"})
+	prov := pool.Provider("")
+
+	callPool(t, prov) // 120 tokens on k1 reaches its cap
+	callPool(t, prov)
+	if got := strings.Join(script.used, ","); got != "k1,k2" {
+		t.Errorf("keys used = %s; want k1,k2", got)
+	}
+	if u := pool.Usage()[0]; !u.Exhausted || u.MaxTokens != 100 {
+		t.Errorf("capped key usage = %+v", u)
+	}
+}
+
+func TestKeyPool_QuotaErrorRetiresKey(t *testing.T) {
+	script := &keyScript{errs: map[string]error{
+		"k1": errors.New("openai API error (status 429): insufficient_quota"),
+	}}
+	pool := newTestPool(script, PoolKey{Key: "k1"}, PoolKey{Key: "k2"})
+	callPool(t, pool.Provider(""))
+	u := pool.Usage()
+	if !u[0].Exhausted || u[0].CoolingDown || u[0].Name != "key-1" {
+		t.Errorf("quota-rejected key = %+v; want exhausted, named key-1", u[0])
+	}
+}
+
+func TestKeyPool_AllKeysLimited(t *testing.T) {
+	limited := errors.New("API error: status 429: slow down")
+	script := &keyScript{errs: map[string]error{"k1": limited, "k2": limited}}
+	pool := newTestPool(script, PoolKey{Key: "k1"}, PoolKey{Key: "k2"})
+
+	_, err := callPool(t, pool.Provider(""))
+	if err == nil || !strings.Contains(err.Error(), "all 2 pool-test keys") || !strings.Contains(err.Error(), "slow down") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestKeyPool_OtherErrorsAreNotRotated(t *testing.T) {
+	script := &keyScript{errs: map[string]error{
+		"k1": errors.New("anthropic API error (status 529): overloaded"),
+	}}
+	pool := newTestPool(script, PoolKey{Key: "k1"}, PoolKey{Key: "k2"})
+	if _, err := callPool(t, pool.Provider("")); err == nil || !strings.Contains(err.Error(), "overloaded") {
+		t.Errorf("err = %v; want the overload error", err)
+	}
+	if len(script.used) != 1 {
+		t.Errorf("keys used = %v; want only k1", script.used)
+	}
+}
+
+func TestKeyPool_ProvidersPerBaseURL(t *testing.T) {
+	var built []string
+	pool := NewKeyPool(Api("pool-test"), []PoolKey{{Key: "k1"}}, func(apiKey, baseURL string) ApiProvider {
+		built = append(built, apiKey+"@"+baseURL)
+		return &keyedProvider{key: apiKey, script: &keyScript{}}
+	})
+	callPool(t, pool.Provider("u1"))
+	callPool(t, pool.Provider("u1"))
+	callPool(t, pool.Provider("u2"))
+	if got := strings.Join(built, ","); got != "k1@u1,k1@u2" {
+		t.Errorf("built = %s", got)
+	}
+}
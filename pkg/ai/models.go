@@ -6,66 +6,82 @@ package ai
 // Built-in model definitions.
 var (
 	ModelClaude4Opus = Model{
-		ID:               "claude-opus-4-20250514",
-		Name:             "Claude Opus 4",
-		Api:              ApiAnthropic,
-		MaxTokens:        200000,
-		MaxOutputTokens:  16384,
-		SupportsImages:   true,
-		SupportsTools:    true,
-		SupportsThinking: true,
+		ID:                        "claude-opus-4-20250514",
+		Name:                      "Claude Opus 4",
+		Api:                       ApiAnthropic,
+		MaxTokens:                 200000,
+		MaxOutputTokens:           16384,
+		SupportsImages:            true,
+		SupportsTools:             true,
+		SupportsParallelToolCalls: true,
+		SupportsThinking:          true,
 	}
 
 	ModelClaude4Sonnet = Model{
-		ID:               "claude-sonnet-4-20250514",
-		Name:             "Claude Sonnet 4",
-		Api:              ApiAnthropic,
-		MaxTokens:        200000,
-		MaxOutputTokens:  16384,
-		SupportsImages:   true,
-		SupportsTools:    true,
-		SupportsThinking: true,
+		ID:                        "claude-sonnet-4-20250514",
+		Name:                      "Claude Sonnet 4",
+		Api:                       ApiAnthropic,
+		MaxTokens:                 200000,
+		MaxOutputTokens:           16384,
+		SupportsImages:            true,
+		SupportsTools:             true,
+		SupportsParallelToolCalls: true,
+		SupportsThinking:          true,
 	}
 
 	ModelClaude35Haiku = Model{
-		ID:               "claude-3-5-haiku-20241022",
-		Name:             "Claude 3.5 Haiku",
-		Api:              ApiAnthropic,
-		MaxTokens:        200000,
-		MaxOutputTokens:  8192,
-		SupportsImages:   true,
-		SupportsTools:    true,
-		SupportsThinking: false,
+		ID:                        "claude-3-5-haiku-20241022",
+		Name:                      "Claude 3.5 Haiku",
+		Api:                       ApiAnthropic,
+		MaxTokens:                 200000,
+		MaxOutputTokens:           8192,
+		SupportsImages:            true,
+		SupportsTools:             true,
+		SupportsParallelToolCalls: true,
+		SupportsThinking:          false,
 	}
 
 	ModelGPT4o = Model{
-		ID:              "gpt-4o",
-		Name:            "GPT-4o",
-		Api:             ApiOpenAI,
-		MaxTokens:       128000,
-		MaxOutputTokens: 16384,
-		SupportsImages:  true,
-		SupportsTools:   true,
+		ID:                        "gpt-4o",
+		Name:                      "GPT-4o",
+		Api:                       ApiOpenAI,
+		MaxTokens:                 128000,
+		MaxOutputTokens:           16384,
+		SupportsImages:            true,
+		SupportsTools:             true,
+		SupportsParallelToolCalls: true,
 	}
 
 	ModelGPT4oMini = Model{
-		ID:              "gpt-4o-mini",
-		Name:            "GPT-4o Mini",
-		Api:             ApiOpenAI,
-		MaxTokens:       128000,
-		MaxOutputTokens: 16384,
-		SupportsImages:  true,
-		SupportsTools:   true,
+		ID:                        "gpt-4o-mini",
+		Name:                      "GPT-4o Mini",
+		Api:                       ApiOpenAI,
+		MaxTokens:                 128000,
+		MaxOutputTokens:           16384,
+		SupportsImages:            true,
+		SupportsTools:             true,
+		SupportsParallelToolCalls: true,
 	}
 
 	ModelGemini25Pro = Model{
-		ID:              "gemini-2.5-pro",
-		Name:            "Gemini 2.5 Pro",
-		Api:             ApiGoogle,
-		MaxTokens:       1000000,
-		MaxOutputTokens: 65536,
-		SupportsImages:  true,
-		SupportsTools:   true,
+		ID:                        "gemini-2.5-pro",
+		Name:                      "Gemini 2.5 Pro",
+		Api:                       ApiGoogle,
+		MaxTokens:                 1000000,
+		MaxOutputTokens:           65536,
+		SupportsImages:            true,
+		SupportsTools:             true,
+		SupportsParallelToolCalls: true,
+	}
+
+	ModelMock = Model{
+		ID:                        "mock",
+		Name:                      "Mock (echo)",
+		Api:                       ApiMock,
+		MaxTokens:                 200000,
+		MaxOutputTokens:           8192,
+		SupportsTools:             true,
+		SupportsParallelToolCalls: true,
 	}
 )
 
@@ -78,6 +94,7 @@ func BuiltinModels() []Model {
 		ModelGPT4o,
 		ModelGPT4oMini,
 		ModelGemini25Pro,
+		ModelMock,
 	}
 }
 
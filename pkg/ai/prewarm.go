@@ -0,0 +1,15 @@
+// ABOUTME: Prewarmer: optional provider hook that prepares for the next call while the user types
+// ABOUTME: Opens a pooled connection and, when asked, caches the stable prompt prefix server-side
+
+package ai
+
+import "context"
+
+// Prewarmer is implemented by providers that can get ready for the next
+// call while the agent is idle, cutting the next turn's time to first token.
+type Prewarmer interface {
+	// Prewarm opens a pooled connection to the provider. With prefix set, it
+	// also sends llmCtx as a one-token request so the provider caches the
+	// prompt prefix the next turn reuses; that request is billed.
+	Prewarm(ctx context.Context, model *Model, llmCtx *Context, prefix bool) error
+}
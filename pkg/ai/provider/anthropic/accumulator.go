@@ -24,8 +24,9 @@ type blockState struct {
 	contentType ai.ContentType
 	id          string
 	name        string
-	text        strings.Builder
+	text        strings.Builder // text, or thinking for a thinking block
 	toolInput   strings.Builder
+	signature   string
 }
 
 // newAccumulator creates an empty accumulator.
@@ -56,6 +57,13 @@ func (a *accumulator) appendToolInput(partial string) {
 	}
 }
 
+// setSignature records the signature of the current thinking block.
+func (a *accumulator) setSignature(sig string) {
+	if a.current != nil {
+		a.current.signature = sig
+	}
+}
+
 // finishBlock finalizes the current block and appends it to content.
 // Returns the finalized Content or nil if no block was in progress.
 func (a *accumulator) finishBlock() *ai.Content {
@@ -68,6 +76,9 @@ func (a *accumulator) finishBlock() *ai.Content {
 	switch a.current.contentType {
 	case ai.ContentText:
 		block.Text = a.current.text.String()
+	case ai.ContentThinking:
+		block.Thinking = a.current.text.String()
+		block.Signature = a.current.signature
 	case ai.ContentToolUse:
 		block.ID = a.current.id
 		block.Name = a.current.name
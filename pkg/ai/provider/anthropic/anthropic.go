@@ -11,6 +11,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"slices"
 
 	"github.com/mailru/easyjson"
 
@@ -35,6 +36,11 @@ type Provider struct {
 
 // New creates an Anthropic provider. If apiKey is empty, it reads ANTHROPIC_API_KEY.
 func New(apiKey, baseURL string) *Provider {
+	return NewWithOptions(apiKey, baseURL, ai.HTTPOptions{})
+}
+
+// NewWithOptions creates a provider whose HTTP client uses the given transport options.
+func NewWithOptions(apiKey, baseURL string, opts ai.HTTPOptions) *Provider {
 	if apiKey == "" {
 		apiKey = os.Getenv("ANTHROPIC_API_KEY")
 	}
@@ -50,7 +56,7 @@ func New(apiKey, baseURL string) *Provider {
 	}
 
 	return &Provider{
-		client: httputil.NewClient(baseURL, headers),
+		client: httputil.NewClientWithOptions(baseURL, headers, opts),
 		apiKey: apiKey,
 	}
 }
@@ -107,6 +113,30 @@ func (p *Provider) runStream(
 	processEvents(stream, reader)
 }
 
+// Prewarm implements ai.Prewarmer. The prefix request asks for one token
+// with caching applied, so the system prompt, tools, and conversation are
+// written to Anthropic's prompt cache for the next turn.
+func (p *Provider) Prewarm(ctx context.Context, model *ai.Model, aiCtx *ai.Context, prefix bool) error {
+	if !prefix || aiCtx == nil || len(aiCtx.Messages) == 0 {
+		return p.client.Warm(ctx)
+	}
+	warm := *aiCtx
+	warm.Tools = slices.Clone(aiCtx.Tools) // ApplyPromptCaching marks the last tool
+	ai.ApplyPromptCaching(&warm, ai.ApiAnthropic)
+
+	body := buildRequestBody(model, &warm, &ai.StreamOptions{MaxTokens: 1})
+	body["stream"] = false
+	bodyJSON, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %w", err)
+	}
+	pilog.Debug("http: POST %s%s model=%s (prewarm)", p.client.BaseURL(), messagesPath, model.Name)
+	if err := p.client.PostDiscard(ctx, messagesPath, bodyJSON); err != nil {
+		return fmt.Errorf("prewarming prompt cache: %w", err)
+	}
+	return nil
+}
+
 // handleErrorResponse reads the error body and finishes the stream with an error.
 func handleErrorResponse(stream *ai.EventStream, resp *http.Response) {
 	body, _ := io.ReadAll(resp.Body)
@@ -194,7 +224,7 @@ func handleContentBlockStart(stream *ai.EventStream, acc *accumulator, ev *sse.E
 	return true
 }
 
-// handleContentBlockDelta processes content deltas (text or tool input JSON).
+// handleContentBlockDelta processes content deltas (text, thinking, or tool input JSON).
 func handleContentBlockDelta(stream *ai.EventStream, acc *accumulator, ev *sse.Event) bool {
 	var payload contentBlockDeltaPayload
 	if easyjson.Unmarshal([]byte(ev.Data), &payload) != nil {
@@ -208,6 +238,11 @@ func handleContentBlockDelta(stream *ai.EventStream, acc *accumulator, ev *sse.E
 	case "input_json_delta":
 		acc.appendToolInput(payload.Delta.PartialJSON)
 		stream.Send(ai.StreamEvent{Type: ai.EventToolUseDelta, ToolInput: payload.Delta.PartialJSON})
+	case "thinking_delta":
+		acc.appendText(payload.Delta.Thinking)
+		stream.Send(ai.StreamEvent{Type: ai.EventThinkingDelta, Text: payload.Delta.Thinking})
+	case "signature_delta":
+		acc.setSignature(payload.Delta.Signature)
 	}
 
 	return true
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
 
 	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
@@ -152,6 +153,76 @@ func TestProviderStreamToolUse(t *testing.T) {
 	}
 }
 
+func TestProviderStreamThinking(t *testing.T) {
+	t.Parallel()
+
+	sseResponse := `event: message_start
+data: {"type":"message_start","message":{"id":"msg_think","type":"message","role":"assistant","content":[],"model":"claude-sonnet-4-20250514","stop_reason":null,"usage":{"input_tokens":10,"output_tokens":0}}}
+
+event: content_block_start
+data: {"type":"content_block_start","index":0,"content_block":{"type":"thinking","thinking":""}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"thinking_delta","thinking":"Let me see."}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"signature_delta","signature":"sig-1"}}
+
+event: content_block_stop
+data: {"type":"content_block_stop","index":0}
+
+event: content_block_start
+data: {"type":"content_block_start","index":1,"content_block":{"type":"text","text":""}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":1,"delta":{"type":"text_delta","text":"Done."}}
+
+event: content_block_stop
+data: {"type":"content_block_stop","index":1}
+
+event: message_delta
+data: {"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":9}}
+
+event: message_stop
+data: {"type":"message_stop"}
+
+`
+	var body map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(sseResponse))
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx := &ai.Context{Messages: []ai.Message{ai.NewTextMessage(ai.RoleUser, "Hi")}}
+	opts := &ai.StreamOptions{MaxTokens: 1024, Thinking: true, ThinkingLevel: "low"}
+	stream := New("test-key", srv.URL).Stream(context.Background(), &ai.ModelClaude4Sonnet, ctx, opts)
+
+	var thinking []string
+	for ev := range stream.Events() {
+		switch ev.Type {
+		case ai.EventThinkingDelta:
+			thinking = append(thinking, ev.Text)
+		case ai.EventError:
+			t.Fatalf("unexpected error event: %v", ev.Error)
+		}
+	}
+
+	if got := body["thinking"]; !reflect.DeepEqual(got, map[string]any{"type": "enabled", "budget_tokens": float64(4096)}) {
+		t.Errorf("request thinking = %#v, want a 4096-token budget", got)
+	}
+	if len(thinking) != 1 || thinking[0] != "Let me see." {
+		t.Errorf("thinking deltas = %q", thinking)
+	}
+	result := stream.Result()
+	want := ai.Content{Type: ai.ContentThinking, Thinking: "Let me see.", Signature: "sig-1"}
+	if len(result.Content) != 2 || !reflect.DeepEqual(result.Content[0], want) || result.Content[1].Text != "Done." {
+		t.Errorf("content = %#v, want a signed thinking block then text", result.Content)
+	}
+}
+
 func TestProviderStreamErrorResponse(t *testing.T) {
 	t.Parallel()
 
@@ -195,6 +266,52 @@ func TestProviderStreamErrorResponse(t *testing.T) {
 	}
 }
 
+func TestProviderPrewarm(t *testing.T) {
+	t.Parallel()
+
+	var method string
+	var body map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		if r.Method == http.MethodPost {
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("decoding request body: %v", err)
+			}
+			_, _ = w.Write([]byte(`{"type":"message"}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	provider := New("test-key", srv.URL)
+	var _ ai.Prewarmer = provider
+	tools := []ai.Tool{{Name: "read"}}
+	ctx := &ai.Context{
+		System:   "sys",
+		Messages: []ai.Message{ai.NewTextMessage(ai.RoleUser, "Hi"), ai.NewTextMessage(ai.RoleAssistant, "Hello")},
+		Tools:    tools,
+	}
+
+	if err := provider.Prewarm(context.Background(), &ai.ModelClaude4Sonnet, ctx, false); err != nil {
+		t.Fatalf("Prewarm(connection) error = %v", err)
+	}
+	if method != http.MethodHead {
+		t.Errorf("connection prewarm used %s, want HEAD", method)
+	}
+
+	if err := provider.Prewarm(context.Background(), &ai.ModelClaude4Sonnet, ctx, true); err != nil {
+		t.Fatalf("Prewarm(prefix) error = %v", err)
+	}
+	if body["stream"] != false || body["max_tokens"] != float64(1) {
+		t.Errorf("prefix request stream=%v max_tokens=%v, want a non-streamed one-token call", body["stream"], body["max_tokens"])
+	}
+	if _, ok := body["system"].([]any); !ok {
+		t.Errorf("system = %#v, want a cached block", body["system"])
+	}
+	if tools[0].CacheControl != nil || ctx.SystemCacheControl != nil {
+		t.Error("Prewarm must not modify the caller's context")
+	}
+}
+
 func TestMessageStartPayload_EasyjsonRoundTrip(t *testing.T) {
 	t.Parallel()
 
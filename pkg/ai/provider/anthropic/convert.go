@@ -22,9 +22,14 @@ func convertMessages(msgs []ai.Message) []map[string]any {
 }
 
 // convertContent transforms internal content blocks into Anthropic API format.
+// Thinking without a signature, as from another provider or a summarized
+// session, cannot be sent back and is dropped.
 func convertContent(blocks []ai.Content) []map[string]any {
 	out := make([]map[string]any, 0, len(blocks))
 	for _, b := range blocks {
+		if b.Type == ai.ContentThinking && b.Signature == "" {
+			continue
+		}
 		out = append(out, convertContentBlock(b))
 	}
 	return out
@@ -44,6 +49,8 @@ func convertContentBlock(b ai.Content) map[string]any {
 				"data":       b.Data,
 			},
 		}
+	case ai.ContentThinking:
+		return map[string]any{"type": "thinking", "thinking": b.Thinking, "signature": b.Signature}
 	case ai.ContentToolUse:
 		return map[string]any{
 			"type":  "tool_use",
@@ -93,6 +100,9 @@ func convertTools(tools []ai.Tool) []map[string]any {
 		if t.Parameters != nil {
 			entry["input_schema"] = json.RawMessage(t.Parameters)
 		}
+		if t.CacheControl != nil {
+			entry["cache_control"] = t.CacheControl
+		}
 		out = append(out, entry)
 	}
 	return out
@@ -107,15 +117,31 @@ func buildRequestBody(model *ai.Model, ctx *ai.Context, opts *ai.StreamOptions)
 	}
 
 	if ctx.System != "" {
-		body["system"] = ctx.System
+		if ctx.SystemCacheControl != nil {
+			body["system"] = []map[string]any{{"type": "text", "text": ctx.System, "cache_control": ctx.SystemCacheControl}}
+		} else {
+			body["system"] = ctx.System
+		}
 	}
 
 	if len(ctx.Messages) > 0 {
-		body["messages"] = convertMessages(ctx.Messages)
+		msgs := convertMessages(ctx.Messages)
+		// With caching on, the conversation so far is cached too, so the
+		// next call reads it back instead of reprocessing it.
+		if ctx.SystemCacheControl != nil {
+			blocks := msgs[len(msgs)-1]["content"].([]map[string]any)
+			if n := len(blocks); n > 0 && blocks[n-1]["type"] != "thinking" {
+				blocks[n-1]["cache_control"] = ctx.SystemCacheControl
+			}
+		}
+		body["messages"] = msgs
 	}
 
 	if len(ctx.Tools) > 0 {
 		body["tools"] = convertTools(ctx.Tools)
+		if !model.SupportsParallelToolCalls {
+			body["tool_choice"] = map[string]any{"type": "auto", "disable_parallel_tool_use": true}
+		}
 	}
 
 	applyStreamOptions(body, opts)
@@ -132,10 +158,22 @@ func resolveMaxTokens(model *ai.Model, opts *ai.StreamOptions) int {
 }
 
 // applyStreamOptions applies optional streaming parameters to the request body.
+// Extended thinking takes a token budget, which max_tokens must exceed, and
+// rules out a custom temperature or top_p.
 func applyStreamOptions(body map[string]any, opts *ai.StreamOptions) {
 	if opts == nil {
 		return
 	}
+	if budget := opts.ThinkingBudget(); budget > 0 {
+		body["thinking"] = map[string]any{"type": "enabled", "budget_tokens": budget}
+		if maxTokens, _ := body["max_tokens"].(int); maxTokens <= budget {
+			body["max_tokens"] = budget + maxTokens
+		}
+		if len(opts.StopSequences) > 0 {
+			body["stop_sequences"] = opts.StopSequences
+		}
+		return
+	}
 	if opts.Temperature > 0 {
 		body["temperature"] = opts.Temperature
 	}
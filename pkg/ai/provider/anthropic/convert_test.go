@@ -4,6 +4,7 @@
 package anthropic
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
@@ -103,3 +104,91 @@ func TestConvertContentBlock_ToolResultMultipleImages(t *testing.T) {
 		t.Fatalf("expected 3 content parts; got %d", len(contentArr))
 	}
 }
+
+func TestBuildRequestBody_CacheHints(t *testing.T) {
+	t.Parallel()
+
+	cc := &ai.CacheControl{Type: "ephemeral"}
+	ctx := &ai.Context{
+		System:             "be brief",
+		SystemCacheControl: cc,
+		Messages:           []ai.Message{ai.NewTextMessage(ai.RoleUser, "a"), ai.NewTextMessage(ai.RoleAssistant, "b")},
+		Tools:              []ai.Tool{{Name: "read"}, {Name: "edit", CacheControl: cc}},
+	}
+
+	body := buildRequestBody(&ai.Model{ID: "m"}, ctx, nil)
+
+	system, ok := body["system"].([]map[string]any)
+	if !ok || system[0]["cache_control"] != cc {
+		t.Errorf("system = %#v, want a cached text block", body["system"])
+	}
+	tools := body["tools"].([]map[string]any)
+	if _, ok := tools[0]["cache_control"]; ok || tools[1]["cache_control"] != cc {
+		t.Errorf("tools = %#v, want only the last tool cached", tools)
+	}
+	msgs := body["messages"].([]map[string]any)
+	first := msgs[0]["content"].([]map[string]any)
+	last := msgs[1]["content"].([]map[string]any)
+	if _, ok := first[0]["cache_control"]; ok || last[0]["cache_control"] != cc {
+		t.Errorf("messages = %#v, want a breakpoint on the last block only", msgs)
+	}
+
+	plain := buildRequestBody(&ai.Model{ID: "m"}, &ai.Context{System: "be brief"}, nil)
+	if plain["system"] != "be brief" {
+		t.Errorf("system without caching = %#v, want a plain string", plain["system"])
+	}
+}
+
+func TestBuildRequestBody_Thinking(t *testing.T) {
+	t.Parallel()
+
+	opts := &ai.StreamOptions{MaxTokens: 8000, Temperature: 0.2, Thinking: true, ThinkingLevel: "high"}
+	body := buildRequestBody(&ai.Model{ID: "m"}, &ai.Context{}, opts)
+	if got := body["thinking"]; !reflect.DeepEqual(got, map[string]any{"type": "enabled", "budget_tokens": 24000}) {
+		t.Errorf("thinking = %#v, want a 24000-token budget", got)
+	}
+	if body["max_tokens"] != 32000 {
+		t.Errorf("max_tokens = %v, want room above the budget", body["max_tokens"])
+	}
+	if _, ok := body["temperature"]; ok {
+		t.Error("temperature must not be sent with thinking")
+	}
+
+	opts.Thinking = false
+	body = buildRequestBody(&ai.Model{ID: "m"}, &ai.Context{}, opts)
+	if _, ok := body["thinking"]; ok || body["temperature"] != 0.2 || body["max_tokens"] != 8000 {
+		t.Errorf("body without thinking = %#v", body)
+	}
+}
+
+func TestConvertContent_Thinking(t *testing.T) {
+	t.Parallel()
+
+	got := convertContent([]ai.Content{
+		{Type: ai.ContentThinking, Thinking: "signed", Signature: "sig"},
+		{Type: ai.ContentThinking, Thinking: "from another provider"},
+		{Type: ai.ContentText, Text: "answer"},
+	})
+	want := []map[string]any{
+		{"type": "thinking", "thinking": "signed", "signature": "sig"},
+		{"type": "text", "text": "answer"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("convertContent = %#v, want %#v", got, want)
+	}
+}
+
+func TestBuildRequestBody_ParallelToolUse(t *testing.T) {
+	t.Parallel()
+
+	ctx := &ai.Context{Tools: []ai.Tool{{Name: "read"}}}
+	body := buildRequestBody(&ai.Model{ID: "m"}, ctx, nil)
+	choice, ok := body["tool_choice"].(map[string]any)
+	if !ok || choice["disable_parallel_tool_use"] != true {
+		t.Errorf("tool_choice = %#v, want parallel tool use disabled", body["tool_choice"])
+	}
+	body = buildRequestBody(&ai.Model{ID: "m", SupportsParallelToolCalls: true}, ctx, nil)
+	if _, ok := body["tool_choice"]; ok {
+		t.Error("tool_choice sent for a model that supports parallel calls")
+	}
+}
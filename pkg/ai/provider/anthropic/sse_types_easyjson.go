@@ -704,6 +704,8 @@ func easyjson5f687493Decode5(in *jlexer.Lexer, out *struct {
 	Type        string `json:"type"`
 	Text        string `json:"text"`
 	PartialJSON string `json:"partial_json"`
+	Thinking    string `json:"thinking"`
+	Signature   string `json:"signature"`
 }) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
@@ -729,6 +731,10 @@ func easyjson5f687493Decode5(in *jlexer.Lexer, out *struct {
 			out.Text = string(in.String())
 		case "partial_json":
 			out.PartialJSON = string(in.String())
+		case "thinking":
+			out.Thinking = string(in.String())
+		case "signature":
+			out.Signature = string(in.String())
 		default:
 			in.SkipRecursive()
 		}
@@ -743,6 +749,8 @@ func easyjson5f687493Encode5(out *jwriter.Writer, in struct {
 	Type        string `json:"type"`
 	Text        string `json:"text"`
 	PartialJSON string `json:"partial_json"`
+	Thinking    string `json:"thinking"`
+	Signature   string `json:"signature"`
 }) {
 	out.RawByte('{')
 	first := true
@@ -762,5 +770,15 @@ func easyjson5f687493Encode5(out *jwriter.Writer, in struct {
 		out.RawString(prefix)
 		out.String(string(in.PartialJSON))
 	}
+	{
+		const prefix string = ",\"thinking\":"
+		out.RawString(prefix)
+		out.String(string(in.Thinking))
+	}
+	{
+		const prefix string = ",\"signature\":"
+		out.RawString(prefix)
+		out.String(string(in.Signature))
+	}
 	out.RawByte('}')
 }
@@ -49,7 +49,17 @@ type FunctionDecl struct {
 
 // GenerationConfig holds generation parameters for the Gemini API.
 type GenerationConfig struct {
-	MaxOutputTokens int     `json:"maxOutputTokens,omitempty"`
-	Temperature     float64 `json:"temperature,omitempty"`
-	TopP            float64 `json:"topP,omitempty"`
+	MaxOutputTokens int             `json:"maxOutputTokens,omitempty"`
+	Temperature     float64         `json:"temperature,omitempty"`
+	TopP            float64         `json:"topP,omitempty"`
+	ThinkingConfig  *ThinkingConfig `json:"thinkingConfig,omitempty"`
+}
+
+// MaxThinkingBudget is the largest thinking budget every Gemini 2.5 model
+// accepts; Flash stops at 24576 tokens.
+const MaxThinkingBudget = 24576
+
+// ThinkingConfig sets how many tokens a Gemini 2.5 model may think with.
+type ThinkingConfig struct {
+	ThinkingBudget int `json:"thinkingBudget"`
 }
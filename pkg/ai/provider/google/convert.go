@@ -98,6 +98,9 @@ func buildGeminiRequestBody(ctx *ai.Context, opts *ai.StreamOptions) geminiReque
 			Temperature:     opts.Temperature,
 			TopP:            opts.TopP,
 		}
+		if budget := opts.ThinkingBudget(); budget > 0 {
+			req.GenerationConfig.ThinkingConfig = &gemini.ThinkingConfig{ThinkingBudget: min(budget, gemini.MaxThinkingBudget)}
+		}
 	}
 
 	return req
@@ -94,3 +94,17 @@ func TestBuildGeminiRequestBody_ToolResultWithoutImages(t *testing.T) {
 		t.Error("expected no InlineData for text-only result")
 	}
 }
+
+func TestBuildGeminiRequestBody_ThinkingBudget(t *testing.T) {
+	t.Parallel()
+
+	for level, want := range map[string]int{"low": 4096, "xhigh": 24576} {
+		req := buildGeminiRequestBody(&ai.Context{}, &ai.StreamOptions{Thinking: true, ThinkingLevel: level})
+		if tc := req.GenerationConfig.ThinkingConfig; tc == nil || tc.ThinkingBudget != want {
+			t.Errorf("%s: thinkingConfig = %+v, want a budget of %d", level, tc, want)
+		}
+	}
+	if req := buildGeminiRequestBody(&ai.Context{}, &ai.StreamOptions{}); req.GenerationConfig.ThinkingConfig != nil {
+		t.Errorf("thinkingConfig without thinking = %+v, want none", req.GenerationConfig.ThinkingConfig)
+	}
+}
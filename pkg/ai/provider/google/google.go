@@ -26,6 +26,11 @@ type Provider struct {
 
 // New creates a Google AI provider.
 func New(apiKey, baseURL string) *Provider {
+	return NewWithOptions(apiKey, baseURL, ai.HTTPOptions{})
+}
+
+// NewWithOptions creates a Google AI provider whose HTTP client uses the given transport options.
+func NewWithOptions(apiKey, baseURL string, opts ai.HTTPOptions) *Provider {
 	if apiKey == "" {
 		apiKey = os.Getenv("GOOGLE_API_KEY")
 	}
@@ -35,7 +40,7 @@ func New(apiKey, baseURL string) *Provider {
 	return &Provider{
 		baseURL: baseURL,
 		apiKey:  apiKey,
-		client:  &http.Client{},
+		client:  opts.NewClient(),
 	}
 }
 
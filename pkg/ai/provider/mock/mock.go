@@ -0,0 +1,226 @@
+// ABOUTME: Mock provider for local development: echoes the prompt or plays a scripted reply, no API key needed
+// ABOUTME: Latency, chunk pacing, and token counts are configurable; a script file simulates tool calls
+
+package mock
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+const defaultStreamBufferSize = 64
+
+// Step is one scripted reply: text and, optionally, tool calls.
+type Step struct {
+	Text      string     `yaml:"text"`
+	ToolCalls []ToolCall `yaml:"tool_calls"`
+}
+
+// ToolCall is a scripted tool call.
+type ToolCall struct {
+	Name string         `yaml:"name"`
+	Args map[string]any `yaml:"args"`
+}
+
+// LoadScript reads a YAML or JSON list of steps.
+func LoadScript(path string) ([]Step, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading mock script: %w", err)
+	}
+	var steps []Step
+	if err := yaml.Unmarshal(data, &steps); err != nil {
+		return nil, fmt.Errorf("parsing mock script %s: %w", path, err)
+	}
+	for i, s := range steps {
+		for _, tc := range s.ToolCalls {
+			if tc.Name == "" {
+				return nil, fmt.Errorf("mock script %s: step %d has a tool call without a name", path, i+1)
+			}
+		}
+	}
+	return steps, nil
+}
+
+// Options configures the mock provider.
+type Options struct {
+	// Script replaces echoing: the reply to a call is the step whose index
+	// is the number of assistant messages so far. Calls past the end echo.
+	Script []Step
+	// Latency is the delay before the first event.
+	Latency time.Duration
+	// ChunkDelay is the delay between streamed words.
+	ChunkDelay time.Duration
+	// InputTokens and OutputTokens fix the reported usage; zero estimates
+	// it at four characters per token.
+	InputTokens  int
+	OutputTokens int
+}
+
+// Provider answers calls deterministically without a network.
+type Provider struct {
+	opts Options
+}
+
+// New creates a mock provider.
+func New(opts Options) *Provider {
+	return &Provider{opts: opts}
+}
+
+// Api returns the provider identifier.
+func (p *Provider) Api() ai.Api {
+	return ai.ApiMock
+}
+
+// Stream replies to the call: the scripted step for this point of the
+// conversation, or an echo of the last user message.
+func (p *Provider) Stream(ctx context.Context, model *ai.Model, llmCtx *ai.Context, opts *ai.StreamOptions) *ai.EventStream {
+	bufSize := defaultStreamBufferSize
+	if opts != nil && opts.StreamBufferSize > 0 {
+		bufSize = opts.StreamBufferSize
+	}
+	stream := ai.NewEventStream(bufSize)
+	go p.run(ctx, stream, model, llmCtx)
+	return stream
+}
+
+func (p *Provider) run(ctx context.Context, stream *ai.EventStream, model *ai.Model, llmCtx *ai.Context) {
+	if llmCtx == nil {
+		llmCtx = &ai.Context{}
+	}
+	step := p.reply(llmCtx)
+	if !p.sleep(ctx, p.opts.Latency) {
+		stream.FinishWithError(ctx.Err())
+		return
+	}
+	stream.Send(ai.StreamEvent{Type: ai.EventMessageStart})
+
+	msg := &ai.AssistantMessage{StopReason: ai.StopEndTurn}
+	if model != nil {
+		msg.Model = model.ID
+	}
+	if step.Text != "" {
+		for i, word := range strings.SplitAfter(step.Text, " ") {
+			if i > 0 && !p.sleep(ctx, p.opts.ChunkDelay) {
+				stream.FinishWithError(ctx.Err())
+				return
+			}
+			stream.Send(ai.StreamEvent{Type: ai.EventContentDelta, Text: word})
+		}
+		stream.Send(ai.StreamEvent{Type: ai.EventContentDone})
+		msg.Content = append(msg.Content, ai.Content{Type: ai.ContentText, Text: step.Text})
+	}
+
+	for i, tc := range step.ToolCalls {
+		input, err := json.Marshal(tc.Args)
+		if err != nil {
+			stream.FinishWithError(fmt.Errorf("mock: tool call %s: %w", tc.Name, err))
+			return
+		}
+		if tc.Args == nil {
+			input = []byte("{}")
+		}
+		id := fmt.Sprintf("mock_%d_%d", countAssistant(llmCtx.Messages), i)
+		stream.Send(ai.StreamEvent{Type: ai.EventToolUseStart, ToolID: id, ToolName: tc.Name})
+		stream.Send(ai.StreamEvent{Type: ai.EventToolUseDelta, ToolID: id, ToolInput: string(input)})
+		stream.Send(ai.StreamEvent{Type: ai.EventToolUseDone, ToolID: id})
+		msg.Content = append(msg.Content, ai.Content{Type: ai.ContentToolUse, ID: id, Name: tc.Name, Input: input})
+		msg.StopReason = ai.StopToolUse
+	}
+
+	msg.Usage = p.usage(llmCtx, step)
+	stream.Send(ai.StreamEvent{Type: ai.EventMessageDelta, Usage: &msg.Usage, StopReason: msg.StopReason})
+	stream.Send(ai.StreamEvent{Type: ai.EventMessageDone})
+	stream.Finish(msg)
+}
+
+// reply picks the scripted step for the conversation so far, or echoes.
+func (p *Provider) reply(llmCtx *ai.Context) Step {
+	if n := countAssistant(llmCtx.Messages); n < len(p.opts.Script) {
+		return p.opts.Script[n]
+	}
+	return Step{Text: echo(llmCtx.Messages)}
+}
+
+// echo answers the last message: the user's text, or a note on the tool
+// results it carries.
+func echo(msgs []ai.Message) string {
+	if len(msgs) == 0 {
+		return "echo: (empty conversation)"
+	}
+	var text []string
+	var results int
+	for _, c := range msgs[len(msgs)-1].Content {
+		switch c.Type {
+		case ai.ContentText:
+			text = append(text, c.Text)
+		case ai.ContentToolResult:
+			results++
+		}
+	}
+	if len(text) == 0 && results > 0 {
+		return fmt.Sprintf("echo: received %d tool result(s)", results)
+	}
+	return "echo: " + strings.Join(text, "\n")
+}
+
+// usage returns the configured token counts or estimates them.
+func (p *Provider) usage(llmCtx *ai.Context, step Step) ai.Usage {
+	u := ai.Usage{InputTokens: p.opts.InputTokens, OutputTokens: p.opts.OutputTokens}
+	if u.InputTokens == 0 {
+		n := len(llmCtx.System)
+		for _, m := range llmCtx.Messages {
+			for _, c := range m.Content {
+				n += len(c.Text) + len(c.ResultText) + len(c.Input)
+			}
+		}
+		u.InputTokens = estimate(n)
+	}
+	if u.OutputTokens == 0 {
+		n := len(step.Text)
+		for _, tc := range step.ToolCalls {
+			n += len(tc.Name) + len(fmt.Sprint(tc.Args))
+		}
+		u.OutputTokens = estimate(n)
+	}
+	return u
+}
+
+// estimate converts a character count to tokens, at least one.
+func estimate(chars int) int {
+	return max(1, (chars+3)/4)
+}
+
+// countAssistant returns the number of assistant messages in msgs.
+func countAssistant(msgs []ai.Message) int {
+	n := 0
+	for _, m := range msgs {
+		if m.Role == ai.RoleAssistant {
+			n++
+		}
+	}
+	return n
+}
+
+// sleep waits d, reporting false when ctx ends first.
+func (p *Provider) sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
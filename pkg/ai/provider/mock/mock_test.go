@@ -0,0 +1,140 @@
+// ABOUTME: Tests for the mock provider: echo replies, scripted tool calls, usage, latency, and cancellation
+// ABOUTME: Streams are drained in-process; scripts are written to tempdirs
+
+package mock
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mauromedda/pi-coding-agent-go/pkg/ai"
+)
+
+// drain collects the streamed text and the final message.
+func drain(t *testing.T, s *ai.EventStream) (string, *ai.AssistantMessage, error) {
+	t.Helper()
+	var b strings.Builder
+	var err error
+	for ev := range s.Events() {
+		switch ev.Type {
+		case ai.EventContentDelta:
+			b.WriteString(ev.Text)
+		case ai.EventError:
+			err = ev.Error
+		}
+	}
+	return b.String(), s.Result(), err
+}
+
+func userCtx(text string) *ai.Context {
+	return &ai.Context{Messages: []ai.Message{ai.NewTextMessage(ai.RoleUser, text)}}
+}
+
+func TestProvider_Echo(t *testing.T) {
+	t.Parallel()
+	p := New(Options{})
+	if p.Api() != ai.ApiMock {
+		t.Errorf("Api = %q; want mock", p.Api())
+	}
+
+	text, msg, err := drain(t, p.Stream(context.Background(), &ai.ModelMock, userCtx("hello there"), nil))
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	if text != "echo: hello there" {
+		t.Errorf("text = %q; want %q", text, "echo: hello there")
+	}
+	if msg.StopReason != ai.StopEndTurn || msg.Model != "mock" {
+		t.Errorf("msg = %+v", msg)
+	}
+	if msg.Usage.InputTokens != 3 || msg.Usage.OutputTokens != 5 {
+		t.Errorf("usage = %+v; want 3 in, 5 out", msg.Usage)
+	}
+}
+
+func TestProvider_EchoToolResults(t *testing.T) {
+	t.Parallel()
+	llmCtx := &ai.Context{Messages: []ai.Message{{
+		Role:    ai.RoleUser,
+		Content: []ai.Content{{Type: ai.ContentToolResult, ID: "t1", ResultText: "ok"}},
+	}}}
+	text, _, _ := drain(t, New(Options{}).Stream(context.Background(), nil, llmCtx, nil))
+	if text != "echo: received 1 tool result(s)" {
+		t.Errorf("text = %q", text)
+	}
+}
+
+func TestProvider_Script(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "script.yaml")
+	script := "- text: Reading it.\n  tool_calls:\n    - name: read\n      args: {path: main.go}\n- text: Done.\n"
+	if err := os.WriteFile(path, []byte(script), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	steps, err := LoadScript(path)
+	if err != nil {
+		t.Fatalf("LoadScript: %v", err)
+	}
+	p := New(Options{Script: steps, InputTokens: 100, OutputTokens: 7})
+
+	llmCtx := userCtx("go")
+	text, msg, err := drain(t, p.Stream(context.Background(), nil, llmCtx, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "Reading it." || msg.StopReason != ai.StopToolUse {
+		t.Errorf("first reply = %q, %s", text, msg.StopReason)
+	}
+	if len(msg.Content) != 2 || msg.Content[1].Name != "read" {
+		t.Fatalf("content = %+v; want text and a read call", msg.Content)
+	}
+	var args map[string]string
+	if err := json.Unmarshal(msg.Content[1].Input, &args); err != nil || args["path"] != "main.go" {
+		t.Errorf("input = %s (%v)", msg.Content[1].Input, err)
+	}
+	if msg.Usage != (ai.Usage{InputTokens: 100, OutputTokens: 7}) {
+		t.Errorf("usage = %+v; want the configured counts", msg.Usage)
+	}
+
+	// The next step follows the first assistant message; past the end, echo.
+	llmCtx.Messages = append(llmCtx.Messages, ai.Message{Role: ai.RoleAssistant, Content: msg.Content})
+	if text, _, _ := drain(t, p.Stream(context.Background(), nil, llmCtx, nil)); text != "Done." {
+		t.Errorf("second reply = %q; want Done.", text)
+	}
+	llmCtx.Messages = append(llmCtx.Messages, ai.NewTextMessage(ai.RoleAssistant, "Done."), ai.NewTextMessage(ai.RoleUser, "more"))
+	if text, _, _ := drain(t, p.Stream(context.Background(), nil, llmCtx, nil)); text != "echo: more" {
+		t.Errorf("reply past the script = %q; want an echo", text)
+	}
+}
+
+func TestLoadScript_Invalid(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "script.yaml")
+	if err := os.WriteFile(path, []byte("- tool_calls:\n    - args: {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadScript(path); err == nil {
+		t.Error("expected an error for an unnamed tool call")
+	}
+}
+
+func TestProvider_LatencyAndCancel(t *testing.T) {
+	t.Parallel()
+	p := New(Options{Latency: 50 * time.Millisecond, ChunkDelay: 10 * time.Millisecond})
+	start := time.Now()
+	drain(t, p.Stream(context.Background(), nil, userCtx("a b c"), nil))
+	if d := time.Since(start); d < 70*time.Millisecond {
+		t.Errorf("stream took %s; want latency plus chunk delays", d)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, msg, err := drain(t, p.Stream(ctx, nil, userCtx("x"), nil)); err == nil || msg != nil {
+		t.Errorf("cancelled stream = %+v, %v; want an error", msg, err)
+	}
+}
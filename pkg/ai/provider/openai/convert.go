@@ -62,13 +62,19 @@ func buildRequestBody(model *ai.Model, ctx *ai.Context, opts *ai.StreamOptions)
 	// Convert tools
 	if len(ctx.Tools) > 0 {
 		body["tools"] = convertTools(ctx.Tools)
+		if !model.SupportsParallelToolCalls {
+			body["parallel_tool_calls"] = false
+		}
 	}
 
 	if opts != nil {
 		if opts.MaxTokens > 0 {
 			body["max_tokens"] = opts.MaxTokens
 		}
-		if opts.Temperature > 0 {
+		// Reasoning models take an effort instead of a temperature
+		if effort := opts.ReasoningEffort(); effort != "" {
+			body["reasoning_effort"] = effort
+		} else if opts.Temperature > 0 {
 			body["temperature"] = opts.Temperature
 		}
 	}
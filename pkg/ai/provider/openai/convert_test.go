@@ -113,3 +113,40 @@ func TestConvertMessages_ToolResultWithoutImages(t *testing.T) {
 		t.Errorf("expected 'file content'; got %q", content)
 	}
 }
+
+func TestBuildRequestBody_ReasoningEffort(t *testing.T) {
+	t.Parallel()
+
+	opts := &ai.StreamOptions{Temperature: 0.5, Thinking: true, ThinkingLevel: "xhigh"}
+	body := buildRequestBody(&ai.Model{ID: "o3"}, &ai.Context{}, opts)
+	if body["reasoning_effort"] != "high" {
+		t.Errorf("reasoning_effort = %v, want high", body["reasoning_effort"])
+	}
+	if _, ok := body["temperature"]; ok {
+		t.Error("temperature must not be sent with a reasoning effort")
+	}
+
+	opts.Thinking = false
+	body = buildRequestBody(&ai.Model{ID: "gpt-4o"}, &ai.Context{}, opts)
+	if _, ok := body["reasoning_effort"]; ok || body["temperature"] != 0.5 {
+		t.Errorf("body without thinking = %#v", body)
+	}
+}
+
+func TestBuildRequestBody_ParallelToolCalls(t *testing.T) {
+	t.Parallel()
+
+	ctx := &ai.Context{Tools: []ai.Tool{{Name: "read"}}}
+	body := buildRequestBody(&ai.Model{ID: "qwen"}, ctx, nil)
+	if body["parallel_tool_calls"] != false {
+		t.Errorf("parallel_tool_calls = %v, want false", body["parallel_tool_calls"])
+	}
+	body = buildRequestBody(&ai.Model{ID: "gpt-4o", SupportsParallelToolCalls: true}, ctx, nil)
+	if _, ok := body["parallel_tool_calls"]; ok {
+		t.Error("parallel_tool_calls sent for a model that supports parallel calls")
+	}
+	body = buildRequestBody(&ai.Model{ID: "qwen"}, &ai.Context{}, nil)
+	if _, ok := body["parallel_tool_calls"]; ok {
+		t.Error("parallel_tool_calls sent without tools")
+	}
+}
@@ -32,6 +32,11 @@ type Provider struct {
 
 // New creates an OpenAI provider.
 func New(apiKey, baseURL string) *Provider {
+	return NewWithOptions(apiKey, baseURL, ai.HTTPOptions{})
+}
+
+// NewWithOptions creates a provider whose HTTP client uses the given transport options.
+func NewWithOptions(apiKey, baseURL string, opts ai.HTTPOptions) *Provider {
 	if apiKey == "" {
 		apiKey = os.Getenv("OPENAI_API_KEY")
 	}
@@ -46,7 +51,7 @@ func New(apiKey, baseURL string) *Provider {
 	}
 
 	return &Provider{
-		client: httputil.NewClient(baseURL, headers),
+		client: httputil.NewClientWithOptions(baseURL, headers, opts),
 	}
 }
 
@@ -98,6 +103,27 @@ func (p *Provider) doStream(ctx context.Context, model *ai.Model, llmCtx *ai.Con
 	return p.processSSE(reader, stream)
 }
 
+// Prewarm implements ai.Prewarmer. OpenAI-compatible servers cache prompt
+// prefixes automatically, so the prefix request is the next turn's context
+// capped at one token.
+func (p *Provider) Prewarm(ctx context.Context, model *ai.Model, llmCtx *ai.Context, prefix bool) error {
+	if !prefix || llmCtx == nil || len(llmCtx.Messages) == 0 {
+		return p.client.Warm(ctx)
+	}
+	body := buildRequestBody(model, llmCtx, &ai.StreamOptions{MaxTokens: 1})
+	body["stream"] = false
+	delete(body, "stream_options")
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("marshaling request: %w", err)
+	}
+	pilog.Debug("http: POST %s%s model=%s (prewarm)", p.client.BaseURL(), chatCompletionPath, model.Name)
+	if err := p.client.PostDiscard(ctx, chatCompletionPath, bodyBytes); err != nil {
+		return fmt.Errorf("prewarming prompt prefix: %w", err)
+	}
+	return nil
+}
+
 func (p *Provider) processSSE(reader *sse.Reader, stream *ai.EventStream) error {
 	var result ai.AssistantMessage
 	var toolCalls []toolCallAccumulator
@@ -212,6 +212,40 @@ func TestProviderStreamErrorResponse(t *testing.T) {
 	}
 }
 
+func TestProviderPrewarm(t *testing.T) {
+	t.Parallel()
+
+	var methods []string
+	var body map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		if r.Method == http.MethodPost {
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("decoding request body: %v", err)
+			}
+			_, _ = w.Write([]byte(`{"choices":[]}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	provider := New("test-key", srv.URL)
+	var _ ai.Prewarmer = provider
+	ctx := &ai.Context{Messages: []ai.Message{ai.NewTextMessage(ai.RoleUser, "Hi")}}
+
+	if err := provider.Prewarm(context.Background(), &ai.ModelGPT4o, &ai.Context{}, true); err != nil {
+		t.Fatalf("Prewarm(empty) error = %v", err)
+	}
+	if err := provider.Prewarm(context.Background(), &ai.ModelGPT4o, ctx, true); err != nil {
+		t.Fatalf("Prewarm(prefix) error = %v", err)
+	}
+	if len(methods) != 2 || methods[0] != http.MethodHead || methods[1] != http.MethodPost {
+		t.Errorf("methods = %v, want HEAD for an empty context then POST", methods)
+	}
+	if body["stream"] != false || body["max_tokens"] != float64(1) || body["stream_options"] != nil {
+		t.Errorf("prefix body = %v, want a non-streamed one-token call", body)
+	}
+}
+
 func TestConvertMessages(t *testing.T) {
 	t.Parallel()
 
@@ -85,6 +85,9 @@ func buildVertexRequestBody(ctx *ai.Context, opts *ai.StreamOptions) vertexReque
 			MaxOutputTokens: opts.MaxTokens,
 			Temperature:     opts.Temperature,
 		}
+		if budget := opts.ThinkingBudget(); budget > 0 {
+			req.GenerationConfig.ThinkingConfig = &gemini.ThinkingConfig{ThinkingBudget: min(budget, gemini.MaxThinkingBudget)}
+		}
 	}
 
 	return req
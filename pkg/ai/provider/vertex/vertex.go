@@ -26,6 +26,11 @@ type Provider struct {
 
 // New creates a Vertex AI provider.
 func New(projectID, location, baseURL string) *Provider {
+	return NewWithOptions(projectID, location, baseURL, ai.HTTPOptions{})
+}
+
+// NewWithOptions creates a Vertex AI provider whose HTTP client uses the given transport options.
+func NewWithOptions(projectID, location, baseURL string, opts ai.HTTPOptions) *Provider {
 	if projectID == "" {
 		projectID = os.Getenv("VERTEX_PROJECT_ID")
 	}
@@ -42,7 +47,7 @@ func New(projectID, location, baseURL string) *Provider {
 		projectID: projectID,
 		location:  location,
 		baseURL:   baseURL,
-		client:    &http.Client{},
+		client:    opts.NewClient(),
 	}
 }
 
@@ -52,3 +52,15 @@ func HasProvider(api Api) bool {
 	registryMu.RUnlock()
 	return ok
 }
+
+// WrapProviders replaces every registered factory with one whose providers
+// are passed through wrap. Providers registered afterwards are not wrapped.
+func WrapProviders(wrap func(ApiProvider) ApiProvider) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for api, factory := range registry {
+		registry[api] = func(baseURL string) ApiProvider {
+			return wrap(factory(baseURL))
+		}
+	}
+}
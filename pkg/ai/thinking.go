@@ -0,0 +1,60 @@
+// ABOUTME: Thinking levels mapped to provider parameters: token budgets and OpenAI reasoning effort
+// ABOUTME: Levels are named as in settings (minimal, low, medium, high, xhigh); an unknown name means medium
+
+package ai
+
+// thinkingBudgets are the thinking token budgets of the levels. Anthropic
+// accepts no less than 1024.
+var thinkingBudgets = map[string]int{
+	"minimal": 1024,
+	"low":     4096,
+	"medium":  10000,
+	"high":    24000,
+	"xhigh":   32000,
+}
+
+// ThinkingBudget returns the thinking token budget for a level: zero for
+// "off", and medium's for an empty or unknown name.
+func ThinkingBudget(level string) int {
+	if level == "off" {
+		return 0
+	}
+	if b, ok := thinkingBudgets[level]; ok {
+		return b
+	}
+	return thinkingBudgets["medium"]
+}
+
+// ReasoningEffort returns OpenAI's reasoning_effort for a level: "" for
+// "off", "high" for xhigh, which OpenAI does not have, and "medium" for an
+// empty or unknown name.
+func ReasoningEffort(level string) string {
+	switch level {
+	case "off":
+		return ""
+	case "minimal", "low", "medium", "high":
+		return level
+	case "xhigh":
+		return "high"
+	default:
+		return "medium"
+	}
+}
+
+// ThinkingBudget returns the thinking token budget opts ask for; zero when
+// thinking is off.
+func (o *StreamOptions) ThinkingBudget() int {
+	if o == nil || !o.Thinking {
+		return 0
+	}
+	return ThinkingBudget(o.ThinkingLevel)
+}
+
+// ReasoningEffort returns the reasoning effort opts ask for; "" when
+// thinking is off.
+func (o *StreamOptions) ReasoningEffort() string {
+	if o == nil || !o.Thinking {
+		return ""
+	}
+	return ReasoningEffort(o.ThinkingLevel)
+}
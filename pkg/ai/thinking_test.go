@@ -0,0 +1,40 @@
+// ABOUTME: Tests for thinking level mapping to token budgets and OpenAI reasoning effort
+// ABOUTME: Covers off, known and unknown level names, and StreamOptions with thinking disabled
+
+package ai
+
+import "testing"
+
+func TestThinkingLevels(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		level  string
+		budget int
+		effort string
+	}{
+		{"off", 0, ""},
+		{"minimal", 1024, "minimal"},
+		{"low", 4096, "low"},
+		{"medium", 10000, "medium"},
+		{"high", 24000, "high"},
+		{"xhigh", 32000, "high"},
+		{"", 10000, "medium"},
+		{"bogus", 10000, "medium"},
+	} {
+		if got := ThinkingBudget(tc.level); got != tc.budget {
+			t.Errorf("ThinkingBudget(%q) = %d, want %d", tc.level, got, tc.budget)
+		}
+		if got := ReasoningEffort(tc.level); got != tc.effort {
+			t.Errorf("ReasoningEffort(%q) = %q, want %q", tc.level, got, tc.effort)
+		}
+	}
+
+	opts := &StreamOptions{ThinkingLevel: "high"}
+	if opts.ThinkingBudget() != 0 || opts.ReasoningEffort() != "" {
+		t.Error("options with Thinking off should ask for no thinking")
+	}
+	if (*StreamOptions)(nil).ThinkingBudget() != 0 {
+		t.Error("nil options should ask for no thinking")
+	}
+}
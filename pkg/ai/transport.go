@@ -0,0 +1,195 @@
+// ABOUTME: Provider HTTP transport options: timeouts, keepalive, HTTP/2, proxy, and custom CA roots
+// ABOUTME: Builds http.Clients whose streamed bodies fail fast when the server goes silent
+
+package ai
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// Default transport limits, used when the matching HTTPOptions field is zero.
+const (
+	DefaultConnectTimeout        = 30 * time.Second
+	DefaultTLSHandshakeTimeout   = 10 * time.Second
+	DefaultResponseHeaderTimeout = 30 * time.Second
+	DefaultRequestTimeout        = 5 * time.Minute
+	DefaultKeepAlive             = 30 * time.Second
+	DefaultIdleConnTimeout       = 90 * time.Second
+	DefaultHTTP2PingTimeout      = 15 * time.Second
+)
+
+// ErrStreamIdle is returned by a response body read that exceeded IdleReadTimeout.
+var ErrStreamIdle = errors.New("stream idle timeout")
+
+// HTTPOptions tunes the HTTP client used by a provider. Zero durations select
+// the defaults above; negative durations disable the limit entirely.
+type HTTPOptions struct {
+	ConnectTimeout        time.Duration // TCP dial
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration // request sent -> status line (time to first token on most backends)
+	IdleReadTimeout       time.Duration // max silence between body reads; off by default
+	RequestTimeout        time.Duration // whole request including the streamed body
+	KeepAlive             time.Duration // TCP keepalive probe interval
+	IdleConnTimeout       time.Duration // how long pooled connections stay open
+	DisableHTTP2          bool
+	HTTP2PingInterval     time.Duration  // PING silent HTTP/2 connections; off by default
+	HTTP2PingTimeout      time.Duration  // close the connection when a PING goes unanswered
+	Proxy                 string         // proxy URL for every request; empty uses HTTPS_PROXY/HTTP_PROXY
+	RootCAs               *x509.CertPool // trusted roots; nil uses the system pool
+	InsecureSkipVerify    bool           // skip certificate verification; for debugging only
+	Dump                  *WireDump      // write each request and response to a file; nil disables
+}
+
+// limit resolves a configured duration: zero means def, negative means none.
+func limit(v, def time.Duration) time.Duration {
+	switch {
+	case v == 0:
+		return def
+	case v < 0:
+		return 0
+	default:
+		return v
+	}
+}
+
+// proxyFunc returns the transport's proxy selector. A configured proxy
+// still honors NO_PROXY, and loopback hosts always go direct.
+func (o HTTPOptions) proxyFunc() func(*http.Request) (*url.URL, error) {
+	if o.Proxy == "" {
+		return http.ProxyFromEnvironment
+	}
+	noProxy := os.Getenv("NO_PROXY")
+	if noProxy == "" {
+		noProxy = os.Getenv("no_proxy")
+	}
+	proxy := (&httpproxy.Config{HTTPProxy: o.Proxy, HTTPSProxy: o.Proxy, NoProxy: noProxy}).ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxy(req.URL)
+	}
+}
+
+// NewTransport builds an http.Transport honoring the options. Without a
+// configured Proxy, proxy settings come from the environment (HTTP_PROXY,
+// HTTPS_PROXY, NO_PROXY).
+func (o HTTPOptions) NewTransport() *http.Transport {
+	keepAlive := limit(o.KeepAlive, DefaultKeepAlive)
+	if o.KeepAlive < 0 {
+		keepAlive = -1 // net.Dialer treats negative as disabled, zero as its own default
+	}
+	t := &http.Transport{
+		Proxy: o.proxyFunc(),
+		DialContext: (&net.Dialer{
+			Timeout:   limit(o.ConnectTimeout, DefaultConnectTimeout),
+			KeepAlive: keepAlive,
+		}).DialContext,
+		TLSClientConfig: &tls.Config{
+			MinVersion:         tls.VersionTLS12,
+			RootCAs:            o.RootCAs,
+			InsecureSkipVerify: o.InsecureSkipVerify,
+		},
+		TLSHandshakeTimeout:   limit(o.TLSHandshakeTimeout, DefaultTLSHandshakeTimeout),
+		ResponseHeaderTimeout: limit(o.ResponseHeaderTimeout, DefaultResponseHeaderTimeout),
+		MaxIdleConns:          100,
+		IdleConnTimeout:       limit(o.IdleConnTimeout, DefaultIdleConnTimeout),
+		ForceAttemptHTTP2:     !o.DisableHTTP2,
+		HTTP2: &http.HTTP2Config{
+			SendPingTimeout: limit(o.HTTP2PingInterval, 0),
+			PingTimeout:     limit(o.HTTP2PingTimeout, DefaultHTTP2PingTimeout),
+		},
+	}
+	if o.DisableHTTP2 {
+		var p http.Protocols
+		p.SetHTTP1(true)
+		t.Protocols = &p
+	}
+	return t
+}
+
+// NewClient builds an http.Client from the options. When IdleReadTimeout is
+// set, response bodies abort with ErrStreamIdle once the server stays silent
+// for that long, so a stalled stream fails the turn instead of hanging it.
+// With Dump set, every exchange is also written to a dump file.
+func (o HTTPOptions) NewClient() *http.Client {
+	var rt http.RoundTripper = o.NewTransport()
+	if idle := limit(o.IdleReadTimeout, 0); idle > 0 {
+		rt = &idleReadTransport{base: rt, idle: idle}
+	}
+	if o.Dump != nil {
+		rt = o.Dump.Wrap(rt)
+	}
+	return &http.Client{
+		Timeout:   limit(o.RequestTimeout, DefaultRequestTimeout),
+		Transport: rt,
+	}
+}
+
+// idleReadTransport wraps response bodies in an inactivity watchdog.
+type idleReadTransport struct {
+	base http.RoundTripper
+	idle time.Duration
+}
+
+func (t *idleReadTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithCancelCause(req.Context())
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel(nil)
+		return nil, err
+	}
+	body := &idleReadBody{rc: resp.Body, ctx: ctx, cancel: cancel, idle: t.idle}
+	body.timer = time.AfterFunc(t.idle, body.expire)
+	resp.Body = body
+	return resp, nil
+}
+
+// idleReadBody cancels the request when no Read completes within idle.
+type idleReadBody struct {
+	rc     io.ReadCloser
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	idle   time.Duration
+
+	mu    sync.Mutex
+	timer *time.Timer
+}
+
+func (b *idleReadBody) expire() {
+	b.cancel(fmt.Errorf("%w: no data for %s", ErrStreamIdle, b.idle))
+}
+
+func (b *idleReadBody) Read(p []byte) (int, error) {
+	n, err := b.rc.Read(p)
+	if n > 0 {
+		b.mu.Lock()
+		b.timer.Reset(b.idle)
+		b.mu.Unlock()
+	}
+	if err != nil && err != io.EOF {
+		if cause := context.Cause(b.ctx); errors.Is(cause, ErrStreamIdle) {
+			return n, cause
+		}
+	}
+	return n, err
+}
+
+func (b *idleReadBody) Close() error {
+	b.mu.Lock()
+	b.timer.Stop()
+	b.mu.Unlock()
+	err := b.rc.Close()
+	b.cancel(nil)
+	return err
+}
@@ -0,0 +1,164 @@
+// ABOUTME: Tests for provider HTTP transport options: defaults, disabled limits, HTTP/2, proxy, CA roots
+// ABOUTME: Verifies the idle-read watchdog aborts stalled streams and spares active ones
+
+package ai
+
+import (
+	"crypto/x509"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHTTPOptions_Defaults(t *testing.T) {
+	t.Parallel()
+
+	c := HTTPOptions{}.NewClient()
+	if c.Timeout != DefaultRequestTimeout {
+		t.Errorf("Timeout = %v, want %v", c.Timeout, DefaultRequestTimeout)
+	}
+	tr, ok := c.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("Transport = %T, want *http.Transport without idle timeout", c.Transport)
+	}
+	if tr.ResponseHeaderTimeout != DefaultResponseHeaderTimeout {
+		t.Errorf("ResponseHeaderTimeout = %v, want %v", tr.ResponseHeaderTimeout, DefaultResponseHeaderTimeout)
+	}
+	if !tr.ForceAttemptHTTP2 || tr.Protocols != nil {
+		t.Error("HTTP/2 should be enabled by default")
+	}
+	if tr.HTTP2.SendPingTimeout != 0 {
+		t.Errorf("SendPingTimeout = %v, want 0 (off)", tr.HTTP2.SendPingTimeout)
+	}
+}
+
+func TestHTTPOptions_NegativeDisables(t *testing.T) {
+	t.Parallel()
+
+	c := HTTPOptions{RequestTimeout: -1, ResponseHeaderTimeout: -1}.NewClient()
+	if c.Timeout != 0 {
+		t.Errorf("Timeout = %v, want 0 (none)", c.Timeout)
+	}
+	if tr := c.Transport.(*http.Transport); tr.ResponseHeaderTimeout != 0 {
+		t.Errorf("ResponseHeaderTimeout = %v, want 0 (none)", tr.ResponseHeaderTimeout)
+	}
+}
+
+func TestHTTPOptions_DisableHTTP2(t *testing.T) {
+	t.Parallel()
+
+	tr := HTTPOptions{DisableHTTP2: true, HTTP2PingInterval: 10 * time.Second}.NewTransport()
+	if tr.ForceAttemptHTTP2 {
+		t.Error("ForceAttemptHTTP2 should be false")
+	}
+	if tr.Protocols == nil || tr.Protocols.HTTP2() || !tr.Protocols.HTTP1() {
+		t.Errorf("Protocols = %v, want HTTP/1 only", tr.Protocols)
+	}
+}
+
+func TestHTTPOptions_IdleReadTimeoutAbortsStall(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("data: first\n\n"))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done() // stall until the client gives up
+	}))
+	defer srv.Close()
+
+	c := HTTPOptions{IdleReadTimeout: 50 * time.Millisecond}.NewClient()
+	resp, err := c.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	defer resp.Body.Close()
+
+	start := time.Now()
+	_, err = io.ReadAll(resp.Body)
+	if !errors.Is(err, ErrStreamIdle) {
+		t.Fatalf("ReadAll error = %v, want ErrStreamIdle", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("stall detected after %v, want ~50ms", elapsed)
+	}
+}
+
+func TestHTTPOptions_IdleReadTimeoutResetsOnData(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		for range 5 {
+			w.Write([]byte("data: tick\n\n"))
+			w.(http.Flusher).Flush()
+			time.Sleep(30 * time.Millisecond)
+		}
+	}))
+	defer srv.Close()
+
+	// Total stream time (~150ms) exceeds the idle limit, but no single gap does.
+	c := HTTPOptions{IdleReadTimeout: 100 * time.Millisecond}.NewClient()
+	resp, err := c.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if len(body) != 5*len("data: tick\n\n") {
+		t.Errorf("body length = %d, want full stream", len(body))
+	}
+}
+
+func TestHTTPOptions_Proxy(t *testing.T) {
+	t.Setenv("NO_PROXY", "internal.example")
+
+	tr := HTTPOptions{Proxy: "http://proxy.corp:3128"}.NewTransport()
+	for _, tc := range []struct {
+		url, want string
+	}{
+		{"https://api.anthropic.com/v1/messages", "http://proxy.corp:3128"},
+		{"http://example.com/", "http://proxy.corp:3128"},
+		{"https://internal.example/v1", ""},
+		{"http://localhost:11434/v1", ""},
+	} {
+		req, _ := http.NewRequest(http.MethodGet, tc.url, nil)
+		got, err := tr.Proxy(req)
+		if err != nil {
+			t.Fatalf("Proxy(%s): %v", tc.url, err)
+		}
+		if (got == nil && tc.want != "") || (got != nil && got.String() != tc.want) {
+			t.Errorf("Proxy(%s) = %v, want %q", tc.url, got, tc.want)
+		}
+	}
+}
+
+func TestHTTPOptions_RootCAs(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	if _, err := (HTTPOptions{}).NewClient().Get(srv.URL); err == nil {
+		t.Error("a self-signed server should fail verification against the system roots")
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+	resp, err := HTTPOptions{RootCAs: pool}.NewClient().Get(srv.URL)
+	if err != nil {
+		t.Fatalf("with the server's CA: %v", err)
+	}
+	resp.Body.Close()
+
+	resp, err = HTTPOptions{InsecureSkipVerify: true}.NewClient().Get(srv.URL)
+	if err != nil {
+		t.Fatalf("with verification off: %v", err)
+	}
+	resp.Body.Close()
+}
@@ -58,6 +58,7 @@ type Content struct {
 	MediaType    string          `json:"media_type,omitempty"`    // Image media type
 	Data         string          `json:"data,omitempty"`          // Base64 image data
 	Thinking     string          `json:"thinking,omitempty"`      // Extended thinking text
+	Signature    string          `json:"signature,omitempty"`     // Provider signature of a thinking block
 	CacheControl *CacheControl   `json:"cache_control,omitempty"` // Provider caching hint
 	Images       []ImageContent  `json:"images,omitempty"`        // Images attached to tool results
 }
@@ -66,6 +67,11 @@ type Content struct {
 type Message struct {
 	Role    Role      `json:"role"`
 	Content []Content `json:"content"`
+	Pinned  bool      `json:"pinned,omitempty"` // Kept verbatim by compaction and eviction
+
+	// Ephemeral marks a transient system reminder: dropped first by
+	// compaction and hidden from exports.
+	Ephemeral bool `json:"ephemeral,omitempty"`
 }
 
 // NewTextMessage creates a message with a single text content block.
@@ -100,21 +106,25 @@ const (
 	ApiOpenAI    Api = "openai"
 	ApiGoogle    Api = "google"
 	ApiVertex    Api = "vertex"
+	ApiMock      Api = "mock" // local echo/script provider; no network
 )
 
 // Model defines a model's metadata.
 type Model struct {
-	ID               string            `json:"id"`
-	Name             string            `json:"name"`
-	Api              Api               `json:"api"`
-	MaxTokens        int               `json:"max_tokens"`
-	MaxOutputTokens  int               `json:"max_output_tokens"`
-	ContextWindow    int               `json:"context_window,omitempty"`
-	SupportsImages   bool              `json:"supports_images"`
-	SupportsTools    bool              `json:"supports_tools"`
-	SupportsThinking bool              `json:"supports_thinking"`
-	BaseURL          string            `json:"base_url,omitempty"`
-	CustomHeaders    map[string]string `json:"custom_headers,omitempty"`
+	ID                        string            `json:"id"`
+	Name                      string            `json:"name"`
+	Api                       Api               `json:"api"`
+	MaxTokens                 int               `json:"max_tokens"`
+	MaxOutputTokens           int               `json:"max_output_tokens"`
+	ContextWindow             int               `json:"context_window,omitempty"`
+	SupportsImages            bool              `json:"supports_images"`
+	SupportsTools             bool              `json:"supports_tools"`
+	SupportsParallelToolCalls bool              `json:"supports_parallel_tool_calls"`
+	MaxToolSchemas            int               `json:"max_tool_schemas,omitempty"` // tools sent per call; 0 = all
+	TextToolCalls             bool              `json:"text_tool_calls,omitempty"`  // tools described in the prompt and called in fenced text blocks
+	SupportsThinking          bool              `json:"supports_thinking"`
+	BaseURL                   string            `json:"base_url,omitempty"`
+	CustomHeaders             map[string]string `json:"custom_headers,omitempty"`
 }
 
 // EffectiveContextWindow returns ContextWindow if set, otherwise MaxTokens.
@@ -140,6 +150,7 @@ type StreamOptions struct {
 	TopP             float64  `json:"top_p,omitempty"`
 	StopSequences    []string `json:"stop_sequences,omitempty"`
 	Thinking         bool     `json:"thinking,omitempty"`
+	ThinkingLevel    string   `json:"thinking_level,omitempty"`     // sizes Thinking: minimal to xhigh; "" is medium
 	StreamBufferSize int      `json:"stream_buffer_size,omitempty"` // 0 = provider default
 }
 
@@ -0,0 +1,185 @@
+// ABOUTME: Wire dumps for provider debugging: each HTTP exchange is written to its own file
+// ABOUTME: Credentials in headers and query strings are redacted; streamed response chunks are written as they arrive
+
+package ai
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	pilog "github.com/mauromedda/pi-coding-agent-go/internal/log"
+)
+
+// redactedValue replaces a credential in a dump.
+const redactedValue = "[REDACTED]"
+
+// WireDump writes every request a provider client sends, and the response
+// it gets, to a file of its own. Files are named by sequence number and,
+// when the request context carries one, the agent's request ID.
+type WireDump struct {
+	// Dir returns the directory dumps go to; it is resolved on the first
+	// call, so it may name a log that does not exist yet at startup.
+	Dir func() string
+	// Redact, when set, scrubs credentials from bodies, e.g. keys pasted
+	// into a prompt. Headers and query parameters are always redacted.
+	Redact func(string) string
+
+	seq atomic.Int64
+}
+
+// Wrap returns rt dumping each round trip.
+func (d *WireDump) Wrap(rt http.RoundTripper) http.RoundTripper {
+	return &dumpTransport{base: rt, dump: d}
+}
+
+// open creates the file for one exchange.
+func (d *WireDump) open(req *http.Request) (*os.File, error) {
+	dir := d.Dir()
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("creating dump directory: %w", err)
+	}
+	name := fmt.Sprintf("%04d", d.seq.Add(1))
+	if id := pilog.RequestID(req.Context()); id != "" {
+		name += "-" + id
+	}
+	return os.OpenFile(filepath.Join(dir, name+".http"), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+}
+
+func (d *WireDump) redact(s string) string {
+	if d.Redact == nil {
+		return s
+	}
+	return d.Redact(s)
+}
+
+type dumpTransport struct {
+	base http.RoundTripper
+	dump *WireDump
+}
+
+func (t *dumpTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	f, err := t.dump.open(req)
+	if err != nil {
+		// Dumping is best effort; the call goes ahead undumped.
+		pilog.Debug("wire dump: %v", err)
+		return t.base.RoundTrip(req)
+	}
+
+	var body []byte
+	if req.Body != nil {
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("reading request body: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	start := time.Now()
+	fmt.Fprintf(f, "=== REQUEST %s\n%s %s\n", start.Format(time.RFC3339Nano), req.Method, redactURL(req.URL))
+	writeHeaders(f, req.Header)
+	fmt.Fprintf(f, "\n%s\n", t.dump.redact(string(body)))
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		fmt.Fprintf(f, "\n=== ERROR after %s\n%v\n", time.Since(start).Round(time.Millisecond), err)
+		f.Close()
+		return nil, err
+	}
+	fmt.Fprintf(f, "\n=== RESPONSE %s after %s\n", resp.Status, time.Since(start).Round(time.Millisecond))
+	writeHeaders(f, resp.Header)
+	f.WriteString("\n")
+	resp.Body = &dumpBody{rc: resp.Body, f: f, dump: t.dump, start: start}
+	return resp, nil
+}
+
+// dumpBody copies a response body to the dump as the provider reads it,
+// so a stream that stalls or breaks shows how far it got.
+type dumpBody struct {
+	rc    io.ReadCloser
+	f     *os.File
+	dump  *WireDump
+	start time.Time
+
+	mu     sync.Mutex
+	n      int64
+	closed bool
+}
+
+func (b *dumpBody) Read(p []byte) (int, error) {
+	n, err := b.rc.Read(p)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if n > 0 && !b.closed {
+		b.f.WriteString(b.dump.redact(string(p[:n])))
+		b.n += int64(n)
+	}
+	if err != nil && err != io.EOF && !b.closed {
+		fmt.Fprintf(b.f, "\n=== READ ERROR after %s\n%v\n", time.Since(b.start).Round(time.Millisecond), err)
+	}
+	return n, err
+}
+
+func (b *dumpBody) Close() error {
+	err := b.rc.Close()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.closed {
+		b.closed = true
+		fmt.Fprintf(b.f, "\n=== END %d bytes in %s\n", b.n, time.Since(b.start).Round(time.Millisecond))
+		b.f.Close()
+	}
+	return err
+}
+
+// isCredential reports whether a header or query parameter name holds one.
+func isCredential(name string) bool {
+	name = strings.ToLower(name)
+	for _, s := range []string{"authorization", "key", "token", "secret", "cookie", "signature"} {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeHeaders writes h sorted by name, credentials redacted.
+func writeHeaders(w io.Writer, h http.Header) {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, v := range h[name] {
+			if isCredential(name) {
+				v = redactedValue
+			}
+			fmt.Fprintf(w, "%s: %s\n", name, v)
+		}
+	}
+}
+
+// redactURL returns u with credential query parameters and userinfo redacted.
+func redactURL(u *url.URL) string {
+	c := *u
+	c.User = nil
+	q := c.Query()
+	for name := range q {
+		if isCredential(name) {
+			q.Set(name, redactedValue)
+		}
+	}
+	c.RawQuery = q.Encode()
+	return c.String()
+}
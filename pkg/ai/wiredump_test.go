@@ -0,0 +1,86 @@
+// ABOUTME: Tests for wire dumps: request and streamed response capture, credential redaction, file naming
+// ABOUTME: Runs real HTTP round trips against httptest servers; dumps go to tempdirs
+
+package ai
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	pilog "github.com/mauromedda/pi-coding-agent-go/internal/log"
+)
+
+func TestWireDump_RequestAndStream(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "data: {\"delta\":\"hel\"}\n\n")
+		w.(http.Flusher).Flush()
+		io.WriteString(w, "data: {\"delta\":\"lo\"}\n\n")
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	dump := &WireDump{
+		Dir:    func() string { return dir },
+		Redact: func(s string) string { return strings.ReplaceAll(s, "sk-secret", "[REDACTED]") },
+	}
+	client := HTTPOptions{Dump: dump}.NewClient()
+
+	ctx := pilog.WithRequestID(context.Background(), "req42")
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, srv.URL+"/v1/messages?key=AIzaXYZ&beta=true",
+		strings.NewReader(`{"prompt":"my key is sk-secret"}`))
+	req.Header.Set("X-Api-Key", "sk-secret")
+	req.Header.Set("Anthropic-Version", "2023-06-01")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), "lo") {
+		t.Fatalf("body = %q; the dump must not consume it", body)
+	}
+
+	got, err := os.ReadFile(filepath.Join(dir, "0001-req42.http"))
+	if err != nil {
+		t.Fatalf("dump file: %v", err)
+	}
+	dumped := string(got)
+	for _, want := range []string{
+		"POST " + srv.URL + "/v1/messages?beta=true&key=%5BREDACTED%5D",
+		"X-Api-Key: [REDACTED]",
+		"Anthropic-Version: 2023-06-01",
+		`{"prompt":"my key is [REDACTED]"}`,
+		"=== RESPONSE 200 OK",
+		`data: {"delta":"hel"}`,
+		`data: {"delta":"lo"}`,
+		"=== END",
+	} {
+		if !strings.Contains(dumped, want) {
+			t.Errorf("dump lacks %q:\n%s", want, dumped)
+		}
+	}
+	if strings.Contains(dumped, "sk-secret") || strings.Contains(dumped, "AIzaXYZ") {
+		t.Errorf("dump leaks a credential:\n%s", dumped)
+	}
+}
+
+func TestWireDump_TransportError(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	client := HTTPOptions{Dump: &WireDump{Dir: func() string { return dir }}}.NewClient()
+	if _, err := client.Get("http://127.0.0.1:1/unreachable"); err == nil {
+		t.Fatal("expected a connection error")
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "0001.http"))
+	if err != nil || !strings.Contains(string(got), "=== ERROR") {
+		t.Errorf("dump = %q, %v; want the error recorded", got, err)
+	}
+}
@@ -58,29 +58,35 @@ func resetDetectCache() {
 }
 
 func detect() Capability {
+	return DetectFromEnv(os.Getenv)
+}
+
+// DetectFromEnv is Detect without caching, reading variables through getenv.
+// Callers that need a deterministic environment (tests, capability matrices) use it directly.
+func DetectFromEnv(getenv func(string) string) Capability {
 	// 1. Kitty
-	if os.Getenv("KITTY_WINDOW_ID") != "" {
+	if getenv("KITTY_WINDOW_ID") != "" {
 		return Capability{Images: ProtoKitty, TrueColor: true}
 	}
 
-	term := strings.ToLower(os.Getenv("TERM_PROGRAM"))
+	term := strings.ToLower(getenv("TERM_PROGRAM"))
 
 	if term == "kitty" {
 		return Capability{Images: ProtoKitty, TrueColor: true}
 	}
 
 	// 2. Ghostty (Kitty-compatible)
-	if os.Getenv("GHOSTTY_RESOURCES_DIR") != "" || term == "ghostty" {
+	if getenv("GHOSTTY_RESOURCES_DIR") != "" || term == "ghostty" {
 		return Capability{Images: ProtoKitty, TrueColor: true}
 	}
 
 	// 3. WezTerm (Kitty-compatible)
-	if os.Getenv("WEZTERM_PANE") != "" || term == "wezterm" {
+	if getenv("WEZTERM_PANE") != "" || term == "wezterm" {
 		return Capability{Images: ProtoKitty, TrueColor: true}
 	}
 
 	// 4. iTerm2
-	if os.Getenv("ITERM_SESSION_ID") != "" || term == "iterm.app" {
+	if getenv("ITERM_SESSION_ID") != "" || term == "iterm.app" {
 		return Capability{Images: ProtoITerm2, TrueColor: true}
 	}
 
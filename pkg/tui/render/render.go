@@ -0,0 +1,224 @@
+// ABOUTME: Double-buffered line-diff renderer: keeps the frame on screen and writes only what changed
+// ABOUTME: A changed line is rewritten from its first differing cell, with SGR and hyperlink state restored
+
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/internal/pool"
+	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/width"
+	"github.com/rivo/uniseg"
+)
+
+const (
+	syncBegin   = "\x1b[?2026h" // synchronized update: the terminal shows the frame at once
+	syncEnd     = "\x1b[?2026l"
+	sgrReset    = "\x1b[0m"
+	eraseLine   = "\x1b[K"
+	eraseBelow  = "\x1b[J"
+	linkClose   = "\x1b]8;;\x1b\\"
+	linkPrefix  = "\x1b]8;"
+	csiPrefix   = "\x1b["
+	cursorToFmt = "\x1b[%d;%dH"
+)
+
+// Stats counts the work done by a Renderer.
+type Stats struct {
+	Frames int // frames that wrote anything
+	Lines  int // lines rewritten, in whole or in part
+	Bytes  int // bytes written to the terminal
+}
+
+// Renderer paints frames on a full-screen (alternate screen) terminal. It
+// keeps the lines currently on screen and, for each new frame, moves the
+// cursor to the lines that differ and rewrites them from the first cell
+// that changed. Unchanged lines cost nothing, and appending to a line only
+// sends the appended text, which matters over SSH.
+//
+// A Renderer is not safe for concurrent use.
+type Renderer struct {
+	out    io.Writer
+	front  []string // lines on screen, as last rendered
+	width  int
+	height int
+	full   bool // repaint every line on the next frame
+	stats  Stats
+}
+
+// New returns a Renderer writing to out. The first frame repaints the
+// whole screen.
+func New(out io.Writer) *Renderer {
+	return &Renderer{out: out, full: true}
+}
+
+// Resize sets the screen size. Lines wider than width are cut and only the
+// last height lines of a frame are shown; zero means unlimited. A new size
+// forces a full repaint, since the terminal may have reflowed the screen.
+func (r *Renderer) Resize(width, height int) {
+	if width == r.width && height == r.height {
+		return
+	}
+	r.width, r.height = width, height
+	r.full = true
+}
+
+// Invalidate forces a full repaint on the next frame, e.g. after another
+// program used the terminal.
+func (r *Renderer) Invalidate() {
+	r.full = true
+}
+
+// Stats returns the counters accumulated so far.
+func (r *Renderer) Stats() Stats {
+	return r.stats
+}
+
+// Render paints frame, a newline-separated screen, writing only the
+// regions that differ from the previous frame. Nothing is written when the
+// frame is unchanged.
+func (r *Renderer) Render(frame string) error {
+	lines := strings.Split(frame, "\n")
+	if r.height > 0 && len(lines) > r.height {
+		lines = lines[len(lines)-r.height:]
+	}
+
+	buf := pool.GetBytesBuffer()
+	defer pool.PutBytesBuffer(buf)
+	buf.WriteString(syncBegin + sgrReset)
+	header := buf.Len()
+
+	for i, line := range lines {
+		old, known := "", false
+		if !r.full && i < len(r.front) {
+			old, known = r.front[i], true
+		}
+		if known && old == line {
+			continue
+		}
+		r.writeLine(buf, i, old, line)
+	}
+	if (r.full || len(lines) < len(r.front)) && (r.height == 0 || len(lines) < r.height) {
+		fmt.Fprintf(buf, cursorToFmt, len(lines)+1, 1)
+		buf.WriteString(eraseBelow)
+	}
+
+	r.front = lines
+	r.full = false
+	if buf.Len() == header {
+		return nil
+	}
+	buf.WriteString(syncEnd)
+
+	n, err := r.out.Write(buf.Bytes())
+	r.stats.Frames++
+	r.stats.Bytes += n
+	if err != nil {
+		return fmt.Errorf("writing frame: %w", err)
+	}
+	return nil
+}
+
+// writeLine rewrites screen row row, which shows old, to show line. The
+// cells both share are left alone; the rest of the line is written after
+// restoring the style in effect at that point, and cleared to the end.
+func (r *Renderer) writeLine(buf *bytes.Buffer, row int, old, line string) {
+	cut, col := commonPrefix(old, line)
+	if r.width > 0 && col >= r.width {
+		return // the difference is past the right edge
+	}
+	rest := line[cut:]
+	restWidth := width.VisibleWidth(rest)
+	if r.width > 0 && col+restWidth > r.width {
+		rest = width.SliceByColumn(rest, 0, r.width-col)
+		restWidth = r.width - col
+	}
+
+	state, link := stateAt(line[:cut])
+	fmt.Fprintf(buf, cursorToFmt, row+1, col+1)
+	buf.WriteString(state)
+	buf.WriteString(rest)
+	if link || strings.Contains(rest, linkPrefix) {
+		buf.WriteString(linkClose)
+	}
+	if state != "" || strings.IndexByte(rest, '\x1b') >= 0 {
+		buf.WriteString(sgrReset)
+	}
+	// At the right edge the cursor sits on the last cell, which erasing
+	// would blank; a full-width line has nothing to clear anyway.
+	if r.width == 0 || col+restWidth < r.width {
+		buf.WriteString(eraseLine)
+	}
+	r.stats.Lines++
+}
+
+// commonPrefix returns the length in bytes of the longest prefix line
+// shares with old that ends between two cells (never inside an escape
+// sequence or a grapheme cluster), and the columns it covers.
+func commonPrefix(old, line string) (cut, col int) {
+	for cut < len(old) && cut < len(line) {
+		n := unitLen(line, cut)
+		if unitLen(old, cut) != n || old[cut:cut+n] != line[cut:cut+n] {
+			break
+		}
+		switch {
+		case line[cut] == '\x1b':
+		case n == 1:
+			col++
+		default:
+			col += width.VisibleWidth(line[cut : cut+n])
+		}
+		cut += n
+	}
+	return cut, col
+}
+
+// unitLen returns the length of the escape sequence or grapheme cluster
+// starting at s[i].
+func unitLen(s string, i int) int {
+	if s[i] == '\x1b' {
+		return width.SequenceEnd(s, i) - i
+	}
+	if s[i] < utf8.RuneSelf && (i+1 == len(s) || s[i+1] < utf8.RuneSelf) {
+		return 1
+	}
+	cluster, _, _, _ := uniseg.FirstGraphemeClusterInString(s[i:], -1)
+	return len(cluster)
+}
+
+// stateAt returns the sequences that recreate the SGR style and open
+// hyperlink at the end of prefix, and whether a hyperlink is open.
+func stateAt(prefix string) (string, bool) {
+	if strings.IndexByte(prefix, '\x1b') < 0 {
+		return "", false
+	}
+	var sgr width.ActiveSGR
+	link := ""
+	for _, seq := range width.ExtractANSI(prefix) {
+		switch {
+		case strings.HasPrefix(seq, linkPrefix):
+			link = seq
+			if linkTarget(seq) == "" {
+				link = ""
+			}
+		case strings.HasPrefix(seq, csiPrefix) && strings.HasSuffix(seq, "m"):
+			sgr.Apply(seq)
+		}
+	}
+	return sgr.String() + link, link != ""
+}
+
+// linkTarget returns the URI of an OSC 8 sequence; it is empty for the
+// sequence that ends a hyperlink.
+func linkTarget(seq string) string {
+	body := strings.TrimPrefix(seq, linkPrefix)
+	body = strings.TrimSuffix(strings.TrimSuffix(body, "\x1b\\"), "\x07")
+	if _, uri, ok := strings.Cut(body, ";"); ok {
+		return uri
+	}
+	return ""
+}
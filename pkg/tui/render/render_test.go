@@ -0,0 +1,258 @@
+// ABOUTME: Tests for the line-diff renderer: partial line rewrites, style and hyperlink restore, resizing
+// ABOUTME: A small screen emulator checks that the diffs always reproduce the full frame
+
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/width"
+)
+
+// renderAll renders frames in order and returns what the last one wrote.
+func renderAll(t *testing.T, r *Renderer, out *bytes.Buffer, frames ...string) string {
+	t.Helper()
+	for _, f := range frames {
+		out.Reset()
+		if err := r.Render(f); err != nil {
+			t.Fatalf("Render(%q): %v", f, err)
+		}
+	}
+	return out.String()
+}
+
+// screen emulates the subset of a terminal the renderer drives: cursor
+// positioning, erase to end of line and below, and plain text. Styles and
+// hyperlinks are ignored.
+type screen struct {
+	rows     [][]rune
+	row, col int
+}
+
+func newScreen(w, h int) *screen {
+	s := &screen{rows: make([][]rune, h)}
+	for i := range s.rows {
+		s.rows[i] = []rune(strings.Repeat(" ", w))
+	}
+	return s
+}
+
+func (s *screen) apply(out string) {
+	for i := 0; i < len(out); {
+		if out[i] != '\x1b' {
+			if s.col < len(s.rows[s.row]) {
+				s.rows[s.row][s.col] = rune(out[i])
+			}
+			s.col++
+			i++
+			continue
+		}
+		end := width.SequenceEnd(out, i)
+		seq := out[i:end]
+		i = end
+		switch {
+		case strings.HasSuffix(seq, "H"):
+			fmt.Sscanf(seq, "\x1b[%d;%dH", &s.row, &s.col)
+			s.row--
+			s.col--
+		case seq == eraseLine:
+			for c := s.col; c < len(s.rows[s.row]); c++ {
+				s.rows[s.row][c] = ' '
+			}
+		case seq == eraseBelow:
+			for c := s.col; c < len(s.rows[s.row]); c++ {
+				s.rows[s.row][c] = ' '
+			}
+			for r := s.row + 1; r < len(s.rows); r++ {
+				for c := range s.rows[r] {
+					s.rows[r][c] = ' '
+				}
+			}
+		}
+	}
+}
+
+func (s *screen) String() string {
+	lines := make([]string, len(s.rows))
+	for i, r := range s.rows {
+		lines[i] = strings.TrimRight(string(r), " ")
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
+
+func TestRender_FirstFramePaintsEverything(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	got := renderAll(t, New(&out), &out, "one\ntwo")
+	want := syncBegin + sgrReset +
+		"\x1b[1;1Hone" + eraseLine + "\x1b[2;1Htwo" + eraseLine +
+		"\x1b[3;1H" + eraseBelow + syncEnd
+	if got != want {
+		t.Errorf("first frame = %q; want %q", got, want)
+	}
+}
+
+func TestRender_UnchangedFrameWritesNothing(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	r := New(&out)
+	if got := renderAll(t, r, &out, "same\nframe", "same\nframe"); got != "" {
+		t.Errorf("unchanged frame wrote %q", got)
+	}
+	if s := r.Stats(); s.Frames != 1 || s.Lines != 2 {
+		t.Errorf("Stats = %+v; want 1 frame, 2 lines", s)
+	}
+}
+
+func TestRender_AppendOnlySendsNewText(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	got := renderAll(t, New(&out), &out, "header\nhello", "header\nhello world")
+	want := syncBegin + sgrReset + "\x1b[2;6H world" + eraseLine + syncEnd
+	if got != want {
+		t.Errorf("append = %q; want %q", got, want)
+	}
+}
+
+func TestRender_RestoresStyleAtTheCut(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	got := renderAll(t, New(&out), &out, "\x1b[1m\x1b[31mabc", "\x1b[1m\x1b[31mabd")
+	if want := "\x1b[1;3H\x1b[1m\x1b[31md" + sgrReset + eraseLine; !strings.Contains(got, want) {
+		t.Errorf("styled change = %q; want it to contain %q", got, want)
+	}
+}
+
+func TestRender_StyleChangeRewritesFromTheStyledCell(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	got := renderAll(t, New(&out), &out, "ab\x1b[31mcd", "ab\x1b[32mcd")
+	if want := "\x1b[1;3H\x1b[32mcd"; !strings.Contains(got, want) {
+		t.Errorf("recolor = %q; want it to contain %q", got, want)
+	}
+}
+
+func TestRender_RestoresOpenHyperlink(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	open := "\x1b]8;;https://a.dev\x1b\\"
+	got := renderAll(t, New(&out), &out, open+"ab"+linkClose, open+"ac"+linkClose)
+	if want := "\x1b[1;2H" + open + "c" + linkClose; !strings.Contains(got, want) {
+		t.Errorf("link change = %q; want it to contain %q", got, want)
+	}
+}
+
+func TestRender_NeverSplitsGraphemeClusters(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	got := renderAll(t, New(&out), &out, "cafe", "café")
+	if want := "\x1b[1;4Hé"; !strings.Contains(got, want) {
+		t.Errorf("combining mark = %q; want it to contain %q", got, want)
+	}
+}
+
+func TestRender_ShrinkClearsBelow(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	got := renderAll(t, New(&out), &out, "a\nb\nc", "a")
+	if want := syncBegin + sgrReset + "\x1b[2;1H" + eraseBelow + syncEnd; got != want {
+		t.Errorf("shrink = %q; want %q", got, want)
+	}
+}
+
+func TestRender_ClipsToScreen(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	r := New(&out)
+	r.Resize(4, 2)
+	got := renderAll(t, r, &out, "dropped\nabcdef\nxy")
+	if strings.Contains(got, "dropped") || strings.Contains(got, "ef") {
+		t.Errorf("frame should keep the last 2 lines cut to 4 columns; got %q", got)
+	}
+	if !strings.Contains(got, "\x1b[1;1Habcd\x1b[2;1Hxy"+eraseLine) {
+		t.Errorf("a full-width line must not be erased after; got %q", got)
+	}
+	if strings.Contains(got, eraseBelow) {
+		t.Errorf("a full screen has nothing below to clear; got %q", got)
+	}
+}
+
+func TestRender_ResizeAndInvalidateRepaint(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	r := New(&out)
+	renderAll(t, r, &out, "a\nb")
+
+	r.Resize(80, 24)
+	if got := renderAll(t, r, &out, "a\nb"); !strings.Contains(got, "\x1b[1;1Ha") || !strings.Contains(got, "\x1b[2;1Hb") {
+		t.Errorf("resize should repaint every line; got %q", got)
+	}
+	r.Resize(80, 24)
+	if got := renderAll(t, r, &out, "a\nb"); got != "" {
+		t.Errorf("same size should not repaint; got %q", got)
+	}
+	r.Invalidate()
+	if got := renderAll(t, r, &out, "a\nb"); !strings.Contains(got, "\x1b[1;1Ha") {
+		t.Errorf("Invalidate should repaint; got %q", got)
+	}
+}
+
+func TestRender_DiffsReproduceFrames(t *testing.T) {
+	t.Parallel()
+	frames := []string{
+		"pi-go\n\nassistant: Hel",
+		"pi-go\n\nassistant: Hello, wor",
+		"pi-go\n\nassistant: Hello, world!\n\n> ",
+		"pi-go\n\n\x1b[1massistant\x1b[0m: Hello, world!\n\n> ls",
+		"pi-go\n\x1b[2m  thinking\x1b[0m\n> l",
+		"short",
+		"a much longer first line that is cut\nsecond\nthird\nfourth\nfifth\nsixth",
+		"",
+	}
+	var out bytes.Buffer
+	r := New(&out)
+	r.Resize(30, 5)
+	s := newScreen(30, 5)
+	for _, f := range frames {
+		s.apply(renderAll(t, r, &out, f))
+
+		lines := strings.Split(width.StripANSI(f), "\n")
+		if len(lines) > 5 {
+			lines = lines[len(lines)-5:]
+		}
+		for i, l := range lines {
+			if len(l) > 30 {
+				l = l[:30]
+			}
+			lines[i] = strings.TrimRight(l, " ")
+		}
+		if want := strings.TrimRight(strings.Join(lines, "\n"), "\n"); s.String() != want {
+			t.Fatalf("after %q the screen shows\n%s\nwant\n%s", f, s, want)
+		}
+	}
+}
+
+func TestCommonPrefix(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		old, line string
+		cut, col  int
+	}{
+		{"", "abc", 0, 0},
+		{"abc", "abd", 2, 2},
+		{"abc", "ab", 2, 2},
+		{"\x1b[1mab", "\x1b[1mac", 5, 1},
+		{"\x1b[31mx", "\x1b[32mx", 0, 0},
+		{"日本語", "日本人", 6, 4},
+		{"e", "é", 0, 0},
+	}
+	for _, tt := range tests {
+		cut, col := commonPrefix(tt.old, tt.line)
+		if cut != tt.cut || col != tt.col {
+			t.Errorf("commonPrefix(%q, %q) = %d, %d; want %d, %d", tt.old, tt.line, cut, col, tt.cut, tt.col)
+		}
+	}
+}
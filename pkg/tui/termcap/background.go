@@ -0,0 +1,101 @@
+// ABOUTME: Terminal background detection: OSC 11 query on /dev/tty, answered before the TUI reads input
+// ABOUTME: A DA1 query follows as a sentinel so the whole reply is consumed and nothing leaks into the editor
+
+package termcap
+
+import (
+	"bytes"
+	"os"
+	"regexp"
+	"strconv"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// backgroundQuery asks for the background color (OSC 11), then for the
+// primary device attributes (DA1). Every terminal answers DA1, so its reply
+// marks the end of the response whether or not OSC 11 is supported.
+const backgroundQuery = "\x1b]11;?\x07\x1b[c"
+
+// osc11Reply matches "ESC ] 11 ; rgb:RRRR/GGGG/BBBB" with 1-4 hex digits per channel.
+var osc11Reply = regexp.MustCompile(`\x1b\]11;rgb:([0-9a-fA-F]{1,4})/([0-9a-fA-F]{1,4})/([0-9a-fA-F]{1,4})`)
+
+// da1Reply matches the end of a DA1 reply: "ESC [ ? ... c".
+var da1Reply = regexp.MustCompile(`\x1b\[\?[0-9;]*c`)
+
+// ParseBackgroundReply extracts the background color from an OSC 11 reply,
+// with each channel scaled to [0, 1].
+func ParseBackgroundReply(reply []byte) (r, g, b float64, ok bool) {
+	sm := osc11Reply.FindSubmatch(reply)
+	if sm == nil {
+		return 0, 0, 0, false
+	}
+	var ch [3]float64
+	for i, hex := range sm[1:] {
+		v, err := strconv.ParseUint(string(hex), 16, 16)
+		if err != nil {
+			return 0, 0, 0, false
+		}
+		ch[i] = float64(v) / float64(uint64(1)<<(4*len(hex))-1)
+	}
+	return ch[0], ch[1], ch[2], true
+}
+
+// IsDarkColor reports whether a color with channels in [0, 1] is dark,
+// by its relative luminance.
+func IsDarkColor(r, g, b float64) bool {
+	return 0.2126*r+0.7152*g+0.0722*b < 0.5
+}
+
+// QueryBackground asks the terminal for its background color and reports
+// whether it is dark. ok is false when the answer is unknown: not a
+// terminal, a terminal that does not answer OSC 11, or no reply within
+// timeout. It must run before anything else reads the terminal.
+func QueryBackground(timeout time.Duration) (dark, ok bool) {
+	c := Detect()
+	if c.Family == FamilyDumb || c.Multiplexer == "screen" ||
+		!term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return false, false
+	}
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return false, false
+	}
+	defer tty.Close()
+	// Without a read deadline a silent terminal would block startup, and a
+	// reader left behind would steal keystrokes from the TUI.
+	if err := tty.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return false, false
+	}
+	// Fd would switch the file to blocking mode and disable the deadline.
+	conn, err := tty.SyscallConn()
+	if err != nil {
+		return false, false
+	}
+	var state *term.State
+	if cerr := conn.Control(func(fd uintptr) { state, err = term.MakeRaw(int(fd)) }); cerr != nil || err != nil {
+		return false, false
+	}
+	defer func() {
+		_ = conn.Control(func(fd uintptr) { _ = term.Restore(int(fd), state) })
+	}()
+
+	if _, err := tty.WriteString(backgroundQuery); err != nil {
+		return false, false
+	}
+	var reply bytes.Buffer
+	buf := make([]byte, 256)
+	for !da1Reply.Match(reply.Bytes()) {
+		n, err := tty.Read(buf)
+		reply.Write(buf[:n])
+		if err != nil {
+			break
+		}
+	}
+	r, g, b, ok := ParseBackgroundReply(reply.Bytes())
+	if !ok {
+		return false, false
+	}
+	return IsDarkColor(r, g, b), true
+}
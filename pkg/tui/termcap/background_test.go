@@ -0,0 +1,48 @@
+// ABOUTME: Tests for OSC 11 background reply parsing and the light/dark decision
+// ABOUTME: The terminal query itself needs a real tty and is not exercised here
+
+package termcap
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseBackgroundReply(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		reply   string
+		r, g, b float64
+		ok      bool
+	}{
+		{name: "xterm 16-bit BEL", reply: "\x1b]11;rgb:ffff/ffff/ffff\x07\x1b[?64;1c", r: 1, g: 1, b: 1, ok: true},
+		{name: "ST terminated", reply: "\x1b]11;rgb:0000/8080/0000\x1b\\", g: 0x8080 / 65535.0, ok: true},
+		{name: "8-bit channels", reply: "\x1b]11;rgb:1e/1e/2e\x07", r: 0x1e / 255.0, g: 0x1e / 255.0, b: 0x2e / 255.0, ok: true},
+		{name: "DA1 only", reply: "\x1b[?62;22c"},
+		{name: "empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			r, g, b, ok := ParseBackgroundReply([]byte(tt.reply))
+			near := func(x, y float64) bool { return math.Abs(x-y) < 1e-9 }
+			if ok != tt.ok || !near(r, tt.r) || !near(g, tt.g) || !near(b, tt.b) {
+				t.Errorf("ParseBackgroundReply = %v %v %v %v; want %v %v %v %v", r, g, b, ok, tt.r, tt.g, tt.b, tt.ok)
+			}
+		})
+	}
+}
+
+func TestIsDarkColor(t *testing.T) {
+	t.Parallel()
+	if !IsDarkColor(0.12, 0.12, 0.18) {
+		t.Error("a near-black background should be dark")
+	}
+	if IsDarkColor(0.99, 0.96, 0.89) {
+		t.Error("a solarized-light background should not be dark")
+	}
+	if !IsDarkColor(0, 0, 1) {
+		t.Error("pure blue is dark by luminance")
+	}
+}
@@ -0,0 +1,203 @@
+// ABOUTME: Downsamples SGR color codes to the detected depth: truecolor to 256, 256 to 16, or no color
+// ABOUTME: Without color, background-only codes become reverse video so selections stay visible
+
+package termcap
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// sgrSeq matches one SGR escape sequence like "\x1b[38;5;208m".
+var sgrSeq = regexp.MustCompile(`\x1b\[([0-9;]*)m`)
+
+// ansi16 holds the RGB values of the 16 basic colors (xterm defaults).
+var ansi16 = [16][3]int{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// cubeLevels are the channel values of the 6x6x6 cube of the 256-color palette.
+var cubeLevels = [6]int{0, 95, 135, 175, 215, 255}
+
+// Downsample rewrites the SGR sequences in code for a terminal of the given
+// depth. Attributes (bold, dim, italic, underline, reverse) are kept.
+func Downsample(code string, depth ColorDepth) string {
+	if depth == ColorTrue || code == "" {
+		return code
+	}
+	return sgrSeq.ReplaceAllStringFunc(code, func(seq string) string {
+		params := sgrSeq.FindStringSubmatch(seq)[1]
+		if params == "" {
+			return seq // reset
+		}
+		out := downsampleParams(strings.Split(params, ";"), depth)
+		if len(out) == 0 {
+			return ""
+		}
+		return "\x1b[" + strings.Join(out, ";") + "m"
+	})
+}
+
+// downsampleParams rewrites the colors in one SGR parameter list.
+func downsampleParams(params []string, depth ColorDepth) []string {
+	var out []string
+	droppedBg, hasAttr := false, false
+	for i := 0; i < len(params); i++ {
+		p := params[i]
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			continue
+		}
+		switch {
+		case (n == 38 || n == 48) && i+2 < len(params) && params[i+1] == "5":
+			idx, _ := strconv.Atoi(params[i+2])
+			i += 2
+			out = appendColor(out, n == 48, idx, color256RGB(idx), depth)
+			droppedBg = droppedBg || (n == 48 && depth == ColorNone)
+		case (n == 38 || n == 48) && i+4 < len(params) && params[i+1] == "2":
+			var rgb [3]int
+			for c := range rgb {
+				rgb[c], _ = strconv.Atoi(params[i+2+c])
+			}
+			i += 4
+			out = appendColor(out, n == 48, -1, rgb, depth)
+			droppedBg = droppedBg || (n == 48 && depth == ColorNone)
+		case n >= 30 && n <= 37, n >= 90 && n <= 97:
+			if depth != ColorNone {
+				out = append(out, p)
+			}
+		case n >= 40 && n <= 47, n >= 100 && n <= 107:
+			if depth != ColorNone {
+				out = append(out, p)
+			} else {
+				droppedBg = true
+			}
+		default:
+			out = append(out, p)
+			hasAttr = hasAttr || n == 7
+		}
+	}
+	if droppedBg && !hasAttr {
+		out = append(out, "7")
+	}
+	return out
+}
+
+// appendColor appends a foreground or background color at depth. idx is
+// the 256-color index, or -1 for a truecolor value.
+func appendColor(out []string, bg bool, idx int, rgb [3]int, depth ColorDepth) []string {
+	base := 38
+	if bg {
+		base = 48
+	}
+	switch depth {
+	case Color256:
+		if idx < 0 {
+			idx = nearest256(rgb)
+		}
+		return append(out, strconv.Itoa(base), "5", strconv.Itoa(idx))
+	case Color16:
+		if idx < 0 || idx >= 16 {
+			idx = nearest16(rgb)
+		}
+		offset := base - 8 // 30 or 40
+		if idx >= 8 {
+			offset += 60 // bright: 90 or 100
+			idx -= 8
+		}
+		return append(out, strconv.Itoa(offset+idx))
+	}
+	return out // ColorNone
+}
+
+// PaletteRGB returns the RGB value of a 256-color palette index, using
+// the xterm defaults for the 16 basic colors.
+func PaletteRGB(idx int) [3]int {
+	return color256RGB(idx)
+}
+
+// color256RGB returns the RGB value of a 256-color palette index.
+func color256RGB(idx int) [3]int {
+	switch {
+	case idx < 0 || idx > 255:
+		return [3]int{}
+	case idx < 16:
+		return ansi16[idx]
+	case idx < 232:
+		idx -= 16
+		return [3]int{cubeLevels[idx/36], cubeLevels[idx/6%6], cubeLevels[idx%6]}
+	default:
+		v := 8 + 10*(idx-232)
+		return [3]int{v, v, v}
+	}
+}
+
+// nearest256 returns the cube or gray-ramp index closest to rgb.
+func nearest256(rgb [3]int) int {
+	best, bestDist := 16, -1
+	for idx := 16; idx < 256; idx++ {
+		if d := distance(rgb, color256RGB(idx)); bestDist < 0 || d < bestDist {
+			best, bestDist = idx, d
+		}
+	}
+	return best
+}
+
+// hueColors are the basic color indexes of the six hues, 60 degrees apart
+// starting at red.
+var hueColors = [6]int{1, 3, 2, 6, 4, 5} // red, yellow, green, cyan, blue, magenta
+
+// nearest16 returns the basic color for rgb. Nearest-RGB matching against
+// the basic palette turns most pastel theme colors gray, so saturated
+// colors keep their hue instead (bright when light) and grays follow a
+// lightness ramp.
+func nearest16(rgb [3]int) int {
+	hi, lo := max(rgb[0], rgb[1], rgb[2]), min(rgb[0], rgb[1], rgb[2])
+	light := (hi + lo) / 2
+	if hi-lo < 64 {
+		switch {
+		case light < 40:
+			return 0
+		case light < 128:
+			return 8
+		case light < 210:
+			return 7
+		}
+		return 15
+	}
+	idx := hueColors[hueSector(rgb, hi, lo)]
+	if light > 127 {
+		idx += 8
+	}
+	return idx
+}
+
+// hueSector returns the hue of rgb rounded to the nearest of six 60-degree
+// sectors, 0 (red) to 5 (magenta).
+func hueSector(rgb [3]int, hi, lo int) int {
+	r, g, b := float64(rgb[0]), float64(rgb[1]), float64(rgb[2])
+	d := float64(hi - lo)
+	var h float64 // in sixths of a turn
+	switch hi {
+	case rgb[0]:
+		h = (g - b) / d
+	case rgb[1]:
+		h = 2 + (b-r)/d
+	default:
+		h = 4 + (r-g)/d
+	}
+	return (int(math.Round(h)) + 6) % 6
+}
+
+func distance(a, b [3]int) int {
+	d := 0
+	for i := range a {
+		d += (a[i] - b[i]) * (a[i] - b[i])
+	}
+	return d
+}
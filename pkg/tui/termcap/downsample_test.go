@@ -0,0 +1,73 @@
+// ABOUTME: Tests for SGR color downsampling to 256, 16, and no colors
+// ABOUTME: Covers 256-color and truecolor codes, attributes, resets, and backgrounds without color
+
+package termcap
+
+import "testing"
+
+func TestDownsample(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		code  string
+		depth ColorDepth
+		want  string
+	}{
+		{name: "truecolor kept", code: "\x1b[38;2;255;135;0m", depth: ColorTrue, want: "\x1b[38;2;255;135;0m"},
+		{name: "truecolor to 256", code: "\x1b[38;2;255;135;0m", depth: Color256, want: "\x1b[38;5;208m"},
+		{name: "truecolor bg to 256 gray", code: "\x1b[48;2;48;48;48m", depth: Color256, want: "\x1b[48;5;236m"},
+		{name: "256 kept", code: "\x1b[38;5;208m", depth: Color256, want: "\x1b[38;5;208m"},
+		{name: "256 to 16", code: "\x1b[38;5;203m", depth: Color16, want: "\x1b[91m"},
+		{name: "256 low index to 16", code: "\x1b[38;5;4m", depth: Color16, want: "\x1b[34m"},
+		{name: "256 bg to 16", code: "\x1b[48;5;236m", depth: Color16, want: "\x1b[100m"},
+		{name: "bold and color to 16", code: "\x1b[1m\x1b[38;5;117m", depth: Color16, want: "\x1b[1m\x1b[96m"},
+		{name: "basic kept at 16", code: "\x1b[36m", depth: Color16, want: "\x1b[36m"},
+		{name: "no color keeps attributes", code: "\x1b[1;38;5;208m", depth: ColorNone, want: "\x1b[1m"},
+		{name: "no color drops foreground", code: "\x1b[90m", depth: ColorNone, want: ""},
+		{name: "no color background reverses", code: "\x1b[48;5;236m", depth: ColorNone, want: "\x1b[7m"},
+		{name: "no color basic background reverses", code: "\x1b[44m", depth: ColorNone, want: "\x1b[7m"},
+		{name: "reverse not doubled", code: "\x1b[7;48;5;236m", depth: ColorNone, want: "\x1b[7m"},
+		{name: "reset kept", code: "\x1b[0m", depth: ColorNone, want: "\x1b[0m"},
+		{name: "empty", code: "", depth: Color16, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := Downsample(tt.code, tt.depth); got != tt.want {
+				t.Errorf("Downsample(%q, %v) = %q; want %q", tt.code, tt.depth, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFoldASCII(t *testing.T) {
+	t.Parallel()
+	in := "╭─ Title ─╮\n│ ✓ done ▸ next … ⠋ │\n╰━━━━━━━━━╯ \x1b[1mbold\x1b[0m héllo"
+	want := "+- Title -+\n| + done > next . . |\n+---------+ \x1b[1mbold\x1b[0m héllo"
+	if got := FoldASCII(in); got != want {
+		t.Errorf("FoldASCII =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestNearest16(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		idx  int // 256-color source
+		want int
+	}{
+		{idx: 114, want: 10}, // pale green: bright green, not yellow
+		{idx: 117, want: 14}, // sky blue: bright cyan, not gray
+		{idx: 203, want: 9},  // salmon: bright red
+		{idx: 221, want: 11}, // gold: bright yellow
+		{idx: 28, want: 2},   // dark green
+		{idx: 160, want: 1},  // dark red
+		{idx: 236, want: 8},  // dark gray stays visible on black
+		{idx: 254, want: 15},
+		{idx: 16, want: 0},
+	}
+	for _, tt := range tests {
+		if got := nearest16(color256RGB(tt.idx)); got != tt.want {
+			t.Errorf("nearest16(color %d) = %d; want %d", tt.idx, got, tt.want)
+		}
+	}
+}
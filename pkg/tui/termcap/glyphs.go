@@ -0,0 +1,93 @@
+// ABOUTME: Glyph sets for separators, spinners, and trees chosen from detected capabilities
+// ABOUTME: Unicode box-drawing/braille when supported, plain ASCII otherwise; FoldASCII rewrites rendered text
+
+package termcap
+
+import "strings"
+
+// Glyphs holds the characters used to draw chrome.
+type Glyphs struct {
+	HRule    string   // horizontal separator cell
+	Spinner  []string // animation frames; Spinner[0] is the static "busy" marker
+	TreeMid  string   // branch connector for a non-last child
+	TreeEnd  string   // branch connector for the last child
+	TreePipe string   // vertical continuation under a non-last child
+	Bullet   string
+	BarFull  string // filled cell of a usage bar
+	BarEmpty string // unused cell of a usage bar
+}
+
+var unicodeGlyphs = Glyphs{
+	HRule:    "─",
+	Spinner:  []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"},
+	TreeMid:  "├── ",
+	TreeEnd:  "└── ",
+	TreePipe: "│   ",
+	Bullet:   "•",
+	BarFull:  "█",
+	BarEmpty: "░",
+}
+
+var asciiGlyphs = Glyphs{
+	HRule:    "-",
+	Spinner:  []string{"|", "/", "-", "\\"},
+	TreeMid:  "|-- ",
+	TreeEnd:  "`-- ",
+	TreePipe: "|   ",
+	Bullet:   "*",
+	BarFull:  "#",
+	BarEmpty: ".",
+}
+
+// Glyphs returns the glyph set appropriate for the terminal.
+func (c Capabilities) Glyphs() Glyphs {
+	if c.Unicode {
+		return unicodeGlyphs
+	}
+	return asciiGlyphs
+}
+
+// asciiFold maps single-cell symbols to a single ASCII cell, so folded text
+// keeps its layout.
+var asciiFold = map[rune]rune{
+	'✓': '+', '✔': '+', '✗': 'x', '✘': 'x',
+	'▸': '>', '▾': 'v', '▶': '>', '►': '>', '◂': '<', '◀': '<',
+	'→': '>', '←': '<', '↑': '^', '↓': 'v', '↳': '>', '⎿': '`',
+	'○': 'o', '◦': 'o', '●': '*', '•': '*', '◆': '*', '◇': 'o', '⏺': '*',
+	'…': '.', '⚠': '!', '⏎': '<', '⏹': '#', '⏸': '"', '≥': '>', '≤': '<',
+	'█': '#', '▓': '#', '▒': '#', '░': '.',
+}
+
+// FoldASCII replaces box-drawing, block, braille, and symbol characters in s
+// with ASCII look-alikes of the same width. Escape sequences and other text
+// are left alone.
+func FoldASCII(s string) string {
+	return strings.Map(foldRune, s)
+}
+
+func foldRune(r rune) rune {
+	if r < 0x80 {
+		return r
+	}
+	if a, ok := asciiFold[r]; ok {
+		return a
+	}
+	switch {
+	case r >= 0x2500 && r <= 0x257F: // box drawing
+		return boxRune(r)
+	case r >= 0x2800 && r <= 0x28FF: // braille (spinners)
+		return '.'
+	}
+	return r
+}
+
+// boxRune maps a box-drawing character to '-', '|', or '+'.
+func boxRune(r rune) rune {
+	switch r {
+	case '─', '━', '┄', '┅', '┈', '┉', '╌', '╍', '═', '╴', '╶', '╸', '╺':
+		return '-'
+	case '│', '┃', '┆', '┇', '┊', '┋', '╎', '╏', '║', '╵', '╷', '╹', '╻':
+		return '|'
+	}
+	return '+'
+}
@@ -0,0 +1,261 @@
+// ABOUTME: Terminal capability detection: color depth, unicode, family, multiplexer, hyperlinks, images
+// ABOUTME: Builds a degradation matrix from environment variables; cached for the process lifetime
+
+package termcap
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/image"
+)
+
+// ColorDepth is the number of colors the terminal can render.
+type ColorDepth int
+
+const (
+	ColorNone ColorDepth = iota // NO_COLOR or dumb terminal
+	Color16                     // basic ANSI palette
+	Color256                    // xterm 256-color palette
+	ColorTrue                   // 24-bit RGB
+)
+
+// String returns a short label for the color depth.
+func (d ColorDepth) String() string {
+	switch d {
+	case Color16:
+		return "16"
+	case Color256:
+		return "256"
+	case ColorTrue:
+		return "truecolor"
+	default:
+		return "none"
+	}
+}
+
+// Family identifies the terminal emulator (or the best guess from $TERM).
+type Family string
+
+const (
+	FamilyUnknown         Family = "unknown"
+	FamilyDumb            Family = "dumb"
+	FamilyXterm           Family = "xterm"
+	FamilyLinuxConsole    Family = "linux-console"
+	FamilyWindowsTerminal Family = "windows-terminal"
+	FamilyWindowsConsole  Family = "windows-console"
+	FamilyKitty           Family = "kitty"
+	FamilyGhostty         Family = "ghostty"
+	FamilyWezTerm         Family = "wezterm"
+	FamilyITerm2          Family = "iterm2"
+	FamilyAppleTerminal   Family = "apple-terminal"
+	FamilyVSCode          Family = "vscode"
+	FamilyAlacritty       Family = "alacritty"
+	FamilyVTE             Family = "vte"
+)
+
+// Capabilities is the detected degradation matrix.
+type Capabilities struct {
+	Family      Family
+	Multiplexer string // "tmux", "screen", or "" when running directly
+	Colors      ColorDepth
+	Unicode     bool
+	Hyperlinks  bool // OSC 8
+	Images      image.ImageProtocol
+}
+
+var (
+	detectOnce sync.Once
+	cached     Capabilities
+)
+
+// Detect probes the process environment once and caches the result.
+func Detect() Capabilities {
+	detectOnce.Do(func() {
+		cached = DetectFrom(os.Getenv, runtime.GOOS)
+	})
+	return cached
+}
+
+// DetectFrom computes capabilities from getenv and the target OS.
+func DetectFrom(getenv func(string) string, goos string) Capabilities {
+	term := strings.ToLower(getenv("TERM"))
+	c := Capabilities{
+		Family:      detectFamily(getenv, term, goos),
+		Multiplexer: detectMultiplexer(getenv, term),
+	}
+	c.Colors = detectColors(getenv, term, c.Family)
+	c.Unicode = detectUnicode(getenv, c.Family)
+	c.Hyperlinks = detectHyperlinks(getenv, c.Family, c.Multiplexer)
+	c.Images = image.DetectFromEnv(getenv).Images
+	if c.Multiplexer != "" {
+		// Graphics escapes need passthrough wrapping that multiplexers often drop.
+		c.Images = image.ProtoNone
+	}
+	return c
+}
+
+func detectFamily(getenv func(string) string, term, goos string) Family {
+	if term == "dumb" {
+		return FamilyDumb
+	}
+	if getenv("WT_SESSION") != "" {
+		return FamilyWindowsTerminal
+	}
+	if getenv("KITTY_WINDOW_ID") != "" {
+		return FamilyKitty
+	}
+	if getenv("GHOSTTY_RESOURCES_DIR") != "" {
+		return FamilyGhostty
+	}
+	if getenv("WEZTERM_PANE") != "" {
+		return FamilyWezTerm
+	}
+	if getenv("ITERM_SESSION_ID") != "" {
+		return FamilyITerm2
+	}
+	switch strings.ToLower(getenv("TERM_PROGRAM")) {
+	case "kitty":
+		return FamilyKitty
+	case "ghostty":
+		return FamilyGhostty
+	case "wezterm":
+		return FamilyWezTerm
+	case "iterm.app":
+		return FamilyITerm2
+	case "apple_terminal":
+		return FamilyAppleTerminal
+	case "vscode":
+		return FamilyVSCode
+	case "alacritty":
+		return FamilyAlacritty
+	}
+	if getenv("VTE_VERSION") != "" {
+		return FamilyVTE
+	}
+	switch {
+	case term == "linux":
+		return FamilyLinuxConsole
+	case strings.HasPrefix(term, "xterm"):
+		return FamilyXterm
+	case strings.HasPrefix(term, "alacritty"):
+		return FamilyAlacritty
+	case term == "" && goos == "windows":
+		return FamilyWindowsConsole
+	}
+	return FamilyUnknown
+}
+
+func detectMultiplexer(getenv func(string) string, term string) string {
+	if getenv("TMUX") != "" || strings.HasPrefix(term, "tmux") {
+		return "tmux"
+	}
+	if getenv("STY") != "" || strings.HasPrefix(term, "screen") {
+		return "screen"
+	}
+	return ""
+}
+
+func detectColors(getenv func(string) string, term string, fam Family) ColorDepth {
+	if getenv("NO_COLOR") != "" || fam == FamilyDumb {
+		return ColorNone
+	}
+	switch strings.ToLower(getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return ColorTrue
+	}
+	switch fam {
+	case FamilyWindowsTerminal, FamilyKitty, FamilyGhostty, FamilyWezTerm,
+		FamilyITerm2, FamilyVSCode, FamilyAlacritty:
+		return ColorTrue
+	case FamilyAppleTerminal:
+		return Color256
+	case FamilyLinuxConsole, FamilyWindowsConsole:
+		return Color16
+	}
+	if strings.Contains(term, "256color") {
+		return Color256
+	}
+	if term == "" {
+		return ColorNone
+	}
+	return Color16
+}
+
+func detectUnicode(getenv func(string) string, fam Family) bool {
+	switch fam {
+	case FamilyDumb, FamilyLinuxConsole, FamilyWindowsConsole:
+		return false
+	case FamilyWindowsTerminal, FamilyKitty, FamilyGhostty, FamilyWezTerm,
+		FamilyITerm2, FamilyAppleTerminal, FamilyVSCode, FamilyAlacritty:
+		return true
+	}
+	// First non-empty locale variable wins, per POSIX precedence.
+	for _, key := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if v := getenv(key); v != "" {
+			v = strings.ToLower(v)
+			return strings.Contains(v, "utf-8") || strings.Contains(v, "utf8")
+		}
+	}
+	return false
+}
+
+func detectHyperlinks(getenv func(string) string, fam Family, mux string) bool {
+	if mux != "" {
+		return false
+	}
+	switch fam {
+	case FamilyWindowsTerminal, FamilyKitty, FamilyGhostty, FamilyWezTerm,
+		FamilyITerm2, FamilyVSCode, FamilyAlacritty:
+		return true
+	case FamilyVTE:
+		// GNOME VTE gained OSC 8 in 0.50 (VTE_VERSION=5000).
+		v, err := strconv.Atoi(getenv("VTE_VERSION"))
+		return err == nil && v >= 5000
+	}
+	return false
+}
+
+// Hyperlink wraps text in an OSC 8 link when supported; otherwise it returns
+// "text (url)" or just url when text is empty or equal to it.
+func (c Capabilities) Hyperlink(url, text string) string {
+	if text == "" {
+		text = url
+	}
+	if c.Hyperlinks {
+		return "\x1b]8;;" + url + "\x1b\\" + text + "\x1b]8;;\x1b\\"
+	}
+	if text == url {
+		return url
+	}
+	return fmt.Sprintf("%s (%s)", text, url)
+}
+
+// Matrix returns the capability rows as label/value pairs for display.
+func (c Capabilities) Matrix() [][2]string {
+	mux := c.Multiplexer
+	if mux == "" {
+		mux = "none"
+	}
+	return [][2]string{
+		{"family", string(c.Family)},
+		{"multiplexer", mux},
+		{"colors", c.Colors.String()},
+		{"unicode", yesNo(c.Unicode)},
+		{"hyperlinks", yesNo(c.Hyperlinks)},
+		{"images", c.Images.String()},
+		{"separator", c.Glyphs().HRule},
+		{"spinner", strings.Join(c.Glyphs().Spinner, " ")},
+	}
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,187 @@
+// ABOUTME: Tests for terminal capability detection and the glyph/hyperlink fallbacks
+// ABOUTME: Drives DetectFrom with synthetic environments for each terminal family
+
+package termcap
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mauromedda/pi-coding-agent-go/pkg/tui/image"
+)
+
+func envFunc(env map[string]string) func(string) string {
+	return func(k string) string { return env[k] }
+}
+
+func TestDetectFrom(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		env        map[string]string
+		goos       string
+		family     Family
+		mux        string
+		colors     ColorDepth
+		unicode    bool
+		hyperlinks bool
+		images     image.ImageProtocol
+	}{
+		{
+			name:   "plain xterm 256 utf8",
+			env:    map[string]string{"TERM": "xterm-256color", "LANG": "en_US.UTF-8"},
+			family: FamilyXterm, colors: Color256, unicode: true,
+		},
+		{
+			name:   "xterm with C locale",
+			env:    map[string]string{"TERM": "xterm", "LANG": "C"},
+			family: FamilyXterm, colors: Color16,
+		},
+		{
+			name:   "LC_ALL overrides LANG",
+			env:    map[string]string{"TERM": "xterm", "LANG": "en_US.UTF-8", "LC_ALL": "C"},
+			family: FamilyXterm, colors: Color16,
+		},
+		{
+			name:   "dumb terminal",
+			env:    map[string]string{"TERM": "dumb", "LANG": "en_US.UTF-8"},
+			family: FamilyDumb, colors: ColorNone,
+		},
+		{
+			name:   "NO_COLOR",
+			env:    map[string]string{"TERM": "xterm-256color", "COLORTERM": "truecolor", "NO_COLOR": "1"},
+			family: FamilyXterm, colors: ColorNone,
+		},
+		{
+			name:   "linux console",
+			env:    map[string]string{"TERM": "linux", "LANG": "en_US.UTF-8"},
+			family: FamilyLinuxConsole, colors: Color16,
+		},
+		{
+			name:   "windows terminal",
+			env:    map[string]string{"WT_SESSION": "abc"},
+			goos:   "windows",
+			family: FamilyWindowsTerminal, colors: ColorTrue, unicode: true, hyperlinks: true,
+		},
+		{
+			name:   "legacy windows console",
+			env:    map[string]string{},
+			goos:   "windows",
+			family: FamilyWindowsConsole, colors: Color16,
+		},
+		{
+			name:   "kitty",
+			env:    map[string]string{"TERM": "xterm-kitty", "KITTY_WINDOW_ID": "1"},
+			family: FamilyKitty, colors: ColorTrue, unicode: true, hyperlinks: true, images: image.ProtoKitty,
+		},
+		{
+			name:   "kitty inside tmux",
+			env:    map[string]string{"TERM": "tmux-256color", "TMUX": "/tmp/tmux", "KITTY_WINDOW_ID": "1"},
+			family: FamilyKitty, mux: "tmux", colors: ColorTrue, unicode: true,
+		},
+		{
+			name:   "screen",
+			env:    map[string]string{"TERM": "screen-256color", "STY": "1.pts", "LANG": "en_US.UTF-8"},
+			family: FamilyUnknown, mux: "screen", colors: Color256, unicode: true,
+		},
+		{
+			name:   "new VTE supports hyperlinks",
+			env:    map[string]string{"TERM": "xterm-256color", "VTE_VERSION": "6003", "COLORTERM": "truecolor", "LANG": "en_US.UTF-8"},
+			family: FamilyVTE, colors: ColorTrue, unicode: true, hyperlinks: true,
+		},
+		{
+			name:   "old VTE lacks hyperlinks",
+			env:    map[string]string{"TERM": "xterm-256color", "VTE_VERSION": "4205", "LANG": "en_US.UTF-8"},
+			family: FamilyVTE, colors: Color256, unicode: true,
+		},
+		{
+			name:   "iterm2",
+			env:    map[string]string{"TERM_PROGRAM": "iTerm.app", "TERM": "xterm-256color"},
+			family: FamilyITerm2, colors: ColorTrue, unicode: true, hyperlinks: true, images: image.ProtoITerm2,
+		},
+		{
+			name:   "apple terminal",
+			env:    map[string]string{"TERM_PROGRAM": "Apple_Terminal", "TERM": "xterm-256color"},
+			family: FamilyAppleTerminal, colors: Color256, unicode: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			goos := tt.goos
+			if goos == "" {
+				goos = "linux"
+			}
+			c := DetectFrom(envFunc(tt.env), goos)
+			if c.Family != tt.family {
+				t.Errorf("Family = %q; want %q", c.Family, tt.family)
+			}
+			if c.Multiplexer != tt.mux {
+				t.Errorf("Multiplexer = %q; want %q", c.Multiplexer, tt.mux)
+			}
+			if c.Colors != tt.colors {
+				t.Errorf("Colors = %v; want %v", c.Colors, tt.colors)
+			}
+			if c.Unicode != tt.unicode {
+				t.Errorf("Unicode = %v; want %v", c.Unicode, tt.unicode)
+			}
+			if c.Hyperlinks != tt.hyperlinks {
+				t.Errorf("Hyperlinks = %v; want %v", c.Hyperlinks, tt.hyperlinks)
+			}
+			if c.Images != tt.images {
+				t.Errorf("Images = %v; want %v", c.Images, tt.images)
+			}
+		})
+	}
+}
+
+func TestHyperlink(t *testing.T) {
+	t.Parallel()
+	on := Capabilities{Hyperlinks: true}
+	if got := on.Hyperlink("https://x.dev", "docs"); got != "\x1b]8;;https://x.dev\x1b\\docs\x1b]8;;\x1b\\" {
+		t.Errorf("OSC 8 link = %q", got)
+	}
+
+	off := Capabilities{}
+	if got := off.Hyperlink("https://x.dev", "docs"); got != "docs (https://x.dev)" {
+		t.Errorf("fallback = %q", got)
+	}
+	if got := off.Hyperlink("https://x.dev", ""); got != "https://x.dev" {
+		t.Errorf("fallback without text = %q", got)
+	}
+}
+
+func TestGlyphs_ASCIIFallback(t *testing.T) {
+	t.Parallel()
+	g := Capabilities{Unicode: false}.Glyphs()
+	all := g.HRule + g.TreeMid + g.TreeEnd + g.TreePipe + g.Bullet + g.BarFull + g.BarEmpty + strings.Join(g.Spinner, "")
+	for _, r := range all {
+		if r > 0x7f {
+			t.Fatalf("ASCII glyph set contains non-ASCII rune %q", r)
+		}
+	}
+	if u := (Capabilities{Unicode: true}).Glyphs(); u.HRule != "─" {
+		t.Errorf("unicode HRule = %q; want ─", u.HRule)
+	}
+}
+
+func TestMatrix_ReportsAllRows(t *testing.T) {
+	t.Parallel()
+	rows := Capabilities{Family: FamilyXterm, Colors: Color256}.Matrix()
+	got := map[string]string{}
+	for _, r := range rows {
+		got[r[0]] = r[1]
+	}
+	want := map[string]string{
+		"family": "xterm", "multiplexer": "none", "colors": "256",
+		"unicode": "no", "hyperlinks": "no", "separator": "-",
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %q; want %q", k, got[k], v)
+		}
+	}
+	if _, ok := got["images"]; !ok {
+		t.Error("missing images row")
+	}
+}
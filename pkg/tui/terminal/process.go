@@ -13,9 +13,10 @@ import (
 
 // ProcessTerminal is a real terminal backed by os.Stdout and x/term.
 type ProcessTerminal struct {
-	mu       sync.Mutex
-	oldState *term.State
-	resizeFn func(width, height int)
+	mu        sync.Mutex
+	oldState  *term.State
+	restoreVT func() // undoes enableVT; nil outside raw mode
+	resizeFn  func(width, height int)
 }
 
 // NewProcessTerminal returns a ProcessTerminal ready for use.
@@ -24,6 +25,9 @@ func NewProcessTerminal() *ProcessTerminal {
 }
 
 // EnterRawMode switches stdin to raw mode, saving the previous state.
+// Calling it again while raw re-applies raw mode (a shell may have reset
+// the terminal while the process was stopped) but keeps the state saved
+// first, so ExitRawMode still restores the shell's line mode.
 func (t *ProcessTerminal) EnterRawMode() error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -32,7 +36,15 @@ func (t *ProcessTerminal) EnterRawMode() error {
 	if err != nil {
 		return fmt.Errorf("entering raw mode: %w", err)
 	}
-	t.oldState = state
+	if t.oldState == nil {
+		t.oldState = state
+	}
+	if t.restoreVT == nil {
+		// Best effort: consoles predating VT support still get raw input.
+		if restore, err := enableVT(); err == nil {
+			t.restoreVT = restore
+		}
+	}
 	return nil
 }
 
@@ -44,6 +56,10 @@ func (t *ProcessTerminal) ExitRawMode() error {
 	if t.oldState == nil {
 		return nil
 	}
+	if t.restoreVT != nil {
+		t.restoreVT()
+		t.restoreVT = nil
+	}
 	if err := term.Restore(int(os.Stdin.Fd()), t.oldState); err != nil {
 		return fmt.Errorf("exiting raw mode: %w", err)
 	}
@@ -78,3 +94,36 @@ func (t *ProcessTerminal) OnResize(fn func(width, height int)) {
 
 	t.startResizeListener()
 }
+
+// Suspend leaves raw mode, stops the process group until SIGCONT (Ctrl+Z
+// semantics), then re-enters raw mode and invokes the resize callback so the
+// caller repaints. It is a no-op on platforms without job control.
+func (t *ProcessTerminal) Suspend() error {
+	t.mu.Lock()
+	wasRaw := t.oldState != nil
+	t.mu.Unlock()
+
+	if wasRaw {
+		if err := t.ExitRawMode(); err != nil {
+			return err
+		}
+	}
+
+	suspendProcess()
+
+	if wasRaw {
+		if err := t.EnterRawMode(); err != nil {
+			return err
+		}
+	}
+
+	t.mu.Lock()
+	fn := t.resizeFn
+	t.mu.Unlock()
+	if fn != nil {
+		if w, h, err := t.Size(); err == nil {
+			fn(w, h)
+		}
+	}
+	return nil
+}
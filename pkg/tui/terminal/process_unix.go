@@ -1,4 +1,4 @@
-// ABOUTME: Unix-specific SIGWINCH handling for ProcessTerminal resize events.
+// ABOUTME: Unix-specific SIGWINCH handling and job-control suspend for ProcessTerminal.
 // ABOUTME: Spawns a goroutine that listens for SIGWINCH and invokes the resize callback.
 
 //go:build unix
@@ -35,3 +35,19 @@ func (t *ProcessTerminal) startResizeListener() {
 		}
 	}()
 }
+
+// enableVT is a no-op: Unix terminals always interpret escape sequences.
+func enableVT() (restore func(), err error) {
+	return func() {}, nil
+}
+
+// suspendProcess stops the process group and blocks until SIGCONT.
+// SIGSTOP is used so the stop cannot be swallowed by a SIGTSTP handler.
+func suspendProcess() {
+	cont := make(chan os.Signal, 1)
+	signal.Notify(cont, syscall.SIGCONT)
+	defer signal.Stop(cont)
+
+	_ = syscall.Kill(0, syscall.SIGSTOP)
+	<-cont
+}
@@ -1,13 +1,71 @@
-// ABOUTME: Windows stub for ProcessTerminal resize handling.
-// ABOUTME: Placeholder; Windows does not use SIGWINCH signals.
+// ABOUTME: Windows console support for ProcessTerminal: VT processing in raw mode and polled resizes
+// ABOUTME: Windows Terminal and other ConPTY hosts interpret the same escape sequences as Unix terminals
 
 //go:build windows
 
 package terminal
 
-// startResizeListener is a no-op on Windows.
-// Windows terminal resize detection requires SetConsoleMode and
-// ReadConsoleInput, which is left for future implementation.
+import (
+	"os"
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+// resizePollInterval is how often the console size is checked; Windows
+// has no SIGWINCH.
+const resizePollInterval = 250 * time.Millisecond
+
+// startResizeListener polls the console size and calls the resize
+// callback when it changes.
 func (t *ProcessTerminal) startResizeListener() {
-	// No-op: Windows resize detection is not yet implemented.
+	go func() {
+		lastW, lastH, _ := t.Size()
+		ticker := time.NewTicker(resizePollInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			w, h, err := t.Size()
+			if err != nil || (w == lastW && h == lastH) {
+				continue
+			}
+			lastW, lastH = w, h
+
+			t.mu.Lock()
+			fn := t.resizeFn
+			t.mu.Unlock()
+			if fn != nil {
+				fn(w, h)
+			}
+		}
+	}()
 }
+
+// enableVT turns on virtual terminal processing for the console: output
+// escape sequences (cursor moves, colors, the alternate screen) are
+// interpreted, and keys arrive as VT input the way they do on Unix. It
+// must run after stdin is in raw mode, whose saved state restores the
+// input mode; the returned func restores the output mode.
+func enableVT() (restore func(), err error) {
+	in := windows.Handle(os.Stdin.Fd())
+	var inMode uint32
+	if err := windows.GetConsoleMode(in, &inMode); err != nil {
+		return nil, err
+	}
+	if err := windows.SetConsoleMode(in, inMode|windows.ENABLE_VIRTUAL_TERMINAL_INPUT); err != nil {
+		return nil, err
+	}
+
+	out := windows.Handle(os.Stdout.Fd())
+	var outMode uint32
+	if err := windows.GetConsoleMode(out, &outMode); err != nil {
+		return nil, err
+	}
+	vt := outMode | windows.ENABLE_PROCESSED_OUTPUT | windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING | windows.DISABLE_NEWLINE_AUTO_RETURN
+	if err := windows.SetConsoleMode(out, vt); err != nil {
+		return nil, err
+	}
+	return func() { _ = windows.SetConsoleMode(out, outMode) }, nil
+}
+
+// suspendProcess is a no-op on Windows, which has no job control.
+func suspendProcess() {}
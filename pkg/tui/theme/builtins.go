@@ -48,7 +48,8 @@ var builtins = map[string]*Theme{
 		},
 	},
 	"light": {
-		Name: "light",
+		Name:  "light",
+		Light: true,
 		Palette: Palette{
 			Primary:   NewColor("\x1b[30m"),
 			Secondary: NewColor("\x1b[37m"),
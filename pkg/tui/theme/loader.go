@@ -49,6 +49,7 @@ type jsonPalette struct {
 type jsonTheme struct {
 	Name    string      `json:"name"`
 	Palette jsonPalette `json:"palette"`
+	Light   bool        `json:"light"`
 }
 
 // LoadFile reads a JSON theme file and returns a Theme.
@@ -70,6 +71,7 @@ func LoadFile(path string) (*Theme, error) {
 	return &Theme{
 		Name:    jt.Name,
 		Palette: p,
+		Light:   jt.Light,
 	}, nil
 }
 
@@ -0,0 +1,53 @@
+// ABOUTME: Theme lookup by name: built-ins first, then <name>.json in the theme directories
+// ABOUTME: Available lists every selectable theme name for pickers and completion
+
+package theme
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Resolve returns the theme called name: a built-in, or the first
+// <name>.json found in dirs. A file theme without a name takes name.
+func Resolve(name string, dirs []string) (*Theme, error) {
+	if th := Builtin(name); th != nil {
+		return th, nil
+	}
+	for _, dir := range dirs {
+		th, err := LoadFile(filepath.Join(dir, name+".json"))
+		if err != nil {
+			continue
+		}
+		if th.Name == "" {
+			th.Name = name
+		}
+		return th, nil
+	}
+	return nil, fmt.Errorf("unknown theme %q", name)
+}
+
+// Available returns the built-in theme names followed by the sorted names
+// of the JSON themes in dirs. A file shadowed by an earlier name is listed once.
+func Available(dirs []string) []string {
+	names := BuiltinNames()
+	var custom []string
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			name, ok := strings.CutSuffix(e.Name(), ".json")
+			if !ok || e.IsDir() || name == "" || slices.Contains(names, name) || slices.Contains(custom, name) {
+				continue
+			}
+			custom = append(custom, name)
+		}
+	}
+	slices.Sort(custom)
+	return append(names, custom...)
+}
@@ -0,0 +1,62 @@
+// ABOUTME: Tests for theme lookup by name and listing of available themes
+// ABOUTME: Covers built-ins, JSON themes in directories, shadowing, and unknown names
+
+package theme
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func writeTheme(t *testing.T, dir, file, data string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, file), []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+
+	project, user := t.TempDir(), t.TempDir()
+	writeTheme(t, project, "ocean.json", `{"palette": {"accent": "\u001b[34m"}, "light": true}`)
+	writeTheme(t, user, "ocean.json", `{"name": "user-ocean"}`)
+	writeTheme(t, user, "dusk.json", `{"name": "Dusk"}`)
+	dirs := []string{project, user}
+
+	if th, err := Resolve("light", dirs); err != nil || th != Builtin("light") || !th.Light {
+		t.Errorf("Resolve(light) = %v, %v; want the light built-in", th, err)
+	}
+	th, err := Resolve("ocean", dirs)
+	if err != nil {
+		t.Fatalf("Resolve(ocean): %v", err)
+	}
+	if th.Name != "ocean" || !th.Light || th.Palette.Accent.Code() != "\x1b[34m" {
+		t.Errorf("Resolve(ocean) = %+v; want the project file, named after it", th)
+	}
+	if th, _ := Resolve("dusk", dirs); th == nil || th.Name != "Dusk" {
+		t.Errorf("Resolve(dusk) = %v; want the user file", th)
+	}
+	if _, err := Resolve("nope", dirs); err == nil {
+		t.Error("Resolve(nope) should fail")
+	}
+}
+
+func TestAvailable(t *testing.T) {
+	t.Parallel()
+
+	project, user := t.TempDir(), t.TempDir()
+	writeTheme(t, project, "zebra.json", `{}`)
+	writeTheme(t, project, "notes.txt", ``)
+	writeTheme(t, user, "dark.json", `{}`)
+	writeTheme(t, user, "ocean.json", `{}`)
+	writeTheme(t, user, "zebra.json", `{}`)
+
+	got := Available([]string{project, user, filepath.Join(user, "missing")})
+	want := []string{"default", "dark", "light", "monochrome", "ocean", "zebra"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Available = %q; want %q", got, want)
+	}
+}
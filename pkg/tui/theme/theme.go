@@ -3,6 +3,8 @@
 
 package theme
 
+import "reflect"
+
 // Color represents a terminal color that can style text.
 type Color struct {
 	code string
@@ -86,6 +88,22 @@ type Palette struct {
 type Theme struct {
 	Name    string  `json:"name"`
 	Palette Palette `json:"palette"`
+	// Light marks a palette made for a light terminal background; rendered
+	// Markdown switches to its light style to match.
+	Light bool `json:"light,omitempty"`
+}
+
+// MapColors returns a copy of t with every palette color code passed
+// through fn, e.g. to downsample it for the terminal.
+func (t *Theme) MapColors(fn func(code string) string) *Theme {
+	out := *t
+	pv := reflect.ValueOf(&out.Palette).Elem()
+	for i := range pv.NumField() {
+		if c, ok := pv.Field(i).Interface().(Color); ok {
+			pv.Field(i).Set(reflect.ValueOf(NewColor(fn(c.code))))
+		}
+	}
+	return &out
 }
 
 // DefaultPalette returns the palette matching the current hardcoded colors.
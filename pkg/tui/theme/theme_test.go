@@ -92,3 +92,21 @@ func TestDefaultPalette_AllFieldsSet(t *testing.T) {
 		}
 	}
 }
+
+func TestTheme_MapColors(t *testing.T) {
+	t.Parallel()
+	orig := Builtin("dark")
+	mapped := orig.MapColors(func(code string) string { return "<" + code + ">" })
+	if mapped == orig || mapped.Name != "dark" {
+		t.Fatalf("MapColors should return a copy with the same name, got %p (%q)", mapped, mapped.Name)
+	}
+	if got := mapped.Palette.Accent.Code(); got != "<\x1b[38;5;214m>" {
+		t.Errorf("Accent = %q; want the mapped code", got)
+	}
+	if got := mapped.Palette.Underline.Code(); got != "<\x1b[4m>" {
+		t.Errorf("Underline = %q; want every field mapped", got)
+	}
+	if got := orig.Palette.Accent.Code(); got != "\x1b[38;5;214m" {
+		t.Errorf("original Accent = %q; want it unchanged", got)
+	}
+}
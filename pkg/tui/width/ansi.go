@@ -49,6 +49,12 @@ func containsESC(s string) bool {
 	return strings.IndexByte(s, 0x1b) >= 0
 }
 
+// SequenceEnd returns the index just past the escape sequence starting at
+// s[i], or i when s[i] is not ESC.
+func SequenceEnd(s string, i int) int {
+	return skipANSISequence(s, i)
+}
+
 // skipANSISequence advances past an ANSI escape sequence starting at s[i].
 // Returns the index of the first byte after the sequence.
 func skipANSISequence(s string, i int) int {
@@ -78,3 +78,27 @@ func TestActiveSGR(t *testing.T) {
 		t.Errorf("after reset, SGR.String() = %q, want empty", s)
 	}
 }
+
+func TestSequenceEnd(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		i     int
+		want  int
+	}{
+		{name: "csi", input: "a\x1b[31mb", i: 1, want: 6},
+		{name: "osc st", input: "\x1b]8;;x\x1b\\y", i: 0, want: 8},
+		{name: "not esc", input: "abc", i: 1, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := SequenceEnd(tt.input, tt.i); got != tt.want {
+				t.Errorf("SequenceEnd(%q, %d) = %d; want %d", tt.input, tt.i, got, tt.want)
+			}
+		})
+	}
+}